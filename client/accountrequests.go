@@ -0,0 +1,19 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// AccountRequests fetches up to 'limit' receipts of requests sent to the chain by the agent, the latest first.
+// If 'before' is nil, the latest receipt is fetched first. Otherwise pass Next of the previous page
+// to get older receipts
+func (c *WaspClient) AccountRequests(chainID *coretypes.ChainID, agentID coretypes.AgentID, before *uint32, limit uint32) (*model.AccountRequests, error) {
+	var beforeParam *int64
+	if before != nil {
+		b := int64(*before)
+		beforeParam = &b
+	}
+	limitParam := int64(limit)
+	return c.API().GetAccountRequests(chainID.String(), agentID.Bech32(), beforeParam, &limitParam)
+}
@@ -1,18 +1,21 @@
 package client
 
 import (
-	"net/http"
-
 	"github.com/iotaledger/wasp/packages/coretypes"
-	"github.com/iotaledger/wasp/packages/webapi/routes"
 )
 
 // ActivateChain sends a request to activate a chain in the wasp node
 func (c *WaspClient) ActivateChain(chainid coretypes.ChainID) error {
-	return c.do(http.MethodPost, routes.ActivateChain(chainid.String()), nil, nil)
+	return c.API().ActivateChain(chainid.String())
 }
 
 // DeactivateChain sends a request to deactivate a chain in the wasp node
 func (c *WaspClient) DeactivateChain(chainid coretypes.ChainID) error {
-	return c.do(http.MethodPost, routes.DeactivateChain(chainid.String()), nil, nil)
+	return c.API().DeactivateChain(chainid.String())
+}
+
+// DrainChain sends a request to deactivate a chain gracefully in the wasp node.
+// If 'wait' is true, the call returns when the round in progress is finished and the chain is dismissed
+func (c *WaspClient) DrainChain(chainid coretypes.ChainID, wait bool) error {
+	return c.API().DrainChain(chainid.String(), &wait)
 }
@@ -1,25 +1,23 @@
 package client
 
 import (
-	"net/http"
-
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/webapi/model"
-	"github.com/iotaledger/wasp/packages/webapi/routes"
 )
 
 // PutBlob uploads a blob to the registry
 func (c *WaspClient) PutBlob(data []byte) (hashing.HashValue, error) {
-	req := model.NewBlobData(data)
-	res := &model.BlobInfo{}
-	err := c.do(http.MethodGet, routes.PutBlob(), req, res)
-	return res.Hash.HashValue(), err
+	res, err := c.API().PutBlob(model.NewBlobData(data))
+	if err != nil {
+		return hashing.HashValue{}, err
+	}
+	return res.Hash.HashValue(), nil
 }
 
 // GetBlob fetches a blob by its hash
 func (c *WaspClient) GetBlob(hash hashing.HashValue) ([]byte, error) {
-	res := &model.BlobData{}
-	err := c.do(http.MethodGet, routes.GetBlob(hash.String()), nil, res)
+	res, err := c.API().GetBlob(hash.String())
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +26,23 @@ func (c *WaspClient) GetBlob(hash hashing.HashValue) ([]byte, error) {
 
 // HasBlob returns whether or not a blob exists
 func (c *WaspClient) HasBlob(hash hashing.HashValue) (bool, error) {
-	res := &model.BlobInfo{}
-	err := c.do(http.MethodGet, routes.HasBlob(hash.String()), nil, res)
-	return res.Exists, err
+	res, err := c.API().HasBlob(hash.String())
+	if err != nil {
+		return false, err
+	}
+	return res.Exists, nil
+}
+
+// PutChainBlob uploads a blob to the registry on behalf of the chain. The blob counts against the blob quota of the chain
+func (c *WaspClient) PutChainBlob(chainID *coretypes.ChainID, data []byte) (hashing.HashValue, error) {
+	res, err := c.API().PutChainBlob(chainID.String(), model.NewBlobData(data))
+	if err != nil {
+		return hashing.HashValue{}, err
+	}
+	return res.Hash.HashValue(), nil
+}
+
+// GetChainBlobUsage returns the total size and number of blobs referenced by the chain, and the quota
+func (c *WaspClient) GetChainBlobUsage(chainID *coretypes.ChainID) (*model.BlobUsage, error) {
+	return c.API().GetChainBlobUsage(chainID.String())
 }
@@ -0,0 +1,19 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// GetBlockedPeers fetches NetIDs of the peers the node exchanges no messages with
+func (c *WaspClient) GetBlockedPeers() ([]string, error) {
+	res, err := c.API().GetBlockedPeers()
+	if err != nil {
+		return nil, err
+	}
+	return res.NetIDs, nil
+}
+
+// SetBlockedPeers cuts off the traffic of the node with the peers. Nil restores the traffic with all peers
+func (c *WaspClient) SetBlockedPeers(netIDs []string) error {
+	return c.API().SetBlockedPeers(&model.BlockedPeers{NetIDs: netIDs})
+}
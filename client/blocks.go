@@ -0,0 +1,21 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+// ExportBlocks fetches blocks of the chain with indices from 'from' to 'to' inclusive, serialized with state.WriteBlocks.
+// If 'to' is nil, blocks up to the solid state of the chain are fetched
+func (c *WaspClient) ExportBlocks(chainID *coretypes.ChainID, from uint32, to *uint32) ([]byte, error) {
+	fromParam := int64(from)
+	var toParam *int64
+	if to != nil {
+		t := int64(*to)
+		toParam = &t
+	}
+	res, err := c.API().ExportBlocks(chainID.String(), &fromParam, toParam)
+	if err != nil {
+		return nil, err
+	}
+	return res.Data.Bytes(), nil
+}
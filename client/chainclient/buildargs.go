@@ -0,0 +1,14 @@
+package chainclient
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+)
+
+// BuildArgs builds request arguments with the builder. Values off-loaded by the builder are uploaded
+// to the blob cache of the node on behalf of the chain
+func (c *Client) BuildArgs(b *requestargs.Builder) (requestargs.RequestArgs, error) {
+	return b.Build(func(data []byte) error {
+		_, err := c.WaspClient.PutChainBlob(&c.ChainID, data)
+		return err
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/wasp/client"
 	"github.com/iotaledger/wasp/client/level1"
@@ -37,6 +38,8 @@ func New(
 type PostRequestParams struct {
 	Transfer coretypes.ColoredBalances
 	Args     requestargs.RequestArgs
+	// Mint is the number of new colored tokens minted by the request transaction to each address
+	Mint map[address.Address]int64
 }
 
 // PostRequest sends a request transaction to the chain
@@ -59,6 +62,7 @@ func (c *Client) PostRequest(
 			Transfer:         par.Transfer,
 			Args:             par.Args,
 		}},
+		Mint: par.Mint,
 		Post: true,
 	})
 }
@@ -0,0 +1,275 @@
+package chainclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/client/level1"
+	"github.com/iotaledger/wasp/packages/apilib"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/util/multicall"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// ErrNoQuorum is returned by CallViewChecked when not enough nodes returned the same result of the view call
+var ErrNoQuorum = errors.New("no quorum of nodes agree on the result")
+
+// MultiClient allows to send webapi requests to a specific chain through several committee or access nodes.
+// View calls are spread over the nodes round-robin. If the node doesn't respond or fails with an internal
+// error, the call is retried with the next node and the failed node is skipped for the FailureBackoff period.
+// Errors reported by the node about the call itself, e.g. the wrong function name, are returned without retries.
+// Critical reads are cross-checked with CallViewChecked against the quorum of nodes, so a single lying or
+// unsynced node is detected. Request transactions are built once and posted to the ledger with retries,
+// the same transaction is posted again, so retries never duplicate the request.
+// The client is safe for concurrent use
+type MultiClient struct {
+	Level1Client level1.Level1Client
+	ChainID      coretypes.ChainID
+	SigScheme    signaturescheme.SignatureScheme
+	// Quorum is the number of nodes which must return the same result to CallViewChecked.
+	// The majority of nodes by default
+	Quorum int
+	// PostRetries is the number of attempts to post the request transaction to the ledger
+	PostRetries int
+	// RetryDelay is the delay between attempts to post the request transaction
+	RetryDelay time.Duration
+	// FailureBackoff is the period the failed node is not used for calls, unless all nodes failed
+	FailureBackoff time.Duration
+	// Timeout is the timeout of one node in CallViewChecked
+	Timeout time.Duration
+
+	nodes []*client.WaspClient
+
+	mutex       sync.Mutex
+	next        int
+	failedUntil []time.Time
+}
+
+// NewMultiClient creates a new chainclient.MultiClient with the webapi hosts of the nodes of the chain
+func NewMultiClient(
+	level1Client level1.Level1Client,
+	hosts []string,
+	chainID coretypes.ChainID,
+	sigScheme signaturescheme.SignatureScheme,
+	httpClient ...func() http.Client,
+) *MultiClient {
+	m := &MultiClient{
+		Level1Client:   level1Client,
+		ChainID:        chainID,
+		SigScheme:      sigScheme,
+		Quorum:         len(hosts)/2 + 1,
+		PostRetries:    3,
+		RetryDelay:     time.Second,
+		FailureBackoff: 30 * time.Second,
+		Timeout:        30 * time.Second,
+		nodes:          make([]*client.WaspClient, len(hosts)),
+		failedUntil:    make([]time.Time, len(hosts)),
+	}
+	for i, host := range hosts {
+		if len(httpClient) > 0 {
+			m.nodes[i] = client.NewWaspClient(host, httpClient[0]())
+		} else {
+			m.nodes[i] = client.NewWaspClient(host)
+		}
+	}
+	return m
+}
+
+// Len returns the number of nodes of the client
+func (m *MultiClient) Len() int {
+	return len(m.nodes)
+}
+
+// Client returns the chainclient.Client which sends webapi requests to the node with the index
+func (m *MultiClient) Client(i int) *Client {
+	return New(m.Level1Client, m.nodes[i], m.ChainID, m.SigScheme)
+}
+
+// CallView calls the view function of the contract on one of the nodes. If the node fails,
+// the call is retried with the next node
+func (m *MultiClient) CallView(contractHname coretypes.Hname, fname string, arguments dict.Dict) (dict.Dict, error) {
+	var ret dict.Dict
+	err := m.withFailover(func(w *client.WaspClient) error {
+		var err error
+		ret, err = w.CallView(coretypes.NewContractID(m.ChainID, contractHname), fname, arguments)
+		return err
+	})
+	return ret, err
+}
+
+// CallViewChecked calls the view function of the contract on all nodes and returns the result returned
+// by at least Quorum nodes. Hosts of the nodes which failed or returned a different result are returned
+// as dissenting: they may be lying or not synced with the chain yet.
+// ErrNoQuorum is returned if not enough nodes agree
+func (m *MultiClient) CallViewChecked(contractHname coretypes.Hname, fname string, arguments dict.Dict) (dict.Dict, []string, error) {
+	results := make([]dict.Dict, len(m.nodes))
+	funs := make([]func() error, len(m.nodes))
+	for i := range m.nodes {
+		j := i // duplicate variable for closure
+		funs[j] = func() error {
+			var err error
+			results[j], err = m.nodes[j].CallView(coretypes.NewContractID(m.ChainID, contractHname), fname, arguments)
+			return err
+		}
+	}
+	errs := multicall.MultiCall(funs, m.Timeout)
+
+	votes := make(map[hashing.HashValue][]int)
+	for i, err := range errs {
+		if err != nil {
+			continue
+		}
+		h := results[i].Hash()
+		votes[h] = append(votes[h], i)
+	}
+	var agreed []int
+	for _, nodes := range votes {
+		if len(nodes) > len(agreed) {
+			agreed = nodes
+		}
+	}
+	inQuorum := make(map[int]bool)
+	for _, i := range agreed {
+		inQuorum[i] = true
+	}
+	var dissenting []string
+	for i, w := range m.nodes {
+		if !inQuorum[i] {
+			dissenting = append(dissenting, w.BaseURL())
+		}
+	}
+	if len(agreed) < m.Quorum {
+		return nil, dissenting, fmt.Errorf("%w: %d of %d nodes agree, quorum is %d",
+			ErrNoQuorum, len(agreed), len(m.nodes), m.Quorum)
+	}
+	return results[agreed[0]], dissenting, nil
+}
+
+// PostRequest builds the request transaction and posts it to the ledger. If the post fails,
+// the same transaction is posted again up to PostRetries times
+func (m *MultiClient) PostRequest(
+	contractHname coretypes.Hname,
+	entryPoint coretypes.Hname,
+	params ...PostRequestParams,
+) (*sctransaction.Transaction, error) {
+	par := PostRequestParams{}
+	if len(params) > 0 {
+		par = params[0]
+	}
+	tx, err := apilib.CreateRequestTransaction(apilib.CreateRequestTransactionParams{
+		Level1Client:    m.Level1Client,
+		SenderSigScheme: m.SigScheme,
+		RequestSectionParams: []apilib.RequestSectionParams{{
+			TargetContractID: coretypes.NewContractID(m.ChainID, contractHname),
+			EntryPointCode:   entryPoint,
+			Transfer:         par.Transfer,
+			Args:             par.Args,
+		}},
+		Mint: par.Mint,
+	})
+	if err != nil {
+		return nil, err
+	}
+	attempts := m.PostRetries
+	if attempts < 1 {
+		attempts = 1
+	}
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			time.Sleep(m.RetryDelay)
+		}
+		if err = m.Level1Client.PostTransaction(tx.Transaction); err == nil {
+			return tx, nil
+		}
+	}
+	return nil, fmt.Errorf("can't post the request transaction %s in %d attempts: %v", tx.ID().String(), attempts, err)
+}
+
+// WaitUntilAllRequestsProcessed blocks until all requests in the transaction which target the chain
+// have been processed by one of the nodes. If the node fails, the next node is asked
+func (m *MultiClient) WaitUntilAllRequestsProcessed(tx *sctransaction.Transaction, timeout time.Duration) error {
+	return m.withFailover(func(w *client.WaspClient) error {
+		return w.WaitUntilChainRequestsProcessed(&m.ChainID, tx, timeout)
+	})
+}
+
+// PostRequestSync posts the request and waits until it is processed by one of the nodes
+func (m *MultiClient) PostRequestSync(
+	contractHname coretypes.Hname,
+	entryPoint coretypes.Hname,
+	timeout time.Duration,
+	params ...PostRequestParams,
+) (*sctransaction.Transaction, error) {
+	tx, err := m.PostRequest(contractHname, entryPoint, params...)
+	if err != nil {
+		return nil, err
+	}
+	return tx, m.WaitUntilAllRequestsProcessed(tx, timeout)
+}
+
+// withFailover calls the function with the nodes in turn, starting with the next node round-robin,
+// until it succeeds or fails with the error which is not a failure of the node
+func (m *MultiClient) withFailover(f func(w *client.WaspClient) error) error {
+	if len(m.nodes) == 0 {
+		return errors.New("no nodes")
+	}
+	var errs []string
+	for _, i := range m.nodeOrder() {
+		err := f(m.nodes[i])
+		if err == nil || !isNodeFailure(err) {
+			return err
+		}
+		m.markFailed(i)
+		errs = append(errs, fmt.Sprintf("%s: %v", m.nodes[i].BaseURL(), err))
+	}
+	return fmt.Errorf("all nodes failed:\n%s", strings.Join(errs, "\n"))
+}
+
+// nodeOrder returns indices of all nodes starting with the next node round-robin.
+// Nodes which failed recently are moved to the end
+func (m *MultiClient) nodeOrder() []int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	start := m.next
+	m.next = (m.next + 1) % len(m.nodes)
+
+	nowis := time.Now()
+	healthy := make([]int, 0, len(m.nodes))
+	var failed []int
+	for k := range m.nodes {
+		i := (start + k) % len(m.nodes)
+		if nowis.Before(m.failedUntil[i]) {
+			failed = append(failed, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	return append(healthy, failed...)
+}
+
+func (m *MultiClient) markFailed(i int) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.failedUntil[i] = time.Now().Add(m.FailureBackoff)
+}
+
+// isNodeFailure returns true if the node didn't respond or failed with an internal error.
+// Other errors are answers of the node about the call
+func isNodeFailure(err error) bool {
+	var httpErr *model.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
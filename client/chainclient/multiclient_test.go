@@ -0,0 +1,110 @@
+package chainclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/stretchr/testify/require"
+)
+
+var testContract = coretypes.Hn("test")
+
+// fakeNode serves view calls of the chain with the fixed result or the fixed HTTP status
+type fakeNode struct {
+	server *httptest.Server
+	calls  int32
+}
+
+func newFakeNode(t *testing.T, status int, result dict.Dict) *fakeNode {
+	n := &fakeNode{}
+	n.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&n.calls, 1)
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(result))
+	}))
+	t.Cleanup(n.server.Close)
+	return n
+}
+
+func (n *fakeNode) numCalls() int {
+	return int(atomic.LoadInt32(&n.calls))
+}
+
+func newTestMultiClient(nodes ...*fakeNode) *MultiClient {
+	hosts := make([]string, len(nodes))
+	for i, n := range nodes {
+		hosts[i] = n.server.URL
+	}
+	return NewMultiClient(nil, hosts, coretypes.ChainID{1, 2, 3}, nil)
+}
+
+func result(v string) dict.Dict {
+	return dict.FromGoMap(map[kv.Key][]byte{"v": []byte(v)})
+}
+
+func TestMultiClientCallViewFailover(t *testing.T) {
+	failing := newFakeNode(t, http.StatusInternalServerError, nil)
+	good := newFakeNode(t, http.StatusOK, result("a"))
+	m := newTestMultiClient(failing, good)
+
+	for i := 0; i < 3; i++ {
+		ret, err := m.CallView(testContract, "get", nil)
+		require.NoError(t, err)
+		require.EqualValues(t, "a", ret.MustGet("v"))
+	}
+	// the failed node is skipped after the first failure
+	require.EqualValues(t, 1, failing.numCalls())
+	require.EqualValues(t, 3, good.numCalls())
+}
+
+func TestMultiClientCallViewAllFailed(t *testing.T) {
+	down := newFakeNode(t, http.StatusOK, result("a"))
+	down.server.Close()
+	failing := newFakeNode(t, http.StatusServiceUnavailable, nil)
+	m := newTestMultiClient(down, failing)
+
+	_, err := m.CallView(testContract, "get", nil)
+	require.Error(t, err)
+	require.EqualValues(t, 1, failing.numCalls())
+}
+
+func TestMultiClientCallViewNoRetry(t *testing.T) {
+	notFound := newFakeNode(t, http.StatusNotFound, nil)
+	good := newFakeNode(t, http.StatusOK, result("a"))
+	m := newTestMultiClient(notFound, good)
+
+	// the error of the call is the answer of the node
+	_, err := m.CallView(testContract, "get", nil)
+	require.Error(t, err)
+	require.False(t, isNodeFailure(err))
+	require.EqualValues(t, 0, good.numCalls())
+}
+
+func TestMultiClientCallViewChecked(t *testing.T) {
+	honest1 := newFakeNode(t, http.StatusOK, result("a"))
+	honest2 := newFakeNode(t, http.StatusOK, result("a"))
+	liar := newFakeNode(t, http.StatusOK, result("b"))
+	m := newTestMultiClient(honest1, liar, honest2)
+	require.EqualValues(t, 2, m.Quorum)
+
+	ret, dissenting, err := m.CallViewChecked(testContract, "get", nil)
+	require.NoError(t, err)
+	require.EqualValues(t, "a", ret.MustGet("v"))
+	require.Equal(t, []string{liar.server.URL}, dissenting)
+
+	m.Quorum = 3
+	_, dissenting, err = m.CallViewChecked(testContract, "get", nil)
+	require.True(t, errors.Is(err, ErrNoQuorum))
+	require.Equal(t, []string{liar.server.URL}, dissenting)
+}
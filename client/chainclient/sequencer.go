@@ -0,0 +1,74 @@
+package chainclient
+
+import (
+	"sync"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+)
+
+// Sequencer posts requests of the client to the chain so that they are processed in the order they are posted.
+// Each request is chained to the previous one by the sctransaction.ArgPredecessor argument, and the committee
+// doesn't process the request until its predecessor is processed. Requests posted with the same key pair
+// through different sequencers or directly by PostRequest are not ordered with respect to each other.
+// The sequencer is safe for concurrent use
+type Sequencer struct {
+	client *Client
+
+	mutex sync.Mutex
+	last  *coretypes.RequestID
+}
+
+// NewSequencer creates a sequencer of requests posted with the key pair of the client
+func (c *Client) NewSequencer() *Sequencer {
+	return &Sequencer{client: c}
+}
+
+// PostRequest posts the request which is processed after all requests posted before by the sequencer
+func (s *Sequencer) PostRequest(
+	contractHname coretypes.Hname,
+	entryPoint coretypes.Hname,
+	params ...PostRequestParams,
+) (*sctransaction.Transaction, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	par := PostRequestParams{}
+	if len(params) > 0 {
+		par = params[0]
+	}
+	if par.Args == nil {
+		par.Args = requestargs.New(nil)
+	} else {
+		par.Args = par.Args.Clone()
+	}
+	if s.last != nil {
+		par.Args.AddEncodeSimple(sctransaction.ArgPredecessor, s.last[:])
+	}
+	tx, err := s.client.PostRequest(contractHname, entryPoint, par)
+	if err != nil {
+		return nil, err
+	}
+	// the request is the only one in the transaction
+	last := coretypes.NewRequestID(tx.ID(), 0)
+	s.last = &last
+	return tx, nil
+}
+
+// Last returns the ID of the last request posted by the sequencer, nil if none
+func (s *Sequencer) Last() *coretypes.RequestID {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.last
+}
+
+// Reset forgets the last request, so the next request doesn't wait for it.
+// It is useful when the last request is known to be lost
+func (s *Sequencer) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.last = nil
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"golang.org/x/net/websocket"
+)
+
+// ChainEvents connects to the websocket endpoint of the chain and streams state transitions, requests
+// and events of contracts of the chain. The returned channel is closed when the connection is lost
+// or when done is closed
+func (c *WaspClient) ChainEvents(chainID *coretypes.ChainID, done <-chan struct{}) (<-chan *model.ChainEvent, error) {
+	base := strings.TrimRight(c.baseURL, "/")
+	wsURL := "ws" + strings.TrimPrefix(base, "http") + routes.ChainEvents(chainID.String())
+	ws, err := websocket.Dial(wsURL, "", base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", wsURL, err)
+	}
+	ret := make(chan *model.ChainEvent)
+	go func() {
+		<-done
+		_ = ws.Close()
+	}()
+	go func() {
+		defer close(ret)
+		defer ws.Close()
+		for {
+			msg := &model.ChainEvent{}
+			if err := websocket.JSON.Receive(ws, msg); err != nil {
+				return
+			}
+			select {
+			case ret <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return ret, nil
+}
+
+// ChainEventsHistory fetches the messages of the blocks of the chain with indices from 'from' to 'to' inclusive,
+// in the same format as streamed by ChainEvents. If 'to' is nil, blocks up to the solid state are fetched.
+// The node returns a limited number of blocks at once, the index of the last returned block is in the result
+func (c *WaspClient) ChainEventsHistory(chainID *coretypes.ChainID, from uint32, to *uint32) (*model.ChainEventsHistory, error) {
+	fromParam := int64(from)
+	var toParam *int64
+	if to != nil {
+		t := int64(*to)
+		toParam = &t
+	}
+	return c.API().GetChainEventsHistory(chainID.String(), &fromParam, toParam)
+}
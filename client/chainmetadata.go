@@ -0,0 +1,15 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+// GetChainMetadata fetches the description, the website and the custom metadata of the chain
+func (c *WaspClient) GetChainMetadata(chainID *coretypes.ChainID) (*root.ChainMetadata, error) {
+	res, err := c.API().GetChainMetadata(chainID.String())
+	if err != nil {
+		return nil, err
+	}
+	return res.ChainMetadata(), nil
+}
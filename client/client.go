@@ -12,6 +12,10 @@ import (
 	"github.com/iotaledger/wasp/packages/webapi/model"
 )
 
+// The typed client in api_gen.go is generated from the OpenAPI specification served by a running node.
+// Regenerate it whenever endpoints of the web API change
+//go:generate go run ../tools/api-gen -o api_gen.go
+
 // WaspClient allows to make requests to the Wasp web API.
 type WaspClient struct {
 	httpClient http.Client
@@ -36,7 +40,7 @@ func processResponse(res *http.Response, decodeTo interface{}) error {
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusCreated {
+	if res.StatusCode == http.StatusOK || res.StatusCode == http.StatusCreated || res.StatusCode == http.StatusAccepted {
 		if decodeTo != nil {
 			return json.Unmarshal(resBody, decodeTo)
 		} else {
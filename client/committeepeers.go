@@ -0,0 +1,11 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// GetCommitteePeers fetches the connection status and liveness of committee peers of the chain, as seen by the node
+func (c *WaspClient) GetCommitteePeers(chainID *coretypes.ChainID) ([]*model.CommitteePeer, error) {
+	return c.API().GetCommitteePeers(chainID.String())
+}
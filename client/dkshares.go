@@ -7,24 +7,22 @@ package client
 // The Golang API in this file tries to follow the REST conventions.
 
 import (
-	"net/http"
-
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/webapi/model"
-	"github.com/iotaledger/wasp/packages/webapi/routes"
 )
 
 // DKSharesPost creates a new DKShare and returns its state.
 func (c *WaspClient) DKSharesPost(request *model.DKSharesPostRequest) (*model.DKSharesInfo, error) {
-	var response model.DKSharesInfo
-	err := c.do(http.MethodPost, routes.DKSharesPost(), request, &response)
-	return &response, err
+	return c.API().PostDKShares(request)
+}
+
+// DKSharesReshare distributes new shares of an existing DKShare to a new group of nodes.
+// The address and the shared public key stay the same.
+func (c *WaspClient) DKSharesReshare(sharedAddress *address.Address, request *model.DKSharesReshareRequest) (*model.DKSharesInfo, error) {
+	return c.API().ReshareDKShares(sharedAddress.String(), request)
 }
 
 // DKSharesGet retrieves the representation of an existing DKShare.
 func (c *WaspClient) DKSharesGet(sharedAddress *address.Address) (*model.DKSharesInfo, error) {
-	var sharedAddressStr = sharedAddress.String()
-	var response model.DKSharesInfo
-	err := c.do(http.MethodGet, routes.DKSharesGet(sharedAddressStr), nil, &response)
-	return &response, err
+	return c.API().GetDKShares(sharedAddress.String())
 }
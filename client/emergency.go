@@ -0,0 +1,20 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// DeclareEmergency votes in the wasp node to delegate production of the next numBlocks blocks of the chain
+// to the committee node with the index delegate
+func (c *WaspClient) DeclareEmergency(chainID *coretypes.ChainID, delegate uint16, numBlocks uint32) error {
+	return c.API().DeclareEmergency(chainID.String(), &model.DeclareEmergencyRequest{
+		Delegate:  delegate,
+		NumBlocks: numBlocks,
+	})
+}
+
+// GetEmergencyRecords fetches the records of the emergency modes activated by the committee of the chain
+func (c *WaspClient) GetEmergencyRecords(chainID *coretypes.ChainID) ([]*model.EmergencyRecord, error) {
+	return c.API().GetEmergencyRecords(chainID.String())
+}
@@ -0,0 +1,455 @@
+// Package events subscribes to the events of a chain streamed by the websocket endpoint of a Wasp node.
+// The subscription reconnects when the connection is lost and, after reconnecting, replays the blocks
+// missed in the meantime from the public history endpoint of chain events. Events are deduplicated
+// and delivered on typed channels.
+// Events of contracts are taken from the event log of committed blocks: they are delivered with the
+// block, before its state transition, and never for requests which were not committed
+package events
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+const (
+	DefaultReconnectDelay = 2 * time.Second
+	DefaultBufferSize     = 100
+)
+
+// ErrConnectionLost is sent to the Errors channel each time the websocket connection is lost
+var ErrConnectionLost = errors.New("connection to the node lost")
+
+// StateTransition is the new state of the chain
+type StateTransition struct {
+	BlockIndex uint32
+	BlockSize  uint16
+	AnchorTxID valuetransaction.ID
+	StateHash  string // empty for replayed state transitions
+	Timestamp  int64
+	Replayed   bool
+}
+
+// RequestReceived is the request which arrived to the backlog of the node
+type RequestReceived struct {
+	RequestID coretypes.RequestID
+}
+
+// RequestProcessed is the request processed in the block
+type RequestProcessed struct {
+	RequestID    coretypes.RequestID
+	BlockIndex   uint32
+	IndexInBlock uint16
+	BlockSize    uint16
+	Replayed     bool
+}
+
+// ContractEvent is the message ('vmmsg') or the typed event ('vmevent') published by a contract
+// in the block. For messages Topic and Fields are empty
+type ContractEvent struct {
+	Contract   coretypes.Hname
+	BlockIndex uint32
+	Message    string
+	Topic      string
+	Fields     map[string][]byte
+	Replayed   bool
+}
+
+// Options of the subscription
+type Options struct {
+	// FromStateIndex, if not nil, is the index of the first block events of which are replayed upon subscription.
+	// Records pruned from the event log of the chain are not replayed
+	FromStateIndex *uint32
+	// ReconnectDelay is the delay between connection attempts. Default is DefaultReconnectDelay
+	ReconnectDelay time.Duration
+	// BufferSize is the capacity of each channel. Default is DefaultBufferSize
+	BufferSize int
+}
+
+// Subscription delivers events of the chain until closed. All channels except Errors must be read:
+// delivery blocks while the channel of the next event is full. Errors are dropped if not read
+type Subscription struct {
+	StateTransitions  <-chan *StateTransition
+	RequestsReceived  <-chan *RequestReceived
+	RequestsProcessed <-chan *RequestProcessed
+	ContractEvents    <-chan *ContractEvent
+	Errors            <-chan error
+
+	client  *client.WaspClient
+	chainID coretypes.ChainID
+	opts    Options
+
+	stateTransitions  chan *StateTransition
+	requestsReceived  chan *RequestReceived
+	requestsProcessed chan *RequestProcessed
+	contractEvents    chan *ContractEvent
+	errors            chan error
+
+	// index of the last delivered state transition, valid if hasState
+	lastStateIndex uint32
+	hasState       bool
+	// requests delivered as processed in the block after lastStateIndex, state transition of which is not delivered yet
+	processedAhead map[coretypes.RequestID]bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subscribe starts the subscription to the events of the chain. Connection errors are reported
+// to the Errors channel and the connection is retried until the subscription is closed
+func Subscribe(c *client.WaspClient, chainID *coretypes.ChainID, opts *Options) *Subscription {
+	s := &Subscription{
+		client:         c,
+		chainID:        *chainID,
+		processedAhead: make(map[coretypes.RequestID]bool),
+		done:           make(chan struct{}),
+	}
+	if opts != nil {
+		s.opts = *opts
+	}
+	if s.opts.ReconnectDelay == 0 {
+		s.opts.ReconnectDelay = DefaultReconnectDelay
+	}
+	if s.opts.BufferSize == 0 {
+		s.opts.BufferSize = DefaultBufferSize
+	}
+	s.stateTransitions = make(chan *StateTransition, s.opts.BufferSize)
+	s.requestsReceived = make(chan *RequestReceived, s.opts.BufferSize)
+	s.requestsProcessed = make(chan *RequestProcessed, s.opts.BufferSize)
+	s.contractEvents = make(chan *ContractEvent, s.opts.BufferSize)
+	s.errors = make(chan error, s.opts.BufferSize)
+	s.StateTransitions = s.stateTransitions
+	s.RequestsReceived = s.requestsReceived
+	s.RequestsProcessed = s.requestsProcessed
+	s.ContractEvents = s.contractEvents
+	s.Errors = s.errors
+
+	go s.run()
+	return s
+}
+
+// Close stops the subscription. The channels are closed
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *Subscription) run() {
+	defer func() {
+		close(s.stateTransitions)
+		close(s.requestsReceived)
+		close(s.requestsProcessed)
+		close(s.contractEvents)
+		close(s.errors)
+	}()
+	for {
+		msgs, err := s.client.ChainEvents(&s.chainID, s.done)
+		if err != nil {
+			s.reportError(err)
+		} else {
+			// the connection is established before the replay, so that events published during
+			// the replay are not missed. Duplicates are skipped
+			if err := s.replay(); err != nil {
+				s.reportError(err)
+			}
+			for msg := range msgs {
+				if !s.handle(msg) {
+					return
+				}
+			}
+		}
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		if err == nil {
+			s.reportError(ErrConnectionLost)
+		}
+		select {
+		case <-s.done:
+			return
+		case <-time.After(s.opts.ReconnectDelay):
+		}
+	}
+}
+
+func (s *Subscription) reportError(err error) {
+	select {
+	case s.errors <- err:
+	default:
+	}
+}
+
+// replay delivers the blocks after the last delivered state transition, up to the solid state of the chain
+func (s *Subscription) replay() error {
+	var from uint32
+	switch {
+	case s.hasState:
+		from = s.lastStateIndex + 1
+	case s.opts.FromStateIndex != nil:
+		from = *s.opts.FromStateIndex
+	default:
+		return nil
+	}
+	return s.catchUp(from, nil)
+}
+
+// catchUp delivers blocks from the history of chain events, starting from the block 'from' up to the block
+// of the live state transition or, if it is nil, up to the solid state of the chain.
+// The live state transition is delivered instead of the reconstructed one, which has no state hash
+func (s *Subscription) catchUp(from uint32, live *StateTransition) error {
+	for {
+		var to *uint32
+		if live != nil {
+			if from > live.BlockIndex {
+				return nil
+			}
+			to = &live.BlockIndex
+		}
+		history, err := s.client.ChainEventsHistory(&s.chainID, from, to)
+		if err != nil {
+			var httpErr *model.HTTPError
+			if live == nil && errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusBadRequest {
+				// no blocks after 'from' yet
+				return nil
+			}
+			return fmt.Errorf("failed to fetch chain events of blocks from #%d: %v", from, err)
+		}
+		if len(history.Blocks) == 0 {
+			return nil
+		}
+		for _, b := range history.Blocks {
+			if !s.deliverBlock(b, live) {
+				return nil
+			}
+		}
+		from = history.To + 1
+	}
+}
+
+// deliverBlock delivers events of the block from the history. Events of blocks other than the block of
+// the live state transition are marked as replayed. Returns false if the subscription is closed
+func (s *Subscription) deliverBlock(b *model.BlockEvents, live *StateTransition) bool {
+	replayed := live == nil || live.BlockIndex != b.BlockIndex
+	for _, msg := range b.Events {
+		ok := true
+		switch msg.Type {
+		case "request_out":
+			if rp := parseRequestProcessed(msg.Parts); rp != nil {
+				rp.Replayed = replayed
+				ok = s.deliverRequestProcessed(rp)
+			}
+		case "vmmsg", "vmevent":
+			if ev := parseContractEvent(msg); ev != nil {
+				ev.BlockIndex = b.BlockIndex
+				ev.Replayed = replayed
+				ok = s.deliverContractEvent(ev)
+			}
+		case "state":
+			st := live
+			if replayed {
+				if st = parseStateTransition(msg.Parts); st != nil {
+					st.Replayed = true
+				}
+			}
+			if st != nil {
+				ok = s.deliverStateTransition(st)
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// handle parses the live message and delivers the event. Malformed and unknown messages are skipped.
+// Events of contracts are skipped too: they are delivered from the history with the state transition.
+// Returns false if the subscription is closed
+func (s *Subscription) handle(msg *model.ChainEvent) bool {
+	switch msg.Type {
+	case "state":
+		st := parseStateTransition(msg.Parts)
+		if st == nil || (s.hasState && st.BlockIndex <= s.lastStateIndex) {
+			return true
+		}
+		// blocks missed since the last state transition and the block itself are taken from the history
+		from := st.BlockIndex
+		if s.hasState {
+			from = s.lastStateIndex + 1
+		}
+		if err := s.catchUp(from, st); err != nil {
+			// the state transition is not delivered, the next one or the reconnection retries
+			s.reportError(err)
+		}
+		return !s.isClosed()
+	case "request_in":
+		// tx ID, request index
+		if len(msg.Parts) < 2 {
+			return true
+		}
+		reqid, ok := parseRequestID(msg.Parts[0], msg.Parts[1])
+		if !ok {
+			return true
+		}
+		select {
+		case s.requestsReceived <- &RequestReceived{RequestID: reqid}:
+			return true
+		case <-s.done:
+			return false
+		}
+	case "request_out":
+		if rp := parseRequestProcessed(msg.Parts); rp != nil {
+			return s.deliverRequestProcessed(rp)
+		}
+	}
+	return true
+}
+
+func (s *Subscription) isClosed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// deliverStateTransition delivers state transitions in the order of block indices, skipping duplicates
+func (s *Subscription) deliverStateTransition(st *StateTransition) bool {
+	if s.hasState && st.BlockIndex <= s.lastStateIndex {
+		return true
+	}
+	select {
+	case s.stateTransitions <- st:
+	case <-s.done:
+		return false
+	}
+	s.lastStateIndex = st.BlockIndex
+	s.hasState = true
+	// requests of the block are published before the state transition
+	s.processedAhead = make(map[coretypes.RequestID]bool)
+	return true
+}
+
+// deliverRequestProcessed skips requests of the blocks which were already delivered
+func (s *Subscription) deliverRequestProcessed(rp *RequestProcessed) bool {
+	if s.hasState && rp.BlockIndex <= s.lastStateIndex {
+		return true
+	}
+	if s.processedAhead[rp.RequestID] {
+		return true
+	}
+	select {
+	case s.requestsProcessed <- rp:
+	case <-s.done:
+		return false
+	}
+	s.processedAhead[rp.RequestID] = true
+	return true
+}
+
+// deliverContractEvent skips events of the blocks which were already delivered
+func (s *Subscription) deliverContractEvent(ev *ContractEvent) bool {
+	if s.hasState && ev.BlockIndex <= s.lastStateIndex {
+		return true
+	}
+	select {
+	case s.contractEvents <- ev:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// parseStateTransition parses parts of the 'state' message: block index, block size, state tx ID, state hash, timestamp
+func parseStateTransition(p []string) *StateTransition {
+	if len(p) < 5 {
+		return nil
+	}
+	blockIndex, err1 := strconv.ParseUint(p[0], 10, 32)
+	blockSize, err2 := strconv.ParseUint(p[1], 10, 16)
+	txid, err3 := valuetransaction.IDFromBase58(p[2])
+	ts, err4 := strconv.ParseInt(p[4], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return nil
+	}
+	return &StateTransition{
+		BlockIndex: uint32(blockIndex),
+		BlockSize:  uint16(blockSize),
+		AnchorTxID: txid,
+		StateHash:  p[3],
+		Timestamp:  ts,
+	}
+}
+
+// parseRequestProcessed parses parts of the 'request_out' message: tx ID, request index, block index, index in block, block size
+func parseRequestProcessed(p []string) *RequestProcessed {
+	if len(p) < 5 {
+		return nil
+	}
+	reqid, ok := parseRequestID(p[0], p[1])
+	blockIndex, err1 := strconv.ParseUint(p[2], 10, 32)
+	indexInBlock, err2 := strconv.ParseUint(p[3], 10, 16)
+	blockSize, err3 := strconv.ParseUint(p[4], 10, 16)
+	if !ok || err1 != nil || err2 != nil || err3 != nil {
+		return nil
+	}
+	return &RequestProcessed{
+		RequestID:    reqid,
+		BlockIndex:   uint32(blockIndex),
+		IndexInBlock: uint16(indexInBlock),
+		BlockSize:    uint16(blockSize),
+	}
+}
+
+// parseContractEvent parses the 'vmmsg' message (contract hname, message) or the 'vmevent' message
+// (contract hname, topic, hex encoded fields key=value)
+func parseContractEvent(msg *model.ChainEvent) *ContractEvent {
+	p := msg.Parts
+	if len(p) < 2 {
+		return nil
+	}
+	hname, err := coretypes.HnameFromString(p[0])
+	if err != nil {
+		return nil
+	}
+	if msg.Type == "vmmsg" {
+		return &ContractEvent{Contract: hname, Message: strings.Join(p[1:], " ")}
+	}
+	ev := &ContractEvent{Contract: hname, Topic: p[1], Fields: make(map[string][]byte)}
+	for _, f := range p[2:] {
+		kv := strings.SplitN(f, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if value, err := hex.DecodeString(kv[1]); err == nil {
+			ev.Fields[kv[0]] = value
+		}
+	}
+	return ev
+}
+
+func parseRequestID(txidStr, indexStr string) (coretypes.RequestID, bool) {
+	txid, err := valuetransaction.IDFromBase58(txidStr)
+	if err != nil {
+		return coretypes.RequestID{}, false
+	}
+	index, err := strconv.ParseUint(indexStr, 10, 16)
+	if err != nil {
+		return coretypes.RequestID{}, false
+	}
+	return coretypes.NewRequestID(txid, uint16(index)), true
+}
@@ -0,0 +1,343 @@
+package events
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// number of blocks returned by one call of the fake history endpoint
+	historyPage = 2
+	timeout     = 5 * time.Second
+)
+
+var testContract = coretypes.Hn("test")
+
+// fakeNode serves the websocket endpoint of chain events and the history endpoint of one chain.
+// Each block of the history has one processed request, a message and a typed event of the contract
+type fakeNode struct {
+	t         *testing.T
+	chainID   coretypes.ChainID
+	server    *httptest.Server
+	mu        sync.Mutex
+	blocks    []*model.BlockEvents
+	live      chan *model.ChainEvent
+	drop      chan struct{}
+	connected chan struct{}
+	stop      chan struct{}
+}
+
+func newFakeNode(t *testing.T) *fakeNode {
+	n := &fakeNode{
+		t:         t,
+		chainID:   coretypes.ChainID{1, 2, 3},
+		live:      make(chan *model.ChainEvent),
+		drop:      make(chan struct{}),
+		connected: make(chan struct{}, 10),
+		stop:      make(chan struct{}),
+	}
+	// the origin block
+	n.blocks = []*model.BlockEvents{{BlockIndex: 0, Events: []*model.ChainEvent{stateEvent(0, "")}}}
+
+	mux := http.NewServeMux()
+	mux.Handle(routes.ChainEvents(n.chainID.String()), websocket.Handler(n.handleEvents))
+	mux.HandleFunc(routes.ChainEventsHistory(n.chainID.String()), n.handleHistory)
+	n.server = httptest.NewServer(mux)
+	t.Cleanup(func() {
+		close(n.stop)
+		n.server.Close()
+	})
+	return n
+}
+
+func (n *fakeNode) handleEvents(ws *websocket.Conn) {
+	n.connected <- struct{}{}
+	for {
+		select {
+		case msg := <-n.live:
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				return
+			}
+		case <-n.drop:
+			return
+		case <-n.stop:
+			return
+		}
+	}
+}
+
+func (n *fakeNode) handleHistory(w http.ResponseWriter, r *http.Request) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	solid := uint32(len(n.blocks) - 1)
+	from, err1 := strconv.ParseUint(r.URL.Query().Get("from"), 10, 32)
+	to := uint64(solid)
+	var err2 error
+	if s := r.URL.Query().Get("to"); s != "" {
+		to, err2 = strconv.ParseUint(s, 10, 32)
+	}
+	if err1 != nil || err2 != nil || from > to || to > uint64(solid) {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(&model.HTTPError{StatusCode: http.StatusBadRequest, Message: "wrong block range"})
+		return
+	}
+	if to-from >= historyPage {
+		to = from + historyPage - 1
+	}
+	_ = json.NewEncoder(w).Encode(&model.ChainEventsHistory{
+		From:   uint32(from),
+		To:     uint32(to),
+		Blocks: n.blocks[from : to+1],
+	})
+}
+
+func (n *fakeNode) waitConnected() {
+	select {
+	case <-n.connected:
+	case <-time.After(timeout):
+		n.t.Fatal("the subscription didn't connect")
+	}
+}
+
+// publish sends the live message to the connected subscription
+func (n *fakeNode) publish(msg *model.ChainEvent) {
+	select {
+	case n.live <- msg:
+	case <-time.After(timeout):
+		n.t.Fatal("the subscription doesn't read messages")
+	}
+}
+
+// addBlock commits the next block to the history of the node
+func (n *fakeNode) addBlock() *model.BlockEvents {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	blockIndex := uint32(len(n.blocks))
+	b := &model.BlockEvents{
+		BlockIndex: blockIndex,
+		Events: []*model.ChainEvent{
+			requestOutEvent(blockIndex),
+			{Type: "vmmsg", Parts: []string{testContract.String(), fmt.Sprintf("block %d", blockIndex)}},
+			{Type: "vmevent", Parts: []string{testContract.String(), "topic", "n=" + hex.EncodeToString([]byte{byte(blockIndex)})}},
+			stateEvent(blockIndex, ""),
+		},
+	}
+	n.blocks = append(n.blocks, b)
+	return b
+}
+
+func (n *fakeNode) subscribe(opts *Options) *Subscription {
+	if opts == nil {
+		opts = &Options{}
+	}
+	opts.ReconnectDelay = 10 * time.Millisecond
+	s := Subscribe(client.NewWaspClient(n.server.URL), &n.chainID, opts)
+	n.t.Cleanup(s.Close)
+	return s
+}
+
+func testRequestID(blockIndex uint32) coretypes.RequestID {
+	return coretypes.NewRequestID(valuetransaction.ID(hashing.HashStrings(fmt.Sprintf("request %d", blockIndex))), 0)
+}
+
+func requestOutEvent(blockIndex uint32) *model.ChainEvent {
+	reqid := testRequestID(blockIndex)
+	bi := strconv.Itoa(int(blockIndex))
+	return &model.ChainEvent{Type: "request_out", Parts: []string{reqid.TransactionID().String(), "0", bi, "0", "1"}}
+}
+
+func stateEvent(blockIndex uint32, stateHash string) *model.ChainEvent {
+	var txid valuetransaction.ID
+	bi := strconv.Itoa(int(blockIndex))
+	return &model.ChainEvent{Type: "state", Parts: []string{bi, "1", txid.String(), stateHash, bi + "000"}}
+}
+
+func nextState(t *testing.T, s *Subscription) *StateTransition {
+	select {
+	case st := <-s.StateTransitions:
+		return st
+	case <-time.After(timeout):
+		t.Fatal("state transition not delivered")
+		return nil
+	}
+}
+
+func nextProcessed(t *testing.T, s *Subscription) *RequestProcessed {
+	select {
+	case rp := <-s.RequestsProcessed:
+		return rp
+	case <-time.After(timeout):
+		t.Fatal("processed request not delivered")
+		return nil
+	}
+}
+
+func nextEvent(t *testing.T, s *Subscription) *ContractEvent {
+	select {
+	case ev := <-s.ContractEvents:
+		return ev
+	case <-time.After(timeout):
+		t.Fatal("contract event not delivered")
+		return nil
+	}
+}
+
+// requireBlock checks all events of the block delivered by the subscription
+func requireBlock(t *testing.T, s *Subscription, blockIndex uint32, replayed bool, stateHash string) {
+	rp := nextProcessed(t, s)
+	require.EqualValues(t, testRequestID(blockIndex), rp.RequestID)
+	require.EqualValues(t, blockIndex, rp.BlockIndex)
+
+	msg := nextEvent(t, s)
+	require.EqualValues(t, testContract, msg.Contract)
+	require.EqualValues(t, blockIndex, msg.BlockIndex)
+	require.Equal(t, fmt.Sprintf("block %d", blockIndex), msg.Message)
+	require.Equal(t, replayed, msg.Replayed)
+
+	ev := nextEvent(t, s)
+	require.EqualValues(t, blockIndex, ev.BlockIndex)
+	require.Equal(t, "topic", ev.Topic)
+	require.Equal(t, map[string][]byte{"n": {byte(blockIndex)}}, ev.Fields)
+
+	st := nextState(t, s)
+	require.EqualValues(t, blockIndex, st.BlockIndex)
+	require.Equal(t, replayed, st.Replayed)
+	require.Equal(t, stateHash, st.StateHash)
+}
+
+// requireNothingMore checks that no more events are delivered
+func requireNothingMore(t *testing.T, s *Subscription) {
+	select {
+	case st := <-s.StateTransitions:
+		t.Fatalf("unexpected state transition #%d", st.BlockIndex)
+	case rp := <-s.RequestsProcessed:
+		t.Fatalf("unexpected processed request in block #%d", rp.BlockIndex)
+	case ev := <-s.ContractEvents:
+		t.Fatalf("unexpected contract event in block #%d", ev.BlockIndex)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestLiveBlock(t *testing.T) {
+	n := newFakeNode(t)
+	s := n.subscribe(nil)
+	n.waitConnected()
+
+	b := n.addBlock()
+	reqid := testRequestID(1)
+	n.publish(&model.ChainEvent{Type: "request_in", Parts: []string{reqid.TransactionID().String(), "0"}})
+	n.publish(b.Events[0])
+	// live events of contracts are skipped, they are taken from the history with the state transition
+	n.publish(b.Events[1])
+	n.publish(stateEvent(1, "hash1"))
+
+	select {
+	case rr := <-s.RequestsReceived:
+		require.EqualValues(t, reqid, rr.RequestID)
+	case <-time.After(timeout):
+		t.Fatal("received request not delivered")
+	}
+	// the processed request is delivered once, although it is also in the history
+	requireBlock(t, s, 1, false, "hash1")
+	requireNothingMore(t, s)
+}
+
+func TestReplayFromStateIndex(t *testing.T) {
+	n := newFakeNode(t)
+	for i := 0; i < 5; i++ {
+		n.addBlock()
+	}
+	from := uint32(2)
+	s := n.subscribe(&Options{FromStateIndex: &from})
+
+	// blocks are fetched in several pages
+	for i := uint32(2); i <= 5; i++ {
+		requireBlock(t, s, i, true, "")
+	}
+	requireNothingMore(t, s)
+}
+
+func TestMissedLiveBlocks(t *testing.T) {
+	n := newFakeNode(t)
+	s := n.subscribe(nil)
+	n.waitConnected()
+
+	for i := 0; i < 4; i++ {
+		n.addBlock()
+	}
+	n.publish(stateEvent(1, "hash1"))
+	requireBlock(t, s, 1, false, "hash1")
+
+	// messages of blocks #2 and #3 were dropped by the node
+	n.publish(stateEvent(4, "hash4"))
+	requireBlock(t, s, 2, true, "")
+	requireBlock(t, s, 3, true, "")
+	requireBlock(t, s, 4, false, "hash4")
+	requireNothingMore(t, s)
+}
+
+func TestReconnect(t *testing.T) {
+	n := newFakeNode(t)
+	s := n.subscribe(nil)
+	n.waitConnected()
+
+	n.addBlock()
+	n.publish(stateEvent(1, "hash1"))
+	requireBlock(t, s, 1, false, "hash1")
+
+	// blocks committed while the subscription is disconnected are replayed
+	n.addBlock()
+	n.addBlock()
+	n.drop <- struct{}{}
+	select {
+	case err := <-s.Errors:
+		require.Equal(t, ErrConnectionLost, err)
+	case <-time.After(timeout):
+		t.Fatal("connection loss not reported")
+	}
+	n.waitConnected()
+	requireBlock(t, s, 2, true, "")
+	requireBlock(t, s, 3, true, "")
+
+	// the late live message of the replayed block is a duplicate
+	n.publish(stateEvent(3, "hash3"))
+	requireNothingMore(t, s)
+
+	b := n.addBlock()
+	n.publish(b.Events[0])
+	n.publish(stateEvent(4, "hash4"))
+	requireBlock(t, s, 4, false, "hash4")
+	requireNothingMore(t, s)
+}
+
+func TestCloseClosesChannels(t *testing.T) {
+	n := newFakeNode(t)
+	s := n.subscribe(nil)
+	n.waitConnected()
+	s.Close()
+
+	select {
+	case _, ok := <-s.StateTransitions:
+		require.False(t, ok)
+	case <-time.After(timeout):
+		t.Fatal("channels are not closed")
+	}
+}
@@ -0,0 +1,11 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// GetEquivocationEvidence fetches the evidence of equivocating leaders recorded by the node for the chain
+func (c *WaspClient) GetEquivocationEvidence(chainID *coretypes.ChainID) ([]*model.EquivocationEvidence, error) {
+	return c.API().GetEquivocationEvidence(chainID.String())
+}
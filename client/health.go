@@ -0,0 +1,16 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// Health fetches the health of the node and its active chains
+func (c *WaspClient) Health() (*model.NodeHealth, error) {
+	return c.API().GetHealth()
+}
+
+// Readiness fetches the health of the node and its active chains.
+// Returns error if the node or any of the chains is not ready
+func (c *WaspClient) Readiness() (*model.NodeHealth, error) {
+	return c.API().GetReadiness()
+}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// RotateIdentity replaces the identity key of the node and returns the handover to the new key.
+// If revoke is set, peers won't accept the previous key anymore for any node
+func (c *WaspClient) RotateIdentity(revoke bool) (*model.IdentityHandover, error) {
+	return c.API().RotateIdentity(&model.RotateIdentityRequest{Revoke: revoke})
+}
+
+// GetIdentityHandovers fetches handovers of identity keys known to the node, ordered by time
+func (c *WaspClient) GetIdentityHandovers() ([]*model.IdentityHandover, error) {
+	return c.API().GetIdentityHandovers()
+}
@@ -18,3 +18,18 @@ func (m *MultiClient) DeactivateChain(chainid coretypes.ChainID) error {
 		return w.DeactivateChain(chainid)
 	})
 }
+
+// DrainChain sends a request to deactivate a chain gracefully in all wasp nodes
+func (m *MultiClient) DrainChain(chainid coretypes.ChainID, wait bool) error {
+	return m.Do(func(i int, w *client.WaspClient) error {
+		return w.DrainChain(chainid, wait)
+	})
+}
+
+// DeclareEmergency votes in all wasp nodes to delegate production of the next numBlocks blocks
+// of the chain to the committee node with the index delegate
+func (m *MultiClient) DeclareEmergency(chainid coretypes.ChainID, delegate uint16, numBlocks uint32) error {
+	return m.Do(func(i int, w *client.WaspClient) error {
+		return w.DeclareEmergency(&chainid, delegate, numBlocks)
+	})
+}
@@ -30,3 +30,15 @@ func (m *MultiClient) WaitUntilAllRequestsProcessed(tx *sctransaction.Transactio
 		return w.WaitUntilAllRequestsProcessed(tx, timeout)
 	})
 }
+
+// WaitUntilChainRequestsProcessed blocks until all requests in the given transaction which target
+// the given chain have been processed by all nodes
+func (m *MultiClient) WaitUntilChainRequestsProcessed(chainId *coretypes.ChainID, tx *sctransaction.Transaction, timeout time.Duration) error {
+	oldTimeout := m.Timeout
+	defer func() { m.Timeout = oldTimeout }()
+
+	m.Timeout = timeout + 10*time.Second
+	return m.Do(func(i int, w *client.WaspClient) error {
+		return w.WaitUntilChainRequestsProcessed(chainId, tx, timeout)
+	})
+}
@@ -0,0 +1,10 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// GetNodeResources fetches the number of goroutines and the memory usage of the node
+func (c *WaspClient) GetNodeResources() (*model.NodeResources, error) {
+	return c.API().GetNodeResources()
+}
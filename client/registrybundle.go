@@ -0,0 +1,21 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// ExportRegistry fetches the signed registry bundle of the node. DK shares in the bundle are
+// encrypted to recipientPubKey, the base64-encoded public key of the importing node
+func (c *WaspClient) ExportRegistry(recipientPubKey string) ([]byte, error) {
+	res, err := c.API().ExportRegistry(&model.RegistryExportRequest{RecipientPubKey: recipientPubKey})
+	if err != nil {
+		return nil, err
+	}
+	return res.Data.Bytes(), nil
+}
+
+// ImportRegistry sends a signed registry bundle to be imported by the node. The bundle must be
+// signed by the node with the base64-encoded public key exporterPubKey
+func (c *WaspClient) ImportRegistry(data []byte, exporterPubKey string) error {
+	return c.API().ImportRegistry(&model.RegistryImportRequest{Data: model.NewBytes(data), ExporterPubKey: exporterPubKey})
+}
@@ -1,22 +1,16 @@
 package client
 
 import (
-	"net/http"
 	"time"
 
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/webapi/model"
-	"github.com/iotaledger/wasp/packages/webapi/routes"
 )
 
 // RequestStatus fetches the processing status of a request.
 func (c *WaspClient) RequestStatus(chainId *coretypes.ChainID, reqId *coretypes.RequestID) (*model.RequestStatusResponse, error) {
-	res := &model.RequestStatusResponse{}
-	if err := c.do(http.MethodGet, routes.RequestStatus(chainId.String(), reqId.Base58()), nil, res); err != nil {
-		return nil, err
-	}
-	return res, nil
+	return c.API().GetRequestStatus(chainId.String(), reqId.Base58())
 }
 
 // WaitUntilRequestProcessed blocks until the request has been processed by the node
@@ -24,15 +18,7 @@ func (c *WaspClient) WaitUntilRequestProcessed(chainId *coretypes.ChainID, reqId
 	if timeout == 0 {
 		timeout = model.WaitRequestProcessedDefaultTimeout
 	}
-	if err := c.do(
-		http.MethodGet,
-		routes.WaitRequestProcessed(chainId.String(), reqId.Base58()),
-		&model.WaitRequestProcessedParams{Timeout: timeout},
-		nil,
-	); err != nil {
-		return err
-	}
-	return nil
+	return c.API().WaitRequestProcessed(chainId.String(), reqId.Base58(), &model.WaitRequestProcessedParams{Timeout: timeout})
 }
 
 // WaitUntilAllRequestsProcessed blocks until all requests in the given transaction have been processed
@@ -47,3 +33,14 @@ func (c *WaspClient) WaitUntilAllRequestsProcessed(tx *sctransaction.Transaction
 	}
 	return nil
 }
+
+// WaitUntilChainRequestsProcessed blocks until all requests in the given transaction which target
+// the given chain have been processed by the node. Requests to other chains are ignored
+func (c *WaspClient) WaitUntilChainRequestsProcessed(chainId *coretypes.ChainID, tx *sctransaction.Transaction, timeout time.Duration) error {
+	for _, ref := range tx.RequestsByChain()[*chainId] {
+		if err := c.WaitUntilRequestProcessed(chainId, ref.RequestID(), timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package client
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+)
+
+// SimulateRequest runs the request of the transaction against the current state of the chain
+// without committing it. The transaction doesn't need to be signed
+func (c *WaspClient) SimulateRequest(chainId *coretypes.ChainID, tx *sctransaction.Transaction, index uint16) (*model.SimulationResult, error) {
+	return c.API().SimulateRequest(chainId.String(), &model.SimulateRequest{
+		Transaction: model.NewBytes(tx.Bytes()),
+		Index:       index,
+	})
+}
@@ -29,6 +29,7 @@ func init() {
 		coreutil.Func(FuncIncAndRepeatOnceAfter5s, incCounterAndRepeatOnce),
 		coreutil.Func(FuncIncAndRepeatMany, incCounterAndRepeatMany),
 		coreutil.Func(FuncSpawn, spawn),
+		coreutil.Func(FuncIncOnChain, incCounterOnChain),
 		coreutil.ViewFunc(FuncGetCounter, getCounter),
 	})
 }
@@ -38,6 +39,7 @@ const (
 	FuncIncAndRepeatOnceAfter5s = "incAndRepeatOnceAfter5s"
 	FuncIncAndRepeatMany        = "incAndRepeatMany"
 	FuncSpawn                   = "spawn"
+	FuncIncOnChain              = "incOnChain"
 	FuncGetCounter              = "getCounter"
 )
 
@@ -46,6 +48,7 @@ const (
 	VarCounter     = "counter"
 	VarName        = "name"
 	VarDescription = "dscr"
+	VarChainID     = "chainID"
 )
 
 func init() {
@@ -137,6 +140,26 @@ func incCounterAndRepeatMany(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return nil, nil
 }
 
+// incCounterOnChain posts the request to increment the counter of the contract with the same name on another chain
+func incCounterOnChain(ctx coretypes.Sandbox) (dict.Dict, error) {
+	ctx.Log().Debugf("inccounter.incCounterOnChain")
+	params := ctx.Params()
+	chainID, ok, err := codec.DecodeChainID(params.MustGet(VarChainID))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("incCounterOnChain: target chain ID is missing")
+	}
+	if !ctx.PostRequest(coretypes.PostRequestParams{
+		TargetContractID: coretypes.NewContractID(chainID, ctx.ContractID().Hname()),
+		EntryPoint:       coretypes.Hn(FuncIncCounter),
+	}) {
+		return nil, fmt.Errorf("incCounterOnChain: not enough funds")
+	}
+	return nil, nil
+}
+
 // spawn deploys new contract and calls it
 func spawn(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Debugf("inccounter.spawn")
@@ -8,6 +8,7 @@ import (
 	"github.com/iotaledger/wasp/contracts/common"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/wasmproc"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -132,6 +133,37 @@ func TestIncrementLocalStatePost(t *testing.T) {
 	checkStateCounter(t, chain, nil)
 }
 
+func TestBreakpointStateSet(t *testing.T) {
+	chain := setupTest(t)
+
+	var hits []int64
+	remove := chain.AddWasmBreakpoint(wasmproc.HostStateSet, ScName, VarCounter, func(ctx *wasmproc.DebugContext) {
+		require.EqualValues(t, FuncIncrement, ctx.Function)
+		require.NotNil(t, ctx.Sandbox)
+		// the state is not updated yet
+		prev, _, err := codec.DecodeInt64(ctx.Sandbox.State().MustGet(ctx.Key))
+		require.NoError(t, err)
+		value, _, err := codec.DecodeInt64(ctx.Value)
+		require.NoError(t, err)
+		require.EqualValues(t, prev+1, value)
+		hits = append(hits, value)
+	})
+
+	req := solo.NewCallParams(ScName, FuncIncrement)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, []int64{1, 2}, hits)
+
+	remove()
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+
+	checkStateCounter(t, chain, 3)
+}
+
 func checkStateCounter(t *testing.T, chain *solo.Chain, expected interface{}) {
 	res, err := chain.CallView(
 		ScName, ViewGetCounter,
@@ -0,0 +1,483 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// ordered collections on top of host maps: priority queue and sorted map
+// they follow the design of PriorityQueue and SortedMap of the Go kv/collections package
+// the host can't delete single keys, so removed elements leave empty records which are reused
+
+use std::convert::TryInto;
+
+use crate::context::*;
+use crate::host::*;
+use crate::immutable::*;
+use crate::keys::*;
+use crate::mutable::*;
+
+const KEY_CODE_SIZE: u8 = 0;
+const KEY_CODE_ELEM: u8 = 1;
+const KEY_CODE_SEQ: u8 = 2;
+const KEY_CODE_NODE: u8 = 2;
+const KEY_CODE_HEAD: u8 = 3;
+
+// maximum number of levels of the skip list of the sorted map
+pub const SORTED_MAP_MAX_LEVEL: usize = 16;
+
+// logs error text message and then panics, same as ScBaseContext::panic
+fn panic(text: &str) {
+    ROOT.get_string(&KEY_PANIC).set_value(text)
+}
+
+fn coll_key(code: u8, key: &[u8]) -> Key32 {
+    let mut buf = Vec::with_capacity(key.len() + 1);
+    buf.push(code);
+    buf.extend_from_slice(key);
+    get_key_id_from_bytes(&buf)
+}
+
+fn get_int(obj_id: i32, key_id: Key32) -> i64 {
+    i64::from_le_bytes(get_bytes(obj_id, key_id, TYPE_INT64).try_into().unwrap())
+}
+
+fn set_int(obj_id: i32, key_id: Key32, value: i64) {
+    set_bytes(obj_id, key_id, TYPE_INT64, &value.to_le_bytes());
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
+// element of the heap of the priority queue
+// seq is the sequence number of the push, it keeps the order of elements with equal priorities
+struct PqElem {
+    priority: i64,
+    seq: i64,
+    value: Vec<u8>,
+}
+
+impl PqElem {
+    fn from_bytes(bytes: &[u8]) -> PqElem {
+        if bytes.len() < 16 {
+            panic("corrupted priority queue");
+        }
+        PqElem {
+            priority: i64::from_le_bytes(bytes[..8].try_into().unwrap()),
+            seq: i64::from_le_bytes(bytes[8..16].try_into().unwrap()),
+            value: bytes[16..].to_vec(),
+        }
+    }
+
+    fn to_bytes(&self) -> Vec<u8> {
+        let mut buf = Vec::with_capacity(16 + self.value.len());
+        buf.extend_from_slice(&self.priority.to_le_bytes());
+        buf.extend_from_slice(&self.seq.to_le_bytes());
+        buf.extend_from_slice(&self.value);
+        buf
+    }
+
+    fn less(&self, other: &PqElem) -> bool {
+        if self.priority != other.priority {
+            return self.priority < other.priority;
+        }
+        self.seq < other.seq
+    }
+}
+
+fn pq_length(obj_id: i32) -> i64 {
+    get_int(obj_id, coll_key(KEY_CODE_SIZE, &[]))
+}
+
+fn pq_elem(obj_id: i32, index: i64) -> PqElem {
+    PqElem::from_bytes(&get_bytes(obj_id, coll_key(KEY_CODE_ELEM, &index.to_le_bytes()), TYPE_BYTES))
+}
+
+fn pq_set_elem(obj_id: i32, index: i64, elem: &PqElem) {
+    set_bytes(obj_id, coll_key(KEY_CODE_ELEM, &index.to_le_bytes()), TYPE_BYTES, &elem.to_bytes());
+}
+
+fn pq_peek(obj_id: i32) -> Option<(i64, Vec<u8>)> {
+    if pq_length(obj_id) == 0 {
+        return None;
+    }
+    let elem = pq_elem(obj_id, 0);
+    Some((elem.priority, elem.value))
+}
+
+// priority queue stored in a map as a binary heap
+// elements with the lower priority value are popped first,
+// elements with equal priorities are popped in the order they were pushed
+pub struct ScMutablePriorityQueue {
+    obj_id: i32,
+}
+
+impl ScMutablePriorityQueue {
+    // the map must be used for the priority queue only
+    pub fn new(map: &ScMutableMap) -> ScMutablePriorityQueue {
+        ScMutablePriorityQueue { obj_id: map.obj_id }
+    }
+
+    // get immutable version of the priority queue
+    pub fn immutable(&self) -> ScImmutablePriorityQueue {
+        ScImmutablePriorityQueue { obj_id: self.obj_id }
+    }
+
+    // number of elements in the queue
+    pub fn length(&self) -> i64 {
+        pq_length(self.obj_id)
+    }
+
+    // priority and value of the first element, without removing it
+    pub fn peek(&self) -> Option<(i64, Vec<u8>)> {
+        pq_peek(self.obj_id)
+    }
+
+    // remove the first element and return its priority and value
+    pub fn pop(&self) -> Option<(i64, Vec<u8>)> {
+        let n = self.length();
+        if n == 0 {
+            return None;
+        }
+        let first = pq_elem(self.obj_id, 0);
+        let last = pq_elem(self.obj_id, n - 1);
+        set_bytes(self.obj_id, coll_key(KEY_CODE_ELEM, &(n - 1).to_le_bytes()), TYPE_BYTES, &[]);
+        let n = n - 1;
+        set_int(self.obj_id, coll_key(KEY_CODE_SIZE, &[]), n);
+        if n > 0 {
+            // sift down
+            let mut index = 0;
+            loop {
+                let mut child_index = 2 * index + 1;
+                if child_index >= n {
+                    break;
+                }
+                let mut child = pq_elem(self.obj_id, child_index);
+                if child_index + 1 < n {
+                    let right = pq_elem(self.obj_id, child_index + 1);
+                    if right.less(&child) {
+                        child_index += 1;
+                        child = right;
+                    }
+                }
+                if !child.less(&last) {
+                    break;
+                }
+                pq_set_elem(self.obj_id, index, &child);
+                index = child_index;
+            }
+            pq_set_elem(self.obj_id, index, &last);
+        }
+        Some((first.priority, first.value))
+    }
+
+    // add the value with the priority to the queue
+    pub fn push(&self, priority: i64, value: &[u8]) {
+        let n = self.length();
+        let seq_key = coll_key(KEY_CODE_SEQ, &[]);
+        let seq = get_int(self.obj_id, seq_key);
+        set_int(self.obj_id, seq_key, seq + 1);
+        let elem = PqElem { priority, seq, value: value.to_vec() };
+        // sift up
+        let mut index = n;
+        while index > 0 {
+            let parent_index = (index - 1) / 2;
+            let parent = pq_elem(self.obj_id, parent_index);
+            if !elem.less(&parent) {
+                break;
+            }
+            pq_set_elem(self.obj_id, index, &parent);
+            index = parent_index;
+        }
+        pq_set_elem(self.obj_id, index, &elem);
+        set_int(self.obj_id, coll_key(KEY_CODE_SIZE, &[]), n + 1);
+    }
+}
+
+// immutable priority queue
+pub struct ScImmutablePriorityQueue {
+    obj_id: i32,
+}
+
+impl ScImmutablePriorityQueue {
+    pub fn new(map: &ScImmutableMap) -> ScImmutablePriorityQueue {
+        ScImmutablePriorityQueue { obj_id: map.obj_id }
+    }
+
+    // number of elements in the queue
+    pub fn length(&self) -> i64 {
+        pq_length(self.obj_id)
+    }
+
+    // priority and value of the first element
+    pub fn peek(&self) -> Option<(i64, Vec<u8>)> {
+        pq_peek(self.obj_id)
+    }
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
+// forward links of the node of the skip list, one per level
+type SmLinks = Vec<Option<Vec<u8>>>;
+
+// level of the key in the skip list: 1 + the number of leading zero bit pairs of the FNV-1a hash of the key
+fn sm_level(key: &[u8]) -> usize {
+    let mut h: u64 = 0xcbf29ce484222325;
+    for b in key {
+        h ^= *b as u64;
+        h = h.wrapping_mul(0x100000001b3);
+    }
+    let mut level = 1;
+    while level < SORTED_MAP_MAX_LEVEL && (h >> 62) == 0 {
+        level += 1;
+        h <<= 2;
+    }
+    level
+}
+
+fn sm_links_from_bytes(bytes: &[u8]) -> SmLinks {
+    let mut links = SmLinks::new();
+    if bytes.is_empty() {
+        return links;
+    }
+    let n = bytes[0] as usize;
+    let mut pos = 1;
+    for _ in 0..n {
+        if bytes[pos] == 0 {
+            links.push(None);
+            pos += 1;
+            continue;
+        }
+        let size = u16::from_le_bytes(bytes[pos + 1..pos + 3].try_into().unwrap()) as usize;
+        links.push(Some(bytes[pos + 3..pos + 3 + size].to_vec()));
+        pos += 3 + size;
+    }
+    links
+}
+
+fn sm_links_to_bytes(links: &SmLinks) -> Vec<u8> {
+    let mut buf = vec![links.len() as u8];
+    for next in links {
+        match next {
+            None => buf.push(0),
+            Some(key) => {
+                buf.push(1);
+                buf.extend_from_slice(&(key.len() as u16).to_le_bytes());
+                buf.extend_from_slice(key);
+            }
+        }
+    }
+    buf
+}
+
+// forward links of the node of the key, or of the head if the key is None
+fn sm_links(obj_id: i32, key: Option<&[u8]>) -> SmLinks {
+    let links = match key {
+        None => sm_links_from_bytes(&get_bytes(obj_id, coll_key(KEY_CODE_HEAD, &[]), TYPE_BYTES)),
+        Some(key) => sm_links_from_bytes(&get_bytes(obj_id, coll_key(KEY_CODE_NODE, key), TYPE_BYTES)),
+    };
+    if key.is_none() && links.is_empty() {
+        return vec![None; SORTED_MAP_MAX_LEVEL];
+    }
+    links
+}
+
+fn sm_set_links(obj_id: i32, key: &Option<Vec<u8>>, links: &SmLinks) {
+    match key {
+        None => set_bytes(obj_id, coll_key(KEY_CODE_HEAD, &[]), TYPE_BYTES, &sm_links_to_bytes(links)),
+        Some(key) => set_bytes(obj_id, coll_key(KEY_CODE_NODE, key), TYPE_BYTES, &sm_links_to_bytes(links)),
+    }
+}
+
+// for each level the last key less than the key (None for the head), with its links
+fn sm_predecessors(obj_id: i32, key: &[u8]) -> (Vec<Option<Vec<u8>>>, Vec<SmLinks>) {
+    let mut preds = vec![None; SORTED_MAP_MAX_LEVEL];
+    let mut pred_links = vec![SmLinks::new(); SORTED_MAP_MAX_LEVEL];
+    let mut cur: Option<Vec<u8>> = None;
+    let mut cur_links = sm_links(obj_id, None);
+    for level in (0..SORTED_MAP_MAX_LEVEL).rev() {
+        while level < cur_links.len() {
+            match &cur_links[level] {
+                Some(next) if next.as_slice() < key => {
+                    let next = next.clone();
+                    cur_links = sm_links(obj_id, Some(&next));
+                    cur = Some(next);
+                }
+                _ => break,
+            }
+        }
+        preds[level] = cur.clone();
+        pred_links[level] = cur_links.clone();
+    }
+    (preds, pred_links)
+}
+
+// values are stored with a presence marker, an empty record means the key was removed
+fn sm_get(obj_id: i32, key: &[u8]) -> Option<Vec<u8>> {
+    let bytes = get_bytes(obj_id, coll_key(KEY_CODE_ELEM, key), TYPE_BYTES);
+    if bytes.is_empty() {
+        return None;
+    }
+    Some(bytes[1..].to_vec())
+}
+
+fn sm_iterate_from(obj_id: i32, key: &[u8], f: &mut dyn FnMut(&[u8], &[u8]) -> bool) {
+    let (_, pred_links) = sm_predecessors(obj_id, key);
+    let mut next = pred_links[0][0].clone();
+    while let Some(key) = next {
+        let value = sm_get(obj_id, &key).unwrap_or_default();
+        if !f(&key, &value) {
+            return;
+        }
+        next = sm_links(obj_id, Some(&key))[0].clone();
+    }
+}
+
+fn sm_last(obj_id: i32) -> Option<Vec<u8>> {
+    let mut cur: Option<Vec<u8>> = None;
+    let mut cur_links = sm_links(obj_id, None);
+    for level in (0..SORTED_MAP_MAX_LEVEL).rev() {
+        while level < cur_links.len() {
+            match cur_links[level].clone() {
+                Some(next) => {
+                    cur_links = sm_links(obj_id, Some(&next));
+                    cur = Some(next);
+                }
+                None => break,
+            }
+        }
+    }
+    cur
+}
+
+// map which is iterated in the ascending order of keys
+// keys are linked into a skip list, the level of each key is derived from the hash of the key
+pub struct ScMutableSortedMap {
+    obj_id: i32,
+}
+
+impl ScMutableSortedMap {
+    // the map must be used for the sorted map only
+    pub fn new(map: &ScMutableMap) -> ScMutableSortedMap {
+        ScMutableSortedMap { obj_id: map.obj_id }
+    }
+
+    // remove the key from the map
+    pub fn delete(&self, key: &[u8]) {
+        if sm_get(self.obj_id, key).is_none() {
+            return;
+        }
+        let (preds, mut pred_links) = sm_predecessors(self.obj_id, key);
+        let links = sm_links(self.obj_id, Some(key));
+        for level in 0..links.len() {
+            pred_links[level][level] = links[level].clone();
+            // links of the same predecessor are modified at several levels
+            for upper in level + 1..links.len() {
+                if preds[upper] == preds[level] {
+                    pred_links[upper][level] = links[level].clone();
+                }
+            }
+        }
+        for level in 0..links.len() {
+            if level == 0 || preds[level] != preds[level - 1] {
+                sm_set_links(self.obj_id, &preds[level], &pred_links[level]);
+            }
+        }
+        set_bytes(self.obj_id, coll_key(KEY_CODE_NODE, key), TYPE_BYTES, &[]);
+        set_bytes(self.obj_id, coll_key(KEY_CODE_ELEM, key), TYPE_BYTES, &[]);
+        set_int(self.obj_id, coll_key(KEY_CODE_SIZE, &[]), self.length() - 1);
+    }
+
+    // check if the key exists in the map
+    pub fn exists(&self, key: &[u8]) -> bool {
+        sm_get(self.obj_id, key).is_some()
+    }
+
+    // value of the key
+    pub fn get(&self, key: &[u8]) -> Option<Vec<u8>> {
+        sm_get(self.obj_id, key)
+    }
+
+    // get immutable version of the sorted map
+    pub fn immutable(&self) -> ScImmutableSortedMap {
+        ScImmutableSortedMap { obj_id: self.obj_id }
+    }
+
+    // iterate keys greater or equal to the key in the ascending order, until f returns false
+    pub fn iterate_from(&self, key: &[u8], f: &mut dyn FnMut(&[u8], &[u8]) -> bool) {
+        sm_iterate_from(self.obj_id, key, f)
+    }
+
+    // the greatest key of the map
+    pub fn last(&self) -> Option<Vec<u8>> {
+        sm_last(self.obj_id)
+    }
+
+    // number of keys in the map
+    pub fn length(&self) -> i64 {
+        get_int(self.obj_id, coll_key(KEY_CODE_SIZE, &[]))
+    }
+
+    // set the value of the key
+    pub fn set(&self, key: &[u8], value: &[u8]) {
+        if key.len() > u16::MAX as usize {
+            panic("sorted map key too long");
+        }
+        if sm_get(self.obj_id, key).is_none() {
+            let (preds, mut pred_links) = sm_predecessors(self.obj_id, key);
+            let level = sm_level(key);
+            let mut links = SmLinks::new();
+            for i in 0..level {
+                links.push(pred_links[i][i].clone());
+                pred_links[i][i] = Some(key.to_vec());
+                for upper in i + 1..level {
+                    if preds[upper] == preds[i] {
+                        pred_links[upper][i] = Some(key.to_vec());
+                    }
+                }
+            }
+            sm_set_links(self.obj_id, &Some(key.to_vec()), &links);
+            for i in 0..level {
+                if i == 0 || preds[i] != preds[i - 1] {
+                    sm_set_links(self.obj_id, &preds[i], &pred_links[i]);
+                }
+            }
+            set_int(self.obj_id, coll_key(KEY_CODE_SIZE, &[]), self.length() + 1);
+        }
+        let mut buf = Vec::with_capacity(value.len() + 1);
+        buf.push(1);
+        buf.extend_from_slice(value);
+        set_bytes(self.obj_id, coll_key(KEY_CODE_ELEM, key), TYPE_BYTES, &buf);
+    }
+}
+
+// immutable sorted map
+pub struct ScImmutableSortedMap {
+    obj_id: i32,
+}
+
+impl ScImmutableSortedMap {
+    pub fn new(map: &ScImmutableMap) -> ScImmutableSortedMap {
+        ScImmutableSortedMap { obj_id: map.obj_id }
+    }
+
+    // check if the key exists in the map
+    pub fn exists(&self, key: &[u8]) -> bool {
+        sm_get(self.obj_id, key).is_some()
+    }
+
+    // value of the key
+    pub fn get(&self, key: &[u8]) -> Option<Vec<u8>> {
+        sm_get(self.obj_id, key)
+    }
+
+    // iterate keys greater or equal to the key in the ascending order, until f returns false
+    pub fn iterate_from(&self, key: &[u8], f: &mut dyn FnMut(&[u8], &[u8]) -> bool) {
+        sm_iterate_from(self.obj_id, key, f)
+    }
+
+    // the greatest key of the map
+    pub fn last(&self) -> Option<Vec<u8>> {
+        sm_last(self.obj_id)
+    }
+
+    // number of keys in the map
+    pub fn length(&self) -> i64 {
+        get_int(self.obj_id, coll_key(KEY_CODE_SIZE, &[]))
+    }
+}
@@ -70,6 +70,14 @@ impl ScTransfers {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+// operations of the 256-bit unsigned integer arithmetic, must match the host side
+const UINT256_ADD: i64 = 0;
+const UINT256_SUB: i64 = 1;
+const UINT256_MUL: i64 = 2;
+const UINT256_DIV: i64 = 3;
+const UINT256_MOD: i64 = 4;
+const UINT256_EXP: i64 = 5;
+
 // provide access to utility functions that are handled by the host
 pub struct ScUtility {
     utility: ScMutableMap,
@@ -145,6 +153,13 @@ impl ScUtility {
         ScHash::from_bytes(&hash.value())
     }
 
+    // hashes the specified value bytes using Ethereum-compatible keccak256 hashing and returns the resulting 32-byte hash
+    pub fn hash_keccak256(&self, value: &[u8]) -> ScHash {
+        let hash = self.utility.get_bytes(&KEY_HASH_KECCAK256);
+        hash.set_value(value);
+        ScHash::from_bytes(&hash.value())
+    }
+
     // hashes the specified value bytes using sha3 hashing and returns the resulting 32-byte hash
     pub fn hash_sha3(&self, value: &[u8]) -> ScHash {
         let hash = self.utility.get_bytes(&KEY_HASH_SHA3);
@@ -163,6 +178,57 @@ impl ScUtility {
         let rnd = self.utility.get_int64(&KEY_RANDOM).value();
         (rnd as u64 % max as u64) as i64
     }
+
+    // checks if the specified 64-byte secp256k1 signature of the 32-byte hash is valid
+    // the public key can be either compressed (33 bytes) or uncompressed (65 bytes)
+    pub fn secp256k1_valid_signature(&self, hash: &[u8], pub_key: &[u8], signature: &[u8]) -> bool {
+        let mut encode = BytesEncoder::new();
+        encode.bytes(hash);
+        encode.bytes(pub_key);
+        encode.bytes(signature);
+        self.utility.get_bytes(&KEY_SECP256K1_VALID).set_value(&encode.data());
+        self.utility.get_int64(&KEY_VALID).value() != 0
+    }
+
+    // adds two 32-byte big-endian unsigned 256-bit integers, wraps around on overflow
+    pub fn uint256_add(&self, a: &[u8], b: &[u8]) -> Vec<u8> {
+        self.uint256_op(UINT256_ADD, a, b)
+    }
+
+    // subtracts two 32-byte big-endian unsigned 256-bit integers, wraps around on underflow
+    pub fn uint256_sub(&self, a: &[u8], b: &[u8]) -> Vec<u8> {
+        self.uint256_op(UINT256_SUB, a, b)
+    }
+
+    // multiplies two 32-byte big-endian unsigned 256-bit integers, wraps around on overflow
+    pub fn uint256_mul(&self, a: &[u8], b: &[u8]) -> Vec<u8> {
+        self.uint256_op(UINT256_MUL, a, b)
+    }
+
+    // divides two 32-byte big-endian unsigned 256-bit integers, panics on division by zero
+    pub fn uint256_div(&self, a: &[u8], b: &[u8]) -> Vec<u8> {
+        self.uint256_op(UINT256_DIV, a, b)
+    }
+
+    // remainder of two 32-byte big-endian unsigned 256-bit integers, panics on division by zero
+    pub fn uint256_mod(&self, a: &[u8], b: &[u8]) -> Vec<u8> {
+        self.uint256_op(UINT256_MOD, a, b)
+    }
+
+    // raises 32-byte big-endian unsigned 256-bit integer to the power, modulo 2^256
+    pub fn uint256_exp(&self, a: &[u8], b: &[u8]) -> Vec<u8> {
+        self.uint256_op(UINT256_EXP, a, b)
+    }
+
+    fn uint256_op(&self, op: i64, a: &[u8], b: &[u8]) -> Vec<u8> {
+        let mut encode = BytesEncoder::new();
+        encode.int64(op);
+        encode.bytes(a);
+        encode.bytes(b);
+        let result = self.utility.get_bytes(&KEY_UINT256);
+        result.set_value(&encode.data());
+        result.value()
+    }
 }
 
 // wrapper function for simplified internal access to base58 encoding
@@ -172,6 +238,30 @@ pub(crate) fn base58_encode(bytes: &[u8]) -> String {
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
 
+// reserved keys of the results that hold the structured error, must match the host side
+pub const RESULT_ERROR_CODE: &str = "$err.code";
+pub const RESULT_ERROR_MESSAGE: &str = "$err.msg";
+
+// structured error returned by the called smart contract function
+#[derive(Debug)]
+pub struct ScContractError {
+    pub code: i64,
+    pub message: String,
+}
+
+impl ScContractError {
+    // retrieve the structured error from the results of a call, if the function returned one
+    pub fn from_results(results: &ScImmutableMap) -> Option<ScContractError> {
+        let code = results.get_int64(RESULT_ERROR_CODE);
+        if !code.exists() {
+            return None;
+        }
+        Some(ScContractError { code: code.value(), message: results.get_string(RESULT_ERROR_MESSAGE).value() })
+    }
+}
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
 // shared interface part of ScFuncContext and ScViewContext
 pub trait ScBaseContext {
     // access the current balances for all token colors
@@ -231,6 +321,14 @@ pub trait ScBaseContext {
         ROOT.get_string(&KEY_TRACE).set_value(text)
     }
 
+    // returns the structured error to the caller instead of the results
+    // the caller can handle the error by its code, see ScContractError
+    fn error(&self, code: i64, message: &str) {
+        let results = self.results();
+        results.get_int64(RESULT_ERROR_CODE).set_value(code);
+        results.get_string(RESULT_ERROR_MESSAGE).set_value(message);
+    }
+
     // access diverse utility functions
     fn utility(&self) -> ScUtility {
         ScUtility { utility: ROOT.get_map(&KEY_UTILITY) }
@@ -266,6 +364,22 @@ impl ScFuncContext {
         ROOT.get_map(&KEY_RETURN).immutable()
     }
 
+    // retrieve the number of requests in the batch of the current block
+    pub fn batch_size(&self) -> i64 {
+        ROOT.get_int64(&KEY_BATCH_SIZE).value()
+    }
+
+    // retrieve the index of the block being produced
+    pub fn block_index(&self) -> i64 {
+        ROOT.get_int64(&KEY_BLOCK_INDEX).value()
+    }
+
+    // retrieve the timestamp of the batch agreed by the committee
+    // the timestamp of the request is the block timestamp plus request index nanoseconds
+    pub fn block_timestamp(&self) -> i64 {
+        ROOT.get_int64(&KEY_BLOCK_TIMESTAMP).value()
+    }
+
     // retrieve the agent id of the caller of the smart contract
     pub fn caller(&self) -> ScAgentId { ROOT.get_agent_id(&KEY_CALLER).value() }
 
@@ -337,6 +451,43 @@ impl ScFuncContext {
         ROOT.get_request_id(&KEY_REQUEST_ID).value()
     }
 
+    // access the transfer declared by the request, before fees and the deposit are taken from it
+    // the incoming balances are the declared ones minus fees and minus the deposit iotas
+    pub fn request_declared(&self) -> ScBalances {
+        ScBalances { balances: ROOT.get_map(&KEY_REQUEST_DECLARED).immutable() }
+    }
+
+    // retrieve the number of iotas taken from the declared transfer as the request deposit
+    pub fn request_deposit(&self) -> i64 {
+        ROOT.get_int64(&KEY_REQUEST_DEPOSIT).value()
+    }
+
+    // true if the iota of the request token was accrued to the on-chain account of the sender
+    // it is never part of the declared transfer
+    pub fn request_dust_added(&self) -> bool {
+        ROOT.get_int64(&KEY_REQUEST_DUST_ADDED).value() != 0
+    }
+
+    // access the fees taken from the declared transfer of the request
+    pub fn request_fees(&self) -> ScBalances {
+        ScBalances { balances: ROOT.get_map(&KEY_REQUEST_FEES).immutable() }
+    }
+
+    // retrieve the position of the request in the batch of the current block, starting from 0
+    pub fn request_index(&self) -> i64 {
+        ROOT.get_int64(&KEY_REQUEST_INDEX).value()
+    }
+
+    // retrieve the address which signed the Tangle transaction of the request
+    pub fn request_sender(&self) -> ScAddress {
+        ROOT.get_address(&KEY_REQUEST_SENDER).value()
+    }
+
+    // retrieve the id of the Tangle transaction of the request
+    pub fn request_tx_id(&self) -> ScHash {
+        ROOT.get_hash(&KEY_REQUEST_TX_ID).value()
+    }
+
     // access to mutable state storage
     pub fn state(&self) -> ScMutableMap {
         ROOT.get_map(&KEY_STATE)
@@ -349,6 +500,22 @@ impl ScFuncContext {
         tx.get_address(&KEY_ADDRESS).set_value(address);
         tx.get_int64(&KEY_BALANCES).set_value(transfer.transfers.obj_id as i64);
     }
+
+    // retrieve the verifiable random output of the current block
+    // it can't be biased by validators, however the leader knows it before it selects the requests of the block
+    pub fn vrf(&self) -> ScHash {
+        ROOT.get_hash(&KEY_VRF).value()
+    }
+
+    // retrieve the seed of the vrf: the hash of the previous state, the index and the timestamp of the block
+    pub fn vrf_seed(&self) -> Vec<u8> {
+        ROOT.get_bytes(&KEY_VRF_SEED).value()
+    }
+
+    // retrieve the proof of the vrf: the BLS signature of the seed by the committee
+    pub fn vrf_proof(&self) -> Vec<u8> {
+        ROOT.get_bytes(&KEY_VRF_PROOF).value()
+    }
 }
 
 // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
@@ -386,3 +553,22 @@ impl ScViewContext {
         ROOT.get_map(&KEY_STATE).immutable()
     }
 }
+
+// \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\ // \\
+
+// calls smart contract views from both functions and views
+pub trait ScViewCaller {
+    fn call_view(&self, contract: ScHname, function: ScHname, params: Option<ScMutableMap>) -> ScImmutableMap;
+}
+
+impl ScViewCaller for ScFuncContext {
+    fn call_view(&self, contract: ScHname, function: ScHname, params: Option<ScMutableMap>) -> ScImmutableMap {
+        self.call(contract, function, params, None)
+    }
+}
+
+impl ScViewCaller for ScViewContext {
+    fn call_view(&self, contract: ScHname, function: ScHname, params: Option<ScMutableMap>) -> ScImmutableMap {
+        self.call(contract, function, params)
+    }
+}
@@ -72,4 +72,24 @@ pub const KEY_TRANSFERS        : Key32 = Key32(-38);
 pub const KEY_UTILITY          : Key32 = Key32(-39);
 pub const KEY_VALID            : Key32 = Key32(-40);
 pub const KEY_ZZZZZZZ          : Key32 = Key32(-41);
+// host utility keys added after the version marker
+pub const KEY_HASH_KECCAK256   : Key32 = Key32(-42);
+pub const KEY_SECP256K1_VALID  : Key32 = Key32(-43);
+pub const KEY_UINT256          : Key32 = Key32(-44);
+// request metadata keys
+pub const KEY_BATCH_SIZE       : Key32 = Key32(-45);
+pub const KEY_BLOCK_INDEX      : Key32 = Key32(-46);
+pub const KEY_BLOCK_TIMESTAMP  : Key32 = Key32(-47);
+pub const KEY_REQUEST_INDEX    : Key32 = Key32(-48);
+pub const KEY_REQUEST_SENDER   : Key32 = Key32(-49);
+pub const KEY_REQUEST_TX_ID    : Key32 = Key32(-50);
+// request transfer keys
+pub const KEY_REQUEST_DECLARED : Key32 = Key32(-51);
+pub const KEY_REQUEST_DEPOSIT  : Key32 = Key32(-52);
+pub const KEY_REQUEST_DUST_ADDED: Key32 = Key32(-53);
+pub const KEY_REQUEST_FEES     : Key32 = Key32(-54);
+// verifiable random output keys
+pub const KEY_VRF              : Key32 = Key32(-55);
+pub const KEY_VRF_SEED         : Key32 = Key32(-56);
+pub const KEY_VRF_PROOF        : Key32 = Key32(-57);
 // @formatter:on
@@ -4,6 +4,7 @@
 #![allow(dead_code)]
 
 pub use bytes::*;
+pub use collections::*;
 pub use context::*;
 pub use corecontracts::*;
 pub use exports::ScExports;
@@ -13,6 +14,7 @@ pub use keys::*;
 pub use mutable::*;
 
 mod bytes;
+mod collections;
 mod context;
 mod corecontracts;
 mod exports;
@@ -4,6 +4,7 @@ go 1.15
 
 require (
 	github.com/bytecodealliance/wasmtime-go v0.21.0
+	github.com/cockroachdb/pebble v0.0.0-20201130172119-f19faf8529d6
 	github.com/iotaledger/goshimmer v0.3.7-0.20210214081859-29e3f77b4364
 	github.com/iotaledger/hive.go v0.0.0-20210209113323-87572778f0d9
 	github.com/knadh/koanf v0.14.0
@@ -18,6 +19,7 @@ require (
 	go.dedis.ch/kyber/v3 v3.0.13
 	go.nanomsg.org/mangos/v3 v3.0.1
 	go.uber.org/atomic v1.7.0
+	go.uber.org/goleak v1.0.0
 	go.uber.org/zap v1.16.0
 	golang.org/x/crypto v0.0.0-20201016220609-9e8e0b390897
 	golang.org/x/net v0.0.0-20201202161906-c7110b5ffcbb
@@ -12,6 +12,7 @@ import (
 	"github.com/iotaledger/wasp/plugins/database"
 	"github.com/iotaledger/wasp/plugins/dispatcher"
 	"github.com/iotaledger/wasp/plugins/dkg"
+	"github.com/iotaledger/wasp/plugins/export"
 	"github.com/iotaledger/wasp/plugins/globals"
 	"github.com/iotaledger/wasp/plugins/gracefulshutdown"
 	"github.com/iotaledger/wasp/plugins/logger"
@@ -46,6 +47,7 @@ func main() {
 		dispatcher.Init(),
 		chains.Init(),
 		publisher.Init(),
+		export.Init(),
 		dashboard.Init(),
 		wasmtimevm.Init(),
 		globals.Init(),
@@ -26,12 +26,16 @@ type CreateChainParams struct {
 	Node                  level1.Level1Client
 	CommitteeApiHosts     []string
 	CommitteePeeringHosts []string
-	N                     uint16
-	T                     uint16
-	OriginatorSigScheme   signaturescheme.SignatureScheme
-	Description           string
-	Textout               io.Writer
-	Prefix                string
+	// AccessApiHosts and AccessPeeringHosts are addresses of access nodes of the chain, may be empty.
+	// Access nodes follow the state of the chain and serve view calls, but don't take part in the consensus
+	AccessApiHosts      []string
+	AccessPeeringHosts  []string
+	N                   uint16
+	T                   uint16
+	OriginatorSigScheme signaturescheme.SignatureScheme
+	Description         string
+	Textout             io.Writer
+	Prefix              string
 }
 
 // DeployChain performs all actions needed to deploy the chain
@@ -111,11 +115,18 @@ func DeployChain(par CreateChainParams) (*coretypes.ChainID, *address.Address, *
 
 	chainColor := balance.Color(originTx.ID())
 	committee := multiclient.New(par.CommitteeApiHosts)
+	// chain records are put and activated on committee and access nodes
+	chainNodes := multiclient.New(append(append([]string{}, par.CommitteeApiHosts...), par.AccessApiHosts...))
 	// ------------ put chain records to hosts
-	err = committee.PutChainRecord(&registry.ChainRecord{
+	accessNodes := par.AccessPeeringHosts
+	if accessNodes == nil {
+		accessNodes = []string{}
+	}
+	err = chainNodes.PutChainRecord(&registry.ChainRecord{
 		ChainID:        chainID,
 		Color:          chainColor,
 		CommitteeNodes: par.CommitteePeeringHosts,
+		AccessNodes:    accessNodes,
 	})
 
 	fmt.Fprint(textout, par.Prefix)
@@ -126,7 +137,7 @@ func DeployChain(par CreateChainParams) (*coretypes.ChainID, *address.Address, *
 	fmt.Fprint(textout, "sending smart contract metadata to Wasp nodes.. OK.\n")
 
 	// ------------- activate chain
-	err = committee.ActivateChain(chainID)
+	err = chainNodes.ActivateChain(chainID)
 
 	fmt.Fprint(textout, par.Prefix)
 	if err != nil {
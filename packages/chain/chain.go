@@ -15,15 +15,19 @@ import (
 	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/packages/vm/processors"
 	"sync"
+	"time"
 )
 
 type Chain interface {
 	ID() *coretypes.ChainID
 	Color() *balance.Color
 	Address() address.Address
+	// Size is the size of the committee. Peers with indices from 0 to Size()-1 are committee nodes
 	Size() uint16
+	// Quorum is the quorum of the committee. On access nodes it is 1: one committee node is enough to fetch blocks
 	Quorum() uint16
 	OwnPeerIndex() uint16
+	// NumPeers is the number of peers of the chain: committee nodes followed by access nodes
 	NumPeers() uint16
 	SendMsg(targetPeerIndex uint16, msgType byte, msgData []byte) error
 	SendMsgToCommitteePeers(msgType byte, msgData []byte, ts int64) uint16
@@ -32,12 +36,21 @@ type Chain interface {
 	InitTestRound()
 	HasQuorum() bool
 	PeerStatus() []*PeerStatus
+	// ConsensusInfo returns the state of the consensus of the node. Nil if the node is not in the committee
+	ConsensusInfo() *ConsensusInfo
 	BlobCache() coretypes.BlobCache
 	//
 	SetReadyStateManager()
 	SetReadyConsensus()
 	Dismiss()
 	IsDismissed() bool
+	// Drain stops accepting new requests and dismisses the chain when the round in progress is finished
+	// or after DrainTimeout. The returned channel is closed when the chain is dismissed
+	Drain() <-chan struct{}
+	IsDraining() bool
+	// DeclareEmergency votes to delegate production of the next numBlocks blocks to the committee node
+	// with the index delegate. The emergency mode starts when the emergency quorum of nodes votes the same
+	DeclareEmergency(delegate uint16, numBlocks uint32) error
 	// requests
 	GetRequestProcessingStatus(*coretypes.RequestID) RequestProcessingStatus
 	EventRequestProcessed() *events.Event
@@ -50,12 +63,60 @@ type PeerStatus struct {
 	PeeringID string
 	IsSelf    bool
 	Connected bool
+	// Alive is false if the peer is not connected or nothing was heard from it for PeerDeadTimeout
+	Alive bool
+	// LastHeard is the time of the last message received from the peer. Zero if nothing was received
+	LastHeard time.Time
 }
 
 func (p *PeerStatus) String() string {
 	return fmt.Sprintf("%+v", *p)
 }
 
+// ConsensusInfo is the snapshot of the state of the consensus operator
+type ConsensusInfo struct {
+	// Stage is the name of the current consensus stage
+	Stage string
+	// Leader is the index of the current leader
+	Leader   uint16
+	IsLeader bool
+	// Synced is false while the node is syncing the state and doesn't take part in the consensus
+	Synced bool
+	// StateIndex is the index of the last finalized state known to the operator
+	StateIndex uint32
+	// StateTimestamp is the timestamp of the last finalized state
+	StateTimestamp time.Time
+	// Tentative is true if the anchor transaction of the state is not confirmed yet, see consensus pipelining
+	Tentative bool
+	// Backlog is the number of requests in the backlog of the operator
+	Backlog int
+	// Overdue is the number of requests in the backlog which must be included into the next batch,
+	// see consensus request age
+	Overdue int
+	// RoundInProgress is true from the start of calculations of the round until the new state.
+	// It is false while the node waits for requests or syncs the state
+	RoundInProgress bool
+	// Emergency is true while the block production is delegated to the node EmergencyDelegate,
+	// until the state with the index EmergencyUntil
+	Emergency         bool
+	EmergencyDelegate uint16
+	EmergencyUntil    uint32
+	// RecentRounds are the last finished rounds of the consensus, the latest first
+	RecentRounds []*RoundInfo
+}
+
+// RoundInfo is the round of the consensus which produced the state
+type RoundInfo struct {
+	StateIndex uint32
+	Leader     uint16
+	// Finished is the time the node received the new state
+	Finished time.Time
+	// Duration is the time from the start of calculations on the node until the new state.
+	// Zero if the node didn't take part in the round, for example, when syncing
+	Duration    time.Duration
+	NumRequests int
+}
+
 type RequestProcessingStatus int
 
 const (
@@ -84,12 +145,19 @@ type Operator interface {
 	EventStartProcessingBatchMsg(*StartProcessingBatchMsg)
 	EventResultCalculated(msg *VMResultMsg)
 	EventSignedHashMsg(*SignedHashMsg)
+	EventProposalDigestMsg(*ProposalDigestMsg)
+	EventEmergencyMsg(*EmergencyMsg)
+	EventEmergencyVoteMsg(*EmergencyVoteMsg)
+	EventFairOrderShareMsg(*FairOrderShareMsg)
+	EventSigningCommitmentMsg(*SigningCommitmentMsg)
+	EventVRFShareMsg(*VRFShareMsg)
 	EventNotifyFinalResultPostedMsg(*NotifyFinalResultPostedMsg)
 	EventTransactionInclusionLevelMsg(msg *TransactionInclusionLevelMsg)
 	EventTimerMsg(TimerTick)
 	Close()
 	//
 	IsRequestInBacklog(*coretypes.RequestID) bool
+	ConsensusInfo() *ConsensusInfo
 }
 
 type chainConstructor func(
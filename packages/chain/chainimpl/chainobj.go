@@ -4,6 +4,7 @@
 package chainimpl
 
 import (
+	"fmt"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"sync"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/iotaledger/wasp/packages/chain/consensus"
 	"github.com/iotaledger/wasp/packages/chain/statemgr"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/peering"
 	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/util"
@@ -33,6 +35,9 @@ type chainObj struct {
 	isOpenQueue                  atomic.Bool
 	dismissed                    atomic.Bool
 	dismissOnce                  sync.Once
+	draining                     atomic.Bool
+	drainOnce                    sync.Once
+	drained                      chan struct{}
 	onActivation                 func()
 	//
 	chainID         coretypes.ChainID
@@ -46,6 +51,9 @@ type chainObj struct {
 	stateMgr        chain.StateManager
 	operator        chain.Operator
 	isCommitteeNode atomic.Bool
+	health          *peerHealth
+	// nil if the transcript is not recorded, see transcript.go
+	transcript *transcriptRecorder
 	//
 	eventRequestProcessed *events.Event
 	log                   *logger.Logger
@@ -71,28 +79,36 @@ func newCommitteeObj(
 	log.Debugw("creating committee", "addr", chr.ChainID.String())
 
 	addr := address.Address(chr.ChainID)
-	if util.ContainsDuplicates(chr.CommitteeNodes) {
+	// committee nodes go first in the group of peers of the chain, access nodes follow them
+	groupNodes := append(append([]string{}, chr.CommitteeNodes...), chr.AccessNodes...)
+	if util.ContainsDuplicates(groupNodes) {
 		log.Errorf("can't create chain object for %s: chain record contains duplicate node addresses. Chain nodes: %+v",
-			addr.String(), chr.CommitteeNodes)
+			addr.String(), groupNodes)
 		return nil
 	}
-	dkshare, err := dksProvider.LoadDKShare(&addr)
-	if err != nil {
-		log.Error(err)
-		return nil
-	}
-	if dkshare.Index == nil || !iAmInTheCommittee(chr.CommitteeNodes, dkshare.N, *dkshare.Index, netProvider) {
-		log.Errorf(
-			"chain record inconsistency: the own node %s is not in the committee for %s: %+v",
-			netProvider.Self().NetID(), addr.String(), chr.CommitteeNodes,
-		)
-		return nil
+	var dkshare *tcrypto.DKShare
+	var signer tcrypto.ThresholdSigner
+	ownIndex, isAccessNode := accessNodeIndex(chr, netProvider.Self().NetID())
+	if !isAccessNode {
+		dkshare, signer, err = loadKeyShares(&addr, dksProvider, parameters.GetBool(parameters.ConsensusDealtFrostKeys))
+		if err != nil {
+			log.Error(err)
+			return nil
+		}
+		if dkshare.Index == nil || !iAmInTheCommittee(chr.CommitteeNodes, dkshare.N, *dkshare.Index, netProvider) {
+			log.Errorf(
+				"chain record inconsistency: the own node %s is not in the committee for %s: %+v",
+				netProvider.Self().NetID(), addr.String(), chr.CommitteeNodes,
+			)
+			return nil
+		}
+		ownIndex = *dkshare.Index
 	}
 	var peers peering.GroupProvider
-	if peers, err = netProvider.Group(chr.CommitteeNodes); err != nil {
+	if peers, err = netProvider.Group(groupNodes); err != nil {
 		log.Errorf(
 			"node %s failed to setup committee communication with %+v, reason=%+v",
-			netProvider.Self().NetID(), chr.CommitteeNodes, err,
+			netProvider.Self().NetID(), groupNodes, err,
 		)
 		return nil
 	}
@@ -100,9 +116,11 @@ func newCommitteeObj(
 	ret := &chainObj{
 		procset:      processors.MustNew(),
 		chMsg:        make(chan interface{}, 100),
+		drained:      make(chan struct{}),
 		chainID:      chr.ChainID,
 		color:        chr.Color,
 		peers:        peers,
+		health:       newPeerHealth(),
 		onActivation: onActivation,
 		eventRequestProcessed: events.NewEvent(func(handler interface{}, params ...interface{}) {
 			handler.(func(_ coretypes.RequestID))(params[0].(coretypes.RequestID))
@@ -116,13 +134,24 @@ func newCommitteeObj(
 		ret.ReceiveMessage(recv.Msg)
 	})
 
-	ret.ownIndex = *dkshare.Index
-	ret.size = dkshare.N
-	ret.quorum = dkshare.T
+	ret.ownIndex = ownIndex
+	if isAccessNode {
+		// the access node only follows the state, so one committee node to fetch blocks from is enough
+		ret.log.Infof("the node is an access node of the chain, peer index: %d", ownIndex)
+		ret.size = uint16(len(chr.CommitteeNodes))
+		ret.quorum = 1
+		ret.isReadyConsensus = true
+		ret.stateMgr = statemgr.New(ret, ret.log)
+	} else {
+		ret.size = dkshare.N
+		ret.quorum = dkshare.T
 
-	ret.stateMgr = statemgr.New(ret, ret.log)
-	ret.operator = consensus.NewOperator(ret, dkshare, ret.log)
-	ret.isCommitteeNode.Store(true)
+		ret.startTranscript(dkshare)
+		ret.stateMgr = statemgr.New(ret, ret.log)
+		ret.log.Infof("anchor transactions are signed with the %s threshold signature", signer.Scheme())
+		ret.operator = consensus.NewOperator(ret, dkshare, signer, ret.log)
+		ret.isCommitteeNode.Store(true)
+	}
 	go func() {
 		for msg := range ret.chMsg {
 			ret.dispatchMessage(msg)
@@ -147,6 +176,54 @@ func newCommitteeObj(
 	return ret
 }
 
+// loadKeyShares loads the key share of the node and the signer of anchor transactions of the chain address.
+// The chain with the ED25519 address is controlled by the FROST key share, the committee still uses
+// the BLS key share of the committee address for its internal messages.
+// FROST key shares are not generated by the DKG, they are dealt by the trusted dealer which knows the whole
+// key of the chain. So the FROST chain is run only if 'dealtFrostKeys' is set, for testing and development
+func loadKeyShares(addr *address.Address, dksProvider tcrypto.RegistryProvider, dealtFrostKeys bool) (*tcrypto.DKShare, tcrypto.ThresholdSigner, error) {
+	scheme, err := tcrypto.SigSchemeOfAddress(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if scheme == tcrypto.SigSchemeBLS {
+		dkshare, err := dksProvider.LoadDKShare(addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dkshare, tcrypto.NewBLSSigner(dkshare), nil
+	}
+	if !dealtFrostKeys {
+		return nil, nil, fmt.Errorf("chain %s is controlled by FROST key shares of the trusted dealer. Set '%s' to run it",
+			addr.String(), parameters.ConsensusDealtFrostKeys)
+	}
+	frostShare, err := dksProvider.LoadFrostShare(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	dkshare, err := dksProvider.LoadDKShare(frostShare.CommitteeAddress)
+	if err != nil {
+		return nil, nil, err
+	}
+	if frostShare.Index == nil || dkshare.Index == nil || *frostShare.Index != *dkshare.Index ||
+		frostShare.N != dkshare.N || frostShare.T != dkshare.T {
+		return nil, nil, fmt.Errorf("FROST key share of %s doesn't match the key share of the committee %s",
+			addr.String(), frostShare.CommitteeAddress.String())
+	}
+	return dkshare, tcrypto.NewFrostSigner(frostShare), nil
+}
+
+// accessNodeIndex returns the index of the node in the group of peers of the chain if the node is
+// an access node of the chain
+func accessNodeIndex(chr *registry.ChainRecord, netID string) (uint16, bool) {
+	for i, id := range chr.AccessNodes {
+		if id == netID {
+			return uint16(len(chr.CommitteeNodes) + i), true
+		}
+	}
+	return 0, false
+}
+
 // iAmInTheCommittee checks if NetIDs makes sense
 func iAmInTheCommittee(committeeNodes []string, n, index uint16, netProvider peering.NetworkProvider) bool {
 	if len(committeeNodes) != int(n) {
@@ -0,0 +1,64 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package chainimpl
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+)
+
+func TestAccessNodeIndex(t *testing.T) {
+	chr := &registry.ChainRecord{
+		CommitteeNodes: []string{"wasp0:4000", "wasp1:4000", "wasp2:4000", "wasp3:4000"},
+		AccessNodes:    []string{"wasp4:4000", "wasp5:4000"},
+	}
+
+	// committee nodes are not access nodes
+	_, ok := accessNodeIndex(chr, "wasp1:4000")
+	require.False(t, ok)
+
+	// access nodes follow committee nodes in the group of peers
+	index, ok := accessNodeIndex(chr, "wasp4:4000")
+	require.True(t, ok)
+	require.EqualValues(t, 4, index)
+
+	index, ok = accessNodeIndex(chr, "wasp5:4000")
+	require.True(t, ok)
+	require.EqualValues(t, 5, index)
+
+	_, ok = accessNodeIndex(chr, "wasp6:4000")
+	require.False(t, ok)
+}
+
+func TestLoadKeySharesDealtFrost(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	priv := suite.Scalar().Pick(suite.RandomStream())
+	pub := suite.Point().Mul(priv, nil)
+	dkShare, err := tcrypto.NewDKShare(0, 1, 1, pub, []kyber.Point{pub}, []kyber.Point{pub}, priv)
+	require.NoError(t, err)
+	frostShares, err := tcrypto.DealFrostShares(1, 1, dkShare.Address)
+	require.NoError(t, err)
+
+	provider := testutil.NewDkgRegistryProvider(suite)
+	require.NoError(t, provider.SaveDKShare(dkShare))
+	require.NoError(t, provider.SaveFrostShare(frostShares[0]))
+
+	// the BLS chain doesn't depend on the flag
+	_, _, err = loadKeyShares(dkShare.Address, provider, false)
+	require.NoError(t, err)
+
+	// the key of the FROST chain is known to the dealer, so the chain is run only if allowed explicitly
+	_, _, err = loadKeyShares(frostShares[0].Address, provider, false)
+	require.Error(t, err)
+	loaded, signer, err := loadKeyShares(frostShares[0].Address, provider, true)
+	require.NoError(t, err)
+	require.EqualValues(t, *dkShare.Address, *loaded.Address)
+	require.EqualValues(t, *frostShares[0].Address, *signer.Address())
+}
@@ -14,6 +14,7 @@ func (c *chainObj) dispatchMessage(msg interface{}) {
 	if !c.isOpenQueue.Load() {
 		return
 	}
+	c.recordInput(msg)
 
 	switch msgt := msg.(type) {
 
@@ -43,8 +44,8 @@ func (c *chainObj) dispatchMessage(msg interface{}) {
 		}
 
 	case *chain.RequestMsg:
-		// receive request message
-		if c.operator != nil {
+		// receive request message. New requests are not accepted while draining
+		if c.operator != nil && !c.draining.Load() {
 			c.operator.EventRequestMsg(msgt)
 		}
 
@@ -53,6 +54,11 @@ func (c *chainObj) dispatchMessage(msg interface{}) {
 			c.operator.EventBalancesMsg(msgt)
 		}
 
+	case *chain.EmergencyMsg:
+		if c.operator != nil {
+			c.operator.EventEmergencyMsg(msgt)
+		}
+
 	case *chain.VMResultMsg:
 		// VM finished working
 		if c.operator != nil {
@@ -60,6 +66,9 @@ func (c *chainObj) dispatchMessage(msg interface{}) {
 		}
 
 	case chain.TimerTick:
+		if int(msgt)%int(chain.HeartbeatPeriod/chain.TimerTickPeriod) == 0 {
+			c.sendHeartbeat()
+		}
 
 		if msgt%2 == 0 {
 			if c.stateMgr != nil {
@@ -73,12 +82,30 @@ func (c *chainObj) dispatchMessage(msg interface{}) {
 	}
 }
 
+// accessPeerMsgTypes are types of messages accepted from access nodes of the chain
+var accessPeerMsgTypes = map[byte]bool{
+	chain.MsgHeartbeat:          true,
+	chain.MsgStateIndexPingPong: true,
+	chain.MsgGetBatch:           true,
+}
+
 func (c *chainObj) processPeerMessage(msg *peering.PeerMessage) {
+	// any message is the evidence the peer is alive
+	c.health.heard(msg.SenderIndex)
+
+	if c.isAccessPeer(msg.SenderIndex) && !accessPeerMsgTypes[msg.MsgType] {
+		// access nodes don't take part in the consensus, they only fetch blocks
+		c.log.Debugf("processPeerMessage: dropped message of type %d from the access node #%d", msg.MsgType, msg.SenderIndex)
+		return
+	}
 
 	rdr := bytes.NewReader(msg.MsgData)
 
 	switch msg.MsgType {
 
+	case chain.MsgHeartbeat:
+		// nothing to process
+
 	case chain.MsgStateIndexPingPong:
 		msgt := &chain.StateIndexPingPongMsg{}
 		if err := msgt.Read(rdr); err != nil {
@@ -148,6 +175,76 @@ func (c *chainObj) processPeerMessage(msg *peering.PeerMessage) {
 			c.operator.EventSignedHashMsg(msgt)
 		}
 
+	case chain.MsgProposalDigest:
+		msgt := &chain.ProposalDigestMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		c.stateMgr.EvidenceStateIndex(msgt.BlockIndex)
+
+		msgt.SenderIndex = msg.SenderIndex
+
+		if c.operator != nil {
+			c.operator.EventProposalDigestMsg(msgt)
+		}
+
+	case chain.MsgEmergencyVote:
+		msgt := &chain.EmergencyVoteMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		c.stateMgr.EvidenceStateIndex(msgt.BlockIndex)
+
+		msgt.SenderIndex = msg.SenderIndex
+
+		if c.operator != nil {
+			c.operator.EventEmergencyVoteMsg(msgt)
+		}
+
+	case chain.MsgFairOrderShare:
+		msgt := &chain.FairOrderShareMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		c.stateMgr.EvidenceStateIndex(msgt.BlockIndex)
+
+		msgt.SenderIndex = msg.SenderIndex
+
+		if c.operator != nil {
+			c.operator.EventFairOrderShareMsg(msgt)
+		}
+
+	case chain.MsgVRFShare:
+		msgt := &chain.VRFShareMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		c.stateMgr.EvidenceStateIndex(msgt.BlockIndex)
+
+		msgt.SenderIndex = msg.SenderIndex
+
+		if c.operator != nil {
+			c.operator.EventVRFShareMsg(msgt)
+		}
+
+	case chain.MsgSigningCommitment:
+		msgt := &chain.SigningCommitmentMsg{}
+		if err := msgt.Read(rdr); err != nil {
+			c.log.Error(err)
+			return
+		}
+		c.stateMgr.EvidenceStateIndex(msgt.BlockIndex)
+
+		msgt.SenderIndex = msg.SenderIndex
+
+		if c.operator != nil {
+			c.operator.EventSigningCommitmentMsg(msgt)
+		}
+
 	case chain.MsgGetBatch:
 		msgt := &chain.GetBlockMsg{}
 		if err := msgt.Read(rdr); err != nil {
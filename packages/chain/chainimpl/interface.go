@@ -11,6 +11,7 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/hive.go/events"
 	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/chain/transcript"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/peering"
 	"github.com/iotaledger/wasp/packages/publisher"
@@ -129,7 +130,10 @@ func (c *chainObj) Dismiss() {
 		c.peers.Close()
 
 		c.stateMgr.Close()
-		c.operator.Close()
+		if c.operator != nil {
+			c.operator.Close()
+		}
+		c.closeTranscript()
 	})
 
 	publisher.Publish("dismissed_committee", c.chainID.String())
@@ -139,6 +143,56 @@ func (c *chainObj) IsDismissed() bool {
 	return c.dismissed.Load()
 }
 
+func (c *chainObj) Drain() <-chan struct{} {
+	c.drainOnce.Do(func() {
+		c.log.Infof("Drain committee for %s", c.chainID.String())
+		c.draining.Store(true)
+
+		go func() {
+			deadline := time.Now().Add(chain.DrainTimeout)
+			for !c.IsDismissed() {
+				info := c.ConsensusInfo()
+				if info == nil || !info.RoundInProgress {
+					break
+				}
+				if time.Now().After(deadline) {
+					c.log.Warnf("drain timeout: the round in progress is abandoned")
+					break
+				}
+				time.Sleep(chain.TimerTickPeriod)
+			}
+			c.Dismiss()
+			close(c.drained)
+		}()
+	})
+	return c.drained
+}
+
+func (c *chainObj) IsDraining() bool {
+	return c.draining.Load()
+}
+
+func (c *chainObj) DeclareEmergency(delegate uint16, numBlocks uint32) error {
+	if c.IsDismissed() {
+		return fmt.Errorf("chain %s is not active", c.chainID.String())
+	}
+	if c.operator == nil {
+		return fmt.Errorf("node is not in the committee of the chain %s", c.chainID.String())
+	}
+	if delegate >= c.size {
+		return fmt.Errorf("wrong delegate index %d, committee size is %d", delegate, c.size)
+	}
+	if numBlocks == 0 || numBlocks > chain.MaxEmergencyBlocks {
+		return fmt.Errorf("number of blocks must be from 1 to %d", chain.MaxEmergencyBlocks)
+	}
+	c.log.Warnf("EMERGENCY declared by the owner of the node: delegate #%d, blocks: %d", delegate, numBlocks)
+	c.ReceiveMessage(&chain.EmergencyMsg{
+		Delegate:  delegate,
+		NumBlocks: numBlocks,
+	})
+	return nil
+}
+
 func (c *chainObj) ID() *coretypes.ChainID {
 	return &c.chainID
 }
@@ -175,14 +229,19 @@ func (c *chainObj) ReceiveMessage(msg interface{}) {
 
 // SendMsg sends message to peer by index. It can be both committee peer or access peer.
 // TODO: [KP] Maybe we can use a broadcast instead of this?
+// Returns an error without sending if the peer is known to be dead.
 func (c *chainObj) SendMsg(targetPeerIndex uint16, msgType byte, msgData []byte) error {
 	if peer, ok := c.peers.OtherNodes()[targetPeerIndex]; ok {
+		if c.isPeerDead(targetPeerIndex, peer) {
+			return fmt.Errorf("SendMsg: peer #%d is dead", targetPeerIndex)
+		}
 		peer.SendMsg(&peering.PeerMessage{
 			ChainID:     c.chainID,
 			SenderIndex: c.ownIndex,
 			MsgType:     msgType,
 			MsgData:     msgData,
 		})
+		c.recordOutput(transcript.KindPeerOut, targetPeerIndex, msgType, msgData, 0)
 		return nil
 	}
 	return fmt.Errorf("SendMsg: wrong peer index")
@@ -196,8 +255,17 @@ func (c *chainObj) SendMsgToCommitteePeers(msgType byte, msgData []byte, ts int6
 		MsgType:     msgType,
 		MsgData:     msgData,
 	}
-	c.peers.Broadcast(msg, false)
-	return uint16(len(c.peers.OtherNodes())) // TODO: [KP] Reconsider this, we cannot guaranty if they are actually sent.
+	// peers known to be dead are skipped rather than waiting for the message to time out
+	numSent := uint16(0)
+	for i, peer := range c.committeePeers() {
+		if i == c.ownIndex || c.isPeerDead(i, peer) {
+			continue
+		}
+		peer.SendMsg(msg)
+		numSent++
+	}
+	c.recordOutput(transcript.KindBroadcast, c.ownIndex, msgType, msgData, ts)
+	return numSent // TODO: [KP] Reconsider this, we cannot guaranty if they are actually sent.
 }
 
 // sends message to the peer seq[seqIndex]. If receives error, seqIndex = (seqIndex+1) % size and repeats
@@ -221,7 +289,7 @@ func (c *chainObj) SendMsgInSequence(msgType byte, msgData []byte, seqIndex uint
 	return 0, fmt.Errorf("failed to send")
 }
 
-// returns true if peer is alive. Used by the operator to determine current leader
+// returns true if peer is connected and heard recently. Used by the operator to determine current leader
 func (c *chainObj) IsAlivePeer(peerIndex uint16) bool {
 	allNodes := c.peers.AllNodes()
 	if int(peerIndex) >= len(allNodes) {
@@ -233,7 +301,7 @@ func (c *chainObj) IsAlivePeer(peerIndex uint16) bool {
 	if allNodes[peerIndex] == nil {
 		c.log.Panicf("c.peers[peerIndex] == nil. peerIndex: %d, ownIndex: %d", peerIndex, c.ownIndex)
 	}
-	return allNodes[peerIndex].IsAlive()
+	return !c.isPeerDead(peerIndex, allNodes[peerIndex])
 }
 
 func (c *chainObj) OwnPeerIndex() uint16 {
@@ -246,7 +314,18 @@ func (c *chainObj) NumPeers() uint16 {
 
 // first N peers are committee peers, the rest are access peers in any
 func (c *chainObj) committeePeers() map[uint16]peering.PeerSender {
-	return c.peers.AllNodes()
+	ret := make(map[uint16]peering.PeerSender)
+	for i, peer := range c.peers.AllNodes() {
+		if i < c.size {
+			ret[i] = peer
+		}
+	}
+	return ret
+}
+
+// isAccessPeer returns true if the peer with the index is an access node of the chain
+func (c *chainObj) isAccessPeer(peerIndex uint16) bool {
+	return peerIndex >= c.size
 }
 
 func (c *chainObj) HasQuorum() bool {
@@ -276,16 +355,29 @@ func (c *chainObj) PeerStatus() []*chain.PeerStatus {
 		if status.IsSelf {
 			status.PeeringID = c.netProvider.Self().NetID()
 			status.Connected = true
+			status.Alive = true
 		} else {
 			status.PeeringID = peer.NetID()
 			status.Connected = peer.IsAlive()
+			status.Alive = !c.isPeerDead(i, peer)
+			status.LastHeard = c.health.lastHeardFrom(i)
 		}
 		ret = append(ret, status)
 	}
 	return ret
 }
 
+func (c *chainObj) ConsensusInfo() *chain.ConsensusInfo {
+	if !c.isCommitteeNode.Load() || c.IsDismissed() || c.operator == nil {
+		return nil
+	}
+	return c.operator.ConsensusInfo()
+}
+
 func (c *chainObj) BlobCache() coretypes.BlobCache {
+	if c.transcript != nil {
+		return &recordingBlobCache{BlobCache: c.blobProvider, chain: c}
+	}
 	return c.blobProvider
 }
 
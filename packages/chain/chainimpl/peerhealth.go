@@ -0,0 +1,76 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package chainimpl
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/peering"
+)
+
+// peerHealth tracks liveness of committee peers. Any message received from the peer, including
+// heartbeats, is the evidence the peer is alive. Until the first message is received, the peer
+// is given PeerDeadTimeout from the start of tracking
+type peerHealth struct {
+	mutex     sync.RWMutex
+	now       func() time.Time
+	started   time.Time
+	lastHeard map[uint16]time.Time
+}
+
+func newPeerHealth() *peerHealth {
+	return newPeerHealthWithClock(time.Now)
+}
+
+func newPeerHealthWithClock(now func() time.Time) *peerHealth {
+	return &peerHealth{
+		now:       now,
+		started:   now(),
+		lastHeard: make(map[uint16]time.Time),
+	}
+}
+
+func (h *peerHealth) heard(peerIndex uint16) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.lastHeard[peerIndex] = h.now()
+}
+
+// lastHeardFrom returns the time of the last message from the peer, zero if nothing was received
+func (h *peerHealth) lastHeardFrom(peerIndex uint16) time.Time {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return h.lastHeard[peerIndex]
+}
+
+func (h *peerHealth) isDead(peerIndex uint16) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	last, ok := h.lastHeard[peerIndex]
+	if !ok {
+		last = h.started
+	}
+	return h.now().Sub(last) > chain.PeerDeadTimeout
+}
+
+// isPeerDead returns true if there is no connection with the peer or nothing was heard from it for some time
+func (c *chainObj) isPeerDead(peerIndex uint16, peer peering.PeerSender) bool {
+	if peerIndex == c.ownIndex {
+		return false
+	}
+	return !peer.IsAlive() || c.health.isDead(peerIndex)
+}
+
+// sendHeartbeat sends the heartbeat to all other committee peers, dead ones included,
+// so they learn this node is alive
+func (c *chainObj) sendHeartbeat() {
+	c.peers.Broadcast(&peering.PeerMessage{
+		ChainID:     c.chainID,
+		SenderIndex: c.ownIndex,
+		Timestamp:   time.Now().UnixNano(),
+		MsgType:     chain.MsgHeartbeat,
+	}, false)
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package chainimpl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/stretchr/testify/require"
+)
+
+type testClock struct {
+	t time.Time
+}
+
+func (c *testClock) now() time.Time {
+	return c.t
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func TestPeerHealthGracePeriod(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	h := newPeerHealthWithClock(clock.now)
+
+	// the peer never heard from is alive until the timeout passes from the start of tracking
+	require.False(t, h.isDead(1))
+	require.True(t, h.lastHeardFrom(1).IsZero())
+	clock.advance(chain.PeerDeadTimeout)
+	require.False(t, h.isDead(1))
+	clock.advance(time.Millisecond)
+	require.True(t, h.isDead(1))
+}
+
+func TestPeerHealthRecovery(t *testing.T) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	h := newPeerHealthWithClock(clock.now)
+
+	clock.advance(2 * chain.PeerDeadTimeout)
+	require.True(t, h.isDead(1))
+	require.True(t, h.isDead(2))
+
+	// any message from the dead peer brings it back
+	h.heard(1)
+	require.False(t, h.isDead(1))
+	require.Equal(t, clock.now(), h.lastHeardFrom(1))
+	require.True(t, h.isDead(2))
+
+	clock.advance(chain.PeerDeadTimeout + time.Millisecond)
+	require.True(t, h.isDead(1))
+}
+
+type testPeer struct {
+	peering.PeerSender
+	alive bool
+	sent  []*peering.PeerMessage
+}
+
+func (p *testPeer) IsAlive() bool {
+	return p.alive
+}
+
+func (p *testPeer) SendMsg(msg *peering.PeerMessage) {
+	p.sent = append(p.sent, msg)
+}
+
+type testGroup struct {
+	peering.GroupProvider
+	nodes map[uint16]peering.PeerSender
+}
+
+func (g *testGroup) AllNodes() map[uint16]peering.PeerSender {
+	return g.nodes
+}
+
+func (g *testGroup) OtherNodes() map[uint16]peering.PeerSender {
+	ret := make(map[uint16]peering.PeerSender)
+	for i, p := range g.nodes {
+		if i != 0 {
+			ret[i] = p
+		}
+	}
+	return ret
+}
+
+// newTestChain returns the chain of the node #0 with 4 committee peers. Peers #1 and #2 are heard recently,
+// peer #2 is disconnected, peer #3 is silent beyond the timeout
+func newTestChain() (*chainObj, []*testPeer) {
+	clock := &testClock{t: time.Unix(1000, 0)}
+	peers := make([]*testPeer, 4)
+	nodes := make(map[uint16]peering.PeerSender)
+	for i := range peers {
+		peers[i] = &testPeer{alive: i != 2}
+		nodes[uint16(i)] = peers[i]
+	}
+	c := &chainObj{
+		size:     4,
+		ownIndex: 0,
+		peers:    &testGroup{nodes: nodes},
+		health:   newPeerHealthWithClock(clock.now),
+	}
+	clock.advance(chain.PeerDeadTimeout + time.Millisecond)
+	c.health.heard(1)
+	c.health.heard(2)
+	return c, peers
+}
+
+func TestSendsSkipDeadPeers(t *testing.T) {
+	c, peers := newTestChain()
+
+	require.True(t, c.IsAlivePeer(0))
+	require.True(t, c.IsAlivePeer(1))
+	require.False(t, c.IsAlivePeer(2))
+	require.False(t, c.IsAlivePeer(3))
+
+	require.NoError(t, c.SendMsg(1, chain.MsgSignedHash, []byte{1}))
+	require.Error(t, c.SendMsg(2, chain.MsgSignedHash, []byte{1}))
+	require.Error(t, c.SendMsg(3, chain.MsgSignedHash, []byte{1}))
+	require.Len(t, peers[1].sent, 1)
+	require.Empty(t, peers[2].sent)
+	require.Empty(t, peers[3].sent)
+
+	require.EqualValues(t, 1, c.SendMsgToCommitteePeers(chain.MsgSignedHash, []byte{2}, 0))
+	require.Len(t, peers[1].sent, 2)
+	require.Empty(t, peers[2].sent)
+	require.Empty(t, peers[3].sent)
+
+	// the message goes to the first alive peer of the sequence
+	idx, err := c.SendMsgInSequence(chain.MsgSignedHash, []byte{3}, 2, []uint16{0, 3, 2, 1})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, idx)
+	require.Len(t, peers[1].sent, 3)
+
+	// the silent peer is back as soon as anything is heard from it
+	c.health.heard(3)
+	require.True(t, c.IsAlivePeer(3))
+	require.NoError(t, c.SendMsg(3, chain.MsgSignedHash, []byte{4}))
+	require.Len(t, peers[3].sent, 1)
+}
@@ -0,0 +1,211 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package chainimpl
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/chain/transcript"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+)
+
+// The file contains recording of the transcript of committee messages of the node, see package transcript.
+// Inputs are recorded when they are dispatched, outputs when they are sent to peers
+
+type transcriptRecorder struct {
+	*transcript.Recorder
+	// requests known from the transcript and not completed yet. Accessed from the dispatcher only
+	pending map[coretypes.RequestID]bool
+	// blobs already recorded
+	blobsMutex sync.Mutex
+	blobs      map[hashing.HashValue]bool
+}
+
+// startTranscript starts recording of the transcript if the node runs in the test cluster, see transcript.DirEnvVar
+func (c *chainObj) startTranscript(dkshare *tcrypto.DKShare) {
+	dir := os.Getenv(transcript.DirEnvVar)
+	if dir == "" {
+		return
+	}
+	dkshareBytes, err := dkshare.Bytes()
+	if err != nil {
+		c.log.Errorf("can't record the transcript: %v", err)
+		return
+	}
+	fname := transcript.NewFileName(dir, &c.chainID, c.ownIndex)
+	rec, err := transcript.NewRecorder(fname, &transcript.Header{
+		ChainID:         c.chainID,
+		Color:           c.color,
+		OwnIndex:        c.ownIndex,
+		Size:            c.size,
+		Quorum:          c.quorum,
+		DKShare:         dkshareBytes,
+		BatchTimeBudget: time.Duration(parameters.GetInt(parameters.ConsensusBatchTimeBudget)) * time.Millisecond,
+		MaxBatchSize:    uint16(parameters.GetInt(parameters.ConsensusMaxBatchSize)),
+		FairOrdering:    parameters.GetBool(parameters.ConsensusFairOrdering),
+		EmergencyQuorum: uint16(parameters.GetInt(parameters.ConsensusEmergencyQuorum)),
+		Pipelining:      parameters.GetBool(parameters.ConsensusPipelining),
+		MaxRequestAge:   uint32(parameters.GetInt(parameters.ConsensusMaxRequestAge)),
+	})
+	if err != nil {
+		c.log.Errorf("can't record the transcript: %v", err)
+		return
+	}
+	c.log.Warnf("recording the transcript of committee messages into %s. The transcript contains the private key share of the node", fname)
+	c.transcript = &transcriptRecorder{
+		Recorder: rec,
+		pending:  make(map[coretypes.RequestID]bool),
+		blobs:    make(map[hashing.HashValue]bool),
+	}
+}
+
+func (c *chainObj) closeTranscript() {
+	if c.transcript == nil {
+		return
+	}
+	if err := c.transcript.Err(); err != nil {
+		c.log.Errorf("recording of the transcript failed: %v", err)
+	}
+	if err := c.transcript.Close(); err != nil {
+		c.log.Errorf("closing the transcript: %v", err)
+	}
+}
+
+// recordInput records the message dispatched to the state manager or to the consensus operator
+func (c *chainObj) recordInput(msg interface{}) {
+	if c.transcript == nil {
+		return
+	}
+	rec := &transcript.Record{Timestamp: time.Now().UnixNano()}
+
+	switch msgt := msg.(type) {
+	case *peering.PeerMessage:
+		rec.Kind = transcript.KindPeerIn
+		rec.Peer = msgt.SenderIndex
+		rec.MsgType = msgt.MsgType
+		rec.MsgTimestamp = msgt.Timestamp
+		rec.Data = msgt.MsgData
+		if msgt.MsgType == chain.MsgNotifyRequests {
+			notify := &chain.NotifyReqMsg{}
+			if err := notify.Read(bytes.NewReader(msgt.MsgData)); err == nil {
+				for _, reqid := range notify.RequestIDs {
+					c.transcript.pending[reqid] = true
+				}
+			}
+		}
+
+	case *chain.RequestMsg:
+		if c.draining.Load() {
+			// not passed to the operator
+			return
+		}
+		completed, err := state.IsRequestCompleted(&c.chainID, msgt.RequestId())
+		if err != nil {
+			c.log.Errorf("recordInput: %v", err)
+		}
+		if !completed {
+			c.transcript.pending[*msgt.RequestId()] = true
+		}
+		rec.Kind = transcript.KindRequest
+		rec.Data = transcript.RequestData(msgt, completed)
+
+	case chain.BalancesMsg:
+		rec.Kind = transcript.KindBalances
+		rec.Data = transcript.BalancesData(msgt)
+
+	case *chain.TransactionInclusionLevelMsg:
+		rec.Kind = transcript.KindInclusionLevel
+		rec.Data = transcript.InclusionLevelData(msgt)
+
+	case *chain.StateTransitionMsg:
+		// the state is committed by the state manager before the transition
+		completed := make([]coretypes.RequestID, 0)
+		for reqid := range c.transcript.pending {
+			if ok, err := state.IsRequestCompleted(&c.chainID, &reqid); err == nil && ok {
+				completed = append(completed, reqid)
+				delete(c.transcript.pending, reqid)
+			}
+		}
+		data, err := transcript.StateTransitionData(msgt, completed)
+		if err != nil {
+			c.log.Errorf("recordInput: %v", err)
+			return
+		}
+		rec.Kind = transcript.KindStateTransition
+		rec.Data = data
+
+	case *chain.EmergencyMsg:
+		rec.Kind = transcript.KindEmergency
+		rec.Data = transcript.EmergencyData(msgt)
+
+	case *chain.VMResultMsg:
+		rec.Kind = transcript.KindVMResult
+		rec.Data = transcript.VMResultData(msgt)
+
+	case chain.TimerTick:
+		if msgt%2 == 0 {
+			// ticks of the state manager
+			return
+		}
+		rec.Kind = transcript.KindTimerTick
+		rec.Data = transcript.TimerTickData(msgt / 2)
+
+	default:
+		// messages to the state manager from the node itself
+		return
+	}
+	rec.HasQuorum = c.HasQuorum()
+	rec.SetAlive(c.size, c.IsAlivePeer)
+	c.transcript.Record(rec)
+}
+
+// recordOutput records the message sent to the peer or, with KindBroadcast, to all committee peers
+func (c *chainObj) recordOutput(kind byte, targetPeerIndex uint16, msgType byte, msgData []byte, ts int64) {
+	if c.transcript == nil {
+		return
+	}
+	c.transcript.Record(&transcript.Record{
+		Kind:         kind,
+		Timestamp:    time.Now().UnixNano(),
+		Peer:         targetPeerIndex,
+		MsgType:      msgType,
+		MsgTimestamp: ts,
+		Data:         msgData,
+	})
+}
+
+// recordingBlobCache records blobs used by the node into the transcript
+type recordingBlobCache struct {
+	coretypes.BlobCache
+	chain *chainObj
+}
+
+func (b *recordingBlobCache) GetBlob(h hashing.HashValue) ([]byte, bool, error) {
+	data, ok, err := b.BlobCache.GetBlob(h)
+	if err != nil || !ok {
+		return data, ok, err
+	}
+	t := b.chain.transcript
+	t.blobsMutex.Lock()
+	recorded := t.blobs[h]
+	t.blobs[h] = true
+	t.blobsMutex.Unlock()
+	if !recorded {
+		t.Record(&transcript.Record{
+			Kind:      transcript.KindBlob,
+			Timestamp: time.Now().UnixNano(),
+			Data:      data,
+		})
+	}
+	return data, ok, err
+}
@@ -7,11 +7,11 @@ import (
 	"time"
 
 	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm"
-	"github.com/iotaledger/wasp/plugins/nodeconn"
 )
 
 // takeAction analyzes the state and updates it and takes action such as sending of message,
@@ -20,15 +20,19 @@ import (
 func (op *operator) takeAction() {
 	op.solidifyRequestArgsIfNeeded()
 	op.sendRequestNotificationsToLeader()
+	op.sendSigningCommitmentToLeader()
 	op.startCalculationsAsLeader()
 	op.checkQuorum()
+	op.resendResultToLeader()
 	op.rotateLeader()
 	op.pullInclusionLevel()
+	op.checkTentativeState()
+	op.requestBalancesIfNeeded()
 }
 
 // solidifyRequestArgsIfNeeded runs through all requests and, if needed, attempts to solidify args
 func (op *operator) solidifyRequestArgsIfNeeded() {
-	if time.Now().Before(op.nextArgSolidificationDeadline) {
+	if op.env.now().Before(op.nextArgSolidificationDeadline) {
 		return
 	}
 	reqs := op.allRequests()
@@ -46,7 +50,7 @@ func (op *operator) solidifyRequestArgsIfNeeded() {
 			}
 		}
 	}
-	op.nextArgSolidificationDeadline = time.Now().Add(chain.CheckArgSolidificationEvery)
+	op.nextArgSolidificationDeadline = op.env.now().Add(chain.CheckArgSolidificationEvery)
 }
 
 // pullInclusionLevel if it is known that result transaction was posted by the leader,
@@ -57,15 +61,25 @@ func (op *operator) pullInclusionLevel() {
 	if op.postedResultTxid == nil {
 		return
 	}
-	if time.Now().After(op.nextPullInclusionLevel) {
+	if op.env.now().After(op.nextPullInclusionLevel) {
 		addr := op.chain.Address()
-		if err := nodeconn.RequestInclusionLevelFromNode(op.postedResultTxid, &addr); err != nil {
+		if err := op.env.requestInclusionLevel(op.postedResultTxid, &addr); err != nil {
 			op.log.Errorf("RequestInclusionLevelFromNode: %v", err)
 		}
 		op.setNextPullInclusionStageDeadline()
 	}
 }
 
+// requestBalancesIfNeeded queries balances of the chain address if they didn't come from the goshimmer
+// for some time. Queries of all chains of the node are batched by the node connection
+func (op *operator) requestBalancesIfNeeded() {
+	if op.env.now().Before(op.requestBalancesDeadline) {
+		return
+	}
+	op.env.requestBalances(op.chain.Address())
+	op.requestBalancesDeadline = op.env.now().Add(chain.RequestBalancesPeriod)
+}
+
 // rotateLeader upon expired deadline. The deadline depends on the stage
 func (op *operator) rotateLeader() {
 	if !op.consensusStageDeadlineExpired() {
@@ -75,16 +89,30 @@ func (op *operator) rotateLeader() {
 		op.log.Debugf("leader was not rotated due to no quorum")
 		return
 	}
+	if op.isEmergency() {
+		// the delegate is not rotated, it starts the round from scratch
+		op.log.Warnf("EMERGENCY: round of the delegate #%d timed out. Restarting", op.emergency.delegate)
+		op.startLeaderTerm()
+		return
+	}
 	prevlead, _ := op.currentLeader()
 	leader := op.moveToNextLeader()
 
-	// starting from scratch with the new leader
+	op.log.Infof("LEADER ROTATED #%d --> #%d, I am the leader = %v",
+		prevlead, leader, op.iAmCurrentLeader())
+
+	op.startLeaderTerm()
+}
+
+// startLeaderTerm starts from scratch with the current leader
+func (op *operator) startLeaderTerm() {
 	op.leaderStatus = nil
 	op.sentResultToLeader = nil
+	op.sentResultBlock = nil
+	op.sentResultToLeaderMsg = nil
 	op.postedResultTxid = nil
-
-	op.log.Infof("LEADER ROTATED #%d --> #%d, I am the leader = %v",
-		prevlead, leader, op.iAmCurrentLeader())
+	op.finalizedRound = nil
+	op.discardRound()
 
 	// the consensus stage will become one of two, depending is iAmLeader or not
 	if op.iAmCurrentLeader() {
@@ -105,10 +133,45 @@ func (op *operator) startCalculationsAsLeader() {
 		// no quorum, doesn't make sense to start
 		return
 	}
+	// determine timestamp. Must be max(local clock, prev timestamp+1).
+	// Adjustment enforced, when needed. Once the seed of the block is signed, the timestamp is fixed
+	ts := op.batchTimestamp(op.env.batchTimestamp())
+	prevTs := op.stateTx.MustState().Timestamp()
+	if ts <= prevTs {
+		op.log.Warnf("local clock is not ahead the timestamp of the previous state. prevTs: %d, currentTs: %d, diff: %d ns",
+			prevTs, ts, prevTs-ts)
+		ts = prevTs + 1
+		op.log.Info("timestamp was adjusted to %d", ts)
+	}
+	canStart, canBeEmpty := op.batchTiming(ts)
+	if !canStart {
+		// the minimum block interval has not passed yet
+		return
+	}
+	commitments, ok := op.signingSet()
+	if !ok {
+		// waiting for nonce commitments of the quorum, see signing.go
+		return
+	}
 	// select requests for the batch
-	reqs := op.selectRequestsToProcess()
+	var reqs []*request
+	var orderSig []byte
+	if op.fairOrdering {
+		reqs, orderSig = op.fairOrderedBatch()
+	} else {
+		reqs = op.selectRequestsToProcess()
+	}
 	if len(reqs) == 0 {
-		// empty backlog or nothing is ready
+		// empty backlog or nothing is ready. After the maximum block interval the empty batch is started,
+		// unless the set of requests is already fixed in the fair ordering mode
+		if !canBeEmpty || op.fairOrder != nil {
+			return
+		}
+		op.log.Infof("no requests to process for %v. Starting the empty batch", time.Duration(ts-prevTs))
+	}
+	vrf, ok := op.batchVRF(ts)
+	if !ok {
+		// waiting for signature shares of the seed of the block, see vrf.go
 		return
 	}
 	reqIds := takeIds(reqs)
@@ -118,26 +181,25 @@ func (op *operator) startCalculationsAsLeader() {
 	rewardAddress := op.getFeeDestination()
 
 	// send to subordinated peers requests to process the batch
-	msgData := util.MustBytes(&chain.StartProcessingBatchMsg{
+	msg := &chain.StartProcessingBatchMsg{
 		PeerMsgHeader: chain.PeerMsgHeader{
 			// timestamp is set by SendMsgToCommitteePeers
-			BlockIndex: op.stateTx.MustState().BlockIndex(),
+			BlockIndex:  op.stateTx.MustState().BlockIndex(),
+			SenderIndex: op.peerIndex(),
 		},
-		FeeDestination: rewardAddress,
-		Balances:       op.balances,
-		RequestIds:     reqIds,
-	})
-
-	// determine timestamp. Must be max(local clock, prev timestamp+1).
-	// Adjustment enforced, when needed
-	ts := time.Now().UnixNano()
-	prevTs := op.stateTx.MustState().Timestamp()
-	if ts <= prevTs {
-		op.log.Warnf("local clock is not ahead the timestamp of the previous state. prevTs: %d, currentTs: %d, diff: %d ns",
-			prevTs, ts, prevTs-ts)
-		ts = prevTs + 1
-		op.log.Info("timestamp was adjusted to %d", ts)
+		Timestamp:          ts,
+		FeeDestination:     rewardAddress,
+		Balances:           op.balances,
+		RequestIds:         reqIds,
+		OrderSig:           orderSig,
+		SigningCommitments: commitments,
+		VRFProof:           vrf.Proof,
 	}
+	if err := op.signProposal(msg); err != nil {
+		op.log.Errorf("failed to sign the batch proposal: %v", err)
+		return
+	}
+	msgData := util.MustBytes(msg)
 
 	numSucc := op.chain.SendMsgToCommitteePeers(chain.MsgStartProcessingRequest, msgData, ts)
 
@@ -157,6 +219,7 @@ func (op *operator) startCalculationsAsLeader() {
 		balances:      op.balances,
 		timestamp:     ts,
 		signedResults: make([]*signedResult, op.chain.Size()),
+		commitments:   commitments,
 	}
 	op.log.Debugw("runCalculationsAsync leader",
 		"batch hash", batchHash.String(),
@@ -170,6 +233,7 @@ func (op *operator) startCalculationsAsLeader() {
 		balances:        op.balances,
 		timestamp:       ts,
 		accrueFeesTo:    rewardAddress,
+		vrf:             vrf,
 	})
 	// the LeaderCalculationsStarted stage means at least a quorum of async
 	// calculation tasks has been started: locally and on peers
@@ -204,7 +268,7 @@ func (op *operator) checkQuorum() {
 			op.leaderStatus.signedResults[i] = nil // ignoring
 			continue
 		}
-		err := op.dkshare.VerifySigShare(op.leaderStatus.resultTx.EssenceBytes(), op.leaderStatus.signedResults[i].sigShare)
+		err := op.signer.VerifySigShare(op.leaderStatus.resultTx.EssenceBytes(), op.leaderStatus.signedResults[i].sigShare, op.leaderStatus.commitments)
 		if err != nil {
 			// TODO here we are ignoring wrong signatures. In general, it means it is an attack
 			// In the future when each message will be signed by the peer's identity, the invalidity
@@ -251,12 +315,13 @@ func (op *operator) checkQuorum() {
 
 	// posting finalized transaction to goshimmer
 	addr := op.chain.Address()
-	err = nodeconn.PostTransactionToNode(op.leaderStatus.resultTx.Transaction, &addr, op.chain.OwnPeerIndex())
+	err = op.env.postTransaction(op.leaderStatus.resultTx.Transaction, &addr, op.chain.OwnPeerIndex())
 	if err != nil {
 		op.log.Warnf("PostTransactionToNode failed: %v", err)
 		return
 	}
 	op.log.Debugf("result transaction has been posted to node. txid: %s", txid.String())
+	op.reportAnchorFee(op.leaderStatus.resultTx, stateIndex, op.leaderStatus.batch.Size())
 
 	// notify peers about finalization of the transaction
 	msgData := util.MustBytes(&chain.NotifyFinalResultPostedMsg{
@@ -267,13 +332,12 @@ func (op *operator) checkQuorum() {
 		TxId: txid,
 	})
 
-	numSent := op.chain.SendMsgToCommitteePeers(chain.MsgNotifyFinalResultPosted, msgData, time.Now().UnixNano())
+	numSent := op.chain.SendMsgToCommitteePeers(chain.MsgNotifyFinalResultPosted, msgData, op.env.now().UnixNano())
 	op.log.Debugf("%d peers has been notified about finalized result", numSent)
 
 	op.setNextConsensusStage(consensusStageLeaderResultFinalized)
 	op.setFinalizedTransaction(&txid)
-
-	return
+	op.setFinalizedRound(op.leaderStatus.resultTx, txid, op.leaderStatus.batch)
 }
 
 // sets new currentState transaction and initializes respective variables
@@ -281,8 +345,19 @@ func (op *operator) setNewSCState(stateTx *sctransaction.Transaction, variableSt
 	op.stateTx = stateTx
 	op.currentState = variableState
 	op.sentResultToLeader = nil
+	op.sentResultBlock = nil
+	op.sentResultToLeaderMsg = nil
 	op.postedResultTxid = nil
-	op.requestBalancesDeadline = time.Now()
-	op.resetLeader(stateTx.ID().Bytes())
+	op.finalizedRound = nil
+	op.discardRound()
+	op.requestBalancesDeadline = op.env.now()
+	op.resetLeader(op.stateTxID().Bytes())
+	op.checkEmergencyEnd()
+	op.ownProposalDigests = make(map[uint16]*chain.ProposalDigestMsg)
+	op.peerProposalDigests = make(map[uint16][]*chain.ProposalDigestMsg)
+	op.fairOrder = nil
+	op.fairOrderSigned = make(map[uint16]hashing.HashValue)
+	op.vrf = nil
+	op.vrfSigned = make(map[uint16]int64)
 	op.adjustNotifications()
 }
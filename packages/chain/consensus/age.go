@@ -0,0 +1,118 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Liveness guarantee of the request processing.
+// The leader selects requests by the arrival time and by notifications of peers, so a request may be passed over
+// again and again, for example, when it isn't seen by the same quorum of peers as newer requests or when it
+// doesn't fit into the batch size limit. The age of the request is the number of state transitions since the node
+// received the request message. Time locked requests don't age until the time lock expires.
+// The request of age maxRequestAge or older is overdue and must be included into the next batch:
+// - overdue requests are selected first, the oldest first, and the seen-by-quorum rule starts with them
+// - overdue requests are not deferred by the rate limit, see intake.go
+// - the batch size limit is extended to include all overdue requests, see batchsize.go
+// The overdue request which still can't be included into the batch is rejected for the batch with the
+// reason recorded in the request and logged each time it changes. The reason is cleared when the request is selected.
+// maxRequestAge == 0 disables the rule
+
+// requestAge returns the number of state transitions since the request message was received by the node
+func (op *operator) requestAge(req *request, nowis time.Time) uint32 {
+	stateIndex, ok := op.blockIndex()
+	if !ok || !req.hasMessage() || req.isTimeLocked(nowis) {
+		// the age is counted from the state the request becomes processable in
+		req.stateIndexKnown = false
+		return 0
+	}
+	if !req.stateIndexKnown {
+		req.stateIndexReceived = stateIndex
+		req.stateIndexKnown = true
+	}
+	if stateIndex < req.stateIndexReceived {
+		// the state was rolled back, see pipeline.go
+		return 0
+	}
+	return stateIndex - req.stateIndexReceived
+}
+
+// isOverdue returns true if the request must be included into the next batch
+func (op *operator) isOverdue(req *request, nowis time.Time) bool {
+	return op.maxRequestAge > 0 && op.requestAge(req, nowis) >= op.maxRequestAge
+}
+
+// prioritizeOverdue moves overdue requests to the head of the list, the oldest first.
+// The order of other requests is preserved
+func (op *operator) prioritizeOverdue(reqs []*request, nowis time.Time) []*request {
+	if op.maxRequestAge == 0 {
+		return reqs
+	}
+	sort.SliceStable(reqs, func(i, j int) bool {
+		oi, oj := op.isOverdue(reqs[i], nowis), op.isOverdue(reqs[j], nowis)
+		if oi != oj {
+			return oi
+		}
+		return oi && op.requestAge(reqs[i], nowis) > op.requestAge(reqs[j], nowis)
+	})
+	return reqs
+}
+
+// numOverdue returns the number of overdue requests at the head of the list
+func (op *operator) numOverdue(reqs []*request, nowis time.Time) int {
+	for i, req := range reqs {
+		if !op.isOverdue(req, nowis) {
+			return i
+		}
+	}
+	return len(reqs)
+}
+
+// overdueBatchLimit extends the limit of the batch size to all overdue requests at the head of the selection
+func overdueBatchLimit(limit int, numOverdue int) int {
+	if limit > 0 && limit < numOverdue {
+		return numOverdue
+	}
+	return limit
+}
+
+// rejectOverdue records the reason for each overdue request in the backlog which is not in the selection
+func (op *operator) rejectOverdue(selected []*request, nowis time.Time) {
+	if op.maxRequestAge == 0 {
+		return
+	}
+	inBatch := make(map[*request]bool, len(selected))
+	for _, req := range selected {
+		inBatch[req] = true
+		req.rejectReason = ""
+	}
+	for _, req := range op.requests {
+		if inBatch[req] || op.isTentativeRequest(&req.reqId) || !op.isOverdue(req, nowis) {
+			continue
+		}
+		reason := op.overdueRejectReason(req, nowis)
+		if reason == req.rejectReason {
+			continue
+		}
+		req.rejectReason = reason
+		req.log.Warnf("overdue request of age %d is not included into the batch: %s", op.requestAge(req, nowis), reason)
+	}
+}
+
+// overdueRejectReason returns the reason why the overdue request is not included into the batch
+func (op *operator) overdueRejectReason(req *request, nowis time.Time) string {
+	switch {
+	case !req.hasSolidArgs():
+		return "arguments are not solid"
+	case op.isWaitingForPredecessor(req, nowis):
+		return "waiting for the predecessor"
+	}
+	if seen := numTrue(req.notifications); seen < op.quorum() {
+		return fmt.Sprintf("seen by %d nodes, quorum is %d", seen, op.quorum())
+	}
+	return "not seen by the same quorum of nodes as older overdue requests"
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/stretchr/testify/require"
+)
+
+// addRequests adds n processable requests to the backlog of the operator, each in its own transaction.
+// The requests are received in the current state and seen only by the node itself
+func (o *testOperator) addRequests(n int) []*request {
+	op := o.op()
+	ret := make([]*request, n)
+	for i := range ret {
+		vtx := valuetransaction.New(
+			valuetransaction.NewInputs(),
+			valuetransaction.NewOutputs(map[address.Address][]*balance.Balance{}),
+		)
+		section := sctransaction.NewRequestSectionByWallet(coretypes.NewContractID(o.chainID, 0), coretypes.Hname(i+1))
+		tx, err := sctransaction.NewTransaction(vtx, nil, []*sctransaction.RequestSection{section})
+		require.NoError(o.t, err)
+
+		req := op.newRequest(coretypes.NewRequestID(tx.ID(), 0))
+		req.reqTx = tx
+		req.argsSolid = true
+		req.whenMsgReceived = o.clock
+		req.notifications[op.peerIndex()] = true
+		op.requests[req.reqId] = req
+		ret[i] = req
+	}
+	return ret
+}
+
+// seenBy marks the request as seen by the peers
+func seenBy(req *request, peers ...uint16) {
+	for _, p := range peers {
+		req.notifications[p] = true
+	}
+}
+
+func TestRequestAge(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 1, 0)
+	o.stateTransition(5)
+	op := o.op()
+	op.maxRequestAge = 3
+	req := o.addRequests(1)[0]
+
+	require.EqualValues(t, 0, op.requestAge(req, o.clock))
+	o.stateTransition(7)
+	require.EqualValues(t, 2, op.requestAge(req, o.clock))
+	require.False(t, op.isOverdue(req, o.clock))
+	o.stateTransition(8)
+	require.True(t, op.isOverdue(req, o.clock))
+
+	// the rule is disabled
+	op.maxRequestAge = 0
+	require.False(t, op.isOverdue(req, o.clock))
+}
+
+func TestRequestAgeTimeLocked(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 1, 0)
+	o.stateTransition(5)
+	op := o.op()
+	req := o.addRequests(1)[0]
+	req.reqTx.Requests()[0].WithTimelockUntil(o.clock.Add(intakeWindow))
+
+	o.stateTransition(10)
+	require.EqualValues(t, 0, op.requestAge(req, o.clock))
+
+	// the request ages from the state it becomes processable in
+	later := o.clock.Add(2 * intakeWindow)
+	require.EqualValues(t, 0, op.requestAge(req, later))
+	o.stateTransition(12)
+	require.EqualValues(t, 2, op.requestAge(req, later))
+}
+
+func TestPrioritizeOverdue(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 1, 0)
+	o.stateTransition(10)
+	op := o.op()
+	op.maxRequestAge = 3
+	reqs := o.addRequests(5)
+	ages := []uint32{0, 3, 1, 5, 4}
+	for i, req := range reqs {
+		op.requestAge(req, o.clock)
+		req.stateIndexReceived -= ages[i]
+	}
+
+	ret := op.prioritizeOverdue(append([]*request(nil), reqs...), o.clock)
+	require.Equal(t, []*request{reqs[3], reqs[4], reqs[1], reqs[0], reqs[2]}, ret)
+	require.EqualValues(t, 3, op.numOverdue(ret, o.clock))
+}
+
+func TestOverdueBatchLimit(t *testing.T) {
+	require.EqualValues(t, 0, overdueBatchLimit(0, 5))
+	require.EqualValues(t, 10, overdueBatchLimit(10, 5))
+	require.EqualValues(t, 5, overdueBatchLimit(3, 5))
+
+	reqs := testRequests(1, 2, 3, 4, 5)
+	require.Len(t, truncateBatch(reqs, overdueBatchLimit(2, 4)), 4)
+}
+
+func TestSelectOverdueFirst(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 1, 0)
+	o.stateTransition(10)
+	op := o.op()
+	op.maxRequestAge = 3
+	reqs := o.addRequests(4)
+	for _, req := range reqs {
+		op.requestAge(req, o.clock)
+	}
+	// reqs[0] is overdue and seen by another quorum than the newer requests
+	reqs[0].stateIndexReceived -= 3
+	seenBy(reqs[0], 0, 2)
+	seenBy(reqs[1], 0, 2, 3)
+	seenBy(reqs[2], 0, 3)
+	// reqs[3] is overdue, but not seen by the quorum
+	reqs[3].stateIndexReceived -= 4
+	seenBy(reqs[3], 3)
+
+	candidates := op.prioritizeOverdue([]*request{reqs[1], reqs[2], reqs[0], reqs[3]}, o.clock)
+	selected := op.selectRequestsSeenByQuorum(candidates)
+	require.Equal(t, []*request{reqs[0], reqs[1]}, selected)
+
+	op.rejectOverdue(selected, o.clock)
+	require.Empty(t, reqs[0].rejectReason)
+	require.Equal(t, "seen by 2 nodes, quorum is 3", reqs[3].rejectReason)
+
+	// the reason is cleared when the request is selected
+	seenBy(reqs[3], 0, 2)
+	op.rejectOverdue(op.selectRequestsSeenByQuorum(op.prioritizeOverdue(op.allRequests(), o.clock)), o.clock)
+	require.Empty(t, reqs[3].rejectReason)
+}
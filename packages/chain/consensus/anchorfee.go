@@ -0,0 +1,59 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/txutil"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+// anchorFeePolicy returns the policy of the anchor fee set in the root contract, see root.GetAnchorFeePolicy.
+// The VM charges the fee of the block by the policy of the state the block is calculated from
+func (op *operator) anchorFeePolicy() *root.AnchorFeePolicy {
+	if op.currentState == nil {
+		return &root.AnchorFeePolicy{}
+	}
+	return root.GetAnchorFeePolicy(subrealm.New(op.currentState.Variables(), kv.Key(root.Interface.Hname().Bytes())))
+}
+
+// anchorFeePaid returns iotas the transaction sends to the target address
+func anchorFeePaid(tx *sctransaction.Transaction, target address.Address) int64 {
+	var ret int64
+	tx.Transaction.Outputs().ForEach(func(addr address.Address, bals []*balance.Balance) bool {
+		if addr == target {
+			ret += txutil.BalanceOfColor(bals, balance.ColorIOTA)
+		}
+		return true
+	})
+	return ret
+}
+
+// reportAnchorFee reports the anchor fee paid by the finalized result transaction.
+// The fee is not paid if neither the payer nor the chain owner had enough iotas on the chain
+func (op *operator) reportAnchorFee(tx *sctransaction.Transaction, blockIndex uint32, numRequests uint16) {
+	policy := op.anchorFeePolicy()
+	if policy.Fee == 0 || numRequests == 0 {
+		return
+	}
+	// the target may receive other transfers in the same transaction
+	if anchorFeePaid(tx, policy.Target) < policy.Fee {
+		op.log.Warnf("anchor fee of %d iotas is not paid by block #%d: not enough funds", policy.Fee, blockIndex)
+		return
+	}
+	op.log.Debugf("anchor fee of %d iotas is paid by block #%d to %s", policy.Fee, blockIndex, policy.Target.String())
+	publisher.Publish("anchor_fee",
+		op.chain.ID().String(),
+		tx.ID().String(),
+		policy.Target.String(),
+		fmt.Sprintf("%d", policy.Fee),
+	)
+}
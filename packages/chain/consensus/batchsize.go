@@ -0,0 +1,74 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"time"
+)
+
+// weight of the last measured batch in the moving average of the VM execution time per request
+const batchSizerSmoothing = 0.3
+
+// batchSizer adapts the number of requests in the batch so that VM execution of the batch
+// fits into the wall-clock time budget. The estimate is based on the VM execution time
+// of previous batches, measured on the node itself
+type batchSizer struct {
+	budget time.Duration
+	// 0 means the number of requests is not limited
+	maxSize int
+	// moving average of the VM execution time per request. 0 means nothing has been measured yet
+	avgPerRequest time.Duration
+}
+
+func newBatchSizer(budget time.Duration, maxSize int) *batchSizer {
+	if maxSize < 0 {
+		maxSize = 0
+	}
+	return &batchSizer{
+		budget:  budget,
+		maxSize: maxSize,
+	}
+}
+
+// limit returns the maximum number of requests in the next batch. 0 means no limit
+func (b *batchSizer) limit() int {
+	if b.budget <= 0 || b.avgPerRequest <= 0 {
+		return b.maxSize
+	}
+	ret := int(b.budget / b.avgPerRequest)
+	if ret < 1 {
+		// at least one request must be processed, however heavy it is
+		return 1
+	}
+	if b.maxSize > 0 && ret > b.maxSize {
+		return b.maxSize
+	}
+	return ret
+}
+
+// update takes into account the VM execution time of the batch
+func (b *batchSizer) update(numRequests int, elapsed time.Duration) {
+	if numRequests <= 0 {
+		return
+	}
+	perRequest := elapsed / time.Duration(numRequests)
+	if b.avgPerRequest <= 0 {
+		b.avgPerRequest = perRequest
+		return
+	}
+	b.avgPerRequest = time.Duration(batchSizerSmoothing*float64(perRequest) + (1-batchSizerSmoothing)*float64(b.avgPerRequest))
+}
+
+// truncateBatch leaves at most 'limit' requests in the selection. Requests of the same request
+// transaction which follow each other in the selection are not split between batches. 0 means no limit
+func truncateBatch(reqs []*request, limit int) []*request {
+	if limit <= 0 || len(reqs) <= limit {
+		return reqs
+	}
+	n := limit
+	for n < len(reqs) && *reqs[n].reqId.TransactionID() == *reqs[n-1].reqId.TransactionID() {
+		n++
+	}
+	return reqs[:n]
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchSizerLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		budget   time.Duration
+		maxSize  int
+		measured []time.Duration // VM execution time of batches of 10 requests
+		expected int
+	}{
+		{"unlimited", 0, 0, nil, 0},
+		{"negative max size is unlimited", 0, -1, nil, 0},
+		{"max size", 0, 50, nil, 50},
+		{"no budget", 0, 50, []time.Duration{time.Second}, 50},
+		{"nothing measured", time.Second, 50, nil, 50},
+		{"fits into budget", time.Second, 0, []time.Duration{time.Second}, 10},
+		{"capped by max size", time.Second, 5, []time.Duration{time.Second}, 5},
+		{"at least one request", time.Second, 0, []time.Duration{time.Minute}, 1},
+		{"moving average", time.Second, 0, []time.Duration{time.Second, 2 * time.Second}, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newBatchSizer(tt.budget, tt.maxSize)
+			for _, elapsed := range tt.measured {
+				b.update(10, elapsed)
+			}
+			require.EqualValues(t, tt.expected, b.limit())
+		})
+	}
+}
+
+func TestBatchSizerIgnoresEmptyBatch(t *testing.T) {
+	b := newBatchSizer(time.Second, 0)
+	b.update(0, time.Minute)
+	require.EqualValues(t, 0, b.limit())
+}
+
+// testRequests creates requests, consecutive equal numbers meaning requests of the same transaction
+func testRequests(txs ...byte) []*request {
+	ret := make([]*request, len(txs))
+	for i, n := range txs {
+		var txid valuetransaction.ID
+		txid[0] = n
+		ret[i] = &request{reqId: coretypes.NewRequestID(txid, uint16(i))}
+	}
+	return ret
+}
+
+func TestTruncateBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		reqs     []*request
+		limit    int
+		expected int
+	}{
+		{"no limit", testRequests(1, 2, 3), 0, 3},
+		{"under limit", testRequests(1, 2, 3), 5, 3},
+		{"at limit", testRequests(1, 2, 3), 3, 3},
+		{"truncated", testRequests(1, 2, 3, 4), 2, 2},
+		{"transaction not split", testRequests(1, 2, 2, 2, 3), 2, 4},
+		{"transaction at the end", testRequests(1, 1, 1), 1, 3},
+		{"empty", testRequests(), 1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ret := truncateBatch(tt.reqs, tt.limit)
+			require.Len(t, ret, tt.expected)
+			for i := range ret {
+				require.Same(t, tt.reqs[i], ret[i])
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"sort"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// The file contains the emergency mode of the committee, the way out of the deadlock of the committee
+// without redeploying the chain.
+// The owner of the node declares the emergency: the node votes to delegate production of the next blocks
+// to one node of the committee. The vote is signed with the key share of the node and sent to all peers.
+// When the node collects the emergency quorum of votes for the same delegate in the same state, it
+// activates the emergency mode and stores the signed votes in the registry:
+//  - the delegate is the leader until the end of the mode, the leader is not rotated
+//  - the delegate doesn't wait for the quorum of notifications about requests
+//  - subordinates accept batch proposals only from the delegate
+// Results are signed by the quorum of the committee as usual.
+// The mode ends when the state of the last delegated block is reached.
+//
+// The emergency mode resolves the deadlock of the leader rotation, e.g. when leaders keep failing or
+// proposing conflicting batches. It can't revive the committee with less than the quorum of nodes online:
+// each block is signed with the threshold signature of the quorum, so the emergency quorum is never less
+// than the quorum either, see emergencyQuorum. Such a committee can only be replaced by redeploying the chain
+
+type emergencyMode struct {
+	delegate uint16
+	// the mode is active while the index of the state is less than untilBlockIndex
+	untilBlockIndex uint32
+}
+
+// emergencyQuorum is the number of votes needed to activate the emergency mode. It is clamped to the range
+// from the quorum to the size of the committee: the delegate can't produce blocks without the quorum anyway
+func (op *operator) emergencyQuorum() uint16 {
+	switch {
+	case op.emergencyQuorumParam < op.quorum():
+		return op.quorum()
+	case op.emergencyQuorumParam > op.size():
+		return op.size()
+	}
+	return op.emergencyQuorumParam
+}
+
+func (op *operator) isEmergency() bool {
+	return op.emergency != nil
+}
+
+// EventEmergencyMsg the owner of the node declared the emergency
+func (op *operator) EventEmergencyMsg(msg *chain.EmergencyMsg) {
+	op.eventEmergencyMsgCh <- msg
+}
+
+// eventEmergencyMsg internal handler
+func (op *operator) eventEmergencyMsg(msg *chain.EmergencyMsg) {
+	stateIndex, ok := op.blockIndex()
+	if !ok {
+		op.log.Warnf("EMERGENCY: can't vote, the state is not known yet")
+		return
+	}
+	vote := &chain.EmergencyVoteMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{
+			BlockIndex:  stateIndex,
+			SenderIndex: op.peerIndex(),
+		},
+		Delegate:  msg.Delegate,
+		NumBlocks: msg.NumBlocks,
+	}
+	var err error
+	if vote.SigShare, err = op.dkshare.SignShare(vote.Essence()); err != nil {
+		op.log.Errorf("EMERGENCY: failed to sign the vote: %v", err)
+		return
+	}
+	op.emergencyVotes[vote.SenderIndex] = vote
+
+	numSucc := op.chain.SendMsgToCommitteePeers(chain.MsgEmergencyVote, util.MustBytes(vote), op.env.now().UnixNano())
+	op.log.Warnf("EMERGENCY: voted in state #%d to delegate %d blocks to #%d. Vote sent to %d peers",
+		stateIndex, vote.NumBlocks, vote.Delegate, numSucc)
+
+	op.checkEmergencyVotes()
+	op.takeAction()
+}
+
+// EventEmergencyVoteMsg emergency vote received from the peer
+func (op *operator) EventEmergencyVoteMsg(msg *chain.EmergencyVoteMsg) {
+	op.eventEmergencyVoteMsgCh <- msg
+}
+
+// eventEmergencyVoteMsg internal handler
+func (op *operator) eventEmergencyVoteMsg(msg *chain.EmergencyVoteMsg) {
+	stateIndex, ok := op.blockIndex()
+	if !ok || msg.BlockIndex != stateIndex {
+		op.log.Warnf("EMERGENCY: vote of peer #%d for state #%d ignored, the state is #%d",
+			msg.SenderIndex, msg.BlockIndex, stateIndex)
+		return
+	}
+	if idx, err := msg.SigShare.Index(); err != nil || idx != int(msg.SenderIndex) {
+		op.log.Warnf("EventEmergencyVoteMsg: signature share doesn't belong to the sender #%d", msg.SenderIndex)
+		return
+	}
+	if err := op.dkshare.VerifySigShare(msg.Essence(), msg.SigShare); err != nil {
+		op.log.Warnf("EventEmergencyVoteMsg: invalid signature share from peer #%d: %v", msg.SenderIndex, err)
+		return
+	}
+	if msg.Delegate >= op.size() || msg.NumBlocks == 0 || msg.NumBlocks > chain.MaxEmergencyBlocks {
+		op.log.Warnf("EMERGENCY: invalid vote of peer #%d: delegate #%d, blocks: %d",
+			msg.SenderIndex, msg.Delegate, msg.NumBlocks)
+		return
+	}
+	op.emergencyVotes[msg.SenderIndex] = msg
+	op.log.Warnf("EMERGENCY: peer #%d voted in state #%d to delegate %d blocks to #%d",
+		msg.SenderIndex, stateIndex, msg.NumBlocks, msg.Delegate)
+
+	op.checkEmergencyVotes()
+	op.takeAction()
+}
+
+// checkEmergencyVotes activates the emergency mode if the emergency quorum of votes is the same
+func (op *operator) checkEmergencyVotes() {
+	type emergencyKey struct {
+		delegate  uint16
+		numBlocks uint32
+	}
+	byKey := make(map[emergencyKey][]*chain.EmergencyVoteMsg)
+	for _, vote := range op.emergencyVotes {
+		key := emergencyKey{delegate: vote.Delegate, numBlocks: vote.NumBlocks}
+		byKey[key] = append(byKey[key], vote)
+	}
+	for key, votes := range byKey {
+		if len(votes) >= int(op.emergencyQuorum()) {
+			op.activateEmergency(key.delegate, key.numBlocks, votes)
+			return
+		}
+	}
+}
+
+func (op *operator) activateEmergency(delegate uint16, numBlocks uint32, votes []*chain.EmergencyVoteMsg) {
+	stateIndex := op.mustStateIndex()
+	rec := &registry.EmergencyRecord{
+		ChainID:    *op.chain.ID(),
+		BlockIndex: stateIndex,
+		Delegate:   delegate,
+		NumBlocks:  numBlocks,
+		Votes:      make([]registry.SignedEmergencyVote, len(votes)),
+	}
+	sort.Slice(votes, func(i, j int) bool { return votes[i].SenderIndex < votes[j].SenderIndex })
+	for i, vote := range votes {
+		rec.Votes[i] = registry.SignedEmergencyVote{
+			PeerIndex: vote.SenderIndex,
+			SigShare:  vote.SigShare,
+		}
+	}
+	// the votes are used, the mode can be changed only by the new quorum of votes
+	op.emergencyVotes = make(map[uint16]*chain.EmergencyVoteMsg)
+	op.emergency = &emergencyMode{
+		delegate:        delegate,
+		untilBlockIndex: stateIndex + numBlocks,
+	}
+	op.log.Warnf("EMERGENCY MODE ACTIVATED: %s", rec.String())
+	if err := op.env.saveEmergencyRecord(rec); err != nil {
+		op.log.Errorf("failed to save the emergency record: %v", err)
+	}
+	if op.consensusStage == consensusStageNoSync {
+		// the delegate becomes the leader in the new state
+		return
+	}
+	op.startLeaderTerm()
+}
+
+// checkEmergencyEnd is called upon the new state. The votes for the previous state are discarded
+func (op *operator) checkEmergencyEnd() {
+	op.emergencyVotes = make(map[uint16]*chain.EmergencyVoteMsg)
+	if op.emergency == nil {
+		return
+	}
+	if op.mustStateIndex() >= op.emergency.untilBlockIndex {
+		op.log.Warnf("EMERGENCY MODE ENDED in state #%d, delegate was #%d",
+			op.mustStateIndex(), op.emergency.delegate)
+		op.emergency = nil
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/chain/transcript"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/sctransaction/origin"
+	_ "github.com/iotaledger/wasp/packages/sctransaction/properties"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/pairing"
+)
+
+// testOperator drives the consensus operator of one node of the test committee with the replayer:
+// each input is appended to the transcript and replayed, all peers are alive
+type testOperator struct {
+	t       *testing.T
+	dks     []*tcrypto.DKShare
+	chainID coretypes.ChainID
+	tr      *transcript.Transcript
+	r       *Replayer
+	anchor  *sctransaction.Transaction
+	clock   time.Time
+}
+
+func newTestOperator(t *testing.T, dks []*tcrypto.DKShare, own uint16, emergencyQuorum uint16) *testOperator {
+	dksBytes, err := dks[own].Bytes()
+	require.NoError(t, err)
+	ret := &testOperator{
+		t:       t,
+		dks:     dks,
+		chainID: coretypes.ChainID(*dks[0].Address),
+		clock:   time.Now(),
+	}
+	ret.tr = &transcript.Transcript{
+		Header: &transcript.Header{
+			ChainID:         ret.chainID,
+			OwnIndex:        own,
+			Size:            dks[0].N,
+			Quorum:          dks[0].T,
+			DKShare:         dksBytes,
+			BatchTimeBudget: time.Second,
+			EmergencyQuorum: emergencyQuorum,
+		},
+	}
+	ret.r, err = NewReplayer(ret.tr, pairing.NewSuiteBn256(), testutil.NewLogger(t))
+	require.NoError(t, err)
+	t.Cleanup(ret.r.Close)
+
+	u := utxodb.New()
+	owner := signaturescheme.RandBLS()
+	_, err = u.RequestFunds(owner.Address())
+	require.NoError(t, err)
+	ret.anchor, err = origin.NewOriginTransaction(origin.NewOriginTransactionParams{
+		OriginAddress:             *dks[0].Address,
+		OriginatorSignatureScheme: owner,
+		AllInputs:                 u.GetAddressOutputs(owner.Address()),
+	})
+	require.NoError(t, err)
+	return ret
+}
+
+func (o *testOperator) op() *operator {
+	return o.r.op
+}
+
+func (o *testOperator) feed(rec *transcript.Record) {
+	o.clock = o.clock.Add(10 * time.Millisecond)
+	rec.Timestamp = o.clock.UnixNano()
+	rec.SetAlive(o.tr.Header.Size, func(uint16) bool { return true })
+	rec.HasQuorum = true
+	o.tr.Records = append(o.tr.Records, rec)
+	ok, err := o.r.Step()
+	require.NoError(o.t, err)
+	require.True(o.t, ok)
+}
+
+// stateTransition moves the operator to the synchronized state with the index
+func (o *testOperator) stateTransition(blockIndex uint32) {
+	vs := state.NewVirtualState(mapdb.NewMapDB(), &o.chainID)
+	vs.ApplyBlockIndex(blockIndex)
+	data, err := transcript.StateTransitionData(&chain.StateTransitionMsg{
+		VariableState:     vs,
+		AnchorTransaction: o.anchor,
+		Synchronized:      true,
+	}, nil)
+	require.NoError(o.t, err)
+	o.feed(&transcript.Record{Kind: transcript.KindStateTransition, Data: data})
+}
+
+func (o *testOperator) peerMsg(sender uint16, msgType byte, data []byte, ts int64) {
+	o.feed(&transcript.Record{
+		Kind:         transcript.KindPeerIn,
+		Peer:         sender,
+		MsgType:      msgType,
+		MsgTimestamp: ts,
+		Data:         data,
+	})
+}
+
+func (o *testOperator) declareEmergency(delegate uint16, numBlocks uint32) {
+	o.feed(&transcript.Record{
+		Kind: transcript.KindEmergency,
+		Data: transcript.EmergencyData(&chain.EmergencyMsg{Delegate: delegate, NumBlocks: numBlocks}),
+	})
+}
+
+// vote returns the emergency vote of the peer, signed with its key share
+func (o *testOperator) vote(sender uint16, blockIndex uint32, delegate uint16, numBlocks uint32) *chain.EmergencyVoteMsg {
+	ret := &chain.EmergencyVoteMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{BlockIndex: blockIndex, SenderIndex: sender},
+		Delegate:      delegate,
+		NumBlocks:     numBlocks,
+	}
+	var err error
+	ret.SigShare, err = o.dks[sender].SignShare(ret.Essence())
+	require.NoError(o.t, err)
+	return ret
+}
+
+func (o *testOperator) sendVote(vote *chain.EmergencyVoteMsg) {
+	o.peerMsg(vote.SenderIndex, chain.MsgEmergencyVote, util.MustBytes(vote), 0)
+}
+
+// sendProposal sends the empty batch proposal signed by the leader, with the VRF of the block
+func (o *testOperator) sendProposal(leader uint16, blockIndex uint32, ts int64) {
+	msg := &chain.StartProcessingBatchMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{BlockIndex: blockIndex, SenderIndex: leader},
+		Timestamp:     ts,
+		VRFProof:      vrfProof(o.t, o.dks, []int{0, 1, 2}, o.op().currentState.Hash(), blockIndex+1, ts),
+	}
+	var err error
+	msg.LeaderSig, err = o.dks[leader].SignShare(root.ProposalEssence(&o.chainID, blockIndex, ts, proposalHash(msg)))
+	require.NoError(o.t, err)
+	o.peerMsg(leader, chain.MsgStartProcessingRequest, util.MustBytes(msg), ts)
+}
+
+func TestEmergencyQuorum(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	require.EqualValues(t, 3, newTestOperator(t, dks, 0, 0).op().emergencyQuorum())
+	require.EqualValues(t, 3, newTestOperator(t, dks, 0, 2).op().emergencyQuorum())
+	require.EqualValues(t, 4, newTestOperator(t, dks, 0, 4).op().emergencyQuorum())
+	require.EqualValues(t, 4, newTestOperator(t, dks, 0, 10).op().emergencyQuorum())
+}
+
+func TestEmergencyVoteValidation(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+
+	// votes before the state is known are ignored
+	o.sendVote(o.vote(1, 0, 1, 5))
+	require.Empty(t, o.op().emergencyVotes)
+
+	o.stateTransition(5)
+
+	o.sendVote(o.vote(1, 4, 1, 5))
+	require.Empty(t, o.op().emergencyVotes, "vote for another state")
+
+	forged := o.vote(3, 5, 1, 5)
+	forged.SenderIndex = 2
+	o.sendVote(forged)
+	require.Empty(t, o.op().emergencyVotes, "vote signed by another peer")
+
+	tampered := o.vote(2, 5, 1, 5)
+	tampered.NumBlocks = 6
+	o.sendVote(tampered)
+	require.Empty(t, o.op().emergencyVotes, "signature doesn't match the vote")
+
+	o.sendVote(o.vote(2, 5, 4, 5))
+	require.Empty(t, o.op().emergencyVotes, "delegate out of the committee")
+
+	o.sendVote(o.vote(2, 5, 1, 0))
+	o.sendVote(o.vote(2, 5, 1, chain.MaxEmergencyBlocks+1))
+	require.Empty(t, o.op().emergencyVotes, "wrong number of blocks")
+
+	o.sendVote(o.vote(2, 5, 1, 5))
+	require.Len(t, o.op().emergencyVotes, 1)
+	require.False(t, o.op().isEmergency())
+
+	// votes are discarded in the new state
+	o.stateTransition(6)
+	require.Empty(t, o.op().emergencyVotes)
+}
+
+func TestEmergencyActivation(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+	o.stateTransition(5)
+
+	o.declareEmergency(1, 2)
+	o.sendVote(o.vote(2, 5, 1, 2))
+	// the vote for another number of blocks doesn't count
+	o.sendVote(o.vote(3, 5, 1, 3))
+	require.False(t, o.op().isEmergency())
+
+	o.sendVote(o.vote(3, 5, 1, 2))
+	require.True(t, o.op().isEmergency())
+	leader, _ := o.op().currentLeader()
+	require.EqualValues(t, 1, leader)
+	require.Empty(t, o.op().emergencyVotes)
+
+	require.Len(t, o.r.result.Emergencies, 1)
+	rec := o.r.result.Emergencies[0]
+	require.EqualValues(t, 5, rec.BlockIndex)
+	require.EqualValues(t, 1, rec.Delegate)
+	require.EqualValues(t, 2, rec.NumBlocks)
+	require.Len(t, rec.Votes, 3)
+	for i, v := range rec.Votes {
+		require.EqualValues(t, i+1, v.PeerIndex)
+	}
+}
+
+func TestEmergencyDelegateOnlyProposals(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+	o.stateTransition(5)
+	o.declareEmergency(1, 2)
+	o.sendVote(o.vote(2, 5, 1, 2))
+	o.sendVote(o.vote(3, 5, 1, 2))
+	require.True(t, o.op().isEmergency())
+
+	// the proposal of another peer is rejected before anything else
+	o.sendProposal(3, 5, o.clock.UnixNano())
+	require.NotContains(t, o.op().ownProposalDigests, uint16(3))
+
+	// the proposal of the delegate is accepted. The empty batch is stopped only later by the block interval,
+	// so the VM is not run by the test
+	o.sendProposal(1, 5, o.clock.Add(time.Hour).UnixNano())
+	require.Contains(t, o.op().ownProposalDigests, uint16(1))
+}
+
+func TestEmergencyEnd(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+	o.stateTransition(5)
+	o.declareEmergency(2, 2)
+	o.sendVote(o.vote(1, 5, 2, 2))
+	o.sendVote(o.vote(3, 5, 2, 2))
+	require.True(t, o.op().isEmergency())
+
+	o.stateTransition(6)
+	require.True(t, o.op().isEmergency())
+	leader, _ := o.op().currentLeader()
+	require.EqualValues(t, 2, leader)
+
+	// the state of the last delegated block ends the mode
+	o.stateTransition(7)
+	require.False(t, o.op().isEmergency())
+}
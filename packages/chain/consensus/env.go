@@ -0,0 +1,88 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"os"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/plugins/nodeconn"
+)
+
+// operatorEnv is everything the operator uses outside of the committee: the clock, the connection
+// to the IOTA node and the database of the node. The replay of the transcript substitutes it
+// to make the operator deterministic, see replay.go
+type operatorEnv interface {
+	now() time.Time
+	// batchTimestamp is the local clock of the leader for the timestamp of the new batch
+	batchTimestamp() int64
+	requestBalances(addr address.Address)
+	requestInclusionLevel(txid *valuetransaction.ID, addr *address.Address) error
+	postTransaction(tx *valuetransaction.Transaction, addr *address.Address, leader uint16) error
+	isRequestCompleted(chainID *coretypes.ChainID, reqid *coretypes.RequestID) (bool, error)
+	saveRoundState(rs *registry.RoundState) error
+	getRoundState(chainID *coretypes.ChainID) (*registry.RoundState, error)
+	deleteRoundState(chainID *coretypes.ChainID) error
+	saveEquivocationEvidence(ev *registry.EquivocationEvidence) error
+	saveEmergencyRecord(rec *registry.EmergencyRecord) error
+	// isCrashPoint is true if the test has selected the crash point, see chain.CrashPointEnvVar
+	isCrashPoint(name string) bool
+}
+
+// nodeEnv is the environment of the operator in the node
+type nodeEnv struct{}
+
+func (nodeEnv) now() time.Time {
+	return time.Now()
+}
+
+func (nodeEnv) batchTimestamp() int64 {
+	return time.Now().UnixNano()
+}
+
+func (nodeEnv) requestBalances(addr address.Address) {
+	nodeconn.RequestBalances(addr)
+}
+
+func (nodeEnv) requestInclusionLevel(txid *valuetransaction.ID, addr *address.Address) error {
+	return nodeconn.RequestInclusionLevelFromNode(txid, addr)
+}
+
+func (nodeEnv) postTransaction(tx *valuetransaction.Transaction, addr *address.Address, leader uint16) error {
+	return nodeconn.PostTransactionToNode(tx, addr, leader)
+}
+
+func (nodeEnv) isRequestCompleted(chainID *coretypes.ChainID, reqid *coretypes.RequestID) (bool, error) {
+	return state.IsRequestCompleted(chainID, reqid)
+}
+
+func (nodeEnv) saveRoundState(rs *registry.RoundState) error {
+	return registry.SaveRoundState(rs)
+}
+
+func (nodeEnv) getRoundState(chainID *coretypes.ChainID) (*registry.RoundState, error) {
+	return registry.GetRoundState(chainID)
+}
+
+func (nodeEnv) deleteRoundState(chainID *coretypes.ChainID) error {
+	return registry.DeleteRoundState(chainID)
+}
+
+func (nodeEnv) saveEquivocationEvidence(ev *registry.EquivocationEvidence) error {
+	return registry.SaveEquivocationEvidence(ev)
+}
+
+func (nodeEnv) saveEmergencyRecord(rec *registry.EmergencyRecord) error {
+	return registry.SaveEmergencyRecord(rec)
+}
+
+func (nodeEnv) isCrashPoint(name string) bool {
+	return os.Getenv(chain.CrashPointEnvVar) == name
+}
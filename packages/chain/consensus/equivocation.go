@@ -0,0 +1,157 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+// The file contains detection of the equivocating leader, i.e. the leader which sends different
+// batch proposals to different subordinates in the same round.
+// The leader signs each proposal with its key share. Each subordinate relays the digest of the proposal
+// it received, together with the signature of the leader, to other peers.
+// If a peer finds the digest different from its own, both proposals signed by the leader are the evidence.
+// It is stored in the registry and may be recorded on the chain, see root.FuncRecordEquivocation
+
+// proposalHash identifies the batch proposal as seen by the subordinate
+func proposalHash(msg *chain.StartProcessingBatchMsg) hashing.HashValue {
+	bh := vm.BatchHash(msg.RequestIds, msg.Timestamp, msg.SenderIndex)
+	return hashing.HashData(bh[:], msg.FeeDestination[:])
+}
+
+// signProposal is called by the leader before sending the proposal to subordinates
+func (op *operator) signProposal(msg *chain.StartProcessingBatchMsg) error {
+	essence := root.ProposalEssence(op.chain.ID(), msg.BlockIndex, msg.Timestamp, proposalHash(msg))
+	var err error
+	msg.LeaderSig, err = op.dkshare.SignShare(essence)
+	return err
+}
+
+// verifyLeaderSig checks that the proposal is signed by the leader with the key share of the index
+func verifyLeaderSig(dkshare *tcrypto.DKShare, leaderIndex uint16, essence []byte, sig tbdn.SigShare) error {
+	if leaderIndex >= dkshare.N {
+		return fmt.Errorf("wrong leader index #%d", leaderIndex)
+	}
+	idx, err := sig.Index()
+	if err != nil || idx != int(leaderIndex) {
+		return fmt.Errorf("proposal is not signed by the leader #%d", leaderIndex)
+	}
+	return dkshare.VerifySigShare(essence, sig)
+}
+
+// equivocationEvidence returns the evidence if both digests are conflicting proposals
+// of the same leader in the same round, nil otherwise. Signatures of the leader must be verified before
+func equivocationEvidence(dkshare *tcrypto.DKShare, own, peer *chain.ProposalDigestMsg) (*root.EquivocationEvidence, error) {
+	if own.LeaderIndex != peer.LeaderIndex || own.BlockIndex != peer.BlockIndex || own.ProposalHash == peer.ProposalHash {
+		return nil, nil
+	}
+	diff := own.ProposalTimestamp - peer.ProposalTimestamp
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > root.EquivocationWindow.Nanoseconds() {
+		return nil, nil
+	}
+	commits := make([][]byte, len(dkshare.PublicCommits))
+	for i, c := range dkshare.PublicCommits {
+		var err error
+		if commits[i], err = c.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+	return &root.EquivocationEvidence{
+		BlockIndex:  own.BlockIndex,
+		LeaderIndex: own.LeaderIndex,
+		Proposals: [2]root.SignedProposal{
+			{Timestamp: own.ProposalTimestamp, ProposalHash: own.ProposalHash, LeaderSig: own.LeaderSig},
+			{Timestamp: peer.ProposalTimestamp, ProposalHash: peer.ProposalHash, LeaderSig: peer.LeaderSig},
+		},
+		PublicCommits: commits,
+	}, nil
+}
+
+// crossCheckProposal is called by the subordinate upon receiving the batch proposal from the leader,
+// after the signature of the leader is verified.
+// It sends the digest of the proposal to other peers and checks it against the digests received from peers so far
+func (op *operator) crossCheckProposal(msg *chain.StartProcessingBatchMsg) {
+	digest := &chain.ProposalDigestMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{
+			BlockIndex:  msg.BlockIndex,
+			SenderIndex: op.peerIndex(),
+		},
+		LeaderIndex:       msg.SenderIndex,
+		ProposalTimestamp: msg.Timestamp,
+		ProposalHash:      proposalHash(msg),
+		LeaderSig:         msg.LeaderSig,
+	}
+	op.ownProposalDigests[msg.SenderIndex] = digest
+
+	numSucc := op.chain.SendMsgToCommitteePeers(chain.MsgProposalDigest, util.MustBytes(digest), op.env.now().UnixNano())
+	op.log.Debugf("%d 'msgProposalDigest' messages sent to peers", numSucc)
+
+	for _, peerDigest := range op.peerProposalDigests[msg.SenderIndex] {
+		op.compareProposalDigests(digest, peerDigest)
+	}
+	delete(op.peerProposalDigests, msg.SenderIndex)
+}
+
+// EventProposalDigestMsg digest of the leader's proposal received by the peer
+func (op *operator) EventProposalDigestMsg(msg *chain.ProposalDigestMsg) {
+	op.eventProposalDigestMsgCh <- msg
+}
+
+// eventProposalDigestMsg internal handler
+func (op *operator) eventProposalDigestMsg(msg *chain.ProposalDigestMsg) {
+	op.log.Debugw("EventProposalDigestMsg",
+		"sender", msg.SenderIndex,
+		"leader", msg.LeaderIndex,
+		"proposal hash", msg.ProposalHash.String(),
+	)
+	stateIndex, ok := op.blockIndex()
+	if !ok || msg.BlockIndex != stateIndex {
+		return
+	}
+	essence := root.ProposalEssence(op.chain.ID(), msg.BlockIndex, msg.ProposalTimestamp, msg.ProposalHash)
+	if err := verifyLeaderSig(op.dkshare, msg.LeaderIndex, essence, msg.LeaderSig); err != nil {
+		op.log.Warnf("EventProposalDigestMsg: invalid proposal digest from peer #%d: %v", msg.SenderIndex, err)
+		return
+	}
+	own, ok := op.ownProposalDigests[msg.LeaderIndex]
+	if !ok {
+		// proposal from the leader hasn't reached the node yet
+		op.peerProposalDigests[msg.LeaderIndex] = append(op.peerProposalDigests[msg.LeaderIndex], msg)
+		return
+	}
+	op.compareProposalDigests(own, msg)
+}
+
+func (op *operator) compareProposalDigests(own, peer *chain.ProposalDigestMsg) {
+	ev, err := equivocationEvidence(op.dkshare, own, peer)
+	if err != nil {
+		op.log.Errorf("failed to create equivocation evidence: %v", err)
+		return
+	}
+	if ev == nil {
+		return
+	}
+	op.log.Errorf("EQUIVOCATION DETECTED: %s", ev.String())
+	err = op.env.saveEquivocationEvidence(&registry.EquivocationEvidence{
+		ChainID:     *op.chain.ID(),
+		BlockIndex:  ev.BlockIndex,
+		LeaderIndex: ev.LeaderIndex,
+		Data:        root.EncodeEquivocationEvidence(ev),
+	})
+	if err != nil {
+		op.log.Errorf("failed to save equivocation evidence: %v", err)
+	}
+}
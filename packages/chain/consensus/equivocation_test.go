@@ -0,0 +1,128 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+// newTestDKShares emulates the DKG of the committee of n nodes with the quorum t
+func newTestDKShares(t *testing.T, n, thr uint16) []*tcrypto.DKShare {
+	suite := pairing.NewSuiteBn256()
+	priPoly := share.NewPriPoly(suite, int(thr), nil, suite.RandomStream())
+	pubPoly := priPoly.Commit(nil)
+	_, commits := pubPoly.Info()
+	publicShares := make([]kyber.Point, n)
+	for i, s := range priPoly.Shares(int(n)) {
+		publicShares[i] = suite.Point().Mul(s.V, nil)
+	}
+	ret := make([]*tcrypto.DKShare, n)
+	for i, s := range priPoly.Shares(int(n)) {
+		dks, err := tcrypto.NewDKShare(uint16(i), n, thr, pubPoly.Commit(), commits, publicShares, s.V)
+		require.NoError(t, err)
+		data, err := dks.Bytes()
+		require.NoError(t, err)
+		ret[i], err = tcrypto.DKShareFromBytes(data, suite)
+		require.NoError(t, err)
+	}
+	return ret
+}
+
+// signedDigest returns the digest of the proposal signed by the leader, as relayed by the peer
+func signedDigest(t *testing.T, chainID *coretypes.ChainID, leader *tcrypto.DKShare, peerIndex uint16, ts int64, h hashing.HashValue) *chain.ProposalDigestMsg {
+	sig, err := leader.SignShare(root.ProposalEssence(chainID, 5, ts, h))
+	require.NoError(t, err)
+	return &chain.ProposalDigestMsg{
+		PeerMsgHeader:     chain.PeerMsgHeader{BlockIndex: 5, SenderIndex: peerIndex},
+		LeaderIndex:       *leader.Index,
+		ProposalTimestamp: ts,
+		ProposalHash:      h,
+		LeaderSig:         sig,
+	}
+}
+
+func TestEquivocationDetected(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	chainID := coretypes.ChainID(*dks[0].Address)
+	leader := dks[2]
+	ts := time.Now().UnixNano()
+
+	own := signedDigest(t, &chainID, leader, 0, ts, hashing.HashStrings("proposal A"))
+	peer := signedDigest(t, &chainID, leader, 1, ts+1, hashing.HashStrings("proposal B"))
+	for _, d := range []*chain.ProposalDigestMsg{own, peer} {
+		essence := root.ProposalEssence(&chainID, d.BlockIndex, d.ProposalTimestamp, d.ProposalHash)
+		require.NoError(t, verifyLeaderSig(dks[0], 2, essence, d.LeaderSig))
+	}
+
+	ev, err := equivocationEvidence(dks[0], own, peer)
+	require.NoError(t, err)
+	require.NotNil(t, ev)
+	require.EqualValues(t, 5, ev.BlockIndex)
+	require.EqualValues(t, 2, ev.LeaderIndex)
+	// the evidence is verifiable without the key shares of the committee
+	require.NoError(t, root.VerifyEquivocationEvidence(&chainID, ev))
+
+	back, err := root.DecodeEquivocationEvidence(root.EncodeEquivocationEvidence(ev))
+	require.NoError(t, err)
+	require.NoError(t, root.VerifyEquivocationEvidence(&chainID, back))
+
+	otherChain := coretypes.ChainID{1, 2, 3}
+	require.Error(t, root.VerifyEquivocationEvidence(&otherChain, ev))
+}
+
+func TestEquivocationNotDetected(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	chainID := coretypes.ChainID(*dks[0].Address)
+	leader := dks[1]
+	ts := time.Now().UnixNano()
+	h := hashing.HashStrings("proposal")
+
+	// the same proposal
+	ev, err := equivocationEvidence(dks[0], signedDigest(t, &chainID, leader, 0, ts, h), signedDigest(t, &chainID, leader, 2, ts, h))
+	require.NoError(t, err)
+	require.Nil(t, ev)
+
+	// another term of the leader
+	later := ts + root.EquivocationWindow.Nanoseconds() + 1
+	ev, err = equivocationEvidence(dks[0],
+		signedDigest(t, &chainID, leader, 0, ts, h),
+		signedDigest(t, &chainID, leader, 2, later, hashing.HashStrings("next proposal")))
+	require.NoError(t, err)
+	require.Nil(t, ev)
+}
+
+func TestEquivocationFramingRejected(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	chainID := coretypes.ChainID(*dks[0].Address)
+	ts := time.Now().UnixNano()
+
+	// peer #3 forges the proposal of the leader #1 with its own key share
+	forged := signedDigest(t, &chainID, dks[3], 3, ts, hashing.HashStrings("forged"))
+	forged.LeaderIndex = 1
+	essence := root.ProposalEssence(&chainID, forged.BlockIndex, forged.ProposalTimestamp, forged.ProposalHash)
+	require.Error(t, verifyLeaderSig(dks[0], 1, essence, forged.LeaderSig))
+
+	// the signature of the leader doesn't cover another proposal
+	genuine := signedDigest(t, &chainID, dks[1], 0, ts, hashing.HashStrings("genuine"))
+	genuine.ProposalHash = hashing.HashStrings("tampered")
+	essence = root.ProposalEssence(&chainID, genuine.BlockIndex, genuine.ProposalTimestamp, genuine.ProposalHash)
+	require.Error(t, verifyLeaderSig(dks[0], 1, essence, genuine.LeaderSig))
+
+	// the evidence built from the forged digest doesn't verify
+	own := signedDigest(t, &chainID, dks[1], 0, ts, hashing.HashStrings("genuine"))
+	ev, err := equivocationEvidence(dks[0], own, forged)
+	require.NoError(t, err)
+	require.Error(t, root.VerifyEquivocationEvidence(&chainID, ev))
+}
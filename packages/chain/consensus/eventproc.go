@@ -12,6 +12,7 @@ import (
 	"github.com/iotaledger/wasp/packages/chain"
 	"github.com/iotaledger/wasp/packages/txutil"
 	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
 )
 
 // EventStateTransitionMsg is called when new state transition message sent by the state manager
@@ -21,11 +22,18 @@ func (op *operator) EventStateTransitionMsg(msg *chain.StateTransitionMsg) {
 
 // eventStateTransitionMsg internal event handler
 func (op *operator) eventStateTransitionMsg(msg *chain.StateTransitionMsg) {
+	if op.confirmTentativeState(msg) {
+		// the round started on the tentative state goes on, see pipeline.go
+		op.takeAction()
+		return
+	}
+	op.recordRound(msg)
 	op.setNewSCState(msg.AnchorTransaction, msg.VariableState, msg.Synchronized)
 
 	vh := op.currentState.Hash()
+	leader, _ := op.currentLeader()
 	op.log.Infof("STATE FOR CONSENSUS #%d, synced: %v, leader: %d iAmTheLeader: %v tx: %s, state hash: %s, backlog: %d",
-		op.mustStateIndex(), msg.Synchronized, op.peerPermutation.Current(), op.iAmCurrentLeader(),
+		op.mustStateIndex(), msg.Synchronized, leader, op.iAmCurrentLeader(),
 		op.stateTx.ID().String(), vh.String(), len(op.requests))
 
 	// remove all processed requests from the local backlog
@@ -39,6 +47,8 @@ func (op *operator) eventStateTransitionMsg(msg *chain.StateTransitionMsg) {
 		} else {
 			op.setNextConsensusStage(consensusStageSubStarting)
 		}
+		// resume the round interrupted by the crash, if any
+		op.restoreRound()
 	} else {
 		op.setNextConsensusStage(consensusStageNoSync)
 	}
@@ -59,8 +69,13 @@ func (op *operator) eventBalancesMsg(reqMsg chain.BalancesMsg) {
 	//	op.log.Debugf("EventBalancesMsg: balances not included: %v", err)
 	//	return
 	//}
-	op.balances = reqMsg.Balances
-	op.requestBalancesDeadline = time.Now().Add(chain.RequestBalancesPeriod)
+	if _, ok := reqMsg.Balances[op.stateTxID()]; !ok && op.tentative != nil {
+		// balances of the confirmed state. They are kept for the rollback of the tentative state
+		op.tentative.prevBalances = reqMsg.Balances
+	} else {
+		op.balances = reqMsg.Balances
+	}
+	op.requestBalancesDeadline = op.env.now().Add(chain.RequestBalancesPeriod)
 	op.takeAction()
 }
 
@@ -77,12 +92,22 @@ func (op *operator) eventRequestMsg(reqMsg *chain.RequestMsg) {
 		"backlog notif", len(op.notificationsBacklog),
 		"free tokens attached", reqMsg.FreeTokens != nil,
 	)
+	if r, ok := op.requests[*reqMsg.RequestId()]; !ok || r.reqTx == nil {
+		// the request message is new, check it before it takes space in the backlog
+		if err := op.checkRequestIntake(reqMsg); err != nil {
+			op.log.Warnf("request %s rejected: %v", reqMsg.RequestId().Short(), err)
+			return
+		}
+	}
 	// place request into the backlog
-	req, _ := op.requestFromMsg(reqMsg)
+	req, newMsg := op.requestFromMsg(reqMsg)
 	if req == nil {
 		op.log.Warn("received already processed request id = %s", reqMsg.RequestId().Short())
 		return
 	}
+	if newMsg {
+		op.applyRateLimit(req)
+	}
 	op.takeAction()
 }
 
@@ -133,6 +158,40 @@ func (op *operator) eventStartProcessingBatchMsg(msg *chain.StartProcessingBatch
 		)
 		return
 	}
+	if op.isEmergency() && msg.SenderIndex != op.emergency.delegate {
+		op.log.Warnf("EMERGENCY: batch proposal from #%d rejected, block production is delegated to #%d",
+			msg.SenderIndex, op.emergency.delegate)
+		return
+	}
+	essence := root.ProposalEssence(op.chain.ID(), msg.BlockIndex, msg.Timestamp, proposalHash(msg))
+	if err := verifyLeaderSig(op.dkshare, msg.SenderIndex, essence, msg.LeaderSig); err != nil {
+		op.log.Warnf("EventStartProcessingBatchMsg: batch rejected: %v", err)
+		return
+	}
+	op.crossCheckProposal(msg)
+
+	if err := op.checkBlockInterval(msg); err != nil {
+		op.log.Warnf("EventStartProcessingBatchMsg: batch rejected: %v", err)
+		return
+	}
+	// the empty batch has nothing to order
+	if op.fairOrdering && len(msg.RequestIds) > 0 {
+		if err := verifyFairOrder(op.dkshare, op.chain.ID(), msg); err != nil {
+			op.log.Warnw("EventStartProcessingBatchMsg: batch rejected",
+				"sender", msg.SenderIndex,
+				"reqIds", idsShortStr(msg.RequestIds),
+				"err", err,
+			)
+			return
+		}
+	}
+
+	vrf, err := op.verifyVRF(msg)
+	if err != nil {
+		op.log.Warnf("EventStartProcessingBatchMsg: batch rejected: %v", err)
+		return
+	}
+
 	numOrig := len(msg.RequestIds)
 	reqs := op.collectProcessableBatch(msg.RequestIds)
 	if len(reqs) != numOrig {
@@ -154,7 +213,7 @@ func (op *operator) eventStartProcessingBatchMsg(msg *chain.StartProcessingBatch
 	// Note that if leader's clock is ot synced with the peers clock significantly, committee
 	// will ignore the leader and leader will never earn reward.
 	// TODO: attack analysis
-	localts := time.Now().UnixNano()
+	localts := op.env.now().UnixNano()
 	diff := localts - msg.Timestamp
 	if diff < 0 {
 		diff = -diff
@@ -165,6 +224,9 @@ func (op *operator) eventStartProcessingBatchMsg(msg *chain.StartProcessingBatch
 		return
 	}
 
+	// the signing set of the anchor transaction, if the signature scheme needs it
+	op.batchSigningCommitments = msg.SigningCommitments
+
 	// start async calculation as requested by the leader
 	op.runCalculationsAsync(runCalculationsParams{
 		requests:        reqs,
@@ -172,6 +234,7 @@ func (op *operator) eventStartProcessingBatchMsg(msg *chain.StartProcessingBatch
 		balances:        msg.Balances,
 		accrueFeesTo:    msg.FeeDestination,
 		leaderPeerIndex: msg.SenderIndex,
+		vrf:             vrf,
 	})
 	op.setNextConsensusStage(consensusStageSubCalculationsStarted)
 	op.takeAction()
@@ -193,9 +256,12 @@ func (op *operator) eventResultCalculated(ctx *chain.VMResultMsg) {
 		// out of context. ignore
 		return
 	}
+	op.batchSizer.update(len(ctx.Task.Requests), ctx.Duration)
 	op.log.Debugw("eventResultCalculated",
 		"batch size", ctx.Task.ResultBlock.Size(),
 		"blockIndex", op.mustStateIndex(),
+		"duration", ctx.Duration,
+		"next batch limit", op.batchSizer.limit(),
 	)
 
 	// inform own state manager about new result block. The state manager will start waiting
@@ -253,6 +319,8 @@ func (op *operator) eventSignedHashMsg(msg *chain.SignedHashMsg) {
 		essenceHash: msg.EssenceHash,
 		sigShare:    msg.SigShare,
 	}
+	op.persistRound()
+	op.crashPoint(chain.CrashPointLeaderSigSharePersisted)
 	op.takeAction()
 }
 
@@ -281,6 +349,9 @@ func (op *operator) eventNotifyFinalResultPostedMsg(msg *chain.NotifyFinalResult
 	}
 	op.setNextConsensusStage(consensusStageSubResultFinalized)
 	op.setFinalizedTransaction(&msg.TxId)
+	if op.sentResultToLeader != nil && op.sentResultToLeaderIndex == msg.SenderIndex {
+		op.setFinalizedRound(op.sentResultToLeader, msg.TxId, op.sentResultBlock)
+	}
 }
 
 // EventTransactionInclusionLevelMsg goshimmer send information about transaction
@@ -294,6 +365,9 @@ func (op *operator) eventTransactionInclusionLevelMsg(msg *chain.TransactionIncl
 		"txid", msg.TxId.String(),
 		"level", waspconn.InclusionLevelText(msg.Level),
 	)
+	if op.checkTentativeInclusionLevel(msg.TxId, msg.Level) {
+		return
+	}
 	op.checkInclusionLevel(msg.TxId, msg.Level)
 }
 
@@ -0,0 +1,295 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Fair ordering of requests in the batch.
+// By default the leader decides the order of requests in the batch and the subordinates accept it,
+// so the leader is able to put own request in front of the request of somebody else (front-running).
+// In the fair ordering mode the order of requests is derived from the entropy which is revealed only
+// after the leader has fixed the set of requests of the batch:
+// - the leader selects the batch as usual and sends the hash of the set of request ids, together with
+//   its own signature share of the set, to subordinates (chain.FairOrderShareMsg)
+// - each subordinate signs the set with its key share and sends the signature share back. The node signs
+//   only one set of each leader in the same state, so the leader can't try different sets until it finds
+//   the order it likes
+// - with the quorum of signature shares the leader recovers the threshold signature of the set. The signature
+//   is unique for the set, however nobody can compute it without the quorum of shares, so it is not known
+//   to anybody when the set is fixed
+// - requests are sorted by the hash of the signature and the request id. The leader sends the signature with
+//   the batch proposal, subordinates verify it with the public key of the committee and refuse to process
+//   the batch which is not in the fair order
+// The leader still selects the set of requests seen by the quorum and truncates it to the size of the batch,
+// however the position of each request in the batch is not its choice
+
+// fairOrderResendPeriod is the period the leader re-sends the fixed set to subordinates until the
+// quorum of signature shares is collected
+const fairOrderResendPeriod = 2 * time.Second
+
+// fairOrderSet is the set of requests of the next batch fixed by the leader
+type fairOrderSet struct {
+	// request ids, sorted by id
+	reqIds  []coretypes.RequestID
+	setHash hashing.HashValue
+	// signature shares of the set, by the peer index
+	shares map[uint16]tbdn.SigShare
+	// threshold signature of the set. nil until the quorum of shares is collected
+	orderSig   []byte
+	nextResend time.Time
+}
+
+// batchEntropy returns the entropy of the VM task of the next batch
+func (op *operator) batchEntropy() hashing.HashValue {
+	return (hashing.HashValue)(op.stateTxID())
+}
+
+// fairOrderSetHash is the hash of the set of request ids, independent of the order
+func fairOrderSetHash(reqIds []coretypes.RequestID) hashing.HashValue {
+	sorted := sortIds(reqIds)
+	data := make([][]byte, len(sorted))
+	for i := range sorted {
+		data[i] = sorted[i][:]
+	}
+	return hashing.HashData(data...)
+}
+
+// fairOrderEssence is the data of the request set of the leader signed by the committee
+func fairOrderEssence(chainID *coretypes.ChainID, blockIndex uint32, leaderIndex uint16, setHash hashing.HashValue) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("fairorder")
+	buf.Write(chainID[:])
+	_ = util.WriteUint32(&buf, blockIndex)
+	_ = util.WriteUint16(&buf, leaderIndex)
+	buf.Write(setHash[:])
+	return buf.Bytes()
+}
+
+// fairOrderEntropy is the entropy of the order, derived from the threshold signature of the set
+func fairOrderEntropy(orderSig []byte) hashing.HashValue {
+	return hashing.HashData(orderSig)
+}
+
+// fairOrderKey is the sorting key of the request in the batch with the entropy
+func fairOrderKey(entropy hashing.HashValue, reqId *coretypes.RequestID) hashing.HashValue {
+	return hashing.HashData(entropy[:], reqId[:])
+}
+
+// sortFair sorts requests in the fair order
+func sortFair(reqs []*request, entropy hashing.HashValue) {
+	keys := make(map[*request]hashing.HashValue, len(reqs))
+	for _, r := range reqs {
+		keys[r] = fairOrderKey(entropy, &r.reqId)
+	}
+	sort.Slice(reqs, func(i, j int) bool {
+		ki, kj := keys[reqs[i]], keys[reqs[j]]
+		return bytes.Compare(ki[:], kj[:]) < 0
+	})
+}
+
+// isFairOrder checks if request ids are in the fair order
+func isFairOrder(reqIds []coretypes.RequestID, entropy hashing.HashValue) bool {
+	for i := 1; i < len(reqIds); i++ {
+		prev := fairOrderKey(entropy, &reqIds[i-1])
+		next := fairOrderKey(entropy, &reqIds[i])
+		if bytes.Compare(prev[:], next[:]) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func sortIds(reqIds []coretypes.RequestID) []coretypes.RequestID {
+	ret := make([]coretypes.RequestID, len(reqIds))
+	copy(ret, reqIds)
+	sort.Slice(ret, func(i, j int) bool {
+		return bytes.Compare(ret[i][:], ret[j][:]) < 0
+	})
+	return ret
+}
+
+// verifyFairOrder checks the batch proposal against the threshold signature of its set
+func verifyFairOrder(dkshare *tcrypto.DKShare, chainID *coretypes.ChainID, msg *chain.StartProcessingBatchMsg) error {
+	if len(msg.OrderSig) == 0 {
+		return fmt.Errorf("signature of the request set is missing")
+	}
+	essence := fairOrderEssence(chainID, msg.BlockIndex, msg.SenderIndex, fairOrderSetHash(msg.RequestIds))
+	if err := dkshare.VerifyMasterSignature(essence, msg.OrderSig); err != nil {
+		return fmt.Errorf("invalid signature of the request set: %v", err)
+	}
+	if !isFairOrder(msg.RequestIds, fairOrderEntropy(msg.OrderSig)) {
+		return fmt.Errorf("requests are not in the fair order")
+	}
+	return nil
+}
+
+// fairOrderedBatch is called by the leader instead of selecting the batch in the fair ordering mode.
+// The set of requests is fixed once in the state. The batch in the fair order is returned together with
+// the threshold signature of the set, after the quorum of peers has signed the set. Returns nil until then
+func (op *operator) fairOrderedBatch() ([]*request, []byte) {
+	if op.fairOrder == nil {
+		reqs := op.selectRequestsToProcess()
+		if len(reqs) == 0 {
+			return nil, nil
+		}
+		if err := op.fixFairOrderSet(takeIds(reqs)); err != nil {
+			op.log.Errorf("failed to fix the request set of the batch: %v", err)
+			return nil, nil
+		}
+	}
+	if op.fairOrder.orderSig == nil {
+		op.sendFairOrderSet()
+		return nil, nil
+	}
+	reqs := op.collectProcessableBatch(op.fairOrder.reqIds)
+	if len(reqs) != len(op.fairOrder.reqIds) {
+		// requests of the set remain in the backlog until the state changes
+		op.log.Warnf("some requests of the fixed set are not ready to process")
+		return nil, nil
+	}
+	sortFair(reqs, fairOrderEntropy(op.fairOrder.orderSig))
+	return reqs, op.fairOrder.orderSig
+}
+
+func (op *operator) fixFairOrderSet(reqIds []coretypes.RequestID) error {
+	set := &fairOrderSet{
+		reqIds:  sortIds(reqIds),
+		setHash: fairOrderSetHash(reqIds),
+		shares:  make(map[uint16]tbdn.SigShare),
+	}
+	ownShare, err := op.dkshare.SignShare(op.fairOrderEssence(op.peerIndex(), set.setHash))
+	if err != nil {
+		return err
+	}
+	set.shares[op.peerIndex()] = ownShare
+	op.fairOrder = set
+	op.log.Debugf("request set of the batch fixed: %s, reqs: %+v", set.setHash.String(), idsShortStr(set.reqIds))
+	op.recoverFairOrderSig()
+	return nil
+}
+
+// sendFairOrderSet sends the fixed set with own signature share to subordinates, periodically
+func (op *operator) sendFairOrderSet() {
+	if op.env.now().Before(op.fairOrder.nextResend) {
+		return
+	}
+	msg := &chain.FairOrderShareMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{
+			BlockIndex:  op.mustStateIndex(),
+			SenderIndex: op.peerIndex(),
+		},
+		SetHash:  op.fairOrder.setHash,
+		SigShare: op.fairOrder.shares[op.peerIndex()],
+	}
+	numSucc := op.chain.SendMsgToCommitteePeers(chain.MsgFairOrderShare, util.MustBytes(msg), op.env.now().UnixNano())
+	op.log.Debugf("%d 'msgFairOrderShare' messages sent to peers", numSucc)
+	op.fairOrder.nextResend = op.env.now().Add(fairOrderResendPeriod)
+}
+
+// recoverFairOrderSig recovers the threshold signature of the set when the quorum of shares is collected
+func (op *operator) recoverFairOrderSig() {
+	if op.fairOrder.orderSig != nil || len(op.fairOrder.shares) < int(op.quorum()) {
+		return
+	}
+	sigShares := make([][]byte, 0, len(op.fairOrder.shares))
+	for _, s := range op.fairOrder.shares {
+		sigShares = append(sigShares, s)
+	}
+	sig, err := op.dkshare.RecoverMasterSignature(sigShares, op.fairOrderEssence(op.peerIndex(), op.fairOrder.setHash))
+	if err != nil {
+		op.log.Errorf("failed to recover the signature of the request set: %v", err)
+		return
+	}
+	op.fairOrder.orderSig = sig
+}
+
+func (op *operator) fairOrderEssence(leaderIndex uint16, setHash hashing.HashValue) []byte {
+	return fairOrderEssence(op.chain.ID(), op.mustStateIndex(), leaderIndex, setHash)
+}
+
+// EventFairOrderShareMsg the set of requests of the leader or the signature share of it from the subordinate
+func (op *operator) EventFairOrderShareMsg(msg *chain.FairOrderShareMsg) {
+	op.eventFairOrderShareMsgCh <- msg
+}
+
+// eventFairOrderShareMsg internal handler
+func (op *operator) eventFairOrderShareMsg(msg *chain.FairOrderShareMsg) {
+	op.log.Debugw("EventFairOrderShareMsg",
+		"sender", msg.SenderIndex,
+		"set hash", msg.SetHash.String(),
+	)
+	stateIndex, ok := op.blockIndex()
+	if !ok || msg.BlockIndex != stateIndex || !op.fairOrdering {
+		return
+	}
+	// the leader receives shares of its own set, the subordinate receives the set of the leader
+	leaderIndex := msg.SenderIndex
+	if op.iAmCurrentLeader() {
+		leaderIndex = op.peerIndex()
+	}
+	// the share is verified the same way as the signature of the leader: it must belong to the sender
+	essence := op.fairOrderEssence(leaderIndex, msg.SetHash)
+	if err := verifyLeaderSig(op.dkshare, msg.SenderIndex, essence, msg.SigShare); err != nil {
+		op.log.Warnf("EventFairOrderShareMsg: invalid signature share from peer #%d: %v", msg.SenderIndex, err)
+		return
+	}
+	if op.iAmCurrentLeader() {
+		op.receiveFairOrderShare(msg)
+	} else {
+		op.signFairOrderSet(msg)
+	}
+	op.takeAction()
+}
+
+// receiveFairOrderShare the leader collects signature shares of its set
+func (op *operator) receiveFairOrderShare(msg *chain.FairOrderShareMsg) {
+	if op.fairOrder == nil || msg.SetHash != op.fairOrder.setHash {
+		return
+	}
+	op.fairOrder.shares[msg.SenderIndex] = msg.SigShare
+	op.recoverFairOrderSig()
+}
+
+// signFairOrderSet the subordinate signs the set of the current leader, but only one set of each leader
+// in the state, and sends the signature share back
+func (op *operator) signFairOrderSet(msg *chain.FairOrderShareMsg) {
+	if leader, _ := op.currentLeader(); msg.SenderIndex != leader {
+		op.log.Debugf("EventFairOrderShareMsg: peer #%d is not the current leader", msg.SenderIndex)
+		return
+	}
+	if signed, ok := op.fairOrderSigned[msg.SenderIndex]; ok && signed != msg.SetHash {
+		op.log.Warnf("EventFairOrderShareMsg: leader #%d asked to sign another request set in the same state",
+			msg.SenderIndex)
+		return
+	}
+	share, err := op.dkshare.SignShare(op.fairOrderEssence(msg.SenderIndex, msg.SetHash))
+	if err != nil {
+		op.log.Errorf("failed to sign the request set: %v", err)
+		return
+	}
+	op.fairOrderSigned[msg.SenderIndex] = msg.SetHash
+	reply := &chain.FairOrderShareMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{
+			BlockIndex:  msg.BlockIndex,
+			SenderIndex: op.peerIndex(),
+		},
+		SetHash:  msg.SetHash,
+		SigShare: share,
+	}
+	if err := op.chain.SendMsg(msg.SenderIndex, chain.MsgFairOrderShare, util.MustBytes(reply)); err != nil {
+		op.log.Error(err)
+	}
+}
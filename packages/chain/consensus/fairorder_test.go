@@ -0,0 +1,120 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortFair(t *testing.T) {
+	entropy := hashing.HashStrings("entropy")
+	reqs := testRequests(1, 2, 3, 4, 5, 6, 7, 8)
+	sortFair(reqs, entropy)
+	require.True(t, isFairOrder(takeIds(reqs), entropy))
+
+	// the order doesn't depend on the order of the selection
+	selection := testRequests(1, 2, 3, 4, 5, 6, 7, 8)
+	reversed := make([]*request, len(selection))
+	for i := range selection {
+		reversed[len(selection)-1-i] = selection[i]
+	}
+	sortFair(reversed, entropy)
+	require.EqualValues(t, takeIds(reqs), takeIds(reversed))
+
+	// another entropy leads to another order
+	other := testRequests(1, 2, 3, 4, 5, 6, 7, 8)
+	sortFair(other, hashing.HashStrings("other entropy"))
+	require.NotEqualValues(t, takeIds(reqs), takeIds(other))
+	require.False(t, isFairOrder(takeIds(other), entropy))
+}
+
+func TestIsFairOrder(t *testing.T) {
+	entropy := hashing.HashStrings("entropy")
+	require.True(t, isFairOrder(nil, entropy))
+	require.True(t, isFairOrder(takeIds(testRequests(1)), entropy))
+
+	reqs := testRequests(1, 2, 3)
+	sortFair(reqs, entropy)
+	ids := takeIds(reqs)
+	require.True(t, isFairOrder(ids, entropy))
+
+	ids[0], ids[2] = ids[2], ids[0]
+	require.False(t, isFairOrder(ids, entropy))
+
+	// the same request can't be included twice
+	require.False(t, isFairOrder([]coretypes.RequestID{ids[1], ids[1]}, entropy))
+}
+
+func TestFairOrderSetHash(t *testing.T) {
+	ids := takeIds(testRequests(1, 2, 3))
+	h := fairOrderSetHash(ids)
+	require.EqualValues(t, h, fairOrderSetHash([]coretypes.RequestID{ids[2], ids[0], ids[1]}))
+	require.NotEqualValues(t, h, fairOrderSetHash(ids[:2]))
+	// the hash is taken over the copy
+	require.EqualValues(t, takeIds(testRequests(1, 2, 3)), ids)
+}
+
+// orderSig returns the threshold signature of the request set of the leader, signed by the peers
+func orderSig(t *testing.T, dks []*tcrypto.DKShare, peers []int, chainID *coretypes.ChainID, leader uint16, ids []coretypes.RequestID) []byte {
+	essence := fairOrderEssence(chainID, 5, leader, fairOrderSetHash(ids))
+	shares := make([][]byte, 0, len(peers))
+	for _, i := range peers {
+		s, err := dks[i].SignShare(essence)
+		require.NoError(t, err)
+		shares = append(shares, s)
+	}
+	sig, err := dks[leader].RecoverMasterSignature(shares, essence)
+	require.NoError(t, err)
+	return sig
+}
+
+func TestVerifyFairOrder(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	chainID := coretypes.ChainID(*dks[0].Address)
+	const leader = 2
+
+	newMsg := func(sig []byte, reqs []*request) *chain.StartProcessingBatchMsg {
+		return &chain.StartProcessingBatchMsg{
+			PeerMsgHeader: chain.PeerMsgHeader{BlockIndex: 5, SenderIndex: leader},
+			RequestIds:    takeIds(reqs),
+			OrderSig:      sig,
+		}
+	}
+	reqs := testRequests(1, 2, 3, 4, 5)
+	sig := orderSig(t, dks, []int{0, 2, 3}, &chainID, leader, takeIds(reqs))
+	// any quorum of peers produces the same signature
+	require.EqualValues(t, sig, orderSig(t, dks, []int{1, 2, 3}, &chainID, leader, takeIds(reqs)))
+
+	sortFair(reqs, fairOrderEntropy(sig))
+	require.NoError(t, verifyFairOrder(dks[0], &chainID, newMsg(sig, reqs)))
+
+	t.Run("not in the fair order", func(t *testing.T) {
+		wrong := append([]*request{reqs[4]}, reqs[:4]...)
+		require.Error(t, verifyFairOrder(dks[0], &chainID, newMsg(sig, wrong)))
+	})
+	t.Run("signature missing", func(t *testing.T) {
+		require.Error(t, verifyFairOrder(dks[0], &chainID, newMsg(nil, reqs)))
+	})
+	t.Run("another set", func(t *testing.T) {
+		sub := reqs[:4]
+		require.Error(t, verifyFairOrder(dks[0], &chainID, newMsg(sig, sub)))
+	})
+	t.Run("set of another leader", func(t *testing.T) {
+		msg := newMsg(sig, reqs)
+		msg.SenderIndex = 1
+		require.Error(t, verifyFairOrder(dks[0], &chainID, msg))
+	})
+	t.Run("signed by less than quorum", func(t *testing.T) {
+		essence := fairOrderEssence(&chainID, 5, leader, fairOrderSetHash(takeIds(reqs)))
+		s, err := dks[leader].SignShare(essence)
+		require.NoError(t, err)
+		require.Error(t, verifyFairOrder(dks[0], &chainID, newMsg(s.Value(), reqs)))
+	})
+}
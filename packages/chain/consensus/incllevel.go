@@ -20,7 +20,7 @@ func (op *operator) setFinalizedTransaction(txid *valuetransaction.ID) {
 		op.log.Warn("duplicated transaction to follow")
 	}
 	op.postedResultTxid = txid
-	op.nextPullInclusionLevel = time.Now().Add(initialTimeoutPullInclusionState)
+	op.nextPullInclusionLevel = op.env.now().Add(initialTimeoutPullInclusionState)
 	op.log.Debugf("finalized tx set to %s", txid.String())
 }
 
@@ -47,5 +47,5 @@ func (op *operator) checkInclusionLevel(txid *valuetransaction.ID, level byte) {
 }
 
 func (op *operator) setNextPullInclusionStageDeadline() {
-	op.nextPullInclusionLevel = time.Now().Add(periodPullInclusionStage)
+	op.nextPullInclusionLevel = op.env.now().Add(periodPullInclusionStage)
 }
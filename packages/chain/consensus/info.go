@@ -0,0 +1,99 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+)
+
+// maxRecentRounds is the number of the last rounds kept for introspection
+const maxRecentRounds = 20
+
+// ConsensusInfo returns the snapshot of the state of the operator. It is safe for concurrent access
+func (op *operator) ConsensusInfo() *chain.ConsensusInfo {
+	op.concurrentAccessMutex.RLock()
+	defer op.concurrentAccessMutex.RUnlock()
+
+	if op.info == nil {
+		return &chain.ConsensusInfo{Stage: stages[consensusStageNoSync].name}
+	}
+	ret := *op.info
+	ret.RecentRounds = make([]*chain.RoundInfo, len(op.recentRounds))
+	// the latest first
+	for i, r := range op.recentRounds {
+		ret.RecentRounds[len(op.recentRounds)-1-i] = r
+	}
+	return &ret
+}
+
+// publishInfo updates the snapshot of the state of the operator. It is called from the event loop
+func (op *operator) publishInfo() {
+	info := &chain.ConsensusInfo{
+		Stage:   stages[op.consensusStage].name,
+		Backlog: len(op.requests),
+	}
+	info.Leader, _ = op.currentLeader()
+	info.IsLeader = op.iAmCurrentLeader()
+	info.Synced = op.consensusStage != consensusStageNoSync
+	switch op.consensusStage {
+	case consensusStageResultTransactionBooked,
+		consensusStageLeaderCalculationsStarted, consensusStageLeaderCalculationsFinished, consensusStageLeaderResultFinalized,
+		consensusStageSubCalculationsStarted, consensusStageSubCalculationsFinished, consensusStageSubResultFinalized:
+		info.RoundInProgress = true
+	}
+	if op.emergency != nil {
+		info.Emergency = true
+		info.EmergencyDelegate = op.emergency.delegate
+		info.EmergencyUntil = op.emergency.untilBlockIndex
+	}
+	if op.currentState != nil {
+		info.StateIndex = op.currentState.BlockIndex()
+		info.StateTimestamp = time.Unix(0, op.currentState.Timestamp())
+	}
+	info.Tentative = op.tentative != nil
+	nowis := op.env.now()
+	for _, req := range op.requests {
+		if op.isOverdue(req, nowis) {
+			info.Overdue++
+		}
+	}
+
+	op.concurrentAccessMutex.Lock()
+	defer op.concurrentAccessMutex.Unlock()
+	op.info = info
+}
+
+// markRoundStarted records the start of calculations of the round on the node
+func (op *operator) markRoundStarted() {
+	if op.roundStarted.IsZero() {
+		op.roundStarted = op.env.now()
+	}
+}
+
+// recordRound records the round which produced the new state
+func (op *operator) recordRound(msg *chain.StateTransitionMsg) {
+	newIndex := msg.VariableState.BlockIndex()
+	if prevIndex, ok := op.blockIndex(); ok && newIndex <= prevIndex {
+		return
+	}
+	round := &chain.RoundInfo{
+		StateIndex:  newIndex,
+		Finished:    op.env.now(),
+		NumRequests: len(msg.RequestIDs),
+	}
+	round.Leader, _ = op.currentLeader()
+	if !op.roundStarted.IsZero() {
+		round.Duration = round.Finished.Sub(op.roundStarted)
+	}
+	op.roundStarted = time.Time{}
+
+	op.concurrentAccessMutex.Lock()
+	defer op.concurrentAccessMutex.Unlock()
+	op.recentRounds = append(op.recentRounds, round)
+	if len(op.recentRounds) > maxRecentRounds {
+		op.recentRounds = op.recentRounds[len(op.recentRounds)-maxRecentRounds:]
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+// Spam protection of the committee.
+// Incoming requests are checked against the request intake policy of the chain (root.RequestIntakePolicy)
+// before they are taken into the backlog, so the spammer can't flood the memory of the node and the
+// bandwidth of the committee with requests and notifications about them.
+// Requests which don't transfer the minimum fee or deposit are rejected and never processed by the node.
+// The check depends only on the state, so all nodes of the committee agree on it.
+// The rate limit is advisory: it is counted by the clock of each node, so nodes may disagree on it.
+// Requests of the sender over the limit are taken into the backlog, but the node defers them, i.e. it
+// doesn't notify the leader about them and doesn't select them for the batch until the limit allows.
+// Deferred requests are processed eventually, so the tokens they transfer are never stuck.
+// The policy is read from the current state, so the node accepts all requests until it knows the state
+
+// intakeWindow is the period the rate limit of each sender is counted for
+const intakeWindow = time.Minute
+
+// intakeLimiter counts requests of each sender in the current window
+type intakeLimiter struct {
+	windowStart time.Time
+	counts      map[coretypes.AgentID]int64
+}
+
+func newIntakeLimiter(now time.Time) *intakeLimiter {
+	return &intakeLimiter{
+		windowStart: now,
+		counts:      make(map[coretypes.AgentID]int64),
+	}
+}
+
+// deferUntil counts the request of the sender and returns the time until the request is deferred.
+// Requests within the limit are not deferred, the zero time is returned. Requests over the limit are
+// spread over the following windows, limit requests per window
+func (l *intakeLimiter) deferUntil(sender coretypes.AgentID, limit int64, now time.Time) time.Time {
+	if now.Sub(l.windowStart) >= intakeWindow {
+		l.windowStart = now
+		l.counts = make(map[coretypes.AgentID]int64)
+	}
+	n := l.counts[sender]
+	l.counts[sender]++
+	if n < limit {
+		return time.Time{}
+	}
+	return l.windowStart.Add(time.Duration(n/limit) * intakeWindow)
+}
+
+// intakePolicy returns the request intake policy and the state of the root contract,
+// false if the policy is not known or there is nothing to check
+func (op *operator) intakePolicy() (*root.RequestIntakePolicy, kv.KVStoreReader, bool) {
+	if op.currentState == nil {
+		return nil, nil, false
+	}
+	rootState := subrealm.New(op.currentState.Variables(), kv.Key(root.Interface.Hname().Bytes()))
+	policy := root.GetRequestIntakePolicy(rootState)
+	if policy.RateLimit == 0 && policy.MinFee == 0 && policy.MinDeposit == 0 {
+		return policy, nil, false
+	}
+	return policy, rootState, true
+}
+
+// isChainOwner returns true if the request is sent by the chain owner. Its requests are not limited
+func isChainOwner(rootState kv.KVStoreReader, sender coretypes.AgentID) (bool, error) {
+	chainOwner, _, err := codec.DecodeAgentID(rootState.MustGet(root.VarChainOwnerID))
+	if err != nil {
+		return false, err
+	}
+	return sender == chainOwner, nil
+}
+
+// checkRequestIntake returns an error if the request doesn't transfer the fee or the deposit
+// required by the request intake policy of the chain
+func (op *operator) checkRequestIntake(reqMsg *chain.RequestMsg) error {
+	policy, rootState, ok := op.intakePolicy()
+	if !ok || (policy.MinFee == 0 && policy.MinDeposit == 0) {
+		return nil
+	}
+	ref := &sctransaction.RequestRef{Tx: reqMsg.Transaction, Index: reqMsg.Index}
+	if owner, err := isChainOwner(rootState, ref.SenderAgentID()); err != nil || owner {
+		return err
+	}
+	transfer := ref.RequestSection().Transfer()
+	balanceOf := func(col balance.Color) int64 {
+		if transfer == nil {
+			return 0
+		}
+		return transfer.Balance(col)
+	}
+	var feeColor balance.Color
+	var err error
+	if policy.MinFee > 0 {
+		feeColor, _, _, err = root.GetDefaultFeeInfo(rootState)
+		if err != nil {
+			return err
+		}
+		if fee := balanceOf(feeColor); fee < policy.MinFee {
+			return fmt.Errorf("transferred fee %d is less than minimum %d", fee, policy.MinFee)
+		}
+	}
+	if policy.MinDeposit > 0 {
+		// the deposit comes on top of the fee, if the fee is paid in iotas
+		required := policy.MinDeposit
+		if policy.MinFee > 0 && feeColor == balance.ColorIOTA {
+			required += policy.MinFee
+		}
+		if iotas := balanceOf(balance.ColorIOTA); iotas < required {
+			return fmt.Errorf("transferred %d iotas, the deposit of %d iotas is required", iotas, required)
+		}
+	}
+	return nil
+}
+
+// applyRateLimit defers the new request if its sender exceeded the rate limit of the chain
+func (op *operator) applyRateLimit(req *request) {
+	policy, rootState, ok := op.intakePolicy()
+	if !ok || policy.RateLimit == 0 {
+		return
+	}
+	sender := (&sctransaction.RequestRef{Tx: req.reqTx, Index: req.reqId.Index()}).SenderAgentID()
+	if owner, err := isChainOwner(rootState, sender); err != nil || owner {
+		return
+	}
+	req.deferredUntil = op.intakeLimiter.deferUntil(sender, policy.RateLimit, op.env.now())
+	if !req.deferredUntil.IsZero() {
+		op.log.Infof("request %s deferred until %v: sender %s exceeded the rate limit of %d requests per minute",
+			req.reqId.Short(), req.deferredUntil, sender, policy.RateLimit)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntakeLimiterDeferUntil(t *testing.T) {
+	start := time.Unix(1000, 0)
+	l := newIntakeLimiter(start)
+	sender := coretypes.NewAgentIDFromAddress(address.Random())
+	other := coretypes.NewAgentIDFromAddress(address.Random())
+
+	// requests within the limit are not deferred
+	for i := 0; i < 3; i++ {
+		require.True(t, l.deferUntil(sender, 3, start.Add(time.Second)).IsZero())
+	}
+	// requests over the limit are spread over the following windows
+	for i := 0; i < 3; i++ {
+		require.Equal(t, start.Add(intakeWindow), l.deferUntil(sender, 3, start.Add(2*time.Second)))
+	}
+	require.Equal(t, start.Add(2*intakeWindow), l.deferUntil(sender, 3, start.Add(3*time.Second)))
+
+	// senders are counted separately
+	require.True(t, l.deferUntil(other, 3, start.Add(4*time.Second)).IsZero())
+}
+
+func TestIntakeLimiterNewWindow(t *testing.T) {
+	start := time.Unix(1000, 0)
+	l := newIntakeLimiter(start)
+	sender := coretypes.NewAgentIDFromAddress(address.Random())
+
+	require.True(t, l.deferUntil(sender, 1, start).IsZero())
+	require.False(t, l.deferUntil(sender, 1, start.Add(intakeWindow-time.Millisecond)).IsZero())
+
+	// the count starts over in the next window
+	next := start.Add(intakeWindow)
+	require.True(t, l.deferUntil(sender, 1, next).IsZero())
+	require.Equal(t, next.Add(intakeWindow), l.deferUntil(sender, 1, next))
+}
+
+func TestRequestIsDeferred(t *testing.T) {
+	now := time.Unix(1000, 0)
+	req := testRequests(1)[0]
+	require.False(t, req.isDeferred(now))
+
+	req.deferredUntil = now.Add(time.Second)
+	require.True(t, req.isDeferred(now))
+	require.False(t, req.isDeferred(now.Add(time.Second)))
+}
@@ -0,0 +1,46 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+// blockInterval returns the minimum and the maximum time between blocks set in the root contract,
+// see root.GetBlockInterval
+func (op *operator) blockInterval() (time.Duration, time.Duration) {
+	if op.currentState == nil {
+		return 0, 0
+	}
+	return root.GetBlockInterval(subrealm.New(op.currentState.Variables(), kv.Key(root.Interface.Hname().Bytes())))
+}
+
+// batchTiming returns whether the batch with the timestamp may be started now and whether it may be empty
+func (op *operator) batchTiming(ts int64) (bool, bool) {
+	minInterval, maxInterval := op.blockInterval()
+	elapsed := time.Duration(ts - op.stateTx.MustState().Timestamp())
+	if elapsed < minInterval {
+		return false, false
+	}
+	return true, maxInterval > 0 && elapsed >= maxInterval
+}
+
+// checkBlockInterval returns an error if the batch proposed by the leader violates the timing
+// of blocks of the chain
+func (op *operator) checkBlockInterval(msg *chain.StartProcessingBatchMsg) error {
+	canStart, canBeEmpty := op.batchTiming(msg.Timestamp)
+	if !canStart {
+		return fmt.Errorf("the minimum block interval has not passed")
+	}
+	if len(msg.RequestIds) == 0 && !canBeEmpty {
+		return fmt.Errorf("empty batch before the maximum block interval has passed")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+// setBlockInterval sets the block interval in the root contract of the current state of the operator
+func (o *testOperator) setBlockInterval(min, max time.Duration) {
+	rootState := subrealm.New(o.op().currentState.Variables(), kv.Key(root.Interface.Hname().Bytes()))
+	rootState.Set(root.VarMinBlockInterval, codec.EncodeInt64(min.Milliseconds()))
+	rootState.Set(root.VarMaxBlockInterval, codec.EncodeInt64(max.Milliseconds()))
+}
+
+func batchAfter(o *testOperator, d time.Duration, reqIds ...coretypes.RequestID) *chain.StartProcessingBatchMsg {
+	return &chain.StartProcessingBatchMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{BlockIndex: 5, SenderIndex: 1},
+		Timestamp:     o.op().stateTx.MustState().Timestamp() + d.Nanoseconds(),
+		RequestIds:    reqIds,
+	}
+}
+
+func TestBlockIntervalDefault(t *testing.T) {
+	o := newTestOperator(t, newTestDKShares(t, 4, 3), 0, 0)
+	o.stateTransition(5)
+	var reqid coretypes.RequestID
+
+	require.NoError(t, o.op().checkBlockInterval(batchAfter(o, time.Nanosecond, reqid)))
+	// empty batches are not produced
+	require.Error(t, o.op().checkBlockInterval(batchAfter(o, 24*time.Hour)))
+}
+
+func TestBlockInterval(t *testing.T) {
+	o := newTestOperator(t, newTestDKShares(t, 4, 3), 0, 0)
+	o.stateTransition(5)
+	o.setBlockInterval(2*time.Second, time.Minute)
+	var reqid coretypes.RequestID
+
+	require.Error(t, o.op().checkBlockInterval(batchAfter(o, time.Second, reqid)))
+	require.NoError(t, o.op().checkBlockInterval(batchAfter(o, 2*time.Second, reqid)))
+
+	require.Error(t, o.op().checkBlockInterval(batchAfter(o, 30*time.Second)))
+	require.NoError(t, o.op().checkBlockInterval(batchAfter(o, time.Minute)))
+
+	canStart, canBeEmpty := o.op().batchTiming(batchAfter(o, 10*time.Second).Timestamp)
+	require.True(t, canStart)
+	require.False(t, canBeEmpty)
+}
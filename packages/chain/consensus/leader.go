@@ -5,6 +5,9 @@ package consensus
 
 func (op *operator) currentLeader() (uint16, bool) {
 	_, ok := op.blockIndex()
+	if op.isEmergency() {
+		return op.emergency.delegate, ok
+	}
 	return op.peerPermutation.Current(), ok
 }
 
@@ -0,0 +1,256 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+)
+
+// The file contains pipelining of consensus rounds.
+// Without pipelining the committee starts the next batch only after the anchor transaction of the previous
+// one is confirmed by the ledger. With pipelining, as soon as the result of the round is finalized and posted,
+// the node takes the state produced by the round as the tentative state and starts the next round on it,
+// while the anchor transaction is waiting for the confirmation. The anchor transaction of the next round
+// spends the chain output of the unconfirmed one.
+// At most one anchor transaction is unconfirmed at a time: the round on the tentative state is finalized,
+// but the next one is not started until the tentative state is confirmed.
+// If the anchor transaction is rejected, the node rolls back to the last confirmed state and starts
+// the round from scratch. If it is not confirmed in time, the round on the tentative state is abandoned
+// and the node waits for the anchor as without pipelining.
+// Pipelining is enabled by the 'consensus.pipelining' parameter, which must be the same on all nodes of the committee
+
+// tentativeAnchorTimeout is the time the node waits for the confirmation of the tentative state
+const tentativeAnchorTimeout = 1 * time.Minute
+
+// finalizedRound is the result of the round of the current state which was finalized and posted
+type finalizedRound struct {
+	// tx is the anchor transaction. On subordinates it is not signed, so its ID is not the ID of the posted transaction
+	tx    *sctransaction.Transaction
+	txid  valuetransaction.ID
+	block state.Block
+}
+
+// tentativeState is the state produced by the finalized round, whose anchor transaction is not confirmed yet
+type tentativeState struct {
+	txid   valuetransaction.ID
+	reqIds map[coretypes.RequestID]bool
+	// the last confirmed state, the operator rolls back to it if the anchor transaction is rejected
+	prevStateTx  *sctransaction.Transaction
+	prevState    state.VirtualState
+	prevBalances map[valuetransaction.ID][]*balance.Balance
+	deadline     time.Time
+	// the inclusion level of the anchor transaction is pulled periodically
+	nextPullInclusionLevel time.Time
+}
+
+// stateTxID is the ID of the anchor transaction of the current state, including the tentative one.
+// It is the same on all nodes of the committee
+func (op *operator) stateTxID() valuetransaction.ID {
+	if op.tentative != nil {
+		return op.tentative.txid
+	}
+	return op.stateTx.ID()
+}
+
+// isTentativeRequest returns true if the request is processed in the tentative state
+func (op *operator) isTentativeRequest(reqId *coretypes.RequestID) bool {
+	return op.tentative != nil && op.tentative.reqIds[*reqId]
+}
+
+// setFinalizedRound is called when the result of the round is finalized and posted. With pipelining,
+// the next round is started on the produced state
+func (op *operator) setFinalizedRound(tx *sctransaction.Transaction, txid valuetransaction.ID, block state.Block) {
+	if !op.pipelining || tx == nil || block == nil {
+		return
+	}
+	op.finalizedRound = &finalizedRound{tx: tx, txid: txid, block: block}
+	op.startPipelinedRound()
+}
+
+// startPipelinedRound switches the operator to the state produced by the finalized round and starts the next round
+func (op *operator) startPipelinedRound() {
+	fr := op.finalizedRound
+	if fr == nil || op.tentative != nil || op.currentState == nil {
+		return
+	}
+	newState := op.currentState.Clone()
+	if err := newState.ApplyBlock(fr.block); err != nil {
+		op.log.Errorf("pipelining: can't apply the block to the state: %v", err)
+		return
+	}
+	if newState.Hash() != fr.tx.MustState().StateHash() {
+		op.log.Errorf("pipelining: state hash mismatch. Expected %s, got %s",
+			fr.tx.MustState().StateHash().String(), newState.Hash().String())
+		return
+	}
+	reqIds := make(map[coretypes.RequestID]bool)
+	for _, rid := range fr.block.RequestIDs() {
+		reqIds[*rid] = true
+	}
+	now := op.env.now()
+	tentative := &tentativeState{
+		txid:                   fr.txid,
+		reqIds:                 reqIds,
+		prevStateTx:            op.stateTx,
+		prevState:              op.currentState,
+		prevBalances:           op.balances,
+		deadline:               now.Add(tentativeAnchorTimeout),
+		nextPullInclusionLevel: now.Add(initialTimeoutPullInclusionState),
+	}
+	balances := tentativeBalances(op.balances, fr.tx, fr.txid, op.chain.Address())
+
+	// the round is recorded when it produces the tentative state, not when the state is confirmed
+	op.recordRound(&chain.StateTransitionMsg{
+		VariableState:     newState,
+		AnchorTransaction: fr.tx,
+		RequestIDs:        fr.block.RequestIDs(),
+	})
+	op.tentative = tentative
+	op.setNewSCState(fr.tx, newState, true)
+	op.balances = balances
+
+	leader, _ := op.currentLeader()
+	op.log.Infof("PIPELINED STATE #%d, leader: %d iAmTheLeader: %v, anchor tx: %s (not confirmed)",
+		op.mustStateIndex(), leader, op.iAmCurrentLeader(), fr.txid.String())
+	if op.iAmCurrentLeader() {
+		op.setNextConsensusStage(consensusStageLeaderStarting)
+	} else {
+		op.setNextConsensusStage(consensusStageSubStarting)
+	}
+	op.takeAction()
+}
+
+// confirmTentativeState is called on the state transition. Returns true if the new state is the confirmation
+// of the tentative state: the operator keeps the round in progress and only takes the anchor transaction
+func (op *operator) confirmTentativeState(msg *chain.StateTransitionMsg) bool {
+	if op.tentative == nil {
+		return false
+	}
+	t := op.tentative
+	op.tentative = nil
+	if msg.VariableState.BlockIndex() != op.mustStateIndex() || msg.AnchorTransaction.ID() != t.txid {
+		// the other state was confirmed. The tentative state is discarded, the round on it is abandoned
+		op.log.Warnf("pipelining: tentative state #%d (tx %s) discarded, confirmed state #%d (tx %s)",
+			op.mustStateIndex(), t.txid.String(), msg.VariableState.BlockIndex(), msg.AnchorTransaction.ID().String())
+		return false
+	}
+	op.stateTx = msg.AnchorTransaction
+	op.log.Infof("pipelining: tentative state #%d confirmed, tx: %s", op.mustStateIndex(), t.txid.String())
+
+	if err := op.deleteCompletedRequests(); err != nil {
+		op.log.Errorf("deleteCompletedRequests: %v", err)
+	}
+	// the state manager ignores blocks which are not next to its state, so the block calculated
+	// on the tentative state is sent again
+	if block := op.currentRoundBlock(); block != nil {
+		go func() {
+			op.chain.ReceiveMessage(chain.PendingBlockMsg{
+				Block: block,
+			})
+		}()
+	}
+	// the round on the tentative state may be finalized already
+	op.startPipelinedRound()
+	return true
+}
+
+// currentRoundBlock is the block calculated by the node in the current round, if any
+func (op *operator) currentRoundBlock() state.Block {
+	if op.finalizedRound != nil {
+		return op.finalizedRound.block
+	}
+	if op.leaderStatus != nil && op.leaderStatus.batch != nil {
+		return op.leaderStatus.batch
+	}
+	return op.sentResultBlock
+}
+
+// rollbackTentativeState returns the operator to the last confirmed state. If the anchor transaction
+// was rejected, the round is started from scratch. Otherwise the node waits for the anchor transaction
+func (op *operator) rollbackTentativeState(rejected bool) {
+	t := op.tentative
+	op.tentative = nil
+	op.setNewSCState(t.prevStateTx, t.prevState, true)
+	op.balances = t.prevBalances
+	if rejected {
+		op.log.Warnf("pipelining: anchor tx %s rejected. Rolled back to the state #%d", t.txid.String(), op.mustStateIndex())
+		op.startLeaderTerm()
+		return
+	}
+	op.log.Warnf("pipelining: anchor tx %s not confirmed in %v. Waiting for it in the state #%d",
+		t.txid.String(), tentativeAnchorTimeout, op.mustStateIndex())
+	op.setNextConsensusStage(consensusStageResultTransactionBooked)
+	op.setFinalizedTransaction(&t.txid)
+}
+
+// checkTentativeState pulls the inclusion level of the anchor transaction of the tentative state
+// and abandons the tentative state if it is not confirmed in time
+func (op *operator) checkTentativeState() {
+	if op.tentative == nil {
+		return
+	}
+	now := op.env.now()
+	if now.After(op.tentative.deadline) {
+		op.rollbackTentativeState(false)
+		return
+	}
+	if now.After(op.tentative.nextPullInclusionLevel) {
+		addr := op.chain.Address()
+		if err := op.env.requestInclusionLevel(&op.tentative.txid, &addr); err != nil {
+			op.log.Errorf("RequestInclusionLevelFromNode: %v", err)
+		}
+		op.tentative.nextPullInclusionLevel = now.Add(periodPullInclusionStage)
+	}
+}
+
+// checkTentativeInclusionLevel processes the inclusion level of the anchor transaction of the tentative state.
+// Returns false if the transaction is not the anchor of the tentative state
+func (op *operator) checkTentativeInclusionLevel(txid *valuetransaction.ID, level byte) bool {
+	if op.tentative == nil || op.tentative.txid != *txid {
+		return false
+	}
+	switch level {
+	case waspconn.TransactionInclusionLevelBooked:
+		// the transaction is in the ledger and will be confirmed, the deadline is extended
+		op.tentative.deadline = op.env.now().Add(tentativeAnchorTimeout)
+		op.tentative.nextPullInclusionLevel = op.env.now().Add(periodPullInclusionStage)
+	case waspconn.TransactionInclusionLevelRejected:
+		op.rollbackTentativeState(true)
+	}
+	return true
+}
+
+// tentativeBalances are balances of the chain address after the transaction: outputs consumed by the
+// transaction are removed and its outputs to the address are added
+func tentativeBalances(balances map[valuetransaction.ID][]*balance.Balance, tx *sctransaction.Transaction, txid valuetransaction.ID, addr address.Address) map[valuetransaction.ID][]*balance.Balance {
+	consumed := make(map[valuetransaction.ID]bool)
+	tx.Inputs().ForEach(func(outputID valuetransaction.OutputID) bool {
+		if outputID.Address() == addr {
+			consumed[outputID.TransactionID()] = true
+		}
+		return true
+	})
+	ret := make(map[valuetransaction.ID][]*balance.Balance)
+	for id, bals := range balances {
+		if !consumed[id] {
+			ret[id] = bals
+		}
+	}
+	tx.Outputs().ForEach(func(a address.Address, bals []*balance.Balance) bool {
+		if a == addr {
+			ret[txid] = bals
+		}
+		return true
+	})
+	return ret
+}
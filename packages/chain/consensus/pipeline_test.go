@@ -0,0 +1,95 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/chain/transcript"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTentativeBalances(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+	txid := o.anchor.ID()
+
+	other := valuetransaction.ID(hashing.RandomHash(nil))
+	otherBals := []*balance.Balance{balance.New(balance.ColorIOTA, 10)}
+	bals := tentativeBalances(map[valuetransaction.ID][]*balance.Balance{other: otherBals}, o.anchor, txid, *dks[0].Address)
+	require.Len(t, bals, 2)
+	require.Equal(t, otherBals, bals[other], "outputs not consumed by the transaction are kept")
+	require.NotEmpty(t, bals[txid], "outputs of the transaction to the chain address are added")
+}
+
+// setTentative moves the operator of the test to the tentative state with the next index,
+// as if the round was finalized with pipelining
+func (o *testOperator) setTentative(txid valuetransaction.ID) {
+	op := o.op()
+	op.pipelining = true
+	next := op.currentState.Clone()
+	next.ApplyBlockIndex(op.mustStateIndex() + 1)
+	op.tentative = &tentativeState{
+		txid:                   txid,
+		prevStateTx:            op.stateTx,
+		prevState:              op.currentState,
+		prevBalances:           op.balances,
+		deadline:               o.clock.Add(tentativeAnchorTimeout),
+		nextPullInclusionLevel: o.clock.Add(tentativeAnchorTimeout),
+	}
+	op.currentState = next
+}
+
+func (o *testOperator) inclusionLevel(txid valuetransaction.ID, level byte) {
+	o.feed(&transcript.Record{
+		Kind: transcript.KindInclusionLevel,
+		Data: transcript.InclusionLevelData(&chain.TransactionInclusionLevelMsg{TxId: &txid, Level: level}),
+	})
+}
+
+func TestPipeliningRollbackOnRejection(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+	o.stateTransition(5)
+
+	txid := valuetransaction.ID(hashing.RandomHash(nil))
+	o.setTentative(txid)
+	require.EqualValues(t, 6, o.op().mustStateIndex())
+	require.Equal(t, txid, o.op().stateTxID())
+
+	// inclusion level of another transaction doesn't affect the tentative state
+	o.inclusionLevel(valuetransaction.ID(hashing.RandomHash(nil)), waspconn.TransactionInclusionLevelRejected)
+	require.NotNil(t, o.op().tentative)
+
+	o.inclusionLevel(txid, waspconn.TransactionInclusionLevelBooked)
+	require.NotNil(t, o.op().tentative)
+	require.EqualValues(t, 6, o.op().mustStateIndex())
+
+	o.inclusionLevel(txid, waspconn.TransactionInclusionLevelRejected)
+	require.Nil(t, o.op().tentative)
+	require.EqualValues(t, 5, o.op().mustStateIndex())
+	require.Equal(t, o.anchor.ID(), o.op().stateTxID())
+}
+
+func TestPipeliningRollbackOnTimeout(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+	o.stateTransition(5)
+
+	o.setTentative(valuetransaction.ID(hashing.RandomHash(nil)))
+	o.feed(&transcript.Record{Kind: transcript.KindTimerTick, Data: transcript.TimerTickData(1)})
+	require.NotNil(t, o.op().tentative)
+
+	o.clock = o.clock.Add(tentativeAnchorTimeout + time.Second)
+	o.feed(&transcript.Record{Kind: transcript.KindTimerTick, Data: transcript.TimerTickData(2)})
+	require.Nil(t, o.op().tentative)
+	require.EqualValues(t, 5, o.op().mustStateIndex())
+	require.Equal(t, consensusStageResultTransactionBooked, o.op().consensusStage)
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"time"
+)
+
+// Ordering of requests of one client.
+// Requests posted rapidly are taken into batches in an unpredictable order. The client may chain its requests
+// by the sctransaction.ArgPredecessor argument: the request is not selected for the batch until its predecessor
+// is processed, so the chained requests are processed in FIFO order, one per batch.
+// The leader doesn't wait for the predecessor forever: if it was never posted or was rejected,
+// the dependency is ignored after predecessorTimeout.
+// The dependency is checked only by the leader when selecting requests, because the timeout is not
+// deterministic among nodes
+
+// predecessorTimeout is the time the request waits for its predecessor to be processed
+const predecessorTimeout = 2 * time.Minute
+
+// isWaitingForPredecessor returns true if the request must not be processed yet because its predecessor is not processed
+func (op *operator) isWaitingForPredecessor(req *request, nowis time.Time) bool {
+	pred, ok := req.reqTx.Requests()[req.reqId.Index()].Predecessor()
+	if !ok {
+		return false
+	}
+	if op.isRequestProcessed(pred) {
+		return false
+	}
+	if nowis.Sub(req.whenMsgReceived) > predecessorTimeout {
+		req.log.Debugf("predecessor %s is not processed in %v, ignoring the dependency", pred.Short(), predecessorTimeout)
+		return false
+	}
+	return true
+}
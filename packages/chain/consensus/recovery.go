@@ -0,0 +1,274 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"fmt"
+	"os"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm"
+)
+
+// The file contains crash recovery of the consensus round.
+// The leader persists the round (selected batch, own result and collected signature shares) in the registry
+// each time it changes between the calculation of the own result and the finalization.
+// The subordinate persists the round with its own result and signature share once it is sent to the leader.
+// If the node crashes and restarts in the same state with the same leader, it resumes the round from the
+// persisted state instead of starting a new one. Subordinates re-send their signature shares to the leader
+// until the round is finalized, so the leader collects shares it missed while it was down
+
+// persistRound saves the current round of the leader
+func (op *operator) persistRound() {
+	if op.leaderStatus == nil || op.leaderStatus.resultTx == nil {
+		return
+	}
+	blockBytes, err := util.Bytes(op.leaderStatus.batch)
+	if err != nil {
+		op.log.Errorf("persistRound: %v", err)
+		return
+	}
+	rs := &registry.RoundState{
+		ChainID:     *op.chain.ID(),
+		BlockIndex:  op.mustStateIndex(),
+		StateTxID:   op.stateTxID(),
+		LeaderIndex: op.peerIndex(),
+		BatchHash:   op.leaderStatus.batchHash,
+		Timestamp:   op.leaderStatus.timestamp,
+		RequestIDs:  takeIds(op.leaderStatus.reqs),
+		ResultTx:    op.leaderStatus.resultTx.Bytes(),
+		Block:       blockBytes,
+		SigShares:   make([]registry.RoundSigShare, 0, len(op.leaderStatus.signedResults)),
+	}
+	for i, sr := range op.leaderStatus.signedResults {
+		if sr == nil {
+			continue
+		}
+		rs.SigShares = append(rs.SigShares, registry.RoundSigShare{
+			PeerIndex:   uint16(i),
+			EssenceHash: sr.essenceHash,
+			SigShare:    sr.sigShare,
+		})
+	}
+	if err := op.env.saveRoundState(rs); err != nil {
+		op.log.Errorf("persistRound: %v", err)
+		return
+	}
+	op.roundPersisted = true
+	op.log.Infof("round state persisted: state #%d, batch hash: %s, signature shares: %d/%d",
+		rs.BlockIndex, rs.BatchHash.String(), len(rs.SigShares), op.quorum())
+}
+
+// persistSubRound saves the round of the subordinate after its signature share was sent to the leader
+func (op *operator) persistSubRound(result *vm.VMTask, leader uint16, msg *chain.SignedHashMsg) {
+	blockBytes, err := util.Bytes(result.ResultBlock)
+	if err != nil {
+		op.log.Errorf("persistSubRound: %v", err)
+		return
+	}
+	reqids := make([]coretypes.RequestID, len(result.Requests))
+	for i := range reqids {
+		reqids[i] = *result.Requests[i].RequestID()
+	}
+	rs := &registry.RoundState{
+		ChainID:     *op.chain.ID(),
+		BlockIndex:  op.mustStateIndex(),
+		StateTxID:   op.stateTxID(),
+		LeaderIndex: leader,
+		BatchHash:   msg.BatchHash,
+		Timestamp:   msg.OrigTimestamp,
+		RequestIDs:  reqids,
+		ResultTx:    result.ResultTransaction.Bytes(),
+		Block:       blockBytes,
+		SigShares: []registry.RoundSigShare{{
+			PeerIndex:   op.peerIndex(),
+			EssenceHash: msg.EssenceHash,
+			SigShare:    msg.SigShare,
+		}},
+	}
+	if err := op.env.saveRoundState(rs); err != nil {
+		op.log.Errorf("persistSubRound: %v", err)
+		return
+	}
+	op.roundPersisted = true
+	op.log.Infof("round state persisted: state #%d, batch hash: %s, leader: #%d",
+		rs.BlockIndex, rs.BatchHash.String(), leader)
+	op.crashPoint(chain.CrashPointSubResultPersisted)
+}
+
+// crashPoint exits the process if the test has selected the crash point, see chain.CrashPointEnvVar.
+// The log line is awaited by the cluster test
+func (op *operator) crashPoint(name string) {
+	if !op.env.isCrashPoint(name) {
+		return
+	}
+	op.log.Warn(chain.CrashPointLogMessage(name))
+	_ = op.log.Sync()
+	os.Exit(1)
+}
+
+// discardRound deletes the persisted round, if any. It is called when the round is abandoned by the leader
+func (op *operator) discardRound() {
+	if !op.roundPersisted {
+		return
+	}
+	if err := op.env.deleteRoundState(op.chain.ID()); err != nil {
+		op.log.Errorf("discardRound: %v", err)
+		return
+	}
+	op.roundPersisted = false
+}
+
+// restoreRound resumes the persisted round if it belongs to the current state and the leader of the round
+// is the current leader, either the node itself or the leader the node has sent its result to.
+// The persisted round of any other state or leader is outdated and is deleted.
+// Returns true if the round was resumed
+func (op *operator) restoreRound() bool {
+	rs, err := op.env.getRoundState(op.chain.ID())
+	if err != nil {
+		op.log.Errorf("restoreRound: %v", err)
+		return false
+	}
+	if rs == nil {
+		return false
+	}
+	op.roundPersisted = true
+	leader, _ := op.currentLeader()
+	if rs.StateTxID != op.stateTxID() || rs.BlockIndex != op.mustStateIndex() || rs.LeaderIndex != leader {
+		op.discardRound()
+		return false
+	}
+	if op.signer.NeedsCommitments() {
+		// nonces of the signing set are not persisted, the round can't be finalized after the restart
+		op.discardRound()
+		return false
+	}
+	resultTx, block, err := parseRoundResult(rs)
+	if err == nil {
+		if op.iAmCurrentLeader() {
+			err = op.restoreLeaderRound(rs, resultTx, block)
+		} else {
+			err = op.restoreSubRound(rs, resultTx, block)
+		}
+	}
+	if err != nil {
+		op.log.Errorf("restoreRound: corrupted round state: %v", err)
+		op.discardRound()
+		return false
+	}
+	// the state manager waits for the confirmation of the block, as if it was just calculated
+	go func() {
+		op.chain.ReceiveMessage(chain.PendingBlockMsg{
+			Block: block,
+		})
+	}()
+	op.log.Infof("ROUND RESUMED: %s", rs.String())
+	return true
+}
+
+func parseRoundResult(rs *registry.RoundState) (*sctransaction.Transaction, state.Block, error) {
+	vtx, _, err := valuetransaction.FromBytes(rs.ResultTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	resultTx, err := sctransaction.ParseValueTransaction(vtx)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, err := state.NewBlockFromBytes(rs.Block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resultTx, block, nil
+}
+
+func (op *operator) restoreLeaderRound(rs *registry.RoundState, resultTx *sctransaction.Transaction, block state.Block) error {
+	leaderStatus, err := op.leaderStatusFromRound(rs, resultTx, block)
+	if err != nil {
+		return err
+	}
+	op.leaderStatus = leaderStatus
+	op.setNextConsensusStage(consensusStageLeaderCalculationsFinished)
+	return nil
+}
+
+// restoreSubRound restores the signature share sent to the leader. It is re-sent immediately
+func (op *operator) restoreSubRound(rs *registry.RoundState, resultTx *sctransaction.Transaction, block state.Block) error {
+	var own *registry.RoundSigShare
+	for i := range rs.SigShares {
+		if rs.SigShares[i].PeerIndex == op.peerIndex() {
+			own = &rs.SigShares[i]
+		}
+	}
+	if own == nil {
+		return fmt.Errorf("own signature share is missing")
+	}
+	op.sentResultToLeader = resultTx
+	op.sentResultBlock = block
+	op.sentResultToLeaderIndex = rs.LeaderIndex
+	op.sentResultToLeaderMsg = util.MustBytes(&chain.SignedHashMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{
+			BlockIndex: rs.BlockIndex,
+		},
+		BatchHash:     rs.BatchHash,
+		OrigTimestamp: rs.Timestamp,
+		EssenceHash:   own.EssenceHash,
+		SigShare:      own.SigShare,
+	})
+	op.nextResendResultToLeader = op.env.now()
+	op.setNextConsensusStage(consensusStageSubCalculationsFinished)
+	return nil
+}
+
+func (op *operator) leaderStatusFromRound(rs *registry.RoundState, resultTx *sctransaction.Transaction, block state.Block) (*leaderStatus, error) {
+	ret := &leaderStatus{
+		reqs:          make([]*request, 0, len(rs.RequestIDs)),
+		batch:         block,
+		batchHash:     rs.BatchHash,
+		timestamp:     rs.Timestamp,
+		balances:      op.balances,
+		resultTx:      resultTx,
+		signedResults: make([]*signedResult, op.chain.Size()),
+	}
+	for i := range rs.RequestIDs {
+		// requests are not needed for the finalization. After restart they may be not in the backlog yet
+		if req, ok := op.requests[rs.RequestIDs[i]]; ok {
+			ret.reqs = append(ret.reqs, req)
+		}
+	}
+	for _, s := range rs.SigShares {
+		if s.PeerIndex >= op.chain.Size() {
+			continue
+		}
+		ret.signedResults[s.PeerIndex] = &signedResult{
+			essenceHash: s.EssenceHash,
+			sigShare:    s.SigShare,
+		}
+	}
+	if ret.signedResults[op.chain.OwnPeerIndex()] == nil {
+		return nil, fmt.Errorf("own signature share is missing")
+	}
+	return ret, nil
+}
+
+// resendResultToLeader periodically re-sends the signature share of the subordinate to the leader
+// until the round is finalized, in case the leader missed it while it was restarting
+func (op *operator) resendResultToLeader() {
+	if op.consensusStage != consensusStageSubCalculationsFinished || op.sentResultToLeaderMsg == nil {
+		return
+	}
+	if op.env.now().Before(op.nextResendResultToLeader) {
+		return
+	}
+	if err := op.chain.SendMsg(op.sentResultToLeaderIndex, chain.MsgSignedHash, op.sentResultToLeaderMsg); err != nil {
+		op.log.Debugf("resendResultToLeader: %v", err)
+	}
+	op.nextResendResultToLeader = op.env.now().Add(chain.ResendSignedHashPeriod)
+}
@@ -0,0 +1,652 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/chain/transcript"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+)
+
+// The file contains the replay of the transcript of the committee messages of the node, recorded
+// with transcript.DirEnvVar set (see package transcript), in a new consensus operator.
+// Records are fed into the operator one by one, each after the previous one was processed, with the
+// clock of the operator set to the time of the record. The IOTA node, the registry and the peers are
+// simulated from the transcript, so the operator behaves deterministically and the same transcript
+// always leads to the same messages sent by the operator.
+// The VM is run for real: VM types of the contracts of the chain must be registered in processors.
+// When the recorded node passed the VM result to the operator, the replay waits for the replayed result
+// and checks that the essence of the result transaction is the same.
+// Consensus messages sent by the replayed operator are compared with the recorded ones: the type and
+// the target of each message must match. Any difference is reported as a divergence
+
+// ReplayVMTimeout is the time the replay waits for the result of the VM
+var ReplayVMTimeout = time.Minute
+
+// consensusMsgTypes are the peer messages of the consensus operator
+var consensusMsgTypes = map[byte]bool{
+	chain.MsgNotifyRequests:          true,
+	chain.MsgNotifyFinalResultPosted: true,
+	chain.MsgStartProcessingRequest:  true,
+	chain.MsgSignedHash:              true,
+	chain.MsgProposalDigest:          true,
+	chain.MsgEmergencyVote:           true,
+	chain.MsgFairOrderShare:          true,
+	chain.MsgSigningCommitment:       true,
+	chain.MsgVRFShare:                true,
+}
+
+// ReplayResult is the outcome of the replay
+type ReplayResult struct {
+	// Sent are the consensus messages sent by the replayed operator, in the order of sending
+	Sent []*transcript.Record
+	// Expected are the consensus messages sent by the recorded node
+	Expected []*transcript.Record
+	// Divergences describe where the replayed operator behaved differently from the recorded one.
+	// Empty if the transcript was reproduced
+	Divergences []string
+	// Posted are transactions posted to the IOTA node by the replayed operator
+	Posted []*valuetransaction.Transaction
+	// Evidence is the equivocation evidence found by the replayed operator
+	Evidence []*registry.EquivocationEvidence
+	// Emergencies are the emergency modes activated by the replayed operator
+	Emergencies []*registry.EmergencyRecord
+	// Info is the state of the operator at the end of the replay
+	Info *chain.ConsensusInfo
+}
+
+// Replayer feeds the transcript into the consensus operator record by record
+type Replayer struct {
+	transcript *transcript.Transcript
+	op         *operator
+	chain      *replayChain
+	log        *logger.Logger
+	next       int
+	// the clock of the operator: the time of the current record
+	clock time.Time
+	// the last input record: alive peers and the quorum of connections
+	peers *transcript.Record
+	// the persisted round state and completed requests, like in the database of the node
+	roundState *registry.RoundState
+	completed  map[coretypes.RequestID]bool
+	// index of the last recorded batch proposal used as the timestamp of the batch
+	lastProposal int
+	result       ReplayResult
+}
+
+// NewReplayer creates the consensus operator of the node of the transcript. The key share is
+// restored with the suite. Only chains with the BLS address can be replayed: nonces of FROST signers
+// are random, so signature shares of the recorded signing sets can't be reproduced
+func NewReplayer(tr *transcript.Transcript, suite tcrypto.Suite, log *logger.Logger) (*Replayer, error) {
+	chainAddr := address.Address(tr.Header.ChainID)
+	if scheme, err := tcrypto.SigSchemeOfAddress(&chainAddr); err != nil || scheme != tcrypto.SigSchemeBLS {
+		return nil, fmt.Errorf("NewReplayer: only chains with the BLS address can be replayed")
+	}
+	dkshare, err := tcrypto.DKShareFromBytes(tr.Header.DKShare, suite)
+	if err != nil {
+		return nil, fmt.Errorf("NewReplayer: wrong key share: %v", err)
+	}
+	if dkshare.Index == nil || *dkshare.Index != tr.Header.OwnIndex {
+		return nil, fmt.Errorf("NewReplayer: key share doesn't belong to the node #%d", tr.Header.OwnIndex)
+	}
+	ret := &Replayer{
+		transcript:   tr,
+		log:          log,
+		clock:        time.Now(),
+		completed:    make(map[coretypes.RequestID]bool),
+		lastProposal: -1,
+		result: ReplayResult{
+			Sent:        make([]*transcript.Record, 0),
+			Expected:    make([]*transcript.Record, 0),
+			Divergences: make([]string, 0),
+			Posted:      make([]*valuetransaction.Transaction, 0),
+			Evidence:    make([]*registry.EquivocationEvidence, 0),
+			Emergencies: make([]*registry.EmergencyRecord, 0),
+		},
+	}
+	if len(tr.Records) > 0 {
+		ret.clock = tr.Records[0].Time()
+	}
+	ret.chain = newReplayChain(ret)
+	for _, rec := range tr.Records {
+		if rec.Kind == transcript.KindBlob {
+			ret.chain.blobs[hashing.HashData(rec.Data)] = rec.Data
+		}
+	}
+	ret.op = newOperator(ret.chain, dkshare, tcrypto.NewBLSSigner(dkshare), (*replayEnv)(ret),
+		tr.Header.BatchTimeBudget, int(tr.Header.MaxBatchSize), tr.Header.FairOrdering, tr.Header.EmergencyQuorum, tr.Header.Pipelining,
+		tr.Header.MaxRequestAge, log)
+	ret.sync()
+	return ret, nil
+}
+
+// Replay replays the whole transcript, see Replayer
+func Replay(tr *transcript.Transcript, suite tcrypto.Suite, log *logger.Logger) (*ReplayResult, error) {
+	r, err := NewReplayer(tr, suite, log)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return r.Run()
+}
+
+// Run replays the remaining records and compares the messages sent by the operator with the recorded ones
+func (r *Replayer) Run() (*ReplayResult, error) {
+	for {
+		ok, err := r.Step()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+	}
+	select {
+	case msg := <-r.chain.vmResults:
+		r.diverged("VM result of batch of the leader #%d was not used by the recorded node", msg.Leader)
+	default:
+	}
+	r.compareSent()
+	r.result.Info = r.op.ConsensusInfo()
+	return &r.result, nil
+}
+
+// Step feeds the next record into the operator and waits until it is processed.
+// Returns false when all records were replayed
+func (r *Replayer) Step() (bool, error) {
+	if r.next >= len(r.transcript.Records) {
+		return false, nil
+	}
+	rec := r.transcript.Records[r.next]
+	r.chain.mutex.Lock()
+	r.clock = rec.Time()
+	if rec.Alive != nil {
+		r.peers = rec
+	}
+	r.chain.mutex.Unlock()
+
+	if err := r.feed(rec); err != nil {
+		return false, fmt.Errorf("record #%d (%s): %v", r.next, rec.String(), err)
+	}
+	r.sync()
+	r.next++
+	return true, nil
+}
+
+// Position is the index of the next record to replay
+func (r *Replayer) Position() int {
+	return r.next
+}
+
+// Info is the state of the replayed operator
+func (r *Replayer) Info() *chain.ConsensusInfo {
+	return r.op.ConsensusInfo()
+}
+
+func (r *Replayer) Close() {
+	r.op.Close()
+}
+
+// sync waits until the event loop of the operator processed the previous event
+func (r *Replayer) sync() {
+	r.op.syncCh <- struct{}{}
+}
+
+func (r *Replayer) diverged(format string, args ...interface{}) {
+	msg := fmt.Sprintf("record #%d: %s", r.next, fmt.Sprintf(format, args...))
+	r.log.Warnf("REPLAY DIVERGED: %s", msg)
+	r.chain.mutex.Lock()
+	defer r.chain.mutex.Unlock()
+	r.result.Divergences = append(r.result.Divergences, msg)
+}
+
+func (r *Replayer) feed(rec *transcript.Record) error {
+	switch rec.Kind {
+	case transcript.KindPeerIn:
+		return r.feedPeerMessage(rec)
+
+	case transcript.KindPeerOut, transcript.KindBroadcast:
+		if consensusMsgTypes[rec.MsgType] {
+			r.result.Expected = append(r.result.Expected, rec)
+		}
+
+	case transcript.KindRequest:
+		msg, completed, err := transcript.ParseRequest(rec.Data)
+		if err != nil {
+			return err
+		}
+		if completed {
+			r.completed[*msg.RequestId()] = true
+		}
+		r.op.EventRequestMsg(msg)
+
+	case transcript.KindBalances:
+		msg, err := transcript.ParseBalances(rec.Data)
+		if err != nil {
+			return err
+		}
+		r.op.EventBalancesMsg(msg)
+
+	case transcript.KindInclusionLevel:
+		msg, err := transcript.ParseInclusionLevel(rec.Data)
+		if err != nil {
+			return err
+		}
+		r.op.EventTransactionInclusionLevelMsg(msg)
+
+	case transcript.KindStateTransition:
+		msg, completed, err := transcript.ParseStateTransition(rec.Data, &r.transcript.Header.ChainID)
+		if err != nil {
+			return err
+		}
+		for _, reqid := range completed {
+			r.completed[reqid] = true
+		}
+		r.op.EventStateTransitionMsg(msg)
+
+	case transcript.KindTimerTick:
+		tick, err := transcript.ParseTimerTick(rec.Data)
+		if err != nil {
+			return err
+		}
+		r.op.EventTimerMsg(tick)
+
+	case transcript.KindVMResult:
+		return r.feedVMResult(rec)
+
+	case transcript.KindEmergency:
+		msg, err := transcript.ParseEmergency(rec.Data)
+		if err != nil {
+			return err
+		}
+		r.op.EventEmergencyMsg(msg)
+	}
+	return nil
+}
+
+func (r *Replayer) feedPeerMessage(rec *transcript.Record) error {
+	rdr := bytes.NewReader(rec.Data)
+	switch rec.MsgType {
+	case chain.MsgNotifyRequests:
+		msg := &chain.NotifyReqMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		r.op.EventNotifyReqMsg(msg)
+
+	case chain.MsgNotifyFinalResultPosted:
+		msg := &chain.NotifyFinalResultPostedMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		r.op.EventNotifyFinalResultPostedMsg(msg)
+
+	case chain.MsgStartProcessingRequest:
+		msg := &chain.StartProcessingBatchMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		msg.Timestamp = rec.MsgTimestamp
+		r.op.EventStartProcessingBatchMsg(msg)
+
+	case chain.MsgSignedHash:
+		msg := &chain.SignedHashMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		msg.Timestamp = rec.MsgTimestamp
+		r.op.EventSignedHashMsg(msg)
+
+	case chain.MsgProposalDigest:
+		msg := &chain.ProposalDigestMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		r.op.EventProposalDigestMsg(msg)
+
+	case chain.MsgEmergencyVote:
+		msg := &chain.EmergencyVoteMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		r.op.EventEmergencyVoteMsg(msg)
+
+	case chain.MsgFairOrderShare:
+		msg := &chain.FairOrderShareMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		r.op.EventFairOrderShareMsg(msg)
+
+	case chain.MsgSigningCommitment:
+		msg := &chain.SigningCommitmentMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		r.op.EventSigningCommitmentMsg(msg)
+
+	case chain.MsgVRFShare:
+		msg := &chain.VRFShareMsg{}
+		if err := msg.Read(rdr); err != nil {
+			return err
+		}
+		msg.SenderIndex = rec.Peer
+		r.op.EventVRFShareMsg(msg)
+	}
+	// other messages are for the state manager
+	return nil
+}
+
+// feedVMResult passes the replayed VM result to the operator at the moment the recorded node did it
+func (r *Replayer) feedVMResult(rec *transcript.Record) error {
+	leader, duration, essenceHash, err := transcript.ParseVMResult(rec.Data)
+	if err != nil {
+		return err
+	}
+	var msg *chain.VMResultMsg
+	select {
+	case msg = <-r.chain.vmResults:
+	case <-time.After(ReplayVMTimeout):
+		r.diverged("the VM result of the batch of the leader #%d was not produced", leader)
+		return nil
+	}
+	if msg.Leader != leader {
+		r.diverged("the VM result is of the batch of the leader #%d, expected #%d", msg.Leader, leader)
+	}
+	if h := hashing.HashData(msg.Task.ResultTransaction.EssenceBytes()); h != essenceHash {
+		r.diverged("essence hash of the result transaction is %s, expected %s", h.String(), essenceHash.String())
+	}
+	// the execution time is of the recorded node, so the batch sizer adapts the same way
+	msg.Duration = duration
+	r.op.EventResultCalculated(msg)
+	return nil
+}
+
+// compareSent compares consensus messages sent by the replayed operator with the recorded ones
+func (r *Replayer) compareSent() {
+	r.chain.mutex.Lock()
+	sent := r.result.Sent
+	r.chain.mutex.Unlock()
+
+	expected := r.result.Expected
+	for i := 0; i < len(sent) && i < len(expected); i++ {
+		if sent[i].Kind != expected[i].Kind || sent[i].Peer != expected[i].Peer || sent[i].MsgType != expected[i].MsgType {
+			r.diverged("sent message #%d is '%s', expected '%s'", i, sent[i].String(), expected[i].String())
+			return
+		}
+	}
+	if len(sent) != len(expected) {
+		r.diverged("%d consensus message(s) sent, expected %d", len(sent), len(expected))
+	}
+}
+
+// replayEnv is the environment of the operator simulated from the transcript
+type replayEnv Replayer
+
+func (e *replayEnv) now() time.Time {
+	e.chain.mutex.Lock()
+	defer e.chain.mutex.Unlock()
+	return e.clock
+}
+
+// batchTimestamp is the timestamp of the first recorded batch proposal not yet used, starting from the
+// current record. The recorded node took it from its clock while processing the record
+func (e *replayEnv) batchTimestamp() int64 {
+	records := e.transcript.Records
+	for i := e.next; i < len(records); i++ {
+		if i > e.lastProposal && records[i].Kind == transcript.KindBroadcast && records[i].MsgType == chain.MsgStartProcessingRequest {
+			e.lastProposal = i
+			return records[i].MsgTimestamp
+		}
+	}
+	return e.now().UnixNano()
+}
+
+func (e *replayEnv) requestBalances(_ address.Address) {
+}
+
+func (e *replayEnv) requestInclusionLevel(_ *valuetransaction.ID, _ *address.Address) error {
+	return nil
+}
+
+func (e *replayEnv) postTransaction(tx *valuetransaction.Transaction, _ *address.Address, _ uint16) error {
+	e.chain.mutex.Lock()
+	defer e.chain.mutex.Unlock()
+	e.result.Posted = append(e.result.Posted, tx)
+	return nil
+}
+
+func (e *replayEnv) isRequestCompleted(_ *coretypes.ChainID, reqid *coretypes.RequestID) (bool, error) {
+	return e.completed[*reqid], nil
+}
+
+func (e *replayEnv) saveRoundState(rs *registry.RoundState) error {
+	e.roundState = rs
+	return nil
+}
+
+func (e *replayEnv) getRoundState(_ *coretypes.ChainID) (*registry.RoundState, error) {
+	return e.roundState, nil
+}
+
+func (e *replayEnv) deleteRoundState(_ *coretypes.ChainID) error {
+	e.roundState = nil
+	return nil
+}
+
+func (e *replayEnv) saveEquivocationEvidence(ev *registry.EquivocationEvidence) error {
+	e.chain.mutex.Lock()
+	defer e.chain.mutex.Unlock()
+	e.result.Evidence = append(e.result.Evidence, ev)
+	return nil
+}
+
+func (e *replayEnv) saveEmergencyRecord(rec *registry.EmergencyRecord) error {
+	e.chain.mutex.Lock()
+	defer e.chain.mutex.Unlock()
+	e.result.Emergencies = append(e.result.Emergencies, rec)
+	return nil
+}
+
+func (e *replayEnv) isCrashPoint(_ string) bool {
+	return false
+}
+
+// replayChain is the committee of the replayed operator. Messages sent by the operator are collected,
+// peers are alive as recorded
+type replayChain struct {
+	replayer  *Replayer
+	mutex     sync.Mutex
+	procset   *processors.ProcessorCache
+	blobs     map[hashing.HashValue][]byte
+	vmResults chan *chain.VMResultMsg
+}
+
+func newReplayChain(r *Replayer) *replayChain {
+	return &replayChain{
+		replayer:  r,
+		procset:   processors.MustNew(),
+		blobs:     make(map[hashing.HashValue][]byte),
+		vmResults: make(chan *chain.VMResultMsg, 10),
+	}
+}
+
+func (c *replayChain) header() *transcript.Header {
+	return c.replayer.transcript.Header
+}
+
+func (c *replayChain) ID() *coretypes.ChainID {
+	return &c.header().ChainID
+}
+
+func (c *replayChain) Color() *balance.Color {
+	return &c.header().Color
+}
+
+func (c *replayChain) Address() address.Address {
+	return address.Address(c.header().ChainID)
+}
+
+func (c *replayChain) Size() uint16 {
+	return c.header().Size
+}
+
+func (c *replayChain) Quorum() uint16 {
+	return c.header().Quorum
+}
+
+func (c *replayChain) OwnPeerIndex() uint16 {
+	return c.header().OwnIndex
+}
+
+func (c *replayChain) NumPeers() uint16 {
+	return c.header().Size
+}
+
+func (c *replayChain) SendMsg(targetPeerIndex uint16, msgType byte, msgData []byte) error {
+	if targetPeerIndex == c.OwnPeerIndex() || targetPeerIndex >= c.Size() {
+		return fmt.Errorf("SendMsg: wrong peer index")
+	}
+	if !c.IsAlivePeer(targetPeerIndex) {
+		return fmt.Errorf("SendMsg: peer #%d is dead", targetPeerIndex)
+	}
+	c.sent(&transcript.Record{
+		Kind:    transcript.KindPeerOut,
+		Peer:    targetPeerIndex,
+		MsgType: msgType,
+		Data:    msgData,
+	})
+	return nil
+}
+
+func (c *replayChain) SendMsgToCommitteePeers(msgType byte, msgData []byte, ts int64) uint16 {
+	numSent := uint16(0)
+	for i := uint16(0); i < c.Size(); i++ {
+		if i != c.OwnPeerIndex() && c.IsAlivePeer(i) {
+			numSent++
+		}
+	}
+	c.sent(&transcript.Record{
+		Kind:         transcript.KindBroadcast,
+		Peer:         c.OwnPeerIndex(),
+		MsgType:      msgType,
+		MsgTimestamp: ts,
+		Data:         msgData,
+	})
+	return numSent
+}
+
+func (c *replayChain) sent(rec *transcript.Record) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	rec.Timestamp = c.replayer.clock.UnixNano()
+	c.replayer.result.Sent = append(c.replayer.result.Sent, rec)
+}
+
+func (c *replayChain) IsAlivePeer(peerIndex uint16) bool {
+	if peerIndex == c.OwnPeerIndex() {
+		return true
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.replayer.peers != nil && c.replayer.peers.IsAlive(peerIndex)
+}
+
+func (c *replayChain) HasQuorum() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.replayer.peers != nil && c.replayer.peers.HasQuorum
+}
+
+func (c *replayChain) PeerStatus() []*chain.PeerStatus {
+	ret := make([]*chain.PeerStatus, c.Size())
+	for i := range ret {
+		alive := c.IsAlivePeer(uint16(i))
+		ret[i] = &chain.PeerStatus{
+			Index:     i,
+			IsSelf:    uint16(i) == c.OwnPeerIndex(),
+			Connected: alive,
+			Alive:     alive,
+		}
+	}
+	return ret
+}
+
+// ReceiveMessage collects results of the VM. Other messages are for the state manager
+func (c *replayChain) ReceiveMessage(msg interface{}) {
+	if msgt, ok := msg.(*chain.VMResultMsg); ok {
+		c.vmResults <- msgt
+	}
+}
+
+func (c *replayChain) ConsensusInfo() *chain.ConsensusInfo {
+	return c.replayer.op.ConsensusInfo()
+}
+
+func (c *replayChain) BlobCache() coretypes.BlobCache {
+	return c
+}
+
+// GetBlob returns blobs used by the recorded node
+func (c *replayChain) GetBlob(h hashing.HashValue) ([]byte, bool, error) {
+	data, ok := c.blobs[h]
+	return data, ok, nil
+}
+
+func (c *replayChain) HasBlob(h hashing.HashValue) (bool, error) {
+	_, ok := c.blobs[h]
+	return ok, nil
+}
+
+func (c *replayChain) Processors() *processors.ProcessorCache {
+	return c.procset
+}
+
+func (c *replayChain) InitTestRound()        {}
+func (c *replayChain) SetReadyStateManager() {}
+func (c *replayChain) SetReadyConsensus()    {}
+func (c *replayChain) Dismiss()              {}
+func (c *replayChain) IsDismissed() bool     { return false }
+func (c *replayChain) IsDraining() bool      { return false }
+
+func (c *replayChain) Drain() <-chan struct{} {
+	ret := make(chan struct{})
+	close(ret)
+	return ret
+}
+
+// DeclareEmergency is not used by the replay: the declared emergency is fed from the transcript
+func (c *replayChain) DeclareEmergency(_ uint16, _ uint32) error {
+	return fmt.Errorf("DeclareEmergency: not supported in the replay")
+}
+
+func (c *replayChain) GetRequestProcessingStatus(_ *coretypes.RequestID) chain.RequestProcessingStatus {
+	return chain.RequestProcessingStatusUnknown
+}
+
+func (c *replayChain) EventRequestProcessed() *events.Event {
+	return events.NewEvent(func(handler interface{}, params ...interface{}) {})
+}
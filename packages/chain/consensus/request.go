@@ -12,7 +12,6 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/publisher"
 	"github.com/iotaledger/wasp/packages/sctransaction"
-	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/util"
 )
 
@@ -55,12 +54,12 @@ func (op *operator) requestFromMsg(reqMsg *chain.RequestMsg) (*request, bool) {
 		if msgFirstTime {
 			ret.reqTx = reqMsg.Transaction
 			ret.freeTokens = reqMsg.FreeTokens
-			ret.whenMsgReceived = time.Now()
+			ret.whenMsgReceived = op.env.now()
 			newMsg = true
 		}
 	} else {
 		ret = op.newRequest(*reqId)
-		ret.whenMsgReceived = time.Now()
+		ret.whenMsgReceived = op.env.now()
 		ret.reqTx = reqMsg.Transaction
 		ret.freeTokens = reqMsg.FreeTokens
 		op.requests[*reqId] = ret
@@ -88,7 +87,7 @@ func (op *operator) requestFromMsg(reqMsg *chain.RequestMsg) (*request, bool) {
 	ret.notifications[op.peerIndex()] = true
 
 	tl := ""
-	if msgFirstTime && ret.isTimeLocked(time.Now()) {
+	if msgFirstTime && ret.isTimeLocked(op.env.now()) {
 		tl = fmt.Sprintf(". Time locked until %d (nowis = %d)", ret.timelock(), util.TimeNowUnix())
 	}
 	ret.log.Infof("NEW REQUEST from msg%s", tl)
@@ -108,6 +107,11 @@ func (req *request) isTimeLocked(nowis time.Time) bool {
 	return req.timelock() > uint32(nowis.Unix())
 }
 
+// isDeferred returns true if the request is deferred by the rate limit of the chain, see intake.go
+func (req *request) isDeferred(nowis time.Time) bool {
+	return nowis.Before(req.deferredUntil)
+}
+
 func (req *request) hasMessage() bool {
 	return req.reqTx != nil
 }
@@ -117,7 +121,7 @@ func (req *request) hasSolidArgs() bool {
 }
 
 func (op *operator) isRequestProcessed(reqid *coretypes.RequestID) bool {
-	processed, err := state.IsRequestCompleted(op.chain.ID(), reqid)
+	processed, err := op.env.isRequestCompleted(op.chain.ID(), reqid)
 	if err != nil {
 		panic(err)
 	}
@@ -129,7 +133,7 @@ func (op *operator) deleteCompletedRequests() error {
 	toDelete := make([]*coretypes.RequestID, 0)
 
 	for _, req := range op.requests {
-		if completed, err := state.IsRequestCompleted(op.chain.ID(), &req.reqId); err != nil {
+		if completed, err := op.env.isRequestCompleted(op.chain.ID(), &req.reqId); err != nil {
 			return err
 		} else {
 			if completed {
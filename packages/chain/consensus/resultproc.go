@@ -5,6 +5,7 @@ package consensus
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
@@ -12,6 +13,7 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/packages/vm/runvm"
@@ -23,6 +25,7 @@ type runCalculationsParams struct {
 	balances        map[valuetransaction.ID][]*balance.Balance
 	accrueFeesTo    coretypes.AgentID
 	timestamp       int64
+	vrf             coretypes.VRF
 }
 
 // runs the VM for requests and posts result to committee's queue
@@ -35,7 +38,8 @@ func (op *operator) runCalculationsAsync(par runCalculationsParams) {
 		Processors:         op.chain.Processors(),
 		ChainID:            *op.chain.ID(),
 		Color:              *op.chain.Color(),
-		Entropy:            (hashing.HashValue)(op.stateTx.ID()),
+		Entropy:            op.batchEntropy(),
+		VRF:                par.vrf,
 		Balances:           par.balances,
 		ValidatorFeeTarget: par.accrueFeesTo,
 		Requests:           takeRefs(par.requests),
@@ -43,14 +47,16 @@ func (op *operator) runCalculationsAsync(par runCalculationsParams) {
 		VirtualState:       op.currentState,
 		Log:                op.log,
 	}
+	start := time.Now()
 	ctx.OnFinish = func(_ dict.Dict, _ error, vmError error) {
 		if vmError != nil {
 			op.log.Errorf("VM task failed: %v", vmError)
 			return
 		}
 		op.chain.ReceiveMessage(&chain.VMResultMsg{
-			Task:   ctx,
-			Leader: par.leaderPeerIndex,
+			Task:     ctx,
+			Leader:   par.leaderPeerIndex,
+			Duration: time.Since(start),
 		})
 	}
 	if err := runvm.RunComputationsAsync(ctx); err != nil {
@@ -66,7 +72,13 @@ func (op *operator) sendResultToTheLeader(result *vm.VMTask, leader uint16) {
 		return
 	}
 
-	sigShare, err := op.dkshare.SignShare(result.ResultTransaction.EssenceBytes())
+	sigShare, err := op.signer.SignShare(result.ResultTransaction.EssenceBytes(), op.batchSigningCommitments)
+	if err == tcrypto.ErrNotInSigningSet {
+		// the leader has signatures of the signing set without the node
+		op.log.Debugf("sendResultToTheLeader: the node is not in the signing set of the leader #%d", leader)
+		op.setNextConsensusStage(consensusStageSubCalculationsFinished)
+		return
+	}
 	if err != nil {
 		op.log.Errorf("error while signing transaction %v", err)
 		return
@@ -87,7 +99,7 @@ func (op *operator) sendResultToTheLeader(result *vm.VMTask, leader uint16) {
 		"ts", result.Timestamp,
 	)
 
-	msgData := util.MustBytes(&chain.SignedHashMsg{
+	msg := &chain.SignedHashMsg{
 		PeerMsgHeader: chain.PeerMsgHeader{
 			BlockIndex: op.mustStateIndex(),
 		},
@@ -95,16 +107,21 @@ func (op *operator) sendResultToTheLeader(result *vm.VMTask, leader uint16) {
 		OrigTimestamp: result.Timestamp,
 		EssenceHash:   essenceHash,
 		SigShare:      sigShare,
-	})
+	}
+	msgData := util.MustBytes(msg)
 
 	if err := op.chain.SendMsg(leader, chain.MsgSignedHash, msgData); err != nil {
 		op.log.Error(err)
 		return
 	}
 	op.sentResultToLeader = result.ResultTransaction
+	op.sentResultBlock = result.ResultBlock
 	op.sentResultToLeaderIndex = leader
+	op.sentResultToLeaderMsg = msgData
+	op.nextResendResultToLeader = op.env.now().Add(chain.ResendSignedHashPeriod)
 
 	op.setNextConsensusStage(consensusStageSubCalculationsFinished)
+	op.persistSubRound(result, leader, msg)
 }
 
 func (op *operator) saveOwnResult(result *vm.VMTask) {
@@ -113,7 +130,7 @@ func (op *operator) saveOwnResult(result *vm.VMTask) {
 			stages[consensusStageLeaderCalculationsStarted].name, stages[op.consensusStage].name)
 		return
 	}
-	sigShare, err := op.dkshare.SignShare(result.ResultTransaction.EssenceBytes())
+	sigShare, err := op.signer.SignShare(result.ResultTransaction.EssenceBytes(), op.leaderStatus.commitments)
 	if err != nil {
 		op.log.Errorf("error while signing transaction %v", err)
 		return
@@ -145,13 +162,14 @@ func (op *operator) saveOwnResult(result *vm.VMTask) {
 		essenceHash: essenceHash,
 		sigShare:    sigShare,
 	}
+	op.persistRound()
 	op.setNextConsensusStage(consensusStageLeaderCalculationsFinished)
 }
 
 func (op *operator) aggregateSigShares(sigShares [][]byte) error {
 	resTx := op.leaderStatus.resultTx
 
-	finalSignature, err := op.dkshare.RecoverFullSignature(sigShares, resTx.EssenceBytes())
+	finalSignature, err := op.signer.RecoverFullSignature(sigShares, resTx.EssenceBytes(), op.leaderStatus.commitments)
 	if err != nil {
 		return err
 	}
@@ -7,7 +7,6 @@ package consensus
 import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"sort"
-	"time"
 )
 
 // selectRequestsToProcess select requests to process in the batch.
@@ -16,11 +15,32 @@ import (
 // 3. selects maximum possible set of those which were seen by same quorum of peers
 // only requests in "full batches" are selected, it means request is in the selection together with ALL other requests
 // from the same request transaction, or it is not selected
+// In the emergency mode steps 1 and 3 are skipped, see emergency.go
+// 4. the selection is truncated to the number of requests which is expected to fit into
+// the wall-clock time budget of the batch
+// Overdue requests are selected first and are not truncated, see age.go
+// In the fair ordering mode the selection is then fixed and sorted by the leader, see fairorder.go
 func (op *operator) selectRequestsToProcess() []*request {
-	candidates := op.requestCandidateList()
-	if len(candidates) == 0 {
+	nowis := op.env.now()
+	candidates := op.prioritizeOverdue(op.requestCandidateList(), nowis)
+	var ret []*request
+	if op.isEmergency() {
+		// the delegate doesn't wait for the quorum of notifications, see emergency.go
+		ret = candidates
+	} else {
+		ret = op.selectRequestsSeenByQuorum(candidates)
+	}
+	ret = truncateBatch(ret, overdueBatchLimit(op.batchSizer.limit(), op.numOverdue(ret, nowis)))
+	op.rejectOverdue(ret, nowis)
+	if len(ret) == 0 {
 		return nil
 	}
+	op.log.Debugf("requests selected for process: %d out of total %d", len(ret), len(op.requests))
+	return ret
+}
+
+// selectRequestsSeenByQuorum selects maximum possible set of candidates which were seen by same quorum of peers
+func (op *operator) selectRequestsSeenByQuorum(candidates []*request) []*request {
 	if candidates = op.filterRequestsNotSeenQuorumTimes(candidates); len(candidates) == 0 {
 		return nil
 	}
@@ -37,10 +57,6 @@ func (op *operator) selectRequestsToProcess() []*request {
 		}
 		ret = append(ret, candidates[i])
 	}
-	if len(ret) == 0 {
-		return nil
-	}
-	op.log.Debugf("requests selected for process: %d out of total %d", len(ret), len(op.requests))
 	return ret
 }
 
@@ -56,12 +72,16 @@ func (op *operator) allRequests() []*request {
 // - has known messages
 // - has solid arguments
 // - are not timelocked
+// - are not waiting for the predecessor, see predecessor.go
+// - are not deferred by the rate limit, see intake.go, unless overdue, see age.go
+// - are not processed in the tentative state, see pipeline.go
 // sort by arrival time
 func (op *operator) requestCandidateList() []*request {
 	ret := op.allRequests()
-	nowis := time.Now()
+	nowis := op.env.now()
 	ret = filterRequests(ret, func(r *request) bool {
-		return r.hasMessage() && !r.isTimeLocked(nowis) && r.hasSolidArgs()
+		return r.hasMessage() && !r.isTimeLocked(nowis) && r.hasSolidArgs() && !op.isWaitingForPredecessor(r, nowis) &&
+			(!r.isDeferred(nowis) || op.isOverdue(r, nowis)) && !op.isTentativeRequest(&r.reqId)
 	})
 	sort.Slice(ret, func(i, j int) bool {
 		return ret[i].whenMsgReceived.Before(ret[j].whenMsgReceived)
@@ -72,7 +92,7 @@ func (op *operator) requestCandidateList() []*request {
 func (op *operator) requestsTimeLocked() []*request {
 	ret := make([]*request, 0, len(op.requests))
 
-	nowis := time.Now()
+	nowis := op.env.now()
 	for _, req := range op.requests {
 		if req.reqTx == nil {
 			continue
@@ -88,12 +108,14 @@ func (op *operator) requestsTimeLocked() []*request {
 type requestWithVotes struct {
 	*request
 	seenTimes uint16
+	overdue   bool
 }
 
 func (op *operator) filterRequestsNotSeenQuorumTimes(candidates []*request) []*request {
 	if len(candidates) == 0 {
 		return nil
 	}
+	nowis := op.env.now()
 	ret1 := make([]*requestWithVotes, 0)
 	for _, req := range candidates {
 		votes := numTrue(req.notifications)
@@ -101,11 +123,16 @@ func (op *operator) filterRequestsNotSeenQuorumTimes(candidates []*request) []*r
 			ret1 = append(ret1, &requestWithVotes{
 				request:   req,
 				seenTimes: votes,
+				overdue:   op.isOverdue(req, nowis),
 			})
 		}
 	}
-	sort.Slice(ret1, func(i, j int) bool {
-		return ret1[i].seenTimes > ret1[j].seenTimes
+	// overdue requests stay at the head in the order of age, see age.go
+	sort.SliceStable(ret1, func(i, j int) bool {
+		if ret1[i].overdue != ret1[j].overdue {
+			return ret1[i].overdue
+		}
+		return !ret1[i].overdue && ret1[i].seenTimes > ret1[j].seenTimes
 	})
 	ret := candidates[:0] // same underlying array
 	for _, req := range ret1 {
@@ -115,9 +142,9 @@ func (op *operator) filterRequestsNotSeenQuorumTimes(candidates []*request) []*r
 }
 
 func (op *operator) collectProcessableBatch(reqIds []coretypes.RequestID) []*request {
-	nowis := time.Now()
+	nowis := op.env.now()
 	return filterRequests(op.takeFromIds(reqIds), func(r *request) bool {
-		return r.hasMessage() && !r.isTimeLocked(nowis) && r.hasSolidArgs()
+		return r.hasMessage() && !r.isTimeLocked(nowis) && r.hasSolidArgs() && !op.isTentativeRequest(&r.reqId)
 	})
 }
 
@@ -0,0 +1,108 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// The file contains the round of nonce commitments, needed by signature schemes such as FROST
+// (see tcrypto.ThresholdSigner). At the start of each leader term each node generates the fresh nonce.
+// Subordinates send commitments to the leader and re-send them until the batch proposal comes.
+// The leader doesn't start the batch until it has commitments of the quorum, including its own, and sends
+// commitments of the selected signing set with the proposal. Only nodes of the signing set sign the
+// anchor transaction. With BLS nothing is exchanged and any quorum of signature shares is enough
+
+// startSigningTerm generates the fresh nonce of the node for the new leader term
+func (op *operator) startSigningTerm() {
+	op.signingCommitments = nil
+	op.ownSigningCommitment = nil
+	op.batchSigningCommitments = nil
+	op.nextResendSigningCommitment = time.Time{}
+	if !op.signer.NeedsCommitments() {
+		return
+	}
+	commitment, err := op.signer.Commit()
+	if err != nil {
+		op.log.Errorf("startSigningTerm: %v", err)
+		return
+	}
+	op.ownSigningCommitment = commitment
+	op.signingCommitments = tcrypto.SigningCommitments{op.peerIndex(): commitment}
+}
+
+// sendSigningCommitmentToLeader sends the own commitment to the current leader until the batch proposal comes
+func (op *operator) sendSigningCommitmentToLeader() {
+	if op.ownSigningCommitment == nil || op.iAmCurrentLeader() {
+		return
+	}
+	if op.consensusStage != consensusStageSubStarting && op.consensusStage != consensusStageSubNotificationsSent {
+		return
+	}
+	if op.env.now().Before(op.nextResendSigningCommitment) {
+		return
+	}
+	leader, _ := op.currentLeader()
+	msgData := util.MustBytes(&chain.SigningCommitmentMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{
+			BlockIndex: op.mustStateIndex(),
+		},
+		Commitment: op.ownSigningCommitment,
+	})
+	if err := op.chain.SendMsg(leader, chain.MsgSigningCommitment, msgData); err != nil {
+		op.log.Debugf("sendSigningCommitmentToLeader: %v", err)
+	}
+	op.nextResendSigningCommitment = op.env.now().Add(chain.ResendSigningCommitmentPeriod)
+}
+
+// EventSigningCommitmentMsg the commitment of the subordinate to the nonce of its next signature share
+func (op *operator) EventSigningCommitmentMsg(msg *chain.SigningCommitmentMsg) {
+	op.eventSigningCommitmentMsgCh <- msg
+}
+
+func (op *operator) eventSigningCommitmentMsg(msg *chain.SigningCommitmentMsg) {
+	if op.ownSigningCommitment == nil {
+		return
+	}
+	stateIndex, ok := op.blockIndex()
+	if !ok || msg.BlockIndex != stateIndex || !op.iAmCurrentLeader() || op.consensusStage != consensusStageLeaderStarting {
+		// the subordinate re-sends it
+		op.log.Debugf("EventSigningCommitmentMsg: commitment from peer #%d is out of context", msg.SenderIndex)
+		return
+	}
+	if msg.SenderIndex >= op.size() {
+		op.log.Warnf("EventSigningCommitmentMsg: wrong peer index #%d", msg.SenderIndex)
+		return
+	}
+	if err := op.signer.VerifyCommitment(msg.Commitment); err != nil {
+		op.log.Warnf("EventSigningCommitmentMsg: invalid commitment from peer #%d: %v", msg.SenderIndex, err)
+		return
+	}
+	op.signingCommitments[msg.SenderIndex] = msg.Commitment
+	op.takeAction()
+}
+
+// signingSet returns commitments of signers of the anchor transaction: the leader and quorum-1 peers
+// with the lowest indices which sent commitments. Returns false if there are not enough commitments yet.
+// Nil if the signature scheme doesn't need commitments
+func (op *operator) signingSet() (tcrypto.SigningCommitments, bool) {
+	if !op.signer.NeedsCommitments() {
+		return nil, true
+	}
+	if op.ownSigningCommitment == nil || len(op.signingCommitments) < int(op.quorum()) {
+		return nil, false
+	}
+	ret := tcrypto.SigningCommitments{op.peerIndex(): op.signingCommitments[op.peerIndex()]}
+	for _, index := range op.signingCommitments.Indices() {
+		if len(ret) >= int(op.quorum()) {
+			break
+		}
+		ret[index] = op.signingCommitments[index]
+	}
+	return ret, true
+}
@@ -65,6 +65,8 @@ var stages = map[int]*stageParams{
 			consensusStageLeaderStarting,
 			consensusStageSubStarting,
 			consensusStageLeaderCalculationsStarted,
+			// the round persisted before the crash is resumed, see recovery.go
+			consensusStageLeaderCalculationsFinished,
 		},
 	},
 	// unlimited time for VM TODO VM timeouts/gas budget to prevent loops
@@ -107,6 +109,8 @@ var stages = map[int]*stageParams{
 			consensusStageLeaderStarting,
 			consensusStageSubNotificationsSent,
 			consensusStageSubCalculationsStarted,
+			// the round persisted before the crash is resumed, see recovery.go
+			consensusStageSubCalculationsFinished,
 			consensusStageSubResultFinalized,
 		},
 	},
@@ -185,9 +189,15 @@ func (op *operator) setNextConsensusStage(nextStage int) {
 		op.log.Warnf("UNEXPECTED next consensusStage: %s -> %s, leader: %d, iAmTheLeader: %v",
 			stages[op.consensusStage].name, nextStageParams.name, leader, op.iAmCurrentLeader())
 	}
+	if nextStage == consensusStageLeaderCalculationsStarted || nextStage == consensusStageSubCalculationsStarted {
+		op.markRoundStarted()
+	}
+	if nextStage == consensusStageLeaderStarting || nextStage == consensusStageSubStarting {
+		op.startSigningTerm()
+	}
 	saveStage := op.consensusStage
 	op.consensusStage = nextStage
-	op.consensusStageDeadline = time.Now().Add(nextStageParams.timeout)
+	op.consensusStageDeadline = op.env.now().Add(nextStageParams.timeout)
 	timeout := "timeout: not set"
 	if nextStageParams.timeoutSet {
 		timeout = fmt.Sprintf("timeout: %v", nextStageParams.timeout)
@@ -201,7 +211,7 @@ func (op *operator) consensusStageDeadlineExpired() bool {
 	if !stageParams.timeoutSet {
 		return false
 	}
-	return time.Now().After(op.consensusStageDeadline)
+	return op.env.now().After(op.consensusStageDeadline)
 }
 
 func oneOf(elem int, set ...int) bool {
@@ -13,6 +13,7 @@ import (
 	"github.com/iotaledger/wasp/packages/chain"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/tcrypto"
@@ -23,8 +24,11 @@ import (
 
 type operator struct {
 	chain chain.Chain
+	env   operatorEnv
 
 	dkshare *tcrypto.DKShare
+	// signs anchor transactions. Other messages of the committee are signed with dkshare
+	signer tcrypto.ThresholdSigner
 	//currentState
 	currentState state.VirtualState
 	stateTx      *sctransaction.Transaction
@@ -47,17 +51,71 @@ type operator struct {
 	leaderStatus            *leaderStatus
 	sentResultToLeaderIndex uint16
 	sentResultToLeader      *sctransaction.Transaction
+	sentResultBlock         state.Block
+	// signed hash message sent to the leader, re-sent until the round is finalized, see recovery.go
+	sentResultToLeaderMsg    []byte
+	nextResendResultToLeader time.Time
+	// true if the round of the leader may be persisted in the registry
+	roundPersisted bool
 
 	postedResultTxid       *valuetransaction.ID
 	nextPullInclusionLevel time.Time // if postedResultTxid != nil
 
 	nextArgSolidificationDeadline time.Time
 
+	// digests of the leader proposals in the current state, by leader index
+	ownProposalDigests  map[uint16]*chain.ProposalDigestMsg
+	peerProposalDigests map[uint16][]*chain.ProposalDigestMsg
+
+	// votes for the emergency mode in the current state, by sender, and the active mode, see emergency.go
+	emergencyVotes map[uint16]*chain.EmergencyVoteMsg
+	emergency      *emergencyMode
+	// number of votes required to activate the emergency mode. The quorum of the committee if 0
+	emergencyQuorumParam uint16
+
+	// adapts number of requests in the batch to the VM execution time
+	batchSizer *batchSizer
+	// if true, requests in the batch are ordered by the threshold signature of the request set, see fairorder.go
+	fairOrdering bool
+	// the request set fixed by the node as the leader in the current state
+	fairOrder *fairOrderSet
+	// hashes of request sets signed for each leader in the current state
+	fairOrderSigned map[uint16]hashing.HashValue
+	// the seed of the next block fixed by the node as the leader in the current state, see vrf.go
+	vrf *vrfRound
+	// batch timestamps signed for each leader in the current state
+	vrfSigned map[uint16]int64
+	// counts requests of senders for the rate limit, see intake.go
+	intakeLimiter *intakeLimiter
+	// if true, the next round is started before the anchor transaction of the previous one is confirmed,
+	// see pipeline.go
+	pipelining bool
+	// the finalized round of the current state and the state produced by the previous round
+	// which is not confirmed yet
+	finalizedRound *finalizedRound
+	tentative      *tentativeState
+	// number of state transitions after which the request must be included into the batch, see age.go.
+	// 0 if disabled
+	maxRequestAge uint32
+
 	log *logger.Logger
 
 	// data for concurrent access, from APIs mostly
 	concurrentAccessMutex sync.RWMutex
 	requestIdsProtected   map[coretypes.RequestID]bool
+	// snapshot of the state of the operator and the last rounds, see info.go
+	info         *chain.ConsensusInfo
+	recentRounds []*chain.RoundInfo
+
+	// time of the start of calculations in the current round. Zero if not started
+	roundStarted time.Time
+
+	// nonce commitments of the current leader term, see signing.go. The leader collects commitments
+	// of peers by index, the subordinate keeps the own commitment and the signing set of the batch
+	signingCommitments          tcrypto.SigningCommitments
+	ownSigningCommitment        []byte
+	nextResendSigningCommitment time.Time
+	batchSigningCommitments     tcrypto.SigningCommitments
 
 	// Channels for accepting external events.
 	eventStateTransitionMsgCh           chan *chain.StateTransitionMsg
@@ -67,10 +125,18 @@ type operator struct {
 	eventStartProcessingBatchMsgCh      chan *chain.StartProcessingBatchMsg
 	eventResultCalculatedCh             chan *chain.VMResultMsg
 	eventSignedHashMsgCh                chan *chain.SignedHashMsg
+	eventProposalDigestMsgCh            chan *chain.ProposalDigestMsg
+	eventEmergencyMsgCh                 chan *chain.EmergencyMsg
+	eventEmergencyVoteMsgCh             chan *chain.EmergencyVoteMsg
+	eventFairOrderShareMsgCh            chan *chain.FairOrderShareMsg
+	eventSigningCommitmentMsgCh         chan *chain.SigningCommitmentMsg
+	eventVRFShareMsgCh                  chan *chain.VRFShareMsg
 	eventNotifyFinalResultPostedMsgCh   chan *chain.NotifyFinalResultPostedMsg
 	eventTransactionInclusionLevelMsgCh chan *chain.TransactionInclusionLevelMsg
 	eventTimerMsgCh                     chan chain.TimerTick
-	closeCh                             chan bool
+	// the replay waits on it until the previous event is processed, see replay.go
+	syncCh  chan struct{}
+	closeCh chan bool
 }
 
 type leaderStatus struct {
@@ -82,6 +148,8 @@ type leaderStatus struct {
 	resultTx      *sctransaction.Transaction
 	finalized     bool
 	signedResults []*signedResult
+	// commitments of the signing set sent with the batch proposal. Nil for BLS
+	commitments tcrypto.SigningCommitments
 }
 
 type signedResult struct {
@@ -103,19 +171,59 @@ type request struct {
 	notifications []bool
 	// true if arguments were decoded/solidified already. If not, the request in not eligible for the batch
 	argsSolid bool
+	// the node doesn't propose the request until the time, see intake.go
+	deferredUntil time.Time
+	// index of the state the age of the request is counted from, if known, see age.go
+	stateIndexReceived uint32
+	stateIndexKnown    bool
+	// the last reason why the overdue request was not included into the batch, see age.go
+	rejectReason string
 
 	log *logger.Logger
 }
 
-func NewOperator(committee chain.Chain, dkshare *tcrypto.DKShare, log *logger.Logger) *operator {
+func NewOperator(committee chain.Chain, dkshare *tcrypto.DKShare, signer tcrypto.ThresholdSigner, log *logger.Logger) *operator {
+	return newOperator(committee, dkshare, signer, nodeEnv{},
+		time.Duration(parameters.GetInt(parameters.ConsensusBatchTimeBudget))*time.Millisecond,
+		parameters.GetInt(parameters.ConsensusMaxBatchSize),
+		parameters.GetBool(parameters.ConsensusFairOrdering),
+		uint16(parameters.GetInt(parameters.ConsensusEmergencyQuorum)),
+		parameters.GetBool(parameters.ConsensusPipelining),
+		uint32(parameters.GetInt(parameters.ConsensusMaxRequestAge)),
+		log,
+	)
+}
+
+func newOperator(
+	committee chain.Chain,
+	dkshare *tcrypto.DKShare,
+	signer tcrypto.ThresholdSigner,
+	env operatorEnv,
+	batchTimeBudget time.Duration,
+	maxBatchSize int,
+	fairOrdering bool,
+	emergencyQuorum uint16,
+	pipelining bool,
+	maxRequestAge uint32,
+	log *logger.Logger,
+) *operator {
 	defer committee.SetReadyConsensus()
 
 	ret := &operator{
 		chain:                               committee,
+		env:                                 env,
 		dkshare:                             dkshare,
+		signer:                              signer,
 		requests:                            make(map[coretypes.RequestID]*request),
 		requestIdsProtected:                 make(map[coretypes.RequestID]bool),
+		ownProposalDigests:                  make(map[uint16]*chain.ProposalDigestMsg),
+		peerProposalDigests:                 make(map[uint16][]*chain.ProposalDigestMsg),
+		emergencyVotes:                      make(map[uint16]*chain.EmergencyVoteMsg),
+		fairOrderSigned:                     make(map[uint16]hashing.HashValue),
+		vrfSigned:                           make(map[uint16]int64),
+		emergencyQuorumParam:                emergencyQuorum,
 		peerPermutation:                     util.NewPermutation16(committee.Size(), nil),
+		intakeLimiter:                       newIntakeLimiter(env.now()),
 		log:                                 log.Named("c"),
 		eventStateTransitionMsgCh:           make(chan *chain.StateTransitionMsg),
 		eventBalancesMsgCh:                  make(chan chain.BalancesMsg),
@@ -124,10 +232,21 @@ func NewOperator(committee chain.Chain, dkshare *tcrypto.DKShare, log *logger.Lo
 		eventStartProcessingBatchMsgCh:      make(chan *chain.StartProcessingBatchMsg),
 		eventResultCalculatedCh:             make(chan *chain.VMResultMsg),
 		eventSignedHashMsgCh:                make(chan *chain.SignedHashMsg),
+		eventProposalDigestMsgCh:            make(chan *chain.ProposalDigestMsg),
+		eventEmergencyMsgCh:                 make(chan *chain.EmergencyMsg),
+		eventEmergencyVoteMsgCh:             make(chan *chain.EmergencyVoteMsg),
+		eventFairOrderShareMsgCh:            make(chan *chain.FairOrderShareMsg),
+		eventSigningCommitmentMsgCh:         make(chan *chain.SigningCommitmentMsg),
+		eventVRFShareMsgCh:                  make(chan *chain.VRFShareMsg),
 		eventNotifyFinalResultPostedMsgCh:   make(chan *chain.NotifyFinalResultPostedMsg),
 		eventTransactionInclusionLevelMsgCh: make(chan *chain.TransactionInclusionLevelMsg),
 		eventTimerMsgCh:                     make(chan chain.TimerTick),
+		syncCh:                              make(chan struct{}),
 		closeCh:                             make(chan bool),
+		batchSizer:                          newBatchSizer(batchTimeBudget, maxBatchSize),
+		fairOrdering:                        fairOrdering,
+		pipelining:                          pipelining,
+		maxRequestAge:                       maxRequestAge,
 	}
 	ret.setNextConsensusStage(consensusStageNoSync)
 	go ret.recvLoop()
@@ -169,6 +288,30 @@ func (op *operator) recvLoop() {
 			if ok {
 				op.eventSignedHashMsg(msg)
 			}
+		case msg, ok := <-op.eventProposalDigestMsgCh:
+			if ok {
+				op.eventProposalDigestMsg(msg)
+			}
+		case msg, ok := <-op.eventEmergencyMsgCh:
+			if ok {
+				op.eventEmergencyMsg(msg)
+			}
+		case msg, ok := <-op.eventEmergencyVoteMsgCh:
+			if ok {
+				op.eventEmergencyVoteMsg(msg)
+			}
+		case msg, ok := <-op.eventFairOrderShareMsgCh:
+			if ok {
+				op.eventFairOrderShareMsg(msg)
+			}
+		case msg, ok := <-op.eventSigningCommitmentMsgCh:
+			if ok {
+				op.eventSigningCommitmentMsg(msg)
+			}
+		case msg, ok := <-op.eventVRFShareMsgCh:
+			if ok {
+				op.eventVRFShareMsg(msg)
+			}
 		case msg, ok := <-op.eventNotifyFinalResultPostedMsgCh:
 			if ok {
 				op.eventNotifyFinalResultPostedMsg(msg)
@@ -181,9 +324,11 @@ func (op *operator) recvLoop() {
 			if ok {
 				op.eventTimerMsg(msg)
 			}
+		case <-op.syncCh:
 		case <-op.closeCh:
 			return
 		}
+		op.publishInfo()
 	}
 }
 
@@ -0,0 +1,223 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Verifiable random output of the block, see coretypes.VRF.
+// Before the leader proposes the batch, the committee signs the seed of the block: the hash of the current state,
+// the index of the next block and the timestamp of the batch:
+// - the leader fixes the timestamp and sends it, together with its own signature share of the seed,
+//   to subordinates (chain.VRFShareMsg)
+// - each subordinate signs the seed with its key share and sends the signature share back. The node signs
+//   only one timestamp of each leader in the same state, so the leader can't try different timestamps until
+//   it finds the output it likes
+// - with the quorum of signature shares the leader recovers the BLS threshold signature of the seed. It sends
+//   the signature with the batch proposal, subordinates check it with the BLS address of the committee and
+//   refuse to process the batch without the valid signature
+// The seed is always signed with the BLS key shares of the committee, whatever is the signature scheme of
+// the anchor transaction
+
+// vrfResendPeriod is the period the leader re-sends the fixed timestamp to subordinates until the
+// quorum of signature shares is collected
+const vrfResendPeriod = 2 * time.Second
+
+// vrfRound is the seed of the next block fixed by the leader
+type vrfRound struct {
+	timestamp int64
+	seed      []byte
+	// signature shares of the seed, by the peer index
+	shares map[uint16]tbdn.SigShare
+	// BLS signature of the seed. nil until the quorum of shares is collected
+	sig        signaturescheme.Signature
+	nextResend time.Time
+}
+
+// batchVRF is called by the leader before it proposes the batch. The timestamp of the batch is fixed once in
+// the state, the timestamp of the next call is ignored then, see batchTimestamp.
+// Returns the VRF of the block after the quorum of peers has signed its seed, false until then
+func (op *operator) batchVRF(ts int64) (coretypes.VRF, bool) {
+	if op.vrf == nil {
+		if err := op.fixVRFSeed(ts); err != nil {
+			op.log.Errorf("failed to fix the seed of the block: %v", err)
+			return coretypes.VRF{}, false
+		}
+	}
+	if op.vrf.sig == nil {
+		op.sendVRFShare()
+		return coretypes.VRF{}, false
+	}
+	vrf, err := coretypes.NewVRF(op.currentState.Hash(), op.mustStateIndex()+1, op.vrf.timestamp, op.vrf.sig)
+	if err != nil {
+		op.log.Errorf("failed to produce the VRF of the block: %v", err)
+		return coretypes.VRF{}, false
+	}
+	return vrf, true
+}
+
+// batchTimestamp returns the timestamp fixed for the VRF of the next block, if any, otherwise the local clock
+func (op *operator) batchTimestamp(ts int64) int64 {
+	if op.vrf != nil {
+		return op.vrf.timestamp
+	}
+	return ts
+}
+
+func (op *operator) fixVRFSeed(ts int64) error {
+	round := &vrfRound{
+		timestamp: ts,
+		seed:      op.vrfSeed(ts),
+		shares:    make(map[uint16]tbdn.SigShare),
+	}
+	ownShare, err := op.dkshare.SignShare(round.seed)
+	if err != nil {
+		return err
+	}
+	round.shares[op.peerIndex()] = ownShare
+	op.vrf = round
+	op.log.Debugf("seed of the block fixed with the timestamp %d", ts)
+	op.recoverVRFSig()
+	return nil
+}
+
+// sendVRFShare sends the fixed timestamp with own signature share to subordinates, periodically
+func (op *operator) sendVRFShare() {
+	if op.env.now().Before(op.vrf.nextResend) {
+		return
+	}
+	msg := &chain.VRFShareMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{
+			BlockIndex:  op.mustStateIndex(),
+			SenderIndex: op.peerIndex(),
+		},
+		BatchTimestamp: op.vrf.timestamp,
+		SigShare:       op.vrf.shares[op.peerIndex()],
+	}
+	numSucc := op.chain.SendMsgToCommitteePeers(chain.MsgVRFShare, util.MustBytes(msg), op.env.now().UnixNano())
+	op.log.Debugf("%d 'msgVRFShare' messages sent to peers", numSucc)
+	op.vrf.nextResend = op.env.now().Add(vrfResendPeriod)
+}
+
+// recoverVRFSig recovers the BLS signature of the seed when the quorum of shares is collected
+func (op *operator) recoverVRFSig() {
+	if op.vrf.sig != nil || len(op.vrf.shares) < int(op.quorum()) {
+		return
+	}
+	sigShares := make([][]byte, 0, len(op.vrf.shares))
+	for _, s := range op.vrf.shares {
+		sigShares = append(sigShares, s)
+	}
+	sig, err := op.dkshare.RecoverFullSignature(sigShares, op.vrf.seed)
+	if err != nil {
+		op.log.Errorf("failed to recover the signature of the seed: %v", err)
+		return
+	}
+	op.vrf.sig = sig
+}
+
+// vrfSeed is the seed of the next block with the timestamp
+func (op *operator) vrfSeed(ts int64) []byte {
+	return coretypes.VRFSeed(op.currentState.Hash(), op.mustStateIndex()+1, ts)
+}
+
+// verifyVRF checks the VRF of the batch proposal and returns it
+func (op *operator) verifyVRF(msg *chain.StartProcessingBatchMsg) (coretypes.VRF, error) {
+	if len(msg.VRFProof) == 0 {
+		return coretypes.VRF{}, fmt.Errorf("VRF proof is missing")
+	}
+	vrf, err := coretypes.VRFFromProof(op.currentState.Hash(), msg.BlockIndex+1, msg.Timestamp, msg.VRFProof, op.dkshare.Address)
+	if err != nil {
+		return coretypes.VRF{}, fmt.Errorf("invalid VRF proof: %v", err)
+	}
+	return vrf, nil
+}
+
+// EventVRFShareMsg the timestamp of the leader or the signature share of the seed from the subordinate
+func (op *operator) EventVRFShareMsg(msg *chain.VRFShareMsg) {
+	op.eventVRFShareMsgCh <- msg
+}
+
+// eventVRFShareMsg internal handler
+func (op *operator) eventVRFShareMsg(msg *chain.VRFShareMsg) {
+	op.log.Debugw("EventVRFShareMsg",
+		"sender", msg.SenderIndex,
+		"ts", msg.BatchTimestamp,
+	)
+	stateIndex, ok := op.blockIndex()
+	if !ok || msg.BlockIndex != stateIndex {
+		return
+	}
+	// the share must belong to the sender
+	if err := verifyLeaderSig(op.dkshare, msg.SenderIndex, op.vrfSeed(msg.BatchTimestamp), msg.SigShare); err != nil {
+		op.log.Warnf("EventVRFShareMsg: invalid signature share from peer #%d: %v", msg.SenderIndex, err)
+		return
+	}
+	if op.iAmCurrentLeader() {
+		op.receiveVRFShare(msg)
+	} else {
+		op.signVRFSeed(msg)
+	}
+	op.takeAction()
+}
+
+// receiveVRFShare the leader collects signature shares of its seed
+func (op *operator) receiveVRFShare(msg *chain.VRFShareMsg) {
+	if op.vrf == nil || msg.BatchTimestamp != op.vrf.timestamp {
+		return
+	}
+	op.vrf.shares[msg.SenderIndex] = msg.SigShare
+	op.recoverVRFSig()
+}
+
+// signVRFSeed the subordinate signs the seed of the current leader, but only one timestamp of each leader
+// in the state, and sends the signature share back
+func (op *operator) signVRFSeed(msg *chain.VRFShareMsg) {
+	if leader, _ := op.currentLeader(); msg.SenderIndex != leader {
+		op.log.Debugf("EventVRFShareMsg: peer #%d is not the current leader", msg.SenderIndex)
+		return
+	}
+	if signed, ok := op.vrfSigned[msg.SenderIndex]; ok && signed != msg.BatchTimestamp {
+		op.log.Warnf("EventVRFShareMsg: leader #%d asked to sign another timestamp in the same state",
+			msg.SenderIndex)
+		return
+	}
+	if msg.BatchTimestamp <= op.currentState.Timestamp() {
+		op.log.Warnf("EventVRFShareMsg: timestamp of leader #%d is not ahead of the state", msg.SenderIndex)
+		return
+	}
+	diff := op.env.now().UnixNano() - msg.BatchTimestamp
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > chain.MaxClockDifferenceAllowed.Nanoseconds() {
+		op.log.Warnf("EventVRFShareMsg: clock difference with leader #%d is too big: %d ns", msg.SenderIndex, diff)
+		return
+	}
+	share, err := op.dkshare.SignShare(op.vrfSeed(msg.BatchTimestamp))
+	if err != nil {
+		op.log.Errorf("failed to sign the seed of the block: %v", err)
+		return
+	}
+	op.vrfSigned[msg.SenderIndex] = msg.BatchTimestamp
+	reply := &chain.VRFShareMsg{
+		PeerMsgHeader: chain.PeerMsgHeader{
+			BlockIndex:  msg.BlockIndex,
+			SenderIndex: op.peerIndex(),
+		},
+		BatchTimestamp: msg.BatchTimestamp,
+		SigShare:       share,
+	}
+	if err := op.chain.SendMsg(msg.SenderIndex, chain.MsgVRFShare, util.MustBytes(reply)); err != nil {
+		op.log.Error(err)
+	}
+}
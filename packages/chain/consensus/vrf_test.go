@@ -0,0 +1,116 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package consensus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/require"
+)
+
+// vrfProof returns the threshold BLS signature of the seed of the block, signed by the peers
+func vrfProof(t *testing.T, dks []*tcrypto.DKShare, peers []int, prevStateHash hashing.HashValue, blockIndex uint32, ts int64) []byte {
+	seed := coretypes.VRFSeed(prevStateHash, blockIndex, ts)
+	shares := make([][]byte, 0, len(peers))
+	for _, i := range peers {
+		s, err := dks[i].SignShare(seed)
+		require.NoError(t, err)
+		shares = append(shares, s)
+	}
+	sig, err := dks[peers[0]].RecoverFullSignature(shares, seed)
+	require.NoError(t, err)
+	return sig.Bytes()
+}
+
+func TestVerifyVRF(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+	o.stateTransition(5)
+	stateHash := o.op().currentState.Hash()
+	ts := o.clock.UnixNano()
+
+	newMsg := func(proof []byte) *chain.StartProcessingBatchMsg {
+		return &chain.StartProcessingBatchMsg{
+			PeerMsgHeader: chain.PeerMsgHeader{BlockIndex: 5, SenderIndex: 1},
+			Timestamp:     ts,
+			VRFProof:      proof,
+		}
+	}
+	proof := vrfProof(t, dks, []int{0, 1, 2}, stateHash, 6, ts)
+	vrf, err := o.op().verifyVRF(newMsg(proof))
+	require.NoError(t, err)
+	require.EqualValues(t, 6, vrf.BlockIndex)
+	require.NoError(t, vrf.Verify(dks[0].Address))
+	// any quorum of peers produces the same output
+	other, err := o.op().verifyVRF(newMsg(vrfProof(t, dks, []int{1, 2, 3}, stateHash, 6, ts)))
+	require.NoError(t, err)
+	require.EqualValues(t, vrf.Output, other.Output)
+
+	t.Run("proof missing", func(t *testing.T) {
+		_, err := o.op().verifyVRF(newMsg(nil))
+		require.Error(t, err)
+	})
+	t.Run("another timestamp", func(t *testing.T) {
+		_, err := o.op().verifyVRF(newMsg(vrfProof(t, dks, []int{0, 1, 2}, stateHash, 6, ts+1)))
+		require.Error(t, err)
+	})
+	t.Run("another block", func(t *testing.T) {
+		_, err := o.op().verifyVRF(newMsg(vrfProof(t, dks, []int{0, 1, 2}, stateHash, 7, ts)))
+		require.Error(t, err)
+	})
+	t.Run("signed by another key", func(t *testing.T) {
+		sig := signaturescheme.RandBLS().Sign(coretypes.VRFSeed(stateHash, 6, ts))
+		_, err := o.op().verifyVRF(newMsg(sig.Bytes()))
+		require.Error(t, err)
+	})
+}
+
+func TestSignVRFSeed(t *testing.T) {
+	dks := newTestDKShares(t, 4, 3)
+	o := newTestOperator(t, dks, 0, 0)
+	o.stateTransition(5)
+	// the emergency fixes the leader of the test
+	o.declareEmergency(1, 2)
+	o.sendVote(o.vote(2, 5, 1, 2))
+	o.sendVote(o.vote(3, 5, 1, 2))
+	require.True(t, o.op().isEmergency())
+
+	stateHash := o.op().currentState.Hash()
+	sendShare := func(sender uint16, ts int64) {
+		msg := &chain.VRFShareMsg{
+			PeerMsgHeader:  chain.PeerMsgHeader{BlockIndex: 5, SenderIndex: sender},
+			BatchTimestamp: ts,
+		}
+		var err error
+		msg.SigShare, err = dks[sender].SignShare(coretypes.VRFSeed(stateHash, 6, ts))
+		require.NoError(t, err)
+		o.peerMsg(sender, chain.MsgVRFShare, util.MustBytes(msg), ts)
+	}
+
+	// only the leader is answered
+	ts := o.clock.UnixNano()
+	sendShare(2, ts)
+	require.NotContains(t, o.op().vrfSigned, uint16(2))
+
+	// the timestamp too far from the local clock is not signed
+	sendShare(1, o.clock.Add(time.Hour).UnixNano())
+	require.NotContains(t, o.op().vrfSigned, uint16(1))
+
+	sendShare(1, ts)
+	require.EqualValues(t, ts, o.op().vrfSigned[1])
+	// the leader can't get another timestamp signed in the same state
+	sendShare(1, ts+1)
+	require.EqualValues(t, ts, o.op().vrfSigned[1])
+
+	// the next state resets the round
+	o.stateTransition(6)
+	require.Empty(t, o.op().vrfSigned)
+}
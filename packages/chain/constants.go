@@ -3,7 +3,10 @@
 
 package chain
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 const (
 	// confirmation time assumption. Average time from posting a transaction to finality
@@ -37,4 +40,47 @@ const (
 
 	// check arg solidification period
 	CheckArgSolidificationEvery = 1 * time.Second
+
+	// subordinate re-sends the signature share to the leader until the result is finalized
+	ResendSignedHashPeriod = 3 * time.Second
+
+	// subordinate re-sends the nonce commitment to the leader until the batch proposal comes.
+	// Only for signature schemes which need commitments, see tcrypto.ThresholdSigner
+	ResendSigningCommitmentPeriod = 1 * time.Second
+
+	// each node sends heartbeats to other committee peers with the period
+	HeartbeatPeriod = 2 * time.Second
+
+	// committee peer is considered dead if no message was received from it for the period.
+	// Messages are not sent to dead peers until they are heard again
+	PeerDeadTimeout = 3 * HeartbeatPeriod
+
+	// draining chain is dismissed after the period even if the round in progress is not finished
+	DrainTimeout = 2 * time.Minute
 )
+
+// MaxEmergencyBlocks is the maximum number of blocks production of which can be delegated to one node
+// in the emergency mode, see consensus/emergency.go
+const MaxEmergencyBlocks = 100
+
+// MaxSyncBlocksAhead is the maximum number of blocks a lagging node requests from the committee peers at once.
+// The chain of received blocks is validated by the anchor transaction of the last block, see statemgr/catchup.go
+const MaxSyncBlocksAhead = 16
+
+// CrashPointEnvVar is the environment variable of the node process which names the point of the consensus round
+// at which the node exits immediately, as if it crashed. It is set only by cluster tests to crash the node
+// deterministically, see tools/cluster/crash.go
+const CrashPointEnvVar = "WASP_CRASH_POINT"
+
+// Crash points of the consensus round, see CrashPointEnvVar
+const (
+	// the leader has persisted the round with the signature share of a peer, before the result is finalized
+	CrashPointLeaderSigSharePersisted = "leaderSigSharePersisted"
+	// the subordinate has sent its signature share to the leader and persisted the round
+	CrashPointSubResultPersisted = "subResultPersisted"
+)
+
+// CrashPointLogMessage is logged by the consensus operator right before the node exits at the crash point
+func CrashPointLogMessage(name string) string {
+	return fmt.Sprintf("CRASH POINT '%s' reached", name)
+}
@@ -41,3 +41,10 @@ func (reqMsg *RequestMsg) RequestBlock() *sctransaction.RequestSection {
 func (reqMsg *RequestMsg) Timelock() uint32 {
 	return reqMsg.RequestBlock().Timelock()
 }
+
+// EmergencyMsg is sent to the consensus operator when the owner of the node declares the emergency:
+// the node votes to delegate production of the next NumBlocks blocks to the node Delegate
+type EmergencyMsg struct {
+	Delegate  uint16
+	NumBlocks uint32
+}
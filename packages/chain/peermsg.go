@@ -4,12 +4,14 @@
 package chain
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/packages/util"
 )
 
@@ -104,6 +106,18 @@ func (msg *StartProcessingBatchMsg) Write(w io.Writer) error {
 	if err := waspconn.WriteBalances(w, msg.Balances); err != nil {
 		return err
 	}
+	if err := util.WriteBytes16(w, msg.LeaderSig); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, msg.OrderSig); err != nil {
+		return err
+	}
+	if err := msg.SigningCommitments.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, msg.VRFProof); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -128,6 +142,21 @@ func (msg *StartProcessingBatchMsg) Read(r io.Reader) error {
 	if msg.Balances, err = waspconn.ReadBalances(r); err != nil {
 		return err
 	}
+	if msg.LeaderSig, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if msg.OrderSig, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if len(msg.OrderSig) == 0 {
+		msg.OrderSig = nil
+	}
+	if msg.SigningCommitments, err = tcrypto.ReadSigningCommitments(r); err != nil {
+		return err
+	}
+	if msg.VRFProof, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -173,6 +202,169 @@ func (msg *SignedHashMsg) Read(r io.Reader) error {
 	return nil
 }
 
+func (msg *ProposalDigestMsg) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, msg.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, msg.LeaderIndex); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, msg.ProposalTimestamp); err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.ProposalHash[:]); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, msg.LeaderSig); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *ProposalDigestMsg) Read(r io.Reader) error {
+	if err := util.ReadUint32(r, &msg.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &msg.LeaderIndex); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &msg.ProposalTimestamp); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &msg.ProposalHash); err != nil {
+		return err
+	}
+	var err error
+	if msg.LeaderSig, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Essence returns bytes of the vote which are signed by the sender
+func (msg *EmergencyVoteMsg) Essence() []byte {
+	var buf bytes.Buffer
+	_ = msg.writeEssence(&buf)
+	return buf.Bytes()
+}
+
+func (msg *EmergencyVoteMsg) writeEssence(w io.Writer) error {
+	if err := util.WriteUint32(w, msg.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, msg.Delegate); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, msg.NumBlocks); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *EmergencyVoteMsg) Write(w io.Writer) error {
+	if err := msg.writeEssence(w); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, msg.SigShare); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *EmergencyVoteMsg) Read(r io.Reader) error {
+	if err := util.ReadUint32(r, &msg.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &msg.Delegate); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &msg.NumBlocks); err != nil {
+		return err
+	}
+	var err error
+	if msg.SigShare, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *FairOrderShareMsg) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, msg.BlockIndex); err != nil {
+		return err
+	}
+	if _, err := w.Write(msg.SetHash[:]); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, msg.SigShare); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *FairOrderShareMsg) Read(r io.Reader) error {
+	if err := util.ReadUint32(r, &msg.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &msg.SetHash); err != nil {
+		return err
+	}
+	var err error
+	if msg.SigShare, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *VRFShareMsg) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, msg.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint64(w, uint64(msg.BatchTimestamp)); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, msg.SigShare); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *VRFShareMsg) Read(r io.Reader) error {
+	if err := util.ReadUint32(r, &msg.BlockIndex); err != nil {
+		return err
+	}
+	var ts uint64
+	if err := util.ReadUint64(r, &ts); err != nil {
+		return err
+	}
+	msg.BatchTimestamp = int64(ts)
+	var err error
+	if msg.SigShare, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *SigningCommitmentMsg) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, msg.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, msg.Commitment); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (msg *SigningCommitmentMsg) Read(r io.Reader) error {
+	if err := util.ReadUint32(r, &msg.BlockIndex); err != nil {
+		return err
+	}
+	var err error
+	if msg.Commitment, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (msg *GetBlockMsg) Write(w io.Writer) error {
 	return util.WriteUint32(w, msg.BlockIndex)
 }
@@ -4,6 +4,8 @@
 package chain
 
 import (
+	"time"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -11,6 +13,7 @@ import (
 	"github.com/iotaledger/wasp/packages/peering"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
 	"github.com/iotaledger/wasp/packages/vm"
 )
@@ -26,6 +29,12 @@ const (
 	MsgStateUpdate             = 6 + peering.FirstUserMsgCode
 	MsgBatchHeader             = 7 + peering.FirstUserMsgCode
 	MsgTestTrace               = 8 + peering.FirstUserMsgCode
+	MsgProposalDigest          = 9 + peering.FirstUserMsgCode
+	MsgHeartbeat               = 10 + peering.FirstUserMsgCode
+	MsgEmergencyVote           = 11 + peering.FirstUserMsgCode
+	MsgFairOrderShare          = 12 + peering.FirstUserMsgCode
+	MsgSigningCommitment       = 13 + peering.FirstUserMsgCode
+	MsgVRFShare                = 14 + peering.FirstUserMsgCode
 )
 
 type TimerTick int
@@ -75,6 +84,16 @@ type StartProcessingBatchMsg struct {
 	FeeDestination coretypes.AgentID
 	// balances/outputs
 	Balances map[valuetransaction.ID][]*balance.Balance
+	// signature of the proposal by the leader with its key share, see root.ProposalEssence
+	LeaderSig tbdn.SigShare
+	// threshold signature of the set of requests in the fair ordering mode, nil otherwise.
+	// The order of requests is derived from it, see consensus/fairorder.go
+	OrderSig []byte
+	// nonce commitments of signers of the anchor transaction, collected by the leader.
+	// Empty if the signature scheme of the chain doesn't need them, see tcrypto.ThresholdSigner
+	SigningCommitments tcrypto.SigningCommitments
+	// BLS signature of the seed of the block by the committee, see coretypes.VRF and consensus/vrf.go
+	VRFProof []byte
 }
 
 // after calculations the result peer responds to the start processing msg
@@ -93,6 +112,64 @@ type SignedHashMsg struct {
 	SigShare tbdn.SigShare
 }
 
+// message is sent by the subordinate to other peers upon receiving StartProcessingBatchMsg from the leader.
+// It relays the digest of the received batch proposal together with the signature of the leader.
+// Peers cross-check digests in order to detect the leader which signs different proposals for different peers
+type ProposalDigestMsg struct {
+	PeerMsgHeader
+	// index of the leader which sent the proposal
+	LeaderIndex uint16
+	// timestamp of the proposal, as set by the leader
+	ProposalTimestamp int64
+	// hash of the proposal
+	ProposalHash hashing.HashValue
+	// signature of the proposal by the leader
+	LeaderSig tbdn.SigShare
+}
+
+// message is sent by the peer to all other peers when the owner of the node declares the emergency,
+// see consensus/emergency.go. It is the vote, signed with the key share of the sender, to delegate
+// production of the next blocks to one node of the committee
+type EmergencyVoteMsg struct {
+	PeerMsgHeader
+	// index of the node the block production is delegated to
+	Delegate uint16
+	// number of blocks the emergency mode lasts
+	NumBlocks uint32
+	// signature of the vote essence by the sender
+	SigShare tbdn.SigShare
+}
+
+// message is exchanged between the leader and subordinates in the fair ordering mode, see consensus/fairorder.go.
+// The leader sends it to fix the set of requests of the next batch, subordinates send back
+// their signature shares of the set. The threshold signature of the set determines the order of requests
+type FairOrderShareMsg struct {
+	PeerMsgHeader
+	// hash of the set of request ids, independent of the order
+	SetHash hashing.HashValue
+	// signature of the set by the sender with its key share
+	SigShare tbdn.SigShare
+}
+
+// message is sent by the subordinate to the leader of the term if the signature scheme of the chain
+// needs nonce commitments. The leader includes commitments of the quorum into StartProcessingBatchMsg
+type SigningCommitmentMsg struct {
+	PeerMsgHeader
+	// commitment to the nonce of the next signature share of the sender
+	Commitment []byte
+}
+
+// message is exchanged between the leader and subordinates to produce the VRF of the block, see consensus/vrf.go.
+// The leader sends it to fix the timestamp of the next batch, subordinates send back their signature shares
+// of the seed of the block
+type VRFShareMsg struct {
+	PeerMsgHeader
+	// timestamp of the next batch, which is part of the seed
+	BatchTimestamp int64
+	// signature of the seed by the sender with its key share
+	SigShare tbdn.SigShare
+}
+
 // request block of updates from peer. Used in syn process
 type GetBlockMsg struct {
 	PeerMsgHeader
@@ -126,7 +203,7 @@ type TestTraceMsg struct {
 
 // state manager notifies consensus operator about changed state
 // only sent internally within committee
-// state transition is always from state N to state N+1
+// state transition is from state N to state N+1, or to N+k when the node catches up with the committee
 type StateTransitionMsg struct {
 	// new variable state
 	VariableState state.VirtualState
@@ -155,4 +232,6 @@ type StateTransactionEvidenced struct {
 type VMResultMsg struct {
 	Task   *vm.VMTask
 	Leader uint16
+	// wall-clock time of the VM execution of the batch
+	Duration time.Duration
 }
@@ -10,6 +10,7 @@ import (
 
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/publisher"
 	"github.com/iotaledger/wasp/packages/state"
@@ -23,7 +24,11 @@ func (sm *stateManager) takeAction() {
 	if sm.checkStateApproval() {
 		return
 	}
+	if sm.checkCatchUp() {
+		return
+	}
 	sm.requestStateTransactionIfNeeded()
+	sm.requestCatchUpTransactionIfNeeded()
 	sm.requestStateUpdateFromPeerIfNeeded()
 }
 
@@ -145,18 +150,29 @@ func (sm *stateManager) checkStateApproval() bool {
 	sm.syncMessageDeadline = time.Now() // if not synced then immediately
 	sm.consensusNotifiedOnStateTransition = false
 
-	// publish state transition
+	sm.pruneSyncedBlocks()
+
+	sm.publishStateTransition(pending.block, varStateHash)
+	return true
+}
+
+// publishStateTransition publishes the new solid state and requests processed by the block
+func (sm *stateManager) publishStateTransition(block state.Block, stateHash hashing.HashValue) {
+	txid := block.StateTransactionID()
 	publisher.Publish("state",
 		sm.chain.ID().String(),
 		strconv.Itoa(int(sm.solidState.BlockIndex())),
-		strconv.Itoa(int(pending.block.Size())),
-		sm.approvingTransaction.ID().String(),
-		varStateHash.String(),
-		fmt.Sprintf("%d", pending.block.Timestamp()),
+		strconv.Itoa(int(block.Size())),
+		txid.String(),
+		stateHash.String(),
+		fmt.Sprintf("%d", block.Timestamp()),
 	)
 	// publish processed requests
-	for i, reqid := range pending.block.RequestIDs() {
-
+	for i, reqid := range block.RequestIDs() {
+		if *reqid == (coretypes.RequestID{}) {
+			// the state update of the empty block is not a request
+			continue
+		}
 		sm.chain.EventRequestProcessed().Trigger(*reqid)
 
 		publisher.Publish("request_out",
@@ -165,10 +181,9 @@ func (sm *stateManager) checkStateApproval() bool {
 			fmt.Sprintf("%d", reqid.Index()),
 			strconv.Itoa(int(sm.solidState.BlockIndex())),
 			strconv.Itoa(i),
-			strconv.Itoa(int(pending.block.Size())),
+			strconv.Itoa(int(block.Size())),
 		)
 	}
-	return true
 }
 
 func (sm *stateManager) requestStateUpdateFromPeerIfNeeded() {
@@ -181,19 +196,29 @@ func (sm *stateManager) requestStateUpdateFromPeerIfNeeded() {
 		// not time yet for the next message
 		return
 	}
-	// it is time to ask for the next state update to next peer in the permutation
-	data := util.MustBytes(&chain.GetBlockMsg{
-		PeerMsgHeader: chain.PeerMsgHeader{
-			BlockIndex: sm.solidState.BlockIndex() + 1,
-		},
-	})
-	// send messages until first without error
-	for i := uint16(0); i < sm.chain.Size(); i++ {
-		if err := sm.chain.SendMsg(sm.permutation.Next(), chain.MsgGetBatch, data); err == nil {
-			break
+	// it is time to ask for the missing blocks ahead, each to the next peer in the permutation,
+	// so the blocks are received from several peers in parallel
+	last := sm.largestEvidencedStateIndex
+	if last > sm.solidState.BlockIndex()+chain.MaxSyncBlocksAhead {
+		last = sm.solidState.BlockIndex() + chain.MaxSyncBlocksAhead
+	}
+	for blockIndex := sm.solidState.BlockIndex() + 1; blockIndex <= last; blockIndex++ {
+		if _, ok := sm.syncedBlocks[blockIndex]; ok {
+			continue
+		}
+		data := util.MustBytes(&chain.GetBlockMsg{
+			PeerMsgHeader: chain.PeerMsgHeader{
+				BlockIndex: blockIndex,
+			},
+		})
+		// send messages until first without error
+		for i := uint16(0); i < sm.chain.Size(); i++ {
+			if err := sm.chain.SendMsg(sm.permutation.Next(), chain.MsgGetBatch, data); err == nil {
+				break
+			}
 		}
-		sm.syncMessageDeadline = time.Now().Add(chain.PeriodBetweenSyncMessages)
 	}
+	sm.syncMessageDeadline = time.Now().Add(chain.PeriodBetweenSyncMessages)
 }
 
 // index of evidenced state index is passed to record the largest one.
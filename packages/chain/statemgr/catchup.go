@@ -0,0 +1,160 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package statemgr
+
+import (
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/plugins/nodeconn"
+)
+
+// Catching up of a lagging node.
+// The node which is more than one block behind the committee requests up to chain.MaxSyncBlocksAhead
+// missing blocks from the committee peers in parallel. Instead of waiting for the anchor transaction
+// of each block from the tangle, the node requests only the anchor transaction of the last block
+// of the contiguous sequence of received blocks. The state hash is chained through all blocks, so
+// the state hash in that transaction validates the whole sequence at once.
+// The sequence is then committed block by block, so that all blocks are stored in the DB
+
+// isSyncedBlockExpected returns true if the block with the index may be received from peers while syncing
+func (sm *stateManager) isSyncedBlockExpected(blockIndex uint32) bool {
+	if !sm.solidStateValid {
+		return false
+	}
+	return blockIndex > sm.solidState.BlockIndex() &&
+		blockIndex <= sm.solidState.BlockIndex()+chain.MaxSyncBlocksAhead
+}
+
+// lastSyncedBlock returns the last block of the contiguous sequence of synced blocks
+// which starts right after the solid state, or nil if the next block has not been received yet
+func (sm *stateManager) lastSyncedBlock() state.Block {
+	var ret state.Block
+	for blockIndex := sm.solidState.BlockIndex() + 1; ; blockIndex++ {
+		b, ok := sm.syncedBlocks[blockIndex]
+		if !ok {
+			return ret
+		}
+		ret = b
+	}
+}
+
+// requestCatchUpTransactionIfNeeded requests from the node the anchor transaction of the
+// last block of the synced sequence. It only makes sense for sequences of more than one block:
+// the next block alone is approved the usual way, as a pending block
+func (sm *stateManager) requestCatchUpTransactionIfNeeded() {
+	if !sm.solidStateValid || sm.catchUpTransaction != nil {
+		return
+	}
+	if sm.catchUpTransactionDeadline.After(time.Now()) {
+		return
+	}
+	last := sm.lastSyncedBlock()
+	if last == nil || last.StateIndex() < sm.solidState.BlockIndex()+2 {
+		return
+	}
+	txid := last.StateTransactionID()
+	sm.log.Debugf("query catch-up transaction from the node. State index: #%d, txid = %s",
+		last.StateIndex(), txid.String())
+	_ = nodeconn.RequestConfirmedTransactionFromNode(&txid)
+	sm.catchUpTransactionDeadline = time.Now().Add(chain.StateTransactionRequestTimeout)
+}
+
+// checkCatchUp validates the synced blocks with the catch-up transaction and commits them.
+// Returns true if the state transition took place
+func (sm *stateManager) checkCatchUp() bool {
+	if !sm.solidStateValid || sm.catchUpTransaction == nil {
+		return false
+	}
+	tx := sm.catchUpTransaction
+	sm.catchUpTransaction = nil
+
+	stateBlock := tx.MustState()
+	if stateBlock.BlockIndex() <= sm.solidState.BlockIndex()+1 {
+		// outdated
+		return false
+	}
+	blocks := make([]state.Block, 0, stateBlock.BlockIndex()-sm.solidState.BlockIndex())
+	for blockIndex := sm.solidState.BlockIndex() + 1; blockIndex <= stateBlock.BlockIndex(); blockIndex++ {
+		b, ok := sm.syncedBlocks[blockIndex]
+		if !ok {
+			return false
+		}
+		blocks = append(blocks, b)
+	}
+
+	// replay the sequence in memory and check the resulting state hash
+	stateToApprove := sm.solidState.Clone()
+	for _, b := range blocks {
+		if err := stateToApprove.ApplyBlock(b); err != nil {
+			sm.log.Errorf("catch-up: can't apply block #%d: %v", b.StateIndex(), err)
+			sm.discardSyncedBlocks()
+			return false
+		}
+	}
+	if stateToApprove.Hash() != stateBlock.StateHash() {
+		h1 := stateToApprove.Hash()
+		h2 := stateBlock.StateHash()
+		sm.log.Errorf("catch-up: state hash %s of synced blocks #%d..#%d is not equal to the hash %s in the anchor transaction %s",
+			h1.String(), blocks[0].StateIndex(), stateBlock.BlockIndex(), h2.String(), tx.ID().String())
+		sm.discardSyncedBlocks()
+		return false
+	}
+
+	// the sequence is valid, commit it
+	for _, b := range blocks {
+		nextState := sm.solidState.Clone()
+		if err := nextState.ApplyBlock(b); err != nil {
+			// already checked above
+			sm.log.Panicf("catch-up: %v", err)
+		}
+		if err := nextState.CommitToDb(b); err != nil {
+			sm.log.Errorf("catch-up: failed to save state at index #%d: %v", nextState.BlockIndex(), err)
+			sm.discardSyncedBlocks()
+			return false
+		}
+		sm.solidState = nextState
+		sm.publishStateTransition(b, nextState.Hash())
+	}
+	vh := sm.solidState.Hash()
+	sm.log.Infof("STATE TRANSITION TO #%d BY CATCH-UP OF %d BLOCKS. Anchor transaction: %s",
+		sm.solidState.BlockIndex(), len(blocks), tx.ID().String())
+	sm.log.Debugf("STATE TRANSITION BY CATCH-UP. State hash: %s", vh.String())
+
+	sm.approvingTransaction = tx
+
+	// update state manager variables to the new state
+	sm.nextStateTransaction = nil
+	sm.pendingBlocks = make(map[hashing.HashValue]*pendingBlock) // clear pending batches
+	sm.permutation.Shuffle(vh[:])
+	sm.syncMessageDeadline = time.Now() // if not synced then immediately
+	sm.catchUpTransactionDeadline = time.Now()
+	sm.consensusNotifiedOnStateTransition = false
+	sm.pruneSyncedBlocks()
+	return true
+}
+
+// pruneSyncedBlocks removes synced blocks which are not ahead of the solid state anymore
+func (sm *stateManager) pruneSyncedBlocks() {
+	for blockIndex := range sm.syncedBlocks {
+		if !sm.isSyncedBlockExpected(blockIndex) {
+			delete(sm.syncedBlocks, blockIndex)
+		}
+	}
+	for blockIndex := range sm.syncedBatches {
+		if !sm.isSyncedBlockExpected(blockIndex) {
+			delete(sm.syncedBatches, blockIndex)
+		}
+	}
+}
+
+// discardSyncedBlocks drops all synced blocks when they can't be validated.
+// They will be requested again, from other peers
+func (sm *stateManager) discardSyncedBlocks() {
+	sm.syncedBlocks = make(map[uint32]state.Block)
+	sm.syncedBatches = make(map[uint32]*syncedBatch)
+	sm.catchUpTransactionDeadline = time.Now()
+}
@@ -0,0 +1,225 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package statemgr
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/require"
+)
+
+// testChain is the committee of 4 nodes without connections to peers
+type testChain struct {
+	chain.Chain
+	chainID coretypes.ChainID
+}
+
+func (c *testChain) ID() *coretypes.ChainID       { return &c.chainID }
+func (c *testChain) Size() uint16                 { return 4 }
+func (c *testChain) NumPeers() uint16             { return 4 }
+func (c *testChain) Quorum() uint16               { return 3 }
+func (c *testChain) HasQuorum() bool              { return false }
+func (c *testChain) ReceiveMessage(_ interface{}) {}
+func (c *testChain) EventRequestProcessed() *events.Event {
+	return events.NewEvent(func(handler interface{}, params ...interface{}) {})
+}
+
+// newTestStateManager returns the state manager with the solid state #solidIndex,
+// which knows the committee is at the state #solidIndex+3. It doesn't ask peers or the node for anything
+func newTestStateManager(t *testing.T, solidIndex uint32) *stateManager {
+	c := &testChain{chainID: coretypes.ChainID{1, 3, 3, 7}}
+	solid := state.NewVirtualState(mapdb.NewMapDB(), &c.chainID)
+	solid.ApplyBlockIndex(solidIndex)
+	never := time.Now().Add(time.Hour)
+	return &stateManager{
+		chain:                      c,
+		solidStateValid:            true,
+		solidState:                 solid,
+		pingPong:                   make([]bool, c.Size()),
+		pendingBlocks:              make(map[hashing.HashValue]*pendingBlock),
+		syncedBatches:              make(map[uint32]*syncedBatch),
+		syncedBlocks:               make(map[uint32]state.Block),
+		largestEvidencedStateIndex: solidIndex + 3,
+		syncMessageDeadline:        never,
+		catchUpTransactionDeadline: never,
+		permutation:                util.NewPermutation16(c.NumPeers(), nil),
+		log:                        testutil.NewLogger(t),
+	}
+}
+
+// testBlock is the block of two state updates, each setting its own variable
+func testBlock(t *testing.T, blockIndex uint32, txid valuetransaction.ID) state.Block {
+	updates := make([]state.StateUpdate, 2)
+	for i := range updates {
+		reqid := coretypes.NewRequestID((valuetransaction.ID)(hashing.HashStrings(fmt.Sprintf("req %d", blockIndex))), uint16(i))
+		updates[i] = state.NewStateUpdate(&reqid)
+		updates[i].Mutations().Add(buffered.NewMutationSet(testVar(blockIndex, i), []byte{byte(i)}))
+	}
+	b, err := state.NewBlock(updates)
+	require.NoError(t, err)
+	return b.WithBlockIndex(blockIndex).WithStateTransaction(txid)
+}
+
+func testVar(blockIndex uint32, i int) kv.Key {
+	return kv.Key(fmt.Sprintf("var %d.%d", blockIndex, i))
+}
+
+// anchorTransaction returns the anchor transaction of the state with the index and the hash
+func anchorTransaction(t *testing.T, blockIndex uint32, stateHash hashing.HashValue) *sctransaction.Transaction {
+	vtx := valuetransaction.New(
+		valuetransaction.NewInputs(),
+		valuetransaction.NewOutputs(map[address.Address][]*balance.Balance{}),
+	)
+	tx, err := sctransaction.NewTransaction(vtx, sctransaction.NewStateSection(sctransaction.NewStateSectionParams{
+		BlockIndex: blockIndex,
+		StateHash:  stateHash,
+	}), nil)
+	require.NoError(t, err)
+	return tx
+}
+
+// stateHashAfter is the hash of the state after the blocks are applied to the solid state
+func stateHashAfter(t *testing.T, sm *stateManager, blocks ...state.Block) hashing.HashValue {
+	vs := sm.solidState.Clone()
+	for _, b := range blocks {
+		require.NoError(t, vs.ApplyBlock(b))
+	}
+	return vs.Hash()
+}
+
+// sendBlock delivers the block from the peer as the header and state updates, in the order of indices
+func sendBlock(sm *stateManager, b state.Block, order ...uint16) {
+	updates := make([]state.StateUpdate, 0, b.Size())
+	b.ForEach(func(_ uint16, su state.StateUpdate) bool {
+		updates = append(updates, su)
+		return true
+	})
+	sm.eventBlockHeaderMsg(&chain.BlockHeaderMsg{
+		PeerMsgHeader:       chain.PeerMsgHeader{BlockIndex: b.StateIndex(), SenderIndex: 1},
+		Size:                b.Size(),
+		AnchorTransactionID: b.StateTransactionID(),
+	})
+	if len(order) == 0 {
+		for i := range updates {
+			order = append(order, uint16(i))
+		}
+	}
+	for _, i := range order {
+		sm.eventStateUpdateMsg(&chain.StateUpdateMsg{
+			PeerMsgHeader:   chain.PeerMsgHeader{BlockIndex: b.StateIndex(), SenderIndex: 1},
+			StateUpdate:     updates[i],
+			IndexInTheBlock: i,
+		})
+	}
+}
+
+// threeBlocks returns blocks #6, #7, #8 after the solid state #5 and the anchor transaction of the last one
+func threeBlocks(t *testing.T, sm *stateManager) ([]state.Block, *sctransaction.Transaction) {
+	var other valuetransaction.ID
+	blocks := []state.Block{testBlock(t, 6, other), testBlock(t, 7, other), testBlock(t, 8, other)}
+	tx := anchorTransaction(t, 8, stateHashAfter(t, sm, blocks...))
+	blocks[2] = blocks[2].WithStateTransaction(tx.ID())
+	return blocks, tx
+}
+
+func TestCatchUpMultipleBlocks(t *testing.T) {
+	sm := newTestStateManager(t, 5)
+	blocks, tx := threeBlocks(t, sm)
+	expectedHash := stateHashAfter(t, sm, blocks...)
+
+	for _, b := range blocks {
+		sendBlock(sm, b)
+	}
+	require.Len(t, sm.syncedBlocks, 3)
+	require.Empty(t, sm.syncedBatches)
+	require.EqualValues(t, 8, sm.lastSyncedBlock().StateIndex())
+
+	sm.eventStateTransactionMsg(&chain.StateTransactionMsg{Transaction: tx})
+
+	require.EqualValues(t, 8, sm.solidState.BlockIndex())
+	require.EqualValues(t, expectedHash, sm.solidState.Hash())
+	require.Equal(t, tx, sm.approvingTransaction)
+	require.Nil(t, sm.catchUpTransaction)
+	require.Empty(t, sm.syncedBlocks)
+	for _, b := range blocks {
+		require.Equal(t, []byte{1}, sm.solidState.Variables().MustGet(testVar(b.StateIndex(), 1)))
+	}
+}
+
+func TestCatchUpHashMismatch(t *testing.T) {
+	sm := newTestStateManager(t, 5)
+	blocks, _ := threeBlocks(t, sm)
+	// the anchor of the state which isn't the result of the synced blocks
+	tx := anchorTransaction(t, 8, hashing.HashStrings("another state"))
+	blocks[2] = blocks[2].WithStateTransaction(tx.ID())
+	solidHash := sm.solidState.Hash()
+
+	for _, b := range blocks {
+		sendBlock(sm, b)
+	}
+	require.Len(t, sm.syncedBlocks, 3)
+
+	sm.eventStateTransactionMsg(&chain.StateTransactionMsg{Transaction: tx})
+
+	// blocks are discarded, to be requested again from other peers
+	require.EqualValues(t, 5, sm.solidState.BlockIndex())
+	require.EqualValues(t, solidHash, sm.solidState.Hash())
+	require.Empty(t, sm.syncedBlocks)
+	require.Empty(t, sm.syncedBatches)
+	require.Nil(t, sm.approvingTransaction)
+}
+
+func TestCatchUpOutOfOrder(t *testing.T) {
+	sm := newTestStateManager(t, 5)
+	blocks, tx := threeBlocks(t, sm)
+	expectedHash := stateHashAfter(t, sm, blocks...)
+
+	// blocks and their state updates arrive in the reverse order
+	sendBlock(sm, blocks[2], 1, 0)
+	sendBlock(sm, blocks[1], 1, 0)
+	require.Len(t, sm.syncedBlocks, 2)
+	require.Nil(t, sm.lastSyncedBlock())
+
+	// the anchor of the last block arrives before the first block: nothing to validate yet
+	sm.eventStateTransactionMsg(&chain.StateTransactionMsg{Transaction: tx})
+	require.EqualValues(t, 5, sm.solidState.BlockIndex())
+	require.Len(t, sm.syncedBlocks, 2)
+
+	sendBlock(sm, blocks[0], 1, 0)
+	require.EqualValues(t, 8, sm.lastSyncedBlock().StateIndex())
+
+	sm.eventStateTransactionMsg(&chain.StateTransactionMsg{Transaction: tx})
+	require.EqualValues(t, 8, sm.solidState.BlockIndex())
+	require.EqualValues(t, expectedHash, sm.solidState.Hash())
+}
+
+func TestCatchUpIgnoresUnexpectedBlocks(t *testing.T) {
+	sm := newTestStateManager(t, 5)
+	var txid valuetransaction.ID
+
+	// the solid block and blocks too far ahead are not accepted
+	sendBlock(sm, testBlock(t, 5, txid))
+	sendBlock(sm, testBlock(t, 5+chain.MaxSyncBlocksAhead+1, txid))
+	require.Empty(t, sm.syncedBlocks)
+	require.Empty(t, sm.syncedBatches)
+
+	sendBlock(sm, testBlock(t, 5+chain.MaxSyncBlocksAhead, txid))
+	require.Len(t, sm.syncedBlocks, 1)
+}
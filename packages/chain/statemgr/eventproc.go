@@ -15,7 +15,10 @@ func (sm *stateManager) EventStateIndexPingPongMsg(msg *chain.StateIndexPingPong
 }
 func (sm *stateManager) eventStateIndexPingPongMsg(msg *chain.StateIndexPingPongMsg) {
 	before := sm.numPongsHasQuorum()
-	sm.pingPongReceived(msg.SenderIndex)
+	if msg.SenderIndex < uint16(len(sm.pingPong)) {
+		// pings of access nodes are answered, but they are not the evidence of the committee
+		sm.pingPongReceived(msg.SenderIndex)
+	}
 	after := sm.numPongsHasQuorum()
 
 	if msg.RSVP && sm.solidStateValid {
@@ -85,13 +88,14 @@ func (sm *stateManager) eventBlockHeaderMsg(msg *chain.BlockHeaderMsg) {
 		"size", msg.Size,
 		"state tx", msg.AnchorTransactionID.String(),
 	)
-	if sm.syncedBatch != nil &&
-		sm.syncedBatch.stateIndex == msg.BlockIndex &&
-		sm.syncedBatch.stateTxId == msg.AnchorTransactionID &&
-		len(sm.syncedBatch.stateUpdates) == int(msg.Size) {
+	if !sm.isSyncedBlockExpected(msg.BlockIndex) {
+		return
+	}
+	sb, ok := sm.syncedBatches[msg.BlockIndex]
+	if ok && sb.stateTxId == msg.AnchorTransactionID && len(sb.stateUpdates) == int(msg.Size) {
 		return // no need to start from scratch
 	}
-	sm.syncedBatch = &syncedBatch{
+	sm.syncedBatches[msg.BlockIndex] = &syncedBatch{
 		stateIndex:   msg.BlockIndex,
 		stateUpdates: make([]state.StateUpdate, msg.Size),
 		stateTxId:    msg.AnchorTransactionID,
@@ -109,13 +113,11 @@ func (sm *stateManager) eventStateUpdateMsg(msg *chain.StateUpdateMsg) {
 		"state index", msg.BlockIndex,
 		"block index", msg.IndexInTheBlock,
 	)
-	if sm.syncedBatch == nil {
-		return
-	}
-	if sm.syncedBatch.stateIndex != msg.BlockIndex {
+	sb, ok := sm.syncedBatches[msg.BlockIndex]
+	if !ok {
 		return
 	}
-	if int(msg.IndexInTheBlock) >= len(sm.syncedBatch.stateUpdates) {
+	if int(msg.IndexInTheBlock) >= len(sb.stateUpdates) {
 		sm.log.Errorf("bad block index in the state update message")
 		return
 	}
@@ -123,35 +125,38 @@ func (sm *stateManager) eventStateUpdateMsg(msg *chain.StateUpdateMsg) {
 	sm.log.Debugf("EventStateUpdateMsg: receiving stateUpdate block index: %d hash: %s",
 		msg.IndexInTheBlock, sh.String())
 
-	sm.syncedBatch.stateUpdates[msg.IndexInTheBlock] = msg.StateUpdate
-	sm.syncedBatch.msgCounter++
+	sb.stateUpdates[msg.IndexInTheBlock] = msg.StateUpdate
+	sb.msgCounter++
 
-	if int(sm.syncedBatch.msgCounter) < len(sm.syncedBatch.stateUpdates) {
+	if int(sb.msgCounter) < len(sb.stateUpdates) {
 		// some are missing
 		return
 	}
 	// check if whole block already received
-	for _, su := range sm.syncedBatch.stateUpdates {
+	for _, su := range sb.stateUpdates {
 		if su == nil {
 			// some state updates are missing
 			return
 		}
 	}
 	// the whole block received
-	batch, err := state.NewBlock(sm.syncedBatch.stateUpdates)
+	delete(sm.syncedBatches, msg.BlockIndex)
+	batch, err := state.NewBlock(sb.stateUpdates)
 	if err != nil {
 		sm.log.Errorf("failed to create block: %v", err)
-		sm.syncedBatch = nil
 		return
 	}
-	batch.WithBlockIndex(sm.syncedBatch.stateIndex).WithStateTransaction(sm.syncedBatch.stateTxId)
+	batch.WithBlockIndex(sb.stateIndex).WithStateTransaction(sb.stateTxId)
 
 	sm.log.Debugf("EventStateUpdateMsg: reconstructed block %s", batch.String())
 
-	sm.syncedBatch = nil
-	go sm.chain.ReceiveMessage(chain.PendingBlockMsg{
-		Block: batch,
-	})
+	sm.syncedBlocks[batch.StateIndex()] = batch
+	if batch.StateIndex() == sm.solidState.BlockIndex()+1 {
+		// the next block is also approved the usual way, by its own anchor transaction
+		go sm.chain.ReceiveMessage(chain.PendingBlockMsg{
+			Block: batch,
+		})
+	}
 	sm.takeAction()
 }
 
@@ -183,6 +188,14 @@ func (sm *stateManager) eventStateTransactionMsg(msg *chain.StateTransactionMsg)
 
 	sm.evidenceStateIndex(stateBlock.BlockIndex())
 
+	if sm.solidStateValid && stateBlock.BlockIndex() > sm.solidState.BlockIndex()+1 {
+		// may be the anchor of the blocks received while catching up
+		if b, ok := sm.syncedBlocks[stateBlock.BlockIndex()]; ok && b.StateTransactionID() == msg.ID() {
+			sm.catchUpTransaction = msg.Transaction
+			sm.takeAction()
+		}
+		return
+	}
 	if sm.solidStateValid {
 		if stateBlock.BlockIndex() != sm.solidState.BlockIndex()+1 {
 			sm.log.Debugf("skip state transaction: expected with state index #%d, got #%d, Txid: %s",
@@ -56,10 +56,19 @@ type stateManager struct {
 	// the timeout deadline for sync inquiries
 	syncMessageDeadline time.Time
 
-	// current block being synced
-	syncedBatch *syncedBatch
+	// blocks being received from peers while syncing, by block index
+	syncedBatches map[uint32]*syncedBatch
 
-	// for the pseudo-random sequence of peers
+	// blocks received from peers ahead of the solid state, not validated yet, by block index
+	syncedBlocks map[uint32]state.Block
+
+	// anchor transaction of the last block in the contiguous sequence of syncedBlocks.
+	// It validates all blocks of the sequence at once
+	catchUpTransaction         *sctransaction.Transaction
+	catchUpTransactionDeadline time.Time
+
+	// for the pseudo-random sequence of committee peers. Blocks are fetched only from committee nodes,
+	// also by access nodes of the chain
 	permutation *util.Permutation16
 
 	// logger
@@ -98,7 +107,9 @@ func New(c chain.Chain, log *logger.Logger) chain.StateManager {
 		chain:                        c,
 		pingPong:                     make([]bool, c.Size()),
 		pendingBlocks:                make(map[hashing.HashValue]*pendingBlock),
-		permutation:                  util.NewPermutation16(c.NumPeers(), nil),
+		syncedBatches:                make(map[uint32]*syncedBatch),
+		syncedBlocks:                 make(map[uint32]state.Block),
+		permutation:                  util.NewPermutation16(c.Size(), nil),
 		log:                          log.Named("s"),
 		evidenceStateIndexCh:         make(chan uint32),
 		eventStateIndexPingPongMsgCh: make(chan *chain.StateIndexPingPongMsg),
@@ -0,0 +1,82 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package transcript
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"sync"
+)
+
+// Recorder writes the transcript into the file. Each record is written as soon as it is recorded,
+// so the transcript of the killed node is complete up to the moment of the kill.
+// All methods are no-op on the nil recorder
+type Recorder struct {
+	mutex sync.Mutex
+	file  *os.File
+	err   error
+}
+
+// NewRecorder creates the file of the transcript and writes the header into it
+func NewRecorder(fname string, header *Header) (*Recorder, error) {
+	// the transcript contains the private key share of the node
+	if err := os.MkdirAll(path.Dir(fname), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(fname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := header.Write(&buf); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &Recorder{file: f}, nil
+}
+
+// Record writes the record into the transcript. After the first error recording stops, see Err
+func (r *Recorder) Record(rec *Record) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil || r.err != nil {
+		return
+	}
+	_, r.err = r.file.Write(rec.Bytes())
+}
+
+// Err returns the error which stopped the recording, if any
+func (r *Recorder) Err() error {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+// Close closes the file of the transcript. Records after close are ignored
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
@@ -0,0 +1,328 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package transcript
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// The file contains the data of the records of local inputs of the consensus operator.
+// The data of peer messages is the message itself
+
+// stateHeader is implemented by the virtual state: index, timestamp and hash of the state
+type stateHeader interface {
+	Write(w io.Writer) error
+	Read(r io.Reader) error
+}
+
+// RequestData is the data of the KindRequest record. Completed is true if the request was
+// already completed in the state of the node when the request message was received
+func RequestData(msg *chain.RequestMsg, completed bool) []byte {
+	var buf bytes.Buffer
+	_ = util.WriteBytes32(&buf, msg.Transaction.Bytes())
+	_ = util.WriteUint16(&buf, msg.Index)
+	_ = cbalances.WriteColoredBalances(&buf, msg.FreeTokens)
+	_ = util.WriteBoolByte(&buf, completed)
+	return buf.Bytes()
+}
+
+func ParseRequest(data []byte) (*chain.RequestMsg, bool, error) {
+	r := bytes.NewReader(data)
+	tx, err := readTransaction(r)
+	if err != nil {
+		return nil, false, err
+	}
+	ret := &chain.RequestMsg{Transaction: tx}
+	if err := util.ReadUint16(r, &ret.Index); err != nil {
+		return nil, false, err
+	}
+	if ret.FreeTokens, err = cbalances.ReadColoredBalance(r); err != nil {
+		return nil, false, err
+	}
+	if ret.FreeTokens.Len() == 0 {
+		ret.FreeTokens = nil
+	}
+	var completed bool
+	if err := util.ReadBoolByte(r, &completed); err != nil {
+		return nil, false, err
+	}
+	return ret, completed, nil
+}
+
+// BalancesData is the data of the KindBalances record
+func BalancesData(msg chain.BalancesMsg) []byte {
+	var buf bytes.Buffer
+	_ = util.WriteUint16(&buf, uint16(len(msg.Balances)))
+	for txid, bals := range msg.Balances {
+		_, _ = buf.Write(txid[:])
+		_ = util.WriteUint16(&buf, uint16(len(bals)))
+		for _, bal := range bals {
+			_, _ = buf.Write(bal.Color[:])
+			_ = util.WriteInt64(&buf, bal.Value)
+		}
+	}
+	return buf.Bytes()
+}
+
+func ParseBalances(data []byte) (chain.BalancesMsg, error) {
+	r := bytes.NewReader(data)
+	var numTx uint16
+	if err := util.ReadUint16(r, &numTx); err != nil {
+		return chain.BalancesMsg{}, err
+	}
+	ret := chain.BalancesMsg{Balances: make(map[valuetransaction.ID][]*balance.Balance)}
+	for i := uint16(0); i < numTx; i++ {
+		var txid valuetransaction.ID
+		if err := util.ReadTransactionId(r, &txid); err != nil {
+			return chain.BalancesMsg{}, err
+		}
+		var numBals uint16
+		if err := util.ReadUint16(r, &numBals); err != nil {
+			return chain.BalancesMsg{}, err
+		}
+		bals := make([]*balance.Balance, numBals)
+		for j := range bals {
+			var col balance.Color
+			var value int64
+			if err := util.ReadColor(r, &col); err != nil {
+				return chain.BalancesMsg{}, err
+			}
+			if err := util.ReadInt64(r, &value); err != nil {
+				return chain.BalancesMsg{}, err
+			}
+			bals[j] = balance.New(col, value)
+		}
+		ret.Balances[txid] = bals
+	}
+	return ret, nil
+}
+
+// InclusionLevelData is the data of the KindInclusionLevel record
+func InclusionLevelData(msg *chain.TransactionInclusionLevelMsg) []byte {
+	var buf bytes.Buffer
+	_, _ = buf.Write(msg.TxId[:])
+	_ = util.WriteByte(&buf, msg.Level)
+	return buf.Bytes()
+}
+
+func ParseInclusionLevel(data []byte) (*chain.TransactionInclusionLevelMsg, error) {
+	r := bytes.NewReader(data)
+	var txid valuetransaction.ID
+	if err := util.ReadTransactionId(r, &txid); err != nil {
+		return nil, err
+	}
+	level, err := util.ReadByte(r)
+	if err != nil {
+		return nil, err
+	}
+	return &chain.TransactionInclusionLevelMsg{TxId: &txid, Level: level}, nil
+}
+
+// StateTransitionData is the data of the KindStateTransition record: the header and all variables of
+// the new state, the anchor transaction and the requests completed in the state among the requests
+// known from the transcript
+func StateTransitionData(msg *chain.StateTransitionMsg, completed []coretypes.RequestID) ([]byte, error) {
+	header, ok := msg.VariableState.(stateHeader)
+	if !ok {
+		return nil, fmt.Errorf("StateTransitionData: unsupported virtual state %T", msg.VariableState)
+	}
+	var buf bytes.Buffer
+	if err := header.Write(&buf); err != nil {
+		return nil, err
+	}
+	if err := msg.VariableState.Variables().DangerouslyDumpToDict().Write(&buf); err != nil {
+		return nil, err
+	}
+	var txBytes []byte
+	if msg.AnchorTransaction != nil {
+		txBytes = msg.AnchorTransaction.Bytes()
+	}
+	if err := util.WriteBytes32(&buf, txBytes); err != nil {
+		return nil, err
+	}
+	if err := writeRequestIDs(&buf, msg.RequestIDs); err != nil {
+		return nil, err
+	}
+	if err := util.WriteBoolByte(&buf, msg.Synchronized); err != nil {
+		return nil, err
+	}
+	completedIDs := make([]*coretypes.RequestID, len(completed))
+	for i := range completed {
+		completedIDs[i] = &completed[i]
+	}
+	if err := writeRequestIDs(&buf, completedIDs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseStateTransition restores the state transition with the virtual state in the new in-memory database
+func ParseStateTransition(data []byte, chainID *coretypes.ChainID) (*chain.StateTransitionMsg, []coretypes.RequestID, error) {
+	r := bytes.NewReader(data)
+	db := mapdb.NewMapDB()
+	vs := state.NewVirtualState(db, chainID)
+	if err := vs.Read(r); err != nil {
+		return nil, nil, err
+	}
+	variables := dict.New()
+	if err := variables.Read(r); err != nil {
+		return nil, nil, err
+	}
+	// the same realm as the one of the variables of the virtual state
+	vars := db.WithRealm(append(db.Realm(), dbprovider.ObjectTypeStateVariable))
+	var err error
+	variables.ForEach(func(key kv.Key, value []byte) bool {
+		err = vars.Set([]byte(key), value)
+		return err == nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	ret := &chain.StateTransitionMsg{VariableState: vs}
+	txBytes, err := readBytes32(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(txBytes) > 0 {
+		if ret.AnchorTransaction, err = parseTransaction(txBytes); err != nil {
+			return nil, nil, err
+		}
+	}
+	if ret.RequestIDs, err = readRequestIDs(r); err != nil {
+		return nil, nil, err
+	}
+	if err := util.ReadBoolByte(r, &ret.Synchronized); err != nil {
+		return nil, nil, err
+	}
+	completedIDs, err := readRequestIDs(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	completed := make([]coretypes.RequestID, len(completedIDs))
+	for i := range completedIDs {
+		completed[i] = *completedIDs[i]
+	}
+	return ret, completed, nil
+}
+
+// TimerTickData is the data of the KindTimerTick record
+func TimerTickData(tick chain.TimerTick) []byte {
+	return util.Uint32To4Bytes(uint32(tick))
+}
+
+func ParseTimerTick(data []byte) (chain.TimerTick, error) {
+	tick, err := util.Uint32From4Bytes(data)
+	if err != nil {
+		return 0, err
+	}
+	return chain.TimerTick(tick), nil
+}
+
+// VMResultData is the data of the KindVMResult record: the leader of the batch, the duration of
+// the VM execution and the hash of the essence of the result transaction
+func VMResultData(msg *chain.VMResultMsg) []byte {
+	var buf bytes.Buffer
+	_ = util.WriteUint16(&buf, msg.Leader)
+	_ = util.WriteInt64(&buf, int64(msg.Duration))
+	essenceHash := hashing.HashData(msg.Task.ResultTransaction.EssenceBytes())
+	_, _ = buf.Write(essenceHash[:])
+	return buf.Bytes()
+}
+
+func ParseVMResult(data []byte) (uint16, time.Duration, hashing.HashValue, error) {
+	r := bytes.NewReader(data)
+	var leader uint16
+	var duration int64
+	var essenceHash hashing.HashValue
+	if err := util.ReadUint16(r, &leader); err != nil {
+		return 0, 0, essenceHash, err
+	}
+	if err := util.ReadInt64(r, &duration); err != nil {
+		return 0, 0, essenceHash, err
+	}
+	if err := util.ReadHashValue(r, &essenceHash); err != nil {
+		return 0, 0, essenceHash, err
+	}
+	return leader, time.Duration(duration), essenceHash, nil
+}
+
+// EmergencyData is the data of the KindEmergency record
+func EmergencyData(msg *chain.EmergencyMsg) []byte {
+	var buf bytes.Buffer
+	_ = util.WriteUint16(&buf, msg.Delegate)
+	_ = util.WriteUint32(&buf, msg.NumBlocks)
+	return buf.Bytes()
+}
+
+func ParseEmergency(data []byte) (*chain.EmergencyMsg, error) {
+	r := bytes.NewReader(data)
+	ret := &chain.EmergencyMsg{}
+	if err := util.ReadUint16(r, &ret.Delegate); err != nil {
+		return nil, err
+	}
+	if err := util.ReadUint32(r, &ret.NumBlocks); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func readTransaction(r io.Reader) (*sctransaction.Transaction, error) {
+	data, err := readBytes32(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseTransaction(data)
+}
+
+func parseTransaction(data []byte) (*sctransaction.Transaction, error) {
+	vtx, _, err := valuetransaction.FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return sctransaction.ParseValueTransaction(vtx)
+}
+
+func writeRequestIDs(w io.Writer, reqids []*coretypes.RequestID) error {
+	if err := util.WriteUint16(w, uint16(len(reqids))); err != nil {
+		return err
+	}
+	for _, reqid := range reqids {
+		if err := reqid.Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRequestIDs(r io.Reader) ([]*coretypes.RequestID, error) {
+	var n uint16
+	if err := util.ReadUint16(r, &n); err != nil {
+		return nil, err
+	}
+	ret := make([]*coretypes.RequestID, n)
+	for i := range ret {
+		ret[i] = new(coretypes.RequestID)
+		if err := ret[i].Read(r); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
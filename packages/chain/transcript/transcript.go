@@ -0,0 +1,372 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// Package transcript contains the transcript of the committee messages of one node of the chain:
+// messages received from and sent to peers, together with local inputs of the consensus operator
+// (requests, balances, state transitions, timer ticks and VM results), each with the local time of the node.
+// The transcript is recorded by the node when DirEnvVar is set and is replayed
+// into the consensus operator with consensus.Replay.
+//
+// The transcript contains the private key share of the node. It is meant only for test clusters, so the
+// recording is not configurable by the node config: DirEnvVar is set only by tools/cluster
+package transcript
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// record kinds
+const (
+	// KindPeerIn is the message received from the committee peer
+	KindPeerIn = byte(iota + 1)
+	// KindPeerOut is the message sent to the committee peer
+	KindPeerOut
+	// KindBroadcast is the message sent to all committee peers
+	KindBroadcast
+	// KindRequest is the request message from the IOTA node
+	KindRequest
+	// KindBalances is the balances of the chain address from the IOTA node
+	KindBalances
+	// KindInclusionLevel is the inclusion level of the transaction from the IOTA node
+	KindInclusionLevel
+	// KindStateTransition is the new solid state from the state manager
+	KindStateTransition
+	// KindTimerTick is the timer tick of the consensus operator
+	KindTimerTick
+	// KindVMResult marks the moment the result of the VM was passed to the consensus operator
+	KindVMResult
+	// KindBlob is the blob used by the consensus operator to solidify arguments of requests
+	KindBlob
+	// KindEmergency is the emergency declared by the owner of the node
+	KindEmergency
+)
+
+var kindNames = map[byte]string{
+	KindPeerIn:          "peer-in",
+	KindPeerOut:         "peer-out",
+	KindBroadcast:       "broadcast",
+	KindRequest:         "request",
+	KindBalances:        "balances",
+	KindInclusionLevel:  "inclusion-level",
+	KindStateTransition: "state-transition",
+	KindTimerTick:       "timer-tick",
+	KindVMResult:        "vm-result",
+	KindBlob:            "blob",
+	KindEmergency:       "emergency",
+}
+
+// DirEnvVar is the environment variable with the directory the node records transcripts into.
+// The relative directory is resolved against the working directory of the node
+const DirEnvVar = "WASP_TRANSCRIPT_DIR"
+
+const (
+	transcriptMagic   = "WTRS"
+	transcriptVersion = byte(4)
+)
+
+// Header contains the parameters of the node needed to replay the transcript
+type Header struct {
+	ChainID  coretypes.ChainID
+	Color    balance.Color
+	OwnIndex uint16
+	Size     uint16
+	Quorum   uint16
+	// DKShare is the serialized key share of the node
+	DKShare []byte
+	// consensus parameters of the node
+	BatchTimeBudget time.Duration
+	MaxBatchSize    uint16
+	FairOrdering    bool
+	EmergencyQuorum uint16
+	Pipelining      bool
+	MaxRequestAge   uint32
+}
+
+// Record is one entry of the transcript
+type Record struct {
+	Kind byte
+	// Timestamp is the local time of the node in nanoseconds
+	Timestamp int64
+	// Peer is the sender of the incoming or the target of the outgoing message
+	Peer    uint16
+	MsgType byte
+	// MsgTimestamp is the timestamp of the peer message
+	MsgTimestamp int64
+	Data         []byte
+	// Alive is the bitmap of committee peers alive at the time of the record and HasQuorum is
+	// the quorum of connections. Both are set only for inputs of the consensus operator
+	Alive     []byte
+	HasQuorum bool
+}
+
+// Transcript is the loaded transcript of the node
+type Transcript struct {
+	Header  *Header
+	Records []*Record
+}
+
+// FileName is the name of the transcript file of the node in the directory. Each run of the node,
+// i.e. each activation of the chain, is recorded into a separate file, starting from run 0
+func FileName(dir string, chainID *coretypes.ChainID, ownIndex uint16, run int) string {
+	if run == 0 {
+		return path.Join(dir, fmt.Sprintf("%s.%d.transcript", chainID.String(), ownIndex))
+	}
+	return path.Join(dir, fmt.Sprintf("%s.%d.%d.transcript", chainID.String(), ownIndex, run))
+}
+
+// NewFileName is the name of the file for the next run of the node
+func NewFileName(dir string, chainID *coretypes.ChainID, ownIndex uint16) string {
+	for run := 0; ; run++ {
+		fname := FileName(dir, chainID, ownIndex, run)
+		if _, err := os.Stat(fname); os.IsNotExist(err) {
+			return fname
+		}
+	}
+}
+
+// Load loads the transcript from the file
+func Load(fname string) (*Transcript, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	return Read(bytes.NewReader(data))
+}
+
+// Read reads the transcript. The last record, truncated because the node was killed
+// while writing it, is ignored
+func Read(r io.Reader) (*Transcript, error) {
+	ret := &Transcript{
+		Header:  new(Header),
+		Records: make([]*Record, 0),
+	}
+	if err := ret.Header.Read(r); err != nil {
+		return nil, fmt.Errorf("wrong transcript header: %v", err)
+	}
+	for {
+		rec := new(Record)
+		err := rec.Read(r)
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ret, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("wrong transcript record #%d: %v", len(ret.Records), err)
+		}
+		ret.Records = append(ret.Records, rec)
+	}
+}
+
+func (h *Header) Write(w io.Writer) error {
+	if _, err := w.Write([]byte(transcriptMagic)); err != nil {
+		return err
+	}
+	if err := util.WriteByte(w, transcriptVersion); err != nil {
+		return err
+	}
+	if err := h.ChainID.Write(w); err != nil {
+		return err
+	}
+	if _, err := w.Write(h.Color[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, h.OwnIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, h.Size); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, h.Quorum); err != nil {
+		return err
+	}
+	if err := util.WriteBytes32(w, h.DKShare); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, int64(h.BatchTimeBudget)); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, h.MaxBatchSize); err != nil {
+		return err
+	}
+	if err := util.WriteBoolByte(w, h.FairOrdering); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, h.EmergencyQuorum); err != nil {
+		return err
+	}
+	if err := util.WriteBoolByte(w, h.Pipelining); err != nil {
+		return err
+	}
+	return util.WriteUint32(w, h.MaxRequestAge)
+}
+
+func (h *Header) Read(r io.Reader) error {
+	var magic [len(transcriptMagic)]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return err
+	}
+	if string(magic[:]) != transcriptMagic {
+		return fmt.Errorf("not a transcript")
+	}
+	version, err := util.ReadByte(r)
+	if err != nil {
+		return err
+	}
+	if version != transcriptVersion {
+		return fmt.Errorf("unsupported transcript version %d", version)
+	}
+	if err := h.ChainID.Read(r); err != nil {
+		return err
+	}
+	if err := util.ReadColor(r, &h.Color); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &h.OwnIndex); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &h.Size); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &h.Quorum); err != nil {
+		return err
+	}
+	if h.DKShare, err = readBytes32(r); err != nil {
+		return err
+	}
+	var budget int64
+	if err := util.ReadInt64(r, &budget); err != nil {
+		return err
+	}
+	h.BatchTimeBudget = time.Duration(budget)
+	if err := util.ReadUint16(r, &h.MaxBatchSize); err != nil {
+		return err
+	}
+	if err := util.ReadBoolByte(r, &h.FairOrdering); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &h.EmergencyQuorum); err != nil {
+		return err
+	}
+	if err := util.ReadBoolByte(r, &h.Pipelining); err != nil {
+		return err
+	}
+	return util.ReadUint32(r, &h.MaxRequestAge)
+}
+
+func (rec *Record) Write(w io.Writer) error {
+	if err := util.WriteByte(w, rec.Kind); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, rec.Timestamp); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, rec.Peer); err != nil {
+		return err
+	}
+	if err := util.WriteByte(w, rec.MsgType); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, rec.MsgTimestamp); err != nil {
+		return err
+	}
+	if err := util.WriteBytes32(w, rec.Data); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, rec.Alive); err != nil {
+		return err
+	}
+	return util.WriteBoolByte(w, rec.HasQuorum)
+}
+
+func (rec *Record) Read(r io.Reader) error {
+	var err error
+	if rec.Kind, err = util.ReadByte(r); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &rec.Timestamp); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &rec.Peer); err != nil {
+		return err
+	}
+	if rec.MsgType, err = util.ReadByte(r); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &rec.MsgTimestamp); err != nil {
+		return err
+	}
+	if rec.Data, err = readBytes32(r); err != nil {
+		return err
+	}
+	if rec.Alive, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	return util.ReadBoolByte(r, &rec.HasQuorum)
+}
+
+// readBytes32 reads the data written by util.WriteBytes32. Unlike util.ReadBytes32, it fails
+// with io.ErrUnexpectedEOF when the data is truncated
+func readBytes32(r io.Reader) ([]byte, error) {
+	var length uint32
+	if err := util.ReadUint32(r, &length); err != nil {
+		return nil, err
+	}
+	ret := make([]byte, length)
+	if _, err := io.ReadFull(r, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bytes is the serialized record
+func (rec *Record) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = rec.Write(&buf)
+	return buf.Bytes()
+}
+
+// Time is the local time of the node of the record
+func (rec *Record) Time() time.Time {
+	return time.Unix(0, rec.Timestamp)
+}
+
+// IsAlive returns true if the peer was alive at the time of the record
+func (rec *Record) IsAlive(peerIndex uint16) bool {
+	if int(peerIndex/8) >= len(rec.Alive) {
+		return false
+	}
+	return rec.Alive[peerIndex/8]&(1<<(peerIndex%8)) != 0
+}
+
+// SetAlive fills the bitmap of alive committee peers of the record
+func (rec *Record) SetAlive(size uint16, isAlive func(peerIndex uint16) bool) {
+	rec.Alive = make([]byte, (size+7)/8)
+	for i := uint16(0); i < size; i++ {
+		if isAlive(i) {
+			rec.Alive[i/8] |= 1 << (i % 8)
+		}
+	}
+}
+
+func (rec *Record) String() string {
+	name, ok := kindNames[rec.Kind]
+	if !ok {
+		name = fmt.Sprintf("kind-%d", rec.Kind)
+	}
+	switch rec.Kind {
+	case KindPeerIn, KindPeerOut, KindBroadcast:
+		return fmt.Sprintf("%s %s peer #%d msg type %d, %d bytes",
+			rec.Time().Format("15:04:05.000000"), name, rec.Peer, rec.MsgType, len(rec.Data))
+	}
+	return fmt.Sprintf("%s %s, %d bytes", rec.Time().Format("15:04:05.000000"), name, len(rec.Data))
+}
@@ -0,0 +1,109 @@
+package transcript
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "transcript")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	chainID := coretypes.ChainID{1, 2, 3}
+	header := &Header{
+		ChainID:         chainID,
+		Color:           balance.Color{4, 5},
+		OwnIndex:        1,
+		Size:            4,
+		Quorum:          3,
+		DKShare:         []byte("dkshare"),
+		BatchTimeBudget: time.Second,
+		MaxBatchSize:    100,
+		FairOrdering:    true,
+		EmergencyQuorum: 3,
+		Pipelining:      true,
+		MaxRequestAge:   10,
+	}
+	fname := NewFileName(dir, &chainID, 1)
+	require.EqualValues(t, FileName(dir, &chainID, 1, 0), fname)
+	rec, err := NewRecorder(fname, header)
+	require.NoError(t, err)
+	// the transcript contains the private key share, it is readable only by the owner
+	fi, err := os.Stat(fname)
+	require.NoError(t, err)
+	require.EqualValues(t, 0600, fi.Mode().Perm())
+	// the next run of the node is recorded into the new file
+	require.EqualValues(t, FileName(dir, &chainID, 1, 1), NewFileName(dir, &chainID, 1))
+
+	in := &Record{
+		Kind:         KindPeerIn,
+		Timestamp:    time.Now().UnixNano(),
+		Peer:         2,
+		MsgType:      chain.MsgSignedHash,
+		MsgTimestamp: 42,
+		Data:         []byte{1, 2, 3},
+		HasQuorum:    true,
+	}
+	in.SetAlive(header.Size, func(i uint16) bool { return i != 3 })
+	rec.Record(in)
+	rec.Record(&Record{Kind: KindTimerTick, Timestamp: in.Timestamp + 1, Data: TimerTickData(17)})
+	require.NoError(t, rec.Close())
+	// records after close are ignored
+	rec.Record(&Record{Kind: KindTimerTick})
+	require.NoError(t, rec.Err())
+
+	tr, err := Load(fname)
+	require.NoError(t, err)
+	require.EqualValues(t, header, tr.Header)
+	require.Len(t, tr.Records, 2)
+	require.EqualValues(t, in, tr.Records[0])
+	require.True(t, tr.Records[0].IsAlive(2))
+	require.False(t, tr.Records[0].IsAlive(3))
+	require.False(t, tr.Records[0].IsAlive(100))
+	tick, err := ParseTimerTick(tr.Records[1].Data)
+	require.NoError(t, err)
+	require.EqualValues(t, 17, tick)
+
+	// the record truncated by the kill of the node is ignored
+	data, err := ioutil.ReadFile(fname)
+	require.NoError(t, err)
+	truncated := path.Join(dir, "truncated")
+	require.NoError(t, ioutil.WriteFile(truncated, data[:len(data)-3], 0644))
+	tr, err = Load(truncated)
+	require.NoError(t, err)
+	require.Len(t, tr.Records, 1)
+
+	_, err = Load(path.Join(dir, "nonexistent"))
+	require.Error(t, err)
+	require.NoError(t, ioutil.WriteFile(truncated, []byte("not a transcript"), 0644))
+	_, err = Load(truncated)
+	require.Error(t, err)
+}
+
+func TestLocalInputs(t *testing.T) {
+	txid := valuetransaction.ID{7}
+	balances := chain.BalancesMsg{Balances: map[valuetransaction.ID][]*balance.Balance{
+		txid: {balance.New(balance.ColorIOTA, 10), balance.New(balance.Color{1}, 1)},
+	}}
+	bals, err := ParseBalances(BalancesData(balances))
+	require.NoError(t, err)
+	require.EqualValues(t, balances, bals)
+
+	level := &chain.TransactionInclusionLevelMsg{TxId: &txid, Level: 2}
+	lvl, err := ParseInclusionLevel(InclusionLevelData(level))
+	require.NoError(t, err)
+	require.EqualValues(t, level, lvl)
+
+	_, _, _, err = ParseVMResult([]byte{1})
+	require.Error(t, err)
+}
@@ -5,11 +5,14 @@ package coretypes
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"io"
+	"strings"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/util/bech32"
 	"github.com/mr-tron/base58"
 )
 
@@ -25,6 +28,9 @@ const AgentIDLength = ContractIDLength // max(ContractIDLength, address.Length)
 // An attempt to interpret the AgentID in the wrong way invokes panic
 type AgentID [AgentIDLength]byte
 
+// AgentIDHRP is the human-readable prefix of the bech32 encoding of the AgentID
+const AgentIDHRP = "wagent"
+
 // NewAgentIDFromContractID makes AgentID from ContractID
 func NewAgentIDFromContractID(id ContractID) (ret AgentID) {
 	copy(ret[:], id[:])
@@ -101,8 +107,31 @@ func (a AgentID) String() string {
 	return "C/" + cid.String()
 }
 
-// NewAgentIDFromString parses the human-readable string representation
+// Bech32 human readable form with checksum and the AgentIDHRP prefix
+func (a AgentID) Bech32() string {
+	ret, err := bech32.Encode(AgentIDHRP, a[:])
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// NewAgentIDFromBech32 decodes bech32 string with the AgentIDHRP prefix to the AgentID
+func NewAgentIDFromBech32(s string) (ret AgentID, err error) {
+	var b []byte
+	b, err = bech32.DecodeWithHRP(AgentIDHRP, s)
+	if err != nil {
+		return
+	}
+	return NewAgentIDFromBytes(b)
+}
+
+// NewAgentIDFromString parses the human-readable string representation:
+// either 'A/<address>', 'C/<contract id>' or bech32 with the AgentIDHRP prefix
 func NewAgentIDFromString(s string) (ret AgentID, err error) {
+	if strings.HasPrefix(strings.ToLower(s), AgentIDHRP+"1") {
+		return NewAgentIDFromBech32(s)
+	}
 	if len(s) < 2 {
 		err = errors.New("invalid length")
 		return
@@ -143,3 +172,22 @@ func ReadAgentID(r io.Reader, agentID *AgentID) error {
 func (a AgentID) Base58() string {
 	return base58.Encode(a[:])
 }
+
+// MarshalJSON encodes the AgentID as bech32 string
+func (a AgentID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Bech32())
+}
+
+// UnmarshalJSON decodes the AgentID from any of the string representations
+func (a *AgentID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	ret, err := NewAgentIDFromString(s)
+	if err != nil {
+		return err
+	}
+	*a = ret
+	return nil
+}
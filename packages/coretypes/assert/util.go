@@ -29,3 +29,21 @@ func (a Assert) Require(cond bool, format string, args ...interface{}) {
 func (a Assert) RequireNoError(err error) {
 	a.Require(err == nil, fmt.Sprintf("%v", err))
 }
+
+// RequireAuthorized panics with the VMError of the code VMErrorUnauthorized, if the condition is not met
+func (a Assert) RequireAuthorized(cond bool, format string, args ...interface{}) {
+	a.RequireCode(cond, coretypes.VMErrorUnauthorized, format, args...)
+}
+
+// RequireCode panics with the VMError of the code, if the condition is not met. Unlike Require,
+// the panic keeps the code of the error, the message is only logged
+func (a Assert) RequireCode(cond bool, code coretypes.VMErrorCode, format string, args ...interface{}) {
+	if cond {
+		return
+	}
+	err := coretypes.NewVMError(code, format, args...)
+	if a.log != nil {
+		a.log.Infof("%v", err)
+	}
+	panic(err)
+}
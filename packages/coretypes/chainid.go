@@ -5,9 +5,12 @@ package coretypes
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
+	"strings"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/util/bech32"
 	"github.com/mr-tron/base58"
 )
 
@@ -22,6 +25,9 @@ type ChainID address.Address
 
 var NilChainID = ChainID{}
 
+// ChainIDHRP is the human-readable prefix of the bech32 encoding of the ChainID
+const ChainIDHRP = "wchain"
+
 // NewChainIDFromBase58 constructor decodes base58 string to the ChainID
 func NewChainIDFromBase58(b58 string) (ret ChainID, err error) {
 	var b []byte
@@ -37,6 +43,29 @@ func NewChainIDFromBase58(b58 string) (ret ChainID, err error) {
 	return
 }
 
+// NewChainIDFromBech32 decodes bech32 string with the ChainIDHRP prefix to the ChainID
+func NewChainIDFromBech32(s string) (ret ChainID, err error) {
+	var b []byte
+	b, err = bech32.DecodeWithHRP(ChainIDHRP, s)
+	if err != nil {
+		return
+	}
+	if len(b) != ChainIDLength {
+		err = ErrWrongDataLength
+		return
+	}
+	copy(ret[:], b)
+	return
+}
+
+// NewChainIDFromString decodes the ChainID either from the bech32 or from the base58 encoding
+func NewChainIDFromString(s string) (ChainID, error) {
+	if strings.HasPrefix(strings.ToLower(s), ChainIDHRP+"1") {
+		return NewChainIDFromBech32(s)
+	}
+	return NewChainIDFromBase58(s)
+}
+
 // NewChainIDFromBytes reconstructs a ChainID from its binary representation.
 func NewChainIDFromBytes(data []byte) (ret ChainID, err error) {
 	err = ret.Read(bytes.NewReader(data))
@@ -53,6 +82,34 @@ func (chid ChainID) String() string {
 	return address.Address(chid).String()
 }
 
+// Bech32 human readable form with checksum and the ChainIDHRP prefix
+func (chid ChainID) Bech32() string {
+	ret, err := bech32.Encode(ChainIDHRP, chid[:])
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// MarshalJSON encodes the ChainID as bech32 string
+func (chid ChainID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(chid.Bech32())
+}
+
+// UnmarshalJSON decodes the ChainID from bech32 or base58 string
+func (chid *ChainID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	ret, err := NewChainIDFromString(s)
+	if err != nil {
+		return err
+	}
+	*chid = ret
+	return nil
+}
+
 // Write to writer
 func (chid *ChainID) Write(w io.Writer) error {
 	_, err := w.Write(chid[:])
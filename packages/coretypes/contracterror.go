@@ -0,0 +1,105 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Reserved keys of results of the entry point. A contract returns the structured error by
+// setting them in results instead of returning the error. This way also Wasm contracts,
+// which can't return errors, can report the error to the calling contract
+const (
+	// ResultKeyErrorCode is the int64 error code, specific to the contract
+	ResultKeyErrorCode = kv.Key("$err.code")
+	// ResultKeyErrorMessage is the error message string
+	ResultKeyErrorMessage = kv.Key("$err.msg")
+)
+
+// ContractError is the structured error returned by the entry point of the contract.
+// The code identifies the error among errors of the contract, the calling contract
+// can handle the error by its code
+type ContractError struct {
+	// Contract is the hname of the contract which returned the error
+	Contract Hname
+	// EntryPoint is the hname of the entry point which returned the error
+	EntryPoint Hname
+	Code       int64
+	Message    string
+}
+
+// NewContractError creates the structured error to be returned by the entry point.
+// The contract and entry point are filled in by the VM
+func NewContractError(code int64, format string, args ...interface{}) *ContractError {
+	return &ContractError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *ContractError) Error() string {
+	return fmt.Sprintf("contract %s, entry point %s: error %d: %s", e.Contract, e.EntryPoint, e.Code, e.Message)
+}
+
+// Results encodes the error in reserved keys of results
+func (e *ContractError) Results() dict.Dict {
+	ret := dict.New()
+	ret.Set(ResultKeyErrorCode, util.Uint64To8Bytes(uint64(e.Code)))
+	ret.Set(ResultKeyErrorMessage, []byte(e.Message))
+	return ret
+}
+
+// ContractErrorFromResults decodes the structured error from the results of the call. Returns nil if the results
+// don't contain the error
+func ContractErrorFromResults(contract, entryPoint Hname, results dict.Dict) *ContractError {
+	if results == nil {
+		return nil
+	}
+	codeBin, err := results.Get(ResultKeyErrorCode)
+	if err != nil || codeBin == nil {
+		return nil
+	}
+	code, err := util.Uint64From8Bytes(codeBin)
+	if err != nil {
+		return nil
+	}
+	msg, _ := results.Get(ResultKeyErrorMessage)
+	return &ContractError{
+		Contract:   contract,
+		EntryPoint: entryPoint,
+		Code:       int64(code),
+		Message:    string(msg),
+	}
+}
+
+// CheckContractError converts the result of the call of the entry point to the structured error,
+// if the entry point returned one, either in results or as ContractError
+func CheckContractError(contract, entryPoint Hname, results dict.Dict, err error) (dict.Dict, error) {
+	if err != nil {
+		var ce *ContractError
+		if errors.As(err, &ce) && ce.Contract == 0 {
+			ce.Contract = contract
+			ce.EntryPoint = entryPoint
+		}
+		return nil, err
+	}
+	if ce := ContractErrorFromResults(contract, entryPoint, results); ce != nil {
+		return nil, ce
+	}
+	return results, nil
+}
+
+// AsContractError returns the structured error if err is or wraps one
+func AsContractError(err error) (*ContractError, bool) {
+	var ce *ContractError
+	if errors.As(err, &ce) {
+		return ce, true
+	}
+	return nil, false
+}
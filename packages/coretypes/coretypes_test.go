@@ -4,9 +4,13 @@
 package coretypes
 
 import (
+	"encoding/json"
+	"strings"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -98,6 +102,57 @@ func TestAgentID(t *testing.T) {
 
 }
 
+func TestBech32(t *testing.T) {
+	chid := (ChainID)(address.Random())
+	s := chid.Bech32()
+	t.Logf("chid bech32 = %s", s)
+	require.True(t, strings.HasPrefix(s, ChainIDHRP+"1"))
+
+	chidBack, err := NewChainIDFromBech32(s)
+	require.NoError(t, err)
+	require.EqualValues(t, chid, chidBack)
+
+	chidBack, err = NewChainIDFromString(s)
+	require.NoError(t, err)
+	require.EqualValues(t, chid, chidBack)
+
+	chidBack, err = NewChainIDFromString(chid.String())
+	require.NoError(t, err)
+	require.EqualValues(t, chid, chidBack)
+
+	// corrupted checksum
+	_, err = NewChainIDFromBech32(s[:len(s)-1] + string(s[len(s)-1]^1))
+	require.Error(t, err)
+
+	aid := NewAgentIDFromContractID(NewContractID(chid, Hn("22")))
+	s = aid.Bech32()
+	t.Logf("aid bech32 = %s", s)
+	require.True(t, strings.HasPrefix(s, AgentIDHRP+"1"))
+
+	aidBack, err := NewAgentIDFromString(s)
+	require.NoError(t, err)
+	require.EqualValues(t, aid, aidBack)
+
+	// wrong prefix
+	_, err = NewAgentIDFromBech32(chid.Bech32())
+	require.Error(t, err)
+
+	data, err := json.Marshal(struct {
+		ChainID ChainID
+		AgentID AgentID
+	}{chid, aid})
+	require.NoError(t, err)
+	t.Logf("json = %s", string(data))
+
+	var back struct {
+		ChainID ChainID
+		AgentID AgentID
+	}
+	require.NoError(t, json.Unmarshal(data, &back))
+	require.EqualValues(t, chid, back.ChainID)
+	require.EqualValues(t, aid, back.AgentID)
+}
+
 func TestHname(t *testing.T) {
 	hn1 := Hn("first")
 
@@ -118,3 +173,28 @@ func TestHnameCollision(t *testing.T) {
 
 	require.NotEqualValues(t, hn1, hn2)
 }
+
+func TestContractError(t *testing.T) {
+	target, ep := Hn("target"), Hn("func")
+	ce := NewContractError(5, "not enough %s", "funds")
+	results := ce.Results()
+
+	_, err := CheckContractError(target, ep, results, nil)
+	back, ok := AsContractError(err)
+	require.True(t, ok)
+	require.EqualValues(t, 5, back.Code)
+	require.EqualValues(t, "not enough funds", back.Message)
+	require.EqualValues(t, target, back.Contract)
+	require.EqualValues(t, ep, back.EntryPoint)
+
+	// the error returned by the Go contract gets the contract and the entry point
+	_, err = CheckContractError(target, ep, nil, NewContractError(7, "failed"))
+	back, ok = AsContractError(err)
+	require.True(t, ok)
+	require.EqualValues(t, 7, back.Code)
+	require.EqualValues(t, target, back.Contract)
+
+	ret, err := CheckContractError(target, ep, dict.New(), nil)
+	require.NoError(t, err)
+	require.NotNil(t, ret)
+}
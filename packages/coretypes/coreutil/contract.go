@@ -7,7 +7,10 @@ package coreutil
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 )
@@ -26,6 +29,21 @@ type ContractFunctionInterface struct {
 	Name        string
 	Handler     Handler
 	ViewHandler ViewHandler
+	// ParamHints are names of parameters of the entry point, used only by tools
+	ParamHints []string
+	// ParamTypes are declared types of parameters, checked by requestargs.Builder
+	ParamTypes map[string]coretypes.ParamType
+}
+
+// TypedParam is the name and the type of the parameter of the entry point, see WithTypedParams
+type TypedParam struct {
+	Name string
+	Type coretypes.ParamType
+}
+
+// Param declares the parameter of the entry point with its type
+func Param(name string, typ coretypes.ParamType) TypedParam {
+	return TypedParam{Name: name, Type: typ}
 }
 
 // Funcs declares init entry point and a list of full and view entry points
@@ -71,6 +89,23 @@ func ViewFunc(name string, handler ViewHandler) ContractFunctionInterface {
 	}
 }
 
+// WithParams declares names of parameters of the entry point, see ContractInterface.EntryPoints
+func (f ContractFunctionInterface) WithParams(params ...string) ContractFunctionInterface {
+	f.ParamHints = params
+	return f
+}
+
+// WithTypedParams declares names and types of parameters of the entry point
+func (f ContractFunctionInterface) WithTypedParams(params ...TypedParam) ContractFunctionInterface {
+	f.ParamHints = make([]string, len(params))
+	f.ParamTypes = make(map[string]coretypes.ParamType, len(params))
+	for i, p := range params {
+		f.ParamHints[i] = p.Name
+		f.ParamTypes[p.Name] = p.Type
+	}
+	return f
+}
+
 type Handler func(ctx coretypes.Sandbox) (dict.Dict, error)
 type ViewHandler func(ctx coretypes.SandboxView) (dict.Dict, error)
 
@@ -92,6 +127,36 @@ func (i *ContractInterface) GetDescription() string {
 	return i.Description
 }
 
+// EntryPoints lists entry points of the contract sorted by name, implements coretypes.ProcessorDescriber
+func (i *ContractInterface) EntryPoints() []coretypes.EntryPointInfo {
+	ret := make([]coretypes.EntryPointInfo, 0, len(i.Functions))
+	for _, f := range i.Functions {
+		ret = append(ret, coretypes.EntryPointInfo{
+			Name:       f.Name,
+			IsView:     f.IsView(),
+			ParamHints: f.ParamHints,
+			ParamTypes: f.ParamTypes,
+		})
+	}
+	sort.Slice(ret, func(k, l int) bool { return ret[k].Name < ret[l].Name })
+	return ret
+}
+
+// NewArgsBuilder returns the builder of arguments of the request to the entry point of the contract,
+// which checks parameters declared by the entry point
+func (i *ContractInterface) NewArgsBuilder(funcName string) *requestargs.Builder {
+	f, ok := i.GetFunction(funcName)
+	if !ok {
+		return requestargs.NewBuilderWithError(fmt.Errorf("entry point '%s' not found in the contract '%s'", funcName, i.Name))
+	}
+	return requestargs.NewBuilder(&coretypes.EntryPointInfo{
+		Name:       f.Name,
+		IsView:     f.IsView(),
+		ParamHints: f.ParamHints,
+		ParamTypes: f.ParamTypes,
+	})
+}
+
 // Hname caches the value
 func (i *ContractInterface) Hname() coretypes.Hname {
 	if i.hname == 0 {
@@ -25,6 +25,43 @@ type EntryPoint interface {
 	CallView(ctx SandboxView) (dict.Dict, error)
 }
 
+// Interruptible is implemented by entry points of VM types which are able to stop the running code
+// from another goroutine, e.g. when the view call timed out. The interrupted call returns an error
+type Interruptible interface {
+	Interrupt()
+}
+
+// EntryPointInfo describes the entry point of the contract for tools, see ProcessorDescriber
+type EntryPointInfo struct {
+	Name   string
+	IsView bool
+	// ParamHints are names of parameters of the entry point, if known
+	ParamHints []string
+	// ParamTypes are declared types of parameters by name. Parameters without the declared type accept any value
+	ParamTypes map[string]ParamType
+}
+
+// ParamType is the type of the value of the entry point parameter, as encoded by the kv/codec package
+type ParamType string
+
+const (
+	ParamTypeBytes      = ParamType("bytes")
+	ParamTypeString     = ParamType("string")
+	ParamTypeInt64      = ParamType("int64")
+	ParamTypeAddress    = ParamType("address")
+	ParamTypeAgentID    = ParamType("agentid")
+	ParamTypeChainID    = ParamType("chainid")
+	ParamTypeContractID = ParamType("contractid")
+	ParamTypeColor      = ParamType("color")
+	ParamTypeHname      = ParamType("hname")
+	ParamTypeHashValue  = ParamType("hash")
+)
+
+// ProcessorDescriber is implemented by processors which can enumerate their entry points
+type ProcessorDescriber interface {
+	EntryPoints() []EntryPointInfo
+}
+
 var ErrWrongTypeEntryPoint = fmt.Errorf("wrong type of entry point")
 
 // nilEntryPoint is the entry point implementation which does nothing when called
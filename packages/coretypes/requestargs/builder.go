@@ -0,0 +1,220 @@
+package requestargs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+// BlobUploader stores the value off-loaded from request arguments, so that the chain is able to solidify them
+type BlobUploader func(data []byte) error
+
+// Builder builds request arguments of the entry point, checking parameters declared by the entry point.
+// Keys which are not parameters of the entry point are rejected. Entry points which don't declare
+// parameters accept any keys, as nothing is known about them. Values must be of the declared type, if any,
+// and raw []byte values must be decodable as the declared type. Values bigger than the optimal size
+// are off-loaded to the blob cache by Build, see NewOptimizedRequestArgs.
+// The first error is kept and returned by Build
+type Builder struct {
+	ep      *coretypes.EntryPointInfo
+	optSize int
+	params  dict.Dict
+	err     error
+}
+
+// NewBuilder creates the builder of arguments of the entry point. With nil entry point nothing is checked
+func NewBuilder(ep *coretypes.EntryPointInfo) *Builder {
+	return &Builder{
+		ep:      ep,
+		optSize: optimalSize,
+		params:  dict.New(),
+	}
+}
+
+// NewBuilderWithError creates the builder which fails with the error, e.g. when the entry point is unknown
+func NewBuilderWithError(err error) *Builder {
+	ret := NewBuilder(nil)
+	ret.err = err
+	return ret
+}
+
+// WithOptimalSize sets the size of values above which they are off-loaded to the blob cache
+func (b *Builder) WithOptimalSize(size int) *Builder {
+	if size > optimalSize {
+		b.optSize = size
+	}
+	return b
+}
+
+// Set encodes the value with the kv/codec package and sets it as the parameter
+func (b *Builder) Set(name string, value interface{}) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if b.err = b.checkName(name); b.err != nil {
+		return b
+	}
+	if data, ok := value.([]byte); ok {
+		return b.SetBytes(name, data)
+	}
+	typ, ok := typeOf(value)
+	if !ok {
+		b.err = fmt.Errorf("parameter '%s': can't encode value of type %T", name, value)
+		return b
+	}
+	if declared, ok := b.declaredType(name); ok && declared != typ && declared != coretypes.ParamTypeBytes {
+		b.err = fmt.Errorf("parameter '%s' of '%s' must be %s, got %s", name, b.ep.Name, declared, typ)
+		return b
+	}
+	b.params.Set(kv.Key(name), codec.Encode(value))
+	return b
+}
+
+// SetBytes sets the value of the parameter already encoded
+func (b *Builder) SetBytes(name string, data []byte) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if b.err = b.checkName(name); b.err != nil {
+		return b
+	}
+	if declared, ok := b.declaredType(name); ok {
+		if err := checkEncoded(declared, data); err != nil {
+			b.err = fmt.Errorf("parameter '%s' of '%s' must be %s: %v", name, b.ep.Name, declared, err)
+			return b
+		}
+	}
+	b.params.Set(kv.Key(name), data)
+	return b
+}
+
+// SetMany sets encoded values of all keys of the dictionary, in the order of keys
+func (b *Builder) SetMany(params dict.Dict) *Builder {
+	keys := params.KeysSorted()
+	for _, k := range keys {
+		b.SetBytes(string(k), params[k])
+	}
+	return b
+}
+
+// Params returns parameters set so far, not encoded as request arguments
+func (b *Builder) Params() dict.Dict {
+	return b.params.Clone()
+}
+
+// Build returns request arguments or the first error. Values bigger than the optimal size are passed
+// to the uploader and replaced by blob references. With nil uploader all values are kept in the arguments
+func (b *Builder) Build(upload BlobUploader) (RequestArgs, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if upload == nil {
+		return New().AddEncodeSimpleMany(b.params), nil
+	}
+	ret, optimized := NewOptimizedRequestArgs(b.params, b.optSize)
+	keys := make([]kv.Key, 0, len(optimized))
+	for k := range optimized {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for _, k := range keys {
+		if err := upload(optimized[k]); err != nil {
+			return nil, fmt.Errorf("uploading value of parameter '%s': %v", k, err)
+		}
+	}
+	return ret, nil
+}
+
+// MustBuild is Build which panics on error
+func (b *Builder) MustBuild(upload BlobUploader) RequestArgs {
+	ret, err := b.Build(upload)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+func (b *Builder) checkName(name string) error {
+	if b.ep == nil || (b.ep.ParamHints == nil && b.ep.ParamTypes == nil) {
+		return nil
+	}
+	if _, ok := b.ep.ParamTypes[name]; ok {
+		return nil
+	}
+	for _, h := range b.ep.ParamHints {
+		if h == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown parameter '%s' of '%s'", name, b.ep.Name)
+}
+
+func (b *Builder) declaredType(name string) (coretypes.ParamType, bool) {
+	if b.ep == nil {
+		return "", false
+	}
+	ret, ok := b.ep.ParamTypes[name]
+	return ret, ok
+}
+
+// typeOf returns the parameter type of the value accepted by codec.Encode
+func typeOf(value interface{}) (coretypes.ParamType, bool) {
+	switch value.(type) {
+	case int, byte, int16, int32, int64, uint16, uint32, uint64:
+		return coretypes.ParamTypeInt64, true
+	case string:
+		return coretypes.ParamTypeString, true
+	case []byte:
+		return coretypes.ParamTypeBytes, true
+	case hashing.HashValue, *hashing.HashValue:
+		return coretypes.ParamTypeHashValue, true
+	case address.Address, *address.Address:
+		return coretypes.ParamTypeAddress, true
+	case balance.Color, *balance.Color:
+		return coretypes.ParamTypeColor, true
+	case coretypes.ChainID, *coretypes.ChainID:
+		return coretypes.ParamTypeChainID, true
+	case coretypes.ContractID, *coretypes.ContractID:
+		return coretypes.ParamTypeContractID, true
+	case coretypes.AgentID, *coretypes.AgentID:
+		return coretypes.ParamTypeAgentID, true
+	case coretypes.Hname:
+		return coretypes.ParamTypeHname, true
+	}
+	return "", false
+}
+
+// checkEncoded returns an error if the data can't be decoded as the value of the type
+func checkEncoded(typ coretypes.ParamType, data []byte) error {
+	var err error
+	switch typ {
+	case coretypes.ParamTypeBytes, coretypes.ParamTypeString:
+		return nil
+	case coretypes.ParamTypeInt64:
+		_, _, err = codec.DecodeInt64(data)
+	case coretypes.ParamTypeHashValue:
+		_, _, err = codec.DecodeHashValue(data)
+	case coretypes.ParamTypeAddress:
+		_, _, err = codec.DecodeAddress(data)
+	case coretypes.ParamTypeColor:
+		_, _, err = codec.DecodeColor(data)
+	case coretypes.ParamTypeChainID:
+		_, _, err = codec.DecodeChainID(data)
+	case coretypes.ParamTypeContractID:
+		_, _, err = codec.DecodeContractID(data)
+	case coretypes.ParamTypeAgentID:
+		_, _, err = codec.DecodeAgentID(data)
+	case coretypes.ParamTypeHname:
+		_, _, err = codec.DecodeHname(data)
+	default:
+		return fmt.Errorf("unknown parameter type '%s'", typ)
+	}
+	return err
+}
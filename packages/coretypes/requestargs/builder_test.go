@@ -0,0 +1,78 @@
+package requestargs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/stretchr/testify/require"
+)
+
+var testEntryPoint = &coretypes.EntryPointInfo{
+	Name:       "test",
+	ParamHints: []string{"n", "s", "h", "any"},
+	ParamTypes: map[string]coretypes.ParamType{
+		"n": coretypes.ParamTypeInt64,
+		"s": coretypes.ParamTypeString,
+		"h": coretypes.ParamTypeHname,
+	},
+}
+
+func TestBuilder(t *testing.T) {
+	args, err := NewBuilder(testEntryPoint).
+		Set("n", 42).
+		Set("s", "hello").
+		Set("h", coretypes.Hn("contract")).
+		SetBytes("any", []byte{1, 2, 3}).
+		Build(nil)
+	require.NoError(t, err)
+	require.Len(t, args, 4)
+	require.EqualValues(t, codec.EncodeInt64(42), args["-n"])
+	require.EqualValues(t, []byte{1, 2, 3}, args["-any"])
+}
+
+func TestBuilderChecks(t *testing.T) {
+	_, err := NewBuilder(testEntryPoint).Set("unknown", 1).Build(nil)
+	require.Error(t, err)
+
+	_, err = NewBuilder(testEntryPoint).Set("n", "42").Build(nil)
+	require.Error(t, err)
+
+	_, err = NewBuilder(testEntryPoint).SetBytes("h", []byte{1, 2}).Build(nil)
+	require.Error(t, err)
+
+	_, err = NewBuilder(testEntryPoint).Set("n", struct{}{}).Build(nil)
+	require.Error(t, err)
+
+	// the first error is kept
+	_, err = NewBuilder(testEntryPoint).Set("unknown", 1).Set("n", 42).Build(nil)
+	require.Error(t, err)
+
+	// nothing is known about the entry point without declared parameters
+	_, err = NewBuilder(&coretypes.EntryPointInfo{Name: "test"}).Set("unknown", 1).Build(nil)
+	require.NoError(t, err)
+}
+
+func TestBuilderOffload(t *testing.T) {
+	big := bytes.Repeat([]byte{1}, 100)
+	uploaded := make([][]byte, 0)
+	args, err := NewBuilder(testEntryPoint).
+		Set("s", "small").
+		SetBytes("any", big).
+		Build(func(data []byte) error {
+			uploaded = append(uploaded, data)
+			return nil
+		})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{big}, uploaded)
+	require.True(t, args.HasBlobRef())
+	require.EqualValues(t, codec.EncodeString("small"), args["-s"])
+
+	args, err = NewBuilder(testEntryPoint).SetBytes("any", big).WithOptimalSize(200).Build(func(data []byte) error {
+		t.Fatal("nothing to upload")
+		return nil
+	})
+	require.NoError(t, err)
+	require.False(t, args.HasBlobRef())
+}
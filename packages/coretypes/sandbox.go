@@ -6,6 +6,7 @@ package coretypes
 import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
@@ -43,12 +44,21 @@ type Sandbox interface {
 	MintedSupply() int64
 	// GetTimestamp return current timestamp of the context
 	GetTimestamp() int64
+	// RequestMetadata returns the metadata of the current block and of the position of the request in it.
+	// The metadata is the same on all validators of the committee, see RequestMetadata
+	RequestMetadata() RequestMetadata
+	// VRF returns the verifiable random output of the current block with the proof, see VRF.
+	// Unlike GetEntropy, it can be checked by anyone and can't be biased by validators
+	VRF() VRF
 	// GetEntropy 32 random bytes based on the hash of the current state transaction
 	GetEntropy() hashing.HashValue // 32 bytes of deterministic and unpredictably random data
 	// Balances returns colored balances owned by the smart contract
 	Balances() ColoredBalances
 	// IncomingTransfer return colored balances transferred by the call. They are already accounted into the Balances()
 	IncomingTransfer() ColoredBalances
+	// RequestTransfer returns the exact composition of the tokens carried by the request in the context of which
+	// is the current call, see RequestTransfer. It is the same for all calls in the context of the request
+	RequestTransfer() RequestTransfer
 	// Balance return number of tokens of specific color in the balance of the smart contract
 	Balance(col balance.Color) int64
 	// TransferToAddress send tokens to the L1 ledger address
@@ -57,8 +67,14 @@ type Sandbox interface {
 	PostRequest(par PostRequestParams) bool
 	// Log interface provides local logging on the machine. It also includes Panicf methods which logs and panics
 	Log() LogInterface
-	// Event publishes "vmmsg" message through Publisher on nanomsg. It also logs locally, but it is not the same thing
+	// Event publishes "vmmsg" message through Publisher on nanomsg and stores it in the free-form log of the contract.
+	// It also logs locally, but it is not the same thing.
+	// Deprecated: use EmitEvent, free-form messages can't be queried by indexers
 	Event(msg string)
+	// EmitEvent stores the typed event with the topic and the payload of fields in the event log of the contract
+	// and publishes "vmevent" message through Publisher on nanomsg.
+	// Events can be queried by topic and by values of the fields listed in 'indexed'
+	EmitEvent(topic string, fields dict.Dict, indexed ...kv.Key)
 	//
 	Utils() Utils
 }
@@ -71,3 +87,40 @@ type PostRequestParams struct {
 	Params           dict.Dict
 	Transfer         ColoredBalances
 }
+
+// RequestTransfer is the composition of the tokens carried by the request to the chain.
+// The incoming transfer of the call from the request is Declared minus Fees and minus Deposit iotas.
+// Contracts may check it to enforce exact deposit requirements
+type RequestTransfer struct {
+	// OutputID is the output of the request transaction to the chain address, which holds the tokens of the request
+	OutputID valuetransaction.OutputID
+	// Declared is the transfer declared by the request, per color
+	Declared ColoredBalances
+	// Fees are the tokens taken from the declared transfer as fees of the chain owner and of validators
+	Fees ColoredBalances
+	// Deposit is the number of iotas taken from the declared transfer as the request deposit
+	Deposit int64
+	// DustAdded is true if the iota of the request token was uncolored and accrued to the on-chain account
+	// of the sender. It is never part of the declared transfer
+	DustAdded bool
+}
+
+// RequestMetadata is the metadata of the request in the block, verified by the committee.
+// All values are deterministic: each validator computes the same values for the same batch,
+// so contracts can rely on them in ordering- and time-sensitive logic
+type RequestMetadata struct {
+	// BlockIndex is the index of the block being produced
+	BlockIndex uint32
+	// BlockTimestamp is the timestamp of the batch proposed by the leader and accepted by each validator
+	// only if it is within the tolerance of its local clock. GetTimestamp of the request is
+	// BlockTimestamp plus the RequestIndex nanoseconds
+	BlockTimestamp int64
+	// RequestIndex is the position of the request in the batch, starting from 0
+	RequestIndex uint16
+	// BatchSize is the number of requests in the batch
+	BatchSize uint16
+	// TransactionID is the ID of the L1 value transaction which contains the request
+	TransactionID valuetransaction.ID
+	// SenderAddress is the address which signed the L1 transaction of the request
+	SenderAddress address.Address
+}
@@ -12,11 +12,14 @@ type Utils interface {
 	Hashing() Hashing
 	ED25519() ED25519
 	BLS() BLS
+	Secp256k1() Secp256k1
+	Uint256() Uint256
 }
 
 type Hashing interface {
 	Blake2b(data []byte) hashing.HashValue
 	Sha3(data []byte) hashing.HashValue
+	Keccak256(data []byte) hashing.HashValue
 	Hname(name string) Hname
 }
 
@@ -35,3 +38,21 @@ type BLS interface {
 	AddressFromPublicKey(pubKey []byte) (address.Address, error)
 	AggregateBLSSignatures(pubKeysBin [][]byte, sigsBin [][]byte) ([]byte, []byte, error)
 }
+
+// Secp256k1 verifies ECDSA signatures over the secp256k1 curve, as used by Bitcoin and Ethereum
+type Secp256k1 interface {
+	// ValidSignature checks the 64 byte (r, s) signature of the 32 byte hash of the data.
+	// The public key is either in compressed (33 bytes) or uncompressed (65 bytes) form
+	ValidSignature(hash []byte, pubKey []byte, signature []byte) bool
+}
+
+// Uint256 implements arithmetic of unsigned 256-bit integers.
+// Operands and results are 32 byte big-endian values, results wrap around modulo 2^256
+type Uint256 interface {
+	Add(a, b []byte) ([]byte, error)
+	Sub(a, b []byte) ([]byte, error)
+	Mul(a, b []byte) ([]byte, error)
+	Div(a, b []byte) ([]byte, error)
+	Mod(a, b []byte) ([]byte, error)
+	Exp(a, b []byte) ([]byte, error)
+}
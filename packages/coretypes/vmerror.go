@@ -0,0 +1,153 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VMErrorCode classifies the failure of the request or of the call, so that clients don't depend on
+// the text of errors. The values are stable: they are stored in the chain log and must never be
+// changed or reused, new codes are only appended
+type VMErrorCode uint16
+
+const (
+	// VMErrorNone means the call succeeded
+	VMErrorNone = VMErrorCode(0)
+	// VMErrorUser is the error raised by the contract itself: returned error, ContractError or panic
+	VMErrorUser = VMErrorCode(1)
+	// VMErrorInternal is the failure of the VM, not caused by the request
+	VMErrorInternal = VMErrorCode(2)
+	// VMErrorContractNotFound means the target contract is not deployed on the chain
+	VMErrorContractNotFound = VMErrorCode(3)
+	// VMErrorEntryPointNotFound means the target contract has no such entry point
+	VMErrorEntryPointNotFound = VMErrorCode(4)
+	// VMErrorInvalidCall means the entry point can't be called this way, e.g. the view from the request
+	VMErrorInvalidCall = VMErrorCode(5)
+	// VMErrorUnauthorized means the caller is not allowed to call the entry point
+	VMErrorUnauthorized = VMErrorCode(6)
+	// VMErrorParamDecodeFailed means a parameter of the call can't be decoded as the expected type
+	VMErrorParamDecodeFailed = VMErrorCode(7)
+	// VMErrorOutOfBudget means the call exceeded a limit of the chain: call depth, state size,
+	// number of contracts, execution budget or time of the view
+	VMErrorOutOfBudget = VMErrorCode(8)
+	// VMErrorReentrantCall means the call re-entered the contract locked against reentrancy
+	VMErrorReentrantCall = VMErrorCode(9)
+	// VMErrorNotEnoughFees means the transfer of the request doesn't cover the fees
+	VMErrorNotEnoughFees = VMErrorCode(10)
+	// VMErrorNotEnoughDeposit means the transfer of the request doesn't cover the deposit
+	VMErrorNotEnoughDeposit = VMErrorCode(11)
+	// VMErrorContractPaused means the target contract is paused by the chain owner or the pauser
+	VMErrorContractPaused = VMErrorCode(12)
+)
+
+var vmErrorNames = []string{
+	VMErrorNone:               "none",
+	VMErrorUser:               "user-error",
+	VMErrorInternal:           "internal",
+	VMErrorContractNotFound:   "contract-not-found",
+	VMErrorEntryPointNotFound: "entry-point-not-found",
+	VMErrorInvalidCall:        "invalid-call",
+	VMErrorUnauthorized:       "unauthorized",
+	VMErrorParamDecodeFailed:  "param-decode-failed",
+	VMErrorOutOfBudget:        "out-of-budget",
+	VMErrorReentrantCall:      "reentrant-call",
+	VMErrorNotEnoughFees:      "not-enough-fees",
+	VMErrorNotEnoughDeposit:   "not-enough-deposit",
+	VMErrorContractPaused:     "contract-paused",
+}
+
+// VMErrorCodes returns all defined codes, in the order of values
+func VMErrorCodes() []VMErrorCode {
+	ret := make([]VMErrorCode, len(vmErrorNames))
+	for i := range ret {
+		ret[i] = VMErrorCode(i)
+	}
+	return ret
+}
+
+func (c VMErrorCode) String() string {
+	if int(c) < len(vmErrorNames) {
+		return vmErrorNames[c]
+	}
+	return fmt.Sprintf("unknown-%d", uint16(c))
+}
+
+// VMError is the error of the VM, the sandbox or a core contract with the stable code
+type VMError struct {
+	Code    VMErrorCode
+	Message string
+}
+
+// NewVMError creates the error with the code
+func NewVMError(code VMErrorCode, format string, args ...interface{}) *VMError {
+	return &VMError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *VMError) Error() string {
+	return e.Message
+}
+
+// VMErrorCodeOf returns the code of the error. Errors not classified by the VM, including
+// ContractError, are raised by the contract, so they are user errors
+func VMErrorCodeOf(err error) VMErrorCode {
+	if err == nil {
+		return VMErrorNone
+	}
+	var vmErr *VMError
+	if errors.As(err, &vmErr) {
+		return vmErr.Code
+	}
+	return VMErrorUser
+}
+
+// RecoveredVMError is the error of the call which panicked with the value. The code of the VMError the call
+// panicked with is kept, any other panic is raised by the contract
+func RecoveredVMError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("recovered from panic in VM: %w", err)
+	}
+	return fmt.Errorf("recovered from panic in VM: %v", r)
+}
+
+// requestOutcomeOk is the outcome of the successful request in the chain log
+const requestOutcomeOk = "Ok"
+
+// FormatRequestOutcome encodes the outcome of the request for the chain log: either "Ok"
+// or "error <code> (<name>): <message>"
+func FormatRequestOutcome(err error) string {
+	if err == nil {
+		return requestOutcomeOk
+	}
+	code := VMErrorCodeOf(err)
+	return fmt.Sprintf("error %d (%s): %s", code, code, err.Error())
+}
+
+// ParseRequestOutcome is the reverse of FormatRequestOutcome. Returns VMErrorNone and empty message
+// for the successful request
+func ParseRequestOutcome(s string) (VMErrorCode, string, error) {
+	if s == requestOutcomeOk {
+		return VMErrorNone, "", nil
+	}
+	if !strings.HasPrefix(s, "error ") {
+		return 0, "", fmt.Errorf("wrong request outcome '%s'", s)
+	}
+	s = strings.TrimPrefix(s, "error ")
+	i := strings.Index(s, " (")
+	j := strings.Index(s, "): ")
+	if i < 0 || j < i {
+		return 0, "", fmt.Errorf("wrong request outcome '%s'", s)
+	}
+	code, err := strconv.ParseUint(s[:i], 10, 16)
+	if err != nil {
+		return 0, "", fmt.Errorf("wrong request outcome '%s': %v", s, err)
+	}
+	return VMErrorCode(code), s[j+3:], nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVMErrorCodeNames(t *testing.T) {
+	names := make(map[string]bool)
+	for _, code := range VMErrorCodes() {
+		name := code.String()
+		require.NotEmpty(t, name)
+		require.False(t, names[name], "duplicate name %s", name)
+		names[name] = true
+	}
+	require.EqualValues(t, "unknown-1000", VMErrorCode(1000).String())
+}
+
+func TestVMErrorCodeOf(t *testing.T) {
+	require.EqualValues(t, VMErrorNone, VMErrorCodeOf(nil))
+	require.EqualValues(t, VMErrorUser, VMErrorCodeOf(errors.New("some error")))
+	require.EqualValues(t, VMErrorUser, VMErrorCodeOf(NewContractError(1, "contract error")))
+
+	err := NewVMError(VMErrorContractNotFound, "contract %s not found", "abc")
+	require.EqualValues(t, VMErrorContractNotFound, VMErrorCodeOf(err))
+	require.EqualValues(t, VMErrorContractNotFound, VMErrorCodeOf(fmt.Errorf("wrapped: %w", err)))
+	require.EqualValues(t, VMErrorContractNotFound, VMErrorCodeOf(RecoveredVMError(err)))
+	require.EqualValues(t, VMErrorUser, VMErrorCodeOf(RecoveredVMError("string panic")))
+}
+
+func TestRequestOutcome(t *testing.T) {
+	require.EqualValues(t, "Ok", FormatRequestOutcome(nil))
+	code, msg, err := ParseRequestOutcome("Ok")
+	require.NoError(t, err)
+	require.EqualValues(t, VMErrorNone, code)
+	require.EqualValues(t, "", msg)
+
+	s := FormatRequestOutcome(NewVMError(VMErrorOutOfBudget, "exceeded: (%d)", 5))
+	require.EqualValues(t, "error 8 (out-of-budget): exceeded: (5)", s)
+	code, msg, err = ParseRequestOutcome(s)
+	require.NoError(t, err)
+	require.EqualValues(t, VMErrorOutOfBudget, code)
+	require.EqualValues(t, "exceeded: (5)", msg)
+
+	_, _, err = ParseRequestOutcome("something else")
+	require.Error(t, err)
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package coretypes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// VRF is the verifiable random output of the block. The seed of the block is made of the hash of the previous
+// state, the index and the timestamp of the block, see VRFSeed. The proof is the BLS threshold signature of the
+// seed by the committee, collected by the leader during the consensus round before the batch is proposed.
+// The BLS signature is unique for the seed and can't be computed without the quorum of key shares, and each node
+// signs only one seed of the leader in the state. So no validator, including the leader, is able to choose or to
+// grind the output, and nobody knows it before the leader fixes the timestamp of the block.
+// Anyone can check the VRF with the BLS address of the committee: the hash of the previous state is in the anchor
+// transaction of the previous block and the block index in the anchor transaction of the block.
+// The address of the committee is the address of the chain, unless the chain is controlled by FROST. Then it
+// is the BLS address of the committee, which signs the VRF and the internal messages of the committee.
+// The leader learns the output before it selects requests of the batch, so contracts should fix bets and bids
+// in one request and draw with the VRF of a later block
+type VRF struct {
+	// Output is the random value derived from the proof
+	Output hashing.HashValue
+	// PrevStateHash is the hash of the state the block is applied to
+	PrevStateHash hashing.HashValue
+	// BlockIndex is the index of the block
+	BlockIndex uint32
+	// Timestamp is the timestamp of the batch of the block
+	Timestamp int64
+	// Proof is the BLS signature of the seed (bytes of the signature scheme signature with the public key)
+	Proof []byte
+}
+
+// VRFSeed is the data of the block signed by the committee to produce the VRF
+func VRFSeed(prevStateHash hashing.HashValue, blockIndex uint32, timestamp int64) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("vrf")
+	buf.Write(prevStateHash[:])
+	buf.Write(util.Uint32To4Bytes(blockIndex))
+	buf.Write(util.Uint64To8Bytes(uint64(timestamp)))
+	return buf.Bytes()
+}
+
+// NewVRF returns the VRF of the block with the signature of its seed by the committee.
+// Only the BLS signature is accepted: other signature schemes, for example Ed25519, are not unique
+func NewVRF(prevStateHash hashing.HashValue, blockIndex uint32, timestamp int64, sig signaturescheme.Signature) (VRF, error) {
+	proof := sig.Bytes()
+	if len(proof) == 0 || proof[0] != address.VersionBLS {
+		return VRF{}, errors.New("VRF proof must be the BLS signature")
+	}
+	ret := VRF{
+		Output:        vrfOutput(proof),
+		PrevStateHash: prevStateHash,
+		BlockIndex:    blockIndex,
+		Timestamp:     timestamp,
+		Proof:         proof,
+	}
+	if !sig.IsValid(ret.Seed()) {
+		return VRF{}, errors.New("VRF proof is not a valid signature of the seed")
+	}
+	return ret, nil
+}
+
+// VRFFromProof restores the VRF of the block from the bytes of the proof and checks that it was signed by the
+// committee with the BLS address
+func VRFFromProof(prevStateHash hashing.HashValue, blockIndex uint32, timestamp int64, proof []byte, committeeAddress *address.Address) (VRF, error) {
+	if len(proof) <= 1+signaturescheme.BLSPublicKeySize || proof[0] != address.VersionBLS {
+		return VRF{}, errors.New("VRF proof must be the BLS signature")
+	}
+	sig := signaturescheme.NewBLSSignature(proof[1:1+signaturescheme.BLSPublicKeySize], proof[1+signaturescheme.BLSPublicKeySize:])
+	if sig.Address() != *committeeAddress {
+		return VRF{}, fmt.Errorf("VRF is signed by %s, not by the committee %s", sig.Address().String(), committeeAddress.String())
+	}
+	return NewVRF(prevStateHash, blockIndex, timestamp, sig)
+}
+
+// Seed returns the data signed by the committee, see VRFSeed
+func (v *VRF) Seed() []byte {
+	return VRFSeed(v.PrevStateHash, v.BlockIndex, v.Timestamp)
+}
+
+// Verify checks the VRF against the BLS address of the committee
+func (v *VRF) Verify(committeeAddress *address.Address) error {
+	vrf, err := VRFFromProof(v.PrevStateHash, v.BlockIndex, v.Timestamp, v.Proof, committeeAddress)
+	if err != nil {
+		return err
+	}
+	if v.Output != vrf.Output {
+		return errors.New("VRF output doesn't match the proof")
+	}
+	return nil
+}
+
+func vrfOutput(proof []byte) hashing.HashValue {
+	return hashing.HashData([]byte("vrf"), proof)
+}
@@ -89,7 +89,7 @@ const tplBase = `
 {{define "agentid"}}
 	{{ $chainid := index . 0 }}
 	{{ $agentid := index . 1 }}
-	<a href="{{ uri "chainAccount" $chainid (replace $agentid.String "/" ":" 1) }}"><tt>{{ $agentid }}</tt></a>
+	<a href="{{ uri "chainAccount" $chainid.Bech32 $agentid.Bech32 }}"><tt>{{ $agentid.Bech32 }}</tt></a>
 	{{if $agentid.IsAddress}} {{ template "exploreAddressInTangle" $agentid.MustAddress }} {{end}}
 {{end}}
 
@@ -102,6 +102,17 @@ const tplBase = `
 	</dl>
 {{end}}
 
+{{define "tokenbalances"}}
+	{{ $tokens := index . 1 }}
+	<dl>
+		{{range $color, $bal := index . 0}}
+			{{ $token := index $tokens $color }}
+			<dt><tt>{{ $color }}</tt>{{if $token}} {{ $token.Name }}{{end}}</dt>
+			<dd>{{if $token}}{{ $token.FormatAmount $bal }}{{else}}{{ $bal }}{{end}}</dd>
+		{{end}}
+	</dl>
+{{end}}
+
 {{define "tab"}}
 	{{ $title := index . 0 }}
 	{{ $href := index . 1 }}
@@ -20,8 +20,8 @@ import (
 func chainBreadcrumb(e *echo.Echo, chainID coretypes.ChainID) Tab {
 	return Tab{
 		Path:  e.Reverse("chain"),
-		Title: fmt.Sprintf("Chain %.8s…", chainID),
-		Href:  e.Reverse("chain", chainID.String()),
+		Title: fmt.Sprintf("Chain %.14s…", chainID.Bech32()),
+		Href:  e.Reverse("chain", chainID.Bech32()),
 	}
 }
 
@@ -32,7 +32,7 @@ func initChain(e *echo.Echo, r renderer) {
 }
 
 func handleChain(c echo.Context) error {
-	chainid, err := coretypes.NewChainIDFromBase58(c.Param("chainid"))
+	chainid, err := coretypes.NewChainIDFromString(c.Param("chainid"))
 	if err != nil {
 		return err
 	}
@@ -54,6 +54,7 @@ func handleChain(c echo.Context) error {
 		if err != nil {
 			return err
 		}
+		result.ReadCache = state.GetReadCacheStats(&chainid)
 
 		chain := chains.GetChain(chainid)
 
@@ -77,6 +78,11 @@ func handleChain(c echo.Context) error {
 			return err
 		}
 
+		result.Tokens, err = fetchTokenMetadata(chain, result.TotalAssets)
+		if err != nil {
+			return err
+		}
+
 		result.Blobs, err = fetchBlobs(chain)
 		if err != nil {
 			return err
@@ -111,6 +117,30 @@ func fetchTotalAssets(chain chain.Chain) (map[balance.Color]int64, error) {
 	return accounts.DecodeBalances(bal)
 }
 
+// fetchTokenMetadata returns metadata of colored tokens in balances which is registered on the chain
+func fetchTokenMetadata(chain chain.Chain, balances map[balance.Color]int64) (map[balance.Color]*accounts.TokenMetadata, error) {
+	ret := make(map[balance.Color]*accounts.TokenMetadata)
+	for color := range balances {
+		if color == balance.ColorIOTA {
+			continue
+		}
+		res, err := callView(chain, accounts.Interface.Hname(), accounts.FuncGetTokenMetadata, codec.MakeDict(map[string]interface{}{
+			accounts.ParamColor: color,
+		}))
+		if err != nil {
+			return nil, err
+		}
+		data := res.MustGet(accounts.ParamTokenMetadata)
+		if data == nil {
+			continue
+		}
+		if ret[color], err = accounts.DecodeTokenMetadata(data); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
 func fetchBlobs(chain chain.Chain) (map[hashing.HashValue]uint32, error) {
 	ret, err := callView(chain, blob.Interface.Hname(), blob.FuncListBlobs, nil)
 	if err != nil {
@@ -127,9 +157,11 @@ type ChainTemplateParams struct {
 	ChainRecord  *registry.ChainRecord
 	Block        state.Block
 	VirtualState state.VirtualState
+	ReadCache    state.ReadCacheStats
 	RootInfo     RootInfo
 	Accounts     []coretypes.AgentID
 	TotalAssets  map[balance.Color]int64
+	Tokens       map[balance.Color]*accounts.TokenMetadata
 	Blobs        map[hashing.HashValue]uint32
 	Committee    struct {
 		Size       uint16
@@ -151,10 +183,10 @@ const tplChain = `
 		{{ $desc := trim 50 $rootinfo.Description }}
 
 		<div class="card fluid">
-			<h2 class="section">{{if $desc}}{{$desc}}{{else}}Chain <tt>{{$chainid}}</tt>{{end}}</h2>
+			<h2 class="section">{{if $desc}}{{$desc}}{{else}}Chain <tt>{{$chainid.Bech32}}</tt>{{end}}</h2>
 
 			<dl>
-				<dt>ChainID</dt><dd><tt>{{.ChainRecord.ChainID}}</tt></dd>
+				<dt>ChainID</dt><dd><tt>{{.ChainRecord.ChainID.Bech32}}</tt></dd>
 				<dt>Chain address</dt><dd>{{template "address" .RootInfo.ChainAddress}}</dd>
 				<dt>Chain color</dt><dd><tt>{{.RootInfo.ChainColor}}</tt></dd>
 				<dt>Active</dt><dd><tt>{{.ChainRecord.Active}}</tt></dd>
@@ -176,7 +208,7 @@ const tplChain = `
 				<h3 class="section">Contracts</h3>
 				<dl>
 				{{range $_, $c := $rootinfo.Contracts}}
-					<dt><a href="{{ uri "chainContract" $chainid $c.Hname }}"><tt>{{trim 30 $c.Name}}</tt></a></dt>
+					<dt><a href="{{ uri "chainContract" $chainid.Bech32 $c.Hname }}"><tt>{{trim 30 $c.Name}}</tt></a></dt>
 					<dd><tt>{{trim 50 $c.Description}}</tt></dd>
 				{{end}}
 				</dl>
@@ -199,7 +231,7 @@ const tplChain = `
 					</tbody>
 				</table>
 				<h4>Total assets</h4>
-				{{ template "balances" .TotalAssets }}
+				{{ template "tokenbalances" (args .TotalAssets .Tokens) }}
 			</div>
 
 			<div class="card fluid">
@@ -214,7 +246,7 @@ const tplChain = `
 					<tbody>
 					{{range $hash, $size := .Blobs}}
 						<tr>
-							<td style="flex: 2"><a href="{{ uri "chainBlob" $chainid (hashref $hash) }}"><tt>{{ hashref $hash }}</tt></a></td>
+							<td style="flex: 2"><a href="{{ uri "chainBlob" $chainid.Bech32 (hashref $hash) }}"><tt>{{ hashref $hash }}</tt></a></td>
 							<td>{{ $size }}</td>
 						</tr>
 					{{end}}
@@ -228,11 +260,15 @@ const tplChain = `
 					<dt>State index</dt><dd><tt>{{.Block.StateIndex}}</tt></dd>
 					<dt>State hash</dt><dd><tt>{{.VirtualState.Hash}}</tt></dd>
 					<dt>Last updated</dt><dd><tt>{{formatTimestamp .Block.Timestamp}}</tt> in transaction <tt>{{.Block.StateTransactionID}}</tt></dd>
+					{{if .ReadCache.Capacity}}
+					<dt>Read cache</dt><dd><tt>{{.ReadCache.Size}} of {{.ReadCache.Capacity}} keys, hit rate {{printf "%.2f" .ReadCache.HitRate}}</tt> ({{.ReadCache.Hits}} hits, {{.ReadCache.Misses}} misses, {{.ReadCache.Invalidations}} invalidated)</dd>
+					{{end}}
 				</dl>
 			</div>
 
 			<div class="card fluid">
 				<h3 class="section">Committee</h3>
+				<a href="{{ uri "chainCommittee" $chainid.Bech32 }}">Committee health</a>
 				<dl>
 				<dt>Size</dt>      <dd><tt>{{.Committee.Size}}</tt></dd>
 				<dt>Quorum</dt>    <dd><tt>{{.Committee.Quorum}}</tt></dd>
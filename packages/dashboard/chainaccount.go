@@ -20,7 +20,7 @@ func initChainAccount(e *echo.Echo, r renderer) {
 }
 
 func handleChainAccount(c echo.Context) error {
-	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainid"))
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainid"))
 	if err != nil {
 		return err
 	}
@@ -33,7 +33,7 @@ func handleChainAccount(c echo.Context) error {
 	result := &ChainAccountTemplateParams{
 		BaseTemplateParams: BaseParams(c, chainBreadcrumb(c.Echo(), chainID), Tab{
 			Path:  c.Path(),
-			Title: fmt.Sprintf("Account %.8s…", agentID),
+			Title: fmt.Sprintf("Account %.14s…", agentID.Bech32()),
 			Href:  "#",
 		}),
 		ChainID: chainID,
@@ -52,6 +52,10 @@ func handleChainAccount(c echo.Context) error {
 		if err != nil {
 			return err
 		}
+		result.Tokens, err = fetchTokenMetadata(chain, result.Balances)
+		if err != nil {
+			return err
+		}
 	}
 
 	return c.Render(http.StatusOK, c.Path(), result)
@@ -64,6 +68,7 @@ type ChainAccountTemplateParams struct {
 	AgentID coretypes.AgentID
 
 	Balances map[balance.Color]int64
+	Tokens   map[balance.Color]*accounts.TokenMetadata
 }
 
 const tplChainAccount = `
@@ -79,7 +84,7 @@ const tplChainAccount = `
 		</div>
 		<div class="card fluid">
 			<h3 class="section">Balances</h3>
-			{{ template "balances" .Balances }}
+			{{ template "tokenbalances" (args .Balances .Tokens) }}
 		</div>
 		{{ template "ws" .ChainID }}
 	{{else}}
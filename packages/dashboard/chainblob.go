@@ -24,7 +24,7 @@ func initChainBlob(e *echo.Echo, r renderer) {
 }
 
 func handleChainBlob(c echo.Context) error {
-	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainid"))
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainid"))
 	if err != nil {
 		return err
 	}
@@ -73,7 +73,7 @@ func handleChainBlob(c echo.Context) error {
 }
 
 func handleChainBlobDownload(c echo.Context) error {
-	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainid"))
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainid"))
 	if err != nil {
 		return err
 	}
@@ -149,7 +149,7 @@ const tplChainBlob = `
 						<td><tt>{{ trim 30 (bytesToString $field.Key) }}</tt></td>
 						<td style="flex: 2"><pre style="white-space: pre-wrap">{{ trim 100 (bytesToString $field.Value) }}</pre></td>
 						<td class="align-right" style="flex: 0.5">{{ len $field.Value }}</td>
-						<td style="flex: 0.5"><a href="{{ uri "chainBlobDownload" $chainid (hashref $hash) (base58 $field.Key) }}">Download</a></td>
+						<td style="flex: 0.5"><a href="{{ uri "chainBlobDownload" $chainid.Bech32 (hashref $hash) (base58 $field.Key) }}">Download</a></td>
 					</tr>
 				{{end}}
 				</tbody>
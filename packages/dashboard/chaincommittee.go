@@ -0,0 +1,154 @@
+package dashboard
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+)
+
+func initChainCommittee(e *echo.Echo, r renderer) {
+	route := e.GET("/chain/:chainid/committee", handleChainCommittee)
+	route.Name = "chainCommittee"
+	r[route.Path] = makeTemplate(e, tplChainCommittee, tplWs)
+}
+
+func handleChainCommittee(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainid"))
+	if err != nil {
+		return err
+	}
+
+	result := &ChainCommitteeTemplateParams{
+		BaseTemplateParams: BaseParams(c, chainBreadcrumb(c.Echo(), chainID), Tab{
+			Path:  c.Path(),
+			Title: "Committee",
+			Href:  "#",
+		}),
+		ChainID: chainID,
+	}
+
+	ch := chains.GetChain(chainID)
+	if ch != nil {
+		result.Found = true
+		result.Size = ch.Size()
+		result.Quorum = ch.Quorum()
+		result.OwnPeerIndex = ch.OwnPeerIndex()
+		result.HasQuorum = ch.HasQuorum()
+		result.PeerStatus = ch.PeerStatus()
+		result.Consensus = ch.ConsensusInfo()
+	}
+
+	return c.Render(http.StatusOK, c.Path(), result)
+}
+
+type ChainCommitteeTemplateParams struct {
+	BaseTemplateParams
+
+	ChainID coretypes.ChainID
+
+	Found        bool
+	Size         uint16
+	Quorum       uint16
+	OwnPeerIndex uint16
+	HasQuorum    bool
+	PeerStatus   []*chain.PeerStatus
+	// Consensus is nil if the node is not in the committee of the chain
+	Consensus *chain.ConsensusInfo
+}
+
+const tplChainCommittee = `
+{{define "title"}}Committee health{{end}}
+
+{{define "body"}}
+	{{if .Found}}
+		{{ $consensus := .Consensus }}
+
+		<div class="card fluid">
+			<h2 class="section">Committee</h2>
+			<dl>
+				<dt>Size</dt>           <dd><tt>{{.Size}}</tt></dd>
+				<dt>Quorum</dt>         <dd><tt>{{.Quorum}}</tt></dd>
+				<dt>Own peer index</dt> <dd><tt>{{.OwnPeerIndex}}</tt></dd>
+				<dt>Has quorum</dt>     <dd><tt>{{.HasQuorum}}</tt></dd>
+			</dl>
+		</div>
+
+		<div class="card fluid">
+			<h3 class="section">Peers</h3>
+			<table>
+			<thead>
+				<tr>
+					<th style="flex: 0.5">Index</th>
+					<th style="flex: 2">ID</th>
+					<th>Connected</th>
+					<th>Alive</th>
+					<th style="flex: 2">Last heard</th>
+					<th>Role</th>
+				</tr>
+			</thead>
+			<tbody>
+			{{range $_, $s := .PeerStatus}}
+				<tr>
+					<td style="flex: 0.5">{{$s.Index}}</td>
+					<td style="flex: 2"><tt>{{$s.PeeringID}}</tt>{{if $s.IsSelf}} (self){{end}}</td>
+					<td>{{if $s.Connected}}up{{else}}down{{end}}</td>
+					<td>{{if $s.Alive}}yes{{else}}no{{end}}</td>
+					<td style="flex: 2">{{if $s.IsSelf}}-{{else if $s.LastHeard.IsZero}}never{{else}}<tt>{{formatTimestamp $s.LastHeard}}</tt>{{end}}</td>
+					<td>{{if $consensus}}{{if eq $s.Index $consensus.Leader}}leader{{end}}{{end}}</td>
+				</tr>
+			{{end}}
+			</tbody>
+			</table>
+		</div>
+
+		{{if $consensus}}
+			<div class="card fluid">
+				<h3 class="section">Consensus</h3>
+				<dl>
+					<dt>Stage</dt>                <dd><tt>{{$consensus.Stage}}</tt></dd>
+					<dt>Synced</dt>               <dd><tt>{{$consensus.Synced}}</tt></dd>
+					<dt>Current leader</dt>       <dd><tt>{{$consensus.Leader}}</tt>{{if $consensus.IsLeader}} (self){{end}}</dd>
+					<dt>Last state index</dt>     <dd><tt>{{$consensus.StateIndex}}</tt></dd>
+					<dt>Last state timestamp</dt> <dd><tt>{{formatTimestamp $consensus.StateTimestamp}}</tt></dd>
+					<dt>Request backlog</dt>      <dd><tt>{{$consensus.Backlog}}</tt></dd>
+					<dt>Overdue requests</dt>     <dd><tt>{{$consensus.Overdue}}</tt></dd>
+					{{if $consensus.Emergency}}
+					<dt>Emergency mode</dt>       <dd><tt>blocks delegated to #{{$consensus.EmergencyDelegate}} until state #{{$consensus.EmergencyUntil}}</tt></dd>
+					{{end}}
+				</dl>
+				<h4>Recent rounds</h4>
+				<table>
+				<thead>
+					<tr>
+						<th>State index</th>
+						<th>Leader</th>
+						<th style="flex: 2">Finished</th>
+						<th>Duration</th>
+						<th>Requests</th>
+					</tr>
+				</thead>
+				<tbody>
+				{{range $_, $r := $consensus.RecentRounds}}
+					<tr>
+						<td>{{$r.StateIndex}}</td>
+						<td>{{$r.Leader}}</td>
+						<td style="flex: 2"><tt>{{formatTimestamp $r.Finished}}</tt></td>
+						<td>{{if $r.Duration}}{{$r.Duration}}{{else}}-{{end}}</td>
+						<td>{{$r.NumRequests}}</td>
+					</tr>
+				{{end}}
+				</tbody>
+				</table>
+			</div>
+		{{else}}
+			<div class="card fluid warning">The node is not in the committee of the chain.</div>
+		{{end}}
+		{{ template "ws" .ChainID }}
+	{{else}}
+		<div class="card fluid error">Chain <tt>{{.ChainID}}</tt> is not running on the node.</div>
+	{{end}}
+{{end}}
+`
@@ -20,7 +20,7 @@ func initChainContract(e *echo.Echo, r renderer) {
 }
 
 func handleChainContract(c echo.Context) error {
-	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainid"))
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainid"))
 	if err != nil {
 		return err
 	}
@@ -10,5 +10,6 @@ func chainsInit(e *echo.Echo, r renderer) Tab {
 	initChainAccount(e, r)
 	initChainBlob(e, r)
 	initChainContract(e, r)
+	initChainCommittee(e, r)
 	return tab
 }
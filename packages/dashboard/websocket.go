@@ -20,7 +20,7 @@ func addWsEndpoints(e *echo.Echo) {
 }
 
 func handleWebSocket(c echo.Context) error {
-	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainid"))
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainid"))
 	if err != nil {
 		return err
 	}
@@ -75,7 +75,7 @@ func startWsForwarder() {
 const tplWs = `
 {{define "ws"}}
 	<script>
-		const url = 'ws://' +  location.host + '{{ uri "chainWs" . }}';
+		const url = 'ws://' +  location.host + '{{ uri "chainWs" .Bech32 }}';
 		console.log('opening WebSocket to ' + url);
 		const ws = new WebSocket(url);
 
@@ -0,0 +1,118 @@
+package dbprovider
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/iotaledger/goshimmer/packages/database"
+	"github.com/iotaledger/hive.go/kvstore"
+	hivepebble "github.com/iotaledger/hive.go/kvstore/pebble"
+	"github.com/iotaledger/hive.go/logger"
+)
+
+// storage engines of the node database
+const (
+	// BackendInMemory keeps the database in memory, it is lost on restart
+	BackendInMemory = "inmemory"
+	// BackendBadger is the BadgerDB engine. Default
+	BackendBadger = "badger"
+	// BackendPebble is the Pebble engine, the LSM tree with RocksDB compatible file format and compaction
+	// heuristics. It has lower write amplification than BadgerDB for the write-heavy chains
+	BackendPebble = "pebble"
+)
+
+// Backend is the storage engine of the node database. All chain states and the registry
+// are stored in the key/value stores of one backend, partitioned by the chain ID
+type Backend interface {
+	// NewStore returns the key/value store of the whole database
+	NewStore() kvstore.KVStore
+	// Close flushes the data to the disk and closes the database
+	Close() error
+	// RequiresGC tells if GC must be run periodically to reclaim the space
+	RequiresGC() bool
+	GC() error
+}
+
+// BackendFactory opens the backend in the directory. The directory is created, if needed
+type BackendFactory func(dbDir string) (Backend, error)
+
+// Backends lists names of all supported storage engines
+var Backends = []string{BackendInMemory, BackendBadger, BackendPebble}
+
+var backendFactories = map[string]BackendFactory{
+	BackendInMemory: func(string) (Backend, error) {
+		return database.NewMemDB()
+	},
+	BackendBadger: func(dbDir string) (Backend, error) {
+		return database.NewDB(dbDir)
+	},
+	BackendPebble: func(dbDir string) (Backend, error) {
+		return newPebbleDB(dbDir)
+	},
+}
+
+// RegisterBackend makes the storage engine selectable by name, both in the node config and in
+// the migration tool. It allows to add engines which are not built into the node by default,
+// e.g. RocksDB which requires cgo and the native library. Must be called before the database is opened
+func RegisterBackend(name string, factory BackendFactory) {
+	if _, ok := backendFactories[name]; ok {
+		panic(fmt.Sprintf("database backend '%s' is already registered", name))
+	}
+	backendFactories[name] = factory
+	Backends = append(Backends, name)
+}
+
+// NewDBProvider creates the database provider with the storage engine selected by name.
+// dbDir is ignored by the in-memory backend
+func NewDBProvider(backend string, dbDir string, log *logger.Logger) (*DBProvider, error) {
+	db, err := openBackend(backend, dbDir)
+	if err != nil {
+		return nil, err
+	}
+	return newDBProvider(db, log), nil
+}
+
+func openBackend(backend string, dbDir string) (Backend, error) {
+	factory, ok := backendFactories[backend]
+	if !ok {
+		return nil, fmt.Errorf("unknown database backend '%s'. Supported: %v", backend, Backends)
+	}
+	return factory(dbDir)
+}
+
+// pebbleDB adapts the Pebble engine to the database interface of the node
+type pebbleDB struct {
+	db *pebble.DB
+}
+
+func newPebbleDB(dbDir string) (*pebbleDB, error) {
+	if err := os.MkdirAll(dbDir, 0700); err != nil {
+		return nil, err
+	}
+	db, err := hivepebble.CreateDB(dbDir)
+	if err != nil {
+		return nil, err
+	}
+	return &pebbleDB{db: db}, nil
+}
+
+func (p *pebbleDB) NewStore() kvstore.KVStore {
+	return hivepebble.New(p.db)
+}
+
+func (p *pebbleDB) Close() error {
+	if err := p.db.Flush(); err != nil {
+		return err
+	}
+	return p.db.Close()
+}
+
+// RequiresGC is false: Pebble reclaims the space by compactions in the background
+func (p *pebbleDB) RequiresGC() bool {
+	return false
+}
+
+func (p *pebbleDB) GC() error {
+	return nil
+}
@@ -12,13 +12,13 @@ import (
 
 type DBProvider struct {
 	log             *logger.Logger
-	db              database.DB
+	db              Backend
 	store           kvstore.KVStore
 	partitions      map[coretypes.ChainID]kvstore.KVStore
 	partitionsMutex *sync.RWMutex
 }
 
-func newDBProvider(db database.DB, log *logger.Logger) *DBProvider {
+func newDBProvider(db Backend, log *logger.Logger) *DBProvider {
 	return &DBProvider{
 		log:             log,
 		db:              db,
@@ -15,6 +15,15 @@ const (
 	ObjectTypeNodeIdentity
 	ObjectTypeBlobCache
 	ObjectTypeBlobCacheTTL
+	ObjectTypeEquivocationEvidence
+	ObjectTypeConsensusRound
+	ObjectTypeBlobRef
+	ObjectTypeChainBlob
+	ObjectTypeStateCheckpoint
+	ObjectTypeRegistryVersion
+	ObjectTypeEmergencyRecord
+	ObjectTypeIdentityHandover
+	ObjectTypeFrostShare
 )
 
 // MakeKey makes key within the partition. It consists to one byte for object type
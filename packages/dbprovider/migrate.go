@@ -0,0 +1,38 @@
+package dbprovider
+
+import (
+	"github.com/iotaledger/hive.go/kvstore"
+)
+
+// number of key/value pairs written to the target database in one batch during migration
+const migrateBatchSize = 10000
+
+// Migrate copies all key/value pairs of all partitions from the source database to the target database.
+// The target database is expected to be empty. Returns the number of copied pairs
+func Migrate(src, dst *DBProvider) (int, error) {
+	count := 0
+	batch := dst.store.Batched()
+	var err error
+	errIterate := src.store.Iterate(kvstore.EmptyPrefix, func(key kvstore.Key, value kvstore.Value) bool {
+		if err = batch.Set(key, value); err != nil {
+			return false
+		}
+		count++
+		if count%migrateBatchSize == 0 {
+			if err = batch.Commit(); err != nil {
+				return false
+			}
+			batch = dst.store.Batched()
+		}
+		return true
+	})
+	if errIterate != nil {
+		batch.Cancel()
+		return count, errIterate
+	}
+	if err != nil {
+		batch.Cancel()
+		return count, err
+	}
+	return count, batch.Commit()
+}
@@ -0,0 +1,58 @@
+package dbprovider
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnknownBackend(t *testing.T) {
+	_, err := NewDBProvider("leveldb", t.TempDir(), testutil.NewLogger(t))
+	require.Error(t, err)
+}
+
+func TestMigrate(t *testing.T) {
+	log := testutil.NewLogger(t)
+	src := NewInMemoryDBProvider(log)
+	dst, err := NewDBProvider(BackendInMemory, "", log)
+	require.NoError(t, err)
+
+	chainID := coretypes.ChainID{1, 2, 3}
+	for i := 0; i < migrateBatchSize+10; i++ {
+		err = src.GetPartition(&chainID).Set(MakeKey(ObjectTypeStateVariable, []byte{byte(i), byte(i >> 8)}), []byte{byte(i)})
+		require.NoError(t, err)
+	}
+	err = src.GetRegistryPartition().Set(MakeKey(ObjectTypeChainRecord, chainID[:]), []byte("record"))
+	require.NoError(t, err)
+
+	n, err := Migrate(src, dst)
+	require.NoError(t, err)
+	require.EqualValues(t, migrateBatchSize+11, n)
+
+	v, err := dst.GetPartition(&chainID).Get(MakeKey(ObjectTypeStateVariable, []byte{5, 0}))
+	require.NoError(t, err)
+	require.EqualValues(t, []byte{5}, v)
+	v, err = dst.GetRegistryPartition().Get(MakeKey(ObjectTypeChainRecord, chainID[:]))
+	require.NoError(t, err)
+	require.EqualValues(t, []byte("record"), v)
+}
+
+func TestRegisterBackend(t *testing.T) {
+	const name = "test-backend"
+	opened := ""
+	RegisterBackend(name, func(dbDir string) (Backend, error) {
+		opened = dbDir
+		return openBackend(BackendInMemory, "")
+	})
+	require.Contains(t, Backends, name)
+	require.Panics(t, func() {
+		RegisterBackend(name, nil)
+	})
+
+	dir := t.TempDir()
+	_, err := NewDBProvider(name, dir, testutil.NewLogger(t))
+	require.NoError(t, err)
+	require.EqualValues(t, dir, opened)
+}
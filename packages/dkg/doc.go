@@ -8,6 +8,9 @@
 //
 // Implementation is based on <https://github.com/dedis/kyber/blob/master/share/dkg/rabin/dkg.go>
 // which is based on <https://link.springer.com/article/10.1007/s00145-006-0347-3>.
+//
+// An existing key can be reshared to a new group of nodes, see Node.ReshareDistributedKey.
+// The resharing is based on <https://github.com/dedis/kyber/blob/master/share/dkg/pedersen/dkg.go>.
 package dkg
 
 // TODO: Only authenticated nodes can initiate (and participate in?) the DKG.
@@ -20,7 +20,9 @@ import (
 	"github.com/iotaledger/wasp/packages/util"
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/share"
+	pedersen_dkg "go.dedis.ch/kyber/v3/share/dkg/pedersen"
 	rabin_dkg "go.dedis.ch/kyber/v3/share/dkg/rabin"
+	pedersen_vss "go.dedis.ch/kyber/v3/share/vss/pedersen"
 	rabin_vss "go.dedis.ch/kyber/v3/share/vss/rabin"
 )
 
@@ -30,7 +32,8 @@ const (
 	//
 	// NOTE: initiatorInitMsgType must be unique across all the uses of peering package,
 	// because it is used to start new chain, thus chainID is not used for message recognition.
-	initiatorInitMsgType byte = peering.FirstUserMsgCode + 184 // Initiator -> Peer: init new DKG, reply with initiatorStatusMsgType.
+	initiatorInitMsgType    byte = peering.FirstUserMsgCode + 184 // Initiator -> Peer: init new DKG, reply with initiatorStatusMsgType.
+	initiatorReshareMsgType byte = peering.FirstUserMsgCode + 185 // Initiator -> Peer: init resharing of an existing key, reply with initiatorStatusMsgType.
 	//
 	// Initiator <-> Peer proc communication.
 	initiatorMsgBase         byte = peering.FirstUserMsgCode + 4 // 4 to align with round numbers.
@@ -54,11 +57,21 @@ const (
 	// in response to duplicated messages from other peers. They should be treated
 	// in a special way to avoid infinite message loops.
 	rabinEcho byte = peering.FirstUserMsgCode + 44
+	//
+	// Peer <-> Peer communication for the resharing protocol (Pedersen).
+	reshareMsgBase              byte = peering.FirstUserMsgCode + 54
+	reshareDealMsgType          byte = reshareMsgBase + 1
+	reshareResponseMsgType      byte = reshareMsgBase + 2
+	reshareJustificationMsgType byte = reshareMsgBase + 3
+	reshareMsgFree              byte = reshareMsgBase + 4 // Just a placeholder for first unallocated message type.
+	//
+	// Echoed messages of the resharing protocol, the same as rabinEcho.
+	reshareEcho byte = peering.FirstUserMsgCode + 64
 )
 
 // Checks if that's a Initiator -> PeerNode message.
 func isDkgInitNodeMsg(msgType byte) bool {
-	return msgType == initiatorInitMsgType
+	return msgType == initiatorInitMsgType || msgType == initiatorReshareMsgType
 }
 
 // Checks if that's a Initiator <-> PeerProc message.
@@ -81,22 +94,48 @@ func isDkgRabinEchoMsg(msgType byte) bool {
 	return rabinEcho <= msgType && msgType < rabinMsgFree-rabinMsgBase+rabinEcho
 }
 
+// Checks if that's a PeerProc <-> PeerProc message of the resharing protocol.
+func isDkgReshareRoundMsg(msgType byte) bool {
+	return reshareMsgBase <= msgType && msgType < reshareMsgFree
+}
+
+// Checks if that's a PeerProc <-> PeerProc echoed / repeated message of the resharing protocol.
+func isDkgReshareEchoMsg(msgType byte) bool {
+	return reshareEcho <= msgType && msgType < reshareMsgFree-reshareMsgBase+reshareEcho
+}
+
+// Checks if that's a PeerProc <-> PeerProc message of any of the protocols.
+func isDkgRoundMsg(msgType byte) bool {
+	return isDkgRabinRoundMsg(msgType) || isDkgReshareRoundMsg(msgType)
+}
+
+// Checks if that's a PeerProc <-> PeerProc echoed / repeated message of any of the protocols.
+func isDkgEchoMsg(msgType byte) bool {
+	return isDkgRabinEchoMsg(msgType) || isDkgReshareEchoMsg(msgType)
+}
+
 func makeDkgRoundEchoMsg(msgType byte) (byte, error) {
 	if isDkgRabinRoundMsg(msgType) {
 		return msgType - rabinMsgBase + rabinEcho, nil
 	}
-	if isDkgRabinEchoMsg(msgType) {
+	if isDkgReshareRoundMsg(msgType) {
+		return msgType - reshareMsgBase + reshareEcho, nil
+	}
+	if isDkgEchoMsg(msgType) {
 		return msgType, nil
 	}
 	return msgType, errors.New("round_msg_type_expected")
 }
 func makeDkgRoundMsg(msgType byte) (byte, error) {
-	if isDkgRabinRoundMsg(msgType) {
+	if isDkgRoundMsg(msgType) {
 		return msgType, nil
 	}
 	if isDkgRabinEchoMsg(msgType) {
 		return msgType - rabinEcho + rabinMsgBase, nil
 	}
+	if isDkgReshareEchoMsg(msgType) {
+		return msgType - reshareEcho + reshareMsgBase, nil
+	}
 	return msgType, errors.New("round_or_echo_msg_type_expected")
 }
 
@@ -140,6 +179,12 @@ func readInitiatorMsg(peerMessage *peering.PeerMessage, suite kyber.Group) (bool
 			return true, nil, err
 		}
 		return true, &msg, nil
+	case initiatorReshareMsgType:
+		msg := initiatorReshareMsg{}
+		if err := msg.fromBytes(peerMessage.MsgData, suite); err != nil {
+			return true, nil, err
+		}
+		return true, &msg, nil
 	case initiatorStepMsgType:
 		msg := initiatorStepMsg{}
 		if err := msg.fromBytes(peerMessage.MsgData, suite); err != nil {
@@ -282,6 +327,147 @@ func (m *initiatorInitMsg) IsResponse() bool {
 	return false
 }
 
+//
+// initiatorReshareMsg
+//
+// This is a message sent by the initiator to all the peers of the old
+// and the new groups to initiate the resharing of an existing key.
+//
+type initiatorReshareMsg struct {
+	step          byte
+	dkgRef        string // Some unique string to identify duplicate initialization.
+	sharedAddress *address.Address
+	publicCommits []kyber.Point // Commits of the existing key, needed by the new peers.
+	oldPeerNetIDs []string
+	oldPeerPubs   []kyber.Point
+	oldThreshold  uint16
+	newPeerNetIDs []string
+	newPeerPubs   []kyber.Point
+	threshold     uint16
+	initiatorPub  kyber.Point
+	timeout       time.Duration
+	roundRetry    time.Duration
+	suite         kyber.Group // Transient, for un-marshaling only.
+}
+
+func (m *initiatorReshareMsg) MsgType() byte {
+	return initiatorReshareMsgType
+}
+func (m *initiatorReshareMsg) Step() byte {
+	return m.step
+}
+func (m *initiatorReshareMsg) SetStep(step byte) {
+	m.step = step
+}
+func (m *initiatorReshareMsg) Write(w io.Writer) error {
+	var err error
+	if err = util.WriteByte(w, m.step); err != nil {
+		return err
+	}
+	if err = util.WriteString16(w, m.dkgRef); err != nil {
+		return err
+	}
+	if err = util.WriteBytes16(w, m.sharedAddress.Bytes()); err != nil {
+		return err
+	}
+	if err = writePoints(w, m.publicCommits); err != nil {
+		return err
+	}
+	if err = util.WriteStrings16(w, m.oldPeerNetIDs); err != nil {
+		return err
+	}
+	if err = writePoints(w, m.oldPeerPubs); err != nil {
+		return err
+	}
+	if err = util.WriteUint16(w, m.oldThreshold); err != nil {
+		return err
+	}
+	if err = util.WriteStrings16(w, m.newPeerNetIDs); err != nil {
+		return err
+	}
+	if err = writePoints(w, m.newPeerPubs); err != nil {
+		return err
+	}
+	if err = util.WriteUint16(w, m.threshold); err != nil {
+		return err
+	}
+	if err = util.WriteMarshaled(w, m.initiatorPub); err != nil {
+		return err
+	}
+	if err = util.WriteInt64(w, m.timeout.Milliseconds()); err != nil {
+		return err
+	}
+	if err = util.WriteInt64(w, m.roundRetry.Milliseconds()); err != nil {
+		return err
+	}
+	return nil
+}
+func (m *initiatorReshareMsg) Read(r io.Reader) error {
+	var err error
+	if m.step, err = util.ReadByte(r); err != nil {
+		return err
+	}
+	if m.dkgRef, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	var addrBytes []byte
+	if addrBytes, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	var addr address.Address
+	if addr, _, err = address.FromBytes(addrBytes); err != nil {
+		return err
+	}
+	m.sharedAddress = &addr
+	if m.publicCommits, err = readPoints(r, m.suite); err != nil {
+		return err
+	}
+	if m.oldPeerNetIDs, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
+	if m.oldPeerPubs, err = readPoints(r, m.suite); err != nil {
+		return err
+	}
+	if err = util.ReadUint16(r, &m.oldThreshold); err != nil {
+		return err
+	}
+	if m.newPeerNetIDs, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
+	if m.newPeerPubs, err = readPoints(r, m.suite); err != nil {
+		return err
+	}
+	if err = util.ReadUint16(r, &m.threshold); err != nil {
+		return err
+	}
+	m.initiatorPub = m.suite.Point()
+	if err = util.ReadMarshaled(r, m.initiatorPub); err != nil {
+		return err
+	}
+	var timeoutMS int64
+	if err = util.ReadInt64(r, &timeoutMS); err != nil {
+		return err
+	}
+	m.timeout = time.Duration(timeoutMS) * time.Millisecond
+	var roundRetryMS int64
+	if err = util.ReadInt64(r, &roundRetryMS); err != nil {
+		return err
+	}
+	m.roundRetry = time.Duration(roundRetryMS) * time.Millisecond
+	return nil
+}
+func (m *initiatorReshareMsg) fromBytes(buf []byte, group kyber.Group) error {
+	r := bytes.NewReader(buf)
+	m.suite = group
+	return m.Read(r)
+}
+func (m *initiatorReshareMsg) Error() error {
+	return nil
+}
+func (m *initiatorReshareMsg) IsResponse() bool {
+	return false
+}
+
 //
 // initiatorStepMsg
 //
@@ -1120,3 +1306,351 @@ func readVssDeal(r io.Reader, d **rabin_vss.Deal, group kyber.Group) error {
 	*d = &dd
 	return nil
 }
+
+//
+//	pedersen_dkg.Deal
+//
+// The deal is nil, if the sender has no deal for the receiver,
+// because all the peers exchange messages in each step.
+//
+type reshareDealMsg struct {
+	step byte
+	deal *pedersen_dkg.Deal
+}
+
+func (m *reshareDealMsg) MsgType() byte {
+	return reshareDealMsgType
+}
+func (m *reshareDealMsg) Step() byte {
+	return m.step
+}
+func (m *reshareDealMsg) SetStep(step byte) {
+	m.step = step
+}
+func (m *reshareDealMsg) Write(w io.Writer) error {
+	var err error
+	if err = util.WriteByte(w, m.step); err != nil {
+		return err
+	}
+	if err = util.WriteBoolByte(w, m.deal == nil); err != nil {
+		return err
+	}
+	if m.deal == nil {
+		return nil
+	}
+	if err = util.WriteUint32(w, m.deal.Index); err != nil {
+		return err
+	}
+	if err = util.WriteMarshaled(w, m.deal.Deal.DHKey); err != nil {
+		return err
+	}
+	if err = util.WriteBytes16(w, m.deal.Deal.Signature); err != nil {
+		return err
+	}
+	if err = util.WriteBytes16(w, m.deal.Deal.Nonce); err != nil {
+		return err
+	}
+	if err = util.WriteBytes16(w, m.deal.Deal.Cipher); err != nil {
+		return err
+	}
+	if err = util.WriteBytes16(w, m.deal.Signature); err != nil {
+		return err
+	}
+	return nil
+}
+func (m *reshareDealMsg) Read(r io.Reader) error {
+	var err error
+	if m.step, err = util.ReadByte(r); err != nil {
+		return err
+	}
+	var dealNil bool
+	if err = util.ReadBoolByte(r, &dealNil); err != nil {
+		return err
+	}
+	if dealNil {
+		m.deal = nil
+		return nil
+	}
+	if err = util.ReadUint32(r, &m.deal.Index); err != nil {
+		return err
+	}
+	if err = util.ReadMarshaled(r, m.deal.Deal.DHKey); err != nil {
+		return err
+	}
+	if m.deal.Deal.Signature, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if m.deal.Deal.Nonce, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if m.deal.Deal.Cipher, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if m.deal.Signature, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	return nil
+}
+func (m *reshareDealMsg) fromBytes(buf []byte, group kyber.Group) error {
+	m.deal = &pedersen_dkg.Deal{
+		Deal: &pedersen_vss.EncryptedDeal{
+			DHKey: group.Point(),
+		},
+	}
+	rdr := bytes.NewReader(buf)
+	return m.Read(rdr)
+}
+
+//
+//	pedersen_dkg.Response
+//
+type reshareResponseMsg struct {
+	step      byte
+	responses []*pedersen_dkg.Response
+}
+
+func (m *reshareResponseMsg) MsgType() byte {
+	return reshareResponseMsgType
+}
+func (m *reshareResponseMsg) Step() byte {
+	return m.step
+}
+func (m *reshareResponseMsg) SetStep(step byte) {
+	m.step = step
+}
+func (m *reshareResponseMsg) Write(w io.Writer) error {
+	var err error
+	if err = util.WriteByte(w, m.step); err != nil {
+		return err
+	}
+	listLen := uint32(len(m.responses))
+	if err = util.WriteUint32(w, listLen); err != nil {
+		return err
+	}
+	for _, r := range m.responses {
+		if err = util.WriteUint32(w, r.Index); err != nil {
+			return err
+		}
+		if err = util.WriteBytes16(w, r.Response.SessionID); err != nil {
+			return err
+		}
+		if err = util.WriteUint32(w, r.Response.Index); err != nil {
+			return err
+		}
+		if err = util.WriteBoolByte(w, r.Response.Status); err != nil {
+			return err
+		}
+		if err = util.WriteBytes16(w, r.Response.Signature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (m *reshareResponseMsg) Read(r io.Reader) error {
+	var err error
+	if m.step, err = util.ReadByte(r); err != nil {
+		return err
+	}
+	var listLen uint32
+	if err = util.ReadUint32(r, &listLen); err != nil {
+		return err
+	}
+	m.responses = make([]*pedersen_dkg.Response, int(listLen))
+	for i := range m.responses {
+		response := pedersen_dkg.Response{
+			Response: &pedersen_vss.Response{},
+		}
+		m.responses[i] = &response
+		if err = util.ReadUint32(r, &response.Index); err != nil {
+			return err
+		}
+		if response.Response.SessionID, err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+		if err = util.ReadUint32(r, &response.Response.Index); err != nil {
+			return err
+		}
+		if err = util.ReadBoolByte(r, &response.Response.Status); err != nil {
+			return err
+		}
+		if response.Response.Signature, err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (m *reshareResponseMsg) fromBytes(buf []byte) error {
+	rdr := bytes.NewReader(buf)
+	return m.Read(rdr)
+}
+
+//
+//	pedersen_dkg.Justification
+//
+type reshareJustificationMsg struct {
+	step           byte
+	justifications []*pedersen_dkg.Justification
+	group          kyber.Group // Just for un-marshaling.
+}
+
+func (m *reshareJustificationMsg) MsgType() byte {
+	return reshareJustificationMsgType
+}
+func (m *reshareJustificationMsg) Step() byte {
+	return m.step
+}
+func (m *reshareJustificationMsg) SetStep(step byte) {
+	m.step = step
+}
+func (m *reshareJustificationMsg) Write(w io.Writer) error {
+	var err error
+	if err = util.WriteByte(w, m.step); err != nil {
+		return err
+	}
+	jLen := uint32(len(m.justifications))
+	if err = util.WriteUint32(w, jLen); err != nil {
+		return err
+	}
+	for _, j := range m.justifications {
+		if err = util.WriteUint32(w, j.Index); err != nil {
+			return err
+		}
+		if err = util.WriteBytes16(w, j.Justification.SessionID); err != nil {
+			return err
+		}
+		if err = util.WriteUint32(w, j.Justification.Index); err != nil {
+			return err
+		}
+		if err = writePedersenVssDeal(w, j.Justification.Deal); err != nil {
+			return err
+		}
+		if err = util.WriteBytes16(w, j.Justification.Signature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (m *reshareJustificationMsg) Read(r io.Reader) error {
+	var err error
+	if m.step, err = util.ReadByte(r); err != nil {
+		return err
+	}
+	var jLen uint32
+	if err = util.ReadUint32(r, &jLen); err != nil {
+		return err
+	}
+	m.justifications = make([]*pedersen_dkg.Justification, int(jLen))
+	for i := range m.justifications {
+		j := pedersen_dkg.Justification{
+			Justification: &pedersen_vss.Justification{},
+		}
+		m.justifications[i] = &j
+		if err = util.ReadUint32(r, &j.Index); err != nil {
+			return err
+		}
+		if j.Justification.SessionID, err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+		if err = util.ReadUint32(r, &j.Justification.Index); err != nil {
+			return err
+		}
+		if err = readPedersenVssDeal(r, &j.Justification.Deal, m.group); err != nil {
+			return err
+		}
+		if j.Justification.Signature, err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (m *reshareJustificationMsg) fromBytes(buf []byte, group kyber.Group) error {
+	m.group = group
+	rdr := bytes.NewReader(buf)
+	return m.Read(rdr)
+}
+
+//
+// type pedersen_vss.Deal struct {
+// 	SessionID []byte			// Unique session identifier for this protocol run
+// 	SecShare *share.PriShare	// Private share generated by the dealer
+// 	T uint32					// Threshold used for this secret sharing run
+// 	Commitments []kyber.Point	// Commitments are the coefficients used to verify the shares against
+// }
+//
+func writePedersenVssDeal(w io.Writer, d *pedersen_vss.Deal) error {
+	var err error
+	if err = util.WriteBytes16(w, d.SessionID); err != nil {
+		return err
+	}
+	if err = writePriShare(w, d.SecShare); err != nil {
+		return err
+	}
+	if err = util.WriteUint32(w, d.T); err != nil {
+		return err
+	}
+	if err = util.WriteUint32(w, uint32(len(d.Commitments))); err != nil {
+		return err
+	}
+	for i := range d.Commitments {
+		if err = util.WriteMarshaled(w, d.Commitments[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func readPedersenVssDeal(r io.Reader, d **pedersen_vss.Deal, group kyber.Group) error {
+	var err error
+	dd := pedersen_vss.Deal{
+		SecShare: &share.PriShare{V: group.Scalar()}, // The share is never nil in a deal.
+	}
+	if dd.SessionID, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	if err = readPriShare(r, &dd.SecShare); err != nil {
+		return err
+	}
+	if err = util.ReadUint32(r, &dd.T); err != nil {
+		return err
+	}
+	var commitmentCount uint32
+	if err = util.ReadUint32(r, &commitmentCount); err != nil {
+		return err
+	}
+	dd.Commitments = make([]kyber.Point, int(commitmentCount))
+	for i := range dd.Commitments {
+		dd.Commitments[i] = group.Point()
+		if err = util.ReadMarshaled(r, dd.Commitments[i]); err != nil {
+			return err
+		}
+	}
+	*d = &dd
+	return nil
+}
+
+func writePoints(w io.Writer, points []kyber.Point) error {
+	var err error
+	if err = util.WriteUint16(w, uint16(len(points))); err != nil {
+		return err
+	}
+	for i := range points {
+		if err = util.WriteMarshaled(w, points[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func readPoints(r io.Reader, group kyber.Group) ([]kyber.Point, error) {
+	var err error
+	var arrLen uint16
+	if err = util.ReadUint16(r, &arrLen); err != nil {
+		return nil, err
+	}
+	points := make([]kyber.Point, arrLen)
+	for i := range points {
+		points[i] = group.Point()
+		if err = util.ReadMarshaled(r, points[i]); err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/peering"
@@ -23,6 +24,7 @@ import (
 type Node struct {
 	secKey      kyber.Scalar
 	pubKey      kyber.Point
+	keyLock     *sync.RWMutex            // The identity is replaced by SetIdentity.
 	suite       Suite                    // Cryptography to use.
 	netProvider peering.NetworkProvider  // Network to communicate through.
 	registry    tcrypto.RegistryProvider // Where to store the generated keys.
@@ -47,6 +49,7 @@ func NewNode(
 	n := Node{
 		secKey:      secKey,
 		pubKey:      pubKey,
+		keyLock:     &sync.RWMutex{},
 		suite:       suite,
 		netProvider: netProvider,
 		registry:    registry,
@@ -63,6 +66,21 @@ func NewNode(
 	return &n
 }
 
+// SetIdentity replaces the identity key pair of the node, after the peering identity is rotated.
+// The procedures already running keep using the previous key.
+func (n *Node) SetIdentity(secKey kyber.Scalar, pubKey kyber.Point) {
+	n.keyLock.Lock()
+	defer n.keyLock.Unlock()
+	n.secKey = secKey
+	n.pubKey = pubKey
+}
+
+func (n *Node) identity() (kyber.Scalar, kyber.Point) {
+	n.keyLock.RLock()
+	defer n.keyLock.RUnlock()
+	return n.secKey, n.pubKey
+}
+
 func (n *Node) Close() {
 	close(n.recvStopCh)
 	close(n.recvQueue)
@@ -100,6 +118,7 @@ func (n *Node) GenerateDistributedKey(
 	}
 	defer netGroup.Close()
 	dkgID := coretypes.NewRandomChainID()
+	_, initiatorPub := n.identity()
 	recvCh := make(chan *peering.RecvEvent, peerCount*2)
 	attachID := n.netProvider.Attach(&dkgID, func(recv *peering.RecvEvent) {
 		recvCh <- recv
@@ -128,7 +147,7 @@ func (n *Node) GenerateDistributedKey(
 				dkgRef:       dkgID.String(), // It could be some other identifier.
 				peerNetIDs:   peerNetIDs,
 				peerPubs:     peerPubs,
-				initiatorPub: n.pubKey,
+				initiatorPub: initiatorPub,
 				threshold:    threshold,
 				timeout:      timeout,
 				roundRetry:   roundRetry,
@@ -179,32 +198,11 @@ func (n *Node) GenerateDistributedKey(
 	); err != nil {
 		return nil, err
 	}
-	sharedAddress := pubShareResponses[0].sharedAddress
-	sharedPublic := pubShareResponses[0].sharedPublic
-	publicShares := make([]kyber.Point, peerCount)
-	for i := range pubShareResponses {
-		if *sharedAddress != *pubShareResponses[i].sharedAddress {
-			return nil, fmt.Errorf("nodes generated different addresses")
-		}
-		if !sharedPublic.Equal(pubShareResponses[i].sharedPublic) {
-			return nil, fmt.Errorf("nodes generated different shared public keys")
-		}
-		publicShares[i] = pubShareResponses[i].publicShare
-		{
-			var pubShareBytes []byte
-			if pubShareBytes, err = pubShareResponses[i].publicShare.MarshalBinary(); err != nil {
-				return nil, err
-			}
-			err = bdn.Verify(
-				n.suite,
-				pubShareResponses[i].publicShare,
-				pubShareBytes,
-				pubShareResponses[i].signature,
-			)
-			if err != nil {
-				return nil, err
-			}
-		}
+	var sharedAddress *address.Address
+	var sharedPublic kyber.Point
+	var publicShares []kyber.Point
+	if sharedAddress, sharedPublic, publicShares, err = n.checkPubShares(pubShareResponses, peerCount); err != nil {
+		return nil, err
 	}
 	n.log.Debugf("Generated SharedAddress=%v, SharedPublic=%v", sharedAddress, sharedPublic)
 	//
@@ -232,6 +230,43 @@ func (n *Node) GenerateDistributedKey(
 	return &dkShare, nil
 }
 
+// checkPubShares checks, if all the peers responded with the same shared public key,
+// and their public shares are signed by the corresponding private shares.
+func (n *Node) checkPubShares(
+	pubShareResponses map[int]*initiatorPubShareMsg,
+	peerCount uint16,
+) (*address.Address, kyber.Point, []kyber.Point, error) {
+	var err error
+	sharedAddress := pubShareResponses[0].sharedAddress
+	sharedPublic := pubShareResponses[0].sharedPublic
+	publicShares := make([]kyber.Point, peerCount)
+	for i := range pubShareResponses {
+		if *sharedAddress != *pubShareResponses[i].sharedAddress {
+			return nil, nil, nil, fmt.Errorf("nodes generated different addresses")
+		}
+		if !sharedPublic.Equal(pubShareResponses[i].sharedPublic) {
+			return nil, nil, nil, fmt.Errorf("nodes generated different shared public keys")
+		}
+		publicShares[i] = pubShareResponses[i].publicShare
+		{
+			var pubShareBytes []byte
+			if pubShareBytes, err = pubShareResponses[i].publicShare.MarshalBinary(); err != nil {
+				return nil, nil, nil, err
+			}
+			err = bdn.Verify(
+				n.suite,
+				pubShareResponses[i].publicShare,
+				pubShareBytes,
+				pubShareResponses[i].signature,
+			)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	return sharedAddress, sharedPublic, publicShares, nil
+}
+
 // GroupSuite returns the cryptography Group used by this node.
 func (n *Node) GroupSuite() kyber.Group {
 	return n.suite
@@ -253,21 +288,36 @@ func (n *Node) recvLoop() {
 
 // onInitMsg is a callback to handle the DKG initialization messages.
 func (n *Node) onInitMsg(recv *peering.RecvEvent) {
-	if recv.Msg.MsgType != initiatorInitMsgType {
-		return
-	}
-	var err error
-	var p *proc
-	req := initiatorInitMsg{}
-	if err = req.fromBytes(recv.Msg.MsgData, n.suite); err != nil {
-		n.log.Warnf("Dropping unknown message: %v", recv)
+	switch recv.Msg.MsgType {
+	case initiatorInitMsgType:
+		req := initiatorInitMsg{}
+		if err := req.fromBytes(recv.Msg.MsgData, n.suite); err != nil {
+			n.log.Warnf("Dropping unknown message: %v", recv)
+			return
+		}
+		n.startProc(recv, req.dkgRef, req.step, func() (*proc, error) {
+			return onInitiatorInit(&recv.Msg.ChainID, &req, n)
+		})
+	case initiatorReshareMsgType:
+		req := initiatorReshareMsg{}
+		if err := req.fromBytes(recv.Msg.MsgData, n.suite); err != nil {
+			n.log.Warnf("Dropping unknown message: %v", recv)
+			return
+		}
+		n.startProc(recv, req.dkgRef, req.step, func() (*proc, error) {
+			return onInitiatorReshare(&recv.Msg.ChainID, &req, n)
+		})
 	}
+}
+
+// startProc creates the DKG process for the initialization message, if it is not created yet.
+func (n *Node) startProc(recv *peering.RecvEvent, dkgRef string, step byte, create func() (*proc, error)) {
 	n.procLock.RLock()
-	if _, ok := n.processes[req.dkgRef]; ok {
+	if _, ok := n.processes[dkgRef]; ok {
 		// To have idempotence for retries, we need to consider duplicate
 		// messages as success, if process is already created.
 		n.procLock.RUnlock()
-		recv.From.SendMsg(makePeerMessage(&recv.Msg.ChainID, req.step, &initiatorStatusMsg{
+		recv.From.SendMsg(makePeerMessage(&recv.Msg.ChainID, step, &initiatorStatusMsg{
 			error: nil,
 		}))
 		return
@@ -276,12 +326,14 @@ func (n *Node) onInitMsg(recv *peering.RecvEvent) {
 	go func() {
 		// This part should be executed async, because it accesses the network again, and can
 		// be locked because of the naive implementation of `events.Event`. It locks on all the callbacks.
+		var err error
+		var p *proc
 		n.procLock.Lock()
-		if p, err = onInitiatorInit(&recv.Msg.ChainID, &req, n); err == nil {
+		if p, err = create(); err == nil {
 			n.processes[p.dkgRef] = p
 		}
 		n.procLock.Unlock()
-		recv.From.SendMsg(makePeerMessage(&recv.Msg.ChainID, req.step, &initiatorStatusMsg{
+		recv.From.SendMsg(makePeerMessage(&recv.Msg.ChainID, step, &initiatorStatusMsg{
 			error: err,
 		}))
 	}()
@@ -12,9 +12,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/dkg"
 	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/iotaledger/wasp/packages/testutil"
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/kyber/v3"
@@ -233,3 +235,100 @@ func TestLowN(t *testing.T) {
 		require.NotNil(t, dkShare.SharedPublic)
 	}
 }
+
+// TestReshare checks, if the key is reshared to the new group of peers
+// keeping the same address, and the new shares produce valid signatures.
+func TestReshare(t *testing.T) {
+	log := testutil.NewLogger(t)
+	defer log.Sync()
+	//
+	// Create a fake network and keys for the tests.
+	var timeout = 100 * time.Second
+	var peerCount uint16 = 6
+	var peerNetIDs []string = make([]string, peerCount)
+	var peerPubs []kyber.Point = make([]kyber.Point, len(peerNetIDs))
+	var peerSecs []kyber.Scalar = make([]kyber.Scalar, len(peerNetIDs))
+	var suite = pairing.NewSuiteBn256() // NOTE: That's from the Pairing Adapter.
+	for i := range peerNetIDs {
+		peerPair := key.NewKeyPair(suite)
+		peerNetIDs[i] = fmt.Sprintf("P%02d", i)
+		peerSecs[i] = peerPair.Private
+		peerPubs[i] = peerPair.Public
+	}
+	var peeringNetwork *testutil.PeeringNetwork = testutil.NewPeeringNetwork(
+		peerNetIDs, peerPubs, peerSecs, 10000,
+		testutil.NewPeeringNetReliable(),
+		testutil.WithLevel(log, logger.LevelWarn, false),
+	)
+	var networkProviders []peering.NetworkProvider = peeringNetwork.NetworkProviders()
+	//
+	// Initialize the DKG subsystem in each node.
+	var dkgNodes []*dkg.Node = make([]*dkg.Node, len(peerNetIDs))
+	var registries []*testutil.DkgRegistryProvider = make([]*testutil.DkgRegistryProvider, len(peerNetIDs))
+	for i := range peerNetIDs {
+		registries[i] = testutil.NewDkgRegistryProvider(suite)
+		dkgNodes[i] = dkg.NewNode(
+			peerSecs[i], peerPubs[i], suite, networkProviders[i], registries[i],
+			testutil.WithLevel(log.With("NetID", peerNetIDs[i]), logger.LevelDebug, false),
+		)
+	}
+	//
+	// Generate the key for the first 4 peers, then reshare it to the last 5 peers.
+	dkShare, err := dkgNodes[0].GenerateDistributedKey(
+		peerNetIDs[:4],
+		peerPubs[:4],
+		3,
+		1*time.Second,
+		2*time.Second,
+		timeout,
+	)
+	require.Nil(t, err)
+	reshared, err := dkgNodes[1].ReshareDistributedKey(
+		dkShare.Address,
+		peerNetIDs[:4],
+		nil, // NOTE: Should be taken from the peering node.
+		peerNetIDs[1:],
+		nil,
+		4,
+		1*time.Second,
+		2*time.Second,
+		timeout,
+	)
+	require.Nil(t, err)
+	require.EqualValues(t, *dkShare.Address, *reshared.Address)
+	require.True(t, dkShare.SharedPublic.Equal(reshared.SharedPublic))
+	require.EqualValues(t, 5, reshared.N)
+	require.EqualValues(t, 4, reshared.T)
+	//
+	// Any 4 of the new shares produce the signature of the key.
+	data := []byte("the committee is rotated")
+	sigShares := make([][]byte, 0)
+	var newShare *tcrypto.DKShare
+	for i := 2; i < len(peerNetIDs); i++ {
+		newShare, err = registries[i].LoadDKShare(dkShare.Address)
+		require.Nil(t, err)
+		require.EqualValues(t, i-1, *newShare.Index)
+		sigShare, err := newShare.SignShare(data)
+		require.Nil(t, err)
+		require.Nil(t, newShare.VerifySigShare(data, sigShare))
+		sigShares = append(sigShares, sigShare)
+	}
+	signature, err := newShare.RecoverMasterSignature(sigShares, data)
+	require.Nil(t, err)
+	require.Nil(t, newShare.VerifyMasterSignature(data, signature))
+	//
+	// The initiator can't reshare the key it holds no share of.
+	_, err = dkgNodes[5].ReshareDistributedKey(
+		&address.Address{1, 2, 3},
+		peerNetIDs[1:],
+		nil,
+		peerNetIDs[:4],
+		nil,
+		3,
+		1*time.Second,
+		2*time.Second,
+		timeout,
+	)
+	require.Error(t, err)
+	require.IsType(t, dkg.InvalidParamsError{}, err)
+}
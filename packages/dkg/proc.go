@@ -18,6 +18,7 @@ import (
 	"github.com/iotaledger/wasp/packages/tcrypto"
 	"github.com/mr-tron/base58"
 	"go.dedis.ch/kyber/v3"
+	pedersen_dkg "go.dedis.ch/kyber/v3/share/dkg/pedersen"
 	rabin_dkg "go.dedis.ch/kyber/v3/share/dkg/rabin"
 	"go.dedis.ch/kyber/v3/sign/bdn"
 	"go.dedis.ch/kyber/v3/util/key"
@@ -45,15 +46,19 @@ type proc struct {
 	nodeIndex    uint16             // Index of this node.
 	initiatorPub kyber.Point
 	threshold    uint16
-	roundRetry   time.Duration               // Retry period for the Peer <-> Peer communication.
-	netGroup     peering.GroupProvider       // A group for which the distributed key is generated.
-	dkgImpl      *rabin_dkg.DistKeyGenerator // The cryptographic implementation to use.
-	dkgLock      *sync.RWMutex               // Guard access to dkgImpl
-	attachID     interface{}                 // We keep it here to be able to detach from the network.
-	peerMsgCh    chan *peering.RecvEvent     // A buffer for the received peer messages.
-	log          *logger.Logger              // A logger to use.
-	myNetID      string                      // Just to make logging easier.
-	steps        map[byte]*procStep          // All the steps for the procedure.
+	roundRetry   time.Duration                  // Retry period for the Peer <-> Peer communication.
+	netGroup     peering.GroupProvider          // A group for which the distributed key is generated.
+	dkgImpl      *rabin_dkg.DistKeyGenerator    // The cryptographic implementation to use.
+	reshareImpl  *pedersen_dkg.DistKeyGenerator // Used instead of dkgImpl, if an existing key is reshared.
+	reshareOld   bool                           // If this node holds a share of the reshared key.
+	reshareNew   bool                           // If this node receives a share of the reshared key.
+	reshareN     uint16                         // Number of peers receiving a share of the reshared key.
+	dkgLock      *sync.RWMutex                  // Guard access to dkgImpl and reshareImpl.
+	attachID     interface{}                    // We keep it here to be able to detach from the network.
+	peerMsgCh    chan *peering.RecvEvent        // A buffer for the received peer messages.
+	log          *logger.Logger                 // A logger to use.
+	myNetID      string                         // Just to make logging easier.
+	steps        map[byte]*procStep             // All the steps for the procedure.
 }
 
 func onInitiatorInit(dkgID *coretypes.ChainID, msg *initiatorInitMsg, node *Node) (*proc, error) {
@@ -67,7 +72,8 @@ func onInitiatorInit(dkgID *coretypes.ChainID, msg *initiatorInitMsg, node *Node
 	var dkgImpl *rabin_dkg.DistKeyGenerator
 	if len(msg.peerPubs) >= 2 {
 		// We use real DKG only if N >= 2. Otherwise we just generate key pair, and that's all.
-		if dkgImpl, err = rabin_dkg.NewDistKeyGenerator(node.suite, node.secKey, msg.peerPubs, int(msg.threshold)); err != nil {
+		secKey, _ := node.identity()
+		if dkgImpl, err = rabin_dkg.NewDistKeyGenerator(node.suite, secKey, msg.peerPubs, int(msg.threshold)); err != nil {
 			return nil, err
 		}
 	}
@@ -160,7 +166,7 @@ func (p *proc) processLoop(timeout time.Duration, doneCh chan map[uint16]*peerin
 	for {
 		select {
 		case recv := <-p.peerMsgCh:
-			if isDkgInitProcRecvMsg(recv.Msg.MsgType) || isDkgRabinRoundMsg(recv.Msg.MsgType) || isDkgRabinEchoMsg(recv.Msg.MsgType) {
+			if isDkgInitProcRecvMsg(recv.Msg.MsgType) || isDkgRoundMsg(recv.Msg.MsgType) || isDkgEchoMsg(recv.Msg.MsgType) {
 				step := readDkgMessageStep(recv.Msg.MsgData)
 				if s := p.steps[step]; s != nil {
 					s.recv(recv)
@@ -715,11 +721,11 @@ func (s *procStep) run() {
 					recv.From.SendMsg(s.initResp)
 					continue
 				}
-				if isDkgRabinEchoMsg(recv.Msg.MsgType) {
+				if isDkgEchoMsg(recv.Msg.MsgType) {
 					// Do not respond to echo messages, a resend loop will be initiated otherwise.
 					continue
 				}
-				if isDkgRabinRoundMsg(recv.Msg.MsgType) {
+				if isDkgRoundMsg(recv.Msg.MsgType) {
 					// Resend the peer messages as echo messages, because we don't need the responses anymore.
 					s.sendEcho(recv)
 					continue
@@ -749,12 +755,12 @@ func (s *procStep) run() {
 				})
 				continue
 			}
-			if isDkgRabinRoundMsg(recv.Msg.MsgType) || isDkgRabinEchoMsg(recv.Msg.MsgType) {
+			if isDkgRoundMsg(recv.Msg.MsgType) || isDkgEchoMsg(recv.Msg.MsgType) {
 				// in the current step we consider echo messages as ordinary round messages,
 				// because it is possible that we have requested for them.
 				if s.recvMsgs[recv.Msg.SenderIndex] == nil {
 					s.recvMsgs[recv.Msg.SenderIndex] = recv.Msg
-				} else if s.sentMsgs != nil && isDkgRabinRoundMsg(recv.Msg.MsgType) {
+				} else if s.sentMsgs != nil && isDkgRoundMsg(recv.Msg.MsgType) {
 					// If that's a repeated message from the peer, maybe our message has been
 					// lost, so we repeat it as an echo, to avoid resend loops.
 					s.sendEcho(recv)
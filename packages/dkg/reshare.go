@@ -0,0 +1,515 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package dkg
+
+//
+// This file contains the resharing of an existing distributed key. The shared
+// public key (and the address) stays the same, only the private shares are
+// replaced by the new ones, held by the new group of peers. The old and the new
+// groups can overlap. The procedure is based on the resharing protocol of
+// <https://github.com/dedis/kyber/blob/master/share/dkg/pedersen/dkg.go>.
+//
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+	pedersen_dkg "go.dedis.ch/kyber/v3/share/dkg/pedersen"
+)
+
+const (
+	reshareStep0Initialize         = byte(0)
+	reshareStep1SendDeals          = byte(1)
+	reshareStep2SendResponses      = byte(2)
+	reshareStep3SendJustifications = byte(3)
+	reshareStep4ReturnPubShare     = byte(4)
+	reshareStep5CommitAndTerminate = byte(5)
+)
+
+// ReshareDistributedKey distributes new shares of the existing key among the new group of peers.
+// This function is executed on the DKG initiator node, which must hold a share of the key.
+// The old peers must be listed in the order of their share indexes. The public keys of the peers
+// are taken from the peering network, if they are not specified.
+func (n *Node) ReshareDistributedKey(
+	sharedAddress *address.Address,
+	oldPeerNetIDs []string,
+	oldPeerPubs []kyber.Point,
+	newPeerNetIDs []string,
+	newPeerPubs []kyber.Point,
+	threshold uint16,
+	roundRetry time.Duration, // Retry for Peer <-> Peer communication.
+	stepRetry time.Duration, // Retry for Initiator -> Peer communication.
+	timeout time.Duration, // Timeout for the entire procedure.
+) (*tcrypto.DKShare, error) {
+	n.log.Infof(
+		"Starting new DKG resharing procedure, initiator=%v, address=%v, oldPeers=%+v, newPeers=%+v",
+		n.netProvider.Self().NetID(), sharedAddress, oldPeerNetIDs, newPeerNetIDs,
+	)
+	var err error
+	var oldShare *tcrypto.DKShare
+	if oldShare, err = n.registry.LoadDKShare(sharedAddress); err != nil {
+		return nil, invalidParams(fmt.Errorf("the initiator has no share of the key %v: %v", sharedAddress, err))
+	}
+	var newPeerCount = uint16(len(newPeerNetIDs))
+	//
+	// Some validation for the parameters.
+	if oldShare.N < 2 {
+		return nil, invalidParams(errors.New("a key of a single node cannot be reshared"))
+	}
+	if uint16(len(oldPeerNetIDs)) != oldShare.N {
+		return nil, invalidParams(fmt.Errorf("the key is shared by %d peers, %d old peers specified", oldShare.N, len(oldPeerNetIDs)))
+	}
+	if newPeerCount < 2 || threshold < 2 || threshold > newPeerCount {
+		return nil, invalidParams(fmt.Errorf("wrong resharing parameters: N = %d, T = %d", newPeerCount, threshold))
+	}
+	if threshold < newPeerCount/2+1 {
+		// Quorum t must be larger than half size in order to avoid more than one valid quorum in committee.
+		return nil, invalidParams(fmt.Errorf("wrong resharing parameters: for N = %d value T must be at least %d", newPeerCount, newPeerCount/2+1))
+	}
+	if oldPeerPubs != nil && len(oldPeerPubs) != len(oldPeerNetIDs) || newPeerPubs != nil && len(newPeerPubs) != len(newPeerNetIDs) {
+		return nil, invalidParams(errors.New("inconsistent peer NetIDs and public keys"))
+	}
+	//
+	// Setup network connections.
+	var netGroup peering.GroupProvider
+	if netGroup, err = n.netProvider.Group(reshareGroupNetIDs(oldPeerNetIDs, newPeerNetIDs)); err != nil {
+		return nil, err
+	}
+	defer netGroup.Close()
+	dkgID := coretypes.NewRandomChainID()
+	_, initiatorPub := n.identity()
+	recvCh := make(chan *peering.RecvEvent, len(netGroup.AllNodes())*2)
+	attachID := n.netProvider.Attach(&dkgID, func(recv *peering.RecvEvent) {
+		recvCh <- recv
+	})
+	defer n.netProvider.Detach(attachID)
+	rTimeout := stepRetry
+	gTimeout := timeout
+	if oldPeerPubs == nil {
+		if oldPeerPubs, err = resolvePeerPubs(netGroup, oldPeerNetIDs, timeout); err != nil {
+			return nil, err
+		}
+	}
+	if newPeerPubs == nil {
+		if newPeerPubs, err = resolvePeerPubs(netGroup, newPeerNetIDs, timeout); err != nil {
+			return nil, err
+		}
+	}
+	// The new peers go first in the group, so their indexes in the group are the indexes of the new shares.
+	newPeers := make(map[uint16]peering.PeerSender)
+	for i, peer := range netGroup.AllNodes() {
+		if i < newPeerCount {
+			newPeers[i] = peer
+		}
+	}
+	//
+	// Initialize the peers.
+	if err = n.exchangeInitiatorAcks(netGroup, netGroup.AllNodes(), recvCh, rTimeout, gTimeout, reshareStep0Initialize,
+		func(peerIdx uint16, peer peering.PeerSender) {
+			n.log.Debugf("Initiator sends step=%v command to %v", reshareStep0Initialize, peer.NetID())
+			peer.SendMsg(makePeerMessage(&dkgID, reshareStep0Initialize, &initiatorReshareMsg{
+				dkgRef:        dkgID.String(), // It could be some other identifier.
+				sharedAddress: sharedAddress,
+				publicCommits: oldShare.PublicCommits,
+				oldPeerNetIDs: oldPeerNetIDs,
+				oldPeerPubs:   oldPeerPubs,
+				oldThreshold:  oldShare.T,
+				newPeerNetIDs: newPeerNetIDs,
+				newPeerPubs:   newPeerPubs,
+				threshold:     threshold,
+				initiatorPub:  initiatorPub,
+				timeout:       timeout,
+				roundRetry:    roundRetry,
+			}))
+		},
+	); err != nil {
+		return nil, err
+	}
+	//
+	// Perform the resharing steps, each step in parallel, all steps sequentially.
+	if err = n.exchangeInitiatorStep(netGroup, netGroup.AllNodes(), recvCh, rTimeout, gTimeout, &dkgID, reshareStep1SendDeals); err != nil {
+		return nil, err
+	}
+	if err = n.exchangeInitiatorStep(netGroup, netGroup.AllNodes(), recvCh, rTimeout, gTimeout, &dkgID, reshareStep2SendResponses); err != nil {
+		return nil, err
+	}
+	if err = n.exchangeInitiatorStep(netGroup, netGroup.AllNodes(), recvCh, rTimeout, gTimeout, &dkgID, reshareStep3SendJustifications); err != nil {
+		return nil, err
+	}
+	//
+	// Now get the public keys from the new peers, the old ones are done.
+	pubShareResponses := map[int]*initiatorPubShareMsg{}
+	if err = n.exchangeInitiatorMsgs(netGroup, newPeers, recvCh, rTimeout, gTimeout, reshareStep4ReturnPubShare,
+		func(peerIdx uint16, peer peering.PeerSender) {
+			n.log.Debugf("Initiator sends step=%v command to %v", reshareStep4ReturnPubShare, peer.NetID())
+			peer.SendMsg(makePeerMessage(&dkgID, reshareStep4ReturnPubShare, &initiatorStepMsg{}))
+		},
+		func(recv *peering.RecvEvent, initMsg initiatorMsg) (bool, error) {
+			switch msg := initMsg.(type) {
+			case *initiatorPubShareMsg:
+				pubShareResponses[int(recv.Msg.SenderIndex)] = msg
+				return true, nil
+			default:
+				n.log.Errorf("unexpected message type instead of initiatorPubShareMsg: %V", msg)
+				return false, errors.New("unexpected message type instead of initiatorPubShareMsg")
+			}
+		},
+	); err != nil {
+		return nil, err
+	}
+	var sharedPublic kyber.Point
+	var publicShares []kyber.Point
+	if _, sharedPublic, publicShares, err = n.checkPubShares(pubShareResponses, newPeerCount); err != nil {
+		return nil, err
+	}
+	if !sharedPublic.Equal(oldShare.SharedPublic) {
+		return nil, errors.New("the shared public key has changed during the resharing")
+	}
+	n.log.Debugf("Reshared SharedAddress=%v, SharedPublic=%v", sharedAddress, sharedPublic)
+	//
+	// Commit the keys to persistent storage.
+	if err = n.exchangeInitiatorAcks(netGroup, newPeers, recvCh, rTimeout, gTimeout, reshareStep5CommitAndTerminate,
+		func(peerIdx uint16, peer peering.PeerSender) {
+			n.log.Debugf("Initiator sends step=%v command to %v", reshareStep5CommitAndTerminate, peer.NetID())
+			peer.SendMsg(makePeerMessage(&dkgID, reshareStep5CommitAndTerminate, &initiatorDoneMsg{
+				pubShares: publicShares,
+			}))
+		},
+	); err != nil {
+		return nil, err
+	}
+	dkShare := tcrypto.DKShare{
+		Address:       sharedAddress,
+		N:             newPeerCount,
+		T:             threshold,
+		Index:         nil, // Not meaningful in this case.
+		SharedPublic:  sharedPublic,
+		PublicCommits: nil, // Not meaningful in this case.
+		PublicShares:  publicShares,
+		PrivateShare:  nil, // Not meaningful in this case.
+	}
+	return &dkShare, nil
+}
+
+// reshareGroupNetIDs returns the peers of the both groups, the new peers first.
+func reshareGroupNetIDs(oldPeerNetIDs, newPeerNetIDs []string) []string {
+	ret := make([]string, 0, len(oldPeerNetIDs)+len(newPeerNetIDs))
+	ret = append(ret, newPeerNetIDs...)
+	for _, oldNetID := range oldPeerNetIDs {
+		if indexOfNetID(newPeerNetIDs, oldNetID) < 0 {
+			ret = append(ret, oldNetID)
+		}
+	}
+	return ret
+}
+
+func indexOfNetID(netIDs []string, netID string) int {
+	for i := range netIDs {
+		if netIDs[i] == netID {
+			return i
+		}
+	}
+	return -1
+}
+
+// resolvePeerPubs takes the public keys of the peers from the peering network.
+func resolvePeerPubs(netGroup peering.GroupProvider, peerNetIDs []string, timeout time.Duration) ([]kyber.Point, error) {
+	var err error
+	peerPubs := make([]kyber.Point, len(peerNetIDs))
+	for i := range peerNetIDs {
+		var peerIdx uint16
+		if peerIdx, err = netGroup.PeerIndexByNetID(peerNetIDs[i]); err != nil {
+			return nil, err
+		}
+		peer := netGroup.AllNodes()[peerIdx]
+		if err = peer.Await(timeout); err != nil {
+			return nil, err
+		}
+		if peerPubs[i] = peer.PubKey(); peerPubs[i] == nil {
+			return nil, fmt.Errorf("Have no public key for %v", peer.NetID())
+		}
+	}
+	return peerPubs, nil
+}
+
+// onInitiatorReshare creates the resharing process on a peer of the old or the new group.
+func onInitiatorReshare(dkgID *coretypes.ChainID, msg *initiatorReshareMsg, node *Node) (*proc, error) {
+	log := node.log.With("dkgID", dkgID.String())
+	var err error
+
+	var netGroup peering.GroupProvider
+	if netGroup, err = node.netProvider.Group(reshareGroupNetIDs(msg.oldPeerNetIDs, msg.newPeerNetIDs)); err != nil {
+		return nil, err
+	}
+	var nodeIndex uint16
+	if nodeIndex, err = netGroup.PeerIndex(node.netProvider.Self()); err != nil {
+		return nil, err
+	}
+	myNetID := node.netProvider.Self().NetID()
+	oldIndex := indexOfNetID(msg.oldPeerNetIDs, myNetID)
+	secKey, _ := node.identity()
+	config := pedersen_dkg.Config{
+		Suite:        node.suite,
+		Longterm:     secKey,
+		OldNodes:     msg.oldPeerPubs,
+		NewNodes:     msg.newPeerPubs,
+		Threshold:    int(msg.threshold),
+		OldThreshold: int(msg.oldThreshold),
+	}
+	if oldIndex >= 0 {
+		var oldShare *tcrypto.DKShare
+		if oldShare, err = node.registry.LoadDKShare(msg.sharedAddress); err != nil {
+			return nil, err
+		}
+		if int(*oldShare.Index) != oldIndex {
+			return nil, fmt.Errorf("the share has index %d, but the peer is listed at %d", *oldShare.Index, oldIndex)
+		}
+		config.Share = &pedersen_dkg.DistKeyShare{
+			Commits: oldShare.PublicCommits,
+			Share:   &share.PriShare{I: oldIndex, V: oldShare.PrivateShare},
+		}
+	} else {
+		config.PublicCoeffs = msg.publicCommits
+	}
+	var reshareImpl *pedersen_dkg.DistKeyGenerator
+	if reshareImpl, err = pedersen_dkg.NewDistKeyHandler(&config); err != nil {
+		return nil, err
+	}
+	p := proc{
+		dkgRef:       msg.dkgRef,
+		dkgID:        dkgID,
+		node:         node,
+		nodeIndex:    nodeIndex,
+		initiatorPub: msg.initiatorPub,
+		threshold:    msg.threshold,
+		roundRetry:   msg.roundRetry,
+		netGroup:     netGroup,
+		reshareImpl:  reshareImpl,
+		reshareOld:   oldIndex >= 0,
+		reshareNew:   int(nodeIndex) < len(msg.newPeerNetIDs),
+		reshareN:     uint16(len(msg.newPeerNetIDs)),
+		dkgLock:      &sync.RWMutex{},
+		peerMsgCh:    make(chan *peering.RecvEvent, len(netGroup.AllNodes())),
+		log:          log,
+		myNetID:      myNetID,
+	}
+	p.log.Infof("Starting DKG resharing Peer process at %v for DkgID=%v", p.myNetID, p.dkgID.String())
+	stepsStart := make(chan map[uint16]*peering.PeerMessage)
+	p.steps = make(map[byte]*procStep)
+	p.steps[reshareStep1SendDeals] = newProcStep(reshareStep1SendDeals, &p,
+		stepsStart,
+		p.reshareStep1SendDealsMakeSent,
+		p.reshareStepMakeResp,
+	)
+	p.steps[reshareStep2SendResponses] = newProcStep(reshareStep2SendResponses, &p,
+		p.steps[reshareStep1SendDeals].doneCh,
+		p.reshareStep2SendResponsesMakeSent,
+		p.reshareStepMakeResp,
+	)
+	p.steps[reshareStep3SendJustifications] = newProcStep(reshareStep3SendJustifications, &p,
+		p.steps[reshareStep2SendResponses].doneCh,
+		p.reshareStep3SendJustificationsMakeSent,
+		p.reshareStepMakeResp,
+	)
+	lastStep := reshareStep3SendJustifications
+	if p.reshareNew {
+		// Only the new peers receive the new shares.
+		p.steps[reshareStep4ReturnPubShare] = newProcStep(reshareStep4ReturnPubShare, &p,
+			p.steps[reshareStep3SendJustifications].doneCh,
+			p.reshareStep4ReturnPubShareMakeSent,
+			p.reshareStep4ReturnPubShareMakeResp,
+		)
+		p.steps[reshareStep5CommitAndTerminate] = newProcStep(reshareStep5CommitAndTerminate, &p,
+			p.steps[reshareStep4ReturnPubShare].doneCh,
+			p.reshareStep5CommitAndTerminateMakeSent,
+			p.reshareStepMakeResp,
+		)
+		lastStep = reshareStep5CommitAndTerminate
+	}
+	go p.processLoop(msg.timeout, p.steps[lastStep].doneCh)
+	p.attachID = p.netGroup.Attach(dkgID, p.onPeerMessage)
+	stepsStart <- make(map[uint16]*peering.PeerMessage)
+	return &p, nil
+}
+
+// All the peers exchange messages with all the other peers in each step,
+// the messages are empty, if there is nothing to say to the particular peer.
+func (p *proc) reshareSentMsgs(step byte, makeMsg func(peerIdx uint16) msgByteCoder) map[uint16]*peering.PeerMessage {
+	sentMsgs := make(map[uint16]*peering.PeerMessage)
+	for i := range p.netGroup.OtherNodes() {
+		sentMsgs[i] = makePeerMessage(p.dkgID, step, makeMsg(i))
+	}
+	return sentMsgs
+}
+
+func (p *proc) reshareStepMakeResp(step byte, initRecv *peering.RecvEvent, recvMsgs map[uint16]*peering.PeerMessage) (*peering.PeerMessage, error) {
+	return makePeerMessage(p.dkgID, step, &initiatorStatusMsg{error: nil}), nil
+}
+
+// reshareStep1SendDeals
+func (p *proc) reshareStep1SendDealsMakeSent(step byte, initRecv *peering.RecvEvent, prevMsgs map[uint16]*peering.PeerMessage) (map[uint16]*peering.PeerMessage, error) {
+	var err error
+	// Deals are indexed by the new peer indexes, that are the same as the indexes in the group.
+	deals := make(map[int]*pedersen_dkg.Deal)
+	if p.reshareOld {
+		p.dkgLock.Lock()
+		if deals, err = p.reshareImpl.Deals(); err != nil {
+			p.dkgLock.Unlock()
+			p.log.Errorf("Deals -> %+v", err)
+			return nil, err
+		}
+		p.dkgLock.Unlock()
+	}
+	return p.reshareSentMsgs(step, func(peerIdx uint16) msgByteCoder {
+		return &reshareDealMsg{deal: deals[int(peerIdx)]}
+	}), nil
+}
+
+// reshareStep2SendResponses
+func (p *proc) reshareStep2SendResponsesMakeSent(step byte, initRecv *peering.RecvEvent, prevMsgs map[uint16]*peering.PeerMessage) (map[uint16]*peering.PeerMessage, error) {
+	var err error
+	ourResponses := []*pedersen_dkg.Response{}
+	for i := range prevMsgs {
+		peerDealMsg := reshareDealMsg{}
+		if err = peerDealMsg.fromBytes(prevMsgs[i].MsgData, p.node.suite); err != nil {
+			return nil, err
+		}
+		if peerDealMsg.deal == nil {
+			continue
+		}
+		if !p.reshareNew {
+			return nil, fmt.Errorf("unexpected deal from %v for a peer not receiving a share", i)
+		}
+		var r *pedersen_dkg.Response
+		p.dkgLock.Lock()
+		if r, err = p.reshareImpl.ProcessDeal(peerDealMsg.deal); err != nil {
+			p.dkgLock.Unlock()
+			p.log.Errorf("ProcessDeal(%v) -> %+v", i, err)
+			return nil, err
+		}
+		p.dkgLock.Unlock()
+		ourResponses = append(ourResponses, r)
+	}
+	return p.reshareSentMsgs(step, func(peerIdx uint16) msgByteCoder {
+		return &reshareResponseMsg{responses: ourResponses}
+	}), nil
+}
+
+// reshareStep3SendJustifications
+func (p *proc) reshareStep3SendJustificationsMakeSent(step byte, initRecv *peering.RecvEvent, prevMsgs map[uint16]*peering.PeerMessage) (map[uint16]*peering.PeerMessage, error) {
+	var err error
+	ourJustifications := []*pedersen_dkg.Justification{}
+	for i := range prevMsgs {
+		peerResponseMsg := reshareResponseMsg{}
+		if err = peerResponseMsg.fromBytes(prevMsgs[i].MsgData); err != nil {
+			return nil, fmt.Errorf("Response: decoding failed: %v", err)
+		}
+		for _, r := range peerResponseMsg.responses {
+			var j *pedersen_dkg.Justification
+			p.dkgLock.Lock()
+			if j, err = p.reshareImpl.ProcessResponse(r); err != nil {
+				p.dkgLock.Unlock()
+				p.log.Errorf("ProcessResponse(%v) -> %+v", i, err)
+				return nil, err
+			}
+			p.dkgLock.Unlock()
+			if j != nil {
+				ourJustifications = append(ourJustifications, j)
+			}
+		}
+	}
+	return p.reshareSentMsgs(step, func(peerIdx uint16) msgByteCoder {
+		return &reshareJustificationMsg{justifications: ourJustifications}
+	}), nil
+}
+
+// reshareStep4ReturnPubShare
+func (p *proc) reshareStep4ReturnPubShareMakeSent(step byte, initRecv *peering.RecvEvent, prevMsgs map[uint16]*peering.PeerMessage) (map[uint16]*peering.PeerMessage, error) {
+	var err error
+	for i := range prevMsgs {
+		peerJustificationMsg := reshareJustificationMsg{}
+		if err = peerJustificationMsg.fromBytes(prevMsgs[i].MsgData, p.node.suite); err != nil {
+			return nil, fmt.Errorf("Justification: decoding failed: %v", err)
+		}
+		p.dkgLock.Lock()
+		for _, j := range peerJustificationMsg.justifications {
+			if err = p.reshareImpl.ProcessJustification(j); err != nil {
+				p.dkgLock.Unlock()
+				return nil, fmt.Errorf("Justification: processing failed: %v", err)
+			}
+		}
+		p.dkgLock.Unlock()
+	}
+	p.dkgLock.Lock()
+	p.reshareImpl.SetTimeout()
+	if !p.reshareImpl.Certified() {
+		p.dkgLock.Unlock()
+		return nil, fmt.Errorf("node not certified")
+	}
+	var distKeyShare *pedersen_dkg.DistKeyShare
+	if distKeyShare, err = p.reshareImpl.DistKeyShare(); err != nil {
+		p.dkgLock.Unlock()
+		return nil, err
+	}
+	p.dkgLock.Unlock()
+	//
+	// Save the needed info.
+	ownIndex := uint16(distKeyShare.PriShare().I)
+	publicShares := make([]kyber.Point, p.reshareN)
+	publicShares[ownIndex] = p.node.suite.Point().Mul(distKeyShare.PriShare().V, nil)
+	p.dkShare, err = tcrypto.NewDKShare(
+		ownIndex,                  // Index
+		p.reshareN,                // N
+		p.threshold,               // T
+		distKeyShare.Public(),     // SharedPublic
+		distKeyShare.Commits,      // PublicCommits
+		publicShares,              // PublicShares
+		distKeyShare.PriShare().V, // PrivateShare
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.log.Debugf("The key is reshared, shared public: %v.", p.dkShare.SharedPublic)
+	return make(map[uint16]*peering.PeerMessage), nil // Nothing to exchange with the peers.
+}
+func (p *proc) reshareStep4ReturnPubShareMakeResp(step byte, initRecv *peering.RecvEvent, recvMsgs map[uint16]*peering.PeerMessage) (*peering.PeerMessage, error) {
+	var err error
+	var pubShareMsg *initiatorPubShareMsg
+	if pubShareMsg, err = p.makeInitiatorPubShareMsg(step); err != nil {
+		return nil, err
+	}
+	return makePeerMessage(p.dkgID, step, pubShareMsg), nil
+}
+
+// reshareStep5CommitAndTerminate
+func (p *proc) reshareStep5CommitAndTerminateMakeSent(step byte, initRecv *peering.RecvEvent, prevMsgs map[uint16]*peering.PeerMessage) (map[uint16]*peering.PeerMessage, error) {
+	var err error
+	var doneMsg = initiatorDoneMsg{}
+	if err = doneMsg.fromBytes(initRecv.Msg.MsgData, p.node.suite); err != nil {
+		p.log.Warnf("Dropping message, failed to decode: %v", initRecv)
+		return nil, err
+	}
+	if p.dkShare == nil {
+		return nil, errors.New("there is no dkShare to commit")
+	}
+	p.dkShare.PublicShares = doneMsg.pubShares // Store public shares of all the other peers.
+	if p.reshareOld {
+		// The old share is replaced by the new one.
+		err = p.node.registry.ReplaceDKShare(p.dkShare)
+	} else {
+		err = p.node.registry.SaveDKShare(p.dkShare)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return make(map[uint16]*peering.PeerMessage), nil
+}
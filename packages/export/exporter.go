@@ -0,0 +1,129 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/hive.go/logger"
+	"go.uber.org/atomic"
+)
+
+// Exporter collects records into batches and writes them to all sinks. The node is never blocked
+// by the sinks: records which don't fit into the buffer are dropped and counted
+type Exporter struct {
+	sinks         map[string]Sink
+	records       chan *Record
+	batchSize     int
+	flushInterval time.Duration
+	dropped       atomic.Int64
+	log           *logger.Logger
+}
+
+// NewExporter creates sinks of the URIs. The batch is written when it has batchSize records
+// or flushInterval passed since the last write
+func NewExporter(uris []string, bufferSize, batchSize int, flushInterval time.Duration, log *logger.Logger) (*Exporter, error) {
+	if bufferSize <= 0 || batchSize <= 0 || flushInterval <= 0 {
+		return nil, fmt.Errorf("buffer size, batch size and flush interval must be positive")
+	}
+	ret := &Exporter{
+		sinks:         make(map[string]Sink),
+		records:       make(chan *Record, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		log:           log,
+	}
+	for _, uri := range uris {
+		s, err := NewSink(uri)
+		if err != nil {
+			ret.closeSinks()
+			return nil, err
+		}
+		ret.sinks[uri] = s
+	}
+	return ret, nil
+}
+
+// Push adds the record to the buffer. Returns false if the buffer is full and the record is dropped
+func (e *Exporter) Push(r *Record) bool {
+	select {
+	case e.records <- r:
+		return true
+	default:
+		e.dropped.Inc()
+		return false
+	}
+}
+
+// PushPublished adds the message of the node publisher, if it is exported
+func (e *Exporter) PushPublished(msgType string, parts []string) {
+	if r, ok := RecordFromPublished(msgType, parts); ok {
+		e.Push(r)
+	}
+}
+
+// Dropped is the number of records dropped because the buffer was full
+func (e *Exporter) Dropped() int64 {
+	return e.dropped.Load()
+}
+
+// Run writes batches until the shutdown signal. Records left in the buffer are written
+// before the sinks are closed
+func (e *Exporter) Run(shutdownSignal <-chan struct{}) {
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Record, 0, e.batchSize)
+	var reportedDropped int64
+	for {
+		select {
+		case r := <-e.records:
+			batch = append(batch, r)
+			if len(batch) >= e.batchSize {
+				e.write(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				e.write(batch)
+				batch = batch[:0]
+			}
+			if d := e.Dropped(); d > reportedDropped {
+				e.log.Warnf("export: %d records dropped, the sinks are too slow", d-reportedDropped)
+				reportedDropped = d
+			}
+		case <-shutdownSignal:
+		drain:
+			for {
+				select {
+				case r := <-e.records:
+					batch = append(batch, r)
+				default:
+					break drain
+				}
+			}
+			if len(batch) > 0 {
+				e.write(batch)
+			}
+			e.closeSinks()
+			return
+		}
+	}
+}
+
+// write writes the batch to each sink. The failure of one sink doesn't affect others,
+// the batch is lost for the failed sink
+func (e *Exporter) write(batch []*Record) {
+	for uri, s := range e.sinks {
+		if err := s.Write(batch); err != nil {
+			e.log.Errorf("export to %s: %d records lost: %v", redacted(uri), len(batch), err)
+		}
+	}
+}
+
+func (e *Exporter) closeSinks() {
+	for uri, s := range e.sinks {
+		if err := s.Close(); err != nil {
+			e.log.Warnf("export: closing %s: %v", redacted(uri), err)
+		}
+	}
+}
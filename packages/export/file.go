@@ -0,0 +1,49 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// fileSink appends records to the file as newline-delimited JSON, one record per line
+type fileSink struct {
+	f *os.File
+}
+
+// newFileSink opens the file of the URI 'file:///absolute/path' or 'file:relative/path'
+func newFileSink(uri *url.URL) (Sink, error) {
+	path := uri.Path
+	if path == "" {
+		path = uri.Opaque
+	}
+	if path == "" {
+		return nil, fmt.Errorf("export file sink: path of the file is missing")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{f: f}, nil
+}
+
+func (s *fileSink) Write(records []*Record) error {
+	w := bufio.NewWriter(s.f)
+	enc := json.NewEncoder(w)
+	for _, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (s *fileSink) Close() error {
+	return s.f.Close()
+}
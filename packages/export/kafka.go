@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const kafkaTimeout = 10 * time.Second
+
+// kafkaSink produces records to the Kafka topic through the REST proxy of the cluster
+// (Confluent REST Proxy API v2), so that the node doesn't depend on the Kafka client library.
+// Records are keyed by the chain ID: records of one chain go to one partition and keep the order
+type kafkaSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+type kafkaRecord struct {
+	Key   string  `json:"key"`
+	Value *Record `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+// newKafkaSink creates the sink of the URI 'kafka://proxyhost:port/topic', or 'kafka+https://...'
+// for the REST proxy behind TLS
+func newKafkaSink(uri *url.URL) (Sink, error) {
+	topic := strings.Trim(uri.Path, "/")
+	if topic == "" || uri.Host == "" {
+		return nil, fmt.Errorf("export kafka sink: expected 'kafka://proxyhost:port/topic'")
+	}
+	scheme := "http"
+	if uri.Scheme == "kafka+https" {
+		scheme = "https"
+	}
+	endpoint := url.URL{Scheme: scheme, User: uri.User, Host: uri.Host, Path: "/topics/" + topic}
+	return &kafkaSink{
+		endpoint: endpoint.String(),
+		client:   &http.Client{Timeout: kafkaTimeout},
+	}, nil
+}
+
+func (s *kafkaSink) Write(records []*Record) error {
+	req := kafkaProduceRequest{Records: make([]kafkaRecord, len(records))}
+	for i, r := range records {
+		req.Records[i] = kafkaRecord{Key: r.ChainID, Value: r}
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Post(s.endpoint, "application/vnd.kafka.json.v2+json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("export kafka sink: %s: %s", res.Status, string(body))
+	}
+	return nil
+}
+
+func (s *kafkaSink) Close() error {
+	return nil
+}
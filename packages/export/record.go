@@ -0,0 +1,107 @@
+// Package export streams processed requests and events of contracts, published by the node,
+// to external sinks: newline-delimited JSON files, Kafka and PostgreSQL
+package export
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// types of exported records
+const (
+	// RecordRequest is the request processed in the committed block
+	RecordRequest = "request"
+	// RecordMessage is the message of the contract, see Sandbox.Event
+	RecordMessage = "message"
+	// RecordEvent is the typed event of the contract, see Sandbox.EmitEvent
+	RecordEvent = "event"
+)
+
+// Record is one exported item. Fields not related to the Type are empty
+type Record struct {
+	Type      string `json:"type"`
+	ChainID   string `json:"chainId"`
+	Timestamp int64  `json:"timestamp"`
+	// RequestID, BlockIndex, Position and BlockSize are of the request
+	RequestID  string `json:"requestId,omitempty"`
+	BlockIndex uint32 `json:"blockIndex,omitempty"`
+	Position   int    `json:"position,omitempty"`
+	BlockSize  int    `json:"blockSize,omitempty"`
+	// Contract is the hname of the contract which emitted the message or the event
+	Contract string `json:"contract,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Topic    string `json:"topic,omitempty"`
+	// Fields of the typed event, values are hex encoded
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// RecordFromPublished converts the message of the node publisher to the record.
+// Returns false for messages which are not exported
+func RecordFromPublished(msgType string, parts []string) (*Record, bool) {
+	ts := time.Now().UnixNano()
+	switch msgType {
+	case "request_out":
+		// chainID txID index blockIndex position blockSize
+		if len(parts) < 6 {
+			return nil, false
+		}
+		blockIndex, err := strconv.Atoi(parts[3])
+		if err != nil {
+			return nil, false
+		}
+		position, err := strconv.Atoi(parts[4])
+		if err != nil {
+			return nil, false
+		}
+		blockSize, err := strconv.Atoi(parts[5])
+		if err != nil {
+			return nil, false
+		}
+		return &Record{
+			Type:       RecordRequest,
+			ChainID:    parts[0],
+			Timestamp:  ts,
+			RequestID:  "[" + parts[2] + "]" + parts[1],
+			BlockIndex: uint32(blockIndex),
+			Position:   position,
+			BlockSize:  blockSize,
+		}, true
+
+	case "vmmsg":
+		// chainID hname message
+		if len(parts) < 3 {
+			return nil, false
+		}
+		return &Record{
+			Type:      RecordMessage,
+			ChainID:   parts[0],
+			Timestamp: ts,
+			Contract:  parts[1],
+			Message:   strings.Join(parts[2:], " "),
+		}, true
+
+	case "vmevent":
+		// chainID hname topic field=hexvalue...
+		if len(parts) < 3 {
+			return nil, false
+		}
+		ret := &Record{
+			Type:      RecordEvent,
+			ChainID:   parts[0],
+			Timestamp: ts,
+			Contract:  parts[1],
+			Topic:     parts[2],
+			Fields:    make(map[string]string),
+		}
+		for _, f := range parts[3:] {
+			i := strings.LastIndex(f, "=")
+			if i < 0 {
+				continue
+			}
+			ret.Fields[f[:i]] = f[i+1:]
+		}
+		return ret, true
+	}
+	return nil, false
+}
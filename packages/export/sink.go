@@ -0,0 +1,61 @@
+package export
+
+import (
+	"fmt"
+	"net/url"
+	"sync"
+)
+
+// Sink receives batches of records. Write is never called concurrently for the same sink
+type Sink interface {
+	Write(records []*Record) error
+	Close() error
+}
+
+// SinkFactory creates the sink from the URI of the node configuration
+type SinkFactory func(uri *url.URL) (Sink, error)
+
+var (
+	factories      = make(map[string]SinkFactory)
+	factoriesMutex sync.RWMutex
+)
+
+func init() {
+	RegisterSink("file", newFileSink)
+	RegisterSink("kafka", newKafkaSink)
+	RegisterSink("kafka+https", newKafkaSink)
+	RegisterSink("postgres", newPostgresSink)
+	RegisterSink("postgresql", newPostgresSink)
+}
+
+// RegisterSink makes the sink available for URIs of the scheme. The built-in schemes are 'file',
+// 'kafka', 'kafka+https', 'postgres' and 'postgresql'. The factory of the scheme registered before is replaced
+func RegisterSink(scheme string, factory SinkFactory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+	factories[scheme] = factory
+}
+
+// NewSink creates the sink from the URI, e.g. 'file:///var/log/wasp/export.ndjson'
+func NewSink(uri string) (Sink, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("wrong export sink '%s': %v", uri, err)
+	}
+	factoriesMutex.RLock()
+	factory, ok := factories[u.Scheme]
+	factoriesMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown export sink '%s'", u.Scheme)
+	}
+	return factory(u)
+}
+
+// redacted is the URI for logs, without the password
+func redacted(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "<wrong uri>"
+	}
+	return u.Redacted()
+}
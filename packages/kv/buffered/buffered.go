@@ -122,6 +122,10 @@ func (b *bufferedKVStore) Del(key kv.Key) {
 	b.mutations.Add(NewMutationDel(key))
 }
 
+func (b *bufferedKVStore) DelPrefix(prefix kv.Key) {
+	b.mutations.Add(NewMutationDelPrefix(prefix))
+}
+
 func (b *bufferedKVStore) Get(key kv.Key) ([]byte, error) {
 	mut := b.mutations.Latest(key)
 	if mut != nil {
@@ -159,7 +163,7 @@ func (b *bufferedKVStore) Iterate(prefix kv.Key, f func(key kv.Key, value []byte
 	return b.db.Iterate([]byte(prefix), func(key kvstore.Key, value kvstore.Value) bool {
 		k := kv.Key(key)
 		_, ok := seen[k]
-		if ok {
+		if ok || b.mutations.IsDeletedByPrefix(k) {
 			return true
 		}
 		return f(k, value)
@@ -180,7 +184,7 @@ func (b *bufferedKVStore) IterateKeys(prefix kv.Key, f func(key kv.Key) bool) er
 	return b.db.IterateKeys([]byte(prefix), func(key kvstore.Key) bool {
 		k := kv.Key(key)
 		_, ok := seen[k]
-		if ok {
+		if ok || b.mutations.IsDeletedByPrefix(k) {
 			return true
 		}
 		return f(k)
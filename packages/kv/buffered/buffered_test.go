@@ -79,3 +79,41 @@ func TestBufferedKVStore(t *testing.T) {
 		m,
 	)
 }
+
+func TestBufferedKVStoreDelPrefix(t *testing.T) {
+	db := mapdb.NewMapDB()
+	_ = db.Set([]byte("a1"), []byte("v1"))
+	_ = db.Set([]byte("a2"), []byte("v2"))
+	_ = db.Set([]byte("b1"), []byte("v3"))
+
+	b := NewBufferedKVStore(db)
+	b.DelPrefix(kv.Key("a"))
+	b.Set(kv.Key("a3"), []byte("v4"))
+
+	// not committed to DB
+	v, err := db.Get([]byte("a1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("v1"), v)
+
+	v, err = b.Get(kv.Key("a1"))
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+	assert.False(t, b.MustHas(kv.Key("a2")))
+	assert.True(t, b.MustHas(kv.Key("b1")))
+
+	keys := make([]kv.Key, 0)
+	b.MustIterateKeys(kv.Key("a"), func(key kv.Key) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Equal(t, []kv.Key{"a3"}, keys)
+
+	assert.EqualValues(
+		t,
+		map[kv.Key][]byte{
+			kv.Key("a3"): []byte("v4"),
+			kv.Key("b1"): []byte("v3"),
+		},
+		b.DangerouslyDumpToDict(),
+	)
+}
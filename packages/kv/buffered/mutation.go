@@ -8,7 +8,7 @@ import (
 	"github.com/iotaledger/wasp/packages/util"
 )
 
-// Mutation represents a single "set" or "del" operation over a KVStore
+// Mutation represents a single "set", "del" or "del prefix" operation over a KVStore
 type Mutation interface {
 	Read(io.Reader) error
 	Write(io.Writer) error
@@ -17,7 +17,7 @@ type Mutation interface {
 
 	ApplyTo(w kv.KVStoreWriter)
 
-	// Key returns the key that is mutated
+	// Key returns the key that is mutated, or the prefix of deleted keys
 	Key() kv.Key
 	// Value returns the value after the mutation (nil if deleted)
 	Value() []byte
@@ -37,11 +37,18 @@ type MutationSequence interface {
 
 	// Iterate over all mutations in order, even ones affecting the same key repeatedly
 	Iterate(func(mut Mutation) bool)
-	// Iterate over the latest mutation recorded for each key
+	// Iterate over the latest mutation recorded for each key. Keys deleted by prefix deletions
+	// and not set after them are not included, see IterateDeletedPrefixes
 	IterateLatest(func(key kv.Key, mut Mutation) bool)
 	// Iterate over the latest value recorded for each non-deleted key
 	IterateValues(prefix kv.Key, f func(key kv.Key, value []byte) bool) (map[kv.Key]bool, bool)
+	// Iterate over prefixes of all prefix deletions in order
+	IterateDeletedPrefixes(f func(prefix kv.Key) bool)
+	// IsDeletedByPrefix returns true if the key is deleted by a prefix deletion and not set after it
+	IsDeletedByPrefix(key kv.Key) bool
 
+	// Latest returns the latest mutation of the key. For the key deleted by a prefix deletion
+	// it is the "del" mutation of the key. Returns nil if the key is not mutated
 	Latest(key kv.Key) Mutation
 
 	Add(mut Mutation)
@@ -52,17 +59,22 @@ type MutationSequence interface {
 const (
 	mutationMagicSet = iota
 	mutationMagicDel
+	mutationMagicDelPrefix
 )
 
 type mutationSequence struct {
 	muts        []Mutation
 	latestByKey map[kv.Key]*Mutation
+	// prefixes of prefix deletions. The prefix deletion is recorded as a single mutation,
+	// deleted keys are enumerated only when the sequence is applied to the store
+	deletedPrefixes []kv.Key
 }
 
 func NewMutationSequence() MutationSequence {
 	return &mutationSequence{
-		muts:        make([]Mutation, 0),
-		latestByKey: make(map[kv.Key]*Mutation),
+		muts:            make([]Mutation, 0),
+		latestByKey:     make(map[kv.Key]*Mutation),
+		deletedPrefixes: make([]kv.Key, 0),
 	}
 }
 
@@ -146,13 +158,44 @@ func (ms *mutationSequence) IterateValues(prefix kv.Key, f func(key kv.Key, valu
 	return seen, false
 }
 
+func (ms *mutationSequence) IterateDeletedPrefixes(f func(prefix kv.Key) bool) {
+	for _, prefix := range ms.deletedPrefixes {
+		if !f(prefix) {
+			break
+		}
+	}
+}
+
+func (ms *mutationSequence) IsDeletedByPrefix(key kv.Key) bool {
+	if _, ok := ms.latestByKey[key]; ok {
+		// set or deleted after prefix deletions
+		return false
+	}
+	for _, prefix := range ms.deletedPrefixes {
+		if key.HasPrefix(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 func (ms *mutationSequence) Len() int {
 	return len(ms.muts)
 }
 
 func (ms *mutationSequence) Add(mut Mutation) {
 	ms.muts = append(ms.muts, mut)
-	ms.latestByKey[mut.Key()] = &mut
+	if mut.getMagic() != mutationMagicDelPrefix {
+		ms.latestByKey[mut.Key()] = &mut
+		return
+	}
+	prefix := mut.Key()
+	for key := range ms.latestByKey {
+		if key.HasPrefix(prefix) {
+			delete(ms.latestByKey, key)
+		}
+	}
+	ms.deletedPrefixes = append(ms.deletedPrefixes, prefix)
 }
 
 func (ms *mutationSequence) ApplyTo(w kv.KVStoreWriter) {
@@ -164,6 +207,9 @@ func (ms *mutationSequence) ApplyTo(w kv.KVStoreWriter) {
 func (ms *mutationSequence) Latest(key kv.Key) Mutation {
 	mut, ok := ms.latestByKey[key]
 	if !ok {
+		if ms.IsDeletedByPrefix(key) {
+			return NewMutationDel(key)
+		}
 		return nil
 	}
 	return *mut
@@ -174,7 +220,9 @@ func (ms *mutationSequence) Clone() MutationSequence {
 	for k, v := range ms.latestByKey {
 		mapClone[k] = v
 	}
-	return &mutationSequence{muts: ms.muts[:], latestByKey: mapClone}
+	prefixesClone := make([]kv.Key, len(ms.deletedPrefixes))
+	copy(prefixesClone, ms.deletedPrefixes)
+	return &mutationSequence{muts: ms.muts[:], latestByKey: mapClone, deletedPrefixes: prefixesClone}
 }
 
 type mutationSet struct {
@@ -186,12 +234,18 @@ type mutationDel struct {
 	k kv.Key
 }
 
+type mutationDelPrefix struct {
+	prefix kv.Key
+}
+
 func newFromMagic(magic int) (Mutation, error) {
 	switch magic {
 	case mutationMagicSet:
 		return &mutationSet{}, nil
 	case mutationMagicDel:
 		return &mutationDel{}, nil
+	case mutationMagicDelPrefix:
+		return &mutationDelPrefix{}, nil
 	}
 	return nil, fmt.Errorf("Unknown mutation magic %d", magic)
 }
@@ -284,3 +338,41 @@ func (m *mutationDel) Value() []byte {
 func (m *mutationDel) ApplyTo(w kv.KVStoreWriter) {
 	w.Del(m.k)
 }
+
+func (m *mutationDelPrefix) getMagic() int {
+	return mutationMagicDelPrefix
+}
+
+// NewMutationDelPrefix creates the mutation which deletes all keys with the prefix
+func NewMutationDelPrefix(prefix kv.Key) *mutationDelPrefix {
+	return &mutationDelPrefix{prefix: prefix}
+}
+
+func (m *mutationDelPrefix) Write(w io.Writer) error {
+	return util.WriteBytes16(w, []byte(m.prefix))
+}
+
+func (m *mutationDelPrefix) Read(r io.Reader) error {
+	prefix, err := util.ReadBytes16(r)
+	if err != nil {
+		return err
+	}
+	m.prefix = kv.Key(prefix)
+	return nil
+}
+
+func (m *mutationDelPrefix) String() string {
+	return fmt.Sprintf("DEL PREFIX %s", m.prefix)
+}
+
+func (m *mutationDelPrefix) Key() kv.Key {
+	return m.prefix
+}
+
+func (m *mutationDelPrefix) Value() []byte {
+	return nil
+}
+
+func (m *mutationDelPrefix) ApplyTo(w kv.KVStoreWriter) {
+	w.DelPrefix(m.prefix)
+}
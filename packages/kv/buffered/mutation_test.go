@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/stretchr/testify/assert"
@@ -51,3 +52,59 @@ func TestMutationSequenceMarshaling(t *testing.T) {
 
 	assert.EqualValues(t, util.GetHashValue(ms), util.GetHashValue(ms2))
 }
+
+func TestApplyMutationDelPrefix(t *testing.T) {
+	vars := dict.New()
+	vars.Set("a1", []byte("v1"))
+	vars.Set("a2", []byte("v2"))
+	vars.Set("b1", []byte("v3"))
+
+	NewMutationDelPrefix("a").ApplyTo(vars)
+
+	assert.EqualValues(t, 1, len(vars))
+	v, _ := vars.Get("b1")
+	assert.Equal(t, []byte("v3"), v)
+}
+
+func TestMutationSequenceDelPrefix(t *testing.T) {
+	ms := NewMutationSequence()
+	ms.Add(NewMutationSet("a1", []byte("v1")))
+	ms.Add(NewMutationSet("b1", []byte("v2")))
+	ms.Add(NewMutationDelPrefix("a"))
+	ms.Add(NewMutationSet("a2", []byte("v3")))
+
+	// the prefix deletion is a single mutation
+	assert.EqualValues(t, 4, ms.Len())
+
+	assert.True(t, ms.IsDeletedByPrefix("a1"))
+	assert.True(t, ms.IsDeletedByPrefix("a3"))
+	assert.False(t, ms.IsDeletedByPrefix("a2"))
+	assert.False(t, ms.IsDeletedByPrefix("b1"))
+
+	assert.Nil(t, ms.Latest("a1").Value())
+	assert.Nil(t, ms.Latest("a3").Value())
+	assert.Equal(t, []byte("v3"), ms.Latest("a2").Value())
+	assert.Nil(t, ms.Latest("c1"))
+
+	n := 0
+	ms.IterateLatest(func(key kv.Key, mut Mutation) bool {
+		assert.True(t, key == "a2" || key == "b1")
+		n++
+		return true
+	})
+	assert.EqualValues(t, 2, n)
+
+	vars := dict.New()
+	vars.Set("a0", []byte("v0"))
+	ms.ApplyTo(vars)
+	assert.EqualValues(t, dict.Dict{"a2": []byte("v3"), "b1": []byte("v2")}, vars)
+
+	var buf bytes.Buffer
+	err := ms.Write(&buf)
+	assert.NoError(t, err)
+	ms2 := NewMutationSequence()
+	err = ms2.Read(bytes.NewBuffer(buf.Bytes()))
+	assert.NoError(t, err)
+	assert.EqualValues(t, util.GetHashValue(ms), util.GetHashValue(ms2))
+	assert.True(t, ms2.IsDeletedByPrefix("a1"))
+}
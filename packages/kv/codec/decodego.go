@@ -0,0 +1,81 @@
+package codec
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+)
+
+// DecodeInto decodes the value into the variable pointed by target. It is the reverse of Encode.
+// Returns false if the value is nil, then the target is not changed
+func DecodeInto(b []byte, target interface{}) (bool, error) {
+	var ok bool
+	var err error
+	switch t := target.(type) {
+	case *int:
+		var v int64
+		if v, ok, err = DecodeInt64(b); ok {
+			*t = int(v)
+		}
+	case *int64:
+		var v int64
+		if v, ok, err = DecodeInt64(b); ok {
+			*t = v
+		}
+	case *uint64:
+		var v int64
+		if v, ok, err = DecodeInt64(b); ok {
+			*t = uint64(v)
+		}
+	case *string:
+		var v string
+		if v, ok, err = DecodeString(b); ok {
+			*t = v
+		}
+	case *[]byte:
+		if ok = b != nil; ok {
+			*t = b
+		}
+	case *hashing.HashValue:
+		var v hashing.HashValue
+		if v, ok, err = DecodeHashValue(b); ok {
+			*t = v
+		}
+	case *address.Address:
+		var v address.Address
+		if v, ok, err = DecodeAddress(b); ok {
+			*t = v
+		}
+	case *balance.Color:
+		var v balance.Color
+		if v, ok, err = DecodeColor(b); ok {
+			*t = v
+		}
+	case *coretypes.ChainID:
+		var v coretypes.ChainID
+		if v, ok, err = DecodeChainID(b); ok {
+			*t = v
+		}
+	case *coretypes.ContractID:
+		var v coretypes.ContractID
+		if v, ok, err = DecodeContractID(b); ok {
+			*t = v
+		}
+	case *coretypes.AgentID:
+		var v coretypes.AgentID
+		if v, ok, err = DecodeAgentID(b); ok {
+			*t = v
+		}
+	case *coretypes.Hname:
+		var v coretypes.Hname
+		if v, ok, err = DecodeHname(b); ok {
+			*t = v
+		}
+	default:
+		return false, fmt.Errorf("can't decode into %T", target)
+	}
+	return ok, err
+}
@@ -114,3 +114,23 @@ func TestMapConcurrentAccess(t *testing.T) {
 	require.EqualValues(t, m1.MustLen(), 0)
 	require.EqualValues(t, m2.MustLen(), 0)
 }
+
+func TestMapErase(t *testing.T) {
+	vars := dict.New()
+	m := NewMap(vars, "testMap")
+	other := NewMap(vars, "otherMap")
+
+	m.MustSetAt([]byte("k1"), []byte("datum1"))
+	m.MustSetAt([]byte("k2"), []byte("datum2"))
+	other.MustSetAt([]byte("k1"), []byte("datum3"))
+
+	m.Erase()
+	assert.Zero(t, m.MustLen())
+	assert.False(t, m.MustHasAt([]byte("k1")))
+	assert.False(t, m.MustHasAt([]byte("k2")))
+	assert.EqualValues(t, 1, other.MustLen())
+	assert.EqualValues(t, []byte("datum3"), other.MustGetAt([]byte("k1")))
+
+	m.MustSetAt([]byte("k3"), []byte("datum4"))
+	assert.EqualValues(t, 1, m.MustLen())
+}
@@ -0,0 +1,309 @@
+package collections
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// PriorityQueue represents a priority queue stored in a kv.KVStore as a binary heap.
+// Elements with the lower priority value are popped first, elements with equal priorities
+// are popped in the order they were pushed. Push and Pop access O(log n) keys
+type PriorityQueue struct {
+	*ImmutablePriorityQueue
+	kvw kv.KVStoreWriter
+}
+
+// ImmutablePriorityQueue provides read-only access to a PriorityQueue in a kv.KVStoreReader.
+type ImmutablePriorityQueue struct {
+	kvr  kv.KVStoreReader
+	name string
+}
+
+const (
+	pqSizeKeyCode = byte(iota)
+	pqElemKeyCode
+	pqSeqKeyCode
+)
+
+// pqElem is the element of the heap. seq is the sequence number of the push, it makes the order
+// of elements with equal priorities deterministic
+type pqElem struct {
+	priority int64
+	seq      uint64
+	value    []byte
+}
+
+func NewPriorityQueue(kv kv.KVStore, name string) *PriorityQueue {
+	return &PriorityQueue{
+		ImmutablePriorityQueue: NewPriorityQueueReadOnly(kv, name),
+		kvw:                    kv,
+	}
+}
+
+func NewPriorityQueueReadOnly(kv kv.KVStoreReader, name string) *ImmutablePriorityQueue {
+	return &ImmutablePriorityQueue{
+		kvr:  kv,
+		name: name,
+	}
+}
+
+func (q *PriorityQueue) Immutable() *ImmutablePriorityQueue {
+	return q.ImmutablePriorityQueue
+}
+
+func (q *ImmutablePriorityQueue) getKey(code byte) kv.Key {
+	var buf bytes.Buffer
+	buf.Write([]byte(q.name))
+	buf.WriteByte(code)
+	return kv.Key(buf.Bytes())
+}
+
+func (q *ImmutablePriorityQueue) getElemKey(idx uint32) kv.Key {
+	var buf bytes.Buffer
+	buf.Write([]byte(q.name))
+	buf.WriteByte(pqElemKeyCode)
+	_ = util.WriteUint32(&buf, idx)
+	return kv.Key(buf.Bytes())
+}
+
+func (e *pqElem) less(other *pqElem) bool {
+	if e.priority != other.priority {
+		return e.priority < other.priority
+	}
+	return e.seq < other.seq
+}
+
+func encodePQElem(e *pqElem) []byte {
+	var buf bytes.Buffer
+	_ = util.WriteInt64(&buf, e.priority)
+	_ = util.WriteUint64(&buf, e.seq)
+	buf.Write(e.value)
+	return buf.Bytes()
+}
+
+func decodePQElem(data []byte) (*pqElem, error) {
+	if len(data) < 16 {
+		return nil, errors.New("corrupted data")
+	}
+	return &pqElem{
+		priority: int64(util.MustUint64From8Bytes(data[:8])),
+		seq:      util.MustUint64From8Bytes(data[8:16]),
+		value:    data[16:],
+	}, nil
+}
+
+// Len == 0/empty/non-existent are equivalent
+func (q *ImmutablePriorityQueue) Len() (uint32, error) {
+	v, err := q.kvr.Get(q.getKey(pqSizeKeyCode))
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return 0, nil
+	}
+	if len(v) != 4 {
+		return 0, errors.New("corrupted data")
+	}
+	return util.MustUint32From4Bytes(v), nil
+}
+
+func (q *ImmutablePriorityQueue) MustLen() uint32 {
+	n, err := q.Len()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func (q *ImmutablePriorityQueue) getElem(idx uint32) (*pqElem, error) {
+	data, err := q.kvr.Get(q.getElemKey(idx))
+	if err != nil {
+		return nil, err
+	}
+	return decodePQElem(data)
+}
+
+// Peek returns the priority and the value of the first element without removing it.
+// The value is nil if the queue is empty
+func (q *ImmutablePriorityQueue) Peek() (int64, []byte, error) {
+	n, err := q.Len()
+	if err != nil || n == 0 {
+		return 0, nil, err
+	}
+	e, err := q.getElem(0)
+	if err != nil {
+		return 0, nil, err
+	}
+	return e.priority, e.value, nil
+}
+
+func (q *ImmutablePriorityQueue) MustPeek() (int64, []byte) {
+	priority, value, err := q.Peek()
+	if err != nil {
+		panic(err)
+	}
+	return priority, value
+}
+
+// Iterate iterates elements in the order they are stored in the heap. The order is deterministic,
+// but it is not the order of priorities
+func (q *ImmutablePriorityQueue) Iterate(f func(priority int64, value []byte) bool) error {
+	n, err := q.Len()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < n; i++ {
+		e, err := q.getElem(i)
+		if err != nil {
+			return err
+		}
+		if !f(e.priority, e.value) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *ImmutablePriorityQueue) MustIterate(f func(priority int64, value []byte) bool) {
+	err := q.Iterate(f)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (q *PriorityQueue) setSize(n uint32) {
+	if n == 0 {
+		q.kvw.Del(q.getKey(pqSizeKeyCode))
+	} else {
+		q.kvw.Set(q.getKey(pqSizeKeyCode), util.Uint32To4Bytes(n))
+	}
+}
+
+func (q *PriorityQueue) setElem(idx uint32, e *pqElem) {
+	q.kvw.Set(q.getElemKey(idx), encodePQElem(e))
+}
+
+func (q *PriorityQueue) nextSeq() (uint64, error) {
+	v, err := q.kvr.Get(q.getKey(pqSeqKeyCode))
+	if err != nil {
+		return 0, err
+	}
+	var seq uint64
+	if v != nil {
+		if seq, err = util.Uint64From8Bytes(v); err != nil {
+			return 0, err
+		}
+	}
+	q.kvw.Set(q.getKey(pqSeqKeyCode), util.Uint64To8Bytes(seq+1))
+	return seq, nil
+}
+
+// Push adds the value with the priority to the queue
+func (q *PriorityQueue) Push(priority int64, value []byte) error {
+	n, err := q.Len()
+	if err != nil {
+		return err
+	}
+	if n == ^uint32(0) {
+		return errors.New("priority queue is full")
+	}
+	seq, err := q.nextSeq()
+	if err != nil {
+		return err
+	}
+	e := &pqElem{priority: priority, seq: seq, value: value}
+	// sift up
+	idx := n
+	for idx > 0 {
+		parentIdx := (idx - 1) / 2
+		parent, err := q.getElem(parentIdx)
+		if err != nil {
+			return err
+		}
+		if !e.less(parent) {
+			break
+		}
+		q.setElem(idx, parent)
+		idx = parentIdx
+	}
+	q.setElem(idx, e)
+	q.setSize(n + 1)
+	return nil
+}
+
+func (q *PriorityQueue) MustPush(priority int64, value []byte) {
+	err := q.Push(priority, value)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Pop removes the first element from the queue and returns its priority and value.
+// The value is nil if the queue is empty
+func (q *PriorityQueue) Pop() (int64, []byte, error) {
+	n, err := q.Len()
+	if err != nil || n == 0 {
+		return 0, nil, err
+	}
+	first, err := q.getElem(0)
+	if err != nil {
+		return 0, nil, err
+	}
+	last, err := q.getElem(n - 1)
+	if err != nil {
+		return 0, nil, err
+	}
+	q.kvw.Del(q.getElemKey(n - 1))
+	n--
+	q.setSize(n)
+	if n == 0 {
+		q.kvw.Del(q.getKey(pqSeqKeyCode))
+		return first.priority, first.value, nil
+	}
+	// sift down
+	idx := uint32(0)
+	for {
+		childIdx := 2*idx + 1
+		if childIdx >= n || childIdx < idx {
+			break
+		}
+		child, err := q.getElem(childIdx)
+		if err != nil {
+			return 0, nil, err
+		}
+		if childIdx+1 < n {
+			right, err := q.getElem(childIdx + 1)
+			if err != nil {
+				return 0, nil, err
+			}
+			if right.less(child) {
+				childIdx, child = childIdx+1, right
+			}
+		}
+		if !child.less(last) {
+			break
+		}
+		q.setElem(idx, child)
+		idx = childIdx
+	}
+	q.setElem(idx, last)
+	return first.priority, first.value, nil
+}
+
+func (q *PriorityQueue) MustPop() (int64, []byte) {
+	priority, value, err := q.Pop()
+	if err != nil {
+		panic(err)
+	}
+	return priority, value
+}
+
+// Erase deletes all elements of the queue with one prefix deletion
+func (q *PriorityQueue) Erase() {
+	q.kvw.DelPrefix(q.getKey(pqElemKeyCode))
+	q.kvw.Del(q.getKey(pqSizeKeyCode))
+	q.kvw.Del(q.getKey(pqSeqKeyCode))
+}
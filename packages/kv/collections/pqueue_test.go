@@ -0,0 +1,70 @@
+package collections
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	vars := dict.New()
+	q := NewPriorityQueue(vars, "testQueue")
+	require.Zero(t, q.MustLen())
+	_, v := q.MustPop()
+	require.Nil(t, v)
+
+	priorities := []int64{5, -3, 8, 0, 5, 13, -3, 2, 5}
+	for i, p := range priorities {
+		q.MustPush(p, []byte{byte(i)})
+	}
+	require.EqualValues(t, len(priorities), q.MustLen())
+	p, v := q.MustPeek()
+	require.EqualValues(t, -3, p)
+	require.EqualValues(t, []byte{1}, v)
+
+	// equal priorities are popped in the order of pushes
+	expected := []struct {
+		priority int64
+		value    byte
+	}{{-3, 1}, {-3, 6}, {0, 3}, {2, 7}, {5, 0}, {5, 4}, {5, 8}, {8, 2}, {13, 5}}
+	for _, e := range expected {
+		p, v := q.MustPop()
+		require.EqualValues(t, e.priority, p)
+		require.EqualValues(t, []byte{e.value}, v)
+	}
+	require.Zero(t, q.MustLen())
+	require.Empty(t, vars)
+}
+
+func TestPriorityQueueInterleaved(t *testing.T) {
+	vars := dict.New()
+	q := NewPriorityQueue(vars, "testQueue")
+	q.MustPush(10, []byte("a"))
+	q.MustPush(1, []byte("b"))
+	_, v := q.MustPop()
+	require.EqualValues(t, "b", string(v))
+	q.MustPush(5, []byte("c"))
+	q.MustPush(20, nil)
+
+	n := 0
+	q.Immutable().MustIterate(func(priority int64, value []byte) bool {
+		n++
+		return true
+	})
+	require.EqualValues(t, 3, n)
+
+	_, v = q.MustPop()
+	require.EqualValues(t, "c", string(v))
+	_, v = q.MustPop()
+	require.EqualValues(t, "a", string(v))
+	p, v := q.MustPop()
+	require.EqualValues(t, 20, p)
+	require.NotNil(t, v)
+	require.Empty(t, v)
+
+	q.MustPush(1, []byte("d"))
+	q.Erase()
+	require.Zero(t, q.MustLen())
+	require.Empty(t, vars)
+}
@@ -0,0 +1,427 @@
+package collections
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// SortedMap represents a key-value collection in a kv.KVStore which is iterated in the ascending order of keys.
+// Keys are linked into a skip list. The level of each key is derived from the hash of the key, so
+// the structure is deterministic and doesn't depend on the order of insertions.
+// Get and Has access one key, Set and Del access O(log n) keys on average
+type SortedMap struct {
+	*ImmutableSortedMap
+	kvw kv.KVStoreWriter
+}
+
+// ImmutableSortedMap provides read-only access to a SortedMap in a kv.KVStoreReader.
+type ImmutableSortedMap struct {
+	kvr  kv.KVStoreReader
+	name string
+}
+
+const (
+	smSizeKeyCode = byte(iota)
+	smElemKeyCode
+	smNodeKeyCode
+	smHeadKeyCode
+)
+
+// SortedMapMaxLevel is the maximum number of levels of the skip list. With the branching factor of 4 it is
+// enough for 2^32 keys
+const SortedMapMaxLevel = 16
+
+// smLinks are forward links of the node of the skip list, one per level. nil means no next key
+type smLinks [][]byte
+
+func NewSortedMap(kv kv.KVStore, name string) *SortedMap {
+	return &SortedMap{
+		ImmutableSortedMap: NewSortedMapReadOnly(kv, name),
+		kvw:                kv,
+	}
+}
+
+func NewSortedMapReadOnly(kv kv.KVStoreReader, name string) *ImmutableSortedMap {
+	return &ImmutableSortedMap{
+		kvr:  kv,
+		name: name,
+	}
+}
+
+func (m *SortedMap) Immutable() *ImmutableSortedMap {
+	return m.ImmutableSortedMap
+}
+
+func (m *ImmutableSortedMap) Name() string {
+	return m.name
+}
+
+func (m *ImmutableSortedMap) getKey(code byte, key []byte) kv.Key {
+	var buf bytes.Buffer
+	buf.Write([]byte(m.name))
+	buf.WriteByte(code)
+	buf.Write(key)
+	return kv.Key(buf.Bytes())
+}
+
+// sortedMapLevel returns the level of the key in the skip list: 1 + the number of leading zero bit pairs
+// of the hash of the key, i.e. the branching factor is 4
+func sortedMapLevel(key []byte) int {
+	h := hashing.HashData(key)
+	level := 1
+	for _, b := range h[:SortedMapMaxLevel/4] {
+		for shift := 6; shift >= 0; shift -= 2 {
+			if (b>>uint(shift))&0x03 != 0 {
+				return level
+			}
+			level++
+			if level == SortedMapMaxLevel {
+				return level
+			}
+		}
+	}
+	return level
+}
+
+func encodeSMLinks(links smLinks) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(byte(len(links)))
+	for _, next := range links {
+		_ = util.WriteBoolByte(&buf, next != nil)
+		if next != nil {
+			_ = util.WriteBytes16(&buf, next)
+		}
+	}
+	return buf.Bytes()
+}
+
+func decodeSMLinks(data []byte) (smLinks, error) {
+	r := bytes.NewReader(data)
+	n, err := util.ReadByte(r)
+	if err != nil {
+		return nil, err
+	}
+	ret := make(smLinks, n)
+	for i := range ret {
+		var present bool
+		if err := util.ReadBoolByte(r, &present); err != nil {
+			return nil, err
+		}
+		if !present {
+			continue
+		}
+		if ret[i], err = util.ReadBytes16(r); err != nil {
+			return nil, err
+		}
+		if ret[i] == nil {
+			ret[i] = []byte{}
+		}
+	}
+	return ret, nil
+}
+
+// getLinks returns forward links of the node of the key, or of the head of the list if the key is nil
+func (m *ImmutableSortedMap) getLinks(key []byte) (smLinks, error) {
+	var data []byte
+	var err error
+	if key == nil {
+		data, err = m.kvr.Get(m.getKey(smHeadKeyCode, nil))
+		if err == nil && data == nil {
+			return make(smLinks, SortedMapMaxLevel), nil
+		}
+	} else {
+		data, err = m.kvr.Get(m.getKey(smNodeKeyCode, key))
+		if err == nil && data == nil {
+			return nil, errors.New("corrupted data: skip list node is missing")
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSMLinks(data)
+}
+
+// findPredecessors returns, for each level, the last key less than the key (nil for the head)
+// together with its forward links
+func (m *ImmutableSortedMap) findPredecessors(key []byte) ([][]byte, []smLinks, error) {
+	preds := make([][]byte, SortedMapMaxLevel)
+	predLinks := make([]smLinks, SortedMapMaxLevel)
+	var cur []byte
+	curLinks, err := m.getLinks(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	for level := SortedMapMaxLevel - 1; level >= 0; level-- {
+		for level < len(curLinks) && curLinks[level] != nil && bytes.Compare(curLinks[level], key) < 0 {
+			cur = curLinks[level]
+			if curLinks, err = m.getLinks(cur); err != nil {
+				return nil, nil, err
+			}
+		}
+		preds[level] = cur
+		predLinks[level] = curLinks
+	}
+	return preds, predLinks, nil
+}
+
+func (m *ImmutableSortedMap) GetAt(key []byte) ([]byte, error) {
+	return m.kvr.Get(m.getKey(smElemKeyCode, key))
+}
+
+func (m *ImmutableSortedMap) MustGetAt(key []byte) []byte {
+	ret, err := m.GetAt(key)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+func (m *ImmutableSortedMap) HasAt(key []byte) (bool, error) {
+	return m.kvr.Has(m.getKey(smElemKeyCode, key))
+}
+
+func (m *ImmutableSortedMap) MustHasAt(key []byte) bool {
+	ret, err := m.HasAt(key)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+func (m *ImmutableSortedMap) Len() (uint32, error) {
+	v, err := m.kvr.Get(m.getKey(smSizeKeyCode, nil))
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return 0, nil
+	}
+	if len(v) != 4 {
+		return 0, errors.New("corrupted data")
+	}
+	return util.MustUint32From4Bytes(v), nil
+}
+
+func (m *ImmutableSortedMap) MustLen() uint32 {
+	n, err := m.Len()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// IterateFrom iterates keys greater or equal to the key in the ascending order. nil key means from the first key
+func (m *ImmutableSortedMap) IterateFrom(key []byte, f func(elemKey []byte, value []byte) bool) error {
+	var next []byte
+	if key == nil {
+		links, err := m.getLinks(nil)
+		if err != nil {
+			return err
+		}
+		next = links[0]
+	} else {
+		_, predLinks, err := m.findPredecessors(key)
+		if err != nil {
+			return err
+		}
+		next = predLinks[0][0]
+	}
+	for next != nil {
+		value, err := m.GetAt(next)
+		if err != nil {
+			return err
+		}
+		if !f(next, value) {
+			return nil
+		}
+		links, err := m.getLinks(next)
+		if err != nil {
+			return err
+		}
+		next = links[0]
+	}
+	return nil
+}
+
+// Iterate iterates all keys in the ascending order
+func (m *ImmutableSortedMap) Iterate(f func(elemKey []byte, value []byte) bool) error {
+	return m.IterateFrom(nil, f)
+}
+
+func (m *ImmutableSortedMap) MustIterate(f func(elemKey []byte, value []byte) bool) {
+	err := m.Iterate(f)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (m *ImmutableSortedMap) MustIterateFrom(key []byte, f func(elemKey []byte, value []byte) bool) {
+	err := m.IterateFrom(key, f)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// First returns the least key of the map, or nil if the map is empty
+func (m *ImmutableSortedMap) First() ([]byte, error) {
+	links, err := m.getLinks(nil)
+	if err != nil {
+		return nil, err
+	}
+	return links[0], nil
+}
+
+// Last returns the greatest key of the map, or nil if the map is empty
+func (m *ImmutableSortedMap) Last() ([]byte, error) {
+	var cur []byte
+	curLinks, err := m.getLinks(nil)
+	if err != nil {
+		return nil, err
+	}
+	for level := SortedMapMaxLevel - 1; level >= 0; level-- {
+		for level < len(curLinks) && curLinks[level] != nil {
+			cur = curLinks[level]
+			if curLinks, err = m.getLinks(cur); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return cur, nil
+}
+
+func (m *SortedMap) setLinks(key []byte, links smLinks) {
+	if key == nil {
+		m.kvw.Set(m.getKey(smHeadKeyCode, nil), encodeSMLinks(links))
+	} else {
+		m.kvw.Set(m.getKey(smNodeKeyCode, key), encodeSMLinks(links))
+	}
+}
+
+func (m *SortedMap) addToSize(amount int) error {
+	n, err := m.Len()
+	if err != nil {
+		return err
+	}
+	n = uint32(int(n) + amount)
+	if n == 0 {
+		m.kvw.Del(m.getKey(smSizeKeyCode, nil))
+	} else {
+		m.kvw.Set(m.getKey(smSizeKeyCode, nil), util.Uint32To4Bytes(n))
+	}
+	return nil
+}
+
+// linkKey inserts the key into the skip list. Predecessors are modified in place
+func (m *SortedMap) linkKey(key []byte) error {
+	preds, predLinks, err := m.findPredecessors(key)
+	if err != nil {
+		return err
+	}
+	level := sortedMapLevel(key)
+	links := make(smLinks, level)
+	for i := 0; i < level; i++ {
+		links[i] = predLinks[i][i]
+		predLinks[i][i] = key
+	}
+	m.setLinks(key, links)
+	// the same predecessor may be modified at several levels, it is written once
+	for i := 0; i < level; i++ {
+		if i > 0 && bytes.Equal(preds[i], preds[i-1]) && (preds[i] == nil) == (preds[i-1] == nil) {
+			continue
+		}
+		m.setLinks(preds[i], predLinks[i])
+	}
+	return nil
+}
+
+// unlinkKey removes the key from the skip list
+func (m *SortedMap) unlinkKey(key []byte) error {
+	preds, predLinks, err := m.findPredecessors(key)
+	if err != nil {
+		return err
+	}
+	links, err := m.getLinks(key)
+	if err != nil {
+		return err
+	}
+	for i := range links {
+		predLinks[i][i] = links[i]
+	}
+	for i := range links {
+		if i > 0 && bytes.Equal(preds[i], preds[i-1]) && (preds[i] == nil) == (preds[i-1] == nil) {
+			continue
+		}
+		m.setLinks(preds[i], predLinks[i])
+	}
+	m.kvw.Del(m.getKey(smNodeKeyCode, key))
+	return nil
+}
+
+func (m *SortedMap) SetAt(key []byte, value []byte) error {
+	if key == nil {
+		key = []byte{}
+	}
+	if len(key) > math.MaxUint16 {
+		return fmt.Errorf("key of the sorted map is too long: %d bytes", len(key))
+	}
+	ok, err := m.HasAt(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err = m.linkKey(key); err != nil {
+			return err
+		}
+		if err = m.addToSize(1); err != nil {
+			return err
+		}
+	}
+	m.kvw.Set(m.getKey(smElemKeyCode, key), value)
+	return nil
+}
+
+func (m *SortedMap) MustSetAt(key []byte, value []byte) {
+	err := m.SetAt(key, value)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (m *SortedMap) DelAt(key []byte) error {
+	if key == nil {
+		key = []byte{}
+	}
+	ok, err := m.HasAt(key)
+	if err != nil || !ok {
+		return err
+	}
+	if err = m.unlinkKey(key); err != nil {
+		return err
+	}
+	if err = m.addToSize(-1); err != nil {
+		return err
+	}
+	m.kvw.Del(m.getKey(smElemKeyCode, key))
+	return nil
+}
+
+func (m *SortedMap) MustDelAt(key []byte) {
+	err := m.DelAt(key)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Erase deletes all elements of the map with prefix deletions
+func (m *SortedMap) Erase() {
+	m.kvw.DelPrefix(m.getKey(smElemKeyCode, nil))
+	m.kvw.DelPrefix(m.getKey(smNodeKeyCode, nil))
+	m.kvw.Del(m.getKey(smHeadKeyCode, nil))
+	m.kvw.Del(m.getKey(smSizeKeyCode, nil))
+}
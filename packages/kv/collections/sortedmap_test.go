@@ -0,0 +1,96 @@
+package collections
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/stretchr/testify/require"
+)
+
+func sortedKeys(m *ImmutableSortedMap) []string {
+	ret := make([]string, 0)
+	m.MustIterate(func(elemKey []byte, value []byte) bool {
+		ret = append(ret, string(elemKey))
+		return true
+	})
+	return ret
+}
+
+func TestSortedMap(t *testing.T) {
+	vars := dict.New()
+	m := NewSortedMap(vars, "testMap")
+	require.Zero(t, m.MustLen())
+	require.Empty(t, sortedKeys(m.Immutable()))
+
+	m.MustSetAt([]byte("k2"), []byte("v2"))
+	m.MustSetAt([]byte("k3"), []byte("v3"))
+	m.MustSetAt([]byte("k1"), []byte("v1"))
+	m.MustSetAt([]byte("k2"), []byte("v2'"))
+	require.EqualValues(t, 3, m.MustLen())
+	require.EqualValues(t, []string{"k1", "k2", "k3"}, sortedKeys(m.Immutable()))
+	require.EqualValues(t, "v2'", string(m.MustGetAt([]byte("k2"))))
+	require.True(t, m.MustHasAt([]byte("k3")))
+	require.False(t, m.MustHasAt([]byte("k4")))
+
+	first, err := m.First()
+	require.NoError(t, err)
+	require.EqualValues(t, "k1", string(first))
+	last, err := m.Last()
+	require.NoError(t, err)
+	require.EqualValues(t, "k3", string(last))
+
+	from := make([]string, 0)
+	m.MustIterateFrom([]byte("k15"), func(elemKey []byte, value []byte) bool {
+		from = append(from, string(elemKey))
+		return true
+	})
+	require.EqualValues(t, []string{"k2", "k3"}, from)
+
+	m.MustDelAt([]byte("k2"))
+	m.MustDelAt([]byte("k4"))
+	require.EqualValues(t, 2, m.MustLen())
+	require.EqualValues(t, []string{"k1", "k3"}, sortedKeys(m.Immutable()))
+
+	m.Erase()
+	require.Zero(t, m.MustLen())
+	require.Empty(t, vars)
+}
+
+func TestSortedMapRandom(t *testing.T) {
+	const n = 500
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", rand.Intn(1000000))
+	}
+
+	vars := dict.New()
+	m := NewSortedMap(vars, "testMap")
+	expected := make(map[string]bool)
+	for _, k := range keys {
+		m.MustSetAt([]byte(k), []byte(k))
+		expected[k] = true
+	}
+	// delete every third key
+	for i := 0; i < n; i += 3 {
+		m.MustDelAt([]byte(keys[i]))
+		delete(expected, keys[i])
+	}
+	sorted := make([]string, 0, len(expected))
+	for k := range expected {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	require.EqualValues(t, len(sorted), m.MustLen())
+	require.EqualValues(t, sorted, sortedKeys(m.Immutable()))
+
+	// the structure doesn't depend on the order of insertions
+	vars2 := dict.New()
+	m2 := NewSortedMap(vars2, "testMap")
+	for i := len(sorted) - 1; i >= 0; i-- {
+		m2.MustSetAt([]byte(sorted[i]), []byte(sorted[i]))
+	}
+	require.EqualValues(t, vars.Hash(), vars2.Hash())
+}
@@ -54,6 +54,7 @@ func NewTimestampedLogReadOnly(kv kv.KVStoreReader, name kv.Key) *ImmutableTimes
 const (
 	tslSizeKeyCode = byte(iota)
 	tslElemKeyCode
+	tslFirstKeyCode
 )
 
 func (l *TimestampedLog) Immutable() *ImmutableTimestampedLog {
@@ -67,6 +68,13 @@ func (l *ImmutableTimestampedLog) getSizeKey() kv.Key {
 	return kv.Key(buf.Bytes())
 }
 
+func (l *ImmutableTimestampedLog) getFirstKey() kv.Key {
+	var buf bytes.Buffer
+	buf.Write([]byte(l.name))
+	buf.WriteByte(tslFirstKeyCode)
+	return kv.Key(buf.Bytes())
+}
+
 func (l *ImmutableTimestampedLog) getElemKey(idx uint32) kv.Key {
 	var buf bytes.Buffer
 	buf.Write([]byte(l.name))
@@ -92,6 +100,8 @@ func (l *TimestampedLog) addToSize(amount int) (uint32, error) {
 	return prevSize, nil
 }
 
+// Len returns number of records ever appended to the log, i.e. the index of the next record.
+// Records with indices less than FirstIndex may have been pruned
 func (l *ImmutableTimestampedLog) Len() (uint32, error) {
 	v, err := l.kvr.Get(l.getSizeKey())
 	if err != nil {
@@ -114,6 +124,50 @@ func (l *ImmutableTimestampedLog) MustLen() uint32 {
 	return n
 }
 
+// FirstIndex returns index of the earliest record which was not pruned
+func (l *ImmutableTimestampedLog) FirstIndex() (uint32, error) {
+	v, err := l.kvr.Get(l.getFirstKey())
+	if err != nil {
+		return 0, err
+	}
+	if v == nil {
+		return 0, nil
+	}
+	if len(v) != 4 {
+		return 0, errors.New("corrupted data")
+	}
+	return util.MustUint32From4Bytes(v), nil
+}
+
+func (l *ImmutableTimestampedLog) MustFirstIndex() uint32 {
+	n, err := l.FirstIndex()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// NumRecords returns number of records in the log which were not pruned
+func (l *ImmutableTimestampedLog) NumRecords() (uint32, error) {
+	n, err := l.Len()
+	if err != nil {
+		return 0, err
+	}
+	first, err := l.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	return n - first, nil
+}
+
+func (l *ImmutableTimestampedLog) MustNumRecords() uint32 {
+	n, err := l.NumRecords()
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
 // Append appends data with timestamp to the end of the log.
 // Returns error if timestamp is inconsistent, i.e. less than the latest timestamp
 func (l *TimestampedLog) Append(ts int64, data []byte) error {
@@ -163,7 +217,12 @@ func (l *ImmutableTimestampedLog) latest() (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	if idx == 0 {
+	first, err := l.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	if idx == first {
+		// empty or all records pruned
 		return 0, nil
 	}
 	data, err := l.kvr.Get(l.getElemKey(idx - 1))
@@ -182,10 +241,14 @@ func (l *ImmutableTimestampedLog) Earliest() (int64, error) {
 	if err != nil {
 		return 0, err
 	}
-	if n == 0 {
+	first, err := l.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	if n == first {
 		return 0, nil
 	}
-	data, err := l.kvr.Get(l.getElemKey(0))
+	data, err := l.kvr.Get(l.getElemKey(first))
 	if err != nil {
 		return 0, err
 	}
@@ -211,6 +274,14 @@ func (l *ImmutableTimestampedLog) getRawRecordAtIndex(idx uint32) ([]byte, error
 	if idx >= n {
 		return nil, nil
 	}
+	first, err := l.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+	if idx < first {
+		// pruned
+		return nil, nil
+	}
 	v, err := l.kvr.Get(l.getElemKey(idx))
 	if err != nil {
 		return nil, err
@@ -284,7 +355,11 @@ func (l *ImmutableTimestampedLog) TakeTimeSlice(fromTs, toTs int64) (*TimeSlice,
 	if err != nil {
 		return nil, err
 	}
-	if n == 0 {
+	first, err := l.FirstIndex()
+	if err != nil {
+		return nil, err
+	}
+	if n == first {
 		// empty slice
 		return nil, nil
 	}
@@ -305,14 +380,14 @@ func (l *ImmutableTimestampedLog) TakeTimeSlice(fromTs, toTs int64) (*TimeSlice,
 	if fromTs > toTs {
 		return nil, nil
 	}
-	lowerIdx, ok, err := l.findLowerIdx(fromTs, 0, n-1)
+	lowerIdx, ok, err := l.findLowerIdx(fromTs, first, n-1)
 	if err != nil {
 		return nil, err
 	}
 	if !ok {
 		return nil, nil
 	}
-	upperIdx, ok, err := l.findUpperIdx(toTs, 0, n-1)
+	upperIdx, ok, err := l.findUpperIdx(toTs, first, n-1)
 	if err != nil {
 		return nil, err
 	}
@@ -463,10 +538,82 @@ func (l *ImmutableTimestampedLog) findUpperIdx(ts int64, fromIdx, toIdx uint32)
 	return l.findUpperIdx(ts, fromIdx, middleIdx)
 }
 
-// TODO not finished with Erase
+// PruneHead deletes at most n earliest records from the log. Indices of the remaining records do not change.
+// Returns number of deleted records
+func (l *TimestampedLog) PruneHead(n uint32) (uint32, error) {
+	numRecords, err := l.NumRecords()
+	if err != nil {
+		return 0, err
+	}
+	if n > numRecords {
+		n = numRecords
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	first, err := l.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	for i := first; i < first+n; i++ {
+		l.kvw.Del(l.getElemKey(i))
+	}
+	l.kvw.Set(l.getFirstKey(), util.Uint32To4Bytes(first+n))
+	return n, nil
+}
+
+func (l *TimestampedLog) MustPruneHead(n uint32) uint32 {
+	ret, err := l.PruneHead(n)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
 
+// PruneBefore deletes records with timestamps less than ts, the earliest first, but not more than maxRecords.
+// Returns number of deleted records
+func (l *TimestampedLog) PruneBefore(ts int64, maxRecords uint32) (uint32, error) {
+	n, err := l.Len()
+	if err != nil {
+		return 0, err
+	}
+	first, err := l.FirstIndex()
+	if err != nil {
+		return 0, err
+	}
+	if n == first {
+		return 0, nil
+	}
+	idx, ok, err := l.findLowerIdx(ts, first, n-1)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		// all records are older
+		idx = n
+	}
+	if idx-first > maxRecords {
+		idx = first + maxRecords
+	}
+	return l.PruneHead(idx - first)
+}
+
+func (l *TimestampedLog) MustPruneBefore(ts int64, maxRecords uint32) uint32 {
+	ret, err := l.PruneBefore(ts, maxRecords)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// Erase deletes all records of the log with one prefix deletion
 func (l *TimestampedLog) Erase() {
-	panic("implement me")
+	var buf bytes.Buffer
+	buf.Write([]byte(l.name))
+	buf.WriteByte(tslElemKeyCode)
+	l.kvw.DelPrefix(kv.Key(buf.Bytes()))
+	l.kvw.Del(l.getFirstKey())
+	l.setSize(0)
 }
 
 func (sl *TimeSlice) FromToIndices() (uint32, uint32) {
@@ -1,6 +1,7 @@
 package collections
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -109,3 +110,43 @@ func TestTlogBig(t *testing.T) {
 	assert.EqualValues(t, tl.MustLen(), tslice.NumPoints())
 	assert.EqualValues(t, tl.MustLen(), tslice.NumPoints())
 }
+
+func TestTlogPrune(t *testing.T) {
+	vars := dict.New()
+	tl := NewTimestampedLog(vars, "testTimestampedlog")
+
+	initLog(t, tl)
+	earliest := tl.MustEarliest()
+	latest := tl.MustLatest()
+
+	assert.EqualValues(t, 10, tl.MustPruneHead(10))
+	assert.EqualValues(t, numPoints, tl.MustLen())
+	assert.EqualValues(t, 10, tl.MustFirstIndex())
+	assert.EqualValues(t, numPoints-10, tl.MustNumRecords())
+	assert.EqualValues(t, earliest+2*step, tl.MustEarliest())
+
+	tsl := tl.MustTakeTimeSlice(0, 0)
+	assert.EqualValues(t, numPoints-10, tsl.NumPoints())
+	first, last := tsl.FromToIndices()
+	assert.EqualValues(t, 10, first)
+	assert.EqualValues(t, numPoints-1, last)
+
+	// nothing to prune
+	assert.EqualValues(t, 0, tl.MustPruneBefore(earliest, math.MaxUint32))
+
+	// the number of pruned records is bounded
+	assert.EqualValues(t, 1, tl.MustPruneBefore(earliest+5*step, 1))
+	assert.EqualValues(t, 11, tl.MustFirstIndex())
+
+	pruned := tl.MustPruneBefore(earliest+5*step, math.MaxUint32)
+	assert.EqualValues(t, 3*changeTsEvery-1, pruned)
+	assert.EqualValues(t, earliest+5*step, tl.MustEarliest())
+
+	assert.EqualValues(t, numPoints-25, tl.MustPruneBefore(latest+1, math.MaxUint32))
+	assert.Zero(t, tl.MustNumRecords())
+	assert.Nil(t, tl.MustTakeTimeSlice(0, 0))
+
+	tl.MustAppend(latest, []byte("datum"))
+	assert.EqualValues(t, 1, tl.MustNumRecords())
+	assert.EqualValues(t, latest, tl.MustEarliest())
+}
@@ -23,11 +23,14 @@ func New(kv kv.KVStoreReader, log ...coretypes.LogInterface) decoder {
 	return decoder{kv, l}
 }
 
+// panic panics with the VMError of the code VMErrorParamDecodeFailed, so that the failure of the call
+// due to the wrong or missing parameter is classified in the chain log
 func (p *decoder) panic(err error) {
-	if p.log == nil {
-		panic(err)
+	vmErr := coretypes.NewVMError(coretypes.VMErrorParamDecodeFailed, "%v", err)
+	if p.log != nil {
+		p.log.Infof("%v", vmErr)
 	}
-	p.log.Panicf("%v", err)
+	panic(vmErr)
 }
 
 func (p *decoder) GetInt64(key kv.Key, def ...int64) (int64, error) {
@@ -21,6 +21,10 @@ func (s *subrealm) Del(key kv.Key) {
 	s.kv.Del(s.prefix + key)
 }
 
+func (s *subrealm) DelPrefix(prefix kv.Key) {
+	s.kv.DelPrefix(s.prefix + prefix)
+}
+
 // Get returns the value, or nil if not found
 func (s *subrealm) Get(key kv.Key) ([]byte, error) {
 	return s.kv.Get(s.prefix + key)
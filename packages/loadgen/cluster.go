@@ -0,0 +1,69 @@
+package loadgen
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/client/wallet/packages/seed"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/client"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/client/level1"
+	"github.com/iotaledger/wasp/client/multiclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+// DefaultClusterTimeout is the time the cluster target waits until the request is processed by committee nodes
+const DefaultClusterTimeout = 60 * time.Second
+
+type clusterTarget struct {
+	clients   []*chainclient.Client
+	committee *multiclient.MultiClient
+	timeout   time.Duration
+}
+
+// NewClusterTarget returns the target which posts requests to the chain of a live cluster. Senders are derived
+// from the seed and funded by the faucet of the level 1 ledger. Requests are posted through the first of the hosts
+// of the web API and the request is processed when all nodes of the committee at the hosts have processed it
+func NewClusterTarget(level1Client level1.Level1Client, apiHosts []string, chainID coretypes.ChainID, senderSeed *seed.Seed, numSenders int, timeout time.Duration) (Target, error) {
+	if len(apiHosts) == 0 {
+		return nil, fmt.Errorf("no hosts of the web API")
+	}
+	if timeout == 0 {
+		timeout = DefaultClusterTimeout
+	}
+	waspClient := client.NewWaspClient(apiHosts[0])
+	ret := &clusterTarget{
+		clients:   make([]*chainclient.Client, numSenders),
+		committee: multiclient.New(apiHosts),
+		timeout:   timeout,
+	}
+	for i := range ret.clients {
+		addr := senderSeed.Address(uint64(i)).Address
+		if err := level1Client.RequestFunds(&addr); err != nil {
+			return nil, fmt.Errorf("requesting funds for sender %d: %v", i, err)
+		}
+		sigScheme := signaturescheme.ED25519(*senderSeed.KeyPair(uint64(i)))
+		ret.clients[i] = chainclient.New(level1Client, waspClient, chainID, sigScheme)
+	}
+	return ret, nil
+}
+
+func (t *clusterTarget) NumSenders() int {
+	return len(t.clients)
+}
+
+func (t *clusterTarget) Post(sender int, contract string, entryPoint string, params dict.Dict, transfer int64) error {
+	par := chainclient.PostRequestParams{Args: requestargs.New(params)}
+	if transfer > 0 {
+		par.Transfer = cbalances.NewIotasOnly(transfer)
+	}
+	tx, err := t.clients[sender].PostRequest(coretypes.Hn(contract), coretypes.Hn(entryPoint), par)
+	if err != nil {
+		return err
+	}
+	return t.committee.WaitUntilAllRequestsProcessed(tx, t.timeout)
+}
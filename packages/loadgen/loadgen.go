@@ -0,0 +1,310 @@
+// package loadgen generates workloads of requests against a chain and measures the latency and the throughput
+// of their processing. The same workload can be run against the Solo chain, which measures the VM alone
+// (see the package solotarget), or against the chain of a live cluster, which includes consensus
+// (see NewClusterTarget). Reports can be saved as JSON to track performance regressions
+package loadgen
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+// ParamPayload is the parameter of each request which contains the random payload of Workload.PayloadSize bytes
+const ParamPayload = "loadgenPayload"
+
+// Call is a kind of requests of the workload
+type Call struct {
+	Contract   string
+	EntryPoint string
+	// Params are the parameters of the request, besides the payload
+	Params dict.Dict
+	// Transfer is the number of iotas transferred by the request
+	Transfer int64
+	// Weight is the share of the call in the workload relative to other calls. 0 is the same as 1
+	Weight int
+}
+
+// String returns the name of the call in reports
+func (c *Call) String() string {
+	return c.Contract + "::" + c.EntryPoint
+}
+
+// Target is the chain the workload is run against
+type Target interface {
+	// NumSenders is the number of senders of requests prepared by the target
+	NumSenders() int
+	// Post sends the request on behalf of the sender with the index from 0 to NumSenders()-1 and blocks
+	// until the request is processed. It returns an error if the request can't be posted or fails.
+	// Post is called concurrently for different senders
+	Post(sender int, contract string, entryPoint string, params dict.Dict, transfer int64) error
+}
+
+// Workload is the description of requests generated by Run
+type Workload struct {
+	// Calls is the mix of requests
+	Calls []Call
+	// Rate is the number of requests started per second. If 0, each sender posts the next request
+	// as soon as the previous one is processed
+	Rate float64
+	// Requests is the total number of requests. If 0, requests are generated until Duration elapses
+	Requests int
+	// Duration limits the time of the run. If 0, the run ends after Requests requests
+	Duration time.Duration
+	// PayloadSize is the size of the random payload in ParamPayload of each request. If 0, there is no payload
+	PayloadSize int
+	// Seed of the pseudo-random generator of the mix and the payloads, so runs are repeatable
+	Seed int64
+}
+
+func (w *Workload) validate() error {
+	if len(w.Calls) == 0 {
+		return errors.New("no calls in the workload")
+	}
+	for i := range w.Calls {
+		if w.Calls[i].Weight < 0 {
+			return fmt.Errorf("call %s: weight can't be negative", w.Calls[i].String())
+		}
+	}
+	if w.Rate < 0 || w.Requests < 0 || w.Duration < 0 || w.PayloadSize < 0 {
+		return errors.New("rate, requests, duration and payload size can't be negative")
+	}
+	if w.Requests == 0 && w.Duration == 0 {
+		return errors.New("either the number of requests or the duration must be set")
+	}
+	return nil
+}
+
+// ParseCalls parses the mix of calls in the form 'contract:entryPoint[:weight],...'
+func ParseCalls(s string) ([]Call, error) {
+	ret := make([]Call, 0)
+	for _, item := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid call '%s', expected 'contract:entryPoint[:weight]'", item)
+		}
+		c := Call{Contract: parts[0], EntryPoint: parts[1]}
+		if len(parts) == 3 {
+			if _, err := fmt.Sscanf(parts[2], "%d", &c.Weight); err != nil || c.Weight < 0 {
+				return nil, fmt.Errorf("invalid weight of call '%s'", item)
+			}
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
+
+// Stats are the latencies of processed requests
+type Stats struct {
+	Requests int `json:"requests"`
+	Failed   int `json:"failed"`
+	// latencies of successful requests, in nanoseconds in JSON
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+
+	latencies []time.Duration
+}
+
+func (s *Stats) add(latency time.Duration, err error) {
+	s.Requests++
+	if err != nil {
+		s.Failed++
+		return
+	}
+	s.latencies = append(s.latencies, latency)
+}
+
+func (s *Stats) compute() {
+	if len(s.latencies) == 0 {
+		return
+	}
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	var sum time.Duration
+	for _, l := range s.latencies {
+		sum += l
+	}
+	s.Mean = sum / time.Duration(len(s.latencies))
+	s.P50 = percentile(s.latencies, 50)
+	s.P90 = percentile(s.latencies, 90)
+	s.P99 = percentile(s.latencies, 99)
+	s.Max = s.latencies[len(s.latencies)-1]
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func (s *Stats) String() string {
+	return fmt.Sprintf("requests: %d failed: %d latency mean: %v p50: %v p90: %v p99: %v max: %v",
+		s.Requests, s.Failed, s.Mean, s.P50, s.P90, s.P99, s.Max)
+}
+
+// Report is the outcome of the run of the workload
+type Report struct {
+	Stats
+	// Elapsed is the time of the run, in nanoseconds in JSON
+	Elapsed time.Duration `json:"elapsed"`
+	// Throughput is the number of successful requests per second
+	Throughput float64 `json:"throughput"`
+	// ByCall are the stats of each call of the workload
+	ByCall map[string]*Stats `json:"byCall"`
+	// LastError is the error of the last failed request
+	LastError string `json:"lastError,omitempty"`
+}
+
+func (r *Report) String() string {
+	ret := fmt.Sprintf("elapsed: %v throughput: %.2f req/s %s", r.Elapsed, r.Throughput, r.Stats.String())
+	names := make([]string, 0, len(r.ByCall))
+	for name := range r.ByCall {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ret += fmt.Sprintf("\n    %s: %s", name, r.ByCall[name].String())
+	}
+	if r.LastError != "" {
+		ret += "\n    last error: " + r.LastError
+	}
+	return ret
+}
+
+type job struct {
+	call    *Call
+	payload []byte
+}
+
+type result struct {
+	call    *Call
+	latency time.Duration
+	err     error
+}
+
+// Run runs the workload against the target and returns the report
+func Run(target Target, w *Workload) (*Report, error) {
+	if err := w.validate(); err != nil {
+		return nil, err
+	}
+	numSenders := target.NumSenders()
+	if numSenders <= 0 {
+		return nil, errors.New("the target has no senders")
+	}
+
+	jobs := make(chan *job, numSenders)
+	results := make(chan *result, numSenders)
+	var wg sync.WaitGroup
+	for i := 0; i < numSenders; i++ {
+		wg.Add(1)
+		go func(sender int) {
+			defer wg.Done()
+			for j := range jobs {
+				params := j.call.Params.Clone()
+				if j.payload != nil {
+					params.Set(ParamPayload, j.payload)
+				}
+				start := time.Now()
+				err := target.Post(sender, j.call.Contract, j.call.EntryPoint, params, j.call.Transfer)
+				results <- &result{call: j.call, latency: time.Since(start), err: err}
+			}
+		}(i)
+	}
+
+	start := time.Now()
+	go generate(w, jobs, start)
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	ret := &Report{ByCall: make(map[string]*Stats)}
+	for r := range results {
+		ret.add(r.latency, r.err)
+		name := r.call.String()
+		if ret.ByCall[name] == nil {
+			ret.ByCall[name] = &Stats{}
+		}
+		ret.ByCall[name].add(r.latency, r.err)
+		if r.err != nil {
+			ret.LastError = r.err.Error()
+		}
+	}
+	ret.Elapsed = time.Since(start)
+	ret.compute()
+	for _, s := range ret.ByCall {
+		s.compute()
+	}
+	if ret.Elapsed > 0 {
+		ret.Throughput = float64(ret.Requests-ret.Failed) / ret.Elapsed.Seconds()
+	}
+	return ret, nil
+}
+
+// generate sends jobs of the workload to senders at the rate of the workload and closes the channel at the end
+func generate(w *Workload, jobs chan<- *job, start time.Time) {
+	defer close(jobs)
+
+	rnd := rand.New(rand.NewSource(w.Seed))
+	totalWeight := 0
+	for i := range w.Calls {
+		totalWeight += weight(&w.Calls[i])
+	}
+	var deadline <-chan time.Time
+	if w.Duration > 0 {
+		deadline = time.After(w.Duration - time.Since(start))
+	}
+	var ticker *time.Ticker
+	if w.Rate > 0 {
+		ticker = time.NewTicker(time.Duration(float64(time.Second) / w.Rate))
+		defer ticker.Stop()
+	}
+	for n := 0; w.Requests == 0 || n < w.Requests; n++ {
+		j := &job{call: pick(w.Calls, totalWeight, rnd)}
+		if w.PayloadSize > 0 {
+			j.payload = make([]byte, w.PayloadSize)
+			rnd.Read(j.payload)
+		}
+		if ticker != nil {
+			select {
+			case <-ticker.C:
+			case <-deadline:
+				return
+			}
+		}
+		select {
+		case jobs <- j:
+		case <-deadline:
+			return
+		}
+	}
+}
+
+func weight(c *Call) int {
+	if c.Weight == 0 {
+		return 1
+	}
+	return c.Weight
+}
+
+func pick(calls []Call, totalWeight int, rnd *rand.Rand) *Call {
+	n := rnd.Intn(totalWeight)
+	for i := range calls {
+		n -= weight(&calls[i])
+		if n < 0 {
+			return &calls[i]
+		}
+	}
+	return &calls[len(calls)-1]
+}
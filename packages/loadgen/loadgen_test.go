@@ -0,0 +1,95 @@
+package loadgen
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCalls(t *testing.T) {
+	calls, err := ParseCalls("inccounter:increment:3, accounts:deposit")
+	require.NoError(t, err)
+	require.Equal(t, []Call{
+		{Contract: "inccounter", EntryPoint: "increment", Weight: 3},
+		{Contract: "accounts", EntryPoint: "deposit"},
+	}, calls)
+
+	_, err = ParseCalls("inccounter")
+	require.Error(t, err)
+	_, err = ParseCalls("inccounter:increment:x")
+	require.Error(t, err)
+}
+
+func TestPercentile(t *testing.T) {
+	s := &Stats{}
+	for i := 100; i >= 1; i-- {
+		s.add(time.Duration(i), nil)
+	}
+	s.add(0, errors.New("failed"))
+	s.compute()
+	require.EqualValues(t, 101, s.Requests)
+	require.EqualValues(t, 1, s.Failed)
+	require.EqualValues(t, 50, s.P50)
+	require.EqualValues(t, 90, s.P90)
+	require.EqualValues(t, 99, s.P99)
+	require.EqualValues(t, 100, s.Max)
+	require.EqualValues(t, 50, s.Mean)
+}
+
+type mockTarget struct {
+	mutex    sync.Mutex
+	posted   map[string]int
+	payloads map[int]bool
+}
+
+func (t *mockTarget) NumSenders() int {
+	return 4
+}
+
+func (t *mockTarget) Post(sender int, contract string, entryPoint string, params dict.Dict, transfer int64) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.posted[contract+"::"+entryPoint]++
+	t.payloads[len(params.MustGet(ParamPayload))] = true
+	if contract == "fail" {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestRunMix(t *testing.T) {
+	target := &mockTarget{posted: make(map[string]int), payloads: make(map[int]bool)}
+	report, err := Run(target, &Workload{
+		Calls:       []Call{{Contract: "ok", EntryPoint: "a", Weight: 3}, {Contract: "fail", EntryPoint: "b"}},
+		Requests:    1000,
+		PayloadSize: 10,
+		Seed:        rand.Int63(),
+	})
+	require.NoError(t, err)
+	require.EqualValues(t, 1000, report.Requests)
+	require.EqualValues(t, target.posted["ok::a"], report.ByCall["ok::a"].Requests)
+	require.EqualValues(t, target.posted["fail::b"], report.Failed)
+	require.InDelta(t, 750, target.posted["ok::a"], 100)
+	require.Equal(t, map[int]bool{10: true}, target.payloads)
+	require.Equal(t, "failed", report.LastError)
+}
+
+func TestRunDuration(t *testing.T) {
+	target := &mockTarget{posted: make(map[string]int), payloads: make(map[int]bool)}
+	report, err := Run(target, &Workload{
+		Calls:    []Call{{Contract: "ok", EntryPoint: "a"}},
+		Rate:     100,
+		Duration: 300 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	require.InDelta(t, 30, report.Requests, 10)
+	require.EqualValues(t, 0, report.Failed)
+
+	_, err = Run(target, &Workload{Calls: []Call{{Contract: "ok", EntryPoint: "a"}}})
+	require.Error(t, err)
+}
@@ -0,0 +1,43 @@
+// package solotarget runs workloads of the load generator against the Solo chain, which measures
+// the performance of the VM without consensus and networking
+package solotarget
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/loadgen"
+	"github.com/iotaledger/wasp/packages/solo"
+)
+
+type soloTarget struct {
+	chain   *solo.Chain
+	senders []signaturescheme.SignatureScheme
+}
+
+// New returns the target which posts requests to the Solo chain on behalf of numSenders new senders with funds.
+// Solo processes requests of the chain one by one, so concurrent senders only add the waiting time to the latency.
+// Each request spends 1 iota of the sender besides the transfer, so a sender can post up to solo.Saldo requests
+func New(chain *solo.Chain, numSenders int) loadgen.Target {
+	ret := &soloTarget{
+		chain:   chain,
+		senders: make([]signaturescheme.SignatureScheme, numSenders),
+	}
+	for i := range ret.senders {
+		ret.senders[i] = chain.Env.NewSignatureSchemeWithFunds()
+	}
+	return ret
+}
+
+func (t *soloTarget) NumSenders() int {
+	return len(t.senders)
+}
+
+func (t *soloTarget) Post(sender int, contract string, entryPoint string, params dict.Dict, transfer int64) error {
+	req := solo.NewCallParamsFromDic(contract, entryPoint, params)
+	if transfer > 0 {
+		req.WithTransfer(balance.ColorIOTA, transfer)
+	}
+	_, err := t.chain.PostRequestSync(req, t.senders[sender])
+	return err
+}
@@ -0,0 +1,40 @@
+package solotarget
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/loadgen"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoloLoad(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	report, err := loadgen.Run(New(chain, 3), &loadgen.Workload{
+		Calls: []loadgen.Call{
+			{Contract: accounts.Interface.Name, EntryPoint: accounts.FuncDeposit, Transfer: 1, Weight: 3},
+			// only the chain owner can set the utility price
+			{Contract: root.Interface.Name, EntryPoint: root.FuncSetUtilityPrice},
+		},
+		Requests:    40,
+		PayloadSize: 100,
+		Seed:        1,
+	})
+	require.NoError(t, err)
+	t.Logf("%s", report)
+
+	deposit := report.ByCall[accounts.Interface.Name+"::"+accounts.FuncDeposit]
+	setPrice := report.ByCall[root.Interface.Name+"::"+root.FuncSetUtilityPrice]
+	require.EqualValues(t, 40, report.Requests)
+	require.EqualValues(t, 40, deposit.Requests+setPrice.Requests)
+	require.EqualValues(t, 0, deposit.Failed)
+	require.EqualValues(t, setPrice.Requests, setPrice.Failed)
+	require.EqualValues(t, setPrice.Failed, report.Failed)
+	require.NotEmpty(t, report.LastError)
+	require.True(t, report.Throughput > 0)
+	require.True(t, report.P50 <= report.P90 && report.P90 <= report.P99 && report.P99 <= report.Max)
+}
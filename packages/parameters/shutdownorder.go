@@ -8,4 +8,6 @@ const (
 	PriorityDispatcher
 	PriorityWebAPI
 	PriorityBadgerGarbageCollection
+	PriorityBlobCleanup
+	PriorityStateCheckpoint
 )
@@ -0,0 +1,157 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package peering
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/util"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/sign/bls"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+// IdentityHandover is the record of the rotation of the peering identity key of the node with the NetID:
+// the old key hands over the identity of the node to the new key. The record is signed by both keys,
+// which proves the authority of the old key and the possession of the new one.
+// Once the peers know the handover, they don't accept the old key for the NetID anymore.
+// If the old key is Revoked, e.g. because it was compromised, it is not accepted for any NetID
+type IdentityHandover struct {
+	NetID     string
+	OldPubKey kyber.Point
+	NewPubKey kyber.Point
+	Revoked   bool
+	Timestamp int64
+	OldSig    []byte
+	NewSig    []byte
+}
+
+// IdentityRotator is implemented by network providers able to switch the identity key of the node
+// without dropping the sessions with peers.
+type IdentityRotator interface {
+	// RotateIdentity switches the node to the new key pair and sends the handover to all known peers
+	RotateIdentity(handover *IdentityHandover, newKeyPair *key.Pair) error
+	// AddHandovers makes the network aware of the handovers, e.g. loaded from the registry
+	AddHandovers(handovers []*IdentityHandover)
+	// OnHandover sets the callback called for each new valid handover received from peers
+	OnHandover(callback func(handover *IdentityHandover))
+}
+
+// NewIdentityHandover creates the handover from the old key pair to the new one, signed by both of them
+func NewIdentityHandover(netID string, oldKeyPair, newKeyPair *key.Pair, revoked bool, suite pairing.Suite) (*IdentityHandover, error) {
+	ret := &IdentityHandover{
+		NetID:     netID,
+		OldPubKey: oldKeyPair.Public,
+		NewPubKey: newKeyPair.Public,
+		Revoked:   revoked,
+		Timestamp: time.Now().UnixNano(),
+	}
+	essence, err := ret.essence()
+	if err != nil {
+		return nil, err
+	}
+	if ret.OldSig, err = bls.Sign(suite, oldKeyPair.Private, essence); err != nil {
+		return nil, err
+	}
+	if ret.NewSig, err = bls.Sign(suite, newKeyPair.Private, essence); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// IdentityHandoverFromBytes decodes the handover. The signatures are not verified
+func IdentityHandoverFromBytes(data []byte, suite kyber.Group) (*IdentityHandover, error) {
+	ret := &IdentityHandover{}
+	if err := ret.Read(bytes.NewReader(data), suite); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Verify checks signatures of both keys
+func (h *IdentityHandover) Verify(suite pairing.Suite) error {
+	if h.OldPubKey.Equal(h.NewPubKey) {
+		return errors.New("handover_to_same_key")
+	}
+	essence, err := h.essence()
+	if err != nil {
+		return err
+	}
+	if err = bls.Verify(suite, h.OldPubKey, essence, h.OldSig); err != nil {
+		return err
+	}
+	return bls.Verify(suite, h.NewPubKey, essence, h.NewSig)
+}
+
+func (h *IdentityHandover) essence() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := util.WriteString16(&buf, h.NetID); err != nil {
+		return nil, err
+	}
+	if err := util.WriteMarshaled(&buf, h.OldPubKey); err != nil {
+		return nil, err
+	}
+	if err := util.WriteMarshaled(&buf, h.NewPubKey); err != nil {
+		return nil, err
+	}
+	if err := util.WriteBoolByte(&buf, h.Revoked); err != nil {
+		return nil, err
+	}
+	if err := util.WriteInt64(&buf, h.Timestamp); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *IdentityHandover) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := h.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (h *IdentityHandover) Write(w io.Writer) error {
+	essence, err := h.essence()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(essence); err != nil {
+		return err
+	}
+	if err = util.WriteBytes16(w, h.OldSig); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, h.NewSig)
+}
+
+func (h *IdentityHandover) Read(r io.Reader, suite kyber.Group) error {
+	var err error
+	if h.NetID, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	h.OldPubKey = suite.Point()
+	if err = util.ReadMarshaled(r, h.OldPubKey); err != nil {
+		return err
+	}
+	h.NewPubKey = suite.Point()
+	if err = util.ReadMarshaled(r, h.NewPubKey); err != nil {
+		return err
+	}
+	if err = util.ReadBoolByte(r, &h.Revoked); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &h.Timestamp); err != nil {
+		return err
+	}
+	if h.OldSig, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	h.NewSig, err = util.ReadBytes16(r)
+	return err
+}
@@ -0,0 +1,44 @@
+package peering_test
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+func TestIdentityHandover(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	oldPair := key.NewKeyPair(suite)
+	newPair := key.NewKeyPair(suite)
+
+	h, err := peering.NewIdentityHandover("localhost:4000", oldPair, newPair, true, suite)
+	require.NoError(t, err)
+	require.NoError(t, h.Verify(suite))
+
+	data, err := h.Bytes()
+	require.NoError(t, err)
+	back, err := peering.IdentityHandoverFromBytes(data, suite)
+	require.NoError(t, err)
+	require.NoError(t, back.Verify(suite))
+	require.Equal(t, h.NetID, back.NetID)
+	require.True(t, back.OldPubKey.Equal(oldPair.Public))
+	require.True(t, back.NewPubKey.Equal(newPair.Public))
+	require.True(t, back.Revoked)
+	require.Equal(t, h.Timestamp, back.Timestamp)
+
+	back.Revoked = false
+	require.Error(t, back.Verify(suite))
+
+	// The new key must prove the possession.
+	forged, err := peering.NewIdentityHandover("localhost:4000", oldPair, key.NewKeyPair(suite), false, suite)
+	require.NoError(t, err)
+	forged.NewPubKey = newPair.Public
+	require.Error(t, forged.Verify(suite))
+
+	same, err := peering.NewIdentityHandover("localhost:4000", oldPair, oldPair, false, suite)
+	require.NoError(t, err)
+	require.Error(t, same.Verify(suite))
+}
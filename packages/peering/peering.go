@@ -26,6 +26,10 @@ const (
 	MsgTypeReserved  = byte(0)
 	MsgTypeHandshake = byte(1)
 	MsgTypeMsgChunk  = byte(2)
+	// MsgTypeEncrypted wraps a complete message encrypted with the session key of the peers
+	MsgTypeEncrypted = byte(3)
+	// MsgTypeIdentityHandover carries the IdentityHandover of the sender
+	MsgTypeIdentityHandover = byte(4)
 
 	// FirstUserMsgCode is the first committee message type.
 	// All the equal and larger msg types are committee messages.
@@ -56,6 +60,15 @@ type NetworkProvider interface {
 	PeerStatus() []PeerStatusProvider
 }
 
+// PeerBlocker is implemented by network providers able to cut off the traffic
+// with some peers, e.g. to simulate network partitions in tests.
+type PeerBlocker interface {
+	// BlockPeers drops all the messages sent to or received from the peers
+	// with the NetIDs. It replaces the previous set, nil unblocks all peers.
+	BlockPeers(peerNetIDs []string)
+	BlockedPeers() []string
+}
+
 // GroupProvider stands for a subset of a peer-to-peer network
 // that is responsible for achieving some common goal, eg,
 // consensus committee, DKG group, etc.
@@ -153,11 +166,11 @@ func NewPeerMessageFromBytes(buf []byte) (*PeerMessage, error) {
 	}
 	switch m.MsgType {
 	case MsgTypeReserved:
-	case MsgTypeHandshake:
+	case MsgTypeHandshake, MsgTypeIdentityHandover:
 		if m.MsgData, err = util.ReadBytes32(r); err != nil {
 			return nil, err
 		}
-	case MsgTypeMsgChunk:
+	case MsgTypeMsgChunk, MsgTypeEncrypted:
 		if m.MsgData, err = util.ReadBytes32(r); err != nil {
 			return nil, err
 		}
@@ -209,11 +222,11 @@ func (m *PeerMessage) Bytes() ([]byte, error) {
 	}
 	switch m.MsgType {
 	case MsgTypeReserved:
-	case MsgTypeHandshake:
+	case MsgTypeHandshake, MsgTypeIdentityHandover:
 		if err = util.WriteBytes32(&buf, m.MsgData); err != nil {
 			return nil, err
 		}
-	case MsgTypeMsgChunk:
+	case MsgTypeMsgChunk, MsgTypeEncrypted:
 		if err = util.WriteBytes32(&buf, m.MsgData); err != nil {
 			return nil, err
 		}
@@ -5,16 +5,29 @@ package udp
 
 import (
 	"bytes"
+	"errors"
+	"time"
 
 	"github.com/iotaledger/wasp/packages/util"
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/sign/bls"
 )
 
+// handshakeMaxSkew is the maximal difference between the timestamp of the received handshake and
+// the local clock. Older handshakes are considered replayed.
+const handshakeMaxSkew = 1 * time.Minute
+
 type handshakeMsg struct {
 	netID   string      // Their NetID
 	pubKey  kyber.Point // Our PubKey.
 	respond bool        // Do the message asks for a response?
+	// Our ephemeral key for the key exchange. Is nil, if the node does not support
+	// encryption. It is appended to the payload, so older nodes just ignore it.
+	ephPubKey []byte
+	// The time of sending in nanoseconds, strictly increasing for each node. It is sent
+	// together with the ephemeral key, 0 if the node does not send it. The receiver
+	// rejects stale handshakes, so they can't be replayed.
+	timestamp int64
 }
 
 func (m *handshakeMsg) bytes(secKey kyber.Scalar, suite Suite) ([]byte, error) {
@@ -31,6 +44,14 @@ func (m *handshakeMsg) bytes(secKey kyber.Scalar, suite Suite) ([]byte, error) {
 	if err = util.WriteBoolByte(&payloadBuf, m.respond); err != nil {
 		return nil, err
 	}
+	if m.ephPubKey != nil {
+		if err = util.WriteBytes16(&payloadBuf, m.ephPubKey); err != nil {
+			return nil, err
+		}
+		if err = util.WriteInt64(&payloadBuf, m.timestamp); err != nil {
+			return nil, err
+		}
+	}
 	var payload = payloadBuf.Bytes()
 	var signature []byte
 	if signature, err = bls.Sign(suite, secKey, payload); err != nil {
@@ -75,6 +96,16 @@ func handshakeMsgFromBytes(buf []byte, suite Suite) (*handshakeMsg, error) {
 	if err = util.ReadBoolByte(rPayload, &m.respond); err != nil {
 		return nil, err
 	}
+	if rPayload.Len() > 0 {
+		if m.ephPubKey, err = util.ReadBytes16(rPayload); err != nil {
+			return nil, err
+		}
+	}
+	if rPayload.Len() > 0 {
+		if err = util.ReadInt64(rPayload, &m.timestamp); err != nil {
+			return nil, err
+		}
+	}
 	//
 	// Verify the signature.
 	if err = bls.Verify(suite, m.pubKey, payload, signature); err != nil {
@@ -82,3 +113,22 @@ func handshakeMsgFromBytes(buf []byte, suite Suite) (*handshakeMsg, error) {
 	}
 	return &m, nil
 }
+
+// checkTime rejects the handshake sent too long ago, or in the future, according to the local clock.
+// Handshakes without the timestamp are only accepted, if the plaintext peering is allowed.
+func (m *handshakeMsg) checkTime(now time.Time, requireEncryption bool) error {
+	if m.timestamp == 0 {
+		if requireEncryption {
+			return errors.New("handshake_without_timestamp")
+		}
+		return nil
+	}
+	diff := now.UnixNano() - m.timestamp
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > handshakeMaxSkew.Nanoseconds() {
+		return errors.New("handshake_expired")
+	}
+	return nil
+}
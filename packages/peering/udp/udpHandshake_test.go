@@ -6,6 +6,7 @@ package udp
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"go.dedis.ch/kyber/v3/pairing"
@@ -34,6 +35,31 @@ func TestHandshakeCodec(t *testing.T) {
 	require.Equal(t, a.netID, b.netID)
 	require.True(t, a.pubKey.Equal(b.pubKey))
 	require.Equal(t, a.respond, b.respond)
+	require.Nil(t, b.ephPubKey)
+	//
+	// With the ephemeral key.
+	eph, err := newEphemeralKey()
+	require.Nil(t, err)
+	a.ephPubKey = eph.public
+	var bufEph []byte
+	bufEph, err = a.bytes(pair.Private, suite)
+	require.Nil(t, err)
+	var e *handshakeMsg
+	e, err = handshakeMsgFromBytes(bufEph, suite)
+	require.Nil(t, err)
+	require.Equal(t, a.ephPubKey, e.ephPubKey)
+	require.Zero(t, e.timestamp)
+	//
+	// With the timestamp.
+	now := time.Now()
+	a.timestamp = now.UnixNano()
+	var bufTs []byte
+	bufTs, err = a.bytes(pair.Private, suite)
+	require.Nil(t, err)
+	var f *handshakeMsg
+	f, err = handshakeMsgFromBytes(bufTs, suite)
+	require.Nil(t, err)
+	require.Equal(t, a.timestamp, f.timestamp)
 	//
 	// Damaged message.
 	buf[2] = buf[2] + 1
@@ -43,6 +69,19 @@ func TestHandshakeCodec(t *testing.T) {
 	require.Nil(t, c)
 }
 
+func TestHandshakeTime(t *testing.T) {
+	now := time.Now()
+	fresh := handshakeMsg{timestamp: now.Add(-time.Second).UnixNano()}
+	require.Nil(t, fresh.checkTime(now, true))
+	stale := handshakeMsg{timestamp: now.Add(-2 * handshakeMaxSkew).UnixNano()}
+	require.NotNil(t, stale.checkTime(now, true))
+	future := handshakeMsg{timestamp: now.Add(2 * handshakeMaxSkew).UnixNano()}
+	require.NotNil(t, future.checkTime(now, true))
+	legacy := handshakeMsg{}
+	require.NotNil(t, legacy.checkTime(now, true))
+	require.Nil(t, legacy.checkTime(now, false))
+}
+
 func TestUDPAddrString(t *testing.T) {
 	var err error
 	var addr *net.UDPAddr
@@ -0,0 +1,183 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package udp
+
+import (
+	"errors"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/peering"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+// identityHandovers keeps handovers known to the node. Only the first handover from each old key
+// is accepted, so the holder of the compromised key can't redirect the identity once it was rotated.
+type identityHandovers struct {
+	byOldKey map[string]*peering.IdentityHandover // By the marshaled OldPubKey.
+	revoked  map[string]bool                      // Marshaled revoked keys.
+	callback func(handover *peering.IdentityHandover)
+}
+
+func pubKeyID(pubKey kyber.Point) string {
+	data, err := pubKey.MarshalBinary()
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// keyPair returns the current identity key pair of the node.
+func (n *NetImpl) keyPair() *key.Pair {
+	n.identityLock.RLock()
+	defer n.identityLock.RUnlock()
+	return n.nodeKeyPair
+}
+
+// RotateIdentity implements peering.IdentityRotator.
+// The handover is sent to the peers before the new key is used in handshakes. Encrypted sessions
+// are kept, because they depend on the ephemeral keys only, so the ongoing consensus is not disturbed.
+func (n *NetImpl) RotateIdentity(handover *peering.IdentityHandover, newKeyPair *key.Pair) error {
+	var err error
+	if handover.NetID != n.myNetID {
+		return errors.New("handover_of_other_node")
+	}
+	if !handover.OldPubKey.Equal(n.keyPair().Public) || !handover.NewPubKey.Equal(newKeyPair.Public) {
+		return errors.New("handover_keys_mismatch")
+	}
+	if err = handover.Verify(n.suite); err != nil {
+		return err
+	}
+	var msgData []byte
+	if msgData, err = handover.Bytes(); err != nil {
+		return err
+	}
+	peers := n.knownPeers()
+	msg := &peering.PeerMessage{
+		Timestamp: time.Now().UnixNano(),
+		MsgType:   peering.MsgTypeIdentityHandover,
+		MsgData:   msgData,
+	}
+	for _, p := range peers {
+		p.SendMsg(msg)
+	}
+	n.identityLock.Lock()
+	n.nodeKeyPair = newKeyPair
+	n.identityLock.Unlock()
+	n.addHandover(handover)
+	for _, p := range peers {
+		p.sendHandshake(true)
+	}
+	n.log.Infof("Identity of %v rotated, revoked=%v", n.myNetID, handover.Revoked)
+	return nil
+}
+
+// AddHandovers implements peering.IdentityRotator.
+func (n *NetImpl) AddHandovers(handovers []*peering.IdentityHandover) {
+	for _, h := range handovers {
+		if err := h.Verify(n.suite); err != nil {
+			n.log.Warnf("Ignoring invalid identity handover of %v, reason=%v", h.NetID, err)
+			continue
+		}
+		n.addHandover(h)
+	}
+}
+
+// OnHandover implements peering.IdentityRotator.
+func (n *NetImpl) OnHandover(callback func(handover *peering.IdentityHandover)) {
+	n.handoversLock.Lock()
+	defer n.handoversLock.Unlock()
+	n.handovers.callback = callback
+}
+
+// addHandover stores the verified handover. Returns false, if the old key was already handed over.
+func (n *NetImpl) addHandover(h *peering.IdentityHandover) bool {
+	n.handoversLock.Lock()
+	defer n.handoversLock.Unlock()
+	oldKeyID := pubKeyID(h.OldPubKey)
+	if known, ok := n.handovers.byOldKey[oldKeyID]; ok {
+		if !known.NewPubKey.Equal(h.NewPubKey) || known.NetID != h.NetID {
+			n.log.Warnf("Ignoring conflicting identity handover of %v", h.NetID)
+		}
+		return false
+	}
+	n.handovers.byOldKey[oldKeyID] = h
+	if h.Revoked {
+		n.handovers.revoked[oldKeyID] = true
+	}
+	return true
+}
+
+// checkIdentity returns an error, if the key can't be used by the node anymore.
+func (n *NetImpl) checkIdentity(netID string, pubKey kyber.Point) error {
+	n.handoversLock.RLock()
+	defer n.handoversLock.RUnlock()
+	keyID := pubKeyID(pubKey)
+	if n.handovers.revoked[keyID] {
+		return errors.New("identity_key_revoked")
+	}
+	if h, ok := n.handovers.byOldKey[keyID]; ok && h.NetID == netID {
+		return errors.New("identity_key_handed_over")
+	}
+	return nil
+}
+
+// isHandedOver checks, if the identity of the node was handed over from the old key to the new one,
+// possibly via several rotations.
+func (n *NetImpl) isHandedOver(netID string, oldPubKey, newPubKey kyber.Point) bool {
+	n.handoversLock.RLock()
+	defer n.handoversLock.RUnlock()
+	pubKey := oldPubKey
+	for i := 0; i <= len(n.handovers.byOldKey); i++ {
+		h, ok := n.handovers.byOldKey[pubKeyID(pubKey)]
+		if !ok || h.NetID != netID {
+			return false
+		}
+		if h.NewPubKey.Equal(newPubKey) {
+			return true
+		}
+		pubKey = h.NewPubKey
+	}
+	return false
+}
+
+// receiveHandover handles the handover received from the network.
+func (n *NetImpl) receiveHandover(msg *peering.PeerMessage) {
+	var err error
+	var h *peering.IdentityHandover
+	if h, err = peering.IdentityHandoverFromBytes(msg.MsgData, n.suite); err != nil {
+		n.log.Warnf("Error while decoding an identity handover, reason=%v", err)
+		return
+	}
+	if err = h.Verify(n.suite); err != nil {
+		n.log.Warnf("Dropping invalid identity handover of %v, reason=%v", h.NetID, err)
+		return
+	}
+	if !n.addHandover(h) {
+		return
+	}
+	n.log.Infof("Identity of %v handed over to a new key, revoked=%v", h.NetID, h.Revoked)
+	n.peersLock.RLock()
+	p, ok := n.peers[h.NetID]
+	n.peersLock.RUnlock()
+	if ok {
+		p.handleHandover(h)
+	}
+	n.handoversLock.RLock()
+	callback := n.handovers.callback
+	n.handoversLock.RUnlock()
+	if callback != nil {
+		callback(h)
+	}
+}
+
+func (n *NetImpl) knownPeers() []*peer {
+	n.peersLock.RLock()
+	defer n.peersLock.RUnlock()
+	ret := make([]*peer, 0, len(n.peers))
+	for _, p := range n.peers {
+		ret = append(ret, p)
+	}
+	return ret
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"net"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -36,20 +37,39 @@ type NetImpl struct {
 	peersLock   *sync.RWMutex
 	recvEvents  *events.Event
 	recvQueue   chan *peering.RecvEvent // A queue for received messages.
-	nodeKeyPair *key.Pair
+	nodeKeyPair *key.Pair               // Replaced by RotateIdentity, use keyPair().
+	ephKey      *ephemeralKey           // For the key exchange with peers.
 	suite       Suite
 	log         *logger.Logger
+	// Don't exchange user messages with peers not supporting the encryption.
+	requireEncryption bool
+	blocked           map[string]bool // NetIDs of peers cut off by BlockPeers.
+	blockedLock       *sync.RWMutex
+	lastHandshake     int64 // Timestamp of the latest sent handshake.
+	lastHandshakeLock *sync.Mutex
+	identityLock      *sync.RWMutex
+	handovers         *identityHandovers
+	handoversLock     *sync.RWMutex
 }
 
 // NewNetworkProvider is a constructor for the TCP based
 // peering network implementation.
-func NewNetworkProvider(myNetID string, port int, nodeKeyPair *key.Pair, suite Suite, log *logger.Logger) (*NetImpl, error) {
+//
+// The user messages are encrypted with the session keys exchanged in the
+// handshakes, signed by the node identity keys. The messages and handshakes
+// are protected against replays. The peers not supporting the encryption get
+// the messages in plaintext, unless requireEncryption is set.
+func NewNetworkProvider(myNetID string, port int, nodeKeyPair *key.Pair, suite Suite, requireEncryption bool, log *logger.Logger) (*NetImpl, error) {
 	var err error
 	if err = peering.CheckMyNetID(myNetID, port); err != nil {
 		// can't continue because NetID parameter is not correct
 		log.Panicf("checkMyNetworkID: '%v'. || Check the 'netid' parameter in config.json", err)
 		return nil, err
 	}
+	var ephKey *ephemeralKey
+	if ephKey, err = newEphemeralKey(); err != nil {
+		return nil, err
+	}
 	var myUDPConn *net.UDPConn
 	if myUDPConn, err = net.ListenUDP("udp", &net.UDPAddr{Port: port}); err != nil {
 		return nil, err
@@ -64,8 +84,21 @@ func NewNetworkProvider(myNetID string, port int, nodeKeyPair *key.Pair, suite S
 		recvEvents:  nil, // Initialized bellow.
 		recvQueue:   make(chan *peering.RecvEvent, recvQueueSize),
 		nodeKeyPair: nodeKeyPair,
+		ephKey:      ephKey,
 		suite:       suite,
 		log:         log,
+
+		requireEncryption: requireEncryption,
+		blocked:           make(map[string]bool),
+		blockedLock:       &sync.RWMutex{},
+		lastHandshake:     0,
+		lastHandshakeLock: &sync.Mutex{},
+		identityLock:      &sync.RWMutex{},
+		handovers: &identityHandovers{
+			byOldKey: make(map[string]*peering.IdentityHandover),
+			revoked:  make(map[string]bool),
+		},
+		handoversLock: &sync.RWMutex{},
 	}
 	n.recvEvents = events.NewEvent(n.eventHandler)
 	return &n, nil
@@ -161,6 +194,35 @@ func (n *NetImpl) PeerStatus() []peering.PeerStatusProvider {
 	return peerStatus
 }
 
+// BlockPeers implements peering.PeerBlocker.
+func (n *NetImpl) BlockPeers(peerNetIDs []string) {
+	blocked := make(map[string]bool, len(peerNetIDs))
+	for _, netID := range peerNetIDs {
+		blocked[netID] = true
+	}
+	n.blockedLock.Lock()
+	defer n.blockedLock.Unlock()
+	n.blocked = blocked
+}
+
+// BlockedPeers implements peering.PeerBlocker.
+func (n *NetImpl) BlockedPeers() []string {
+	n.blockedLock.RLock()
+	defer n.blockedLock.RUnlock()
+	ret := make([]string, 0, len(n.blocked))
+	for netID := range n.blocked {
+		ret = append(ret, netID)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (n *NetImpl) isBlocked(peerNetID string) bool {
+	n.blockedLock.RLock()
+	defer n.blockedLock.RUnlock()
+	return n.blocked[peerNetID]
+}
+
 // NetID implements peering.PeerSender for the Self() node.
 func (n *NetImpl) NetID() string {
 	return n.myNetID
@@ -168,7 +230,7 @@ func (n *NetImpl) NetID() string {
 
 // PubKey implements peering.PeerSender for the Self() node.
 func (n *NetImpl) PubKey() kyber.Point {
-	return n.nodeKeyPair.Public
+	return n.keyPair().Public
 }
 
 // SendMsg implements peering.PeerSender for the Self() node.
@@ -262,6 +324,17 @@ func (n *NetImpl) receiveLoop(stopCh chan bool) {
 				n.log.Warnf("Error while decoding a UDP handshake, reason=%v", err)
 				continue
 			}
+			if err = h.checkTime(time.Now(), n.requireEncryption); err != nil {
+				n.log.Warnf("Dropping UDP handshake from %v, reason=%v", peerUDPAddr, err)
+				continue
+			}
+			if n.isBlocked(h.netID) {
+				continue
+			}
+			if err = n.checkIdentity(h.netID, h.pubKey); err != nil {
+				n.log.Warnf("Dropping UDP handshake from %v, reason=%v", peerUDPAddr, err)
+				continue
+			}
 			n.peersLock.Lock()
 			if p, ok := n.peers[h.netID]; ok {
 				if oldUDPAddrStr, newUDPAddrStr := p.handleHandshake(h, peerUDPAddr); oldUDPAddrStr != newUDPAddrStr {
@@ -284,13 +357,16 @@ func (n *NetImpl) receiveLoop(stopCh chan bool) {
 			n.peersLock.RLock()
 			if p, ok := n.peersByAddr[remoteUDPAddrStr]; ok {
 				n.peersLock.RUnlock()
+				if n.isBlocked(p.NetID()) {
+					continue
+				}
 				var reconstructedMsg *peering.PeerMessage
 				if reconstructedMsg, err = peering.NewPeerMessageFromChunks(peerMsg.MsgData, maxChunkSize, p.msgChopper); err != nil {
 					n.log.Warnf("Error while decoding chunked message, reason=%v", err)
 					continue
 				}
 				if reconstructedMsg != nil {
-					n.receiveUserMsg(reconstructedMsg, peerUDPAddr)
+					n.receiveMsg(reconstructedMsg, peerUDPAddr)
 				}
 			} else {
 				n.peersLock.RUnlock()
@@ -298,13 +374,21 @@ func (n *NetImpl) receiveLoop(stopCh chan bool) {
 				continue
 			}
 		default:
-			n.receiveUserMsg(peerMsg, peerUDPAddr)
+			n.receiveMsg(peerMsg, peerUDPAddr)
 		}
 	}
 }
 
-func (n *NetImpl) receiveUserMsg(msg *peering.PeerMessage, peerUDPAddr *net.UDPAddr) {
-	if !msg.IsUserMessage() {
+// receiveMsg handles the complete (not chunked) message, which is either a user message, or
+// an encrypted envelope of it.
+func (n *NetImpl) receiveMsg(msg *peering.PeerMessage, peerUDPAddr *net.UDPAddr) {
+	var err error
+	if msg.MsgType == peering.MsgTypeIdentityHandover {
+		// The handover is signed, so it is accepted from any peer.
+		n.receiveHandover(msg)
+		return
+	}
+	if msg.MsgType != peering.MsgTypeEncrypted && !msg.IsUserMessage() {
 		n.log.Warnf("Dropping received message, unexpected MsgType=%v", msg.MsgType)
 		return
 	}
@@ -312,6 +396,23 @@ func (n *NetImpl) receiveUserMsg(msg *peering.PeerMessage, peerUDPAddr *net.UDPA
 	n.peersLock.RLock()
 	if p, ok := n.peersByAddr[remoteUDPAddrStr]; ok {
 		n.peersLock.RUnlock()
+		if n.isBlocked(p.NetID()) {
+			return
+		}
+		if msg.MsgType == peering.MsgTypeEncrypted {
+			if msg, err = p.decryptMsg(msg); err != nil {
+				n.log.Warnf("Dropping received message from %v, unable to decrypt, reason=%v", remoteUDPAddrStr, err)
+				return
+			}
+			if !msg.IsUserMessage() {
+				n.log.Warnf("Dropping received message, unexpected MsgType=%v", msg.MsgType)
+				return
+			}
+		} else if n.requireEncryption || p.encrypted() != nil {
+			// The peer supporting encryption never sends user messages in plaintext.
+			n.log.Warnf("Dropping received plaintext message from %v", remoteUDPAddrStr)
+			return
+		}
 		p.noteReceived()
 		n.recvQueue <- &peering.RecvEvent{
 			From: p,
@@ -323,6 +424,19 @@ func (n *NetImpl) receiveUserMsg(msg *peering.PeerMessage, peerUDPAddr *net.UDPA
 	n.log.Warnf("Dropping received message from unknown peer=%v", remoteUDPAddrStr)
 }
 
+// handshakeTimestamp returns the timestamp for the outgoing handshake. The timestamps are
+// strictly increasing, so the peers can tell replayed handshakes from the new ones.
+func (n *NetImpl) handshakeTimestamp() int64 {
+	n.lastHandshakeLock.Lock()
+	defer n.lastHandshakeLock.Unlock()
+	ts := time.Now().UnixNano()
+	if ts <= n.lastHandshake {
+		ts = n.lastHandshake + 1
+	}
+	n.lastHandshake = ts
+	return ts
+}
+
 func (n *NetImpl) maintenanceLoop(stopCh chan bool) {
 	for {
 		select {
@@ -5,6 +5,7 @@ package udp_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/peering"
@@ -25,9 +26,9 @@ func TestUDPPeeringImpl(t *testing.T) {
 	chain2 := coretypes.NewRandomChainID()
 	netIDs := []string{"localhost:9017", "localhost:9018", "localhost:9019"}
 	nodes := make([]peering.NetworkProvider, len(netIDs))
-	nodes[0], err0 = udp.NewNetworkProvider(netIDs[0], 9017, key.NewKeyPair(suite), suite, log.Named("node0"))
-	nodes[1], err1 = udp.NewNetworkProvider(netIDs[1], 9018, key.NewKeyPair(suite), suite, log.Named("node1"))
-	nodes[2], err2 = udp.NewNetworkProvider(netIDs[2], 9019, key.NewKeyPair(suite), suite, log.Named("node2"))
+	nodes[0], err0 = udp.NewNetworkProvider(netIDs[0], 9017, key.NewKeyPair(suite), suite, false, log.Named("node0"))
+	nodes[1], err1 = udp.NewNetworkProvider(netIDs[1], 9018, key.NewKeyPair(suite), suite, false, log.Named("node1"))
+	nodes[2], err2 = udp.NewNetworkProvider(netIDs[2], 9019, key.NewKeyPair(suite), suite, false, log.Named("node2"))
 	require.Nil(t, err0)
 	require.Nil(t, err1)
 	require.Nil(t, err2)
@@ -52,3 +53,98 @@ func TestUDPPeeringImpl(t *testing.T) {
 
 	<-doneCh
 }
+
+func TestUDPPeeringBlockPeers(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	log := testutil.NewLogger(t)
+	defer log.Sync()
+	chainID := coretypes.NewRandomChainID()
+	netIDs := []string{"localhost:9020", "localhost:9021"}
+	node0, err := udp.NewNetworkProvider(netIDs[0], 9020, key.NewKeyPair(suite), suite, false, log.Named("node0"))
+	require.Nil(t, err)
+	node1, err := udp.NewNetworkProvider(netIDs[1], 9021, key.NewKeyPair(suite), suite, false, log.Named("node1"))
+	require.Nil(t, err)
+	go node0.Run(make(<-chan struct{}))
+	go node1.Run(make(<-chan struct{}))
+
+	recvCh := make(chan *peering.RecvEvent, 10)
+	node0.Attach(nil, func(recv *peering.RecvEvent) {
+		recvCh <- recv
+	})
+	n1p0, err := node1.PeerByNetID(netIDs[0])
+	require.Nil(t, err)
+	require.Nil(t, n1p0.Await(5*time.Second))
+
+	node0.BlockPeers([]string{netIDs[1]})
+	require.Equal(t, []string{netIDs[1]}, node0.BlockedPeers())
+	n1p0.SendMsg(&peering.PeerMessage{ChainID: chainID, MsgType: 125})
+	select {
+	case <-recvCh:
+		t.Fatal("message from the blocked peer received")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	node0.BlockPeers(nil)
+	require.Empty(t, node0.BlockedPeers())
+	n1p0.SendMsg(&peering.PeerMessage{ChainID: chainID, MsgType: 125})
+	select {
+	case recv := <-recvCh:
+		require.Equal(t, netIDs[1], recv.From.NetID())
+	case <-time.After(5 * time.Second):
+		t.Fatal("message from the unblocked peer not received")
+	}
+}
+
+func TestUDPPeeringRotateIdentity(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	log := testutil.NewLogger(t)
+	defer log.Sync()
+	chainID := coretypes.NewRandomChainID()
+	netIDs := []string{"localhost:9022", "localhost:9023"}
+	node0, err := udp.NewNetworkProvider(netIDs[0], 9022, key.NewKeyPair(suite), suite, false, log.Named("node0"))
+	require.Nil(t, err)
+	oldPair := key.NewKeyPair(suite)
+	node1, err := udp.NewNetworkProvider(netIDs[1], 9023, oldPair, suite, false, log.Named("node1"))
+	require.Nil(t, err)
+	go node0.Run(make(<-chan struct{}))
+	go node1.Run(make(<-chan struct{}))
+
+	recvCh := make(chan *peering.RecvEvent, 10)
+	node0.Attach(nil, func(recv *peering.RecvEvent) {
+		recvCh <- recv
+	})
+	handoverCh := make(chan *peering.IdentityHandover, 1)
+	node0.OnHandover(func(handover *peering.IdentityHandover) {
+		handoverCh <- handover
+	})
+	n0p1, err := node0.PeerByNetID(netIDs[1])
+	require.Nil(t, err)
+	n1p0, err := node1.PeerByNetID(netIDs[0])
+	require.Nil(t, err)
+	require.Nil(t, n1p0.Await(5*time.Second))
+	require.True(t, n0p1.PubKey().Equal(oldPair.Public))
+
+	newPair := key.NewKeyPair(suite)
+	handover, err := peering.NewIdentityHandover(netIDs[1], oldPair, newPair, true, suite)
+	require.Nil(t, err)
+	require.Error(t, node1.RotateIdentity(handover, key.NewKeyPair(suite)))
+	require.Nil(t, node1.RotateIdentity(handover, newPair))
+	require.True(t, node1.PubKey().Equal(newPair.Public))
+
+	select {
+	case received := <-handoverCh:
+		require.True(t, received.NewPubKey.Equal(newPair.Public))
+	case <-time.After(5 * time.Second):
+		t.Fatal("handover not received")
+	}
+	require.True(t, n0p1.PubKey().Equal(newPair.Public))
+
+	// The session survives the rotation.
+	n1p0.SendMsg(&peering.PeerMessage{ChainID: chainID, MsgType: 125})
+	select {
+	case recv := <-recvCh:
+		require.Equal(t, netIDs[1], recv.From.NetID())
+	case <-time.After(5 * time.Second):
+		t.Fatal("message after the rotation not received")
+	}
+}
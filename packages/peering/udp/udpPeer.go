@@ -4,6 +4,7 @@
 package udp
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"net"
@@ -29,6 +30,9 @@ type peer struct {
 	remoteNetID   string
 	remotePubKey  kyber.Point
 	remoteUDPAddr *net.UDPAddr
+	remoteEphKey  []byte   // Ephemeral key of the peer, nil if the peer does not support encryption.
+	session       *session // Encrypts the messages, if the key exchange was completed.
+	lastHandshake int64    // Timestamp of the latest accepted handshake, older ones are replays.
 	waitReady     *util.WaitChan
 	accessLock    *sync.RWMutex
 	lastMsgSent   time.Time
@@ -72,6 +76,9 @@ func newPeer(remoteNetID string, remoteUDPAddr *net.UDPAddr, n *NetImpl) (*peer,
 		remoteNetID:   remoteNetID,
 		remotePubKey:  nil, // Will be retrieved on handshake.
 		remoteUDPAddr: remoteUDPAddr,
+		remoteEphKey:  nil, // Will be retrieved on handshake.
+		session:       nil, // Will be established on handshake.
+		lastHandshake: 0,
 		waitReady:     util.NewWaitChan(),
 		accessLock:    &sync.RWMutex{},
 		lastMsgSent:   time.Time{},
@@ -94,6 +101,20 @@ func (p *peer) usePeer() {
 func (p *peer) handleHandshake(handshake *handshakeMsg, remoteUDPAddr *net.UDPAddr) (string, string) {
 	p.accessLock.Lock()
 	oldUDPAddrStr := p.remoteUDPAddr.String()
+	if handshake.timestamp != 0 && handshake.timestamp <= p.lastHandshake {
+		// The signed handshake is genuine, but was already used.
+		p.accessLock.Unlock()
+		p.log.Warnf("Ignoring replayed handshake from %v", remoteUDPAddr)
+		return oldUDPAddrStr, oldUDPAddrStr
+	}
+	if p.remotePubKey != nil && !p.remotePubKey.Equal(handshake.pubKey) &&
+		!p.net.isHandedOver(p.remoteNetID, p.remotePubKey, handshake.pubKey) {
+		// The key can only be changed by the handover signed by the known key.
+		p.accessLock.Unlock()
+		p.log.Warnf("Ignoring handshake from %v, remote PubKey has changed without a handover", remoteUDPAddr)
+		return oldUDPAddrStr, oldUDPAddrStr
+	}
+	p.lastHandshake = handshake.timestamp
 	newUDPAddrStr := remoteUDPAddr.String()
 	if oldUDPAddrStr != newUDPAddrStr {
 		p.log.Warnf("Remote UDPAddr has changed, old=%v, new=%v", oldUDPAddrStr, newUDPAddrStr)
@@ -107,12 +128,11 @@ func (p *peer) handleHandshake(handshake *handshakeMsg, remoteUDPAddr *net.UDPAd
 	} else if p.remotePubKey != nil && p.remotePubKey.Equal(handshake.pubKey) {
 		// It's just a ping.
 	} else {
-		// New PublicKey is used by the peer!
-		if !p.remotePubKey.Equal(handshake.pubKey) {
-			p.log.Warnf("Remote PubKey has changed, old=%v, new=%v", p.remotePubKey, handshake.pubKey)
-		}
+		// New PublicKey is used by the peer, it was handed over by the old one.
+		p.log.Infof("Remote PubKey has changed, old=%v, new=%v", p.remotePubKey, handshake.pubKey)
 		p.remotePubKey = handshake.pubKey
 	}
+	p.handleEphKey(handshake.ephPubKey)
 	p.lastMsgRecv = time.Now()
 	p.accessLock.Unlock()
 	if handshake.respond {
@@ -122,15 +142,48 @@ func (p *peer) handleHandshake(handshake *handshakeMsg, remoteUDPAddr *net.UDPAd
 	return oldUDPAddrStr, newUDPAddrStr
 }
 
+// handleEphKey establishes a new session, if the ephemeral key of the peer has changed.
+// Once the session is established, it is never downgraded to the plaintext.
+// Should be called with the accessLock held.
+func (p *peer) handleEphKey(remoteEphKey []byte) {
+	if remoteEphKey == nil {
+		if p.session != nil || p.net.requireEncryption {
+			p.log.Warnf("Peer %v does not support encrypted peering, refusing to downgrade", p.remoteNetID)
+		}
+		return
+	}
+	if p.session != nil && bytes.Equal(p.remoteEphKey, remoteEphKey) {
+		return
+	}
+	session, err := p.net.ephKey.newSession(remoteEphKey)
+	if err != nil {
+		p.log.Warnf("Unable to establish a session with %v, reason=%v", p.remoteNetID, err)
+		return
+	}
+	p.remoteEphKey = remoteEphKey
+	p.session = session
+	p.log.Debugf("Session established with %v", p.remoteNetID)
+}
+
+// encrypted returns the session, if the traffic with the peer is encrypted.
+func (p *peer) encrypted() *session {
+	p.accessLock.RLock()
+	defer p.accessLock.RUnlock()
+	return p.session
+}
+
 func (p *peer) sendHandshake(respond bool) {
 	var err error
+	keyPair := p.net.keyPair()
 	handshake := handshakeMsg{
-		netID:   p.net.NetID(),
-		pubKey:  p.net.PubKey(),
-		respond: respond,
+		netID:     p.net.NetID(),
+		pubKey:    keyPair.Public,
+		respond:   respond,
+		ephPubKey: p.net.ephKey.public,
+		timestamp: p.net.handshakeTimestamp(),
 	}
 	var msgDataBin []byte
-	if msgDataBin, err = handshake.bytes(p.net.nodeKeyPair.Private, p.net.suite); err != nil {
+	if msgDataBin, err = handshake.bytes(keyPair.Private, p.net.suite); err != nil {
 		p.log.Errorf("Unable to encode outgoing handshake msg, reason=%v", err)
 	}
 	p.SendMsg(&peering.PeerMessage{
@@ -140,6 +193,16 @@ func (p *peer) sendHandshake(respond bool) {
 	})
 }
 
+// handleHandover switches the peer to the new key, if the handover is from the key known for it.
+func (p *peer) handleHandover(handover *peering.IdentityHandover) {
+	p.accessLock.Lock()
+	defer p.accessLock.Unlock()
+	if p.remotePubKey != nil && p.remotePubKey.Equal(handover.OldPubKey) {
+		p.log.Infof("Remote PubKey handed over, old=%v, new=%v", p.remotePubKey, handover.NewPubKey)
+		p.remotePubKey = handover.NewPubKey
+	}
+}
+
 func (p *peer) noteReceived() {
 	p.accessLock.Lock()
 	p.lastMsgRecv = time.Now()
@@ -179,11 +242,18 @@ func (p *peer) PubKey() kyber.Point {
 func (p *peer) SendMsg(msg *peering.PeerMessage) {
 	var err error
 	var msgChunks [][]byte
+	if p.net.isBlocked(p.NetID()) {
+		return
+	}
 	if msg.IsUserMessage() {
 		if !p.waitReady.WaitTimeout(sendMsgSyncTimeout) {
 			// Just log a warning and try to send a message anyway.
 			p.log.Warn("Sending a message despite the peering is not established yet, MsgType=%v", msg.MsgType)
 		}
+		if msg, err = p.encryptMsg(msg); err != nil {
+			p.log.Warnf("Dropping outgoing message, reason=%v", err)
+			return
+		}
 	}
 	if msgChunks, err = msg.ChunkedBytes(maxChunkSize, p.msgChopper); err != nil {
 		p.log.Warnf("Dropping outgoing message, unable to encode, reason=%v", err)
@@ -206,6 +276,46 @@ func (p *peer) SendMsg(msg *peering.PeerMessage) {
 	p.lastMsgSent = time.Now()
 }
 
+// encryptMsg wraps the message into the encrypted envelope, if the session with the peer is established.
+// Otherwise the message is sent in plaintext, unless the encryption is required.
+func (p *peer) encryptMsg(msg *peering.PeerMessage) (*peering.PeerMessage, error) {
+	var err error
+	session := p.encrypted()
+	if session == nil {
+		if p.net.requireEncryption {
+			return nil, errors.New("encrypted_session_not_established")
+		}
+		return msg, nil
+	}
+	var msgBin []byte
+	if msgBin, err = msg.Bytes(); err != nil {
+		return nil, err
+	}
+	var sealed []byte
+	if sealed, err = session.seal(msgBin); err != nil {
+		return nil, err
+	}
+	return &peering.PeerMessage{
+		Timestamp: msg.Timestamp,
+		MsgType:   peering.MsgTypeEncrypted,
+		MsgData:   sealed,
+	}, nil
+}
+
+// decryptMsg is the reverse of encryptMsg.
+func (p *peer) decryptMsg(envelope *peering.PeerMessage) (*peering.PeerMessage, error) {
+	var err error
+	session := p.encrypted()
+	if session == nil {
+		return nil, errors.New("encrypted_session_not_established")
+	}
+	var msgBin []byte
+	if msgBin, err = session.open(envelope.MsgData); err != nil {
+		return nil, err
+	}
+	return peering.NewPeerMessageFromBytes(msgBin)
+}
+
 // IsAlive implements peering.PeerSender and peering.PeerStatusProvider interfaces for the remote peers.
 // Return true if is alive and average latencyRingBuf in nanosec.
 func (p *peer) IsAlive() bool {
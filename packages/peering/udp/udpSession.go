@@ -0,0 +1,161 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package udp
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/util"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+)
+
+// sessionKeyContext separates the session keys from other uses of the shared secret.
+const sessionKeyContext = "wasp-peering-session"
+
+// ephemeralKey is the X25519 key pair of the node used for the key exchange with peers.
+// It is generated on each start of the node, so the session keys do not outlive the process.
+// The public part is sent in the handshake signed with the node identity key,
+// that authenticates the key exchange.
+type ephemeralKey struct {
+	private []byte
+	public  []byte
+}
+
+func newEphemeralKey() (*ephemeralKey, error) {
+	private := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(private); err != nil {
+		return nil, err
+	}
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ephemeralKey{private: private, public: public}, nil
+}
+
+// replayWindowSize is the number of the latest sequence numbers remembered by the receiver.
+// Messages may be reordered by the network within the window, older messages are dropped.
+const replayWindowSize = 64
+
+// session encrypts the messages exchanged with the peer. Each direction has its own key, so
+// a message can't be reflected back to its sender. Messages are numbered by the sender,
+// the sequence number is the nonce of the message, and the receiver drops repeated numbers.
+type session struct {
+	send     cipher.AEAD
+	recv     cipher.AEAD
+	lock     sync.Mutex
+	sendSeq  uint64 // The sequence number of the last sent message.
+	recvMax  uint64 // The highest sequence number received.
+	recvMask uint64 // Bit i is set, if recvMax-i was received.
+}
+
+// newSession derives the symmetric keys shared with the peer from the ephemeral keys of both sides.
+// Both peers get the same pair of keys, independently of which of them initiated the handshake.
+func (k *ephemeralKey) newSession(remotePublic []byte) (*session, error) {
+	shared, err := curve25519.X25519(k.private, remotePublic)
+	if err != nil {
+		return nil, err
+	}
+	first, second := k.public, remotePublic
+	if bytes.Compare(first, second) > 0 {
+		first, second = second, first
+	}
+	directionKey := func(from, to []byte) (cipher.AEAD, error) {
+		key := hashing.HashData([]byte(sessionKeyContext), shared, first, second, from, to)
+		return chacha20poly1305.NewX(key[:])
+	}
+	ret := &session{}
+	if ret.send, err = directionKey(k.public, remotePublic); err != nil {
+		return nil, err
+	}
+	if ret.recv, err = directionKey(remotePublic, k.public); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// seal encrypts and authenticates the message. The sequence number is prepended to the result.
+func (s *session) seal(plaintext []byte) ([]byte, error) {
+	s.lock.Lock()
+	if s.sendSeq == math.MaxUint64 {
+		s.lock.Unlock()
+		return nil, errors.New("session_exhausted")
+	}
+	s.sendSeq++
+	seq := s.sendSeq
+	s.lock.Unlock()
+
+	header := util.Uint64To8Bytes(seq)
+	ret := make([]byte, len(header), len(header)+len(plaintext)+s.send.Overhead())
+	copy(ret, header)
+	return s.send.Seal(ret, seqNonce(s.send, seq), plaintext, header), nil
+}
+
+// open is the reverse of seal. It fails on the message which was already received.
+func (s *session) open(data []byte) ([]byte, error) {
+	if len(data) < 8 {
+		return nil, errors.New("encrypted_msg_too_short")
+	}
+	seq, err := util.Uint64From8Bytes(data[:8])
+	if err != nil {
+		return nil, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.isFresh(seq) {
+		return nil, errors.New("encrypted_msg_replayed")
+	}
+	plaintext, err := s.recv.Open(nil, seqNonce(s.recv, seq), data[8:], data[:8])
+	if err != nil {
+		return nil, err
+	}
+	s.markReceived(seq)
+	return plaintext, nil
+}
+
+// isFresh checks the sequence number against the replay window. Should be called with the lock held.
+func (s *session) isFresh(seq uint64) bool {
+	if seq == 0 {
+		return false
+	}
+	if seq > s.recvMax {
+		return true
+	}
+	diff := s.recvMax - seq
+	if diff >= replayWindowSize {
+		return false
+	}
+	return s.recvMask&(1<<diff) == 0
+}
+
+// markReceived moves the replay window. Should be called with the lock held.
+func (s *session) markReceived(seq uint64) {
+	if seq > s.recvMax {
+		shift := seq - s.recvMax
+		if shift >= replayWindowSize {
+			s.recvMask = 0
+		} else {
+			s.recvMask <<= shift
+		}
+		s.recvMask |= 1
+		s.recvMax = seq
+		return
+	}
+	s.recvMask |= 1 << (s.recvMax - seq)
+}
+
+// seqNonce makes the nonce from the sequence number. The key of the direction is never used
+// with the same sequence number twice.
+func seqNonce(aead cipher.AEAD, seq uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	copy(nonce[len(nonce)-8:], util.Uint64To8Bytes(seq))
+	return nonce
+}
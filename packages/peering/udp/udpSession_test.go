@@ -0,0 +1,91 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package udp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession(t *testing.T) {
+	a, err := newEphemeralKey()
+	require.Nil(t, err)
+	b, err := newEphemeralKey()
+	require.Nil(t, err)
+	c, err := newEphemeralKey()
+	require.Nil(t, err)
+
+	sessionAB, err := a.newSession(b.public)
+	require.Nil(t, err)
+	sessionBA, err := b.newSession(a.public)
+	require.Nil(t, err)
+	sessionCA, err := c.newSession(a.public)
+	require.Nil(t, err)
+
+	msg := []byte("committee message")
+	sealed, err := sessionAB.seal(msg)
+	require.Nil(t, err)
+	require.NotContains(t, string(sealed), string(msg))
+	//
+	// The peer opens the message.
+	opened, err := sessionBA.open(sealed)
+	require.Nil(t, err)
+	require.Equal(t, msg, opened)
+	//
+	// Other nodes can't open it.
+	_, err = sessionCA.open(sealed)
+	require.NotNil(t, err)
+	//
+	// The message can't be reflected to the sender.
+	_, err = sessionAB.open(sealed)
+	require.NotNil(t, err)
+	//
+	// Damaged message.
+	damaged, err := sessionAB.seal(msg)
+	require.Nil(t, err)
+	damaged[len(damaged)-1]++
+	_, err = sessionBA.open(damaged)
+	require.NotNil(t, err)
+	_, err = sessionBA.open(damaged[:5])
+	require.NotNil(t, err)
+}
+
+func TestSessionReplay(t *testing.T) {
+	a, err := newEphemeralKey()
+	require.Nil(t, err)
+	b, err := newEphemeralKey()
+	require.Nil(t, err)
+	sessionAB, err := a.newSession(b.public)
+	require.Nil(t, err)
+	sessionBA, err := b.newSession(a.public)
+	require.Nil(t, err)
+
+	sealed := make([][]byte, replayWindowSize+2)
+	for i := range sealed {
+		sealed[i], err = sessionAB.seal([]byte{byte(i)})
+		require.Nil(t, err)
+	}
+	//
+	// The message is accepted once.
+	_, err = sessionBA.open(sealed[1])
+	require.Nil(t, err)
+	_, err = sessionBA.open(sealed[1])
+	require.NotNil(t, err)
+	//
+	// Reordered messages within the window are accepted.
+	_, err = sessionBA.open(sealed[replayWindowSize])
+	require.Nil(t, err)
+	opened, err := sessionBA.open(sealed[2])
+	require.Nil(t, err)
+	require.Equal(t, []byte{2}, opened)
+	_, err = sessionBA.open(sealed[2])
+	require.NotNil(t, err)
+	//
+	// Messages older than the window are dropped.
+	_, err = sessionBA.open(sealed[replayWindowSize+1])
+	require.Nil(t, err)
+	_, err = sessionBA.open(sealed[0])
+	require.NotNil(t, err)
+}
@@ -1,42 +1,87 @@
 package registry
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/timeutil"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/dbprovider"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv/codec"
-	"time"
+	"github.com/iotaledger/wasp/packages/util"
 )
 
 // implements BlobCacheProvide interface
+//
+// The blob cache is the content-addressed blob store of the node, shared by all chains.
+// Blobs uploaded for a chain (PutChainBlob) are referenced by the chain and are counted
+// against the blob quota of the chain. Blobs are kept as long as at least one chain references
+// them. Unreferenced blobs expire after the TTL and are removed by CleanupBlobs
+
+// DefaultBlobQuota is the default maximum total size of blobs referenced by one chain, in bytes
+const DefaultBlobQuota = 64 * 1024 * 1024
+
+// BlobCleanupPeriod is the period of removal of expired blobs by RunBlobCleanup
+const BlobCleanupPeriod = 10 * time.Minute
 
-// TODO blob cache cleanup
+var ErrBlobQuotaExceeded = errors.New("blob quota of the chain exceeded")
 
 func dbKeyForBlob(h hashing.HashValue) []byte {
 	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobCache, h[:])
 }
 
 func dbKeyForBlobTTL(h hashing.HashValue) []byte {
-	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobCacheTTL)
+	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobCacheTTL, h[:])
+}
+
+// dbKeyForBlobRef is the reference of the blob by the chain, indexed by the blob
+func dbKeyForBlobRef(h hashing.HashValue, chainID *coretypes.ChainID) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeBlobRef, h[:], chainID[:])
+}
+
+// dbKeyForChainBlob is the reference of the blob by the chain, indexed by the chain. The value is the size of the blob
+func dbKeyForChainBlob(chainID *coretypes.ChainID, h hashing.HashValue) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeChainBlob, chainID[:], h[:])
+}
+
+// SetBlobQuota sets the maximum total size of blobs referenced by one chain, in bytes. 0 means unlimited
+func (r *Impl) SetBlobQuota(quota int64) {
+	r.blobMutex.Lock()
+	defer r.blobMutex.Unlock()
+	r.blobQuota = quota
+}
+
+// BlobQuota returns the maximum total size of blobs referenced by one chain, in bytes. 0 means unlimited
+func (r *Impl) BlobQuota() int64 {
+	r.blobMutex.Lock()
+	defer r.blobMutex.Unlock()
+	return r.blobQuota
 }
 
 // PutBlob Writes data into the registry with the key of its hash
-// Also stores TTL if provided
+// Also stores TTL if provided. The TTL is ignored if the blob is referenced by a chain
 func (r *Impl) PutBlob(data []byte, ttl ...time.Duration) (hashing.HashValue, error) {
+	r.blobMutex.Lock()
+	defer r.blobMutex.Unlock()
+
 	h := hashing.HashData(data)
 	err := r.dbProvider.GetRegistryPartition().Set(dbKeyForBlob(h), data)
 	if err != nil {
 		return hashing.NilHash, err
 	}
-	nowis := time.Now()
-	cleanAfter := nowis.Add(coretypes.DefaultTTL).UnixNano()
-	if len(ttl) > 0 {
-		cleanAfter = nowis.Add(ttl[0]).UnixNano()
+	referenced, err := r.isBlobReferenced(h)
+	if err != nil {
+		return hashing.NilHash, err
 	}
-	if cleanAfter > 0 {
-		err = r.dbProvider.GetRegistryPartition().Set(dbKeyForBlobTTL(h), codec.EncodeInt64(cleanAfter))
-		if err != nil {
+	if !referenced {
+		t := coretypes.DefaultTTL
+		if len(ttl) > 0 {
+			t = ttl[0]
+		}
+		if err := r.setBlobTTL(h, t); err != nil {
 			return hashing.NilHash, err
 		}
 	}
@@ -44,6 +89,116 @@ func (r *Impl) PutBlob(data []byte, ttl ...time.Duration) (hashing.HashValue, er
 	return h, nil
 }
 
+// PutChainBlob writes data into the registry with the key of its hash and makes the chain reference it.
+// The blob doesn't expire while referenced. Returns ErrBlobQuotaExceeded if the total size of blobs
+// referenced by the chain would exceed the quota
+func (r *Impl) PutChainBlob(chainID *coretypes.ChainID, data []byte) (hashing.HashValue, error) {
+	r.blobMutex.Lock()
+	defer r.blobMutex.Unlock()
+
+	db := r.dbProvider.GetRegistryPartition()
+	h := hashing.HashData(data)
+	exists, err := db.Has(dbKeyForChainBlob(chainID, h))
+	if err != nil {
+		return hashing.NilHash, err
+	}
+	if !exists && r.blobQuota > 0 {
+		usage, _, err := r.chainBlobUsage(chainID)
+		if err != nil {
+			return hashing.NilHash, err
+		}
+		if usage+int64(len(data)) > r.blobQuota {
+			return hashing.NilHash, fmt.Errorf("%w: %d bytes used of %d, blob size %d bytes",
+				ErrBlobQuotaExceeded, usage, r.blobQuota, len(data))
+		}
+	}
+	if err := db.Set(dbKeyForBlob(h), data); err != nil {
+		return hashing.NilHash, err
+	}
+	if err := db.Set(dbKeyForBlobRef(h, chainID), []byte{}); err != nil {
+		return hashing.NilHash, err
+	}
+	if err := db.Set(dbKeyForChainBlob(chainID, h), util.Uint32To4Bytes(uint32(len(data)))); err != nil {
+		return hashing.NilHash, err
+	}
+	if err := db.Delete(dbKeyForBlobTTL(h)); err != nil {
+		return hashing.NilHash, err
+	}
+	r.log.Infof("data blob has been stored for chain %s. size: %d bytes, hash: %s", chainID, len(data), h)
+	return h, nil
+}
+
+// ReleaseChainBlob removes the reference of the blob by the chain. When the blob is not referenced
+// by any chain, it expires after the default TTL
+func (r *Impl) ReleaseChainBlob(chainID *coretypes.ChainID, h hashing.HashValue) error {
+	r.blobMutex.Lock()
+	defer r.blobMutex.Unlock()
+
+	db := r.dbProvider.GetRegistryPartition()
+	if err := db.Delete(dbKeyForBlobRef(h, chainID)); err != nil {
+		return err
+	}
+	if err := db.Delete(dbKeyForChainBlob(chainID, h)); err != nil {
+		return err
+	}
+	referenced, err := r.isBlobReferenced(h)
+	if err != nil || referenced {
+		return err
+	}
+	return r.setBlobTTL(h, coretypes.DefaultTTL)
+}
+
+// ChainBlobUsage returns the total size in bytes and the number of blobs referenced by the chain
+func (r *Impl) ChainBlobUsage(chainID *coretypes.ChainID) (int64, int, error) {
+	r.blobMutex.Lock()
+	defer r.blobMutex.Unlock()
+	return r.chainBlobUsage(chainID)
+}
+
+// CleanupBlobs removes unreferenced blobs with expired TTL. Returns number of removed blobs
+func (r *Impl) CleanupBlobs() (int, error) {
+	r.blobMutex.Lock()
+	defer r.blobMutex.Unlock()
+
+	db := r.dbProvider.GetRegistryPartition()
+	now := time.Now().UnixNano()
+	expired := make([]hashing.HashValue, 0)
+	err := db.Iterate([]byte{dbprovider.ObjectTypeBlobCacheTTL}, func(key kvstore.Key, value kvstore.Value) bool {
+		cleanAfter, _, err := codec.DecodeInt64(value)
+		if err != nil || cleanAfter > now {
+			return true
+		}
+		if h, err := hashing.HashValueFromBytes(key[1:]); err == nil {
+			expired = append(expired, h)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, h := range expired {
+		if err := db.Delete(dbKeyForBlob(h)); err != nil {
+			return 0, err
+		}
+		if err := db.Delete(dbKeyForBlobTTL(h)); err != nil {
+			return 0, err
+		}
+	}
+	if len(expired) > 0 {
+		r.log.Infof("removed %d expired data blob(s)", len(expired))
+	}
+	return len(expired), nil
+}
+
+// RunBlobCleanup periodically removes expired blobs until the shutdown signal
+func (r *Impl) RunBlobCleanup(shutdownSignal <-chan struct{}) {
+	timeutil.NewTicker(func() {
+		if _, err := r.CleanupBlobs(); err != nil {
+			r.log.Warnf("blob cleanup failed: %s", err)
+		}
+	}, BlobCleanupPeriod, shutdownSignal)
+}
+
 // Reads data from registry by hash. Returns existence flag
 func (r *Impl) GetBlob(h hashing.HashValue) ([]byte, bool, error) {
 	ret, err := r.dbProvider.GetRegistryPartition().Get(dbKeyForBlob(h))
@@ -56,3 +211,32 @@ func (r *Impl) GetBlob(h hashing.HashValue) ([]byte, bool, error) {
 func (r *Impl) HasBlob(h hashing.HashValue) (bool, error) {
 	return r.dbProvider.GetRegistryPartition().Has(dbKeyForBlob(h))
 }
+
+func (r *Impl) setBlobTTL(h hashing.HashValue, ttl time.Duration) error {
+	cleanAfter := time.Now().Add(ttl).UnixNano()
+	return r.dbProvider.GetRegistryPartition().Set(dbKeyForBlobTTL(h), codec.EncodeInt64(cleanAfter))
+}
+
+func (r *Impl) isBlobReferenced(h hashing.HashValue) (bool, error) {
+	referenced := false
+	err := r.dbProvider.GetRegistryPartition().Iterate(dbprovider.MakeKey(dbprovider.ObjectTypeBlobRef, h[:]),
+		func(key kvstore.Key, value kvstore.Value) bool {
+			referenced = true
+			return false
+		})
+	return referenced, err
+}
+
+func (r *Impl) chainBlobUsage(chainID *coretypes.ChainID) (int64, int, error) {
+	var size int64
+	count := 0
+	err := r.dbProvider.GetRegistryPartition().Iterate(dbprovider.MakeKey(dbprovider.ObjectTypeChainBlob, chainID[:]),
+		func(key kvstore.Key, value kvstore.Value) bool {
+			if s, err := util.Uint32From4Bytes(value); err == nil {
+				size += int64(s)
+				count++
+			}
+			return true
+		})
+	return size, count, err
+}
@@ -1,11 +1,15 @@
 package registry
 
 import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/dbprovider"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/testutil"
 	"github.com/stretchr/testify/require"
-	"testing"
 )
 
 func TestBlobPutGet(t *testing.T) {
@@ -25,3 +29,85 @@ func TestBlobPutGet(t *testing.T) {
 	require.True(t, ok)
 	require.EqualValues(t, data, back)
 }
+
+func TestBlobTTL(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+
+	expiring, err := reg.PutBlob([]byte("expiring"), time.Nanosecond)
+	require.NoError(t, err)
+	kept, err := reg.PutBlob([]byte("kept"))
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond)
+	n, err := reg.CleanupBlobs()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, n)
+
+	ok, err := reg.HasBlob(expiring)
+	require.NoError(t, err)
+	require.False(t, ok)
+	ok, err = reg.HasBlob(kept)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestChainBlobRefs(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+	chain1 := coretypes.ChainID{1}
+	chain2 := coretypes.ChainID{2}
+
+	data := []byte("shared-data")
+	h, err := reg.PutBlob(data, time.Nanosecond)
+	require.NoError(t, err)
+	_, err = reg.PutChainBlob(&chain1, data)
+	require.NoError(t, err)
+	_, err = reg.PutChainBlob(&chain2, data)
+	require.NoError(t, err)
+
+	// referenced blobs don't expire
+	time.Sleep(time.Millisecond)
+	n, err := reg.CleanupBlobs()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, n)
+
+	size, count, err := reg.ChainBlobUsage(&chain1)
+	require.NoError(t, err)
+	require.EqualValues(t, len(data), size)
+	require.EqualValues(t, 1, count)
+
+	require.NoError(t, reg.ReleaseChainBlob(&chain1, h))
+	size, count, err = reg.ChainBlobUsage(&chain1)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, size)
+	require.EqualValues(t, 0, count)
+
+	require.NoError(t, reg.ReleaseChainBlob(&chain2, h))
+	ok, err := reg.HasBlob(h)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestChainBlobQuota(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+	reg.SetBlobQuota(10)
+	chainID := coretypes.ChainID{1}
+
+	_, err := reg.PutChainBlob(&chainID, []byte("12345678"))
+	require.NoError(t, err)
+	// the same blob is not counted twice
+	_, err = reg.PutChainBlob(&chainID, []byte("12345678"))
+	require.NoError(t, err)
+	_, err = reg.PutChainBlob(&chainID, []byte("123"))
+	require.True(t, errors.Is(err, ErrBlobQuotaExceeded))
+
+	// quota is per chain
+	otherChainID := coretypes.ChainID{2}
+	_, err = reg.PutChainBlob(&otherChainID, []byte("123"))
+	require.NoError(t, err)
+}
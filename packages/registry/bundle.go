@@ -0,0 +1,287 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/util"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/encrypt/ecies"
+	"go.dedis.ch/kyber/v3/sign/bdn"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+// Bundle is a snapshot of the registry records needed to run the node's chains
+// on another machine: chain records (including committee nodes) and DK shares.
+// DK shares contain private key material, so they are encrypted to the identity key
+// of the importing node. The bundle is signed with the identity key of the exporting node.
+type Bundle struct {
+	ChainRecords      []*ChainRecord
+	EncryptedDKShares [][]byte
+	NodePubKey        kyber.Point
+	RecipientPubKey   kyber.Point
+	Signature         []byte
+	// DKShares are decrypted by Open. They are never serialized.
+	DKShares []*tcrypto.DKShare
+}
+
+// ExportBundle collects all chain records and DK shares of the node into a signed bundle.
+// DK shares are encrypted to the recipient, the identity public key of the node which will import the bundle.
+func (r *Impl) ExportBundle(recipient kyber.Point) (*Bundle, error) {
+	if recipient == nil {
+		return nil, fmt.Errorf("public key of the importing node is required")
+	}
+	chainRecords, err := GetChainRecords()
+	if err != nil {
+		return nil, err
+	}
+	dkShares, err := r.loadAllDKShares()
+	if err != nil {
+		return nil, err
+	}
+	pair, err := r.GetNodeIdentity()
+	if err != nil {
+		return nil, err
+	}
+	return newBundle(r.suite, pair, recipient, chainRecords, dkShares)
+}
+
+// ImportBundle parses the serialized bundle, checks it was signed by the trusted exporting node,
+// decrypts the DK shares with the identity key of the node and stores the records in the registry.
+// Records which already exist in the registry are left untouched. Imported chain records are always stored as inactive.
+func (r *Impl) ImportBundle(data []byte, exporter kyber.Point) (*Bundle, error) {
+	b, err := BundleFromBytes(data, r.suite)
+	if err != nil {
+		return nil, err
+	}
+	pair, err := r.GetNodeIdentity()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Open(r.suite, exporter, pair); err != nil {
+		return nil, err
+	}
+	kvStore := r.dbProvider.GetRegistryPartition()
+	for _, dkShare := range b.DKShares {
+		exists, err := kvStore.Has(dbKeyForDKShare(dkShare.Address))
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			r.log.Warnf("DK share %s already exists, skipping", dkShare.Address)
+			continue
+		}
+		if err := r.SaveDKShare(dkShare); err != nil {
+			return nil, err
+		}
+	}
+	for _, rec := range b.ChainRecords {
+		existing, err := GetChainRecord(&rec.ChainID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			r.log.Warnf("chain record %s already exists, skipping", rec.ChainID)
+			continue
+		}
+		rec.Active = false
+		if err := SaveChainRecord(rec); err != nil {
+			return nil, err
+		}
+	}
+	r.log.Infof("registry bundle imported: %d chain record(s), %d DK share(s)", len(b.ChainRecords), len(b.DKShares))
+	return b, nil
+}
+
+func (r *Impl) loadAllDKShares() ([]*tcrypto.DKShare, error) {
+	ret := make([]*tcrypto.DKShare, 0)
+	var innerErr error
+	err := r.dbProvider.GetRegistryPartition().Iterate([]byte{dbprovider.ObjectTypeDistributedKeyData}, func(key kvstore.Key, value kvstore.Value) bool {
+		dkShare, err := r.dkShareFromBytes(value)
+		if err != nil {
+			innerErr = err
+			return false
+		}
+		ret = append(ret, dkShare)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if innerErr != nil {
+		return nil, innerErr
+	}
+	return ret, nil
+}
+
+func newBundle(suite tcrypto.Suite, exporter *key.Pair, recipient kyber.Point, chainRecords []*ChainRecord, dkShares []*tcrypto.DKShare) (*Bundle, error) {
+	ret := &Bundle{
+		ChainRecords:      chainRecords,
+		EncryptedDKShares: make([][]byte, len(dkShares)),
+		RecipientPubKey:   recipient,
+		DKShares:          dkShares,
+	}
+	for i, dkShare := range dkShares {
+		data, err := dkShare.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		if ret.EncryptedDKShares[i], err = ecies.Encrypt(suite, recipient, data, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := ret.sign(suite, exporter); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (b *Bundle) sign(suite tcrypto.Suite, pair *key.Pair) error {
+	b.NodePubKey = pair.Public
+	essence, err := b.essenceBytes()
+	if err != nil {
+		return err
+	}
+	b.Signature, err = bdn.Sign(suite, pair.Private, essence)
+	return err
+}
+
+// VerifySignature checks the bundle was signed by the exporter. The public key of the exporter
+// must come from a trusted source, never from the bundle itself.
+func (b *Bundle) VerifySignature(suite tcrypto.Suite, exporter kyber.Point) error {
+	if exporter == nil {
+		return fmt.Errorf("public key of the exporting node is required")
+	}
+	if !b.NodePubKey.Equal(exporter) {
+		return fmt.Errorf("registry bundle is not exported by the trusted node")
+	}
+	essence, err := b.essenceBytes()
+	if err != nil {
+		return err
+	}
+	if err := bdn.Verify(suite, exporter, essence, b.Signature); err != nil {
+		return fmt.Errorf("invalid registry bundle signature: %v", err)
+	}
+	return nil
+}
+
+// Open verifies the bundle against the public key of the exporter and decrypts
+// its DK shares with the identity key of the recipient.
+func (b *Bundle) Open(suite tcrypto.Suite, exporter kyber.Point, recipient *key.Pair) error {
+	if err := b.VerifySignature(suite, exporter); err != nil {
+		return err
+	}
+	if !b.RecipientPubKey.Equal(recipient.Public) {
+		return fmt.Errorf("registry bundle is encrypted to another node")
+	}
+	b.DKShares = make([]*tcrypto.DKShare, len(b.EncryptedDKShares))
+	for i, ciphertext := range b.EncryptedDKShares {
+		data, err := ecies.Decrypt(suite, recipient.Private, ciphertext, nil)
+		if err != nil {
+			return fmt.Errorf("can't decrypt DK share #%d: %v", i, err)
+		}
+		if b.DKShares[i], err = tcrypto.DKShareFromBytes(data, suite); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BundleFromBytes deserializes the bundle.
+func BundleFromBytes(data []byte, suite tcrypto.Suite) (*Bundle, error) {
+	ret := &Bundle{}
+	if err := ret.Read(bytes.NewReader(data), suite); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Bytes returns the serialized bundle.
+func (b *Bundle) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (b *Bundle) essenceBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := b.writeEssence(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (b *Bundle) writeEssence(w io.Writer) error {
+	if err := util.WriteUint16(w, uint16(len(b.ChainRecords))); err != nil {
+		return err
+	}
+	for _, rec := range b.ChainRecords {
+		if err := rec.Write(w); err != nil {
+			return err
+		}
+	}
+	if err := util.WriteUint16(w, uint16(len(b.EncryptedDKShares))); err != nil {
+		return err
+	}
+	for _, data := range b.EncryptedDKShares {
+		if err := util.WriteBytes32(w, data); err != nil {
+			return err
+		}
+	}
+	if err := util.WriteMarshaled(w, b.NodePubKey); err != nil {
+		return err
+	}
+	return util.WriteMarshaled(w, b.RecipientPubKey)
+}
+
+func (b *Bundle) Write(w io.Writer) error {
+	if err := b.writeEssence(w); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, b.Signature)
+}
+
+func (b *Bundle) Read(r io.Reader, suite tcrypto.Suite) error {
+	var err error
+	var n uint16
+	if err = util.ReadUint16(r, &n); err != nil {
+		return err
+	}
+	b.ChainRecords = make([]*ChainRecord, n)
+	for i := range b.ChainRecords {
+		b.ChainRecords[i] = new(ChainRecord)
+		if err = b.ChainRecords[i].Read(r); err != nil {
+			return err
+		}
+	}
+	if err = util.ReadUint16(r, &n); err != nil {
+		return err
+	}
+	b.EncryptedDKShares = make([][]byte, n)
+	for i := range b.EncryptedDKShares {
+		if b.EncryptedDKShares[i], err = util.ReadBytes32(r); err != nil {
+			return err
+		}
+	}
+	b.NodePubKey = suite.Point()
+	if err = util.ReadMarshaled(r, b.NodePubKey); err != nil {
+		return err
+	}
+	b.RecipientPubKey = suite.Point()
+	if err = util.ReadMarshaled(r, b.RecipientPubKey); err != nil {
+		return err
+	}
+	if b.Signature, err = util.ReadBytes16(r); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+func TestBundleSignAndSerialize(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	exporter := key.NewKeyPair(suite)
+	recipient := key.NewKeyPair(suite)
+
+	recs := []*ChainRecord{{
+		ChainID:        coretypes.ChainID{1, 2, 3},
+		Color:          balance.Color{4, 5, 6},
+		CommitteeNodes: []string{"wasp1:4000", "wasp2:4000"},
+		Active:         true,
+	}}
+	priv := suite.Scalar().Pick(suite.RandomStream())
+	pub := suite.Point().Mul(priv, nil)
+	dkShare, err := tcrypto.NewDKShare(0, 1, 1, pub, []kyber.Point{pub}, []kyber.Point{pub}, priv)
+	require.NoError(t, err)
+
+	b, err := newBundle(suite, exporter, recipient.Public, recs, []*tcrypto.DKShare{dkShare})
+	require.NoError(t, err)
+	require.NoError(t, b.VerifySignature(suite, exporter.Public))
+
+	data, err := b.Bytes()
+	require.NoError(t, err)
+	shareData, err := dkShare.Bytes()
+	require.NoError(t, err)
+	require.NotContains(t, string(data), string(shareData))
+
+	back, err := BundleFromBytes(data, suite)
+	require.NoError(t, err)
+	require.Nil(t, back.DKShares)
+	require.NoError(t, back.Open(suite, exporter.Public, recipient))
+	require.Len(t, back.ChainRecords, 1)
+	require.EqualValues(t, recs[0].ChainID, back.ChainRecords[0].ChainID)
+	require.EqualValues(t, recs[0].CommitteeNodes, back.ChainRecords[0].CommitteeNodes)
+	require.Len(t, back.DKShares, 1)
+	require.EqualValues(t, *dkShare.Address, *back.DKShares[0].Address)
+	require.True(t, dkShare.PrivateShare.Equal(back.DKShares[0].PrivateShare))
+
+	back.ChainRecords[0].CommitteeNodes = []string{"evil:4000"}
+	require.Error(t, back.VerifySignature(suite, exporter.Public))
+}
+
+func TestBundleUntrusted(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	exporter := key.NewKeyPair(suite)
+	recipient := key.NewKeyPair(suite)
+	attacker := key.NewKeyPair(suite)
+
+	// self-signed by an attacker: valid signature, but not by the trusted exporter
+	b, err := newBundle(suite, attacker, recipient.Public, []*ChainRecord{{ChainID: coretypes.ChainID{1}}}, nil)
+	require.NoError(t, err)
+	require.Error(t, b.VerifySignature(suite, exporter.Public))
+	require.Error(t, b.VerifySignature(suite, nil))
+	require.Error(t, b.Open(suite, exporter.Public, recipient))
+
+	// encrypted to another node
+	b, err = newBundle(suite, exporter, attacker.Public, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, b.VerifySignature(suite, exporter.Public))
+	require.Error(t, b.Open(suite, exporter.Public, recipient))
+}
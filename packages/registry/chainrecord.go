@@ -16,11 +16,15 @@ import (
 )
 
 // ChainRecord is a minimum data needed to load a committee for the chain
-// it is up to the node (not smart contract) to check authorizations to create/update this record
+// it is up to the node (not smart contract) to check authorizations to create/update this record.
+// Access nodes follow the state of the chain by fetching blocks from committee nodes and serve view calls
+// without taking part in the consensus. The node is an access node of the chain if its network ID is in AccessNodes.
+// Committee nodes serve blocks only to access nodes listed in their chain record
 type ChainRecord struct {
 	ChainID        coretypes.ChainID
 	Color          balance.Color // origin tx hash
 	CommitteeNodes []string      // "host_addr:port"
+	AccessNodes    []string      // "host_addr:port"
 	Active         bool
 }
 
@@ -39,7 +43,7 @@ func SaveChainRecord(bd *ChainRecord) error {
 	if err := bd.Write(&buf); err != nil {
 		return err
 	}
-	if err := database.GetRegistryPartition().Set(dbkeyChainRecord(&bd.ChainID), buf.Bytes()); err != nil {
+	if err := database.GetRegistryPartition().Set(dbkeyChainRecord(&bd.ChainID), encodeRecord(chainRecordVersion, buf.Bytes())); err != nil {
 		return err
 	}
 	publisher.Publish("chainrec", bd.ChainID.String(), bd.Color.String())
@@ -54,6 +58,14 @@ func GetChainRecord(chainID *coretypes.ChainID) (*ChainRecord, error) {
 	if err != nil {
 		return nil, err
 	}
+	return chainRecordFromBytes(data)
+}
+
+func chainRecordFromBytes(value []byte) (*ChainRecord, error) {
+	data, err := decodeRecord(chainRecordVersion, value)
+	if err != nil {
+		return nil, err
+	}
 	ret := new(ChainRecord)
 	if err := ret.Read(bytes.NewReader(data)); err != nil {
 		return nil, err
@@ -103,11 +115,10 @@ func GetChainRecords() ([]*ChainRecord, error) {
 	ret := make([]*ChainRecord, 0)
 
 	err := db.Iterate([]byte{dbprovider.ObjectTypeChainRecord}, func(key kvstore.Key, value kvstore.Value) bool {
-		bd := new(ChainRecord)
-		if err := bd.Read(bytes.NewReader(value)); err == nil {
+		if bd, err := chainRecordFromBytes(value); err == nil {
 			ret = append(ret, bd)
 		} else {
-			log.Warnf("corrupted chain record with key %s", base58.Encode(key))
+			log.Warnf("corrupted chain record with key %s: %v", base58.Encode(key), err)
 		}
 		return true
 	})
@@ -124,6 +135,9 @@ func (bd *ChainRecord) Write(w io.Writer) error {
 	if err := util.WriteStrings16(w, bd.CommitteeNodes); err != nil {
 		return err
 	}
+	if err := util.WriteStrings16(w, bd.AccessNodes); err != nil {
+		return err
+	}
 	if err := util.WriteBoolByte(w, bd.Active); err != nil {
 		return err
 	}
@@ -141,6 +155,9 @@ func (bd *ChainRecord) Read(r io.Reader) error {
 	if bd.CommitteeNodes, err = util.ReadStrings16(r); err != nil {
 		return err
 	}
+	if bd.AccessNodes, err = util.ReadStrings16(r); err != nil {
+		return err
+	}
 	if err = util.ReadBoolByte(r, &bd.Active); err != nil {
 		return err
 	}
@@ -151,5 +168,8 @@ func (bd *ChainRecord) String() string {
 	ret := "      Target: " + bd.ChainID.String() + "\n"
 	ret += "      Color: " + bd.Color.String() + "\n"
 	ret += fmt.Sprintf("      Committee nodes: %+v\n", bd.CommitteeNodes)
+	if len(bd.AccessNodes) > 0 {
+		ret += fmt.Sprintf("      Access nodes: %+v\n", bd.AccessNodes)
+	}
 	return ret
 }
@@ -0,0 +1,263 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/timeutil"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/packages/util"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/sign/bdn"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+// State checkpoints.
+// The node periodically records the index and the hash of the solid state of each active chain, together with
+// the essence hash of its last block, in the registry. The checkpoint is signed with the identity key of the node.
+// After the crash recovery VerifyStateCheckpoint checks the state stored in the DB against the last checkpoint:
+// the state which went back behind the checkpoint or can't be reproduced from its blocks is corrupted
+
+// DefaultCheckpointPeriod is the default period of recording state checkpoints by RunStateCheckpointing
+const DefaultCheckpointPeriod = 1 * time.Minute
+
+// StateCheckpoint is the signed record of the solid state of the chain at some moment
+type StateCheckpoint struct {
+	ChainID    coretypes.ChainID
+	StateIndex uint32
+	StateHash  hashing.HashValue
+	// BlockHash is the essence hash of the block with the index StateIndex
+	BlockHash  hashing.HashValue
+	Timestamp  int64
+	NodePubKey kyber.Point
+	Signature  []byte
+}
+
+func dbkeyStateCheckpoint(chainID *coretypes.ChainID) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeStateCheckpoint, chainID[:])
+}
+
+// CheckpointState records the checkpoint of the current solid state of the chain, unless the last checkpoint
+// has the same state index. Returns the last checkpoint, nil if the chain has no state yet
+func (r *Impl) CheckpointState(chainID *coretypes.ChainID) (*StateCheckpoint, error) {
+	vs, block, exists, err := state.LoadSolidState(chainID)
+	if err != nil || !exists {
+		return nil, err
+	}
+	last, err := r.GetStateCheckpoint(chainID)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil && last.StateIndex == vs.BlockIndex() {
+		return last, nil
+	}
+	pair, err := r.GetNodeIdentity()
+	if err != nil {
+		return nil, err
+	}
+	cp := &StateCheckpoint{
+		ChainID:    *chainID,
+		StateIndex: vs.BlockIndex(),
+		StateHash:  vs.Hash(),
+		BlockHash:  block.EssenceHash(),
+		Timestamp:  time.Now().UnixNano(),
+	}
+	if err := cp.sign(r.suite, pair); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := cp.Write(&buf); err != nil {
+		return nil, err
+	}
+	if err := r.dbProvider.GetRegistryPartition().Set(dbkeyStateCheckpoint(chainID), buf.Bytes()); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// GetStateCheckpoint returns the last checkpoint of the chain or nil if there's none
+func (r *Impl) GetStateCheckpoint(chainID *coretypes.ChainID) (*StateCheckpoint, error) {
+	data, err := r.dbProvider.GetRegistryPartition().Get(dbkeyStateCheckpoint(chainID))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ret := new(StateCheckpoint)
+	if err := ret.Read(bytes.NewReader(data), r.suite); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// CheckpointStates records checkpoints of all active chains
+func (r *Impl) CheckpointStates() error {
+	chainRecords, err := GetChainRecords()
+	if err != nil {
+		return err
+	}
+	for _, rec := range chainRecords {
+		if !rec.Active {
+			continue
+		}
+		if _, err := r.CheckpointState(&rec.ChainID); err != nil {
+			r.log.Warnf("state checkpoint of chain %s failed: %v", rec.ChainID.String(), err)
+		}
+	}
+	return nil
+}
+
+// RunStateCheckpointing periodically records checkpoints of all active chains until the shutdown signal
+func (r *Impl) RunStateCheckpointing(period time.Duration, shutdownSignal <-chan struct{}) {
+	timeutil.NewTicker(func() {
+		if err := r.CheckpointStates(); err != nil {
+			r.log.Warnf("state checkpointing failed: %s", err)
+		}
+	}, period, shutdownSignal)
+}
+
+// VerifyStateCheckpoint checks the state of the chain stored in the DB against the last checkpoint:
+//  - the checkpoint must be signed by the node
+//  - the solid state must not be behind the checkpoint
+//  - the block of the checkpoint must have the same essence hash
+//  - the state replayed from all blocks must have the hash of the checkpoint at its index and be equal
+//    to the solid state, see state.VerifySolidState
+// Returns the checkpoint, nil if there's none. Errors of inconsistent state wrap state.ErrStateCorrupted
+func (r *Impl) VerifyStateCheckpoint(chainID *coretypes.ChainID) (*StateCheckpoint, error) {
+	cp, err := r.GetStateCheckpoint(chainID)
+	if err != nil || cp == nil {
+		return nil, err
+	}
+	pubKey, err := r.GetNodePublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if !cp.NodePubKey.Equal(pubKey) {
+		return cp, fmt.Errorf("%w: the checkpoint is not recorded by the node", state.ErrStateCorrupted)
+	}
+	if err := cp.VerifySignature(r.suite); err != nil {
+		return cp, fmt.Errorf("%w: %v", state.ErrStateCorrupted, err)
+	}
+	vs, err := state.VerifySolidState(chainID, func(vs state.VirtualState, b state.Block) error {
+		if vs.BlockIndex() != cp.StateIndex {
+			return nil
+		}
+		if b.EssenceHash() != cp.BlockHash {
+			return fmt.Errorf("%w: block #%d has essence hash %s, the checkpoint has %s",
+				state.ErrStateCorrupted, cp.StateIndex, b.EssenceHash().String(), cp.BlockHash.String())
+		}
+		if vs.Hash() != cp.StateHash {
+			return fmt.Errorf("%w: state #%d has hash %s, the checkpoint has %s",
+				state.ErrStateCorrupted, cp.StateIndex, vs.Hash().String(), cp.StateHash.String())
+		}
+		return nil
+	})
+	if err != nil {
+		return cp, err
+	}
+	if vs == nil {
+		return cp, fmt.Errorf("%w: state not found", state.ErrStateCorrupted)
+	}
+	if vs.BlockIndex() < cp.StateIndex {
+		return cp, fmt.Errorf("%w: state #%d is behind the checkpoint #%d",
+			state.ErrStateCorrupted, vs.BlockIndex(), cp.StateIndex)
+	}
+	return cp, nil
+}
+
+func (cp *StateCheckpoint) sign(suite tcrypto.Suite, pair *key.Pair) error {
+	cp.NodePubKey = pair.Public
+	essence, err := cp.essenceBytes()
+	if err != nil {
+		return err
+	}
+	cp.Signature, err = bdn.Sign(suite, pair.Private, essence)
+	return err
+}
+
+// VerifySignature checks the checkpoint signature against the public key of the node which recorded it
+func (cp *StateCheckpoint) VerifySignature(suite tcrypto.Suite) error {
+	essence, err := cp.essenceBytes()
+	if err != nil {
+		return err
+	}
+	if err := bdn.Verify(suite, cp.NodePubKey, essence, cp.Signature); err != nil {
+		return fmt.Errorf("invalid state checkpoint signature: %v", err)
+	}
+	return nil
+}
+
+func (cp *StateCheckpoint) String() string {
+	return fmt.Sprintf("checkpoint of state #%d of chain %s: state hash %s, block hash %s",
+		cp.StateIndex, cp.ChainID.String(), cp.StateHash.String(), cp.BlockHash.String())
+}
+
+func (cp *StateCheckpoint) essenceBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := cp.writeEssence(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (cp *StateCheckpoint) writeEssence(w io.Writer) error {
+	if err := cp.ChainID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, cp.StateIndex); err != nil {
+		return err
+	}
+	if _, err := w.Write(cp.StateHash[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(cp.BlockHash[:]); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, cp.Timestamp); err != nil {
+		return err
+	}
+	return util.WriteMarshaled(w, cp.NodePubKey)
+}
+
+func (cp *StateCheckpoint) Write(w io.Writer) error {
+	if err := cp.writeEssence(w); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, cp.Signature)
+}
+
+func (cp *StateCheckpoint) Read(r io.Reader, suite tcrypto.Suite) error {
+	if err := cp.ChainID.Read(r); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &cp.StateIndex); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &cp.StateHash); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &cp.BlockHash); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &cp.Timestamp); err != nil {
+		return err
+	}
+	cp.NodePubKey = suite.Point()
+	if err := util.ReadMarshaled(r, cp.NodePubKey); err != nil {
+		return err
+	}
+	var err error
+	cp.Signature, err = util.ReadBytes16(r)
+	return err
+}
@@ -0,0 +1,42 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+func TestStateCheckpointSignAndSerialize(t *testing.T) {
+	suite := pairing.NewSuiteBn256()
+	pair := key.NewKeyPair(suite)
+
+	cp := &StateCheckpoint{
+		ChainID:    coretypes.ChainID{1, 2, 3},
+		StateIndex: 42,
+		StateHash:  hashing.HashStrings("state"),
+		BlockHash:  hashing.HashStrings("block"),
+		Timestamp:  100,
+	}
+	require.NoError(t, cp.sign(suite, pair))
+	require.NoError(t, cp.VerifySignature(suite))
+
+	var buf bytes.Buffer
+	require.NoError(t, cp.Write(&buf))
+	back := new(StateCheckpoint)
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes()), suite))
+	require.NoError(t, back.VerifySignature(suite))
+	require.True(t, back.NodePubKey.Equal(pair.Public))
+	require.EqualValues(t, cp.ChainID, back.ChainID)
+	require.EqualValues(t, cp.StateIndex, back.StateIndex)
+	require.EqualValues(t, cp.StateHash, back.StateHash)
+	require.EqualValues(t, cp.BlockHash, back.BlockHash)
+	require.EqualValues(t, cp.Timestamp, back.Timestamp)
+
+	back.StateIndex = 43
+	require.Error(t, back.VerifySignature(suite))
+}
@@ -28,16 +28,44 @@ func (r *Impl) SaveDKShare(dkShare *tcrypto.DKShare) error {
 	if buf, err = dkShare.Bytes(); err != nil {
 		return err
 	}
-	return kvStore.Set(dbKey, buf)
+	return kvStore.Set(dbKey, encodeRecord(dkShareVersion, buf))
 
 }
 
+// ReplaceDKShare implements dkg.RegistryProvider.
+// It replaces the existing share of the same address, that's the case after the key is reshared.
+func (r *Impl) ReplaceDKShare(dkShare *tcrypto.DKShare) error {
+	var err error
+	var exists bool
+	dbKey := dbKeyForDKShare(dkShare.Address)
+	kvStore := database.GetRegistryPartition()
+	if exists, err = kvStore.Has(dbKey); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("attempt to replace non-existent DK key share")
+	}
+	var buf []byte
+	if buf, err = dkShare.Bytes(); err != nil {
+		return err
+	}
+	return kvStore.Set(dbKey, encodeRecord(dkShareVersion, buf))
+}
+
 // LoadDKShare implements dkg.RegistryProvider.
 func (r *Impl) LoadDKShare(sharedAddress *address.Address) (*tcrypto.DKShare, error) {
 	data, err := r.dbProvider.GetRegistryPartition().Get(dbKeyForDKShare(sharedAddress))
 	if err != nil {
 		return nil, err
 	}
+	return r.dkShareFromBytes(data)
+}
+
+func (r *Impl) dkShareFromBytes(value []byte) (*tcrypto.DKShare, error) {
+	data, err := decodeRecord(dkShareVersion, value)
+	if err != nil {
+		return nil, err
+	}
 	return tcrypto.DKShareFromBytes(data, r.suite)
 }
 
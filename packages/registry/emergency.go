@@ -0,0 +1,128 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/plugins/database"
+	"github.com/mr-tron/base58"
+)
+
+// SignedEmergencyVote is the vote of the committee peer for the emergency mode, signed with the key share of that peer
+type SignedEmergencyVote struct {
+	PeerIndex uint16
+	SigShare  []byte
+}
+
+// EmergencyRecord is the record of the emergency mode activated by the committee: from the state with the
+// index BlockIndex the next NumBlocks blocks are produced by the node Delegate.
+// Votes can be verified against the DK share of the chain
+type EmergencyRecord struct {
+	ChainID    coretypes.ChainID
+	BlockIndex uint32
+	Delegate   uint16
+	NumBlocks  uint32
+	Votes      []SignedEmergencyVote
+}
+
+func dbkeyEmergencyRecord(chainID *coretypes.ChainID, blockIndex uint32) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeEmergencyRecord, chainID[:], util.Uint32To4Bytes(blockIndex))
+}
+
+// SaveEmergencyRecord stores the record of the emergency mode activated in the state with the block index.
+// The record of the mode activated later in the same state replaces the previous one
+func SaveEmergencyRecord(rec *EmergencyRecord) error {
+	var buf bytes.Buffer
+	if err := rec.Write(&buf); err != nil {
+		return err
+	}
+	return database.GetRegistryPartition().Set(dbkeyEmergencyRecord(&rec.ChainID, rec.BlockIndex), buf.Bytes())
+}
+
+// GetEmergencyRecords returns all records of the emergency mode of the chain, ordered by the block index
+func GetEmergencyRecords(chainID *coretypes.ChainID) ([]*EmergencyRecord, error) {
+	db := database.GetRegistryPartition()
+	ret := make([]*EmergencyRecord, 0)
+
+	prefix := dbprovider.MakeKey(dbprovider.ObjectTypeEmergencyRecord, chainID[:])
+	err := db.Iterate(prefix, func(key kvstore.Key, value kvstore.Value) bool {
+		rec := new(EmergencyRecord)
+		if err := rec.Read(bytes.NewReader(value)); err == nil {
+			ret = append(ret, rec)
+		} else {
+			log.Warnf("corrupted emergency record with key %s", base58.Encode(key))
+		}
+		return true
+	})
+	return ret, err
+}
+
+func (rec *EmergencyRecord) Write(w io.Writer) error {
+	if err := rec.ChainID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, rec.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, rec.Delegate); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, rec.NumBlocks); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(rec.Votes))); err != nil {
+		return err
+	}
+	for i := range rec.Votes {
+		if err := util.WriteUint16(w, rec.Votes[i].PeerIndex); err != nil {
+			return err
+		}
+		if err := util.WriteBytes16(w, rec.Votes[i].SigShare); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rec *EmergencyRecord) Read(r io.Reader) error {
+	if err := rec.ChainID.Read(r); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &rec.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &rec.Delegate); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &rec.NumBlocks); err != nil {
+		return err
+	}
+	var n uint16
+	if err := util.ReadUint16(r, &n); err != nil {
+		return err
+	}
+	rec.Votes = make([]SignedEmergencyVote, n)
+	for i := range rec.Votes {
+		if err := util.ReadUint16(r, &rec.Votes[i].PeerIndex); err != nil {
+			return err
+		}
+		var err error
+		if rec.Votes[i].SigShare, err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rec *EmergencyRecord) String() string {
+	return fmt.Sprintf("emergency in block #%d of chain %s: blocks #%d..#%d delegated to #%d by %d votes",
+		rec.BlockIndex, rec.ChainID.String(), rec.BlockIndex+1, rec.BlockIndex+rec.NumBlocks, rec.Delegate, len(rec.Votes))
+}
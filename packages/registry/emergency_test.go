@@ -0,0 +1,29 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmergencyRecordSerialize(t *testing.T) {
+	rec := &EmergencyRecord{
+		ChainID:    coretypes.ChainID{1, 2, 3},
+		BlockIndex: 42,
+		Delegate:   1,
+		NumBlocks:  5,
+		Votes: []SignedEmergencyVote{
+			{PeerIndex: 0, SigShare: []byte("sig0")},
+			{PeerIndex: 1, SigShare: []byte("sig1")},
+			{PeerIndex: 3, SigShare: []byte("sig3")},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, rec.Write(&buf))
+
+	back := new(EmergencyRecord)
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, rec, back)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/plugins/database"
+	"github.com/mr-tron/base58"
+)
+
+// EquivocationEvidence is the evidence of the equivocating leader of the chain, found by the node.
+// Data is the serialized root.EquivocationEvidence, i.e. the conflicting proposals signed by the leader.
+// It is verifiable by anybody and may be submitted to the root contract of the chain, see root.FuncRecordEquivocation
+type EquivocationEvidence struct {
+	ChainID     coretypes.ChainID
+	BlockIndex  uint32
+	LeaderIndex uint16
+	Data        []byte
+}
+
+func dbkeyEquivocationEvidence(chainID *coretypes.ChainID, blockIndex uint32, leaderIndex uint16) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeEquivocationEvidence,
+		chainID[:], util.Uint32To4Bytes(blockIndex), util.Uint16To2Bytes(leaderIndex))
+}
+
+// SaveEquivocationEvidence stores the evidence. Only the first evidence for the same round and leader is kept
+func SaveEquivocationEvidence(ev *EquivocationEvidence) error {
+	key := dbkeyEquivocationEvidence(&ev.ChainID, ev.BlockIndex, ev.LeaderIndex)
+	db := database.GetRegistryPartition()
+	exists, err := db.Has(key)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err := ev.Write(&buf); err != nil {
+		return err
+	}
+	return db.Set(key, buf.Bytes())
+}
+
+// GetEquivocationEvidence returns all evidence of equivocating leaders, recorded by the node for the chain
+func GetEquivocationEvidence(chainID *coretypes.ChainID) ([]*EquivocationEvidence, error) {
+	db := database.GetRegistryPartition()
+	ret := make([]*EquivocationEvidence, 0)
+
+	prefix := dbprovider.MakeKey(dbprovider.ObjectTypeEquivocationEvidence, chainID[:])
+	err := db.Iterate(prefix, func(key kvstore.Key, value kvstore.Value) bool {
+		ev := new(EquivocationEvidence)
+		if err := ev.Read(bytes.NewReader(value)); err == nil {
+			ret = append(ret, ev)
+		} else {
+			log.Warnf("corrupted equivocation evidence record with key %s", base58.Encode(key))
+		}
+		return true
+	})
+	return ret, err
+}
+
+func (ev *EquivocationEvidence) Write(w io.Writer) error {
+	if err := ev.ChainID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, ev.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, ev.LeaderIndex); err != nil {
+		return err
+	}
+	return util.WriteBytes32(w, ev.Data)
+}
+
+func (ev *EquivocationEvidence) Read(r io.Reader) error {
+	if err := ev.ChainID.Read(r); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &ev.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &ev.LeaderIndex); err != nil {
+		return err
+	}
+	var err error
+	ev.Data, err = util.ReadBytes32(r)
+	return err
+}
+
+func (ev *EquivocationEvidence) String() string {
+	return fmt.Sprintf("equivocation of leader #%d in block #%d of chain %s", ev.LeaderIndex, ev.BlockIndex, ev.ChainID.String())
+}
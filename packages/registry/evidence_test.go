@@ -0,0 +1,24 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEquivocationEvidenceSerialize(t *testing.T) {
+	ev := &EquivocationEvidence{
+		ChainID:     coretypes.ChainID{1, 2, 3},
+		BlockIndex:  42,
+		LeaderIndex: 3,
+		Data:        []byte("evidence"),
+	}
+	var buf bytes.Buffer
+	require.NoError(t, ev.Write(&buf))
+
+	back := new(EquivocationEvidence)
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, ev, back)
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/iotaledger/wasp/plugins/database"
+)
+
+// SaveFrostShare implements tcrypto.RegistryProvider.
+func (r *Impl) SaveFrostShare(frostShare *tcrypto.FrostShare) error {
+	var err error
+	var exists bool
+	dbKey := dbKeyForFrostShare(frostShare.Address)
+	kvStore := database.GetRegistryPartition()
+	if exists, err = kvStore.Has(dbKey); err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("attempt to overwrite existing FROST key share")
+	}
+	var buf []byte
+	if buf, err = frostShare.Bytes(); err != nil {
+		return err
+	}
+	return kvStore.Set(dbKey, encodeRecord(frostShareVersion, buf))
+}
+
+// LoadFrostShare implements tcrypto.RegistryProvider.
+func (r *Impl) LoadFrostShare(addr *address.Address) (*tcrypto.FrostShare, error) {
+	value, err := r.dbProvider.GetRegistryPartition().Get(dbKeyForFrostShare(addr))
+	if err != nil {
+		return nil, err
+	}
+	data, err := decodeRecord(frostShareVersion, value)
+	if err != nil {
+		return nil, err
+	}
+	return tcrypto.FrostShareFromBytes(data)
+}
+
+func dbKeyForFrostShare(addr *address.Address) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeFrostShare, addr.Bytes())
+}
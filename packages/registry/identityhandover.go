@@ -0,0 +1,110 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/mr-tron/base58"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+func dbKeyForIdentityHandover(h *peering.IdentityHandover) ([]byte, error) {
+	oldPubKey, err := h.OldPubKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return dbprovider.MakeKey(dbprovider.ObjectTypeIdentityHandover, []byte(h.NetID), oldPubKey), nil
+}
+
+// RotateNodeIdentity generates the new identity key pair of the node and stores it in place of the current one,
+// together with the handover from the current key to the new one. If revoke is set, the current key is revoked,
+// i.e. peers won't accept it anymore for any node. The caller is responsible for passing the handover and
+// the new key pair to the network provider, see peering.IdentityRotator
+func (r *Impl) RotateNodeIdentity(netID string, revoke bool) (*peering.IdentityHandover, *key.Pair, error) {
+	oldPair, err := r.GetNodeIdentity()
+	if err != nil {
+		return nil, nil, err
+	}
+	newPair := key.NewKeyPair(r.suite)
+	handover, err := peering.NewIdentityHandover(netID, oldPair, newPair, revoke, r.suite)
+	if err != nil {
+		return nil, nil, err
+	}
+	handoverKey, handoverData, err := r.identityHandoverRecord(handover)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := keyPairToBytes(newPair)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the handover and the new key are written in one batch, so the handover never points to a key which was not stored
+	err = util.DbSetMulti(r.dbProvider.GetRegistryPartition(),
+		[][]byte{handoverKey, dbKeyForNodeIdentity()},
+		[][]byte{handoverData, data},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.log.Infof("Node identity key rotated, revoked=%v", revoke)
+	return handover, newPair, nil
+}
+
+// SaveIdentityHandover stores the handover, either of this node or received from peers.
+// The handover is stored once for the old key of the node: another handover of the same key is rejected
+func (r *Impl) SaveIdentityHandover(h *peering.IdentityHandover) error {
+	dbKey, data, err := r.identityHandoverRecord(h)
+	if err != nil || dbKey == nil {
+		return err
+	}
+	return r.dbProvider.GetRegistryPartition().Set(dbKey, data)
+}
+
+// identityHandoverRecord returns the key and the value of the handover in the registry.
+// The key is nil if the same handover is already stored
+func (r *Impl) identityHandoverRecord(h *peering.IdentityHandover) ([]byte, []byte, error) {
+	dbKey, err := dbKeyForIdentityHandover(h)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := h.Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	existing, err := r.dbProvider.GetRegistryPartition().Get(dbKey)
+	switch {
+	case err == kvstore.ErrKeyNotFound:
+		return dbKey, data, nil
+	case err != nil:
+		return nil, nil, err
+	case bytes.Equal(existing, data):
+		return nil, nil, nil
+	}
+	return nil, nil, fmt.Errorf("another identity handover of the key of %s is already stored", h.NetID)
+}
+
+// GetIdentityHandovers returns all stored handovers, ordered by the timestamp
+func (r *Impl) GetIdentityHandovers() ([]*peering.IdentityHandover, error) {
+	ret := make([]*peering.IdentityHandover, 0)
+	err := r.dbProvider.GetRegistryPartition().Iterate([]byte{dbprovider.ObjectTypeIdentityHandover}, func(key kvstore.Key, value kvstore.Value) bool {
+		if h, err := peering.IdentityHandoverFromBytes(value, r.suite); err == nil {
+			ret = append(ret, h)
+		} else {
+			r.log.Warnf("corrupted identity handover with key %s", base58.Encode(key))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Timestamp < ret[j].Timestamp })
+	return ret, nil
+}
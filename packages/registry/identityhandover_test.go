@@ -0,0 +1,43 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/util/key"
+)
+
+func TestRotateNodeIdentity(t *testing.T) {
+	log := testutil.NewLogger(t)
+	suite := pairing.NewSuiteBn256()
+	reg := NewRegistry(suite, log, dbprovider.NewInMemoryDBProvider(log))
+
+	oldPair, err := reg.GetNodeIdentity()
+	require.NoError(t, err)
+	handover, newPair, err := reg.RotateNodeIdentity("wasp0:4000", false)
+	require.NoError(t, err)
+	require.True(t, handover.OldPubKey.Equal(oldPair.Public))
+
+	// the new key and the handover to it are stored together
+	current, err := reg.GetNodeIdentity()
+	require.NoError(t, err)
+	require.True(t, current.Public.Equal(newPair.Public))
+	handovers, err := reg.GetIdentityHandovers()
+	require.NoError(t, err)
+	require.Len(t, handovers, 1)
+	require.True(t, handovers[0].NewPubKey.Equal(newPair.Public))
+
+	// the same handover received again is accepted, another handover of the old key is rejected
+	require.NoError(t, reg.SaveIdentityHandover(handover))
+	other, err := peering.NewIdentityHandover("wasp0:4000", oldPair, key.NewKeyPair(suite), false, suite)
+	require.NoError(t, err)
+	require.Error(t, reg.SaveIdentityHandover(other))
+
+	handovers, err = reg.GetIdentityHandovers()
+	require.NoError(t, err)
+	require.Len(t, handovers, 1)
+}
@@ -0,0 +1,219 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// The registry records (chain records with committee nodes and DK shares) are stored with the version
+// of their format in the first byte. The version of the registry as a whole is stored under its own key.
+// At startup of the node the registry is migrated from the stored version to the RegistryVersion by
+// running all migrations in between. To change the format of a record:
+//   - increase the version of the record
+//   - append the migration which rewrites records of the previous version
+//   - increase RegistryVersion
+
+const (
+	// RegistryVersion is the version of the registry supported by this version of Wasp.
+	// It must be equal to the number of migrations
+	RegistryVersion = 2
+
+	chainRecordVersion byte = 2
+	dkShareVersion     byte = 1
+	frostShareVersion  byte = 1
+)
+
+// Migration converts records of the registry from the version to the next one.
+// Changes are written into the batch, which is committed together with the new version of the registry
+type Migration struct {
+	Description string
+	Migrate     func(db kvstore.KVStore, batch kvstore.BatchedMutations) error
+}
+
+// migrations[i] migrates the registry from the version i to the version i+1
+var migrations = []Migration{
+	{
+		Description: "add the format version to chain records and DK shares",
+		Migrate:     migrateUnversionedRecords,
+	},
+	{
+		Description: "add access nodes to chain records",
+		Migrate:     migrateChainRecordsAccessNodes,
+	},
+}
+
+func init() {
+	if len(migrations) != RegistryVersion {
+		panic("registry: number of migrations must be equal to RegistryVersion")
+	}
+}
+
+func dbkeyRegistryVersion() []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeRegistryVersion)
+}
+
+// GetRegistryVersion returns the version of the registry stored in the database.
+// The registry of the node which never stored the version is of version 0, the empty registry
+// is of the current version
+func (r *Impl) GetRegistryVersion() (uint32, error) {
+	db := r.dbProvider.GetRegistryPartition()
+	data, err := db.Get(dbkeyRegistryVersion())
+	if err == kvstore.ErrKeyNotFound {
+		empty, err := isRegistryEmpty(db)
+		if err != nil {
+			return 0, err
+		}
+		if empty {
+			return RegistryVersion, nil
+		}
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return util.Uint32From4Bytes(data)
+}
+
+// MigrateRegistry migrates records of the registry to the current format. Each migration is committed
+// atomically together with the new version, so the interrupted migration continues at the next startup.
+// Returns the version of the registry before the migration
+func (r *Impl) MigrateRegistry() (uint32, error) {
+	db := r.dbProvider.GetRegistryPartition()
+	version, err := r.GetRegistryVersion()
+	if err != nil {
+		return 0, err
+	}
+	if version > RegistryVersion {
+		return version, fmt.Errorf("registry version %d is not supported by this version of Wasp. Supported version: %d",
+			version, RegistryVersion)
+	}
+	for v := version; v < RegistryVersion; v++ {
+		r.log.Infof("migrating registry from version %d to %d: %s", v, v+1, migrations[v].Description)
+		batch := db.Batched()
+		if err := migrations[v].Migrate(db, batch); err != nil {
+			batch.Cancel()
+			return version, fmt.Errorf("migration of registry from version %d failed: %v", v, err)
+		}
+		if err := batch.Set(dbkeyRegistryVersion(), util.Uint32To4Bytes(v+1)); err != nil {
+			batch.Cancel()
+			return version, err
+		}
+		if err := batch.Commit(); err != nil {
+			return version, err
+		}
+	}
+	if version == RegistryVersion {
+		// stores the version of the new registry
+		return version, db.Set(dbkeyRegistryVersion(), util.Uint32To4Bytes(RegistryVersion))
+	}
+	r.log.Infof("registry migrated from version %d to %d", version, RegistryVersion)
+	return version, nil
+}
+
+func isRegistryEmpty(db kvstore.KVStore) (bool, error) {
+	empty := true
+	for _, objType := range []byte{dbprovider.ObjectTypeChainRecord, dbprovider.ObjectTypeDistributedKeyData, dbprovider.ObjectTypeFrostShare} {
+		err := db.Iterate([]byte{objType}, func(_ kvstore.Key, _ kvstore.Value) bool {
+			empty = false
+			return false
+		})
+		if err != nil || !empty {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// encodeRecord prepends the version of the format to the serialized record
+func encodeRecord(version byte, data []byte) []byte {
+	ret := make([]byte, 0, len(data)+1)
+	ret = append(ret, version)
+	return append(ret, data...)
+}
+
+// decodeRecord checks the version of the stored record and returns the serialized record
+func decodeRecord(version byte, value []byte) ([]byte, error) {
+	if len(value) == 0 {
+		return nil, fmt.Errorf("empty registry record")
+	}
+	if value[0] != version {
+		return nil, fmt.Errorf("registry record of version %d is not supported, expected version %d. Registry must be migrated",
+			value[0], version)
+	}
+	return value[1:], nil
+}
+
+// migrateUnversionedRecords migrates from the version 0: chain records and DK shares were stored without version.
+// Records become of the version 1, the following migrations take them further
+func migrateUnversionedRecords(db kvstore.KVStore, batch kvstore.BatchedMutations) error {
+	for objType, version := range map[byte]byte{
+		dbprovider.ObjectTypeChainRecord:        1,
+		dbprovider.ObjectTypeDistributedKeyData: 1,
+	} {
+		var innerErr error
+		err := db.Iterate([]byte{objType}, func(key kvstore.Key, value kvstore.Value) bool {
+			innerErr = batch.Set(key, encodeRecord(version, value))
+			return innerErr == nil
+		})
+		if err != nil {
+			return err
+		}
+		if innerErr != nil {
+			return innerErr
+		}
+	}
+	return nil
+}
+
+// migrateChainRecordsAccessNodes migrates from the version 1: chain records were stored without access nodes
+func migrateChainRecordsAccessNodes(db kvstore.KVStore, batch kvstore.BatchedMutations) error {
+	var innerErr error
+	err := db.Iterate([]byte{dbprovider.ObjectTypeChainRecord}, func(key kvstore.Key, value kvstore.Value) bool {
+		var data []byte
+		if data, innerErr = decodeRecord(1, value); innerErr != nil {
+			return false
+		}
+		var rec *ChainRecord
+		if rec, innerErr = readChainRecordV1(data); innerErr != nil {
+			return false
+		}
+		var buf bytes.Buffer
+		if innerErr = rec.Write(&buf); innerErr != nil {
+			return false
+		}
+		innerErr = batch.Set(key, encodeRecord(chainRecordVersion, buf.Bytes()))
+		return innerErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return innerErr
+}
+
+// readChainRecordV1 reads the chain record of the version 1, without access nodes
+func readChainRecordV1(data []byte) (*ChainRecord, error) {
+	r := bytes.NewReader(data)
+	ret := new(ChainRecord)
+	var err error
+	if err = ret.ChainID.Read(r); err != nil {
+		return nil, err
+	}
+	if err = util.ReadColor(r, &ret.Color); err != nil {
+		return nil, err
+	}
+	if ret.CommitteeNodes, err = util.ReadStrings16(r); err != nil {
+		return nil, err
+	}
+	if err = util.ReadBoolByte(r, &ret.Active); err != nil {
+		return nil, err
+	}
+	ret.AccessNodes = []string{}
+	return ret, nil
+}
@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateEmptyRegistry(t *testing.T) {
+	log := testutil.NewLogger(t)
+	reg := NewRegistry(nil, log, dbprovider.NewInMemoryDBProvider(log))
+
+	from, err := reg.MigrateRegistry()
+	require.NoError(t, err)
+	require.EqualValues(t, RegistryVersion, from)
+	v, err := reg.GetRegistryVersion()
+	require.NoError(t, err)
+	require.EqualValues(t, RegistryVersion, v)
+}
+
+func TestMigrateUnversionedRecords(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+
+	// the chain record stored by the node before versioning
+	rec := &ChainRecord{
+		ChainID:        coretypes.ChainID{1, 2, 3},
+		Color:          balance.Color{4, 5, 6},
+		CommitteeNodes: []string{"127.0.0.1:4000", "127.0.0.1:4001"},
+		AccessNodes:    []string{},
+		Active:         true,
+	}
+	key := dbkeyChainRecord(&rec.ChainID)
+	require.NoError(t, db.GetRegistryPartition().Set(key, chainRecordV1Bytes(t, rec)))
+
+	v, err := reg.GetRegistryVersion()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, v)
+
+	from, err := reg.MigrateRegistry()
+	require.NoError(t, err)
+	require.EqualValues(t, 0, from)
+	v, err = reg.GetRegistryVersion()
+	require.NoError(t, err)
+	require.EqualValues(t, RegistryVersion, v)
+
+	data, err := db.GetRegistryPartition().Get(key)
+	require.NoError(t, err)
+	back, err := chainRecordFromBytes(data)
+	require.NoError(t, err)
+	require.EqualValues(t, rec, back)
+
+	// the migrated registry is not migrated again
+	from, err = reg.MigrateRegistry()
+	require.NoError(t, err)
+	require.EqualValues(t, RegistryVersion, from)
+	data2, err := db.GetRegistryPartition().Get(key)
+	require.NoError(t, err)
+	require.EqualValues(t, data, data2)
+}
+
+func TestMigrateChainRecordsAccessNodes(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+
+	// the chain record of the version 1, without access nodes
+	rec := &ChainRecord{
+		ChainID:        coretypes.ChainID{1, 2, 3},
+		Color:          balance.Color{4, 5, 6},
+		CommitteeNodes: []string{"127.0.0.1:4000", "127.0.0.1:4001"},
+		AccessNodes:    []string{},
+	}
+	key := dbkeyChainRecord(&rec.ChainID)
+	require.NoError(t, db.GetRegistryPartition().Set(key, encodeRecord(1, chainRecordV1Bytes(t, rec))))
+	require.NoError(t, db.GetRegistryPartition().Set(dbkeyRegistryVersion(), []byte{1, 0, 0, 0}))
+
+	_, err := chainRecordFromBytes(encodeRecord(1, chainRecordV1Bytes(t, rec)))
+	require.Error(t, err)
+
+	from, err := reg.MigrateRegistry()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, from)
+
+	data, err := db.GetRegistryPartition().Get(key)
+	require.NoError(t, err)
+	back, err := chainRecordFromBytes(data)
+	require.NoError(t, err)
+	require.EqualValues(t, rec, back)
+
+	// access nodes are stored
+	rec.AccessNodes = []string{"127.0.0.1:4002"}
+	var buf bytes.Buffer
+	require.NoError(t, rec.Write(&buf))
+	back, err = chainRecordFromBytes(encodeRecord(chainRecordVersion, buf.Bytes()))
+	require.NoError(t, err)
+	require.EqualValues(t, rec, back)
+}
+
+// chainRecordV1Bytes serializes the chain record in the format of the version 1, without access nodes
+func chainRecordV1Bytes(t *testing.T, rec *ChainRecord) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, rec.ChainID.Write(&buf))
+	_, err := buf.Write(rec.Color[:])
+	require.NoError(t, err)
+	require.NoError(t, util.WriteStrings16(&buf, rec.CommitteeNodes))
+	require.NoError(t, util.WriteBoolByte(&buf, rec.Active))
+	return buf.Bytes()
+}
+
+func TestRegistryFromNewerVersion(t *testing.T) {
+	log := testutil.NewLogger(t)
+	db := dbprovider.NewInMemoryDBProvider(log)
+	reg := NewRegistry(nil, log, db)
+
+	require.NoError(t, db.GetRegistryPartition().Set(dbkeyRegistryVersion(), []byte{RegistryVersion + 1, 0, 0, 0}))
+	_, err := reg.MigrateRegistry()
+	require.Error(t, err)
+
+	_, err = chainRecordFromBytes([]byte{chainRecordVersion + 1, 1, 2, 3})
+	require.Error(t, err)
+}
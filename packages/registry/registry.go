@@ -4,6 +4,8 @@
 package registry
 
 import (
+	"sync"
+
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/dbprovider"
 	"github.com/iotaledger/wasp/packages/tcrypto"
@@ -16,13 +18,17 @@ type Impl struct {
 	suite      tcrypto.Suite
 	log        *logger.Logger
 	dbProvider *dbprovider.DBProvider
+	// blobMutex serializes updates of blobs, their references and TTLs
+	blobMutex sync.Mutex
+	blobQuota int64
 }
 
 // New creates new instance of the registry implementation.
 func NewRegistry(suite tcrypto.Suite, log *logger.Logger, dbp ...*dbprovider.DBProvider) *Impl {
 	ret := &Impl{
-		suite: suite,
-		log:   log.Named("registry"),
+		suite:     suite,
+		log:       log.Named("registry"),
+		blobQuota: DefaultBlobQuota,
 	}
 	if len(dbp) == 0 {
 		ret.dbProvider = database.GetInstance()
@@ -1,6 +1,8 @@
 package registry
 
 import (
+	"time"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/parameters"
 	flag "github.com/spf13/pflag"
@@ -9,10 +11,16 @@ import (
 const (
 	// CfgBindAddress defines the config flag of the web API binding address.
 	CfgRewardAddress = "reward.address"
+	// CfgBlobQuotaMB defines the config flag of the maximum total size of blobs referenced by one chain
+	CfgBlobQuotaMB = "blobs.chainQuotaMB"
+	// CfgCheckpointPeriodSeconds defines the config flag of the period of recording state checkpoints
+	CfgCheckpointPeriodSeconds = "state.checkpointPeriodSeconds"
 )
 
 func InitFlags() {
 	flag.String(CfgRewardAddress, "", "reward address for this Wasp node. Empty (default) means no rewards are collected")
+	flag.Int(CfgBlobQuotaMB, DefaultBlobQuota/(1024*1024), "maximum total size of blobs referenced by one chain, in megabytes. 0 means unlimited")
+	flag.Int(CfgCheckpointPeriodSeconds, int(DefaultCheckpointPeriod/time.Second), "period of recording checkpoints of chain states, in seconds. 0 means disabled")
 }
 
 func GetFeeDestination(scaddr *address.Address) address.Address {
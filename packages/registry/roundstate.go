@@ -0,0 +1,200 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/plugins/database"
+)
+
+// RoundSigShare is the signature share of the result transaction, collected by the leader from the committee peer
+type RoundSigShare struct {
+	PeerIndex   uint16
+	EssenceHash hashing.HashValue
+	SigShare    []byte
+}
+
+// RoundState is the in-progress consensus round of the node, persisted so that the node which crashed
+// between the exchange of signature shares and the finalization can resume the round after restart.
+// The leader keeps signature shares collected so far, the subordinate keeps its own share sent to the leader.
+// The round is valid only in the state anchored by StateTxID
+type RoundState struct {
+	ChainID     coretypes.ChainID
+	BlockIndex  uint32
+	StateTxID   valuetransaction.ID
+	LeaderIndex uint16
+	BatchHash   hashing.HashValue
+	Timestamp   int64
+	RequestIDs  []coretypes.RequestID
+	// serialized result transaction and block of the batch, calculated by the node
+	ResultTx  []byte
+	Block     []byte
+	SigShares []RoundSigShare
+}
+
+func dbkeyRoundState(chainID *coretypes.ChainID) []byte {
+	return dbprovider.MakeKey(dbprovider.ObjectTypeConsensusRound, chainID[:])
+}
+
+// SaveRoundState stores the round state. Only the latest round of the chain is kept
+func SaveRoundState(rs *RoundState) error {
+	var buf bytes.Buffer
+	if err := rs.Write(&buf); err != nil {
+		return err
+	}
+	return database.GetRegistryPartition().Set(dbkeyRoundState(&rs.ChainID), buf.Bytes())
+}
+
+// GetRoundState returns the persisted round of the chain or nil if there's none
+func GetRoundState(chainID *coretypes.ChainID) (*RoundState, error) {
+	data, err := database.GetRegistryPartition().Get(dbkeyRoundState(chainID))
+	if err == kvstore.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ret := new(RoundState)
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// DeleteRoundState deletes the persisted round of the chain, if any
+func DeleteRoundState(chainID *coretypes.ChainID) error {
+	err := database.GetRegistryPartition().Delete(dbkeyRoundState(chainID))
+	if err == kvstore.ErrKeyNotFound {
+		return nil
+	}
+	return err
+}
+
+func (rs *RoundState) Write(w io.Writer) error {
+	if err := rs.ChainID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, rs.BlockIndex); err != nil {
+		return err
+	}
+	if _, err := w.Write(rs.StateTxID[:]); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, rs.LeaderIndex); err != nil {
+		return err
+	}
+	if _, err := w.Write(rs.BatchHash[:]); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, rs.Timestamp); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(rs.RequestIDs))); err != nil {
+		return err
+	}
+	for i := range rs.RequestIDs {
+		if err := rs.RequestIDs[i].Write(w); err != nil {
+			return err
+		}
+	}
+	if err := util.WriteBytes32(w, rs.ResultTx); err != nil {
+		return err
+	}
+	if err := util.WriteBytes32(w, rs.Block); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(rs.SigShares))); err != nil {
+		return err
+	}
+	for i := range rs.SigShares {
+		if err := rs.SigShares[i].Write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rs *RoundState) Read(r io.Reader) error {
+	if err := rs.ChainID.Read(r); err != nil {
+		return err
+	}
+	if err := util.ReadUint32(r, &rs.BlockIndex); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(r, rs.StateTxID[:]); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &rs.LeaderIndex); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &rs.BatchHash); err != nil {
+		return err
+	}
+	if err := util.ReadInt64(r, &rs.Timestamp); err != nil {
+		return err
+	}
+	var n uint16
+	if err := util.ReadUint16(r, &n); err != nil {
+		return err
+	}
+	rs.RequestIDs = make([]coretypes.RequestID, n)
+	for i := range rs.RequestIDs {
+		if err := rs.RequestIDs[i].Read(r); err != nil {
+			return err
+		}
+	}
+	var err error
+	if rs.ResultTx, err = util.ReadBytes32(r); err != nil {
+		return err
+	}
+	if rs.Block, err = util.ReadBytes32(r); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &n); err != nil {
+		return err
+	}
+	rs.SigShares = make([]RoundSigShare, n)
+	for i := range rs.SigShares {
+		if err := rs.SigShares[i].Read(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rs *RoundState) String() string {
+	return fmt.Sprintf("round of leader #%d in block #%d of chain %s: batch %s, %d request(s), %d signature share(s)",
+		rs.LeaderIndex, rs.BlockIndex, rs.ChainID.String(), rs.BatchHash.String(), len(rs.RequestIDs), len(rs.SigShares))
+}
+
+func (s *RoundSigShare) Write(w io.Writer) error {
+	if err := util.WriteUint16(w, s.PeerIndex); err != nil {
+		return err
+	}
+	if _, err := w.Write(s.EssenceHash[:]); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, s.SigShare)
+}
+
+func (s *RoundSigShare) Read(r io.Reader) error {
+	if err := util.ReadUint16(r, &s.PeerIndex); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &s.EssenceHash); err != nil {
+		return err
+	}
+	var err error
+	s.SigShare, err = util.ReadBytes16(r)
+	return err
+}
@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"bytes"
+	"testing"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundStateSerialize(t *testing.T) {
+	txid := valuetransaction.ID(hashing.HashStrings("tx"))
+	rs := &RoundState{
+		ChainID:     coretypes.ChainID{1, 2, 3},
+		BlockIndex:  42,
+		StateTxID:   txid,
+		LeaderIndex: 2,
+		BatchHash:   hashing.HashStrings("batch"),
+		Timestamp:   100,
+		RequestIDs:  []coretypes.RequestID{coretypes.NewRequestID(txid, 0), coretypes.NewRequestID(txid, 1)},
+		ResultTx:    []byte("result tx"),
+		Block:       []byte("block"),
+		SigShares: []RoundSigShare{
+			{PeerIndex: 2, EssenceHash: hashing.HashStrings("essence"), SigShare: []byte("sig2")},
+			{PeerIndex: 0, EssenceHash: hashing.HashStrings("essence"), SigShare: []byte("sig0")},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, rs.Write(&buf))
+
+	back := new(RoundState)
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, rs, back)
+}
+
+func TestRoundStateSerializeEmpty(t *testing.T) {
+	rs := &RoundState{
+		ChainID:    coretypes.ChainID{1, 2, 3},
+		RequestIDs: []coretypes.RequestID{},
+		ResultTx:   []byte{},
+		Block:      []byte{},
+		SigShares:  []RoundSigShare{},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, rs.Write(&buf))
+
+	back := new(RoundState)
+	require.NoError(t, back.Read(bytes.NewReader(buf.Bytes())))
+	require.EqualValues(t, rs, back)
+}
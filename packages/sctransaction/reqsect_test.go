@@ -3,6 +3,7 @@ package sctransaction
 import (
 	"bytes"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -20,3 +21,24 @@ func TestWriteRead(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, buf1.Bytes(), buf.Bytes())
 }
+
+func TestPredecessor(t *testing.T) {
+	cid := coretypes.NewContractID(coretypes.ChainID{}, root.Interface.Hname())
+	rsec := NewRequestSectionByWallet(cid, coretypes.EntryPointInit)
+	_, ok := rsec.Predecessor()
+	require.False(t, ok)
+
+	var pred coretypes.RequestID
+	copy(pred[:], "some transaction id")
+	rsec.WithArgs(requestargs.New(nil).AddEncodeSimple(ArgPredecessor, pred[:]))
+	// not solid yet
+	_, ok = rsec.Predecessor()
+	require.False(t, ok)
+
+	ok, err := rsec.SolidifyArgs(nil)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ret, ok := rsec.Predecessor()
+	require.True(t, ok)
+	require.EqualValues(t, pred, *ret)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/util"
 )
@@ -43,6 +44,11 @@ type RequestSection struct {
 	transfer coretypes.ColoredBalances
 }
 
+// ArgPredecessor is the reserved argument of the request with the ID of the request it must be processed after.
+// The committee doesn't select the request for the batch until the predecessor is processed, so requests
+// chained this way are processed in FIFO order, one per batch. See chainclient.Sequencer
+const ArgPredecessor = kv.Key("$$predecessor$$")
+
 type RequestRef struct {
 	Tx    *Transaction
 	Index uint16
@@ -123,6 +129,23 @@ func (req *RequestSection) Timelock() uint32 {
 	return req.timelock
 }
 
+// Predecessor returns the ID of the request which must be processed before this one, if the request
+// has the ArgPredecessor argument. Valid only when arguments are solid
+func (req *RequestSection) Predecessor() (*coretypes.RequestID, bool) {
+	if req.solidArgs == nil {
+		return nil, false
+	}
+	data := req.solidArgs.MustGet(ArgPredecessor)
+	if data == nil {
+		return nil, false
+	}
+	ret, err := coretypes.NewRequestIDFromBytes(data)
+	if err != nil {
+		return nil, false
+	}
+	return &ret, true
+}
+
 func (req *RequestSection) Transfer() coretypes.ColoredBalances {
 	return req.transfer
 }
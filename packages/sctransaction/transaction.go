@@ -108,6 +108,20 @@ func (tx *Transaction) Requests() []*RequestSection {
 	return tx.requestSection
 }
 
+// RequestsByChain groups references to the requests of the transaction by the target chain.
+// The order of requests within each group is the order of request sections in the transaction
+func (tx *Transaction) RequestsByChain() map[coretypes.ChainID][]RequestRef {
+	ret := make(map[coretypes.ChainID][]RequestRef)
+	for i, req := range tx.requestSection {
+		chid := req.Target().ChainID()
+		ret[chid] = append(ret[chid], RequestRef{
+			Tx:    tx,
+			Index: uint16(i),
+		})
+	}
+	return ret
+}
+
 // Sender returns first input address. It is the unique address, because
 // ParseValueTransaction doesn't allow other options
 func (tx *Transaction) Sender() *address.Address {
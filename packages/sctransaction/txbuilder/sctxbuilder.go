@@ -100,6 +100,20 @@ func (txb *Builder) AddRequestSection(req *sctransaction.RequestSection) error {
 	return nil
 }
 
+// AddRequestSections adds several request blocks, possibly targeting different chains, to the
+// transaction. Either all request blocks are added or, in case of error, the builder is left unchanged.
+// All requests will be settled atomically by the ledger in one value transaction
+func (txb *Builder) AddRequestSections(reqs ...*sctransaction.RequestSection) error {
+	tmp := txb.Clone()
+	for _, req := range reqs {
+		if err := tmp.AddRequestSection(req); err != nil {
+			return err
+		}
+	}
+	*txb = *tmp
+	return nil
+}
+
 // AddMinting adds amounts to be minted from iotas to respective addresses
 func (txb *Builder) AddMinting(mint map[address.Address]int64) {
 	for addr, amount := range mint {
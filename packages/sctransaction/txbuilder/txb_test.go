@@ -124,6 +124,51 @@ func TestRequestSimple(t *testing.T) {
 	})
 }
 
+func TestRequestsMultiChain(t *testing.T) {
+	u := utxodb.New()
+	chain1 := coretypes.ChainID(signaturescheme.RandBLS().Address())
+	chain2 := coretypes.ChainID(signaturescheme.RandBLS().Address())
+	wallet := signaturescheme.ED25519(ed25519.GenerateKeyPair())
+	_, err := u.RequestFunds(wallet.Address())
+	assert.NoError(t, err)
+
+	outs := u.GetAddressOutputs(wallet.Address())
+	txb, err := NewFromOutputBalances(outs)
+	assert.NoError(t, err)
+
+	err = txb.AddRequestSections(
+		sctransaction.NewRequestSection(0, coretypes.NewContractID(chain1, 0), 1),
+		sctransaction.NewRequestSection(0, coretypes.NewContractID(chain2, 0), 1),
+		sctransaction.NewRequestSection(0, coretypes.NewContractID(chain1, 0), 2),
+	)
+	assert.NoError(t, err)
+
+	tx, err := txb.Build(false)
+	assert.NoError(t, err)
+
+	tx.Sign(wallet)
+	assert.True(t, tx.SignaturesValid())
+
+	err = u.AddTransaction(tx.Transaction)
+	assert.NoError(t, err)
+
+	byChain := tx.RequestsByChain()
+	require.Len(t, byChain, 2)
+	require.Len(t, byChain[chain1], 2)
+	require.Len(t, byChain[chain2], 1)
+	require.EqualValues(t, 0, byChain[chain1][0].Index)
+	require.EqualValues(t, 2, byChain[chain1][1].Index)
+	require.EqualValues(t, 1, byChain[chain2][0].Index)
+
+	outs = u.GetAddressOutputs((address.Address)(chain1))
+	bals, _ := waspconn.OutputBalancesByColor(outs)
+	require.EqualValues(t, 2, bals[balance.Color(tx.ID())])
+
+	outs = u.GetAddressOutputs((address.Address)(chain2))
+	bals, _ = waspconn.OutputBalancesByColor(outs)
+	require.EqualValues(t, 1, bals[balance.Color(tx.ID())])
+}
+
 func TestMintOk(t *testing.T) {
 	u := utxodb.New()
 	chainSigScheme := signaturescheme.RandBLS()
@@ -0,0 +1,204 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/stretchr/testify/require"
+)
+
+// AccountsSnapshot is the balance sheet of the chain at some block: balances of all on-chain accounts,
+// total assets of the chain and balances of involved addresses in UTXODB
+type AccountsSnapshot struct {
+	ChainID      coretypes.ChainID
+	ChainOwnerID coretypes.AgentID
+	BlockIndex   uint32
+	Accounts     map[coretypes.AgentID]coretypes.ColoredBalances
+	TotalAssets  coretypes.ColoredBalances
+	// Addresses are balances in UTXODB of the chain address, of the chain originator and
+	// of all addresses which own on-chain accounts, plus addresses requested in DumpAccounts
+	Addresses map[address.Address]coretypes.ColoredBalances
+}
+
+// AccountsDiff is the difference between two snapshots of the balance sheet.
+// Only non-zero deltas are kept, so the diff of equal snapshots is empty
+type AccountsDiff struct {
+	Accounts    map[coretypes.AgentID]coretypes.ColoredBalances
+	TotalAssets coretypes.ColoredBalances
+	Addresses   map[address.Address]coretypes.ColoredBalances
+}
+
+// DumpAccounts takes the snapshot of balances of all on-chain accounts and L1 balances of involved addresses.
+// Balances of additional addresses are included if they are listed in addrs.
+// The snapshot is printed in the human readable form with %s
+func (ch *Chain) DumpAccounts(addrs ...address.Address) *AccountsSnapshot {
+	info, _ := ch.GetInfo()
+	ret := &AccountsSnapshot{
+		ChainID:      ch.ChainID,
+		ChainOwnerID: info.ChainOwnerID,
+		BlockIndex:   ch.State.BlockIndex(),
+		Accounts:     make(map[coretypes.AgentID]coretypes.ColoredBalances),
+		TotalAssets:  ch.GetTotalAssets(),
+		Addresses:    make(map[address.Address]coretypes.ColoredBalances),
+	}
+	involved := append([]address.Address{ch.ChainAddress, ch.OriginatorAddress}, addrs...)
+	for _, agentID := range ch.GetAccounts() {
+		ret.Accounts[agentID] = ch.GetAccountBalance(agentID)
+		if agentID.IsAddress() {
+			involved = append(involved, agentID.MustAddress())
+		}
+	}
+	for _, addr := range involved {
+		ret.Addresses[addr] = cbalances.NewFromMap(ch.Env.GetAddressBalances(addr))
+	}
+	return ret
+}
+
+// DiffAccounts returns deltas of balances between two snapshots, i.e. after - before
+func DiffAccounts(before, after *AccountsSnapshot) *AccountsDiff {
+	ret := &AccountsDiff{
+		Accounts:    make(map[coretypes.AgentID]coretypes.ColoredBalances),
+		TotalAssets: balanceOf(after.TotalAssets).Diff(balanceOf(before.TotalAssets)),
+		Addresses:   make(map[address.Address]coretypes.ColoredBalances),
+	}
+	for agentID := range unionAgentIDs(before.Accounts, after.Accounts) {
+		if d := balanceOf(after.Accounts[agentID]).Diff(balanceOf(before.Accounts[agentID])); d.Len() > 0 {
+			ret.Accounts[agentID] = d
+		}
+	}
+	for addr := range unionAddresses(before.Addresses, after.Addresses) {
+		if d := balanceOf(after.Addresses[addr]).Diff(balanceOf(before.Addresses[addr])); d.Len() > 0 {
+			ret.Addresses[addr] = d
+		}
+	}
+	return ret
+}
+
+// IsEmpty returns true if no balance has changed
+func (d *AccountsDiff) IsEmpty() bool {
+	return len(d.Accounts) == 0 && len(d.Addresses) == 0 && d.TotalAssets.Len() == 0
+}
+
+// Account returns the delta of the on-chain account balance of the color
+func (d *AccountsDiff) Account(agentID coretypes.AgentID, col balance.Color) int64 {
+	return balanceOf(d.Accounts[agentID]).Balance(col)
+}
+
+// Address returns the delta of the address balance of the color in UTXODB
+func (d *AccountsDiff) Address(addr address.Address, col balance.Color) int64 {
+	return balanceOf(d.Addresses[addr]).Balance(col)
+}
+
+// AssertAccountDelta asserts the delta of the on-chain account balance of the color.
+// On failure the whole diff is printed
+func (ch *Chain) AssertAccountDelta(diff *AccountsDiff, agentID coretypes.AgentID, col balance.Color, delta int64) {
+	require.EqualValues(ch.Env.T, delta, diff.Account(agentID, col),
+		"unexpected delta of account %s, color %s\n%s", agentID, col, diff)
+}
+
+// AssertAddressDelta asserts the delta of the address balance of the color in UTXODB.
+// On failure the whole diff is printed
+func (env *Solo) AssertAddressDelta(diff *AccountsDiff, addr address.Address, col balance.Color, delta int64) {
+	require.EqualValues(env.T, delta, diff.Address(addr, col),
+		"unexpected delta of address %s, color %s\n%s", addr, col, diff)
+}
+
+func (s *AccountsSnapshot) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "ChainID: %s\nChain owner: %s\nBlock index: %d\n", s.ChainID, s.ChainOwnerID, s.BlockIndex)
+	fmt.Fprintf(&buf, "Accounts:\n")
+	writeAccounts(&buf, s.Accounts)
+	fmt.Fprintf(&buf, "Total assets:\n")
+	writeBalances(&buf, s.TotalAssets)
+	fmt.Fprintf(&buf, "Addresses:\n")
+	writeAddresses(&buf, s.Addresses)
+	return buf.String()
+}
+
+func (d *AccountsDiff) String() string {
+	if d.IsEmpty() {
+		return "no changes\n"
+	}
+	var buf bytes.Buffer
+	if len(d.Accounts) > 0 {
+		fmt.Fprintf(&buf, "Accounts:\n")
+		writeAccounts(&buf, d.Accounts)
+	}
+	if d.TotalAssets.Len() > 0 {
+		fmt.Fprintf(&buf, "Total assets:\n")
+		writeBalances(&buf, d.TotalAssets)
+	}
+	if len(d.Addresses) > 0 {
+		fmt.Fprintf(&buf, "Addresses:\n")
+		writeAddresses(&buf, d.Addresses)
+	}
+	return buf.String()
+}
+
+func writeAccounts(buf *bytes.Buffer, accounts map[coretypes.AgentID]coretypes.ColoredBalances) {
+	keys := make([]coretypes.AgentID, 0, len(accounts))
+	for agentID := range accounts {
+		keys = append(keys, agentID)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, agentID := range keys {
+		fmt.Fprintf(buf, "  %s:\n", agentID)
+		writeBalances(buf, accounts[agentID])
+	}
+}
+
+func writeAddresses(buf *bytes.Buffer, addrs map[address.Address]coretypes.ColoredBalances) {
+	keys := make([]address.Address, 0, len(addrs))
+	for addr := range addrs {
+		keys = append(keys, addr)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	for _, addr := range keys {
+		fmt.Fprintf(buf, "  %s:\n", addr)
+		writeBalances(buf, addrs[addr])
+	}
+}
+
+func writeBalances(buf *bytes.Buffer, bals coretypes.ColoredBalances) {
+	balanceOf(bals).IterateDeterministic(func(col balance.Color, bal int64) bool {
+		fmt.Fprintf(buf, "       %s: %d\n", col, bal)
+		return true
+	})
+}
+
+func balanceOf(bals coretypes.ColoredBalances) coretypes.ColoredBalances {
+	if bals == nil {
+		return cbalances.Nil
+	}
+	return bals
+}
+
+func unionAgentIDs(a, b map[coretypes.AgentID]coretypes.ColoredBalances) map[coretypes.AgentID]bool {
+	ret := make(map[coretypes.AgentID]bool)
+	for k := range a {
+		ret[k] = true
+	}
+	for k := range b {
+		ret[k] = true
+	}
+	return ret
+}
+
+func unionAddresses(a, b map[address.Address]coretypes.ColoredBalances) map[address.Address]bool {
+	ret := make(map[address.Address]bool)
+	for k := range a {
+		ret[k] = true
+	}
+	for k := range b {
+		ret[k] = true
+	}
+	return ret
+}
@@ -0,0 +1,24 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/registry"
+)
+
+// WithPersistentBlobCache makes the environment keep blobs in the persistent database in the directory
+// 'dbDir', the same content-addressed blob store the Wasp node uses. Blobs survive the test and
+// may be shared by several test runs. Without the option blobs are kept in memory
+func WithPersistentBlobCache(dbDir string) Option {
+	return func(env *Solo) {
+		env.blobCacheDir = dbDir
+	}
+}
+
+func newPersistentBlobCache(dbDir string, log *logger.Logger) coretypes.BlobCacheFull {
+	return registry.NewRegistry(nil, log.Named("registry"), dbprovider.NewPersistentDBProvider(dbDir, log))
+}
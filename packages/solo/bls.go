@@ -0,0 +1,107 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/tcrypto"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+// Option is an optional parameter of the Solo environment, see New
+type Option func(env *Solo)
+
+// WithBLSCommittee makes all chains of the environment controlled by the BLS threshold address of
+// a committee of n nodes with the quorum t, like chains on the Wasp network.
+// Key shares of the committee members are created by emulation of the DKG and each state transition
+// is signed by t members the same way as by the consensus: partial signatures are aggregated into the
+// BLS signature. Without the option the chain address is an ED25519 address
+func WithBLSCommittee(n, t uint16) Option {
+	return func(env *Solo) {
+		require.True(env.T, t > 0 && t <= n, "wrong BLS committee quorum %d of %d", t, n)
+		env.blsN = n
+		env.blsT = t
+	}
+}
+
+// BLSCommittee is the emulated committee of nodes sharing the BLS key of the chain address
+type BLSCommittee struct {
+	// Address is the BLS address of the chain
+	Address address.Address
+	// DKShares are the key shares of the committee members, indexed by the peer index
+	DKShares []*tcrypto.DKShare
+}
+
+// newBLSCommittee emulates the DKG: the dealer generates the secret polynomial of degree t-1
+// and distributes its shares to n members
+func newBLSCommittee(env *Solo, n, t uint16) *BLSCommittee {
+	suite := pairing.NewSuiteBn256()
+	priPoly := share.NewPriPoly(suite, int(t), nil, suite.RandomStream())
+	_, commits := priPoly.Commit(nil).Info()
+	priShares := priPoly.Shares(int(n))
+	publicShares := make([]kyber.Point, n)
+	for i, s := range priShares {
+		publicShares[i] = suite.Point().Mul(s.V, nil)
+	}
+	sharedPublic := priPoly.Commit(nil).Commit()
+
+	ret := &BLSCommittee{DKShares: make([]*tcrypto.DKShare, n)}
+	for i, s := range priShares {
+		dks, err := tcrypto.NewDKShare(uint16(i), n, t, sharedPublic, commits, publicShares, s.V)
+		require.NoError(env.T, err)
+		// the key share is restored from bytes like by the registry of the node,
+		// which attaches the suite to it
+		data, err := dks.Bytes()
+		require.NoError(env.T, err)
+		ret.DKShares[i], err = tcrypto.DKShareFromBytes(data, suite)
+		require.NoError(env.T, err)
+	}
+	ret.Address = *ret.DKShares[0].Address
+	return ret
+}
+
+// newVRFCommittee returns the committee which signs the VRF of blocks of the chain: the BLS committee of
+// the chain or, for the chain controlled by the ED25519 address, the committee of one node.
+// The VRF is always the BLS signature, like on the Wasp network, see coretypes.VRF
+func newVRFCommittee(env *Solo, committee *BLSCommittee) *BLSCommittee {
+	if committee != nil {
+		return committee
+	}
+	return newBLSCommittee(env, 1, 1)
+}
+
+// VRFAddress returns the BLS address of the committee which signs the VRF of blocks of the chain.
+// It is the chain address, unless the chain is controlled by the ED25519 address
+func (ch *Chain) VRFAddress() address.Address {
+	return ch.vrfCommittee.Address
+}
+
+// sign signs the transaction by the quorum of the committee
+func (c *BLSCommittee) sign(env *Solo, tx *sctransaction.Transaction, blockIndex uint32) {
+	err := tx.PutSignature(c.signData(env, tx.EssenceBytes(), blockIndex))
+	require.NoError(env.T, err)
+}
+
+// signData signs the data by the quorum of the committee. Each time another subset of members
+// is selected, depending on the block index
+func (c *BLSCommittee) signData(env *Solo, data []byte, blockIndex uint32) signaturescheme.Signature {
+	n := len(c.DKShares)
+	t := int(c.DKShares[0].T)
+	sigShares := make([][]byte, 0, t)
+	for i := 0; i < t; i++ {
+		dks := c.DKShares[(int(blockIndex)+i)%n]
+		sigShare, err := dks.SignShare(data)
+		require.NoError(env.T, err)
+		require.NoError(env.T, c.DKShares[0].VerifySigShare(data, sigShare))
+		sigShares = append(sigShares, sigShare)
+	}
+	signature, err := c.DKShares[0].RecoverFullSignature(sigShares, data)
+	require.NoError(env.T, err)
+	return signature
+}
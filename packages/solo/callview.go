@@ -0,0 +1,99 @@
+package solo
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/stretchr/testify/require"
+)
+
+// callViewResult calls the view and decodes the result with the key into the variable pointed by target.
+// The result must be present
+func (ch *Chain) callViewResult(scName, funName string, resultKey kv.Key, target interface{}, params ...interface{}) error {
+	res, err := ch.CallView(scName, funName, params...)
+	if err != nil {
+		return err
+	}
+	ok, err := codec.DecodeInto(res.MustGet(resultKey), target)
+	if err != nil {
+		return fmt.Errorf("%s::%s: result '%s': %v", scName, funName, resultKey, err)
+	}
+	if !ok {
+		return fmt.Errorf("%s::%s: result '%s' not found", scName, funName, resultKey)
+	}
+	return nil
+}
+
+// CallViewInt64 calls the view and returns the int64 result with the key
+func (ch *Chain) CallViewInt64(scName, funName string, resultKey kv.Key, params ...interface{}) (int64, error) {
+	var ret int64
+	err := ch.callViewResult(scName, funName, resultKey, &ret, params...)
+	return ret, err
+}
+
+// CallViewString calls the view and returns the string result with the key
+func (ch *Chain) CallViewString(scName, funName string, resultKey kv.Key, params ...interface{}) (string, error) {
+	var ret string
+	err := ch.callViewResult(scName, funName, resultKey, &ret, params...)
+	return ret, err
+}
+
+// CallViewAgentID calls the view and returns the agent ID result with the key
+func (ch *Chain) CallViewAgentID(scName, funName string, resultKey kv.Key, params ...interface{}) (coretypes.AgentID, error) {
+	var ret coretypes.AgentID
+	err := ch.callViewResult(scName, funName, resultKey, &ret, params...)
+	return ret, err
+}
+
+// CallViewDecode calls the view and decodes its results into the struct pointed by target.
+// Each exported field of the struct is decoded from the result with the key given by the 'codec' tag
+// of the field or, if the tag is missing, with the name of the field. Fields tagged with `codec:"-"`
+// are skipped. Fields of results not returned by the view are left unchanged.
+// Fields must be of types supported by codec.DecodeInto
+func (ch *Chain) CallViewDecode(scName, funName string, target interface{}, params ...interface{}) error {
+	res, err := ch.CallView(scName, funName, params...)
+	if err != nil {
+		return err
+	}
+	if err := DecodeResults(res, target); err != nil {
+		return fmt.Errorf("%s::%s: %v", scName, funName, err)
+	}
+	return nil
+}
+
+// MustCallViewDecode is CallViewDecode which fails the test on error
+func (ch *Chain) MustCallViewDecode(scName, funName string, target interface{}, params ...interface{}) {
+	err := ch.CallViewDecode(scName, funName, target, params...)
+	require.NoError(ch.Env.T, err)
+}
+
+// DecodeResults decodes the results of the call into the struct pointed by target, see CallViewDecode
+func DecodeResults(res dict.Dict, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a pointer to a struct, got %T", target)
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+		key := field.Tag.Get("codec")
+		if key == "-" {
+			continue
+		}
+		if key == "" {
+			key = field.Name
+		}
+		if _, err := codec.DecodeInto(res.MustGet(kv.Key(key)), v.Field(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("field %s, result '%s': %v", field.Name, key, err)
+		}
+	}
+	return nil
+}
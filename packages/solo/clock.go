@@ -9,39 +9,59 @@ import "time"
 func (env *Solo) LogicalTime() time.Time {
 	env.clockMutex.RLock()
 	defer env.clockMutex.RUnlock()
-	return env.logicalTime
+	return env.now()
+}
+
+// now returns the logical time. In the wall-clock mode it is the time of the last advance
+// plus the real time passed since then, multiplied by the speed-up factor
+func (env *Solo) now() time.Time {
+	if !env.wallClock {
+		return env.logicalTime
+	}
+	return env.logicalTime.Add(time.Duration(float64(time.Since(env.wallClockAt)) * env.speedUp))
 }
 
 // AdvanceClockTo advances logical clock to the specific time moment in the (logical) future
 func (env *Solo) AdvanceClockTo(ts time.Time) {
 	env.clockMutex.Lock()
-	defer env.clockMutex.Unlock()
 	env.advanceClockTo(ts)
+	env.clockMutex.Unlock()
+
+	env.tick()
 }
 
 func (env *Solo) advanceClockTo(ts time.Time) {
-	if !env.logicalTime.Before(ts) {
+	if !env.now().Before(ts) {
 		env.logger.Panic("can'T advance clock to the past")
 	}
 	env.logicalTime = ts
+	env.wallClockAt = time.Now()
 }
 
 // AdvanceClockBy advances logical clock by time step
 func (env *Solo) AdvanceClockBy(step time.Duration) {
 	env.clockMutex.Lock()
-	defer env.clockMutex.Unlock()
+	env.advanceClockTo(env.now().Add(step))
+	env.clockMutex.Unlock()
 
-	env.advanceClockTo(env.logicalTime.Add(step))
 	env.logger.Infof("AdvanceClockBy: logical clock advanced by %v", step)
+	env.tick()
 }
 
-// ClockStep advances logical clock by time step set by SetTimeStep
+// ClockStep advances logical clock by time step set by WithTimeStep or SetTimeStep.
+// In the wall-clock mode the clock advances by itself, so the call only confirms pending transactions
 func (env *Solo) ClockStep() {
 	env.clockMutex.Lock()
-	defer env.clockMutex.Unlock()
+	step := env.timeStep
+	if !env.wallClock {
+		env.advanceClockTo(env.logicalTime.Add(step))
+	}
+	env.clockMutex.Unlock()
 
-	env.advanceClockTo(env.logicalTime.Add(env.timeStep))
-	env.logger.Infof("ClockStep: logical clock advanced by %v", env.timeStep)
+	if !env.wallClock {
+		env.logger.Infof("ClockStep: logical clock advanced by %v", step)
+	}
+	env.tick()
 }
 
 // SetTimeStep sets default time step for the 'solo' instance
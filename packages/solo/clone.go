@@ -0,0 +1,284 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/sctransaction/origin"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/txutil/vtxbuilder"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/iotaledger/wasp/packages/vm/statetxbuilder"
+	"github.com/stretchr/testify/require"
+)
+
+// Clone creates the new chain with the same state as the current state of the chain: the same contracts,
+// accounts and contract states. It is intended for A/B testing: for example, the new version of the
+// contract can be deployed on the clone and its behavior compared with the old version on identical state.
+//
+// The clone is a separate chain with its own chain address, so the chain ID and the chain color are
+// different. All occurrences of them in the state are replaced with the ones of the clone, so contract
+// IDs and agent IDs of contracts refer to the clone.
+// Colored tokens can't be duplicated on the ledger, so tokens of each color owned by the chain are minted
+// again with the new color and replaced in the state as well, see ClonedColor. Iotas stay iotas.
+// The owner and the originator of the clone are the same as of the original chain.
+//
+// The clone starts with the origin block and the block #1, which contains the whole copied state.
+// Requests not yet processed by the original chain are not copied
+func (ch *Chain) Clone(name string) *Chain {
+	env := ch.Env
+	require.False(env.T, ch.imported, "can't clone the imported chain")
+
+	ch.runVMMutex.Lock()
+	srcState := ch.State.Clone()
+	assets := ch.stateOutputBalances()
+	ch.runVMMutex.Unlock()
+
+	env.logger.Infof("cloning chain '%s' into '%s'", ch.Name, name)
+
+	var chSig signaturescheme.SignatureScheme
+	var committee *BLSCommittee
+	var chainAddress address.Address
+	if env.blsN > 0 {
+		committee = newBLSCommittee(env, env.blsN, env.blsT)
+		chainAddress = committee.Address
+	} else {
+		chSig = signaturescheme.ED25519(env.newKeyPair())
+		chainAddress = chSig.Address()
+	}
+	chainID := coretypes.ChainID(chainAddress)
+
+	// the funder provides iotas for the chain token and for the assets of the clone
+	funder := signaturescheme.ED25519(env.newKeyPair())
+	need := int64(1)
+	for _, amount := range assets {
+		need += amount
+	}
+	for collected := int64(0); collected < need; collected += testutil.RequestFundsAmount {
+		_, err := env.utxoDB.RequestFunds(funder.Address())
+		require.NoError(env.T, err)
+	}
+	colors := ch.mintClonedColors(funder, assets)
+
+	stateTx, err := origin.NewOriginTransaction(origin.NewOriginTransactionParams{
+		OriginAddress:             chainAddress,
+		OriginatorSignatureScheme: funder,
+		AllInputs:                 env.utxoDB.GetAddressOutputs(funder.Address()),
+	})
+	require.NoError(env.T, err)
+	err = env.confirmTransaction(stateTx)
+	require.NoError(env.T, err)
+	chainColor := balance.Color(stateTx.ID())
+
+	vtxb, err := vtxbuilder.NewFromOutputBalances(env.utxoDB.GetAddressOutputs(funder.Address()))
+	require.NoError(env.T, err)
+	for col, amount := range assets {
+		if newCol, ok := colors[col]; ok {
+			col = newCol
+		}
+		err = vtxb.MoveTokensToAddress(chainAddress, col, amount)
+		require.NoError(env.T, err)
+	}
+	tx := vtxb.Build(false)
+	tx.Sign(funder)
+	err = env.utxoDB.AddTransaction(tx)
+	require.NoError(env.T, err)
+
+	tracer := newRequestTracer()
+	captures := newLogCaptures()
+	ret := &Chain{
+		Env:                 env,
+		Name:                name,
+		ChainSigScheme:      chSig,
+		Committee:           committee,
+		vrfCommittee:        newVRFCommittee(env, committee),
+		OriginatorSigScheme: ch.OriginatorSigScheme,
+		ChainID:             chainID,
+		ChainAddress:        chainAddress,
+		ChainColor:          chainColor,
+		OriginatorAddress:   ch.OriginatorAddress,
+		OriginatorAgentID:   ch.OriginatorAgentID,
+		ValidatorFeeTarget:  ch.ValidatorFeeTarget,
+		StateTx:             stateTx,
+		State:               state.NewVirtualState(mapdb.NewMapDB(), &chainID),
+		proc:                processors.MustNew(),
+		Log:                 captures.withCapture(tracer.withTracer(env.logger.Named(name))),
+		tracer:              tracer,
+		logCaptures:         captures,
+		viewLimits:          ch.viewLimits,
+		invariants:          append([]Invariant(nil), ch.invariants...),
+		clonedColors:        colors,
+		//
+		runVMMutex:   &sync.Mutex{},
+		chInRequest:  make(chan sctransaction.RequestRef),
+		backlog:      make([]sctransaction.RequestRef, 0),
+		backlogMutex: &sync.RWMutex{},
+		stop:         make(chan struct{}),
+	}
+	if !ch.ValidatorFeeTarget.IsAddress() && ch.ValidatorFeeTarget.MustContractID().ChainID() == ch.ChainID {
+		// the fee target is the contract on the original chain
+		ret.ValidatorFeeTarget = coretypes.NewAgentIDFromContractID(
+			coretypes.NewContractID(chainID, ch.ValidatorFeeTarget.MustContractID().Hname()))
+	}
+
+	originBlock := state.MustNewOriginBlock(&ret.ChainColor)
+	err = ret.State.ApplyBlock(originBlock)
+	require.NoError(env.T, err)
+	err = ret.State.CommitToDb(originBlock)
+	require.NoError(env.T, err)
+	ret.stateHashes = []hashing.HashValue{ret.State.Hash()}
+	ret.blocks = []state.Block{originBlock}
+
+	replacer := newCloneReplacer()
+	replacer.add(ch.ChainID[:], chainID[:])
+	replacer.add(ch.ChainColor[:], chainColor[:])
+	for col, newCol := range colors {
+		replacer.add(col[:], newCol[:])
+	}
+	block, err := cloneBlock(srcState, replacer)
+	require.NoError(env.T, err)
+
+	newState := ret.State.Clone()
+	err = newState.ApplyBlock(block)
+	require.NoError(env.T, err)
+
+	stxb, err := statetxbuilder.New(chainAddress, chainColor, waspconn.OutputsToBalances(env.utxoDB.GetAddressOutputs(chainAddress)))
+	require.NoError(env.T, err)
+	err = stxb.SetStateParams(block.StateIndex(), newState.Hash(), block.Timestamp())
+	require.NoError(env.T, err)
+	cloneTx, err := stxb.Build()
+	require.NoError(env.T, err)
+	if committee != nil {
+		committee.sign(env, cloneTx, block.StateIndex())
+	} else {
+		cloneTx.Sign(chSig)
+	}
+	block.WithStateTransaction(cloneTx.ID())
+	ret.settleStateTransition(ret.State.Clone(), block, cloneTx)
+
+	env.glbMutex.Lock()
+	env.chains[chainID] = ret
+	env.glbMutex.Unlock()
+
+	ret.startBacklogLoops()
+
+	ret.Log.Infof("chain '%s' cloned from '%s'. Chain ID: %s, %d key(s) copied", ret.Name, ch.Name, ret.ChainID, replacer.keys)
+	return ret
+}
+
+// ClonedColor returns the color which replaces the color of the original chain in the clone, see Clone.
+// Colors which are not replaced, including iotas, are returned as is
+func (ch *Chain) ClonedColor(col balance.Color) balance.Color {
+	if newCol, ok := ch.clonedColors[col]; ok {
+		return newCol
+	}
+	return col
+}
+
+// stateOutputBalances returns tokens in the output of the current state transaction, except the chain token.
+// These are the tokens owned by on-chain accounts
+func (ch *Chain) stateOutputBalances() map[balance.Color]int64 {
+	ret := make(map[balance.Color]int64)
+	stateTxID := ch.StateTx.ID()
+	for oid, bals := range ch.Env.utxoDB.GetAddressOutputs(ch.ChainAddress) {
+		if oid.TransactionID() != stateTxID {
+			continue
+		}
+		for _, b := range bals {
+			if b.Color != ch.ChainColor {
+				ret[b.Color] += b.Value
+			}
+		}
+	}
+	return ret
+}
+
+// mintClonedColors mints tokens of each color of the assets with the new color to the funder address.
+// Each color needs its own transaction, because the new color is the ID of the minting transaction
+func (ch *Chain) mintClonedColors(funder signaturescheme.SignatureScheme, assets map[balance.Color]int64) map[balance.Color]balance.Color {
+	env := ch.Env
+	cols := make([]balance.Color, 0, len(assets))
+	for col := range assets {
+		if col != balance.ColorIOTA {
+			cols = append(cols, col)
+		}
+	}
+	sort.Slice(cols, func(i, j int) bool { return bytes.Compare(cols[i][:], cols[j][:]) < 0 })
+
+	ret := make(map[balance.Color]balance.Color)
+	for _, col := range cols {
+		vtxb, err := vtxbuilder.NewFromOutputBalances(env.utxoDB.GetAddressOutputs(funder.Address()))
+		require.NoError(env.T, err)
+		err = vtxb.MintColoredTokens(funder.Address(), balance.ColorIOTA, assets[col])
+		require.NoError(env.T, err)
+		tx := vtxb.Build(false)
+		tx.Sign(funder)
+		err = env.utxoDB.AddTransaction(tx)
+		require.NoError(env.T, err)
+		ret[col] = balance.Color(tx.ID())
+	}
+	return ret
+}
+
+// cloneReplacer replaces byte sequences of the original chain with the ones of the clone
+type cloneReplacer struct {
+	from [][]byte
+	to   [][]byte
+	keys int
+}
+
+func newCloneReplacer() *cloneReplacer {
+	return &cloneReplacer{}
+}
+
+func (r *cloneReplacer) add(from, to []byte) {
+	r.from = append(r.from, append([]byte(nil), from...))
+	r.to = append(r.to, append([]byte(nil), to...))
+}
+
+func (r *cloneReplacer) replace(data []byte) []byte {
+	for i := range r.from {
+		data = bytes.ReplaceAll(data, r.from[i], r.to[i])
+	}
+	return data
+}
+
+// cloneBlock returns the block #1 which sets all key/value pairs of the state, with the replacements
+func cloneBlock(src state.VirtualState, r *cloneReplacer) (state.Block, error) {
+	vars := make(map[kv.Key][]byte)
+	src.Variables().MustIterate("", func(key kv.Key, value []byte) bool {
+		vars[kv.Key(r.replace([]byte(key)))] = r.replace(value)
+		return true
+	})
+	keys := make([]kv.Key, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	r.keys = len(keys)
+
+	su := state.NewStateUpdate(nil).WithTimestamp(src.Timestamp())
+	for _, k := range keys {
+		su.Mutations().Add(buffered.NewMutationSet(k, vars[k]))
+	}
+	block, err := state.NewBlock([]state.StateUpdate{su})
+	if err != nil {
+		return nil, err
+	}
+	return block.WithBlockIndex(1), nil
+}
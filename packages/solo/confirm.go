@@ -0,0 +1,158 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"sync"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+)
+
+// The file contains hooks which emulate callbacks of the node connection (the WaspConn plugin of Goshimmer):
+// the code under test may react to confirmations and rejections of transactions the same way it does in
+// the real node, without a Goshimmer instance
+
+// LedgerConfirmHandler is called each time a smart contract transaction is confirmed by the ledger
+type LedgerConfirmHandler func(tx *sctransaction.Transaction)
+
+// LedgerRejectHandler is called each time a pending transaction is rejected on confirmation
+// or removed from the ledger with ReorgTransaction
+type LedgerRejectHandler func(tx *sctransaction.Transaction, reason error)
+
+// ledgerHooks are handlers attached with OnLedgerConfirm and OnLedgerReject
+type ledgerHooks struct {
+	mutex     sync.RWMutex
+	onConfirm []LedgerConfirmHandler
+	onReject  []LedgerRejectHandler
+}
+
+// OnLedgerConfirm attaches the handler which is called each time a smart contract transaction is confirmed:
+// origin and state transactions of chains, requests and transactions added with AddToLedger or PostTransaction.
+// With SetConfirmationDelay the handler is called when the pending transaction is confirmed, not when it is added.
+// The handler is called synchronously in the order of confirmations and the transaction is already in the
+// UTXODB ledger. It may inspect the ledger, but must not add transactions to it
+func (env *Solo) OnLedgerConfirm(fn LedgerConfirmHandler) {
+	env.ledgerHooks.mutex.Lock()
+	defer env.ledgerHooks.mutex.Unlock()
+	env.ledgerHooks.onConfirm = append(env.ledgerHooks.onConfirm, fn)
+}
+
+// OnLedgerReject attaches the handler which is called each time a pending transaction is rejected on
+// confirmation, for example because its inputs were spent meanwhile, or removed with ReorgTransaction.
+// Same restrictions as for OnLedgerConfirm apply
+func (env *Solo) OnLedgerReject(fn LedgerRejectHandler) {
+	env.ledgerHooks.mutex.Lock()
+	defer env.ledgerHooks.mutex.Unlock()
+	env.ledgerHooks.onReject = append(env.ledgerHooks.onReject, fn)
+}
+
+func (env *Solo) notifyConfirmed(tx *sctransaction.Transaction) {
+	env.ledgerHooks.mutex.RLock()
+	defer env.ledgerHooks.mutex.RUnlock()
+	for _, fn := range env.ledgerHooks.onConfirm {
+		fn(tx)
+	}
+}
+
+func (env *Solo) notifyRejected(tx *sctransaction.Transaction, reason error) {
+	env.ledgerHooks.mutex.RLock()
+	defer env.ledgerHooks.mutex.RUnlock()
+	for _, fn := range env.ledgerHooks.onReject {
+		fn(tx, reason)
+	}
+}
+
+// ConfirmationRecorder is the test double of the node connection which records confirmed and rejected
+// transactions, see RecordConfirmations
+type ConfirmationRecorder struct {
+	mutex     sync.Mutex
+	confirmed []valuetransaction.ID
+	rejected  map[valuetransaction.ID]error
+	changed   chan struct{}
+}
+
+// RecordConfirmations attaches the new ConfirmationRecorder to the ledger
+func (env *Solo) RecordConfirmations() *ConfirmationRecorder {
+	ret := &ConfirmationRecorder{
+		confirmed: make([]valuetransaction.ID, 0),
+		rejected:  make(map[valuetransaction.ID]error),
+		changed:   make(chan struct{}),
+	}
+	env.OnLedgerConfirm(func(tx *sctransaction.Transaction) {
+		ret.mutex.Lock()
+		defer ret.mutex.Unlock()
+		ret.confirmed = append(ret.confirmed, tx.ID())
+		ret.notify()
+	})
+	env.OnLedgerReject(func(tx *sctransaction.Transaction, reason error) {
+		ret.mutex.Lock()
+		defer ret.mutex.Unlock()
+		ret.rejected[tx.ID()] = reason
+		ret.notify()
+	})
+	return ret
+}
+
+// notify wakes up goroutines waiting in WaitConfirmed. The mutex must be locked
+func (r *ConfirmationRecorder) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// Confirmed returns IDs of confirmed transactions in the order of confirmation
+func (r *ConfirmationRecorder) Confirmed() []valuetransaction.ID {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]valuetransaction.ID(nil), r.confirmed...)
+}
+
+// Rejected returns the reason the transaction was rejected, or nil if it wasn't
+func (r *ConfirmationRecorder) Rejected(txid valuetransaction.ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.rejected[txid]
+}
+
+// IsConfirmed returns true if the confirmation of the transaction was recorded
+func (r *ConfirmationRecorder) IsConfirmed(txid valuetransaction.ID) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.isConfirmed(txid)
+}
+
+func (r *ConfirmationRecorder) isConfirmed(txid valuetransaction.ID) bool {
+	for i := range r.confirmed {
+		if r.confirmed[i] == txid {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitConfirmed waits until the transaction is confirmed. Returns false if the transaction
+// was rejected or not confirmed within the timeout
+func (r *ConfirmationRecorder) WaitConfirmed(txid valuetransaction.ID, timeout time.Duration) bool {
+	deadline := time.After(timeout)
+	for {
+		r.mutex.Lock()
+		if r.isConfirmed(txid) {
+			r.mutex.Unlock()
+			return true
+		}
+		if _, rejected := r.rejected[txid]; rejected {
+			r.mutex.Unlock()
+			return false
+		}
+		changed := r.changed
+		r.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return false
+		}
+	}
+}
@@ -41,6 +41,14 @@
 // the development and debugging of the smart contract logic in IDE such as GoLand, before writing it as
 // a Rust/Wasm smart contract.
 //
+// State proofs
+//
+// 'solo' doesn't provide proofs of keys of the state, e.g. to test that the data of the contract is provable
+// to light clients. The state hash of the chain is a hash chain over the state updates of the blocks
+// (see state.VirtualState), not a commitment to the key/value pairs of the state, so there is no proof of
+// a key or of an absent key against the state hash. Chain.StateHashAt returns the state hash after each
+// block, which is what the anchor transaction of the chain commits to.
+//
 // Example test
 //
 // The following example deploys chain and retrieves basic info from the deployed chain.
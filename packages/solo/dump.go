@@ -0,0 +1,161 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+)
+
+// DumpEventLogRecords is the number of the latest event log records of the chain included in the dump
+const DumpEventLogRecords = 20
+
+// batchRecord is the composition of the batch run by the chain and the index of the resulting block, kept for the dump
+type batchRecord struct {
+	trace      string
+	blockIndex uint32
+	requests   []string
+	err        error
+}
+
+// recordBatch keeps the composition of the last batch of the chain. If the batch returns an error and the environment
+// is in the Debug mode, the chain is dumped to the log. Failed requests are expected by many tests, so they are not
+// dumped otherwise, however the dump on failure of the test shows the last batch
+func (ch *Chain) recordBatch(batch []vm.RequestRefWithFreeTokens, trace string, err error) {
+	rec := &batchRecord{
+		trace:      trace,
+		blockIndex: ch.State.BlockIndex(),
+		requests:   make([]string, len(batch)),
+		err:        err,
+	}
+	for i := range batch {
+		rec.requests[i] = requestString(&batch[i].RequestRef)
+	}
+	ch.lastBatchMutex.Lock()
+	ch.lastBatch = rec
+	ch.lastBatchMutex.Unlock()
+
+	if err != nil && ch.Env.debug {
+		ch.Log.Debugf("batch '%s' returned error: %v\n%s", trace, err, ch.Dump())
+	}
+}
+
+// dumpOnFailure logs the dump of all chains of the environment if the test has failed.
+// It is called when the test finishes, before the environment is closed
+func (env *Solo) dumpOnFailure() {
+	if env.noFailureDump || !env.T.Failed() {
+		return
+	}
+	env.T.Logf("solo: the test has failed, dump of chains:\n%s", env.Dump())
+}
+
+// Dump returns the human readable dump of all chains of the environment, see Chain.Dump.
+// It is logged automatically when the test fails, unless the environment is created with NoFailureDump
+func (env *Solo) Dump() string {
+	env.glbMutex.RLock()
+	chains := make([]*Chain, 0, len(env.chains))
+	for _, ch := range env.chains {
+		chains = append(chains, ch)
+	}
+	env.glbMutex.RUnlock()
+	sort.Slice(chains, func(i, j int) bool { return chains[i].Name < chains[j].Name })
+
+	var buf bytes.Buffer
+	for _, ch := range chains {
+		buf.WriteString(ch.Dump())
+	}
+	return buf.String()
+}
+
+// Dump returns the human readable dump of the chain for triage of failed tests: requests in the backlog,
+// the composition of the last batch, balances of on-chain accounts and the latest event log records
+// of all contracts
+func (ch *Chain) Dump() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "======== chain '%s' %s, block index: %d\n", ch.Name, ch.ChainID, ch.State.BlockIndex())
+
+	ch.backlogMutex.RLock()
+	backlog := make([]string, len(ch.backlog))
+	for i := range ch.backlog {
+		backlog[i] = requestString(&ch.backlog[i])
+	}
+	ch.backlogMutex.RUnlock()
+	fmt.Fprintf(&buf, "---- backlog: %d request(s)\n", len(backlog))
+	for _, s := range backlog {
+		fmt.Fprintf(&buf, "    %s\n", s)
+	}
+
+	ch.lastBatchMutex.Lock()
+	last := ch.lastBatch
+	ch.lastBatchMutex.Unlock()
+	if last == nil {
+		fmt.Fprintf(&buf, "---- last batch: none\n")
+	} else {
+		fmt.Fprintf(&buf, "---- last batch: '%s', %d request(s), block index: %d\n", last.trace, len(last.requests), last.blockIndex)
+		for _, s := range last.requests {
+			fmt.Fprintf(&buf, "    %s\n", s)
+		}
+		if last.err != nil {
+			fmt.Fprintf(&buf, "    error: %v\n", last.err)
+		}
+	}
+
+	fmt.Fprintf(&buf, "---- accounts\n%s", ch.DumpAccounts())
+
+	fmt.Fprintf(&buf, "---- latest event log records\n")
+	records, err := ch.latestEventLogRecords(DumpEventLogRecords)
+	if err != nil {
+		fmt.Fprintf(&buf, "    error: %v\n", err)
+	}
+	for _, r := range records {
+		fmt.Fprintf(&buf, "    %d %s: %s\n", r.rec.Timestamp, r.contract, string(r.rec.Data))
+	}
+	return buf.String()
+}
+
+type contractLogRecord struct {
+	contract string
+	rec      *collections.TimestampedLogRecord
+}
+
+// latestEventLogRecords returns up to n latest free-form event log records of all contracts of the chain,
+// the latest first
+func (ch *Chain) latestEventLogRecords(n int) ([]contractLogRecord, error) {
+	_, contracts := ch.GetInfo()
+	ret := make([]contractLogRecord, 0)
+	for hname, rec := range contracts {
+		res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetRecords,
+			eventlog.ParamContractHname, hname,
+			eventlog.ParamMaxLastRecords, n,
+		)
+		if err != nil {
+			return nil, err
+		}
+		recs := collections.NewArrayReadOnly(res, eventlog.ParamRecords)
+		for i := uint16(0); i < recs.MustLen(); i++ {
+			r, err := collections.ParseRawLogRecord(recs.MustGetAt(i))
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, contractLogRecord{contract: rec.Name, rec: r})
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool { return ret[i].rec.Timestamp > ret[j].rec.Timestamp })
+	if len(ret) > n {
+		ret = ret[:n]
+	}
+	return ret, nil
+}
+
+func requestString(ref *sctransaction.RequestRef) string {
+	target := ref.RequestSection().Target()
+	return fmt.Sprintf("%s -> %s::%s from %s", ref.RequestID().Short(), target.Hname(),
+		ref.RequestSection().EntryPointCode(), ref.SenderAgentID())
+}
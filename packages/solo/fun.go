@@ -37,27 +37,12 @@ func (ch *Chain) String() string {
 	return string(buf.Bytes())
 }
 
-// DumpAccounts dumps all account balances into the human readable string
-func (ch *Chain) DumpAccounts() string {
-	info, _ := ch.GetInfo()
-	ret := fmt.Sprintf("ChainID: %s\nChain owner: %s\n", ch.ChainID, info.ChainOwnerID)
-	acc := ch.GetAccounts()
-	for _, aid := range acc {
-		ret += fmt.Sprintf("  %s:\n", aid)
-		bals := ch.GetAccountBalance(aid)
-		bals.IterateDeterministic(func(col balance.Color, bal int64) bool {
-			ret += fmt.Sprintf("       %s: %d\n", col, bal)
-			return true
-		})
-	}
-	return ret
-}
-
 // FindContract is a view call to the 'root' smart contract on the chain.
-// It returns registry record of the deployed smart contract with the given name
+// It returns registry record of the deployed smart contract with the given name, also if the
+// contract is deployed under an alias
 func (ch *Chain) FindContract(scName string) (*root.ContractRecord, error) {
-	retDict, err := ch.CallView(root.Interface.Name, root.FuncFindContract,
-		root.ParamHname, coretypes.Hn(scName),
+	retDict, err := ch.CallView(root.Interface.Name, root.FuncFindContractByName,
+		root.ParamName, scName,
 	)
 	if err != nil {
 		return nil, err
@@ -235,6 +220,14 @@ func (ch *Chain) DeployContract(sigScheme signaturescheme.SignatureScheme, name
 	return err
 }
 
+// DeployContracts deploys several contracts atomically in one request by calling
+// the 'deployContracts' entry point of the 'root' contract
+func (ch *Chain) DeployContracts(sigScheme signaturescheme.SignatureScheme, contracts ...*root.ContractDeployment) error {
+	req := NewCallParamsFromDic(root.Interface.Name, root.FuncDeployContracts, root.EncodeContractDeployments(contracts...))
+	_, err := ch.PostRequestSync(req, sigScheme)
+	return err
+}
+
 // DeployWasmContract is syntactic sugar for uploading Wasm binary from file and
 // deploying the smart contract in one call
 func (ch *Chain) DeployWasmContract(sigScheme signaturescheme.SignatureScheme, name string, fname string, params ...interface{}) error {
@@ -286,6 +279,16 @@ func (ch *Chain) GetInfo() (ChainInfo, map[coretypes.Hname]*root.ContractRecord)
 	}, contracts
 }
 
+// GetChainMetadata calls the view in the 'root' core smart contract to retrieve the description,
+// the website and the custom metadata of the chain
+func (ch *Chain) GetChainMetadata() *root.ChainMetadata {
+	res, err := ch.CallView(root.Interface.Name, root.FuncGetChainMetadata)
+	require.NoError(ch.Env.T, err)
+	ret, err := root.GetChainMetadata(res)
+	require.NoError(ch.Env.T, err)
+	return ret
+}
+
 // GetAddressBalance returns number of tokens of given color contained in the given address
 // on the UTXODB ledger
 func (env *Solo) GetAddressBalance(addr address.Address, col balance.Color) int64 {
@@ -349,6 +352,24 @@ func (ch *Chain) GetTotalAssets() coretypes.ColoredBalances {
 	)
 }
 
+// GetDepositInfo returns the deposit required from each request by the chain
+//  - number of iotas deposited by the request on top of the fees
+//  - deposit policy: root.DepositRefund or root.DepositForfeit
+func (ch *Chain) GetDepositInfo() (int64, int64) {
+	ret, err := ch.CallView(root.Interface.Name, root.FuncGetDepositInfo)
+	require.NoError(ch.Env.T, err)
+
+	minDeposit, ok, err := codec.DecodeInt64(ret.MustGet(root.ParamMinDeposit))
+	require.NoError(ch.Env.T, err)
+	require.True(ch.Env.T, ok)
+
+	depositPolicy, ok, err := codec.DecodeInt64(ret.MustGet(root.ParamDepositPolicy))
+	require.NoError(ch.Env.T, err)
+	require.True(ch.Env.T, ok)
+
+	return minDeposit, depositPolicy
+}
+
 // GetFeeInfo returns the fee info for the specific chain and smart contract
 //  - color of the fee tokens in the chain
 //  - chain owner part of the fee (number of tokens)
@@ -378,6 +399,34 @@ func (ch *Chain) GetFeeInfo(contractName string) (balance.Color, int64, int64) {
 	return feeColor, ownerFee, validatorFee
 }
 
+// GetTokenMetadata calls the view in the 'accounts' core smart contract to retrieve metadata
+// of the colored token. Returns nil if the token is not registered on the chain
+func (ch *Chain) GetTokenMetadata(color balance.Color) *accounts.TokenMetadata {
+	res, err := ch.CallView(accounts.Interface.Name, accounts.FuncGetTokenMetadata, accounts.ParamColor, color)
+	require.NoError(ch.Env.T, err)
+	data := res.MustGet(accounts.ParamTokenMetadata)
+	if data == nil {
+		return nil
+	}
+	ret, err := accounts.DecodeTokenMetadata(data)
+	require.NoError(ch.Env.T, err)
+	return ret
+}
+
+// GetEscrow calls the view in the 'accounts' core smart contract to retrieve the escrow.
+// Returns nil if the escrow was claimed or refunded
+func (ch *Chain) GetEscrow(id int64) *accounts.Escrow {
+	res, err := ch.CallView(accounts.Interface.Name, accounts.FuncGetEscrow, accounts.ParamEscrowID, id)
+	require.NoError(ch.Env.T, err)
+	data := res.MustGet(accounts.ParamEscrow)
+	if data == nil {
+		return nil
+	}
+	ret, err := accounts.DecodeEscrow(data)
+	require.NoError(ch.Env.T, err)
+	return ret
+}
+
 // GetEventLogRecords calls the view in the  'eventlog' core smart contract to retrieve
 // latest up to 50 records for a given smart contract.
 // It returns records as array in time-descending order.
@@ -414,6 +463,54 @@ func (ch *Chain) GetEventLogRecordsString(name string) (string, error) {
 	return ret, nil
 }
 
+// GetEvents calls the view in the 'eventlog' core smart contract to retrieve latest up to 50
+// typed events with the topic, emitted by the smart contract. Optional pair of the field name and the value
+// selects only events with the indexed field equal to the value.
+// It returns events in time-descending order
+func (ch *Chain) GetEvents(name string, topic string, fieldAndValue ...interface{}) ([]*eventlog.Event, error) {
+	params := []interface{}{
+		eventlog.ParamContractHname, coretypes.Hn(name),
+		eventlog.ParamTopic, topic,
+	}
+	if len(fieldAndValue) > 0 {
+		require.Len(ch.Env.T, fieldAndValue, 2, "expected field name and value")
+		params = append(params, eventlog.ParamField, fieldAndValue[0], eventlog.ParamValue, fieldAndValue[1])
+	}
+	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetEvents, params...)
+	if err != nil {
+		return nil, err
+	}
+	recs := collections.NewArrayReadOnly(res, eventlog.ParamRecords)
+	ret := make([]*eventlog.Event, recs.MustLen())
+	for i := uint16(0); i < recs.MustLen(); i++ {
+		_, ev, err := eventlog.ParseEventRecord(recs.MustGetAt(i))
+		require.NoError(ch.Env.T, err)
+		ret[i] = ev
+	}
+	return ret, nil
+}
+
+// GetRequestReceipts calls the view in the 'eventlog' core smart contract to retrieve up to maxRecords
+// latest receipts of requests sent to the chain by the agent.
+// It returns receipts in time-descending order
+func (ch *Chain) GetRequestReceipts(sender coretypes.AgentID, maxRecords int) ([]*eventlog.RequestReceipt, error) {
+	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetRequestReceipts,
+		eventlog.ParamAgentID, sender,
+		eventlog.ParamMaxLastRecords, maxRecords,
+	)
+	if err != nil {
+		return nil, err
+	}
+	recs := collections.NewArrayReadOnly(res, eventlog.ParamRecords)
+	ret := make([]*eventlog.RequestReceipt, recs.MustLen())
+	for i := uint16(0); i < recs.MustLen(); i++ {
+		_, receipt, err := eventlog.ParseReceiptRecord(recs.MustGetAt(i))
+		require.NoError(ch.Env.T, err)
+		ret[i] = receipt
+	}
+	return ret, nil
+}
+
 // GetEventLogNumRecords returns total number of eventlog records for the given contract.
 func (ch *Chain) GetEventLogNumRecords(name string) int {
 	res, err := ch.CallView(eventlog.Interface.Name, eventlog.FuncGetNumRecords,
@@ -0,0 +1,155 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/stretchr/testify/require"
+)
+
+// UpdateGoldenEnvVar makes golden state checks (re)write the golden files instead of comparing with them
+// when set to any non-empty value. Run the tests with SOLO_UPDATE_GOLDEN=1 to regenerate fixtures after
+// an intended change of the state layout
+const UpdateGoldenEnvVar = "SOLO_UPDATE_GOLDEN"
+
+var updateGolden = os.Getenv(UpdateGoldenEnvVar) != ""
+
+// StateKeyFilter selects state variables to be included into the state dump
+type StateKeyFilter func(key kv.Key) bool
+
+// ExcludeKeyPrefixes returns the filter which skips state variables with any of the prefixes,
+// for example variables which contain timestamps or other non-deterministic values
+func ExcludeKeyPrefixes(prefixes ...kv.Key) StateKeyFilter {
+	return func(key kv.Key) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(string(key), string(p)) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// IncludeKeyPrefixes returns the filter which only takes state variables with any of the prefixes
+func IncludeKeyPrefixes(prefixes ...kv.Key) StateKeyFilter {
+	return func(key kv.Key) bool {
+		for _, p := range prefixes {
+			if strings.HasPrefix(string(key), string(p)) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// StateHashAt returns the hash of the chain state after the block with the given index was applied.
+// Index 0 is the origin state. Returns false if the chain has no state with the index yet
+func (ch *Chain) StateHashAt(index uint32) (hashing.HashValue, bool) {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	if int(index) >= len(ch.stateHashes) {
+		return hashing.NilHash, false
+	}
+	return ch.stateHashes[index], true
+}
+
+// DumpContractState returns deterministic human-readable dump of the state of the contract.
+// Each state variable which passes all filters is dumped as a 'key: value' line, lines are sorted by key.
+// Printable keys are quoted, other keys and all values are hex encoded
+func (ch *Chain) DumpContractState(contractName string, filters ...StateKeyFilter) string {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	contractState := subrealm.New(ch.State.Variables(), kv.Key(coretypes.Hn(contractName).Bytes()))
+	lines := make([]string, 0)
+	contractState.MustIterate(kv.EmptyPrefix, func(key kv.Key, value []byte) bool {
+		for _, f := range filters {
+			if !f(key) {
+				return true
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", dumpKey(key), hex.EncodeToString(value)))
+		return true
+	})
+	sort.Strings(lines)
+	var buf bytes.Buffer
+	for _, l := range lines {
+		buf.WriteString(l)
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+func dumpKey(key kv.Key) string {
+	if key != "" && strings.IndexFunc(string(key), func(r rune) bool {
+		return r < 0x20 || r > 0x7e
+	}) < 0 {
+		return strconv.Quote(string(key))
+	}
+	return "0x" + hex.EncodeToString([]byte(key))
+}
+
+// CheckGoldenState compares the dump of the contract state (see DumpContractState) with the golden file.
+// By convention golden files are kept in the 'testdata' directory of the test package.
+// With SOLO_UPDATE_GOLDEN set the golden file is (re)written instead
+func (ch *Chain) CheckGoldenState(fname string, contractName string, filters ...StateKeyFilter) error {
+	dump := ch.DumpContractState(contractName, filters...)
+	if updateGolden {
+		if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+			return err
+		}
+		ch.Log.Infof("golden file %s updated", fname)
+		return ioutil.WriteFile(fname, []byte(dump), 0644)
+	}
+	expected, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return fmt.Errorf("can't read golden file: %v. Run the test with %s=1 to create it", err, UpdateGoldenEnvVar)
+	}
+	if string(expected) != dump {
+		return fmt.Errorf("state of contract '%s' differs from golden file %s\n%s",
+			contractName, fname, diffLines(string(expected), dump))
+	}
+	return nil
+}
+
+// AssertGoldenState fails the test if the state of the contract differs from the golden file
+func (ch *Chain) AssertGoldenState(fname string, contractName string, filters ...StateKeyFilter) {
+	require.NoError(ch.Env.T, ch.CheckGoldenState(fname, contractName, filters...))
+}
+
+// diffLines lists lines missing in one of the dumps
+func diffLines(expected, actual string) string {
+	expLines := strings.Split(strings.TrimSpace(expected), "\n")
+	actLines := strings.Split(strings.TrimSpace(actual), "\n")
+	var buf bytes.Buffer
+	writeMissing(&buf, "- ", expLines, actLines)
+	writeMissing(&buf, "+ ", actLines, expLines)
+	return buf.String()
+}
+
+func writeMissing(buf *bytes.Buffer, tag string, lines, other []string) {
+	set := make(map[string]bool)
+	for _, l := range other {
+		set[l] = true
+	}
+	for _, l := range lines {
+		if !set[l] {
+			buf.WriteString(tag + l + "\n")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/stretchr/testify/require"
+)
+
+// Invariant is a condition which must hold for the chain after each batch of requests.
+// It returns error if the condition is violated
+type Invariant func(ch *Chain) error
+
+// AddInvariant adds the invariant to the chain. All invariants of the chain are evaluated after
+// every batch of requests run on the chain, so the test fails at the batch which introduced the violation.
+// See InvariantIotaConservation and InvariantStateHash for the built-in invariants
+func (ch *Chain) AddInvariant(inv Invariant) {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	ch.invariants = append(ch.invariants, inv)
+}
+
+// checkInvariants evaluates all invariants of the chain. It must be called without the runVMMutex locked,
+// because invariants call views of the chain
+func (ch *Chain) checkInvariants(trace string) {
+	ch.runVMMutex.Lock()
+	invariants := make([]Invariant, len(ch.invariants))
+	copy(invariants, ch.invariants)
+	blockIndex := ch.State.BlockIndex()
+	ch.runVMMutex.Unlock()
+
+	for i, inv := range invariants {
+		err := inv(ch)
+		require.NoError(ch.Env.T, err, "chain '%s': invariant #%d violated after batch '%s', block #%d",
+			ch.Name, i, trace, blockIndex)
+	}
+}
+
+// InvariantIotaConservation checks that tokens in the UTXODB output of the current state transaction
+// of the chain are exactly the tokens owned by on-chain accounts, plus the chain token.
+// It also checks that the sum of all on-chain accounts is equal to total assets of the chain
+func InvariantIotaConservation(ch *Chain) error {
+	ch.runVMMutex.Lock()
+	stateTxID := ch.StateTx.ID()
+	ch.runVMMutex.Unlock()
+
+	onLedger := make(map[balance.Color]int64)
+	for oid, bals := range ch.Env.utxoDB.GetAddressOutputs(ch.ChainAddress) {
+		if oid.TransactionID() != stateTxID {
+			// outputs of requests not processed yet
+			continue
+		}
+		for _, b := range bals {
+			onLedger[b.Color] += b.Value
+		}
+	}
+	if onLedger[ch.ChainColor] != 1 {
+		return fmt.Errorf("expected 1 chain token in the state output, got %d", onLedger[ch.ChainColor])
+	}
+	delete(onLedger, ch.ChainColor)
+
+	total := ch.GetTotalAssets()
+	if !total.Equal(cbalances.NewFromMap(onLedger)) {
+		return fmt.Errorf("total assets on chain %s != balance of the state output %s",
+			total.String(), cbalances.NewFromMap(onLedger).String())
+	}
+
+	sum := make(map[balance.Color]int64)
+	for _, acc := range ch.GetAccounts() {
+		ch.GetAccountBalance(acc).AddToMap(sum)
+	}
+	if !total.Equal(cbalances.NewFromMap(sum)) {
+		return fmt.Errorf("total assets on chain %s != sum of all accounts %s",
+			total.String(), cbalances.NewFromMap(sum).String())
+	}
+	return nil
+}
+
+// InvariantStateHash checks that the hash of the current state of the chain is the one recorded for the
+// block index and that it is committed in the current state transaction
+func InvariantStateHash(ch *Chain) error {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	blockIndex := ch.State.BlockIndex()
+	if int(blockIndex) != len(ch.stateHashes)-1 {
+		return fmt.Errorf("block index %d of the state doesn't match the number of state transitions %d",
+			blockIndex, len(ch.stateHashes)-1)
+	}
+	stateHash := ch.State.Hash()
+	if stateHash != ch.stateHashes[blockIndex] {
+		return fmt.Errorf("state hash %s != recorded hash %s of block #%d",
+			stateHash.String(), ch.stateHashes[blockIndex].String(), blockIndex)
+	}
+	stateSection, ok := ch.StateTx.State()
+	if !ok {
+		return fmt.Errorf("state transaction %s has no state section", ch.StateTx.ID().String())
+	}
+	if stateSection.BlockIndex() != blockIndex {
+		return fmt.Errorf("block index %d in the state transaction != block index %d of the state",
+			stateSection.BlockIndex(), blockIndex)
+	}
+	if stateSection.StateHash() != stateHash {
+		return fmt.Errorf("state hash %s in the state transaction != state hash %s",
+			stateSection.StateHash().String(), stateHash.String())
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+)
+
+// pendingTx is a transaction added to the ledger but not confirmed yet
+type pendingTx struct {
+	tx            *sctransaction.Transaction
+	confirmAtTick int
+	// enqueue is true if requests of the transaction are to be dispatched to chains upon confirmation
+	enqueue bool
+}
+
+// SetConfirmationDelay sets the number of ticks of the logical clock the transaction added with AddToLedger
+// stays unconfirmed. Each ClockStep, AdvanceClockBy and AdvanceClockTo call is one tick.
+// Unconfirmed transaction is not in the UTXODB ledger and its requests are not visible to chains.
+// It can be removed from the ledger with ReorgTransaction, as if it was orphaned on L1.
+// 0 (the default) means transactions are confirmed immediately.
+// State transactions of chains and requests posted with PostRequestSync are always confirmed immediately
+func (env *Solo) SetConfirmationDelay(ticks int) {
+	env.pendingMutex.Lock()
+	defer env.pendingMutex.Unlock()
+	if ticks < 0 {
+		ticks = 0
+	}
+	env.confirmationDelay = ticks
+}
+
+// IsPending returns true if the transaction was added to the ledger but is not confirmed yet
+func (env *Solo) IsPending(txid valuetransaction.ID) bool {
+	env.pendingMutex.Lock()
+	defer env.pendingMutex.Unlock()
+	return env.findPending(txid) >= 0
+}
+
+// PendingTransactions returns unconfirmed transactions in the order they were added to the ledger
+func (env *Solo) PendingTransactions() []*sctransaction.Transaction {
+	env.pendingMutex.Lock()
+	defer env.pendingMutex.Unlock()
+	ret := make([]*sctransaction.Transaction, len(env.pending))
+	for i, p := range env.pending {
+		ret[i] = p.tx
+	}
+	return ret
+}
+
+// ReorgTransaction removes unconfirmed transaction from the ledger. Requests contained in the transaction
+// never reach backlogs of chains and the inputs of the transaction remain unspent.
+// Returns error if the transaction is already confirmed or unknown
+func (env *Solo) ReorgTransaction(txid valuetransaction.ID) error {
+	env.pendingMutex.Lock()
+	i := env.findPending(txid)
+	if i < 0 {
+		env.pendingMutex.Unlock()
+		return fmt.Errorf("transaction %s is not pending", txid.String())
+	}
+	tx := env.pending[i].tx
+	env.pending = append(env.pending[:i], env.pending[i+1:]...)
+	env.pendingMutex.Unlock()
+
+	env.logger.Infof("ReorgTransaction: transaction %s removed from the ledger", txid.String())
+	env.notifyRejected(tx, fmt.Errorf("transaction %s was orphaned", txid.String()))
+	return nil
+}
+
+// addToLedgerPending adds the transaction to the list of unconfirmed transactions.
+// Returns false if the confirmation delay is not set, i.e. the transaction must be confirmed immediately
+func (env *Solo) addToLedgerPending(tx *sctransaction.Transaction) bool {
+	env.pendingMutex.Lock()
+	defer env.pendingMutex.Unlock()
+
+	if env.confirmationDelay == 0 {
+		return false
+	}
+	env.pending = append(env.pending, &pendingTx{
+		tx:            tx,
+		confirmAtTick: env.ticks + env.confirmationDelay,
+	})
+	env.logger.Infof("AddToLedger: transaction %s is pending for %d tick(s)", tx.ID().String(), env.confirmationDelay)
+	return true
+}
+
+// enqueueOnConfirmation postpones dispatching of requests of the unconfirmed transaction until it is confirmed.
+// Returns false if the transaction is not pending
+func (env *Solo) enqueueOnConfirmation(tx *sctransaction.Transaction) bool {
+	env.pendingMutex.Lock()
+	defer env.pendingMutex.Unlock()
+
+	i := env.findPending(tx.ID())
+	if i < 0 {
+		return false
+	}
+	env.pending[i].enqueue = true
+	return true
+}
+
+// tick is called on each advance of the logical clock. It confirms pending transactions which are due.
+// Transaction which became invalid meanwhile, for example because its inputs were spent by another
+// transaction, is rejected
+func (env *Solo) tick() {
+	env.pendingMutex.Lock()
+	env.ticks++
+	confirmed := make([]*pendingTx, 0)
+	remaining := env.pending[:0]
+	for _, p := range env.pending {
+		if p.confirmAtTick <= env.ticks {
+			confirmed = append(confirmed, p)
+		} else {
+			remaining = append(remaining, p)
+		}
+	}
+	env.pending = remaining
+	env.pendingMutex.Unlock()
+
+	for _, p := range confirmed {
+		if err := env.confirmTransaction(p.tx); err != nil {
+			env.logger.Warnf("transaction %s rejected on confirmation: %v", p.tx.ID().String(), err)
+			env.notifyRejected(p.tx, err)
+			continue
+		}
+		env.logger.Infof("transaction %s confirmed", p.tx.ID().String())
+		if p.enqueue {
+			env.EnqueueRequests(p.tx)
+		}
+	}
+}
+
+func (env *Solo) findPending(txid valuetransaction.ID) int {
+	for i, p := range env.pending {
+		if p.tx.ID() == txid {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+)
+
+// The file contains access to the UTXODB ledger of the Solo environment for clients outside of the test,
+// for example the local devnet of wasp-cli, which exposes the ledger through the Goshimmer UTXODB API
+
+// RequestFunds sends LedgerParams.Saldo iotas from the faucet of the ledger to the address
+func (env *Solo) RequestFunds(target address.Address) error {
+	env.ledgerMutex.Lock()
+	defer env.ledgerMutex.Unlock()
+	return env.requestFunds(target)
+}
+
+// GetAddressOutputs returns all confirmed unspent outputs of the address
+func (env *Solo) GetAddressOutputs(addr address.Address) map[valuetransaction.OutputID][]*balance.Balance {
+	env.ledgerMutex.RLock()
+	defer env.ledgerMutex.RUnlock()
+	return env.utxoDB.GetAddressOutputs(addr)
+}
+
+// IsConfirmed returns true if the transaction is confirmed in the UTXODB ledger
+func (env *Solo) IsConfirmed(txid valuetransaction.ID) bool {
+	return env.utxoDB.IsConfirmed(&txid)
+}
+
+// PostTransaction adds the value transaction, signed by the client, to the ledger and dispatches
+// smart contract requests contained in it to the chains. Requests are processed asynchronously
+func (env *Solo) PostTransaction(vtx *valuetransaction.Transaction) error {
+	tx, err := sctransaction.ParseValueTransaction(vtx)
+	if err != nil {
+		return err
+	}
+	env.ledgerMutex.Lock()
+	err = env.AddToLedger(tx)
+	env.ledgerMutex.Unlock()
+	if err != nil {
+		return err
+	}
+	env.logger.Infof("PostTransaction: tx %s with %d request(s) added to the ledger",
+		tx.ID().String(), len(tx.Requests()))
+	env.EnqueueRequests(tx)
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/txutil/vtxbuilder"
+	"github.com/stretchr/testify/require"
+)
+
+// LedgerParams are the economic parameters of the emulated L1 ledger (UTXODB), see WithLedgerParams
+type LedgerParams struct {
+	// Saldo is the amount of iotas the faucet sends to each new wallet and chain originator
+	Saldo int64
+	// DustThresholdIotas is the minimum number of tokens in each output of a wallet transaction.
+	// Outputs with less tokens are rejected by the ledger. 0 means no dust rule
+	DustThresholdIotas int64
+	// TransactionFee is the number of iotas each wallet transaction pays to the FeeAddress of the ledger
+	TransactionFee int64
+}
+
+// DefaultLedgerParams returns the parameters of the ledger without the WithLedgerParams option:
+// Saldo iotas from the faucet, no dust rule and no fees
+func DefaultLedgerParams() LedgerParams {
+	return LedgerParams{Saldo: Saldo}
+}
+
+// WithLedgerParams makes the emulated L1 ledger of the environment follow the economics of the target network.
+// Dust rule and fees apply to wallet transactions built by Solo: requests, minting and uncoloring of tokens,
+// and transactions added with AddToLedger. Origin and state transactions of chains are exempt
+func WithLedgerParams(params LedgerParams) Option {
+	return func(env *Solo) {
+		require.True(env.T, params.Saldo > 0, "saldo must be positive")
+		require.True(env.T, params.DustThresholdIotas >= 0 && params.TransactionFee >= 0,
+			"dust threshold and fee can't be negative")
+		env.ledgerParams = params
+	}
+}
+
+// LedgerParams returns the economic parameters of the emulated L1 ledger
+func (env *Solo) LedgerParams() LedgerParams {
+	return env.ledgerParams
+}
+
+// FeeAddress is the address which collects transaction fees of the ledger, see LedgerParams
+func (env *Solo) FeeAddress() address.Address {
+	return env.feeAddress
+}
+
+// requestFunds sends LedgerParams.Saldo iotas from the faucet to the address.
+// The UTXODB faucet always sends testutil.RequestFundsAmount iotas, so other amounts
+// are collected by a temporary wallet and moved from it to the target address
+func (env *Solo) requestFunds(target address.Address) error {
+	saldo := env.ledgerParams.Saldo
+	if saldo == testutil.RequestFundsAmount {
+		_, err := env.utxoDB.RequestFunds(target)
+		return err
+	}
+	faucet := signaturescheme.ED25519(env.newKeyPair())
+	for collected := int64(0); collected < saldo; collected += testutil.RequestFundsAmount {
+		if _, err := env.utxoDB.RequestFunds(faucet.Address()); err != nil {
+			return err
+		}
+	}
+	vtxb, err := vtxbuilder.NewFromOutputBalances(env.utxoDB.GetAddressOutputs(faucet.Address()))
+	if err != nil {
+		return err
+	}
+	if err := vtxb.MoveTokensToAddress(target, balance.ColorIOTA, saldo); err != nil {
+		return err
+	}
+	tx := vtxb.Build(false)
+	tx.Sign(faucet)
+	return env.utxoDB.AddTransaction(tx)
+}
+
+// addTransactionFee makes the transaction being built pay the fee to the fee address
+func (env *Solo) addTransactionFee(vtxb *vtxbuilder.Builder) error {
+	if env.ledgerParams.TransactionFee == 0 {
+		return nil
+	}
+	if err := vtxb.MoveTokensToAddress(env.feeAddress, balance.ColorIOTA, env.ledgerParams.TransactionFee); err != nil {
+		return fmt.Errorf("can't pay transaction fee of %d iotas: %v", env.ledgerParams.TransactionFee, err)
+	}
+	return nil
+}
+
+// checkDust returns an error if the transaction has an output with less tokens than the dust threshold.
+// The fee output is not a real L1 output, so it is exempt
+func (env *Solo) checkDust(tx *valuetransaction.Transaction) error {
+	if env.ledgerParams.DustThresholdIotas == 0 {
+		return nil
+	}
+	var err error
+	tx.Outputs().ForEach(func(addr address.Address, bals []*balance.Balance) bool {
+		if addr == env.feeAddress {
+			return true
+		}
+		var sum int64
+		for _, b := range bals {
+			sum += b.Value
+		}
+		if sum < env.ledgerParams.DustThresholdIotas {
+			err = fmt.Errorf("dust output: %d token(s) to address %s is less than the threshold %d",
+				sum, addr.String(), env.ledgerParams.DustThresholdIotas)
+			return false
+		}
+		return true
+	})
+	return err
+}
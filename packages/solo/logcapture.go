@@ -0,0 +1,205 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/iotaledger/hive.go/logger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LogCapture is the in-memory sink of log entries of one chain, see CaptureLogs.
+// The capture receives entries of all levels, independently of the level of the logger
+type LogCapture struct {
+	mutex   sync.Mutex
+	entries []*TraceEntry
+	stopped bool
+	owner   *logCaptures
+}
+
+// logCaptures dispatches log entries of the chain to all active captures
+type logCaptures struct {
+	mutex    sync.Mutex
+	captures []*LogCapture
+}
+
+func newLogCaptures() *logCaptures {
+	return &logCaptures{captures: make([]*LogCapture, 0)}
+}
+
+func (c *logCaptures) add(lc *LogCapture) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.captures = append(c.captures, lc)
+}
+
+func (c *logCaptures) remove(lc *LogCapture) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, x := range c.captures {
+		if x == lc {
+			c.captures = append(c.captures[:i], c.captures[i+1:]...)
+			return
+		}
+	}
+}
+
+func (c *logCaptures) write(e *TraceEntry) {
+	c.mutex.Lock()
+	captures := make([]*LogCapture, len(c.captures))
+	copy(captures, c.captures)
+	c.mutex.Unlock()
+
+	for _, lc := range captures {
+		lc.add(e)
+	}
+}
+
+// withCapture returns the logger which, in addition to the normal output, passes all entries to active captures
+func (c *logCaptures) withCapture(log *logger.Logger) *logger.Logger {
+	return log.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &captureCore{captures: c})
+	})).Sugar()
+}
+
+// captureCore is the zapcore.Core which only passes entries to the captures of the chain
+type captureCore struct {
+	captures *logCaptures
+	fields   []zapcore.Field
+}
+
+func (c *captureCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *captureCore) With(fields []zapcore.Field) zapcore.Core {
+	ret := &captureCore{
+		captures: c.captures,
+		fields:   make([]zapcore.Field, 0, len(c.fields)+len(fields)),
+	}
+	ret.fields = append(ret.fields, c.fields...)
+	ret.fields = append(ret.fields, fields...)
+	return ret
+}
+
+func (c *captureCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *captureCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	c.captures.write(&TraceEntry{
+		Time:    ent.Time,
+		Level:   ent.Level,
+		Logger:  ent.LoggerName,
+		Message: ent.Message,
+		Fields:  enc.Fields,
+	})
+	return nil
+}
+
+func (c *captureCore) Sync() error {
+	return nil
+}
+
+// CaptureLogs starts capturing log entries of the chain: the VM, the sandbox, the core contracts and Solo itself.
+// Only entries logged after the call are captured. Several captures can be active at the same time,
+// each of them receives all entries until it is stopped
+func (ch *Chain) CaptureLogs() *LogCapture {
+	ret := &LogCapture{
+		entries: make([]*TraceEntry, 0),
+		owner:   ch.logCaptures,
+	}
+	ch.logCaptures.add(ret)
+	return ret
+}
+
+func (lc *LogCapture) add(e *TraceEntry) {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	if lc.stopped {
+		return
+	}
+	lc.entries = append(lc.entries, e)
+}
+
+// Stop stops capturing. The captured entries remain available
+func (lc *LogCapture) Stop() {
+	lc.mutex.Lock()
+	lc.stopped = true
+	lc.mutex.Unlock()
+	lc.owner.remove(lc)
+}
+
+// Reset discards all captured entries
+func (lc *LogCapture) Reset() {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	lc.entries = lc.entries[:0]
+}
+
+// Entries returns captured entries with the level equal or above minLevel, in the order they were logged.
+// By default all entries are returned
+func (lc *LogCapture) Entries(minLevel ...zapcore.Level) []*TraceEntry {
+	level := zapcore.DebugLevel
+	if len(minLevel) > 0 {
+		level = minLevel[0]
+	}
+	return lc.filter(func(e *TraceEntry) bool {
+		return e.Level >= level
+	})
+}
+
+// Warnings returns captured entries of the level 'warn' and above
+func (lc *LogCapture) Warnings() []*TraceEntry {
+	return lc.Entries(zapcore.WarnLevel)
+}
+
+// Errors returns captured entries of the level 'error' and above
+func (lc *LogCapture) Errors() []*TraceEntry {
+	return lc.Entries(zapcore.ErrorLevel)
+}
+
+// Search returns captured entries with the level equal or above minLevel which contain the substring in the message
+func (lc *LogCapture) Search(substr string, minLevel zapcore.Level) []*TraceEntry {
+	return lc.filter(func(e *TraceEntry) bool {
+		return e.Level >= minLevel && strings.Contains(e.Message, substr)
+	})
+}
+
+// Contains returns true if any entry with the level equal or above minLevel contains the substring in the message
+func (lc *LogCapture) Contains(substr string, minLevel zapcore.Level) bool {
+	return len(lc.Search(substr, minLevel)) > 0
+}
+
+// String returns captured entries in human-readable form, one entry per line
+func (lc *LogCapture) String() string {
+	entries := lc.Entries()
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (lc *LogCapture) filter(f func(e *TraceEntry) bool) []*TraceEntry {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+	ret := make([]*TraceEntry, 0)
+	for _, e := range lc.entries {
+		if f(e) {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
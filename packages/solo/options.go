@@ -0,0 +1,69 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"time"
+
+	"github.com/iotaledger/goshimmer/client/wallet/packages/seed"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/stretchr/testify/require"
+)
+
+// Debug sets the logging level of the environment to 'debug', otherwise it is 'info'
+func Debug() Option {
+	return func(env *Solo) {
+		env.debug = true
+	}
+}
+
+// PrintStackTrace makes the logger of the environment print the stack trace in case of errors
+func PrintStackTrace() Option {
+	return func(env *Solo) {
+		env.printStackTrace = true
+	}
+}
+
+// NoFailureDump disables the dump of all chains to the log of the test when the test fails, see Solo.Dump
+func NoFailureDump() Option {
+	return func(env *Solo) {
+		env.noFailureDump = true
+	}
+}
+
+// WithTimeStep sets the step of the logical clock for each PostRequestSync call. DefaultTimeStep by default
+func WithTimeStep(step time.Duration) Option {
+	return func(env *Solo) {
+		require.True(env.T, step >= 0, "time step can't be negative")
+		env.timeStep = step
+	}
+}
+
+// WithSeed makes ED25519 keys generated by the environment deterministic: signature schemes of
+// NewSignatureScheme and NewSignatureSchemeWithFunds, chain addresses and originators of NewChain
+// are derived from the seed in the order they are created. Addresses in the test output are then
+// the same in each run. Without the option the keys are random
+func WithSeed(seedBytes []byte) Option {
+	return func(env *Solo) {
+		env.seed = seed.NewSeed(seedBytes)
+	}
+}
+
+// WithLogger makes the environment log to the logger instead of the logger of the test.
+// Debug and PrintStackTrace don't apply to the logger
+func WithLogger(log *logger.Logger) Option {
+	return func(env *Solo) {
+		env.logger = log
+	}
+}
+
+// newKeyPair returns the next key pair derived from the seed of the environment or
+// the random key pair if the environment has no seed, see WithSeed
+func (env *Solo) newKeyPair() ed25519.KeyPair {
+	if env.seed == nil {
+		return ed25519.GenerateKeyPair()
+	}
+	return *env.seed.KeyPair(env.seedIndex.Inc() - 1)
+}
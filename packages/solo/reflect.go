@@ -0,0 +1,103 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iotaledger/wasp/contracts/native"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/vm/core"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/iotaledger/wasp/plugins/wasmtimevm"
+)
+
+// FuncDescribe is the optional view of the Wasm contract which describes parameters of its functions.
+// It returns one result per function: the key is the name of the function, the value is the string
+// with comma-separated names of its parameters
+const FuncDescribe = "describe"
+
+// ContractInterface is the interface of the contract deployed on the chain, see Chain.ContractInterface
+type ContractInterface struct {
+	Name        string
+	Hname       coretypes.Hname
+	Description string
+	// EntryPoints sorted by name
+	EntryPoints []coretypes.EntryPointInfo
+}
+
+// ContractInterface returns entry points of the contract deployed on the chain: their names,
+// kinds (view or full) and parameter hints.
+// Parameter hints are declared by native contracts with WithParams. Wasm contracts provide them
+// if they export the FuncDescribe view, otherwise only names and kinds of entry points are known
+func (ch *Chain) ContractInterface(hname coretypes.Hname) (*ContractInterface, error) {
+	_, contracts := ch.GetInfo()
+	rec, ok := contracts[hname]
+	if !ok {
+		return nil, fmt.Errorf("contract %s not found", hname)
+	}
+	proc, err := ch.getProcessorForReflection(rec)
+	if err != nil {
+		return nil, err
+	}
+	describer, ok := proc.(coretypes.ProcessorDescriber)
+	if !ok {
+		return nil, fmt.Errorf("processor of the contract '%s' can't describe its entry points", rec.Name)
+	}
+	ret := &ContractInterface{
+		Name:        rec.Name,
+		Hname:       hname,
+		Description: rec.Description,
+		EntryPoints: describer.EntryPoints(),
+	}
+	for i := range ret.EntryPoints {
+		if ret.EntryPoints[i].Name == FuncDescribe && ret.EntryPoints[i].IsView {
+			return ret, ch.applyDescribe(ret)
+		}
+	}
+	return ret, nil
+}
+
+// getProcessorForReflection returns the processor of the contract. The Wasm processor is created
+// anew and is not put into the processor cache of the chain, so it never runs requests
+func (ch *Chain) getProcessorForReflection(rec *root.ContractRecord) (coretypes.Processor, error) {
+	if proc, err := core.GetProcessor(rec.ProgramHash); err == nil {
+		return proc, nil
+	}
+	if proc, ok := native.GetProcessor(rec.ProgramHash); ok {
+		return proc, nil
+	}
+	binary, err := ch.GetWasmBinary(rec.ProgramHash)
+	if err != nil {
+		return nil, err
+	}
+	return processors.NewProcessorFromBinary(wasmtimevm.VMType, binary)
+}
+
+// applyDescribe calls the FuncDescribe view of the contract and sets parameter hints of entry points
+func (ch *Chain) applyDescribe(ci *ContractInterface) error {
+	res, err := ch.CallView(ci.Name, FuncDescribe)
+	if err != nil {
+		return fmt.Errorf("calling '%s' of the contract '%s': %v", FuncDescribe, ci.Name, err)
+	}
+	for i := range ci.EntryPoints {
+		s, ok, err := codec.DecodeString(res.MustGet(kv.Key(ci.EntryPoints[i].Name)))
+		if err != nil {
+			return err
+		}
+		if !ok || s == "" {
+			continue
+		}
+		hints := strings.Split(s, ",")
+		for j := range hints {
+			hints[j] = strings.TrimSpace(hints[j])
+		}
+		ci.EntryPoints[i].ParamHints = hints
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/stretchr/testify/require"
+)
+
+// ExportBlocks returns all blocks of the chain, from the origin block to the current state
+func (ch *Chain) ExportBlocks() []state.Block {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	ret := make([]state.Block, len(ch.blocks))
+	copy(ret, ch.blocks)
+	return ret
+}
+
+// SaveBlocks saves all blocks of the chain into the file in the same format as the
+// block export of the Wasp node ('wasp-cli chain export-blocks')
+func (ch *Chain) SaveBlocks(fname string) {
+	var buf bytes.Buffer
+	err := state.WriteBlocks(&buf, ch.ExportBlocks())
+	require.NoError(ch.Env.T, err)
+	err = ioutil.WriteFile(fname, buf.Bytes(), 0644)
+	require.NoError(ch.Env.T, err)
+}
+
+// ImportChain reconstructs the chain by replaying the blocks exported from a real Wasp chain or from
+// another Solo chain. Blocks must start with the origin block and follow each other.
+// The state updates of each request are logged with the request ID, so they can be inspected with
+// RequestTrace. The state of the chain at any index is available with StateHashAt and by importing
+// only the blocks up to the index.
+//
+// The imported chain is read-only: views can be called and the state can be inspected with
+// DumpContractState, however requests can't be posted to it because Solo doesn't own the chain address
+func (env *Solo) ImportChain(name string, blocks []state.Block) *Chain {
+	require.NotEmpty(env.T, blocks, "no blocks to import")
+	require.EqualValues(env.T, 0, blocks[0].StateIndex(), "the first block must be the origin block")
+
+	env.logger.Infof("importing chain '%s' from %d block(s)", name, len(blocks))
+	tracer := newRequestTracer()
+	captures := newLogCaptures()
+	ret := &Chain{
+		Env:          env,
+		Name:         name,
+		State:        state.NewVirtualState(mapdb.NewMapDB(), &coretypes.NilChainID),
+		proc:         processors.MustNew(),
+		Log:          captures.withCapture(tracer.withTracer(env.logger.Named(name))),
+		tracer:       tracer,
+		logCaptures:  captures,
+		stateHashes:  make([]hashing.HashValue, 0, len(blocks)),
+		blocks:       make([]state.Block, 0, len(blocks)),
+		imported:     true,
+		runVMMutex:   &sync.Mutex{},
+		chInRequest:  make(chan sctransaction.RequestRef),
+		backlog:      make([]sctransaction.RequestRef, 0),
+		backlogMutex: &sync.RWMutex{},
+		stop:         make(chan struct{}),
+	}
+	for _, b := range blocks {
+		ret.replayBlock(b)
+	}
+	info, _ := ret.GetInfo()
+	ret.ChainID = info.ChainID
+	ret.ChainColor = info.ChainColor
+	ret.ChainAddress = info.ChainAddress
+	ret.Log.Infof("chain '%s' imported. Chain ID: %s, state index: %d", ret.Name, ret.ChainID, ret.State.BlockIndex())
+	return ret
+}
+
+// ImportChainFromFile imports the chain from the file with exported blocks, see ImportChain
+func (env *Solo) ImportChainFromFile(name string, fname string) *Chain {
+	f, err := os.Open(fname)
+	require.NoError(env.T, err)
+	defer f.Close()
+
+	blocks, err := state.ReadBlocks(f)
+	require.NoError(env.T, err)
+	return env.ImportChain(name, blocks)
+}
+
+func (ch *Chain) replayBlock(block state.Block) {
+	err := ch.State.ApplyBlock(block)
+	require.NoError(ch.Env.T, err)
+	err = ch.State.CommitToDb(block)
+	require.NoError(ch.Env.T, err)
+
+	block.ForEach(func(_ uint16, su state.StateUpdate) bool {
+		if su.RequestID() == nil {
+			// origin block
+			return true
+		}
+		log := ch.Log.With(vm.LogFieldRequestID, su.RequestID().String())
+		numMut := 0
+		su.Mutations().Iterate(func(mut buffered.Mutation) bool {
+			numMut++
+			log.Debugf("replayed mutation: %s", mut.String())
+			return true
+		})
+		log.Infof("replayed request %s in block #%d: %d mutation(s)", su.RequestID().String(), block.StateIndex(), numMut)
+		return true
+	})
+	ch.stateHashes = append(ch.stateHashes, ch.State.Hash())
+	ch.blocks = append(ch.blocks, block)
+	ch.Log.Debugf("block #%d replayed. State hash: %s", block.StateIndex(), ch.State.Hash().String())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/vm"
+	"sort"
 	"time"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
@@ -75,6 +76,56 @@ func NewCallParamsOptimized(scName, funName string, optSize int, params ...inter
 	return ret, retOptimized
 }
 
+// NewCallParamsChecked creates call parameters like NewCallParams, checking the parameters against
+// the interface of the contract deployed on the chain, see requestargs.Builder.
+// Values bigger than the optimal size are uploaded to the registry and passed as blob references
+func (ch *Chain) NewCallParamsChecked(scName, funName string, params ...interface{}) (*CallParams, error) {
+	ci, err := ch.ContractInterface(coretypes.Hn(scName))
+	if err != nil {
+		return nil, err
+	}
+	var ep *coretypes.EntryPointInfo
+	for i := range ci.EntryPoints {
+		if ci.EntryPoints[i].Name == funName {
+			ep = &ci.EntryPoints[i]
+		}
+	}
+	if ep == nil {
+		return nil, fmt.Errorf("entry point '%s' not found in the contract '%s'", funName, scName)
+	}
+	b := requestargs.NewBuilder(ep)
+	par := toMap(params...)
+	keys := make([]string, 0, len(par))
+	for k := range par {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.Set(k, par[k])
+	}
+	args, err := b.Build(func(data []byte) error {
+		ch.Env.PutBlobDataIntoRegistry(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CallParams{
+		targetName: scName,
+		target:     coretypes.Hn(scName),
+		epName:     funName,
+		entryPoint: coretypes.Hn(funName),
+		args:       args,
+	}, nil
+}
+
+// WithTargetHname sends the request to the contract deployed under the hname instead of hname of the name,
+// e.g. to the contract deployed under an alias
+func (r *CallParams) WithTargetHname(hname coretypes.Hname) *CallParams {
+	r.target = hname
+	return r
+}
+
 // WithTransfer is a shorthand for the most often used case where only
 // a single color is transferred by WithTransfers
 func (r *CallParams) WithTransfer(color balance.Color, amount int64) *CallParams {
@@ -125,6 +176,15 @@ func (ch *Chain) RequestFromParamsToLedger(req *CallParams, sigScheme signatures
 	ch.Env.ledgerMutex.Lock()
 	defer ch.Env.ledgerMutex.Unlock()
 
+	tx := ch.requestFromParams(req, sigScheme)
+	err := ch.Env.confirmTransaction(tx)
+	require.NoError(ch.Env.T, err)
+	return tx
+}
+
+// requestFromParams creates signed transaction with one request based on parameters and sigScheme,
+// without adding it to the ledger. The ledger mutex must be locked by the caller
+func (ch *Chain) requestFromParams(req *CallParams, sigScheme signaturescheme.SignatureScheme) *sctransaction.Transaction {
 	if sigScheme == nil {
 		sigScheme = ch.OriginatorSigScheme
 	}
@@ -141,6 +201,9 @@ func (ch *Chain) RequestFromParamsToLedger(req *CallParams, sigScheme signatures
 
 	txb.AddMinting(req.mint)
 
+	err = ch.Env.addTransactionFee(txb.Builder)
+	require.NoError(ch.Env.T, err)
+
 	tx, err := txb.Build(false)
 	require.NoError(ch.Env.T, err)
 
@@ -149,11 +212,64 @@ func (ch *Chain) RequestFromParamsToLedger(req *CallParams, sigScheme signatures
 	_, err = tx.Properties()
 	require.NoError(ch.Env.T, err)
 
-	err = ch.Env.AddToLedger(tx)
+	err = ch.Env.checkDust(tx.Transaction)
 	require.NoError(ch.Env.T, err)
 	return tx
 }
 
+// ChainRequest is a request to a particular chain, used to build transactions
+// with requests to several chains
+type ChainRequest struct {
+	Chain  *Chain
+	Params *CallParams
+}
+
+// PostRequestsMultiChain creates one transaction with requests to several chains and adds it
+// to the ledger. All requests are confirmed atomically.
+// The requests are then dispatched to backlogs of respective chains and processed asynchronously,
+// therefore the test should wait for completion with WaitForEmptyBacklog on each target chain.
+// The sigScheme is used to sign the transaction, if nil, the originator of the first chain is used
+func (env *Solo) PostRequestsMultiChain(sigScheme signaturescheme.SignatureScheme, reqs ...ChainRequest) *sctransaction.Transaction {
+	require.True(env.T, len(reqs) > 0)
+	if sigScheme == nil {
+		sigScheme = reqs[0].Chain.OriginatorSigScheme
+	}
+	env.ledgerMutex.Lock()
+	allOuts := env.utxoDB.GetAddressOutputs(sigScheme.Address())
+	txb, err := txbuilder.NewFromOutputBalances(allOuts)
+	require.NoError(env.T, err)
+
+	sections := make([]*sctransaction.RequestSection, len(reqs))
+	for i, r := range reqs {
+		sections[i] = sctransaction.NewRequestSectionByWallet(coretypes.NewContractID(r.Chain.ChainID, r.Params.target), r.Params.entryPoint).
+			WithTransfer(r.Params.transfer).
+			WithArgs(r.Params.args)
+		txb.AddMinting(r.Params.mint)
+	}
+	err = txb.AddRequestSections(sections...)
+	require.NoError(env.T, err)
+
+	err = env.addTransactionFee(txb.Builder)
+	require.NoError(env.T, err)
+
+	tx, err := txb.Build(false)
+	require.NoError(env.T, err)
+
+	tx.Sign(sigScheme)
+
+	_, err = tx.Properties()
+	require.NoError(env.T, err)
+
+	err = env.AddToLedger(tx)
+	env.ledgerMutex.Unlock()
+	require.NoError(env.T, err)
+
+	env.logger.Infof("PostRequestsMultiChain: %d request(s) to %d chain(s) in tx %s",
+		len(reqs), len(tx.RequestsByChain()), tx.ID().String())
+	env.EnqueueRequests(tx)
+	return tx
+}
+
 // PostRequestSync posts a request synchronously  sent by the test program to the smart contract on the same or another chain:
 //  - creates a request transaction with the request block on it. The sigScheme is used to
 //    sign the inputs of the transaction or OriginatorSigScheme is used if parameter is nil
@@ -178,7 +294,7 @@ func (ch *Chain) PostRequestSyncTx(req *CallParams, sigScheme signaturescheme.Si
 	tx := ch.RequestFromParamsToLedger(req, sigScheme)
 
 	reqID := coretypes.NewRequestID(tx.ID(), 0)
-	ch.Log.Infof("PostRequestSync: %s::%s -- %s", req.targetName, req.epName, reqID.String())
+	ch.Log.With(vm.LogFieldRequestID, reqID.String()).Infof("PostRequestSync: %s::%s -- %s", req.targetName, req.epName, reqID.String())
 
 	r := vm.RequestRefWithFreeTokens{}
 	r.Tx = tx
@@ -197,6 +313,9 @@ func (ch *Chain) callViewFull(req *CallParams) (dict.Dict, error) {
 	defer ch.runVMMutex.Unlock()
 
 	vctx := viewcontext.New(ch.ChainID, ch.State.Variables(), ch.State.Timestamp(), ch.proc, ch.Log)
+	if ch.viewLimits != (viewcontext.Limits{}) {
+		vctx = vctx.WithLimits(ch.viewLimits)
+	}
 	a, ok, err := req.args.SolidifyRequestArguments(ch.Env.registry)
 	if err != nil || !ok {
 		return nil, fmt.Errorf("solo.internal error: can't solidify args")
@@ -216,9 +335,40 @@ func (ch *Chain) CallView(scName string, funName string, params ...interface{})
 	defer ch.runVMMutex.Unlock()
 
 	vctx := viewcontext.New(ch.ChainID, ch.State.Variables(), ch.State.Timestamp(), ch.proc, ch.Log)
+	if ch.viewLimits != (viewcontext.Limits{}) {
+		vctx = vctx.WithLimits(ch.viewLimits)
+	}
 	return vctx.CallView(coretypes.Hn(scName), coretypes.Hn(funName), p)
 }
 
+// CallViewByHname calls the view entry point of the smart contract with parameters already
+// encoded into the dictionary, as they come from the web API
+func (ch *Chain) CallViewByHname(contract, entryPoint coretypes.Hname, params dict.Dict) (dict.Dict, error) {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	vctx := viewcontext.New(ch.ChainID, ch.State.Variables(), ch.State.Timestamp(), ch.proc, ch.Log)
+	if ch.viewLimits != (viewcontext.Limits{}) {
+		vctx = vctx.WithLimits(ch.viewLimits)
+	}
+	return vctx.CallView(contract, entryPoint, params)
+}
+
+// IsRequestProcessed returns true if the request is included in one of the blocks of the chain
+func (ch *Chain) IsRequestProcessed(reqID coretypes.RequestID) bool {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	for _, b := range ch.blocks {
+		for _, rid := range b.RequestIDs() {
+			if *rid == reqID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // WaitForEmptyBacklog waits until the backlog queue of the chain becomes empty.
 // It is useful when smart contract(s) in the test are posting asynchronous requests
 // between chains.
@@ -25,12 +25,22 @@ func (ch *Chain) validateBatch(batch []vm.RequestRefWithFreeTokens) {
 	}
 }
 
+// runBatch runs the batch of requests and settles the state transition, then evaluates invariants of the chain
 func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (dict.Dict, error) {
+	callRes, callErr := ch.runVM(batch, trace)
+	ch.recordBatch(batch, trace, callErr)
+	ch.checkInvariants(trace)
+	return callRes, callErr
+}
+
+func (ch *Chain) runVM(batch []vm.RequestRefWithFreeTokens, trace string) (dict.Dict, error) {
 	ch.Log.Debugf("runBatch ('%s')", trace)
 
 	ch.runVMMutex.Lock()
 	defer ch.runVMMutex.Unlock()
 
+	require.False(ch.Env.T, ch.imported, "can't run requests on the imported chain")
+	require.False(ch.Env.T, ch.stopped, "can't run requests on the chain which is shut down")
 	ch.validateBatch(batch)
 
 	// solidify arguments
@@ -40,19 +50,26 @@ func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (di
 		}
 	}
 
+	// the committee signs the seed of the block, like in the consensus round
+	ts := ch.Env.LogicalTime().UnixNano()
+	blockIndex := ch.State.BlockIndex() + 1
+	seed := coretypes.VRFSeed(ch.State.Hash(), blockIndex, ts)
+	vrf, err := coretypes.NewVRF(ch.State.Hash(), blockIndex, ts, ch.vrfCommittee.signData(ch.Env, seed, blockIndex))
+	require.NoError(ch.Env.T, err)
+
 	task := &vm.VMTask{
 		Processors:         ch.proc,
 		ChainID:            ch.ChainID,
 		Color:              ch.ChainColor,
 		Entropy:            hashing.RandomHash(nil),
+		VRF:                vrf,
 		ValidatorFeeTarget: ch.ValidatorFeeTarget,
 		Balances:           waspconn.OutputsToBalances(ch.Env.utxoDB.GetAddressOutputs(ch.ChainAddress)),
 		Requests:           batch,
-		Timestamp:          ch.Env.LogicalTime().UnixNano(),
+		Timestamp:          ts,
 		VirtualState:       ch.State.Clone(),
 		Log:                ch.Log,
 	}
-	var err error
 	var wg sync.WaitGroup
 	var callRes dict.Dict
 	var callErr error
@@ -69,7 +86,11 @@ func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (di
 	require.NoError(ch.Env.T, err)
 
 	wg.Wait()
-	task.ResultTransaction.Sign(ch.ChainSigScheme)
+	if ch.Committee != nil {
+		ch.Committee.sign(ch.Env, task.ResultTransaction, ch.State.BlockIndex()+1)
+	} else {
+		task.ResultTransaction.Sign(ch.ChainSigScheme)
+	}
 
 	// check semantic validity of the transaction
 	_, err = task.ResultTransaction.Properties()
@@ -80,7 +101,7 @@ func (ch *Chain) runBatch(batch []vm.RequestRefWithFreeTokens, trace string) (di
 }
 
 func (ch *Chain) settleStateTransition(newState state.VirtualState, block state.Block, stateTx *sctransaction.Transaction) {
-	err := ch.Env.AddToLedger(stateTx)
+	err := ch.Env.confirmTransaction(stateTx)
 	require.NoError(ch.Env.T, err)
 
 	err = newState.ApplyBlock(block)
@@ -93,6 +114,9 @@ func (ch *Chain) settleStateTransition(newState state.VirtualState, block state.
 
 	ch.StateTx = stateTx
 	ch.State = newState
+	ch.stateHashes = append(ch.stateHashes, newState.Hash())
+	ch.blocks = append(ch.blocks, block)
+	require.EqualValues(ch.Env.T, len(ch.stateHashes)-1, newState.BlockIndex())
 
 	ch.Log.Infof("state transition #%d --> #%d. Requests in the block: %d. Posted: %d",
 		prevBlockIndex, ch.State.BlockIndex(), len(block.RequestIDs()), len(ch.StateTx.Requests()))
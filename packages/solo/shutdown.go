@@ -0,0 +1,105 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/goleak"
+)
+
+// ShutdownTimeout is the maximum time Chain.Shutdown and Solo.Close wait for the goroutines of the chain
+// or of the environment to stop, before they are reported as leaked
+const ShutdownTimeout = 5 * time.Second
+
+// startBacklogLoops starts the goroutines which process the backlog of the chain. They are stopped by Shutdown
+func (ch *Chain) startBacklogLoops() {
+	ch.loops.Add(2)
+	go func() {
+		defer ch.loops.Done()
+		ch.readRequestsLoop()
+	}()
+	go func() {
+		defer ch.loops.Done()
+		ch.batchLoop()
+	}()
+}
+
+// Shutdown stops the backlog processing of the chain and waits until the batch in progress, if any, is settled.
+// Requests remaining in the backlog are not processed and requests sent to the chain afterwards are ignored.
+// The state of the chain can still be queried with views, but requests can't be run on it anymore.
+// The test fails if goroutines of the chain don't stop within ShutdownTimeout.
+// Shutdown may be called more than once. Chains still running are shut down by Solo.Close
+func (ch *Chain) Shutdown() {
+	ch.shutdownOnce.Do(ch.shutdown)
+}
+
+func (ch *Chain) shutdown() {
+	env := ch.Env
+	// after the chain is removed from the environment no more requests are sent to its channel
+	env.glbMutex.Lock()
+	if env.chains[ch.ChainID] == ch {
+		delete(env.chains, ch.ChainID)
+	}
+	env.glbMutex.Unlock()
+
+	close(ch.chInRequest)
+	close(ch.stop)
+	if !waitTimeout(&ch.loops, ShutdownTimeout) {
+		env.T.Errorf("chain '%s': backlog goroutines didn't stop in %v: %v", ch.Name, ShutdownTimeout, goleak.Find())
+	}
+
+	// flush: the batch run synchronously, e.g. by PostRequestSync, is settled before the chain is marked as stopped
+	ch.runVMMutex.Lock()
+	ch.stopped = true
+	ch.runVMMutex.Unlock()
+
+	ch.backlogMutex.RLock()
+	backlog := len(ch.backlog)
+	ch.backlogMutex.RUnlock()
+	if backlog > 0 {
+		ch.Log.Warnf("chain '%s' shut down with %d request(s) in the backlog", ch.Name, backlog)
+	}
+	ch.Log.Infof("chain '%s' shut down at state index %d", ch.Name, ch.State.BlockIndex())
+}
+
+// Close shuts down all chains of the environment (see Chain.Shutdown) and stops the wall clock (see WithWallClock).
+// The test fails if goroutines of the environment don't stop within ShutdownTimeout, so tests leaking them are
+// detected. Close is called automatically when the test finishes. It may be called more than once
+func (env *Solo) Close() {
+	env.closeOnce.Do(env.close)
+}
+
+func (env *Solo) close() {
+	env.glbMutex.RLock()
+	chains := make([]*Chain, 0, len(env.chains))
+	for _, ch := range env.chains {
+		chains = append(chains, ch)
+	}
+	env.glbMutex.RUnlock()
+
+	for _, ch := range chains {
+		ch.Shutdown()
+	}
+	close(env.stop)
+	if !waitTimeout(&env.loops, ShutdownTimeout) {
+		env.T.Errorf("solo: goroutines of the environment didn't stop in %v: %v", ShutdownTimeout, goleak.Find())
+	}
+}
+
+// waitTimeout waits for the wait group and returns false if it didn't finish within the timeout
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
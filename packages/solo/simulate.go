@@ -0,0 +1,43 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/simulate"
+	"github.com/stretchr/testify/require"
+)
+
+// SimulateRequest runs the request against the current state of the chain, the same way as
+// the simulation endpoint of the web API, without adding the request to the ledger and without
+// changing the state. The outcome contains the result, emitted events, fees and the state diff.
+// The sigScheme is used as in PostRequestSync
+func (ch *Chain) SimulateRequest(req *CallParams, sigScheme signaturescheme.SignatureScheme) (*simulate.Result, error) {
+	ch.Env.ledgerMutex.Lock()
+	tx := ch.requestFromParams(req, sigScheme)
+	ch.Env.ledgerMutex.Unlock()
+
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	require.False(ch.Env.T, ch.imported, "can't run requests on the imported chain")
+	ok, err := tx.Requests()[0].SolidifyArgs(ch.Env.registry)
+	require.NoError(ch.Env.T, err)
+	require.True(ch.Env.T, ok)
+
+	ch.Log.Infof("SimulateRequest: %s::%s", req.targetName, req.epName)
+	return simulate.Run(&simulate.Params{
+		ChainID:            ch.ChainID,
+		ChainColor:         ch.ChainColor,
+		Processors:         ch.proc,
+		ValidatorFeeTarget: ch.ValidatorFeeTarget,
+		State:              ch.State,
+		Balances:           waspconn.OutputsToBalances(ch.Env.utxoDB.GetAddressOutputs(ch.ChainAddress)),
+		Request:            sctransaction.RequestRef{Tx: tx, Index: 0},
+		Timestamp:          ch.Env.LogicalTime().UnixNano(),
+		Log:                ch.Log,
+	})
+}
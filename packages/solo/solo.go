@@ -9,15 +9,16 @@ import (
 	"testing"
 	"time"
 
+	"github.com/iotaledger/goshimmer/client/wallet/packages/seed"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/utxodb"
-	"github.com/iotaledger/hive.go/crypto/ed25519"
 	"github.com/iotaledger/hive.go/kvstore/mapdb"
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/dbprovider"
+	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/sctransaction/origin"
@@ -27,6 +28,7 @@ import (
 	"github.com/iotaledger/wasp/packages/vm"
 	"github.com/iotaledger/wasp/packages/vm/processors"
 	_ "github.com/iotaledger/wasp/packages/vm/sandbox"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
 	"github.com/iotaledger/wasp/packages/vm/wasmproc"
 	"github.com/iotaledger/wasp/plugins/wasmtimevm"
 	"github.com/stretchr/testify/require"
@@ -52,8 +54,39 @@ type Solo struct {
 	clockMutex  *sync.RWMutex
 	logicalTime time.Time
 	timeStep    time.Duration
+	// the logical clock follows the real time, see WithWallClock
+	wallClock   bool
+	speedUp     float64
+	wallClockAt time.Time
 	chains      map[coretypes.ChainID]*Chain
 	doOnce      sync.Once
+	// simulation of the L1 confirmation latency, see SetConfirmationDelay
+	pendingMutex      *sync.Mutex
+	confirmationDelay int
+	ticks             int
+	pending           []*pendingTx
+	// size and quorum of the BLS committee of chains, see WithBLSCommittee. 0 means ED25519 chain addresses
+	blsN uint16
+	blsT uint16
+	// economics of the ledger, see WithLedgerParams
+	ledgerParams LedgerParams
+	feeAddress   address.Address
+	// callbacks of the ledger, see OnLedgerConfirm
+	ledgerHooks ledgerHooks
+	// goroutines of the environment, stopped by Close
+	loops     sync.WaitGroup
+	stop      chan struct{}
+	closeOnce sync.Once
+	// logging parameters, see Debug, PrintStackTrace and WithLogger
+	debug           bool
+	printStackTrace bool
+	// source of deterministic keys, see WithSeed. nil means random keys
+	seed      *seed.Seed
+	seedIndex atomic.Uint64
+	// directory of the persistent blob cache, see WithPersistentBlobCache
+	blobCacheDir string
+	// chains are not dumped when the test fails, see NoFailureDump
+	noFailureDump bool
 }
 
 // Chain represents state of individual chain.
@@ -66,9 +99,14 @@ type Chain struct {
 	Name string
 
 	// ChainSigScheme signature scheme of the chain address, the one used to control funds owned by the chain.
-	// In Solo it is Ed25519 signature scheme (in full Wasp environment is is a BLS address)
+	// By default in Solo it is Ed25519 signature scheme (in full Wasp environment is is a BLS address).
+	// It is nil if the chain is controlled by the BLS committee, see WithBLSCommittee
 	ChainSigScheme signaturescheme.SignatureScheme
 
+	// Committee is the emulated committee sharing the BLS key of the chain address.
+	// It is nil unless the environment is created with the WithBLSCommittee option
+	Committee *BLSCommittee
+
 	// OriginatorSigScheme the signature scheme used to create the chain (origin transaction).
 	// It is a default signature scheme in many of 'solo' calls which require private key.
 	OriginatorSigScheme signaturescheme.SignatureScheme
@@ -104,12 +142,46 @@ type Chain struct {
 	// processor cache
 	proc *processors.ProcessorCache
 
+	// signs the VRF of blocks, see VRFAddress
+	vrfCommittee *BLSCommittee
+
+	// collects log entries per request ID
+	tracer *requestTracer
+
+	// active captures of log entries, see CaptureLogs
+	logCaptures *logCaptures
+
+	// hashes of all states of the chain, indexed by block index
+	stateHashes []hashing.HashValue
+
+	// all blocks of the chain, indexed by block index, see ExportBlocks
+	blocks []state.Block
+	// true if the chain was reconstructed from blocks of another chain, see ImportChain
+	imported bool
+	// colors of the original chain replaced in the clone, see Clone
+	clonedColors map[balance.Color]balance.Color
+
+	// limits of view calls, see SetViewLimits
+	viewLimits viewcontext.Limits
+
+	// evaluated after each batch, see AddInvariant
+	invariants []Invariant
+
 	// related to asynchronous backlog processing
 	runVMMutex   *sync.Mutex
 	reqCounter   atomic.Int32
 	chInRequest  chan sctransaction.RequestRef
 	backlog      []sctransaction.RequestRef
 	backlogMutex *sync.RWMutex
+	// backlog processing is stopped by Shutdown
+	loops        sync.WaitGroup
+	stop         chan struct{}
+	shutdownOnce sync.Once
+	stopped      bool
+
+	// the last batch run by the chain, see Dump
+	lastBatch      *batchRecord
+	lastBatchMutex sync.Mutex
 }
 
 var (
@@ -120,82 +192,133 @@ var (
 // New creates an instance of the `solo` environment for the test instances.
 //   'debug' parameter 'true' means logging level is 'debug', otherwise 'info'
 //   'printStackTrace' controls printing stack trace in case of errors
-func New(t *testing.T, debug bool, printStackTrace bool) *Solo {
+//   'opts' are optional parameters of the environment, for example WithBLSCommittee
+// The environment is closed when the test finishes, see Close
+func New(t *testing.T, debug bool, printStackTrace bool, opts ...Option) *Solo {
+	flags := func(env *Solo) {
+		env.debug = debug
+		env.printStackTrace = printStackTrace
+	}
+	return NewWithOptions(t, append([]Option{flags}, opts...)...)
+}
+
+// NewWithOptions creates an instance of the `solo` environment configured only with options,
+// for example Debug, WithTimeStep or WithSeed.
+// By default the logging level is 'info', the step of the logical clock is DefaultTimeStep and keys are random.
+// The environment is closed when the test finishes, see Close. If the test has failed, the dump of all chains
+// is logged before, see Dump
+func NewWithOptions(t *testing.T, opts ...Option) *Solo {
+	ret := &Solo{
+		T:            t,
+		utxoDB:       utxodb.New(),
+		glbMutex:     &sync.RWMutex{},
+		clockMutex:   &sync.RWMutex{},
+		ledgerMutex:  &sync.RWMutex{},
+		logicalTime:  time.Now(),
+		timeStep:     DefaultTimeStep,
+		chains:       make(map[coretypes.ChainID]*Chain),
+		pendingMutex: &sync.Mutex{},
+		pending:      make([]*pendingTx, 0),
+		ledgerParams: DefaultLedgerParams(),
+		stop:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(ret)
+	}
 	doOnce.Do(func() {
 		glbLogger = testutil.NewLogger(t, "04:05.000")
-		if !debug {
-			glbLogger = testutil.WithLevel(glbLogger, zapcore.InfoLevel, printStackTrace)
+		if !ret.debug {
+			glbLogger = testutil.WithLevel(glbLogger, zapcore.InfoLevel, ret.printStackTrace)
 		}
 		wasmtimeConstructor := func(binary []byte) (coretypes.Processor, error) {
 			return wasmproc.GetProcessor(binary, glbLogger)
 		}
 		err := processors.RegisterVMType(wasmtimevm.VMType, wasmtimeConstructor)
 		require.NoError(t, err)
+		err = processors.RegisterBinaryFilter(wasmtimevm.VMType, wasmproc.PrepareBinary)
+		require.NoError(t, err)
 	})
-	reg := registry.NewRegistry(nil, glbLogger.Named("registry"), dbprovider.NewInMemoryDBProvider(glbLogger))
-	ret := &Solo{
-		T:           t,
-		logger:      glbLogger,
-		utxoDB:      utxodb.New(),
-		registry:    reg,
-		glbMutex:    &sync.RWMutex{},
-		clockMutex:  &sync.RWMutex{},
-		ledgerMutex: &sync.RWMutex{},
-		logicalTime: time.Now(),
-		timeStep:    DefaultTimeStep,
-		chains:      make(map[coretypes.ChainID]*Chain),
+	if ret.logger == nil {
+		ret.logger = glbLogger
+	}
+	if ret.blobCacheDir != "" {
+		ret.registry = newPersistentBlobCache(ret.blobCacheDir, ret.logger)
+	} else {
+		ret.registry = registry.NewRegistry(nil, ret.logger.Named("registry"), dbprovider.NewInMemoryDBProvider(ret.logger))
 	}
+	ret.feeAddress = signaturescheme.ED25519(ret.newKeyPair()).Address()
+	t.Cleanup(ret.Close)
+	// cleanups run in reverse order, so chains are dumped before the environment is closed
+	t.Cleanup(ret.dumpOnFailure)
 	return ret
 }
 
 // NewChain deploys new chain instance.
 //
-// If 'chainOriginator' is nil, new one is generated and solo.Saldo (=1337) iotas are loaded from the UTXODB faucet.
+// If 'chainOriginator' is nil, new one is generated and LedgerParams().Saldo iotas (by default solo.Saldo = 1337)
+// are loaded from the UTXODB faucet.
 // If 'validatorFeeTarget' is skipped, it is assumed equal to OriginatorAgentID
 // To deploy the chai instance the following steps are performed:
-//  - chain signature scheme (private key), chain address and chain ID are created
+//  - chain signature scheme (private key), chain address and chain ID are created.
+//    With the WithBLSCommittee option key shares of the BLS address are created instead
 //  - empty virtual state is initialized
 //  - origin transaction is created by the originator and added to the UTXODB
 //  - 'init' request transaction to the 'root' contract is created and added to UTXODB
-//  - backlog processing threads (goroutines) are started
+//  - backlog processing threads (goroutines) are started. They are stopped by Shutdown
 //  - VM processor cache is initialized
 //  - 'init' request is run by the VM. The 'root' contracts deploys the rest of the core contracts:
 //    'blob', 'accountsc', 'chainlog'
 // Upon return, the chain is fully functional to process requests
 func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name string, validatorFeeTarget ...coretypes.AgentID) *Chain {
 	env.logger.Infof("deploying new chain '%s'", name)
-	chSig := signaturescheme.ED25519(ed25519.GenerateKeyPair()) // chain address will be ED25519, not BLS
+	var chSig signaturescheme.SignatureScheme
+	var committee *BLSCommittee
+	var chainAddress address.Address
+	if env.blsN > 0 {
+		committee = newBLSCommittee(env, env.blsN, env.blsT)
+		chainAddress = committee.Address
+	} else {
+		chSig = signaturescheme.ED25519(env.newKeyPair()) // chain address will be ED25519, not BLS
+		chainAddress = chSig.Address()
+	}
 	if chainOriginator == nil {
-		chainOriginator = signaturescheme.ED25519(ed25519.GenerateKeyPair())
-		_, err := env.utxoDB.RequestFunds(chainOriginator.Address())
+		chainOriginator = signaturescheme.ED25519(env.newKeyPair())
+		err := env.requestFunds(chainOriginator.Address())
 		require.NoError(env.T, err)
 	}
-	chainID := coretypes.ChainID(chSig.Address())
+	chainID := coretypes.ChainID(chainAddress)
 	originatorAgentID := coretypes.NewAgentIDFromAddress(chainOriginator.Address())
 	feeTarget := originatorAgentID
 	if len(validatorFeeTarget) > 0 {
 		feeTarget = validatorFeeTarget[0]
 	}
+	tracer := newRequestTracer()
+	captures := newLogCaptures()
 	ret := &Chain{
 		Env:                 env,
 		Name:                name,
 		ChainSigScheme:      chSig,
+		Committee:           committee,
+		vrfCommittee:        newVRFCommittee(env, committee),
 		OriginatorSigScheme: chainOriginator,
-		ChainAddress:        chSig.Address(),
+		ChainAddress:        chainAddress,
 		OriginatorAddress:   chainOriginator.Address(),
 		OriginatorAgentID:   originatorAgentID,
 		ValidatorFeeTarget:  feeTarget,
 		ChainID:             chainID,
 		State:               state.NewVirtualState(mapdb.NewMapDB(), &chainID),
 		proc:                processors.MustNew(),
-		Log:                 env.logger.Named(name),
+		Log:                 captures.withCapture(tracer.withTracer(env.logger.Named(name))),
+		tracer:              tracer,
+		logCaptures:         captures,
 		//
 		runVMMutex:   &sync.Mutex{},
 		chInRequest:  make(chan sctransaction.RequestRef),
 		backlog:      make([]sctransaction.RequestRef, 0),
 		backlogMutex: &sync.RWMutex{},
+		stop:         make(chan struct{}),
 	}
-	env.AssertAddressBalance(ret.OriginatorAddress, balance.ColorIOTA, testutil.RequestFundsAmount)
+	env.AssertAddressBalance(ret.OriginatorAddress, balance.ColorIOTA, env.ledgerParams.Saldo)
 	var err error
 	ret.StateTx, err = origin.NewOriginTransaction(origin.NewOriginTransactionParams{
 		OriginAddress:             ret.ChainAddress,
@@ -204,7 +327,7 @@ func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name
 	})
 	require.NoError(env.T, err)
 	require.NotNil(env.T, ret.StateTx)
-	err = env.utxoDB.AddTransaction(ret.StateTx.Transaction)
+	err = env.confirmTransaction(ret.StateTx)
 	require.NoError(env.T, err)
 
 	ret.ChainColor = balance.Color(ret.StateTx.ID())
@@ -214,6 +337,8 @@ func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name
 	require.NoError(env.T, err)
 	err = ret.State.CommitToDb(originBlock)
 	require.NoError(env.T, err)
+	ret.stateHashes = []hashing.HashValue{ret.State.Hash()}
+	ret.blocks = []state.Block{originBlock}
 
 	initTx, err := origin.NewRootInitRequestTransaction(origin.NewRootInitRequestTransactionParams{
 		ChainID:              chainID,
@@ -226,15 +351,14 @@ func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name
 	require.NoError(env.T, err)
 	require.NotNil(env.T, initTx)
 
-	err = env.utxoDB.AddTransaction(initTx.Transaction)
+	err = env.confirmTransaction(initTx)
 	require.NoError(env.T, err)
 
 	env.glbMutex.Lock()
 	env.chains[chainID] = ret
 	env.glbMutex.Unlock()
 
-	go ret.readRequestsLoop()
-	go ret.batchLoop()
+	ret.startBacklogLoops()
 
 	r := vm.RequestRefWithFreeTokens{}
 	r.Tx = initTx
@@ -247,25 +371,37 @@ func (env *Solo) NewChain(chainOriginator signaturescheme.SignatureScheme, name
 }
 
 // AddToLedger adds (synchronously confirms) transaction to the UTXODB ledger. Return error if it is
-// invalid or double spend
+// invalid or double spend.
+// If the confirmation delay is set with SetConfirmationDelay, the transaction is pending instead and
+// is added to the UTXODB ledger only after the delay.
+// The transaction must follow the dust rule of the ledger, see WithLedgerParams
 func (env *Solo) AddToLedger(tx *sctransaction.Transaction) error {
-	return env.utxoDB.AddTransaction(tx.Transaction)
+	if err := env.checkDust(tx.Transaction); err != nil {
+		return err
+	}
+	if env.addToLedgerPending(tx) {
+		return nil
+	}
+	return env.confirmTransaction(tx)
 }
 
-// EnqueueRequests dispatches requests contained in the transaction among chains
+// confirmTransaction adds transaction to the UTXODB ledger bypassing the confirmation delay
+func (env *Solo) confirmTransaction(tx *sctransaction.Transaction) error {
+	if err := env.utxoDB.AddTransaction(tx.Transaction); err != nil {
+		return err
+	}
+	env.notifyConfirmed(tx)
+	return nil
+}
+
+// EnqueueRequests dispatches requests contained in the transaction among chains.
+// Requests of the pending transaction are dispatched when the transaction is confirmed
 func (env *Solo) EnqueueRequests(tx *sctransaction.Transaction) {
-	reqRefByChain := make(map[coretypes.ChainID][]sctransaction.RequestRef)
-	for i, rsect := range tx.Requests() {
-		chid := rsect.Target().ChainID()
-		_, ok := reqRefByChain[chid]
-		if !ok {
-			reqRefByChain[chid] = make([]sctransaction.RequestRef, 0)
-		}
-		reqRefByChain[chid] = append(reqRefByChain[chid], sctransaction.RequestRef{
-			Tx:    tx,
-			Index: uint16(i),
-		})
+	if env.enqueueOnConfirmation(tx) {
+		return
 	}
+	reqRefByChain := tx.RequestsByChain()
+
 	env.glbMutex.RLock()
 	defer env.glbMutex.RUnlock()
 
@@ -293,11 +429,12 @@ func (ch *Chain) addToBacklog(r sctransaction.RequestRef) {
 	defer ch.backlogMutex.Unlock()
 	ch.backlog = append(ch.backlog, r)
 	tl := r.RequestSection().Timelock()
+	log := ch.Log.With(vm.LogFieldRequestID, r.RequestID().String())
 	if tl == 0 {
-		ch.Log.Infof("added to backlog: %s len: %d", r.RequestID().String(), len(ch.backlog))
+		log.Infof("added to backlog: %s len: %d", r.RequestID().String(), len(ch.backlog))
 	} else {
 		tlTime := time.Unix(int64(tl), 0)
-		ch.Log.Infof("added to backlog: %s. Time locked for: %v",
+		log.Infof("added to backlog: %s. Time locked for: %v",
 			r.RequestID().Short(), tlTime.Sub(ch.Env.LogicalTime()))
 	}
 }
@@ -328,6 +465,11 @@ func (ch *Chain) collateBatch() []vm.RequestRefWithFreeTokens {
 // batchLoop mimics leader's behavior in the Wasp committee
 func (ch *Chain) batchLoop() {
 	for {
+		select {
+		case <-ch.stop:
+			return
+		default:
+		}
 		batch := ch.collateBatch()
 		if len(batch) > 0 {
 			_, err := ch.runBatch(batch, "batchLoop")
@@ -336,7 +478,11 @@ func (ch *Chain) batchLoop() {
 			}
 			continue
 		}
-		time.Sleep(50 * time.Millisecond)
+		select {
+		case <-ch.stop:
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
 	}
 }
 
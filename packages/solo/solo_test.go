@@ -1,10 +1,36 @@
 package solo
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/sctransaction/txbuilder"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/zap/zapcore"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPutBlobData(t *testing.T) {
@@ -23,3 +49,784 @@ func TestPutBlobData(t *testing.T) {
 	require.Len(env.T, sargs, 1)
 	require.EqualValues(env.T, data, sargs.MustGet("dataName"))
 }
+
+func TestRequestTrace(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	tx, _, err := chain.PostRequestSyncTx(req, nil)
+	require.NoError(t, err)
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+
+	trace := chain.RequestTrace(reqID)
+	require.NotEmpty(t, trace)
+	for _, e := range trace {
+		_, ok := e.Fields[vm.LogFieldRequestID]
+		require.False(t, ok)
+	}
+	s := chain.RequestTraceString(reqID)
+	t.Logf("trace:\n%s", s)
+	require.Contains(t, s, "PostRequestSync: accounts::deposit")
+	require.Contains(t, s, "[req] "+reqID.String()+": Ok")
+
+	require.Empty(t, chain.RequestTrace(coretypes.NewRequestID(tx.ID(), 1)))
+}
+
+func TestCaptureLogs(t *testing.T) {
+	env := New(t, false, false)
+	chain1 := env.NewChain(nil, "chain1")
+	chain2 := env.NewChain(nil, "chain2")
+
+	capture := chain1.CaptureLogs()
+	req := NewCallParams("dummy", "dummy")
+	_, err := chain1.PostRequestSync(req, nil)
+	require.Error(t, err)
+	_, err = chain2.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	require.NotEmpty(t, capture.Entries())
+	require.True(t, capture.Contains("does not exist", zapcore.ErrorLevel))
+	require.Len(t, capture.Search("does not exist", zapcore.ErrorLevel), 1)
+	require.NotEmpty(t, capture.Errors())
+	for _, e := range capture.Entries() {
+		require.True(t, strings.Contains(e.Logger, "chain1"))
+	}
+	for _, e := range capture.Warnings() {
+		require.True(t, e.Level >= zapcore.WarnLevel)
+	}
+
+	capture.Stop()
+	_, err = chain1.PostRequestSync(req, nil)
+	require.Error(t, err)
+	require.Len(t, capture.Search("does not exist", zapcore.ErrorLevel), 1)
+	capture.Reset()
+	require.Empty(t, capture.Entries())
+}
+
+func TestStateHashAt(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	h0, ok := chain.StateHashAt(0)
+	require.True(t, ok)
+	h1, ok := chain.StateHashAt(1)
+	require.True(t, ok)
+	require.NotEqualValues(t, h0, h1)
+	require.EqualValues(t, chain.State.Hash(), h1)
+	_, ok = chain.StateHashAt(2)
+	require.False(t, ok)
+
+	_, err := chain.UploadBlob(nil, "field", "value")
+	require.NoError(t, err)
+	h2, ok := chain.StateHashAt(2)
+	require.True(t, ok)
+	require.EqualValues(t, chain.State.Hash(), h2)
+	h1again, _ := chain.StateHashAt(1)
+	require.EqualValues(t, h1, h1again)
+}
+
+func TestGoldenState(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	fname := filepath.Join(t.TempDir(), "testdata", "blob.golden")
+
+	_, err := chain.UploadBlob(nil, "field", "value")
+	require.NoError(t, err)
+
+	require.Error(t, chain.CheckGoldenState(fname, blob.Interface.Name))
+
+	updateGolden = true
+	err = chain.CheckGoldenState(fname, blob.Interface.Name)
+	updateGolden = false
+	require.NoError(t, err)
+	chain.AssertGoldenState(fname, blob.Interface.Name)
+
+	_, err = chain.UploadBlob(nil, "field", "another value")
+	require.NoError(t, err)
+	err = chain.CheckGoldenState(fname, blob.Interface.Name)
+	require.Error(t, err)
+	t.Logf("%v", err)
+
+	// the directory of blobs changed, values of the first blob did not
+	firstBlob := blob.MustGetBlobHash(codec.MakeDict(map[string]interface{}{"field": "value"}))
+	onlyFirst := IncludeKeyPrefixes(kv.Key("v"+string(firstBlob[:])), kv.Key("s"+string(firstBlob[:])))
+	updateGolden = true
+	require.NoError(t, chain.CheckGoldenState(fname, blob.Interface.Name, onlyFirst))
+	updateGolden = false
+
+	_, err = chain.UploadBlob(nil, "field", "third value")
+	require.NoError(t, err)
+	chain.AssertGoldenState(fname, blob.Interface.Name, onlyFirst)
+}
+
+func TestConfirmationDelay(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	env.SetConfirmationDelay(2)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	tx := env.PostRequestsMultiChain(user, ChainRequest{
+		Chain:  chain,
+		Params: NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42),
+	})
+	require.True(t, env.IsPending(tx.ID()))
+	require.Len(t, env.PendingTransactions(), 1)
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, Saldo)
+
+	env.AdvanceClockBy(time.Second)
+	require.True(t, env.IsPending(tx.ID()))
+	require.EqualValues(t, 0, chain.backlogLen())
+
+	env.AdvanceClockBy(time.Second)
+	require.False(t, env.IsPending(tx.ID()))
+	require.Empty(t, env.PendingTransactions())
+
+	chain.WaitForEmptyBacklog()
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+1)
+}
+
+func TestReorgTransaction(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	env.SetConfirmationDelay(1)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	tx := env.PostRequestsMultiChain(user, ChainRequest{
+		Chain:  chain,
+		Params: NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42),
+	})
+	require.NoError(t, env.ReorgTransaction(tx.ID()))
+	require.Error(t, env.ReorgTransaction(tx.ID()))
+
+	env.AdvanceClockBy(time.Second)
+	require.EqualValues(t, 0, chain.backlogLen())
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, Saldo)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 0)
+
+	// the inputs of the orphaned transaction can be spent again
+	env.SetConfirmationDelay(0)
+	tx = env.PostRequestsMultiChain(user, ChainRequest{
+		Chain:  chain,
+		Params: NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42),
+	})
+	require.False(t, env.IsPending(tx.ID()))
+	require.Error(t, env.ReorgTransaction(tx.ID()))
+
+	chain.WaitForEmptyBacklog()
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+1)
+}
+
+func TestLedgerConfirmCallbacks(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	rec := env.RecordConfirmations()
+	env.SetConfirmationDelay(1)
+
+	user := env.NewSignatureSchemeWithFunds()
+	tx := env.PostRequestsMultiChain(user, ChainRequest{
+		Chain:  chain,
+		Params: NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42),
+	})
+	require.False(t, rec.IsConfirmed(tx.ID()))
+	env.AdvanceClockBy(time.Second)
+	require.True(t, rec.WaitConfirmed(tx.ID(), time.Second))
+
+	// the state transaction of the block with the request is confirmed as well
+	chain.WaitForEmptyBacklog()
+	require.True(t, rec.IsConfirmed(chain.StateTx.ID()))
+
+	orphaned := env.PostRequestsMultiChain(user, ChainRequest{
+		Chain:  chain,
+		Params: NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42),
+	})
+	require.NoError(t, env.ReorgTransaction(orphaned.ID()))
+	require.Error(t, rec.Rejected(orphaned.ID()))
+	require.False(t, rec.WaitConfirmed(orphaned.ID(), time.Second))
+}
+
+func TestImportChain(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	_, err := chain.UploadBlob(nil, "field", "value")
+	require.NoError(t, err)
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	tx, _, err := chain.PostRequestSyncTx(req, nil)
+	require.NoError(t, err)
+	reqID := coretypes.NewRequestID(tx.ID(), 0)
+
+	fname := filepath.Join(t.TempDir(), "blocks.bin")
+	chain.SaveBlocks(fname)
+
+	imported := env.ImportChainFromFile("imported", fname)
+	require.EqualValues(t, chain.ChainID, imported.ChainID)
+	require.EqualValues(t, chain.ChainColor, imported.ChainColor)
+	require.EqualValues(t, chain.State.BlockIndex(), imported.State.BlockIndex())
+	for i := uint32(0); i <= chain.State.BlockIndex(); i++ {
+		h, ok := chain.StateHashAt(i)
+		require.True(t, ok)
+		hImported, ok := imported.StateHashAt(i)
+		require.True(t, ok)
+		require.EqualValues(t, h, hImported)
+	}
+	require.EqualValues(t, chain.DumpContractState(accounts.Interface.Name), imported.DumpContractState(accounts.Interface.Name))
+	require.Contains(t, imported.RequestTraceString(reqID), "replayed request")
+
+	partial := env.ImportChain("partial", chain.ExportBlocks()[:2])
+	h1, _ := chain.StateHashAt(1)
+	require.EqualValues(t, h1, partial.State.Hash())
+}
+
+func TestCloneChain(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	color, err := env.MintTokens(user, 10)
+	require.NoError(t, err)
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).
+		WithTransfers(map[balance.Color]int64{balance.ColorIOTA: 42, color: 10})
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	clone := chain.Clone("clone")
+	require.NotEqualValues(t, chain.ChainID, clone.ChainID)
+	require.NotEqualValues(t, color, clone.ClonedColor(color))
+	require.EqualValues(t, balance.ColorIOTA, clone.ClonedColor(balance.ColorIOTA))
+	require.EqualValues(t, 1, clone.State.BlockIndex())
+
+	info, contracts := clone.GetInfo()
+	require.EqualValues(t, clone.ChainID, info.ChainID)
+	require.EqualValues(t, clone.ChainColor, info.ChainColor)
+	require.EqualValues(t, chain.OriginatorAgentID, info.ChainOwnerID)
+	_, origContracts := chain.GetInfo()
+	require.Len(t, contracts, len(origContracts))
+
+	clone.CheckChain()
+	require.NoError(t, InvariantIotaConservation(clone))
+	require.NoError(t, InvariantStateHash(clone))
+	userIotas := chain.GetAccountBalance(userAgentID).Balance(balance.ColorIOTA)
+	clone.AssertAccountBalance(userAgentID, balance.ColorIOTA, userIotas)
+	clone.AssertAccountBalance(userAgentID, clone.ClonedColor(color), 10)
+	clone.AssertAccountBalance(userAgentID, color, 0)
+
+	// the clone is independent from the original chain
+	err = clone.DepositIotasToL2(user, 5)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, userIotas)
+	require.Greater(t, clone.GetAccountBalance(userAgentID).Balance(balance.ColorIOTA), userIotas)
+	require.NoError(t, InvariantIotaConservation(clone))
+}
+
+func TestViewLimits(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	_, err := chain.CallView(root.Interface.Name, root.FuncGetChainInfo)
+	require.NoError(t, err)
+
+	chain.SetViewLimits(1, 0)
+	_, err = chain.CallView(root.Interface.Name, root.FuncGetChainInfo)
+	require.True(t, errors.Is(err, viewcontext.ErrBudgetExceeded))
+	// deterministic
+	_, err = chain.CallView(root.Interface.Name, root.FuncGetChainInfo)
+	require.True(t, errors.Is(err, viewcontext.ErrBudgetExceeded))
+
+	chain.SetViewLimits(0, time.Second)
+	_, err = chain.CallView(root.Interface.Name, root.FuncGetChainInfo)
+	require.NoError(t, err)
+}
+
+func TestCallViewTyped(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	owner, err := chain.CallViewAgentID(root.Interface.Name, root.FuncGetChainInfo, root.VarChainOwnerID)
+	require.NoError(t, err)
+	require.EqualValues(t, chain.OriginatorAgentID, owner)
+
+	description, err := chain.CallViewString(root.Interface.Name, root.FuncGetChainInfo, root.VarDescription)
+	require.NoError(t, err)
+	require.EqualValues(t, "'solo' testing chain", description)
+
+	maxCallDepth, err := chain.CallViewInt64(root.Interface.Name, root.FuncGetChainInfo, root.VarMaxCallDepth)
+	require.NoError(t, err)
+	require.EqualValues(t, root.DefaultMaxCallDepth, maxCallDepth)
+
+	_, err = chain.CallViewInt64(root.Interface.Name, root.FuncGetChainInfo, "notExisting")
+	require.Error(t, err)
+	// wrong type
+	_, err = chain.CallViewInt64(root.Interface.Name, root.FuncGetChainInfo, root.VarChainOwnerID)
+	require.Error(t, err)
+
+	var info struct {
+		ChainID     coretypes.ChainID `codec:"c"`
+		Owner       coretypes.AgentID `codec:"o"`
+		Description string            `codec:"d"`
+		FeeColor    balance.Color     `codec:"f"`
+		OwnerFee    int64             `codec:"do"`
+		Ignored     string            `codec:"-"`
+		Missing     string
+	}
+	info.Missing = "unchanged"
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetChainInfo, &info)
+	require.EqualValues(t, chain.ChainID, info.ChainID)
+	require.EqualValues(t, chain.OriginatorAgentID, info.Owner)
+	require.EqualValues(t, "'solo' testing chain", info.Description)
+	require.EqualValues(t, balance.ColorIOTA, info.FeeColor)
+	require.EqualValues(t, 0, info.OwnerFee)
+	require.EqualValues(t, "unchanged", info.Missing)
+
+	err = chain.CallViewDecode(root.Interface.Name, root.FuncGetChainInfo, info)
+	require.Error(t, err)
+}
+
+func TestInvariants(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	numChecks := 0
+	chain.AddInvariant(InvariantIotaConservation)
+	chain.AddInvariant(InvariantStateHash)
+	chain.AddInvariant(func(ch *Chain) error {
+		numChecks++
+		return nil
+	})
+	require.NoError(t, InvariantIotaConservation(chain))
+	require.NoError(t, InvariantStateHash(chain))
+
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, numChecks)
+
+	// corrupt the recorded state hash
+	last := len(chain.stateHashes) - 1
+	chain.stateHashes[last] = hashing.RandomHash(nil)
+	require.Error(t, InvariantStateHash(chain))
+}
+
+func TestDiffAccounts(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	before := chain.DumpAccounts(user.Address())
+	require.True(t, DiffAccounts(before, chain.DumpAccounts(user.Address())).IsEmpty())
+
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	after := chain.DumpAccounts(user.Address())
+	diff := DiffAccounts(before, after)
+	t.Logf("before:\n%s\nafter:\n%s\ndiff:\n%s", before, after, diff)
+	require.False(t, diff.IsEmpty())
+	chain.AssertAccountDelta(diff, userAgentID, balance.ColorIOTA, 42+1)
+	env.AssertAddressDelta(diff, user.Address(), balance.ColorIOTA, -(42 + 1))
+	require.EqualValues(t, 42+1, diff.TotalAssets.Balance(balance.ColorIOTA))
+	require.EqualValues(t, 0, diff.Account(chain.OriginatorAgentID, balance.ColorIOTA))
+}
+
+func TestBLSCommittee(t *testing.T) {
+	env := New(t, false, false, WithBLSCommittee(4, 3))
+	chain := env.NewChain(nil, "chain1")
+	require.Nil(t, chain.ChainSigScheme)
+	require.NotNil(t, chain.Committee)
+	require.Len(t, chain.Committee.DKShares, 4)
+	require.EqualValues(t, address.VersionBLS, chain.ChainAddress.Version())
+	require.EqualValues(t, chain.Committee.Address, chain.ChainAddress)
+
+	// each block is signed by another subset of the committee
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	for i := 0; i < 5; i++ {
+		req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 10)
+		_, err := chain.PostRequestSync(req, user)
+		require.NoError(t, err)
+	}
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 5*(10+1))
+	require.EqualValues(t, 6, chain.State.BlockIndex())
+}
+
+func TestLedgerParams(t *testing.T) {
+	env := New(t, false, false, WithLedgerParams(LedgerParams{
+		Saldo:              5000,
+		DustThresholdIotas: 10,
+		TransactionFee:     3,
+	}))
+	chain := env.NewChain(nil, "chain1")
+
+	user := env.NewSignatureSchemeWithFunds()
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, 5000)
+
+	// each wallet transaction pays the fee
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 100)
+	_, err := chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, 5000-100-1-3)
+	env.AssertAddressBalance(env.FeeAddress(), balance.ColorIOTA, 3)
+
+	_, err = env.MintTokens(user, 50)
+	require.NoError(t, err)
+	env.AssertAddressBalance(env.FeeAddress(), balance.ColorIOTA, 6)
+
+	// the request without transfer sends only the request token to the chain
+	dust := env.NewSignatureSchemeWithFunds()
+	outs := env.utxoDB.GetAddressOutputs(dust.Address())
+	txb, err := txbuilder.NewFromOutputBalances(outs)
+	require.NoError(t, err)
+	err = txb.AddRequestSection(sctransaction.NewRequestSectionByWallet(coretypes.NewContractID(chain.ChainID, accounts.Interface.Hname()), coretypes.Hn(accounts.FuncDeposit)))
+	require.NoError(t, err)
+	tx, err := txb.Build(false)
+	require.NoError(t, err)
+	tx.Sign(dust)
+	require.Error(t, env.AddToLedger(tx))
+	env.AssertAddressBalance(dust.Address(), balance.ColorIOTA, 5000)
+}
+
+func TestLedgerParamsSmallSaldo(t *testing.T) {
+	env := New(t, false, false, WithLedgerParams(LedgerParams{Saldo: 100}))
+	require.EqualValues(t, 100, env.LedgerParams().Saldo)
+	user := env.NewSignatureSchemeWithFunds()
+	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, 100)
+
+	chain := env.NewChain(nil, "chain1")
+	env.AssertAddressBalance(chain.OriginatorAddress, balance.ColorIOTA, 100-2)
+}
+
+func TestContractInterface(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "ch1")
+
+	ci, err := chain.ContractInterface(accounts.Interface.Hname())
+	require.NoError(t, err)
+	require.EqualValues(t, accounts.Name, ci.Name)
+	require.Len(t, ci.EntryPoints, len(accounts.Interface.Functions))
+
+	found := false
+	for _, ep := range ci.EntryPoints {
+		if ep.Name != accounts.FuncGetEscrow {
+			continue
+		}
+		found = true
+		require.True(t, ep.IsView)
+		require.EqualValues(t, []string{accounts.ParamEscrowID}, ep.ParamHints)
+	}
+	require.True(t, found)
+
+	_, err = chain.ContractInterface(coretypes.Hn("dummy"))
+	require.Error(t, err)
+}
+
+func TestStubEntryPoint(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+
+	// simulated failure
+	remove := chain.StubEntryPoint(accounts.Interface.Hname(), accounts.FuncDeposit, func(ctx coretypes.Sandbox) (dict.Dict, error) {
+		return nil, errors.New("simulated failure")
+	})
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+	// the transfer is returned to the address of the sender
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1)
+
+	// instrumented original
+	remove()
+	calls := 0
+	orig, ok := accounts.Interface.GetEntryPoint(coretypes.Hn(accounts.FuncDeposit))
+	require.True(t, ok)
+	chain.StubEntryPoint(accounts.Interface.Hname(), accounts.FuncDeposit, func(ctx coretypes.Sandbox) (dict.Dict, error) {
+		calls++
+		return orig.Call(ctx)
+	})
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, calls)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1+42+1)
+
+	// stubbed view
+	chain.StubViewEntryPoint(root.Interface.Hname(), root.FuncGetChainInfo, func(ctx coretypes.SandboxView) (dict.Dict, error) {
+		return nil, errors.New("simulated failure")
+	})
+	_, err = chain.CallView(root.Interface.Name, root.FuncGetChainInfo)
+	require.Error(t, err)
+}
+
+func TestStubEntryPointOfOneInstance(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	// two contracts of the same program
+	err := chain.DeployContract(nil, "first", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	err = chain.DeployContract(nil, "second", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	chain.StubEntryPoint(coretypes.Hn("first"), sbtestsc.FuncIncCounter, func(ctx coretypes.Sandbox) (dict.Dict, error) {
+		return nil, errors.New("simulated failure")
+	})
+	chain.StubViewEntryPoint(coretypes.Hn("second"), sbtestsc.FuncGetCounter, func(ctx coretypes.SandboxView) (dict.Dict, error) {
+		return nil, errors.New("simulated failure")
+	})
+
+	_, err = chain.PostRequestSync(NewCallParams("first", sbtestsc.FuncIncCounter), nil)
+	require.Error(t, err)
+	_, err = chain.PostRequestSync(NewCallParams("second", sbtestsc.FuncIncCounter), nil)
+	require.NoError(t, err)
+
+	counter, err := chain.CallViewInt64("first", sbtestsc.FuncGetCounter, sbtestsc.VarCounter)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, counter)
+	_, err = chain.CallView("second", sbtestsc.FuncGetCounter)
+	require.Error(t, err)
+}
+
+func TestSimulateRequest(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	err := chain.DeployContract(nil, "test", sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+	stateHash := chain.State.Hash()
+	blockIndex := chain.State.BlockIndex()
+
+	res, err := chain.SimulateRequest(NewCallParams("test", sbtestsc.FuncIncCounter), nil)
+	require.NoError(t, err)
+	require.NoError(t, res.Error)
+	require.NotEmpty(t, res.StateDiff)
+	require.EqualValues(t, balance.ColorIOTA, res.FeeColor)
+
+	res, err = chain.SimulateRequest(NewCallParams("test", sbtestsc.FuncEventLogTypedEvent, sbtestsc.VarCounter, 7), nil)
+	require.NoError(t, err)
+	require.NoError(t, res.Error)
+	found := false
+	for _, ev := range res.Events {
+		if ev.Topic == sbtestsc.EventTopicCounter {
+			require.EqualValues(t, coretypes.Hn("test"), ev.Contract)
+			require.EqualValues(t, codec.EncodeInt64(7), ev.Fields.MustGet(sbtestsc.VarCounter))
+			found = true
+		}
+	}
+	require.True(t, found)
+
+	res, err = chain.SimulateRequest(NewCallParams("test", sbtestsc.FuncPanicFullEP), nil)
+	require.NoError(t, err)
+	require.Error(t, res.Error)
+
+	// nothing is committed
+	require.EqualValues(t, stateHash, chain.State.Hash())
+	require.EqualValues(t, blockIndex, chain.State.BlockIndex())
+	counter, err := chain.CallViewInt64("test", sbtestsc.FuncGetCounter, sbtestsc.VarCounter)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, counter)
+}
+
+func TestNewCallParamsChecked(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	_, err := chain.NewCallParamsChecked(accounts.Interface.Name, accounts.FuncHarvest, accounts.ParamMinAmount, "1")
+	require.Error(t, err)
+	_, err = chain.NewCallParamsChecked(accounts.Interface.Name, accounts.FuncHarvest, "dummy", 1)
+	require.Error(t, err)
+	_, err = chain.NewCallParamsChecked(accounts.Interface.Name, "dummy")
+	require.Error(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	req, err := chain.NewCallParamsChecked(accounts.Interface.Name, accounts.FuncDeposit, accounts.ParamAgentID, userAgentID)
+	require.NoError(t, err)
+	_, err = chain.PostRequestSync(req.WithTransfer(balance.ColorIOTA, 42), nil)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42)
+}
+
+func TestWallClock(t *testing.T) {
+	env := New(t, false, false, WithWallClock(1000))
+	chain := env.NewChain(nil, "chain1")
+	env.SetConfirmationDelay(2)
+
+	t0 := env.LogicalTime()
+	time.Sleep(10 * time.Millisecond)
+	require.True(t, env.LogicalTime().Sub(t0) >= 10*time.Second)
+
+	// the pending transaction is confirmed by ticks of the clock
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	tx := env.PostRequestsMultiChain(user, ChainRequest{
+		Chain:  chain,
+		Params: NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42),
+	})
+	require.True(t, env.IsPending(tx.ID()))
+	time.Sleep(5 * WallClockTick)
+	require.False(t, env.IsPending(tx.ID()))
+	chain.WaitForEmptyBacklog()
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+1)
+
+	stats := chain.Stats()
+	require.Zero(t, stats.Backlog)
+	require.Zero(t, stats.Unprocessed)
+	require.EqualValues(t, chain.State.BlockIndex()+1, stats.Blocks)
+
+	// the clock still can be advanced manually
+	t1 := env.LogicalTime()
+	env.AdvanceClockBy(time.Hour)
+	require.True(t, env.LogicalTime().Sub(t1) >= time.Hour)
+}
+
+func TestShutdown(t *testing.T) {
+	env := New(t, false, false, WithWallClock(1))
+	chain1 := env.NewChain(nil, "chain1")
+	chain2 := env.NewChain(nil, "chain2")
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain1.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	chain1.Shutdown()
+	chain1.Shutdown()
+	env.glbMutex.RLock()
+	_, ok := env.chains[chain1.ChainID]
+	env.glbMutex.RUnlock()
+	require.False(t, ok)
+
+	// the state of the chain which is shut down is still available
+	chain1.AssertAccountBalance(userAgentID, balance.ColorIOTA, 42+1)
+	// the other chain is still running
+	_, err = chain2.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	env.Close()
+	env.Close()
+	goleak.VerifyNone(t)
+}
+
+func TestStressTest(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	res := chain.StressTest(5, 10, func(sender int, i int) *CallParams {
+		return NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 1)
+	})
+	t.Logf("%s", res)
+	require.EqualValues(t, 50, res.Requests)
+	require.True(t, res.Blocks > 0 && res.Blocks <= 50)
+	require.True(t, res.MaxBacklog <= 50)
+	require.True(t, res.Throughput > 0)
+	require.Zero(t, chain.Stats().Unprocessed)
+	chain.CheckAccountLedger()
+}
+
+func TestFetchWasm(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "solo-wasm")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+	defer os.Setenv(WasmCacheEnvVar, os.Getenv(WasmCacheEnvVar))
+	require.NoError(t, os.Setenv(WasmCacheEnvVar, cacheDir))
+
+	binary := []byte("\x00asm\x01\x00\x00\x00")
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v0.1.0/example_bg.wasm", r.URL.Path)
+		downloads++
+		_, _ = w.Write(binary)
+	}))
+	defer server.Close()
+	defer func(url string) { WasmBaseURL = url }(WasmBaseURL)
+	WasmBaseURL = server.URL
+
+	sum := sha256.Sum256(binary)
+	a := WasmArtifact{Name: "example", Version: "v0.1.0", SHA256: hex.EncodeToString(sum[:])}
+	ret, err := FetchWasm(a)
+	require.NoError(t, err)
+	require.EqualValues(t, binary, ret)
+	require.EqualValues(t, 1, downloads)
+
+	// the second time the binary is taken from the cache
+	ret, err = FetchWasm(a)
+	require.NoError(t, err)
+	require.EqualValues(t, binary, ret)
+	require.EqualValues(t, 1, downloads)
+
+	wrong := a
+	wrong.SHA256 = hex.EncodeToString(make([]byte, sha256.Size))
+	_, err = FetchWasm(wrong)
+	require.Error(t, err)
+	require.EqualValues(t, 2, downloads)
+
+	unpinned := a
+	unpinned.SHA256 = ""
+	_, err = FetchWasm(unpinned)
+	require.Error(t, err)
+
+	defer os.Setenv(WasmOfflineEnvVar, os.Getenv(WasmOfflineEnvVar))
+	require.NoError(t, os.Setenv(WasmOfflineEnvVar, "1"))
+	ret, err = FetchWasm(a)
+	require.NoError(t, err)
+	require.EqualValues(t, binary, ret)
+	other := a
+	other.Version = "v0.2.0"
+	_, err = FetchWasm(other)
+	require.Error(t, err)
+	require.EqualValues(t, 2, downloads)
+}
+
+func TestOptions(t *testing.T) {
+	seed := hashing.HashStrings("solo seed")
+	env1 := NewWithOptions(t, WithSeed(seed[:]), WithTimeStep(time.Second))
+	env2 := NewWithOptions(t, Debug(), WithSeed(seed[:]))
+	require.EqualValues(t, time.Second, env1.timeStep)
+	require.EqualValues(t, DefaultTimeStep, env2.timeStep)
+	require.True(t, env2.debug)
+
+	// keys are derived from the seed in the same order
+	require.EqualValues(t, env1.FeeAddress(), env2.FeeAddress())
+	require.EqualValues(t, env1.NewSignatureScheme().Address(), env2.NewSignatureScheme().Address())
+	require.NotEqual(t, New(t, false, false).NewSignatureScheme().Address(), New(t, false, false).NewSignatureScheme().Address())
+
+	before := env1.LogicalTime()
+	env1.ClockStep()
+	require.EqualValues(t, time.Second, env1.LogicalTime().Sub(before))
+}
+
+func TestDump(t *testing.T) {
+	env := New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	s := chain.Dump()
+	require.Contains(t, s, "chain 'chain1' "+chain.ChainID.String())
+	require.Contains(t, s, "backlog: 0 request(s)")
+	require.Contains(t, s, "last batch: 'new', 1 request(s), block index: 1")
+
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	_, err = chain.PostRequestSync(NewCallParams(root.Interface.Name, "nonExistent"), nil)
+	require.Error(t, err)
+
+	s = chain.Dump()
+	t.Logf("dump:\n%s", s)
+	require.Contains(t, s, "last batch: 'post', 1 request(s), block index: 3")
+	require.Contains(t, s, "error: ")
+	require.Contains(t, s, "---- accounts")
+	require.Contains(t, s, "---- latest event log records")
+	require.Contains(t, env.Dump(), s)
+
+	env1 := NewWithOptions(t, NoFailureDump())
+	require.True(t, env1.noFailureDump)
+}
@@ -3,7 +3,7 @@ package solo
 import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
-	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/sctransaction/txbuilder"
 	"github.com/stretchr/testify/require"
@@ -11,7 +11,7 @@ import (
 
 // NewSignatureSchemeWithFunds generates new ed25519 signature scheme
 // and requests some tokens from the UTXODB faucet.
-// The amount of tokens is equal to solo.Saldo (=1337) iotas
+// The amount of tokens is equal to LedgerParams().Saldo, by default solo.Saldo (=1337) iotas
 func (env *Solo) NewSignatureSchemeWithFunds() signaturescheme.SignatureScheme {
 	ret, _ := env.NewSignatureSchemeWithFundsAndPubKey()
 	return ret
@@ -19,14 +19,14 @@ func (env *Solo) NewSignatureSchemeWithFunds() signaturescheme.SignatureScheme {
 
 // NewSignatureSchemeWithFundsAndPubKey generates new ed25519 signature scheme
 // and requests some tokens from the UTXODB faucet.
-// The amount of tokens is equal to solo.Saldo (=1337) iotas
+// The amount of tokens is equal to LedgerParams().Saldo, by default solo.Saldo (=1337) iotas
 // Returns signature scheme interface and public key in binary form
 func (env *Solo) NewSignatureSchemeWithFundsAndPubKey() (signaturescheme.SignatureScheme, []byte) {
 	env.ledgerMutex.Lock()
 	defer env.ledgerMutex.Unlock()
 
 	ret, pubKeyBytes := env.NewSignatureSchemeAndPubKey()
-	_, err := env.utxoDB.RequestFunds(ret.Address())
+	err := env.requestFunds(ret.Address())
 	require.NoError(env.T, err)
 	return ret, pubKeyBytes
 }
@@ -40,7 +40,7 @@ func (env *Solo) NewSignatureScheme() signaturescheme.SignatureScheme {
 // NewSignatureSchemeAndPubKey generates new ed25519 signature scheme
 // Returns signature scheme interface and public key in binary form
 func (env *Solo) NewSignatureSchemeAndPubKey() (signaturescheme.SignatureScheme, []byte) {
-	keypair := ed25519.GenerateKeyPair()
+	keypair := env.newKeyPair()
 	ret := signaturescheme.ED25519(keypair)
 	env.AssertAddressBalance(ret.Address(), balance.ColorIOTA, 0)
 	return ret, keypair.PublicKey.Bytes()
@@ -59,9 +59,15 @@ func (env *Solo) MintTokens(wallet signaturescheme.SignatureScheme, amount int64
 	if err = txb.MintColoredTokens(wallet.Address(), balance.ColorIOTA, amount); err != nil {
 		return balance.Color{}, err
 	}
+	if err = env.addTransactionFee(txb.Builder); err != nil {
+		return balance.Color{}, err
+	}
 	tx := txb.BuildValueTransactionOnly(false)
 	tx.Sign(wallet)
 
+	if err = env.checkDust(tx); err != nil {
+		return balance.Color{}, err
+	}
 	if err = env.utxoDB.AddTransaction(tx); err != nil {
 		return balance.Color{}, err
 	}
@@ -80,9 +86,15 @@ func (env *Solo) DestroyColoredTokens(wallet signaturescheme.SignatureScheme, co
 	if err = txb.EraseColor(wallet.Address(), color, amount); err != nil {
 		return err
 	}
+	if err = env.addTransactionFee(txb.Builder); err != nil {
+		return err
+	}
 	tx := txb.BuildValueTransactionOnly(false)
 	tx.Sign(wallet)
 
+	if err = env.checkDust(tx); err != nil {
+		return err
+	}
 	return env.utxoDB.AddTransaction(tx)
 }
 
@@ -92,3 +104,8 @@ func (env *Solo) PutBlobDataIntoRegistry(data []byte) hashing.HashValue {
 	env.logger.Infof("Solo::PutBlobDataIntoRegistry: len = %d, hash = %s", len(data), h)
 	return h
 }
+
+// BlobCache returns the registry of blobs of the environment, shared by all chains
+func (env *Solo) BlobCache() coretypes.BlobCacheFull {
+	return env.registry
+}
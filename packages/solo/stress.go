@@ -0,0 +1,146 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/stretchr/testify/require"
+)
+
+// StressBuilder builds the i-th request of the sender. It is called concurrently from goroutines of all senders
+type StressBuilder func(sender int, i int) *CallParams
+
+// StressResult is the report of the StressTest
+type StressResult struct {
+	// Requests is the number of requests posted and processed
+	Requests int
+	// Blocks is the number of blocks the requests were processed in
+	Blocks int
+	// Duration is the time from the first request posted until the backlog was drained
+	Duration time.Duration
+	// Throughput is the number of processed requests per second
+	Throughput float64
+	// MaxBacklog is the maximum number of requests seen waiting in the backlog
+	MaxBacklog int
+}
+
+func (r *StressResult) String() string {
+	return fmt.Sprintf("%d requests in %d blocks in %v: %.1f req/s, max backlog %d",
+		r.Requests, r.Blocks, r.Duration, r.Throughput, r.MaxBacklog)
+}
+
+// stressSampleInterval is the period of sampling of the backlog depth during the StressTest
+const stressSampleInterval = 5 * time.Millisecond
+
+// StressTest floods the chain with requests posted concurrently by nSenders goroutines, each with its
+// own wallet, reqsPerSender requests each. Unlike PostRequestSync, the requests go through the backlog
+// and the batch loop of the chain, the same way requests from other chains do.
+// The call waits until all requests are processed, at most maxWait (default 1 minute), and fails the
+// test if the backlog is not drained in time or any of the requests is not included in a block
+func (ch *Chain) StressTest(nSenders, reqsPerSender int, builderFn StressBuilder, maxWait ...time.Duration) *StressResult {
+	require.True(ch.Env.T, nSenders > 0 && reqsPerSender > 0, "number of senders and requests must be positive")
+	maxw := time.Minute
+	if len(maxWait) > 0 {
+		maxw = maxWait[0]
+	}
+	wallets := make([]signaturescheme.SignatureScheme, nSenders)
+	for i := range wallets {
+		wallets[i] = ch.Env.NewSignatureSchemeWithFunds()
+	}
+	blocksBefore := ch.Stats().Blocks
+
+	stopSampling := make(chan struct{})
+	maxBacklog := 0
+	var samplerWg sync.WaitGroup
+	samplerWg.Add(1)
+	go func() {
+		defer samplerWg.Done()
+		ticker := time.NewTicker(stressSampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if l := ch.backlogLen(); l > maxBacklog {
+					maxBacklog = l
+				}
+			case <-stopSampling:
+				return
+			}
+		}
+	}()
+
+	reqIDs := make([][]coretypes.RequestID, nSenders)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for s := 0; s < nSenders; s++ {
+		wg.Add(1)
+		go func(sender int) {
+			defer wg.Done()
+			reqIDs[sender] = make([]coretypes.RequestID, reqsPerSender)
+			for i := 0; i < reqsPerSender; i++ {
+				tx := ch.RequestFromParamsToLedger(builderFn(sender, i), wallets[sender])
+				reqIDs[sender][i] = coretypes.NewRequestID(tx.ID(), 0)
+				ch.Env.EnqueueRequests(tx)
+			}
+		}(s)
+	}
+	wg.Wait()
+
+	drained := ch.waitForDrain(time.Now().Add(maxw))
+	duration := time.Since(start)
+	close(stopSampling)
+	samplerWg.Wait()
+	require.True(ch.Env.T, drained, "StressTest: backlog not drained in %v, %d requests left", maxw, ch.backlogLen())
+
+	missing := ch.unprocessedRequests(reqIDs)
+	require.Zero(ch.Env.T, missing, "StressTest: %d requests not included in blocks", missing)
+
+	ret := &StressResult{
+		Requests:   nSenders * reqsPerSender,
+		Blocks:     ch.Stats().Blocks - blocksBefore,
+		Duration:   duration,
+		Throughput: float64(nSenders*reqsPerSender) / duration.Seconds(),
+		MaxBacklog: maxBacklog,
+	}
+	ch.Log.Infof("StressTest: %s", ret)
+	return ret
+}
+
+// waitForDrain waits until all requests posted to the chain are processed or the deadline passes
+func (ch *Chain) waitForDrain(deadline time.Time) bool {
+	for ch.backlogLen() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// unprocessedRequests returns the number of requests not included in any block of the chain
+func (ch *Chain) unprocessedRequests(reqIDs [][]coretypes.RequestID) int {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+
+	processed := make(map[coretypes.RequestID]bool)
+	for _, b := range ch.blocks {
+		for _, rid := range b.RequestIDs() {
+			processed[*rid] = true
+		}
+	}
+	ret := 0
+	for _, ids := range reqIDs {
+		for _, id := range ids {
+			if !processed[id] {
+				ret++
+			}
+		}
+	}
+	return ret
+}
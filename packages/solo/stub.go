@@ -0,0 +1,115 @@
+package solo
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/iotaledger/wasp/plugins/wasmtimevm"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProcessor is the processor of the contract with some of its entry points replaced by stubs
+type stubProcessor struct {
+	coretypes.Processor
+	stubs map[coretypes.Hname]coretypes.EntryPoint
+}
+
+func (p *stubProcessor) GetEntryPoint(code coretypes.Hname) (coretypes.EntryPoint, bool) {
+	if ep, ok := p.stubs[code]; ok {
+		return ep, true
+	}
+	return p.Processor.GetEntryPoint(code)
+}
+
+// StubEntryPoint replaces the full entry point 'funcName' of the contract with the Go function 'fn',
+// for example to simulate failures of core contracts or to instrument their behavior.
+// The original entry point is still available to the stub, e.g. root.Interface.GetEntryPoint.
+// The original is restored with the returned function or at the end of the test
+func (ch *Chain) StubEntryPoint(contract coretypes.Hname, funcName string, fn coreutil.Handler) func() {
+	ep := coreutil.Func(funcName, fn)
+	return ch.stubEntryPoint(contract, funcName, &ep)
+}
+
+// StubViewEntryPoint replaces the view entry point 'funcName' of the contract, see StubEntryPoint
+func (ch *Chain) StubViewEntryPoint(contract coretypes.Hname, funcName string, fn coreutil.ViewHandler) func() {
+	ep := coreutil.ViewFunc(funcName, fn)
+	return ch.stubEntryPoint(contract, funcName, &ep)
+}
+
+func (ch *Chain) stubEntryPoint(contract coretypes.Hname, funcName string, ep coretypes.EntryPoint) func() {
+	rec, err := ch.findContractByHname(contract)
+	require.NoError(ch.Env.T, err)
+	code := coretypes.Hn(funcName)
+	ch.replaceStubs(contract, rec.ProgramHash, func(stubs map[coretypes.Hname]coretypes.EntryPoint) {
+		stubs[code] = ep
+	})
+	removed := false
+	remove := func() {
+		if removed {
+			return
+		}
+		removed = true
+		ch.replaceStubs(contract, rec.ProgramHash, func(stubs map[coretypes.Hname]coretypes.EntryPoint) {
+			delete(stubs, code)
+		})
+	}
+	ch.Env.T.Cleanup(remove)
+	return remove
+}
+
+// replaceStubs modifies the copy of stubs of the contract and makes the contract use the new processor,
+// so the processor used by the running batch is never mutated. Other contracts of the same program
+// keep the original processor
+func (ch *Chain) replaceStubs(contract coretypes.Hname, programHash hashing.HashValue, modify func(stubs map[coretypes.Hname]coretypes.EntryPoint)) {
+	proc, err := ch.proc.GetOrCreateContractProcessor(contract, programHash, ch.getBinary)
+	require.NoError(ch.Env.T, err)
+	orig := proc
+	stubs := make(map[coretypes.Hname]coretypes.EntryPoint)
+	if sp, ok := proc.(*stubProcessor); ok {
+		orig = sp.Processor
+		for k, v := range sp.stubs {
+			stubs[k] = v
+		}
+	}
+	modify(stubs)
+	if len(stubs) == 0 {
+		ch.proc.ReplaceContractProcessor(contract, nil)
+		return
+	}
+	ch.proc.ReplaceContractProcessor(contract, &stubProcessor{Processor: orig, stubs: stubs})
+}
+
+func (ch *Chain) getBinary(programHash hashing.HashValue) (string, []byte, error) {
+	if vmtype, ok := processors.GetBuiltinProcessorType(programHash); ok {
+		return vmtype, nil, nil
+	}
+	binary, err := ch.GetWasmBinary(programHash)
+	if err != nil {
+		return "", nil, err
+	}
+	// the binary is prepared the same way the VM does it
+	rootState := subrealm.New(ch.State.Variables(), kv.Key(root.Interface.Hname().Bytes()))
+	binary, err = processors.FilterBinary(wasmtimevm.VMType, binary, root.GetProgramFloatPolicy(rootState, programHash))
+	if err != nil {
+		return "", nil, err
+	}
+	return wasmtimevm.VMType, binary, nil
+}
+
+func (ch *Chain) findContractByHname(contract coretypes.Hname) (*root.ContractRecord, error) {
+	ret, err := ch.CallView(root.Interface.Name, root.FuncFindContract, root.ParamHname, contract)
+	if err != nil {
+		return nil, err
+	}
+	recBin := ret.MustGet(root.ParamData)
+	if recBin == nil {
+		return nil, fmt.Errorf("smart contract %s not found", contract)
+	}
+	return root.DecodeContractRecord(recBin)
+}
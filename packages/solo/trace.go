@@ -0,0 +1,158 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// TraceEntry is one log line produced while running a particular request
+type TraceEntry struct {
+	Time    time.Time
+	Level   zapcore.Level
+	Logger  string
+	Message string
+	Fields  map[string]interface{}
+}
+
+// String human readable form of the trace entry
+func (e *TraceEntry) String() string {
+	ret := fmt.Sprintf("%s\t%s\t%s\t%s", e.Time.Format("04:05.000"), e.Level.CapitalString(), e.Logger, e.Message)
+	if len(e.Fields) == 0 {
+		return ret
+	}
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fields := make([]string, len(keys))
+	for i, k := range keys {
+		fields[i] = fmt.Sprintf("%s=%v", k, e.Fields[k])
+	}
+	return ret + "\t" + strings.Join(fields, " ")
+}
+
+// requestTracer collects log entries tagged with the request ID
+type requestTracer struct {
+	mutex   sync.Mutex
+	entries map[string][]*TraceEntry
+}
+
+func newRequestTracer() *requestTracer {
+	return &requestTracer{entries: make(map[string][]*TraceEntry)}
+}
+
+func (t *requestTracer) add(reqID string, e *TraceEntry) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.entries[reqID] = append(t.entries[reqID], e)
+}
+
+func (t *requestTracer) get(reqID string) []*TraceEntry {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	ret := make([]*TraceEntry, len(t.entries[reqID]))
+	copy(ret, t.entries[reqID])
+	return ret
+}
+
+// withTracer returns the logger which, in addition to the normal output, passes
+// all entries tagged with the request ID to the tracer.
+// The tracer receives entries of all levels, independently of the level of the logger
+func (t *requestTracer) withTracer(log *logger.Logger) *logger.Logger {
+	return log.Desugar().WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, &traceCore{tracer: t})
+	})).Sugar()
+}
+
+// traceCore is the zapcore.Core which only records entries to the tracer
+type traceCore struct {
+	tracer *requestTracer
+	fields []zapcore.Field
+}
+
+func (c *traceCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *traceCore) With(fields []zapcore.Field) zapcore.Core {
+	ret := &traceCore{
+		tracer: c.tracer,
+		fields: make([]zapcore.Field, 0, len(c.fields)+len(fields)),
+	}
+	ret.fields = append(ret.fields, c.fields...)
+	ret.fields = append(ret.fields, fields...)
+	return ret
+}
+
+func (c *traceCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return ce.AddCore(ent, c)
+}
+
+func (c *traceCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !hasRequestIDField(c.fields) && !hasRequestIDField(fields) {
+		return nil
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range c.fields {
+		f.AddTo(enc)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	reqID, ok := enc.Fields[vm.LogFieldRequestID].(string)
+	if !ok {
+		return nil
+	}
+	delete(enc.Fields, vm.LogFieldRequestID)
+	c.tracer.add(reqID, &TraceEntry{
+		Time:    ent.Time,
+		Level:   ent.Level,
+		Logger:  ent.LoggerName,
+		Message: ent.Message,
+		Fields:  enc.Fields,
+	})
+	return nil
+}
+
+func (c *traceCore) Sync() error {
+	return nil
+}
+
+func hasRequestIDField(fields []zapcore.Field) bool {
+	for _, f := range fields {
+		if f.Key == vm.LogFieldRequestID {
+			return true
+		}
+	}
+	return false
+}
+
+// RequestTrace returns all log entries produced by the VM, the sandbox and the core contracts
+// while running the request with the given ID, in the order they were logged.
+// Entries of all levels are collected, even if the logger of the chain is not in the 'debug' mode
+func (ch *Chain) RequestTrace(reqID coretypes.RequestID) []*TraceEntry {
+	return ch.tracer.get(reqID.String())
+}
+
+// RequestTraceString returns the trace of the request in human-readable form, one entry per line
+func (ch *Chain) RequestTraceString(reqID coretypes.RequestID) string {
+	entries := ch.RequestTrace(reqID)
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = e.String()
+	}
+	return strings.Join(lines, "\n")
+}
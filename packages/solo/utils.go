@@ -1,9 +1,15 @@
 package solo
 
 import (
+	"time"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
+	"github.com/stretchr/testify/require"
 )
 
 // GrantDeployPermission gives permission to the specified agentID to deploy SCs into the chain
@@ -27,3 +33,94 @@ func (ch *Chain) RevokeDeployPermission(sigScheme signaturescheme.SignatureSchem
 	_, err := ch.PostRequestSync(req, sigScheme)
 	return err
 }
+
+// SetDeployPolicy sets who may deploy contracts on the chain, one of root.DeployPolicyAllowList,
+// root.DeployPolicyOpen, root.DeployPolicyOwnerOnly
+func (ch *Chain) SetDeployPolicy(sigScheme signaturescheme.SignatureScheme, policy int64) error {
+	if sigScheme == nil {
+		sigScheme = ch.OriginatorSigScheme
+	}
+
+	req := NewCallParams(root.Interface.Name, root.FuncSetDeployPolicy, root.ParamDeployPolicy, policy)
+	_, err := ch.PostRequestSync(req, sigScheme)
+	return err
+}
+
+// DepositIotasToL2 sends the specified amount of iotas from the address of sigScheme to its
+// on-chain account by posting accounts.deposit request.
+// Asserts the balances on both layers: the address loses amount+1 iotas (+1 for the request token),
+// the on-chain account receives all of them. Assumes no fees are charged by the 'accounts' contract
+func (ch *Chain) DepositIotasToL2(sigScheme signaturescheme.SignatureScheme, amount int64) error {
+	if sigScheme == nil {
+		sigScheme = ch.OriginatorSigScheme
+	}
+	addr := sigScheme.Address()
+	agentID := coretypes.NewAgentIDFromAddress(addr)
+	l1Before := ch.Env.GetAddressBalance(addr, balance.ColorIOTA)
+	l2Before := ch.GetAccountBalance(agentID).Balance(balance.ColorIOTA)
+
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, amount)
+	if _, err := ch.PostRequestSync(req, sigScheme); err != nil {
+		return err
+	}
+	ch.Env.AssertAddressBalance(addr, balance.ColorIOTA, l1Before-amount-1)
+	ch.AssertAccountBalance(agentID, balance.ColorIOTA, l2Before+amount+1)
+	return nil
+}
+
+// WithdrawToL1 moves on-chain funds of sigScheme back to its address by posting accounts.withdrawToAddress request.
+// The 'accounts' contract always withdraws the whole account, so amount is the balance of the color
+// expected to be on the account. Asserts that amount tokens of the color arrive to the address
+// and the on-chain account is empty after the withdrawal
+func (ch *Chain) WithdrawToL1(sigScheme signaturescheme.SignatureScheme, color balance.Color, amount int64) error {
+	if sigScheme == nil {
+		sigScheme = ch.OriginatorSigScheme
+	}
+	addr := sigScheme.Address()
+	agentID := coretypes.NewAgentIDFromAddress(addr)
+	ch.AssertAccountBalance(agentID, color, amount)
+	l1Before := ch.Env.GetAddressBalance(addr, color)
+
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncWithdrawToAddress)
+	if _, err := ch.PostRequestSync(req, sigScheme); err != nil {
+		return err
+	}
+	// the request token of the withdrawal request is accrued to the account and withdrawn too
+	ch.Env.AssertAddressBalance(addr, color, l1Before+amount)
+	require.Zero(ch.Env.T, ch.GetAccountBalance(agentID).Len())
+	return nil
+}
+
+// HarvestFees sweeps fees accrued on the on-chain account of sigScheme (by default the originator,
+// which is also the default ValidatorFeeTarget) to its address by posting accounts.harvest request.
+// Balances of colors below minAmount stay on the account.
+// Returns harvested balances
+func (ch *Chain) HarvestFees(sigScheme signaturescheme.SignatureScheme, minAmount int64) (map[balance.Color]int64, error) {
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncHarvest, accounts.ParamMinAmount, minAmount)
+	res, err := ch.PostRequestSync(req, sigScheme)
+	if err != nil {
+		return nil, err
+	}
+	return accounts.DecodeBalances(res)
+}
+
+// TransferAccount moves all funds of the on-chain account of sigScheme (by default the originator) to the account
+// of the target agent ID by posting accounts.transferAccount request.
+// Returns moved balances
+func (ch *Chain) TransferAccount(sigScheme signaturescheme.SignatureScheme, target coretypes.AgentID) (map[balance.Color]int64, error) {
+	req := NewCallParams(accounts.Interface.Name, accounts.FuncTransferAccount, accounts.ParamAgentID, target)
+	res, err := ch.PostRequestSync(req, sigScheme)
+	if err != nil {
+		return nil, err
+	}
+	return accounts.DecodeBalances(res)
+}
+
+// SetViewLimits restricts the execution budget and the wall-clock time of view calls on the chain,
+// the same way as view calls on the Wasp node are restricted by the node config.
+// By default view calls in Solo are not limited
+func (ch *Chain) SetViewLimits(budget int64, timeout time.Duration) {
+	ch.runVMMutex.Lock()
+	defer ch.runVMMutex.Unlock()
+	ch.viewLimits = viewcontext.Limits{Budget: budget, Timeout: timeout}
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"runtime"
+	"time"
+)
+
+// WallClockTick is the period of ticks of the logical clock in the wall-clock mode, see WithWallClock
+const WallClockTick = 100 * time.Millisecond
+
+// WithWallClock makes the logical clock of the environment follow the real time, accelerated by
+// the speed-up factor (1 means real time). It is meant for soak tests, which keep chains running
+// for minutes: time-locked requests are unlocked and pending transactions are confirmed
+// (see SetConfirmationDelay) while the test is waiting, without calls to the clock.
+// AdvanceClockBy and AdvanceClockTo still move the clock forward. The wall clock is stopped by Close
+func WithWallClock(speedUp float64) Option {
+	return func(env *Solo) {
+		if speedUp <= 0 {
+			speedUp = 1
+		}
+		env.wallClock = true
+		env.speedUp = speedUp
+		env.wallClockAt = time.Now()
+
+		env.loops.Add(1)
+		go func() {
+			defer env.loops.Done()
+			env.wallClockLoop()
+		}()
+	}
+}
+
+func (env *Solo) wallClockLoop() {
+	ticker := time.NewTicker(WallClockTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			env.tick()
+		case <-env.stop:
+			return
+		}
+	}
+}
+
+// ChainStats are sizes of the internal structures of the chain, used by soak tests to detect leaks
+type ChainStats struct {
+	// Backlog is the number of requests waiting in the backlog, including time-locked ones
+	Backlog int
+	// Unprocessed is the number of requests posted to the chain and not yet processed
+	Unprocessed int
+	// Processors is the number of processors of programs cached by the chain
+	Processors int
+	// Blocks is the number of blocks kept in memory
+	Blocks int
+	// Goroutines is the number of goroutines of the test process
+	Goroutines int
+}
+
+// Stats returns sizes of the internal structures of the chain
+func (ch *Chain) Stats() ChainStats {
+	ch.backlogMutex.RLock()
+	backlog := len(ch.backlog)
+	ch.backlogMutex.RUnlock()
+
+	ch.runVMMutex.Lock()
+	blocks := len(ch.blocks)
+	ch.runVMMutex.Unlock()
+
+	return ChainStats{
+		Backlog:     backlog,
+		Unprocessed: ch.backlogLen(),
+		Processors:  ch.proc.Len(),
+		Blocks:      blocks,
+		Goroutines:  runtime.NumGoroutine(),
+	}
+}
@@ -0,0 +1,28 @@
+package solo
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/vm/wasmproc"
+)
+
+// AddWasmBreakpoint sets the breakpoint on the host function of wasm contracts of the chain.
+// The 'callback' is called before the host function is executed, while the wasm code is paused.
+// The breakpoint is restricted to the contract 'scName' and to the state key 'key', unless they are empty.
+// Host functions are the wasmproc.Host* constants, for example wasmproc.HostStateSet.
+// The breakpoint is removed with the returned function or at the end of the test
+func (ch *Chain) AddWasmBreakpoint(hostFunction string, scName string, key kv.Key, callback func(ctx *wasmproc.DebugContext)) func() {
+	chainID := ch.ChainID
+	bp := &wasmproc.Breakpoint{
+		HostFunction: hostFunction,
+		ChainID:      &chainID,
+		Key:          key,
+		Callback:     callback,
+	}
+	if scName != "" {
+		bp.Contract = coretypes.Hn(scName)
+	}
+	remove := wasmproc.AddBreakpoint(bp)
+	ch.Env.T.Cleanup(remove)
+	return remove
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package solo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/stretchr/testify/require"
+)
+
+// The file contains the helper which downloads published wasm binaries of example contracts and of the SDK
+// by version tag, so that test suites don't keep wasm binaries in the repository.
+// Each binary is pinned by its SHA-256 checksum and is cached locally, so tests run offline once
+// the binaries are in the cache
+
+const (
+	// WasmCacheEnvVar is the directory of the cache of downloaded wasm binaries.
+	// By default it is 'wasp/solo-wasm' in the user cache directory
+	WasmCacheEnvVar = "SOLO_WASM_CACHE"
+	// WasmOfflineEnvVar disables downloads when set to any non-empty value: binaries must be in the cache
+	WasmOfflineEnvVar = "SOLO_WASM_OFFLINE"
+	// WasmBaseURLEnvVar overrides the default WasmBaseURL, for example with the URL of a mirror
+	WasmBaseURLEnvVar = "SOLO_WASM_BASE_URL"
+)
+
+// WasmBaseURL is the location of published wasm binaries. The binary of the WasmArtifact without URL
+// is downloaded from <WasmBaseURL>/<Version>/<Name>_bg.wasm
+var WasmBaseURL = "https://github.com/iotaledger/wasp/releases/download"
+
+// WasmFetchTimeout is the timeout of the download of one wasm binary
+var WasmFetchTimeout = 2 * time.Minute
+
+// WasmArtifact is the published wasm binary pinned by the checksum
+type WasmArtifact struct {
+	// Name is the name of the contract, for example 'fairroulette'
+	Name string
+	// Version is the version tag of the release, for example 'v0.1.0'
+	Version string
+	// SHA256 is the hex-encoded SHA-256 checksum of the binary. Mandatory
+	SHA256 string
+	// URL overrides the location of the binary derived from WasmBaseURL
+	URL string
+}
+
+func (a *WasmArtifact) url() string {
+	if a.URL != "" {
+		return a.URL
+	}
+	base := WasmBaseURL
+	if s := os.Getenv(WasmBaseURLEnvVar); s != "" {
+		base = s
+	}
+	return fmt.Sprintf("%s/%s/%s_bg.wasm", strings.TrimSuffix(base, "/"), a.Version, a.Name)
+}
+
+func (a *WasmArtifact) String() string {
+	return fmt.Sprintf("%s@%s", a.Name, a.Version)
+}
+
+func (a *WasmArtifact) checkSum(binary []byte) error {
+	h := sha256.Sum256(binary)
+	if sum := hex.EncodeToString(h[:]); !strings.EqualFold(sum, a.SHA256) {
+		return fmt.Errorf("checksum mismatch of wasm binary %s: expected %s, got %s", a.String(), a.SHA256, sum)
+	}
+	return nil
+}
+
+// WasmCacheDir returns the directory of the cache of downloaded wasm binaries, see WasmCacheEnvVar
+func WasmCacheDir() (string, error) {
+	if dir := os.Getenv(WasmCacheEnvVar); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("can't locate the cache of wasm binaries, set %s: %v", WasmCacheEnvVar, err)
+	}
+	return filepath.Join(dir, "wasp", "solo-wasm"), nil
+}
+
+// FetchWasm returns the wasm binary of the artifact from the local cache, or downloads it into the cache.
+// The binary is rejected if its checksum differs from the pinned one, both in the cache and downloaded.
+// With SOLO_WASM_OFFLINE set the binary is never downloaded
+func FetchWasm(a WasmArtifact) ([]byte, error) {
+	if a.Name == "" || a.Version == "" {
+		return nil, fmt.Errorf("name and version of wasm artifact must be specified")
+	}
+	if a.SHA256 == "" {
+		return nil, fmt.Errorf("checksum of wasm binary %s is not pinned", a.String())
+	}
+	dir, err := WasmCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	// the cached file is named by the checksum, so re-pinning a version never picks up a stale binary
+	fname := filepath.Join(dir, a.Name, a.Version, strings.ToLower(a.SHA256)+".wasm")
+	if binary, err := ioutil.ReadFile(fname); err == nil {
+		if err = a.checkSum(binary); err == nil {
+			return binary, nil
+		}
+		// corrupted cache entry: download again
+		_ = os.Remove(fname)
+	}
+	if os.Getenv(WasmOfflineEnvVar) != "" {
+		return nil, fmt.Errorf("wasm binary %s is not in the cache %s and %s is set", a.String(), dir, WasmOfflineEnvVar)
+	}
+	binary, err := downloadWasm(a.url())
+	if err != nil {
+		return nil, fmt.Errorf("can't download wasm binary %s: %v", a.String(), err)
+	}
+	if err = a.checkSum(binary); err != nil {
+		return nil, err
+	}
+	if err = writeFileAtomic(fname, binary); err != nil {
+		return nil, fmt.Errorf("can't cache wasm binary %s: %v", a.String(), err)
+	}
+	return binary, nil
+}
+
+func downloadWasm(url string) ([]byte, error) {
+	client := &http.Client{Timeout: WasmFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// writeFileAtomic writes the file through the temporary file, so that concurrent test runs
+// never see a partially written binary
+func writeFileAtomic(fname string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(fname), filepath.Base(fname)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), fname)
+}
+
+// FetchWasm returns the pinned wasm binary of the artifact, see FetchWasm. Fails the test if the binary
+// can't be fetched
+func (env *Solo) FetchWasm(a WasmArtifact) []byte {
+	binary, err := FetchWasm(a)
+	require.NoError(env.T, err)
+	return binary
+}
+
+// UploadWasmArtifact fetches the pinned wasm binary of the artifact and uploads it into the blob registry of the chain
+func (ch *Chain) UploadWasmArtifact(sigScheme signaturescheme.SignatureScheme, a WasmArtifact) (hashing.HashValue, error) {
+	binary, err := FetchWasm(a)
+	if err != nil {
+		return hashing.NilHash, err
+	}
+	return ch.UploadWasm(sigScheme, binary)
+}
+
+// DeployWasmArtifact fetches the pinned wasm binary of the artifact and deploys it on the chain
+// as the contract with the name. It is the same as DeployWasmContract, but for published binaries
+func (ch *Chain) DeployWasmArtifact(sigScheme signaturescheme.SignatureScheme, name string, a WasmArtifact, params ...interface{}) error {
+	hprog, err := ch.UploadWasmArtifact(sigScheme, a)
+	if err != nil {
+		return err
+	}
+	return ch.DeployContract(sigScheme, name, hprog, params...)
+}
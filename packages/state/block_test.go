@@ -1,6 +1,7 @@
 package state
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -69,3 +70,29 @@ func TestBatchMarshaling(t *testing.T) {
 
 	assert.EqualValues(t, util.GetHashValue(batch1), util.GetHashValue(batch2))
 }
+
+func TestWriteReadBlocks(t *testing.T) {
+	txid := (transaction.ID)(hashing.HashStrings("test string 1"))
+	reqid := coretypes.NewRequestID(txid, 0)
+	su := NewStateUpdate(&reqid)
+	su.Mutations().Add(buffered.NewMutationSet("k", []byte{1}))
+	b1, err := NewBlock([]StateUpdate{su})
+	assert.NoError(t, err)
+	b1.WithBlockIndex(1).WithStateTransaction(txid)
+	blocks := []Block{MustNewOriginBlock(nil), b1}
+
+	var buf bytes.Buffer
+	err = WriteBlocks(&buf, blocks)
+	assert.NoError(t, err)
+
+	back, err := ReadBlocks(&buf)
+	assert.NoError(t, err)
+	assert.Len(t, back, 2)
+	for i := range blocks {
+		assert.EqualValues(t, util.GetHashValue(blocks[i]), util.GetHashValue(back[i]))
+		assert.EqualValues(t, blocks[i].StateTransactionID(), back[i].StateTransactionID())
+	}
+
+	_, err = ReadBlocks(bytes.NewReader([]byte("garbage")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package state
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/parameters"
+)
+
+// The read cache keeps values of recently read state variables of the chain in memory, in front of the database.
+// Hot keys, like the registry of tokens queried by every request, are then read from the database once.
+// All virtual states of the chain loaded from the database (by the state manager, view calls and the webapi)
+// share the cache of the chain. The cache mirrors the solid state in the database: uncommitted mutations
+// of the virtual state are never cached, and keys written by CommitToDb are invalidated after the commit.
+// The least recently used key is evicted when the cache is full. Absent keys are cached too
+
+// ReadCacheStats are metrics of the read cache of the chain
+type ReadCacheStats struct {
+	// Capacity is the maximum number of keys in the cache. 0 means the cache is disabled
+	Capacity int
+	// Size is the number of keys in the cache
+	Size int
+	// Hits is the number of reads served from the cache
+	Hits uint64
+	// Misses is the number of reads served from the database
+	Misses uint64
+	// Invalidations is the number of keys removed from the cache by commits of blocks
+	Invalidations uint64
+}
+
+// HitRate returns the share of reads served from the cache, from 0 to 1
+func (s ReadCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+type readCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[kv.Key]*list.Element
+	lru      *list.List // front is the most recently used
+	// incremented by each invalidation. A value read from the database is cached only if there was no
+	// invalidation during the read, otherwise the value may be older than the commit
+	generation uint64
+	stats      ReadCacheStats
+}
+
+type readCacheEntry struct {
+	key   kv.Key
+	value []byte // nil if the key is absent
+}
+
+func newReadCache(capacity int) *readCache {
+	return &readCache{
+		capacity: capacity,
+		entries:  make(map[kv.Key]*list.Element),
+		lru:      list.New(),
+		stats:    ReadCacheStats{Capacity: capacity},
+	}
+}
+
+// get returns the cached value of the key and the generation of the cache for the following put
+func (c *readCache) get(key kv.Key) ([]byte, bool, uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false, c.generation
+	}
+	c.stats.Hits++
+	c.lru.MoveToFront(e)
+	return e.Value.(*readCacheEntry).value, true, c.generation
+}
+
+// put caches the value read from the database, unless the cache was invalidated after the generation
+func (c *readCache) put(key kv.Key, value []byte, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*readCacheEntry).value = value
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&readCacheEntry{key: key, value: value})
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Remove(c.lru.Back()).(*readCacheEntry)
+		delete(c.entries, oldest.key)
+	}
+}
+
+// invalidate removes the keys from the cache
+func (c *readCache) invalidate(keys []kv.Key) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generation++
+	for _, key := range keys {
+		if e, ok := c.entries[key]; ok {
+			c.lru.Remove(e)
+			delete(c.entries, key)
+			c.stats.Invalidations++
+		}
+	}
+}
+
+func (c *readCache) getStats() ReadCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	ret := c.stats
+	ret.Size = c.lru.Len()
+	return ret
+}
+
+// cachedKVStore reads state variables through the read cache. Other operations go to the database
+type cachedKVStore struct {
+	kvstore.KVStore
+	cache *readCache
+}
+
+func (s *cachedKVStore) Get(key kvstore.Key) (kvstore.Value, error) {
+	value, ok, generation := s.cache.get(kv.Key(key))
+	if !ok {
+		v, err := s.KVStore.Get(key)
+		if err != nil && err != kvstore.ErrKeyNotFound {
+			return nil, err
+		}
+		value = v
+		s.cache.put(kv.Key(key), value, generation)
+	}
+	if value == nil {
+		return nil, kvstore.ErrKeyNotFound
+	}
+	// the caller may modify the value
+	return append([]byte(nil), value...), nil
+}
+
+func (s *cachedKVStore) Has(key kvstore.Key) (bool, error) {
+	value, ok, _ := s.cache.get(kv.Key(key))
+	if ok {
+		return value != nil, nil
+	}
+	return s.KVStore.Has(key)
+}
+
+var (
+	readCaches      = make(map[coretypes.ChainID]*readCache)
+	readCachesMutex sync.Mutex
+)
+
+// chainReadCache returns the read cache of the chain or nil if the cache is disabled
+func chainReadCache(chainID *coretypes.ChainID) *readCache {
+	readCachesMutex.Lock()
+	defer readCachesMutex.Unlock()
+
+	if c, ok := readCaches[*chainID]; ok {
+		return c
+	}
+	var ret *readCache
+	if capacity := parameters.GetInt(parameters.DatabaseStateCacheSize); capacity > 0 {
+		ret = newReadCache(capacity)
+	}
+	readCaches[*chainID] = ret
+	return ret
+}
+
+// GetReadCacheStats returns metrics of the read cache of state variables of the chain
+func GetReadCacheStats(chainID *coretypes.ChainID) ReadCacheStats {
+	c := chainReadCache(chainID)
+	if c == nil {
+		return ReadCacheStats{}
+	}
+	return c.getStats()
+}
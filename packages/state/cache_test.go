@@ -0,0 +1,104 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/packages/database"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCacheLRU(t *testing.T) {
+	c := newReadCache(2)
+	_, ok, gen := c.get("a")
+	assert.False(t, ok)
+	c.put("a", []byte{1}, gen)
+	c.put("b", nil, gen)
+
+	v, ok, _ := c.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte{1}, v)
+
+	// "b" is the least recently used
+	c.put("c", []byte{3}, gen)
+	_, ok, _ = c.get("b")
+	assert.False(t, ok)
+	_, ok, _ = c.get("a")
+	assert.True(t, ok)
+
+	stats := c.getStats()
+	assert.EqualValues(t, 2, stats.Size)
+	assert.EqualValues(t, 2, stats.Hits)
+	assert.EqualValues(t, 2, stats.Misses)
+	assert.EqualValues(t, 0.5, stats.HitRate())
+}
+
+func TestReadCacheStaleRead(t *testing.T) {
+	c := newReadCache(10)
+	_, _, gen := c.get("a")
+	// the key is committed while the old value is being read from the db
+	c.invalidate([]kv.Key{"a"})
+	c.put("a", []byte{1}, gen)
+	_, ok, _ := c.get("a")
+	assert.False(t, ok)
+}
+
+func TestReadCacheCommit(t *testing.T) {
+	tmpdb, _ := database.NewMemDB()
+	partition := tmpdb.NewStore().WithRealm([]byte("2"))
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+	cache := newReadCache(10)
+
+	txid1 := (transaction.ID)(hashing.HashStrings("test string 1"))
+	reqid1 := coretypes.NewRequestID(txid1, 5)
+	su1 := NewStateUpdate(&reqid1)
+	su1.Mutations().Add(buffered.NewMutationSet("x", []byte{1}))
+	batch1, err := NewBlock([]StateUpdate{su1})
+	assert.NoError(t, err)
+
+	vs := newVirtualState(partition, &chainID, cache)
+	err = vs.ApplyBlock(batch1)
+	assert.NoError(t, err)
+	err = vs.CommitToDb(batch1)
+	assert.NoError(t, err)
+
+	// the view call reads the committed value through the cache
+	view, _, _, err := loadSolidState(partition, &chainID, cache)
+	assert.NoError(t, err)
+	v, _ := view.Variables().Get("x")
+	assert.Equal(t, []byte{1}, v)
+	v, _ = view.Variables().Get("y")
+	assert.Nil(t, v)
+	assert.EqualValues(t, 2, cache.getStats().Size)
+	v, _ = view.Variables().Get("x")
+	assert.Equal(t, []byte{1}, v)
+	assert.EqualValues(t, 1, cache.getStats().Hits)
+
+	// uncommitted mutations are not cached
+	txid2 := (transaction.ID)(hashing.HashStrings("test string 2"))
+	reqid2 := coretypes.NewRequestID(txid2, 6)
+	su2 := NewStateUpdate(&reqid2)
+	su2.Mutations().Add(buffered.NewMutationSet("x", []byte{2}))
+	su2.Mutations().Add(buffered.NewMutationSet("y", []byte{3}))
+	batch2, err := NewBlock([]StateUpdate{su2})
+	assert.NoError(t, err)
+	batch2.WithBlockIndex(1)
+	err = vs.ApplyBlock(batch2)
+	assert.NoError(t, err)
+	v, _ = view.Variables().Get("x")
+	assert.Equal(t, []byte{1}, v)
+
+	// the commit invalidates written keys
+	err = vs.CommitToDb(batch2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cache.getStats().Size)
+	assert.EqualValues(t, 2, cache.getStats().Invalidations)
+	v, _ = view.Variables().Get("x")
+	assert.Equal(t, []byte{2}, v)
+	v, _ = view.Variables().Get("y")
+	assert.Equal(t, []byte{3}, v)
+}
@@ -0,0 +1,86 @@
+package state
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// historyMagic marks the beginning of the serialized sequence of blocks
+var historyMagic = []byte("WASPBLKS")
+
+// WriteBlocks serializes the sequence of blocks of the chain, for example to export the history of the chain
+// for replaying it elsewhere
+func WriteBlocks(w io.Writer, blocks []Block) error {
+	if _, err := w.Write(historyMagic); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, uint32(len(blocks))); err != nil {
+		return err
+	}
+	for _, b := range blocks {
+		var buf bytes.Buffer
+		if err := b.Write(&buf); err != nil {
+			return err
+		}
+		if err := util.WriteBytes32(w, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadBlocks is the reverse of WriteBlocks
+func ReadBlocks(r io.Reader) ([]Block, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(data, historyMagic) {
+		return nil, fmt.Errorf("not a sequence of blocks")
+	}
+	rdr := bytes.NewReader(data[len(historyMagic):])
+	var n uint32
+	if err := util.ReadUint32(rdr, &n); err != nil {
+		return nil, err
+	}
+	ret := make([]Block, n)
+	for i := range ret {
+		blockBytes, err := util.ReadBytes32(rdr)
+		if err != nil {
+			return nil, err
+		}
+		if ret[i], err = NewBlockFromBytes(blockBytes); err != nil {
+			return nil, fmt.Errorf("block #%d: %v", i, err)
+		}
+	}
+	return ret, nil
+}
+
+// LoadBlocks loads blocks of the chain with indices from 'from' to 'to' inclusive from the database of the node.
+// Returns error if any of the blocks is not in the database
+func LoadBlocks(chainID *coretypes.ChainID, from, to uint32) ([]Block, error) {
+	if from > to {
+		return nil, fmt.Errorf("wrong block range %d..%d", from, to)
+	}
+	ret := make([]Block, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		b, err := LoadBlock(chainID, i)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			return nil, fmt.Errorf("block #%d not found", i)
+		}
+		ret = append(ret, b)
+		if i == to {
+			// prevents overflow when to == math.MaxUint32
+			break
+		}
+	}
+	return ret, nil
+}
@@ -23,19 +23,30 @@ type virtualState struct {
 	empty      bool
 	stateHash  hashing.HashValue
 	variables  buffered.BufferedKVStore
+	// read cache of state variables in the db, nil if not cached. See cache.go
+	cache *readCache
 }
 
 func NewVirtualState(db kvstore.KVStore, chainID *coretypes.ChainID) *virtualState {
+	return newVirtualState(db, chainID, nil)
+}
+
+func newVirtualState(db kvstore.KVStore, chainID *coretypes.ChainID, cache *readCache) *virtualState {
+	vars := subRealm(db, []byte{dbprovider.ObjectTypeStateVariable})
+	if vars != nil && cache != nil {
+		vars = &cachedKVStore{KVStore: vars, cache: cache}
+	}
 	return &virtualState{
 		chainID:   *chainID,
 		db:        db,
-		variables: buffered.NewBufferedKVStore(subRealm(db, []byte{dbprovider.ObjectTypeStateVariable})),
+		variables: buffered.NewBufferedKVStore(vars),
 		empty:     true,
+		cache:     cache,
 	}
 }
 
 func NewEmptyVirtualState(chainID *coretypes.ChainID) *virtualState {
-	return NewVirtualState(getSCPartition(chainID), chainID)
+	return newVirtualState(getSCPartition(chainID), chainID, chainReadCache(chainID))
 }
 
 func getSCPartition(chainID *coretypes.ChainID) kvstore.KVStore {
@@ -58,6 +69,7 @@ func (vs *virtualState) Clone() VirtualState {
 		empty:      vs.empty,
 		stateHash:  vs.stateHash,
 		variables:  vs.variables.Clone(),
+		cache:      vs.cache,
 	}
 }
 
@@ -180,9 +192,31 @@ func (vs *virtualState) CommitToDb(b Block) error {
 		values = append(values, []byte{0})
 	}
 
+	// apply uncommitted prefix deletions. Keys set after the deletion are stored below
+	mutations := vs.variables.Mutations()
+	// state variables to invalidate in the read cache
+	var varKeys []kv.Key
+	var iterErr error
+	mutations.IterateDeletedPrefixes(func(prefix kv.Key) bool {
+		iterErr = vs.db.IterateKeys(dbkeyStateVariable(prefix), func(key kvstore.Key) bool {
+			k := kv.Key(key[len(dbkeyStateVariable("")):])
+			if mutations.IsDeletedByPrefix(k) {
+				keys = append(keys, append([]byte(nil), key...))
+				values = append(values, nil)
+				varKeys = append(varKeys, k)
+			}
+			return true
+		})
+		return iterErr == nil
+	})
+	if iterErr != nil {
+		return iterErr
+	}
+
 	// store uncommitted mutations
 	vs.variables.Mutations().IterateLatest(func(k kv.Key, mut buffered.Mutation) bool {
 		keys = append(keys, dbkeyStateVariable(k))
+		varKeys = append(varKeys, k)
 
 		// if mutation is MutationDel, mut.Value() = nil and the key is deleted
 		values = append(values, mut.Value())
@@ -193,15 +227,18 @@ func (vs *virtualState) CommitToDb(b Block) error {
 	if err != nil {
 		return err
 	}
+	if vs.cache != nil {
+		vs.cache.invalidate(varKeys)
+	}
 	vs.variables.ClearMutations()
 	return nil
 }
 
 func LoadSolidState(chainID *coretypes.ChainID) (VirtualState, Block, bool, error) {
-	return loadSolidState(getSCPartition(chainID), chainID)
+	return loadSolidState(getSCPartition(chainID), chainID, chainReadCache(chainID))
 }
 
-func loadSolidState(db kvstore.KVStore, chainID *coretypes.ChainID) (VirtualState, Block, bool, error) {
+func loadSolidState(db kvstore.KVStore, chainID *coretypes.ChainID, cache *readCache) (VirtualState, Block, bool, error) {
 	stateIndexBin, err := db.Get(dbprovider.MakeKey(dbprovider.ObjectTypeSolidStateIndex))
 	if err == kvstore.ErrKeyNotFound {
 		return nil, nil, false, nil
@@ -217,7 +254,7 @@ func loadSolidState(db kvstore.KVStore, chainID *coretypes.ChainID) (VirtualStat
 		return nil, nil, false, err
 	}
 
-	vs := NewVirtualState(db, chainID)
+	vs := newVirtualState(db, chainID, cache)
 	if err = vs.Read(bytes.NewReader(values[0])); err != nil {
 		return nil, nil, false, fmt.Errorf("loading variable state: %v", err)
 	}
@@ -193,7 +193,7 @@ func TestCommit(t *testing.T) {
 	v, _ = partition.Get(dbkeyStateVariable(kv.Key([]byte("x"))))
 	assert.Equal(t, []byte{1}, v)
 
-	vs1_2, batch1_2, _, err := loadSolidState(partition, &chainID)
+	vs1_2, batch1_2, _, err := loadSolidState(partition, &chainID, nil)
 
 	assert.NoError(t, err)
 	assert.EqualValues(t, util.GetHashValue(batch1), util.GetHashValue(batch1_2))
@@ -228,3 +228,56 @@ func TestCommit(t *testing.T) {
 	v, _ = partition.Get(dbkeyStateVariable(kv.Key([]byte("x"))))
 	assert.Nil(t, v)
 }
+
+func TestCommitDelPrefix(t *testing.T) {
+	tmpdb, _ := database.NewMemDB()
+	db := tmpdb.NewStore()
+
+	partition := db.WithRealm([]byte("2"))
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+
+	txid1 := (transaction.ID)(hashing.HashStrings("test string 1"))
+	reqid1 := coretypes.NewRequestID(txid1, 5)
+	su1 := NewStateUpdate(&reqid1)
+	su1.Mutations().Add(buffered.NewMutationSet("a1", []byte{1}))
+	su1.Mutations().Add(buffered.NewMutationSet("a2", []byte{2}))
+	su1.Mutations().Add(buffered.NewMutationSet("b1", []byte{3}))
+
+	batch1, err := NewBlock([]StateUpdate{su1})
+	assert.NoError(t, err)
+	vs := NewVirtualState(partition, &chainID)
+	err = vs.ApplyBlock(batch1)
+	assert.NoError(t, err)
+	err = vs.CommitToDb(batch1)
+	assert.NoError(t, err)
+
+	txid2 := (transaction.ID)(hashing.HashStrings("test string 2"))
+	reqid2 := coretypes.NewRequestID(txid2, 6)
+	su2 := NewStateUpdate(&reqid2)
+	su2.Mutations().Add(buffered.NewMutationDelPrefix("a"))
+	su2.Mutations().Add(buffered.NewMutationSet("a3", []byte{4}))
+
+	batch2, err := NewBlock([]StateUpdate{su2})
+	assert.NoError(t, err)
+	batch2.WithBlockIndex(1)
+	err = vs.ApplyBlock(batch2)
+	assert.NoError(t, err)
+
+	v, _ := vs.Variables().Get("a1")
+	assert.Nil(t, v)
+	// not committed yet
+	v, _ = partition.Get(dbkeyStateVariable("a1"))
+	assert.Equal(t, []byte{1}, v)
+
+	err = vs.CommitToDb(batch2)
+	assert.NoError(t, err)
+
+	v, _ = partition.Get(dbkeyStateVariable("a1"))
+	assert.Nil(t, v)
+	v, _ = partition.Get(dbkeyStateVariable("a2"))
+	assert.Nil(t, v)
+	v, _ = partition.Get(dbkeyStateVariable("a3"))
+	assert.Equal(t, []byte{4}, v)
+	v, _ = partition.Get(dbkeyStateVariable("b1"))
+	assert.Equal(t, []byte{3}, v)
+}
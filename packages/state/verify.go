@@ -0,0 +1,81 @@
+package state
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/iotaledger/hive.go/kvstore"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+// ErrStateCorrupted means the state of the chain stored in the DB is inconsistent
+var ErrStateCorrupted = errors.New("state is corrupted")
+
+// VerifySolidState replays in memory all blocks of the chain stored in the DB, starting from the origin,
+// and checks the result against the solid state stored in the DB: the state hash and all variables must be equal.
+// The optional 'onBlock' is called with each replayed block and the state after it, to check intermediate
+// states. The error returned by 'onBlock' stops the verification.
+// Returns the solid state, nil if there's no state in the DB. Errors of inconsistent state wrap ErrStateCorrupted
+func VerifySolidState(chainID *coretypes.ChainID, onBlock func(vs VirtualState, b Block) error) (VirtualState, error) {
+	return verifySolidState(getSCPartition(chainID), chainID, onBlock)
+}
+
+func verifySolidState(db kvstore.KVStore, chainID *coretypes.ChainID, onBlock func(vs VirtualState, b Block) error) (VirtualState, error) {
+	solidState, _, exists, err := loadSolidState(db, chainID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrStateCorrupted, err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	replayed := NewVirtualState(mapdb.NewMapDB(), chainID)
+	for i := uint32(0); i <= solidState.BlockIndex(); i++ {
+		b, err := loadBlock(db, i)
+		if err != nil {
+			return nil, fmt.Errorf("%w: block #%d: %v", ErrStateCorrupted, i, err)
+		}
+		if b == nil {
+			return nil, fmt.Errorf("%w: block #%d not found", ErrStateCorrupted, i)
+		}
+		if err := replayed.ApplyBlock(b); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrStateCorrupted, err)
+		}
+		if onBlock != nil {
+			if err := onBlock(replayed, b); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if replayed.Hash() != solidState.Hash() {
+		return nil, fmt.Errorf("%w: state hash %s of the state #%d is not equal to the hash %s of the replayed blocks",
+			ErrStateCorrupted, solidState.Hash().String(), solidState.BlockIndex(), replayed.Hash().String())
+	}
+
+	// the replayed state is not committed, all its variables are mutations
+	expected := dict.New()
+	replayed.Variables().Mutations().ApplyTo(expected)
+	var mismatch error
+	found := 0
+	err = solidState.Variables().Iterate("", func(key kv.Key, value []byte) bool {
+		if !bytes.Equal(expected[key], value) {
+			mismatch = fmt.Errorf("%w: wrong value of the variable '%s'", ErrStateCorrupted, key)
+			return false
+		}
+		found++
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mismatch != nil {
+		return nil, mismatch
+	}
+	if found != len(expected) {
+		return nil, fmt.Errorf("%w: %d variable(s) are missing", ErrStateCorrupted, len(expected)-found)
+	}
+	return solidState, nil
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/kvstore/mapdb"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySolidState(t *testing.T) {
+	db := mapdb.NewMapDB()
+	chainID := coretypes.ChainID{1, 3, 3, 7}
+
+	vs, err := verifySolidState(db, &chainID, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, vs)
+
+	vs1 := NewVirtualState(db, &chainID)
+	origin := MustNewOriginBlock(nil)
+	assert.NoError(t, vs1.ApplyBlock(origin))
+	assert.NoError(t, vs1.CommitToDb(origin))
+
+	txid := (transaction.ID)(hashing.HashStrings("test string 1"))
+	reqid := coretypes.NewRequestID(txid, 0)
+	su := NewStateUpdate(&reqid)
+	su.Mutations().Add(buffered.NewMutationSet("x", []byte{1}))
+	su.Mutations().Add(buffered.NewMutationSet("y", []byte{2}))
+	b1, err := NewBlock([]StateUpdate{su})
+	assert.NoError(t, err)
+	b1.WithBlockIndex(1).WithStateTransaction(txid)
+	assert.NoError(t, vs1.ApplyBlock(b1))
+	assert.NoError(t, vs1.CommitToDb(b1))
+
+	hashes := make([]hashing.HashValue, 0)
+	vs, err = verifySolidState(db, &chainID, func(vs VirtualState, b Block) error {
+		hashes = append(hashes, vs.Hash())
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, vs.BlockIndex())
+	assert.EqualValues(t, vs1.Hash(), vs.Hash())
+	assert.Len(t, hashes, 2)
+	assert.EqualValues(t, vs1.Hash(), hashes[1])
+
+	// silently corrupted variable
+	assert.NoError(t, db.Set(dbkeyStateVariable("y"), []byte{3}))
+	_, err = verifySolidState(db, &chainID, nil)
+	assert.True(t, errors.Is(err, ErrStateCorrupted))
+
+	// lost variable
+	assert.NoError(t, db.Delete(dbkeyStateVariable("y")))
+	_, err = verifySolidState(db, &chainID, nil)
+	assert.True(t, errors.Is(err, ErrStateCorrupted))
+
+	// lost block
+	assert.NoError(t, db.Set(dbkeyStateVariable("y"), []byte{2}))
+	_, err = verifySolidState(db, &chainID, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Delete(dbkeyBatch(0)))
+	_, err = verifySolidState(db, &chainID, nil)
+	assert.True(t, errors.Is(err, ErrStateCorrupted))
+}
@@ -211,25 +211,27 @@ func (s *DKShare) VerifyOwnSigShare(data []byte, sigshare tbdn.SigShare) error {
 }
 
 // VerifyMasterSignature checks signature against master public key
-// NOTE: Not used.
 func (s *DKShare) VerifyMasterSignature(data []byte, signature []byte) error {
 	return bdn.Verify(s.suite, s.SharedPublic, data, signature) // TODO: [KP] Why not `tbdn`.
 }
 
+// RecoverMasterSignature recovers the signature of the master key from partial sigshares.
+// Returns the plain BLS signature, which can be verified with VerifyMasterSignature
+func (s *DKShare) RecoverMasterSignature(sigShares [][]byte, data []byte) ([]byte, error) {
+	if s.N > 1 {
+		pubPoly := share.NewPubPoly(s.suite, nil, s.PublicCommits)
+		return tbdn.Recover(s.suite, pubPoly, data, sigShares, int(s.T), int(s.N))
+	}
+	singleSigShare := tbdn.SigShare(sigShares[0])
+	return singleSigShare.Value(), nil
+}
+
 // RecoverFullSignature generates (recovers) master signature from partial sigshares.
 // returns signature as defined in the value Tangle
 func (s *DKShare) RecoverFullSignature(sigShares [][]byte, data []byte) (signaturescheme.Signature, error) {
-	var err error
-	var recoveredSignature []byte
-	if s.N > 1 {
-		pubPoly := share.NewPubPoly(s.suite, nil, s.PublicCommits)
-		recoveredSignature, err = tbdn.Recover(s.suite, pubPoly, data, sigShares, int(s.T), int(s.N))
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		singleSigShare := tbdn.SigShare(sigShares[0])
-		recoveredSignature = singleSigShare.Value()
+	recoveredSignature, err := s.RecoverMasterSignature(sigShares, data)
+	if err != nil {
+		return nil, err
 	}
 	pubKeyBin, err := s.SharedPublic.MarshalBinary()
 	if err != nil {
@@ -0,0 +1,511 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package tcrypto
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/util"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/group/edwards25519"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+// FROST (Flexible Round-Optimized Schnorr Threshold signatures) over Ed25519.
+// Signing takes two rounds. First each signer commits to a pair of fresh nonces (Commit). Then each signer
+// of the signing set signs with commitments of all signers of the set (SignShare). Binding factors derived
+// from the message and all commitments tie each nonce to the particular signing set and message.
+// The challenge is computed as in Ed25519, so the aggregated signature is the plain Ed25519 signature
+// of the shared public key and it controls the ED25519 address on L1.
+// The nonce is erased as soon as the signature share is produced, it is never used twice
+
+var frostSuite = edwards25519.NewBlakeSHA256Ed25519()
+
+const frostCommitmentSize = 64
+
+// FrostShare is the key share of the node for the FROST threshold signature over Ed25519.
+// The committee still signs its internal messages (batch proposals, fair ordering, emergency votes) with
+// the BLS key share, which is stored in the registry under CommitteeAddress
+type FrostShare struct {
+	Address          *address.Address
+	CommitteeAddress *address.Address
+	Index            *uint16
+	N                uint16
+	T                uint16
+	SharedPublic     kyber.Point
+	PublicShares     []kyber.Point
+	PrivateShare     kyber.Scalar
+}
+
+// frostSigner is the ThresholdSigner of the FROST key share. It keeps the nonce of the node between
+// the commitment and the signature share
+type frostSigner struct {
+	share      *FrostShare
+	nonceMutex sync.Mutex
+	nonce      *frostNonce
+}
+
+// NewFrostSigner returns the ThresholdSigner of the FROST key share
+func NewFrostSigner(frostShare *FrostShare) ThresholdSigner {
+	return &frostSigner{share: frostShare}
+}
+
+// frostNonce is the secret pair of nonces of the node and the commitment to it
+type frostNonce struct {
+	hiding     kyber.Scalar
+	binding    kyber.Scalar
+	commitment []byte
+}
+
+// NewFrostShare creates the key share of the node
+func NewFrostShare(
+	index uint16,
+	n uint16,
+	t uint16,
+	sharedPublic kyber.Point,
+	publicShares []kyber.Point,
+	privateShare kyber.Scalar,
+	committeeAddress *address.Address,
+) (*FrostShare, error) {
+	addr, err := frostAddress(sharedPublic)
+	if err != nil {
+		return nil, err
+	}
+	return &FrostShare{
+		Address:          addr,
+		CommitteeAddress: committeeAddress,
+		Index:            &index,
+		N:                n,
+		T:                t,
+		SharedPublic:     sharedPublic,
+		PublicShares:     publicShares,
+		PrivateShare:     privateShare,
+	}, nil
+}
+
+// DealFrostShares generates the new key and splits it into n shares with the threshold t.
+// The dealer knows the whole key, so it must be trusted by the committee: nodes run chains with dealt
+// keys only for testing and development, see parameters.ConsensusDealtFrostKeys.
+// The DKG of the node generates BLS keys only
+func DealFrostShares(n, t uint16, committeeAddress *address.Address) ([]*FrostShare, error) {
+	if n < 1 || t < 1 || t > n {
+		return nil, fmt.Errorf("wrong parameters: N = %d, T = %d", n, t)
+	}
+	priPoly := share.NewPriPoly(frostSuite, int(t), nil, frostSuite.RandomStream())
+	pubPoly := priPoly.Commit(nil)
+	publicShares := make([]kyber.Point, n)
+	for i := range publicShares {
+		publicShares[i] = pubPoly.Eval(i).V
+	}
+	ret := make([]*FrostShare, n)
+	for i, priShare := range priPoly.Shares(int(n)) {
+		s, err := NewFrostShare(uint16(i), n, t, pubPoly.Commit(), publicShares, priShare.V, committeeAddress)
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = s
+	}
+	return ret, nil
+}
+
+// FrostShareFromBytes reads the share from bytes
+func FrostShareFromBytes(buf []byte) (*FrostShare, error) {
+	s := &FrostShare{}
+	if err := s.Read(bytes.NewReader(buf)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Bytes returns byte representation of the share
+func (s *FrostShare) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *FrostShare) Write(w io.Writer) error {
+	if err := util.WriteBytes16(w, s.Address.Bytes()); err != nil {
+		return err
+	}
+	if err := util.WriteBytes16(w, s.CommitteeAddress.Bytes()); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, *s.Index); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, s.N); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, s.T); err != nil {
+		return err
+	}
+	if err := util.WriteMarshaled(w, s.SharedPublic); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(len(s.PublicShares))); err != nil {
+		return err
+	}
+	for i := range s.PublicShares {
+		if err := util.WriteMarshaled(w, s.PublicShares[i]); err != nil {
+			return err
+		}
+	}
+	return util.WriteMarshaled(w, s.PrivateShare)
+}
+
+func (s *FrostShare) Read(r io.Reader) error {
+	var err error
+	if s.Address, err = readAddress(r); err != nil {
+		return err
+	}
+	if s.CommitteeAddress, err = readAddress(r); err != nil {
+		return err
+	}
+	var index uint16
+	if err = util.ReadUint16(r, &index); err != nil {
+		return err
+	}
+	s.Index = &index
+	if err = util.ReadUint16(r, &s.N); err != nil {
+		return err
+	}
+	if err = util.ReadUint16(r, &s.T); err != nil {
+		return err
+	}
+	s.SharedPublic = frostSuite.Point()
+	if err = util.ReadMarshaled(r, s.SharedPublic); err != nil {
+		return err
+	}
+	var arrLen uint16
+	if err = util.ReadUint16(r, &arrLen); err != nil {
+		return err
+	}
+	s.PublicShares = make([]kyber.Point, arrLen)
+	for i := range s.PublicShares {
+		s.PublicShares[i] = frostSuite.Point()
+		if err = util.ReadMarshaled(r, s.PublicShares[i]); err != nil {
+			return err
+		}
+	}
+	s.PrivateShare = frostSuite.Scalar()
+	return util.ReadMarshaled(r, s.PrivateShare)
+}
+
+func readAddress(r io.Reader) (*address.Address, error) {
+	data, err := util.ReadBytes16(r)
+	if err != nil {
+		return nil, err
+	}
+	addr, _, err := address.FromBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	return &addr, nil
+}
+
+// Scheme implements ThresholdSigner
+func (s *frostSigner) Scheme() SigScheme {
+	return SigSchemeFrostEd25519
+}
+
+// Address implements ThresholdSigner
+func (s *frostSigner) Address() *address.Address {
+	return s.share.Address
+}
+
+// NeedsCommitments implements ThresholdSigner
+func (s *frostSigner) NeedsCommitments() bool {
+	return true
+}
+
+// Commit implements ThresholdSigner. The commitment is D || E, the nonces multiplied by the base point
+func (s *frostSigner) Commit() ([]byte, error) {
+	nonce := &frostNonce{
+		hiding:  frostSuite.Scalar().Pick(frostSuite.RandomStream()),
+		binding: frostSuite.Scalar().Pick(frostSuite.RandomStream()),
+	}
+	hidingBytes, err := frostSuite.Point().Mul(nonce.hiding, nil).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	bindingBytes, err := frostSuite.Point().Mul(nonce.binding, nil).MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	nonce.commitment = append(hidingBytes, bindingBytes...)
+
+	s.nonceMutex.Lock()
+	defer s.nonceMutex.Unlock()
+	s.nonce = nonce
+	return nonce.commitment, nil
+}
+
+// VerifyCommitment implements ThresholdSigner
+func (s *frostSigner) VerifyCommitment(commitment []byte) error {
+	_, _, err := parseFrostCommitment(commitment)
+	return err
+}
+
+// SignShare implements ThresholdSigner. The share is z = d + e*rho + lambda*s*c
+func (s *frostSigner) SignShare(data []byte, commitments SigningCommitments) (tbdn.SigShare, error) {
+	s.nonceMutex.Lock()
+	defer s.nonceMutex.Unlock()
+
+	nonce := s.nonce
+	if nonce == nil || !bytes.Equal(commitments[*s.share.Index], nonce.commitment) {
+		return nil, ErrNotInSigningSet
+	}
+	// the nonce is erased before anything is signed with it
+	s.nonce = nil
+
+	ctx, err := s.share.signingContext(data, commitments)
+	if err != nil {
+		return nil, err
+	}
+	lambda := frostLagrange(*s.share.Index, ctx.indices)
+	z := frostSuite.Scalar().Mul(nonce.binding, ctx.rho[*s.share.Index])
+	z = frostSuite.Scalar().Add(nonce.hiding, z)
+	keyPart := frostSuite.Scalar().Mul(lambda, s.share.PrivateShare)
+	keyPart = frostSuite.Scalar().Mul(keyPart, ctx.challenge)
+	z = frostSuite.Scalar().Add(z, keyPart)
+	return encodeFrostSigShare(*s.share.Index, z)
+}
+
+// VerifySigShare implements ThresholdSigner. Checks z*G == D + rho*E + lambda*c*Y_i
+func (s *frostSigner) VerifySigShare(data []byte, sigShare tbdn.SigShare, commitments SigningCommitments) error {
+	index, z, err := decodeFrostSigShare(sigShare)
+	if err != nil {
+		return err
+	}
+	if index >= s.share.N || int(index) >= len(s.share.PublicShares) {
+		return fmt.Errorf("wrong index of the signature share #%d", index)
+	}
+	if _, ok := commitments[index]; !ok {
+		return fmt.Errorf("signer #%d is not in the signing set", index)
+	}
+	ctx, err := s.share.signingContext(data, commitments)
+	if err != nil {
+		return err
+	}
+	lambda := frostLagrange(index, ctx.indices)
+	expected := frostSuite.Point().Mul(ctx.rho[index], ctx.binding[index])
+	expected = frostSuite.Point().Add(ctx.hiding[index], expected)
+	keyPart := frostSuite.Point().Mul(frostSuite.Scalar().Mul(lambda, ctx.challenge), s.share.PublicShares[index])
+	expected = frostSuite.Point().Add(expected, keyPart)
+	if !frostSuite.Point().Mul(z, nil).Equal(expected) {
+		return fmt.Errorf("invalid signature share of signer #%d", index)
+	}
+	return nil
+}
+
+// RecoverFullSignature implements ThresholdSigner. Shares of all signers of the signing set are needed.
+// The signature is R || z, where z is the sum of shares
+func (s *frostSigner) RecoverFullSignature(sigShares [][]byte, data []byte, commitments SigningCommitments) (signaturescheme.Signature, error) {
+	ctx, err := s.share.signingContext(data, commitments)
+	if err != nil {
+		return nil, err
+	}
+	shares := make(map[uint16]kyber.Scalar)
+	for _, sigShare := range sigShares {
+		index, z, err := decodeFrostSigShare(sigShare)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := commitments[index]; !ok {
+			return nil, fmt.Errorf("signer #%d is not in the signing set", index)
+		}
+		shares[index] = z
+	}
+	z := frostSuite.Scalar().Zero()
+	for _, index := range ctx.indices {
+		zi, ok := shares[index]
+		if !ok {
+			return nil, fmt.Errorf("signature share of signer #%d is missing", index)
+		}
+		z = frostSuite.Scalar().Add(z, zi)
+	}
+	rBytes, err := ctx.groupCommitment.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	zBytes, err := z.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pubKeyBytes, err := s.share.SharedPublic.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sigBytes := append(rBytes, zBytes...)
+
+	pubKey, _, err := ed25519.PublicKeyFromBytes(pubKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	sig, _, err := ed25519.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, err
+	}
+	if !pubKey.VerifySignature(data, sig) {
+		return nil, fmt.Errorf("aggregated signature is invalid")
+	}
+	// serialized as the signature of the value Tangle: version of the address, public key, signature
+	var buf bytes.Buffer
+	buf.WriteByte(address.VersionED25519)
+	buf.Write(pubKeyBytes)
+	buf.Write(sigBytes)
+	finalSignature, _, err := signaturescheme.Ed25519SignatureFromBytes(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	if finalSignature.Address() != *s.share.Address {
+		return nil, fmt.Errorf("aggregated signature doesn't match the address %s", s.share.Address.String())
+	}
+	return finalSignature, nil
+}
+
+// frostSigningContext is derived from the message and commitments of the signing set, the same way
+// by each signer and the aggregator
+type frostSigningContext struct {
+	indices []uint16
+	hiding  map[uint16]kyber.Point
+	binding map[uint16]kyber.Point
+	rho     map[uint16]kyber.Scalar
+	// R = sum(D + rho*E)
+	groupCommitment kyber.Point
+	// c = H(R || Y || m), as in Ed25519
+	challenge kyber.Scalar
+}
+
+func (s *FrostShare) signingContext(data []byte, commitments SigningCommitments) (*frostSigningContext, error) {
+	if len(commitments) < int(s.T) {
+		return nil, fmt.Errorf("not enough signers: %d, threshold: %d", len(commitments), s.T)
+	}
+	ret := &frostSigningContext{
+		indices:         commitments.Indices(),
+		hiding:          make(map[uint16]kyber.Point),
+		binding:         make(map[uint16]kyber.Point),
+		rho:             make(map[uint16]kyber.Scalar),
+		groupCommitment: frostSuite.Point().Null(),
+	}
+	encoded := commitments.Bytes()
+	for _, index := range ret.indices {
+		if index >= s.N {
+			return nil, fmt.Errorf("wrong index of the signer #%d", index)
+		}
+		hiding, binding, err := parseFrostCommitment(commitments[index])
+		if err != nil {
+			return nil, fmt.Errorf("wrong commitment of the signer #%d: %v", index, err)
+		}
+		ret.hiding[index] = hiding
+		ret.binding[index] = binding
+		var indexBytes [2]byte
+		binary.BigEndian.PutUint16(indexBytes[:], index)
+		ret.rho[index] = frostHashToScalar([]byte("FROST-Ed25519-rho"), indexBytes[:], data, encoded)
+
+		part := frostSuite.Point().Mul(ret.rho[index], ret.binding[index])
+		part = frostSuite.Point().Add(ret.hiding[index], part)
+		ret.groupCommitment = frostSuite.Point().Add(ret.groupCommitment, part)
+	}
+	rBytes, err := ret.groupCommitment.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pubKeyBytes, err := s.SharedPublic.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	ret.challenge = frostHashToScalar(rBytes, pubKeyBytes, data)
+	return ret, nil
+}
+
+// parseFrostCommitment returns points D and E of the commitment
+func parseFrostCommitment(commitment []byte) (kyber.Point, kyber.Point, error) {
+	if len(commitment) != frostCommitmentSize {
+		return nil, nil, fmt.Errorf("wrong size of the commitment: %d", len(commitment))
+	}
+	hiding := frostSuite.Point()
+	if err := hiding.UnmarshalBinary(commitment[:frostCommitmentSize/2]); err != nil {
+		return nil, nil, err
+	}
+	binding := frostSuite.Point()
+	if err := binding.UnmarshalBinary(commitment[frostCommitmentSize/2:]); err != nil {
+		return nil, nil, err
+	}
+	return hiding, binding, nil
+}
+
+// frostHashToScalar is SHA-512 of the data reduced modulo the group order, as in Ed25519
+func frostHashToScalar(data ...[]byte) kyber.Scalar {
+	h := sha512.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return frostSuite.Scalar().SetBytes(h.Sum(nil))
+}
+
+// frostLagrange is the Lagrange coefficient of the signer at 0. Shares are evaluations at index+1
+func frostLagrange(index uint16, indices []uint16) kyber.Scalar {
+	num := frostSuite.Scalar().One()
+	den := frostSuite.Scalar().One()
+	xi := frostSuite.Scalar().SetInt64(int64(index) + 1)
+	for _, j := range indices {
+		if j == index {
+			continue
+		}
+		xj := frostSuite.Scalar().SetInt64(int64(j) + 1)
+		num = frostSuite.Scalar().Mul(num, xj)
+		den = frostSuite.Scalar().Mul(den, frostSuite.Scalar().Sub(xj, xi))
+	}
+	return frostSuite.Scalar().Div(num, den)
+}
+
+// frostAddress is the ED25519 address of the public key
+func frostAddress(sharedPublic kyber.Point) (*address.Address, error) {
+	pubKeyBytes, err := sharedPublic.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pubKey, _, err := ed25519.PublicKeyFromBytes(pubKeyBytes)
+	if err != nil {
+		return nil, err
+	}
+	ret := address.FromED25519PubKey(pubKey)
+	return &ret, nil
+}
+
+// the signature share is encoded as tbdn.SigShare: 2 bytes of the index and the scalar
+func encodeFrostSigShare(index uint16, z kyber.Scalar) (tbdn.SigShare, error) {
+	zBytes, err := z.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]byte, 2, 2+len(zBytes))
+	binary.BigEndian.PutUint16(ret, index)
+	return append(ret, zBytes...), nil
+}
+
+func decodeFrostSigShare(sigShare tbdn.SigShare) (uint16, kyber.Scalar, error) {
+	index, err := sigShare.Index()
+	if err != nil {
+		return 0, nil, err
+	}
+	z := frostSuite.Scalar()
+	if err := z.UnmarshalBinary(sigShare.Value()); err != nil {
+		return 0, nil, err
+	}
+	return uint16(index), z, nil
+}
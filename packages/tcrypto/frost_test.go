@@ -0,0 +1,132 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package tcrypto
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/stretchr/testify/require"
+)
+
+// frostSignShares commits and signs the data with signers of the set
+func frostSignShares(t *testing.T, signers []ThresholdSigner, set []uint16, data []byte) ([][]byte, SigningCommitments) {
+	commitments := make(SigningCommitments)
+	for _, i := range set {
+		c, err := signers[i].Commit()
+		require.NoError(t, err)
+		require.NoError(t, signers[i].VerifyCommitment(c))
+		commitments[i] = c
+	}
+	sigShares := make([][]byte, 0, len(set))
+	for _, i := range set {
+		sigShare, err := signers[i].SignShare(data, commitments)
+		require.NoError(t, err)
+		sigShares = append(sigShares, sigShare)
+	}
+	return sigShares, commitments
+}
+
+func newTestFrostSigners(t *testing.T, n, threshold uint16) []ThresholdSigner {
+	shares, err := DealFrostShares(n, threshold, &address.Address{address.VersionBLS})
+	require.NoError(t, err)
+	ret := make([]ThresholdSigner, n)
+	for i := range shares {
+		ret[i] = NewFrostSigner(shares[i])
+	}
+	return ret
+}
+
+func TestFrostSignature(t *testing.T) {
+	signers := newTestFrostSigners(t, 4, 3)
+	require.EqualValues(t, address.VersionED25519, signers[0].Address().Version())
+	scheme, err := SigSchemeOfAddress(signers[0].Address())
+	require.NoError(t, err)
+	require.EqualValues(t, SigSchemeFrostEd25519, scheme)
+
+	data := []byte("anchor transaction essence")
+	// any quorum of signers produces the signature of the same address
+	for _, set := range [][]uint16{{0, 1, 2}, {1, 2, 3}, {0, 2, 3}} {
+		sigShares, commitments := frostSignShares(t, signers, set, data)
+		for _, sigShare := range sigShares {
+			require.NoError(t, signers[set[0]].VerifySigShare(data, sigShare, commitments))
+		}
+		sig, err := signers[3].RecoverFullSignature(sigShares, data, commitments)
+		require.NoError(t, err)
+		require.True(t, sig.IsValid(data))
+		require.EqualValues(t, *signers[0].Address(), sig.Address())
+	}
+}
+
+func TestFrostInvalidShares(t *testing.T) {
+	signers := newTestFrostSigners(t, 4, 3)
+	data := []byte("anchor transaction essence")
+	sigShares, commitments := frostSignShares(t, signers, []uint16{0, 1, 2}, data)
+
+	// the share is bound to the message
+	require.Error(t, signers[3].VerifySigShare([]byte("other data"), sigShares[0], commitments))
+
+	// shares of all signers of the set are needed
+	_, err := signers[3].RecoverFullSignature(sigShares[:2], data, commitments)
+	require.Error(t, err)
+
+	// less than the threshold of signers
+	delete(commitments, 2)
+	require.Error(t, signers[3].VerifySigShare(data, sigShares[0], commitments))
+
+	require.Error(t, signers[0].VerifyCommitment([]byte{1, 2, 3}))
+}
+
+func TestFrostNonceUsedOnce(t *testing.T) {
+	signers := newTestFrostSigners(t, 4, 3)
+	data := []byte("anchor transaction essence")
+	_, commitments := frostSignShares(t, signers, []uint16{0, 1, 2}, data)
+
+	// the nonce was erased after the signature share
+	_, err := signers[0].SignShare([]byte("other data"), commitments)
+	require.Equal(t, ErrNotInSigningSet, err)
+
+	// the node which didn't commit is not in the signing set
+	_, err = signers[3].SignShare(data, commitments)
+	require.Equal(t, ErrNotInSigningSet, err)
+
+	// the commitment is replaced by the next one
+	c, err := signers[3].Commit()
+	require.NoError(t, err)
+	_, err = signers[3].Commit()
+	require.NoError(t, err)
+	commitments[3] = c
+	_, err = signers[3].SignShare(data, commitments)
+	require.Equal(t, ErrNotInSigningSet, err)
+}
+
+func TestFrostShareBytes(t *testing.T) {
+	shares, err := DealFrostShares(4, 3, &address.Address{address.VersionBLS})
+	require.NoError(t, err)
+	data, err := shares[2].Bytes()
+	require.NoError(t, err)
+	back, err := FrostShareFromBytes(data)
+	require.NoError(t, err)
+	require.EqualValues(t, *shares[2].Address, *back.Address)
+	require.EqualValues(t, *shares[2].CommitteeAddress, *back.CommitteeAddress)
+	require.EqualValues(t, 2, *back.Index)
+	require.EqualValues(t, 4, back.N)
+	require.EqualValues(t, 3, back.T)
+	require.True(t, shares[2].PrivateShare.Equal(back.PrivateShare))
+	require.True(t, shares[2].SharedPublic.Equal(back.SharedPublic))
+	require.Len(t, back.PublicShares, 4)
+}
+
+func TestSigningCommitmentsBytes(t *testing.T) {
+	commitments := SigningCommitments{3: []byte{1, 2}, 0: []byte{3}}
+	require.EqualValues(t, []uint16{0, 3}, commitments.Indices())
+	back, err := ReadSigningCommitments(bytes.NewReader(commitments.Bytes()))
+	require.NoError(t, err)
+	require.EqualValues(t, commitments, back)
+
+	back, err = ReadSigningCommitments(bytes.NewReader(SigningCommitments(nil).Bytes()))
+	require.NoError(t, err)
+	require.Nil(t, back)
+}
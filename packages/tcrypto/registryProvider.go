@@ -11,5 +11,8 @@ import (
 // It should be implemented by registry.impl
 type RegistryProvider interface {
 	SaveDKShare(dkShare *DKShare) error
+	ReplaceDKShare(dkShare *DKShare) error
 	LoadDKShare(sharedAddress *address.Address) (*DKShare, error)
+	SaveFrostShare(frostShare *FrostShare) error
+	LoadFrostShare(addr *address.Address) (*FrostShare, error)
 }
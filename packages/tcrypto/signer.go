@@ -0,0 +1,195 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package tcrypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// SigScheme is the threshold signature scheme of the chain address. Anchor transactions of the chain
+// are signed with it, so the scheme must be supported by addresses on L1
+type SigScheme byte
+
+const (
+	// SigSchemeBLS is the threshold BLS (BDN) over BN256. The chain address is the BLS address.
+	// Signature shares are aggregated without interaction between signers
+	SigSchemeBLS = SigScheme(iota)
+	// SigSchemeFrostEd25519 is the FROST threshold Schnorr signature over Ed25519. The chain address is
+	// the ED25519 address. Signers commit to their nonces before signing, see FrostShare
+	SigSchemeFrostEd25519
+)
+
+var sigSchemeNames = map[SigScheme]string{
+	SigSchemeBLS:          "bls",
+	SigSchemeFrostEd25519: "frost-ed25519",
+}
+
+func (s SigScheme) String() string {
+	if name, ok := sigSchemeNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", byte(s))
+}
+
+// SigSchemeFromString returns the scheme by its name, as returned by String
+func SigSchemeFromString(name string) (SigScheme, error) {
+	for s, n := range sigSchemeNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown signature scheme '%s'", name)
+}
+
+// SigSchemeOfAddress returns the scheme which signs for the address
+func SigSchemeOfAddress(addr *address.Address) (SigScheme, error) {
+	switch addr.Version() {
+	case address.VersionBLS:
+		return SigSchemeBLS, nil
+	case address.VersionED25519:
+		return SigSchemeFrostEd25519, nil
+	}
+	return 0, fmt.Errorf("address %s can't be controlled by the committee", addr.String())
+}
+
+// ErrNotInSigningSet is returned by ThresholdSigner.SignShare if the commitment of the node is not
+// among the commitments of signers
+var ErrNotInSigningSet = errors.New("the node is not in the signing set")
+
+// SigningCommitments are nonce commitments of signers of one signature, by the index of the signer.
+// The set of signers of the signature is the set of indices. Empty for schemes which don't need commitments
+type SigningCommitments map[uint16][]byte
+
+// Indices returns indices of signers in ascending order
+func (c SigningCommitments) Indices() []uint16 {
+	ret := make([]uint16, 0, len(c))
+	for i := range c {
+		ret = append(ret, i)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i] < ret[j]
+	})
+	return ret
+}
+
+// Write serializes commitments in the order of indices of signers
+func (c SigningCommitments) Write(w io.Writer) error {
+	if err := util.WriteUint16(w, uint16(len(c))); err != nil {
+		return err
+	}
+	for _, i := range c.Indices() {
+		if err := util.WriteUint16(w, i); err != nil {
+			return err
+		}
+		if err := util.WriteBytes16(w, c[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Bytes returns the serialized commitments, see Write
+func (c SigningCommitments) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = c.Write(&buf)
+	return buf.Bytes()
+}
+
+// ReadSigningCommitments reads commitments serialized by SigningCommitments.Write. Returns nil if there are none
+func ReadSigningCommitments(r io.Reader) (SigningCommitments, error) {
+	var size uint16
+	if err := util.ReadUint16(r, &size); err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	ret := make(SigningCommitments, size)
+	for k := uint16(0); k < size; k++ {
+		var index uint16
+		if err := util.ReadUint16(r, &index); err != nil {
+			return nil, err
+		}
+		commitment, err := util.ReadBytes16(r)
+		if err != nil {
+			return nil, err
+		}
+		ret[index] = commitment
+	}
+	return ret, nil
+}
+
+// ThresholdSigner signs with the key share of the node and aggregates signature shares of the quorum
+// of the committee into the signature of the chain address, valid on L1.
+// Signature shares are encoded as tbdn.SigShare: the index of the signer followed by the value
+type ThresholdSigner interface {
+	Scheme() SigScheme
+	// Address is the address controlled by the key
+	Address() *address.Address
+	// NeedsCommitments is true if signers must commit to nonces before signing, see Commit
+	NeedsCommitments() bool
+	// Commit generates the fresh nonce for the next signature share and returns the commitment to it.
+	// The nonce replaces the previous one and is used for one signature share only
+	Commit() ([]byte, error)
+	// VerifyCommitment checks the commitment received from the peer is well-formed
+	VerifyCommitment(commitment []byte) error
+	// SignShare signs data with the key share of the node. For schemes which need commitments
+	// the commitment of the node must be among commitments of signers, otherwise ErrNotInSigningSet
+	SignShare(data []byte, commitments SigningCommitments) (tbdn.SigShare, error)
+	// VerifySigShare checks the signature share of the peer
+	VerifySigShare(data []byte, sigShare tbdn.SigShare, commitments SigningCommitments) error
+	// RecoverFullSignature aggregates signature shares of the quorum into the signature as defined in the value Tangle
+	RecoverFullSignature(sigShares [][]byte, data []byte, commitments SigningCommitments) (signaturescheme.Signature, error)
+}
+
+// blsSigner is the ThresholdSigner of the BLS key share. Commitments are ignored
+type blsSigner struct {
+	dkShare *DKShare
+}
+
+// NewBLSSigner returns the ThresholdSigner of the BLS key share
+func NewBLSSigner(dkShare *DKShare) ThresholdSigner {
+	return &blsSigner{dkShare: dkShare}
+}
+
+func (s *blsSigner) Scheme() SigScheme {
+	return SigSchemeBLS
+}
+
+func (s *blsSigner) Address() *address.Address {
+	return s.dkShare.Address
+}
+
+func (s *blsSigner) NeedsCommitments() bool {
+	return false
+}
+
+func (s *blsSigner) Commit() ([]byte, error) {
+	return nil, nil
+}
+
+func (s *blsSigner) VerifyCommitment(_ []byte) error {
+	return nil
+}
+
+func (s *blsSigner) SignShare(data []byte, _ SigningCommitments) (tbdn.SigShare, error) {
+	return s.dkShare.SignShare(data)
+}
+
+func (s *blsSigner) VerifySigShare(data []byte, sigShare tbdn.SigShare, _ SigningCommitments) error {
+	return s.dkShare.VerifySigShare(data, sigShare)
+}
+
+func (s *blsSigner) RecoverFullSignature(sigShares [][]byte, data []byte, _ SigningCommitments) (signaturescheme.Signature, error) {
+	return s.dkShare.RecoverFullSignature(sigShares, data)
+}
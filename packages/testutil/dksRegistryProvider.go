@@ -12,15 +12,17 @@ import (
 
 // DkgRegistryProvider stands for a mock for dkg.RegistryProvider.
 type DkgRegistryProvider struct {
-	DB    map[string][]byte
-	Suite tcrypto.Suite
+	DB      map[string][]byte
+	FrostDB map[string][]byte
+	Suite   tcrypto.Suite
 }
 
 // NewDkgRegistryProvider creates new mocked DKG registry provider.
 func NewDkgRegistryProvider(suite tcrypto.Suite) *DkgRegistryProvider {
 	return &DkgRegistryProvider{
-		DB:    map[string][]byte{},
-		Suite: suite,
+		DB:      map[string][]byte{},
+		FrostDB: map[string][]byte{},
+		Suite:   suite,
 	}
 }
 
@@ -35,6 +37,14 @@ func (p *DkgRegistryProvider) SaveDKShare(dkShare *tcrypto.DKShare) error {
 	return nil
 }
 
+// ReplaceDKShare implements dkg.RegistryProvider.
+func (p *DkgRegistryProvider) ReplaceDKShare(dkShare *tcrypto.DKShare) error {
+	if _, ok := p.DB[dkShare.Address.String()]; !ok {
+		return fmt.Errorf("DKShare not found for %v", dkShare.Address)
+	}
+	return p.SaveDKShare(dkShare)
+}
+
 // LoadDKShare implements dkg.RegistryProvider.
 func (p *DkgRegistryProvider) LoadDKShare(sharedAddress *address.Address) (*tcrypto.DKShare, error) {
 	var dkShareBytes = p.DB[sharedAddress.String()]
@@ -43,3 +53,22 @@ func (p *DkgRegistryProvider) LoadDKShare(sharedAddress *address.Address) (*tcry
 	}
 	return tcrypto.DKShareFromBytes(dkShareBytes, p.Suite)
 }
+
+// SaveFrostShare implements tcrypto.RegistryProvider.
+func (p *DkgRegistryProvider) SaveFrostShare(frostShare *tcrypto.FrostShare) error {
+	frostShareBytes, err := frostShare.Bytes()
+	if err != nil {
+		return err
+	}
+	p.FrostDB[frostShare.Address.String()] = frostShareBytes
+	return nil
+}
+
+// LoadFrostShare implements tcrypto.RegistryProvider.
+func (p *DkgRegistryProvider) LoadFrostShare(addr *address.Address) (*tcrypto.FrostShare, error) {
+	var frostShareBytes = p.FrostDB[addr.String()]
+	if frostShareBytes == nil {
+		return nil, fmt.Errorf("FROST share not found for %v", addr)
+	}
+	return tcrypto.FrostShareFromBytes(frostShareBytes)
+}
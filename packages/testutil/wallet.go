@@ -32,3 +32,9 @@ func (w *Wallet) SigScheme() signaturescheme.SignatureScheme {
 func (w *Wallet) WithIndex(index int) *Wallet {
 	return &Wallet{seed: w.seed, index: uint64(index)}
 }
+
+// NewRandomWallet returns the wallet with a new random seed. Addresses of the wallet
+// are not used by anybody else, which is useful when the ledger is shared between tests
+func NewRandomWallet() *Wallet {
+	return &Wallet{seed: seed.NewSeed(), index: 0}
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/labstack/echo/v4/middleware"
 )
 
-func AddAuthentication(e *echo.Echo, config map[string]string) {
+// AddAuthentication adds the authentication scheme from the config to all endpoints of the server,
+// except the endpoints with paths in publicPaths
+func AddAuthentication(e *echo.Echo, config map[string]string, publicPaths ...string) {
 	if len(config) == 0 {
 		return
 	}
@@ -17,14 +19,24 @@ func AddAuthentication(e *echo.Echo, config map[string]string) {
 	}
 	switch scheme {
 	case "basic":
-		addBasicAuth(e, config["username"], config["password"])
+		addBasicAuth(e, config["username"], config["password"], publicPaths)
 	default:
 		panic(fmt.Sprintf("Unknown auth scheme %s", scheme))
 	}
 }
 
-func addBasicAuth(e *echo.Echo, username string, password string) {
-	e.Use(middleware.BasicAuth(func(u, p string, c echo.Context) (bool, error) {
-		return u == username && p == password, nil
+func addBasicAuth(e *echo.Echo, username string, password string, publicPaths []string) {
+	e.Use(middleware.BasicAuthWithConfig(middleware.BasicAuthConfig{
+		Skipper: func(c echo.Context) bool {
+			for _, p := range publicPaths {
+				if c.Path() == p {
+					return true
+				}
+			}
+			return false
+		},
+		Validator: func(u, p string, c echo.Context) (bool, error) {
+			return u == username && p == password, nil
+		},
 	}))
 }
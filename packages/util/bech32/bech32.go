@@ -0,0 +1,175 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// package bech32 implements the bech32 encoding as specified in BIP-173:
+// a human-readable part (HRP), separator '1' and base32 encoded data followed by 6 characters of checksum
+package bech32
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	charset   = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	separator = '1'
+	// maxLength is the maximum length of the bech32 string
+	maxLength = 90
+	// checksumLength is the number of characters of the checksum
+	checksumLength = 6
+)
+
+var generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+var (
+	ErrInvalidLength    = errors.New("bech32: invalid length")
+	ErrMixedCase        = errors.New("bech32: mixed case")
+	ErrInvalidChecksum  = errors.New("bech32: invalid checksum")
+	ErrInvalidSeparator = errors.New("bech32: missing or misplaced separator")
+	ErrInvalidPadding   = errors.New("bech32: invalid padding")
+)
+
+// Encode encodes data bytes with the human-readable part hrp
+func Encode(hrp string, data []byte) (string, error) {
+	if len(hrp) == 0 {
+		return "", ErrInvalidLength
+	}
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return "", fmt.Errorf("bech32: invalid character in HRP: %q", c)
+		}
+	}
+	hrp = strings.ToLower(hrp)
+	values := convertBits(data, 8, 5, true)
+	if len(hrp)+1+len(values)+checksumLength > maxLength {
+		return "", ErrInvalidLength
+	}
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte(separator)
+	for _, v := range append(values, checksum(hrp, values)...) {
+		sb.WriteByte(charset[v])
+	}
+	return sb.String(), nil
+}
+
+// Decode decodes bech32 string into the human-readable part and data bytes
+func Decode(s string) (string, []byte, error) {
+	if len(s) < 1+1+checksumLength || len(s) > maxLength {
+		return "", nil, ErrInvalidLength
+	}
+	lower := strings.ToLower(s)
+	if lower != s && strings.ToUpper(s) != s {
+		return "", nil, ErrMixedCase
+	}
+	s = lower
+	pos := strings.LastIndexByte(s, separator)
+	if pos < 1 || pos+1+checksumLength > len(s) {
+		return "", nil, ErrInvalidSeparator
+	}
+	hrp := s[:pos]
+	for _, c := range hrp {
+		if c < 33 || c > 126 {
+			return "", nil, fmt.Errorf("bech32: invalid character in HRP: %q", c)
+		}
+	}
+	values := make([]byte, 0, len(s)-pos-1)
+	for _, c := range s[pos+1:] {
+		v := strings.IndexRune(charset, c)
+		if v < 0 {
+			return "", nil, fmt.Errorf("bech32: invalid character: %q", c)
+		}
+		values = append(values, byte(v))
+	}
+	if polymod(append(hrpExpand(hrp), values...)) != 1 {
+		return "", nil, ErrInvalidChecksum
+	}
+	data, ok := convertBitsStrict(values[:len(values)-checksumLength], 5, 8)
+	if !ok {
+		return "", nil, ErrInvalidPadding
+	}
+	return hrp, data, nil
+}
+
+// DecodeWithHRP decodes bech32 string and checks if it has the expected human-readable part
+func DecodeWithHRP(expectedHRP string, s string) ([]byte, error) {
+	hrp, data, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if hrp != expectedHRP {
+		return nil, fmt.Errorf("bech32: expected prefix '%s', got '%s'", expectedHRP, hrp)
+	}
+	return data, nil
+}
+
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func hrpExpand(hrp string) []byte {
+	ret := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		ret = append(ret, hrp[i]>>5)
+	}
+	ret = append(ret, 0)
+	for i := 0; i < len(hrp); i++ {
+		ret = append(ret, hrp[i]&31)
+	}
+	return ret
+}
+
+func checksum(hrp string, values []byte) []byte {
+	v := append(hrpExpand(hrp), values...)
+	v = append(v, make([]byte, checksumLength)...)
+	mod := polymod(v) ^ 1
+	ret := make([]byte, checksumLength)
+	for i := range ret {
+		ret[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return ret
+}
+
+// convertBits regroups bits of the data from groups of fromBits into groups of toBits
+func convertBits(data []byte, fromBits, toBits uint, pad bool) []byte {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+	ret := make([]byte, 0, (uint(len(data))*fromBits+toBits-1)/toBits)
+	for _, b := range data {
+		acc = acc<<fromBits | uint32(b)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			ret = append(ret, byte(acc>>bits&maxv))
+		}
+	}
+	if pad && bits > 0 {
+		ret = append(ret, byte(acc<<(toBits-bits)&maxv))
+	}
+	return ret
+}
+
+// convertBitsStrict is convertBits without padding which checks if the remaining bits are zero padding
+func convertBitsStrict(data []byte, fromBits, toBits uint) ([]byte, bool) {
+	ret := convertBits(data, fromBits, toBits, false)
+	bits := uint(len(data))*fromBits - uint(len(ret))*toBits
+	if bits >= fromBits {
+		return nil, false
+	}
+	if len(data) > 0 && data[len(data)-1]&(1<<bits-1) != 0 {
+		return nil, false
+	}
+	return ret, true
+}
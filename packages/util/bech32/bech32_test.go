@@ -0,0 +1,60 @@
+package bech32
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeVectors(t *testing.T) {
+	hrp, data, err := Decode("A12UEL5L")
+	require.NoError(t, err)
+	require.EqualValues(t, "a", hrp)
+	require.Empty(t, data)
+
+	hrp, data, err = Decode("abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw")
+	require.NoError(t, err)
+	require.EqualValues(t, "abcdef", hrp)
+	require.EqualValues(t, "00443214c74254b635cf84653a56d7c675be77df", hex.EncodeToString(data))
+
+	invalid := []string{
+		"pzry9x0s0muk",  // no separator
+		"1pzry9x0s0muk", // empty HRP
+		"x1b4n0q5v",     // invalid data character
+		"li1dgmt3",      // too short checksum
+		"A1G7SGD8",      // checksum calculated with uppercase HRP
+		"a12UEL5L",      // mixed case
+		"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxx", // wrong checksum
+	}
+	for _, s := range invalid {
+		_, _, err := Decode(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	data := []byte{0, 1, 2, 3, 0xff, 0xfe, 0x80, 0x7f, 0x42}
+	for i := 0; i <= len(data); i++ {
+		s, err := Encode("wtest", data[:i])
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(s, "wtest1"))
+
+		back, err := DecodeWithHRP("wtest", s)
+		require.NoError(t, err)
+		require.EqualValues(t, data[:i], back)
+
+		back, err = DecodeWithHRP("wtest", strings.ToUpper(s))
+		require.NoError(t, err)
+		require.EqualValues(t, data[:i], back)
+
+		_, err = DecodeWithHRP("wother", s)
+		require.Error(t, err)
+	}
+}
+
+func TestTooLong(t *testing.T) {
+	_, err := Encode("w", make([]byte, 60))
+	require.Error(t, err)
+}
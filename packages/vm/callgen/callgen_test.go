@@ -0,0 +1,105 @@
+package callgen
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testInterface = `{
+  "name": "erc20",
+  "funcs": [
+    {
+      "name": "transfer",
+      "params": [
+        {"name": "account", "key": "ac", "type": "AgentID"},
+        {"name": "amount", "key": "am", "type": "Int64"}
+      ]
+    },
+    {
+      "name": "balanceOf",
+      "view": true,
+      "params": [{"name": "account", "key": "ac", "type": "AgentID"}],
+      "results": [{"name": "amount", "key": "am", "type": "Int64"}]
+    },
+    {
+      "name": "getInfo",
+      "view": true,
+      "results": [
+        {"name": "ownerID", "type": "AgentID"},
+        {"name": "data", "type": "Bytes"},
+        {"name": "type", "type": "String"}
+      ]
+    }
+  ],
+  "errors": [
+    {"name": "notEnoughFunds", "code": 1, "description": "the account has not enough tokens"}
+  ]
+}`
+
+func TestNames(t *testing.T) {
+	require.EqualValues(t, "BalanceOf", exportedName("balanceOf"))
+	require.EqualValues(t, "OwnerID", exportedName("ownerID"))
+	require.EqualValues(t, "OwnerID", exportedName("owner_id"))
+	require.EqualValues(t, "ownerID", localName("OwnerID"))
+	require.EqualValues(t, "balance_of", snakeName("balanceOf"))
+	require.EqualValues(t, "OWNER_ID", constName("ownerID"))
+	require.EqualValues(t, "http_url", snakeName("HTTPUrl"))
+	require.EqualValues(t, "typeValue", goArgName("type"))
+	require.EqualValues(t, "type_value", rustArgName("type"))
+}
+
+func TestValidate(t *testing.T) {
+	_, err := Parse([]byte(testInterface))
+	require.NoError(t, err)
+
+	_, err = Parse([]byte(`{"name": "a", "funcs": [{"name": "f", "params": [{"name": "p", "type": "Float"}]}]}`))
+	require.Error(t, err)
+	_, err = Parse([]byte(`{"name": "a", "funcs": [{"name": "f"}, {"name": "F"}]}`))
+	require.Error(t, err)
+	_, err = Parse([]byte(`{"name": "a", "funcs": [{"name": "init"}]}`))
+	require.Error(t, err)
+	_, err = Parse([]byte(`{"name": "a", "funcs": [{"name": "f", "params": [{"name": "transfer", "type": "Int64"}]}]}`))
+	require.Error(t, err)
+	_, err = Parse([]byte(`{"name": "a", "funcs": [
+		{"name": "f", "params": [{"name": "p", "key": "x", "type": "Int64"}]},
+		{"name": "g", "params": [{"name": "p", "key": "y", "type": "Int64"}]}]}`))
+	require.Error(t, err)
+	_, err = Parse([]byte(`{"name": "a", "errors": [{"name": "e1", "code": 1}, {"name": "e2", "code": 1}]}`))
+	require.Error(t, err)
+}
+
+func TestGenerateGo(t *testing.T) {
+	iface, err := Parse([]byte(testInterface))
+	require.NoError(t, err)
+	src, err := GenerateGo(iface, "erc20client")
+	require.NoError(t, err)
+
+	_, err = parser.ParseFile(token.NewFileSet(), "erc20client.go", src, parser.AllErrors)
+	require.NoError(t, err)
+	s := string(src)
+	require.Contains(t, s, "func (c *Client) Transfer(account coretypes.AgentID, amount int64, transfer coretypes.ColoredBalances) error {")
+	require.Contains(t, s, "func (c *ViewClient) BalanceOf(account coretypes.AgentID) (int64, error) {")
+	require.Contains(t, s, "func (c *ViewClient) GetInfo() (*GetInfoResults, error) {")
+	require.Contains(t, s, `const ParamAccount = "ac"`)
+	require.Contains(t, s, `const ResultOwnerID = "ownerID"`)
+	require.Contains(t, s, "ErrNotEnoughFunds = int64(1)")
+}
+
+func TestGenerateRust(t *testing.T) {
+	iface, err := Parse([]byte(testInterface))
+	require.NoError(t, err)
+	src, err := GenerateRust(iface)
+	require.NoError(t, err)
+
+	s := string(src)
+	require.Contains(t, s, "pub fn transfer(ctx: &ScFuncContext, account: &ScAgentId, amount: i64, transfer: Option<ScTransfers>) -> Result<(), ScContractError> {")
+	require.Contains(t, s, "pub fn balance_of<C: ScViewCaller>(ctx: &C, account: &ScAgentId) -> Result<i64, ScContractError> {")
+	require.Contains(t, s, "pub fn get_info<C: ScViewCaller>(ctx: &C) -> Result<GetInfoResults, ScContractError> {")
+	require.Contains(t, s, "    pub type_value: String,")
+	require.Contains(t, s, "pub const ERR_NOT_ENOUGH_FUNDS: i64 = 1;")
+	require.Equal(t, strings.Count(s, "{"), strings.Count(s, "}"))
+}
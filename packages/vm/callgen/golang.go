@@ -0,0 +1,310 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package callgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+type goType struct {
+	name    string
+	zero    string
+	codec   string
+	imports []string
+}
+
+const (
+	importCodec     = "github.com/iotaledger/wasp/packages/kv/codec"
+	importCoretypes = "github.com/iotaledger/wasp/packages/coretypes"
+	importDict      = "github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+var goTypes = map[string]goType{
+	"Address":    {"address.Address", "address.Address{}", "Address", []string{"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"}},
+	"AgentID":    {"coretypes.AgentID", "coretypes.AgentID{}", "AgentID", nil},
+	"Bytes":      {"[]byte", "nil", "", nil},
+	"ChainID":    {"coretypes.ChainID", "coretypes.ChainID{}", "ChainID", nil},
+	"Color":      {"balance.Color", "balance.Color{}", "Color", []string{"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"}},
+	"ContractID": {"coretypes.ContractID", "coretypes.ContractID{}", "ContractID", nil},
+	"Hash":       {"hashing.HashValue", "hashing.HashValue{}", "HashValue", []string{"github.com/iotaledger/wasp/packages/hashing"}},
+	"Hname":      {"coretypes.Hname", "0", "Hname", nil},
+	"Int64":      {"int64", "0", "Int64", nil},
+	"String":     {"string", "\"\"", "String", nil},
+}
+
+// goReserved are names used by the generated code, which can't be names of arguments
+var goReserved = map[string]bool{"c": true, "ctx": true, "err": true, "params": true, "res": true, "ret": true, transferArg: true}
+
+// GenerateGo generates the Go client stubs of the contract in the package pkg.
+// Client calls functions and views of the contract from the full entry point of another contract,
+// ViewClient calls views from the view entry point
+func GenerateGo(iface *Interface, pkg string) ([]byte, error) {
+	if err := iface.Validate(); err != nil {
+		return nil, err
+	}
+	imports := map[string]bool{importCoretypes: true, importDict: true}
+	var body bytes.Buffer
+	writeGoConsts(&body, iface)
+	writeGoClients(&body, iface)
+	for _, f := range iface.Funcs {
+		writeGoFunc(&body, iface, f, imports)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by tools/callgen from the interface of the contract '%s'. DO NOT EDIT.\n\n", iface.Name)
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	src.WriteString("import (\n")
+	std, other := make([]string, 0), make([]string, 0)
+	for imp := range imports {
+		if strings.Contains(imp, ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	for _, imp := range std {
+		fmt.Fprintf(&src, "\t%q\n", imp)
+	}
+	if len(std) > 0 && len(other) > 0 {
+		src.WriteString("\n")
+	}
+	for _, imp := range other {
+		fmt.Fprintf(&src, "\t%q\n", imp)
+	}
+	src.WriteString(")\n\n")
+	src.Write(body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+func writeGoConsts(w *bytes.Buffer, iface *Interface) {
+	fmt.Fprintf(w, "const ScName = %q\n", iface.Name)
+	if iface.Description != "" {
+		fmt.Fprintf(w, "const ScDescription = %q\n", iface.Description)
+	}
+	fmt.Fprintf(w, "const ScHname = coretypes.Hname(0x%08x)\n\n", uint32(coretypes.Hn(iface.Name)))
+
+	params, results := collectKeys(iface)
+	for _, name := range sortedKeys(params) {
+		fmt.Fprintf(w, "const Param%s = %q\n", name, params[name])
+	}
+	if len(params) > 0 {
+		w.WriteString("\n")
+	}
+	for _, name := range sortedKeys(results) {
+		fmt.Fprintf(w, "const Result%s = %q\n", name, results[name])
+	}
+	if len(results) > 0 {
+		w.WriteString("\n")
+	}
+	for _, f := range iface.Funcs {
+		fmt.Fprintf(w, "const %s = %q\n", goFuncConst(f), f.Name)
+	}
+	w.WriteString("\n")
+	for _, f := range iface.Funcs {
+		fmt.Fprintf(w, "const H%s = coretypes.Hname(0x%08x)\n", goFuncConst(f), uint32(coretypes.Hn(f.Name)))
+	}
+	w.WriteString("\n")
+	if len(iface.Errors) == 0 {
+		return
+	}
+	w.WriteString("// Codes of errors of the contract, see coretypes.ContractError\n")
+	w.WriteString("const (\n")
+	for _, e := range iface.Errors {
+		if e.Description != "" {
+			fmt.Fprintf(w, "\t// Err%s: %s\n", exportedName(e.Name), e.Description)
+		}
+		fmt.Fprintf(w, "\tErr%s = int64(%d)\n", exportedName(e.Name), e.Code)
+	}
+	w.WriteString(")\n\n")
+}
+
+func writeGoClients(w *bytes.Buffer, iface *Interface) {
+	fmt.Fprintf(w, "// ViewClient calls views of the contract '%s' from other contracts\n", iface.Name)
+	w.WriteString("type ViewClient struct {\n")
+	w.WriteString("\tcall func(contract, entryPoint coretypes.Hname, params dict.Dict) (dict.Dict, error)\n")
+	w.WriteString("}\n\n")
+	w.WriteString("// NewViewClient creates the client to call views from the view entry point\n")
+	w.WriteString("func NewViewClient(ctx coretypes.SandboxView) *ViewClient {\n")
+	w.WriteString("\treturn &ViewClient{call: ctx.Call}\n")
+	w.WriteString("}\n\n")
+	fmt.Fprintf(w, "// Client calls functions and views of the contract '%s' from other contracts\n", iface.Name)
+	w.WriteString("type Client struct {\n")
+	w.WriteString("\tViewClient\n")
+	w.WriteString("\tctx coretypes.Sandbox\n")
+	w.WriteString("}\n\n")
+	w.WriteString("// NewClient creates the client to call functions and views from the full entry point\n")
+	w.WriteString("func NewClient(ctx coretypes.Sandbox) *Client {\n")
+	w.WriteString("\tviewCall := func(contract, entryPoint coretypes.Hname, params dict.Dict) (dict.Dict, error) {\n")
+	w.WriteString("\t\treturn ctx.Call(contract, entryPoint, params, nil)\n")
+	w.WriteString("\t}\n")
+	w.WriteString("\treturn &Client{ViewClient: ViewClient{call: viewCall}, ctx: ctx}\n")
+	w.WriteString("}\n\n")
+}
+
+func writeGoFunc(w *bytes.Buffer, iface *Interface, f *Func, imports map[string]bool) {
+	name := exportedName(f.Name)
+	args := make([]string, 0, len(f.Params)+1)
+	for _, p := range f.Params {
+		t := goTypes[p.Type]
+		addImports(imports, t)
+		args = append(args, goArgName(p.Name)+" "+t.name)
+	}
+	if !f.View {
+		args = append(args, transferArg+" coretypes.ColoredBalances")
+	}
+
+	for _, r := range f.Results {
+		addImports(imports, goTypes[r.Type])
+	}
+	// results: the error only, the value and the error, or the struct of results and the error
+	var resultType, zero string
+	switch len(f.Results) {
+	case 0:
+		resultType = "error"
+	case 1:
+		t := goTypes[f.Results[0].Type]
+		resultType = "(" + t.name + ", error)"
+		zero = t.zero
+	default:
+		resultType = "(*" + name + "Results, error)"
+		zero = "nil"
+		fmt.Fprintf(w, "// %sResults are results of '%s'\n", name, f.Name)
+		fmt.Fprintf(w, "type %sResults struct {\n", name)
+		for _, r := range f.Results {
+			t := goTypes[r.Type]
+			if r.Description != "" {
+				fmt.Fprintf(w, "\t// %s\n", r.Description)
+			}
+			fmt.Fprintf(w, "\t%s %s\n", exportedName(r.Name), t.name)
+		}
+		w.WriteString("}\n\n")
+	}
+
+	receiver := "*ViewClient"
+	kind := "view"
+	call := "c.call(ScHname, H%s, params)"
+	if !f.View {
+		receiver = "*Client"
+		kind = "function"
+		call = "c.ctx.Call(ScHname, H%s, params, " + transferArg + ")"
+	}
+	call = fmt.Sprintf(call, goFuncConst(f))
+	fmt.Fprintf(w, "// %s calls the %s '%s' of the contract '%s'", name, kind, f.Name, iface.Name)
+	if f.Description != "" {
+		fmt.Fprintf(w, ".\n// %s", f.Description)
+	}
+	w.WriteString("\n")
+	fmt.Fprintf(w, "func (c %s) %s(%s) %s {\n", receiver, name, strings.Join(args, ", "), resultType)
+	w.WriteString("\tparams := dict.New()\n")
+	for _, p := range f.Params {
+		t := goTypes[p.Type]
+		if t.codec == "" {
+			fmt.Fprintf(w, "\tparams.Set(Param%s, %s)\n", exportedName(p.Name), goArgName(p.Name))
+			continue
+		}
+		imports[importCodec] = true
+		fmt.Fprintf(w, "\tparams.Set(Param%s, codec.Encode%s(%s))\n", exportedName(p.Name), t.codec, goArgName(p.Name))
+	}
+	if len(f.Results) == 0 {
+		fmt.Fprintf(w, "\t_, err := %s\n", call)
+		w.WriteString("\treturn err\n")
+		w.WriteString("}\n\n")
+		return
+	}
+	fmt.Fprintf(w, "\tres, err := %s\n", call)
+	w.WriteString("\tif err != nil {\n")
+	fmt.Fprintf(w, "\t\treturn %s, err\n", zero)
+	w.WriteString("\t}\n")
+	if len(f.Results) == 1 {
+		r := f.Results[0]
+		writeGoDecode(w, r, "ret", zero, imports)
+		w.WriteString("\treturn ret, nil\n")
+		w.WriteString("}\n\n")
+		return
+	}
+	fmt.Fprintf(w, "\tret := &%sResults{}\n", name)
+	for _, r := range f.Results {
+		writeGoDecode(w, r, "ret."+exportedName(r.Name), zero, imports)
+	}
+	w.WriteString("\treturn ret, nil\n")
+	w.WriteString("}\n\n")
+}
+
+// writeGoDecode writes decoding of the result into the variable. A missing result is decoded as the zero value
+func writeGoDecode(w *bytes.Buffer, r *Field, v, zero string, imports map[string]bool) {
+	t := goTypes[r.Type]
+	key := "Result" + exportedName(r.Name)
+	if t.codec == "" {
+		op := "="
+		if v == "ret" {
+			op = ":="
+		}
+		fmt.Fprintf(w, "\t%s %s res.MustGet(%s)\n", v, op, key)
+		return
+	}
+	imports[importCodec] = true
+	imports["fmt"] = true
+	if v == "ret" {
+		fmt.Fprintf(w, "\tret, _, err := codec.Decode%s(res.MustGet(%s))\n", t.codec, key)
+	} else {
+		fmt.Fprintf(w, "\t%s, _, err = codec.Decode%s(res.MustGet(%s))\n", v, t.codec, key)
+	}
+	w.WriteString("\tif err != nil {\n")
+	fmt.Fprintf(w, "\t\treturn %s, fmt.Errorf(\"wrong result '%s': %%w\", err)\n", zero, r.Name)
+	w.WriteString("\t}\n")
+}
+
+func goFuncConst(f *Func) string {
+	if f.View {
+		return "View" + exportedName(f.Name)
+	}
+	return "Func" + exportedName(f.Name)
+}
+
+func goArgName(name string) string {
+	ret := localName(name)
+	if goReserved[ret] || token.IsKeyword(ret) {
+		return ret + "Value"
+	}
+	return ret
+}
+
+func addImports(imports map[string]bool, t goType) {
+	for _, imp := range t.imports {
+		imports[imp] = true
+	}
+}
+
+// collectKeys returns keys of parameters and results of all entry points by exported names
+func collectKeys(iface *Interface) (map[string]string, map[string]string) {
+	params := make(map[string]string)
+	results := make(map[string]string)
+	for _, f := range iface.Funcs {
+		for _, p := range f.Params {
+			params[exportedName(p.Name)] = p.key()
+		}
+		for _, r := range f.Results {
+			results[exportedName(r.Name)] = r.key()
+		}
+	}
+	return params, results
+}
+
+func sortedKeys(m map[string]string) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
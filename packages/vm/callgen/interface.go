@@ -0,0 +1,253 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+// package callgen generates typed client stubs for calls of the smart contract from other
+// smart contracts. The interface of the contract is described in JSON: entry points with their
+// typed parameters and results, and errors the contract returns. The stubs encode parameters,
+// call the entry point through the sandbox, decode results and return structured errors
+// of the called contract (coretypes.ContractError), so contracts don't pass raw dicts with
+// stringly-typed keys to each other.
+// Stubs are generated in Go, for native contracts (GenerateGo), and in Rust, for Wasm contracts (GenerateRust)
+package callgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"unicode"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+// Interface is the description of the interface of the smart contract
+type Interface struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Funcs       []*Func      `json:"funcs"`
+	Errors      []*ErrorDesc `json:"errors,omitempty"`
+}
+
+// Func is the entry point of the contract
+type Func struct {
+	Name string `json:"name"`
+	// View is true for the view entry point
+	View        bool     `json:"view,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Params      []*Field `json:"params,omitempty"`
+	Results     []*Field `json:"results,omitempty"`
+}
+
+// Field is the parameter or the result of the entry point
+type Field struct {
+	// Name is the name of the field in stubs
+	Name string `json:"name"`
+	// Key is the key of the field in the dict of parameters or results. By default it is the name
+	Key string `json:"key,omitempty"`
+	// Type is one of the types of Types
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// ErrorDesc is the structured error the contract returns, see coretypes.ContractError
+type ErrorDesc struct {
+	Name        string `json:"name"`
+	Code        int64  `json:"code"`
+	Description string `json:"description,omitempty"`
+}
+
+// Types are the types of fields stubs can encode and decode
+var Types = []string{"Address", "AgentID", "Bytes", "ChainID", "Color", "ContractID", "Hash", "Hname", "Int64", "String"}
+
+// transferArg is the name of the argument of the token transfer of full entry points
+const transferArg = "transfer"
+
+// Load reads the interface description from the JSON file and validates it
+func Load(fname string) (*Interface, error) {
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse decodes the interface description from JSON and validates it
+func Parse(data []byte) (*Interface, error) {
+	ret := &Interface{}
+	if err := json.Unmarshal(data, ret); err != nil {
+		return nil, err
+	}
+	if err := ret.Validate(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Validate checks the description is complete and names don't collide in generated stubs
+func (iface *Interface) Validate() error {
+	if iface.Name == "" {
+		return fmt.Errorf("contract name is missing")
+	}
+	funcNames := make(map[string]bool)
+	// keys of parameters and results with the same name must be the same, because they share the constant
+	paramKeys := make(map[string]string)
+	resultKeys := make(map[string]string)
+	for _, f := range iface.Funcs {
+		if f.Name == "" {
+			return fmt.Errorf("entry point name is missing")
+		}
+		if f.Name == coretypes.FuncInit {
+			return fmt.Errorf("'%s' can't be called by other contracts", f.Name)
+		}
+		if funcNames[exportedName(f.Name)] {
+			return fmt.Errorf("duplicate entry point '%s'", f.Name)
+		}
+		funcNames[exportedName(f.Name)] = true
+		if err := validateFields(f.Params, f.Name, "parameter"); err != nil {
+			return err
+		}
+		for _, p := range f.Params {
+			if p.Name == transferArg && !f.View {
+				return fmt.Errorf("%s: parameter name '%s' is reserved for the token transfer", f.Name, transferArg)
+			}
+		}
+		if err := validateFields(f.Results, f.Name, "result"); err != nil {
+			return err
+		}
+		if err := checkKeys(f.Params, paramKeys, f.Name, "parameter"); err != nil {
+			return err
+		}
+		if err := checkKeys(f.Results, resultKeys, f.Name, "result"); err != nil {
+			return err
+		}
+	}
+	codes := make(map[int64]bool)
+	errNames := make(map[string]bool)
+	for _, e := range iface.Errors {
+		if e.Name == "" {
+			return fmt.Errorf("error name is missing")
+		}
+		if errNames[exportedName(e.Name)] {
+			return fmt.Errorf("duplicate error '%s'", e.Name)
+		}
+		errNames[exportedName(e.Name)] = true
+		if codes[e.Code] {
+			return fmt.Errorf("error '%s': duplicate code %d", e.Name, e.Code)
+		}
+		codes[e.Code] = true
+	}
+	return nil
+}
+
+func validateFields(fields []*Field, funcName, kind string) error {
+	names := make(map[string]bool)
+	for _, fld := range fields {
+		if fld.Name == "" {
+			return fmt.Errorf("%s: %s name is missing", funcName, kind)
+		}
+		if names[exportedName(fld.Name)] {
+			return fmt.Errorf("%s: duplicate %s '%s'", funcName, kind, fld.Name)
+		}
+		names[exportedName(fld.Name)] = true
+		if !isType(fld.Type) {
+			return fmt.Errorf("%s: %s '%s': unknown type '%s', expected one of %s",
+				funcName, kind, fld.Name, fld.Type, strings.Join(Types, ", "))
+		}
+	}
+	return nil
+}
+
+func checkKeys(fields []*Field, keys map[string]string, funcName, kind string) error {
+	for _, fld := range fields {
+		name := exportedName(fld.Name)
+		if key, ok := keys[name]; ok && key != fld.key() {
+			return fmt.Errorf("%s: %s '%s': key '%s' differs from the key '%s' of the %s with the same name in another entry point",
+				funcName, kind, fld.Name, fld.key(), key, kind)
+		}
+		keys[name] = fld.key()
+	}
+	return nil
+}
+
+func isType(t string) bool {
+	for _, s := range Types {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the key of the field in the dict
+func (fld *Field) key() string {
+	if fld.Key != "" {
+		return fld.Key
+	}
+	return fld.Name
+}
+
+// words splits the camel case or snake case name into lower case words
+func words(name string) []string {
+	ret := make([]string, 0)
+	var cur []rune
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			if len(cur) > 0 {
+				ret = append(ret, string(cur))
+			}
+			cur = nil
+			continue
+		case unicode.IsUpper(r) && len(cur) > 0:
+			// the upper case letter starts the word, unless it continues the abbreviation
+			prevUpper := unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !prevUpper || nextLower {
+				ret = append(ret, string(cur))
+				cur = nil
+			}
+		}
+		cur = append(cur, unicode.ToLower(r))
+	}
+	if len(cur) > 0 {
+		ret = append(ret, string(cur))
+	}
+	return ret
+}
+
+// exportedName converts the name to the exported Go identifier, for example 'balanceOf' to 'BalanceOf'
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, w := range words(name) {
+		if w == "id" {
+			b.WriteString("ID")
+			continue
+		}
+		r := []rune(w)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	return b.String()
+}
+
+// localName converts the name to the unexported Go identifier, for example 'BalanceOf' to 'balanceOf'
+func localName(name string) string {
+	e := exportedName(name)
+	if strings.HasPrefix(e, "ID") {
+		return "id" + e[2:]
+	}
+	r := []rune(e)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// snakeName converts the name to the snake case Rust identifier, for example 'balanceOf' to 'balance_of'
+func snakeName(name string) string {
+	return strings.Join(words(name), "_")
+}
+
+// constName converts the name to the Rust constant, for example 'balanceOf' to 'BALANCE_OF'
+func constName(name string) string {
+	return strings.ToUpper(snakeName(name))
+}
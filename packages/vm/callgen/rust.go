@@ -0,0 +1,208 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package callgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+)
+
+type rustType struct {
+	// arg is the type of the argument
+	arg string
+	// result is the type of the result
+	result string
+	// accessor is the name of the typed accessor of the map, for example 'int64' for 'get_int64'
+	accessor string
+}
+
+var rustTypes = map[string]rustType{
+	"Address":    {"&ScAddress", "ScAddress", "address"},
+	"AgentID":    {"&ScAgentId", "ScAgentId", "agent_id"},
+	"Bytes":      {"&[u8]", "Vec<u8>", "bytes"},
+	"ChainID":    {"&ScChainId", "ScChainId", "chain_id"},
+	"Color":      {"&ScColor", "ScColor", "color"},
+	"ContractID": {"&ScContractId", "ScContractId", "contract_id"},
+	"Hash":       {"&ScHash", "ScHash", "hash"},
+	"Hname":      {"ScHname", "ScHname", "hname"},
+	"Int64":      {"i64", "i64", "int64"},
+	"String":     {"&str", "String", "string"},
+}
+
+// rustReserved are names used by the generated code and Rust keywords, which can't be names of arguments
+var rustReserved = map[string]bool{
+	"ctx": true, "params": true, "results": true, transferArg: true,
+	"as": true, "break": true, "const": true, "continue": true, "crate": true, "else": true, "enum": true,
+	"extern": true, "false": true, "fn": true, "for": true, "if": true, "impl": true, "in": true, "let": true,
+	"loop": true, "match": true, "mod": true, "move": true, "mut": true, "pub": true, "ref": true, "return": true,
+	"self": true, "static": true, "struct": true, "super": true, "trait": true, "true": true, "type": true,
+	"unsafe": true, "use": true, "where": true, "while": true,
+}
+
+// GenerateRust generates the Rust module with client stubs of the contract for Wasm contracts.
+// Functions are called from ScFuncContext, views from both ScFuncContext and ScViewContext
+func GenerateRust(iface *Interface) ([]byte, error) {
+	if err := iface.Validate(); err != nil {
+		return nil, err
+	}
+	var w bytes.Buffer
+	fmt.Fprintf(&w, "// Code generated by tools/callgen from the interface of the contract '%s'. DO NOT EDIT.\n\n", iface.Name)
+	w.WriteString("#![allow(dead_code)]\n\n")
+	w.WriteString("use wasmlib::*;\n\n")
+	writeRustConsts(&w, iface)
+	for _, f := range iface.Funcs {
+		writeRustFunc(&w, iface, f)
+	}
+	return append(bytes.TrimRight(w.Bytes(), "\n"), '\n'), nil
+}
+
+func writeRustConsts(w *bytes.Buffer, iface *Interface) {
+	fmt.Fprintf(w, "pub const SC_NAME: &str = %q;\n", iface.Name)
+	if iface.Description != "" {
+		fmt.Fprintf(w, "pub const SC_DESCRIPTION: &str = %q;\n", iface.Description)
+	}
+	fmt.Fprintf(w, "pub const SC_HNAME: ScHname = ScHname(0x%08x);\n\n", uint32(coretypes.Hn(iface.Name)))
+
+	params, results := collectKeys(iface)
+	for _, name := range sortedKeys(params) {
+		fmt.Fprintf(w, "pub const PARAM_%s: &str = %q;\n", constName(name), params[name])
+	}
+	if len(params) > 0 {
+		w.WriteString("\n")
+	}
+	for _, name := range sortedKeys(results) {
+		fmt.Fprintf(w, "pub const RESULT_%s: &str = %q;\n", constName(name), results[name])
+	}
+	if len(results) > 0 {
+		w.WriteString("\n")
+	}
+	for _, f := range iface.Funcs {
+		fmt.Fprintf(w, "pub const %s: &str = %q;\n", rustFuncConst(f), f.Name)
+	}
+	w.WriteString("\n")
+	for _, f := range iface.Funcs {
+		fmt.Fprintf(w, "pub const H%s: ScHname = ScHname(0x%08x);\n", rustFuncConst(f), uint32(coretypes.Hn(f.Name)))
+	}
+	w.WriteString("\n")
+	if len(iface.Errors) == 0 {
+		return
+	}
+	w.WriteString("// codes of errors of the contract, see ScContractError\n")
+	for _, e := range iface.Errors {
+		if e.Description != "" {
+			fmt.Fprintf(w, "// %s\n", e.Description)
+		}
+		fmt.Fprintf(w, "pub const ERR_%s: i64 = %d;\n", constName(e.Name), e.Code)
+	}
+	w.WriteString("\n")
+}
+
+func writeRustFunc(w *bytes.Buffer, iface *Interface, f *Func) {
+	name := snakeName(f.Name)
+	structName := exportedName(f.Name) + "Results"
+
+	var resultType string
+	switch len(f.Results) {
+	case 0:
+		resultType = "()"
+	case 1:
+		resultType = rustTypes[f.Results[0].Type].result
+	default:
+		resultType = structName
+		fmt.Fprintf(w, "// results of '%s'\n", f.Name)
+		fmt.Fprintf(w, "pub struct %s {\n", structName)
+		for _, r := range f.Results {
+			if r.Description != "" {
+				fmt.Fprintf(w, "    // %s\n", r.Description)
+			}
+			fmt.Fprintf(w, "    pub %s: %s,\n", rustFieldName(r.Name), rustTypes[r.Type].result)
+		}
+		w.WriteString("}\n\n")
+	}
+
+	generic := ""
+	args := make([]string, 0, len(f.Params)+2)
+	if f.View {
+		generic = "<C: ScViewCaller>"
+		args = append(args, "ctx: &C")
+	} else {
+		args = append(args, "ctx: &ScFuncContext")
+	}
+	for _, p := range f.Params {
+		args = append(args, rustArgName(p.Name)+": "+rustTypes[p.Type].arg)
+	}
+	if !f.View {
+		args = append(args, transferArg+": Option<ScTransfers>")
+	}
+
+	kind := "view"
+	if !f.View {
+		kind = "function"
+	}
+	fmt.Fprintf(w, "// calls the %s '%s' of the contract '%s'\n", kind, f.Name, iface.Name)
+	if f.Description != "" {
+		fmt.Fprintf(w, "// %s\n", f.Description)
+	}
+	fmt.Fprintf(w, "pub fn %s%s(%s) -> Result<%s, ScContractError> {\n", name, generic, strings.Join(args, ", "), resultType)
+	paramsArg := "None"
+	if len(f.Params) > 0 {
+		paramsArg = "Some(params)"
+		w.WriteString("    let params = ScMutableMap::new();\n")
+		for _, p := range f.Params {
+			t := rustTypes[p.Type]
+			fmt.Fprintf(w, "    params.get_%s(PARAM_%s).set_value(%s);\n", t.accessor, constName(exportedName(p.Name)), rustArgName(p.Name))
+		}
+	}
+	if f.View {
+		fmt.Fprintf(w, "    let results = ctx.call_view(SC_HNAME, H%s, %s);\n", rustFuncConst(f), paramsArg)
+	} else {
+		fmt.Fprintf(w, "    let results = ctx.call(SC_HNAME, H%s, %s, %s);\n", rustFuncConst(f), paramsArg, transferArg)
+	}
+	w.WriteString("    if let Some(err) = ScContractError::from_results(&results) {\n")
+	w.WriteString("        return Err(err);\n")
+	w.WriteString("    }\n")
+	switch len(f.Results) {
+	case 0:
+		w.WriteString("    Ok(())\n")
+	case 1:
+		fmt.Fprintf(w, "    Ok(%s)\n", rustResult(f.Results[0]))
+	default:
+		fmt.Fprintf(w, "    Ok(%s {\n", structName)
+		for _, r := range f.Results {
+			fmt.Fprintf(w, "        %s: %s,\n", rustFieldName(r.Name), rustResult(r))
+		}
+		w.WriteString("    })\n")
+	}
+	w.WriteString("}\n\n")
+}
+
+func rustResult(r *Field) string {
+	return fmt.Sprintf("results.get_%s(RESULT_%s).value()", rustTypes[r.Type].accessor, constName(exportedName(r.Name)))
+}
+
+func rustFuncConst(f *Func) string {
+	if f.View {
+		return "VIEW_" + constName(f.Name)
+	}
+	return "FUNC_" + constName(f.Name)
+}
+
+func rustArgName(name string) string {
+	ret := snakeName(name)
+	if rustReserved[ret] {
+		return ret + "_value"
+	}
+	return ret
+}
+
+func rustFieldName(name string) string {
+	ret := snakeName(name)
+	if rustReserved[ret] && ret != transferArg && ret != "ctx" && ret != "params" && ret != "results" {
+		return ret + "_value"
+	}
+	return ret
+}
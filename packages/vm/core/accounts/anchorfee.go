@@ -0,0 +1,38 @@
+package accounts
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// anchor fees paid, see root.AnchorFeePolicy.
+// Payer agent ID -> total iotas paid
+const varStateAnchorFeesPaid = "af"
+
+// ChargeAnchorFee removes the anchor fee from the account of the payer and records it in the total paid
+// by the payer. The iotas leave the chain with the anchor transaction, so they are removed from the chain ledger.
+// It is called by the VM with the first request of the block. Returns false if the payer can't pay the fee
+func ChargeAnchorFee(state kv.KVStore, payer coretypes.AgentID, fee int64) bool {
+	if fee <= 0 {
+		return true
+	}
+	if !DebitFromAccount(state, payer, cbalances.NewFromMap(map[balance.Color]int64{balance.ColorIOTA: fee})) {
+		return false
+	}
+	paid := collections.NewMap(state, varStateAnchorFeesPaid)
+	paid.MustSetAt(payer[:], util.Uint64To8Bytes(uint64(GetAnchorFeesPaid(state, payer)+fee)))
+	return true
+}
+
+// GetAnchorFeesPaid returns the total of anchor fees paid by the account
+func GetAnchorFeesPaid(state kv.KVStoreReader, payer coretypes.AgentID) int64 {
+	data := collections.NewMapReadOnly(state, varStateAnchorFeesPaid).MustGetAt(payer[:])
+	if data == nil {
+		return 0
+	}
+	return int64(util.MustUint64From8Bytes(data))
+}
@@ -0,0 +1,85 @@
+package accounts
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// refundable deposits of requests, see root.RequestIntakePolicy.
+// Sender agent ID -> locked iotas and the deadline
+const varStateLockedDeposits = "ld"
+
+// LockDeposit keeps the refundable deposit of the request on the account of the 'accounts' contract until the deadline
+// (timestamp in nanoseconds), so that the same iotas can't be deposited by the next request right away.
+// Deposits of the same sender are summed up and locked until the deadline of the last one.
+// It is called by the VM after the request is processed
+func LockDeposit(state kv.KVStore, chainID coretypes.ChainID, sender coretypes.AgentID, amount int64, deadline int64) {
+	myAgentID := coretypes.NewAgentIDFromContractID(Interface.ContractID(chainID))
+	CreditToAccount(state, myAgentID, cbalances.NewFromMap(map[balance.Color]int64{balance.ColorIOTA: amount}))
+	locked, _ := GetLockedDeposit(state, sender)
+	collections.NewMap(state, varStateLockedDeposits).MustSetAt(sender[:], encodeLockedDeposit(locked+amount, deadline))
+}
+
+// GetLockedDeposit returns iotas of the sender locked as deposits of its requests and the deadline
+// they are locked until, or 0 if nothing is locked
+func GetLockedDeposit(state kv.KVStoreReader, sender coretypes.AgentID) (int64, int64) {
+	data := collections.NewMapReadOnly(state, varStateLockedDeposits).MustGetAt(sender[:])
+	if data == nil {
+		return 0, 0
+	}
+	return mustDecodeLockedDeposit(data)
+}
+
+// RefundLockedDeposits returns deposits locked until the timestamp or before to the accounts of the senders.
+// It is called by the VM at the beginning of each batch of requests. Returns the number of refunded senders
+func RefundLockedDeposits(state kv.KVStore, chainID coretypes.ChainID, timestamp int64) int {
+	deposits := collections.NewMap(state, varStateLockedDeposits)
+	if deposits.MustLen() == 0 {
+		return 0
+	}
+	expired := make([][]byte, 0)
+	deposits.Immutable().MustIterate(func(key []byte, value []byte) bool {
+		if _, deadline := mustDecodeLockedDeposit(value); deadline <= timestamp {
+			expired = append(expired, append([]byte(nil), key...))
+		}
+		return true
+	})
+	// the order of mutations must be the same on all nodes
+	sort.Slice(expired, func(i, j int) bool { return bytes.Compare(expired[i], expired[j]) < 0 })
+	myAgentID := coretypes.NewAgentIDFromContractID(Interface.ContractID(chainID))
+	for _, key := range expired {
+		sender, err := coretypes.NewAgentIDFromBytes(key)
+		if err != nil {
+			panic(err)
+		}
+		amount, _ := mustDecodeLockedDeposit(deposits.MustGetAt(key))
+		funds := cbalances.NewFromMap(map[balance.Color]int64{balance.ColorIOTA: amount})
+		if !MoveBetweenAccounts(state, myAgentID, sender, funds) {
+			panic(fmt.Sprintf("RefundLockedDeposits: inconsistent locked deposit of %s", sender.String()))
+		}
+		deposits.MustDelAt(key)
+	}
+	if len(expired) > 0 {
+		mustCheckLedger(state, "RefundLockedDeposits")
+	}
+	return len(expired)
+}
+
+func encodeLockedDeposit(amount int64, deadline int64) []byte {
+	return append(util.Uint64To8Bytes(uint64(amount)), util.Uint64To8Bytes(uint64(deadline))...)
+}
+
+func mustDecodeLockedDeposit(data []byte) (int64, int64) {
+	if len(data) != 16 {
+		panic("wrong locked deposit record")
+	}
+	return int64(util.MustUint64From8Bytes(data[:8])), int64(util.MustUint64From8Bytes(data[8:]))
+}
@@ -0,0 +1,155 @@
+package accounts
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const (
+	varStateEscrows       = "e"
+	varStateEscrowCounter = "ec"
+)
+
+// Escrow is the conditional transfer from the sender to the recipient. The funds are kept on the account
+// of the 'accounts' contract until the recipient claims them or until the deadline.
+// The recipient claims the funds by satisfying the condition: the call to the condition entry point must succeed.
+// After the deadline the funds are returned to the account of the sender on the chain
+type Escrow struct {
+	Sender              coretypes.AgentID
+	Recipient           coretypes.AgentID
+	Funds               coretypes.ColoredBalances
+	ConditionContract   coretypes.Hname
+	ConditionEntryPoint coretypes.Hname
+	// Deadline is the timestamp in nanoseconds
+	Deadline int64
+}
+
+func (e *Escrow) Write(w io.Writer) error {
+	if _, err := w.Write(e.Sender[:]); err != nil {
+		return err
+	}
+	if _, err := w.Write(e.Recipient[:]); err != nil {
+		return err
+	}
+	if err := cbalances.WriteColoredBalances(w, e.Funds); err != nil {
+		return err
+	}
+	if err := e.ConditionContract.Write(w); err != nil {
+		return err
+	}
+	if err := e.ConditionEntryPoint.Write(w); err != nil {
+		return err
+	}
+	return util.WriteInt64(w, e.Deadline)
+}
+
+func (e *Escrow) Read(r io.Reader) error {
+	var err error
+	if err = coretypes.ReadAgentID(r, &e.Sender); err != nil {
+		return err
+	}
+	if err = coretypes.ReadAgentID(r, &e.Recipient); err != nil {
+		return err
+	}
+	if e.Funds, err = cbalances.ReadColoredBalance(r); err != nil {
+		return err
+	}
+	if err = e.ConditionContract.Read(r); err != nil {
+		return err
+	}
+	if err = e.ConditionEntryPoint.Read(r); err != nil {
+		return err
+	}
+	return util.ReadInt64(r, &e.Deadline)
+}
+
+func (e *Escrow) String() string {
+	return fmt.Sprintf("sender: %s, recipient: %s, funds: %s, condition: %s::%s, deadline: %d",
+		e.Sender, e.Recipient, cbalances.Str(e.Funds), e.ConditionContract, e.ConditionEntryPoint, e.Deadline)
+}
+
+func EncodeEscrow(e *Escrow) []byte {
+	return util.MustBytes(e)
+}
+
+func DecodeEscrow(data []byte) (*Escrow, error) {
+	ret := new(Escrow)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+// GetEscrow returns the escrow or nil if it does not exist, i.e. it was claimed or refunded
+func GetEscrow(state kv.KVStoreReader, id int64) (*Escrow, error) {
+	data := collections.NewMapReadOnly(state, varStateEscrows).MustGetAt(codec.EncodeInt64(id))
+	if data == nil {
+		return nil, nil
+	}
+	return DecodeEscrow(data)
+}
+
+// newEscrowID returns the next sequential ID of the escrow
+func newEscrowID(state kv.KVStore) int64 {
+	id, _, err := codec.DecodeInt64(state.MustGet(varStateEscrowCounter))
+	if err != nil {
+		panic(err)
+	}
+	state.Set(varStateEscrowCounter, codec.EncodeInt64(id+1))
+	return id
+}
+
+func setEscrow(state kv.KVStore, id int64, e *Escrow) {
+	collections.NewMap(state, varStateEscrows).MustSetAt(codec.EncodeInt64(id), EncodeEscrow(e))
+}
+
+func delEscrow(state kv.KVStore, id int64) {
+	collections.NewMap(state, varStateEscrows).MustDelAt(codec.EncodeInt64(id))
+}
+
+// RefundExpiredEscrows returns funds of escrows with the deadline before or at the timestamp to the senders.
+// The funds are kept on the account of the 'accounts' contract of the chain.
+// It is called by the VM at the beginning of each batch of requests. Returns IDs of refunded escrows
+func RefundExpiredEscrows(state kv.KVStore, chainID coretypes.ChainID, timestamp int64) []int64 {
+	escrows := collections.NewMap(state, varStateEscrows)
+	if escrows.MustLen() == 0 {
+		return nil
+	}
+	refunds := make(map[int64]*Escrow)
+	expired := make([]int64, 0)
+	escrows.Immutable().MustIterate(func(key []byte, value []byte) bool {
+		e, err := DecodeEscrow(value)
+		if err != nil {
+			panic(err)
+		}
+		if e.Deadline <= timestamp {
+			id, _, err := codec.DecodeInt64(key)
+			if err != nil {
+				panic(err)
+			}
+			expired = append(expired, id)
+			refunds[id] = e
+		}
+		return true
+	})
+	// the order of mutations must be the same on all nodes
+	sort.Slice(expired, func(i, j int) bool { return expired[i] < expired[j] })
+	myAgentID := coretypes.NewAgentIDFromContractID(Interface.ContractID(chainID))
+	for _, id := range expired {
+		if !MoveBetweenAccounts(state, myAgentID, refunds[id].Sender, refunds[id].Funds) {
+			panic(fmt.Sprintf("RefundExpiredEscrows: inconsistent funds of the escrow #%d", id))
+		}
+		delEscrow(state, id)
+	}
+	if len(expired) > 0 {
+		mustCheckLedger(state, "RefundExpiredEscrows")
+	}
+	return expired
+}
@@ -2,6 +2,7 @@ package accounts
 
 import (
 	"fmt"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/assert"
 	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
@@ -72,7 +73,7 @@ func withdrawToAddress(ctx coretypes.Sandbox) (dict.Dict, error) {
 
 	a := assert.NewAssert(ctx.Log())
 
-	a.Require(ctx.Caller().IsAddress(), "caller must be an address")
+	a.RequireAuthorized(ctx.Caller().IsAddress(), "caller must be an address")
 
 	bals, ok := GetAccountBalances(state, ctx.Caller())
 	if !ok {
@@ -98,6 +99,51 @@ func withdrawToAddress(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return nil, nil
 }
 
+// harvest sends accrued fees of the caller to the caller's address on L1 in one step.
+// Intended for the validator fee target and for the chain owner to sweep collected fees.
+// Only balances of colors which reached the threshold are sent, the rest stays on the account.
+// Params:
+// - ParamMinAmount the threshold. Default is 1, i.e. all non-zero balances are harvested
+// Returns harvested balances in the same form as the 'balance' view
+func harvest(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	mustCheckLedger(state, "accounts.harvest.begin")
+	defer mustCheckLedger(state, "accounts.harvest.exit")
+
+	a := assert.NewAssert(ctx.Log())
+	a.RequireAuthorized(ctx.Caller().IsAddress(), "accounts.harvest: caller must be an address")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	minAmount := params.MustGetInt64(ParamMinAmount, 1)
+	a.Require(minAmount > 0, "accounts.harvest: wrong threshold %d", minAmount)
+
+	bals, ok := GetAccountBalances(state, ctx.Caller())
+	if !ok {
+		// empty balance, nothing to harvest
+		return nil, nil
+	}
+	harvested := make(map[balance.Color]int64)
+	for col, bal := range bals {
+		if bal >= minAmount {
+			harvested[col] = bal
+		}
+	}
+	if len(harvested) == 0 {
+		ctx.Log().Debugf("accounts.harvest: nothing above threshold %d", minAmount)
+		return nil, nil
+	}
+	sendTokens := cbalances.NewFromMap(harvested)
+	addr := ctx.Caller().MustAddress()
+
+	a.Require(DebitFromAccount(state, ctx.Caller(), sendTokens),
+		"accounts.harvest.inconsistency. failed to remove tokens from the chain")
+	a.Require(ctx.TransferToAddress(addr, sendTokens),
+		"accounts.harvest.inconsistency: failed to transfer tokens to address")
+
+	ctx.Log().Debugf("accounts.harvest.success. Sent to address %s -- %s", addr.String(), sendTokens.String())
+	return EncodeBalances(harvested), nil
+}
+
 // withdrawToChain sends caller's funds to the caller via account::deposit.
 func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 	state := ctx.State()
@@ -111,7 +157,7 @@ func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Debugf("accounts.withdrawToChain.begin: caller agentID: %s myContractId: %s",
 		caller.String(), cid.String())
 
-	a.Require(!caller.IsAddress(), "caller must be a smart contract")
+	a.RequireAuthorized(!caller.IsAddress(), "caller must be a smart contract")
 
 	bals, ok := GetAccountBalances(state, caller)
 	if !ok {
@@ -140,3 +186,211 @@ func withdrawToChain(ctx coretypes.Sandbox) (dict.Dict, error) {
 	a.Require(succ, "accounts.withdrawToChain.inconsistency: failed to post 'deposit' request")
 	return nil, nil
 }
+
+// transferAccount moves all funds of the caller's account, all colors, to the account of another agent ID in one step,
+// for example when the owner rotates keys or migrates from the address to a smart contract.
+// The incoming transfer of the request is moved together with the account
+// Params:
+// - ParamAgentID the new owner of the funds
+// Returns moved balances in the same form as the 'balance' view
+func transferAccount(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	mustCheckLedger(state, "accounts.transferAccount.begin")
+	defer mustCheckLedger(state, "accounts.transferAccount.exit")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+
+	caller := ctx.Caller()
+	myAgentID := coretypes.NewAgentIDFromContractID(ctx.ContractID())
+	target := params.MustGetAgentID(ParamAgentID)
+	a.Require(target != caller, "accounts.transferAccount: target must be another agent ID")
+	a.Require(target != myAgentID, "accounts.transferAccount: can't transfer to the account of 'accounts'")
+
+	// the incoming transfer is on the account of 'accounts', it becomes part of the caller's account first
+	if transfer := ctx.IncomingTransfer(); transfer != nil && transfer.Len() > 0 {
+		a.Require(MoveBetweenAccounts(state, myAgentID, caller, transfer),
+			"accounts.transferAccount.inconsistency: failed to move the incoming transfer")
+	}
+	bals, ok := GetAccountBalances(state, caller)
+	if !ok {
+		// empty account, nothing to transfer
+		return nil, nil
+	}
+	moved := cbalances.NewFromMap(bals)
+	a.Require(MoveBetweenAccounts(state, caller, target, moved),
+		"accounts.transferAccount.inconsistency: failed to move funds to %s", target)
+
+	ctx.Event(fmt.Sprintf("[transfer account] from: %s, to: %s, funds: %s", caller, target, cbalances.Str(moved)))
+	return EncodeBalances(bals), nil
+}
+
+// registerToken registers or updates metadata of the colored token.
+// The token is registered by the request sent in the transaction which minted the token, i.e. the color of the token is
+// the ID of the request transaction. The caller becomes the owner of the metadata and only the owner can update it later
+// Params:
+// - ParamColor. default is the color minted by the request transaction
+// - ParamTokenName
+// - ParamTokenSymbol
+// - ParamTokenDecimals. default is 0
+// - ParamTokenSupplyCap. default is 0, i.e. no cap
+func registerToken(ctx coretypes.Sandbox) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+
+	reqID := ctx.RequestID()
+	mintedColor := balance.Color(*reqID.TransactionID())
+	color := params.MustGetColor(ParamColor, mintedColor)
+	decimals := params.MustGetInt64(ParamTokenDecimals, 0)
+	a.Require(decimals >= 0 && decimals <= MaxTokenDecimals, "accounts.registerToken: wrong number of decimals %d", decimals)
+	meta := &TokenMetadata{
+		Name:      params.MustGetString(ParamTokenName),
+		Symbol:    params.MustGetString(ParamTokenSymbol),
+		Decimals:  uint8(decimals),
+		SupplyCap: params.MustGetInt64(ParamTokenSupplyCap, 0),
+		Owner:     ctx.Caller(),
+	}
+	a.Require(meta.SupplyCap >= 0, "accounts.registerToken: wrong supply cap %d", meta.SupplyCap)
+	a.Require(meta.Name != "" && meta.Symbol != "", "accounts.registerToken: name and symbol can't be empty")
+
+	prev, err := GetTokenMetadata(ctx.State(), color)
+	a.RequireNoError(err)
+	if prev != nil {
+		a.RequireAuthorized(prev.Owner == ctx.Caller(), "accounts.registerToken: only %s can update metadata of %s", prev.Owner, color)
+		meta.MintedSupply = prev.MintedSupply
+	} else {
+		a.Require(color == mintedColor && ctx.MintedSupply() > 0,
+			"accounts.registerToken: token %s must be minted by the request transaction", color)
+		meta.MintedSupply = ctx.MintedSupply()
+	}
+	a.Require(meta.SupplyCap == 0 || meta.SupplyCap >= meta.MintedSupply,
+		"accounts.registerToken: supply cap %d is less than minted supply %d", meta.SupplyCap, meta.MintedSupply)
+
+	setTokenMetadata(ctx.State(), color, meta)
+	ctx.Event(fmt.Sprintf("[register token] color: %s, name: '%s', symbol: %s, decimals: %d, supply cap: %d",
+		color, meta.Name, meta.Symbol, meta.Decimals, meta.SupplyCap))
+	return nil, nil
+}
+
+// getTokenMetadata returns metadata of the colored token registered on the chain
+// Params:
+// - ParamColor
+// Returns the encoded TokenMetadata in ParamTokenMetadata or nothing if the token is not registered
+func getTokenMetadata(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	color, err := params.GetColor(ParamColor)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := GetTokenMetadata(ctx.State(), color)
+	if err != nil || meta == nil {
+		return nil, err
+	}
+	ret := dict.New()
+	ret.Set(ParamTokenMetadata, EncodeTokenMetadata(meta))
+	return ret, nil
+}
+
+// createEscrow puts the incoming transfer into the escrow for the recipient.
+// The recipient claims the funds with 'claimEscrow' before the deadline by satisfying the condition,
+// otherwise the funds are returned to the account of the caller on the chain after the deadline
+// Params:
+// - ParamAgentID the recipient
+// - ParamConditionSC the hname of the contract of the condition
+// - ParamConditionEP the hname of the entry point of the condition. The call to it must succeed to claim the funds
+// - ParamDeadline the timestamp in nanoseconds
+// Returns the ID of the escrow in ParamEscrowID
+func createEscrow(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	mustCheckLedger(state, "accounts.createEscrow.begin")
+	defer mustCheckLedger(state, "accounts.createEscrow.exit")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+
+	escrow := &Escrow{
+		Sender:              ctx.Caller(),
+		Recipient:           params.MustGetAgentID(ParamAgentID),
+		Funds:               ctx.IncomingTransfer(),
+		ConditionContract:   params.MustGetHname(ParamConditionSC),
+		ConditionEntryPoint: params.MustGetHname(ParamConditionEP),
+		Deadline:            params.MustGetInt64(ParamDeadline),
+	}
+	a.Require(escrow.Funds != nil && escrow.Funds.Len() > 0, "accounts.createEscrow: no funds transferred")
+	a.Require(escrow.Deadline > ctx.GetTimestamp(), "accounts.createEscrow: deadline %d is in the past", escrow.Deadline)
+
+	// the incoming transfer stays on the account of 'accounts' until the escrow is claimed or refunded
+	id := newEscrowID(state)
+	setEscrow(state, id, escrow)
+
+	ctx.Event(fmt.Sprintf("[create escrow] id: %d, %s", id, escrow))
+	ret := dict.New()
+	ret.Set(ParamEscrowID, codec.EncodeInt64(id))
+	return ret, nil
+}
+
+// claimEscrow moves funds of the escrow to the account of the recipient on the chain.
+// The caller must be the recipient. The condition entry point is called with the params of the claim
+// and with the ID of the escrow, the claim fails if the call fails
+// Params:
+// - ParamEscrowID
+func claimEscrow(ctx coretypes.Sandbox) (dict.Dict, error) {
+	state := ctx.State()
+	mustCheckLedger(state, "accounts.claimEscrow.begin")
+	defer mustCheckLedger(state, "accounts.claimEscrow.exit")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a := assert.NewAssert(ctx.Log())
+
+	id := params.MustGetInt64(ParamEscrowID)
+	escrow, err := GetEscrow(state, id)
+	a.RequireNoError(err)
+	a.Require(escrow != nil, "accounts.claimEscrow: escrow #%d does not exist", id)
+	a.RequireAuthorized(escrow.Recipient == ctx.Caller(), "accounts.claimEscrow: only %s can claim escrow #%d", escrow.Recipient, id)
+	a.Require(ctx.GetTimestamp() < escrow.Deadline, "accounts.claimEscrow: escrow #%d has expired", id)
+
+	_, err = ctx.Call(escrow.ConditionContract, escrow.ConditionEntryPoint, ctx.Params(), nil)
+	a.Require(err == nil, "accounts.claimEscrow: condition of escrow #%d is not satisfied: %v", id, err)
+
+	// funds of the escrow are on the account of 'accounts'
+	succ := MoveBetweenAccounts(state, coretypes.NewAgentIDFromContractID(ctx.ContractID()), escrow.Recipient, escrow.Funds)
+	a.Require(succ, "accounts.claimEscrow.inconsistency: failed to move funds of escrow #%d", id)
+	delEscrow(state, id)
+
+	ctx.Event(fmt.Sprintf("[claim escrow] id: %d, recipient: %s, funds: %s", id, escrow.Recipient, cbalances.Str(escrow.Funds)))
+	return nil, nil
+}
+
+// getEscrow returns the escrow
+// Params:
+// - ParamEscrowID
+// Returns the encoded Escrow in ParamEscrow or nothing if the escrow was claimed or refunded
+func getEscrow(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	id, err := params.GetInt64(ParamEscrowID)
+	if err != nil {
+		return nil, err
+	}
+	escrow, err := GetEscrow(ctx.State(), id)
+	if err != nil || escrow == nil {
+		return nil, err
+	}
+	ret := dict.New()
+	ret.Set(ParamEscrow, EncodeEscrow(escrow))
+	return ret, nil
+}
+
+// getAnchorFeesPaid returns the total of anchor fees paid by the account, see root.AnchorFeePolicy
+// Params:
+// - ParamAgentID
+// Returns the number of iotas in ParamAmount
+func getAnchorFeesPaid(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	aid, err := params.GetAgentID(ParamAgentID)
+	if err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	ret.Set(ParamAmount, codec.EncodeInt64(GetAnchorFeesPaid(ctx.State(), aid)))
+	return ret, nil
+}
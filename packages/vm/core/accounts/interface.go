@@ -1,6 +1,7 @@
 package accounts
 
 import (
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
 	"github.com/iotaledger/wasp/packages/hashing"
 )
@@ -20,12 +21,39 @@ var (
 
 func init() {
 	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
-		coreutil.ViewFunc(FuncBalance, getBalance),
+		coreutil.ViewFunc(FuncBalance, getBalance).
+			WithTypedParams(coreutil.Param(ParamAgentID, coretypes.ParamTypeAgentID)),
 		coreutil.ViewFunc(FuncTotalAssets, getTotalAssets),
 		coreutil.ViewFunc(FuncAccounts, getAccounts),
-		coreutil.Func(FuncDeposit, deposit),
+		coreutil.Func(FuncDeposit, deposit).
+			WithTypedParams(coreutil.Param(ParamAgentID, coretypes.ParamTypeAgentID)),
 		coreutil.Func(FuncWithdrawToAddress, withdrawToAddress),
 		coreutil.Func(FuncWithdrawToChain, withdrawToChain),
+		coreutil.Func(FuncHarvest, harvest).
+			WithTypedParams(coreutil.Param(ParamMinAmount, coretypes.ParamTypeInt64)),
+		coreutil.Func(FuncRegisterToken, registerToken).WithTypedParams(
+			coreutil.Param(ParamColor, coretypes.ParamTypeColor),
+			coreutil.Param(ParamTokenName, coretypes.ParamTypeString),
+			coreutil.Param(ParamTokenSymbol, coretypes.ParamTypeString),
+			coreutil.Param(ParamTokenDecimals, coretypes.ParamTypeInt64),
+			coreutil.Param(ParamTokenSupplyCap, coretypes.ParamTypeInt64),
+		),
+		coreutil.ViewFunc(FuncGetTokenMetadata, getTokenMetadata).
+			WithTypedParams(coreutil.Param(ParamColor, coretypes.ParamTypeColor)),
+		coreutil.Func(FuncCreateEscrow, createEscrow).WithTypedParams(
+			coreutil.Param(ParamAgentID, coretypes.ParamTypeAgentID),
+			coreutil.Param(ParamConditionSC, coretypes.ParamTypeHname),
+			coreutil.Param(ParamConditionEP, coretypes.ParamTypeHname),
+			coreutil.Param(ParamDeadline, coretypes.ParamTypeInt64),
+		),
+		coreutil.Func(FuncClaimEscrow, claimEscrow).
+			WithTypedParams(coreutil.Param(ParamEscrowID, coretypes.ParamTypeInt64)),
+		coreutil.ViewFunc(FuncGetEscrow, getEscrow).
+			WithTypedParams(coreutil.Param(ParamEscrowID, coretypes.ParamTypeInt64)),
+		coreutil.ViewFunc(FuncGetAnchorFeesPaid, getAnchorFeesPaid).
+			WithTypedParams(coreutil.Param(ParamAgentID, coretypes.ParamTypeAgentID)),
+		coreutil.Func(FuncTransferAccount, transferAccount).
+			WithTypedParams(coreutil.Param(ParamAgentID, coretypes.ParamTypeAgentID)),
 	})
 }
 
@@ -36,6 +64,27 @@ const (
 	FuncWithdrawToAddress = "withdrawToAddress"
 	FuncWithdrawToChain   = "withdrawToChain"
 	FuncAccounts          = "accounts"
+	FuncHarvest           = "harvest"
+	FuncRegisterToken     = "registerToken"
+	FuncGetTokenMetadata  = "getTokenMetadata"
+	FuncCreateEscrow      = "createEscrow"
+	FuncClaimEscrow       = "claimEscrow"
+	FuncGetEscrow         = "getEscrow"
+	FuncGetAnchorFeesPaid = "getAnchorFeesPaid"
+	FuncTransferAccount   = "transferAccount"
 
-	ParamAgentID = "a"
+	ParamAgentID        = "a"
+	ParamMinAmount      = "m"
+	ParamColor          = "c"
+	ParamTokenName      = "tn"
+	ParamTokenSymbol    = "ts"
+	ParamTokenDecimals  = "td"
+	ParamTokenSupplyCap = "tc"
+	ParamTokenMetadata  = "tm"
+	ParamEscrowID       = "e"
+	ParamEscrow         = "es"
+	ParamDeadline       = "d"
+	ParamConditionSC    = "cs"
+	ParamConditionEP    = "ce"
+	ParamAmount         = "am"
 )
@@ -182,6 +182,11 @@ func getTotalAssetsIntern(state kv.KVStoreReader) coretypes.ColoredBalances {
 	return cbalances.NewFromMap(getAccountBalances(getTotalAssetsAccountR(state)))
 }
 
+// GetTotalAssets returns the sum of tokens on all on-chain accounts
+func GetTotalAssets(state kv.KVStoreReader) coretypes.ColoredBalances {
+	return getTotalAssetsIntern(state)
+}
+
 func calcTotalAssets(state kv.KVStoreReader) coretypes.ColoredBalances {
 	ret := make(map[balance.Color]int64)
 	getAccountsMapR(state).MustIterateKeys(func(key []byte) bool {
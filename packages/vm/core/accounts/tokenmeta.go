@@ -0,0 +1,111 @@
+package accounts
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+const varStateTokenMetadata = "m"
+
+// MaxTokenDecimals is the maximum number of decimals of the colored token
+const MaxTokenDecimals = 18
+
+// TokenMetadata is the metadata of the colored token registered on the chain by the minter of the token.
+// The supply of colored tokens is minted on L1, so the supply cap is not enforced on the chain:
+// it is only checked against the supply minted by the registering transaction
+type TokenMetadata struct {
+	Name     string
+	Symbol   string
+	Decimals uint8
+	// SupplyCap is the maximum supply announced by the minter. 0 means no cap
+	SupplyCap int64
+	// MintedSupply is the number of tokens minted by the transaction which registered the token
+	MintedSupply int64
+	// Owner is the agent which registered the token. Only the owner can update the metadata
+	Owner coretypes.AgentID
+}
+
+func (m *TokenMetadata) Write(w io.Writer) error {
+	if err := util.WriteString16(w, m.Name); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, m.Symbol); err != nil {
+		return err
+	}
+	if err := util.WriteByte(w, m.Decimals); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, m.SupplyCap); err != nil {
+		return err
+	}
+	if err := util.WriteInt64(w, m.MintedSupply); err != nil {
+		return err
+	}
+	_, err := w.Write(m.Owner[:])
+	return err
+}
+
+func (m *TokenMetadata) Read(r io.Reader) error {
+	var err error
+	if m.Name, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if m.Symbol, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if m.Decimals, err = util.ReadByte(r); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &m.SupplyCap); err != nil {
+		return err
+	}
+	if err = util.ReadInt64(r, &m.MintedSupply); err != nil {
+		return err
+	}
+	return coretypes.ReadAgentID(r, &m.Owner)
+}
+
+// FormatAmount formats the amount of tokens according to decimals and symbol of the token, e.g. '12.50 ABC'
+func (m *TokenMetadata) FormatAmount(amount int64) string {
+	if m.Decimals == 0 {
+		return fmt.Sprintf("%d %s", amount, m.Symbol)
+	}
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	s := fmt.Sprintf("%0*d", int(m.Decimals)+1, amount)
+	pos := len(s) - int(m.Decimals)
+	return fmt.Sprintf("%s%s.%s %s", sign, s[:pos], s[pos:], m.Symbol)
+}
+
+func EncodeTokenMetadata(m *TokenMetadata) []byte {
+	return util.MustBytes(m)
+}
+
+func DecodeTokenMetadata(data []byte) (*TokenMetadata, error) {
+	ret := new(TokenMetadata)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+// GetTokenMetadata returns metadata of the token or nil if the token is not registered
+func GetTokenMetadata(state kv.KVStoreReader, color balance.Color) (*TokenMetadata, error) {
+	data := collections.NewMapReadOnly(state, varStateTokenMetadata).MustGetAt(color[:])
+	if data == nil {
+		return nil, nil
+	}
+	return DecodeTokenMetadata(data)
+}
+
+func setTokenMetadata(state kv.KVStore, color balance.Color, m *TokenMetadata) {
+	collections.NewMap(state, varStateTokenMetadata).MustSetAt(color[:], EncodeTokenMetadata(m))
+}
@@ -23,13 +23,23 @@ func storeBlob(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Debugf("blob.storeBlob.begin")
 	state := ctx.State()
 	params := ctx.Params()
+	a := assert.NewAssert(ctx.Log())
 	// calculate a deterministic hash of all blob fields
 	blobHash, kSorted, values := mustGetBlobHash(params)
 
 	directory := GetDirectory(state)
-	assert.NewAssert(ctx.Log()).Require(!directory.MustHasAt(blobHash[:]),
+	a.Require(!directory.MustHasAt(blobHash[:]),
 		"blob.storeBlob.fail: blob with hash %s already exist", blobHash.String())
 
+	if maxBlobSize := getMaxBlobSize(ctx); maxBlobSize > 0 {
+		blobSize := int64(0)
+		for _, v := range values {
+			blobSize += int64(len(v))
+		}
+		a.Require(blobSize <= maxBlobSize,
+			"blob.storeBlob.fail: size of the blob %d exceeds the limit %d", blobSize, maxBlobSize)
+	}
+
 	// get a record by blob hash
 	blbValues := GetBlobValues(state, blobHash)
 	blbSizes := GetBlobSizes(state, blobHash)
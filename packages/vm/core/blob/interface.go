@@ -45,3 +45,12 @@ const (
 	FuncStoreBlob    = "storeBlob"
 	FuncListBlobs    = "listBlobs"
 )
+
+// the blob size limit is set in the 'root' contract. The root contract imports this package,
+// so its view is called by names. They must be the same as root.Name, root.FuncGetResourceLimits
+// and root.ParamMaxBlobSize
+const (
+	rootContractName          = "root"
+	rootFuncGetResourceLimits = "getResourceLimits"
+	rootParamMaxBlobSize      = "$$maxblobsize$$"
+)
@@ -3,11 +3,13 @@ package blob
 import (
 	"fmt"
 
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
 	"github.com/iotaledger/wasp/packages/util"
 )
 
@@ -119,3 +121,13 @@ func DecodeDirectory(blobs dict.Dict) (map[hashing.HashValue]uint32, error) {
 	}
 	return ret, nil
 }
+
+// getMaxBlobSize returns the blob size limit of the chain, 0 means no limit
+func getMaxBlobSize(ctx coretypes.Sandbox) int64 {
+	ret, err := ctx.Call(coretypes.Hn(rootContractName), coretypes.Hn(rootFuncGetResourceLimits), nil, nil)
+	if err != nil {
+		ctx.Log().Panicf("blob: can't get resource limits: %v", err)
+	}
+	par := kvdecoder.New(ret, ctx.Log())
+	return par.MustGetInt64(rootParamMaxBlobSize, 0)
+}
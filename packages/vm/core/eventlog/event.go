@@ -0,0 +1,70 @@
+package eventlog
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Event is the structured event emitted by the smart contract with Sandbox.EmitEvent.
+// The event has a topic and a typed payload: values of the fields are encoded with the codec,
+// same as parameters of the request
+type Event struct {
+	Topic     string
+	Fields    dict.Dict
+	RequestID coretypes.RequestID
+}
+
+func EventFromBytes(data []byte) (*Event, error) {
+	ret := &Event{}
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (e *Event) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = e.Write(&buf)
+	return buf.Bytes()
+}
+
+func (e *Event) Write(w io.Writer) error {
+	if err := util.WriteString16(w, e.Topic); err != nil {
+		return err
+	}
+	if err := e.RequestID.Write(w); err != nil {
+		return err
+	}
+	fields := e.Fields
+	if fields == nil {
+		fields = dict.New()
+	}
+	return fields.Write(w)
+}
+
+func (e *Event) Read(r io.Reader) error {
+	var err error
+	if e.Topic, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if err = e.RequestID.Read(r); err != nil {
+		return err
+	}
+	e.Fields = dict.New()
+	return e.Fields.Read(r)
+}
+
+func (e *Event) String() string {
+	fields := make([]string, 0, len(e.Fields))
+	for _, k := range e.Fields.KeysSorted() {
+		fields = append(fields, fmt.Sprintf("%s=0x%s", string(k), hex.EncodeToString(e.Fields[k])))
+	}
+	return fmt.Sprintf("%s(%s) req: %s", e.Topic, strings.Join(fields, ", "), e.RequestID.String())
+}
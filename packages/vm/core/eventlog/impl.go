@@ -1,6 +1,9 @@
 package eventlog
 
 import (
+	"fmt"
+	"math"
+
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
@@ -27,7 +30,7 @@ func getNumRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 	}
 	ret := dict.New()
 	thelog := collections.NewTimestampedLogReadOnly(ctx.State(), kv.Key(contractHname.Bytes()))
-	ret.Set(ParamNumRecords, codec.EncodeInt64(int64(thelog.MustLen())))
+	ret.Set(ParamNumRecords, codec.EncodeInt64(int64(thelog.MustNumRecords())))
 	return ret, nil
 }
 
@@ -73,3 +76,102 @@ func getRecords(ctx coretypes.SandboxView) (dict.Dict, error) {
 	}
 	return ret, nil
 }
+
+// getEvents returns typed events of the contract with the topic between timestamp interval
+// In time descending order. Each record can be parsed with ParseEventRecord
+// Parameters:
+//	- ParamContractHname Hname of the contract which emitted events
+//	- ParamTopic Topic of events
+//	- ParamField Optional, name of the indexed field of the event
+//	- ParamValue Value of the indexed field. Mandatory if ParamField is present
+//	- ParamFromTs From interval. Defaults to 0
+//	- ParamToTs To Interval. Defaults to now
+//	- ParamMaxLastRecords Max amount of records that you want to return. Defaults to 50
+func getEvents(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+
+	contractHname, err := params.GetHname(ParamContractHname)
+	if err != nil {
+		return nil, err
+	}
+	topic, err := params.GetString(ParamTopic)
+	if err != nil {
+		return nil, err
+	}
+	field, err := params.GetString(ParamField, "")
+	if err != nil {
+		return nil, err
+	}
+	var value []byte
+	if field != "" {
+		if value, err = params.GetBytes(ParamValue); err != nil {
+			return nil, err
+		}
+	}
+	maxLast, err := params.GetInt64(ParamMaxLastRecords, DefaultMaxNumberOfRecords)
+	if err != nil {
+		return nil, err
+	}
+	fromTs, err := params.GetInt64(ParamFromTs, 0)
+	if err != nil {
+		return nil, err
+	}
+	toTs, err := params.GetInt64(ParamToTs, ctx.GetTimestamp())
+	if err != nil {
+		return nil, err
+	}
+
+	data := queryEvents(ctx.State(), contractHname, topic, kv.Key(field), value, fromTs, toTs, uint32(maxLast))
+	if len(data) == 0 {
+		return nil, nil
+	}
+	ret := dict.New()
+	a := collections.NewArray(ret, ParamRecords)
+	for _, s := range data {
+		a.MustPush(s)
+	}
+	return ret, nil
+}
+
+// getRequestReceipts returns receipts of requests sent by the agent to the chain, the latest first.
+// Each record can be parsed with ParseReceiptRecord
+// Parameters:
+//	- ParamAgentID Agent ID of the sender of requests
+//	- ParamBeforeIndex Optional, the receipt with the index before it is returned first. Defaults to the latest receipt
+//	- ParamMaxLastRecords Max amount of records that you want to return. Defaults to 50, not more than MaxReceiptsPerCall
+// Returns:
+//	- ParamRecords Array of raw records
+//	- ParamNextIndex The ParamBeforeIndex of the next page, if there are older receipts
+func getRequestReceipts(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+
+	agentID, err := params.GetAgentID(ParamAgentID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := params.GetInt64(ParamBeforeIndex, int64(NumReceipts(ctx.State(), agentID)))
+	if err != nil {
+		return nil, err
+	}
+	maxLast, err := params.GetInt64(ParamMaxLastRecords, DefaultMaxNumberOfRecords)
+	if err != nil {
+		return nil, err
+	}
+	if before < 0 || before > math.MaxUint32 || maxLast <= 0 {
+		return nil, fmt.Errorf("getRequestReceipts: wrong parameters")
+	}
+	if maxLast > MaxReceiptsPerCall {
+		maxLast = MaxReceiptsPerCall
+	}
+
+	data, next, more := GetReceipts(ctx.State(), agentID, uint32(before), uint32(maxLast))
+	ret := dict.New()
+	a := collections.NewArray(ret, ParamRecords)
+	for _, s := range data {
+		a.MustPush(s)
+	}
+	if more {
+		ret.Set(ParamNextIndex, codec.EncodeInt64(int64(next)))
+	}
+	return ret, nil
+}
@@ -22,6 +22,8 @@ func init() {
 	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
 		coreutil.ViewFunc(FuncGetRecords, getRecords),
 		coreutil.ViewFunc(FuncGetNumRecords, getNumRecords),
+		coreutil.ViewFunc(FuncGetEvents, getEvents),
+		coreutil.ViewFunc(FuncGetRequestReceipts, getRequestReceipts),
 	})
 }
 
@@ -33,10 +35,18 @@ const (
 	ParamMaxLastRecords = "maxLastRecords"
 	ParamNumRecords     = "numRecords"
 	ParamRecords        = "records"
+	ParamTopic          = "topic"
+	ParamField          = "field"
+	ParamValue          = "value"
+	ParamAgentID        = "agentID"
+	ParamBeforeIndex    = "beforeIndex"
+	ParamNextIndex      = "nextIndex"
 
 	// function names
-	FuncGetRecords    = "getRecords"
-	FuncGetNumRecords = "getNumRecords"
+	FuncGetRecords         = "getRecords"
+	FuncGetNumRecords      = "getNumRecords"
+	FuncGetEvents          = "getEvents"
+	FuncGetRequestReceipts = "getRequestReceipts"
 
 	DefaultMaxNumberOfRecords = 50
 )
@@ -1,11 +1,176 @@
 package eventlog
 
 import (
+	"bytes"
+	"fmt"
+	"math"
+	"time"
+
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// The free-form log of the contract is stored under the key equal to the contract hname.
+// Typed events and their indices are stored under the keys prefixed with the contract hname and
+// a marker, which never clashes with the keys of the free-form log
+const (
+	markerEvents     = 'E'
+	markerTopicIndex = 'T'
+	markerFieldIndex = 'F'
+	markerReceipts   = 'R'
 )
 
+// MaxPrunedPerRequest is the maximum number of records pruned from event logs while processing one request.
+// Records left over after the retention policy is changed are pruned gradually by the following requests
+const MaxPrunedPerRequest = 100
+
 func AppendToLog(state kv.KVStore, ts int64, contract coretypes.Hname, data []byte) {
 	collections.NewTimestampedLog(state, kv.Key(contract.Bytes())).MustAppend(ts, data)
 }
+
+// PruneLog deletes records from the log of the contract according to the retention limits:
+// not more than maxRecords latest records and no records older than maxAge seconds relative to ts are kept.
+// 0 means no limit. Not more than 'limit' records are deleted. Returns the number of deleted records
+func PruneLog(state kv.KVStore, ts int64, contract coretypes.Hname, maxRecords int64, maxAge int64, limit uint32) uint32 {
+	return pruneTimestampedLog(collections.NewTimestampedLog(state, kv.Key(contract.Bytes())), ts, maxRecords, maxAge, limit)
+}
+
+// AppendEvent stores the typed event of the contract and adds it to the index of the topic and to the indices
+// of the fields listed in 'indexed'. Panics if an indexed field is not in the event
+func AppendEvent(state kv.KVStore, ts int64, contract coretypes.Hname, ev *Event, indexed []kv.Key) {
+	events := collections.NewTimestampedLog(state, eventsKey(contract))
+	idx := events.MustLen()
+	events.MustAppend(ts, ev.Bytes())
+
+	idxBytes := util.Uint32To4Bytes(idx)
+	collections.NewTimestampedLog(state, topicIndexKey(contract, ev.Topic)).MustAppend(ts, idxBytes)
+	for _, field := range indexed {
+		value := ev.Fields.MustGet(field)
+		if value == nil {
+			panic(fmt.Sprintf("AppendEvent: indexed field '%s' is not in the event", string(field)))
+		}
+		collections.NewTimestampedLog(state, fieldIndexKey(contract, ev.Topic, field, value)).MustAppend(ts, idxBytes)
+	}
+}
+
+// PruneEvents deletes typed events of the contract according to the same retention limits as PruneLog.
+// Entries of the indices which point to deleted events are skipped by queries
+func PruneEvents(state kv.KVStore, ts int64, contract coretypes.Hname, maxRecords int64, maxAge int64, limit uint32) uint32 {
+	return pruneTimestampedLog(collections.NewTimestampedLog(state, eventsKey(contract)), ts, maxRecords, maxAge, limit)
+}
+
+func pruneTimestampedLog(theLog *collections.TimestampedLog, ts int64, maxRecords int64, maxAge int64, limit uint32) uint32 {
+	pruned := uint32(0)
+	// larger ages are rejected by the root contract, so it never overflows
+	if maxAge > 0 && maxAge <= math.MaxInt64/int64(time.Second) {
+		pruned += theLog.MustPruneBefore(ts-maxAge*int64(time.Second), limit)
+	}
+	if maxRecords > 0 && pruned < limit {
+		if n := int64(theLog.MustNumRecords()); n > maxRecords {
+			toPrune := uint32(n - maxRecords)
+			if toPrune > limit-pruned {
+				toPrune = limit - pruned
+			}
+			pruned += theLog.MustPruneHead(toPrune)
+		}
+	}
+	return pruned
+}
+
+// queryEvents returns raw records of typed events of the contract with the timestamp between fromTs and toTs,
+// the latest first. If field is not nil, only events with the field equal to value are returned.
+// Each raw record contains the timestamp and the serialized event, it can be parsed with ParseEventRecord
+func queryEvents(state kv.KVStoreReader, contract coretypes.Hname, topic string, field kv.Key, value []byte, fromTs, toTs int64, maxRecords uint32) [][]byte {
+	indexKey := topicIndexKey(contract, topic)
+	if field != "" {
+		indexKey = fieldIndexKey(contract, topic, field, value)
+	}
+	index := collections.NewTimestampedLogReadOnly(state, indexKey)
+	tts := index.MustTakeTimeSlice(fromTs, toTs)
+	if tts.IsEmpty() {
+		return nil
+	}
+	events := collections.NewTimestampedLogReadOnly(state, eventsKey(contract))
+	firstEvent := events.MustFirstIndex()
+
+	first, last := tts.FromToIndices()
+	ret := make([][]byte, 0)
+	for i := last; uint32(len(ret)) < maxRecords; i-- {
+		r, err := collections.ParseRawLogRecord(index.MustLoadRecordsRaw(i, i, false)[0])
+		if err != nil {
+			panic(err)
+		}
+		idx := util.MustUint32From4Bytes(r.Data)
+		if idx < firstEvent {
+			// the event was pruned, so were all older events
+			break
+		}
+		ret = append(ret, events.MustLoadRecordsRaw(idx, idx, false)...)
+		if i == first {
+			break
+		}
+	}
+	return ret
+}
+
+// ParseEventRecord parses the raw record returned by the view getEvents
+func ParseEventRecord(raw []byte) (int64, *Event, error) {
+	rec, err := collections.ParseRawLogRecord(raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	ev, err := EventFromBytes(rec.Data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return rec.Timestamp, ev, nil
+}
+
+// RecordsBetween returns records of the free-form log and raw records of typed events of the contract
+// with timestamps between fromTs and toTs inclusive, the earliest first. Pruned records are not returned
+func RecordsBetween(state kv.KVStoreReader, contract coretypes.Hname, fromTs, toTs int64) ([]*collections.TimestampedLogRecord, [][]byte) {
+	var records []*collections.TimestampedLogRecord
+	for _, raw := range rawRecordsBetween(state, kv.Key(contract.Bytes()), fromTs, toTs) {
+		r, err := collections.ParseRawLogRecord(raw)
+		if err != nil {
+			panic(err)
+		}
+		records = append(records, r)
+	}
+	return records, rawRecordsBetween(state, eventsKey(contract), fromTs, toTs)
+}
+
+func rawRecordsBetween(state kv.KVStoreReader, key kv.Key, fromTs, toTs int64) [][]byte {
+	if fromTs <= 0 || toTs < fromTs {
+		// 0 means the earliest or the latest record to the time slice
+		return nil
+	}
+	theLog := collections.NewTimestampedLogReadOnly(state, key)
+	tts := theLog.MustTakeTimeSlice(fromTs, toTs)
+	if tts.IsEmpty() {
+		return nil
+	}
+	first, last := tts.FromToIndices()
+	return theLog.MustLoadRecordsRaw(first, last, false)
+}
+
+func eventsKey(contract coretypes.Hname) kv.Key {
+	return kv.Key(append(contract.Bytes(), markerEvents))
+}
+
+func topicIndexKey(contract coretypes.Hname, topic string) kv.Key {
+	h := hashing.HashStrings(topic)
+	return kv.Key(append(append(contract.Bytes(), markerTopicIndex), h[:]...))
+}
+
+func fieldIndexKey(contract coretypes.Hname, topic string, field kv.Key, value []byte) kv.Key {
+	var buf bytes.Buffer
+	_ = util.WriteString16(&buf, topic)
+	_ = util.WriteBytes16(&buf, []byte(field))
+	_ = util.WriteBytes32(&buf, value)
+	h := hashing.HashData(buf.Bytes())
+	return kv.Key(append(append(contract.Bytes(), markerFieldIndex), h[:]...))
+}
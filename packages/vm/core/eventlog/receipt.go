@@ -0,0 +1,163 @@
+package eventlog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/util"
+)
+
+// Receipts of requests are indexed by the sender, so wallets can show users their past interactions with the chain.
+// The index of the sender is the timestamped log stored under the hname of the event log contract itself,
+// the receipts marker and the hash of the agent ID. Only the latest MaxReceiptsPerSender receipts are kept
+
+// MaxReceiptsPerSender is the number of the latest receipts kept in the index of each sender
+const MaxReceiptsPerSender = 1000
+
+// MaxReceiptsPerCall is the maximum number of receipts returned by one call of getRequestReceipts
+const MaxReceiptsPerCall = 100
+
+// RequestReceipt is the outcome of the request processed by the chain
+type RequestReceipt struct {
+	RequestID coretypes.RequestID
+	// BlockIndex is the index of the block the request was processed in
+	BlockIndex uint32
+	// RequestIndex is the index of the request in the block
+	RequestIndex uint16
+	// Contract and EntryPoint are the target of the request
+	Contract   coretypes.Hname
+	EntryPoint coretypes.Hname
+	// ErrorCode is VMErrorNone if the request succeeded
+	ErrorCode coretypes.VMErrorCode
+	// Error is the error message of the failed request
+	Error string
+}
+
+func RequestReceiptFromBytes(data []byte) (*RequestReceipt, error) {
+	ret := &RequestReceipt{}
+	if err := ret.Read(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (rr *RequestReceipt) Bytes() []byte {
+	var buf bytes.Buffer
+	_ = rr.Write(&buf)
+	return buf.Bytes()
+}
+
+func (rr *RequestReceipt) Write(w io.Writer) error {
+	if err := rr.RequestID.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint32(w, rr.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, rr.RequestIndex); err != nil {
+		return err
+	}
+	if err := rr.Contract.Write(w); err != nil {
+		return err
+	}
+	if err := rr.EntryPoint.Write(w); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, uint16(rr.ErrorCode)); err != nil {
+		return err
+	}
+	return util.WriteString16(w, rr.Error)
+}
+
+func (rr *RequestReceipt) Read(r io.Reader) error {
+	var err error
+	if err = rr.RequestID.Read(r); err != nil {
+		return err
+	}
+	if err = util.ReadUint32(r, &rr.BlockIndex); err != nil {
+		return err
+	}
+	if err = util.ReadUint16(r, &rr.RequestIndex); err != nil {
+		return err
+	}
+	if err = rr.Contract.Read(r); err != nil {
+		return err
+	}
+	if err = rr.EntryPoint.Read(r); err != nil {
+		return err
+	}
+	var code uint16
+	if err = util.ReadUint16(r, &code); err != nil {
+		return err
+	}
+	rr.ErrorCode = coretypes.VMErrorCode(code)
+	rr.Error, err = util.ReadString16(r)
+	return err
+}
+
+func (rr *RequestReceipt) String() string {
+	outcome := "ok"
+	if rr.ErrorCode != coretypes.VMErrorNone {
+		outcome = fmt.Sprintf("error %d (%s): %s", rr.ErrorCode, rr.ErrorCode, rr.Error)
+	}
+	return fmt.Sprintf("req: %s block: #%d/%d target: %s::%s %s",
+		rr.RequestID.String(), rr.BlockIndex, rr.RequestIndex, rr.Contract, rr.EntryPoint, outcome)
+}
+
+// AppendReceipt adds the receipt of the request to the index of the sender and prunes the oldest receipt
+// of the sender beyond MaxReceiptsPerSender
+func AppendReceipt(state kv.KVStore, ts int64, sender coretypes.AgentID, receipt *RequestReceipt) {
+	receipts := collections.NewTimestampedLog(state, receiptsKey(sender))
+	receipts.MustAppend(ts, receipt.Bytes())
+	if n := receipts.MustNumRecords(); n > MaxReceiptsPerSender {
+		receipts.MustPruneHead(n - MaxReceiptsPerSender)
+	}
+}
+
+// GetReceipts returns raw records of receipts of requests of the sender, the latest first, starting with
+// the one before the index 'before'. The index of the latest receipt of the sender is NumReceipts()-1.
+// Each raw record contains the timestamp and the receipt, it can be parsed with ParseReceiptRecord.
+// Returns the index to pass as 'before' to get the next page and false if there are no older receipts
+func GetReceipts(state kv.KVStoreReader, sender coretypes.AgentID, before uint32, maxRecords uint32) ([][]byte, uint32, bool) {
+	receipts := collections.NewTimestampedLogReadOnly(state, receiptsKey(sender))
+	first := receipts.MustFirstIndex()
+	if n := receipts.MustLen(); before > n {
+		before = n
+	}
+	if maxRecords == 0 || before <= first {
+		return nil, before, false
+	}
+	from := first
+	if before-first > maxRecords {
+		from = before - maxRecords
+	}
+	return receipts.MustLoadRecordsRaw(from, before-1, true), from, from > first
+}
+
+// NumReceipts returns the number of receipts of the sender ever recorded, including pruned ones
+func NumReceipts(state kv.KVStoreReader, sender coretypes.AgentID) uint32 {
+	return collections.NewTimestampedLogReadOnly(state, receiptsKey(sender)).MustLen()
+}
+
+// ParseReceiptRecord parses the raw record returned by the view getRequestReceipts
+func ParseReceiptRecord(raw []byte) (int64, *RequestReceipt, error) {
+	rec, err := collections.ParseRawLogRecord(raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	receipt, err := RequestReceiptFromBytes(rec.Data)
+	if err != nil {
+		return 0, nil, err
+	}
+	return rec.Timestamp, receipt, nil
+}
+
+func receiptsKey(sender coretypes.AgentID) kv.Key {
+	h := hashing.HashData(sender[:])
+	return kv.Key(append(append(Interface.Hname().Bytes(), markerReceipts), h[:]...))
+}
@@ -0,0 +1,61 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package root
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+)
+
+// payers of the anchor fee, see AnchorFeePolicy
+const (
+	// AnchorFeePayerChain charges the anchor fee to the account of the chain owner. It is the default
+	AnchorFeePayerChain = int64(0)
+	// AnchorFeePayerValidator charges the anchor fee to the account of the validator fee target
+	// of the committee, i.e. to the fees collected by validators
+	AnchorFeePayerValidator = int64(1)
+	// AnchorFeePayerSponsor charges the anchor fee to the account of the external sponsor address.
+	// The sponsor funds it by depositing iotas to its account on the chain
+	AnchorFeePayerSponsor = int64(2)
+)
+
+// AnchorFeePolicy defines who funds the L1 costs of the anchor transaction of each block of the chain.
+// The VM charges the Fee to the on-chain account of the payer with the first request of the block and adds
+// the output with the Fee iotas to the Target address to the anchor transaction. If the account of the payer
+// doesn't have enough iotas, the fee is charged to the chain owner. Blocks without requests are not charged
+type AnchorFeePolicy struct {
+	// Fee is the number of iotas paid for each anchor transaction. 0 means no fee
+	Fee int64
+	// Payer is one of AnchorFeePayerChain, AnchorFeePayerValidator, AnchorFeePayerSponsor
+	Payer int64
+	// Sponsor is the address of the external sponsor, for AnchorFeePayerSponsor
+	Sponsor address.Address
+	// Target is the address the fee is paid to on L1
+	Target address.Address
+}
+
+// GetAnchorFeePolicy returns the policy of the anchor fee of the chain.
+// It is called from VMContext and by the consensus operator, it is not exposed to the sandbox
+func GetAnchorFeePolicy(state kv.KVStoreReader) *AnchorFeePolicy {
+	d := kvdecoder.New(state)
+	return &AnchorFeePolicy{
+		Fee:     d.MustGetInt64(VarAnchorFee, 0),
+		Payer:   d.MustGetInt64(VarAnchorFeePayer, AnchorFeePayerChain),
+		Sponsor: d.MustGetAddress(VarAnchorFeeSponsor, address.Address{}),
+		Target:  d.MustGetAddress(VarAnchorFeeTarget, address.Address{}),
+	}
+}
+
+// PayerAgentID returns the agent ID whose account pays the anchor fee
+func (p *AnchorFeePolicy) PayerAgentID(chainOwnerID, validatorFeeTarget coretypes.AgentID) coretypes.AgentID {
+	switch p.Payer {
+	case AnchorFeePayerValidator:
+		return validatorFeeTarget
+	case AnchorFeePayerSponsor:
+		return coretypes.NewAgentIDFromAddress(p.Sponsor)
+	}
+	return chainOwnerID
+}
@@ -0,0 +1,188 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package root
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/tcrypto/tbdn"
+	"github.com/iotaledger/wasp/packages/util"
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/pairing"
+	"go.dedis.ch/kyber/v3/share"
+	"go.dedis.ch/kyber/v3/sign/bdn"
+)
+
+// EquivocationWindow is the maximum difference between timestamps of two conflicting proposals of the
+// leader to be considered the same round. Proposals further apart may belong to different terms of the
+// leader, after the leader was rotated back
+const EquivocationWindow = 6 * time.Second
+
+// SignedProposal is the batch proposal of the leader, as received by a committee peer.
+// The leader signs it with its key share of the committee key
+type SignedProposal struct {
+	Timestamp    int64
+	ProposalHash hashing.HashValue
+	// signature share of the leader. It includes the index of the key share
+	LeaderSig []byte
+}
+
+// EquivocationEvidence is the proof that the leader signed conflicting batch proposals for the same block.
+// The evidence is self-contained: public commitments of the committee key authenticate the key share of the leader
+// against the chain address, so anybody may verify it, see VerifyEquivocationEvidence
+type EquivocationEvidence struct {
+	BlockIndex    uint32
+	LeaderIndex   uint16
+	Proposals     [2]SignedProposal
+	PublicCommits [][]byte
+}
+
+// ProposalEssence returns the data of the batch proposal signed by the leader
+func ProposalEssence(chainID *coretypes.ChainID, blockIndex uint32, timestamp int64, proposalHash hashing.HashValue) []byte {
+	var buf bytes.Buffer
+	buf.Write(chainID[:])
+	_ = util.WriteUint32(&buf, blockIndex)
+	_ = util.WriteInt64(&buf, timestamp)
+	buf.Write(proposalHash[:])
+	return buf.Bytes()
+}
+
+// VerifyEquivocationEvidence checks that both proposals of the evidence are conflicting proposals for the block,
+// signed by the leader of the chain's committee
+func VerifyEquivocationEvidence(chainID *coretypes.ChainID, ev *EquivocationEvidence) error {
+	if ev.Proposals[0].ProposalHash == ev.Proposals[1].ProposalHash {
+		return fmt.Errorf("proposals are not conflicting")
+	}
+	diff := ev.Proposals[0].Timestamp - ev.Proposals[1].Timestamp
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > EquivocationWindow.Nanoseconds() {
+		return fmt.Errorf("proposals don't belong to the same round")
+	}
+	if len(ev.PublicCommits) == 0 {
+		return fmt.Errorf("public commitments of the committee key are missing")
+	}
+	if address.FromBLSPubKey(ev.PublicCommits[0]) != address.Address(*chainID) {
+		return fmt.Errorf("public commitments don't belong to the committee of the chain")
+	}
+	suite := pairing.NewSuiteBn256()
+	commits := make([]kyber.Point, len(ev.PublicCommits))
+	for i, data := range ev.PublicCommits {
+		commits[i] = suite.Point()
+		if err := commits[i].UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("wrong public commitment #%d: %v", i, err)
+		}
+	}
+	pubShare := share.NewPubPoly(suite, nil, commits).Eval(int(ev.LeaderIndex)).V
+	for i := range ev.Proposals {
+		p := &ev.Proposals[i]
+		sig := tbdn.SigShare(p.LeaderSig)
+		idx, err := sig.Index()
+		if err != nil || idx != int(ev.LeaderIndex) {
+			return fmt.Errorf("proposal #%d is not signed by the leader #%d", i, ev.LeaderIndex)
+		}
+		essence := ProposalEssence(chainID, ev.BlockIndex, p.Timestamp, p.ProposalHash)
+		if err := bdn.Verify(suite, pubShare, essence, sig.Value()); err != nil {
+			return fmt.Errorf("invalid signature of proposal #%d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+func (ev *EquivocationEvidence) String() string {
+	return fmt.Sprintf("equivocation of leader #%d in block #%d: proposals %s and %s",
+		ev.LeaderIndex, ev.BlockIndex, ev.Proposals[0].ProposalHash.String(), ev.Proposals[1].ProposalHash.String())
+}
+
+func (ev *EquivocationEvidence) Write(w io.Writer) error {
+	if err := util.WriteUint32(w, ev.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.WriteUint16(w, ev.LeaderIndex); err != nil {
+		return err
+	}
+	for i := range ev.Proposals {
+		if err := ev.Proposals[i].Write(w); err != nil {
+			return err
+		}
+	}
+	if err := util.WriteUint16(w, uint16(len(ev.PublicCommits))); err != nil {
+		return err
+	}
+	for _, c := range ev.PublicCommits {
+		if err := util.WriteBytes16(w, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ev *EquivocationEvidence) Read(r io.Reader) error {
+	if err := util.ReadUint32(r, &ev.BlockIndex); err != nil {
+		return err
+	}
+	if err := util.ReadUint16(r, &ev.LeaderIndex); err != nil {
+		return err
+	}
+	for i := range ev.Proposals {
+		if err := ev.Proposals[i].Read(r); err != nil {
+			return err
+		}
+	}
+	var n uint16
+	if err := util.ReadUint16(r, &n); err != nil {
+		return err
+	}
+	ev.PublicCommits = make([][]byte, n)
+	for i := range ev.PublicCommits {
+		var err error
+		if ev.PublicCommits[i], err = util.ReadBytes16(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *SignedProposal) Write(w io.Writer) error {
+	if err := util.WriteInt64(w, p.Timestamp); err != nil {
+		return err
+	}
+	if _, err := w.Write(p.ProposalHash[:]); err != nil {
+		return err
+	}
+	return util.WriteBytes16(w, p.LeaderSig)
+}
+
+func (p *SignedProposal) Read(r io.Reader) error {
+	if err := util.ReadInt64(r, &p.Timestamp); err != nil {
+		return err
+	}
+	if err := util.ReadHashValue(r, &p.ProposalHash); err != nil {
+		return err
+	}
+	var err error
+	p.LeaderSig, err = util.ReadBytes16(r)
+	return err
+}
+
+func EncodeEquivocationEvidence(ev *EquivocationEvidence) []byte {
+	return util.MustBytes(ev)
+}
+
+func DecodeEquivocationEvidence(data []byte) (*EquivocationEvidence, error) {
+	ret := new(EquivocationEvidence)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+func dbkeyEquivocationEvidence(blockIndex uint32, leaderIndex uint16) []byte {
+	return append(util.Uint32To4Bytes(blockIndex), util.Uint16To2Bytes(leaderIndex)...)
+}
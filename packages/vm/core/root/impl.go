@@ -11,6 +11,7 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	assert2 "github.com/iotaledger/wasp/packages/coretypes/assert"
+	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
@@ -97,10 +98,12 @@ func initialize(ctx coretypes.Sandbox) (dict.Dict, error) {
 // - ParamProgramHash HashValue is a hash of the blob which represents program binary in the 'blob' contract.
 //     In case of hardcoded examples its an arbitrary unique hash set in the global call examples.AddProcessor
 // - ParamDescription string is an arbitrary string. Defaults to "N/A"
+// - ParamAlias Hname optional. The contract is deployed under the alias instead of hname(name), e.g. when
+//     hname(name) collides with another contract. Other contracts call it by the alias
 func deployContract(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Debugf("root.deployContract.begin")
 	if !isAuthorizedToDeploy(ctx) {
-		return nil, fmt.Errorf("root.deployContract: deploy not permitted for: %s", ctx.Caller())
+		return nil, coretypes.NewVMError(coretypes.VMErrorUnauthorized, "root.deployContract: deploy not permitted for: %s", ctx.Caller())
 	}
 	params := kvdecoder.New(ctx.Params(), ctx.Log())
 	a := assert2.NewAssert(ctx.Log())
@@ -108,17 +111,23 @@ func deployContract(ctx coretypes.Sandbox) (dict.Dict, error) {
 	progHash := params.MustGetHashValue(ParamProgramHash)
 	description := params.MustGetString(ParamDescription, "N/A")
 	name := params.MustGetString(ParamName)
+	alias := params.MustGetHname(ParamAlias, 0)
 	a.Require(name != "", "wrong name")
+	err := checkMaxContracts(ctx.State(), 1)
+	a.Require(err == nil, "root.deployContract.fail: %v", err)
+	hname, err := checkDeployHname(ctx.State(), name, alias)
+	a.RequireCode(err == nil, coretypes.VMErrorInvalidCall, "root.deployContract.fail: %v", err)
 
 	// pass to init function all params not consumed so far
 	initParams := dict.New()
 	for key, value := range ctx.Params() {
-		if key != ParamProgramHash && key != ParamName && key != ParamDescription {
+		if key != ParamProgramHash && key != ParamName && key != ParamDescription && key != ParamAlias {
 			initParams.Set(key, value)
 		}
 	}
 	// calls to loads VM from binary to check if it loads successfully
-	err := ctx.DeployContract(progHash, "", "", nil)
+	fixProgramFloatPolicy(ctx.State(), progHash)
+	err = ctx.DeployContract(progHash, "", "", nil)
 	a.Require(err == nil, "root.deployContract.fail: %v", err)
 
 	// VM loaded successfully. Storing contract in the registry and calling constructor
@@ -127,11 +136,85 @@ func deployContract(ctx coretypes.Sandbox) (dict.Dict, error) {
 		Description: description,
 		Name:        name,
 		Creator:     ctx.Caller(),
+		Alias:       alias,
 	}, initParams)
 	a.Require(err == nil, "root.deployContract.fail: %v", err)
 
 	ctx.Event(fmt.Sprintf("[deploy] name: %s hname: %s, progHash: %s, dscr: '%s'",
-		name, coretypes.Hn(name), progHash.String(), description))
+		name, hname, progHash.String(), description))
+	return nil, nil
+}
+
+// deployContracts deploys several contracts atomically: either all of them are deployed or none.
+// Contracts are deployed and their 'init' constructors are called in the order of the list, so constructors
+// of later contracts may call contracts deployed before them.
+// All contracts are checked before any of them is deployed. If a constructor fails, records of all contracts
+// of the list are removed from the registry and the call fails, so the request is reverted as a whole.
+// The EventTopicDeploy event is emitted for each deployed contract
+// Inputs:
+// - ParamContracts array of encoded ContractDeployment, see EncodeContractDeployments
+func deployContracts(ctx coretypes.Sandbox) (dict.Dict, error) {
+	ctx.Log().Debugf("root.deployContracts.begin")
+	if !isAuthorizedToDeploy(ctx) {
+		return nil, coretypes.NewVMError(coretypes.VMErrorUnauthorized, "root.deployContracts: deploy not permitted for: %s", ctx.Caller())
+	}
+	a := assert2.NewAssert(ctx.Log())
+
+	contracts, err := DecodeContractDeployments(ctx.Params())
+	a.Require(err == nil, "root.deployContracts: wrong parameters: %v", err)
+	a.Require(len(contracts) > 0, "root.deployContracts: no contracts to deploy")
+	a.Require(len(contracts) <= MaxDeployContracts, "root.deployContracts: more than %d contracts", MaxDeployContracts)
+	err = checkMaxContracts(ctx.State(), len(contracts))
+	a.Require(err == nil, "root.deployContracts: %v", err)
+
+	hnames := make(map[coretypes.Hname]bool)
+	names := make(map[string]bool)
+	for _, c := range contracts {
+		a.Require(c.Name != "", "root.deployContracts: wrong name")
+		a.Require(!names[c.Name], "root.deployContracts: duplicate contract '%s'", c.Name)
+		hname, err := checkDeployHname(ctx.State(), c.Name, c.Alias)
+		a.RequireCode(err == nil, coretypes.VMErrorInvalidCall, "root.deployContracts: %v", err)
+		a.RequireCode(!hnames[hname], coretypes.VMErrorInvalidCall,
+			"root.deployContracts: hname collision: contract '%s'/%s collides with another contract of the list, deploy it with an alias", c.Name, hname)
+		hnames[hname] = true
+		names[c.Name] = true
+		if c.Description == "" {
+			c.Description = "N/A"
+		}
+		// calls to loads VM from binary to check if it loads successfully
+		fixProgramFloatPolicy(ctx.State(), c.ProgramHash)
+		err = ctx.DeployContract(c.ProgramHash, "", "", nil)
+		a.Require(err == nil, "root.deployContracts: contract '%s': %v", c.Name, err)
+	}
+
+	records := make([]*ContractRecord, len(contracts))
+	for i, c := range contracts {
+		records[i] = &ContractRecord{
+			ProgramHash: c.ProgramHash,
+			Description: c.Description,
+			Name:        c.Name,
+			Creator:     ctx.Caller(),
+			Alias:       c.Alias,
+		}
+		err = storeAndInitContract(ctx, records[i], c.InitParams)
+		if err != nil {
+			// remove contracts deployed so far, the state of the request is reverted when the call fails
+			for _, prev := range records[:i] {
+				removeContract(ctx.State(), prev)
+			}
+			return nil, fmt.Errorf("root.deployContracts.fail: %v", err)
+		}
+	}
+
+	for i, c := range contracts {
+		fields := dict.New()
+		fields.Set(ParamName, codec.EncodeString(c.Name))
+		fields.Set(ParamHname, codec.EncodeHname(records[i].Hname()))
+		fields.Set(ParamProgramHash, codec.EncodeHashValue(c.ProgramHash))
+		fields.Set(ParamDescription, codec.EncodeString(c.Description))
+		ctx.EmitEvent(EventTopicDeploy, fields, ParamName)
+	}
+	ctx.Log().Debugf("root.deployContracts.success: %d contracts", len(contracts))
 	return nil, nil
 }
 
@@ -156,6 +239,28 @@ func findContract(ctx coretypes.SandboxView) (dict.Dict, error) {
 	return ret, nil
 }
 
+// findContractByName view finds the contract by its name, also if it is deployed under an alias
+// Input:
+// - ParamName
+// Output:
+// - ParamData encoded record of the contract
+// - ParamHname the hname the contract is deployed under
+func findContractByName(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	name, err := params.GetString(ParamName)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := FindContractByName(ctx.State(), name)
+	if err != nil {
+		return nil, err
+	}
+	ret := dict.New()
+	ret.Set(ParamData, EncodeContractRecord(rec))
+	ret.Set(ParamHname, codec.EncodeHname(rec.Hname()))
+	return ret, nil
+}
+
 // getChainInfo view returns general info about the chain: chain ID, chain owner ID,
 // description and the whole contract registry
 // Input: none
@@ -163,6 +268,7 @@ func findContract(ctx coretypes.SandboxView) (dict.Dict, error) {
 // - VarChainID - ChainID
 // - VarChainOwnerID - AgentID
 // - VarDescription - string
+// - VarWebsite - string
 // - VarContractRegistry: a map of contract registry
 func getChainInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
 	info := MustGetChainInfo(ctx.State())
@@ -172,9 +278,11 @@ func getChainInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
 	ret.Set(VarChainColor, codec.EncodeColor(info.ChainColor))
 	ret.Set(VarChainAddress, codec.EncodeAddress(info.ChainAddress))
 	ret.Set(VarDescription, codec.EncodeString(info.Description))
+	ret.Set(VarWebsite, codec.EncodeString(info.Website))
 	ret.Set(VarFeeColor, codec.EncodeColor(info.FeeColor))
 	ret.Set(VarDefaultOwnerFee, codec.EncodeInt64(info.DefaultOwnerFee))
 	ret.Set(VarDefaultValidatorFee, codec.EncodeInt64(info.DefaultValidatorFee))
+	ret.Set(VarMaxCallDepth, codec.EncodeInt64(info.MaxCallDepth))
 
 	src := collections.NewMapReadOnly(ctx.State(), VarContractRegistry)
 	dst := collections.NewMap(ret, VarContractRegistry)
@@ -191,7 +299,7 @@ func getChainInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
 func delegateChainOwnership(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Log().Debugf("root.delegateChainOwnership.begin")
 	a := assert2.NewAssert(ctx.Log())
-	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.delegateChainOwnership: not authorized")
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.delegateChainOwnership: not authorized")
 
 	params := kvdecoder.New(ctx.Params(), ctx.Log())
 	newOwnerID := params.MustGetAgentID(ParamChainOwner)
@@ -213,7 +321,7 @@ func claimChainOwnership(ctx coretypes.Sandbox) (dict.Dict, error) {
 	nextOwner := stateDecoder.MustGetAgentID(VarChainOwnerIDDelegated, currentOwner)
 
 	a.Require(nextOwner != currentOwner, "root.claimChainOwnership: not delegated to another chain owner")
-	a.Require(nextOwner == ctx.Caller(), "root.claimChainOwnership: not authorized")
+	a.RequireAuthorized(nextOwner == ctx.Caller(), "root.claimChainOwnership: not authorized")
 
 	state.Set(VarChainOwnerID, codec.EncodeAgentID(nextOwner))
 	state.Del(VarChainOwnerIDDelegated)
@@ -249,7 +357,7 @@ func getFeeInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
 // - ParamValidatorFee int64 non-negative value of the contract fee. May be skipped, then it is not set
 func setDefaultFee(ctx coretypes.Sandbox) (dict.Dict, error) {
 	a := assert2.NewAssert(ctx.Log())
-	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setDefaultFee: not authorized")
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setDefaultFee: not authorized")
 
 	params := kvdecoder.New(ctx.Params(), ctx.Log())
 
@@ -284,7 +392,7 @@ func setDefaultFee(ctx coretypes.Sandbox) (dict.Dict, error) {
 // - ParamValidatorFee int64 non-negative value of the contract fee. May be skipped, then it is not set
 func setContractFee(ctx coretypes.Sandbox) (dict.Dict, error) {
 	a := assert2.NewAssert(ctx.Log())
-	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setContractFee: not authorized")
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setContractFee: not authorized")
 
 	params := kvdecoder.New(ctx.Params(), ctx.Log())
 
@@ -315,7 +423,7 @@ func setContractFee(ctx coretypes.Sandbox) (dict.Dict, error) {
 //  - ParamDeployer coretypes.AgentID
 func grantDeployPermission(ctx coretypes.Sandbox) (dict.Dict, error) {
 	a := assert2.NewAssert(ctx.Log())
-	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.grantDeployPermissions: not authorized")
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.grantDeployPermissions: not authorized")
 
 	params := kvdecoder.New(ctx.Params(), ctx.Log())
 	deployer := params.MustGetAgentID(ParamDeployer)
@@ -330,7 +438,7 @@ func grantDeployPermission(ctx coretypes.Sandbox) (dict.Dict, error) {
 //  - ParamDeployer coretypes.AgentID
 func revokeDeployPermission(ctx coretypes.Sandbox) (dict.Dict, error) {
 	a := assert2.NewAssert(ctx.Log())
-	a.Require(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.revokeDeployPermissions: not authorized")
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.revokeDeployPermissions: not authorized")
 
 	params := kvdecoder.New(ctx.Params(), ctx.Log())
 	deployer := params.MustGetAgentID(ParamDeployer)
@@ -339,3 +447,673 @@ func revokeDeployPermission(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Event(fmt.Sprintf("[revoke deploy permission] from agentID: %s", deployer))
 	return nil, nil
 }
+
+// setDeployPolicy sets who may deploy contracts on the chain
+// Input:
+//  - ParamDeployPolicy int64 one of DeployPolicyAllowList, DeployPolicyOpen, DeployPolicyOwnerOnly.
+//    Defaults to DeployPolicyAllowList
+func setDeployPolicy(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setDeployPolicy: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	policy := params.MustGetInt64(ParamDeployPolicy, DeployPolicyAllowList)
+	switch policy {
+	case DeployPolicyAllowList:
+		ctx.State().Del(VarDeployPolicy)
+	case DeployPolicyOpen, DeployPolicyOwnerOnly:
+		ctx.State().Set(VarDeployPolicy, codec.EncodeInt64(policy))
+	default:
+		a.Require(false, "root.setDeployPolicy: wrong deploy policy %d", policy)
+	}
+	ctx.Event(fmt.Sprintf("[set deploy policy] %d", policy))
+	return nil, nil
+}
+
+// getDeployPolicy returns the deploy policy of the chain
+// Input:
+//  - ParamDeployer coretypes.AgentID optional. If present, the permission of the agent is checked
+// Output:
+//  - ParamDeployPolicy int64
+//  - ParamPermitted int64 1 if the agent may deploy contracts, otherwise 0. Only if ParamDeployer is present
+func getDeployPolicy(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	ret := dict.New()
+	ret.Set(ParamDeployPolicy, codec.EncodeInt64(GetDeployPolicy(ctx.State())))
+	if ctx.Params().MustHas(ParamDeployer) {
+		deployer := params.MustGetAgentID(ParamDeployer)
+		permitted := int64(0)
+		if isDeployPermitted(ctx.State(), deployer, ctx.ChainOwnerID(), ctx.ContractID().ChainID()) {
+			permitted = 1
+		}
+		ret.Set(ParamPermitted, codec.EncodeInt64(permitted))
+	}
+	return ret, nil
+}
+
+// setMaxCallDepth sets maximum depth of synchronous calls between contracts.
+// Calls deeper than that fail with an error
+// Input:
+//  - ParamMaxCallDepth int64 positive value. If skipped, the default value is restored
+func setMaxCallDepth(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setMaxCallDepth: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	maxCallDepth := params.MustGetInt64(ParamMaxCallDepth, DefaultMaxCallDepth)
+	a.Require(maxCallDepth > 0, "root.setMaxCallDepth: wrong parameter")
+
+	if maxCallDepth == DefaultMaxCallDepth {
+		ctx.State().Del(VarMaxCallDepth)
+	} else {
+		ctx.State().Set(VarMaxCallDepth, codec.EncodeInt64(maxCallDepth))
+	}
+	ctx.Event(fmt.Sprintf("[set max call depth] %d", maxCallDepth))
+	return nil, nil
+}
+
+// setReentrancyLock enables or disables reentrancy lock of the contract.
+// A locked contract can't be called while it is already on the call stack
+// Input:
+//  - ParamHname coretypes.Hname smart contract ID
+//  - ParamLocked int64 0 - unlock, otherwise lock. Default is 1
+func setReentrancyLock(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setReentrancyLock: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	hname := params.MustGetHname(ParamHname)
+	_, err := FindContract(ctx.State(), hname)
+	if err != nil {
+		return nil, err
+	}
+	locks := collections.NewMap(ctx.State(), VarReentrancyLocks)
+	if params.MustGetInt64(ParamLocked, 1) != 0 {
+		locks.MustSetAt(hname.Bytes(), []byte{0xFF})
+		ctx.Event(fmt.Sprintf("[reentrancy lock] %s", hname))
+	} else {
+		locks.MustDelAt(hname.Bytes())
+		ctx.Event(fmt.Sprintf("[reentrancy unlock] %s", hname))
+	}
+	return nil, nil
+}
+
+// setEventLogRetention sets the retention policy of the event log, either the chain default
+// or for the particular smart contract. Records exceeding the limits are pruned when new records are appended
+// Input:
+//  - ParamHname coretypes.Hname smart contract ID. May be skipped, then the chain default is set
+//  - ParamMaxRecords int64 maximum number of records kept. Defaults to 0, no limit
+//  - ParamMaxAge int64 maximum age of records kept in seconds, not more than MaxEventLogRetentionAge.
+//    Defaults to 0, no limit
+func setEventLogRetention(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setEventLogRetention: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	rec := &EventLogRetention{
+		MaxRecords: params.MustGetInt64(ParamMaxRecords, 0),
+		MaxAge:     params.MustGetInt64(ParamMaxAge, 0),
+	}
+	a.Require(rec.MaxRecords >= 0 && rec.MaxAge >= 0 && rec.MaxAge <= MaxEventLogRetentionAge,
+		"root.setEventLogRetention: wrong parameters")
+
+	if ctx.Params().MustHas(ParamHname) {
+		hname := params.MustGetHname(ParamHname)
+		_, err := FindContract(ctx.State(), hname)
+		if err != nil {
+			return nil, err
+		}
+		retention := collections.NewMap(ctx.State(), VarLogRetention)
+		if rec.IsEmpty() {
+			retention.MustDelAt(hname.Bytes())
+		} else {
+			retention.MustSetAt(hname.Bytes(), EncodeEventLogRetention(rec))
+		}
+		return nil, nil
+	}
+	if rec.IsEmpty() {
+		ctx.State().Del(VarDefaultLogRetention)
+	} else {
+		ctx.State().Set(VarDefaultLogRetention, EncodeEventLogRetention(rec))
+	}
+	return nil, nil
+}
+
+// getEventLogRetention returns the event log retention policy in effect for the contract
+// Input:
+//  - ParamHname coretypes.Hname smart contract ID
+// Output:
+//  - ParamMaxRecords int64
+//  - ParamMaxAge int64
+func getEventLogRetention(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params())
+	hname, err := params.GetHname(ParamHname)
+	if err != nil {
+		return nil, err
+	}
+	rec := GetEventLogRetention(ctx.State(), hname)
+	ret := dict.New()
+	ret.Set(ParamMaxRecords, codec.EncodeInt64(rec.MaxRecords))
+	ret.Set(ParamMaxAge, codec.EncodeInt64(rec.MaxAge))
+	return ret, nil
+}
+
+// setChainMetadata changes the description, the website and the custom metadata of the chain.
+// Each of them may be changed separately. The change is emitted as the EventTopicChainMetadata event
+// Input:
+//  - ParamDescription string. May be skipped, then it is not changed
+//  - ParamWebsite string. May be skipped, then it is not changed. Empty value removes the website
+//  - ParamMetadataKey string key of the custom metadata. May be skipped
+//  - ParamMetadataVal string value of the custom metadata. If skipped or empty, the key is removed
+func setChainMetadata(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setChainMetadata: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	fields := dict.New()
+	if ctx.Params().MustHas(ParamDescription) {
+		description := params.MustGetString(ParamDescription)
+		a.Require(len(description) <= MaxChainMetadataLength, "root.setChainMetadata: description too long")
+		ctx.State().Set(VarDescription, codec.EncodeString(description))
+		fields.Set(ParamDescription, codec.EncodeString(description))
+	}
+	if ctx.Params().MustHas(ParamWebsite) {
+		website := params.MustGetString(ParamWebsite)
+		a.Require(len(website) <= MaxChainMetadataLength, "root.setChainMetadata: website too long")
+		if website == "" {
+			ctx.State().Del(VarWebsite)
+		} else {
+			ctx.State().Set(VarWebsite, codec.EncodeString(website))
+		}
+		fields.Set(ParamWebsite, codec.EncodeString(website))
+	}
+	var indexed []kv.Key
+	if ctx.Params().MustHas(ParamMetadataKey) {
+		key := params.MustGetString(ParamMetadataKey)
+		value := params.MustGetString(ParamMetadataVal, "")
+		a.Require(key != "" && len(key) <= MaxChainMetadataLength, "root.setChainMetadata: wrong metadata key")
+		a.Require(len(value) <= MaxChainMetadataLength, "root.setChainMetadata: metadata value too long")
+		values := collections.NewMap(ctx.State(), VarChainMetadata)
+		if value == "" {
+			values.MustDelAt([]byte(key))
+		} else {
+			values.MustSetAt([]byte(key), []byte(value))
+		}
+		fields.Set(ParamMetadataKey, codec.EncodeString(key))
+		fields.Set(ParamMetadataVal, codec.EncodeString(value))
+		indexed = append(indexed, ParamMetadataKey)
+	}
+	a.Require(len(fields) > 0, "root.setChainMetadata: wrong parameters")
+
+	ctx.EmitEvent(EventTopicChainMetadata, fields, indexed...)
+	return nil, nil
+}
+
+// getChainMetadata returns the description, the website and the custom metadata of the chain
+// Input: none
+// Output:
+//  - VarDescription string
+//  - VarWebsite string
+//  - VarChainMetadata a map of the custom metadata
+func getChainMetadata(ctx coretypes.SandboxView) (dict.Dict, error) {
+	d := kvdecoder.New(ctx.State(), ctx.Log())
+	ret := dict.New()
+	ret.Set(VarDescription, codec.EncodeString(d.MustGetString(VarDescription, "")))
+	ret.Set(VarWebsite, codec.EncodeString(d.MustGetString(VarWebsite, "")))
+
+	src := collections.NewMapReadOnly(ctx.State(), VarChainMetadata)
+	dst := collections.NewMap(ret, VarChainMetadata)
+	src.MustIterate(func(elemKey []byte, value []byte) bool {
+		dst.MustSetAt(elemKey, value)
+		return true
+	})
+	return ret, nil
+}
+
+// setRequestIntakePolicy sets the policy applied by nodes of the committee to incoming requests,
+// see RequestIntakePolicy. Each of the limits may be changed separately
+// Input:
+//  - ParamRateLimit int64 maximum number of requests from one sender per minute, 0 - no limit. May be skipped
+//  - ParamMinFee int64 minimum amount of fee color tokens transferred by the request, 0 - no limit. May be skipped
+//  - ParamMinDeposit int64 iotas deposited by each request on top of the fees, 0 - no deposit. May be skipped
+//  - ParamDepositPolicy int64 one of DepositRefund, DepositForfeit. May be skipped
+func setRequestIntakePolicy(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setRequestIntakePolicy: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	a.Require(ctx.Params().MustHas(ParamRateLimit) || ctx.Params().MustHas(ParamMinFee) ||
+		ctx.Params().MustHas(ParamMinDeposit) || ctx.Params().MustHas(ParamDepositPolicy),
+		"root.setRequestIntakePolicy: wrong parameters")
+	if ctx.Params().MustHas(ParamRateLimit) {
+		rateLimit := params.MustGetInt64(ParamRateLimit)
+		a.Require(rateLimit >= 0, "root.setRequestIntakePolicy: wrong rate limit")
+		setOrDelInt64(ctx.State(), VarRequestRateLimit, rateLimit)
+	}
+	if ctx.Params().MustHas(ParamMinFee) {
+		minFee := params.MustGetInt64(ParamMinFee)
+		a.Require(minFee >= 0, "root.setRequestIntakePolicy: wrong minimum fee")
+		setOrDelInt64(ctx.State(), VarMinRequestFee, minFee)
+	}
+	if ctx.Params().MustHas(ParamMinDeposit) {
+		minDeposit := params.MustGetInt64(ParamMinDeposit)
+		a.Require(minDeposit >= 0, "root.setRequestIntakePolicy: wrong minimum deposit")
+		setOrDelInt64(ctx.State(), VarMinRequestDeposit, minDeposit)
+	}
+	if ctx.Params().MustHas(ParamDepositPolicy) {
+		depositPolicy := params.MustGetInt64(ParamDepositPolicy)
+		a.Require(depositPolicy == DepositRefund || depositPolicy == DepositForfeit,
+			"root.setRequestIntakePolicy: wrong deposit policy")
+		setOrDelInt64(ctx.State(), VarRequestDepositPolicy, depositPolicy)
+	}
+	policy := GetRequestIntakePolicy(ctx.State())
+	ctx.Event(fmt.Sprintf("[set request intake policy] rate limit: %d, min fee: %d, min deposit: %d, deposit policy: %d",
+		policy.RateLimit, policy.MinFee, policy.MinDeposit, policy.DepositPolicy))
+	return nil, nil
+}
+
+// getRequestIntakePolicy returns the policy applied to incoming requests
+// Output:
+//  - ParamRateLimit int64
+//  - ParamMinFee int64
+//  - ParamMinDeposit int64
+//  - ParamDepositPolicy int64
+func getRequestIntakePolicy(ctx coretypes.SandboxView) (dict.Dict, error) {
+	policy := GetRequestIntakePolicy(ctx.State())
+	ret := dict.New()
+	ret.Set(ParamRateLimit, codec.EncodeInt64(policy.RateLimit))
+	ret.Set(ParamMinFee, codec.EncodeInt64(policy.MinFee))
+	ret.Set(ParamMinDeposit, codec.EncodeInt64(policy.MinDeposit))
+	ret.Set(ParamDepositPolicy, codec.EncodeInt64(policy.DepositPolicy))
+	return ret, nil
+}
+
+// getDepositInfo returns the deposit each request must carry, in addition to the fees returned by getFeeInfo
+// Output:
+//  - ParamMinDeposit int64 iotas deposited by each request, 0 means no deposit is required
+//  - ParamDepositPolicy int64 one of DepositRefund, DepositForfeit
+func getDepositInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
+	policy := GetRequestIntakePolicy(ctx.State())
+	ret := dict.New()
+	ret.Set(ParamMinDeposit, codec.EncodeInt64(policy.MinDeposit))
+	ret.Set(ParamDepositPolicy, codec.EncodeInt64(policy.DepositPolicy))
+	return ret, nil
+}
+
+// setWasmFloatPolicy sets the float policy of wasm contracts deployed on the chain.
+// The policy applies to programs deployed for the first time, see GetProgramFloatPolicy
+// Input:
+//  - ParamFloatPolicy int64 one of WasmFloatsAllow, WasmFloatsCanonicalize, WasmFloatsReject.
+//    Defaults to WasmFloatsAllow
+func setWasmFloatPolicy(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setWasmFloatPolicy: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	policy := params.MustGetInt64(ParamFloatPolicy, WasmFloatsAllow)
+	switch policy {
+	case WasmFloatsAllow, WasmFloatsCanonicalize, WasmFloatsReject:
+		setOrDelInt64(ctx.State(), VarWasmFloatPolicy, policy)
+	default:
+		a.Require(false, "root.setWasmFloatPolicy: wrong float policy %d", policy)
+	}
+	ctx.Event(fmt.Sprintf("[set wasm float policy] %d", policy))
+	return nil, nil
+}
+
+// getWasmFloatPolicy returns the float policy of wasm contracts deployed on the chain
+// Input:
+//  - ParamProgramHash hashing.HashValue optional. If present, returns the policy applied to the program
+// Output:
+//  - ParamFloatPolicy int64
+func getWasmFloatPolicy(ctx coretypes.SandboxView) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	policy := GetWasmFloatPolicy(ctx.State())
+	if ctx.Params().MustHas(ParamProgramHash) {
+		policy = GetProgramFloatPolicy(ctx.State(), params.MustGetHashValue(ParamProgramHash))
+	}
+	ret := dict.New()
+	ret.Set(ParamFloatPolicy, codec.EncodeInt64(policy))
+	return ret, nil
+}
+
+// setResourceLimits sets the resource limits of the chain, see ResourceLimits. Each of the limits may be
+// changed separately. Lowering a limit below the current usage doesn't remove anything, it only makes
+// requests which increase the usage fail
+// Input:
+//  - ParamMaxContracts int64 maximum number of contracts besides the core contracts, 0 - no limit. May be skipped
+//  - ParamMaxStateSize int64 maximum size of the state of one contract in bytes, 0 - no limit. May be skipped
+//  - ParamMaxBlobSize int64 maximum size of the blob in bytes, 0 - no limit. May be skipped
+func setResourceLimits(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setResourceLimits: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	vars := map[kv.Key]kv.Key{
+		ParamMaxContracts: VarMaxContracts,
+		ParamMaxStateSize: VarMaxStateSize,
+		ParamMaxBlobSize:  VarMaxBlobSize,
+	}
+	changed := false
+	for _, param := range []kv.Key{ParamMaxContracts, ParamMaxStateSize, ParamMaxBlobSize} {
+		if !ctx.Params().MustHas(param) {
+			continue
+		}
+		limit := params.MustGetInt64(param)
+		a.Require(limit >= 0, "root.setResourceLimits: wrong value of %s", param)
+		setOrDelInt64(ctx.State(), vars[param], limit)
+		changed = true
+	}
+	a.Require(changed, "root.setResourceLimits: wrong parameters")
+
+	limits := GetResourceLimits(ctx.State())
+	ctx.Event(fmt.Sprintf("[set resource limits] max contracts: %d, max state size: %d, max blob size: %d",
+		limits.MaxContracts, limits.MaxStateSize, limits.MaxBlobSize))
+	return nil, nil
+}
+
+// getResourceLimits returns the resource limits of the chain
+// Output:
+//  - ParamMaxContracts int64
+//  - ParamMaxStateSize int64
+//  - ParamMaxBlobSize int64
+func getResourceLimits(ctx coretypes.SandboxView) (dict.Dict, error) {
+	limits := GetResourceLimits(ctx.State())
+	ret := dict.New()
+	ret.Set(ParamMaxContracts, codec.EncodeInt64(limits.MaxContracts))
+	ret.Set(ParamMaxStateSize, codec.EncodeInt64(limits.MaxStateSize))
+	ret.Set(ParamMaxBlobSize, codec.EncodeInt64(limits.MaxBlobSize))
+	return ret, nil
+}
+
+// getResourceUsage returns the current usage of the resources limited by ResourceLimits
+// Input:
+//  - ParamHname coretypes.Hname optional. If present, the state size of the contract is returned
+// Output:
+//  - ParamNumContracts int64 number of contracts besides the core contracts
+//  - ParamStateSize int64 size of the state of the contract recorded by the VM. Only if ParamHname is present
+func getResourceUsage(ctx coretypes.SandboxView) (dict.Dict, error) {
+	ret := dict.New()
+	ret.Set(ParamNumContracts, codec.EncodeInt64(GetNumContracts(ctx.State())))
+	if ctx.Params().MustHas(ParamHname) {
+		par := kvdecoder.New(ctx.Params(), ctx.Log())
+		hname := par.MustGetHname(ParamHname)
+		if _, err := FindContract(ctx.State(), hname); err != nil {
+			return nil, err
+		}
+		size, _ := GetStateUsage(ctx.State(), hname)
+		ret.Set(ParamStateSize, codec.EncodeInt64(size))
+	}
+	return ret, nil
+}
+
+// setUtilityPrice sets the price of host utility functions called by wasm smart contracts,
+// such as hashing and signature verification
+// Input:
+//  - ParamUtilityPrice int64 number of utility cost units per 1 iota. Defaults to 0, utility functions are free
+func setUtilityPrice(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setUtilityPrice: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	price := params.MustGetInt64(ParamUtilityPrice, 0)
+	a.Require(price >= 0, "root.setUtilityPrice: wrong price %d", price)
+	setOrDelInt64(ctx.State(), VarUtilityPrice, price)
+	ctx.Event(fmt.Sprintf("[set utility price] %d", price))
+	return nil, nil
+}
+
+// getUtilityPrice returns the price of host utility functions
+// Output:
+//  - ParamUtilityPrice int64 number of utility cost units per 1 iota, 0 if utility functions are free
+func getUtilityPrice(ctx coretypes.SandboxView) (dict.Dict, error) {
+	ret := dict.New()
+	ret.Set(ParamUtilityPrice, codec.EncodeInt64(GetUtilityPrice(ctx.State())))
+	return ret, nil
+}
+
+// setBlockInterval sets the timing of blocks of the chain, see GetBlockInterval
+// Input:
+//  - ParamMinInterval int64 minimum time between blocks in milliseconds. Defaults to 0, no minimum
+//  - ParamMaxInterval int64 maximum time between blocks in milliseconds, not less than MinEmptyBlockInterval
+//    and than the minimum. Defaults to 0, no empty blocks are produced
+func setBlockInterval(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setBlockInterval: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	min := params.MustGetInt64(ParamMinInterval, 0)
+	max := params.MustGetInt64(ParamMaxInterval, 0)
+	a.Require(min >= 0, "root.setBlockInterval: wrong minimum interval %d", min)
+	a.Require(max == 0 || (max >= MinEmptyBlockInterval.Milliseconds() && max >= min),
+		"root.setBlockInterval: wrong maximum interval %d", max)
+	setOrDelInt64(ctx.State(), VarMinBlockInterval, min)
+	setOrDelInt64(ctx.State(), VarMaxBlockInterval, max)
+	ctx.Event(fmt.Sprintf("[set block interval] min: %d ms, max: %d ms", min, max))
+	return nil, nil
+}
+
+// getBlockInterval returns the timing of blocks of the chain
+// Output:
+//  - ParamMinInterval int64 minimum time between blocks in milliseconds
+//  - ParamMaxInterval int64 maximum time between blocks in milliseconds, 0 if empty blocks are not produced
+func getBlockInterval(ctx coretypes.SandboxView) (dict.Dict, error) {
+	min, max := GetBlockInterval(ctx.State())
+	ret := dict.New()
+	ret.Set(ParamMinInterval, codec.EncodeInt64(min.Milliseconds()))
+	ret.Set(ParamMaxInterval, codec.EncodeInt64(max.Milliseconds()))
+	return ret, nil
+}
+
+// setOrDelInt64 stores the value, 0 is stored as the absence of the value
+func setOrDelInt64(state kv.KVStore, key kv.Key, value int64) {
+	if value == 0 {
+		state.Del(key)
+	} else {
+		state.Set(key, codec.EncodeInt64(value))
+	}
+}
+
+// recordEquivocation records the evidence of the equivocating leader of the committee in the state.
+// The evidence is verified against the chain address, so it may be submitted by anybody.
+// Only the first evidence for the same block and leader is kept
+// Input:
+//  - ParamEvidence []byte serialized EquivocationEvidence
+func recordEquivocation(ctx coretypes.Sandbox) (dict.Dict, error) {
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	ev, err := DecodeEquivocationEvidence(params.MustGetBytes(ParamEvidence))
+	if err != nil {
+		return nil, fmt.Errorf("root.recordEquivocation: wrong evidence: %v", err)
+	}
+	chainID := ctx.ContractID().ChainID()
+	if err := VerifyEquivocationEvidence(&chainID, ev); err != nil {
+		return nil, fmt.Errorf("root.recordEquivocation: %v", err)
+	}
+	evidence := collections.NewMap(ctx.State(), VarEquivocationEvidence)
+	key := dbkeyEquivocationEvidence(ev.BlockIndex, ev.LeaderIndex)
+	if evidence.MustHasAt(key) {
+		return nil, nil
+	}
+	evidence.MustSetAt(key, EncodeEquivocationEvidence(ev))
+	ctx.Event(fmt.Sprintf("[equivocation] %s", ev.String()))
+	return nil, nil
+}
+
+// getEquivocations returns the evidence of equivocating leaders recorded in the state
+// Output:
+//  - ParamEvidence array of serialized EquivocationEvidence
+func getEquivocations(ctx coretypes.SandboxView) (dict.Dict, error) {
+	ret := dict.New()
+	arr := collections.NewArray(ret, ParamEvidence)
+	collections.NewMapReadOnly(ctx.State(), VarEquivocationEvidence).MustIterate(func(_ []byte, value []byte) bool {
+		arr.MustPush(value)
+		return true
+	})
+	return ret, nil
+}
+
+// setAnchorFeePolicy sets who funds the L1 costs of the anchor transaction of each block, see AnchorFeePolicy
+// Input:
+//  - ParamAnchorFee int64 number of iotas paid for each anchor transaction. Defaults to 0, no fee
+//  - ParamFeePayer int64 one of AnchorFeePayerChain, AnchorFeePayerValidator, AnchorFeePayerSponsor.
+//    Defaults to AnchorFeePayerChain
+//  - ParamSponsor address.Address address of the sponsor, mandatory for AnchorFeePayerSponsor
+//  - ParamFeeTarget address.Address address the fee is paid to, mandatory if the fee is not 0
+func setAnchorFeePolicy(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setAnchorFeePolicy: not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	fee := params.MustGetInt64(ParamAnchorFee, 0)
+	payer := params.MustGetInt64(ParamFeePayer, AnchorFeePayerChain)
+	a.Require(fee >= 0, "root.setAnchorFeePolicy: wrong fee %d", fee)
+	a.Require(payer >= AnchorFeePayerChain && payer <= AnchorFeePayerSponsor,
+		"root.setAnchorFeePolicy: wrong payer %d", payer)
+
+	state := ctx.State()
+	setOrDelInt64(state, VarAnchorFee, fee)
+	setOrDelInt64(state, VarAnchorFeePayer, payer)
+	if payer == AnchorFeePayerSponsor {
+		a.Require(ctx.Params().MustHas(ParamSponsor), "root.setAnchorFeePolicy: sponsor address is missing")
+		state.Set(VarAnchorFeeSponsor, codec.EncodeAddress(params.MustGetAddress(ParamSponsor)))
+	} else {
+		state.Del(VarAnchorFeeSponsor)
+	}
+	if fee > 0 {
+		a.Require(ctx.Params().MustHas(ParamFeeTarget), "root.setAnchorFeePolicy: fee target address is missing")
+		state.Set(VarAnchorFeeTarget, codec.EncodeAddress(params.MustGetAddress(ParamFeeTarget)))
+	} else {
+		state.Del(VarAnchorFeeTarget)
+	}
+	ctx.Event(fmt.Sprintf("[set anchor fee policy] fee: %d, payer: %d", fee, payer))
+	return nil, nil
+}
+
+// getAnchorFeePolicy returns the policy of the anchor fee of the chain
+// Output:
+//  - ParamAnchorFee int64 number of iotas paid for each anchor transaction
+//  - ParamFeePayer int64 the payer of the fee
+//  - ParamSponsor address.Address address of the sponsor, only for AnchorFeePayerSponsor
+//  - ParamFeeTarget address.Address address the fee is paid to, only if the fee is not 0
+func getAnchorFeePolicy(ctx coretypes.SandboxView) (dict.Dict, error) {
+	policy := GetAnchorFeePolicy(ctx.State())
+	ret := dict.New()
+	ret.Set(ParamAnchorFee, codec.EncodeInt64(policy.Fee))
+	ret.Set(ParamFeePayer, codec.EncodeInt64(policy.Payer))
+	if policy.Payer == AnchorFeePayerSponsor {
+		ret.Set(ParamSponsor, codec.EncodeAddress(policy.Sponsor))
+	}
+	if policy.Fee > 0 {
+		ret.Set(ParamFeeTarget, codec.EncodeAddress(policy.Target))
+	}
+	return ret, nil
+}
+
+// getExecutionUnits returns the execution units consumed by the requests of the block, see RecordExecutionUnits
+// Input:
+//  - ParamBlockIndex int64 optional. Defaults to the last block with requests
+// Output:
+//  - ParamBlockIndex int64 index of the block
+//  - ParamExecUnits int64 execution units consumed by the block, 0 if the block is empty
+//  - ParamTotalUnits int64 execution units consumed by all blocks of the chain
+func getExecutionUnits(ctx coretypes.SandboxView) (dict.Dict, error) {
+	last, ok := GetLastExecutionUnitsBlock(ctx.State())
+	if !ok {
+		return nil, fmt.Errorf("root.getExecutionUnits: no execution units recorded")
+	}
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	blockIndex := params.MustGetInt64(ParamBlockIndex, int64(last))
+	if blockIndex < 0 || blockIndex > int64(last) {
+		return nil, fmt.Errorf("root.getExecutionUnits: wrong block index %d", blockIndex)
+	}
+	units, ok := GetExecutionUnits(ctx.State(), uint32(blockIndex))
+	if !ok {
+		return nil, fmt.Errorf("root.getExecutionUnits: execution units of block #%d are not kept anymore", blockIndex)
+	}
+	ret := dict.New()
+	ret.Set(ParamBlockIndex, codec.EncodeInt64(blockIndex))
+	ret.Set(ParamExecUnits, codec.EncodeInt64(units))
+	ret.Set(ParamTotalUnits, codec.EncodeInt64(GetTotalExecutionUnits(ctx.State())))
+	return ret, nil
+}
+
+// pauseContract pauses the contract: while paused, calls of its full entry points, both from requests and
+// from other contracts, fail with VMErrorContractPaused. Views of the contract still work.
+// Core contracts can't be paused. May be called by the chain owner or by the pauser, see setPauser
+// Input:
+//  - ParamHname coretypes.Hname smart contract ID
+func pauseContract(ctx coretypes.Sandbox) (dict.Dict, error) {
+	return setContractPaused(ctx, true, "root.pauseContract")
+}
+
+// unpauseContract resumes the contract paused by pauseContract.
+// May be called by the chain owner or by the pauser, see setPauser
+// Input:
+//  - ParamHname coretypes.Hname smart contract ID
+func unpauseContract(ctx coretypes.Sandbox) (dict.Dict, error) {
+	return setContractPaused(ctx, false, "root.unpauseContract")
+}
+
+func setContractPaused(ctx coretypes.Sandbox, paused bool, fname string) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(checkAuthorizationToPause(ctx.State(), ctx.Caller()), fname+": not authorized")
+
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	hname := params.MustGetHname(ParamHname)
+	a.Require(!IsCoreContract(hname), fname+": core contract can't be paused")
+	_, err := FindContract(ctx.State(), hname)
+	if err != nil {
+		return nil, err
+	}
+	pausedContracts := collections.NewMap(ctx.State(), VarPausedContracts)
+	if paused {
+		pausedContracts.MustSetAt(hname.Bytes(), []byte{0xFF})
+		ctx.Event(fmt.Sprintf("[pause contract] %s", hname))
+	} else {
+		pausedContracts.MustDelAt(hname.Bytes())
+		ctx.Event(fmt.Sprintf("[unpause contract] %s", hname))
+	}
+	return nil, nil
+}
+
+// setPauser designates the agent which may pause and unpause contracts besides the chain owner,
+// e.g. the key of the incident response team
+// Input:
+//  - ParamPauser coretypes.AgentID. If skipped, the pauser is removed
+func setPauser(ctx coretypes.Sandbox) (dict.Dict, error) {
+	a := assert2.NewAssert(ctx.Log())
+	a.RequireAuthorized(CheckAuthorizationByChainOwner(ctx.State(), ctx.Caller()), "root.setPauser: not authorized")
+
+	if !ctx.Params().MustHas(ParamPauser) {
+		ctx.State().Del(VarPauser)
+		ctx.Event("[remove pauser]")
+		return nil, nil
+	}
+	params := kvdecoder.New(ctx.Params(), ctx.Log())
+	pauser := params.MustGetAgentID(ParamPauser)
+	ctx.State().Set(VarPauser, codec.EncodeAgentID(pauser))
+	ctx.Event(fmt.Sprintf("[set pauser] %s", pauser))
+	return nil, nil
+}
+
+// getPauseInfo returns the pauser of the chain and the pause state of the contract
+// Input:
+//  - ParamHname coretypes.Hname optional. If present, the pause state of the contract is returned
+// Output:
+//  - ParamPauser coretypes.AgentID the pauser, only if designated
+//  - ParamPaused int64 1 if the contract is paused, otherwise 0. Only if ParamHname is present
+func getPauseInfo(ctx coretypes.SandboxView) (dict.Dict, error) {
+	ret := dict.New()
+	if pauser, ok := GetPauser(ctx.State()); ok {
+		ret.Set(ParamPauser, codec.EncodeAgentID(pauser))
+	}
+	if ctx.Params().MustHas(ParamHname) {
+		params := kvdecoder.New(ctx.Params(), ctx.Log())
+		paused := int64(0)
+		if IsContractPaused(ctx.State(), params.MustGetHname(ParamHname)) {
+			paused = 1
+		}
+		ret.Set(ParamPaused, codec.EncodeInt64(paused))
+	}
+	return ret, nil
+}
@@ -2,14 +2,17 @@ package root
 
 import (
 	"bytes"
-	"errors"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"io"
+	"time"
 
 	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/util"
 )
 
@@ -24,13 +27,17 @@ var (
 		Description: description,
 		ProgramHash: hashing.HashStrings(Name),
 	}
-	ErrContractNotFound = errors.New("smart contract not found")
+	ErrContractNotFound = coretypes.NewVMError(coretypes.VMErrorContractNotFound, "smart contract not found")
+	// ErrMaxContractsExceeded is the error of the deployment exceeding ResourceLimits.MaxContracts
+	ErrMaxContractsExceeded = coretypes.NewVMError(coretypes.VMErrorOutOfBudget, "maximum number of contracts exceeded")
 )
 
 func init() {
 	Interface.WithFunctions(initialize, []coreutil.ContractFunctionInterface{
 		coreutil.Func(FuncDeployContract, deployContract),
+		coreutil.Func(FuncDeployContracts, deployContracts),
 		coreutil.ViewFunc(FuncFindContract, findContract),
+		coreutil.ViewFunc(FuncFindContractByName, findContractByName),
 		coreutil.Func(FuncClaimChainOwnership, claimChainOwnership),
 		coreutil.Func(FuncDelegateChainOwnership, delegateChainOwnership),
 		coreutil.ViewFunc(FuncGetChainInfo, getChainInfo),
@@ -39,6 +46,35 @@ func init() {
 		coreutil.Func(FuncSetContractFee, setContractFee),
 		coreutil.Func(FuncGrantDeploy, grantDeployPermission),
 		coreutil.Func(FuncRevokeDeploy, revokeDeployPermission),
+		coreutil.Func(FuncSetDeployPolicy, setDeployPolicy),
+		coreutil.ViewFunc(FuncGetDeployPolicy, getDeployPolicy),
+		coreutil.Func(FuncSetMaxCallDepth, setMaxCallDepth),
+		coreutil.Func(FuncSetReentrancyLock, setReentrancyLock),
+		coreutil.Func(FuncSetEventLogRetention, setEventLogRetention),
+		coreutil.ViewFunc(FuncGetEventLogRetention, getEventLogRetention),
+		coreutil.Func(FuncSetChainMetadata, setChainMetadata),
+		coreutil.ViewFunc(FuncGetChainMetadata, getChainMetadata),
+		coreutil.Func(FuncSetRequestIntakePolicy, setRequestIntakePolicy),
+		coreutil.ViewFunc(FuncGetRequestIntakePolicy, getRequestIntakePolicy),
+		coreutil.ViewFunc(FuncGetDepositInfo, getDepositInfo),
+		coreutil.Func(FuncSetWasmFloatPolicy, setWasmFloatPolicy),
+		coreutil.ViewFunc(FuncGetWasmFloatPolicy, getWasmFloatPolicy),
+		coreutil.Func(FuncSetResourceLimits, setResourceLimits),
+		coreutil.ViewFunc(FuncGetResourceLimits, getResourceLimits),
+		coreutil.ViewFunc(FuncGetResourceUsage, getResourceUsage),
+		coreutil.Func(FuncRecordEquivocation, recordEquivocation),
+		coreutil.ViewFunc(FuncGetEquivocations, getEquivocations),
+		coreutil.Func(FuncSetUtilityPrice, setUtilityPrice),
+		coreutil.ViewFunc(FuncGetUtilityPrice, getUtilityPrice),
+		coreutil.Func(FuncSetBlockInterval, setBlockInterval),
+		coreutil.ViewFunc(FuncGetBlockInterval, getBlockInterval),
+		coreutil.Func(FuncSetAnchorFeePolicy, setAnchorFeePolicy),
+		coreutil.ViewFunc(FuncGetAnchorFeePolicy, getAnchorFeePolicy),
+		coreutil.ViewFunc(FuncGetExecutionUnits, getExecutionUnits),
+		coreutil.Func(FuncPauseContract, pauseContract),
+		coreutil.Func(FuncUnpauseContract, unpauseContract),
+		coreutil.Func(FuncSetPauser, setPauser),
+		coreutil.ViewFunc(FuncGetPauseInfo, getPauseInfo),
 	})
 }
 
@@ -54,31 +90,98 @@ const (
 	VarDefaultValidatorFee   = "dv"
 	VarChainOwnerIDDelegated = "n"
 	VarContractRegistry      = "r"
+	VarContractAliases       = "al"
 	VarDescription           = "d"
 	VarDeployPermissions     = "dep"
+	VarDeployPolicy          = "dp"
+	VarMaxCallDepth          = "mcd"
+	VarReentrancyLocks       = "rl"
+	VarDefaultLogRetention   = "lr"
+	VarLogRetention          = "clr"
+	VarWebsite               = "w"
+	VarChainMetadata         = "md"
+	VarRequestRateLimit      = "rrl"
+	VarMinRequestFee         = "mrf"
+	VarMinRequestDeposit     = "mrd"
+	VarRequestDepositPolicy  = "rdp"
+	VarWasmFloatPolicy       = "wfp"
+	VarProgramFloatPolicies  = "pfp"
+	VarMaxContracts          = "mxc"
+	VarMaxStateSize          = "mxs"
+	VarMaxBlobSize           = "mxb"
+	VarStateUsage            = "su"
+	VarEquivocationEvidence  = "eqv"
+	VarUtilityPrice          = "up"
+	VarMinBlockInterval      = "mbi"
+	VarMaxBlockInterval      = "xbi"
+	VarAnchorFee             = "anf"
+	VarAnchorFeePayer        = "anp"
+	VarAnchorFeeSponsor      = "ans"
+	VarAnchorFeeTarget       = "ant"
+	VarExecUnits             = "eu"
+	VarExecUnitsTotal        = "eut"
+	VarExecUnitsLastBlock    = "eub"
+	VarPausedContracts       = "pc"
+	VarPauser                = "pau"
 )
 
 // param variables
 const (
-	ParamChainID      = "$$chainid$$"
-	ParamChainColor   = "$$color$$"
-	ParamChainAddress = "$$address$$"
-	ParamChainOwner   = "$$owner$$"
-	ParamProgramHash  = "$$proghash$$"
-	ParamDescription  = "$$description$$"
-	ParamHname        = "$$hname$$"
-	ParamName         = "$$name$$"
-	ParamData         = "$$data$$"
-	ParamFeeColor     = "$$feecolor$$"
-	ParamOwnerFee     = "$$ownerfee$$"
-	ParamValidatorFee = "$$validatorfee$$"
-	ParamDeployer     = "$$deployer$$"
+	ParamChainID       = "$$chainid$$"
+	ParamChainColor    = "$$color$$"
+	ParamChainAddress  = "$$address$$"
+	ParamChainOwner    = "$$owner$$"
+	ParamProgramHash   = "$$proghash$$"
+	ParamDescription   = "$$description$$"
+	ParamHname         = "$$hname$$"
+	ParamName          = "$$name$$"
+	ParamAlias         = "$$alias$$"
+	ParamData          = "$$data$$"
+	ParamFeeColor      = "$$feecolor$$"
+	ParamOwnerFee      = "$$ownerfee$$"
+	ParamValidatorFee  = "$$validatorfee$$"
+	ParamDeployer      = "$$deployer$$"
+	ParamDeployPolicy  = "$$deploypolicy$$"
+	ParamPermitted     = "$$permitted$$"
+	ParamMaxCallDepth  = "$$maxcalldepth$$"
+	ParamLocked        = "$$locked$$"
+	ParamMaxRecords    = "$$maxrecords$$"
+	ParamMaxAge        = "$$maxage$$"
+	ParamWebsite       = "$$website$$"
+	ParamMetadataKey   = "$$mdkey$$"
+	ParamMetadataVal   = "$$mdvalue$$"
+	ParamContracts     = "$$contracts$$"
+	ParamRateLimit     = "$$ratelimit$$"
+	ParamMinFee        = "$$minfee$$"
+	ParamMinDeposit    = "$$mindeposit$$"
+	ParamDepositPolicy = "$$depositpolicy$$"
+	ParamFloatPolicy   = "$$floatpolicy$$"
+	ParamMaxContracts  = "$$maxcontracts$$"
+	ParamMaxStateSize  = "$$maxstatesize$$"
+	ParamMaxBlobSize   = "$$maxblobsize$$"
+	ParamNumContracts  = "$$numcontracts$$"
+	ParamStateSize     = "$$statesize$$"
+	ParamEvidence      = "$$evidence$$"
+	ParamUtilityPrice  = "$$utilityprice$$"
+	ParamMinInterval   = "$$minblockinterval$$"
+	ParamMaxInterval   = "$$maxblockinterval$$"
+	ParamAnchorFee     = "$$anchorfee$$"
+	ParamFeePayer      = "$$feepayer$$"
+	ParamSponsor       = "$$sponsor$$"
+	ParamFeeTarget     = "$$feetarget$$"
+	ParamBlockIndex    = "$$blockindex$$"
+	ParamExecUnits     = "$$execunits$$"
+	ParamTotalUnits    = "$$totalunits$$"
+	ParamPaused        = "$$paused$$"
+	ParamPauser        = "$$pauser$$"
 )
 
 // function names
 const (
 	FuncDeployContract         = "deployContract"
+	FuncDeployContracts        = "deployContracts"
 	FuncFindContract           = "findContract"
+	FuncFindContractByName     = "findContractByName"
 	FuncGetChainInfo           = "getChainInfo"
 	FuncDelegateChainOwnership = "delegateChainOwnership"
 	FuncClaimChainOwnership    = "claimChainOwnership"
@@ -87,8 +190,105 @@ const (
 	FuncSetContractFee         = "setContractFee"
 	FuncGrantDeploy            = "grantDeployPermission"
 	FuncRevokeDeploy           = "revokeDeployPermission"
+	FuncSetDeployPolicy        = "setDeployPolicy"
+	FuncGetDeployPolicy        = "getDeployPolicy"
+	FuncSetMaxCallDepth        = "setMaxCallDepth"
+	FuncSetReentrancyLock      = "setReentrancyLock"
+	FuncSetEventLogRetention   = "setEventLogRetention"
+	FuncGetEventLogRetention   = "getEventLogRetention"
+	FuncSetChainMetadata       = "setChainMetadata"
+	FuncGetChainMetadata       = "getChainMetadata"
+	FuncSetRequestIntakePolicy = "setRequestIntakePolicy"
+	FuncGetRequestIntakePolicy = "getRequestIntakePolicy"
+	FuncGetDepositInfo         = "getDepositInfo"
+	FuncSetWasmFloatPolicy     = "setWasmFloatPolicy"
+	FuncGetWasmFloatPolicy     = "getWasmFloatPolicy"
+	FuncSetResourceLimits      = "setResourceLimits"
+	FuncGetResourceLimits      = "getResourceLimits"
+	FuncGetResourceUsage       = "getResourceUsage"
+	FuncRecordEquivocation     = "recordEquivocation"
+	FuncGetEquivocations       = "getEquivocations"
+	FuncSetUtilityPrice        = "setUtilityPrice"
+	FuncGetUtilityPrice        = "getUtilityPrice"
+	FuncSetBlockInterval       = "setBlockInterval"
+	FuncGetBlockInterval       = "getBlockInterval"
+	FuncSetAnchorFeePolicy     = "setAnchorFeePolicy"
+	FuncGetAnchorFeePolicy     = "getAnchorFeePolicy"
+	FuncGetExecutionUnits      = "getExecutionUnits"
+	FuncPauseContract          = "pauseContract"
+	FuncUnpauseContract        = "unpauseContract"
+	FuncSetPauser              = "setPauser"
+	FuncGetPauseInfo           = "getPauseInfo"
 )
 
+// EventTopicChainMetadata is the topic of the event emitted when the chain metadata is changed.
+// The fields of the event are the changed parameters of setChainMetadata, ParamMetadataKey is indexed
+const EventTopicChainMetadata = "chainMetadata"
+
+// EventTopicDeploy is the topic of the event emitted for each contract deployed by deployContracts.
+// The fields of the event are ParamName, ParamHname, ParamProgramHash and ParamDescription, ParamName is indexed
+const EventTopicDeploy = "deploy"
+
+// MaxDeployContracts is the maximum number of contracts deployed by one call of deployContracts
+const MaxDeployContracts = 64
+
+// MaxChainMetadataLength is the maximum length of the description, the website and of keys and values
+// of the custom metadata of the chain
+const MaxChainMetadataLength = 1024
+
+// deploy policies of the chain
+const (
+	// DeployPolicyAllowList lets deploy contracts to the chain owner, contracts of the chain
+	// and agents granted with grantDeployPermission. It is the default policy
+	DeployPolicyAllowList = int64(0)
+	// DeployPolicyOpen lets anyone deploy contracts
+	DeployPolicyOpen = int64(1)
+	// DeployPolicyOwnerOnly lets only the chain owner deploy contracts, granted permissions are ignored
+	DeployPolicyOwnerOnly = int64(2)
+)
+
+// policies of the request deposit, see RequestIntakePolicy.MinDeposit
+const (
+	// DepositRefund locks the deposit on the chain for DepositLockPeriod after the request is processed
+	// and then returns it to the on-chain account of the sender. It is the default policy
+	DepositRefund = int64(0)
+	// DepositForfeit accrues the deposit to the chain owner after the request is processed
+	DepositForfeit = int64(1)
+)
+
+// MinEmptyBlockInterval is the lower bound of the maximum block interval of the chain:
+// empty blocks are never produced more often
+const MinEmptyBlockInterval = 1 * time.Second
+
+// DepositLockPeriod is the time the refundable deposit is locked after the request is processed.
+// Without it the same iotas could be deposited again by the next request, so spam would cost nothing
+const DepositLockPeriod = 1 * time.Hour
+
+// float policies of wasm contracts of the chain. Float arithmetic in wasm is deterministic except for
+// the bit pattern of NaN results, which may differ between platforms and split the consensus.
+// Wasm binaries are checked by the VM before they are loaded, threads and SIMD are always rejected
+const (
+	// WasmFloatsAllow loads wasm binaries with floats as they are. It is the default policy
+	WasmFloatsAllow = int64(0)
+	// WasmFloatsCanonicalize transforms wasm binaries to replace each NaN result of float arithmetic
+	// with the canonical NaN
+	WasmFloatsCanonicalize = int64(1)
+	// WasmFloatsReject rejects wasm binaries with float types or instructions
+	WasmFloatsReject = int64(2)
+)
+
+// DefaultMaxCallDepth is the maximum depth of synchronous calls between contracts
+// when it is not set by the chain owner
+const DefaultMaxCallDepth = 100
+
+// ExecUnitsHistory is the number of the latest blocks the execution units are kept in the state for,
+// see RecordExecutionUnits
+const ExecUnitsHistory = 1000
+
+// MaxEventLogRetentionAge is the largest maximum age of event log records, in seconds, which may be set
+// by the chain owner. It is 100 years
+const MaxEventLogRetentionAge = int64(100 * 365 * 24 * 3600)
+
 // ContractRecord is a structure which contains metadata of the deployed contract instance
 type ContractRecord struct {
 	// The ProgramHash uniquely defines the program of the smart contract
@@ -110,6 +310,29 @@ type ContractRecord struct {
 	// The agentID of the entity which deployed the instance. It can be interpreted as
 	// an priviledged user of the instance, however it is up to the smart contract.
 	Creator coretypes.AgentID
+	// Alias is the hname the contract is deployed under instead of hname(name), because hname(name)
+	// collides with another contract. 0 if the contract is deployed under hname(name)
+	Alias coretypes.Hname
+}
+
+// ContractDeployment is the contract deployed by deployContracts
+type ContractDeployment struct {
+	ProgramHash hashing.HashValue
+	Name        string
+	Description string
+	// InitParams are passed to the 'init' entry point of the contract
+	InitParams dict.Dict
+	// Alias is the hname to deploy the contract under instead of hname(name), 0 for hname(name)
+	Alias coretypes.Hname
+}
+
+// EventLogRetention is the retention policy of the event log records of a contract.
+// Records exceeding any of the limits are pruned when new records are appended. 0 means no limit
+type EventLogRetention struct {
+	// maximum number of records kept in the log
+	MaxRecords int64
+	// maximum age of records kept in the log, in seconds
+	MaxAge int64
 }
 
 // ChainInfo is an API structure which contains main properties of the chain in on place
@@ -119,12 +342,54 @@ type ChainInfo struct {
 	ChainColor          balance.Color
 	ChainAddress        address.Address
 	Description         string
+	Website             string
 	FeeColor            balance.Color
 	DefaultOwnerFee     int64
 	DefaultValidatorFee int64
+	MaxCallDepth        int64
+}
+
+// RequestIntakePolicy protects the committee from the flood of requests. Nodes of the committee apply it
+// to incoming requests before taking them into the backlog, so requests which don't transfer the fee or
+// the deposit are never processed. Requests of the chain owner are not limited. 0 means no limit
+type RequestIntakePolicy struct {
+	// RateLimit is the maximum number of requests from one sender per minute. It is advisory:
+	// requests over the limit are deferred by nodes, not rejected
+	RateLimit int64
+	// MinFee is the minimum amount of tokens of the fee color the request must transfer to the chain
+	MinFee int64
+	// MinDeposit is the amount of iotas each request must transfer to the chain on top of the fees.
+	// The VM takes the deposit before the call and handles it according to DepositPolicy
+	MinDeposit int64
+	// DepositPolicy is one of DepositRefund, DepositForfeit
+	DepositPolicy int64
+}
+
+// ResourceLimits protects validators shared by several chains from a single runaway chain.
+// The limits are enforced by the VM, a request exceeding any of them fails and its state changes
+// are reverted. Core contracts are not limited. 0 means no limit
+type ResourceLimits struct {
+	// MaxContracts is the maximum number of contracts deployed on the chain besides the core contracts
+	MaxContracts int64
+	// MaxStateSize is the maximum size of the state of one contract: the total length of its keys and values
+	MaxStateSize int64
+	// MaxBlobSize is the maximum total size of the fields of a blob stored in the 'blob' contract
+	MaxBlobSize int64
 }
 
+// ChainMetadata is the description of the chain maintained by the chain owner after the deployment
+type ChainMetadata struct {
+	Description string
+	Website     string
+	// Values is the custom metadata of the chain
+	Values map[string]string
+}
+
+// Hname is the hname the contract is deployed under: the alias, if any, or hname(name)
 func (p *ContractRecord) Hname() coretypes.Hname {
+	if p.Alias != 0 {
+		return p.Alias
+	}
 	return coretypes.Hn(p.Name)
 }
 
@@ -148,7 +413,8 @@ func (p *ContractRecord) Write(w io.Writer) error {
 	if _, err := w.Write(p.Creator[:]); err != nil {
 		return err
 	}
-	return nil
+	// the alias is optional, so that records of contracts deployed without it keep their encoding
+	return writeOptionalHname(w, p.Alias)
 }
 
 func (p *ContractRecord) Read(r io.Reader) error {
@@ -171,7 +437,135 @@ func (p *ContractRecord) Read(r io.Reader) error {
 	if err := coretypes.ReadAgentID(r, &p.Creator); err != nil {
 		return err
 	}
-	return nil
+	return readOptionalHname(r, &p.Alias)
+}
+
+func (p *EventLogRetention) IsEmpty() bool {
+	return p.MaxRecords == 0 && p.MaxAge == 0
+}
+
+func (p *EventLogRetention) Write(w io.Writer) error {
+	if err := util.WriteInt64(w, p.MaxRecords); err != nil {
+		return err
+	}
+	return util.WriteInt64(w, p.MaxAge)
+}
+
+func (p *EventLogRetention) Read(r io.Reader) error {
+	if err := util.ReadInt64(r, &p.MaxRecords); err != nil {
+		return err
+	}
+	return util.ReadInt64(r, &p.MaxAge)
+}
+
+func (p *ContractDeployment) Write(w io.Writer) error {
+	if _, err := w.Write(p.ProgramHash[:]); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, p.Name); err != nil {
+		return err
+	}
+	if err := util.WriteString16(w, p.Description); err != nil {
+		return err
+	}
+	initParams := p.InitParams
+	if initParams == nil {
+		initParams = dict.New()
+	}
+	if err := initParams.Write(w); err != nil {
+		return err
+	}
+	return writeOptionalHname(w, p.Alias)
+}
+
+func (p *ContractDeployment) Read(r io.Reader) error {
+	var err error
+	if err := util.ReadHashValue(r, &p.ProgramHash); err != nil {
+		return err
+	}
+	if p.Name, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	if p.Description, err = util.ReadString16(r); err != nil {
+		return err
+	}
+	p.InitParams = dict.New()
+	if err := p.InitParams.Read(r); err != nil {
+		return err
+	}
+	return readOptionalHname(r, &p.Alias)
+}
+
+// writeOptionalHname writes the hname as the last field of the record, only if it is not 0
+func writeOptionalHname(w io.Writer, hname coretypes.Hname) error {
+	if hname == 0 {
+		return nil
+	}
+	return hname.Write(w)
+}
+
+// readOptionalHname reads the hname written by writeOptionalHname: 0 if the record ends before it
+func readOptionalHname(r io.Reader, hname *coretypes.Hname) error {
+	var buf [coretypes.HnameLength]byte
+	n, err := io.ReadFull(r, buf[:])
+	if n == 0 && err == io.EOF {
+		*hname = 0
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return hname.Read(bytes.NewReader(buf[:]))
+}
+
+func EncodeContractDeployment(p *ContractDeployment) []byte {
+	return util.MustBytes(p)
+}
+
+func DecodeContractDeployment(data []byte) (*ContractDeployment, error) {
+	ret := new(ContractDeployment)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
+}
+
+// EncodeContractDeployments encodes the contracts into the parameters of deployContracts
+func EncodeContractDeployments(contracts ...*ContractDeployment) dict.Dict {
+	ret := dict.New()
+	arr := collections.NewArray(ret, ParamContracts)
+	for _, c := range contracts {
+		arr.MustPush(EncodeContractDeployment(c))
+	}
+	return ret
+}
+
+// DecodeContractDeployments decodes the contracts from the parameters of deployContracts
+func DecodeContractDeployments(params kv.KVStoreReader) ([]*ContractDeployment, error) {
+	arr := collections.NewArrayReadOnly(params, ParamContracts)
+	n, err := arr.Len()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*ContractDeployment, n)
+	for i := range ret {
+		data, err := arr.GetAt(uint16(i))
+		if err != nil {
+			return nil, err
+		}
+		if ret[i], err = DecodeContractDeployment(data); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+func EncodeEventLogRetention(p *EventLogRetention) []byte {
+	return util.MustBytes(p)
+}
+
+func DecodeEventLogRetention(data []byte) (*EventLogRetention, error) {
+	ret := new(EventLogRetention)
+	err := ret.Read(bytes.NewReader(data))
+	return ret, err
 }
 
 func EncodeContractRecord(p *ContractRecord) []byte {
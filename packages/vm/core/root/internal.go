@@ -2,13 +2,20 @@ package root
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 )
 
 // FindContract is an internal utility function which finds a contract in the KVStore
@@ -42,9 +49,160 @@ func MustGetChainInfo(state kv.KVStoreReader) ChainInfo {
 		ChainColor:          d.MustGetColor(VarChainColor),
 		ChainAddress:        d.MustGetAddress(VarChainAddress),
 		Description:         d.MustGetString(VarDescription, ""),
+		Website:             d.MustGetString(VarWebsite, ""),
 		FeeColor:            d.MustGetColor(VarFeeColor, balance.ColorIOTA),
 		DefaultOwnerFee:     d.MustGetInt64(VarDefaultOwnerFee, 0),
 		DefaultValidatorFee: d.MustGetInt64(VarDefaultValidatorFee, 0),
+		MaxCallDepth:        d.MustGetInt64(VarMaxCallDepth, DefaultMaxCallDepth),
+	}
+	return ret
+}
+
+// GetChainMetadata returns the metadata of the chain.
+// It decodes both the state of the 'root' contract and the result of the getChainMetadata view
+func GetChainMetadata(state kv.KVStoreReader) (*ChainMetadata, error) {
+	d := kvdecoder.New(state)
+	ret := &ChainMetadata{Values: make(map[string]string)}
+	var err error
+	if ret.Description, err = d.GetString(VarDescription, ""); err != nil {
+		return nil, err
+	}
+	if ret.Website, err = d.GetString(VarWebsite, ""); err != nil {
+		return nil, err
+	}
+	err = collections.NewMapReadOnly(state, VarChainMetadata).Iterate(func(elemKey []byte, value []byte) bool {
+		ret.Values[string(elemKey)] = string(value)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetMaxCallDepth returns maximum depth of synchronous calls allowed on the chain
+func GetMaxCallDepth(state kv.KVStoreReader) int64 {
+	par := kvdecoder.New(state)
+	return par.MustGetInt64(VarMaxCallDepth, DefaultMaxCallDepth)
+}
+
+// GetRequestIntakePolicy returns the policy applied by nodes to incoming requests.
+// It is called by the consensus operator and by the VM, it is not exposed to the sandbox
+func GetRequestIntakePolicy(state kv.KVStoreReader) *RequestIntakePolicy {
+	d := kvdecoder.New(state)
+	return &RequestIntakePolicy{
+		RateLimit:     d.MustGetInt64(VarRequestRateLimit, 0),
+		MinFee:        d.MustGetInt64(VarMinRequestFee, 0),
+		MinDeposit:    d.MustGetInt64(VarMinRequestDeposit, 0),
+		DepositPolicy: d.MustGetInt64(VarRequestDepositPolicy, DepositRefund),
+	}
+}
+
+// GetBlockInterval returns the minimum and the maximum time between timestamps of consecutive blocks
+// of the chain. The committee doesn't start the batch before the minimum interval passes and starts
+// the empty batch after the maximum interval, to keep the timestamp of the state moving.
+// 0 means no limit
+func GetBlockInterval(state kv.KVStoreReader) (time.Duration, time.Duration) {
+	par := kvdecoder.New(state)
+	min := par.MustGetInt64(VarMinBlockInterval, 0)
+	max := par.MustGetInt64(VarMaxBlockInterval, 0)
+	return time.Duration(min) * time.Millisecond, time.Duration(max) * time.Millisecond
+}
+
+// GetUtilityPrice returns the number of cost units of host utility functions per 1 iota. 0 means free
+func GetUtilityPrice(state kv.KVStoreReader) int64 {
+	par := kvdecoder.New(state)
+	return par.MustGetInt64(VarUtilityPrice, 0)
+}
+
+// GetWasmFloatPolicy returns the float policy of wasm contracts deployed on the chain
+func GetWasmFloatPolicy(state kv.KVStoreReader) int64 {
+	par := kvdecoder.New(state)
+	return par.MustGetInt64(VarWasmFloatPolicy, WasmFloatsAllow)
+}
+
+// GetProgramFloatPolicy returns the float policy the VM applies to the program binary. It is the policy
+// of the chain at the first deployment of the program, so all instances of the program and all nodes
+// load the same binary regardless of later changes of the policy.
+// It is called from VMContext and viewcontext, it is not exposed to the sandbox
+func GetProgramFloatPolicy(state kv.KVStoreReader, programHash hashing.HashValue) int64 {
+	data := collections.NewMapReadOnly(state, VarProgramFloatPolicies).MustGetAt(programHash[:])
+	if data == nil {
+		return WasmFloatsAllow
+	}
+	ret, _, err := codec.DecodeInt64(data)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// fixProgramFloatPolicy records the current float policy of the chain for the program deployed for the first time.
+// Programs deployed before keep the policy they are loaded with
+func fixProgramFloatPolicy(state kv.KVStore, programHash hashing.HashValue) {
+	policies := collections.NewMap(state, VarProgramFloatPolicies)
+	if policies.MustHasAt(programHash[:]) {
+		return
+	}
+	policy := GetWasmFloatPolicy(state)
+	if policy == WasmFloatsAllow {
+		return
+	}
+	deployed := false
+	collections.NewMapReadOnly(state, VarContractRegistry).MustIterate(func(_ []byte, value []byte) bool {
+		rec, err := DecodeContractRecord(value)
+		deployed = err == nil && rec.ProgramHash == programHash
+		return !deployed
+	})
+	if !deployed {
+		policies.MustSetAt(programHash[:], codec.EncodeInt64(policy))
+	}
+}
+
+// IsReentrancyLocked returns true if the contract can't be called while it is already on the call stack
+// It is called from VMContext, it is not exposed to the sandbox
+func IsReentrancyLocked(state kv.KVStoreReader, hname coretypes.Hname) bool {
+	return collections.NewMapReadOnly(state, VarReentrancyLocks).MustHasAt(hname.Bytes())
+}
+
+// IsContractPaused returns true if full entry points of the contract can't be called, see pauseContract.
+// It is called from VMContext, it is not exposed to the sandbox
+func IsContractPaused(state kv.KVStoreReader, hname coretypes.Hname) bool {
+	return collections.NewMapReadOnly(state, VarPausedContracts).MustHasAt(hname.Bytes())
+}
+
+// GetPauser returns the agent which may pause and unpause contracts besides the chain owner, false if not designated
+func GetPauser(state kv.KVStoreReader) (coretypes.AgentID, bool) {
+	ret, ok, err := codec.DecodeAgentID(state.MustGet(VarPauser))
+	if err != nil {
+		panic(err)
+	}
+	return ret, ok
+}
+
+// checkAuthorizationToPause returns true if the agent is the chain owner or the pauser
+func checkAuthorizationToPause(state kv.KVStore, agentID coretypes.AgentID) bool {
+	if CheckAuthorizationByChainOwner(state, agentID) {
+		return true
+	}
+	pauser, ok := GetPauser(state)
+	return ok && pauser == agentID
+}
+
+// GetEventLogRetention returns the event log retention policy of the contract.
+// If it is not set for the contract, returns the default policy of the chain.
+// It is called from VMContext, it is not exposed to the sandbox
+func GetEventLogRetention(state kv.KVStoreReader, hname coretypes.Hname) *EventLogRetention {
+	data := collections.NewMapReadOnly(state, VarLogRetention).MustGetAt(hname.Bytes())
+	if data == nil {
+		data = state.MustGet(VarDefaultLogRetention)
+	}
+	if data == nil {
+		return &EventLogRetention{}
+	}
+	ret, err := DecodeEventLogRetention(data)
+	if err != nil {
+		panic(err)
 	}
 	return ret
 }
@@ -134,34 +292,236 @@ func CheckAuthorizationByChainOwner(state kv.KVStore, agentID coretypes.AgentID)
 	return currentOwner == agentID
 }
 
+// FindContractByName finds the contract by its name, also if it is deployed under an alias.
+// The contract registered under hname(name) with another name is not the one
+func FindContractByName(state kv.KVStoreReader, name string) (*ContractRecord, error) {
+	if data := collections.NewMapReadOnly(state, VarContractAliases).MustGetAt([]byte(name)); data != nil {
+		alias, err := coretypes.NewHnameFromBytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("root: %v", err)
+		}
+		return FindContract(state, alias)
+	}
+	rec, err := FindContract(state, coretypes.Hn(name))
+	if err != nil {
+		return nil, err
+	}
+	if rec.Name != name {
+		return nil, ErrContractNotFound
+	}
+	return rec, nil
+}
+
+// checkDeployHname returns the hname the contract with the name and the alias will be deployed under.
+// It fails if the contract with the name is already deployed or the hname is taken by another contract.
+// The check only depends on the state, so all nodes detect the same collisions
+func checkDeployHname(state kv.KVStoreReader, name string, alias coretypes.Hname) (coretypes.Hname, error) {
+	hname := coretypes.Hn(name)
+	if alias != 0 {
+		if alias == hname || alias == coretypes.Hname(^uint32(0)) {
+			return 0, fmt.Errorf("wrong alias %s of contract '%s'", alias, name)
+		}
+		hname = alias
+	}
+	if _, err := FindContractByName(state, name); err == nil {
+		return 0, fmt.Errorf("contract '%s' already exist", name)
+	}
+	if IsCoreContract(hname) {
+		return 0, fmt.Errorf("hname collision: hname %s of contract '%s' is reserved by the core contracts", hname, name)
+	}
+	if rec, err := FindContract(state, hname); err == nil {
+		return 0, fmt.Errorf("hname collision: hname %s of contract '%s' is taken by contract '%s', deploy it with an alias",
+			hname, name, rec.Name)
+	}
+	return hname, nil
+}
+
 // storeAndInitContract internal utility function
 func storeAndInitContract(ctx coretypes.Sandbox, rec *ContractRecord, initParams dict.Dict) error {
-	hname := coretypes.Hn(rec.Name)
+	hname := rec.Hname()
 	contractRegistry := collections.NewMap(ctx.State(), VarContractRegistry)
 	if contractRegistry.MustHasAt(hname.Bytes()) {
 		return fmt.Errorf("contract '%s'/%s already exist", rec.Name, hname.String())
 	}
 	contractRegistry.MustSetAt(hname.Bytes(), EncodeContractRecord(rec))
-	_, err := ctx.Call(coretypes.Hn(rec.Name), coretypes.EntryPointInit, initParams, nil)
+	if rec.Alias != 0 {
+		collections.NewMap(ctx.State(), VarContractAliases).MustSetAt([]byte(rec.Name), hname.Bytes())
+	}
+	_, err := ctx.Call(hname, coretypes.EntryPointInit, initParams, nil)
 	if err != nil {
 		// call to 'init' failed: delete record
-		contractRegistry.MustDelAt(hname.Bytes())
+		removeContract(ctx.State(), rec)
 		err = fmt.Errorf("contract '%s'/%s: calling 'init': %v", rec.Name, hname.String(), err)
 	}
 	return err
 }
 
+// removeContract removes the record and the alias of the contract from the registry
+func removeContract(state kv.KVStore, rec *ContractRecord) {
+	collections.NewMap(state, VarContractRegistry).MustDelAt(rec.Hname().Bytes())
+	if rec.Alias != 0 {
+		collections.NewMap(state, VarContractAliases).MustDelAt([]byte(rec.Name))
+	}
+}
+
+// GetDeployPolicy returns the deploy policy of the chain
+func GetDeployPolicy(state kv.KVStoreReader) int64 {
+	par := kvdecoder.New(state)
+	return par.MustGetInt64(VarDeployPolicy, DeployPolicyAllowList)
+}
+
 // isAuthorizedToDeploy checks if caller is authorized to deploy smart contract
 func isAuthorizedToDeploy(ctx coretypes.Sandbox) bool {
-	caller := ctx.Caller()
-	if caller == ctx.ChainOwnerID() {
+	return isDeployPermitted(ctx.State(), ctx.Caller(), ctx.ChainOwnerID(), ctx.ContractID().ChainID())
+}
+
+// isDeployPermitted checks the agent against the deploy policy of the chain
+func isDeployPermitted(state kv.KVStoreReader, agentID, chainOwnerID coretypes.AgentID, chainID coretypes.ChainID) bool {
+	if agentID == chainOwnerID {
 		// chain owner is always authorized
 		return true
 	}
-	if !caller.IsAddress() {
+	switch GetDeployPolicy(state) {
+	case DeployPolicyOpen:
+		return true
+	case DeployPolicyOwnerOnly:
+		return false
+	}
+	if !agentID.IsAddress() {
 		// smart contract from the same chain is always authorize
-		return caller.MustContractID().ChainID() == ctx.ContractID().ChainID()
+		return agentID.MustContractID().ChainID() == chainID
+	}
+	return collections.NewMapReadOnly(state, VarDeployPermissions).MustHasAt(agentID[:])
+}
+
+// IsCoreContract returns true if the contract is one of the core contracts deployed with the chain
+func IsCoreContract(hname coretypes.Hname) bool {
+	switch hname {
+	case Interface.Hname(), accounts.Interface.Hname(), blob.Interface.Hname(), eventlog.Interface.Hname():
+		return true
+	}
+	return false
+}
+
+// GetResourceLimits returns the resource limits of the chain.
+// It is called from VMContext, it is not exposed to the sandbox
+func GetResourceLimits(state kv.KVStoreReader) *ResourceLimits {
+	d := kvdecoder.New(state)
+	return &ResourceLimits{
+		MaxContracts: d.MustGetInt64(VarMaxContracts, 0),
+		MaxStateSize: d.MustGetInt64(VarMaxStateSize, 0),
+		MaxBlobSize:  d.MustGetInt64(VarMaxBlobSize, 0),
+	}
+}
+
+// GetNumContracts returns the number of contracts deployed on the chain besides the core contracts
+func GetNumContracts(state kv.KVStoreReader) int64 {
+	ret := int64(0)
+	collections.NewMapReadOnly(state, VarContractRegistry).MustIterateKeys(func(key []byte) bool {
+		hname, err := coretypes.NewHnameFromBytes(key)
+		if err != nil {
+			panic(err)
+		}
+		if !IsCoreContract(hname) {
+			ret++
+		}
+		return true
+	})
+	return ret
+}
+
+// GetContractHnames returns hnames of all contracts deployed on the chain, including the core contracts
+func GetContractHnames(state kv.KVStoreReader) []coretypes.Hname {
+	ret := make([]coretypes.Hname, 0)
+	collections.NewMapReadOnly(state, VarContractRegistry).MustIterateKeys(func(key []byte) bool {
+		hname, err := coretypes.NewHnameFromBytes(key)
+		if err != nil {
+			panic(err)
+		}
+		ret = append(ret, hname)
+		return true
+	})
+	return ret
+}
+
+// GetStateUsage returns the size of the state of the contract recorded by the VM and false if
+// the VM didn't record it yet
+func GetStateUsage(state kv.KVStoreReader, hname coretypes.Hname) (int64, bool) {
+	data := collections.NewMapReadOnly(state, VarStateUsage).MustGetAt(hname.Bytes())
+	if data == nil {
+		return 0, false
+	}
+	ret, _, err := codec.DecodeInt64(data)
+	if err != nil {
+		panic(err)
 	}
+	return ret, true
+}
+
+// SetStateUsage records the size of the state of the contract.
+// It is called from VMContext after each request, it is not exposed to the sandbox
+func SetStateUsage(state kv.KVStore, hname coretypes.Hname, size int64) {
+	collections.NewMap(state, VarStateUsage).MustSetAt(hname.Bytes(), codec.EncodeInt64(size))
+}
 
-	return collections.NewMap(ctx.State(), VarDeployPermissions).MustHasAt(caller[:])
+// RecordExecutionUnits records the execution units consumed by the requests of the block.
+// Units of the latest ExecUnitsHistory blocks are kept, together with the total of all blocks.
+// It is called from VMContext with the last request of the batch, it is not exposed to the sandbox
+func RecordExecutionUnits(state kv.KVStore, blockIndex uint32, units int64) {
+	m := collections.NewMap(state, VarExecUnits)
+	m.MustSetAt(util.Uint32To4Bytes(blockIndex), codec.EncodeInt64(units))
+	if blockIndex >= ExecUnitsHistory {
+		m.MustDelAt(util.Uint32To4Bytes(blockIndex - ExecUnitsHistory))
+	}
+	state.Set(VarExecUnitsTotal, codec.EncodeInt64(GetTotalExecutionUnits(state)+units))
+	state.Set(VarExecUnitsLastBlock, util.Uint32To4Bytes(blockIndex))
+}
+
+// GetExecutionUnits returns the execution units consumed by the block and false if they are not known:
+// the block is not produced yet or it is older than ExecUnitsHistory blocks.
+// Empty blocks consume no units
+func GetExecutionUnits(state kv.KVStoreReader, blockIndex uint32) (int64, bool) {
+	last, ok := GetLastExecutionUnitsBlock(state)
+	if !ok || blockIndex > last || last-blockIndex >= ExecUnitsHistory {
+		return 0, false
+	}
+	ret, _, err := codec.DecodeInt64(collections.NewMapReadOnly(state, VarExecUnits).MustGetAt(util.Uint32To4Bytes(blockIndex)))
+	if err != nil {
+		panic(err)
+	}
+	return ret, true
+}
+
+// GetLastExecutionUnitsBlock returns the index of the last block the execution units were recorded for
+func GetLastExecutionUnitsBlock(state kv.KVStoreReader) (uint32, bool) {
+	data := state.MustGet(VarExecUnitsLastBlock)
+	if data == nil {
+		return 0, false
+	}
+	ret, err := util.Uint32From4Bytes(data)
+	if err != nil {
+		panic(err)
+	}
+	return ret, true
+}
+
+// GetTotalExecutionUnits returns the execution units consumed by all blocks of the chain
+func GetTotalExecutionUnits(state kv.KVStoreReader) int64 {
+	ret, _, err := codec.DecodeInt64(state.MustGet(VarExecUnitsTotal))
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+// checkMaxContracts checks if the limit of the number of contracts lets deploy n more contracts
+func checkMaxContracts(state kv.KVStoreReader, n int) error {
+	maxContracts := GetResourceLimits(state).MaxContracts
+	if maxContracts == 0 {
+		return nil
+	}
+	if numContracts := GetNumContracts(state); numContracts+int64(n) > maxContracts {
+		return fmt.Errorf("%w: %d contracts deployed, limit %d", ErrMaxContractsExceeded, numContracts, maxContracts)
+	}
+	return nil
 }
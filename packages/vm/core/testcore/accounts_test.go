@@ -5,9 +5,12 @@ package testcore
 
 import (
 	"testing"
+	"time"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/solo"
 	"github.com/iotaledger/wasp/packages/testutil"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
@@ -75,6 +78,21 @@ func TestAccountsDepositWithdrawToAddress(t *testing.T) {
 	env.AssertAddressBalance(newOwner.Address(), balance.ColorIOTA, testutil.RequestFundsAmount)
 }
 
+func TestAccountsDepositWithdrawHelpers(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	newOwner := env.NewSignatureSchemeWithFunds()
+	err := chain.DepositIotasToL2(newOwner, 42)
+	require.NoError(t, err)
+	chain.CheckAccountLedger()
+
+	err = chain.WithdrawToL1(newOwner, balance.ColorIOTA, 42+1)
+	require.NoError(t, err)
+	chain.CheckAccountLedger()
+	env.AssertAddressBalance(newOwner.Address(), balance.ColorIOTA, testutil.RequestFundsAmount)
+}
+
 func TestAccountsDepositWithdrawToChainFail(t *testing.T) {
 	env := solo.New(t, false, false)
 	chain := env.NewChain(nil, "chain1")
@@ -92,6 +110,252 @@ func TestAccountsDepositWithdrawToChainFail(t *testing.T) {
 	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncWithdrawToChain)
 	_, err = chain.PostRequestSync(req, newOwner)
 	require.Error(t, err)
+	require.EqualValues(t, coretypes.VMErrorUnauthorized, coretypes.VMErrorCodeOf(err))
 	chain.AssertAccountBalance(newOwnerAgentID, balance.ColorIOTA, 42+2)
 	env.AssertAddressBalance(newOwner.Address(), balance.ColorIOTA, testutil.RequestFundsAmount-42-2)
 }
+
+func TestAccountsDepositMultiChain(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain1 := env.NewChain(nil, "chain1")
+	chain2 := env.NewChain(nil, "chain2")
+
+	newOwner := env.NewSignatureSchemeWithFunds()
+	newOwnerAgentID := coretypes.NewAgentIDFromAddress(newOwner.Address())
+	tx := env.PostRequestsMultiChain(newOwner,
+		solo.ChainRequest{
+			Chain:  chain1,
+			Params: solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42),
+		},
+		solo.ChainRequest{
+			Chain:  chain2,
+			Params: solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 10),
+		},
+	)
+	require.Len(t, tx.RequestsByChain(), 2)
+
+	chain1.WaitForEmptyBacklog()
+	chain2.WaitForEmptyBacklog()
+
+	chain1.AssertAccountBalance(newOwnerAgentID, balance.ColorIOTA, 42+1)
+	chain2.AssertAccountBalance(newOwnerAgentID, balance.ColorIOTA, 10+1)
+	env.AssertAddressBalance(newOwner.Address(), balance.ColorIOTA, testutil.RequestFundsAmount-42-10-2)
+	chain1.CheckAccountLedger()
+	chain2.CheckAccountLedger()
+}
+
+func TestRegisterToken(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	minter := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncRegisterToken,
+		accounts.ParamTokenName, "Test token",
+		accounts.ParamTokenSymbol, "TST",
+		accounts.ParamTokenDecimals, 2,
+		accounts.ParamTokenSupplyCap, 10000,
+	).WithMinting(map[address.Address]int64{minter.Address(): 1000})
+	tx, _, err := chain.PostRequestSyncTx(req, minter)
+	require.NoError(t, err)
+	color := balance.Color(tx.ID())
+	env.AssertAddressBalance(minter.Address(), color, 1000)
+
+	meta := chain.GetTokenMetadata(color)
+	require.NotNil(t, meta)
+	require.EqualValues(t, "Test token", meta.Name)
+	require.EqualValues(t, "TST", meta.Symbol)
+	require.EqualValues(t, 2, meta.Decimals)
+	require.EqualValues(t, 10000, meta.SupplyCap)
+	require.EqualValues(t, 1000, meta.MintedSupply)
+	require.EqualValues(t, coretypes.NewAgentIDFromAddress(minter.Address()), meta.Owner)
+	require.EqualValues(t, "10.00 TST", meta.FormatAmount(1000))
+
+	// the owner can update the metadata
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncRegisterToken,
+		accounts.ParamColor, color,
+		accounts.ParamTokenName, "Renamed token",
+		accounts.ParamTokenSymbol, "TST",
+	)
+	_, err = chain.PostRequestSync(req, minter)
+	require.NoError(t, err)
+	require.EqualValues(t, "Renamed token", chain.GetTokenMetadata(color).Name)
+
+	// nobody else can
+	other := env.NewSignatureSchemeWithFunds()
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncRegisterToken,
+		accounts.ParamColor, color,
+		accounts.ParamTokenName, "Stolen token",
+		accounts.ParamTokenSymbol, "XXX",
+	)
+	_, err = chain.PostRequestSync(req, other)
+	require.Error(t, err)
+	require.EqualValues(t, coretypes.VMErrorUnauthorized, coretypes.VMErrorCodeOf(err))
+	require.EqualValues(t, "Renamed token", chain.GetTokenMetadata(color).Name)
+}
+
+func TestRegisterTokenNotMinted(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	wallet := env.NewSignatureSchemeWithFunds()
+	color, err := env.MintTokens(wallet, 1000)
+	require.NoError(t, err)
+
+	// the token was minted by another transaction
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncRegisterToken,
+		accounts.ParamColor, color,
+		accounts.ParamTokenName, "Test token",
+		accounts.ParamTokenSymbol, "TST",
+	)
+	_, err = chain.PostRequestSync(req, wallet)
+	require.Error(t, err)
+	require.Nil(t, chain.GetTokenMetadata(color))
+}
+
+func TestRegisterTokenSupplyCap(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	minter := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncRegisterToken,
+		accounts.ParamTokenName, "Test token",
+		accounts.ParamTokenSymbol, "TST",
+		accounts.ParamTokenSupplyCap, 100,
+	).WithMinting(map[address.Address]int64{minter.Address(): 1000})
+	_, err := chain.PostRequestSync(req, minter)
+	require.Error(t, err)
+}
+
+func TestEscrowClaim(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	sender := env.NewSignatureSchemeWithFunds()
+	senderAgentID := coretypes.NewAgentIDFromAddress(sender.Address())
+	recipient := env.NewSignatureSchemeWithFunds()
+	recipientAgentID := coretypes.NewAgentIDFromAddress(recipient.Address())
+
+	// the condition is always satisfied
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncCreateEscrow,
+		accounts.ParamAgentID, recipientAgentID,
+		accounts.ParamConditionSC, root.Interface.Hname(),
+		accounts.ParamConditionEP, coretypes.Hn(root.FuncGetChainInfo),
+		accounts.ParamDeadline, env.LogicalTime().Add(time.Hour).UnixNano(),
+	).WithTransfer(balance.ColorIOTA, 42)
+	res, err := chain.PostRequestSync(req, sender)
+	require.NoError(t, err)
+	id, _, err := codec.DecodeInt64(res.MustGet(accounts.ParamEscrowID))
+	require.NoError(t, err)
+
+	escrow := chain.GetEscrow(id)
+	require.NotNil(t, escrow)
+	require.EqualValues(t, senderAgentID, escrow.Sender)
+	require.EqualValues(t, 42, escrow.Funds.Balance(balance.ColorIOTA))
+	chain.AssertAccountBalance(senderAgentID, balance.ColorIOTA, 1)
+	chain.CheckAccountLedger()
+
+	// only the recipient can claim
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncClaimEscrow, accounts.ParamEscrowID, id)
+	_, err = chain.PostRequestSync(req, sender)
+	require.Error(t, err)
+	require.EqualValues(t, coretypes.VMErrorUnauthorized, coretypes.VMErrorCodeOf(err))
+
+	_, err = chain.PostRequestSync(req, recipient)
+	require.NoError(t, err)
+	require.Nil(t, chain.GetEscrow(id))
+	chain.AssertAccountBalance(recipientAgentID, balance.ColorIOTA, 42+1)
+	chain.CheckAccountLedger()
+
+	_, err = chain.PostRequestSync(req, recipient)
+	require.Error(t, err)
+}
+
+func TestEscrowRefund(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	sender := env.NewSignatureSchemeWithFunds()
+	senderAgentID := coretypes.NewAgentIDFromAddress(sender.Address())
+	recipient := env.NewSignatureSchemeWithFunds()
+	recipientAgentID := coretypes.NewAgentIDFromAddress(recipient.Address())
+
+	// the deadline must be in the future
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncCreateEscrow,
+		accounts.ParamAgentID, recipientAgentID,
+		accounts.ParamConditionSC, root.Interface.Hname(),
+		accounts.ParamConditionEP, coretypes.Hn(root.FuncGetChainInfo),
+		accounts.ParamDeadline, env.LogicalTime().Add(-time.Hour).UnixNano(),
+	).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, sender)
+	require.Error(t, err)
+
+	// the condition fails: the 'balance' view requires the agent ID
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncCreateEscrow,
+		accounts.ParamAgentID, recipientAgentID,
+		accounts.ParamConditionSC, accounts.Interface.Hname(),
+		accounts.ParamConditionEP, coretypes.Hn(accounts.FuncBalance),
+		accounts.ParamDeadline, env.LogicalTime().Add(time.Hour).UnixNano(),
+	).WithTransfer(balance.ColorIOTA, 42)
+	res, err := chain.PostRequestSync(req, sender)
+	require.NoError(t, err)
+	id, _, err := codec.DecodeInt64(res.MustGet(accounts.ParamEscrowID))
+	require.NoError(t, err)
+
+	claim := solo.NewCallParams(accounts.Interface.Name, accounts.FuncClaimEscrow, accounts.ParamEscrowID, id)
+	_, err = chain.PostRequestSync(claim, recipient)
+	require.Error(t, err)
+	require.NotNil(t, chain.GetEscrow(id))
+
+	// the funds are returned with the first request after the deadline
+	env.AdvanceClockBy(2 * time.Hour)
+	_, err = chain.PostRequestSync(claim, recipient)
+	require.Error(t, err)
+	require.Nil(t, chain.GetEscrow(id))
+	chain.AssertAccountBalance(senderAgentID, balance.ColorIOTA, 42+2)
+	chain.AssertAccountBalance(recipientAgentID, balance.ColorIOTA, 2)
+	chain.CheckAccountLedger()
+}
+
+func TestTransferAccount(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	oldOwner := env.NewSignatureSchemeWithFunds()
+	oldOwnerAgentID := coretypes.NewAgentIDFromAddress(oldOwner.Address())
+	newOwner := env.NewSignatureSchemeWithFunds()
+	newOwnerAgentID := coretypes.NewAgentIDFromAddress(newOwner.Address())
+
+	color, err := env.MintTokens(oldOwner, 100)
+	require.NoError(t, err)
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).
+		WithTransfers(map[balance.Color]int64{balance.ColorIOTA: 42, color: 100})
+	_, err = chain.PostRequestSync(req, oldOwner)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(oldOwnerAgentID, balance.ColorIOTA, 42+1)
+	chain.AssertAccountBalance(oldOwnerAgentID, color, 100)
+
+	// the account can't be transferred to itself
+	_, err = chain.TransferAccount(oldOwner, oldOwnerAgentID)
+	require.Error(t, err)
+
+	// the request token of the failed request and of the transfer request are moved too
+	moved, err := chain.TransferAccount(oldOwner, newOwnerAgentID)
+	require.NoError(t, err)
+	require.EqualValues(t, 42+3, moved[balance.ColorIOTA])
+	require.EqualValues(t, 100, moved[color])
+	chain.AssertAccountBalance(oldOwnerAgentID, balance.ColorIOTA, 0)
+	chain.AssertAccountBalance(oldOwnerAgentID, color, 0)
+	chain.AssertAccountBalance(newOwnerAgentID, balance.ColorIOTA, 42+3)
+	chain.AssertAccountBalance(newOwnerAgentID, color, 100)
+	chain.CheckAccountLedger()
+
+	recs, err := chain.GetEventLogRecordsString(accounts.Interface.Name)
+	require.NoError(t, err)
+	require.Contains(t, recs, "[transfer account] from: "+oldOwnerAgentID.String()+", to: "+newOwnerAgentID.String())
+
+	// nothing left to transfer, except the request token
+	moved, err = chain.TransferAccount(oldOwner, newOwnerAgentID)
+	require.NoError(t, err)
+	require.EqualValues(t, map[balance.Color]int64{balance.ColorIOTA: 1}, moved)
+	chain.AssertAccountBalance(newOwnerAgentID, balance.ColorIOTA, 42+4)
+}
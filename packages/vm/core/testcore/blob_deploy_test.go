@@ -5,6 +5,7 @@ package testcore
 
 import (
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"testing"
 
@@ -172,3 +173,90 @@ func TestDeployGrantFail(t *testing.T) {
 	err = chain.DeployWasmContract(user1, "testCore", wasmFile)
 	require.Error(t, err)
 }
+
+func TestDeployPolicyOpen(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user1 := env.NewSignatureSchemeWithFunds()
+
+	err := chain.SetDeployPolicy(user1, root.DeployPolicyOpen)
+	require.Error(t, err)
+
+	err = chain.SetDeployPolicy(nil, root.DeployPolicyOpen)
+	require.NoError(t, err)
+
+	err = chain.DeployWasmContract(user1, "testCore", wasmFile)
+	require.NoError(t, err)
+
+	err = chain.SetDeployPolicy(nil, root.DeployPolicyAllowList)
+	require.NoError(t, err)
+
+	err = chain.DeployWasmContract(user1, "testInccounter2", wasmFile)
+	require.Error(t, err)
+}
+
+func TestDeployPolicyOwnerOnly(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user1 := env.NewSignatureSchemeWithFunds()
+	user1AgentID := coretypes.NewAgentIDFromAddress(user1.Address())
+
+	err := chain.GrantDeployPermission(nil, user1AgentID)
+	require.NoError(t, err)
+
+	err = chain.SetDeployPolicy(nil, root.DeployPolicyOwnerOnly)
+	require.NoError(t, err)
+
+	// granted permission is ignored
+	err = chain.DeployWasmContract(user1, "testCore", wasmFile)
+	require.Error(t, err)
+
+	err = chain.DeployWasmContract(nil, "testCore", wasmFile)
+	require.NoError(t, err)
+
+	// the permission is back with the allow-list policy
+	err = chain.SetDeployPolicy(nil, root.DeployPolicyAllowList)
+	require.NoError(t, err)
+
+	err = chain.DeployWasmContract(user1, "testInccounter2", wasmFile)
+	require.NoError(t, err)
+}
+
+func TestDeployPolicyWrong(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+
+	err := chain.SetDeployPolicy(nil, 42)
+	require.Error(t, err)
+}
+
+func TestGetDeployPolicy(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user1 := env.NewSignatureSchemeWithFunds()
+	user1AgentID := coretypes.NewAgentIDFromAddress(user1.Address())
+
+	checkPolicy := func(expectedPolicy int64, expectedPermitted int64) {
+		ret, err := chain.CallView(root.Interface.Name, root.FuncGetDeployPolicy, root.ParamDeployer, user1AgentID)
+		require.NoError(t, err)
+		policy, _, err := codec.DecodeInt64(ret.MustGet(root.ParamDeployPolicy))
+		require.NoError(t, err)
+		require.EqualValues(t, expectedPolicy, policy)
+		permitted, _, err := codec.DecodeInt64(ret.MustGet(root.ParamPermitted))
+		require.NoError(t, err)
+		require.EqualValues(t, expectedPermitted, permitted)
+	}
+	checkPolicy(root.DeployPolicyAllowList, 0)
+
+	err := chain.GrantDeployPermission(nil, user1AgentID)
+	require.NoError(t, err)
+	checkPolicy(root.DeployPolicyAllowList, 1)
+
+	err = chain.SetDeployPolicy(nil, root.DeployPolicyOwnerOnly)
+	require.NoError(t, err)
+	checkPolicy(root.DeployPolicyOwnerOnly, 0)
+
+	err = chain.SetDeployPolicy(nil, root.DeployPolicyOpen)
+	require.NoError(t, err)
+	checkPolicy(root.DeployPolicyOpen, 1)
+}
@@ -1,6 +1,9 @@
 package testcore
 
 import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/solo"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
@@ -85,3 +88,67 @@ func TestChainLogDeploy(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, recs, 0)
 }
+
+func TestRequestReceipts(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+
+	receipts, err := chain.GetRequestReceipts(userAgentID, 10)
+	require.NoError(t, err)
+	require.Len(t, receipts, 0)
+
+	for i := 0; i < 3; i++ {
+		req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 1)
+		_, err = chain.PostRequestSync(req, user)
+		require.NoError(t, err)
+	}
+	// only the chain owner can set the utility price
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetUtilityPrice, root.ParamUtilityPrice, 10)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	receipts, err = chain.GetRequestReceipts(userAgentID, 10)
+	require.NoError(t, err)
+	require.Len(t, receipts, 4)
+	require.EqualValues(t, root.Interface.Hname(), receipts[0].Contract)
+	require.EqualValues(t, coretypes.Hn(root.FuncSetUtilityPrice), receipts[0].EntryPoint)
+	require.EqualValues(t, coretypes.VMErrorUnauthorized, receipts[0].ErrorCode)
+	require.NotEmpty(t, receipts[0].Error)
+	require.EqualValues(t, chain.State.BlockIndex(), receipts[0].BlockIndex)
+	for _, r := range receipts[1:] {
+		require.EqualValues(t, coretypes.Hn(accounts.FuncDeposit), r.EntryPoint)
+		require.EqualValues(t, coretypes.VMErrorNone, r.ErrorCode)
+		require.Empty(t, r.Error)
+	}
+
+	// the second page starts with the receipt before the last one of the first page
+	res, err := chain.CallView(eventlog.Interface.Name, eventlog.FuncGetRequestReceipts,
+		eventlog.ParamAgentID, userAgentID,
+		eventlog.ParamMaxLastRecords, 3,
+	)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, collections.NewArrayReadOnly(res, eventlog.ParamRecords).MustLen())
+	next, ok, err := codec.DecodeInt64(res.MustGet(eventlog.ParamNextIndex))
+	require.NoError(t, err)
+	require.True(t, ok)
+	res, err = chain.CallView(eventlog.Interface.Name, eventlog.FuncGetRequestReceipts,
+		eventlog.ParamAgentID, userAgentID,
+		eventlog.ParamBeforeIndex, next,
+		eventlog.ParamMaxLastRecords, 3,
+	)
+	require.NoError(t, err)
+	page := collections.NewArrayReadOnly(res, eventlog.ParamRecords)
+	require.EqualValues(t, 1, page.MustLen())
+	require.Nil(t, res.MustGet(eventlog.ParamNextIndex))
+	_, oldest, err := eventlog.ParseReceiptRecord(page.MustGetAt(0))
+	require.NoError(t, err)
+	require.EqualValues(t, receipts[3], oldest)
+
+	// requests of other senders are not in the index
+	other := env.NewSignatureSchemeWithFunds()
+	receipts, err = chain.GetRequestReceipts(coretypes.NewAgentIDFromAddress(other.Address()), 10)
+	require.NoError(t, err)
+	require.Len(t, receipts, 0)
+}
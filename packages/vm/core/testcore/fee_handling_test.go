@@ -102,3 +102,38 @@ func TestFeeIsEnough2(t *testing.T) {
 	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 1)
 	env.AssertAddressBalance(user.Address(), balance.ColorIOTA, testutil.RequestFundsAmount-3)
 }
+
+func TestHarvestValidatorFees(t *testing.T) {
+	env := solo.New(t, false, false)
+	validator := env.NewSignatureSchemeWithFunds()
+	validatorAgentID := coretypes.NewAgentIDFromAddress(validator.Address())
+	chain := env.NewChain(nil, "chain1", validatorAgentID)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, blob.Interface.Hname(),
+		root.ParamValidatorFee, 10,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	_, err = chain.UploadBlob(user, blob.VarFieldVMType, "dummyType1")
+	require.NoError(t, err)
+	_, err = chain.UploadBlob(user, blob.VarFieldVMType, "dummyType2")
+	require.NoError(t, err)
+	chain.AssertAccountBalance(validatorAgentID, balance.ColorIOTA, 20)
+
+	l1Before := env.GetAddressBalance(validator.Address(), balance.ColorIOTA)
+
+	// below the threshold: nothing is harvested, the request token stays on the account
+	harvested, err := chain.HarvestFees(validator, 100)
+	require.NoError(t, err)
+	require.Len(t, harvested, 0)
+	chain.AssertAccountBalance(validatorAgentID, balance.ColorIOTA, 21)
+
+	harvested, err = chain.HarvestFees(validator, 1)
+	require.NoError(t, err)
+	require.EqualValues(t, 22, harvested[balance.ColorIOTA])
+	require.Zero(t, chain.GetAccountBalance(validatorAgentID).Len())
+	env.AssertAddressBalance(validator.Address(), balance.ColorIOTA, l1Before-2+22)
+}
@@ -4,10 +4,16 @@
 package testcore
 
 import (
+	"fmt"
 	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
 	"testing"
+	"time"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/collections"
 	"github.com/iotaledger/wasp/packages/solo"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
@@ -132,6 +138,83 @@ func TestDeployDouble(t *testing.T) {
 	require.EqualValues(t, sbtestsc.Interface.ProgramHash, rec.ProgramHash)
 }
 
+func TestDeployContracts(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	err := chain.DeployContracts(nil,
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: "first"},
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: "second", Description: "second one"},
+	)
+	require.NoError(t, err)
+
+	_, contracts := chain.GetInfo()
+	require.EqualValues(t, 6, len(contracts))
+	rec, ok := contracts[coretypes.Hn("first")]
+	require.True(t, ok)
+	require.EqualValues(t, "N/A", rec.Description)
+	require.EqualValues(t, chain.OriginatorAgentID, rec.Creator)
+	rec, ok = contracts[coretypes.Hn("second")]
+	require.True(t, ok)
+	require.EqualValues(t, "second one", rec.Description)
+
+	events, err := chain.GetEvents(root.Interface.Name, root.EventTopicDeploy)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	events, err = chain.GetEvents(root.Interface.Name, root.EventTopicDeploy, root.ParamName, "second")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+}
+
+func TestDeployContractsAtomic(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	// the constructor of the last contract fails
+	err := chain.DeployContracts(nil,
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: "first"},
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: "second",
+			InitParams: codec.MakeDict(map[string]interface{}{sbtestsc.ParamFail: 1})},
+	)
+	require.Error(t, err)
+
+	// duplicate names
+	err = chain.DeployContracts(nil,
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: "first"},
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: "first"},
+	)
+	require.Error(t, err)
+
+	// unknown program of the last contract
+	err = chain.DeployContracts(nil,
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: "first"},
+		&root.ContractDeployment{ProgramHash: hashing.RandomHash(nil), Name: "second"},
+	)
+	require.Error(t, err)
+
+	_, contracts := chain.GetInfo()
+	require.EqualValues(t, 4, len(contracts))
+	events, err := chain.GetEvents(root.Interface.Name, root.EventTopicDeploy)
+	require.NoError(t, err)
+	require.Len(t, events, 0)
+}
+
+func TestDeployContractsUnauthorized(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	user := env.NewSignatureSchemeWithFunds()
+	err := chain.DeployContracts(user,
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: "first"},
+	)
+	require.Error(t, err)
+	_, contracts := chain.GetInfo()
+	require.EqualValues(t, 4, len(contracts))
+}
+
 func TestChangeOwnerAuthorized(t *testing.T) {
 	env := solo.New(t, false, false)
 	chain := env.NewChain(nil, "chain1")
@@ -168,3 +251,586 @@ func TestChangeOwnerUnauthorized(t *testing.T) {
 	info, _ := chain.GetInfo()
 	require.EqualValues(t, chain.OriginatorAgentID, info.ChainOwnerID)
 }
+
+func TestChainMetadata(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	meta := chain.GetChainMetadata()
+	require.EqualValues(t, "'solo' testing chain", meta.Description)
+	require.EqualValues(t, "", meta.Website)
+	require.Empty(t, meta.Values)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetChainMetadata,
+		root.ParamDescription, "new description",
+		root.ParamWebsite, "https://example.com",
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetChainMetadata,
+		root.ParamMetadataKey, "logo",
+		root.ParamMetadataVal, "https://example.com/logo.png",
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	meta = chain.GetChainMetadata()
+	require.EqualValues(t, "new description", meta.Description)
+	require.EqualValues(t, "https://example.com", meta.Website)
+	require.EqualValues(t, map[string]string{"logo": "https://example.com/logo.png"}, meta.Values)
+
+	events, err := chain.GetEvents(root.Interface.Name, root.EventTopicChainMetadata)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	events, err = chain.GetEvents(root.Interface.Name, root.EventTopicChainMetadata, root.ParamMetadataKey, "logo")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	// removing the custom value
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetChainMetadata, root.ParamMetadataKey, "logo")
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.Empty(t, chain.GetChainMetadata().Values)
+}
+
+func TestChainMetadataUnauthorized(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	user := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetChainMetadata, root.ParamDescription, "hijacked")
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetChainMetadata)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	require.EqualValues(t, "'solo' testing chain", chain.GetChainMetadata().Description)
+}
+
+func TestRequestIntakePolicy(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	var policy struct {
+		RateLimit int64 `codec:"$$ratelimit$$"`
+		MinFee    int64 `codec:"$$minfee$$"`
+	}
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetRequestIntakePolicy, &policy)
+	require.EqualValues(t, 0, policy.RateLimit)
+	require.EqualValues(t, 0, policy.MinFee)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy, root.ParamRateLimit, 10)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy, root.ParamMinFee, 5)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	rateLimit, err := chain.CallViewInt64(root.Interface.Name, root.FuncGetRequestIntakePolicy, root.ParamRateLimit)
+	require.NoError(t, err)
+	require.EqualValues(t, 10, rateLimit)
+	minFee, err := chain.CallViewInt64(root.Interface.Name, root.FuncGetRequestIntakePolicy, root.ParamMinFee)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, minFee)
+
+	// removing the limit
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy, root.ParamRateLimit, 0)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	rateLimit, err = chain.CallViewInt64(root.Interface.Name, root.FuncGetRequestIntakePolicy, root.ParamRateLimit)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, rateLimit)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy, root.ParamMinFee, -1)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy, root.ParamRateLimit, 1)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+}
+
+func TestRequestDeposit(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	minDeposit, depositPolicy := chain.GetDepositInfo()
+	require.EqualValues(t, 0, minDeposit)
+	require.EqualValues(t, root.DepositRefund, depositPolicy)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy, root.ParamMinDeposit, 100)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	minDeposit, depositPolicy = chain.GetDepositInfo()
+	require.EqualValues(t, 100, minDeposit)
+	require.EqualValues(t, root.DepositRefund, depositPolicy)
+
+	user := env.NewSignatureSchemeWithFunds()
+	userAgentID := coretypes.NewAgentIDFromAddress(user.Address())
+
+	// not enough for the deposit: the transfer is accrued to the sender, nothing is called
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 50)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 50+1)
+
+	// the refundable deposit is locked on the chain after the call
+	accountsAgentID := coretypes.NewAgentIDFromContractID(accounts.Interface.ContractID(chain.ChainID))
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 150)
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 50+1+50+1)
+	chain.AssertAccountBalance(accountsAgentID, balance.ColorIOTA, 100)
+
+	// it is refunded with the first request after the lock period
+	env.AdvanceClockBy(root.DepositLockPeriod + time.Second)
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy, root.ParamDepositPolicy, root.DepositForfeit)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 50+1+50+1+100)
+	chain.AssertAccountBalance(accountsAgentID, balance.ColorIOTA, 0)
+
+	// the forfeited deposit is accrued to the chain owner
+	ownerIotas := chain.GetAccountBalance(chain.OriginatorAgentID).Balance(balance.ColorIOTA)
+	req = solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 150)
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(userAgentID, balance.ColorIOTA, 50+1+50+1+100+50+1)
+	chain.AssertAccountBalance(chain.OriginatorAgentID, balance.ColorIOTA, ownerIotas+100)
+	chain.CheckAccountLedger()
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetRequestIntakePolicy, root.ParamDepositPolicy, 2)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+}
+
+func TestWasmFloatPolicy(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	policy, err := chain.CallViewInt64(root.Interface.Name, root.FuncGetWasmFloatPolicy, root.ParamFloatPolicy)
+	require.NoError(t, err)
+	require.EqualValues(t, root.WasmFloatsAllow, policy)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetWasmFloatPolicy, root.ParamFloatPolicy, root.WasmFloatsCanonicalize)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	policy, err = chain.CallViewInt64(root.Interface.Name, root.FuncGetWasmFloatPolicy, root.ParamFloatPolicy)
+	require.NoError(t, err)
+	require.EqualValues(t, root.WasmFloatsCanonicalize, policy)
+
+	// the program deployed for the first time gets the policy of the chain
+	hwasm, err := chain.UploadWasmFromFile(nil, wasmFile)
+	require.NoError(t, err)
+	err = chain.DeployContract(nil, "testCore", hwasm)
+	require.NoError(t, err)
+	policy, err = chain.CallViewInt64(root.Interface.Name, root.FuncGetWasmFloatPolicy,
+		root.ParamFloatPolicy, root.ParamProgramHash, hwasm)
+	require.NoError(t, err)
+	require.EqualValues(t, root.WasmFloatsCanonicalize, policy)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetWasmFloatPolicy, root.ParamFloatPolicy, root.WasmFloatsReject)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	// the program keeps the policy of the first deployment
+	err = chain.DeployContract(nil, "testCore2", hwasm)
+	require.NoError(t, err)
+	policy, err = chain.CallViewInt64(root.Interface.Name, root.FuncGetWasmFloatPolicy,
+		root.ParamFloatPolicy, root.ParamProgramHash, hwasm)
+	require.NoError(t, err)
+	require.EqualValues(t, root.WasmFloatsCanonicalize, policy)
+
+	// the binary with floats is rejected: (func (param f64 f64) (result f64) local.get 0 local.get 1 f64.div)
+	floatWasm := []byte{
+		0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x07, 0x01, 0x60, 0x02, 0x7c, 0x7c, 0x01, 0x7c,
+		0x03, 0x02, 0x01, 0x00,
+		0x0a, 0x09, 0x01, 0x07, 0x00, 0x20, 0x00, 0x20, 0x01, 0xa3, 0x0b,
+	}
+	hfloat, err := chain.UploadWasm(nil, floatWasm)
+	require.NoError(t, err)
+	err = chain.DeployContract(nil, "floats", hfloat)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetWasmFloatPolicy, root.ParamFloatPolicy, 3)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetWasmFloatPolicy, root.ParamFloatPolicy, root.WasmFloatsAllow)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+}
+
+func TestResourceLimits(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	var limits struct {
+		MaxContracts int64 `codec:"$$maxcontracts$$"`
+		MaxStateSize int64 `codec:"$$maxstatesize$$"`
+		MaxBlobSize  int64 `codec:"$$maxblobsize$$"`
+	}
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetResourceLimits, &limits)
+	require.EqualValues(t, 0, limits.MaxContracts)
+	require.EqualValues(t, 0, limits.MaxStateSize)
+	require.EqualValues(t, 0, limits.MaxBlobSize)
+
+	// core contracts are not counted
+	numContracts, err := chain.CallViewInt64(root.Interface.Name, root.FuncGetResourceUsage, root.ParamNumContracts)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, numContracts)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetResourceLimits,
+		root.ParamMaxContracts, 5,
+		root.ParamMaxBlobSize, 100,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetResourceLimits, root.ParamMaxStateSize, 1000)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetResourceLimits, &limits)
+	require.EqualValues(t, 5, limits.MaxContracts)
+	require.EqualValues(t, 1000, limits.MaxStateSize)
+	require.EqualValues(t, 100, limits.MaxBlobSize)
+
+	// the blob exceeding the limit is not stored
+	_, err = chain.UploadBlob(nil, "field", make([]byte, 101))
+	require.Error(t, err)
+	_, err = chain.UploadBlob(nil, "field", make([]byte, 100))
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetResourceLimits, root.ParamMaxBlobSize, -1)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetResourceLimits)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetResourceLimits, root.ParamMaxContracts, 0)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+}
+
+func TestRecordEquivocation(t *testing.T) {
+	env := solo.New(t, false, false, solo.WithBLSCommittee(4, 3))
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	leader := chain.Committee.DKShares[1]
+	signed := func(ts int64, proposal string) root.SignedProposal {
+		h := hashing.HashStrings(proposal)
+		sig, err := leader.SignShare(root.ProposalEssence(&chain.ChainID, 7, ts, h))
+		require.NoError(t, err)
+		return root.SignedProposal{Timestamp: ts, ProposalHash: h, LeaderSig: sig}
+	}
+	ev := &root.EquivocationEvidence{
+		BlockIndex:  7,
+		LeaderIndex: 1,
+		Proposals:   [2]root.SignedProposal{signed(1000, "a"), signed(1001, "b")},
+	}
+	for _, c := range leader.PublicCommits {
+		data, err := c.MarshalBinary()
+		require.NoError(t, err)
+		ev.PublicCommits = append(ev.PublicCommits, data)
+	}
+
+	// anybody may record the evidence, it is verified by the contract
+	user := env.NewSignatureSchemeWithFunds()
+	forged := *ev
+	forged.LeaderIndex = 2
+	req := solo.NewCallParams(root.Interface.Name, root.FuncRecordEquivocation, root.ParamEvidence, root.EncodeEquivocationEvidence(&forged))
+	_, err := chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncRecordEquivocation, root.ParamEvidence, root.EncodeEquivocationEvidence(ev))
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+	// recorded only once
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	res, err := chain.CallView(root.Interface.Name, root.FuncGetEquivocations)
+	require.NoError(t, err)
+	arr := collections.NewArrayReadOnly(res, root.ParamEvidence)
+	require.EqualValues(t, 1, arr.MustLen())
+	back, err := root.DecodeEquivocationEvidence(arr.MustGetAt(0))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, back.LeaderIndex)
+	require.EqualValues(t, ev.Proposals, back.Proposals)
+}
+
+func TestBlockInterval(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	var interval struct {
+		Min int64 `codec:"$$minblockinterval$$"`
+		Max int64 `codec:"$$maxblockinterval$$"`
+	}
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetBlockInterval, &interval)
+	require.EqualValues(t, 0, interval.Min)
+	require.EqualValues(t, 0, interval.Max)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetBlockInterval,
+		root.ParamMinInterval, 500,
+		root.ParamMaxInterval, 60000,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetBlockInterval, &interval)
+	require.EqualValues(t, 500, interval.Min)
+	require.EqualValues(t, 60000, interval.Max)
+
+	// empty blocks can't be produced more often than root.MinEmptyBlockInterval
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetBlockInterval, root.ParamMaxInterval, 10)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetBlockInterval,
+		root.ParamMinInterval, 5000,
+		root.ParamMaxInterval, 2000,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	user := env.NewSignatureSchemeWithFunds()
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetBlockInterval, root.ParamMinInterval, 0)
+	_, err = chain.PostRequestSync(req, user)
+	require.Error(t, err)
+
+	// skipped parameters restore the defaults
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetBlockInterval)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetBlockInterval, &interval)
+	require.EqualValues(t, 0, interval.Min)
+	require.EqualValues(t, 0, interval.Max)
+}
+
+func TestAnchorFeePolicy(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	sponsor := env.NewSignatureSchemeWithFunds()
+	sponsorAgentID := coretypes.NewAgentIDFromAddress(sponsor.Address())
+	target := env.NewSignatureScheme().Address()
+
+	req := solo.NewCallParams(accounts.Interface.Name, accounts.FuncDeposit).WithTransfer(balance.ColorIOTA, 42)
+	_, err := chain.PostRequestSync(req, sponsor)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(sponsorAgentID, balance.ColorIOTA, 42+1)
+
+	// the sponsor and the fee target are mandatory
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetAnchorFeePolicy,
+		root.ParamAnchorFee, 10,
+		root.ParamFeePayer, root.AnchorFeePayerSponsor,
+		root.ParamFeeTarget, target,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetAnchorFeePolicy,
+		root.ParamAnchorFee, 10,
+		root.ParamFeePayer, root.AnchorFeePayerSponsor,
+		root.ParamSponsor, sponsor.Address(),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetAnchorFeePolicy,
+		root.ParamAnchorFee, 10,
+		root.ParamFeePayer, root.AnchorFeePayerSponsor,
+		root.ParamSponsor, sponsor.Address(),
+		root.ParamFeeTarget, target,
+	)
+	_, err = chain.PostRequestSync(req, sponsor)
+	require.Error(t, err)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	ret, err := chain.CallView(root.Interface.Name, root.FuncGetAnchorFeePolicy)
+	require.NoError(t, err)
+	fee, _, err := codec.DecodeInt64(ret.MustGet(root.ParamAnchorFee))
+	require.NoError(t, err)
+	require.EqualValues(t, 10, fee)
+	payer, _, err := codec.DecodeInt64(ret.MustGet(root.ParamFeePayer))
+	require.NoError(t, err)
+	require.EqualValues(t, root.AnchorFeePayerSponsor, payer)
+
+	// the next block pays the fee from the account of the sponsor to the target address
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetUtilityPrice, root.ParamUtilityPrice, 0)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	chain.AssertAccountBalance(sponsorAgentID, balance.ColorIOTA, 42+1-10)
+	env.AssertAddressBalance(target, balance.ColorIOTA, 10)
+
+	ret, err = chain.CallView(accounts.Interface.Name, accounts.FuncGetAnchorFeesPaid, accounts.ParamAgentID, sponsorAgentID)
+	require.NoError(t, err)
+	paid, _, err := codec.DecodeInt64(ret.MustGet(accounts.ParamAmount))
+	require.NoError(t, err)
+	require.EqualValues(t, 10, paid)
+	chain.CheckAccountLedger()
+}
+
+// findHnameCollision finds two contract names with the same hname
+func findHnameCollision() (string, string) {
+	names := make(map[coretypes.Hname]string)
+	for i := 0; ; i++ {
+		name := fmt.Sprintf("contract%d", i)
+		hname := coretypes.Hn(name)
+		if prev, ok := names[hname]; ok {
+			return prev, name
+		}
+		names[hname] = name
+	}
+}
+
+func TestHnameCollision(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	first, second := findHnameCollision()
+	require.EqualValues(t, coretypes.Hn(first), coretypes.Hn(second))
+
+	err := chain.DeployContract(nil, first, sbtestsc.Interface.ProgramHash)
+	require.NoError(t, err)
+
+	err = chain.DeployContract(nil, second, sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hname collision")
+	require.EqualValues(t, coretypes.VMErrorInvalidCall, coretypes.VMErrorCodeOf(err))
+
+	// the alias can't be the hname of another contract either
+	err = chain.DeployContract(nil, second, sbtestsc.Interface.ProgramHash, root.ParamAlias, accounts.Interface.Hname())
+	require.Error(t, err)
+
+	alias := coretypes.Hn("alias of " + second)
+	err = chain.DeployContract(nil, second, sbtestsc.Interface.ProgramHash, root.ParamAlias, alias)
+	require.NoError(t, err)
+
+	err = chain.DeployContract(nil, second, sbtestsc.Interface.ProgramHash, root.ParamAlias, coretypes.Hn("another alias"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "already exist")
+
+	// both contracts are resolved by name
+	rec, err := chain.FindContract(first)
+	require.NoError(t, err)
+	require.EqualValues(t, first, rec.Name)
+	require.EqualValues(t, coretypes.Hn(first), rec.Hname())
+	rec, err = chain.FindContract(second)
+	require.NoError(t, err)
+	require.EqualValues(t, second, rec.Name)
+	require.EqualValues(t, alias, rec.Hname())
+
+	// and by hname
+	_, contracts := chain.GetInfo()
+	require.EqualValues(t, first, contracts[coretypes.Hn(first)].Name)
+	require.EqualValues(t, second, contracts[alias].Name)
+
+	res, err := chain.CallView(root.Interface.Name, root.FuncFindContractByName, root.ParamName, second)
+	require.NoError(t, err)
+	hname, _, err := codec.DecodeHname(res.MustGet(root.ParamHname))
+	require.NoError(t, err)
+	require.EqualValues(t, alias, hname)
+
+	// the contract under the alias is a separate instance
+	_, err = chain.PostRequestSync(solo.NewCallParams(second, sbtestsc.FuncIncCounter).WithTargetHname(alias), nil)
+	require.NoError(t, err)
+	res, err = chain.CallViewByHname(alias, coretypes.Hn(sbtestsc.FuncGetCounter), nil)
+	require.NoError(t, err)
+	counter, _, err := codec.DecodeInt64(res.MustGet(sbtestsc.VarCounter))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, counter)
+	res, err = chain.CallView(first, sbtestsc.FuncGetCounter)
+	require.NoError(t, err)
+	counter, _, err = codec.DecodeInt64(res.MustGet(sbtestsc.VarCounter))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, counter)
+}
+
+func TestDeployContractsHnameCollision(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	first, second := findHnameCollision()
+	err := chain.DeployContracts(nil,
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: first},
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: second},
+	)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "hname collision")
+
+	alias := coretypes.Hn("alias of " + second)
+	err = chain.DeployContracts(nil,
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: first},
+		&root.ContractDeployment{ProgramHash: sbtestsc.Interface.ProgramHash, Name: second, Alias: alias},
+	)
+	require.NoError(t, err)
+	rec, err := chain.FindContract(second)
+	require.NoError(t, err)
+	require.EqualValues(t, alias, rec.Hname())
+}
+
+func TestExecutionUnits(t *testing.T) {
+	env := solo.New(t, false, false)
+	chain := env.NewChain(nil, "chain1")
+	defer chain.WaitForEmptyBacklog()
+
+	var info struct {
+		BlockIndex int64 `codec:"$$blockindex$$"`
+		ExecUnits  int64 `codec:"$$execunits$$"`
+		TotalUnits int64 `codec:"$$totalunits$$"`
+	}
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetExecutionUnits, &info)
+	require.EqualValues(t, chain.State.BlockIndex(), info.BlockIndex)
+	require.True(t, info.ExecUnits > 0)
+	require.True(t, info.TotalUnits >= info.ExecUnits)
+	totalBefore := info.TotalUnits
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetUtilityPrice, root.ParamUtilityPrice, 10)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetExecutionUnits, &info)
+	require.EqualValues(t, chain.State.BlockIndex(), info.BlockIndex)
+	require.True(t, info.ExecUnits > 0)
+	require.EqualValues(t, totalBefore+info.ExecUnits, info.TotalUnits)
+	units := info.ExecUnits
+
+	// units of the earlier block are kept
+	chain.MustCallViewDecode(root.Interface.Name, root.FuncGetExecutionUnits, &info,
+		root.ParamBlockIndex, chain.State.BlockIndex()-1)
+	require.EqualValues(t, chain.State.BlockIndex()-1, info.BlockIndex)
+	require.True(t, info.ExecUnits > 0)
+
+	_, err = chain.CallView(root.Interface.Name, root.FuncGetExecutionUnits, root.ParamBlockIndex, chain.State.BlockIndex()+1)
+	require.Error(t, err)
+
+	// the units are deterministic: the same request on the same state consumes the same units
+	chain2 := env.NewChain(nil, "chain2")
+	defer chain2.WaitForEmptyBacklog()
+	_, err = chain2.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	units2, err := chain2.CallViewInt64(root.Interface.Name, root.FuncGetExecutionUnits, root.ParamExecUnits)
+	require.NoError(t, err)
+	require.EqualValues(t, units, units2)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -53,6 +54,121 @@ func testCallRecursive(t *testing.T, w bool) {
 	require.EqualValues(t, 32, r)
 }
 
+func TestCallRecursiveMaxDepth(t *testing.T) { run2(t, testCallRecursiveMaxDepth) }
+func testCallRecursiveMaxDepth(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	cID, _ := setupTestSandboxSC(t, chain, nil, w)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetMaxCallDepth, root.ParamMaxCallDepth, 10)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncCallOnChain,
+		sbtestsc.ParamIntParamValue, 31,
+		sbtestsc.ParamHnameContract, cID.Hname(),
+		sbtestsc.ParamHnameEP, coretypes.Hn(sbtestsc.FuncRunRecursion),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncCallOnChain,
+		sbtestsc.ParamIntParamValue, 3,
+		sbtestsc.ParamHnameContract, cID.Hname(),
+		sbtestsc.ParamHnameEP, coretypes.Hn(sbtestsc.FuncRunRecursion),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+}
+
+func TestCallReentrancyLock(t *testing.T) { run2(t, testCallReentrancyLock) }
+func testCallReentrancyLock(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	cID, _ := setupTestSandboxSC(t, chain, nil, w)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetReentrancyLock, root.ParamHname, cID.Hname())
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncCallOnChain,
+		sbtestsc.ParamIntParamValue, 1,
+		sbtestsc.ParamHnameContract, cID.Hname(),
+		sbtestsc.ParamHnameEP, coretypes.Hn(sbtestsc.FuncRunRecursion),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetReentrancyLock,
+		root.ParamHname, cID.Hname(),
+		root.ParamLocked, 0,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncCallOnChain,
+		sbtestsc.ParamIntParamValue, 1,
+		sbtestsc.ParamHnameContract, cID.Hname(),
+		sbtestsc.ParamHnameEP, coretypes.Hn(sbtestsc.FuncRunRecursion),
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+}
+
+func TestCallPausedContract(t *testing.T) { run2(t, testCallPausedContract) }
+func testCallPausedContract(t *testing.T, w bool) {
+	env, chain := setupChain(t, nil)
+	cID, _ := setupTestSandboxSC(t, chain, nil, w)
+
+	pauser := env.NewSignatureSchemeWithFunds()
+	req := solo.NewCallParams(root.Interface.Name, root.FuncPauseContract, root.ParamHname, cID.Hname())
+	_, err := chain.PostRequestSync(req, pauser)
+	require.Error(t, err)
+	require.EqualValues(t, coretypes.VMErrorUnauthorized, coretypes.VMErrorCodeOf(err))
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetPauser,
+		root.ParamPauser, coretypes.NewAgentIDFromAddress(pauser.Address()))
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncPauseContract, root.ParamHname, cID.Hname())
+	_, err = chain.PostRequestSync(req, pauser)
+	require.NoError(t, err)
+
+	ret, err := chain.CallView(root.Interface.Name, root.FuncGetPauseInfo, root.ParamHname, cID.Hname())
+	require.NoError(t, err)
+	paused, _, err := codec.DecodeInt64(ret.MustGet(root.ParamPaused))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, paused)
+
+	// full entry points are rejected, both from the request and from another contract
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncSetInt,
+		sbtestsc.ParamIntParamName, "ppp",
+		sbtestsc.ParamIntParamValue, 314,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	require.EqualValues(t, coretypes.VMErrorContractPaused, coretypes.VMErrorCodeOf(err))
+
+	// views still work
+	_, err = chain.CallView(SandboxSCName, sbtestsc.FuncGetFibonacci, sbtestsc.ParamIntParamValue, n)
+	require.NoError(t, err)
+
+	// core contracts can't be paused
+	req = solo.NewCallParams(root.Interface.Name, root.FuncPauseContract, root.ParamHname, root.Interface.Hname())
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncUnpauseContract, root.ParamHname, cID.Hname())
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncSetInt,
+		sbtestsc.ParamIntParamName, "ppp",
+		sbtestsc.ParamIntParamValue, 314,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+}
+
 const n = 10
 
 func fibo(n int64) int64 {
@@ -2,10 +2,14 @@ package sbtests
 
 import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/crypto/ed25519"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -87,3 +91,154 @@ func testMintedSupplyOk(t *testing.T, w bool) {
 	require.True(t, ok)
 	require.EqualValues(t, supply, supplyBack)
 }
+
+func TestRequestMetadata(t *testing.T) { run2(t, testRequestMetadata, true) }
+func testRequestMetadata(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+
+	user := setupDeployer(t, chain)
+	setupTestSandboxSC(t, chain, user, w)
+
+	req := solo.NewCallParams(sbtestsc.Interface.Name, sbtestsc.FuncGetRequestMetadata)
+	tx, ret, err := chain.PostRequestSyncTx(req, user)
+	require.NoError(t, err)
+
+	blockIndex, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarBlockIndex))
+	require.NoError(t, err)
+	require.EqualValues(t, chain.State.BlockIndex(), blockIndex)
+
+	reqIndex, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarRequestIndex))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, reqIndex)
+
+	batchSize, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarBatchSize))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, batchSize)
+
+	blockTs, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarBlockTimestamp))
+	require.NoError(t, err)
+	ts, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarTimestamp))
+	require.NoError(t, err)
+	require.EqualValues(t, blockTs+reqIndex, ts)
+
+	txid, _, err := codec.DecodeHashValue(ret.MustGet(sbtestsc.VarRequestTxID))
+	require.NoError(t, err)
+	require.EqualValues(t, tx.ID(), txid)
+
+	sender, _, err := codec.DecodeAddress(ret.MustGet(sbtestsc.VarRequestSender))
+	require.NoError(t, err)
+	require.EqualValues(t, user.Address(), sender)
+}
+
+func TestRequestTransfer(t *testing.T) { run2(t, testRequestTransfer, true) }
+func testRequestTransfer(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+
+	user := setupDeployer(t, chain)
+	cID, _ := setupTestSandboxSC(t, chain, user, w)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetContractFee,
+		root.ParamHname, cID.Hname(),
+		root.ParamOwnerFee, 10,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(sbtestsc.Interface.Name, sbtestsc.FuncGetRequestTransfer).
+		WithTransfer(balance.ColorIOTA, 42)
+	tx, ret, err := chain.PostRequestSyncTx(req, user)
+	require.NoError(t, err)
+
+	outputID, _, err := valuetransaction.OutputIDFromBytes(ret.MustGet(sbtestsc.VarRequestOutput))
+	require.NoError(t, err)
+	require.EqualValues(t, tx.ID(), outputID.TransactionID())
+	require.EqualValues(t, address.Address(chain.ChainID), outputID.Address())
+
+	declared, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarDeclaredIotas))
+	require.NoError(t, err)
+	require.EqualValues(t, 42, declared)
+
+	fees, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarFeeIotas))
+	require.NoError(t, err)
+	require.EqualValues(t, 10, fees)
+
+	deposit, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarDeposit))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, deposit)
+
+	dustAdded, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarDustAdded))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, dustAdded)
+
+	incoming, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarIncomingIotas))
+	require.NoError(t, err)
+	require.EqualValues(t, declared-fees-deposit, incoming)
+}
+
+func TestVRF(t *testing.T) {
+	t.Run("BLS committee", func(t *testing.T) {
+		env := solo.New(t, DEBUG, false, solo.WithBLSCommittee(4, 3))
+		chain := env.NewChain(nil, "ch1")
+		// the VRF is signed by the committee of the chain
+		require.EqualValues(t, chain.ChainAddress, chain.VRFAddress())
+		testVRF(t, chain)
+	})
+	t.Run("ED25519 chain", func(t *testing.T) {
+		env := solo.New(t, DEBUG, false)
+		testVRF(t, env.NewChain(nil, "ch1"))
+	})
+}
+
+func testVRF(t *testing.T, chain *solo.Chain) {
+	user := setupDeployer(t, chain)
+	setupTestSandboxSC(t, chain, user, false)
+
+	getVRF := func() coretypes.VRF {
+		req := solo.NewCallParams(sbtestsc.Interface.Name, sbtestsc.FuncGetVRF)
+		ret, err := chain.PostRequestSync(req, user)
+		require.NoError(t, err)
+		output, _, err := codec.DecodeHashValue(ret.MustGet(sbtestsc.VarVRF))
+		require.NoError(t, err)
+		prevStateHash, _, err := codec.DecodeHashValue(ret.MustGet(sbtestsc.VarVRFPrevStateHash))
+		require.NoError(t, err)
+		blockIndex, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarVRFBlockIndex))
+		require.NoError(t, err)
+		ts, _, err := codec.DecodeInt64(ret.MustGet(sbtestsc.VarVRFTimestamp))
+		require.NoError(t, err)
+		return coretypes.VRF{
+			Output:        output,
+			PrevStateHash: prevStateHash,
+			BlockIndex:    uint32(blockIndex),
+			Timestamp:     ts,
+			Proof:         ret.MustGet(sbtestsc.VarVRFProof),
+		}
+	}
+
+	vrfAddress := chain.VRFAddress()
+	prevStateHash := chain.State.Hash()
+	prevBlockIndex := chain.State.BlockIndex()
+	vrf := getVRF()
+	require.NoError(t, vrf.Verify(&vrfAddress))
+	// the VRF is bound to the previous state and to the index of the block
+	require.EqualValues(t, prevStateHash, vrf.PrevStateHash)
+	require.EqualValues(t, prevBlockIndex+1, vrf.BlockIndex)
+	// only the committee can sign it
+	require.Error(t, vrf.Verify(&chain.OriginatorAddress))
+
+	tampered := vrf
+	tampered.Output[0]++
+	require.Error(t, tampered.Verify(&vrfAddress))
+	tampered = vrf
+	tampered.Timestamp++
+	require.Error(t, tampered.Verify(&vrfAddress))
+
+	next := getVRF()
+	require.NoError(t, next.Verify(&vrfAddress))
+	require.NotEqual(t, vrf.Output, next.Output)
+
+	// the Ed25519 signature, like the FROST signature of the anchor transaction, is not unique,
+	// so it is not accepted as the proof
+	sig := signaturescheme.ED25519(ed25519.GenerateKeyPair()).Sign(vrf.Seed())
+	_, err := coretypes.NewVRF(vrf.PrevStateHash, vrf.BlockIndex, vrf.Timestamp, sig)
+	require.Error(t, err)
+}
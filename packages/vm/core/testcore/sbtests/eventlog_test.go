@@ -239,3 +239,147 @@ func testChainLogMultiple(t *testing.T, w bool) {
 	require.EqualValues(t, 1, strings.Count(strTest, "[Event]"))
 	require.EqualValues(t, 1, strings.Count(strTest, "33333"))
 }
+
+func TestEventLogRetentionMaxRecords(t *testing.T) { run2(t, testEventLogRetentionMaxRecords) }
+func testEventLogRetentionMaxRecords(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetEventLogRetention,
+		root.ParamHname, coretypes.Hn(SandboxSCName),
+		root.ParamMaxRecords, 3,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	for i := 1; i < 6; i++ {
+		req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncEventLogGenericData,
+			sbtestsc.VarCounter, i,
+		)
+		_, err := chain.PostRequestSync(req, nil)
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 3, chain.GetEventLogNumRecords(SandboxSCName))
+
+	recs, err := chain.GetEventLogRecords(SandboxSCName)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, len(recs))
+}
+
+func TestEventLogRetentionMaxAge(t *testing.T) { run2(t, testEventLogRetentionMaxAge) }
+func testEventLogRetentionMaxAge(t *testing.T, w bool) {
+	env, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	// default retention of the chain
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetEventLogRetention,
+		root.ParamMaxAge, 1,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	env.SetTimeStep(500 * time.Millisecond)
+	for i := 1; i < 6; i++ {
+		req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncEventLogGenericData,
+			sbtestsc.VarCounter, i,
+		)
+		_, err := chain.PostRequestSync(req, nil)
+		require.NoError(t, err)
+	}
+	recs, err := chain.GetEventLogRecords(SandboxSCName)
+	require.NoError(t, err)
+	require.NotEmpty(t, recs)
+	for _, rec := range recs {
+		require.True(t, rec.Timestamp >= chain.State.Timestamp()-int64(time.Second))
+	}
+}
+
+func TestEventLogRetentionMaxAgeTooLarge(t *testing.T) { run2(t, testEventLogRetentionMaxAgeTooLarge) }
+func testEventLogRetentionMaxAgeTooLarge(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetEventLogRetention,
+		root.ParamMaxAge, root.MaxEventLogRetentionAge+1,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetEventLogRetention,
+		root.ParamMaxAge, root.MaxEventLogRetentionAge,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncEventLogGenericData,
+		sbtestsc.VarCounter, 1,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, chain.GetEventLogNumRecords(SandboxSCName))
+}
+
+func TestEventLogTypedEvents(t *testing.T) { run2(t, testEventLogTypedEvents, true) }
+func testEventLogTypedEvents(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	for i := 1; i < 6; i++ {
+		req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncEventLogTypedEvent,
+			sbtestsc.VarCounter, i%2,
+		)
+		_, err := chain.PostRequestSync(req, nil)
+		require.NoError(t, err)
+	}
+	// typed events are not in the free-form log
+	require.EqualValues(t, 0, chain.GetEventLogNumRecords(SandboxSCName))
+
+	events, err := chain.GetEvents(SandboxSCName, sbtestsc.EventTopicCounter)
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+	for _, ev := range events {
+		require.EqualValues(t, sbtestsc.EventTopicCounter, ev.Topic)
+		require.True(t, ev.Fields.MustHas(sbtestsc.ParamCaller))
+	}
+	// latest first
+	counter, _, err := codec.DecodeInt64(events[0].Fields.MustGet(sbtestsc.VarCounter))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, counter)
+
+	events, err = chain.GetEvents(SandboxSCName, sbtestsc.EventTopicCounter, sbtestsc.VarCounter, 0)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	for _, ev := range events {
+		counter, _, err := codec.DecodeInt64(ev.Fields.MustGet(sbtestsc.VarCounter))
+		require.NoError(t, err)
+		require.EqualValues(t, 0, counter)
+	}
+
+	events, err = chain.GetEvents(SandboxSCName, "unknown topic")
+	require.NoError(t, err)
+	require.Len(t, events, 0)
+}
+
+func TestEventLogTypedEventsRetention(t *testing.T) { run2(t, testEventLogTypedEventsRetention, true) }
+func testEventLogTypedEventsRetention(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetEventLogRetention,
+		root.ParamMaxRecords, 2,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	for i := 1; i < 6; i++ {
+		req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncEventLogTypedEvent,
+			sbtestsc.VarCounter, 7,
+		)
+		_, err := chain.PostRequestSync(req, nil)
+		require.NoError(t, err)
+	}
+	// index entries of pruned events are skipped
+	events, err := chain.GetEvents(SandboxSCName, sbtestsc.EventTopicCounter, sbtestsc.VarCounter, 7)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+}
@@ -0,0 +1,95 @@
+package sbtests
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
+	"github.com/stretchr/testify/require"
+)
+
+func getStateUsage(t *testing.T, chain *solo.Chain) int64 {
+	ret, err := chain.CallView(root.Interface.Name, root.FuncGetResourceUsage,
+		root.ParamHname, coretypes.Hn(SandboxSCName))
+	require.NoError(t, err)
+	size, _, err := codec.DecodeInt64(ret.MustGet(root.ParamStateSize))
+	require.NoError(t, err)
+	return size
+}
+
+func TestStateSizeLimit(t *testing.T) { run2(t, testStateSizeLimit) }
+func testStateSizeLimit(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+	setupTestSandboxSC(t, chain, nil, w)
+
+	req := solo.NewCallParams(SandboxSCName, sbtestsc.FuncSetInt,
+		sbtestsc.ParamIntParamName, "ppp",
+		sbtestsc.ParamIntParamValue, 314,
+	)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	usage := getStateUsage(t, chain)
+	require.True(t, usage >= int64(len("ppp")+8))
+
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetResourceLimits, root.ParamMaxStateSize, usage+10)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	// overwriting the value doesn't increase the state
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncSetInt,
+		sbtestsc.ParamIntParamName, "ppp",
+		sbtestsc.ParamIntParamValue, 42,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, usage, getStateUsage(t, chain))
+
+	// the new key exceeds the limit, the request is reverted
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncSetInt,
+		sbtestsc.ParamIntParamName, "qqq",
+		sbtestsc.ParamIntParamValue, 1,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.Error(t, err)
+	require.EqualValues(t, usage, getStateUsage(t, chain))
+	ret, err := chain.CallView(SandboxSCName, sbtestsc.FuncGetInt, sbtestsc.ParamIntParamName, "qqq")
+	require.NoError(t, err)
+	v, _, err := codec.DecodeInt64(ret.MustGet("qqq"))
+	require.NoError(t, err)
+	require.EqualValues(t, 0, v)
+
+	// removing the limit
+	req = solo.NewCallParams(root.Interface.Name, root.FuncSetResourceLimits, root.ParamMaxStateSize, 0)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncSetInt,
+		sbtestsc.ParamIntParamName, "qqq",
+		sbtestsc.ParamIntParamValue, 1,
+	)
+	_, err = chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+	require.EqualValues(t, usage+int64(len("qqq")+8), getStateUsage(t, chain))
+}
+
+func TestMaxContracts(t *testing.T) { run2(t, testMaxContracts) }
+func testMaxContracts(t *testing.T, w bool) {
+	_, chain := setupChain(t, nil)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetResourceLimits, root.ParamMaxContracts, 1)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	setupTestSandboxSC(t, chain, nil, w)
+	numContracts, err := chain.CallViewInt64(root.Interface.Name, root.FuncGetResourceUsage, root.ParamNumContracts)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, numContracts)
+
+	err = chain.DeployContract(nil, "another", sbtestsc.Interface.ProgramHash)
+	require.Error(t, err)
+	_, contracts := chain.GetInfo()
+	_, ok := contracts[coretypes.Hn("another")]
+	require.False(t, ok)
+}
@@ -29,6 +29,23 @@ func testEventLogGenericData(ctx coretypes.Sandbox) (dict.Dict, error) {
 	return nil, nil
 }
 
+// testEventLogTypedEvent emits the typed event with the counter value as an indexed field
+func testEventLogTypedEvent(ctx coretypes.Sandbox) (dict.Dict, error) {
+	params := ctx.Params()
+	inc, ok, err := codec.DecodeInt64(params.MustGet(VarCounter))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		inc = 1
+	}
+	fields := dict.New()
+	fields.Set(VarCounter, codec.EncodeInt64(inc))
+	fields.Set(ParamCaller, codec.EncodeAgentID(ctx.Caller()))
+	ctx.EmitEvent(EventTopicCounter, fields, VarCounter)
+	return nil, nil
+}
+
 func testEventLogEventData(ctx coretypes.Sandbox) (dict.Dict, error) {
 	ctx.Event("[Event] - Testing Event...")
 	return nil, nil
@@ -0,0 +1,49 @@
+package sbtestsc
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+)
+
+func getRequestMetadata(ctx coretypes.Sandbox) (dict.Dict, error) {
+	md := ctx.RequestMetadata()
+	ret := dict.New()
+	ret.Set(VarBlockIndex, codec.EncodeInt64(int64(md.BlockIndex)))
+	ret.Set(VarBlockTimestamp, codec.EncodeInt64(md.BlockTimestamp))
+	ret.Set(VarRequestIndex, codec.EncodeInt64(int64(md.RequestIndex)))
+	ret.Set(VarBatchSize, codec.EncodeInt64(int64(md.BatchSize)))
+	ret.Set(VarRequestTxID, codec.EncodeHashValue(hashing.HashValue(md.TransactionID)))
+	ret.Set(VarRequestSender, codec.EncodeAddress(md.SenderAddress))
+	ret.Set(VarTimestamp, codec.EncodeInt64(ctx.GetTimestamp()))
+	return ret, nil
+}
+
+func getRequestTransfer(ctx coretypes.Sandbox) (dict.Dict, error) {
+	rt := ctx.RequestTransfer()
+	dustAdded := int64(0)
+	if rt.DustAdded {
+		dustAdded = 1
+	}
+	ret := dict.New()
+	ret.Set(VarRequestOutput, rt.OutputID.Bytes())
+	ret.Set(VarDeclaredIotas, codec.EncodeInt64(rt.Declared.Balance(balance.ColorIOTA)))
+	ret.Set(VarFeeIotas, codec.EncodeInt64(rt.Fees.Balance(balance.ColorIOTA)))
+	ret.Set(VarDeposit, codec.EncodeInt64(rt.Deposit))
+	ret.Set(VarDustAdded, codec.EncodeInt64(dustAdded))
+	ret.Set(VarIncomingIotas, codec.EncodeInt64(ctx.IncomingTransfer().Balance(balance.ColorIOTA)))
+	return ret, nil
+}
+
+func getVRF(ctx coretypes.Sandbox) (dict.Dict, error) {
+	vrf := ctx.VRF()
+	ret := dict.New()
+	ret.Set(VarVRF, codec.EncodeHashValue(vrf.Output))
+	ret.Set(VarVRFPrevStateHash, codec.EncodeHashValue(vrf.PrevStateHash))
+	ret.Set(VarVRFBlockIndex, codec.EncodeInt64(int64(vrf.BlockIndex)))
+	ret.Set(VarVRFTimestamp, codec.EncodeInt64(vrf.Timestamp))
+	ret.Set(VarVRFProof, vrf.Proof)
+	return ret, nil
+}
@@ -27,10 +27,14 @@ func init() {
 		coreutil.ViewFunc(FuncContractIDView, testContractIDView),
 		coreutil.Func(FuncContractIDFull, testContractIDFull),
 		coreutil.Func(FuncGetMintedSupply, getMintedSupply),
+		coreutil.Func(FuncGetRequestMetadata, getRequestMetadata),
+		coreutil.Func(FuncGetRequestTransfer, getRequestTransfer),
+		coreutil.Func(FuncGetVRF, getVRF),
 
 		coreutil.Func(FuncEventLogGenericData, testEventLogGenericData),
 		coreutil.Func(FuncEventLogEventData, testEventLogEventData),
 		coreutil.Func(FuncEventLogDeploy, testEventLogDeploy),
+		coreutil.Func(FuncEventLogTypedEvent, testEventLogTypedEvent),
 		coreutil.ViewFunc(FuncSandboxCall, testSandboxCall),
 
 		coreutil.Func(FuncPanicFullEP, testPanicFullEP),
@@ -66,6 +70,9 @@ const (
 	FuncEventLogGenericData = "testEventLogGenericData"
 	FuncEventLogEventData   = "testEventLogEventData"
 	FuncEventLogDeploy      = "testEventLogDeploy"
+	FuncEventLogTypedEvent  = "testEventLogTypedEvent"
+
+	EventTopicCounter = "counter"
 
 	//Function sandbox test
 	FuncChainOwnerIDView = "testChainOwnerIDView"
@@ -77,6 +84,9 @@ const (
 	FuncCheckContextFromFullEP = "checkContextFromFullEP"
 	FuncCheckContextFromViewEP = "checkContextFromViewEP"
 	FuncGetMintedSupply        = "getMintedSupply"
+	FuncGetRequestMetadata     = "getRequestMetadata"
+	FuncGetRequestTransfer     = "getRequestTransfer"
+	FuncGetVRF                 = "getVRF"
 
 	FuncPanicFullEP             = "testPanicFullEP"
 	FuncPanicViewEP             = "testPanicViewEP"
@@ -107,6 +117,24 @@ const (
 	VarSandboxCall          = "sandboxCall"
 	VarContractNameDeployed = "exampleDeployTR"
 	VarMintedSupply         = "mintedSupply"
+	VarBlockIndex           = "blockIndex"
+	VarBlockTimestamp       = "blockTimestamp"
+	VarRequestIndex         = "requestIndex"
+	VarBatchSize            = "batchSize"
+	VarRequestTxID          = "requestTxID"
+	VarRequestSender        = "requestSender"
+	VarTimestamp            = "timestamp"
+	VarRequestOutput        = "requestOutput"
+	VarDeclaredIotas        = "declaredIotas"
+	VarFeeIotas             = "feeIotas"
+	VarDeposit              = "deposit"
+	VarDustAdded            = "dustAdded"
+	VarIncomingIotas        = "incomingIotas"
+	VarVRF                  = "vrf"
+	VarVRFPrevStateHash     = "vrfPrevStateHash"
+	VarVRFBlockIndex        = "vrfBlockIndex"
+	VarVRFTimestamp         = "vrfTimestamp"
+	VarVRFProof             = "vrfProof"
 
 	// parameters
 	ParamFail            = "initFailParam"
@@ -1,9 +1,11 @@
 package sbtests
 
 import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
 	"github.com/iotaledger/wasp/packages/vm/core/testcore/sbtests/sbtestsc"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -51,3 +53,42 @@ func testTypesView(t *testing.T, w bool) {
 	)
 	require.NoError(t, err)
 }
+
+func TestTypesFullUtilityFee(t *testing.T) { run2(t, testTypesFullUtilityFee) }
+func testTypesFullUtilityFee(t *testing.T, w bool) {
+	env, chain := setupChain(t, nil)
+	cID, _ := setupTestSandboxSC(t, chain, nil, w)
+
+	req := solo.NewCallParams(root.Interface.Name, root.FuncSetUtilityPrice, root.ParamUtilityPrice, 1)
+	_, err := chain.PostRequestSync(req, nil)
+	require.NoError(t, err)
+
+	ownerBefore := chain.GetAccountBalance(chain.OriginatorAgentID).Balance(balance.ColorIOTA)
+	contractBefore := chain.GetAccountBalance(coretypes.NewAgentIDFromContractID(cID)).Balance(balance.ColorIOTA)
+
+	user := env.NewSignatureSchemeWithFunds()
+	req = solo.NewCallParams(SandboxSCName, sbtestsc.FuncPassTypesFull,
+		"string", "string",
+		"string-0", "",
+		"int64", 42,
+		"int64-0", 0,
+		"Hash", hashing.HashStrings("Hash"),
+		"Hname", coretypes.Hn("Hname"),
+		"Hname-0", coretypes.Hname(0),
+		"ContractID", cID,
+		"ChainID", chain.ChainID,
+		"Address", chain.ChainAddress,
+		"AgentID", chain.OriginatorAgentID,
+	).WithTransfer(balance.ColorIOTA, 1000)
+	_, err = chain.PostRequestSync(req, user)
+	require.NoError(t, err)
+
+	// only wasm contracts call host utility functions, the core version is free
+	fee := chain.GetAccountBalance(chain.OriginatorAgentID).Balance(balance.ColorIOTA) - ownerBefore
+	if w {
+		require.True(t, fee > 0)
+	} else {
+		require.EqualValues(t, 0, fee)
+	}
+	chain.AssertAccountBalance(coretypes.NewAgentIDFromContractID(cID), balance.ColorIOTA, contractBefore+1000-fee)
+}
@@ -1,16 +1,33 @@
 package vm
 
 import (
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/publisher"
 )
 
+// Event is the event emitted by the contract while the request is run
+type Event struct {
+	Contract coretypes.Hname
+	// Message is the message of Sandbox.Event, empty for typed events
+	Message string
+	// Topic and Fields are of the typed event of Sandbox.EmitEvent
+	Topic  string
+	Fields dict.Dict
+}
+
+// EventSink receives events of contracts instead of the publisher, e.g. when the request is only simulated
+type EventSink func(ev *Event)
+
 type ContractEventPublisher struct {
 	contractID coretypes.ContractID
 	log        *logger.Logger
+	sink       EventSink
 }
 
 func NewContractEventPublisher(contractID coretypes.ContractID, log *logger.Logger) ContractEventPublisher {
@@ -20,12 +37,35 @@ func NewContractEventPublisher(contractID coretypes.ContractID, log *logger.Logg
 	}
 }
 
+// WithSink returns the publisher which passes events to the sink instead of publishing them. nil sink publishes them
+func (c ContractEventPublisher) WithSink(sink EventSink) ContractEventPublisher {
+	c.sink = sink
+	return c
+}
+
 func (c ContractEventPublisher) Publish(msg string) {
 	c.log.Info(c.contractID.String() + "/event " + msg)
+	if c.sink != nil {
+		c.sink(&Event{Contract: c.contractID.Hname(), Message: msg})
+		return
+	}
 	publisher.Publish("vmmsg", c.contractID.ChainID().String(), c.contractID.Hname().String(), msg)
 }
 
 func (c ContractEventPublisher) Publishf(format string, args ...interface{}) {
-	c.log.Infof(c.contractID.String()+"/event "+format, args...)
-	publisher.Publish("vmmsg", c.contractID.ChainID().String(), c.contractID.Hname().String(), fmt.Sprintf(format, args...))
+	c.Publish(fmt.Sprintf(format, args...))
+}
+
+// PublishEvent publishes the typed event as "vmevent" message. Values of the fields are hex encoded
+func (c ContractEventPublisher) PublishEvent(topic string, fields dict.Dict) {
+	parts := []string{c.contractID.ChainID().String(), c.contractID.Hname().String(), topic}
+	for _, k := range fields.KeysSorted() {
+		parts = append(parts, fmt.Sprintf("%s=%s", string(k), hex.EncodeToString(fields[k])))
+	}
+	c.log.Infof("%s/event %s", c.contractID.String(), strings.Join(parts[2:], " "))
+	if c.sink != nil {
+		c.sink(&Event{Contract: c.contractID.Hname(), Topic: topic, Fields: fields.Clone()})
+		return
+	}
+	publisher.Publish("vmevent", parts...)
 }
@@ -8,8 +8,13 @@ import (
 
 type VMConstructor func(binaryCode []byte) (coretypes.Processor, error)
 
+// BinaryFilter checks the program binary against the wasm float policy of the chain (see root.WasmFloatsAllow)
+// before the processor is created and returns the binary to load. It may reject or transform the binary
+type BinaryFilter func(binaryCode []byte, floatPolicy int64) ([]byte, error)
+
 var (
 	vmconstructors = make(map[string]VMConstructor)
+	binaryFilters  = make(map[string]BinaryFilter)
 	vmfactoryMutex sync.Mutex
 )
 
@@ -39,3 +44,28 @@ func NewProcessorFromBinary(vmtype string, binaryCode []byte) (coretypes.Process
 	}
 	return constructor(binaryCode)
 }
+
+// RegisterBinaryFilter registers the filter of program binaries of the VM type.
+// Binaries of VM types without a filter are loaded as they are
+func RegisterBinaryFilter(vmtype string, filter BinaryFilter) error {
+	vmfactoryMutex.Lock()
+	defer vmfactoryMutex.Unlock()
+
+	if _, ok := binaryFilters[vmtype]; ok {
+		return fmt.Errorf("duplicate binary filter of vm type '%s'", vmtype)
+	}
+	binaryFilters[vmtype] = filter
+	return nil
+}
+
+// FilterBinary prepares the program binary of the VM type for loading according to the float policy
+func FilterBinary(vmtype string, binaryCode []byte, floatPolicy int64) ([]byte, error) {
+	vmfactoryMutex.Lock()
+	filter, ok := binaryFilters[vmtype]
+	vmfactoryMutex.Unlock()
+
+	if !ok {
+		return binaryCode, nil
+	}
+	return filter(binaryCode, floatPolicy)
+}
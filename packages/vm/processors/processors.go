@@ -14,12 +14,15 @@ import (
 type ProcessorCache struct {
 	*sync.Mutex
 	processors map[hashing.HashValue]coretypes.Processor
+	// processors used for particular contracts instead of the processor of their program
+	contractProcessors map[coretypes.Hname]coretypes.Processor
 }
 
 func MustNew() *ProcessorCache {
 	ret := &ProcessorCache{
-		Mutex:      &sync.Mutex{},
-		processors: make(map[hashing.HashValue]coretypes.Processor),
+		Mutex:              &sync.Mutex{},
+		processors:         make(map[hashing.HashValue]coretypes.Processor),
+		contractProcessors: make(map[coretypes.Hname]coretypes.Processor),
 	}
 	// default builtin processor has root contract hash
 	err := ret.NewProcessor(root.Interface.ProgramHash, nil, core.VMType)
@@ -96,6 +99,38 @@ func (cps *ProcessorCache) GetOrCreateProcessorByProgramHash(progHash hashing.Ha
 	return nil, fmt.Errorf("internal error: can't get the deployed processor")
 }
 
+// GetOrCreateContractProcessor returns the processor which replaces the program of the contract,
+// if there is one, otherwise the processor of the program
+func (cps *ProcessorCache) GetOrCreateContractProcessor(contract coretypes.Hname, progHash hashing.HashValue, getBinary func(hashing.HashValue) (string, []byte, error)) (coretypes.Processor, error) {
+	cps.Lock()
+	proc, ok := cps.contractProcessors[contract]
+	cps.Unlock()
+	if ok {
+		return proc, nil
+	}
+	return cps.GetOrCreateProcessorByProgramHash(progHash, getBinary)
+}
+
+// ReplaceContractProcessor makes the contract use the processor instead of the processor of its program.
+// Other contracts deployed from the same program are not affected. nil restores the processor of the program.
+// It is used by tests to replace entry points of contracts
+func (cps *ProcessorCache) ReplaceContractProcessor(contract coretypes.Hname, proc coretypes.Processor) {
+	cps.Lock()
+	defer cps.Unlock()
+	if proc == nil {
+		delete(cps.contractProcessors, contract)
+		return
+	}
+	cps.contractProcessors[contract] = proc
+}
+
+// Len returns the number of processors of programs in the cache
+func (cps *ProcessorCache) Len() int {
+	cps.Lock()
+	defer cps.Unlock()
+	return len(cps.processors)
+}
+
 // RemoveProcessor deletes processor from cache
 func (cps *ProcessorCache) RemoveProcessor(h hashing.HashValue) {
 	cps.Lock()
@@ -70,6 +70,11 @@ func runTask(task *vm.VMTask, txb *statetxbuilder.Builder) {
 		}
 	}
 
+	if len(task.Requests) == 0 {
+		// the empty batch only moves the timestamp of the state forward, see root.GetBlockInterval
+		stateUpdates = append(stateUpdates, state.NewStateUpdate(nil).WithTimestamp(timestamp))
+	}
+
 	// create block from state updates.
 	task.ResultBlock, err = state.NewBlock(stateUpdates)
 	if err != nil {
@@ -75,6 +75,18 @@ func (s *sandbox) RequestID() coretypes.RequestID {
 	return s.vmctx.RequestID()
 }
 
+func (s *sandbox) RequestMetadata() coretypes.RequestMetadata {
+	return s.vmctx.RequestMetadata()
+}
+
+func (s *sandbox) RequestTransfer() coretypes.RequestTransfer {
+	return s.vmctx.RequestTransfer()
+}
+
+func (s *sandbox) VRF() coretypes.VRF {
+	return s.vmctx.VRF()
+}
+
 // note: MintedColor() is RequestID().TransactionID()
 func (s *sandbox) MintedSupply() int64 {
 	return s.vmctx.NumFreeMinted()
@@ -102,6 +114,20 @@ func (s *sandbox) Event(msg string) {
 	s.vmctx.EventPublisher().Publish(msg)
 }
 
+func (s *sandbox) EmitEvent(topic string, fields dict.Dict, indexed ...kv.Key) {
+	if topic == "" {
+		s.Log().Panicf("EmitEvent: topic can't be empty")
+	}
+	for _, field := range indexed {
+		if !fields.MustHas(field) {
+			s.Log().Panicf("EmitEvent: indexed field '%s' is not in the event", string(field))
+		}
+	}
+	s.Log().Infof("eventlog::%s -> topic '%s', %d field(s)", s.vmctx.CurrentContractHname(), topic, len(fields))
+	s.vmctx.StoreEventToEventLog(s.vmctx.CurrentContractHname(), topic, fields, indexed)
+	s.vmctx.EventPublisher().PublishEvent(topic, fields)
+}
+
 func (s *sandbox) IncomingTransfer() coretypes.ColoredBalances {
 	return s.vmctx.GetIncoming()
 }
@@ -30,3 +30,11 @@ func (u utilImpl) ED25519() coretypes.ED25519 {
 func (u utilImpl) BLS() coretypes.BLS {
 	return blsUtil{}
 }
+
+func (u utilImpl) Secp256k1() coretypes.Secp256k1 {
+	return secp256k1Util{}
+}
+
+func (u utilImpl) Uint256() coretypes.Uint256 {
+	return uint256Util{}
+}
@@ -6,6 +6,7 @@ package sandbox_utils
 import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"golang.org/x/crypto/sha3"
 )
 
 type hashUtil struct{}
@@ -18,6 +19,15 @@ func (u hashUtil) Sha3(data []byte) hashing.HashValue {
 	return hashing.HashSha3(data)
 }
 
+// Keccak256 is the original Keccak hash used by Ethereum, which differs from the standardized SHA3
+func (u hashUtil) Keccak256(data []byte) hashing.HashValue {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	var ret hashing.HashValue
+	copy(ret[:], h.Sum(nil))
+	return ret
+}
+
 func (u hashUtil) Hname(s string) coretypes.Hname {
 	return coretypes.Hn(s)
 }
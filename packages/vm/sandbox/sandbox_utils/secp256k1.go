@@ -0,0 +1,143 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package sandbox_utils
+
+import (
+	"math/big"
+)
+
+// parameters of the secp256k1 curve y^2 = x^3 + 7 over the prime field
+var (
+	secpP, _  = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F", 16)
+	secpN, _  = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	secpGx, _ = new(big.Int).SetString("79BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798", 16)
+	secpGy, _ = new(big.Int).SetString("483ADA7726A3C4655DA4FBFC0E1108A8FD17B448A68554199C47D08FFB10D4B8", 16)
+	secpB     = big.NewInt(7)
+)
+
+type secp256k1Util struct {
+}
+
+// secpPoint is a point of the curve in affine coordinates. nil coordinates mean the point at infinity
+type secpPoint struct {
+	x, y *big.Int
+}
+
+func (p secpPoint) isInfinity() bool {
+	return p.x == nil
+}
+
+func (u secp256k1Util) ValidSignature(hash []byte, pubKey []byte, signature []byte) bool {
+	if len(hash) != 32 || len(signature) != 64 {
+		return false
+	}
+	q, ok := secpParsePubKey(pubKey)
+	if !ok {
+		return false
+	}
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if r.Sign() == 0 || s.Sign() == 0 || r.Cmp(secpN) >= 0 || s.Cmp(secpN) >= 0 {
+		return false
+	}
+	e := new(big.Int).SetBytes(hash)
+	w := new(big.Int).ModInverse(s, secpN)
+	u1 := e.Mul(e, w)
+	u1.Mod(u1, secpN)
+	u2 := w.Mul(r, w)
+	u2.Mod(u2, secpN)
+
+	pt := secpAdd(secpScalarMult(secpPoint{secpGx, secpGy}, u1), secpScalarMult(q, u2))
+	if pt.isInfinity() {
+		return false
+	}
+	return new(big.Int).Mod(pt.x, secpN).Cmp(r) == 0
+}
+
+func secpParsePubKey(pubKey []byte) (secpPoint, bool) {
+	switch {
+	case len(pubKey) == 65 && pubKey[0] == 4:
+		x := new(big.Int).SetBytes(pubKey[1:33])
+		y := new(big.Int).SetBytes(pubKey[33:])
+		if x.Cmp(secpP) >= 0 || y.Cmp(secpP) >= 0 {
+			return secpPoint{}, false
+		}
+		// y^2 must be equal to x^3 + 7
+		lhs := new(big.Int).Mul(y, y)
+		lhs.Mod(lhs, secpP)
+		if lhs.Cmp(secpCurveRHS(x)) != 0 {
+			return secpPoint{}, false
+		}
+		return secpPoint{x, y}, true
+	case len(pubKey) == 33 && (pubKey[0] == 2 || pubKey[0] == 3):
+		x := new(big.Int).SetBytes(pubKey[1:])
+		if x.Cmp(secpP) >= 0 {
+			return secpPoint{}, false
+		}
+		y := new(big.Int).ModSqrt(secpCurveRHS(x), secpP)
+		if y == nil {
+			return secpPoint{}, false
+		}
+		if y.Bit(0) != uint(pubKey[0]&1) {
+			y.Sub(secpP, y)
+		}
+		return secpPoint{x, y}, true
+	}
+	return secpPoint{}, false
+}
+
+func secpCurveRHS(x *big.Int) *big.Int {
+	ret := new(big.Int).Mul(x, x)
+	ret.Mul(ret, x)
+	ret.Add(ret, secpB)
+	return ret.Mod(ret, secpP)
+}
+
+func secpAdd(a, b secpPoint) secpPoint {
+	if a.isInfinity() {
+		return b
+	}
+	if b.isInfinity() {
+		return a
+	}
+	var lambda *big.Int
+	if a.x.Cmp(b.x) == 0 {
+		if a.y.Cmp(b.y) != 0 || a.y.Sign() == 0 {
+			// a == -b
+			return secpPoint{}
+		}
+		// doubling: lambda = 3x^2 / 2y
+		num := new(big.Int).Mul(a.x, a.x)
+		num.Mul(num, big.NewInt(3))
+		den := new(big.Int).Lsh(a.y, 1)
+		lambda = num.Mul(num, den.ModInverse(den, secpP))
+	} else {
+		// lambda = (y2 - y1) / (x2 - x1)
+		num := new(big.Int).Sub(b.y, a.y)
+		den := new(big.Int).Sub(b.x, a.x)
+		den.Mod(den, secpP)
+		lambda = num.Mul(num, den.ModInverse(den, secpP))
+	}
+	lambda.Mod(lambda, secpP)
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, a.x)
+	x.Sub(x, b.x)
+	x.Mod(x, secpP)
+	y := new(big.Int).Sub(a.x, x)
+	y.Mul(y, lambda)
+	y.Sub(y, a.y)
+	y.Mod(y, secpP)
+	return secpPoint{x, y}
+}
+
+func secpScalarMult(p secpPoint, k *big.Int) secpPoint {
+	ret := secpPoint{}
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		ret = secpAdd(ret, ret)
+		if k.Bit(i) == 1 {
+			ret = secpAdd(ret, p)
+		}
+	}
+	return ret
+}
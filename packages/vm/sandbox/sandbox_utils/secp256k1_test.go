@@ -0,0 +1,105 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package sandbox_utils
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	ret, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return ret
+}
+
+func TestSecp256k1ValidSignature(t *testing.T) {
+	hash := decodeHex(t, "621f564e1baa5963e353063da794e47602a55f07c74bc90780995cae5dcdbad2")
+	pubKeyCompressed := decodeHex(t, "03170d37f9da5d92c82c940042982e07f6ea2459517ffdd49904ca2d1adf4e9368")
+	pubKey := decodeHex(t, "04170d37f9da5d92c82c940042982e07f6ea2459517ffdd49904ca2d1adf4e9368"+
+		"59429affbe24bc46798a27060835a55a57cc1d05e406ed6ed6f8a2b1dbb37235")
+	sig := decodeHex(t, "b7b806ca2ab0bc0fbb8cdcb85bd1c60c3d5bfa78fbc6f5ad60193e926fe621fd"+
+		"534b8f2b5b999887ae0e19eb1cd8fd0fcba1ba5c0fd631514f6df3c4dc64cdff")
+
+	u := secp256k1Util{}
+	require.True(t, u.ValidSignature(hash, pubKey, sig))
+	require.True(t, u.ValidSignature(hash, pubKeyCompressed, sig))
+
+	wrongHash := make([]byte, len(hash))
+	copy(wrongHash, hash)
+	wrongHash[0] ^= 1
+	require.False(t, u.ValidSignature(wrongHash, pubKey, sig))
+
+	wrongSig := make([]byte, len(sig))
+	copy(wrongSig, sig)
+	wrongSig[63] ^= 1
+	require.False(t, u.ValidSignature(hash, pubKey, wrongSig))
+
+	wrongPubKey := make([]byte, len(pubKey))
+	copy(wrongPubKey, pubKey)
+	wrongPubKey[64] ^= 1
+	require.False(t, u.ValidSignature(hash, wrongPubKey, sig))
+
+	require.False(t, u.ValidSignature(hash[:31], pubKey, sig))
+	require.False(t, u.ValidSignature(hash, pubKey[:33], sig))
+}
+
+func TestKeccak256(t *testing.T) {
+	h := hashUtil{}.Keccak256(nil)
+	require.EqualValues(t, "c5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470", hex.EncodeToString(h[:]))
+}
+
+func TestUint256(t *testing.T) {
+	u := uint256Util{}
+	num := func(b byte) []byte {
+		ret := make([]byte, uint256Size)
+		ret[uint256Size-1] = b
+		return ret
+	}
+	maxUint := make([]byte, uint256Size)
+	for i := range maxUint {
+		maxUint[i] = 0xff
+	}
+
+	res, err := u.Add(num(2), num(3))
+	require.NoError(t, err)
+	require.EqualValues(t, num(5), res)
+
+	res, err = u.Add(maxUint, num(1))
+	require.NoError(t, err)
+	require.EqualValues(t, num(0), res)
+
+	res, err = u.Sub(num(0), num(1))
+	require.NoError(t, err)
+	require.EqualValues(t, maxUint, res)
+
+	res, err = u.Mul(maxUint, maxUint)
+	require.NoError(t, err)
+	require.EqualValues(t, num(1), res)
+
+	res, err = u.Div(num(7), num(2))
+	require.NoError(t, err)
+	require.EqualValues(t, num(3), res)
+
+	res, err = u.Mod(num(7), num(2))
+	require.NoError(t, err)
+	require.EqualValues(t, num(1), res)
+
+	res, err = u.Exp(num(2), num(255))
+	require.NoError(t, err)
+	require.EqualValues(t, 0x80, res[0])
+
+	res, err = u.Exp(num(2), num(0))
+	require.NoError(t, err)
+	require.EqualValues(t, num(1), res)
+
+	_, err = u.Div(num(1), num(0))
+	require.Error(t, err)
+	_, err = u.Mod(num(1), num(0))
+	require.Error(t, err)
+	_, err = u.Add(num(1), []byte{1})
+	require.Error(t, err)
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package sandbox_utils
+
+import (
+	"fmt"
+	"math/big"
+)
+
+const uint256Size = 32
+
+var uint256Modulus = new(big.Int).Lsh(big.NewInt(1), 256)
+
+type uint256Util struct {
+}
+
+func (u uint256Util) Add(a, b []byte) ([]byte, error) {
+	return uint256Op(a, b, func(x, y *big.Int) (*big.Int, error) {
+		return x.Add(x, y), nil
+	})
+}
+
+func (u uint256Util) Sub(a, b []byte) ([]byte, error) {
+	return uint256Op(a, b, func(x, y *big.Int) (*big.Int, error) {
+		return x.Sub(x, y), nil
+	})
+}
+
+func (u uint256Util) Mul(a, b []byte) ([]byte, error) {
+	return uint256Op(a, b, func(x, y *big.Int) (*big.Int, error) {
+		return x.Mul(x, y), nil
+	})
+}
+
+func (u uint256Util) Div(a, b []byte) ([]byte, error) {
+	return uint256Op(a, b, func(x, y *big.Int) (*big.Int, error) {
+		if y.Sign() == 0 {
+			return nil, fmt.Errorf("Uint256: division by zero")
+		}
+		return x.Quo(x, y), nil
+	})
+}
+
+func (u uint256Util) Mod(a, b []byte) ([]byte, error) {
+	return uint256Op(a, b, func(x, y *big.Int) (*big.Int, error) {
+		if y.Sign() == 0 {
+			return nil, fmt.Errorf("Uint256: division by zero")
+		}
+		return x.Rem(x, y), nil
+	})
+}
+
+func (u uint256Util) Exp(a, b []byte) ([]byte, error) {
+	return uint256Op(a, b, func(x, y *big.Int) (*big.Int, error) {
+		return x.Exp(x, y, uint256Modulus), nil
+	})
+}
+
+func uint256Op(a, b []byte, op func(x, y *big.Int) (*big.Int, error)) ([]byte, error) {
+	if len(a) != uint256Size || len(b) != uint256Size {
+		return nil, fmt.Errorf("Uint256: operands must be %d bytes long", uint256Size)
+	}
+	res, err := op(new(big.Int).SetBytes(a), new(big.Int).SetBytes(b))
+	if err != nil {
+		return nil, err
+	}
+	// wrap around, the result of the subtraction may be negative
+	res.Mod(res, uint256Modulus)
+	ret := make([]byte, uint256Size)
+	return res.FillBytes(ret), nil
+}
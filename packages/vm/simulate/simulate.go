@@ -0,0 +1,168 @@
+// Package simulate runs a request on the VM against a copy of the chain state without committing
+// anything, to preview the effects of the request before it is posted
+package simulate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/iotaledger/wasp/packages/vm/runvm"
+)
+
+// Params are inputs of the simulation
+type Params struct {
+	ChainID    coretypes.ChainID
+	ChainColor balance.Color
+	Processors *processors.ProcessorCache
+	// ValidatorFeeTarget receives the validator fees, same as in vm.VMTask
+	ValidatorFeeTarget coretypes.AgentID
+	// State is the state the request is run against. It is not modified
+	State state.VirtualState
+	// Balances are outputs of the chain address. If nil, they are reconstructed from the on-chain accounts
+	Balances map[valuetransaction.ID][]*balance.Balance
+	// Request is the request to simulate. The transaction doesn't need to be signed or posted.
+	// Its arguments must be solid
+	Request   sctransaction.RequestRef
+	Timestamp int64
+	Log       *logger.Logger
+}
+
+// Mutation is the change of the value of the key in the state of the chain. Nil value means the key is deleted
+type Mutation struct {
+	Key   kv.Key
+	Value []byte
+}
+
+// Result is the outcome of the simulated request
+type Result struct {
+	// Result and Error are returned by the called entry point
+	Result dict.Dict
+	Error  error
+	// Events are emitted by contracts while the request is run. They are not published
+	Events []*vm.Event
+	// FeeColor, OwnerFee and ValidatorFee are fees charged by the chain for the call of the target contract.
+	// The VM doesn't meter the execution, so the fees don't depend on the work done by the request
+	FeeColor     balance.Color
+	OwnerFee     int64
+	ValidatorFee int64
+	// StateDiff is the state update of the request, ordered by keys
+	StateDiff []*Mutation
+	// Block is the block the request would produce if it was processed alone
+	Block state.Block
+}
+
+// Run runs the request on the VM and returns its outcome
+func Run(par *Params) (*Result, error) {
+	if par.Request.RequestSection().SolidArgs() == nil {
+		return nil, fmt.Errorf("simulate: arguments of the request are not solid")
+	}
+	balances := par.Balances
+	if balances == nil {
+		balances = reconstructBalances(par.State, par.ChainColor)
+	}
+	balances = withRequestOutputs(balances, par.Request.Tx, address.Address(par.ChainID))
+
+	ret := &Result{Events: make([]*vm.Event, 0)}
+	task := &vm.VMTask{
+		Processors:         par.Processors,
+		ChainID:            par.ChainID,
+		Color:              par.ChainColor,
+		Entropy:            hashing.RandomHash(nil),
+		VRF:                coretypes.VRF{Output: hashing.RandomHash(nil)}, // the simulated block is not anchored
+		ValidatorFeeTarget: par.ValidatorFeeTarget,
+		Balances:           balances,
+		Requests:           []vm.RequestRefWithFreeTokens{{RequestRef: par.Request}},
+		Timestamp:          par.Timestamp,
+		VirtualState:       par.State.Clone(),
+		Log:                par.Log,
+		EventSink: func(ev *vm.Event) {
+			ret.Events = append(ret.Events, ev)
+		},
+	}
+	done := make(chan error, 1)
+	task.OnFinish = func(callResult dict.Dict, callError error, err error) {
+		ret.Result, ret.Error = callResult, callError
+		done <- err
+	}
+	if err := runvm.RunComputationsAsync(task); err != nil {
+		return nil, err
+	}
+	if err := <-done; err != nil {
+		return nil, err
+	}
+
+	rootState := subrealm.New(par.State.Variables(), kv.Key(root.Interface.Hname().Bytes()))
+	ret.FeeColor, ret.OwnerFee, ret.ValidatorFee = root.GetFeeInfo(rootState, par.Request.RequestSection().Target().Hname())
+	ret.Block = task.ResultBlock
+	task.ResultBlock.ForEach(func(_ uint16, su state.StateUpdate) bool {
+		ret.StateDiff = stateDiff(su.Mutations())
+		return false
+	})
+	return ret, nil
+}
+
+// reconstructBalances returns the output of the chain address with the chain token and all on-chain assets.
+// It stands for the real outputs, which are known only to the committee
+func reconstructBalances(vs state.VirtualState, chainColor balance.Color) map[valuetransaction.ID][]*balance.Balance {
+	accountsState := subrealm.New(vs.Variables(), kv.Key(accounts.Interface.Hname().Bytes()))
+	bals := make([]*balance.Balance, 0)
+	hasChainToken := false
+	accounts.GetTotalAssets(accountsState).Iterate(func(col balance.Color, bal int64) bool {
+		bals = append(bals, balance.New(col, bal))
+		hasChainToken = hasChainToken || col == chainColor
+		return true
+	})
+	if !hasChainToken {
+		bals = append(bals, balance.New(chainColor, 1))
+	}
+	return map[valuetransaction.ID][]*balance.Balance{{}: bals}
+}
+
+// withRequestOutputs adds outputs of the request transaction to the chain address, as if it was confirmed.
+// Newly minted tokens, including the request token, get the color of the transaction
+func withRequestOutputs(balances map[valuetransaction.ID][]*balance.Balance, tx *sctransaction.Transaction, chainAddress address.Address) map[valuetransaction.ID][]*balance.Balance {
+	ret := make(map[valuetransaction.ID][]*balance.Balance, len(balances)+1)
+	for txid, bals := range balances {
+		ret[txid] = bals
+	}
+	bals, ok := tx.OutputBalancesByAddress(chainAddress)
+	if !ok {
+		return ret
+	}
+	outs := make([]*balance.Balance, 0, len(bals))
+	for _, b := range bals {
+		col := b.Color
+		if col == balance.ColorNew {
+			col = balance.Color(tx.ID())
+		}
+		outs = append(outs, balance.New(col, b.Value))
+	}
+	ret[tx.ID()] = outs
+	return ret
+}
+
+func stateDiff(muts buffered.MutationSequence) []*Mutation {
+	ret := make([]*Mutation, 0)
+	muts.IterateLatest(func(key kv.Key, mut buffered.Mutation) bool {
+		ret = append(ret, &Mutation{Key: key, Value: mut.Value()})
+		return true
+	})
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Key < ret[j].Key })
+	return ret
+}
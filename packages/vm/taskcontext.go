@@ -14,6 +14,10 @@ import (
 	"github.com/iotaledger/wasp/packages/vm/processors"
 )
 
+// LogFieldRequestID is the name of the structured log field which tags every log line
+// produced by the VM while running a particular request
+const LogFieldRequestID = "reqid"
+
 type RequestRefWithFreeTokens struct {
 	sctransaction.RequestRef
 	FreeTokens coretypes.ColoredBalances
@@ -25,6 +29,8 @@ type VMTask struct {
 	// inputs (immutable)
 	ChainID coretypes.ChainID
 	Color   balance.Color
+	// VRF is the verifiable random output of the block, signed by the committee during the consensus round
+	VRF coretypes.VRF
 	// deterministic source of entropy
 	Entropy            hashing.HashValue
 	Balances           map[valuetransaction.ID][]*balance.Balance
@@ -33,6 +39,8 @@ type VMTask struct {
 	Timestamp          int64
 	VirtualState       state.VirtualState // input immutable
 	Log                *logger.Logger
+	// EventSink, if not nil, receives events of contracts instead of the publisher
+	EventSink EventSink
 	// call when finished
 	OnFinish func(callResult dict.Dict, callError error, vmError error)
 	// outputs
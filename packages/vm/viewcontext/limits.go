@@ -0,0 +1,154 @@
+package viewcontext
+
+import (
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"go.uber.org/atomic"
+)
+
+// cost of the state access by the view, in units of the execution budget
+const (
+	costStateAccess  = 1
+	costIteratedItem = 1
+	costCall         = 10
+)
+
+var (
+	// ErrBudgetExceeded is returned by the view call which exceeded the execution budget
+	ErrBudgetExceeded = coretypes.NewVMError(coretypes.VMErrorOutOfBudget, "view call exceeded the execution budget")
+	// ErrTimeout is returned by the view call which exceeded the wall-clock timeout
+	ErrTimeout = coretypes.NewVMError(coretypes.VMErrorOutOfBudget, "view call timed out")
+)
+
+// Limits restrict resources consumed by one view call, including nested calls.
+// The budget is deterministic: it is consumed by calls and state accesses of the view, so the same call
+// on the same state always fails or succeeds. 0 means unlimited
+type Limits struct {
+	Budget  int64
+	Timeout time.Duration
+}
+
+// meter accounts the execution budget of the view call. After the budget is exceeded or the call is
+// cancelled by the timeout, each next charge panics, so the view is stopped at its next state access.
+// The view which doesn't access the state, e.g. Wasm code in an endless loop, is stopped by
+// interrupting its VM when the call is cancelled, see coretypes.Interruptible
+type meter struct {
+	budget    int64
+	used      atomic.Int64
+	exceeded  atomic.Bool
+	cancelled atomic.Bool
+	// entry points of the nested calls in progress which can be interrupted
+	runningMutex sync.Mutex
+	running      []coretypes.Interruptible
+}
+
+type meterPanic struct {
+	err error
+}
+
+func (m *meter) reset() {
+	m.used.Store(0)
+	m.exceeded.Store(false)
+	m.cancelled.Store(false)
+}
+
+func (m *meter) charge(units int64) {
+	if m.cancelled.Load() {
+		panic(meterPanic{ErrTimeout})
+	}
+	if m.budget <= 0 {
+		return
+	}
+	if m.used.Add(units) > m.budget {
+		m.exceeded.Store(true)
+		panic(meterPanic{ErrBudgetExceeded})
+	}
+}
+
+// enter registers the entry point which is about to run. It is interrupted if the call is cancelled
+func (m *meter) enter(ep coretypes.Interruptible) {
+	m.runningMutex.Lock()
+	defer m.runningMutex.Unlock()
+	m.running = append(m.running, ep)
+}
+
+// leave is called when the entry point registered by the last enter has returned
+func (m *meter) leave() {
+	m.runningMutex.Lock()
+	defer m.runningMutex.Unlock()
+	m.running = m.running[:len(m.running)-1]
+}
+
+// cancel stops the view call: running entry points are interrupted, the rest of the view is stopped
+// at its next state access
+func (m *meter) cancel() {
+	m.cancelled.Store(true)
+	m.runningMutex.Lock()
+	defer m.runningMutex.Unlock()
+	for _, ep := range m.running {
+		ep.Interrupt()
+	}
+}
+
+// err returns the error if the view call exceeded the limits, even if the view ignored the error
+// returned by the nested call
+func (m *meter) err() error {
+	switch {
+	case m.cancelled.Load():
+		return ErrTimeout
+	case m.exceeded.Load():
+		return ErrBudgetExceeded
+	}
+	return nil
+}
+
+// meteredKVStore charges the meter for each access to the state
+type meteredKVStore struct {
+	kv.KVStore
+	meter *meter
+}
+
+func (s *meteredKVStore) Get(key kv.Key) ([]byte, error) {
+	s.meter.charge(costStateAccess)
+	return s.KVStore.Get(key)
+}
+
+func (s *meteredKVStore) Has(key kv.Key) (bool, error) {
+	s.meter.charge(costStateAccess)
+	return s.KVStore.Has(key)
+}
+
+func (s *meteredKVStore) Iterate(prefix kv.Key, f func(key kv.Key, value []byte) bool) error {
+	s.meter.charge(costStateAccess)
+	return s.KVStore.Iterate(prefix, func(key kv.Key, value []byte) bool {
+		s.meter.charge(costIteratedItem)
+		return f(key, value)
+	})
+}
+
+func (s *meteredKVStore) IterateKeys(prefix kv.Key, f func(key kv.Key) bool) error {
+	s.meter.charge(costStateAccess)
+	return s.KVStore.IterateKeys(prefix, func(key kv.Key) bool {
+		s.meter.charge(costIteratedItem)
+		return f(key)
+	})
+}
+
+func (s *meteredKVStore) MustGet(key kv.Key) []byte {
+	return kv.MustGet(s, key)
+}
+
+func (s *meteredKVStore) MustHas(key kv.Key) bool {
+	return kv.MustHas(s, key)
+}
+
+func (s *meteredKVStore) MustIterate(prefix kv.Key, f func(key kv.Key, value []byte) bool) {
+	kv.MustIterate(s, prefix, f)
+}
+
+func (s *meteredKVStore) MustIterateKeys(prefix kv.Key, f func(key kv.Key) bool) {
+	kv.MustIterateKeys(s, prefix, f)
+}
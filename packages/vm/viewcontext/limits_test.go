@@ -0,0 +1,45 @@
+package viewcontext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+type testEntryPoint struct {
+	interrupted atomic.Bool
+}
+
+func (ep *testEntryPoint) Interrupt() {
+	ep.interrupted.Store(true)
+}
+
+func TestMeterBudget(t *testing.T) {
+	m := &meter{budget: 2}
+	m.charge(1)
+	m.charge(1)
+	require.NoError(t, m.err())
+	require.Panics(t, func() { m.charge(1) })
+	require.Equal(t, ErrBudgetExceeded, m.err())
+
+	m.reset()
+	require.NoError(t, m.err())
+	m.charge(2)
+}
+
+func TestMeterCancelInterruptsRunningCalls(t *testing.T) {
+	m := &meter{}
+	outer, inner := &testEntryPoint{}, &testEntryPoint{}
+	m.enter(outer)
+	m.enter(inner)
+	// the nested call has returned
+	m.leave()
+
+	m.cancel()
+	require.True(t, outer.interrupted.Load())
+	require.False(t, inner.interrupted.Load())
+	// the rest of the view is stopped at its next state access
+	require.Panics(t, func() { m.charge(1) })
+	require.Equal(t, ErrTimeout, m.err())
+}
@@ -2,6 +2,8 @@ package viewcontext
 
 import (
 	"fmt"
+	"time"
+
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/kv/buffered"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
@@ -22,8 +25,13 @@ type viewcontext struct {
 	chainID    coretypes.ChainID
 	timestamp  int64
 	log        *logger.Logger
+	callDepth  int64
+	limits     Limits
+	meter      *meter
 }
 
+// NewFromDB creates the context for view calls on the solid state of the chain on the node.
+// View calls are restricted by limits from the node config
 func NewFromDB(chainID coretypes.ChainID, proc *processors.ProcessorCache) (*viewcontext, error) {
 	state_, _, ok, err := state.LoadSolidState(&chainID)
 
@@ -33,7 +41,11 @@ func NewFromDB(chainID coretypes.ChainID, proc *processors.ProcessorCache) (*vie
 	if !ok {
 		return nil, fmt.Errorf("solid state not found for chain %s", chainID.String())
 	}
-	return New(chainID, state_.Variables(), state_.Timestamp(), proc, nil), nil
+	limits := Limits{
+		Budget:  int64(parameters.GetInt(parameters.ViewBudget)),
+		Timeout: time.Duration(parameters.GetInt(parameters.ViewTimeout)) * time.Millisecond,
+	}
+	return New(chainID, state_.Variables(), state_.Timestamp(), proc, nil).WithLimits(limits), nil
 }
 
 func New(chainID coretypes.ChainID, state kv.KVStore, ts int64, proc *processors.ProcessorCache, logSet *logger.Logger) *viewcontext {
@@ -51,15 +63,70 @@ func New(chainID coretypes.ChainID, state kv.KVStore, ts int64, proc *processors
 	}
 }
 
+// WithLimits restricts the execution budget and the wall-clock time of each view call of the context.
+// The context must not be reused after the call timed out
+func (v *viewcontext) WithLimits(limits Limits) *viewcontext {
+	v.limits = limits
+	v.meter = &meter{budget: limits.Budget}
+	v.state = &meteredKVStore{KVStore: v.state, meter: v.meter}
+	return v
+}
+
 // CallView in viewcontext implements own panic catcher.
 func (v *viewcontext) CallView(contractHname coretypes.Hname, epCode coretypes.Hname, params dict.Dict) (dict.Dict, error) {
+	if v.meter == nil {
+		return v.callViewRecover(contractHname, epCode, params)
+	}
+	if v.callDepth > 0 {
+		// nested call
+		v.meter.charge(costCall)
+		return v.callViewRecover(contractHname, epCode, params)
+	}
+	v.meter.reset()
+	if v.limits.Timeout <= 0 {
+		return v.checkMeter(v.callViewRecover(contractHname, epCode, params))
+	}
+	type result struct {
+		ret dict.Dict
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ret, err := v.callViewRecover(contractHname, epCode, params)
+		done <- result{ret, err}
+	}()
+	timer := time.NewTimer(v.limits.Timeout)
+	defer timer.Stop()
+	select {
+	case res := <-done:
+		return v.checkMeter(res.ret, res.err)
+	case <-timer.C:
+		v.meter.cancel()
+		v.log.Warnf("view call %s::%s timed out after %v", contractHname, epCode, v.limits.Timeout)
+		return nil, ErrTimeout
+	}
+}
+
+// checkMeter replaces the result of the call with the error if the view exceeded the limits
+func (v *viewcontext) checkMeter(ret dict.Dict, err error) (dict.Dict, error) {
+	if meterErr := v.meter.err(); meterErr != nil {
+		return nil, meterErr
+	}
+	return ret, err
+}
+
+func (v *viewcontext) callViewRecover(contractHname coretypes.Hname, epCode coretypes.Hname, params dict.Dict) (dict.Dict, error) {
 	var ret dict.Dict
 	var err error
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
 				ret = nil
-				err = fmt.Errorf("recovered from panic in VM: %v", r)
+				if mp, ok := r.(meterPanic); ok {
+					err = mp.err
+					return
+				}
+				err = coretypes.RecoveredVMError(r)
 				if dberr, ok := r.(buffered.DBError); ok {
 					// There was an error accessing DB. The world stops
 					v.log.Panicf("DB error: %v", dberr)
@@ -73,29 +140,50 @@ func (v *viewcontext) CallView(contractHname coretypes.Hname, epCode coretypes.H
 
 func (v *viewcontext) mustCallView(contractHname coretypes.Hname, epCode coretypes.Hname, params dict.Dict) (dict.Dict, error) {
 	var err error
+	maxCallDepth := root.GetMaxCallDepth(contractStateSubpartition(v.state, root.Interface.Hname()))
+	if v.callDepth >= maxCallDepth {
+		return nil, coretypes.NewVMError(coretypes.VMErrorOutOfBudget, "max call depth exceeded: %d", maxCallDepth)
+	}
+	v.callDepth++
+	defer func() { v.callDepth-- }()
+
 	contractRecord, err := root.FindContract(contractStateSubpartition(v.state, root.Interface.Hname()), contractHname)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find contract %s: %v", contractHname, err)
+		return nil, fmt.Errorf("failed to find contract %s: %w", contractHname, err)
 	}
-	proc, err := v.processors.GetOrCreateProcessor(contractRecord, func(programHash hashing.HashValue) (string, []byte, error) {
+	proc, err := v.processors.GetOrCreateContractProcessor(contractHname, contractRecord.ProgramHash, func(programHash hashing.HashValue) (string, []byte, error) {
 		if vmtype, ok := processors.GetBuiltinProcessorType(programHash); ok {
 			return vmtype, nil, nil
 		}
-		return blob.LocateProgram(contractStateSubpartition(v.state, blob.Interface.Hname()), programHash)
+		vmtype, binary, err := blob.LocateProgram(contractStateSubpartition(v.state, blob.Interface.Hname()), programHash)
+		if err != nil {
+			return "", nil, err
+		}
+		floatPolicy := root.GetProgramFloatPolicy(contractStateSubpartition(v.state, root.Interface.Hname()), programHash)
+		binary, err = processors.FilterBinary(vmtype, binary, floatPolicy)
+		if err != nil {
+			return "", nil, err
+		}
+		return vmtype, binary, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, coretypes.NewVMError(coretypes.VMErrorInternal, "failed to create processor of contract %s: %v", contractHname, err)
 	}
 
 	ep, ok := proc.GetEntryPoint(epCode)
 	if !ok {
-		return nil, fmt.Errorf("%s: can't find entry point '%s'", proc.GetDescription(), epCode.String())
+		return nil, coretypes.NewVMError(coretypes.VMErrorEntryPointNotFound, "%s: can't find entry point '%s'", proc.GetDescription(), epCode.String())
 	}
 
 	if !ep.IsView() {
-		return nil, fmt.Errorf("only view entry point can be called in this context")
+		return nil, coretypes.NewVMError(coretypes.VMErrorInvalidCall, "only view entry point can be called in this context")
+	}
+	if i, ok := ep.(coretypes.Interruptible); ok && v.meter != nil {
+		v.meter.enter(i)
+		defer v.meter.leave()
 	}
-	return ep.CallView(newSandboxView(v, contractHname, params))
+	ret, err := ep.CallView(newSandboxView(v, contractHname, params))
+	return coretypes.CheckContractError(contractHname, epCode, ret, err)
 }
 
 func contractStateSubpartition(state kv.KVStore, contractHname coretypes.Hname) kv.KVStore {
@@ -1,7 +1,6 @@
 package vmcontext
 
 import (
-	"errors"
 	"fmt"
 	"github.com/iotaledger/wasp/packages/hashing"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
@@ -11,11 +10,15 @@ import (
 )
 
 var (
-	ErrContractNotFound   = errors.New("contract not found")
-	ErrEntryPointNotFound = errors.New("entry point not found")
-	ErrProcessorNotFound  = errors.New("VM not found. Internal error")
-	ErrNotEnoughFees      = errors.New("not enough fees")
-	ErrWrongRequestToken  = errors.New("wrong request token")
+	ErrContractNotFound   = coretypes.NewVMError(coretypes.VMErrorContractNotFound, "contract not found")
+	ErrEntryPointNotFound = coretypes.NewVMError(coretypes.VMErrorEntryPointNotFound, "entry point not found")
+	ErrProcessorNotFound  = coretypes.NewVMError(coretypes.VMErrorInternal, "VM not found. Internal error")
+	ErrNotEnoughFees      = coretypes.NewVMError(coretypes.VMErrorNotEnoughFees, "not enough fees")
+	ErrWrongRequestToken  = coretypes.NewVMError(coretypes.VMErrorInternal, "wrong request token")
+	ErrCallDepthExceeded  = coretypes.NewVMError(coretypes.VMErrorOutOfBudget, "max call depth exceeded")
+	ErrReentrantCall      = coretypes.NewVMError(coretypes.VMErrorReentrantCall, "reentrant call to the locked contract")
+	ErrStateSizeExceeded  = coretypes.NewVMError(coretypes.VMErrorOutOfBudget, "maximum state size of the contract exceeded")
+	ErrContractPaused     = coretypes.NewVMError(coretypes.VMErrorContractPaused, "contract is paused")
 )
 
 // Call
@@ -29,9 +32,9 @@ func (vmctx *VMContext) Call(targetContract coretypes.Hname, epCode coretypes.Hn
 }
 
 func (vmctx *VMContext) callByProgramHash(targetContract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances, progHash hashing.HashValue) (dict.Dict, error) {
-	proc, err := vmctx.processors.GetOrCreateProcessorByProgramHash(progHash, vmctx.getBinary)
+	proc, err := vmctx.processors.GetOrCreateContractProcessor(targetContract, progHash, vmctx.getBinary)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrProcessorNotFound, err)
 	}
 	ep, ok := proc.GetEntryPoint(epCode)
 	if !ok {
@@ -40,7 +43,10 @@ func (vmctx *VMContext) callByProgramHash(targetContract coretypes.Hname, epCode
 	// distinguishing between two types of entry points. Passing different types of sandboxes
 	if ep.IsView() {
 		if epCode == coretypes.EntryPointInit {
-			return nil, fmt.Errorf("'init' entry point can't be a view")
+			return nil, coretypes.NewVMError(coretypes.VMErrorInvalidCall, "'init' entry point can't be a view")
+		}
+		if err := vmctx.checkCallAllowed(targetContract, true); err != nil {
+			return nil, err
 		}
 		// passing nil as transfer: calling the view should not have effect on chain ledger
 		if err := vmctx.pushCallContextWithTransfer(targetContract, params, nil); err != nil {
@@ -48,7 +54,12 @@ func (vmctx *VMContext) callByProgramHash(targetContract coretypes.Hname, epCode
 		}
 		defer vmctx.popCallContext()
 
-		return ep.CallView(NewSandboxView(vmctx))
+		vmctx.chargeExecUnits(costCall)
+		ret, err := ep.CallView(NewSandboxView(vmctx))
+		return coretypes.CheckContractError(targetContract, epCode, ret, err)
+	}
+	if err := vmctx.checkCallAllowed(targetContract, false); err != nil {
+		return nil, err
 	}
 	if err := vmctx.pushCallContextWithTransfer(targetContract, params, transfer); err != nil {
 		return nil, err
@@ -58,16 +69,18 @@ func (vmctx *VMContext) callByProgramHash(targetContract coretypes.Hname, epCode
 	// prevent calling 'init' not from root contract or not while initializing root
 	if epCode == coretypes.EntryPointInit && targetContract != root.Interface.Hname() {
 		if !vmctx.callerIsRoot() {
-			return nil, fmt.Errorf("attempt to callByProgramHash init not from the root contract")
+			return nil, coretypes.NewVMError(coretypes.VMErrorUnauthorized, "attempt to callByProgramHash init not from the root contract")
 		}
 	}
-	return ep.Call(NewSandbox(vmctx))
+	vmctx.chargeExecUnits(costCall)
+	ret, err := ep.Call(NewSandbox(vmctx))
+	return coretypes.CheckContractError(targetContract, epCode, ret, err)
 }
 
 func (vmctx *VMContext) callNonViewByProgramHash(targetContract coretypes.Hname, epCode coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances, progHash hashing.HashValue) (dict.Dict, error) {
-	proc, err := vmctx.processors.GetOrCreateProcessorByProgramHash(progHash, vmctx.getBinary)
+	proc, err := vmctx.processors.GetOrCreateContractProcessor(targetContract, progHash, vmctx.getBinary)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %v", ErrProcessorNotFound, err)
 	}
 	ep, ok := proc.GetEntryPoint(epCode)
 	if !ok {
@@ -76,7 +89,10 @@ func (vmctx *VMContext) callNonViewByProgramHash(targetContract coretypes.Hname,
 
 	// distinguishing between two types of entry points. Passing different types of sandboxes
 	if ep.IsView() {
-		return nil, fmt.Errorf("non-view entry point expected")
+		return nil, coretypes.NewVMError(coretypes.VMErrorInvalidCall, "non-view entry point expected")
+	}
+	if err := vmctx.checkCallAllowed(targetContract, false); err != nil {
+		return nil, err
 	}
 	if err := vmctx.pushCallContextWithTransfer(targetContract, params, transfer); err != nil {
 		return nil, err
@@ -86,10 +102,12 @@ func (vmctx *VMContext) callNonViewByProgramHash(targetContract coretypes.Hname,
 	// prevent calling 'init' not from root contract or not while initializing root
 	if epCode == coretypes.EntryPointInit && targetContract != root.Interface.Hname() {
 		if !vmctx.callerIsRoot() {
-			return nil, fmt.Errorf("attempt to callByProgramHash init not from the root contract")
+			return nil, coretypes.NewVMError(coretypes.VMErrorUnauthorized, "attempt to callByProgramHash init not from the root contract")
 		}
 	}
-	return ep.Call(NewSandbox(vmctx))
+	vmctx.chargeExecUnits(costCall)
+	ret, err := ep.Call(NewSandbox(vmctx))
+	return coretypes.CheckContractError(targetContract, epCode, ret, err)
 }
 
 func (vmctx *VMContext) callerIsRoot() bool {
@@ -23,6 +23,36 @@ func (vmctx *VMContext) pushCallContextWithTransfer(contract coretypes.Hname, pa
 	return nil
 }
 
+// checkCallAllowed checks the limit of the call stack depth, the pause and the reentrancy lock of the target contract.
+// Views can't modify the state, so the pause and the reentrancy lock do not apply to them
+func (vmctx *VMContext) checkCallAllowed(contract coretypes.Hname, isView bool) error {
+	if int64(len(vmctx.callStack)) >= vmctx.maxCallDepth {
+		return fmt.Errorf("%w: %d", ErrCallDepthExceeded, vmctx.maxCallDepth)
+	}
+	if isView {
+		return nil
+	}
+	if vmctx.isContractPaused(contract) {
+		return fmt.Errorf("%w: %s", ErrContractPaused, contract)
+	}
+	if !vmctx.isOnCallStack(contract) {
+		return nil
+	}
+	if vmctx.isReentrancyLocked(contract) {
+		return fmt.Errorf("%w: %s", ErrReentrantCall, contract)
+	}
+	return nil
+}
+
+func (vmctx *VMContext) isOnCallStack(contract coretypes.Hname) bool {
+	for _, c := range vmctx.callStack {
+		if c.contract == contract {
+			return true
+		}
+	}
+	return false
+}
+
 const traceStack = false
 
 func (vmctx *VMContext) pushCallContext(contract coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances) {
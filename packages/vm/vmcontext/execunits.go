@@ -0,0 +1,35 @@
+package vmcontext
+
+import (
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+// cost of operations of the request in execution units. The units are deterministic: they are consumed
+// by calls and state accesses, including the accounting of the VM itself, so the same batch of requests
+// on the same state always consumes the same number of units on all nodes
+const (
+	costStateRead    = 1
+	costStateWrite   = 1
+	costIteratedItem = 1
+	costCall         = 10
+)
+
+// chargeExecUnits accounts the execution units consumed by the batch
+func (vmctx *VMContext) chargeExecUnits(units int64) {
+	vmctx.execUnits += units
+}
+
+// recordExecUnits records the execution units consumed by the batch in the root contract.
+// It is called with the last request of the batch, so the record is part of its state update.
+// Units consumed by requests which failed and were rolled back are counted as well
+func (vmctx *VMContext) recordExecUnits() {
+	if vmctx.numRequestsRun != vmctx.batchSize {
+		return
+	}
+	units := vmctx.execUnits
+
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil) // create local context for the state
+	defer vmctx.popCallContext()
+
+	root.RecordExecutionUnits(vmctx.State(), vmctx.blockIndex, units)
+}
@@ -104,13 +104,35 @@ func (vmctx *VMContext) PostRequestToSelfWithDelay(entryPoint coretypes.Hname, a
 }
 
 func (vmctx *VMContext) EventPublisher() vm.ContractEventPublisher {
-	return vm.NewContractEventPublisher(vmctx.CurrentContractID(), vmctx.log)
+	return vm.NewContractEventPublisher(vmctx.CurrentContractID(), vmctx.log).WithSink(vmctx.eventSink)
 }
 
 func (vmctx *VMContext) RequestID() coretypes.RequestID {
 	return *vmctx.reqRef.RequestID()
 }
 
+// RequestMetadata is the metadata of the current request in the block
+func (vmctx *VMContext) RequestMetadata() coretypes.RequestMetadata {
+	return coretypes.RequestMetadata{
+		BlockIndex:     vmctx.blockIndex,
+		BlockTimestamp: vmctx.batchTimestamp,
+		RequestIndex:   vmctx.requestIndex,
+		BatchSize:      vmctx.batchSize,
+		TransactionID:  vmctx.reqRef.Tx.ID(),
+		SenderAddress:  *vmctx.reqRef.Tx.MustProperties().SenderAddress(),
+	}
+}
+
+// VRF is the verifiable random output of the current block
+func (vmctx *VMContext) VRF() coretypes.VRF {
+	return vmctx.vrf
+}
+
+// RequestTransfer is the composition of the tokens carried by the current request
+func (vmctx *VMContext) RequestTransfer() coretypes.RequestTransfer {
+	return vmctx.requestTransfer
+}
+
 func (vmctx *VMContext) NumFreeMinted() int64 {
 	return vmctx.reqRef.Tx.MustProperties().NumFreeMintedTokens()
 }
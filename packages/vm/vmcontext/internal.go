@@ -1,10 +1,16 @@
 package vmcontext
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
 	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/buffered"
+	"github.com/iotaledger/wasp/packages/kv/dict"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/packages/vm/core/blob"
 	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
@@ -25,6 +31,89 @@ func (vmctx *VMContext) creditToAccount(agentID coretypes.AgentID, transfer core
 	accounts.CreditToAccount(vmctx.State(), agentID, transfer)
 }
 
+// refundExpiredEscrows returns funds of expired escrows to the senders at the beginning of the batch.
+// Refunds are part of the state update of the first request in the batch
+func (vmctx *VMContext) refundExpiredEscrows() {
+	if vmctx.escrowsRefunded {
+		return
+	}
+	vmctx.escrowsRefunded = true
+
+	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
+	refunded := accounts.RefundExpiredEscrows(vmctx.State(), vmctx.chainID, vmctx.timestamp)
+	vmctx.popCallContext()
+
+	for _, id := range refunded {
+		vmctx.StoreToEventLog(accounts.Interface.Hname(), []byte(fmt.Sprintf("[refund escrow] id: %d", id)))
+	}
+}
+
+// lockDeposit locks the refundable deposit of the sender until root.DepositLockPeriod passes
+func (vmctx *VMContext) lockDeposit(sender coretypes.AgentID, amount int64) {
+	if len(vmctx.callStack) > 0 {
+		vmctx.log.Panicf("lockDeposit must be called only from request")
+	}
+	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
+	defer vmctx.popCallContext()
+
+	accounts.LockDeposit(vmctx.State(), vmctx.chainID, sender, amount, vmctx.timestamp+root.DepositLockPeriod.Nanoseconds())
+}
+
+// refundLockedDeposits returns deposits locked longer than root.DepositLockPeriod to the senders
+// at the beginning of the batch, together with expired escrows
+func (vmctx *VMContext) refundLockedDeposits() {
+	if vmctx.depositsRefunded {
+		return
+	}
+	vmctx.depositsRefunded = true
+
+	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
+	n := accounts.RefundLockedDeposits(vmctx.State(), vmctx.chainID, vmctx.timestamp)
+	vmctx.popCallContext()
+
+	if n > 0 {
+		vmctx.StoreToEventLog(accounts.Interface.Hname(), []byte(fmt.Sprintf("[refund deposits] senders: %d", n)))
+	}
+}
+
+// chargeAnchorFee charges the L1 fee of the anchor transaction of the block according to root.AnchorFeePolicy
+// and adds the output with the fee to the anchor transaction. It is charged once per batch, with the first request.
+// If the payer can't pay the fee, it is charged to the chain owner. If neither can pay, the fee is skipped
+func (vmctx *VMContext) chargeAnchorFee() {
+	if vmctx.anchorFeeCharged {
+		return
+	}
+	vmctx.anchorFeeCharged = true
+
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	policy := root.GetAnchorFeePolicy(vmctx.State())
+	vmctx.popCallContext()
+	if policy.Fee == 0 {
+		return
+	}
+	payer := policy.PayerAgentID(vmctx.chainOwnerID, vmctx.validatorFeeTarget)
+
+	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil) // create local context for the state
+	charged := accounts.ChargeAnchorFee(vmctx.State(), payer, policy.Fee)
+	if !charged && payer != vmctx.chainOwnerID {
+		payer = vmctx.chainOwnerID
+		charged = accounts.ChargeAnchorFee(vmctx.State(), payer, policy.Fee)
+	}
+	vmctx.popCallContext()
+
+	if !charged {
+		vmctx.log.Warnf("anchor fee of %d iotas is not paid: not enough funds", policy.Fee)
+		vmctx.StoreToEventLog(accounts.Interface.Hname(), []byte(fmt.Sprintf("[anchor fee] %d iotas not paid: not enough funds", policy.Fee)))
+		return
+	}
+	fee := cbalances.NewFromMap(map[balance.Color]int64{balance.ColorIOTA: policy.Fee})
+	if err := vmctx.txBuilder.TransferToAddress(policy.Target, fee); err != nil {
+		// the chain ledger and the chain address must be consistent
+		vmctx.log.Panicf("chargeAnchorFee: %v", err)
+	}
+	vmctx.StoreToEventLog(accounts.Interface.Hname(), []byte(fmt.Sprintf("[anchor fee] %d iotas paid by %s", policy.Fee, payer.String())))
+}
+
 // debitFromAccount subtracts tokens from account if it is enough of it.
 // should be called only when posting request
 func (vmctx *VMContext) debitFromAccount(agentID coretypes.AgentID, transfer coretypes.ColoredBalances) bool {
@@ -70,17 +159,57 @@ func (vmctx *VMContext) getFeeInfo() (balance.Color, int64, int64) {
 	return root.GetFeeInfoByContractRecord(vmctx.State(), vmctx.contractRecord)
 }
 
+func (vmctx *VMContext) getRequestIntakePolicy() *root.RequestIntakePolicy {
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	return root.GetRequestIntakePolicy(vmctx.State())
+}
+
+func (vmctx *VMContext) isReentrancyLocked(contract coretypes.Hname) bool {
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	return root.IsReentrancyLocked(vmctx.State(), contract)
+}
+
+func (vmctx *VMContext) isContractPaused(contract coretypes.Hname) bool {
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	return root.IsContractPaused(vmctx.State(), contract)
+}
+
 func (vmctx *VMContext) getBinary(programHash hashing.HashValue) (string, []byte, error) {
 	vmtype, ok := processors.GetBuiltinProcessorType(programHash)
 	if ok {
 		return vmtype, nil, nil
 	}
+	vmtype, binary, err := vmctx.locateProgram(programHash)
+	if err != nil {
+		return "", nil, err
+	}
+	binary, err = processors.FilterBinary(vmtype, binary, vmctx.getProgramFloatPolicy(programHash))
+	if err != nil {
+		return "", nil, err
+	}
+	return vmtype, binary, nil
+}
+
+func (vmctx *VMContext) locateProgram(programHash hashing.HashValue) (string, []byte, error) {
 	vmctx.pushCallContext(blob.Interface.Hname(), nil, nil)
 	defer vmctx.popCallContext()
 
 	return blob.LocateProgram(vmctx.State(), programHash)
 }
 
+func (vmctx *VMContext) getProgramFloatPolicy(programHash hashing.HashValue) int64 {
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	return root.GetProgramFloatPolicy(vmctx.State(), programHash)
+}
+
 func (vmctx *VMContext) getBalance(col balance.Color) int64 {
 	vmctx.pushCallContext(accounts.Interface.Hname(), nil, nil)
 	defer vmctx.popCallContext()
@@ -111,9 +240,158 @@ func (vmctx *VMContext) moveBalance(target coretypes.AgentID, col balance.Color,
 }
 
 func (vmctx *VMContext) StoreToEventLog(contract coretypes.Hname, data []byte) {
+	retention := vmctx.getEventLogRetention(contract)
+
 	vmctx.pushCallContext(eventlog.Interface.Hname(), nil, nil)
 	defer vmctx.popCallContext()
 
 	vmctx.log.Debugf("StoreToEventLog/%s: data: '%s'", contract.String(), string(data))
 	eventlog.AppendToLog(vmctx.State(), vmctx.timestamp, contract, data)
+	vmctx.prunable -= eventlog.PruneLog(vmctx.State(), vmctx.timestamp, contract, retention.MaxRecords, retention.MaxAge, vmctx.prunable)
+}
+
+// StoreEventToEventLog stores the typed event emitted by the contract and indexes it by the topic and the indexed fields
+func (vmctx *VMContext) StoreEventToEventLog(contract coretypes.Hname, topic string, fields dict.Dict, indexed []kv.Key) {
+	retention := vmctx.getEventLogRetention(contract)
+	ev := &eventlog.Event{
+		Topic:     topic,
+		Fields:    fields.Clone(),
+		RequestID: vmctx.RequestID(),
+	}
+
+	vmctx.pushCallContext(eventlog.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	vmctx.log.Debugf("StoreEventToEventLog/%s: %s", contract.String(), ev.String())
+	eventlog.AppendEvent(vmctx.State(), vmctx.timestamp, contract, ev, indexed)
+	vmctx.prunable -= eventlog.PruneEvents(vmctx.State(), vmctx.timestamp, contract, retention.MaxRecords, retention.MaxAge, vmctx.prunable)
+}
+
+// storeReceipt adds the receipt of the request to the index of requests of the sender in the event log
+func (vmctx *VMContext) storeReceipt(err error) {
+	receipt := &eventlog.RequestReceipt{
+		RequestID:    *vmctx.reqRef.RequestID(),
+		BlockIndex:   vmctx.blockIndex,
+		RequestIndex: vmctx.requestIndex,
+		Contract:     vmctx.reqHname,
+		EntryPoint:   vmctx.reqRef.RequestSection().EntryPointCode(),
+		ErrorCode:    coretypes.VMErrorCodeOf(err),
+	}
+	if err != nil {
+		receipt.Error = err.Error()
+	}
+
+	vmctx.pushCallContext(eventlog.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	eventlog.AppendReceipt(vmctx.State(), vmctx.timestamp, vmctx.reqRef.SenderAgentID(), receipt)
+}
+
+func (vmctx *VMContext) getEventLogRetention(contract coretypes.Hname) *root.EventLogRetention {
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	return root.GetEventLogRetention(vmctx.State(), contract)
+}
+
+// updateStateUsage records the state size of contracts changed by the request and checks it against
+// the state size limit of the chain. The size of the state is the total length of keys and values in
+// the partition of the contract. Core contracts are not accounted.
+// Returns the error if the request increases the state of a contract beyond the limit
+func (vmctx *VMContext) updateStateUsage() error {
+	deltas := make(map[coretypes.Hname]int64)
+	mutations := vmctx.stateUpdate.Mutations()
+	mutations.IterateLatest(func(key kv.Key, mut buffered.Mutation) bool {
+		hname, ok := accountedContract(key)
+		if !ok {
+			return true
+		}
+		prev, err := vmctx.virtualState.Variables().Get(key)
+		if err != nil {
+			vmctx.log.Panicf("updateStateUsage: %v", err)
+		}
+		deltas[hname] += entrySize(key, mut.Value()) - entrySize(key, prev)
+		return true
+	})
+	// keys deleted by prefix deletions are not included in the latest mutations
+	counted := make(map[kv.Key]bool)
+	mutations.IterateDeletedPrefixes(func(prefix kv.Key) bool {
+		hname, ok := accountedContract(prefix)
+		if !ok {
+			return true
+		}
+		err := vmctx.virtualState.Variables().Iterate(prefix, func(key kv.Key, value []byte) bool {
+			if !counted[key] && mutations.IsDeletedByPrefix(key) {
+				counted[key] = true
+				deltas[hname] -= entrySize(key, value)
+			}
+			return true
+		})
+		if err != nil {
+			vmctx.log.Panicf("updateStateUsage: %v", err)
+		}
+		return true
+	})
+	if len(deltas) == 0 {
+		return nil
+	}
+	hnames := make([]coretypes.Hname, 0, len(deltas))
+	for hname := range deltas {
+		hnames = append(hnames, hname)
+	}
+	sort.Slice(hnames, func(i, j int) bool { return hnames[i] < hnames[j] })
+
+	vmctx.pushCallContext(root.Interface.Hname(), nil, nil)
+	defer vmctx.popCallContext()
+
+	maxStateSize := root.GetResourceLimits(vmctx.State()).MaxStateSize
+	sizes := make([]int64, len(hnames))
+	for i, hname := range hnames {
+		size, ok := root.GetStateUsage(vmctx.State(), hname)
+		if !ok {
+			size = vmctx.stateSizeBefore(hname)
+		}
+		sizes[i] = size + deltas[hname]
+		if maxStateSize > 0 && deltas[hname] > 0 && sizes[i] > maxStateSize {
+			return fmt.Errorf("%w: contract %s, state size %d, limit %d", ErrStateSizeExceeded, hname, sizes[i], maxStateSize)
+		}
+	}
+	for i, hname := range hnames {
+		root.SetStateUsage(vmctx.State(), hname, sizes[i])
+	}
+	return nil
+}
+
+// stateSizeBefore calculates the state size of the contract before the request.
+// It is needed once for each contract, then the size is recorded in the root contract
+func (vmctx *VMContext) stateSizeBefore(hname coretypes.Hname) int64 {
+	ret := int64(0)
+	err := vmctx.virtualState.Variables().Iterate(kv.Key(hname.Bytes()), func(key kv.Key, value []byte) bool {
+		ret += entrySize(key, value)
+		return true
+	})
+	if err != nil {
+		vmctx.log.Panicf("stateSizeBefore: %v", err)
+	}
+	return ret
+}
+
+// accountedContract returns the contract of the key or of the prefix if its state usage is accounted
+func accountedContract(key kv.Key) (coretypes.Hname, bool) {
+	if len(key) < coretypes.HnameLength {
+		return 0, false
+	}
+	hname, err := coretypes.NewHnameFromBytes([]byte(key[:coretypes.HnameLength]))
+	if err != nil || root.IsCoreContract(hname) {
+		return 0, false
+	}
+	return hname, true
+}
+
+// entrySize is the size of the key/value pair in the partition of the contract, 0 if the key is deleted
+func entrySize(key kv.Key, value []byte) int64 {
+	if value == nil {
+		return 0
+	}
+	return int64(len(key) - coretypes.HnameLength + len(value))
 }
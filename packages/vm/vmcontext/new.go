@@ -26,17 +26,35 @@ type VMContext struct {
 	balances     map[valuetransaction.ID][]*balance.Balance
 	txBuilder    *statetxbuilder.Builder // mutated
 	virtualState state.VirtualState      // mutated
-	log          *logger.Logger
+	taskLog      *logger.Logger
+	eventSink    vm.EventSink
+	log          *logger.Logger // taskLog tagged with the current request ID
 	// fee related
 	validatorFeeTarget coretypes.AgentID // provided by validator
 	feeColor           balance.Color
 	ownerFee           int64
 	validatorFee       int64
+	minDeposit         int64
+	depositPolicy      int64
+	maxCallDepth       int64
+	escrowsRefunded    bool // expired escrows are refunded once per batch, with the first request
+	depositsRefunded   bool // same for locked deposits
+	anchorFeeCharged   bool // same for the anchor fee, see root.AnchorFeePolicy
+	blockIndex         uint32
+	batchTimestamp     int64
+	batchSize          uint16
+	numRequestsRun     uint16 // mutated
+	execUnits          int64  // execution units consumed by the batch, mutated
+	vrf                coretypes.VRF
 	// request context
 	remainingAfterFees coretypes.ColoredBalances
+	requestTransfer    coretypes.RequestTransfer
+	deposit            int64             // iotas taken from the transfer, released after the call
+	prunable           uint32            // number of event log records which still may be pruned in the request
 	entropy            hashing.HashValue // mutates with each request
 	reqRef             vm.RequestRefWithFreeTokens
 	reqHname           coretypes.Hname
+	requestIndex       uint16
 	contractRecord     *root.ContractRecord
 	timestamp          int64
 	stateUpdate        state.StateUpdate
@@ -61,9 +79,16 @@ func NewVMContext(task *vm.VMTask, txb *statetxbuilder.Builder) (*VMContext, err
 		balances:     task.Balances,
 		txBuilder:    txb,
 		virtualState: task.VirtualState.Clone(),
+		taskLog:      task.Log,
+		eventSink:    task.EventSink,
 		log:          task.Log,
 		entropy:      task.Entropy,
 		callStack:    make([]*callContext, 0),
+		// the VM produces the block following the input state
+		blockIndex:     task.VirtualState.BlockIndex() + 1,
+		batchTimestamp: task.Timestamp,
+		batchSize:      uint16(len(task.Requests)),
+		vrf:            task.VRF,
 	}
 	return ret, nil
 }
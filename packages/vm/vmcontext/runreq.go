@@ -2,7 +2,9 @@ package vmcontext
 
 import (
 	"fmt"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
 	"github.com/iotaledger/wasp/packages/hashing"
@@ -10,6 +12,7 @@ import (
 	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/state"
 	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
 	"github.com/iotaledger/wasp/packages/vm/core/root"
 )
 
@@ -25,14 +28,25 @@ func (vmctx *VMContext) RunTheRequest(reqRef vm.RequestRefWithFreeTokens, timest
 		vmctx.mustHandleFees()
 	}
 	vmctx.mustHandleFreeTokens()
+	if !vmctx.isInitChainRequest() {
+		vmctx.refundExpiredEscrows()
+		vmctx.refundLockedDeposits()
+		vmctx.chargeAnchorFee()
+	}
 	defer vmctx.finalizeRequestCall()
 
 	if vmctx.contractRecord == nil {
 		// sc does not exist, stop here
 		vmctx.lastResult = nil
-		vmctx.lastError = fmt.Errorf("smart contract '%s' does not exist", vmctx.reqHname)
+		vmctx.lastError = coretypes.NewVMError(coretypes.VMErrorContractNotFound, "smart contract '%s' does not exist", vmctx.reqHname)
+		return
+	}
+	if !vmctx.isInitChainRequest() && !vmctx.takeDeposit() {
+		// not enough for the deposit, the transfer was accrued to the sender
 		return
 	}
+	defer vmctx.releaseDeposit()
+
 	// snapshot state baseline for rollback in case of panic
 	snapshotTxBuilder := vmctx.txBuilder.Clone()
 	snapshotStateUpdate := vmctx.stateUpdate.Clone()
@@ -43,7 +57,7 @@ func (vmctx *VMContext) RunTheRequest(reqRef vm.RequestRefWithFreeTokens, timest
 		defer func() {
 			if r := recover(); r != nil {
 				vmctx.lastResult = nil
-				vmctx.lastError = fmt.Errorf("recovered from panic in VM: %v", r)
+				vmctx.lastError = coretypes.RecoveredVMError(r)
 				if dberr, ok := r.(buffered.DBError); ok {
 					// There was an error accessing the DB
 					// The world stops
@@ -53,6 +67,9 @@ func (vmctx *VMContext) RunTheRequest(reqRef vm.RequestRefWithFreeTokens, timest
 		}()
 		vmctx.mustCallFromRequest()
 	}()
+	if vmctx.lastError == nil {
+		vmctx.lastError = vmctx.updateStateUsage()
+	}
 
 	if vmctx.lastError != nil {
 		// treating panic and error returned from request the same way
@@ -79,6 +96,12 @@ func (vmctx *VMContext) mustHandleRequestToken() {
 		balance.ColorIOTA: 1,
 	}))
 	vmctx.remainingAfterFees = vmctx.reqRef.RequestSection().Transfer()
+	vmctx.requestTransfer = coretypes.RequestTransfer{
+		OutputID:  valuetransaction.NewOutputID(address.Address(vmctx.ChainID()), vmctx.reqRef.Tx.ID()),
+		Declared:  vmctx.remainingAfterFees,
+		Fees:      cbalances.NewFromMap(nil),
+		DustAdded: true,
+	}
 	vmctx.log.Debugf("mustHandleFees: 1 request token accrued to the sender: %s\n", vmctx.reqRef.SenderAgentID())
 }
 
@@ -100,7 +123,7 @@ func (vmctx *VMContext) mustHandleFees() {
 		// fallback: not enough fees. Accrue everything to the sender
 		sender := vmctx.reqRef.SenderAgentID()
 		vmctx.creditToAccount(sender, transfer)
-		vmctx.lastError = fmt.Errorf("mustHandleFees: not enough fees for request %s. Transfer accrued to %s",
+		vmctx.lastError = coretypes.NewVMError(coretypes.VMErrorNotEnoughFees, "mustHandleFees: not enough fees for request %s. Transfer accrued to %s",
 			vmctx.reqRef.RequestID().Short(), sender.String())
 		vmctx.remainingAfterFees = cbalances.NewFromMap(nil)
 		return
@@ -116,6 +139,9 @@ func (vmctx *VMContext) mustHandleFees() {
 			vmctx.feeColor: vmctx.validatorFee,
 		}))
 	}
+	vmctx.requestTransfer.Fees = cbalances.NewFromMap(map[balance.Color]int64{
+		vmctx.feeColor: totalFee,
+	})
 	// subtract fees from the transfer
 	remaining := map[balance.Color]int64{
 		vmctx.feeColor: -totalFee,
@@ -124,6 +150,50 @@ func (vmctx *VMContext) mustHandleFees() {
 	vmctx.remainingAfterFees = cbalances.NewFromMap(remaining)
 }
 
+// takeDeposit takes the iotas required by root.RequestIntakePolicy from the transfer of the request.
+// The deposit is normally checked by the committee before the request is taken into the backlog.
+// If the transfer is not enough, it is accrued to the sender and the request is not processed
+func (vmctx *VMContext) takeDeposit() bool {
+	vmctx.deposit = 0
+	if vmctx.minDeposit == 0 || vmctx.requesterIsChainOwner() {
+		return true
+	}
+	if vmctx.remainingAfterFees.Balance(balance.ColorIOTA) < vmctx.minDeposit {
+		sender := vmctx.reqRef.SenderAgentID()
+		vmctx.creditToAccount(sender, vmctx.remainingAfterFees)
+		vmctx.remainingAfterFees = cbalances.NewFromMap(nil)
+		vmctx.lastResult = nil
+		vmctx.lastError = coretypes.NewVMError(coretypes.VMErrorNotEnoughDeposit, "takeDeposit: not enough iotas for the deposit of %d in request %s. Transfer accrued to %s",
+			vmctx.minDeposit, vmctx.reqRef.RequestID().Short(), sender.String())
+		return false
+	}
+	remaining := map[balance.Color]int64{
+		balance.ColorIOTA: -vmctx.minDeposit,
+	}
+	vmctx.remainingAfterFees.AddToMap(remaining)
+	vmctx.remainingAfterFees = cbalances.NewFromMap(remaining)
+	vmctx.deposit = vmctx.minDeposit
+	vmctx.requestTransfer.Deposit = vmctx.minDeposit
+	return true
+}
+
+// releaseDeposit accrues the deposit to the chain owner, if it is forfeited, or locks it on the chain
+// for root.DepositLockPeriod, to be refunded to the sender later.
+// The deposit is released even if the request failed
+func (vmctx *VMContext) releaseDeposit() {
+	if vmctx.deposit == 0 {
+		return
+	}
+	if vmctx.depositPolicy == root.DepositForfeit {
+		vmctx.creditToAccount(vmctx.ChainOwnerID(), cbalances.NewFromMap(map[balance.Color]int64{
+			balance.ColorIOTA: vmctx.deposit,
+		}))
+	} else {
+		vmctx.lockDeposit(vmctx.reqRef.SenderAgentID(), vmctx.deposit)
+	}
+	vmctx.deposit = 0
+}
+
 // mustHandleFreeTokens free tokens accrued to the chain owner
 func (vmctx *VMContext) mustHandleFreeTokens() {
 	if vmctx.reqRef.FreeTokens == nil || vmctx.reqRef.FreeTokens.Len() == 0 {
@@ -159,6 +229,8 @@ func (vmctx *VMContext) mustCallFromRequest() {
 
 func (vmctx *VMContext) finalizeRequestCall() {
 	vmctx.mustRequestToEventLog(vmctx.lastError)
+	vmctx.storeReceipt(vmctx.lastError)
+	vmctx.recordExecUnits()
 	vmctx.virtualState.ApplyStateUpdate(vmctx.stateUpdate)
 
 	vmctx.log.Debugw("runTheRequest OUT",
@@ -171,11 +243,7 @@ func (vmctx *VMContext) mustRequestToEventLog(err error) {
 	if err != nil {
 		vmctx.log.Error(err)
 	}
-	e := "Ok"
-	if err != nil {
-		e = err.Error()
-	}
-	msg := fmt.Sprintf("[req] %s: %s", vmctx.reqRef.RequestID().String(), e)
+	msg := fmt.Sprintf("[req] %s: %s", vmctx.reqRef.RequestID().String(), coretypes.FormatRequestOutcome(err))
 	vmctx.log.Infof("eventlog -> '%s'", msg)
 	vmctx.StoreToEventLog(vmctx.reqHname, []byte(msg))
 }
@@ -188,6 +256,9 @@ func (vmctx *VMContext) mustGetBaseValues() {
 	}
 	vmctx.chainOwnerID = info.ChainOwnerID
 	vmctx.feeColor, vmctx.ownerFee, vmctx.validatorFee = vmctx.getFeeInfo()
+	vmctx.maxCallDepth = info.MaxCallDepth
+	policy := vmctx.getRequestIntakePolicy()
+	vmctx.minDeposit, vmctx.depositPolicy = policy.MinDeposit, policy.DepositPolicy
 }
 
 // initRequestContext initializes VMContext for request and returns  if contract exists
@@ -195,12 +266,17 @@ func (vmctx *VMContext) initRequestContext(reqRef vm.RequestRefWithFreeTokens, t
 	reqHname := reqRef.RequestSection().Target().Hname()
 	vmctx.reqRef = reqRef
 	vmctx.reqHname = reqHname
+	vmctx.log = vmctx.taskLog.With(vm.LogFieldRequestID, reqRef.RequestID().String())
 
 	vmctx.timestamp = timestamp
+	vmctx.requestIndex = vmctx.numRequestsRun
+	vmctx.numRequestsRun++
 	vmctx.stateUpdate = state.NewStateUpdate(reqRef.RequestID()).WithTimestamp(timestamp)
 	vmctx.callStack = vmctx.callStack[:0]
+	vmctx.maxCallDepth = root.DefaultMaxCallDepth
 	vmctx.entropy = hashing.HashData(vmctx.entropy[:])
 	vmctx.remainingAfterFees = cbalances.NewFromMap(nil)
+	vmctx.prunable = eventlog.MaxPrunedPerRequest
 
 	vmctx.contractRecord, _ = vmctx.findContractByHname(vmctx.reqHname)
 }
@@ -12,6 +12,8 @@ type stateWrapper struct {
 	contractSubPartitionPrefix kv.Key
 	virtualState               state.VirtualState
 	stateUpdate                state.StateUpdate
+	// execution units consumed by state accesses are added to it, if not nil
+	execUnits *int64
 }
 
 func newStateWrapper(contractHname coretypes.Hname, virtualState state.VirtualState, stateUpdate state.StateUpdate) stateWrapper {
@@ -28,14 +30,23 @@ func (s *stateWrapper) addContractSubPartition(key kv.Key) kv.Key {
 }
 
 func (vmctx *VMContext) stateWrapper() stateWrapper {
-	return newStateWrapper(
+	ret := newStateWrapper(
 		vmctx.CurrentContractHname(),
 		vmctx.virtualState,
 		vmctx.stateUpdate,
 	)
+	ret.execUnits = &vmctx.execUnits
+	return ret
+}
+
+func (s stateWrapper) charge(units int64) {
+	if s.execUnits != nil {
+		*s.execUnits += units
+	}
 }
 
 func (s stateWrapper) Has(name kv.Key) (bool, error) {
+	s.charge(costStateRead)
 	name = s.addContractSubPartition(name)
 	mut := s.stateUpdate.Mutations().Latest(name)
 	if mut != nil {
@@ -45,8 +56,10 @@ func (s stateWrapper) Has(name kv.Key) (bool, error) {
 }
 
 func (s stateWrapper) Iterate(prefix kv.Key, f func(kv.Key, []byte) bool) error {
+	s.charge(costStateRead)
 	prefix = s.addContractSubPartition(prefix)
 	seen, done := s.stateUpdate.Mutations().IterateValues(prefix, func(key kv.Key, value []byte) bool {
+		s.charge(costIteratedItem)
 		return f(key[len(s.contractSubPartitionPrefix):], value)
 	})
 	if done {
@@ -54,16 +67,19 @@ func (s stateWrapper) Iterate(prefix kv.Key, f func(kv.Key, []byte) bool) error
 	}
 	return s.virtualState.Variables().Iterate(prefix, func(key kv.Key, value []byte) bool {
 		_, ok := seen[key]
-		if ok {
+		if ok || s.stateUpdate.Mutations().IsDeletedByPrefix(key) {
 			return true
 		}
+		s.charge(costIteratedItem)
 		return f(key[len(s.contractSubPartitionPrefix):], value)
 	})
 }
 
 func (s stateWrapper) IterateKeys(prefix kv.Key, f func(key kv.Key) bool) error {
+	s.charge(costStateRead)
 	prefix = s.addContractSubPartition(prefix)
 	seen, done := s.stateUpdate.Mutations().IterateValues(prefix, func(key kv.Key, value []byte) bool {
+		s.charge(costIteratedItem)
 		return f(key[len(s.contractSubPartitionPrefix):])
 	})
 	if done {
@@ -71,14 +87,16 @@ func (s stateWrapper) IterateKeys(prefix kv.Key, f func(key kv.Key) bool) error
 	}
 	return s.virtualState.Variables().IterateKeys(prefix, func(key kv.Key) bool {
 		_, ok := seen[key]
-		if ok {
+		if ok || s.stateUpdate.Mutations().IsDeletedByPrefix(key) {
 			return true
 		}
+		s.charge(costIteratedItem)
 		return f(key[len(s.contractSubPartitionPrefix):])
 	})
 }
 
 func (s stateWrapper) Get(name kv.Key) ([]byte, error) {
+	s.charge(costStateRead)
 	name = s.addContractSubPartition(name)
 	mut := s.stateUpdate.Mutations().Latest(name)
 	if mut != nil {
@@ -88,11 +106,19 @@ func (s stateWrapper) Get(name kv.Key) ([]byte, error) {
 }
 
 func (s stateWrapper) Del(name kv.Key) {
+	s.charge(costStateWrite)
 	name = s.addContractSubPartition(name)
 	s.stateUpdate.Mutations().Add(buffered.NewMutationDel(name))
 }
 
+func (s stateWrapper) DelPrefix(prefix kv.Key) {
+	s.charge(costStateWrite)
+	prefix = s.addContractSubPartition(prefix)
+	s.stateUpdate.Mutations().Add(buffered.NewMutationDelPrefix(prefix))
+}
+
 func (s stateWrapper) Set(name kv.Key, value []byte) {
+	s.charge(costStateWrite)
 	name = s.addContractSubPartition(name)
 	s.stateUpdate.Mutations().Add(buffered.NewMutationSet(name, value))
 }
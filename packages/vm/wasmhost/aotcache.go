@@ -0,0 +1,142 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmhost
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/hashing"
+)
+
+// WasmTimeVersion is the version of the wasmtime library. Compiled artifacts are only valid for
+// the version they were compiled with, so the version is part of the cache key.
+// Must be updated together with the version of wasmtime-go in go.mod
+const WasmTimeVersion = "v0.21.0"
+
+const aotCacheFileExt = ".aot"
+
+// AotCache is the on-disk cache of ahead-of-time compiled wasm modules, keyed by the hash of the
+// wasm binary and the version of the compiler. Each file contains the hash of the compiled artifact
+// followed by the artifact. Files with wrong hashes are deleted. When the total size of the cache
+// exceeds the limit, the least recently used files are evicted
+type AotCache struct {
+	dir     string
+	maxSize int64
+	mutex   sync.Mutex
+}
+
+// WasmTimeCache is the cache used by WasmTimeVM. Nil means modules are always compiled
+var WasmTimeCache *AotCache
+
+// NewAotCache creates the cache in the directory. maxSize is the limit of the total size in bytes, 0 means unlimited
+func NewAotCache(dir string, maxSize int64) (*AotCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &AotCache{dir: dir, maxSize: maxSize}, nil
+}
+
+func (c *AotCache) fileName(wasmData []byte, version string) string {
+	h := hashing.HashData(wasmData)
+	return filepath.Join(c.dir, h.String()+"-"+version+aotCacheFileExt)
+}
+
+// Get returns the artifact compiled from the wasm binary with the compiler version, if cached and intact
+func (c *AotCache) Get(wasmData []byte, version string) ([]byte, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	fname := c.fileName(wasmData, version)
+	data, err := ioutil.ReadFile(fname)
+	if err != nil {
+		return nil, false
+	}
+	if len(data) < hashing.HashSize {
+		_ = os.Remove(fname)
+		return nil, false
+	}
+	artifact := data[hashing.HashSize:]
+	h := hashing.HashData(artifact)
+	if !bytes.Equal(h[:], data[:hashing.HashSize]) {
+		// corrupted
+		_ = os.Remove(fname)
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(fname, now, now)
+	return artifact, true
+}
+
+// Put stores the artifact compiled from the wasm binary with the compiler version
+func (c *AotCache) Put(wasmData []byte, version string, artifact []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.maxSize > 0 && int64(hashing.HashSize+len(artifact)) > c.maxSize {
+		// never fits
+		return nil
+	}
+	h := hashing.HashData(artifact)
+	tmp, err := ioutil.TempFile(c.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(h[:])
+	if err == nil {
+		_, err = tmp.Write(artifact)
+	}
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	// the file appears in the cache atomically
+	if err := os.Rename(tmp.Name(), c.fileName(wasmData, version)); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return c.evict()
+}
+
+// evict removes the least recently used files until the total size is within the limit
+func (c *AotCache) evict() error {
+	if c.maxSize <= 0 {
+		return nil
+	}
+	infos, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	files := make([]os.FileInfo, 0, len(infos))
+	total := int64(0)
+	for _, fi := range infos {
+		if fi.IsDir() || !strings.HasSuffix(fi.Name(), aotCacheFileExt) {
+			continue
+		}
+		files = append(files, fi)
+		total += fi.Size()
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+	for _, fi := range files {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, fi.Name())); err != nil {
+			return err
+		}
+		total -= fi.Size()
+	}
+	return nil
+}
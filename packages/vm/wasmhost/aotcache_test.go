@@ -0,0 +1,79 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmhost
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAotCachePutGet(t *testing.T) {
+	c, err := NewAotCache(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	wasm := []byte("wasm binary")
+	_, ok := c.Get(wasm, WasmTimeVersion)
+	require.False(t, ok)
+
+	require.NoError(t, c.Put(wasm, WasmTimeVersion, []byte("artifact")))
+	artifact, ok := c.Get(wasm, WasmTimeVersion)
+	require.True(t, ok)
+	require.EqualValues(t, "artifact", string(artifact))
+
+	// artifacts of other versions of the compiler are not used
+	_, ok = c.Get(wasm, "v0.0.0")
+	require.False(t, ok)
+}
+
+func TestAotCacheCorrupted(t *testing.T) {
+	c, err := NewAotCache(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	wasm := []byte("wasm binary")
+	require.NoError(t, c.Put(wasm, WasmTimeVersion, []byte("artifact")))
+
+	fname := c.fileName(wasm, WasmTimeVersion)
+	data, err := ioutil.ReadFile(fname)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(fname, data, 0644))
+
+	_, ok := c.Get(wasm, WasmTimeVersion)
+	require.False(t, ok)
+	_, err = os.Stat(fname)
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestAotCacheEviction(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewAotCache(dir, 150)
+	require.NoError(t, err)
+
+	artifact := make([]byte, 40)
+	wasm1, wasm2, wasm3 := []byte("wasm1"), []byte("wasm2"), []byte("wasm3")
+	require.NoError(t, c.Put(wasm1, WasmTimeVersion, artifact))
+	require.NoError(t, c.Put(wasm2, WasmTimeVersion, artifact))
+	// wasm1 is used more recently than wasm2
+	past := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(c.fileName(wasm2, WasmTimeVersion), past, past))
+
+	require.NoError(t, c.Put(wasm3, WasmTimeVersion, artifact))
+	_, ok := c.Get(wasm2, WasmTimeVersion)
+	require.False(t, ok)
+	_, ok = c.Get(wasm1, WasmTimeVersion)
+	require.True(t, ok)
+	_, ok = c.Get(wasm3, WasmTimeVersion)
+	require.True(t, ok)
+
+	// too large for the cache
+	require.NoError(t, c.Put([]byte("wasm4"), WasmTimeVersion, make([]byte, 200)))
+	files, err := filepath.Glob(filepath.Join(dir, "*"+aotCacheFileExt))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
@@ -56,7 +56,14 @@ func (host *KvStoreHost) Init(null HostObject, root HostObject, log *logger.Logg
 	host.objIdToObj = nil
 	host.keyIdToKey = [][]byte{[]byte("<null>")}
 	host.keyToKeyId = make(map[string]int32)
-	host.keyIdToKeyMap = make([][]byte, len(keyMap)+1)
+	// predefined keys are not contiguous, the version marker is not in the map
+	maxKey := int32(0)
+	for _, v := range keyMap {
+		if -v > maxKey {
+			maxKey = -v
+		}
+	}
+	host.keyIdToKeyMap = make([][]byte, maxKey+1)
 	for k, v := range keyMap {
 		host.keyIdToKeyMap[-v] = []byte(k)
 	}
@@ -5,6 +5,8 @@ package wasmhost
 
 import (
 	"errors"
+	"sort"
+
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/coretypes"
 )
@@ -37,6 +39,20 @@ func (host *WasmHost) IsView(function string) bool {
 	return (host.funcToIndex[function] & 0x8000) != 0
 }
 
+// EntryPoints lists functions exported by the loaded contract sorted by name, implements coretypes.ProcessorDescriber.
+// Parameters of Wasm functions are not known to the host
+func (host *WasmHost) EntryPoints() []coretypes.EntryPointInfo {
+	ret := make([]coretypes.EntryPointInfo, 0, len(host.funcToIndex))
+	for name := range host.funcToIndex {
+		ret = append(ret, coretypes.EntryPointInfo{
+			Name:   name,
+			IsView: host.IsView(name),
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
 func (host *WasmHost) LoadWasm(wasmData []byte) error {
 	err := host.vm.LoadWasm(wasmData)
 	if err != nil {
@@ -62,6 +78,13 @@ func (host *WasmHost) RunScFunction(functionName string) (err error) {
 	return host.vm.RunScFunction(index)
 }
 
+// Interrupt stops the Wasm code running in the host, if the VM supports it, see coretypes.Interruptible
+func (host *WasmHost) Interrupt() {
+	if vm, ok := host.vm.(coretypes.Interruptible); ok {
+		vm.Interrupt()
+	}
+}
+
 func (host *WasmHost) SetExport(index int32, functionName string) {
 	if index < 0 {
 		// double check that predefined keys are in sync
@@ -46,47 +46,88 @@ const (
 	// to the keys give this one a different value and make sure
 	// the client side in wasplib is updated accordingly
 	KeyZzzzzzz = int32(-41)
+
+	// Keys of the host utility functions added after the version marker.
+	// They extend the key set without invalidating already compiled contracts
+	KeyHashKeccak256  = int32(-42)
+	KeySecp256k1Valid = int32(-43)
+	KeyUint256        = int32(-44)
+
+	// Keys of the request metadata, see coretypes.RequestMetadata
+	KeyBatchSize      = int32(-45)
+	KeyBlockIndex     = int32(-46)
+	KeyBlockTimestamp = int32(-47)
+	KeyRequestIndex   = int32(-48)
+	KeyRequestSender  = int32(-49)
+	KeyRequestTxId    = int32(-50)
+
+	// Keys of the composition of the request transfer, see coretypes.RequestTransfer
+	KeyRequestDeclared  = int32(-51)
+	KeyRequestDeposit   = int32(-52)
+	KeyRequestDustAdded = int32(-53)
+	KeyRequestFees      = int32(-54)
+
+	// Keys of the verifiable random output of the block, see coretypes.VRF
+	KeyVrf      = int32(-55)
+	KeyVrfSeed  = int32(-56)
+	KeyVrfProof = int32(-57)
 )
 
 var keyMap = map[string]int32{
-	"address":         KeyAddress,
-	"balances":        KeyBalances,
-	"base58Bytes":     KeyBase58Bytes,
-	"base58String":    KeyBase58String,
-	"blsAddress":      KeyBlsAddress,
-	"blsAggregate":    KeyBlsAggregate,
-	"blsValid":        KeyBlsValid,
-	"call":            KeyCall,
-	"caller":          KeyCaller,
-	"chainOwnerId":    KeyChainOwnerId,
-	"color":           KeyColor,
-	"contractCreator": KeyContractCreator,
-	"contractId":      KeyContractId,
-	"deploy":          KeyDeploy,
-	"ed25519Address":  KeyEd25519Address,
-	"ed25519Valid":    KeyEd25519Valid,
-	"event":           KeyEvent,
-	"exports":         KeyExports,
-	"hashBlake2b":     KeyHashBlake2b,
-	"hashSha3":        KeyHashSha3,
-	"hname":           KeyHname,
-	"incoming":        KeyIncoming,
-	"length":          KeyLength,
-	"log":             KeyLog,
-	"maps":            KeyMaps,
-	"minted":          KeyMinted,
-	"name":            KeyName,
-	"panic":           KeyPanic,
-	"params":          KeyParams,
-	"post":            KeyPost,
-	"random":          KeyRandom,
-	"requestId":       KeyRequestId,
-	"results":         KeyResults,
-	"return":          KeyReturn,
-	"state":           KeyState,
-	"timestamp":       KeyTimestamp,
-	"trace":           KeyTrace,
-	"transfers":       KeyTransfers,
-	"utility":         KeyUtility,
-	"valid":           KeyValid,
+	"address":          KeyAddress,
+	"balances":         KeyBalances,
+	"base58Bytes":      KeyBase58Bytes,
+	"base58String":     KeyBase58String,
+	"batchSize":        KeyBatchSize,
+	"blockIndex":       KeyBlockIndex,
+	"blockTimestamp":   KeyBlockTimestamp,
+	"blsAddress":       KeyBlsAddress,
+	"blsAggregate":     KeyBlsAggregate,
+	"blsValid":         KeyBlsValid,
+	"call":             KeyCall,
+	"caller":           KeyCaller,
+	"chainOwnerId":     KeyChainOwnerId,
+	"color":            KeyColor,
+	"contractCreator":  KeyContractCreator,
+	"contractId":       KeyContractId,
+	"deploy":           KeyDeploy,
+	"ed25519Address":   KeyEd25519Address,
+	"ed25519Valid":     KeyEd25519Valid,
+	"event":            KeyEvent,
+	"exports":          KeyExports,
+	"hashBlake2b":      KeyHashBlake2b,
+	"hashKeccak256":    KeyHashKeccak256,
+	"hashSha3":         KeyHashSha3,
+	"hname":            KeyHname,
+	"incoming":         KeyIncoming,
+	"length":           KeyLength,
+	"log":              KeyLog,
+	"maps":             KeyMaps,
+	"minted":           KeyMinted,
+	"name":             KeyName,
+	"panic":            KeyPanic,
+	"params":           KeyParams,
+	"post":             KeyPost,
+	"random":           KeyRandom,
+	"requestDeclared":  KeyRequestDeclared,
+	"requestDeposit":   KeyRequestDeposit,
+	"requestDustAdded": KeyRequestDustAdded,
+	"requestFees":      KeyRequestFees,
+	"requestId":        KeyRequestId,
+	"requestIndex":     KeyRequestIndex,
+	"requestSender":    KeyRequestSender,
+	"requestTxId":      KeyRequestTxId,
+	"results":          KeyResults,
+	"return":           KeyReturn,
+	"secp256k1Valid":   KeySecp256k1Valid,
+	"state":            KeyState,
+	"timestamp":        KeyTimestamp,
+	"trace":            KeyTrace,
+	"transfers":        KeyTransfers,
+	"uint256":          KeyUint256,
+	"utility":          KeyUtility,
+	"valid":            KeyValid,
+	"vrf":              KeyVrf,
+	"vrfProof":         KeyVrfProof,
+	"vrfSeed":          KeyVrfSeed,
 }
@@ -5,21 +5,30 @@ package wasmhost
 
 import (
 	"errors"
+
 	"github.com/bytecodealliance/wasmtime-go"
+	"go.uber.org/atomic"
 )
 
 type WasmTimeVM struct {
 	WasmVmBase
-	instance *wasmtime.Instance
-	linker   *wasmtime.Linker
-	memory   *wasmtime.Memory
-	module   *wasmtime.Module
-	store    *wasmtime.Store
+	instance  *wasmtime.Instance
+	interrupt *wasmtime.InterruptHandle
+	linker    *wasmtime.Linker
+	memory    *wasmtime.Memory
+	module    *wasmtime.Module
+	store     *wasmtime.Store
+	// number of nested calls of Wasm code in progress
+	running atomic.Int32
 }
 
 func NewWasmTimeVM() *WasmTimeVM {
 	vm := &WasmTimeVM{}
-	vm.store = wasmtime.NewStore(wasmtime.NewEngine())
+	config := wasmtime.NewConfig()
+	// running code can be stopped from another goroutine, see Interrupt
+	config.SetInterruptable(true)
+	vm.store = wasmtime.NewStore(wasmtime.NewEngineWithConfig(config))
+	vm.interrupt, _ = vm.store.InterruptHandle()
 	vm.linker = wasmtime.NewLinker(vm.store)
 	return vm
 }
@@ -67,7 +76,7 @@ func (vm *WasmTimeVM) LinkHost(impl WasmVM, host *WasmHost) error {
 
 func (vm *WasmTimeVM) LoadWasm(wasmData []byte) error {
 	var err error
-	vm.module, err = wasmtime.NewModule(vm.store.Engine, wasmData)
+	vm.module, err = vm.loadModule(wasmData)
 	if err != nil {
 		return err
 	}
@@ -86,11 +95,36 @@ func (vm *WasmTimeVM) LoadWasm(wasmData []byte) error {
 	return nil
 }
 
+// loadModule deserializes the compiled module from WasmTimeCache or compiles it and stores it in the cache
+func (vm *WasmTimeVM) loadModule(wasmData []byte) (*wasmtime.Module, error) {
+	if WasmTimeCache == nil {
+		return wasmtime.NewModule(vm.store.Engine, wasmData)
+	}
+	if artifact, ok := WasmTimeCache.Get(wasmData, WasmTimeVersion); ok {
+		module, err := wasmtime.NewModuleDeserialize(vm.store.Engine, artifact)
+		if err == nil {
+			return module, nil
+		}
+		// not usable by this engine, compile again
+	}
+	module, err := wasmtime.NewModule(vm.store.Engine, wasmData)
+	if err != nil {
+		return nil, err
+	}
+	if artifact, err := module.Serialize(); err == nil {
+		// failure to cache is not fatal
+		_ = WasmTimeCache.Put(wasmData, WasmTimeVersion, artifact)
+	}
+	return module, nil
+}
+
 func (vm *WasmTimeVM) RunFunction(functionName string) error {
 	export := vm.instance.GetExport(functionName)
 	if export == nil {
 		return errors.New("unknown export function: '" + functionName + "'")
 	}
+	vm.running.Inc()
+	defer vm.running.Dec()
 	_, err := export.Func().Call()
 	return err
 }
@@ -101,11 +135,23 @@ func (vm *WasmTimeVM) RunScFunction(index int32) error {
 		return errors.New("unknown export function: 'on_call_entrypoint'")
 	}
 	frame := vm.PreCall()
+	vm.running.Inc()
 	_, err := export.Func().Call(index)
+	vm.running.Dec()
 	vm.PostCall(frame)
 	return err
 }
 
+// Interrupt stops the Wasm code running in the VM. It may be called from any goroutine: the running call
+// traps and returns the error. Does nothing if no Wasm code is running. Note that Wasmtime keeps the
+// interrupt pending if the code returns at the same moment, so the next call of the VM traps immediately
+func (vm *WasmTimeVM) Interrupt() {
+	if vm.interrupt == nil || vm.running.Load() == 0 {
+		return
+	}
+	vm.interrupt.Interrupt()
+}
+
 func (vm *WasmTimeVM) UnsafeMemory() []byte {
 	return vm.memory.UnsafeData()
 }
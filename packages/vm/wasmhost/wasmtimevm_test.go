@@ -0,0 +1,38 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmhost
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bytecodealliance/wasmtime-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWasmTimeVMInterrupt(t *testing.T) {
+	wasm, err := wasmtime.Wat2Wasm(`(module
+		(memory (export "memory") 1)
+		(func (export "loop") (loop (br 0))))`)
+	require.NoError(t, err)
+	vm := NewWasmTimeVM()
+	require.NoError(t, vm.LoadWasm(wasm))
+
+	// nothing is running
+	vm.Interrupt()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- vm.RunFunction("loop")
+	}()
+	require.Eventually(t, func() bool { return vm.running.Load() > 0 }, 5*time.Second, time.Millisecond)
+	vm.Interrupt()
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("endless loop was not interrupted")
+	}
+	require.EqualValues(t, 0, vm.running.Load())
+}
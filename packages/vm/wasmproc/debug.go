@@ -0,0 +1,161 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmproc
+
+import (
+	"sync"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+)
+
+// Debugging bridge of wasm contracts.
+// A breakpoint pauses the wasm code at the boundary of the host function: the callback of the
+// breakpoint is called synchronously before the host function is executed, so the wasm code
+// doesn't continue until the callback returns. It is meant for tests, for example in Solo
+
+// host functions which can be intercepted by breakpoints
+const (
+	HostStateGet = "state.get"
+	HostStateSet = "state.set"
+	HostStateDel = "state.del"
+	HostCall     = "call"
+	HostDeploy   = "deploy"
+	HostEvent    = "event"
+	HostLog      = "log"
+	HostPost     = "post"
+)
+
+// DebugContext is the context of the intercepted host call
+type DebugContext struct {
+	// HostFunction is the intercepted host function, one of the Host* constants
+	HostFunction string
+	// ContractID is the contract which calls the host function
+	ContractID coretypes.ContractID
+	// Function is the name of the entry point being executed
+	Function string
+	// Key is the key of the state for state functions, empty otherwise
+	Key kv.Key
+	// Value is the value being set for HostStateSet, the current value for HostStateGet,
+	// the encoded argument of the host function otherwise
+	Value []byte
+	// Sandbox is the sandbox of the request, nil for views
+	Sandbox coretypes.Sandbox
+	// SandboxView is the sandbox of the view call, nil for requests
+	SandboxView coretypes.SandboxView
+}
+
+// Breakpoint intercepts calls of the host function
+type Breakpoint struct {
+	// HostFunction is the host function to intercept, one of the Host* constants
+	HostFunction string
+	// ChainID restricts the breakpoint to contracts of the chain, any chain if nil
+	ChainID *coretypes.ChainID
+	// Contract restricts the breakpoint to the contract, any contract if 0
+	Contract coretypes.Hname
+	// Key restricts the state breakpoint to the key, any key if empty
+	Key kv.Key
+	// Callback is called with the context of the intercepted call
+	Callback func(ctx *DebugContext)
+}
+
+var (
+	breakpoints      = make(map[*Breakpoint]struct{})
+	breakpointsMutex = &sync.RWMutex{}
+)
+
+// AddBreakpoint activates the breakpoint in all wasm processors. It returns the function which removes it
+func AddBreakpoint(bp *Breakpoint) func() {
+	breakpointsMutex.Lock()
+	defer breakpointsMutex.Unlock()
+
+	breakpoints[bp] = struct{}{}
+	return func() {
+		breakpointsMutex.Lock()
+		defer breakpointsMutex.Unlock()
+		delete(breakpoints, bp)
+	}
+}
+
+func (bp *Breakpoint) matches(hostFunction string, contractID coretypes.ContractID, key kv.Key) bool {
+	if bp.HostFunction != hostFunction {
+		return false
+	}
+	if bp.ChainID != nil && contractID.ChainID() != *bp.ChainID {
+		return false
+	}
+	if bp.Contract != 0 && contractID.Hname() != bp.Contract {
+		return false
+	}
+	return bp.Key == "" || bp.Key == key
+}
+
+// debugBreak calls callbacks of all breakpoints matching the host call
+func (host *wasmProcessor) debugBreak(hostFunction string, key kv.Key, value []byte) {
+	breakpointsMutex.RLock()
+	if len(breakpoints) == 0 {
+		breakpointsMutex.RUnlock()
+		return
+	}
+	contractID := host.contractID()
+	matching := make([]*Breakpoint, 0)
+	for bp := range breakpoints {
+		if bp.matches(hostFunction, contractID, key) {
+			matching = append(matching, bp)
+		}
+	}
+	// callbacks are called unlocked, so they can add and remove breakpoints
+	breakpointsMutex.RUnlock()
+
+	for _, bp := range matching {
+		bp.Callback(&DebugContext{
+			HostFunction: hostFunction,
+			ContractID:   contractID,
+			Function:     host.function,
+			Key:          key,
+			Value:        value,
+			Sandbox:      host.ctx,
+			SandboxView:  host.ctxView,
+		})
+	}
+}
+
+// debugState wraps the state of the contract to intercept its access by breakpoints
+type debugState struct {
+	kv.KVStore
+	host *wasmProcessor
+}
+
+func newDebugState(host *wasmProcessor, state kv.KVStore) kv.KVStore {
+	return &debugState{KVStore: state, host: host}
+}
+
+func (s *debugState) Set(key kv.Key, value []byte) {
+	s.host.debugBreak(HostStateSet, key, value)
+	s.KVStore.Set(key, value)
+}
+
+func (s *debugState) Del(key kv.Key) {
+	s.host.debugBreak(HostStateDel, key, nil)
+	s.KVStore.Del(key)
+}
+
+func (s *debugState) DelPrefix(prefix kv.Key) {
+	s.host.debugBreak(HostStateDel, prefix, nil)
+	s.KVStore.DelPrefix(prefix)
+}
+
+func (s *debugState) Get(key kv.Key) ([]byte, error) {
+	value, err := s.KVStore.Get(key)
+	if err == nil {
+		s.host.debugBreak(HostStateGet, key, value)
+	}
+	return value, err
+}
+
+func (s *debugState) MustGet(key kv.Key) []byte {
+	value := s.KVStore.MustGet(key)
+	s.host.debugBreak(HostStateGet, key, value)
+	return value
+}
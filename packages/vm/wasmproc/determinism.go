@@ -0,0 +1,709 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmproc
+
+// Validation of wasm binaries for deterministic execution.
+// All nodes of the committee must come to exactly the same result of the contract, so wasm features
+// with nondeterministic semantics are gated before the binary is loaded:
+//  - threads (shared memory, atomic instructions) and SIMD are always rejected
+//  - floating point instructions are allowed, rejected or transformed according to the float policy
+//    of the chain, see root.WasmFloatsAllow, root.WasmFloatsCanonicalize and root.WasmFloatsReject.
+//    Float arithmetic is deterministic in wasm except for the bit pattern of NaN results.
+//    The transformation replaces each NaN produced by arithmetic instructions with the canonical NaN
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+const (
+	wasmSectionType     = 1
+	wasmSectionImport   = 2
+	wasmSectionFunction = 3
+	wasmSectionMemory   = 5
+	wasmSectionGlobal   = 6
+	wasmSectionCode     = 10
+
+	wasmTypeI32  = 0x7f
+	wasmTypeI64  = 0x7e
+	wasmTypeF32  = 0x7d
+	wasmTypeF64  = 0x7c
+	wasmTypeV128 = 0x7b
+	wasmTypeFunc = 0x70
+	wasmTypeExt  = 0x6f
+	wasmTypeNone = 0x40
+
+	wasmPrefixMisc    = 0xfc
+	wasmPrefixSIMD    = 0xfd
+	wasmPrefixThreads = 0xfe
+
+	wasmCanonicalNaN32 = uint32(0x7fc00000)
+	wasmCanonicalNaN64 = uint64(0x7ff8000000000000)
+)
+
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+// WasmFeatures are the features of the wasm binary relevant for deterministic execution
+type WasmFeatures struct {
+	// Floats is true if the binary has floating point types or instructions
+	Floats bool
+	// NaNOps is the number of float instructions which may produce NaN with nondeterministic bits
+	NaNOps int
+	// SIMD is true if the binary has v128 types or SIMD instructions
+	SIMD bool
+	// Threads is true if the binary has shared memories or atomic instructions
+	Threads bool
+}
+
+// PrepareBinary checks the wasm binary against the float policy of the chain and returns the binary to load.
+// It fails if the binary uses threads or SIMD, or floats with root.WasmFloatsReject.
+// With root.WasmFloatsCanonicalize the binary is transformed to produce canonical NaNs
+func PrepareBinary(binaryCode []byte, floatPolicy int64) ([]byte, error) {
+	features, err := AnalyzeWasm(binaryCode)
+	if err != nil {
+		return nil, err
+	}
+	if features.Threads {
+		return nil, fmt.Errorf("wasm binary uses threads, which are nondeterministic")
+	}
+	if features.SIMD {
+		return nil, fmt.Errorf("wasm binary uses SIMD, which is nondeterministic")
+	}
+	switch floatPolicy {
+	case root.WasmFloatsAllow:
+		return binaryCode, nil
+	case root.WasmFloatsReject:
+		if features.Floats {
+			return nil, fmt.Errorf("wasm binary uses floating point, which is rejected by the chain")
+		}
+		return binaryCode, nil
+	case root.WasmFloatsCanonicalize:
+		if features.NaNOps == 0 {
+			return binaryCode, nil
+		}
+		return CanonicalizeNaNs(binaryCode)
+	}
+	return nil, fmt.Errorf("wrong float policy %d", floatPolicy)
+}
+
+// AnalyzeWasm parses the wasm binary and detects its features relevant for deterministic execution
+func AnalyzeWasm(binaryCode []byte) (*WasmFeatures, error) {
+	ret := &WasmFeatures{}
+	err := walkWasm(binaryCode, ret, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// CanonicalizeNaNs transforms the wasm binary so that each float instruction which may produce NaN
+// is followed by the code replacing any NaN result with the canonical NaN.
+// Each function with such instructions gets two new locals, f32 and f64, for the check
+func CanonicalizeNaNs(binaryCode []byte) ([]byte, error) {
+	var out bytes.Buffer
+	err := walkWasm(binaryCode, &WasmFeatures{}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// walkWasm parses the binary and collects its features. If 'out' is not nil, the binary with
+// canonicalized NaNs is written to it
+func walkWasm(binaryCode []byte, features *WasmFeatures, out *bytes.Buffer) error {
+	if len(binaryCode) < len(wasmMagic) || !bytes.Equal(binaryCode[:len(wasmMagic)], wasmMagic) {
+		return fmt.Errorf("not a wasm binary")
+	}
+	if out != nil {
+		out.Write(wasmMagic)
+	}
+	w := &wasmWalker{features: features}
+	r := &wasmReader{data: binaryCode, pos: len(wasmMagic)}
+	for !r.eof() {
+		id, err := r.byte()
+		if err != nil {
+			return err
+		}
+		size, err := r.u32()
+		if err != nil {
+			return err
+		}
+		content, err := r.bytes(size)
+		if err != nil {
+			return err
+		}
+		section := &wasmReader{data: content}
+		var transformed []byte
+		switch id {
+		case wasmSectionType:
+			err = w.typeSection(section)
+		case wasmSectionImport:
+			err = w.importSection(section)
+		case wasmSectionFunction:
+			err = w.functionSection(section)
+		case wasmSectionMemory:
+			err = w.memorySection(section)
+		case wasmSectionGlobal:
+			err = w.globalSection(section)
+		case wasmSectionCode:
+			transformed, err = w.codeSection(section, out != nil)
+		}
+		if err != nil {
+			return fmt.Errorf("wasm section %d: %v", id, err)
+		}
+		if out == nil {
+			continue
+		}
+		if transformed == nil {
+			transformed = content
+		}
+		out.WriteByte(id)
+		writeU32(out, uint32(len(transformed)))
+		out.Write(transformed)
+	}
+	return nil
+}
+
+type wasmWalker struct {
+	features *WasmFeatures
+	// numParams is the number of parameters of each function type
+	numParams []uint32
+	// funcTypes are type indices of functions defined in the code section
+	funcTypes []uint32
+}
+
+func (w *wasmWalker) valueType(t byte) error {
+	switch t {
+	case wasmTypeI32, wasmTypeI64, wasmTypeFunc, wasmTypeExt:
+	case wasmTypeF32, wasmTypeF64:
+		w.features.Floats = true
+	case wasmTypeV128:
+		w.features.SIMD = true
+	default:
+		return fmt.Errorf("unknown value type 0x%02x", t)
+	}
+	return nil
+}
+
+func (w *wasmWalker) valueTypes(r *wasmReader) (uint32, error) {
+	n, err := r.u32()
+	if err != nil {
+		return 0, err
+	}
+	for i := uint32(0); i < n; i++ {
+		t, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		if err := w.valueType(t); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+func (w *wasmWalker) typeSection(r *wasmReader) error {
+	n, err := r.u32()
+	if err != nil {
+		return err
+	}
+	w.numParams = make([]uint32, n)
+	for i := range w.numParams {
+		form, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if form != 0x60 {
+			return fmt.Errorf("unknown type form 0x%02x", form)
+		}
+		if w.numParams[i], err = w.valueTypes(r); err != nil {
+			return err
+		}
+		if _, err = w.valueTypes(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wasmWalker) importSection(r *wasmReader) error {
+	n, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < n; i++ {
+		// module and field names
+		for j := 0; j < 2; j++ {
+			size, err := r.u32()
+			if err != nil {
+				return err
+			}
+			if _, err = r.bytes(size); err != nil {
+				return err
+			}
+		}
+		kind, err := r.byte()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case 0: // function
+			_, err = r.u32()
+		case 1: // table
+			if _, err = r.byte(); err == nil {
+				err = w.limits(r)
+			}
+		case 2: // memory
+			err = w.limits(r)
+		case 3: // global
+			err = w.globalType(r)
+		default:
+			err = fmt.Errorf("unknown import kind %d", kind)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wasmWalker) functionSection(r *wasmReader) error {
+	n, err := r.u32()
+	if err != nil {
+		return err
+	}
+	w.funcTypes = make([]uint32, n)
+	for i := range w.funcTypes {
+		if w.funcTypes[i], err = r.u32(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wasmWalker) memorySection(r *wasmReader) error {
+	n, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < n; i++ {
+		if err := w.limits(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *wasmWalker) limits(r *wasmReader) error {
+	flags, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if flags&0x02 != 0 {
+		w.features.Threads = true
+	}
+	if _, err = r.u32(); err != nil {
+		return err
+	}
+	if flags&0x01 != 0 {
+		_, err = r.u32()
+	}
+	return err
+}
+
+func (w *wasmWalker) globalType(r *wasmReader) error {
+	t, err := r.byte()
+	if err != nil {
+		return err
+	}
+	if err := w.valueType(t); err != nil {
+		return err
+	}
+	// mutability
+	_, err = r.byte()
+	return err
+}
+
+func (w *wasmWalker) globalSection(r *wasmReader) error {
+	n, err := r.u32()
+	if err != nil {
+		return err
+	}
+	for i := uint32(0); i < n; i++ {
+		if err := w.globalType(r); err != nil {
+			return err
+		}
+		if err := w.initExpression(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// initExpression skips the constant expression terminated by 'end'
+func (w *wasmWalker) initExpression(r *wasmReader) error {
+	for {
+		op, err := r.byte()
+		if err != nil {
+			return err
+		}
+		if op == 0x0b {
+			return nil
+		}
+		if _, err := w.instruction(r, op); err != nil {
+			return err
+		}
+	}
+}
+
+// codeSection walks function bodies. If 'transform' is true, returns the section with canonicalized NaNs
+func (w *wasmWalker) codeSection(r *wasmReader, transform bool) ([]byte, error) {
+	n, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	if int(n) != len(w.funcTypes) {
+		return nil, fmt.Errorf("%d function bodies for %d functions", n, len(w.funcTypes))
+	}
+	var out bytes.Buffer
+	writeU32(&out, n)
+	for i := uint32(0); i < n; i++ {
+		size, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		body, err := r.bytes(size)
+		if err != nil {
+			return nil, err
+		}
+		newBody, err := w.functionBody(body, w.funcTypes[i], transform)
+		if err != nil {
+			return nil, fmt.Errorf("function #%d: %v", i, err)
+		}
+		writeU32(&out, uint32(len(newBody)))
+		out.Write(newBody)
+	}
+	if !transform {
+		return nil, nil
+	}
+	return out.Bytes(), nil
+}
+
+func (w *wasmWalker) functionBody(body []byte, typeIndex uint32, transform bool) ([]byte, error) {
+	if int(typeIndex) >= len(w.numParams) {
+		return nil, fmt.Errorf("wrong type index %d", typeIndex)
+	}
+	r := &wasmReader{data: body}
+	numGroups, err := r.u32()
+	if err != nil {
+		return nil, err
+	}
+	localsStart := r.pos
+	numLocals := w.numParams[typeIndex]
+	for i := uint32(0); i < numGroups; i++ {
+		count, err := r.u32()
+		if err != nil {
+			return nil, err
+		}
+		t, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		if err := w.valueType(t); err != nil {
+			return nil, err
+		}
+		numLocals += count
+	}
+	localsEnd := r.pos
+	if !transform {
+		_, err := w.instructions(r, r.len(), nil, 0)
+		return body, err
+	}
+	// the new locals for the NaN check are appended to the locals of the function
+	var code bytes.Buffer
+	nanOps, err := w.instructions(r, r.len(), &code, numLocals)
+	if err != nil {
+		return nil, err
+	}
+	if nanOps == 0 {
+		return body, nil
+	}
+	var out bytes.Buffer
+	writeU32(&out, numGroups+2)
+	out.Write(body[localsStart:localsEnd])
+	writeU32(&out, 1)
+	out.WriteByte(wasmTypeF32)
+	writeU32(&out, 1)
+	out.WriteByte(wasmTypeF64)
+	out.Write(code.Bytes())
+	return out.Bytes(), nil
+}
+
+// instructions walks instructions until the end position. If 'out' is not nil, the instructions are written
+// to it with NaN checks after NaN producing instructions. Returns the number of NaN producing instructions
+func (w *wasmWalker) instructions(r *wasmReader, end int, out *bytes.Buffer, tmpLocal uint32) (int, error) {
+	nanOps := 0
+	for r.pos < end {
+		start := r.pos
+		op, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		nanType, err := w.instruction(r, op)
+		if err != nil {
+			return 0, fmt.Errorf("instruction 0x%02x at %d: %v", op, start, err)
+		}
+		if nanType != 0 {
+			nanOps++
+			w.features.NaNOps++
+		}
+		if out == nil {
+			continue
+		}
+		out.Write(r.data[start:r.pos])
+		switch nanType {
+		case wasmTypeF32:
+			writeNaNCheck(out, tmpLocal, wasmTypeF32)
+		case wasmTypeF64:
+			writeNaNCheck(out, tmpLocal+1, wasmTypeF64)
+		}
+	}
+	return nanOps, nil
+}
+
+// instruction skips immediates of the instruction. Returns the type of the result if the instruction
+// may produce NaN, otherwise 0
+func (w *wasmWalker) instruction(r *wasmReader, op byte) (byte, error) {
+	var err error
+	switch {
+	case op == 0x02 || op == 0x03 || op == 0x04: // block, loop, if
+		err = w.blockType(r)
+	case op == 0x0c || op == 0x0d: // br, br_if
+		_, err = r.u32()
+	case op == 0x0e: // br_table
+		var n uint32
+		if n, err = r.u32(); err == nil {
+			for i := uint32(0); i <= n && err == nil; i++ {
+				_, err = r.u32()
+			}
+		}
+	case op == 0x10 || op == 0xd2: // call, ref.func
+		_, err = r.u32()
+	case op == 0x11: // call_indirect
+		if _, err = r.u32(); err == nil {
+			_, err = r.u32()
+		}
+	case op == 0x1c: // select t*
+		_, err = w.valueTypes(r)
+	case op >= 0x20 && op <= 0x26: // local.*, global.*, table.get/set
+		_, err = r.u32()
+	case op >= 0x28 && op <= 0x3e: // loads and stores
+		if op == 0x2a || op == 0x2b || op == 0x38 || op == 0x39 {
+			w.features.Floats = true
+		}
+		if _, err = r.u32(); err == nil {
+			_, err = r.u32()
+		}
+	case op == 0x3f || op == 0x40: // memory.size, memory.grow
+		_, err = r.u32()
+	case op == 0x41: // i32.const
+		_, err = r.s64()
+	case op == 0x42: // i64.const
+		_, err = r.s64()
+	case op == 0x43: // f32.const
+		w.features.Floats = true
+		_, err = r.bytes(4)
+	case op == 0x44: // f64.const
+		w.features.Floats = true
+		_, err = r.bytes(8)
+	case op == 0xd0: // ref.null
+		_, err = r.byte()
+	case op == wasmPrefixMisc:
+		err = w.miscInstruction(r)
+	case op == wasmPrefixSIMD:
+		w.features.SIMD = true
+		// immediates of SIMD instructions are not parsed, the binary is rejected anyway
+		r.pos = r.len()
+	case op == wasmPrefixThreads:
+		w.features.Threads = true
+		r.pos = r.len()
+	case op <= 0x01 || op == 0x05 || op == 0x0b || op == 0x0f || op == 0x1a || op == 0x1b ||
+		(op >= 0x45 && op <= 0xc4) || op == 0xd1:
+		// instructions without immediates
+		return w.numericInstruction(op), nil
+	default:
+		err = fmt.Errorf("unsupported instruction")
+	}
+	return 0, err
+}
+
+// numericInstruction detects float instructions. Returns the type of the result if the instruction
+// may produce NaN with nondeterministic bits, otherwise 0
+func (w *wasmWalker) numericInstruction(op byte) byte {
+	switch {
+	case op >= 0x5b && op <= 0x66, // float comparisons
+		op >= 0x8b && op <= 0xa6, // float arithmetic
+		op >= 0xa8 && op <= 0xab, // i32.trunc_f*
+		op >= 0xae && op <= 0xbf: // i64.trunc_f*, conversions, reinterpretations
+		w.features.Floats = true
+	default:
+		return 0
+	}
+	switch {
+	case op >= 0x8d && op <= 0x97, op == 0xb6: // f32 ceil..max, f32.demote_f64
+		return wasmTypeF32
+	case op >= 0x9b && op <= 0xa5, op == 0xbb: // f64 ceil..max, f64.promote_f32
+		return wasmTypeF64
+	}
+	return 0
+}
+
+func (w *wasmWalker) miscInstruction(r *wasmReader) error {
+	sub, err := r.u32()
+	if err != nil {
+		return err
+	}
+	switch {
+	case sub <= 7: // saturating truncations of floats
+		w.features.Floats = true
+	case sub == 9 || sub == 13 || sub >= 15 && sub <= 17: // data.drop, elem.drop, table.grow/size/fill
+		_, err = r.u32()
+	case sub == 8 || sub == 10 || sub == 12 || sub == 14: // memory.init, memory.copy, table.init, table.copy
+		if _, err = r.u32(); err == nil {
+			_, err = r.u32()
+		}
+	case sub == 11: // memory.fill
+		_, err = r.u32()
+	default:
+		err = fmt.Errorf("unsupported instruction 0xfc %d", sub)
+	}
+	return err
+}
+
+func (w *wasmWalker) blockType(r *wasmReader) error {
+	if r.eof() {
+		return fmt.Errorf("unexpected end of code")
+	}
+	t := r.data[r.pos]
+	if t == wasmTypeNone {
+		r.pos++
+		return nil
+	}
+	if t >= wasmTypeExt && t <= wasmTypeI32 {
+		r.pos++
+		return w.valueType(t)
+	}
+	// type index
+	_, err := r.s64()
+	return err
+}
+
+// writeNaNCheck writes the code which replaces the NaN on top of the stack with the canonical NaN:
+// local.tee tmp; const canonicalNaN; local.get tmp; local.get tmp; eq; select
+func writeNaNCheck(out *bytes.Buffer, tmp uint32, t byte) {
+	out.WriteByte(0x22)
+	writeU32(out, tmp)
+	if t == wasmTypeF32 {
+		out.WriteByte(0x43)
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], wasmCanonicalNaN32)
+		out.Write(b[:])
+	} else {
+		out.WriteByte(0x44)
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], wasmCanonicalNaN64)
+		out.Write(b[:])
+	}
+	out.WriteByte(0x20)
+	writeU32(out, tmp)
+	out.WriteByte(0x20)
+	writeU32(out, tmp)
+	if t == wasmTypeF32 {
+		out.WriteByte(0x5b)
+	} else {
+		out.WriteByte(0x61)
+	}
+	out.WriteByte(0x1b)
+}
+
+func writeU32(out *bytes.Buffer, v uint32) {
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			out.WriteByte(b)
+			return
+		}
+		out.WriteByte(b | 0x80)
+	}
+}
+
+type wasmReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *wasmReader) len() int {
+	return len(r.data)
+}
+
+func (r *wasmReader) eof() bool {
+	return r.pos >= len(r.data)
+}
+
+func (r *wasmReader) byte() (byte, error) {
+	if r.eof() {
+		return 0, fmt.Errorf("unexpected end of data")
+	}
+	r.pos++
+	return r.data[r.pos-1], nil
+}
+
+func (r *wasmReader) bytes(n uint32) ([]byte, error) {
+	if uint64(r.pos)+uint64(n) > uint64(len(r.data)) {
+		return nil, fmt.Errorf("unexpected end of data")
+	}
+	r.pos += int(n)
+	return r.data[r.pos-int(n) : r.pos], nil
+}
+
+func (r *wasmReader) u32() (uint32, error) {
+	var ret uint64
+	for shift := uint(0); shift < 35; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		ret |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			if ret > math.MaxUint32 {
+				return 0, fmt.Errorf("u32 overflow")
+			}
+			return uint32(ret), nil
+		}
+	}
+	return 0, fmt.Errorf("u32 too long")
+}
+
+func (r *wasmReader) s64() (int64, error) {
+	var ret int64
+	for shift := uint(0); shift < 70; shift += 7 {
+		b, err := r.byte()
+		if err != nil {
+			return 0, err
+		}
+		ret |= int64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			if shift+7 < 64 && b&0x40 != 0 {
+				ret |= -1 << (shift + 7)
+			}
+			return ret, nil
+		}
+	}
+	return 0, fmt.Errorf("s64 too long")
+}
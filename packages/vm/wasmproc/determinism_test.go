@@ -0,0 +1,103 @@
+package wasmproc
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/stretchr/testify/require"
+)
+
+// wasmModule builds the module with one function of the type and the body
+func wasmModule(funcType []byte, body []byte, extraSections ...byte) []byte {
+	ret := append([]byte{}, wasmMagic...)
+	ret = append(ret, wasmSectionType, byte(len(funcType)+1), 1)
+	ret = append(ret, funcType...)
+	ret = append(ret, wasmSectionFunction, 2, 1, 0)
+	ret = append(ret, extraSections...)
+	ret = append(ret, wasmSectionCode, byte(len(body)+2), 1, byte(len(body)))
+	return append(ret, body...)
+}
+
+var (
+	// (func (param f64 f64) (result i64) local.get 0 local.get 1 f64.div i64.reinterpret_f64)
+	divFuncType = []byte{0x60, 2, wasmTypeF64, wasmTypeF64, 1, wasmTypeI64}
+	divBody     = []byte{0, 0x20, 0, 0x20, 1, 0xa3, 0xbd, 0x0b}
+	// (func (param i32 i32) (result i32) local.get 0 local.get 1 i32.add)
+	addFuncType = []byte{0x60, 2, wasmTypeI32, wasmTypeI32, 1, wasmTypeI32}
+	addBody     = []byte{0, 0x20, 0, 0x20, 1, 0x6a, 0x0b}
+)
+
+func TestAnalyzeWasm(t *testing.T) {
+	features, err := AnalyzeWasm(wasmModule(addFuncType, addBody))
+	require.NoError(t, err)
+	require.EqualValues(t, WasmFeatures{}, *features)
+
+	features, err = AnalyzeWasm(wasmModule(divFuncType, divBody))
+	require.NoError(t, err)
+	require.EqualValues(t, WasmFeatures{Floats: true, NaNOps: 1}, *features)
+
+	// shared memory
+	features, err = AnalyzeWasm(wasmModule(addFuncType, addBody, wasmSectionMemory, 4, 1, 0x03, 1, 1))
+	require.NoError(t, err)
+	require.True(t, features.Threads)
+
+	// i32x4.add
+	simdBody := []byte{0, 0x20, 0, 0x20, 1, wasmPrefixSIMD, 0xae, 0x01, 0x0b}
+	features, err = AnalyzeWasm(wasmModule(addFuncType, simdBody))
+	require.NoError(t, err)
+	require.True(t, features.SIMD)
+
+	_, err = AnalyzeWasm([]byte("not wasm"))
+	require.Error(t, err)
+	_, err = AnalyzeWasm(wasmModule(addFuncType, addBody)[:20])
+	require.Error(t, err)
+}
+
+func TestAnalyzeWasmContract(t *testing.T) {
+	binary, err := ioutil.ReadFile("../core/testcore/sbtests/sbtestsc/testcore_bg.wasm")
+	require.NoError(t, err)
+	features, err := AnalyzeWasm(binary)
+	require.NoError(t, err)
+	require.False(t, features.Floats || features.SIMD || features.Threads)
+
+	ret, err := PrepareBinary(binary, root.WasmFloatsCanonicalize)
+	require.NoError(t, err)
+	require.EqualValues(t, binary, ret)
+}
+
+func TestCanonicalizeNaNs(t *testing.T) {
+	ret, err := CanonicalizeNaNs(wasmModule(divFuncType, divBody))
+	require.NoError(t, err)
+	// locals f32 and f64 after 2 params, the NaN check after f64.div
+	expected := []byte{2, 1, wasmTypeF32, 1, wasmTypeF64, 0x20, 0, 0x20, 1, 0xa3,
+		0x22, 3, 0x44, 0, 0, 0, 0, 0, 0, 0xf8, 0x7f, 0x20, 3, 0x20, 3, 0x61, 0x1b,
+		0xbd, 0x0b}
+	require.EqualValues(t, wasmModule(divFuncType, expected), ret)
+
+	features, err := AnalyzeWasm(ret)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, features.NaNOps)
+}
+
+func TestPrepareBinary(t *testing.T) {
+	floats := wasmModule(divFuncType, divBody)
+	ret, err := PrepareBinary(floats, root.WasmFloatsAllow)
+	require.NoError(t, err)
+	require.EqualValues(t, floats, ret)
+	_, err = PrepareBinary(floats, root.WasmFloatsReject)
+	require.Error(t, err)
+	ret, err = PrepareBinary(floats, root.WasmFloatsCanonicalize)
+	require.NoError(t, err)
+	require.NotEqual(t, floats, ret)
+	_, err = PrepareBinary(floats, 3)
+	require.Error(t, err)
+
+	noFloats := wasmModule(addFuncType, addBody)
+	ret, err = PrepareBinary(noFloats, root.WasmFloatsReject)
+	require.NoError(t, err)
+	require.EqualValues(t, noFloats, ret)
+
+	_, err = PrepareBinary(wasmModule(addFuncType, addBody, wasmSectionMemory, 4, 1, 0x03, 1, 1), root.WasmFloatsAllow)
+	require.Error(t, err)
+}
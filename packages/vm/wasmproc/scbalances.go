@@ -28,6 +28,19 @@ func NewScBalances(vm *wasmProcessor, incoming bool) *ScDict {
 	return loadBalances(o, vm.ctxView.Balances())
 }
 
+// NewScRequestBalances returns the transfer declared by the request or the fees taken from it,
+// see coretypes.RequestTransfer
+func NewScRequestBalances(vm *wasmProcessor, fees bool) *ScDict {
+	o := NewScDict(vm)
+	if vm.ctx == nil {
+		o.Panic("No request transfer on views")
+	}
+	if fees {
+		return loadBalances(o, vm.ctx.RequestTransfer().Fees)
+	}
+	return loadBalances(o, vm.ctx.RequestTransfer().Declared)
+}
+
 func loadBalances(o *ScDict, balances coretypes.ColoredBalances) *ScDict {
 	index := 0
 	key := o.host.GetKeyStringFromId(wasmhost.KeyColor)
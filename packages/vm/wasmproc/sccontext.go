@@ -15,30 +15,52 @@ import (
 )
 
 var typeIds = map[int32]int32{
-	wasmhost.KeyBalances:        wasmhost.OBJTYPE_MAP,
-	wasmhost.KeyCall:            wasmhost.OBJTYPE_BYTES,
-	wasmhost.KeyCaller:          wasmhost.OBJTYPE_AGENT_ID,
-	wasmhost.KeyChainOwnerId:    wasmhost.OBJTYPE_AGENT_ID,
-	wasmhost.KeyContractCreator: wasmhost.OBJTYPE_AGENT_ID,
-	wasmhost.KeyDeploy:          wasmhost.OBJTYPE_BYTES,
-	wasmhost.KeyEvent:           wasmhost.OBJTYPE_STRING,
-	wasmhost.KeyExports:         wasmhost.OBJTYPE_STRING | wasmhost.OBJTYPE_ARRAY,
-	wasmhost.KeyContractId:      wasmhost.OBJTYPE_CONTRACT_ID,
-	wasmhost.KeyIncoming:        wasmhost.OBJTYPE_MAP,
-	wasmhost.KeyLog:             wasmhost.OBJTYPE_STRING,
-	wasmhost.KeyMaps:            wasmhost.OBJTYPE_MAP | wasmhost.OBJTYPE_ARRAY,
-	wasmhost.KeyMinted:          wasmhost.OBJTYPE_INT64,
-	wasmhost.KeyPanic:           wasmhost.OBJTYPE_STRING,
-	wasmhost.KeyParams:          wasmhost.OBJTYPE_MAP,
-	wasmhost.KeyPost:            wasmhost.OBJTYPE_BYTES,
-	wasmhost.KeyRequestId:       wasmhost.OBJTYPE_REQUEST_ID,
-	wasmhost.KeyResults:         wasmhost.OBJTYPE_MAP,
-	wasmhost.KeyReturn:          wasmhost.OBJTYPE_MAP,
-	wasmhost.KeyState:           wasmhost.OBJTYPE_MAP,
-	wasmhost.KeyTimestamp:       wasmhost.OBJTYPE_INT64,
-	wasmhost.KeyTrace:           wasmhost.OBJTYPE_STRING,
-	wasmhost.KeyTransfers:       wasmhost.OBJTYPE_MAP | wasmhost.OBJTYPE_ARRAY,
-	wasmhost.KeyUtility:         wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyBalances:         wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyBatchSize:        wasmhost.OBJTYPE_INT64,
+	wasmhost.KeyBlockIndex:       wasmhost.OBJTYPE_INT64,
+	wasmhost.KeyBlockTimestamp:   wasmhost.OBJTYPE_INT64,
+	wasmhost.KeyCall:             wasmhost.OBJTYPE_BYTES,
+	wasmhost.KeyCaller:           wasmhost.OBJTYPE_AGENT_ID,
+	wasmhost.KeyChainOwnerId:     wasmhost.OBJTYPE_AGENT_ID,
+	wasmhost.KeyContractCreator:  wasmhost.OBJTYPE_AGENT_ID,
+	wasmhost.KeyDeploy:           wasmhost.OBJTYPE_BYTES,
+	wasmhost.KeyEvent:            wasmhost.OBJTYPE_STRING,
+	wasmhost.KeyExports:          wasmhost.OBJTYPE_STRING | wasmhost.OBJTYPE_ARRAY,
+	wasmhost.KeyContractId:       wasmhost.OBJTYPE_CONTRACT_ID,
+	wasmhost.KeyIncoming:         wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyLog:              wasmhost.OBJTYPE_STRING,
+	wasmhost.KeyMaps:             wasmhost.OBJTYPE_MAP | wasmhost.OBJTYPE_ARRAY,
+	wasmhost.KeyMinted:           wasmhost.OBJTYPE_INT64,
+	wasmhost.KeyPanic:            wasmhost.OBJTYPE_STRING,
+	wasmhost.KeyParams:           wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyPost:             wasmhost.OBJTYPE_BYTES,
+	wasmhost.KeyRequestDeclared:  wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyRequestDeposit:   wasmhost.OBJTYPE_INT64,
+	wasmhost.KeyRequestDustAdded: wasmhost.OBJTYPE_INT64,
+	wasmhost.KeyRequestFees:      wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyRequestId:        wasmhost.OBJTYPE_REQUEST_ID,
+	wasmhost.KeyRequestIndex:     wasmhost.OBJTYPE_INT64,
+	wasmhost.KeyRequestSender:    wasmhost.OBJTYPE_ADDRESS,
+	wasmhost.KeyRequestTxId:      wasmhost.OBJTYPE_HASH,
+	wasmhost.KeyResults:          wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyReturn:           wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyState:            wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyTimestamp:        wasmhost.OBJTYPE_INT64,
+	wasmhost.KeyTrace:            wasmhost.OBJTYPE_STRING,
+	wasmhost.KeyTransfers:        wasmhost.OBJTYPE_MAP | wasmhost.OBJTYPE_ARRAY,
+	wasmhost.KeyUtility:          wasmhost.OBJTYPE_MAP,
+	wasmhost.KeyVrf:              wasmhost.OBJTYPE_HASH,
+	wasmhost.KeyVrfProof:         wasmhost.OBJTYPE_BYTES,
+	wasmhost.KeyVrfSeed:          wasmhost.OBJTYPE_BYTES,
+}
+
+// host functions of the context which can be intercepted by breakpoints
+var debugHostFunctions = map[int32]string{
+	wasmhost.KeyCall:   HostCall,
+	wasmhost.KeyDeploy: HostDeploy,
+	wasmhost.KeyEvent:  HostEvent,
+	wasmhost.KeyLog:    HostLog,
+	wasmhost.KeyPost:   HostPost,
 }
 
 type ScContext struct {
@@ -85,6 +107,35 @@ func (o *ScContext) GetBytes(keyId int32, typeId int32) []byte {
 		return rid[:]
 	case wasmhost.KeyTimestamp:
 		return codec.EncodeInt64(o.vm.ctx.GetTimestamp())
+	case wasmhost.KeyBatchSize:
+		return codec.EncodeInt64(int64(o.vm.ctx.RequestMetadata().BatchSize))
+	case wasmhost.KeyBlockIndex:
+		return codec.EncodeInt64(int64(o.vm.ctx.RequestMetadata().BlockIndex))
+	case wasmhost.KeyBlockTimestamp:
+		return codec.EncodeInt64(o.vm.ctx.RequestMetadata().BlockTimestamp)
+	case wasmhost.KeyRequestIndex:
+		return codec.EncodeInt64(int64(o.vm.ctx.RequestMetadata().RequestIndex))
+	case wasmhost.KeyRequestSender:
+		addr := o.vm.ctx.RequestMetadata().SenderAddress
+		return addr[:]
+	case wasmhost.KeyRequestTxId:
+		txid := o.vm.ctx.RequestMetadata().TransactionID
+		return txid[:]
+	case wasmhost.KeyRequestDeposit:
+		return codec.EncodeInt64(o.vm.ctx.RequestTransfer().Deposit)
+	case wasmhost.KeyRequestDustAdded:
+		if o.vm.ctx.RequestTransfer().DustAdded {
+			return codec.EncodeInt64(1)
+		}
+		return codec.EncodeInt64(0)
+	case wasmhost.KeyVrf:
+		vrf := o.vm.ctx.VRF()
+		return vrf.Output[:]
+	case wasmhost.KeyVrfProof:
+		return o.vm.ctx.VRF().Proof
+	case wasmhost.KeyVrfSeed:
+		vrf := o.vm.ctx.VRF()
+		return vrf.Seed()
 	}
 	o.invalidKey(keyId)
 	return nil
@@ -98,16 +149,18 @@ func (o *ScContext) GetObjectId(keyId int32, typeId int32) int32 {
 	}
 
 	return GetMapObjectId(o, keyId, typeId, ObjFactories{
-		wasmhost.KeyBalances:  func() WaspObject { return NewScBalances(o.vm, false) },
-		wasmhost.KeyExports:   func() WaspObject { return NewScExports(o.vm) },
-		wasmhost.KeyIncoming:  func() WaspObject { return NewScBalances(o.vm, true) },
-		wasmhost.KeyMaps:      func() WaspObject { return NewScMaps(o.vm) },
-		wasmhost.KeyParams:    func() WaspObject { return NewScDictFromKvStore(&o.vm.KvStoreHost, o.vm.params()) },
-		wasmhost.KeyResults:   func() WaspObject { return NewScDict(o.vm) },
-		wasmhost.KeyReturn:    func() WaspObject { return NewScDict(o.vm) },
-		wasmhost.KeyState:     func() WaspObject { return NewScDictFromKvStore(&o.vm.KvStoreHost, o.vm.state()) },
-		wasmhost.KeyTransfers: func() WaspObject { return NewScTransfers(o.vm) },
-		wasmhost.KeyUtility:   func() WaspObject { return NewScUtility(o.vm) },
+		wasmhost.KeyBalances:        func() WaspObject { return NewScBalances(o.vm, false) },
+		wasmhost.KeyExports:         func() WaspObject { return NewScExports(o.vm) },
+		wasmhost.KeyIncoming:        func() WaspObject { return NewScBalances(o.vm, true) },
+		wasmhost.KeyMaps:            func() WaspObject { return NewScMaps(o.vm) },
+		wasmhost.KeyParams:          func() WaspObject { return NewScDictFromKvStore(&o.vm.KvStoreHost, o.vm.params()) },
+		wasmhost.KeyRequestDeclared: func() WaspObject { return NewScRequestBalances(o.vm, false) },
+		wasmhost.KeyRequestFees:     func() WaspObject { return NewScRequestBalances(o.vm, true) },
+		wasmhost.KeyResults:         func() WaspObject { return NewScDict(o.vm) },
+		wasmhost.KeyReturn:          func() WaspObject { return NewScDict(o.vm) },
+		wasmhost.KeyState:           func() WaspObject { return NewScDictFromKvStore(&o.vm.KvStoreHost, o.vm.state()) },
+		wasmhost.KeyTransfers:       func() WaspObject { return NewScTransfers(o.vm) },
+		wasmhost.KeyUtility:         func() WaspObject { return NewScUtility(o.vm) },
 	})
 }
 
@@ -116,6 +169,9 @@ func (o *ScContext) GetTypeId(keyId int32) int32 {
 }
 
 func (o *ScContext) SetBytes(keyId int32, typeId int32, bytes []byte) {
+	if hostFunction, ok := debugHostFunctions[keyId]; ok {
+		o.vm.debugBreak(hostFunction, "", bytes)
+	}
 	switch keyId {
 	case wasmhost.KeyCall:
 		o.processCall(bytes)
@@ -156,6 +212,11 @@ func (o *ScContext) processCall(bytes []byte) {
 	} else {
 		results, err = o.vm.ctxView.Call(contract, function, params)
 	}
+	if ce, ok := coretypes.AsContractError(err); ok {
+		// the structured error is passed to the contract in reserved keys of results,
+		// so the contract can handle it
+		results, err = ce.Results(), nil
+	}
 	if err != nil {
 		o.Panic("failed to invoke call: %v", err)
 	}
@@ -216,12 +216,16 @@ func (o *ScDict) SetBytes(keyId int32, typeId int32, bytes []byte) {
 
 	if keyId == wasmhost.KeyLength {
 		if o.kvStore != nil {
-			//TODO this goes wrong for state, should clear map tree instead
-			o.kvStore = dict.New()
-			//if (o.typeId & wasmhost.OBJTYPE_ARRAY) != 0 {
-			//	key := o.NestedKey()[1:]
-			//	o.kvStore.Del(kv.Key(key))
-			//}
+			if o.isRoot {
+				o.kvStore = dict.New()
+			} else {
+				// clear the map tree of the nested object in the store of its owner
+				key := o.NestedKey()[1:]
+				o.kvStore.DelPrefix(kv.Key(key + "."))
+				if (o.typeId & wasmhost.OBJTYPE_ARRAY) != 0 {
+					o.kvStore.Del(kv.Key(key))
+				}
+			}
 		}
 		o.objects = make(map[int32]int32)
 		o.length = 0
@@ -4,6 +4,8 @@
 package wasmproc
 
 import (
+	"fmt"
+
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
@@ -23,9 +25,20 @@ type ScUtility struct {
 	hname         coretypes.Hname
 	nextRandom    int
 	random        []byte
+	uint256       []byte
 	valid         bool
 }
 
+// operations of the 256-bit unsigned integer arithmetic, see KeyUint256
+const (
+	Uint256Add = int64(iota)
+	Uint256Sub
+	Uint256Mul
+	Uint256Div
+	Uint256Mod
+	Uint256Exp
+)
+
 func NewScUtility(vm *wasmProcessor) *ScUtility {
 	o := &ScUtility{}
 	o.vm = vm
@@ -60,12 +73,16 @@ func (o *ScUtility) GetBytes(keyId int32, typeId int32) []byte {
 		return []byte(o.base58Encoded)
 	case wasmhost.KeyHashBlake2b:
 		return o.hash.Bytes()
+	case wasmhost.KeyHashKeccak256:
+		return o.hash.Bytes()
 	case wasmhost.KeyHashSha3:
 		return o.hash.Bytes()
 	case wasmhost.KeyHname:
 		return codec.EncodeHname(o.hname)
 	case wasmhost.KeyRandom:
 		return o.getRandom8Bytes()
+	case wasmhost.KeyUint256:
+		return o.uint256
 	case wasmhost.KeyValid:
 		bytes := make([]byte, 8)
 		if o.valid {
@@ -115,6 +132,8 @@ func (o *ScUtility) GetTypeId(keyId int32) int32 {
 		return wasmhost.OBJTYPE_BYTES
 	case wasmhost.KeyHashBlake2b:
 		return wasmhost.OBJTYPE_HASH
+	case wasmhost.KeyHashKeccak256:
+		return wasmhost.OBJTYPE_HASH
 	case wasmhost.KeyHashSha3:
 		return wasmhost.OBJTYPE_HASH
 	case wasmhost.KeyHname:
@@ -123,6 +142,10 @@ func (o *ScUtility) GetTypeId(keyId int32) int32 {
 		return wasmhost.OBJTYPE_STRING
 	case wasmhost.KeyRandom:
 		return wasmhost.OBJTYPE_INT64
+	case wasmhost.KeySecp256k1Valid:
+		return wasmhost.OBJTYPE_BYTES
+	case wasmhost.KeyUint256:
+		return wasmhost.OBJTYPE_BYTES
 	case wasmhost.KeyValid:
 		return wasmhost.OBJTYPE_INT64
 	}
@@ -131,6 +154,7 @@ func (o *ScUtility) GetTypeId(keyId int32) int32 {
 
 func (o *ScUtility) SetBytes(keyId int32, typeId int32, bytes []byte) {
 	utils := o.vm.utils()
+	o.vm.chargeUtility(keyId, bytes)
 	var err error
 	switch keyId {
 	case wasmhost.KeyBlsAddress:
@@ -145,6 +169,8 @@ func (o *ScUtility) SetBytes(keyId int32, typeId int32, bytes []byte) {
 		o.base58Decoded, err = utils.Base58().Decode(string(bytes))
 	case wasmhost.KeyHashBlake2b:
 		o.hash = utils.Hashing().Blake2b(bytes)
+	case wasmhost.KeyHashKeccak256:
+		o.hash = utils.Hashing().Keccak256(bytes)
 	case wasmhost.KeyHashSha3:
 		o.hash = utils.Hashing().Sha3(bytes)
 	case wasmhost.KeyName:
@@ -153,6 +179,10 @@ func (o *ScUtility) SetBytes(keyId int32, typeId int32, bytes []byte) {
 		o.valid = o.validBLSSignature(bytes)
 	case wasmhost.KeyEd25519Valid:
 		o.valid = o.validED25519Signature(bytes)
+	case wasmhost.KeySecp256k1Valid:
+		o.valid = o.validSecp256k1Signature(bytes)
+	case wasmhost.KeyUint256:
+		o.uint256, err = o.uint256Op(bytes)
 	default:
 		o.invalidKey(keyId)
 	}
@@ -195,3 +225,34 @@ func (o *ScUtility) validED25519Signature(bytes []byte) bool {
 	signature := decode.Bytes()
 	return o.vm.utils().ED25519().ValidSignature(data, pubKey, signature)
 }
+
+func (o *ScUtility) validSecp256k1Signature(bytes []byte) bool {
+	decode := NewBytesDecoder(bytes)
+	hash := decode.Bytes()
+	pubKey := decode.Bytes()
+	signature := decode.Bytes()
+	return o.vm.utils().Secp256k1().ValidSignature(hash, pubKey, signature)
+}
+
+func (o *ScUtility) uint256Op(bytes []byte) ([]byte, error) {
+	decode := NewBytesDecoder(bytes)
+	op := decode.Int64()
+	a := decode.Bytes()
+	b := decode.Bytes()
+	u := o.vm.utils().Uint256()
+	switch op {
+	case Uint256Add:
+		return u.Add(a, b)
+	case Uint256Sub:
+		return u.Sub(a, b)
+	case Uint256Mul:
+		return u.Mul(a, b)
+	case Uint256Div:
+		return u.Div(a, b)
+	case Uint256Mod:
+		return u.Mod(a, b)
+	case Uint256Exp:
+		return u.Exp(a, b)
+	}
+	return nil, fmt.Errorf("invalid uint256 operation: %d", op)
+}
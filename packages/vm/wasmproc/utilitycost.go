@@ -0,0 +1,107 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package wasmproc
+
+import (
+	"fmt"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/kvdecoder"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/wasmhost"
+)
+
+// utilityCost is the cost of one call of the host utility function, expressed in cost units.
+// The cost grows with the size of the input, counted in 32 byte words
+type utilityCost struct {
+	base    int64
+	perWord int64
+}
+
+// utilityCosts is the cost table of the host utility functions. Costs are relative to each other
+// and roughly reflect the host CPU time spent by the function
+var utilityCosts = map[int32]utilityCost{
+	wasmhost.KeyBase58Bytes:    {base: 10, perWord: 10},
+	wasmhost.KeyBase58String:   {base: 10, perWord: 10},
+	wasmhost.KeyBlsAddress:     {base: 100},
+	wasmhost.KeyBlsAggregate:   {base: 2000, perWord: 100},
+	wasmhost.KeyBlsValid:       {base: 20000, perWord: 6},
+	wasmhost.KeyEd25519Address: {base: 100},
+	wasmhost.KeyEd25519Valid:   {base: 2000, perWord: 6},
+	wasmhost.KeyHashBlake2b:    {base: 30, perWord: 6},
+	wasmhost.KeyHashKeccak256:  {base: 30, perWord: 6},
+	wasmhost.KeyHashSha3:       {base: 30, perWord: 6},
+	wasmhost.KeyName:           {base: 30, perWord: 6},
+	wasmhost.KeySecp256k1Valid: {base: 3000},
+	wasmhost.KeyUint256:        {base: 5},
+}
+
+// uint256ExpCostPerWord is the additional cost of the exponentiation per word of the exponent
+const uint256ExpCostPerWord = 50
+
+func utilityCallCost(keyId int32, bytes []byte) int64 {
+	cost, ok := utilityCosts[keyId]
+	if !ok {
+		return 0
+	}
+	words := int64(len(bytes)+31) / 32
+	ret := cost.base + cost.perWord*words
+	if keyId == wasmhost.KeyUint256 {
+		decode := NewBytesDecoder(bytes)
+		if decode.Int64() == Uint256Exp {
+			ret += uint256ExpCostPerWord * words
+		}
+	}
+	return ret
+}
+
+// utilityFee converts the cost units to iotas at the price of the chain, see root.FuncSetUtilityPrice.
+// Any started iota is charged in full
+func utilityFee(cost int64, price int64) int64 {
+	if cost <= 0 || price <= 0 {
+		return 0
+	}
+	return (cost + price - 1) / price
+}
+
+// chargeUtility accumulates the cost of the host utility function called by the smart contract.
+// The total is charged when the outermost call of the contract finishes, see chargeUtilityFee
+func (host *wasmProcessor) chargeUtility(keyId int32, bytes []byte) {
+	host.utilityCost += utilityCallCost(keyId, bytes)
+}
+
+// chargeUtilityFee pays the fee for host utility functions called by the request out of the iotas
+// transferred to the contract by the request. The fee is credited to the chain owner.
+// If the transfer is not enough, the call fails and its state changes are rolled back.
+// Views can't pay, so their utility costs are only logged
+func (host *wasmProcessor) chargeUtilityFee() error {
+	if host.utilityCost == 0 {
+		return nil
+	}
+	host.log().Debugf("host utility cost of '%s': %d", host.function, host.utilityCost)
+	if host.ctx == nil {
+		return nil
+	}
+	ret, err := host.ctx.Call(root.Interface.Hname(), coretypes.Hn(root.FuncGetUtilityPrice), nil, nil)
+	if err != nil {
+		return err
+	}
+	par := kvdecoder.New(ret, host.log())
+	fee := utilityFee(host.utilityCost, par.MustGetInt64(root.ParamUtilityPrice, 0))
+	if fee == 0 {
+		return nil
+	}
+	if host.ctx.IncomingTransfer().Balance(balance.ColorIOTA) < fee || host.ctx.Balance(balance.ColorIOTA) < fee {
+		return fmt.Errorf("not enough iotas transferred to pay for host utility functions: %d required", fee)
+	}
+	params := codec.MakeDict(map[string]interface{}{
+		accounts.ParamAgentID: host.ctx.ChainOwnerID(),
+	})
+	_, err = host.ctx.Call(accounts.Interface.Hname(), coretypes.Hn(accounts.FuncDeposit), params, cbalances.NewIotasOnly(fee))
+	return err
+}
@@ -0,0 +1,99 @@
+package wasmproc
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/wasmhost"
+	"github.com/stretchr/testify/require"
+)
+
+// feeSandbox emulates accounts of the contract and of the chain owner for the fee charging
+type feeSandbox struct {
+	coretypes.Sandbox
+	log      coretypes.LogInterface
+	price    int64
+	incoming int64
+	contract int64
+	owner    int64
+}
+
+func (s *feeSandbox) Log() coretypes.LogInterface {
+	return s.log
+}
+
+func (s *feeSandbox) ChainOwnerID() coretypes.AgentID {
+	return coretypes.AgentID{1}
+}
+
+func (s *feeSandbox) IncomingTransfer() coretypes.ColoredBalances {
+	return cbalances.NewIotasOnly(s.incoming)
+}
+
+func (s *feeSandbox) Balance(col balance.Color) int64 {
+	if col != balance.ColorIOTA {
+		return 0
+	}
+	return s.contract
+}
+
+func (s *feeSandbox) Call(target coretypes.Hname, entryPoint coretypes.Hname, params dict.Dict, transfer coretypes.ColoredBalances) (dict.Dict, error) {
+	switch {
+	case target == root.Interface.Hname() && entryPoint == coretypes.Hn(root.FuncGetUtilityPrice):
+		return codec.MakeDict(map[string]interface{}{root.ParamUtilityPrice: s.price}), nil
+	case target == accounts.Interface.Hname() && entryPoint == coretypes.Hn(accounts.FuncDeposit):
+		s.contract -= transfer.Balance(balance.ColorIOTA)
+		s.owner += transfer.Balance(balance.ColorIOTA)
+		return nil, nil
+	}
+	panic("unexpected call")
+}
+
+func TestUtilityFee(t *testing.T) {
+	require.EqualValues(t, 0, utilityFee(0, 100))
+	require.EqualValues(t, 0, utilityFee(100, 0))
+	require.EqualValues(t, 1, utilityFee(1, 100))
+	require.EqualValues(t, 1, utilityFee(100, 100))
+	require.EqualValues(t, 2, utilityFee(101, 100))
+}
+
+func TestChargeUtilityFee(t *testing.T) {
+	sig := make([]byte, 64+32+10)
+	verifyCost := utilityCallCost(wasmhost.KeyEd25519Valid, sig)
+	require.True(t, verifyCost > 0)
+
+	newHost := func(sb *feeSandbox) *wasmProcessor {
+		sb.log = testutil.NewLogger(t)
+		host := &wasmProcessor{ctx: sb, function: "verify"}
+		host.chargeUtility(wasmhost.KeyEd25519Valid, sig)
+		return host
+	}
+
+	t.Run("free", func(t *testing.T) {
+		sb := &feeSandbox{incoming: 10, contract: 10}
+		require.NoError(t, newHost(sb).chargeUtilityFee())
+		require.EqualValues(t, 10, sb.contract)
+		require.EqualValues(t, 0, sb.owner)
+	})
+	t.Run("charged", func(t *testing.T) {
+		sb := &feeSandbox{price: 100, incoming: 100, contract: 100}
+		require.NoError(t, newHost(sb).chargeUtilityFee())
+		fee := utilityFee(verifyCost, 100)
+		require.True(t, fee > 0)
+		require.EqualValues(t, 100-fee, sb.contract)
+		require.EqualValues(t, fee, sb.owner)
+	})
+	t.Run("not enough transferred", func(t *testing.T) {
+		sb := &feeSandbox{price: 1, incoming: 1, contract: 1000000}
+		require.Error(t, newHost(sb).chargeUtilityFee())
+		require.EqualValues(t, 1000000, sb.contract)
+		require.EqualValues(t, 0, sb.owner)
+	})
+}
@@ -13,11 +13,12 @@ import (
 
 type wasmProcessor struct {
 	wasmhost.WasmHost
-	ctx       coretypes.Sandbox
-	ctxView   coretypes.SandboxView
-	function  string
-	nesting   int
-	scContext *ScContext
+	ctx         coretypes.Sandbox
+	ctxView     coretypes.SandboxView
+	function    string
+	nesting     int
+	scContext   *ScContext
+	utilityCost int64
 }
 
 const ViewCopyAllState = "copy_all_state"
@@ -64,6 +65,9 @@ func (host *wasmProcessor) call(ctx coretypes.Sandbox, ctxView coretypes.Sandbox
 
 	host.ctx = ctx
 	host.ctxView = ctxView
+	if host.nesting == 0 {
+		host.utilityCost = 0
+	}
 	host.nesting++
 
 	defer func() {
@@ -94,6 +98,11 @@ func (host *wasmProcessor) call(ctx coretypes.Sandbox, ctxView coretypes.Sandbox
 	results := host.FindSubObject(nil, wasmhost.KeyResults, wasmhost.OBJTYPE_MAP).(*ScDict).kvStore.(dict.Dict)
 	host.scContext.objects = frameObjects
 	host.PopFrame(frame)
+	if host.nesting == 1 {
+		if err = host.chargeUtilityFee(); err != nil {
+			return nil, err
+		}
+	}
 	return results, nil
 }
 
@@ -171,9 +180,9 @@ func (host *wasmProcessor) params() dict.Dict {
 
 func (host *wasmProcessor) state() kv.KVStore {
 	if host.ctx != nil {
-		return host.ctx.State()
+		return newDebugState(host, host.ctx.State())
 	}
-	return NewScViewState(host.ctxView)
+	return newDebugState(host, NewScViewState(host.ctxView))
 }
 
 func (host *wasmProcessor) utils() coretypes.Utils {
@@ -3,6 +3,7 @@ package admapi
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/wasp/packages/coretypes"
@@ -16,12 +17,23 @@ import (
 
 func addChainEndpoints(adm echoswagger.ApiGroup) {
 	adm.POST(routes.ActivateChain(":chainID"), handleActivateChain).
+		SetOperationId("activateChain").
 		AddParamPath("", "chainID", "ChainID (base58)").
 		SetSummary("Activate a chain")
 
 	adm.POST(routes.DeactivateChain(":chainID"), handleDeactivateChain).
+		SetOperationId("deactivateChain").
 		AddParamPath("", "chainID", "ChainID (base58)").
 		SetSummary("Deactivate a chain")
+
+	adm.POST(routes.DrainChain(":chainID"), handleDrainChain).
+		SetOperationId("drainChain").
+		SetSummary("Deactivate a chain gracefully: stop accepting requests and finish the round in progress").
+		SetDescription("The chain is reactivated with the activate endpoint").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamQuery(false, "wait", "Wait until the chain is dismissed", false).
+		AddResponse(http.StatusOK, "Chain has been dismissed", nil, nil).
+		AddResponse(http.StatusAccepted, "Chain is draining", nil, nil)
 }
 
 func handleActivateChain(c echo.Context) error {
@@ -62,3 +74,35 @@ func handleDeactivateChain(c echo.Context) error {
 
 	return c.NoContent(http.StatusOK)
 }
+
+func handleDrainChain(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain id: %s", c.Param("chainID")))
+	}
+	wait := false
+	if s := c.QueryParam("wait"); s != "" {
+		if wait, err = strconv.ParseBool(s); err != nil {
+			return httperrors.BadRequest(fmt.Sprintf("invalid 'wait': %v", err))
+		}
+	}
+
+	bd, err := registry.DeactivateChainRecord(&chainID)
+	if err != nil {
+		return err
+	}
+	drained, err := chains.DrainChain(bd)
+	if err != nil {
+		return err
+	}
+	if !wait {
+		return c.NoContent(http.StatusAccepted)
+	}
+	select {
+	case <-drained:
+	case <-c.Request().Context().Done():
+		return c.Request().Context().Err()
+	}
+	log.Infof("chain has been drained: %s", chainID.String())
+	return c.NoContent(http.StatusOK)
+}
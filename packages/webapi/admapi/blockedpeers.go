@@ -0,0 +1,59 @@
+package admapi
+
+import (
+	"net/http"
+
+	peering_pkg "github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addBlockedPeersEndpoints(adm echoswagger.ApiGroup) {
+	example := model.BlockedPeers{NetIDs: []string{"127.0.0.1:4001", "127.0.0.1:4002"}}
+
+	adm.GET(routes.BlockedPeers(), handleGetBlockedPeers).
+		SetOperationId("getBlockedPeers").
+		SetSummary("Get the peers the node exchanges no messages with").
+		AddResponse(http.StatusOK, "Blocked peers", example, nil)
+
+	adm.POST(routes.BlockedPeers(), handleSetBlockedPeers).
+		SetOperationId("setBlockedPeers").
+		SetSummary("Cut off the traffic with the peers, e.g. to simulate a network partition").
+		SetDescription("Replaces the set of blocked peers. The empty set restores the traffic with all peers").
+		AddParamBody(example, "BlockedPeers", "NetIDs of the peers", true).
+		AddResponse(http.StatusOK, "Blocked peers were set", nil, nil)
+}
+
+func peerBlocker() (peering_pkg.PeerBlocker, error) {
+	blocker, ok := peering.DefaultNetworkProvider().(peering_pkg.PeerBlocker)
+	if !ok {
+		return nil, httperrors.BadRequest("peering of the node can't block peers")
+	}
+	return blocker, nil
+}
+
+func handleGetBlockedPeers(c echo.Context) error {
+	blocker, err := peerBlocker()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.BlockedPeers{NetIDs: blocker.BlockedPeers()})
+}
+
+func handleSetBlockedPeers(c echo.Context) error {
+	var req model.BlockedPeers
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	blocker, err := peerBlocker()
+	if err != nil {
+		return err
+	}
+	log.Infof("blocking peers %v", req.NetIDs)
+	blocker.BlockPeers(req.NetIDs)
+	return c.NoContent(http.StatusOK)
+}
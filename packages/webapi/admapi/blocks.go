@@ -0,0 +1,81 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package admapi
+
+// Endpoint for exporting the history of the chain, to be replayed in Solo.
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addBlocksEndpoints(adm echoswagger.ApiGroup) {
+	example := model.ChainBlocks{From: 0, To: 2, Data: model.NewBytes([]byte("blocks"))}
+
+	adm.GET(routes.ExportBlocks(":chainID"), handleExportBlocks).
+		SetOperationId("exportBlocks").
+		SetSummary("Export blocks of the chain").
+		SetDescription("By default all blocks up to the solid state are exported. May be a heavy operation for a long chain").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamQuery(uint32(0), "from", "Index of the first block", false).
+		AddParamQuery(uint32(0), "to", "Index of the last block (default: index of the solid state)", false).
+		AddResponse(http.StatusOK, "Blocks", example, nil)
+}
+
+func handleExportBlocks(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	virtualState, _, ok, err := state.LoadSolidState(&chainID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httperrors.NotFound(fmt.Sprintf("State not found for chain %s", chainID.String()))
+	}
+	from, err := queryUint32(c, "from", 0)
+	if err != nil {
+		return err
+	}
+	to, err := queryUint32(c, "to", virtualState.BlockIndex())
+	if err != nil {
+		return err
+	}
+	if from > to || to > virtualState.BlockIndex() {
+		return httperrors.BadRequest(fmt.Sprintf("wrong block range %d..%d, solid state index is %d", from, to, virtualState.BlockIndex()))
+	}
+	blocks, err := state.LoadBlocks(&chainID, from, to)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := state.WriteBlocks(&buf, blocks); err != nil {
+		return err
+	}
+	log.Infof("exported blocks #%d..#%d of chain %s", from, to, chainID.String())
+	return c.JSON(http.StatusOK, model.ChainBlocks{From: from, To: to, Data: model.NewBytes(buf.Bytes())})
+}
+
+func queryUint32(c echo.Context, name string, def uint32) (uint32, error) {
+	s := c.QueryParam(name)
+	if s == "" {
+		return def, nil
+	}
+	ret, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, httperrors.BadRequest(fmt.Sprintf("invalid '%s': %v", name, err))
+	}
+	return uint32(ret), nil
+}
@@ -19,19 +19,23 @@ func addChainRecordEndpoints(adm echoswagger.ApiGroup) {
 		ChainID:        model.NewChainID(&coretypes.ChainID{1, 2, 3, 4}),
 		Color:          model.NewColor(&balance.Color{5, 6, 7, 8}),
 		CommitteeNodes: []string{"wasp1:4000", "wasp2:4000"},
+		AccessNodes:    []string{"wasp3:4000"},
 		Active:         false,
 	}
 
 	adm.POST(routes.PutChainRecord(), handlePutChainRecord).
+		SetOperationId("putChainRecord").
 		SetSummary("Create a new chain record").
 		AddParamBody(example, "ChainRecord", "Chain record", true)
 
 	adm.GET(routes.GetChainRecord(":chainID"), handleGetChainRecord).
+		SetOperationId("getChainRecord").
 		SetSummary("Find the chain record for the given chain ID").
 		AddParamPath("", "chainID", "ChainID (base58)").
 		AddResponse(http.StatusOK, "Chain Record", example, nil)
 
 	adm.GET(routes.ListChainRecords(), handleGetChainRecordList).
+		SetOperationId("listChainRecords").
 		SetSummary("Get the list of chain records in the node").
 		AddResponse(http.StatusOK, "Chain Record", []model.ChainRecord{example}, nil)
 }
@@ -62,7 +66,7 @@ func handlePutChainRecord(c echo.Context) error {
 }
 
 func handleGetChainRecord(c echo.Context) error {
-	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
 	if err != nil {
 		return httperrors.BadRequest(err.Error())
 	}
@@ -0,0 +1,50 @@
+package admapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/registry"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addStateCheckpointEndpoints(adm echoswagger.ApiGroup) {
+	example := model.StateCheckpointVerification{
+		ChainID:    model.NewChainID(&coretypes.ChainID{1, 2, 3, 4}),
+		Checkpoint: &model.StateCheckpoint{StateIndex: 42, Timestamp: 1000},
+		Valid:      true,
+	}
+
+	adm.GET(routes.VerifyStateCheckpoint(":chainID"), handleVerifyStateCheckpoint).
+		SetOperationId("verifyStateCheckpoint").
+		SetSummary("Verify the state of the chain stored by the node against the last state checkpoint").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddResponse(http.StatusOK, "Result of the verification", example, nil)
+}
+
+func handleVerifyStateCheckpoint(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	ret := model.StateCheckpointVerification{ChainID: model.NewChainID(&chainID), Valid: true}
+	cp, err := registry.DefaultRegistry().VerifyStateCheckpoint(&chainID)
+	if err != nil {
+		if !errors.Is(err, state.ErrStateCorrupted) {
+			return err
+		}
+		log.Errorf("state of chain %s doesn't match the checkpoint: %v", chainID.String(), err)
+		ret.Valid = false
+		ret.Error = err.Error()
+	}
+	if cp != nil {
+		ret.Checkpoint = model.NewStateCheckpoint(cp)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
@@ -40,11 +40,26 @@ func addDKSharesEndpoints(adm echoswagger.ApiGroup) {
 	}
 
 	adm.POST(routes.DKSharesPost(), handleDKSharesPost).
+		SetOperationId("postDKShares").
 		AddParamBody(requestExample, "DKSharesPostRequest", "Request parameters", true).
 		AddResponse(http.StatusOK, "DK shares info", infoExample, nil).
 		SetSummary("Generate a new distributed key")
 
+	reshareExample := model.DKSharesReshareRequest{
+		OldPeerNetIDs: []string{"wasp1:4000", "wasp2:4000", "wasp3:4000", "wasp4:4000"},
+		PeerNetIDs:    []string{"wasp2:4000", "wasp3:4000", "wasp4:4000", "wasp5:4000"},
+		Threshold:     3,
+		TimeoutMS:     10000,
+	}
+	adm.POST(routes.DKSharesReshare(":sharedAddress"), handleDKSharesReshare).
+		SetOperationId("reshareDKShares").
+		AddParamPath("", "sharedAddress", "Address of the DK share (base58)").
+		AddParamBody(reshareExample, "DKSharesReshareRequest", "Request parameters", true).
+		AddResponse(http.StatusOK, "DK shares info", infoExample, nil).
+		SetSummary("Reshare an existing distributed key to a new group of nodes")
+
 	adm.GET(routes.DKSharesGet(":sharedAddress"), handleDKSharesGet).
+		SetOperationId("getDKShares").
 		AddParamPath("", "sharedAddress", "Address of the DK share (base58)").
 		AddResponse(http.StatusOK, "DK shares info", infoExample, nil).
 		SetSummary("Get distributed key properties")
@@ -64,19 +79,9 @@ func handleDKSharesPost(c echo.Context) error {
 		return httperrors.BadRequest("Inconsistent PeerNetIDs and PeerPubKeys.")
 	}
 
-	var peerPubKeys []kyber.Point = nil
-	if req.PeerPubKeys != nil {
-		peerPubKeys = make([]kyber.Point, len(req.PeerPubKeys))
-		for i := range req.PeerPubKeys {
-			peerPubKeys[i] = suite.Point()
-			b, err := base64.StdEncoding.DecodeString(req.PeerPubKeys[i])
-			if err != nil {
-				return httperrors.BadRequest(fmt.Sprintf("Invalid PeerPubKeys[%v]=%v", i, req.PeerPubKeys[i]))
-			}
-			if err = peerPubKeys[i].UnmarshalBinary(b); err != nil {
-				return httperrors.BadRequest(fmt.Sprintf("Invalid PeerPubKeys[%v]=%v", i, req.PeerPubKeys[i]))
-			}
-		}
+	var peerPubKeys []kyber.Point
+	if peerPubKeys, err = decodePubKeys(suite, "PeerPubKeys", req.PeerPubKeys); err != nil {
+		return err
 	}
 
 	var dkShare *tcrypto.DKShare
@@ -102,6 +107,81 @@ func handleDKSharesPost(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+func handleDKSharesReshare(c echo.Context) error {
+	var req model.DKSharesReshareRequest
+	var err error
+
+	var suite = dkg.DefaultNode().GroupSuite()
+
+	var sharedAddress address.Address
+	if sharedAddress, err = address.FromBase58(c.Param("sharedAddress")); err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid shared address: %v", c.Param("sharedAddress")))
+	}
+
+	if err = c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body.")
+	}
+
+	if req.OldPeerPubKeys != nil && len(req.OldPeerNetIDs) != len(req.OldPeerPubKeys) {
+		return httperrors.BadRequest("Inconsistent OldPeerNetIDs and OldPeerPubKeys.")
+	}
+	if req.PeerPubKeys != nil && len(req.PeerNetIDs) != len(req.PeerPubKeys) {
+		return httperrors.BadRequest("Inconsistent PeerNetIDs and PeerPubKeys.")
+	}
+
+	var oldPeerPubKeys, peerPubKeys []kyber.Point
+	if oldPeerPubKeys, err = decodePubKeys(suite, "OldPeerPubKeys", req.OldPeerPubKeys); err != nil {
+		return err
+	}
+	if peerPubKeys, err = decodePubKeys(suite, "PeerPubKeys", req.PeerPubKeys); err != nil {
+		return err
+	}
+
+	var dkShare *tcrypto.DKShare
+	dkShare, err = dkg.DefaultNode().ReshareDistributedKey(
+		&sharedAddress,
+		req.OldPeerNetIDs,
+		oldPeerPubKeys,
+		req.PeerNetIDs,
+		peerPubKeys,
+		req.Threshold,
+		1*time.Second,
+		3*time.Second,
+		time.Duration(req.TimeoutMS)*time.Millisecond,
+	)
+	if err != nil {
+		if _, ok := err.(dkg_pkg.InvalidParamsError); ok {
+			return httperrors.BadRequest(err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	var response *model.DKSharesInfo
+	if response, err = makeDKSharesInfo(dkShare); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// decodePubKeys decodes the base64 encoded public keys of the peers, nil stays nil.
+func decodePubKeys(suite kyber.Group, field string, encoded []string) ([]kyber.Point, error) {
+	if encoded == nil {
+		return nil, nil
+	}
+	pubKeys := make([]kyber.Point, len(encoded))
+	for i := range encoded {
+		pubKeys[i] = suite.Point()
+		b, err := base64.StdEncoding.DecodeString(encoded[i])
+		if err != nil {
+			return nil, httperrors.BadRequest(fmt.Sprintf("Invalid %s[%v]=%v", field, i, encoded[i]))
+		}
+		if err = pubKeys[i].UnmarshalBinary(b); err != nil {
+			return nil, httperrors.BadRequest(fmt.Sprintf("Invalid %s[%v]=%v", field, i, encoded[i]))
+		}
+	}
+	return pubKeys, nil
+}
+
 func handleDKSharesGet(c echo.Context) error {
 	var err error
 	var dkShare *tcrypto.DKShare
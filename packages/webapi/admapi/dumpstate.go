@@ -18,6 +18,7 @@ import (
 
 func addStateEndpoints(adm echoswagger.ApiGroup) {
 	adm.GET(routes.DumpState(":contractID"), handleDumpSCState).
+		SetOperationId("dumpContractState").
 		AddParamPath("", "contractID", "ContractID").
 		AddResponse(http.StatusOK, "State dump", model.SCStateDump{}, nil).
 		SetSummary("Dump the whole contract state").
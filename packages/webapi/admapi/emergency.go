@@ -0,0 +1,78 @@
+package admapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addEmergencyEndpoints(adm echoswagger.ApiGroup) {
+	example := model.EmergencyRecord{
+		ChainID:    model.NewChainID(&coretypes.ChainID{1, 2, 3, 4}),
+		BlockIndex: 42,
+		Delegate:   1,
+		NumBlocks:  5,
+		Votes: []model.SignedEmergencyVote{
+			{PeerIndex: 0, SigShare: model.NewBytes([]byte("sig0"))},
+			{PeerIndex: 1, SigShare: model.NewBytes([]byte("sig1"))},
+			{PeerIndex: 3, SigShare: model.NewBytes([]byte("sig3"))},
+		},
+	}
+
+	adm.POST(routes.DeclareEmergency(":chainID"), handleDeclareEmergency).
+		SetOperationId("declareEmergency").
+		SetSummary("Vote to delegate block production of the chain to one committee node").
+		SetDescription("The emergency mode starts when the emergency quorum of committee nodes votes for the same delegate").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamBody(model.DeclareEmergencyRequest{}, "DeclareEmergencyRequest", "Delegate and number of blocks", true).
+		AddResponse(http.StatusAccepted, "The vote was sent to the committee", nil, nil)
+
+	adm.GET(routes.EmergencyRecords(":chainID"), handleGetEmergencyRecords).
+		SetOperationId("getEmergencyRecords").
+		SetSummary("Get the records of the emergency modes activated by the committee of the chain").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddResponse(http.StatusOK, "Emergency records", []model.EmergencyRecord{example}, nil)
+}
+
+func handleDeclareEmergency(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	var req model.DeclareEmergencyRequest
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	ch := chains.GetChain(chainID)
+	if ch == nil {
+		return httperrors.NotFound(fmt.Sprintf("Active chain %s not found", chainID))
+	}
+	if err := ch.DeclareEmergency(req.Delegate, req.NumBlocks); err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+func handleGetEmergencyRecords(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	lst, err := registry.GetEmergencyRecords(&chainID)
+	if err != nil {
+		return err
+	}
+	ret := make([]*model.EmergencyRecord, len(lst))
+	for i := range ret {
+		ret[i] = model.NewEmergencyRecord(lst[i])
+	}
+	return c.JSON(http.StatusOK, ret)
+}
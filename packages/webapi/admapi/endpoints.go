@@ -11,19 +11,27 @@ import (
 
 var log *logger.Logger
 
-func initLogger() {
+func InitLogger() {
 	log = logger.NewLogger("webapi/adm")
 }
 
 func AddEndpoints(adm echoswagger.ApiGroup, adminWhitelist []net.IP) {
-	initLogger()
-
 	adm.EchoGroup().Use(protected(adminWhitelist))
 
 	addShutdownEndpoint(adm)
 	addChainRecordEndpoints(adm)
 	addChainEndpoints(adm)
 	addDKSharesEndpoints(adm)
+	addRegistryBundleEndpoints(adm)
+	addEvidenceEndpoints(adm)
+	addEmergencyEndpoints(adm)
+	addStateCheckpointEndpoints(adm)
+	addCommitteePeersEndpoint(adm)
+	addBlockedPeersEndpoints(adm)
+	addIdentityEndpoints(adm)
+	addNodeResourcesEndpoint(adm)
+	addBlocksEndpoints(adm)
+	addStateEndpoints(adm)
 }
 
 // allow only if the remote address is private or in whitelist
@@ -0,0 +1,44 @@
+package admapi
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addEvidenceEndpoints(adm echoswagger.ApiGroup) {
+	example := model.EquivocationEvidence{
+		ChainID:     model.NewChainID(&coretypes.ChainID{1, 2, 3, 4}),
+		BlockIndex:  42,
+		LeaderIndex: 1,
+		Evidence:    model.NewBytes([]byte("evidence")),
+	}
+
+	adm.GET(routes.EquivocationEvidence(":chainID"), handleGetEquivocationEvidence).
+		SetOperationId("getEquivocationEvidence").
+		SetSummary("Get the evidence of equivocating leaders recorded by the node for the chain").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddResponse(http.StatusOK, "Equivocation evidence", []model.EquivocationEvidence{example}, nil)
+}
+
+func handleGetEquivocationEvidence(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	lst, err := registry.GetEquivocationEvidence(&chainID)
+	if err != nil {
+		return err
+	}
+	ret := make([]*model.EquivocationEvidence, len(lst))
+	for i := range ret {
+		ret[i] = model.NewEquivocationEvidence(lst[i])
+	}
+	return c.JSON(http.StatusOK, ret)
+}
@@ -0,0 +1,76 @@
+package admapi
+
+import (
+	"net/http"
+
+	peering_pkg "github.com/iotaledger/wasp/packages/peering"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/dkg"
+	"github.com/iotaledger/wasp/plugins/peering"
+	"github.com/iotaledger/wasp/plugins/registry"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addIdentityEndpoints(adm echoswagger.ApiGroup) {
+	example := model.IdentityHandover{
+		NetID:     "127.0.0.1:4000",
+		OldPubKey: "base64 string",
+		NewPubKey: "base64 string",
+		Timestamp: 1000,
+	}
+
+	adm.POST(routes.RotateIdentity(), handleRotateIdentity).
+		SetOperationId("rotateIdentity").
+		SetSummary("Replace the identity key of the node with a new one").
+		SetDescription("The handover signed by both keys is stored in the registry and sent to the peers. "+
+			"Sessions with the peers are kept, so the consensus of running chains is not interrupted").
+		AddParamBody(model.RotateIdentityRequest{}, "RotateIdentityRequest", "Rotation parameters", true).
+		AddResponse(http.StatusOK, "Handover to the new key", example, nil)
+
+	adm.GET(routes.IdentityHandovers(), handleGetIdentityHandovers).
+		SetOperationId("getIdentityHandovers").
+		SetSummary("Get handovers of identity keys of this node and of the peers, known to the node").
+		AddResponse(http.StatusOK, "Identity handovers ordered by time", []*model.IdentityHandover{&example}, nil)
+}
+
+func handleRotateIdentity(c echo.Context) error {
+	var req model.RotateIdentityRequest
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	rotator, ok := peering.DefaultNetworkProvider().(peering_pkg.IdentityRotator)
+	if !ok {
+		return httperrors.BadRequest("peering of the node can't rotate the identity")
+	}
+	handover, newKeyPair, err := registry.DefaultRegistry().RotateNodeIdentity(peering.DefaultNetworkProvider().Self().NetID(), req.Revoke)
+	if err != nil {
+		return err
+	}
+	if err = rotator.RotateIdentity(handover, newKeyPair); err != nil {
+		return err
+	}
+	dkg.DefaultNode().SetIdentity(newKeyPair.Private, newKeyPair.Public)
+	log.Infof("identity key rotated, revoked=%v", req.Revoke)
+	ret, err := model.NewIdentityHandover(handover)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func handleGetIdentityHandovers(c echo.Context) error {
+	handovers, err := registry.DefaultRegistry().GetIdentityHandovers()
+	if err != nil {
+		return err
+	}
+	ret := make([]*model.IdentityHandover, len(handovers))
+	for i, h := range handovers {
+		if ret[i], err = model.NewIdentityHandover(h); err != nil {
+			return err
+		}
+	}
+	return c.JSON(http.StatusOK, ret)
+}
@@ -0,0 +1,47 @@
+package admapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addCommitteePeersEndpoint(adm echoswagger.ApiGroup) {
+	example := []model.CommitteePeer{
+		{Index: 0, NetID: "127.0.0.1:4000", IsSelf: true, Connected: true, Alive: true},
+		{Index: 1, NetID: "127.0.0.1:4001", Connected: true, Alive: true, LastHeard: time.Now()},
+	}
+
+	adm.GET(routes.CommitteePeers(":chainID"), handleGetCommitteePeers).
+		SetOperationId("getCommitteePeers").
+		SetSummary("Get the connection status and liveness of committee peers of the chain").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddResponse(http.StatusOK, "Committee peers", example, nil)
+}
+
+func handleGetCommitteePeers(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	ch := chains.GetChain(chainID)
+	if ch == nil {
+		return httperrors.NotFound(fmt.Sprintf("Active chain %s not found", chainID))
+	}
+	status := ch.PeerStatus()
+	sort.Slice(status, func(i, j int) bool { return status[i].Index < status[j].Index })
+	ret := make([]*model.CommitteePeer, len(status))
+	for i, s := range status {
+		ret[i] = model.NewCommitteePeer(s)
+	}
+	return c.JSON(http.StatusOK, ret)
+}
@@ -0,0 +1,85 @@
+// Copyright 2020 IOTA Stiftung
+// SPDX-License-Identifier: Apache-2.0
+
+package admapi
+
+// Endpoints for migrating the node registry between nodes.
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/dkg"
+	"github.com/iotaledger/wasp/plugins/registry"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+	"go.dedis.ch/kyber/v3"
+)
+
+func addRegistryBundleEndpoints(adm echoswagger.ApiGroup) {
+	example := model.RegistryBundle{Data: model.NewBytes([]byte("bundle"))}
+	pubKeyExample := base64.StdEncoding.EncodeToString([]byte("key"))
+
+	adm.POST(routes.ExportRegistry(), handleExportRegistry).
+		SetOperationId("exportRegistry").
+		SetSummary("Export chain records and DK shares of the node as a signed bundle for the importing node").
+		AddParamBody(model.RegistryExportRequest{RecipientPubKey: pubKeyExample}, "RegistryExportRequest", "Importing node", true).
+		AddResponse(http.StatusOK, "Registry bundle", example, nil)
+
+	adm.POST(routes.ImportRegistry(), handleImportRegistry).
+		SetOperationId("importRegistry").
+		SetSummary("Import a signed registry bundle exported from a trusted node").
+		AddParamBody(model.RegistryImportRequest{Data: example.Data, ExporterPubKey: pubKeyExample}, "RegistryImportRequest", "Registry bundle", true)
+}
+
+func decodePubKey(s string) (kyber.Point, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	ret := dkg.DefaultNode().GroupSuite().Point()
+	if err := ret.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func handleExportRegistry(c echo.Context) error {
+	var req model.RegistryExportRequest
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	recipient, err := decodePubKey(req.RecipientPubKey)
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid RecipientPubKey=%v", req.RecipientPubKey))
+	}
+	bundle, err := registry.DefaultRegistry().ExportBundle(recipient)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	data, err := bundle.Bytes()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+	log.Infof("registry exported: %d chain record(s), %d DK share(s)", len(bundle.ChainRecords), len(bundle.DKShares))
+	return c.JSON(http.StatusOK, model.RegistryBundle{Data: model.NewBytes(data)})
+}
+
+func handleImportRegistry(c echo.Context) error {
+	var req model.RegistryImportRequest
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	exporter, err := decodePubKey(req.ExporterPubKey)
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid ExporterPubKey=%v", req.ExporterPubKey))
+	}
+	if _, err := registry.DefaultRegistry().ImportBundle(req.Data.Bytes(), exporter); err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	return c.NoContent(http.StatusCreated)
+}
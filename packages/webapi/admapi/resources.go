@@ -0,0 +1,21 @@
+package admapi
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addNodeResourcesEndpoint(adm echoswagger.ApiGroup) {
+	adm.GET(routes.NodeResources(), handleGetNodeResources).
+		SetOperationId("getNodeResources").
+		SetSummary("Get the number of goroutines and the memory usage of the node").
+		AddResponse(http.StatusOK, "Resource usage", model.NodeResources{Goroutines: 120, HeapAlloc: 1 << 24}, nil)
+}
+
+func handleGetNodeResources(c echo.Context) error {
+	return c.JSON(http.StatusOK, model.NewNodeResources())
+}
@@ -11,6 +11,7 @@ import (
 
 func addShutdownEndpoint(adm echoswagger.ApiGroup) {
 	adm.GET(routes.Shutdown(), handleShutdown).
+		SetOperationId("shutdown").
 		SetSummary("Shut down the node")
 }
 
@@ -1,10 +1,13 @@
 package blob
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
+	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/hashing"
+	registry_pkg "github.com/iotaledger/wasp/packages/registry"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
 	"github.com/iotaledger/wasp/packages/webapi/model"
 	"github.com/iotaledger/wasp/packages/webapi/routes"
@@ -17,19 +20,37 @@ func AddEndpoints(server echoswagger.ApiRouter) {
 	example := model.NewBlobInfo(true, hashing.RandomHash(nil))
 
 	server.GET(routes.PutBlob(), handlePutBlob).
+		SetOperationId("putBlob").
 		SetSummary("Upload a blob to the registry").
+		AddParamBody(model.NewBlobData([]byte("blob content")), "Blob", "Blob data", true).
 		AddResponse(http.StatusOK, "Blob properties", example, nil)
 
 	server.GET(routes.GetBlob(":hash"), handleGetBlob).
+		SetOperationId("getBlob").
 		AddParamPath("", "hash", "Blob hash (base64)").
 		SetSummary("Fetch a blob by its hash").
 		AddResponse(http.StatusOK, "Blob data", model.NewBlobData([]byte("blob content")), nil).
 		AddResponse(http.StatusNotFound, "Not found", httperrors.NotFound("Not found"), nil)
 
 	server.GET(routes.HasBlob(":hash"), handleHasBlob).
+		SetOperationId("hasBlob").
 		AddParamPath("", "hash", "Blob hash (base64)").
 		SetSummary("Find out if a blob exists in the registry").
 		AddResponse(http.StatusOK, "Blob properties", example, nil)
+
+	server.POST(routes.PutChainBlob(":chainID"), handlePutChainBlob).
+		SetOperationId("putChainBlob").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		SetSummary("Upload a blob to the registry on behalf of the chain. The blob is kept while the chain references it").
+		AddParamBody(model.NewBlobData([]byte("blob content")), "Blob", "Blob data", true).
+		AddResponse(http.StatusOK, "Blob properties", example, nil).
+		AddResponse(http.StatusRequestEntityTooLarge, "Blob quota of the chain exceeded", httperrors.RequestEntityTooLarge("Blob quota exceeded"), nil)
+
+	server.GET(routes.ChainBlobUsage(":chainID"), handleChainBlobUsage).
+		SetOperationId("getChainBlobUsage").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		SetSummary("Get the total size and number of blobs referenced by the chain").
+		AddResponse(http.StatusOK, "Blob usage", model.BlobUsage{}, nil)
 }
 
 func handlePutBlob(c echo.Context) error {
@@ -70,3 +91,38 @@ func handleHasBlob(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, model.NewBlobInfo(ok, hash))
 }
+
+func handlePutChainBlob(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+	var req model.BlobData
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	hash, err := registry.DefaultRegistry().PutChainBlob(&chainID, req.Data.Bytes())
+	if errors.Is(err, registry_pkg.ErrBlobQuotaExceeded) {
+		return httperrors.RequestEntityTooLarge(err.Error())
+	}
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewBlobInfo(true, hash))
+}
+
+func handleChainBlobUsage(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+	size, count, err := registry.DefaultRegistry().ChainBlobUsage(&chainID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.BlobUsage{
+		Size:  size,
+		Count: count,
+		Quota: registry.DefaultRegistry().BlobQuota(),
+	})
+}
@@ -0,0 +1,104 @@
+// Package chainevents streams messages of the node publisher related to a chain
+// (state transitions, requests and events of contracts) to websocket clients
+package chainevents
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/publisher"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+	"golang.org/x/net/websocket"
+)
+
+// messages waiting to be written to a slow client are limited. If the buffer is full, new messages
+// for the client are dropped rather than blocking the publisher
+const clientBufferSize = 100
+
+// types of publisher messages with the chain ID as the first part
+var chainMsgTypes = map[string]bool{
+	"state":       true,
+	"request_in":  true,
+	"request_out": true,
+	"vmmsg":       true,
+	"vmevent":     true,
+}
+
+// ChainID -> *sync.Map{} (map of connected clients)
+var clients = sync.Map{}
+
+var forwarderOnce sync.Once
+
+// AddEndpoints adds the websocket endpoint of chain events and the endpoint of their history.
+// Websockets are not described by the OpenAPI specification, so the websocket endpoint is added
+// directly to the echo server
+func AddEndpoints(server echoswagger.ApiRouter, e *echo.Echo) {
+	forwarderOnce.Do(startForwarder)
+	e.GET(routes.ChainEvents(":chainID"), handleChainEvents)
+	addHistoryEndpoint(server)
+}
+
+func handleChainEvents(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+
+	websocket.Handler(func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		v, _ := clients.LoadOrStore(chainID.String(), &sync.Map{})
+		chainClients := v.(*sync.Map)
+
+		clientCh := make(chan *model.ChainEvent, clientBufferSize)
+		chainClients.Store(clientCh, clientCh)
+		defer chainClients.Delete(clientCh)
+
+		// the client is not expected to send anything. Reading detects the closed connection
+		closed := make(chan struct{})
+		go func() {
+			var msg string
+			for websocket.Message.Receive(ws, &msg) == nil {
+			}
+			close(closed)
+		}()
+
+		for {
+			select {
+			case msg := <-clientCh:
+				if err := websocket.JSON.Send(ws, msg); err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}).ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+func startForwarder() {
+	publisher.Event.Attach(events.NewClosure(func(msgType string, parts []string) {
+		if !chainMsgTypes[msgType] || len(parts) < 1 {
+			return
+		}
+		v, ok := clients.Load(parts[0])
+		if !ok {
+			return
+		}
+		msg := &model.ChainEvent{Type: msgType, Parts: parts[1:]}
+		v.(*sync.Map).Range(func(key interface{}, clientCh interface{}) bool {
+			select {
+			case clientCh.(chan *model.ChainEvent) <- msg:
+			default:
+			}
+			return true
+		})
+	}))
+}
@@ -0,0 +1,173 @@
+package chainevents
+
+// Endpoint for the history of chain events, for clients which missed messages of the websocket endpoint.
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+// MaxHistoryBlocks is the maximum number of blocks returned by one call of the history endpoint
+const MaxHistoryBlocks = 100
+
+func addHistoryEndpoint(server echoswagger.ApiRouter) {
+	example := model.ChainEventsHistory{
+		From: 1,
+		To:   1,
+		Blocks: []*model.BlockEvents{{
+			BlockIndex: 1,
+			Events: []*model.ChainEvent{
+				{Type: "vmmsg", Parts: []string{"cebf5908", "hello"}},
+				{Type: "state", Parts: []string{"1", "1", "4ng7UGV5nA4ixQ2FWtCbexW5rrqbkJALHCFGmkdp6b6Z", "", "1611919553000000000"}},
+			},
+		}},
+	}
+
+	server.GET(routes.ChainEventsHistory(":chainID"), handleChainEventsHistory).
+		SetOperationId("getChainEventsHistory").
+		SetSummary("Get the messages of committed blocks of the chain, as streamed by the websocket endpoint").
+		SetDescription(fmt.Sprintf("Messages are reconstructed from the blocks and the event log of the chain. "+
+			"Records pruned from the event log are not returned. At most %d blocks are returned at once", MaxHistoryBlocks)).
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamQuery(uint32(0), "from", "Index of the first block", false).
+		AddParamQuery(uint32(0), "to", "Index of the last block (default: index of the solid state)", false).
+		AddResponse(http.StatusOK, "Messages of the blocks", example, nil)
+}
+
+func handleChainEventsHistory(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+	virtualState, _, ok, err := state.LoadSolidState(&chainID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httperrors.NotFound(fmt.Sprintf("State not found for chain %s", chainID.String()))
+	}
+	from, err := queryUint32(c, "from", 0)
+	if err != nil {
+		return err
+	}
+	to, err := queryUint32(c, "to", virtualState.BlockIndex())
+	if err != nil {
+		return err
+	}
+	if from > to || to > virtualState.BlockIndex() {
+		return httperrors.BadRequest(fmt.Sprintf("wrong block range %d..%d, solid state index is %d", from, to, virtualState.BlockIndex()))
+	}
+	if to-from >= MaxHistoryBlocks {
+		to = from + MaxHistoryBlocks - 1
+	}
+	blocks, err := state.LoadBlocks(&chainID, from, to)
+	if err != nil {
+		return err
+	}
+	// requests of the block are processed after the timestamp of the previous block
+	prevTimestamp := int64(0)
+	if from > 0 {
+		prev, err := state.LoadBlock(&chainID, from-1)
+		if err != nil {
+			return err
+		}
+		if prev != nil {
+			prevTimestamp = prev.Timestamp()
+		}
+	}
+
+	vars := virtualState.Variables()
+	contracts := root.GetContractHnames(subrealm.New(vars, kv.Key(root.Interface.Hname().Bytes())))
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i] < contracts[j] })
+	logState := subrealm.New(vars, kv.Key(eventlog.Interface.Hname().Bytes()))
+
+	ret := &model.ChainEventsHistory{From: from, To: to, Blocks: make([]*model.BlockEvents, 0, len(blocks))}
+	for _, b := range blocks {
+		ret.Blocks = append(ret.Blocks, blockEvents(b, prevTimestamp, contracts, logState))
+		prevTimestamp = b.Timestamp()
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+// blockEvents reconstructs the messages published while the block was processed, in the same order:
+// processed requests, events of contracts ordered by time and the state transition.
+// The state hash is not known from the block, so it is empty in the state transition
+func blockEvents(b state.Block, prevTimestamp int64, contracts []coretypes.Hname, logState kv.KVStoreReader) *model.BlockEvents {
+	ret := &model.BlockEvents{BlockIndex: b.StateIndex(), Events: make([]*model.ChainEvent, 0)}
+	blockIndex := strconv.Itoa(int(b.StateIndex()))
+	blockSize := strconv.Itoa(int(b.Size()))
+
+	for i, reqid := range b.RequestIDs() {
+		if *reqid == (coretypes.RequestID{}) {
+			continue
+		}
+		ret.Events = append(ret.Events, &model.ChainEvent{
+			Type:  "request_out",
+			Parts: []string{reqid.TransactionID().String(), strconv.Itoa(int(reqid.Index())), blockIndex, strconv.Itoa(i), blockSize},
+		})
+	}
+
+	type timedEvent struct {
+		ts int64
+		ev *model.ChainEvent
+	}
+	contractEvents := make([]timedEvent, 0)
+	for _, hname := range contracts {
+		records, events := eventlog.RecordsBetween(logState, hname, prevTimestamp+1, b.Timestamp())
+		for _, r := range records {
+			contractEvents = append(contractEvents, timedEvent{
+				ts: r.Timestamp,
+				ev: &model.ChainEvent{Type: "vmmsg", Parts: []string{hname.String(), string(r.Data)}},
+			})
+		}
+		for _, raw := range events {
+			ts, ev, err := eventlog.ParseEventRecord(raw)
+			if err != nil {
+				continue
+			}
+			parts := []string{hname.String(), ev.Topic}
+			for _, k := range ev.Fields.KeysSorted() {
+				parts = append(parts, fmt.Sprintf("%s=%s", string(k), hex.EncodeToString(ev.Fields[k])))
+			}
+			contractEvents = append(contractEvents, timedEvent{ts: ts, ev: &model.ChainEvent{Type: "vmevent", Parts: parts}})
+		}
+	}
+	sort.SliceStable(contractEvents, func(i, j int) bool { return contractEvents[i].ts < contractEvents[j].ts })
+	for _, e := range contractEvents {
+		ret.Events = append(ret.Events, e.ev)
+	}
+
+	txid := b.StateTransactionID()
+	ret.Events = append(ret.Events, &model.ChainEvent{
+		Type:  "state",
+		Parts: []string{blockIndex, blockSize, txid.String(), "", strconv.FormatInt(b.Timestamp(), 10)},
+	})
+	return ret
+}
+
+func queryUint32(c echo.Context, name string, def uint32) (uint32, error) {
+	s := c.QueryParam(name)
+	if s == "" {
+		return def, nil
+	}
+	ret, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, httperrors.BadRequest(fmt.Sprintf("invalid '%s': %v", name, err))
+	}
+	return uint32(ret), nil
+}
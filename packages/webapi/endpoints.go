@@ -6,6 +6,8 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/webapi/admapi"
 	"github.com/iotaledger/wasp/packages/webapi/blob"
+	"github.com/iotaledger/wasp/packages/webapi/chainevents"
+	"github.com/iotaledger/wasp/packages/webapi/health"
 	"github.com/iotaledger/wasp/packages/webapi/info"
 	"github.com/iotaledger/wasp/packages/webapi/request"
 	"github.com/iotaledger/wasp/packages/webapi/state"
@@ -16,7 +18,13 @@ var log *logger.Logger
 
 func Init(server echoswagger.ApiRoot, adminWhitelist []net.IP) {
 	log = logger.NewLogger("WebAPI")
+	admapi.InitLogger()
+	request.InitLogger()
+	addEndpoints(server, adminWhitelist)
+	log.Infof("added web api endpoints")
+}
 
+func addEndpoints(server echoswagger.ApiRoot, adminWhitelist []net.IP) {
 	server.SetRequestContentType("application/json")
 	server.SetResponseContentType("application/json")
 
@@ -25,8 +33,9 @@ func Init(server echoswagger.ApiRoot, adminWhitelist []net.IP) {
 	info.AddEndpoints(pub)
 	request.AddEndpoints(pub)
 	state.AddEndpoints(pub)
+	chainevents.AddEndpoints(pub, server.Echo())
+	health.AddEndpoints(pub)
 
 	adm := server.Group("admin", "").SetDescription("Admin endpoints")
 	admapi.AddEndpoints(adm, adminWhitelist)
-	log.Infof("added web api endpoints")
 }
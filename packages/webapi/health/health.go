@@ -0,0 +1,111 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/iotaledger/wasp/plugins/nodeconn"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func AddEndpoints(server echoswagger.ApiRouter) {
+	example := model.NodeHealth{
+		Ready:         true,
+		NodeConnected: true,
+		Chains: []*model.ChainHealth{{
+			ChainID:        "atoi1qqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqq",
+			Ready:          true,
+			InCommittee:    true,
+			Synced:         true,
+			Quorum:         true,
+			StateIndex:     42,
+			StateTimestamp: time.Now(),
+			StateAge:       3,
+		}},
+	}
+
+	server.GET(routes.Healthz(), handleHealthz).
+		SetOperationId("getHealth").
+		SetSummary("Liveness probe: health of the node and its chains. Always 200 while the node is running").
+		AddResponse(http.StatusOK, "Node health", example, nil)
+
+	server.GET(routes.Readyz(), handleReadyz).
+		SetOperationId("getReadiness").
+		SetSummary("Readiness probe: health of the node and its chains. 503 if the node or any chain is not ready").
+		AddResponse(http.StatusOK, "Node is ready", example, nil).
+		AddResponse(http.StatusServiceUnavailable, "Node is not ready", example, nil)
+}
+
+func handleHealthz(c echo.Context) error {
+	return c.JSON(http.StatusOK, nodeHealth())
+}
+
+func handleReadyz(c echo.Context) error {
+	h := nodeHealth()
+	if !h.Ready {
+		return c.JSON(http.StatusServiceUnavailable, h)
+	}
+	return c.JSON(http.StatusOK, h)
+}
+
+func nodeHealth() *model.NodeHealth {
+	ret := &model.NodeHealth{
+		NodeConnected: nodeconn.IsConnected(),
+		Chains:        make([]*model.ChainHealth, 0),
+	}
+	maxStateAge := time.Duration(parameters.GetInt(parameters.WebAPIMaxStateAge)) * time.Second
+	now := time.Now()
+	for _, ch := range chains.GetChains() {
+		ret.Chains = append(ret.Chains, chainHealth(ch, maxStateAge, now))
+	}
+	sort.Slice(ret.Chains, func(i, j int) bool { return ret.Chains[i].ChainID < ret.Chains[j].ChainID })
+
+	ret.Ready = ret.NodeConnected
+	for _, h := range ret.Chains {
+		ret.Ready = ret.Ready && h.Ready
+	}
+	return ret
+}
+
+func chainHealth(ch chain.Chain, maxStateAge time.Duration, now time.Time) *model.ChainHealth {
+	ret := &model.ChainHealth{
+		ChainID:  model.NewChainID(ch.ID()),
+		Synced:   true,
+		Quorum:   true,
+		Draining: ch.IsDraining(),
+		Problems: make([]string, 0),
+	}
+	if info := ch.ConsensusInfo(); info != nil {
+		ret.InCommittee = true
+		ret.Synced = info.Synced
+		ret.Quorum = ch.HasQuorum()
+		ret.StateIndex = info.StateIndex
+		ret.StateTimestamp = info.StateTimestamp
+		if !info.StateTimestamp.IsZero() && info.StateTimestamp.Before(now) {
+			ret.StateAge = int64(now.Sub(info.StateTimestamp) / time.Second)
+		}
+	}
+
+	if !ret.Synced {
+		ret.Problems = append(ret.Problems, "state is not synced")
+	}
+	if !ret.Quorum {
+		ret.Problems = append(ret.Problems, "quorum of the committee is not reachable")
+	}
+	if ret.Draining {
+		ret.Problems = append(ret.Problems, "chain is draining")
+	}
+	if ret.InCommittee && maxStateAge > 0 && time.Duration(ret.StateAge)*time.Second > maxStateAge {
+		ret.Problems = append(ret.Problems, fmt.Sprintf("last state transition was %ds ago", ret.StateAge))
+	}
+	ret.Ready = len(ret.Problems) == 0
+	return ret
+}
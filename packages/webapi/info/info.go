@@ -1,6 +1,7 @@
 package info
 
 import (
+	"encoding/base64"
 	"net/http"
 
 	"github.com/iotaledger/wasp/packages/parameters"
@@ -14,14 +15,21 @@ import (
 
 func AddEndpoints(server echoswagger.ApiRouter) {
 	server.GET(routes.Info(), handleInfo).
+		SetOperationId("getInfo").
 		SetSummary("Get information about the node").
 		AddResponse(http.StatusOK, "Node properties", model.InfoResponse{}, nil)
 }
 
 func handleInfo(c echo.Context) error {
+	self := peering.DefaultNetworkProvider().Self()
+	pubKey, err := self.PubKey().MarshalBinary()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
 	return c.JSON(http.StatusOK, model.InfoResponse{
 		Version:       banner.AppVersion,
-		NetworkId:     peering.DefaultNetworkProvider().Self().NetID(),
+		NetworkId:     self.NetID(),
 		PublisherPort: parameters.GetInt(parameters.NanomsgPublisherPort),
+		PubKey:        base64.StdEncoding.EncodeToString(pubKey),
 	})
 }
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+)
+
+type RequestReceipt struct {
+	RequestID    string `swagger:"desc(ID of the request (base58))"`
+	Timestamp    int64  `swagger:"desc(Time the request was processed, in Unix nanoseconds)"`
+	BlockIndex   uint32 `swagger:"desc(Index of the block the request was processed in)"`
+	RequestIndex uint16 `swagger:"desc(Index of the request in the block)"`
+	Contract     string `swagger:"desc(Hname of the target contract)"`
+	EntryPoint   string `swagger:"desc(Hname of the target entry point)"`
+	ErrorCode    uint16 `swagger:"desc(Stable code of the error, 0 on success)"`
+	Error        string `swagger:"desc(Error returned by the request, empty on success)"`
+}
+
+type AccountRequests struct {
+	AgentID  string            `swagger:"desc(Agent ID of the sender of the requests (bech32))"`
+	Total    uint32            `swagger:"desc(Number of requests of the agent ever processed by the chain, including pruned receipts)"`
+	Receipts []*RequestReceipt `swagger:"desc(Receipts of the requests, the latest first)"`
+	Next     *uint32           `swagger:"desc(Value of the 'before' parameter to get the next page, null if there are no older receipts)"`
+}
+
+func NewRequestReceipt(ts int64, r *eventlog.RequestReceipt) *RequestReceipt {
+	return &RequestReceipt{
+		RequestID:    r.RequestID.Base58(),
+		Timestamp:    ts,
+		BlockIndex:   r.BlockIndex,
+		RequestIndex: r.RequestIndex,
+		Contract:     r.Contract.String(),
+		EntryPoint:   r.EntryPoint.String(),
+		ErrorCode:    uint16(r.ErrorCode),
+		Error:        r.Error,
+	}
+}
+
+func NewAccountRequests(agentID coretypes.AgentID, total uint32) *AccountRequests {
+	return &AccountRequests{
+		AgentID:  agentID.Bech32(),
+		Total:    total,
+		Receipts: make([]*RequestReceipt, 0),
+	}
+}
@@ -18,3 +18,9 @@ type BlobInfo struct {
 func NewBlobInfo(exists bool, hash hashing.HashValue) *BlobInfo {
 	return &BlobInfo{Exists: exists, Hash: NewHashValue(hash)}
 }
+
+type BlobUsage struct {
+	Size  int64 `swagger:"desc(Total size of blobs referenced by the chain, in bytes)"`
+	Count int   `swagger:"desc(Number of blobs referenced by the chain)"`
+	Quota int64 `swagger:"desc(Maximum total size of blobs referenced by the chain, in bytes. 0 means unlimited)"`
+}
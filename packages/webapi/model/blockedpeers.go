@@ -0,0 +1,5 @@
+package model
+
+type BlockedPeers struct {
+	NetIDs []string `swagger:"desc(NetIDs of the peers the node exchanges no messages with)"`
+}
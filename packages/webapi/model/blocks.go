@@ -0,0 +1,8 @@
+package model
+
+// ChainBlocks is the sequence of blocks of the chain, serialized with state.WriteBlocks
+type ChainBlocks struct {
+	From uint32 `swagger:"desc(Index of the first block)"`
+	To   uint32 `swagger:"desc(Index of the last block)"`
+	Data Bytes  `swagger:"desc(Serialized blocks (base64-encoded))"`
+}
@@ -0,0 +1,23 @@
+package model
+
+// ChainEvent is the message of the node publisher related to the chain, streamed by the websocket
+// endpoint of the chain. Type is one of 'state', 'request_in', 'request_out', 'vmmsg' and 'vmevent'.
+// Parts are the parts of the published message without the chain ID
+type ChainEvent struct {
+	Type  string   `json:"type" swagger:"desc(Type of the message)"`
+	Parts []string `json:"parts" swagger:"desc(Parts of the message, without the chain ID)"`
+}
+
+// BlockEvents are the messages published by the node while processing the block: processed requests,
+// events of contracts and the state transition, in the format of the websocket endpoint
+type BlockEvents struct {
+	BlockIndex uint32        `json:"blockIndex" swagger:"desc(Index of the block)"`
+	Events     []*ChainEvent `json:"events" swagger:"desc(Messages of the block, the state transition is the last one)"`
+}
+
+// ChainEventsHistory are the messages of the committed blocks of the chain
+type ChainEventsHistory struct {
+	From   uint32         `json:"from" swagger:"desc(Index of the first block)"`
+	To     uint32         `json:"to" swagger:"desc(Index of the last block)"`
+	Blocks []*BlockEvents `json:"blocks" swagger:"desc(Messages of each block)"`
+}
@@ -6,11 +6,11 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 )
 
-// ChainID is the base58 representation of coretypes.ChainID
+// ChainID is the bech32 representation of coretypes.ChainID. The base58 representation is also accepted
 type ChainID string
 
 func NewChainID(chainID *coretypes.ChainID) ChainID {
-	return ChainID(chainID.String())
+	return ChainID(chainID.Bech32())
 }
 
 func (ch ChainID) MarshalJSON() ([]byte, error) {
@@ -22,13 +22,13 @@ func (ch *ChainID) UnmarshalJSON(b []byte) error {
 	if err := json.Unmarshal(b, &s); err != nil {
 		return err
 	}
-	_, err := coretypes.NewChainIDFromBase58(s)
+	_, err := coretypes.NewChainIDFromString(s)
 	*ch = ChainID(s)
 	return err
 }
 
 func (ch ChainID) ChainID() coretypes.ChainID {
-	chainID, err := coretypes.NewChainIDFromBase58(string(ch))
+	chainID, err := coretypes.NewChainIDFromString(string(ch))
 	if err != nil {
 		panic(err)
 	}
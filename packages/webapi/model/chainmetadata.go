@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+type ChainMetadata struct {
+	ChainID     ChainID           `swagger:"desc(ChainID (bech32-encoded))"`
+	Description string            `swagger:"desc(Description of the chain)"`
+	Website     string            `swagger:"desc(Website of the chain)"`
+	Values      map[string]string `swagger:"desc(Custom metadata of the chain)"`
+}
+
+func NewChainMetadata(chainID *coretypes.ChainID, meta *root.ChainMetadata) *ChainMetadata {
+	return &ChainMetadata{
+		ChainID:     NewChainID(chainID),
+		Description: meta.Description,
+		Website:     meta.Website,
+		Values:      meta.Values,
+	}
+}
+
+func (m *ChainMetadata) ChainMetadata() *root.ChainMetadata {
+	values := m.Values
+	if values == nil {
+		values = make(map[string]string)
+	}
+	return &root.ChainMetadata{
+		Description: m.Description,
+		Website:     m.Website,
+		Values:      values,
+	}
+}
@@ -8,6 +8,7 @@ type ChainRecord struct {
 	ChainID        ChainID  `swagger:"desc(ChainID (base58-encoded))"`
 	Color          Color    `swagger:"desc(Chain color (base58-encoded))"`
 	CommitteeNodes []string `swagger:"desc(List of committee nodes (network IDs))"`
+	AccessNodes    []string `swagger:"desc(List of access nodes (network IDs))"`
 	Active         bool     `swagger:"desc(Whether or not the chain is active)"`
 }
 
@@ -16,6 +17,7 @@ func NewChainRecord(bd *registry.ChainRecord) *ChainRecord {
 		ChainID:        NewChainID(&bd.ChainID),
 		Color:          NewColor(&bd.Color),
 		CommitteeNodes: bd.CommitteeNodes[:],
+		AccessNodes:    bd.AccessNodes[:],
 		Active:         bd.Active,
 	}
 }
@@ -25,6 +27,7 @@ func (bd *ChainRecord) ChainRecord() *registry.ChainRecord {
 		ChainID:        bd.ChainID.ChainID(),
 		Color:          bd.Color.Color(),
 		CommitteeNodes: bd.CommitteeNodes[:],
+		AccessNodes:    bd.AccessNodes[:],
 		Active:         bd.Active,
 	}
 }
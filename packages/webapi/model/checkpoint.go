@@ -0,0 +1,28 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/registry"
+)
+
+type StateCheckpoint struct {
+	StateIndex uint32    `swagger:"desc(Index of the state at the checkpoint)"`
+	StateHash  HashValue `swagger:"desc(Hash of the state at the checkpoint (base58-encoded))"`
+	BlockHash  HashValue `swagger:"desc(Essence hash of the block of the state at the checkpoint (base58-encoded))"`
+	Timestamp  int64     `swagger:"desc(Time when the checkpoint was recorded (unix nanoseconds))"`
+}
+
+type StateCheckpointVerification struct {
+	ChainID    ChainID          `swagger:"desc(ChainID (base58-encoded))"`
+	Checkpoint *StateCheckpoint `swagger:"desc(The last checkpoint of the chain, null if there's none)"`
+	Valid      bool             `swagger:"desc(True if the state of the chain stored by the node matches the checkpoint)"`
+	Error      string           `swagger:"desc(Description of the inconsistency if the state is not valid)"`
+}
+
+func NewStateCheckpoint(cp *registry.StateCheckpoint) *StateCheckpoint {
+	return &StateCheckpoint{
+		StateIndex: cp.StateIndex,
+		StateHash:  NewHashValue(cp.StateHash),
+		BlockHash:  NewHashValue(cp.BlockHash),
+		Timestamp:  cp.Timestamp,
+	}
+}
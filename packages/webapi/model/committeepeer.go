@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+)
+
+type CommitteePeer struct {
+	Index     int       `swagger:"desc(Index of the peer in the committee)"`
+	NetID     string    `swagger:"desc(Network ID of the peer)"`
+	IsSelf    bool      `swagger:"desc(True if the peer is the node itself)"`
+	Connected bool      `swagger:"desc(True if the node is connected with the peer)"`
+	Alive     bool      `swagger:"desc(True if the peer is connected and was heard recently)"`
+	LastHeard time.Time `swagger:"desc(Time of the last message received from the peer. Zero if nothing was received)"`
+}
+
+func NewCommitteePeer(s *chain.PeerStatus) *CommitteePeer {
+	return &CommitteePeer{
+		Index:     s.Index,
+		NetID:     s.PeeringID,
+		IsSelf:    s.IsSelf,
+		Connected: s.Connected,
+		Alive:     s.Alive,
+		LastHeard: s.LastHeard,
+	}
+}
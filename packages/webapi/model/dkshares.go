@@ -8,6 +8,16 @@ type DKSharesPostRequest struct {
 	TimeoutMS   uint16   `json:"timeoutMS" swagger:"desc(Timeout in milliseconds.)"`
 }
 
+// DKSharesReshareRequest is a POST request for resharing an existing DKShare to a new group of peers.
+type DKSharesReshareRequest struct {
+	OldPeerNetIDs  []string `json:"oldPeerNetIDs" swagger:"desc(NetIDs of the nodes sharing the key now, in the order of their share indexes.)"`
+	OldPeerPubKeys []string `json:"oldPeerPubKeys" swagger:"desc(Optional, base64 encoded public keys of the nodes sharing the key now.)"`
+	PeerNetIDs     []string `json:"peerNetIDs" swagger:"desc(NetIDs of the nodes sharing the key after the resharing.)"`
+	PeerPubKeys    []string `json:"peerPubKeys" swagger:"desc(Optional, base64 encoded public keys of the nodes sharing the key after the resharing.)"`
+	Threshold      uint16   `json:"threshold" swagger:"desc(Should be =< len(PeerNetIDs))"`
+	TimeoutMS      uint16   `json:"timeoutMS" swagger:"desc(Timeout in milliseconds.)"`
+}
+
 // DKSharesInfo stands for the DKShare representation, returned by the GET and POST methods.
 type DKSharesInfo struct {
 	Address      string   `json:"address" swagger:"desc(New generated shared address.)"`
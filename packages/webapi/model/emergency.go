@@ -0,0 +1,40 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/registry"
+)
+
+type DeclareEmergencyRequest struct {
+	Delegate  uint16 `swagger:"desc(Index of the committee node the block production is delegated to)"`
+	NumBlocks uint32 `swagger:"desc(Number of blocks the emergency mode lasts)"`
+}
+
+type SignedEmergencyVote struct {
+	PeerIndex uint16 `swagger:"desc(Index of the peer which voted)"`
+	SigShare  Bytes  `swagger:"desc(Signature share of the peer (base64-encoded))"`
+}
+
+type EmergencyRecord struct {
+	ChainID    ChainID               `swagger:"desc(ChainID (base58-encoded))"`
+	BlockIndex uint32                `swagger:"desc(Index of the state in which the emergency mode was activated)"`
+	Delegate   uint16                `swagger:"desc(Index of the committee node the block production was delegated to)"`
+	NumBlocks  uint32                `swagger:"desc(Number of delegated blocks)"`
+	Votes      []SignedEmergencyVote `swagger:"desc(Signed votes of committee nodes)"`
+}
+
+func NewEmergencyRecord(rec *registry.EmergencyRecord) *EmergencyRecord {
+	ret := &EmergencyRecord{
+		ChainID:    NewChainID(&rec.ChainID),
+		BlockIndex: rec.BlockIndex,
+		Delegate:   rec.Delegate,
+		NumBlocks:  rec.NumBlocks,
+		Votes:      make([]SignedEmergencyVote, len(rec.Votes)),
+	}
+	for i, v := range rec.Votes {
+		ret.Votes[i] = SignedEmergencyVote{
+			PeerIndex: v.PeerIndex,
+			SigShare:  NewBytes(v.SigShare),
+		}
+	}
+	return ret
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/registry"
+)
+
+type EquivocationEvidence struct {
+	ChainID     ChainID `swagger:"desc(ChainID (base58-encoded))"`
+	BlockIndex  uint32  `swagger:"desc(Index of the block in which the leader equivocated)"`
+	LeaderIndex uint16  `swagger:"desc(Peer index of the equivocating leader)"`
+	Evidence    Bytes   `swagger:"desc(Conflicting proposals signed by the leader (base64-encoded). It is the parameter of the root.recordEquivocation entry point)"`
+}
+
+func NewEquivocationEvidence(ev *registry.EquivocationEvidence) *EquivocationEvidence {
+	return &EquivocationEvidence{
+		ChainID:     NewChainID(&ev.ChainID),
+		BlockIndex:  ev.BlockIndex,
+		LeaderIndex: ev.LeaderIndex,
+		Evidence:    NewBytes(ev.Data),
+	}
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type NodeHealth struct {
+	Ready         bool           `swagger:"desc(True if the node is connected to the IOTA node and all active chains are ready)"`
+	NodeConnected bool           `swagger:"desc(True if the node is connected to the IOTA (Goshimmer) node)"`
+	Chains        []*ChainHealth `swagger:"desc(Health of active chains)"`
+}
+
+type ChainHealth struct {
+	ChainID        ChainID   `swagger:"desc(ChainID (bech32-encoded))"`
+	Ready          bool      `swagger:"desc(True if the chain is synced, the quorum of the committee is reachable and the last state is not too old)"`
+	InCommittee    bool      `swagger:"desc(True if the node is a member of the committee of the chain)"`
+	Synced         bool      `swagger:"desc(True if the state of the chain is synced with the ledger. Always true if the node is not in the committee)"`
+	Quorum         bool      `swagger:"desc(True if the quorum of committee peers is alive)"`
+	Draining       bool      `swagger:"desc(True if the chain doesn't accept new requests and is going to be deactivated)"`
+	StateIndex     uint32    `swagger:"desc(Index of the last state of the chain)"`
+	StateTimestamp time.Time `swagger:"desc(Timestamp of the last state of the chain. Zero if unknown)"`
+	StateAge       int64     `swagger:"desc(Seconds since the last state transition of the chain. 0 if unknown)"`
+	Problems       []string  `swagger:"desc(Reasons the chain is not ready)"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"encoding/base64"
+
+	"github.com/iotaledger/wasp/packages/peering"
+)
+
+type RotateIdentityRequest struct {
+	Revoke bool `swagger:"desc(Revoke the current key, e.g. because it was compromised. Peers won't accept it for any node)"`
+}
+
+type IdentityHandover struct {
+	NetID     string `swagger:"desc(NetID of the node which rotated the identity key)"`
+	OldPubKey string `swagger:"desc(Previous identity public key of the node (base64-encoded))"`
+	NewPubKey string `swagger:"desc(New identity public key of the node (base64-encoded))"`
+	Revoked   bool   `swagger:"desc(Whether the previous key is revoked)"`
+	Timestamp int64  `swagger:"desc(Time of the rotation in nanoseconds)"`
+}
+
+func NewIdentityHandover(h *peering.IdentityHandover) (*IdentityHandover, error) {
+	oldPubKey, err := h.OldPubKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	newPubKey, err := h.NewPubKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &IdentityHandover{
+		NetID:     h.NetID,
+		OldPubKey: base64.StdEncoding.EncodeToString(oldPubKey),
+		NewPubKey: base64.StdEncoding.EncodeToString(newPubKey),
+		Revoked:   h.Revoked,
+		Timestamp: h.Timestamp,
+	}, nil
+}
@@ -4,4 +4,5 @@ type InfoResponse struct {
 	Version       string `swagger:"desc(Wasp version)"`
 	NetworkId     string `swagger:"desc('hostname:port'; uniquely identifies the node)"`
 	PublisherPort int    `swagger:"desc(Nanomsg port that exposes publisher messages)"`
+	PubKey        string `swagger:"desc(Identity public key of the node (base64-encoded))"`
 }
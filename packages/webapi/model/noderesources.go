@@ -0,0 +1,24 @@
+package model
+
+import "runtime"
+
+type NodeResources struct {
+	Goroutines int    `swagger:"desc(Number of goroutines of the node)"`
+	HeapAlloc  uint64 `swagger:"desc(Bytes of allocated heap objects)"`
+	HeapSys    uint64 `swagger:"desc(Bytes of heap memory obtained from the OS)"`
+	Sys        uint64 `swagger:"desc(Total bytes of memory obtained from the OS)"`
+	NumGC      uint32 `swagger:"desc(Number of completed GC cycles)"`
+}
+
+// NewNodeResources reads the resource usage of the running node process
+func NewNodeResources() *NodeResources {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return &NodeResources{
+		Goroutines: runtime.NumGoroutine(),
+		HeapAlloc:  m.HeapAlloc,
+		HeapSys:    m.HeapSys,
+		Sys:        m.Sys,
+		NumGC:      m.NumGC,
+	}
+}
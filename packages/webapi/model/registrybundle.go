@@ -0,0 +1,17 @@
+package model
+
+// RegistryBundle is the serialized and signed export of the node registry.
+type RegistryBundle struct {
+	Data Bytes `swagger:"desc(Signed registry bundle (base64-encoded))"`
+}
+
+// RegistryExportRequest is a request to export the node registry for the importing node.
+type RegistryExportRequest struct {
+	RecipientPubKey string `swagger:"desc(Identity public key of the importing node (base64-encoded). DK shares are encrypted to it)"`
+}
+
+// RegistryImportRequest is a request to import a registry bundle exported by a trusted node.
+type RegistryImportRequest struct {
+	Data           Bytes  `swagger:"desc(Signed registry bundle (base64-encoded))"`
+	ExporterPubKey string `swagger:"desc(Identity public key of the exporting node (base64-encoded). The bundle must be signed with it)"`
+}
@@ -0,0 +1,71 @@
+package model
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/vm"
+	"github.com/iotaledger/wasp/packages/vm/simulate"
+)
+
+type SimulateRequest struct {
+	Transaction Bytes  `swagger:"desc(Request transaction, doesn't need to be signed (base64))"`
+	Index       uint16 `swagger:"desc(Index of the request in the transaction)"`
+}
+
+type SimulatedEvent struct {
+	Contract string    `swagger:"desc(Hname of the contract which emitted the event)"`
+	Message  string    `swagger:"desc(Message of the event)"`
+	Topic    string    `swagger:"desc(Topic of the typed event, empty for plain messages)"`
+	Fields   dict.Dict `swagger:"desc(Fields of the typed event)"`
+}
+
+type StateMutation struct {
+	Key   Bytes  `swagger:"desc(Key of the state (base64))"`
+	Value *Bytes `swagger:"desc(New value of the key (base64), null if the key is deleted)"`
+}
+
+type SimulationResult struct {
+	Result       dict.Dict         `swagger:"desc(Result of the called entry point)"`
+	Error        string            `swagger:"desc(Error returned by the called entry point, empty on success)"`
+	ErrorCode    uint16            `swagger:"desc(Stable code of the error, 0 on success)"`
+	Events       []*SimulatedEvent `swagger:"desc(Events emitted while the request was run)"`
+	FeeColor     Color             `swagger:"desc(Color of the fees of the target contract)"`
+	OwnerFee     int64             `swagger:"desc(Fee charged by the chain owner)"`
+	ValidatorFee int64             `swagger:"desc(Fee charged by the validators)"`
+	StateDiff    []*StateMutation  `swagger:"desc(Changes of the state of the chain, ordered by keys)"`
+}
+
+func NewSimulationResult(res *simulate.Result) *SimulationResult {
+	ret := &SimulationResult{
+		Result:       res.Result,
+		Events:       make([]*SimulatedEvent, len(res.Events)),
+		FeeColor:     NewColor(&res.FeeColor),
+		OwnerFee:     res.OwnerFee,
+		ValidatorFee: res.ValidatorFee,
+		StateDiff:    make([]*StateMutation, len(res.StateDiff)),
+	}
+	if res.Error != nil {
+		ret.Error = res.Error.Error()
+		ret.ErrorCode = uint16(coretypes.VMErrorCodeOf(res.Error))
+	}
+	for i, ev := range res.Events {
+		ret.Events[i] = newSimulatedEvent(ev)
+	}
+	for i, mut := range res.StateDiff {
+		ret.StateDiff[i] = &StateMutation{Key: NewBytes([]byte(mut.Key))}
+		if mut.Value != nil {
+			value := NewBytes(mut.Value)
+			ret.StateDiff[i].Value = &value
+		}
+	}
+	return ret
+}
+
+func newSimulatedEvent(ev *vm.Event) *SimulatedEvent {
+	return &SimulatedEvent{
+		Contract: ev.Contract.String(),
+		Message:  ev.Message,
+		Topic:    ev.Topic,
+		Fields:   ev.Fields,
+	}
+}
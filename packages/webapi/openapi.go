@@ -0,0 +1,35 @@
+package webapi
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/iotaledger/wasp/packages/webapi/openapi"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+// AddOpenAPIEndpoint serves the OpenAPI v3 specification of the web API at <docPath>/openapi.json,
+// next to the Swagger 2.0 specification served by echoswagger at <docPath>/swagger.json.
+// The specification is generated on the first request, when all endpoints are registered
+func AddOpenAPIEndpoint(server echoswagger.ApiRoot, docPath string) {
+	var once sync.Once
+	var spec []byte
+	var specErr error
+
+	server.Echo().GET(docPath+"/openapi.json", func(c echo.Context) error {
+		once.Do(func() {
+			var doc *openapi.Document
+			doc, specErr = openapi.Generate(server.Echo(), docPath+"/swagger.json", c.Request().Header)
+			if specErr != nil {
+				log.Errorf("failed to generate the OpenAPI specification: %v", specErr)
+				return
+			}
+			spec, specErr = doc.JSON()
+		})
+		if specErr != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, specErr)
+		}
+		return c.JSONBlob(http.StatusOK, spec)
+	})
+}
@@ -0,0 +1,344 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// GoType is the Go type used by the generated client for the schema in components
+type GoType struct {
+	// Name is the qualified name of the type, for example 'dict.Dict'
+	Name string
+	// Import is the import path of the package of the type
+	Import string
+	// Value is true if the type is used by value, like maps and slices. Otherwise it is used by pointer
+	Value bool
+}
+
+const modelImport = "github.com/iotaledger/wasp/packages/webapi/model"
+
+// DefaultGoTypes maps schemas of the web API which are not declared in the 'model' package to Go types.
+// Other schemas are mapped to the types of the 'model' package with the same name
+var DefaultGoTypes = map[string]GoType{
+	"JSONDict": {Name: "dict.Dict", Import: "github.com/iotaledger/wasp/packages/kv/dict", Value: true},
+}
+
+// GenerateClient generates the source code of the typed client of the web API described by the document.
+// The client is the type API in the package pkg, which is expected to be the 'client' package with
+// the WaspClient type. Every operation of the document must have the operation ID, which becomes
+// the name of the method of the client
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &clientGen{
+		imports: map[string]bool{"net/http": true},
+	}
+	methods := doc.Methods()
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].OperationID < methods[j].OperationID
+	})
+	names := make(map[string]bool)
+	var body bytes.Buffer
+	for _, m := range methods {
+		if m.OperationID == "" {
+			return nil, fmt.Errorf("%s %s: operation ID is missing", m.HTTPMethod, m.Path)
+		}
+		name := exportedName(m.OperationID)
+		if names[name] {
+			return nil, fmt.Errorf("%s %s: duplicate operation ID '%s'", m.HTTPMethod, m.Path, m.OperationID)
+		}
+		names[name] = true
+		if err := g.writeMethod(&body, name, m); err != nil {
+			return nil, fmt.Errorf("%s %s: %v", m.HTTPMethod, m.Path, err)
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by tools/api-gen from the OpenAPI specification of the web API. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", pkg)
+	src.WriteString("import (\n")
+	std, other := make([]string, 0), make([]string, 0)
+	for imp := range g.imports {
+		if strings.Contains(imp, ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	for _, imp := range std {
+		fmt.Fprintf(&src, "\t%q\n", imp)
+	}
+	if len(other) > 0 {
+		src.WriteString("\n")
+	}
+	for _, imp := range other {
+		fmt.Fprintf(&src, "\t%q\n", imp)
+	}
+	src.WriteString(")\n\n")
+	src.WriteString("// API is the typed client of the Wasp web API, with one method per operation of the OpenAPI specification\n")
+	src.WriteString("type API struct {\n\tc *WaspClient\n}\n\n")
+	src.WriteString("// API returns the typed client of the web API\n")
+	src.WriteString("func (c *WaspClient) API() *API {\n\treturn &API{c: c}\n}\n")
+	src.Write(body.Bytes())
+
+	return format.Source(src.Bytes())
+}
+
+type clientGen struct {
+	imports map[string]bool
+}
+
+type goParam struct {
+	name     string
+	apiName  string
+	goType   string
+	required bool
+}
+
+func (g *clientGen) writeMethod(w *bytes.Buffer, name string, m Method) error {
+	used := map[string]bool{"route": true, "query": true, "res": true, "err": true, "body": true}
+	var pathParams, queryParams []goParam
+	for _, p := range m.Parameters {
+		t, err := g.primitiveType(p.Schema)
+		if err != nil {
+			return fmt.Errorf("parameter '%s': %v", p.Name, err)
+		}
+		gp := goParam{name: paramName(p.Name, used), apiName: p.Name, goType: t, required: p.Required}
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, gp)
+		case "query":
+			queryParams = append(queryParams, gp)
+		default:
+			return fmt.Errorf("parameter '%s' in %s is not supported", p.Name, p.In)
+		}
+	}
+	args := make([]string, 0)
+	for _, p := range pathParams {
+		args = append(args, p.name+" string")
+	}
+	for _, p := range queryParams {
+		if p.required {
+			args = append(args, p.name+" "+p.goType)
+		} else {
+			args = append(args, p.name+" *"+p.goType)
+		}
+	}
+	bodyArg := "nil"
+	if m.RequestBody != nil {
+		t, err := g.schemaType(m.RequestBody.Content[contentTypeJSON])
+		if err != nil {
+			return fmt.Errorf("request body: %v", err)
+		}
+		args = append(args, "body "+t.Name)
+		bodyArg = "body"
+	}
+	res, err := g.responseType(m.Operation)
+	if err != nil {
+		return fmt.Errorf("response: %v", err)
+	}
+
+	route, err := routeExpr(m.Path, pathParams)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n// %s calls %s %s\n", name, m.HTTPMethod, m.Path)
+	if m.Summary != "" {
+		fmt.Fprintf(w, "// %s\n", m.Summary)
+	}
+	if m.Deprecated {
+		fmt.Fprintf(w, "//\n// Deprecated: the endpoint is deprecated in the web API\n")
+	}
+	results := "error"
+	if res != nil {
+		results = "(" + res.Name + ", error)"
+	}
+	fmt.Fprintf(w, "func (a *API) %s(%s) %s {\n", name, strings.Join(args, ", "), results)
+
+	fmt.Fprintf(w, "\troute := %s\n", route)
+	if len(pathParams) > 0 {
+		g.imports["net/url"] = true
+	}
+	if len(queryParams) > 0 {
+		g.imports["net/url"] = true
+		fmt.Fprintf(w, "\tquery := url.Values{}\n")
+		for _, p := range queryParams {
+			val := p.name
+			if !p.required {
+				val = "*" + p.name
+				fmt.Fprintf(w, "\tif %s != nil {\n", p.name)
+			}
+			if p.goType == "string" {
+				fmt.Fprintf(w, "\tquery.Set(%q, %s)\n", p.apiName, val)
+			} else {
+				g.imports["fmt"] = true
+				fmt.Fprintf(w, "\tquery.Set(%q, fmt.Sprint(%s))\n", p.apiName, val)
+			}
+			if !p.required {
+				fmt.Fprintf(w, "\t}\n")
+			}
+		}
+		fmt.Fprintf(w, "\tif len(query) > 0 {\n\troute += \"?\" + query.Encode()\n\t}\n")
+	}
+	method := "http.Method" + strings.Title(strings.ToLower(m.HTTPMethod))
+	if res == nil {
+		fmt.Fprintf(w, "\treturn a.c.do(%s, route, %s, nil)\n}\n", method, bodyArg)
+		return nil
+	}
+	if res.Value {
+		fmt.Fprintf(w, "\tvar res %s\n", res.Name)
+		fmt.Fprintf(w, "\terr := a.c.do(%s, route, %s, &res)\n", method, bodyArg)
+		fmt.Fprintf(w, "\treturn res, err\n}\n")
+		return nil
+	}
+	fmt.Fprintf(w, "\tres := &%s{}\n", strings.TrimPrefix(res.Name, "*"))
+	fmt.Fprintf(w, "\tif err := a.c.do(%s, route, %s, res); err != nil {\n", method, bodyArg)
+	fmt.Fprintf(w, "\treturn nil, err\n\t}\n\treturn res, nil\n}\n")
+	return nil
+}
+
+// responseType returns the type of the successful response of the operation, or nil if it has no content
+func (g *clientGen) responseType(op *Operation) (*GoType, error) {
+	for _, code := range []string{"200", "201"} {
+		r, ok := op.Responses[code]
+		if !ok {
+			continue
+		}
+		mt := r.Content[contentTypeJSON]
+		if mt == nil || mt.Schema == nil {
+			return nil, nil
+		}
+		t, err := g.schemaType(mt)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func (g *clientGen) schemaType(mt *MediaType) (GoType, error) {
+	if mt == nil || mt.Schema == nil {
+		return GoType{}, fmt.Errorf("JSON schema is missing")
+	}
+	return g.goType(mt.Schema)
+}
+
+func (g *clientGen) goType(s *Schema) (GoType, error) {
+	if name := s.RefName(); name != "" {
+		if t, ok := DefaultGoTypes[name]; ok {
+			g.imports[t.Import] = true
+			return t, nil
+		}
+		g.imports[modelImport] = true
+		return GoType{Name: "*model." + name}, nil
+	}
+	if s.Type == "array" {
+		if s.Items == nil {
+			return GoType{}, fmt.Errorf("array without items")
+		}
+		elem, err := g.goType(s.Items)
+		if err != nil {
+			return GoType{}, err
+		}
+		return GoType{Name: "[]" + elem.Name, Value: true}, nil
+	}
+	t, err := g.primitiveType(s)
+	if err != nil {
+		return GoType{}, err
+	}
+	return GoType{Name: t, Value: true}, nil
+}
+
+func (g *clientGen) primitiveType(s *Schema) (string, error) {
+	if s == nil {
+		return "string", nil
+	}
+	switch s.Type {
+	case "", "string":
+		return "string", nil
+	case "integer":
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	}
+	return "", fmt.Errorf("unsupported type '%s'", s.Type)
+}
+
+// routeExpr returns the Go expression which builds the route from the path template and path parameters
+func routeExpr(path string, params []goParam) (string, error) {
+	byName := make(map[string]goParam, len(params))
+	for _, p := range params {
+		byName[p.apiName] = p
+	}
+	parts := make([]string, 0)
+	rest := path
+	for {
+		i := strings.Index(rest, "{")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(rest[i:], "}")
+		if j < 0 {
+			return "", fmt.Errorf("malformed path")
+		}
+		p, ok := byName[rest[i+1:i+j]]
+		if !ok {
+			return "", fmt.Errorf("path parameter '%s' is not declared", rest[i+1:i+j])
+		}
+		if i > 0 {
+			parts = append(parts, fmt.Sprintf("%q", rest[:i]))
+		}
+		parts = append(parts, "url.PathEscape("+p.name+")")
+		rest = rest[i+j+1:]
+	}
+	if rest != "" || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%q", rest))
+	}
+	return strings.Join(parts, " + "), nil
+}
+
+func exportedName(id string) string {
+	r := []rune(goIdent(id))
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func paramName(name string, used map[string]bool) string {
+	r := []rune(goIdent(name))
+	r[0] = unicode.ToLower(r[0])
+	ret := string(r)
+	for used[ret] {
+		ret += "Param"
+	}
+	used[ret] = true
+	return ret
+}
+
+// goIdent removes from the name characters which are not allowed in Go identifiers, capitalizing the next one
+func goIdent(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, c := range name {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			c = unicode.ToUpper(c)
+			upper = false
+		}
+		b.WriteRune(c)
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "p" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,154 @@
+// Package openapi generates the OpenAPI v3 specification of the Wasp web API and the typed Go client from it.
+//
+// The endpoints of the web API are documented with echoswagger, which produces the Swagger 2.0 specification.
+// The OpenAPI v3 specification is converted from it, so both always describe the same set of endpoints.
+// The node serves it at /doc/openapi.json. The typed client in the 'client' package is generated from it
+// with tools/api-gen
+package openapi
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Version of the OpenAPI specification produced by the package
+const Version = "3.0.3"
+
+// Document is the root object of the OpenAPI v3 specification.
+// Only the subset of the specification used by the Wasp web API is supported
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// PathItem contains operations of the path, by HTTP method
+type PathItem struct {
+	Get    *Operation `json:"get,omitempty"`
+	Put    *Operation `json:"put,omitempty"`
+	Post   *Operation `json:"post,omitempty"`
+	Delete *Operation `json:"delete,omitempty"`
+	Patch  *Operation `json:"patch,omitempty"`
+}
+
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter of the operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Description string                `json:"description,omitempty"`
+	Required    bool                  `json:"required,omitempty"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Schema is the JSON schema of the parameter, request body or response.
+// Swagger 2.0 uses the same form of schemas, except references to definitions
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+}
+
+// Method is the operation of the document together with its path and HTTP method
+type Method struct {
+	Path       string
+	HTTPMethod string
+	*Operation
+}
+
+// Methods returns all operations of the document, sorted by path and HTTP method
+func (d *Document) Methods() []Method {
+	ret := make([]Method, 0)
+	for path, item := range d.Paths {
+		for _, m := range []struct {
+			method string
+			op     *Operation
+		}{
+			{"GET", item.Get},
+			{"PUT", item.Put},
+			{"POST", item.Post},
+			{"DELETE", item.Delete},
+			{"PATCH", item.Patch},
+		} {
+			if m.op != nil {
+				ret = append(ret, Method{Path: path, HTTPMethod: m.method, Operation: m.op})
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Path != ret[j].Path {
+			return ret[i].Path < ret[j].Path
+		}
+		return ret[i].HTTPMethod < ret[j].HTTPMethod
+	})
+	return ret
+}
+
+// JSON returns the document in JSON format
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// FromJSON parses the OpenAPI v3 document
+func FromJSON(data []byte) (*Document, error) {
+	ret := &Document{}
+	if err := json.Unmarshal(data, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
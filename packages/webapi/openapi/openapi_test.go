@@ -0,0 +1,170 @@
+package openapi
+
+import (
+	"go/parser"
+	"go/token"
+	"net/http"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+	"github.com/stretchr/testify/require"
+)
+
+const swaggerSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "Wasp API", "version": "0.1"},
+  "paths": {
+    "/blob/get/{hash}": {
+      "get": {
+        "operationId": "getBlob",
+        "summary": "Fetch a blob by its hash",
+        "parameters": [{"name": "hash", "in": "path", "type": "string"}],
+        "responses": {
+          "200": {"description": "Blob data", "schema": {"$ref": "#/definitions/BlobData"}},
+          "404": {"description": "Not found", "schema": {"$ref": "#/definitions/HTTPError"}}
+        }
+      }
+    },
+    "/adm/chain/{chainID}/blocks": {
+      "get": {
+        "operationId": "exportBlocks",
+        "parameters": [
+          {"name": "chainID", "in": "path", "required": true, "type": "string"},
+          {"name": "from", "in": "query", "type": "integer", "format": "int32"}
+        ],
+        "responses": {"200": {"description": "Blocks", "schema": {"$ref": "#/definitions/ChainBlocks"}}}
+      }
+    },
+    "/adm/chainrecord": {
+      "post": {
+        "operationId": "putChainRecord",
+        "parameters": [{"name": "ChainRecord", "in": "body", "required": true, "schema": {"$ref": "#/definitions/ChainRecord"}}],
+        "responses": {}
+      }
+    },
+    "/adm/chainrecords": {
+      "get": {
+        "operationId": "listChainRecords",
+        "responses": {"200": {"description": "Chain records", "schema": {"type": "array", "items": {"$ref": "#/definitions/ChainRecord"}}}}
+      }
+    },
+    "/contract/{contractID}/callview/{fname}": {
+      "get": {
+        "operationId": "callView",
+        "parameters": [
+          {"name": "contractID", "in": "path", "type": "string"},
+          {"name": "fname", "in": "path", "type": "string"},
+          {"name": "params", "in": "body", "schema": {"$ref": "#/definitions/JSONDict"}}
+        ],
+        "responses": {"200": {"description": "Result", "schema": {"$ref": "#/definitions/JSONDict"}}}
+      }
+    }
+  },
+  "definitions": {
+    "BlobData": {"type": "object", "properties": {"Data": {"type": "string"}}},
+    "ChainRecord": {"type": "object", "properties": {"Committee": {"type": "array", "items": {"$ref": "#/definitions/PeerAddress"}}}}
+  }
+}`
+
+func TestFromSwagger(t *testing.T) {
+	doc, err := FromSwagger([]byte(swaggerSpec))
+	require.NoError(t, err)
+	require.Equal(t, Version, doc.OpenAPI)
+	require.Equal(t, "Wasp API", doc.Info.Title)
+	require.Len(t, doc.Methods(), 5)
+
+	op := doc.Paths["/blob/get/{hash}"].Get
+	require.NotNil(t, op)
+	require.Equal(t, "getBlob", op.OperationID)
+	require.Len(t, op.Parameters, 1)
+	require.True(t, op.Parameters[0].Required)
+	require.EqualValues(t, "string", op.Parameters[0].Schema.Type)
+	require.EqualValues(t, "#/components/schemas/BlobData", op.Responses["200"].Content[contentTypeJSON].Schema.Ref)
+	require.EqualValues(t, "HTTPError", op.Responses["404"].Content[contentTypeJSON].Schema.RefName())
+
+	op = doc.Paths["/adm/chainrecord"].Post
+	require.NotNil(t, op)
+	require.Empty(t, op.Parameters)
+	require.NotNil(t, op.RequestBody)
+	require.True(t, op.RequestBody.Required)
+	require.EqualValues(t, "ChainRecord", op.RequestBody.Content[contentTypeJSON].Schema.RefName())
+	require.Contains(t, op.Responses, "200")
+
+	items := doc.Components.Schemas["ChainRecord"].Properties["Committee"].Items
+	require.EqualValues(t, "PeerAddress", items.RefName())
+
+	data, err := doc.JSON()
+	require.NoError(t, err)
+	back, err := FromJSON(data)
+	require.NoError(t, err)
+	backData, err := back.JSON()
+	require.NoError(t, err)
+	require.Equal(t, string(data), string(backData))
+}
+
+func TestFromSwaggerWrongVersion(t *testing.T) {
+	_, err := FromSwagger([]byte(`{"swagger": "1.2", "paths": {}}`))
+	require.Error(t, err)
+}
+
+func TestGenerateClient(t *testing.T) {
+	doc, err := FromSwagger([]byte(swaggerSpec))
+	require.NoError(t, err)
+
+	src, err := GenerateClient(doc, "client")
+	require.NoError(t, err)
+	_, err = parser.ParseFile(token.NewFileSet(), "api_gen.go", src, 0)
+	require.NoError(t, err)
+
+	code := string(src)
+	require.Contains(t, code, "func (a *API) GetBlob(hash string) (*model.BlobData, error)")
+	require.Contains(t, code, `route := "/blob/get/" + url.PathEscape(hash)`)
+	require.Contains(t, code, "func (a *API) ExportBlocks(chainID string, from *int64) (*model.ChainBlocks, error)")
+	require.Contains(t, code, `query.Set("from", fmt.Sprint(*from))`)
+	require.Contains(t, code, "func (a *API) PutChainRecord(body *model.ChainRecord) error")
+	require.Contains(t, code, "func (a *API) ListChainRecords() ([]*model.ChainRecord, error)")
+	require.Contains(t, code, "func (a *API) CallView(contractID string, fname string, body dict.Dict) (dict.Dict, error)")
+	require.Contains(t, code, `"github.com/iotaledger/wasp/packages/kv/dict"`)
+}
+
+func TestGenerateClientNoOperationID(t *testing.T) {
+	doc, err := FromSwagger([]byte(swaggerSpec))
+	require.NoError(t, err)
+	doc.Paths["/blob/get/{hash}"].Get.OperationID = ""
+
+	_, err = GenerateClient(doc, "client")
+	require.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	server := echoswagger.New(echo.New(), "/doc", &echoswagger.Info{Title: "Wasp API", Version: "0.1"})
+	handler := func(c echo.Context) error { return nil }
+	server.GET("/blob/has/:hash", handler).
+		SetOperationId("hasBlob").
+		AddParamPath("", "hash", "Blob hash").
+		AddResponse(http.StatusOK, "Blob properties", model.BlobInfo{}, nil)
+	server.GET("/blob/put", handler).
+		SetOperationId("putBlob").
+		AddParamBody(model.BlobData{}, "Blob", "Blob data", true).
+		AddResponse(http.StatusOK, "Blob properties", model.BlobInfo{}, nil)
+
+	doc, err := Generate(server.Echo(), "/doc/swagger.json", nil)
+	require.NoError(t, err)
+	require.Len(t, doc.Methods(), 2)
+
+	op := doc.Paths["/blob/has/{hash}"].Get
+	require.NotNil(t, op)
+	require.Equal(t, "hasBlob", op.OperationID)
+	require.EqualValues(t, "BlobInfo", op.Responses["200"].Content[contentTypeJSON].Schema.RefName())
+	require.Contains(t, doc.Components.Schemas, "BlobInfo")
+
+	op = doc.Paths["/blob/put"].Get
+	require.NotNil(t, op)
+	require.NotNil(t, op.RequestBody)
+	require.EqualValues(t, "BlobData", op.RequestBody.Content[contentTypeJSON].Schema.RefName())
+
+	_, err = GenerateClient(doc, "client")
+	require.NoError(t, err)
+}
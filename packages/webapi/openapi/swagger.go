@@ -0,0 +1,208 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	swaggerDefinitionsPrefix = "#/definitions/"
+	schemasPrefix            = "#/components/schemas/"
+	contentTypeJSON          = "application/json"
+)
+
+// swagger is the subset of the Swagger 2.0 specification produced by echoswagger
+type swagger struct {
+	Swagger     string                                  `json:"swagger"`
+	Info        Info                                    `json:"info"`
+	BasePath    string                                  `json:"basePath"`
+	Tags        []Tag                                   `json:"tags"`
+	Paths       map[string]map[string]*swaggerOperation `json:"paths"`
+	Definitions map[string]*Schema                      `json:"definitions"`
+}
+
+type swaggerOperation struct {
+	Tags        []string                    `json:"tags"`
+	Summary     string                      `json:"summary"`
+	Description string                      `json:"description"`
+	OperationID string                      `json:"operationId"`
+	Deprecated  bool                        `json:"deprecated"`
+	Parameters  []*swaggerParameter         `json:"parameters"`
+	Responses   map[string]*swaggerResponse `json:"responses"`
+}
+
+type swaggerParameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description"`
+	Required    bool          `json:"required"`
+	Schema      *Schema       `json:"schema"`
+	Type        string        `json:"type"`
+	Format      string        `json:"format"`
+	Items       *Schema       `json:"items"`
+	Enum        []interface{} `json:"enum"`
+	Default     interface{}   `json:"default"`
+}
+
+type swaggerResponse struct {
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+// FromSwagger converts the Swagger 2.0 specification in JSON format to the OpenAPI v3 document
+func FromSwagger(data []byte) (*Document, error) {
+	var sw swagger
+	if err := json.Unmarshal(data, &sw); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(sw.Swagger, "2.") {
+		return nil, fmt.Errorf("unsupported swagger version '%s'", sw.Swagger)
+	}
+	ret := &Document{
+		OpenAPI: Version,
+		Info:    sw.Info,
+		Tags:    sw.Tags,
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+	}
+	if sw.BasePath != "" && sw.BasePath != "/" {
+		ret.Servers = []Server{{URL: sw.BasePath}}
+	}
+	for name, def := range sw.Definitions {
+		ret.Components.Schemas[name] = convertSchema(def)
+	}
+	for path, methods := range sw.Paths {
+		item := &PathItem{}
+		for method, swop := range methods {
+			op, err := convertOperation(swop)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %v", strings.ToUpper(method), path, err)
+			}
+			switch strings.ToUpper(method) {
+			case http.MethodGet:
+				item.Get = op
+			case http.MethodPut:
+				item.Put = op
+			case http.MethodPost:
+				item.Post = op
+			case http.MethodDelete:
+				item.Delete = op
+			case http.MethodPatch:
+				item.Patch = op
+			default:
+				return nil, fmt.Errorf("%s %s: unsupported method", strings.ToUpper(method), path)
+			}
+		}
+		ret.Paths[path] = item
+	}
+	return ret, nil
+}
+
+// Generate returns the OpenAPI v3 specification of the endpoints registered in the echo server with echoswagger.
+// swaggerPath is the path where echoswagger serves the Swagger 2.0 specification. The header is sent with
+// the request of the Swagger specification, for example to pass the authentication of the server
+func Generate(e *echo.Echo, swaggerPath string, header http.Header) (*Document, error) {
+	req := httptest.NewRequest(http.MethodGet, swaggerPath, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return nil, fmt.Errorf("can't get swagger specification from %s: status %d", swaggerPath, rec.Code)
+	}
+	return FromSwagger(rec.Body.Bytes())
+}
+
+func convertOperation(swop *swaggerOperation) (*Operation, error) {
+	op := &Operation{
+		Tags:        swop.Tags,
+		Summary:     swop.Summary,
+		Description: swop.Description,
+		OperationID: swop.OperationID,
+		Deprecated:  swop.Deprecated,
+		Parameters:  make([]*Parameter, 0, len(swop.Parameters)),
+		Responses:   make(map[string]*Response),
+	}
+	for _, p := range swop.Parameters {
+		switch p.In {
+		case "body":
+			if op.RequestBody != nil {
+				return nil, fmt.Errorf("more than one body parameter")
+			}
+			op.RequestBody = &RequestBody{
+				Description: p.Description,
+				Required:    p.Required,
+				Content: map[string]*MediaType{
+					contentTypeJSON: {Schema: convertSchema(p.Schema)},
+				},
+			}
+		case "path", "query", "header":
+			op.Parameters = append(op.Parameters, &Parameter{
+				Name:        p.Name,
+				In:          p.In,
+				Description: p.Description,
+				// path parameters are always required
+				Required: p.Required || p.In == "path",
+				Schema: &Schema{
+					Type:    p.Type,
+					Format:  p.Format,
+					Items:   convertSchema(p.Items),
+					Enum:    p.Enum,
+					Default: p.Default,
+				},
+			})
+		default:
+			return nil, fmt.Errorf("unsupported parameter '%s' in %s", p.Name, p.In)
+		}
+	}
+	for code, r := range swop.Responses {
+		resp := &Response{Description: r.Description}
+		if r.Schema != nil {
+			resp.Content = map[string]*MediaType{
+				contentTypeJSON: {Schema: convertSchema(r.Schema)},
+			}
+		}
+		op.Responses[code] = resp
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["200"] = &Response{Description: "OK"}
+	}
+	return op, nil
+}
+
+// convertSchema returns the copy of the schema with references to definitions replaced by references to
+// schemas in components
+func convertSchema(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+	ret := *s
+	if strings.HasPrefix(s.Ref, swaggerDefinitionsPrefix) {
+		ret.Ref = schemasPrefix + strings.TrimPrefix(s.Ref, swaggerDefinitionsPrefix)
+	}
+	ret.Items = convertSchema(s.Items)
+	ret.AdditionalProperties = convertSchema(s.AdditionalProperties)
+	if s.Properties != nil {
+		ret.Properties = make(map[string]*Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			ret.Properties[name] = convertSchema(prop)
+		}
+	}
+	return &ret
+}
+
+// RefName returns the name of the schema in components referenced by the schema, or "" if it isn't a reference
+func (s *Schema) RefName() string {
+	if s == nil || !strings.HasPrefix(s.Ref, schemasPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(s.Ref, schemasPrefix)
+}
@@ -0,0 +1,27 @@
+package webapi
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/webapi/openapi"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGeneratedClientUpToDate checks that client/api_gen.go is generated from the current web API.
+// If it fails, regenerate the client with tools/api-gen (go generate ./client) against a node running the new API
+func TestGeneratedClientUpToDate(t *testing.T) {
+	server := echoswagger.New(echo.New(), "/doc", &echoswagger.Info{Title: "Wasp API", Version: "0.1"})
+	addEndpoints(server, nil)
+
+	doc, err := openapi.Generate(server.Echo(), "/doc/swagger.json", nil)
+	require.NoError(t, err)
+	src, err := openapi.GenerateClient(doc, "client")
+	require.NoError(t, err)
+
+	checkedIn, err := ioutil.ReadFile("../../client/api_gen.go")
+	require.NoError(t, err)
+	require.Equal(t, string(checkedIn), string(src), "client/api_gen.go is out of date, regenerate it with tools/api-gen")
+}
@@ -0,0 +1,112 @@
+package request
+
+// Endpoint for the history of requests sent to the chain by an account, read from the index of receipts in the event log.
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/subrealm"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+// DefaultAccountRequestsLimit is the number of receipts returned by the history endpoint if the limit is not specified
+const DefaultAccountRequestsLimit = 20
+
+func addAccountRequestsEndpoint(server echoswagger.ApiRouter) {
+	next := uint32(17)
+	example := model.AccountRequests{
+		AgentID: "a1qq3vgfn0mhnlamr2xws2ta8rsr3jt4yfngr9ep89wcfkz4pvfzkaq3x3rt2",
+		Total:   42,
+		Receipts: []*model.RequestReceipt{{
+			RequestID:    "8GQVTqrvSeXq5rVjeKjZYu4H8X1eCyUEbN6rQJkr2e9BHvE",
+			Timestamp:    1611919553000000000,
+			BlockIndex:   12,
+			RequestIndex: 0,
+			Contract:     "3c4b5e02",
+			EntryPoint:   "bdc9c9cc",
+		}},
+		Next: &next,
+	}
+
+	server.GET(routes.AccountRequests(":chainID", ":agentID"), handleAccountRequests).
+		SetOperationId("getAccountRequests").
+		SetSummary("Get receipts of requests sent to the chain by the account, the latest first").
+		SetDescription(fmt.Sprintf("Only the latest %d requests of each account are kept. "+
+			"At most %d receipts are returned at once", eventlog.MaxReceiptsPerSender, eventlog.MaxReceiptsPerCall)).
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamPath("", "agentID", "Agent ID of the account (bech32)").
+		AddParamQuery(uint32(0), "before", "The receipt before the index is returned first (default: the latest receipt)", false).
+		AddParamQuery(uint32(0), "limit", fmt.Sprintf("Maximum number of receipts (default: %d)", DefaultAccountRequestsLimit), false).
+		AddResponse(http.StatusOK, "Receipts of the requests", example, nil)
+}
+
+func handleAccountRequests(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+	agentID, err := coretypes.NewAgentIDFromString(c.Param("agentID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid agent ID %+v: %s", c.Param("agentID"), err.Error()))
+	}
+	virtualState, _, ok, err := state.LoadSolidState(&chainID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httperrors.NotFound(fmt.Sprintf("State not found for chain %s", chainID.String()))
+	}
+	logState := subrealm.New(virtualState.Variables(), kv.Key(eventlog.Interface.Hname().Bytes()))
+	total := eventlog.NumReceipts(logState, agentID)
+
+	before, err := queryUint32(c, "before", total)
+	if err != nil {
+		return err
+	}
+	limit, err := queryUint32(c, "limit", DefaultAccountRequestsLimit)
+	if err != nil {
+		return err
+	}
+	if limit == 0 {
+		return httperrors.BadRequest("'limit' must be positive")
+	}
+	if limit > eventlog.MaxReceiptsPerCall {
+		limit = eventlog.MaxReceiptsPerCall
+	}
+
+	ret := model.NewAccountRequests(agentID, total)
+	records, next, more := eventlog.GetReceipts(logState, agentID, before, limit)
+	for _, raw := range records {
+		ts, receipt, err := eventlog.ParseReceiptRecord(raw)
+		if err != nil {
+			return err
+		}
+		ret.Receipts = append(ret.Receipts, model.NewRequestReceipt(ts, receipt))
+	}
+	if more {
+		ret.Next = &next
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func queryUint32(c echo.Context, name string, def uint32) (uint32, error) {
+	s := c.QueryParam(name)
+	if s == "" {
+		return def, nil
+	}
+	ret, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, httperrors.BadRequest(fmt.Sprintf("invalid '%s': %v", name, err))
+	}
+	return uint32(ret), nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/wasp/packages/chain"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
@@ -16,18 +17,29 @@ import (
 	"github.com/pangpanglabs/echoswagger/v2"
 )
 
+var log *logger.Logger
+
+func InitLogger() {
+	log = logger.NewLogger("webapi/request")
+}
+
 func AddEndpoints(server echoswagger.ApiRouter) {
 	server.GET(routes.RequestStatus(":chainID", ":reqID"), handleRequestStatus).
+		SetOperationId("getRequestStatus").
 		SetSummary("Get the processing status of a given request in the node").
 		AddParamPath("", "chainID", "ChainID (base58)").
 		AddParamPath("", "reqID", "Request ID (base58)").
 		AddResponse(http.StatusOK, "Request status", model.RequestStatusResponse{}, nil)
 
 	server.GET(routes.WaitRequestProcessed(":chainID", ":reqID"), handleWaitRequestProcessed).
+		SetOperationId("waitRequestProcessed").
 		SetSummary("Wait until the given request has been processed by the node").
 		AddParamPath("", "chainID", "ChainID (base58)").
 		AddParamPath("", "reqID", "Request ID (base58)").
 		AddParamBody(model.WaitRequestProcessedParams{}, "Params", "Optional parameters", false)
+
+	addSimulateEndpoint(server)
+	addAccountRequestsEndpoint(server)
 }
 
 func handleRequestStatus(c echo.Context) error {
@@ -90,7 +102,7 @@ func handleWaitRequestProcessed(c echo.Context) error {
 }
 
 func parseParams(c echo.Context) (chain.Chain, *coretypes.RequestID, error) {
-	chainID, err := coretypes.NewChainIDFromBase58(c.Param("chainID"))
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
 	if err != nil {
 		return nil, nil, httperrors.BadRequest(fmt.Sprintf("Invalid chain ID %+v: %s", c.Param("chainID"), err.Error()))
 	}
@@ -0,0 +1,93 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/state"
+	"github.com/iotaledger/wasp/packages/vm/simulate"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addSimulateEndpoint(server echoswagger.ApiRouter) {
+	server.POST(routes.SimulateRequest(":chainID"), handleSimulateRequest).
+		SetOperationId("simulateRequest").
+		SetSummary("Run the request against the current state of the chain without committing it").
+		SetDescription("The transaction is neither posted nor checked for the signature. "+
+			"On-chain assets stand for the outputs of the chain address, fees don't depend on the work done by the request").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddParamBody(model.SimulateRequest{}, "Request", "Request to simulate", true).
+		AddResponse(http.StatusOK, "Outcome of the request", model.SimulationResult{}, nil)
+}
+
+func handleSimulateRequest(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID %+v: %s", c.Param("chainID"), err.Error()))
+	}
+	ch := chains.GetChain(chainID)
+	if ch == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %+v", chainID.String()))
+	}
+	var req model.SimulateRequest
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest("Invalid request body")
+	}
+	vtx, _, err := valuetransaction.FromBytes(req.Transaction.Bytes())
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid transaction: %s", err.Error()))
+	}
+	tx, err := sctransaction.ParseValueTransaction(vtx)
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid smart contract transaction: %s", err.Error()))
+	}
+	if int(req.Index) >= len(tx.Requests()) {
+		return httperrors.BadRequest(fmt.Sprintf("Request index %d out of range", req.Index))
+	}
+	reqSection := tx.Requests()[req.Index]
+	if reqSection.Target().ChainID() != chainID {
+		return httperrors.BadRequest(fmt.Sprintf("The request is not sent to chain %s", chainID.String()))
+	}
+	ok, err := reqSection.SolidifyArgs(ch.BlobCache())
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid arguments of the request: %s", err.Error()))
+	}
+	if !ok {
+		return httperrors.BadRequest("Blobs referenced by arguments of the request are not in the registry")
+	}
+
+	virtualState, _, ok, err := state.LoadSolidState(&chainID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httperrors.NotFound(fmt.Sprintf("State not found for chain %s", chainID.String()))
+	}
+	// the request can't be processed earlier than in the next block
+	ts := time.Now().UnixNano()
+	if ts <= virtualState.Timestamp() {
+		ts = virtualState.Timestamp() + 1
+	}
+	res, err := simulate.Run(&simulate.Params{
+		ChainID:    chainID,
+		ChainColor: *ch.Color(),
+		Processors: ch.Processors(),
+		State:      virtualState,
+		Request:    sctransaction.RequestRef{Tx: tx, Index: req.Index},
+		Timestamp:  ts,
+		Log:        log,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewSimulationResult(res))
+}
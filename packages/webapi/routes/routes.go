@@ -4,6 +4,14 @@ func Info() string {
 	return "/info"
 }
 
+func Healthz() string {
+	return "/healthz"
+}
+
+func Readyz() string {
+	return "/readyz"
+}
+
 func CallView(contractID string, hname string) string {
 	return "/contract/" + contractID + "/callview/" + hname
 }
@@ -16,6 +24,14 @@ func WaitRequestProcessed(chainID string, reqID string) string {
 	return "/chain/" + chainID + "/request/" + reqID + "/wait"
 }
 
+func SimulateRequest(chainID string) string {
+	return "/chain/" + chainID + "/request/simulate"
+}
+
+func AccountRequests(chainID string, agentID string) string {
+	return "/chain/" + chainID + "/account/" + agentID + "/requests"
+}
+
 func StateQuery(chainID string) string {
 	return "/chain/" + chainID + "/state/query"
 }
@@ -32,6 +48,14 @@ func HasBlob(hash string) string {
 	return "/blob/has/" + hash
 }
 
+func PutChainBlob(chainID string) string {
+	return "/chain/" + chainID + "/blob/put"
+}
+
+func ChainBlobUsage(chainID string) string {
+	return "/chain/" + chainID + "/blob/usage"
+}
+
 func ActivateChain(chainID string) string {
 	return "/adm/chain/" + chainID + "/activate"
 }
@@ -40,6 +64,10 @@ func DeactivateChain(chainID string) string {
 	return "/adm/chain/" + chainID + "/deactivate"
 }
 
+func DrainChain(chainID string) string {
+	return "/adm/chain/" + chainID + "/drain"
+}
+
 func ListChainRecords() string {
 	return "/adm/chainrecords"
 }
@@ -60,6 +88,10 @@ func DKSharesGet(sharedAddress string) string {
 	return "/adm/dks/" + sharedAddress
 }
 
+func DKSharesReshare(sharedAddress string) string {
+	return "/adm/dks/" + sharedAddress + "/reshare"
+}
+
 func DumpState(contractID string) string {
 	return "/adm/contract/" + contractID + "/dumpstate"
 }
@@ -67,3 +99,63 @@ func DumpState(contractID string) string {
 func Shutdown() string {
 	return "/adm/shutdown"
 }
+
+func ExportRegistry() string {
+	return "/adm/registry/export"
+}
+
+func ImportRegistry() string {
+	return "/adm/registry/import"
+}
+
+func EquivocationEvidence(chainID string) string {
+	return "/adm/chain/" + chainID + "/evidence"
+}
+
+func DeclareEmergency(chainID string) string {
+	return "/adm/chain/" + chainID + "/emergency"
+}
+
+func EmergencyRecords(chainID string) string {
+	return "/adm/chain/" + chainID + "/emergency/records"
+}
+
+func VerifyStateCheckpoint(chainID string) string {
+	return "/adm/chain/" + chainID + "/checkpoint/verify"
+}
+
+func ExportBlocks(chainID string) string {
+	return "/adm/chain/" + chainID + "/blocks"
+}
+
+func ChainMetadata(chainID string) string {
+	return "/chain/" + chainID + "/metadata"
+}
+
+func ChainEvents(chainID string) string {
+	return "/chain/" + chainID + "/events/ws"
+}
+
+func ChainEventsHistory(chainID string) string {
+	return "/chain/" + chainID + "/events/history"
+}
+
+func CommitteePeers(chainID string) string {
+	return "/adm/chain/" + chainID + "/peers"
+}
+
+func NodeResources() string {
+	return "/adm/node/resources"
+}
+
+func BlockedPeers() string {
+	return "/adm/peering/blocked"
+}
+
+func RotateIdentity() string {
+	return "/adm/peering/identity/rotate"
+}
+
+func IdentityHandovers() string {
+	return "/adm/peering/identity/handovers"
+}
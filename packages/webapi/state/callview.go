@@ -2,6 +2,7 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -22,11 +23,14 @@ func AddEndpoints(server echoswagger.ApiRouter) {
 	}.JSONDict()
 
 	server.GET(routes.CallView(":contractID", ":fname"), handleCallView).
+		SetOperationId("callView").
 		SetSummary("Call a view function on a contract").
 		AddParamPath("", "contractID", "ContractID (base58-encoded)").
 		AddParamPath("getInfo", "fname", "Function name").
 		AddParamBody(dictExample, "params", "Parameters", false).
 		AddResponse(http.StatusOK, "Result", dictExample, nil)
+
+	addChainMetadataEndpoint(server)
 }
 
 func handleCallView(c echo.Context) error {
@@ -56,6 +60,9 @@ func handleCallView(c echo.Context) error {
 	}
 
 	ret, err := vctx.CallView(contractID.Hname(), coretypes.Hn(fname), params)
+	if errors.Is(err, viewcontext.ErrTimeout) {
+		return httperrors.Timeout(fmt.Sprintf("View call failed: %v", err))
+	}
 	if err != nil {
 		return httperrors.BadRequest(fmt.Sprintf("View call failed: %v", err))
 	}
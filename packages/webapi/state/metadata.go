@@ -0,0 +1,54 @@
+package state
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/iotaledger/wasp/plugins/chains"
+	"github.com/labstack/echo/v4"
+	"github.com/pangpanglabs/echoswagger/v2"
+)
+
+func addChainMetadataEndpoint(server echoswagger.ApiRouter) {
+	example := model.ChainMetadata{
+		Description: "My chain",
+		Website:     "https://example.com",
+		Values:      map[string]string{"logo": "https://example.com/logo.png"},
+	}
+
+	server.GET(routes.ChainMetadata(":chainID"), handleGetChainMetadata).
+		SetOperationId("getChainMetadata").
+		SetSummary("Get the description, the website and the custom metadata of the chain").
+		AddParamPath("", "chainID", "ChainID (base58)").
+		AddResponse(http.StatusOK, "Chain metadata", example, nil)
+}
+
+func handleGetChainMetadata(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+	chain := chains.GetChain(chainID)
+	if chain == nil {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %s", chainID))
+	}
+	vctx, err := viewcontext.NewFromDB(*chain.ID(), chain.Processors())
+	if err != nil {
+		return fmt.Errorf("failed to create context: %v", err)
+	}
+	ret, err := vctx.CallView(root.Interface.Hname(), coretypes.Hn(root.FuncGetChainMetadata), nil)
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("View call failed: %v", err))
+	}
+	meta, err := root.GetChainMetadata(ret)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewChainMetadata(&chainID, meta))
+}
@@ -5,6 +5,8 @@ import (
 	"sync"
 
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
 	"github.com/iotaledger/wasp/packages/coretypes"
 
 	"github.com/iotaledger/hive.go/daemon"
@@ -87,15 +89,25 @@ func ActivateChain(chr *registry_pkg.ChainRecord) error {
 		return fmt.Errorf("cannot activate chain for deactivated chain record")
 	}
 
-	_, ok := chains[chr.ChainID]
-	if ok {
-		log.Debugf("chain is already active: %s", chr.ChainID.String())
-		return nil
+	if c, ok := chains[chr.ChainID]; ok {
+		if !c.IsDismissed() {
+			log.Debugf("chain is already active: %s", chr.ChainID.String())
+			return nil
+		}
+		// the chain was deactivated or drained: replace it with the new chain object
+		delete(chains, chr.ChainID)
+		nodeconn.Unsubscribe((address.Address)(chr.ChainID))
 	}
 	// create new chain object
 	defaultRegistry := registry.DefaultRegistry()
+	chainID := chr.ChainID
 	c := chain.New(chr, log, peering.DefaultNetworkProvider(), defaultRegistry, defaultRegistry, func() {
-		nodeconn.Subscribe((address.Address)(chr.ChainID), chr.Color)
+		nodeconn.Subscribe((address.Address)(chainID), chr.Color)
+		nodeconn.AttachBalancesConsumer((address.Address)(chainID), PluginName, func(bals map[valuetransaction.ID][]*balance.Balance) {
+			if c := GetChain(chainID); c != nil {
+				c.ReceiveMessage(chain.BalancesMsg{Balances: bals})
+			}
+		})
 	})
 	if c != nil {
 		chains[chr.ChainID] = c
@@ -121,6 +133,24 @@ func DeactivateChain(chr *registry_pkg.ChainRecord) error {
 	return nil
 }
 
+// DrainChain deactivates chain in the node gracefully: the chain stops accepting new requests
+// and is dismissed when the round in progress is finished.
+// The returned channel is closed when the chain is dismissed
+func DrainChain(chr *registry_pkg.ChainRecord) (<-chan struct{}, error) {
+	chainsMutex.RLock()
+	defer chainsMutex.RUnlock()
+
+	c, ok := chains[chr.ChainID]
+	if !ok || c.IsDismissed() {
+		log.Debugf("chain is not active: %s", chr.ChainID.String())
+		ret := make(chan struct{})
+		close(ret)
+		return ret, nil
+	}
+	log.Debugf("draining chain: %s", chr.ChainID.String())
+	return c.Drain(), nil
+}
+
 // GetChain returns active chain object or nil if it doesn't exist
 func GetChain(chainID coretypes.ChainID) chain.Chain {
 	chainsMutex.RLock()
@@ -134,3 +164,17 @@ func GetChain(chainID coretypes.ChainID) chain.Chain {
 	}
 	return ret
 }
+
+// GetChains returns all active chain objects
+func GetChains() []chain.Chain {
+	chainsMutex.RLock()
+	defer chainsMutex.RUnlock()
+
+	ret := make([]chain.Chain, 0, len(chains))
+	for _, c := range chains {
+		if !c.IsDismissed() {
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
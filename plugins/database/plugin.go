@@ -1,4 +1,4 @@
-// Package database is a plugin that manages the badger database (e.g. garbage collection).
+// Package database is a plugin that manages the database (e.g. garbage collection).
 package database
 
 import (
@@ -67,9 +67,15 @@ func createInstance() {
 	if parameters.GetBool(parameters.DatabaseInMemory) {
 		log.Infof("IN MEMORY DATABASE")
 		dbProvider = dbprovider.NewInMemoryDBProvider(log)
-	} else {
-		dbDir := parameters.GetString(parameters.DatabaseDir)
-		dbProvider = dbprovider.NewPersistentDBProvider(dbDir, log)
+		return
+	}
+	backend := parameters.GetString(parameters.DatabaseBackend)
+	dbDir := parameters.GetString(parameters.DatabaseDir)
+	log.Infof("database backend: %s, directory: %s", backend, dbDir)
+	var err error
+	dbProvider, err = dbprovider.NewDBProvider(backend, dbDir, log)
+	if err != nil {
+		log.Fatal(err)
 	}
 }
 
@@ -0,0 +1,62 @@
+// Package export is the plugin which streams processed requests and events of contracts
+// to the external sinks configured in the node configuration
+package export
+
+import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
+	"github.com/iotaledger/hive.go/events"
+	"github.com/iotaledger/hive.go/logger"
+	"github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/wasp/packages/export"
+	"github.com/iotaledger/wasp/packages/parameters"
+	"github.com/iotaledger/wasp/packages/publisher"
+)
+
+// PluginName is the name of the Export plugin.
+const PluginName = "Export"
+
+var (
+	log      *logger.Logger
+	exporter *export.Exporter
+)
+
+func Init() *node.Plugin {
+	return node.NewPlugin(PluginName, node.Enabled, configure, run)
+}
+
+func configure(_ *node.Plugin) {
+	log = logger.NewLogger(PluginName)
+
+	sinks := parameters.GetStringSlice(parameters.ExportSinks)
+	if len(sinks) == 0 {
+		return
+	}
+	var err error
+	exporter, err = export.NewExporter(
+		sinks,
+		parameters.GetInt(parameters.ExportBufferSize),
+		parameters.GetInt(parameters.ExportBatchSize),
+		time.Duration(parameters.GetInt(parameters.ExportFlushInterval))*time.Millisecond,
+		log,
+	)
+	if err != nil {
+		log.Panicf("%v: %v", PluginName, err)
+	}
+}
+
+func run(_ *node.Plugin) {
+	if exporter == nil {
+		log.Infof("export is disabled, no sinks configured")
+		return
+	}
+	err := daemon.BackgroundWorker(PluginName, func(shutdownSignal <-chan struct{}) {
+		exporter.Run(shutdownSignal)
+	})
+	if err != nil {
+		panic(err)
+	}
+	publisher.Event.Attach(events.NewClosure(exporter.PushPublished))
+	log.Infof("exporting requests and events to %d sink(s)", len(parameters.GetStringSlice(parameters.ExportSinks)))
+}
@@ -38,6 +38,11 @@ func msgDataToEvent(data []byte) {
 		roundtrip := time.Since(time.Unix(0, msgt.Timestamp))
 		log.Infof("PING %d response from node. Roundtrip %v", msgt.Id, roundtrip)
 
+	case *waspconn.WaspFromNodeAddressOutputsMsg:
+		if !balances.deliver(msgt.Address, msgt.Balances) {
+			EventMessageReceived.Trigger(msgt)
+		}
+
 	default:
 		EventMessageReceived.Trigger(msgt)
 	}
@@ -0,0 +1,166 @@
+package nodeconn
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/waspconn"
+)
+
+const (
+	// balance queries of all chains collected during the period are sent to the node in one batch
+	balancesBatchPeriod = 200 * time.Millisecond
+	// maximum number of addresses queried in one batch. The rest is left for the next batch
+	maxBalancesBatchSize = 100
+)
+
+// BalancesConsumer receives balances of the address it is attached to
+type BalancesConsumer func(bals map[valuetransaction.ID][]*balance.Balance)
+
+// balancesMux multiplexes balance queries of many chains over the one connection with the node:
+// queries are collected and deduplicated, sent in batches, and balances coming from the node
+// are fanned out to consumers attached to the address
+type balancesMux struct {
+	mutex     sync.Mutex
+	pending   map[address.Address]struct{}
+	consumers map[address.Address]map[string]BalancesConsumer
+}
+
+var balances = newBalancesMux()
+
+func newBalancesMux() *balancesMux {
+	return &balancesMux{
+		pending:   make(map[address.Address]struct{}),
+		consumers: make(map[address.Address]map[string]BalancesConsumer),
+	}
+}
+
+// RequestBalances queues balance queries for addresses. Queries are sent to the node in the next batch,
+// repeated queries for the same address are sent once
+func RequestBalances(addrs ...address.Address) {
+	balances.request(addrs...)
+}
+
+// AttachBalancesConsumer attaches the consumer with the id to balances of the address.
+// The consumer replaces the one previously attached with the same id.
+// Balances of addresses without consumers are passed with EventMessageReceived
+func AttachBalancesConsumer(addr address.Address, id string, consumer BalancesConsumer) {
+	balances.attach(addr, id, consumer)
+}
+
+// DetachBalancesConsumer detaches the consumer with the id from balances of the address
+func DetachBalancesConsumer(addr address.Address, id string) {
+	balances.detach(addr, id)
+}
+
+func (m *balancesMux) request(addrs ...address.Address) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, addr := range addrs {
+		m.pending[addr] = struct{}{}
+	}
+}
+
+// takePending removes from the queue and returns at most max addresses, in deterministic order
+func (m *balancesMux) takePending(max int) []address.Address {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	ret := make([]address.Address, 0, len(m.pending))
+	for addr := range m.pending {
+		ret = append(ret, addr)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	if len(ret) > max {
+		ret = ret[:max]
+	}
+	for _, addr := range ret {
+		delete(m.pending, addr)
+	}
+	return ret
+}
+
+func (m *balancesMux) attach(addr address.Address, id string, consumer BalancesConsumer) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, ok := m.consumers[addr]; !ok {
+		m.consumers[addr] = make(map[string]BalancesConsumer)
+	}
+	m.consumers[addr][id] = consumer
+}
+
+func (m *balancesMux) detach(addr address.Address, id string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.consumers[addr], id)
+	if len(m.consumers[addr]) == 0 {
+		delete(m.consumers, addr)
+	}
+}
+
+func (m *balancesMux) detachAll(addr address.Address) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(m.consumers, addr)
+	delete(m.pending, addr)
+}
+
+// deliver passes balances to all consumers attached to the address.
+// Returns false if there are no consumers
+func (m *balancesMux) deliver(addr address.Address, bals map[valuetransaction.ID][]*balance.Balance) bool {
+	m.mutex.Lock()
+	consumers := make([]BalancesConsumer, 0, len(m.consumers[addr]))
+	for _, c := range m.consumers[addr] {
+		consumers = append(consumers, c)
+	}
+	m.mutex.Unlock()
+
+	for _, c := range consumers {
+		c(bals)
+	}
+	return len(consumers) > 0
+}
+
+// sendBalanceRequests sends the next batch of queued balance queries to the node.
+// Queries which failed to be sent are queued again
+func sendBalanceRequests() {
+	addrs := balances.takePending(maxBalancesBatchSize)
+	if len(addrs) == 0 {
+		return
+	}
+	for i, addr := range addrs {
+		data, err := waspconn.EncodeMsg(&waspconn.WaspToNodeGetOutputsMsg{
+			Address: addr,
+		})
+		if err == nil {
+			err = SendDataToNode(data)
+		}
+		if err != nil {
+			log.Errorf("sending balance queries to node: %v", err)
+			balances.request(addrs[i:]...)
+			return
+		}
+	}
+	log.Debugf("sent balance queries to node for %d addresses", len(addrs))
+}
+
+func keepSendingBalanceRequests(shutdownSignal <-chan struct{}) {
+	for {
+		select {
+		case <-shutdownSignal:
+			return
+		case <-time.After(balancesBatchPeriod):
+			sendBalanceRequests()
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package nodeconn
+
+import (
+	"testing"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalancesMuxBatching(t *testing.T) {
+	m := newBalancesMux()
+	addr1 := address.Random()
+	addr2 := address.Random()
+	addr3 := address.Random()
+
+	m.request(addr1, addr2)
+	m.request(addr1, addr3)
+
+	batch := m.takePending(2)
+	require.Len(t, batch, 2)
+	rest := m.takePending(2)
+	require.Len(t, rest, 1)
+	require.ElementsMatch(t, []address.Address{addr1, addr2, addr3}, append(batch, rest...))
+	require.Empty(t, m.takePending(2))
+}
+
+func TestBalancesMuxFanOut(t *testing.T) {
+	m := newBalancesMux()
+	addr1 := address.Random()
+	addr2 := address.Random()
+	bals := map[valuetransaction.ID][]*balance.Balance{
+		valuetransaction.ID{}: {balance.New(balance.ColorIOTA, 42)},
+	}
+
+	received := make(map[string]int)
+	consumer := func(id string) BalancesConsumer {
+		return func(b map[valuetransaction.ID][]*balance.Balance) {
+			require.EqualValues(t, bals, b)
+			received[id]++
+		}
+	}
+	m.attach(addr1, "a", consumer("a"))
+	m.attach(addr1, "b", consumer("b"))
+	m.attach(addr2, "c", consumer("c"))
+
+	require.True(t, m.deliver(addr1, bals))
+	require.EqualValues(t, map[string]int{"a": 1, "b": 1}, received)
+
+	m.detach(addr1, "a")
+	require.True(t, m.deliver(addr1, bals))
+	require.EqualValues(t, map[string]int{"a": 1, "b": 2}, received)
+
+	m.request(addr2)
+	m.detachAll(addr2)
+	require.False(t, m.deliver(addr2, bals))
+	require.Empty(t, m.takePending(10))
+	require.False(t, m.deliver(address.Random(), bals))
+}
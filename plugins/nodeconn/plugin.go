@@ -40,6 +40,7 @@ func run(_ *node.Plugin) {
 		go nodeConnect()
 		go keepSendingSubscriptionIfNeeded(shutdownSignal)
 		go keepSendingSubscriptionForced(shutdownSignal)
+		go keepSendingBalanceRequests(shutdownSignal)
 
 		<-shutdownSignal
 
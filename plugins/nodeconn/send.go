@@ -22,6 +22,8 @@ func SendWaspIdToNode() error {
 	return nil
 }
 
+// RequestOutputsFromNode sends the balance query for the address to the node immediately.
+// Use RequestBalances to batch queries of many chains
 func RequestOutputsFromNode(addr *address.Address) error {
 	data, err := waspconn.EncodeMsg(&waspconn.WaspToNodeGetOutputsMsg{
 		Address: *addr,
@@ -22,6 +22,7 @@ func Unsubscribe(addr address.Address) {
 	defer bconnMutex.Unlock()
 
 	delete(subscriptions, addr)
+	balances.detachAll(addr)
 }
 
 func sendSubscriptions(forceSend bool) {
@@ -37,11 +37,22 @@ func Init(suite *pairing.SuiteBn256) *node.Plugin {
 			parameters.GetInt(parameters.PeeringPort),
 			nodeKeyPair,
 			suite,
+			parameters.GetBool(parameters.PeeringRequireEncryption),
 			log,
 		)
 		if err != nil {
 			panic(err)
 		}
+		var handovers []*peering_pkg.IdentityHandover
+		if handovers, err = registry.DefaultRegistry().GetIdentityHandovers(); err != nil {
+			panic(err)
+		}
+		defaultNetworkProvider.AddHandovers(handovers)
+		defaultNetworkProvider.OnHandover(func(handover *peering_pkg.IdentityHandover) {
+			if err := registry.DefaultRegistry().SaveIdentityHandover(handover); err != nil {
+				log.Errorf("Unable to store the identity handover of %v, reason=%v", handover.NetID, err)
+			}
+		})
 		log.Infof(
 			"--------------------------------- NetID is %s -----------------------------------",
 			defaultNetworkProvider.Self().NetID(),
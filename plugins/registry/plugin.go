@@ -4,8 +4,12 @@
 package registry
 
 import (
+	"time"
+
+	"github.com/iotaledger/hive.go/daemon"
 	"github.com/iotaledger/hive.go/logger"
 	hive_node "github.com/iotaledger/hive.go/node"
+	"github.com/iotaledger/wasp/packages/parameters"
 	registry_pkg "github.com/iotaledger/wasp/packages/registry"
 	tcrypto_pkg "github.com/iotaledger/wasp/packages/tcrypto"
 )
@@ -25,9 +29,25 @@ func DefaultRegistry() *registry_pkg.Impl {
 func Init(suite tcrypto_pkg.Suite) *hive_node.Plugin {
 	configure := func(_ *hive_node.Plugin) {
 		defaultRegistry = registry_pkg.NewRegistry(suite, logger.NewLogger(pluginName))
+		if _, err := defaultRegistry.MigrateRegistry(); err != nil {
+			logger.NewLogger(pluginName).Panicf("failed to migrate the registry: %v", err)
+		}
+		defaultRegistry.SetBlobQuota(int64(parameters.GetInt(registry_pkg.CfgBlobQuotaMB)) * 1024 * 1024)
 	}
 	run := func(_ *hive_node.Plugin) {
-		// Nothing to run here.
+		err := daemon.BackgroundWorker(pluginName+"[BlobCleanup]", defaultRegistry.RunBlobCleanup, parameters.PriorityBlobCleanup)
+		if err != nil {
+			logger.NewLogger(pluginName).Errorf("failed to start as daemon: %s", err)
+		}
+		checkpointPeriod := time.Duration(parameters.GetInt(registry_pkg.CfgCheckpointPeriodSeconds)) * time.Second
+		if checkpointPeriod > 0 {
+			err = daemon.BackgroundWorker(pluginName+"[StateCheckpoint]", func(shutdownSignal <-chan struct{}) {
+				defaultRegistry.RunStateCheckpointing(checkpointPeriod, shutdownSignal)
+			}, parameters.PriorityStateCheckpoint)
+			if err != nil {
+				logger.NewLogger(pluginName).Errorf("failed to start as daemon: %s", err)
+			}
+		}
 	}
 	return hive_node.NewPlugin(pluginName, hive_node.Enabled, configure, run)
 }
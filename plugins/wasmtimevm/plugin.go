@@ -9,7 +9,9 @@ import (
 	"github.com/iotaledger/hive.go/logger"
 	"github.com/iotaledger/hive.go/node"
 	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/parameters"
 	"github.com/iotaledger/wasp/packages/vm/processors"
+	"github.com/iotaledger/wasp/packages/vm/wasmhost"
 	"github.com/iotaledger/wasp/packages/vm/wasmproc"
 )
 
@@ -32,6 +34,19 @@ func configure(_ *node.Plugin) {
 	if err != nil {
 		log.Panicf("%v: %v", VMType, err)
 	}
+	// wasm binaries are checked for nondeterministic features before loading
+	err = processors.RegisterBinaryFilter(VMType, wasmproc.PrepareBinary)
+	if err != nil {
+		log.Panicf("%v: %v", VMType, err)
+	}
+	// compiled modules are cached on disk to avoid compiling them again after restart
+	if dir := parameters.GetString(parameters.WasmtimeCacheDir); dir != "" {
+		maxSize := int64(parameters.GetInt(parameters.WasmtimeCacheMaxSize)) * 1024 * 1024
+		wasmhost.WasmTimeCache, err = wasmhost.NewAotCache(dir, maxSize)
+		if err != nil {
+			log.Panicf("%v: %v", VMType, err)
+		}
+	}
 	log.Infof("registered VM type: '%s'", VMType)
 }
 
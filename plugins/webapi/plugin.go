@@ -15,6 +15,7 @@ import (
 	"github.com/iotaledger/wasp/packages/util/auth"
 	"github.com/iotaledger/wasp/packages/webapi"
 	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/pangpanglabs/echoswagger/v2"
@@ -23,6 +24,9 @@ import (
 // PluginName is the name of the web API plugin.
 const PluginName = "WebAPI"
 
+// docPath is the path of the API documentation and specifications
+const docPath = "/doc"
+
 var (
 	Server echoswagger.ApiRoot
 
@@ -44,7 +48,7 @@ func WaitUntilIsUp() { // TODO: Not used?
 func configure(*node.Plugin) {
 	log = logger.NewLogger(PluginName)
 
-	Server = echoswagger.New(echo.New(), "/doc", &echoswagger.Info{
+	Server = echoswagger.New(echo.New(), docPath, &echoswagger.Info{
 		Title:       "Wasp API",
 		Description: "REST API for the IOTA Wasp node",
 		Version:     "0.1",
@@ -57,9 +61,11 @@ func configure(*node.Plugin) {
 		Format: `${time_rfc3339_nano} ${remote_ip} ${method} ${uri} ${status} error="${error}"` + "\n",
 	}))
 
-	auth.AddAuthentication(Server.Echo(), parameters.GetStringToString(parameters.WebAPIAuth))
+	// probes of orchestrators and load balancers are not authenticated
+	auth.AddAuthentication(Server.Echo(), parameters.GetStringToString(parameters.WebAPIAuth), routes.Healthz(), routes.Readyz())
 
 	webapi.Init(Server, adminWhitelist())
+	webapi.AddOpenAPIEndpoint(Server, docPath)
 }
 
 func customHTTPErrorHandler(err error, c echo.Context) {
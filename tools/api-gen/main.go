@@ -0,0 +1,85 @@
+// program generates the typed client of the Wasp web API (client/api_gen.go) from the OpenAPI v3
+// specification served by a running node at /doc/openapi.json, or from the specification saved to a file.
+// Both the OpenAPI v3 and the Swagger 2.0 (/doc/swagger.json) specifications are accepted.
+// Optionally the specification is saved, so changes of the web API show up in the diff
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/webapi/openapi"
+)
+
+func main() {
+	spec := flag.String("spec", "http://127.0.0.1:9090/doc/openapi.json", "URL or file of the web API specification")
+	out := flag.String("o", "api_gen.go", "output file of the generated client")
+	pkg := flag.String("package", "client", "package of the generated client")
+	saveSpec := flag.String("save-spec", "", "if set, the OpenAPI v3 specification is saved to the file")
+	flag.Parse()
+
+	if err := generate(*spec, *out, *pkg, *saveSpec); err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(spec, out, pkg, saveSpec string) error {
+	data, err := readSpec(spec)
+	if err != nil {
+		return err
+	}
+	doc, err := parseSpec(data)
+	if err != nil {
+		return err
+	}
+	src, err := openapi.GenerateClient(doc, pkg)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("generated %s from %s: %d operations\n", out, spec, len(doc.Methods()))
+	if saveSpec == "" {
+		return nil
+	}
+	data, err = doc.JSON()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(saveSpec, data, 0644)
+}
+
+func readSpec(spec string) ([]byte, error) {
+	if !strings.HasPrefix(spec, "http://") && !strings.HasPrefix(spec, "https://") {
+		return ioutil.ReadFile(spec)
+	}
+	res, err := http.Get(spec)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", spec, res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+func parseSpec(data []byte) (*openapi.Document, error) {
+	var version struct {
+		Swagger string `json:"swagger"`
+	}
+	if err := json.Unmarshal(data, &version); err != nil {
+		return nil, err
+	}
+	if version.Swagger != "" {
+		return openapi.FromSwagger(data)
+	}
+	return openapi.FromJSON(data)
+}
@@ -0,0 +1,62 @@
+// program generates typed client stubs for calls of the smart contract from other smart contracts
+// from the JSON description of the interface of the contract: the Go package for native contracts
+// and the Rust module for Wasm contracts. See the package packages/vm/callgen
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/iotaledger/wasp/packages/vm/callgen"
+)
+
+func main() {
+	in := flag.String("i", "interface.json", "JSON description of the interface of the contract")
+	goOut := flag.String("go", "", "if set, the Go client is generated into the file")
+	pkg := flag.String("package", "", "package of the Go client. By default it is the contract name followed by 'client'")
+	rustOut := flag.String("rust", "", "if set, the Rust client is generated into the file")
+	flag.Parse()
+
+	if *goOut == "" && *rustOut == "" {
+		fmt.Printf("nothing to generate: specify -go and/or -rust\n")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if err := generate(*in, *goOut, *pkg, *rustOut); err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(in, goOut, pkg, rustOut string) error {
+	iface, err := callgen.Load(in)
+	if err != nil {
+		return fmt.Errorf("%s: %v", in, err)
+	}
+	if goOut != "" {
+		if pkg == "" {
+			pkg = iface.Name + "client"
+		}
+		src, err := callgen.GenerateGo(iface, pkg)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(goOut, src, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("generated %s from %s\n", goOut, in)
+	}
+	if rustOut != "" {
+		src, err := callgen.GenerateRust(iface)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(rustOut, src, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("generated %s from %s\n", rustOut, in)
+	}
+	return nil
+}
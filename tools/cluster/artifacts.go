@@ -0,0 +1,209 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+)
+
+// waspLogFile is the log file written by each wasp node into its directory, see templates.WaspConfig
+const waspLogFile = "wasp.log"
+
+// log time layouts of the console encoder of the node logger
+var logTimeLayouts = []string{
+	"2006-01-02T15:04:05.000Z0700",
+	time.RFC3339Nano,
+}
+
+// LogLine is one entry of the node log
+type LogLine struct {
+	Time    time.Time
+	Node    int
+	Level   string
+	Logger  string
+	Message string
+}
+
+func (l *LogLine) String() string {
+	return fmt.Sprintf("%s  node#%d  %-5s  %s  %s", l.Time.Format("15:04:05.000"), l.Node, l.Level, l.Logger, l.Message)
+}
+
+// CollectArtifacts saves the log, the config and the recorded transcripts of each wasp node, together
+// with the state index of every chain known to the node, into a subdirectory of dir per node.
+// The committee messages of all nodes are merged into a single timeline sorted by time, which is
+// saved to timeline.txt and returned
+func (cluster *Cluster) CollectArtifacts(dir string) ([]*LogLine, error) {
+	if cluster.DataPath == "" {
+		return nil, fmt.Errorf("cluster %s has not been started", cluster.Name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := copyFile(configPath(cluster.DataPath), path.Join(dir, "cluster.json")); err != nil {
+		return nil, err
+	}
+	timeline := make([]*LogLine, 0)
+	for i := 0; i < cluster.Config.Wasp.NumNodes; i++ {
+		nodePath := waspNodeDataPath(cluster.DataPath, i)
+		nodeDir := path.Join(dir, fmt.Sprintf("wasp%d", i))
+		if err := os.MkdirAll(nodeDir, 0755); err != nil {
+			return nil, err
+		}
+		for _, fname := range []string{"config.json", waspLogFile} {
+			if err := copyFile(path.Join(nodePath, fname), path.Join(nodeDir, fname)); err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+		if err := ioutil.WriteFile(path.Join(nodeDir, "chains.txt"), []byte(cluster.chainsIndex(i)), 0644); err != nil {
+			return nil, err
+		}
+		if err := copyDir(path.Join(nodePath, transcriptsDir), path.Join(nodeDir, transcriptsDir)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		lines, err := readNodeLog(path.Join(nodePath, waspLogFile), i)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		for _, l := range lines {
+			if isCommitteeLogger(l.Logger) {
+				timeline = append(timeline, l)
+			}
+		}
+	}
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+	var buf bytes.Buffer
+	for _, l := range timeline {
+		buf.WriteString(l.String())
+		buf.WriteString("\n")
+	}
+	if err := ioutil.WriteFile(path.Join(dir, "timeline.txt"), buf.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	return timeline, nil
+}
+
+// chainsIndex lists chains of the node with the index of the solid state of each chain
+func (cluster *Cluster) chainsIndex(nodeIndex int) string {
+	if !cluster.IsNodeUp(nodeIndex) {
+		return "node is down\n"
+	}
+	records, err := cluster.WaspClient(nodeIndex).GetChainRecordList()
+	if err != nil {
+		return fmt.Sprintf("can't get chain records: %v\n", err)
+	}
+	var buf bytes.Buffer
+	for _, rec := range records {
+		scid := root.Interface.ContractID(rec.ChainID)
+		dump, err := cluster.WaspClient(nodeIndex).DumpSCState(&scid)
+		if err != nil {
+			fmt.Fprintf(&buf, "%s active: %v state index: error: %v\n", rec.ChainID.String(), rec.Active, err)
+			continue
+		}
+		fmt.Fprintf(&buf, "%s active: %v state index: %d\n", rec.ChainID.String(), rec.Active, dump.Index)
+	}
+	return buf.String()
+}
+
+// readNodeLog parses the log written with the console encoder: time, level, logger, caller and message
+// separated by tabs. Lines which can't be parsed are continuation of the message of the previous entry
+func readNodeLog(fname string, nodeIndex int) ([]*LogLine, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ret := make([]*LogLine, 0)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		l, ok := parseLogLine(line, nodeIndex)
+		if ok {
+			ret = append(ret, l)
+			continue
+		}
+		if len(ret) > 0 {
+			ret[len(ret)-1].Message += "\n" + line
+		}
+	}
+	return ret, scanner.Err()
+}
+
+func parseLogLine(line string, nodeIndex int) (*LogLine, bool) {
+	fields := strings.SplitN(line, "\t", 5)
+	if len(fields) < 4 {
+		return nil, false
+	}
+	var t time.Time
+	var err error
+	for _, layout := range logTimeLayouts {
+		if t, err = time.Parse(layout, fields[0]); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, false
+	}
+	ret := &LogLine{
+		Time:   t,
+		Node:   nodeIndex,
+		Level:  fields[1],
+		Logger: fields[2],
+	}
+	// the caller is omitted when disabled in the logger config
+	ret.Message = fields[len(fields)-1]
+	return ret, true
+}
+
+// isCommitteeLogger selects log entries of the consensus ('c') and state manager ('s') of chains
+// and of the peering
+func isCommitteeLogger(name string) bool {
+	segments := strings.Split(name, ".")
+	for i, s := range segments {
+		if i > 0 && (s == "c" || s == "s") {
+			return true
+		}
+		if strings.EqualFold(s, "peering") {
+			return true
+		}
+	}
+	return false
+}
+
+func copyFile(src, dst string) error {
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, data, 0644)
+}
+
+func copyDir(src, dst string) error {
+	files, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if err := copyFile(path.Join(src, f.Name()), path.Join(dst, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -10,11 +10,13 @@ import (
 	"path"
 	"sort"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
 	"github.com/iotaledger/goshimmer/client/wallet/packages/seed"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/tangle"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
@@ -25,6 +27,7 @@ import (
 	"github.com/iotaledger/wasp/client/level1/goshimmer"
 	"github.com/iotaledger/wasp/client/multiclient"
 	waspapi "github.com/iotaledger/wasp/packages/apilib"
+	"github.com/iotaledger/wasp/packages/chain/transcript"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/sctransaction"
@@ -39,9 +42,15 @@ type Cluster struct {
 	Name    string
 	Config  *ClusterConfig
 	Started bool
+	// DataPath is the cluster data directory, set when the cluster is started
+	DataPath string
 
 	goshimmerCmd *exec.Cmd
 	waspCmds     []*exec.Cmd
+
+	// hooks called for each line of the log of the wasp node, see KillNodeOnLog
+	logHooksMutex sync.Mutex
+	logHooks      []func(nodeIndex int, line string)
 }
 
 func New(name string, config *ClusterConfig) *Cluster {
@@ -62,12 +71,16 @@ func (clu *Cluster) Level1Client() level1.Level1Client {
 
 func (clu *Cluster) DeployDefaultChain() (*Chain, error) {
 	committee := clu.Config.AllNodes()
-	minQuorum := len(committee)/2 + 1
-	quorum := len(committee) * 3 / 4
+	return clu.DeployChain("Default chain", committee, defaultQuorum(len(committee)))
+}
+
+func defaultQuorum(committeeSize int) uint16 {
+	minQuorum := committeeSize/2 + 1
+	quorum := committeeSize * 3 / 4
 	if quorum < minQuorum {
 		quorum = minQuorum
 	}
-	return clu.DeployChain("Default chain", committee, uint16(quorum))
+	return uint16(quorum)
 }
 
 func (clu *Cluster) DeployChain(description string, committeeNodes []int, quorum uint16) (*Chain, error) {
@@ -280,6 +293,7 @@ func (cluster *Cluster) Start(dataPath string) error {
 		return fmt.Errorf("Data path %s does not exist", dataPath)
 	}
 
+	cluster.DataPath = dataPath
 	err = cluster.start(dataPath)
 	if err != nil {
 		return err
@@ -295,7 +309,7 @@ func (cluster *Cluster) start(dataPath string) error {
 	initOk := make(chan bool, cluster.Config.Wasp.NumNodes)
 
 	if !cluster.Config.Goshimmer.Provided {
-		cmd, err := cluster.startServer("goshimmer", goshimmerDataPath(dataPath), "goshimmer", initOk, "WebAPI started")
+		cmd, err := cluster.startServer("goshimmer", goshimmerDataPath(dataPath), nil, "goshimmer", initOk, "WebAPI started")
 		if err != nil {
 			return err
 		}
@@ -310,11 +324,9 @@ func (cluster *Cluster) start(dataPath string) error {
 	}
 
 	for i := 0; i < cluster.Config.Wasp.NumNodes; i++ {
-		cmd, err := cluster.startServer("wasp", waspNodeDataPath(dataPath, i), fmt.Sprintf("wasp %d", i), initOk, "nanomsg publisher is running")
-		if err != nil {
+		if err := cluster.startWaspNode(i, initOk); err != nil {
 			return err
 		}
-		cluster.waspCmds[i] = cmd
 	}
 
 	for i := 0; i < cluster.Config.Wasp.NumNodes; i++ {
@@ -328,9 +340,26 @@ func (cluster *Cluster) start(dataPath string) error {
 	return nil
 }
 
-func (cluster *Cluster) startServer(command string, cwd string, name string, initOk chan<- bool, initOkMsg string) (*exec.Cmd, error) {
+// startWaspNode starts the wasp node. env are additional environment variables of the process, in the form "key=value"
+func (cluster *Cluster) startWaspNode(nodeIndex int, initOk chan<- bool, env ...string) error {
+	if cluster.Config.Wasp.RecordTranscripts {
+		env = append(env, transcript.DirEnvVar+"="+transcriptsDir)
+	}
+	cmd, err := cluster.startServer("wasp", waspNodeDataPath(cluster.DataPath, nodeIndex), env, fmt.Sprintf("wasp %d", nodeIndex),
+		initOk, "nanomsg publisher is running", func(line string) { cluster.runLogHooks(nodeIndex, line) })
+	if err != nil {
+		return err
+	}
+	cluster.waspCmds[nodeIndex] = cmd
+	return nil
+}
+
+func (cluster *Cluster) startServer(command string, cwd string, env []string, name string, initOk chan<- bool, initOkMsg string, hooks ...func(string)) (*exec.Cmd, error) {
 	cmd := exec.Command(command)
 	cmd.Dir = cwd
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -350,8 +379,10 @@ func (cluster *Cluster) startServer(command string, cwd string, name string, ini
 	)
 	go scanLog(
 		stdoutPipe,
-		func(line string) { fmt.Printf("[ %s] %s\n", name, line) },
-		waitFor(initOkMsg, initOk),
+		append([]func(string){
+			func(line string) { fmt.Printf("[ %s] %s\n", name, line) },
+			waitFor(initOkMsg, initOk),
+		}, hooks...)...,
 	)
 
 	return cmd, nil
@@ -462,6 +493,29 @@ func (cluster *Cluster) PostTransaction(tx *sctransaction.Transaction) error {
 	return nil
 }
 
+// PostRequestsMultiChain posts one transaction with request sections targeting, possibly, several chains.
+// All requests are confirmed atomically by the ledger
+func (cluster *Cluster) PostRequestsMultiChain(sigScheme signaturescheme.SignatureScheme, sections ...waspapi.RequestSectionParams) (*sctransaction.Transaction, error) {
+	return waspapi.CreateRequestTransaction(waspapi.CreateRequestTransactionParams{
+		Level1Client:         cluster.Level1Client(),
+		SenderSigScheme:      sigScheme,
+		RequestSectionParams: sections,
+		Post:                 true,
+		WaitForConfirmation:  true,
+	})
+}
+
+// WaitUntilRequestsProcessedByChains blocks until each of the chains processes the requests
+// of the transaction which target that chain
+func (cluster *Cluster) WaitUntilRequestsProcessedByChains(tx *sctransaction.Transaction, timeout time.Duration, chains ...*Chain) error {
+	for _, ch := range chains {
+		if err := ch.CommitteeMultiClient().WaitUntilChainRequestsProcessed(&ch.ChainID, tx, timeout); err != nil {
+			return fmt.Errorf("chain %s: %v", ch.ChainID.String(), err)
+		}
+	}
+	return nil
+}
+
 func (cluster *Cluster) VerifyAddressBalances(addr *address.Address, totalExpected int64, expect map[balance.Color]int64, comment ...string) bool {
 	allOuts, err := cluster.Level1Client().GetConfirmedAccountOutputs(addr)
 	if err != nil {
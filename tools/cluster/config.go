@@ -10,10 +10,20 @@ import (
 )
 
 type GoshimmerConfig struct {
-	ApiPort  int
-	Provided bool
+	ApiPort int
+	// WaspConnPort is the port wasp nodes connect to, 0 means the default one
+	WaspConnPort int
+	// ports of other goshimmer services are calculated as this value + service index,
+	// 0 means the default ports, see goshimmerServicePorts
+	FirstServicePort int
+	Provided         bool
 }
 
+const defaultWaspConnPort = 5000
+
+// default ports of the goshimmer services: dashboard, gossip, fpc, profiling, prometheus, analysis server
+var goshimmerServicePorts = []int{8081, 14666, 10895, 6061, 9311, 16178}
+
 type WaspConfig struct {
 	NumNodes int
 
@@ -22,11 +32,19 @@ type WaspConfig struct {
 	FirstPeeringPort   int
 	FirstNanomsgPort   int
 	FirstDashboardPort int
+
+	// if true, nodes store their databases on disk, so they can be restarted, see RestartNode
+	PersistentDatabase bool
+
+	// if true, nodes record transcripts of committee messages, see Cluster.Transcript
+	RecordTranscripts bool
 }
 
 type ClusterConfig struct {
 	Wasp      WaspConfig
 	Goshimmer GoshimmerConfig
+	// Resources is the resource budget of each node, checked by tests of the cluster
+	Resources ResourceBudget
 }
 
 func DefaultConfig() *ClusterConfig {
@@ -39,9 +57,11 @@ func DefaultConfig() *ClusterConfig {
 			FirstDashboardPort: 7000,
 		},
 		Goshimmer: GoshimmerConfig{
-			ApiPort:  8080,
-			Provided: false,
+			ApiPort:      8080,
+			WaspConnPort: defaultWaspConnPort,
+			Provided:     false,
 		},
+		Resources: DefaultResourceBudget(),
 	}
 }
 
@@ -75,6 +95,20 @@ func (c *ClusterConfig) goshimmerApiHost() string {
 	return fmt.Sprintf("127.0.0.1:%d", c.Goshimmer.ApiPort)
 }
 
+func (c *ClusterConfig) WaspConnPort() int {
+	if c.Goshimmer.WaspConnPort == 0 {
+		return defaultWaspConnPort
+	}
+	return c.Goshimmer.WaspConnPort
+}
+
+func (c *ClusterConfig) goshimmerServicePort(service int) int {
+	if c.Goshimmer.FirstServicePort == 0 {
+		return goshimmerServicePorts[service]
+	}
+	return c.Goshimmer.FirstServicePort + service
+}
+
 func (c *ClusterConfig) waspHosts(nodeIndexes []int, getHost func(i int) string) []string {
 	hosts := make([]string, 0)
 	for _, i := range nodeIndexes {
@@ -151,7 +185,14 @@ func (c *ClusterConfig) GoshimmerConfigTemplateParams() *templates.GoshimmerConf
 		panic("should not reach here")
 	}
 	return &templates.GoshimmerConfigParams{
-		ApiPort: c.Goshimmer.ApiPort,
+		ApiPort:        c.Goshimmer.ApiPort,
+		WaspConnPort:   c.WaspConnPort(),
+		DashboardPort:  c.goshimmerServicePort(0),
+		GossipPort:     c.goshimmerServicePort(1),
+		FPCPort:        c.goshimmerServicePort(2),
+		ProfilingPort:  c.goshimmerServicePort(3),
+		PrometheusPort: c.goshimmerServicePort(4),
+		AnalysisPort:   c.goshimmerServicePort(5),
 	}
 }
 
@@ -161,5 +202,8 @@ func (c *ClusterConfig) WaspConfigTemplateParams(i int) *templates.WaspConfigPar
 		DashboardPort: c.DashboardPort(i),
 		PeeringPort:   c.PeeringPort(i),
 		NanomsgPort:   c.NanomsgPort(i),
+		NodeConnPort:  c.WaspConnPort(),
+
+		PersistentDatabase: c.Wasp.PersistentDatabase,
 	}
 }
@@ -0,0 +1,129 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/chain"
+)
+
+// KillNode kills the process of the wasp node without the graceful shutdown, as if the node crashed
+func (cluster *Cluster) KillNode(nodeIndex int) error {
+	if !cluster.IsNodeUp(nodeIndex) {
+		return fmt.Errorf("node %d is not running", nodeIndex)
+	}
+	fmt.Printf("[cluster] Killing wasp node %d\n", nodeIndex)
+	if err := cluster.waspCmds[nodeIndex].Process.Kill(); err != nil {
+		return err
+	}
+	waitCmd(&cluster.waspCmds[nodeIndex])
+	fmt.Printf("[cluster] Node %d has been killed\n", nodeIndex)
+	return nil
+}
+
+// RestartNode starts the stopped or killed wasp node again in its data directory.
+// The node keeps its chains only if the cluster was configured with the persistent database
+func (cluster *Cluster) RestartNode(nodeIndex int) error {
+	if cluster.IsNodeUp(nodeIndex) {
+		return fmt.Errorf("node %d is running", nodeIndex)
+	}
+	initOk := make(chan bool, 1)
+	if err := cluster.startWaspNode(nodeIndex, initOk); err != nil {
+		return err
+	}
+	select {
+	case <-initOk:
+	case <-time.After(10 * time.Second):
+		return fmt.Errorf("timeout restarting wasp node %d", nodeIndex)
+	}
+	fmt.Printf("[cluster] Node %d has been restarted\n", nodeIndex)
+	return nil
+}
+
+// CrashNodesAt restarts the nodes so that the first of them which reaches the crash point of the consensus
+// exits immediately, as if it crashed there, see chain.CrashPointEnvVar.
+// The index of the crashed node is sent to the returned channel. The crashed node is restarted by RestartNode
+// without the crash point
+func (cluster *Cluster) CrashNodesAt(nodeIndexes []int, point string) (<-chan int, error) {
+	ret := make(chan int, 1)
+	crashed := cluster.NotifyOnLog(nodeIndexes, chain.CrashPointLogMessage(point))
+	for _, nodeIndex := range nodeIndexes {
+		cluster.StopNode(nodeIndex)
+		initOk := make(chan bool, 1)
+		if err := cluster.startWaspNode(nodeIndex, initOk, chain.CrashPointEnvVar+"="+point); err != nil {
+			return nil, err
+		}
+		select {
+		case <-initOk:
+		case <-time.After(10 * time.Second):
+			return nil, fmt.Errorf("timeout restarting wasp node %d", nodeIndex)
+		}
+	}
+	go func() {
+		nodeIndex := <-crashed
+		waitCmd(&cluster.waspCmds[nodeIndex])
+		fmt.Printf("[cluster] Node %d has crashed at '%s'\n", nodeIndex, point)
+		ret <- nodeIndex
+	}()
+	return ret, nil
+}
+
+// KillNodeOnLog kills the first node among nodeIndexes which logs the line containing msg.
+// Only one node is killed. The index of the killed node is sent to the returned channel
+func (cluster *Cluster) KillNodeOnLog(nodeIndexes []int, msg string) <-chan int {
+	ret := make(chan int, 1)
+	logged := cluster.NotifyOnLog(nodeIndexes, msg)
+	go func() {
+		nodeIndex := <-logged
+		if err := cluster.KillNode(nodeIndex); err != nil {
+			fmt.Printf("[cluster] %v\n", err)
+			return
+		}
+		ret <- nodeIndex
+	}()
+	return ret
+}
+
+// NotifyOnLog sends to the returned channel the index of the first node among nodeIndexes
+// which logs the line containing msg
+func (cluster *Cluster) NotifyOnLog(nodeIndexes []int, msg string) <-chan int {
+	ret := make(chan int, 1)
+	var once sync.Once
+	cluster.addLogHook(func(nodeIndex int, line string) {
+		if !oneOfNodes(nodeIndex, nodeIndexes) || !strings.Contains(line, msg) {
+			return
+		}
+		once.Do(func() {
+			ret <- nodeIndex
+		})
+	})
+	return ret
+}
+
+func (cluster *Cluster) addLogHook(hook func(nodeIndex int, line string)) {
+	cluster.logHooksMutex.Lock()
+	defer cluster.logHooksMutex.Unlock()
+	cluster.logHooks = append(cluster.logHooks, hook)
+}
+
+func (cluster *Cluster) runLogHooks(nodeIndex int, line string) {
+	cluster.logHooksMutex.Lock()
+	hooks := make([]func(int, string), len(cluster.logHooks))
+	copy(hooks, cluster.logHooks)
+	cluster.logHooksMutex.Unlock()
+
+	for _, hook := range hooks {
+		hook(nodeIndex, line)
+	}
+}
+
+func oneOfNodes(nodeIndex int, nodeIndexes []int) bool {
+	for _, i := range nodeIndexes {
+		if i == nodeIndex {
+			return true
+		}
+	}
+	return false
+}
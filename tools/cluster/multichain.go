@@ -0,0 +1,111 @@
+package cluster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address/signaturescheme"
+	valuetransaction "github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	waspapi "github.com/iotaledger/wasp/packages/apilib"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/subscribe"
+)
+
+// DisjointCommittees splits the nodes of the cluster into numChains committees of equal size
+// which do not share nodes. The remaining nodes are not used
+func (clu *Cluster) DisjointCommittees(numChains int) ([][]int, error) {
+	nodes := clu.Config.AllNodes()
+	if numChains <= 0 || len(nodes) < numChains {
+		return nil, fmt.Errorf("can't split %d nodes into %d committees", len(nodes), numChains)
+	}
+	size := len(nodes) / numChains
+	ret := make([][]int, numChains)
+	for i := range ret {
+		ret[i] = nodes[i*size : (i+1)*size]
+	}
+	return ret, nil
+}
+
+// DeployChains deploys one chain for each of the committees, with the default quorum.
+// Committees are usually obtained with DisjointCommittees
+func (clu *Cluster) DeployChains(committees [][]int) ([]*Chain, error) {
+	ret := make([]*Chain, len(committees))
+	for i, committee := range committees {
+		ch, err := clu.DeployChain(fmt.Sprintf("Chain #%d", i), committee, defaultQuorum(len(committee)))
+		if err != nil {
+			return nil, fmt.Errorf("deploying chain #%d: %v", i, err)
+		}
+		ret[i] = ch
+	}
+	return ret, nil
+}
+
+// PostCrossChainRequest posts the request to the source chain, the contract of which is expected to post
+// numCrossRequests requests to the target chain. It blocks until the source chain processes the request and
+// the target chain processes all requests posted by the source chain in the same state transition.
+// Returns the request transaction and the state transaction of the source chain, which carries the cross-chain requests
+func (clu *Cluster) PostCrossChainRequest(
+	sigScheme signaturescheme.SignatureScheme,
+	source, target *Chain,
+	section waspapi.RequestSectionParams,
+	numCrossRequests int,
+	timeout time.Duration,
+) (*sctransaction.Transaction, *valuetransaction.ID, error) {
+	// the state transaction of the source chain is only known from the publisher's messages
+	sub, err := subscribe.SubscribeMulti(clu.Config.NanomsgHosts(source.CommitteeNodes), []string{"state", "request_out"})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer sub.Close()
+
+	tx, err := clu.PostRequestsMultiChain(sigScheme, section)
+	if err != nil {
+		return nil, nil, err
+	}
+	stateTxID, err := waitForStateTransaction(sub, &source.ChainID, tx.ID(), timeout)
+	if err != nil {
+		return tx, nil, err
+	}
+	fmt.Printf("[cluster] request tx %s processed by chain %s in state tx %s\n",
+		tx.ID().String(), source.ChainID.String(), stateTxID.String())
+
+	for i := 0; i < numCrossRequests; i++ {
+		reqID := coretypes.NewRequestID(*stateTxID, uint16(i))
+		if err := target.CommitteeMultiClient().WaitUntilRequestProcessed(&target.ChainID, &reqID, timeout); err != nil {
+			return tx, stateTxID, fmt.Errorf("chain %s: cross-chain request %s: %v", target.ChainID.String(), reqID.String(), err)
+		}
+	}
+	return tx, stateTxID, nil
+}
+
+// waitForStateTransaction returns ID of the state transaction of the chain which settled the requests of the request transaction
+func waitForStateTransaction(sub *subscribe.Subscription, chainID *coretypes.ChainID, reqTxID valuetransaction.ID, timeout time.Duration) (*valuetransaction.ID, error) {
+	chainIDStr := chainID.String()
+	stateTxByIndex := make(map[string]string)
+	stateIndex := ""
+	deadline := time.After(timeout)
+	for {
+		select {
+		case msg := <-sub.HostMessages:
+			m := msg.Message
+			switch {
+			case m[0] == "state" && len(m) >= 5 && m[1] == chainIDStr:
+				// state <chain ID> <state index> <block size> <state tx ID> ...
+				stateTxByIndex[m[2]] = m[4]
+			case m[0] == "request_out" && len(m) >= 5 && m[1] == chainIDStr && m[2] == reqTxID.String():
+				// request_out <chain ID> <request tx ID> <request index> <state index> ...
+				stateIndex = m[4]
+			}
+			if txid, ok := stateTxByIndex[stateIndex]; ok && stateIndex != "" {
+				ret, err := valuetransaction.IDFromBase58(txid)
+				if err != nil {
+					return nil, err
+				}
+				return &ret, nil
+			}
+		case <-deadline:
+			return nil, fmt.Errorf("timeout while waiting for chain %s to process request tx %s", chainIDStr, reqTxID.String())
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/state"
+)
+
+// Partition cuts off the peering traffic between the groups of wasp nodes, as if the network was split.
+// Nodes within one group keep communicating with each other. Nodes not listed in any group are not affected.
+// The traffic is dropped by the peering of the nodes themselves, so no firewall rules are needed
+func (cluster *Cluster) Partition(groups ...[]int) error {
+	for gi, group := range groups {
+		blocked := make([]int, 0)
+		for gj, other := range groups {
+			if gj != gi {
+				blocked = append(blocked, other...)
+			}
+		}
+		for _, nodeIndex := range group {
+			if err := cluster.WaspClient(nodeIndex).SetBlockedPeers(cluster.Config.PeeringHosts(blocked)); err != nil {
+				return fmt.Errorf("node %d: %v", nodeIndex, err)
+			}
+		}
+	}
+	fmt.Printf("[cluster] Nodes partitioned: %v\n", groups)
+	return nil
+}
+
+// HealPartition restores the peering traffic between all running nodes
+func (cluster *Cluster) HealPartition() error {
+	for _, nodeIndex := range cluster.ActiveNodes() {
+		if err := cluster.WaspClient(nodeIndex).SetBlockedPeers(nil); err != nil {
+			return fmt.Errorf("node %d: %v", nodeIndex, err)
+		}
+	}
+	fmt.Printf("[cluster] Partition healed\n")
+	return nil
+}
+
+// PartitionFor partitions the nodes into the groups, holds the partition for the duration and heals it
+func (cluster *Cluster) PartitionFor(duration time.Duration, groups ...[]int) error {
+	if err := cluster.Partition(groups...); err != nil {
+		return err
+	}
+	time.Sleep(duration)
+	return cluster.HealPartition()
+}
+
+// VerifyNoDivergence checks that the running committee nodes of the chain store the same blocks.
+// Nodes may lag behind, but the blocks they have must be equal to the blocks of other nodes
+func (ch *Chain) VerifyNoDivergence() error {
+	var ref []state.Block
+	refNode := -1
+	for _, nodeIndex := range ch.CommitteeNodes {
+		if !ch.Cluster.IsNodeUp(nodeIndex) {
+			continue
+		}
+		data, err := ch.Cluster.WaspClient(nodeIndex).ExportBlocks(&ch.ChainID, 0, nil)
+		if err != nil {
+			return fmt.Errorf("node %d: %v", nodeIndex, err)
+		}
+		blocks, err := state.ReadBlocks(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("node %d: %v", nodeIndex, err)
+		}
+		for i := 0; i < len(blocks) && i < len(ref); i++ {
+			if blocks[i].EssenceHash() != ref[i].EssenceHash() {
+				return fmt.Errorf("block #%d of node %d differs from the block of node %d",
+					blocks[i].StateIndex(), nodeIndex, refNode)
+			}
+		}
+		if len(blocks) > len(ref) {
+			ref, refNode = blocks, nodeIndex
+		}
+	}
+	return nil
+}
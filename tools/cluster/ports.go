@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// PortsEnvVar overrides the directory of the reservation files, shared by all processes of the machine
+const PortsEnvVar = "WASP_CLUSTER_PORTS"
+
+// ports reserved for clusters form blocks of portBlockSize ports, starting at firstReservedPort
+const (
+	firstReservedPort = 20000
+	portBlockSize     = 100
+	numPortBlocks     = 100
+)
+
+// PortReservation is the block of ports reserved for the cluster, so that clusters of tests
+// running in parallel, also in different processes, don't collide. Release it after the cluster is stopped
+type PortReservation struct {
+	Block    int
+	lockPath string
+}
+
+// ReservePorts reserves a free block of ports and sets all ports of the config, including
+// the ports of goshimmer, to it. The reservation is a file in the reservation directory, created
+// exclusively. Reservations of processes which are gone are taken over
+func ReservePorts(config *ClusterConfig) (*PortReservation, error) {
+	needed := 4*config.Wasp.NumNodes + 2 + len(goshimmerServicePorts)
+	if needed > portBlockSize {
+		return nil, fmt.Errorf("%d nodes need %d ports, more than %d ports of the block", config.Wasp.NumNodes, needed, portBlockSize)
+	}
+	dir := reservationsDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	for block := 0; block < numPortBlocks; block++ {
+		lockPath := path.Join(dir, fmt.Sprintf("block-%d.lock", block))
+		if !lockBlock(lockPath) {
+			continue
+		}
+		first := firstReservedPort + block*portBlockSize
+		if !portsFree(first, needed) {
+			// used by some process outside of the reservations
+			_ = os.Remove(lockPath)
+			continue
+		}
+		setPorts(config, first)
+		fmt.Printf("[cluster] reserved ports %d..%d\n", first, first+needed-1)
+		return &PortReservation{Block: block, lockPath: lockPath}, nil
+	}
+	return nil, fmt.Errorf("no free block of ports in %d..%d", firstReservedPort, firstReservedPort+numPortBlocks*portBlockSize-1)
+}
+
+// Release frees the block of ports for other clusters
+func (r *PortReservation) Release() {
+	if err := os.Remove(r.lockPath); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("[cluster] failed to release ports: %v\n", err)
+	}
+}
+
+func reservationsDir() string {
+	if dir := os.Getenv(PortsEnvVar); dir != "" {
+		return dir
+	}
+	return path.Join(os.TempDir(), "wasp-cluster-ports")
+}
+
+func setPorts(config *ClusterConfig, first int) {
+	n := config.Wasp.NumNodes
+	config.Wasp.FirstApiPort = first
+	config.Wasp.FirstPeeringPort = first + n
+	config.Wasp.FirstNanomsgPort = first + 2*n
+	config.Wasp.FirstDashboardPort = first + 3*n
+	config.Goshimmer.ApiPort = first + 4*n
+	config.Goshimmer.WaspConnPort = first + 4*n + 1
+	config.Goshimmer.FirstServicePort = first + 4*n + 2
+}
+
+// lockBlock creates the reservation file with the pid of the process. The file of the process
+// which is not running anymore is removed and created again
+func lockBlock(lockPath string) bool {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = f.WriteString(strconv.Itoa(os.Getpid()))
+			f.Close()
+			return err == nil
+		}
+		if !os.IsExist(err) || !isStale(lockPath) {
+			return false
+		}
+		_ = os.Remove(lockPath)
+	}
+	return false
+}
+
+func isStale(lockPath string) bool {
+	data, err := ioutil.ReadFile(lockPath)
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		// the owner didn't write the pid yet
+		return false
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return true
+	}
+	return proc.Signal(syscall.Signal(0)) != nil
+}
+
+// portsFree checks that nobody listens on the ports, both TCP and UDP, as the peering is UDP
+func portsFree(first, num int) bool {
+	for port := first; port < first+num; port++ {
+		addr := fmt.Sprintf("127.0.0.1:%d", port)
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return false
+		}
+		l.Close()
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			return false
+		}
+		pc.Close()
+	}
+	return true
+}
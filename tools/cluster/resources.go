@@ -0,0 +1,296 @@
+package cluster
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clockTicksPerSecond is the unit of CPU times in /proc/<pid>/stat (USER_HZ), which is 100 on all common Linux platforms
+const clockTicksPerSecond = 100
+
+// ResourceBudget is the maximum resource usage of each wasp node allowed during the test, see MonitorResources.
+// Zero value of the field means the resource is not limited
+type ResourceBudget struct {
+	// MaxCPUPercent is the CPU usage of the node process between two samples, 100 is one core
+	MaxCPUPercent float64
+	// MaxRSSMB is the resident memory of the node process in megabytes
+	MaxRSSMB int
+	// MaxHeapMB is the heap allocated by the node in megabytes
+	MaxHeapMB     int
+	MaxGoroutines int
+	// MaxDBSizeMB is the size of the database directory of the node in megabytes
+	MaxDBSizeMB int
+}
+
+// DefaultResourceBudget catches leaks and runaway goroutines without limiting normal operation of the cluster
+func DefaultResourceBudget() ResourceBudget {
+	return ResourceBudget{
+		MaxRSSMB:      2048,
+		MaxHeapMB:     1024,
+		MaxGoroutines: 5000,
+		MaxDBSizeMB:   1024,
+	}
+}
+
+// ResourceUsage is the sample of the resource usage of the wasp node
+type ResourceUsage struct {
+	Node       int
+	Time       time.Time
+	CPUPercent float64
+	RSS        uint64
+	HeapAlloc  uint64
+	Goroutines int
+	DBSize     int64
+
+	// cumulative CPU time of the process, used to calculate CPUPercent
+	cpuTicks uint64
+}
+
+func (u *ResourceUsage) String() string {
+	return fmt.Sprintf("node#%d: cpu %.1f%%, rss %d MB, heap %d MB, goroutines %d, db %d MB",
+		u.Node, u.CPUPercent, u.RSS>>20, u.HeapAlloc>>20, u.Goroutines, u.DBSize>>20)
+}
+
+// violations returns descriptions of limits of the budget exceeded by the sample
+func (u *ResourceUsage) violations(b *ResourceBudget) []string {
+	ret := make([]string, 0)
+	if b.MaxCPUPercent > 0 && u.CPUPercent > b.MaxCPUPercent {
+		ret = append(ret, fmt.Sprintf("node#%d: cpu %.1f%% exceeds %.1f%%", u.Node, u.CPUPercent, b.MaxCPUPercent))
+	}
+	if b.MaxRSSMB > 0 && u.RSS > uint64(b.MaxRSSMB)<<20 {
+		ret = append(ret, fmt.Sprintf("node#%d: rss %d MB exceeds %d MB", u.Node, u.RSS>>20, b.MaxRSSMB))
+	}
+	if b.MaxHeapMB > 0 && u.HeapAlloc > uint64(b.MaxHeapMB)<<20 {
+		ret = append(ret, fmt.Sprintf("node#%d: heap %d MB exceeds %d MB", u.Node, u.HeapAlloc>>20, b.MaxHeapMB))
+	}
+	if b.MaxGoroutines > 0 && u.Goroutines > b.MaxGoroutines {
+		ret = append(ret, fmt.Sprintf("node#%d: %d goroutines exceed %d", u.Node, u.Goroutines, b.MaxGoroutines))
+	}
+	if b.MaxDBSizeMB > 0 && u.DBSize > int64(b.MaxDBSizeMB)<<20 {
+		ret = append(ret, fmt.Sprintf("node#%d: db %d MB exceeds %d MB", u.Node, u.DBSize>>20, b.MaxDBSizeMB))
+	}
+	return ret
+}
+
+// NodeResources samples the resource usage of the running wasp node. Goroutines and the heap are reported
+// by the node, CPU and resident memory are read from /proc, so they are zero on systems without it.
+// The CPU usage is calculated since the previous sample prev, if provided
+func (cluster *Cluster) NodeResources(nodeIndex int, prev ...*ResourceUsage) (*ResourceUsage, error) {
+	if !cluster.IsNodeUp(nodeIndex) {
+		return nil, fmt.Errorf("node %d is not running", nodeIndex)
+	}
+	ret := &ResourceUsage{Node: nodeIndex, Time: time.Now()}
+	res, err := cluster.WaspClient(nodeIndex).GetNodeResources()
+	if err != nil {
+		return nil, err
+	}
+	ret.Goroutines = res.Goroutines
+	ret.HeapAlloc = res.HeapAlloc
+
+	if cmd := cluster.waspCmds[nodeIndex]; cmd != nil && cmd.Process != nil {
+		ret.cpuTicks, _ = readProcCPUTicks(cmd.Process.Pid)
+		ret.RSS, _ = readProcRSS(cmd.Process.Pid)
+	}
+	if len(prev) > 0 && prev[0] != nil && ret.cpuTicks >= prev[0].cpuTicks {
+		elapsed := ret.Time.Sub(prev[0].Time).Seconds()
+		if elapsed > 0 {
+			cpu := float64(ret.cpuTicks-prev[0].cpuTicks) / clockTicksPerSecond
+			ret.CPUPercent = 100 * cpu / elapsed
+		}
+	}
+	ret.DBSize, _ = dirSize(path.Join(waspNodeDataPath(cluster.DataPath, nodeIndex), "waspdb"))
+	return ret, nil
+}
+
+// readProcCPUTicks returns user and system CPU time of the process in clock ticks
+func readProcCPUTicks(pid int) (uint64, error) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, err
+	}
+	// the command name in parentheses may contain spaces, fields are counted after it
+	s := string(data)
+	fields := strings.Fields(s[strings.LastIndex(s, ")")+1:])
+	// utime and stime are fields 14 and 15 of the stat, 12th and 13th after the command name
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("unexpected format of /proc/%d/stat", pid)
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return utime + stime, nil
+}
+
+// readProcRSS returns the resident memory of the process in bytes
+func readProcRSS(pid int) (uint64, error) {
+	f, err := os.Open(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "VmRSS:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			return kb << 10, err
+		}
+	}
+	return 0, fmt.Errorf("VmRSS not found in /proc/%d/status", pid)
+}
+
+func dirSize(dir string) (int64, error) {
+	var ret int64
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			ret += info.Size()
+		}
+		return nil
+	})
+	return ret, err
+}
+
+// ResourceMonitor periodically samples the resource usage of all running wasp nodes of the cluster
+// and records the peak usage and the violations of the budget
+type ResourceMonitor struct {
+	cluster *Cluster
+	budget  ResourceBudget
+
+	mutex      sync.Mutex
+	last       map[int]*ResourceUsage
+	peak       map[int]*ResourceUsage
+	violations []string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// MonitorResources starts sampling the resource usage of nodes each period until Stop is called
+func (cluster *Cluster) MonitorResources(period time.Duration, budget ResourceBudget) *ResourceMonitor {
+	m := &ResourceMonitor{
+		cluster:    cluster,
+		budget:     budget,
+		last:       make(map[int]*ResourceUsage),
+		peak:       make(map[int]*ResourceUsage),
+		violations: make([]string, 0),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go m.run(period)
+	return m
+}
+
+func (m *ResourceMonitor) run(period time.Duration) {
+	defer close(m.done)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		m.sample()
+		select {
+		case <-m.stop:
+			m.sample()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *ResourceMonitor) sample() {
+	for i := 0; i < m.cluster.Config.Wasp.NumNodes; i++ {
+		if !m.cluster.IsNodeUp(i) {
+			continue
+		}
+		m.mutex.Lock()
+		prev := m.last[i]
+		m.mutex.Unlock()
+
+		u, err := m.cluster.NodeResources(i, prev)
+		if err != nil {
+			// the node may be stopping or restarting
+			continue
+		}
+
+		m.mutex.Lock()
+		m.last[i] = u
+		m.peak[i] = maxUsage(m.peak[i], u)
+		for _, v := range u.violations(&m.budget) {
+			fmt.Printf("[cluster] resource budget exceeded: %s\n", v)
+			m.violations = append(m.violations, u.Time.Format("15:04:05.000")+" "+v)
+		}
+		m.mutex.Unlock()
+	}
+}
+
+// Stop stops sampling and takes the last sample
+func (m *ResourceMonitor) Stop() {
+	select {
+	case <-m.stop:
+		return
+	default:
+	}
+	close(m.stop)
+	<-m.done
+}
+
+// Violations returns the exceeded limits of the budget, each with the time of the sample
+func (m *ResourceMonitor) Violations() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	ret := make([]string, len(m.violations))
+	copy(ret, m.violations)
+	return ret
+}
+
+// Peaks returns the peak usage of each resource per node, indexed by the node index
+func (m *ResourceMonitor) Peaks() map[int]*ResourceUsage {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	ret := make(map[int]*ResourceUsage)
+	for i, u := range m.peak {
+		cp := *u
+		ret[i] = &cp
+	}
+	return ret
+}
+
+// maxUsage returns the usage with the maximum of each resource of both samples
+func maxUsage(a, b *ResourceUsage) *ResourceUsage {
+	if a == nil {
+		cp := *b
+		return &cp
+	}
+	ret := *a
+	ret.Time = b.Time
+	if b.CPUPercent > ret.CPUPercent {
+		ret.CPUPercent = b.CPUPercent
+	}
+	if b.RSS > ret.RSS {
+		ret.RSS = b.RSS
+	}
+	if b.HeapAlloc > ret.HeapAlloc {
+		ret.HeapAlloc = b.HeapAlloc
+	}
+	if b.Goroutines > ret.Goroutines {
+		ret.Goroutines = b.Goroutines
+	}
+	if b.DBSize > ret.DBSize {
+		ret.DBSize = b.DBSize
+	}
+	return &ret
+}
@@ -1,7 +1,14 @@
 package templates
 
 type GoshimmerConfigParams struct {
-	ApiPort int
+	ApiPort        int
+	WaspConnPort   int
+	DashboardPort  int
+	GossipPort     int
+	FPCPort        int
+	ProfilingPort  int
+	PrometheusPort int
+	AnalysisPort   int
 }
 
 const GoshimmerConfig = `
@@ -11,7 +18,7 @@ const GoshimmerConfig = `
       "serverAddress": "node1.goshimmer.dev:188"
     },
     "server": {
-      "bindAddress": "0.0.0.0:16178"
+      "bindAddress": "0.0.0.0:{{.AnalysisPort}}"
     },
     "dashboard": {
       "bindAddress": "0.0.0.0:80",
@@ -25,7 +32,7 @@ const GoshimmerConfig = `
     "port": 14626
   },
   "dashboard": {
-    "bindAddress": "127.0.0.1:8081",
+    "bindAddress": "127.0.0.1:{{.DashboardPort}}",
     "dev": false,
     "basic_auth": {
       "enabled": false,
@@ -72,10 +79,10 @@ const GoshimmerConfig = `
     }
   },
   "fpc": {
-    "bindAddress": "0.0.0.0:10895"
+    "bindAddress": "0.0.0.0:{{.FPCPort}}"
   },
   "gossip": {
-    "port": 14666,
+    "port": {{.GossipPort}},
     "ageThreshold": "5s",
     "tipsBroadcaster": {
       "interval": "10s"
@@ -113,10 +120,10 @@ const GoshimmerConfig = `
     "timeout": "1m"
   },
   "profiling": {
-    "bindAddress": "127.0.0.1:6061"
+    "bindAddress": "127.0.0.1:{{.ProfilingPort}}"
   },
   "prometheus": {
-    "bindAddress": "127.0.0.1:9311"
+    "bindAddress": "127.0.0.1:{{.PrometheusPort}}"
   },
   "webapi": {
     "auth": {
@@ -130,7 +137,7 @@ const GoshimmerConfig = `
     "originPublicKey": "9DB3j9cWYSuEEtkvanrzqkzCQMdH1FGv3TawJdVbDxkd"
   },
   "waspconn": {
-    "port": 5000,
+    "port": {{.WaspConnPort}},
     "utxodbenabled": true,
     "utxodbconfirmseconds": 0,
     "utxodbconfirmrandomize": false,
@@ -5,12 +5,15 @@ type WaspConfigParams struct {
 	DashboardPort int
 	PeeringPort   int
 	NanomsgPort   int
+	NodeConnPort  int
+	// if false, the node keeps its database in memory and loses it on restart
+	PersistentDatabase bool
 }
 
 const WaspConfig = `
 {
   "database": {
-    "inMemory": true,
+    "inMemory": {{not .PersistentDatabase}},
     "directory": "waspdb"
   },
   "logger": {
@@ -43,7 +46,7 @@ const WaspConfig = `
     "netid": "127.0.0.1:{{.PeeringPort}}"
   },
   "nodeconn": {
-    "address": "127.0.0.1:5000"
+    "address": "127.0.0.1:{{.NodeConnPort}}"
   },
   "nanomsg":{
     "port": {{.NanomsgPort}}
@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	"github.com/iotaledger/wasp/packages/apilib"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrossChainRequest(t *testing.T) {
+	setupShared(t)
+
+	committees, err := clu.DisjointCommittees(2)
+	check(err, t)
+	chains, err := clu.DeployChains(committees)
+	check(err, t)
+	require.Len(t, chains, 2)
+	source, target := chains[0], chains[1]
+
+	name := "inc"
+	chain = source
+	sourceContractID := deployInccounter42(t, name, 42)
+	chain = target
+	deployInccounter42(t, name, 42)
+
+	testOwner := testutil.NewRandomWallet()
+	err = requestFunds(clu, testOwner.Address(), "testOwner")
+	check(err, t)
+
+	_, stateTxID, err := clu.PostCrossChainRequest(testOwner.SigScheme(), source, target,
+		apilib.RequestSectionParams{
+			TargetContractID: sourceContractID,
+			EntryPointCode:   coretypes.Hn(inccounter.FuncIncOnChain),
+			Transfer:         cbalances.NewIotasOnly(1),
+			Args: requestargs.New().AddEncodeSimpleMany(codec.MakeDict(map[string]interface{}{
+				inccounter.VarChainID: target.ChainID,
+			})),
+		}, 1, 30*time.Second)
+	check(err, t)
+	require.NotNil(t, stateTxID)
+
+	// the counter is only incremented on the target chain
+	chain = source
+	expectCounter(t, sourceContractID.Hname(), 42)
+	chain = target
+	expectCounter(t, sourceContractID.Hname(), 43)
+}
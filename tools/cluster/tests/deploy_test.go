@@ -18,7 +18,7 @@ import (
 )
 
 func TestDeployChain(t *testing.T) {
-	setup(t, "test_cluster")
+	setupShared(t)
 
 	counter, err := clu.StartMessageCounter(map[string]int{
 		"chainrec":            2,
@@ -54,7 +54,7 @@ func TestDeployChain(t *testing.T) {
 }
 
 func TestDeployContractOnly(t *testing.T) {
-	setup(t, "test_cluster")
+	setupShared(t)
 
 	counter, err := clu.StartMessageCounter(map[string]int{
 		"chainrec":            2,
@@ -127,7 +127,7 @@ func TestDeployContractOnly(t *testing.T) {
 }
 
 func TestDeployContractAndSpawn(t *testing.T) {
-	setup(t, "test_cluster")
+	setupShared(t)
 
 	counter, err := clu.StartMessageCounter(map[string]int{
 		"chainrec":            2,
@@ -0,0 +1,276 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/cbalances"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/tools/cluster"
+	"github.com/stretchr/testify/require"
+)
+
+// feeScenario builds an end-to-end fee and accounting scenario on the chain:
+// inccounter contracts are deployed with the given fee configurations, requests are posted
+// from several wallets and the accrual of the owner and validator fees is tracked.
+// verify checks the expected changes of balances against the accounts contract and the L1 ledger.
+//
+// Usage:
+//
+//	s := newFeeScenario(t, chain).withDefaultFees(1, 0).withContract("a", 0, 2).withWallets(2).build()
+//	s.post(0, "a", 10).post(1, "a", 1).verify()
+type feeScenario struct {
+	t                   *testing.T
+	chain               *cluster.Chain
+	defaultOwnerFee     int64
+	defaultValidatorFee int64
+	contracts           map[string]*feeContract
+	// order of deployment
+	contractNames []string
+	wallets       []*feeWallet
+	// iota balances of on-chain accounts after the scenario was built
+	before map[coretypes.AgentID]int64
+	// expected change of iota balances of on-chain accounts since the scenario was built
+	expected map[coretypes.AgentID]int64
+	built    bool
+}
+
+type feeContract struct {
+	name         string
+	hname        coretypes.Hname
+	ownerFee     int64
+	validatorFee int64
+}
+
+type feeWallet struct {
+	wallet *testutil.Wallet
+	client *chainclient.Client
+	// iotas spent by the wallet on L1 since it was funded
+	spent int64
+}
+
+func newFeeScenario(t *testing.T, chain *cluster.Chain) *feeScenario {
+	return &feeScenario{
+		t:         t,
+		chain:     chain,
+		contracts: make(map[string]*feeContract),
+		expected:  make(map[coretypes.AgentID]int64),
+	}
+}
+
+// withDefaultFees sets the default fees of the chain, applied to contracts without fees of their own
+func (s *feeScenario) withDefaultFees(ownerFee, validatorFee int64) *feeScenario {
+	require.False(s.t, s.built, "scenario already built")
+	s.defaultOwnerFee = ownerFee
+	s.defaultValidatorFee = validatorFee
+	return s
+}
+
+// withContract adds the inccounter contract with the given fees. Zero fee means the default fee of the chain applies
+func (s *feeScenario) withContract(name string, ownerFee, validatorFee int64) *feeScenario {
+	require.False(s.t, s.built, "scenario already built")
+	_, exists := s.contracts[name]
+	require.False(s.t, exists, "duplicate contract '%s'", name)
+	s.contracts[name] = &feeContract{
+		name:         name,
+		hname:        coretypes.Hn(name),
+		ownerFee:     ownerFee,
+		validatorFee: validatorFee,
+	}
+	s.contractNames = append(s.contractNames, name)
+	return s
+}
+
+// withWallets adds n wallets with fresh random addresses, funded on L1 when the scenario is built
+func (s *feeScenario) withWallets(n int) *feeScenario {
+	require.False(s.t, s.built, "scenario already built")
+	for i := 0; i < n; i++ {
+		w := testutil.NewRandomWallet()
+		s.wallets = append(s.wallets, &feeWallet{
+			wallet: w,
+			client: s.chain.Client(w.SigScheme()),
+		})
+	}
+	return s
+}
+
+// build configures the fees of the chain, deploys the contracts and funds the wallets.
+// Balances of on-chain accounts are recorded, so verify checks only changes made by posted requests
+func (s *feeScenario) build() *feeScenario {
+	require.False(s.t, s.built, "scenario already built")
+
+	if s.defaultOwnerFee != 0 || s.defaultValidatorFee != 0 {
+		s.postByChainOwner(root.FuncSetDefaultFee, map[string]interface{}{
+			root.ParamOwnerFee:     s.defaultOwnerFee,
+			root.ParamValidatorFee: s.defaultValidatorFee,
+		})
+	}
+	for _, name := range s.contractNames {
+		c := s.contracts[name]
+		_, err := s.chain.DeployContract(name, inccounter.Interface.ProgramHash.String(), "fee scenario: "+name, map[string]interface{}{
+			inccounter.VarCounter: 0,
+		})
+		check(err, s.t)
+		if c.ownerFee != 0 || c.validatorFee != 0 {
+			s.postByChainOwner(root.FuncSetContractFee, map[string]interface{}{
+				root.ParamHname:        c.hname,
+				root.ParamOwnerFee:     c.ownerFee,
+				root.ParamValidatorFee: c.validatorFee,
+			})
+		}
+		s.checkFeeInfo(c)
+	}
+	for i, w := range s.wallets {
+		err := requestFunds(s.chain.Cluster, w.wallet.Address(), fmt.Sprintf("fee scenario wallet #%d", i))
+		check(err, s.t)
+	}
+
+	s.before = make(map[coretypes.AgentID]int64)
+	for agentID, bals := range getBalancesOnChain(s.t, s.chain) {
+		s.before[agentID] = bals[balance.ColorIOTA]
+	}
+	s.built = true
+	return s
+}
+
+// post posts the request to increment the counter from the wallet with the given index, transferring the iotas,
+// waits until it is processed and records the expected accruals
+func (s *feeScenario) post(walletIndex int, contractName string, iotas int64) *feeScenario {
+	require.True(s.t, s.built, "scenario not built")
+	require.True(s.t, walletIndex >= 0 && walletIndex < len(s.wallets), "wrong wallet index %d", walletIndex)
+	c, ok := s.contracts[contractName]
+	require.True(s.t, ok, "unknown contract '%s'", contractName)
+	w := s.wallets[walletIndex]
+
+	params := chainclient.PostRequestParams{}
+	if iotas > 0 {
+		params.Transfer = cbalances.NewIotasOnly(iotas)
+	}
+	tx, err := w.client.PostRequest(c.hname, coretypes.Hn(inccounter.FuncIncCounter), params)
+	check(err, s.t)
+	err = s.chain.CommitteeMultiClient().WaitUntilAllRequestsProcessed(tx, 30*time.Second)
+	check(err, s.t)
+
+	// the request token always accrues to the sender
+	sender := coretypes.NewAgentIDFromAddress(*w.wallet.Address())
+	s.expected[sender]++
+	w.spent += 1 + iotas
+
+	ownerFee, validatorFee := s.effectiveFees(c)
+	totalFee := ownerFee + validatorFee
+	if iotas < totalFee {
+		// not enough fees: the whole transfer is accrued to the sender
+		s.expected[sender] += iotas
+		return s
+	}
+	s.expected[*s.chain.OriginatorID()] += ownerFee
+	s.expected[s.validatorFeeTarget()] += validatorFee
+	s.expected[s.contractAgentID(c)] += iotas - totalFee
+	return s
+}
+
+// verify checks the changes of on-chain balances since the scenario was built, the L1 balances of
+// the wallets and that the L1 balance of the chain address is consistent with the accounts contract
+func (s *feeScenario) verify() {
+	require.True(s.t, s.built, "scenario not built")
+
+	after := getBalancesOnChain(s.t, s.chain)
+	for agentID, delta := range s.expected {
+		require.EqualValues(s.t, s.before[agentID]+delta, after[agentID][balance.ColorIOTA],
+			"iota balance of %s", agentID.String())
+	}
+	for agentID, bals := range after {
+		if _, ok := s.expected[agentID]; ok {
+			continue
+		}
+		require.EqualValues(s.t, s.before[agentID], bals[balance.ColorIOTA],
+			"unexpected change of iota balance of %s", agentID.String())
+	}
+
+	for i, w := range s.wallets {
+		remaining := testutil.RequestFundsAmount - w.spent
+		if !s.chain.Cluster.VerifyAddressBalances(w.wallet.Address(), remaining, map[balance.Color]int64{
+			balance.ColorIOTA: remaining,
+		}, fmt.Sprintf("fee scenario wallet #%d", i)) {
+			s.t.Fail()
+		}
+	}
+
+	totalIotas := getTotalBalance(s.t, s.chain)[balance.ColorIOTA]
+	if !s.chain.Cluster.VerifyAddressBalances(&s.chain.Address, totalIotas+1, map[balance.Color]int64{
+		balance.ColorIOTA: totalIotas,
+		s.chain.Color:     1,
+	}, "fee scenario chain") {
+		s.t.Fail()
+	}
+	checkLedger(s.t, s.chain)
+}
+
+// effectiveFees returns the fees charged for requests to the contract, taking the default fees into account
+func (s *feeScenario) effectiveFees(c *feeContract) (int64, int64) {
+	ownerFee, validatorFee := c.ownerFee, c.validatorFee
+	if ownerFee == 0 {
+		ownerFee = s.defaultOwnerFee
+	}
+	if validatorFee == 0 {
+		validatorFee = s.defaultValidatorFee
+	}
+	return ownerFee, validatorFee
+}
+
+// validatorFeeTarget is the agent validator fees are accrued to.
+// Currently all nodes accrue validator fees to the accounts contract of the chain
+func (s *feeScenario) validatorFeeTarget() coretypes.AgentID {
+	return coretypes.NewAgentIDFromContractID(s.chain.ContractID(accounts.Interface.Hname()))
+}
+
+func (s *feeScenario) contractAgentID(c *feeContract) coretypes.AgentID {
+	return coretypes.NewAgentIDFromContractID(s.chain.ContractID(c.hname))
+}
+
+func (s *feeScenario) postByChainOwner(funcName string, params map[string]interface{}) {
+	tx, err := s.chain.OriginatorClient().PostRequest(
+		root.Interface.Hname(),
+		coretypes.Hn(funcName),
+		chainclient.PostRequestParams{
+			Args: requestargs.New().AddEncodeSimpleMany(codec.MakeDict(params)),
+		},
+	)
+	check(err, s.t)
+	err = s.chain.CommitteeMultiClient().WaitUntilAllRequestsProcessed(tx, 30*time.Second)
+	check(err, s.t)
+}
+
+// checkFeeInfo checks the fees of the contract reported by the root contract
+func (s *feeScenario) checkFeeInfo(c *feeContract) {
+	ret, err := s.chain.Cluster.WaspClient(0).CallView(
+		s.chain.ContractID(root.Interface.Hname()),
+		root.FuncGetFeeInfo,
+		dict.FromGoMap(map[kv.Key][]byte{
+			root.ParamHname: c.hname.Bytes(),
+		}),
+	)
+	check(err, s.t)
+
+	ownerFee, validatorFee := s.effectiveFees(c)
+	feeColor, _, err := codec.DecodeColor(ret.MustGet(root.ParamFeeColor))
+	check(err, s.t)
+	require.EqualValues(s.t, balance.ColorIOTA, feeColor)
+	actual, _, err := codec.DecodeInt64(ret.MustGet(root.ParamOwnerFee))
+	check(err, s.t)
+	require.EqualValues(s.t, ownerFee, actual)
+	actual, _, err = codec.DecodeInt64(ret.MustGet(root.ParamValidatorFee))
+	check(err, s.t)
+	require.EqualValues(s.t, validatorFee, actual)
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"testing"
+
+	clutest "github.com/iotaledger/wasp/tools/cluster/testutil"
+)
+
+func TestFeesNone(t *testing.T) {
+	_, chain := clutest.AcquireWithChain(t)
+
+	newFeeScenario(t, chain).
+		withContract("nofees", 0, 0).
+		withWallets(2).
+		build().
+		post(0, "nofees", 0).
+		post(1, "nofees", 10).
+		verify()
+}
+
+func TestFeesContractOwnerAndValidator(t *testing.T) {
+	_, chain := clutest.AcquireWithChain(t)
+
+	newFeeScenario(t, chain).
+		withContract("ownerfee", 5, 0).
+		withContract("validatorfee", 0, 3).
+		withContract("bothfees", 4, 2).
+		withWallets(3).
+		build().
+		post(0, "ownerfee", 5).
+		post(1, "ownerfee", 12).
+		post(1, "validatorfee", 3).
+		post(2, "validatorfee", 10).
+		post(0, "bothfees", 6).
+		post(2, "bothfees", 20).
+		verify()
+}
+
+func TestFeesDefault(t *testing.T) {
+	_, chain := clutest.AcquireWithChain(t)
+
+	newFeeScenario(t, chain).
+		withDefaultFees(2, 1).
+		withContract("default", 0, 0).
+		withContract("override", 7, 0).
+		withWallets(2).
+		build().
+		post(0, "default", 3).
+		post(1, "default", 10).
+		post(0, "override", 8).
+		post(1, "override", 30).
+		verify()
+}
+
+func TestFeesNotEnough(t *testing.T) {
+	_, chain := clutest.AcquireWithChain(t)
+
+	newFeeScenario(t, chain).
+		withContract("expensive", 10, 5).
+		withWallets(2).
+		build().
+		post(0, "expensive", 0).
+		post(0, "expensive", 14).
+		post(1, "expensive", 15).
+		post(1, "expensive", 3).
+		verify()
+}
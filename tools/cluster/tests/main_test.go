@@ -0,0 +1,15 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	clutest "github.com/iotaledger/wasp/tools/cluster/testutil"
+)
+
+func TestMain(m *testing.M) {
+	code := m.Run()
+	// stop the cluster shared between the tests, if any
+	clutest.Shutdown()
+	os.Exit(code)
+}
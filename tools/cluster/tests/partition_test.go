@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/client/multiclient"
+	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	clutest "github.com/iotaledger/wasp/tools/cluster/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPartitionNoQuorum splits the committee in two halves, none of them having the quorum.
+// The chain stalls during the partition and resumes after it is healed
+func TestPartitionNoQuorum(t *testing.T) {
+	clu = clutest.NewCluster(t)
+
+	chain, err = clu.DeployDefaultChain()
+	check(err, t)
+
+	name := "inc"
+	contractID := deployInccounter42(t, name, 42)
+
+	testOwner := wallet.WithIndex(1)
+	err = requestFunds(clu, testOwner.Address(), "testOwner")
+	check(err, t)
+
+	err = clu.Partition([]int{0, 1}, []int{2, 3})
+	check(err, t)
+
+	myClient := chain.SCClient(contractID.Hname(), testOwner.SigScheme())
+	tx, err := myClient.PostRequest(inccounter.FuncIncCounter)
+	check(err, t)
+
+	err = chain.CommitteeMultiClient().WaitUntilAllRequestsProcessed(tx, 10*time.Second)
+	require.Error(t, err, "request processed without the quorum")
+
+	err = clu.HealPartition()
+	check(err, t)
+
+	err = chain.CommitteeMultiClient().WaitUntilAllRequestsProcessed(tx, 60*time.Second)
+	check(err, t)
+	expectCounter(t, contractID.Hname(), 43)
+	require.NoError(t, chain.VerifyNoDivergence())
+}
+
+// TestPartitionMinority cuts off one committee node. The rest of the committee keeps producing blocks,
+// the node catches up after the partition is healed
+func TestPartitionMinority(t *testing.T) {
+	clu = clutest.NewCluster(t)
+
+	chain, err = clu.DeployDefaultChain()
+	check(err, t)
+
+	name := "inc"
+	contractID := deployInccounter42(t, name, 42)
+
+	testOwner := wallet.WithIndex(1)
+	err = requestFunds(clu, testOwner.Address(), "testOwner")
+	check(err, t)
+
+	err = clu.Partition([]int{0, 1, 2}, []int{3})
+	check(err, t)
+
+	myClient := chain.SCClient(contractID.Hname(), testOwner.SigScheme())
+	tx, err := myClient.PostRequest(inccounter.FuncIncCounter)
+	check(err, t)
+	majority := multiclient.New(clu.Config.ApiHosts([]int{0, 1, 2}))
+	err = majority.WaitUntilAllRequestsProcessed(tx, 30*time.Second)
+	check(err, t)
+
+	err = clu.HealPartition()
+	check(err, t)
+
+	tx, err = myClient.PostRequest(inccounter.FuncIncCounter)
+	check(err, t)
+	err = chain.CommitteeMultiClient().WaitUntilAllRequestsProcessed(tx, 60*time.Second)
+	check(err, t)
+	expectCounter(t, contractID.Hname(), 44)
+	require.NoError(t, chain.VerifyNoDivergence())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
 	"github.com/iotaledger/wasp/client/chainclient"
 	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	"github.com/iotaledger/wasp/packages/apilib"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
@@ -94,7 +95,7 @@ func getCounter(t *testing.T, hname coretypes.Hname) int64 {
 }
 
 func TestPostDeployInccounter(t *testing.T) {
-	setup(t, "test_cluster")
+	setupShared(t)
 
 	chain, err = clu.DeployDefaultChain()
 	check(err, t)
@@ -105,7 +106,7 @@ func TestPostDeployInccounter(t *testing.T) {
 }
 
 func TestPost1Request(t *testing.T) {
-	setup(t, "test_cluster")
+	setupShared(t)
 
 	chain, err = clu.DeployDefaultChain()
 	check(err, t)
@@ -114,7 +115,7 @@ func TestPost1Request(t *testing.T) {
 	contractID := deployInccounter42(t, name, 42)
 	t.Logf("-------------- deployed contract. Name: '%s' id: %s", name, contractID.String())
 
-	testOwner := wallet.WithIndex(1)
+	testOwner := testutil.NewRandomWallet()
 	mySigScheme := testOwner.SigScheme()
 	myAddress := testOwner.Address()
 	err = requestFunds(clu, myAddress, "myAddress")
@@ -132,7 +133,7 @@ func TestPost1Request(t *testing.T) {
 }
 
 func TestPost3Recursive(t *testing.T) {
-	setup(t, "test_cluster")
+	setupShared(t)
 
 	chain, err = clu.DeployDefaultChain()
 	check(err, t)
@@ -141,7 +142,7 @@ func TestPost3Recursive(t *testing.T) {
 	contractID := deployInccounter42(t, name, 42)
 	t.Logf("-------------- deployed contract. Name: '%s' id: %s", name, contractID.String())
 
-	testOwner := wallet.WithIndex(1)
+	testOwner := testutil.NewRandomWallet()
 	mySigScheme := testOwner.SigScheme()
 	myAddress := testOwner.Address()
 	err = requestFunds(clu, myAddress, "myAddress")
@@ -245,3 +246,49 @@ func TestPost5AsyncRequests(t *testing.T) {
 	}
 	checkLedger(t, chain)
 }
+
+func TestPostMultiChainRequests(t *testing.T) {
+	setupShared(t)
+
+	chain1, err := clu.DeployDefaultChain()
+	check(err, t)
+	chain2, err := clu.DeployDefaultChain()
+	check(err, t)
+
+	name := "inc"
+	chain = chain1
+	contractID1 := deployInccounter42(t, name, 42)
+	chain = chain2
+	contractID2 := deployInccounter42(t, name, 42)
+
+	testOwner := testutil.NewRandomWallet()
+	mySigScheme := testOwner.SigScheme()
+	myAddress := testOwner.Address()
+	err = requestFunds(clu, myAddress, "myAddress")
+	check(err, t)
+
+	tx, err := clu.PostRequestsMultiChain(mySigScheme,
+		apilib.RequestSectionParams{
+			TargetContractID: contractID1,
+			EntryPointCode:   coretypes.Hn(inccounter.FuncIncCounter),
+		},
+		apilib.RequestSectionParams{
+			TargetContractID: contractID2,
+			EntryPointCode:   coretypes.Hn(inccounter.FuncIncCounter),
+		},
+		apilib.RequestSectionParams{
+			TargetContractID: contractID2,
+			EntryPointCode:   coretypes.Hn(inccounter.FuncIncCounter),
+		},
+	)
+	check(err, t)
+	require.Len(t, tx.RequestsByChain(), 2)
+
+	err = clu.WaitUntilRequestsProcessedByChains(tx, 30*time.Second, chain1, chain2)
+	check(err, t)
+
+	chain = chain1
+	expectCounter(t, contractID1.Hname(), 43)
+	chain = chain2
+	expectCounter(t, contractID2.Hname(), 44)
+}
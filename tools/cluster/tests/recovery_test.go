@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	waspchain "github.com/iotaledger/wasp/packages/chain"
+	"github.com/iotaledger/wasp/tools/cluster"
+	clutest "github.com/iotaledger/wasp/tools/cluster/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLeaderCrashRecovery crashes the leader right after it has persisted the first signature share of
+// a subordinate, i.e. after the result of the batch is calculated and before the quorum of shares is
+// collected for the finalization. After restart the leader resumes the round
+func TestLeaderCrashRecovery(t *testing.T) {
+	clu = clutest.NewCluster(t, func(config *cluster.ClusterConfig) {
+		config.Wasp.PersistentDatabase = true
+	})
+
+	chain, err = clu.DeployDefaultChain()
+	check(err, t)
+
+	name := "inc"
+	contractID := deployInccounter42(t, name, 42)
+
+	testOwner := wallet.WithIndex(1)
+	err = requestFunds(clu, testOwner.Address(), "testOwner")
+	check(err, t)
+
+	crashed, err := clu.CrashNodesAt(chain.CommitteeNodes, waspchain.CrashPointLeaderSigSharePersisted)
+	check(err, t)
+
+	myClient := chain.SCClient(contractID.Hname(), testOwner.SigScheme())
+	tx, err := myClient.PostRequest(inccounter.FuncIncCounter)
+	check(err, t)
+
+	var leader int
+	select {
+	case leader = <-crashed:
+	case <-time.After(30 * time.Second):
+		t.Fatal("leader has not crashed")
+	}
+	resumed := clu.NotifyOnLog([]int{leader}, "ROUND RESUMED")
+	err = clu.RestartNode(leader)
+	check(err, t)
+
+	select {
+	case <-resumed:
+	case <-time.After(30 * time.Second):
+		t.Fatalf("round was not resumed by the leader #%d", leader)
+	}
+	err = chain.CommitteeMultiClient().WaitUntilAllRequestsProcessed(tx, 60*time.Second)
+	check(err, t)
+
+	// the request is processed exactly once
+	expectCounter(t, contractID.Hname(), 43)
+	require.True(t, clu.IsNodeUp(leader))
+}
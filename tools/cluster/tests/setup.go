@@ -106,6 +106,12 @@ func setup(t *testing.T, configPath string) {
 	clu = clutest.NewCluster(t)
 }
 
+// setupShared is used by tests which deploy their own chains and fund only fresh addresses,
+// so they can run on the cluster shared with other tests
+func setupShared(t *testing.T) {
+	clu = clutest.Acquire(t)
+}
+
 func setupAndLoad(t *testing.T, name string, description string, nrOfRequests int, expectedMessages map[string]int) {
 	setup(t, "test_cluster")
 
@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/tools/cluster"
+	clutest "github.com/iotaledger/wasp/tools/cluster/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSharedClusterChainPerTest(t *testing.T) {
+	var clu1, clu2 *cluster.Cluster
+	var chainID1, chainID2 coretypes.ChainID
+
+	t.Run("first", func(t *testing.T) {
+		clu, chain := clutest.AcquireWithChain(t)
+		clu1 = clu
+		chainID1, _ = getChainInfo(t, chain)
+		require.EqualValues(t, chain.ChainID, chainID1)
+	})
+	t.Run("second", func(t *testing.T) {
+		clu, chain := clutest.AcquireWithChain(t)
+		clu2 = clu
+		chainID2, _ = getChainInfo(t, chain)
+		require.EqualValues(t, chain.ChainID, chainID2)
+	})
+	require.Same(t, clu1, clu2)
+	require.NotEqualValues(t, chainID1, chainID2)
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/contracts/native/inccounter"
+	"github.com/iotaledger/wasp/packages/chain/consensus"
+	"github.com/iotaledger/wasp/packages/testutil"
+	"github.com/iotaledger/wasp/tools/cluster"
+	clutest "github.com/iotaledger/wasp/tools/cluster/testutil"
+	"github.com/stretchr/testify/require"
+	"go.dedis.ch/kyber/v3/pairing"
+)
+
+// TestTranscriptReplay records transcripts of committee messages while processing a request and
+// replays the transcript of each committee node in the consensus operator alone
+func TestTranscriptReplay(t *testing.T) {
+	clu = clutest.NewCluster(t, func(config *cluster.ClusterConfig) {
+		config.Wasp.RecordTranscripts = true
+	})
+
+	chain, err = clu.DeployDefaultChain()
+	check(err, t)
+
+	name := "inc"
+	contractID := deployInccounter42(t, name, 42)
+
+	testOwner := wallet.WithIndex(1)
+	err = requestFunds(clu, testOwner.Address(), "testOwner")
+	check(err, t)
+
+	myClient := chain.SCClient(contractID.Hname(), testOwner.SigScheme())
+	tx, err := myClient.PostRequest(inccounter.FuncIncCounter)
+	check(err, t)
+	err = chain.CommitteeMultiClient().WaitUntilAllRequestsProcessed(tx, 30*time.Second)
+	check(err, t)
+	expectCounter(t, contractID.Hname(), 43)
+
+	suite := pairing.NewSuiteBn256()
+	for _, i := range chain.CommitteeNodes {
+		tr, err := chain.Transcript(i, 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, tr.Records)
+
+		res, err := consensus.Replay(tr, suite, testutil.NewLogger(t))
+		require.NoError(t, err)
+		require.Empty(t, res.Divergences, "node #%d", i)
+	}
+}
@@ -0,0 +1,48 @@
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/iotaledger/wasp/tools/cluster"
+)
+
+// ArtifactsEnvVar overrides the directory where artifacts of failed cluster tests are collected
+const ArtifactsEnvVar = "WASP_CLUSTER_ARTIFACTS"
+
+// only the tail of the timeline is printed, the whole timeline is in the artifact directory
+const timelinePrintLimit = 200
+
+// collectOnFailure registers the cleanup which collects the artifacts of the cluster when the test fails.
+// It must be registered after the cleanup which stops the cluster, so that it runs while the nodes are up
+func collectOnFailure(t *testing.T, clu *cluster.Cluster) {
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		dir := path.Join(artifactsDir(), t.Name())
+		fmt.Printf("[cluster] test %s failed, collecting artifacts into %s\n", t.Name(), dir)
+		timeline, err := clu.CollectArtifacts(dir)
+		if err != nil {
+			fmt.Printf("[cluster] failed to collect artifacts: %v\n", err)
+			return
+		}
+		from := 0
+		if len(timeline) > timelinePrintLimit {
+			from = len(timeline) - timelinePrintLimit
+			fmt.Printf("[cluster] ... %d earlier committee messages in %s\n", from, path.Join(dir, "timeline.txt"))
+		}
+		for _, l := range timeline[from:] {
+			fmt.Printf("[timeline] %s\n", l)
+		}
+	})
+}
+
+func artifactsDir() string {
+	if dir := os.Getenv(ArtifactsEnvVar); dir != "" {
+		return dir
+	}
+	return path.Join(os.TempDir(), "wasp-cluster-artifacts")
+}
@@ -0,0 +1,38 @@
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/iotaledger/wasp/tools/cluster"
+)
+
+// ResourcesEnvVar disables checking the resource budget of cluster nodes when set to "off",
+// for example when profiling the nodes
+const ResourcesEnvVar = "WASP_CLUSTER_RESOURCES"
+
+// resourceSamplePeriod is the period of sampling the resource usage of nodes during the test
+const resourceSamplePeriod = 2 * time.Second
+
+// monitorResources samples the resource usage of the nodes during the test and fails the test
+// if any node exceeds the budget of the cluster config. It must be registered after the cleanup
+// which stops the cluster, so that the last sample is taken while the nodes are up
+func monitorResources(t *testing.T, clu *cluster.Cluster) {
+	if os.Getenv(ResourcesEnvVar) == "off" {
+		return
+	}
+	monitor := clu.MonitorResources(resourceSamplePeriod, clu.Config.Resources)
+	t.Cleanup(func() {
+		monitor.Stop()
+		for i := 0; i < clu.Config.Wasp.NumNodes; i++ {
+			if u, ok := monitor.Peaks()[i]; ok {
+				fmt.Printf("[cluster] peak usage %s\n", u)
+			}
+		}
+		for _, v := range monitor.Violations() {
+			t.Errorf("resource budget exceeded: %s", v)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package testutil
+
+import (
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/iotaledger/wasp/tools/cluster"
+	"github.com/stretchr/testify/require"
+)
+
+// the cluster shared between tests of the test binary. Starting the cluster is the dominant
+// cost of the cluster tests, so tests which do not depend on the fresh L1 ledger can reuse it
+// and only deploy a new chain for each test
+var shared = struct {
+	sync.Mutex
+	clu  *cluster.Cluster
+	refs int
+	// ports of the cluster, released when it is stopped
+	ports *cluster.PortReservation
+	// the data directory is kept if any test using the cluster failed
+	failed bool
+	// stop the cluster when the last user releases it
+	stopOnRelease bool
+}{}
+
+// Acquire returns the shared cluster, starting it on the first use.
+// The cluster is released automatically when the test finishes, however it is kept running
+// for the following tests. Call Shutdown from TestMain to stop it.
+// Like clusters created by NewCluster, the shared cluster reserves its own ports and data directory
+func Acquire(t *testing.T) *cluster.Cluster {
+	if testing.Short() {
+		t.Skip("Skipping cluster test in short mode")
+	}
+
+	shared.Lock()
+	defer shared.Unlock()
+
+	if shared.clu == nil {
+		config := cluster.DefaultConfig()
+		ports, err := cluster.ReservePorts(config)
+		require.NoError(t, err)
+		clu := cluster.New("shared", config)
+
+		dataPath, err := ioutil.TempDir("", "wasp-cluster-shared-")
+		if err == nil {
+			err = clu.InitDataPath(".", dataPath, true)
+		}
+		if err == nil {
+			err = clu.Start(dataPath)
+		}
+		if err != nil {
+			clu.Stop()
+			ports.Release()
+			t.Fatal(err)
+		}
+
+		shared.clu = clu
+		shared.ports = ports
+		shared.failed = false
+		shared.stopOnRelease = false
+	}
+	shared.refs++
+	t.Cleanup(func() {
+		if t.Failed() {
+			shared.Lock()
+			shared.failed = true
+			shared.Unlock()
+		}
+		Release()
+	})
+	collectOnFailure(t, shared.clu)
+	monitorResources(t, shared.clu)
+	return shared.clu
+}
+
+// AcquireWithChain returns the shared cluster together with a new chain deployed
+// on all nodes of the cluster, so the test does not see state left by other tests
+func AcquireWithChain(t *testing.T) (*cluster.Cluster, *cluster.Chain) {
+	clu := Acquire(t)
+	chain, err := clu.DeployDefaultChain()
+	require.NoError(t, err)
+	return clu, chain
+}
+
+// Release decrements the number of users of the shared cluster. The cluster keeps running,
+// unless Shutdown was called while it was in use
+func Release() {
+	shared.Lock()
+	defer shared.Unlock()
+
+	if shared.refs == 0 {
+		panic("testutil.Release: shared cluster is not acquired")
+	}
+	shared.refs--
+	if shared.refs == 0 && shared.stopOnRelease {
+		stopShared()
+	}
+}
+
+// Shutdown stops the shared cluster if it is running.
+// If the cluster is still in use, it is stopped when the last user releases it
+func Shutdown() {
+	shared.Lock()
+	defer shared.Unlock()
+
+	if shared.refs > 0 {
+		shared.stopOnRelease = true
+		return
+	}
+	stopShared()
+}
+
+func stopShared() {
+	if shared.clu == nil {
+		return
+	}
+	shared.clu.Stop()
+	shared.ports.Release()
+	removeData(shared.clu.DataPath, shared.failed)
+	shared.clu = nil
+	shared.ports = nil
+	shared.stopOnRelease = false
+}
@@ -1,30 +1,58 @@
 package testutil
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
-	"path"
 	"testing"
 
 	"github.com/iotaledger/wasp/tools/cluster"
 	"github.com/stretchr/testify/require"
 )
 
-func NewCluster(t *testing.T) *cluster.Cluster {
+// NewCluster starts a new cluster for the test. The default config of the cluster may be adjusted with modifyConfig.
+// The cluster gets its own block of ports and its own data directory, so tests creating clusters may run
+// in parallel, also with the shared cluster and with tests of other packages. The data directory is removed
+// when the test succeeds and kept for inspection otherwise
+func NewCluster(t *testing.T, modifyConfig ...func(config *cluster.ClusterConfig)) *cluster.Cluster {
 	if testing.Short() {
 		t.Skip("Skipping cluster test in short mode")
 	}
 
 	config := cluster.DefaultConfig()
+	for _, modify := range modifyConfig {
+		modify(config)
+	}
+	ports, err := cluster.ReservePorts(config)
+	require.NoError(t, err)
+	t.Cleanup(ports.Release)
+
 	clu := cluster.New(t.Name(), config)
 
-	dataPath := path.Join(os.TempDir(), "wasp-cluster")
-	err := clu.InitDataPath(".", dataPath, true)
+	dataPath, err := ioutil.TempDir("", "wasp-cluster-")
+	require.NoError(t, err)
+	t.Cleanup(func() { removeData(dataPath, t.Failed()) })
+
+	err = clu.InitDataPath(".", dataPath, true)
 	require.NoError(t, err)
 
 	err = clu.Start(dataPath)
 	require.NoError(t, err)
 
 	t.Cleanup(clu.Stop)
+	collectOnFailure(t, clu)
+	monitorResources(t, clu)
 
 	return clu
 }
+
+// removeData removes the cluster data directory, unless a test using the cluster failed
+func removeData(dataPath string, failed bool) {
+	if failed {
+		fmt.Printf("[cluster] cluster data kept in %s\n", dataPath)
+		return
+	}
+	if err := os.RemoveAll(dataPath); err != nil {
+		fmt.Printf("[cluster] failed to remove cluster data: %v\n", err)
+	}
+}
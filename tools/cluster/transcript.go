@@ -0,0 +1,25 @@
+package cluster
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/iotaledger/wasp/packages/chain/transcript"
+)
+
+// transcriptsDir is the directory of transcripts in the directory of the node, see startWaspNode
+const transcriptsDir = "transcripts"
+
+// Transcript loads the transcript of committee messages of the chain recorded by the node.
+// Run is the number of the run of the chain on the node, starting from 0: each restart of the node
+// records a new transcript. Nodes record transcripts only if Wasp.RecordTranscripts is set in the config
+func (ch *Chain) Transcript(nodeIndex int, run int) (*transcript.Transcript, error) {
+	for i, n := range ch.CommitteeNodes {
+		if n != nodeIndex {
+			continue
+		}
+		dir := path.Join(waspNodeDataPath(ch.Cluster.DataPath, nodeIndex), transcriptsDir)
+		return transcript.Load(transcript.FileName(dir, &ch.ChainID, uint16(i), run))
+	}
+	return nil, fmt.Errorf("node #%d is not in the committee of the chain %s", nodeIndex, ch.ChainID.String())
+}
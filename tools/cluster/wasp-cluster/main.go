@@ -40,7 +40,10 @@ func main() {
 	commonFlags.IntVarP(&config.Wasp.FirstPeeringPort, "first-peering-port", "p", config.Wasp.FirstPeeringPort, "First wasp Peering port")
 	commonFlags.IntVarP(&config.Wasp.FirstNanomsgPort, "first-nanomsg-port", "u", config.Wasp.FirstNanomsgPort, "First wasp nanomsg (publisher) port")
 	commonFlags.IntVarP(&config.Wasp.FirstDashboardPort, "first-dashboard-port", "h", config.Wasp.FirstDashboardPort, "First wasp dashboard port")
+	commonFlags.BoolVarP(&config.Wasp.PersistentDatabase, "persistent-db", "", config.Wasp.PersistentDatabase, "If true, wasp nodes store their databases on disk")
+	commonFlags.BoolVarP(&config.Wasp.RecordTranscripts, "record-transcripts", "", config.Wasp.RecordTranscripts, "If true, wasp nodes record transcripts of committee messages (contain private key shares)")
 	commonFlags.IntVarP(&config.Goshimmer.ApiPort, "goshimmer-api-port", "w", config.Goshimmer.ApiPort, "Goshimmer API port")
+	commonFlags.IntVarP(&config.Goshimmer.WaspConnPort, "goshimmer-waspconn-port", "", config.Goshimmer.WaspConnPort, "Goshimmer port wasp nodes connect to")
 	commonFlags.BoolVarP(&config.Goshimmer.Provided, "goshimmer-provided", "g", config.Goshimmer.Provided, "If true, Goshimmer node will not be spawn")
 
 	if len(os.Args) < 2 {
@@ -0,0 +1,63 @@
+// program copies the Wasp node database to another storage engine, for example
+// from the default BadgerDB to Pebble. The node must be stopped while the database is migrated.
+// The target directory must be empty. After the migration the node config must be updated with
+// the new 'database.backend' and 'database.directory'
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/iotaledger/wasp/packages/dbprovider"
+	"go.uber.org/zap"
+)
+
+func main() {
+	fromBackend := flag.String("from-backend", dbprovider.BackendBadger, fmt.Sprintf("storage engine of the source database %v", dbprovider.Backends))
+	fromDir := flag.String("from", "waspdb", "directory of the source database")
+	toBackend := flag.String("to-backend", dbprovider.BackendPebble, fmt.Sprintf("storage engine of the target database %v", dbprovider.Backends))
+	toDir := flag.String("to", "", "directory of the target database")
+	flag.Parse()
+
+	if *toDir == "" || *toDir == *fromDir {
+		fmt.Printf("Usage: dbmigrate -from <dir> -to <other dir> [-from-backend <engine>] [-to-backend <engine>]\n")
+		os.Exit(1)
+	}
+	if entries, err := ioutil.ReadDir(*toDir); err == nil && len(entries) > 0 {
+		fmt.Printf("error: target directory %s is not empty\n", *toDir)
+		os.Exit(1)
+	}
+	if err := migrate(*fromBackend, *fromDir, *toBackend, *toDir); err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func migrate(fromBackend, fromDir, toBackend, toDir string) error {
+	zapLog, err := zap.NewDevelopment()
+	if err != nil {
+		return err
+	}
+	log := zapLog.Sugar()
+
+	src, err := dbprovider.NewDBProvider(fromBackend, fromDir, log.Named("from"))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := dbprovider.NewDBProvider(toBackend, toDir, log.Named("to"))
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	fmt.Printf("migrating %s (%s) --> %s (%s)\n", fromDir, fromBackend, toDir, toBackend)
+	n, err := dbprovider.Migrate(src, dst)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("copied %d key/value pairs\n", n)
+	return nil
+}
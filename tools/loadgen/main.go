@@ -0,0 +1,96 @@
+// program runs the workload of requests against the chain of a live cluster and reports latency percentiles
+// and throughput, see the package packages/loadgen. Senders of requests are derived from the seed and funded
+// by the faucet of Goshimmer. The report can be saved as JSON to compare runs of different versions.
+// Workloads against the Solo chain are run from Go tests, see the package packages/loadgen/solotarget
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/goshimmer/client/wallet/packages/seed"
+	"github.com/iotaledger/wasp/client/level1/goshimmer"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/loadgen"
+	"github.com/mr-tron/base58"
+)
+
+func main() {
+	goshimmerHost := flag.String("goshimmer", "127.0.0.1:8080", "host of the Goshimmer web API")
+	apiHosts := flag.String("wasp", "127.0.0.1:9090", "comma-separated hosts of the web API of committee nodes")
+	chain := flag.String("chain", "", "ID of the chain (base58)")
+	calls := flag.String("calls", "", "mix of requests: 'contract:entryPoint[:weight],...'")
+	transfer := flag.Int64("transfer", 0, "number of iotas transferred by each request")
+	senders := flag.Int("senders", 4, "number of senders of requests")
+	rate := flag.Float64("rate", 0, "requests per second. If 0, each sender posts the next request when the previous one is processed")
+	requests := flag.Int("n", 100, "total number of requests. If 0, requests are posted until -duration elapses")
+	duration := flag.Duration("duration", 0, "maximum duration of the run")
+	payload := flag.Int("payload", 0, "size of the random payload of each request in bytes")
+	seedB58 := flag.String("seed", "", "seed of senders (base58). If empty, a new seed is generated")
+	timeout := flag.Duration("timeout", loadgen.DefaultClusterTimeout, "time to wait until a request is processed")
+	out := flag.String("o", "", "if set, the report is saved to the file as JSON")
+	flag.Parse()
+
+	if *chain == "" || *calls == "" {
+		fmt.Printf("Usage: loadgen -chain <chain ID> -calls <contract:entryPoint[:weight],...> [options]\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	if err := run(*goshimmerHost, strings.Split(*apiHosts, ","), *chain, *seedB58, *senders, *timeout, &loadgen.Workload{
+		Rate:        *rate,
+		Requests:    *requests,
+		Duration:    *duration,
+		PayloadSize: *payload,
+		Seed:        time.Now().UnixNano(),
+	}, *calls, *transfer, *out); err != nil {
+		fmt.Printf("error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(goshimmerHost string, apiHosts []string, chain, seedB58 string, senders int, timeout time.Duration, w *loadgen.Workload, calls string, transfer int64, out string) error {
+	chainID, err := coretypes.NewChainIDFromString(chain)
+	if err != nil {
+		return err
+	}
+	w.Calls, err = loadgen.ParseCalls(calls)
+	if err != nil {
+		return err
+	}
+	for i := range w.Calls {
+		w.Calls[i].Transfer = transfer
+	}
+	senderSeed := seed.NewSeed()
+	if seedB58 != "" {
+		seedBytes, err := base58.Decode(seedB58)
+		if err != nil {
+			return fmt.Errorf("invalid seed: %v", err)
+		}
+		senderSeed = seed.NewSeed(seedBytes)
+	}
+
+	fmt.Printf("funding %d senders...\n", senders)
+	target, err := loadgen.NewClusterTarget(goshimmer.NewGoshimmerClient(goshimmerHost), apiHosts, chainID, senderSeed, senders, timeout)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("running the workload...\n")
+	report, err := loadgen.Run(target, w)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", report)
+	if out == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(out, data, 0644)
+}
@@ -3,22 +3,28 @@ package chain
 import (
 	"fmt"
 	"os"
+	"strconv"
 
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
 	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/client/chainclient"
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/packages/kv"
 	"github.com/iotaledger/wasp/packages/kv/codec"
 	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/sctransaction"
 	"github.com/iotaledger/wasp/packages/util"
 	"github.com/iotaledger/wasp/packages/vm/core/accounts"
 	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	cliutil "github.com/iotaledger/wasp/tools/wasp-cli/util"
+	"github.com/iotaledger/wasp/tools/wasp-cli/wallet"
 )
 
 func listAccountsCmd(args []string) {
 	ret, err := SCClient(accounts.Interface.Hname()).CallView(accounts.FuncAccounts, nil)
 	log.Check(err)
 
-	log.Printf("Total %d account(s) in chain %s\n", len(ret), GetCurrentChainID())
+	log.Printf("Total %d account(s) in chain %s\n", len(ret), GetCurrentChainID().Bech32())
 
 	header := []string{"agentid"}
 	rows := make([][]string, len(ret))
@@ -26,26 +32,31 @@ func listAccountsCmd(args []string) {
 	for k := range ret {
 		agentId, _, err := codec.DecodeAgentID([]byte(k))
 		log.Check(err)
-		rows[i] = []string{agentId.String()}
+		rows[i] = []string{agentId.Bech32()}
 		i++
 	}
 	log.PrintTable(header, rows)
 }
 
 func balanceCmd(args []string) {
-	if len(args) != 1 {
-		log.Usage("%s chain balance <agentid>\n", os.Args[0])
+	if len(args) > 1 {
+		log.Usage("%s chain balance [agentid]\n", os.Args[0])
 	}
 
-	agentID, err := coretypes.NewAgentIDFromString(args[0])
-	log.Check(err)
+	// by default, the account of the wallet address
+	agentID := coretypes.NewAgentIDFromAddress(wallet.Load().Address())
+	if len(args) == 1 {
+		var err error
+		agentID, err = coretypes.NewAgentIDFromString(args[0])
+		log.Check(err)
+	}
 
 	ret, err := SCClient(accounts.Interface.Hname()).CallView(accounts.FuncBalance, dict.FromGoMap(map[kv.Key][]byte{
 		accounts.ParamAgentID: agentID[:],
 	}))
 	log.Check(err)
 
-	header := []string{"color", "amount"}
+	header := []string{"color", "amount", "token"}
 	rows := make([][]string, len(ret))
 	i := 0
 	for k, v := range ret {
@@ -54,8 +65,103 @@ func balanceCmd(args []string) {
 		bal, err := util.Uint64From8Bytes(v)
 		log.Check(err)
 
-		rows[i] = []string{color.String(), fmt.Sprintf("%d", bal)}
+		token := ""
+		if meta := getTokenMetadata(color); meta != nil {
+			token = fmt.Sprintf("%s (%s)", meta.FormatAmount(int64(bal)), meta.Name)
+		}
+		rows[i] = []string{color.String(), fmt.Sprintf("%d", bal), token}
 		i++
 	}
 	log.PrintTable(header, rows)
 }
+
+// getTokenMetadata returns metadata of the colored token registered on the chain, or nil
+func getTokenMetadata(color balance.Color) *accounts.TokenMetadata {
+	if color == balance.ColorIOTA {
+		return nil
+	}
+	ret, err := SCClient(accounts.Interface.Hname()).CallView(accounts.FuncGetTokenMetadata, dict.FromGoMap(map[kv.Key][]byte{
+		accounts.ParamColor: color[:],
+	}))
+	log.Check(err)
+	data := ret.MustGet(accounts.ParamTokenMetadata)
+	if data == nil {
+		return nil
+	}
+	meta, err := accounts.DecodeTokenMetadata(data)
+	log.Check(err)
+	return meta
+}
+
+func registerTokenCmd(args []string) {
+	if len(args) < 3 || len(args) > 5 {
+		log.Usage("%s chain register-token <amount> <name> <symbol> [decimals] [supply-cap]\n", os.Args[0])
+	}
+	amount, err := strconv.ParseInt(args[0], 10, 64)
+	log.Check(err)
+	builder := accounts.Interface.NewArgsBuilder(accounts.FuncRegisterToken).
+		Set(accounts.ParamTokenName, args[1]).
+		Set(accounts.ParamTokenSymbol, args[2])
+	if len(args) > 3 {
+		decimals, err := strconv.ParseInt(args[3], 10, 64)
+		log.Check(err)
+		builder.Set(accounts.ParamTokenDecimals, decimals)
+	}
+	if len(args) > 4 {
+		supplyCap, err := strconv.ParseInt(args[4], 10, 64)
+		log.Check(err)
+		builder.Set(accounts.ParamTokenSupplyCap, supplyCap)
+	}
+	tokenArgs, err := Client().BuildArgs(builder)
+	log.Check(err)
+
+	// the token is minted to the wallet address by the same transaction which registers it
+	tx := cliutil.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return SCClient(accounts.Interface.Hname()).PostRequest(
+			accounts.FuncRegisterToken,
+			chainclient.PostRequestParams{
+				Args: tokenArgs,
+				Mint: map[address.Address]int64{wallet.Load().Address(): amount},
+			},
+		)
+	})
+	log.Printf("Color of the token: %s\n", tx.ID().String())
+}
+
+func harvestCmd(args []string) {
+	if len(args) > 1 {
+		log.Usage("%s chain harvest [min-amount]\n", os.Args[0])
+	}
+	minAmount := int64(1)
+	if len(args) == 1 {
+		var err error
+		minAmount, err = strconv.ParseInt(args[0], 10, 64)
+		log.Check(err)
+	}
+	harvestArgs, err := Client().BuildArgs(accounts.Interface.NewArgsBuilder(accounts.FuncHarvest).
+		Set(accounts.ParamMinAmount, minAmount))
+	log.Check(err)
+	cliutil.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return SCClient(accounts.Interface.Hname()).PostRequest(
+			accounts.FuncHarvest,
+			chainclient.PostRequestParams{Args: harvestArgs},
+		)
+	})
+}
+
+func transferAccountCmd(args []string) {
+	if len(args) != 1 {
+		log.Usage("%s chain transfer-account <agentid>\n", os.Args[0])
+	}
+	target, err := coretypes.NewAgentIDFromString(args[0])
+	log.Check(err)
+	transferArgs, err := Client().BuildArgs(accounts.Interface.NewArgsBuilder(accounts.FuncTransferAccount).
+		Set(accounts.ParamAgentID, target))
+	log.Check(err)
+	cliutil.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return SCClient(accounts.Interface.Hname()).PostRequest(
+			accounts.FuncTransferAccount,
+			chainclient.PostRequestParams{Args: transferArgs},
+		)
+	})
+}
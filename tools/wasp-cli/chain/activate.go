@@ -1,6 +1,15 @@
 package chain
 
-import "github.com/iotaledger/wasp/tools/wasp-cli/log"
+import (
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/spf13/pflag"
+)
+
+var drainWait bool
+
+func initDrainFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&drainWait, "wait", "", false, "wait until the round in progress is finished and the chain is dismissed")
+}
 
 func activateCmd(args []string) {
 	log.Check(MultiClient().ActivateChain(GetCurrentChainID()))
@@ -9,3 +18,9 @@ func activateCmd(args []string) {
 func deactivateCmd(args []string) {
 	log.Check(MultiClient().DeactivateChain(GetCurrentChainID()))
 }
+
+// drainCmd deactivates the chain gracefully: the nodes stop accepting requests and
+// dismiss the chain after the round in progress. The chain is reactivated with 'activate'
+func drainCmd(args []string) {
+	log.Check(MultiClient().DrainChain(GetCurrentChainID(), drainWait))
+}
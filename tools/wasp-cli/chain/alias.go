@@ -4,6 +4,7 @@ import (
 	"github.com/iotaledger/wasp/packages/coretypes"
 	"github.com/iotaledger/wasp/tools/wasp-cli/config"
 	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	cliutil "github.com/iotaledger/wasp/tools/wasp-cli/util"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -31,10 +32,13 @@ func initAliasFlags(flags *pflag.FlagSet) {
 func AddChainAlias(chainAlias string, id string) {
 	config.Set("chains."+chainAlias, id)
 	SetCurrentChain(chainAlias)
+	chainID, err := coretypes.NewChainIDFromString(id)
+	log.Check(err)
+	cliutil.TrackChain(chainID)
 }
 
 func GetCurrentChainID() coretypes.ChainID {
-	chid, err := coretypes.NewChainIDFromBase58(viper.GetString("chains." + GetChainAlias()))
+	chid, err := coretypes.NewChainIDFromString(viper.GetString("chains." + GetChainAlias()))
 	log.Check(err)
 	return chid
 }
@@ -22,8 +22,14 @@ func storeBlobCmd(args []string) {
 }
 
 func uploadBlob(fieldValues dict.Dict, forceWait bool) (hash hashing.HashValue) {
+	hosts := config.CommitteeApi(chainCommittee())
+	quorum := uploadQuorum
+	if quorum > len(hosts) {
+		// e.g. the single-node devnet
+		quorum = len(hosts)
+	}
 	util.WithSCTransaction(func() (tx *sctransaction.Transaction, err error) {
-		hash, tx, err = Client().UploadBlob(fieldValues, config.CommitteeApi(chainCommittee()), uploadQuorum)
+		hash, tx, err = Client().UploadBlob(fieldValues, hosts, quorum)
 		if err == nil {
 			log.Printf("uploaded blob to chain -- hash: %s", hash)
 		}
@@ -61,7 +67,7 @@ func listBlobsCmd(args []string) {
 	blobs, err := blob.DecodeSizesMap(ret)
 	log.Check(err)
 
-	log.Printf("Total %d blob(s) in chain %s\n", len(ret), GetCurrentChainID())
+	log.Printf("Total %d blob(s) in chain %s\n", len(ret), GetCurrentChainID().Bech32())
 
 	header := []string{"hash", "size"}
 	rows := make([][]string, len(ret))
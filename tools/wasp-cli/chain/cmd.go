@@ -15,25 +15,43 @@ func InitCommands(commands map[string]func([]string), flags *pflag.FlagSet) {
 	initDeployFlags(fs)
 	initUploadFlags(fs)
 	initAliasFlags(fs)
+	initEventsFlags(fs)
+	initDrainFlags(fs)
 	flags.AddFlagSet(fs)
 }
 
 var subcmds = map[string]func([]string){
-	"list":            listCmd,
-	"deploy":          deployCmd,
-	"info":            infoCmd,
-	"list-contracts":  listContractsCmd,
-	"deploy-contract": deployContractCmd,
-	"list-accounts":   listAccountsCmd,
-	"balance":         balanceCmd,
-	"list-blobs":      listBlobsCmd,
-	"store-blob":      storeBlobCmd,
-	"show-blob":       showBlobCmd,
-	"log":             logCmd,
-	"post-request":    postRequestCmd,
-	"call-view":       callViewCmd,
-	"activate":        activateCmd,
-	"deactivate":      deactivateCmd,
+	"list":             listCmd,
+	"deploy":           deployCmd,
+	"info":             infoCmd,
+	"list-contracts":   listContractsCmd,
+	"deploy-contract":  deployContractCmd,
+	"list-accounts":    listAccountsCmd,
+	"balance":          balanceCmd,
+	"harvest":          harvestCmd,
+	"transfer-account": transferAccountCmd,
+	"register-token":   registerTokenCmd,
+	"list-blobs":       listBlobsCmd,
+	"store-blob":       storeBlobCmd,
+	"show-blob":        showBlobCmd,
+	"log":              logCmd,
+	"events":           eventsCmd,
+	"post-request":     postRequestCmd,
+	"call-view":        callViewCmd,
+	"repl":             replCmd,
+	"activate":         activateCmd,
+	"deactivate":       deactivateCmd,
+	"drain":            drainCmd,
+	"emergency":        emergencyCmd,
+	"emergency-log":    emergencyRecordsCmd,
+	"export-blocks":    exportBlocksCmd,
+	"metadata":         metadataCmd,
+	"set-metadata":     setMetadataCmd,
+	"deposit":          depositCmd,
+	"set-deposit":      setDepositCmd,
+	"evidence":         evidenceCmd,
+	"post-evidence":    postEvidenceCmd,
+	"reshare":          reshareCmd,
 }
 
 func chainCmd(args []string) {
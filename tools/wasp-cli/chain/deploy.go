@@ -2,7 +2,9 @@ package chain
 
 import (
 	"os"
+	"strconv"
 
+	"github.com/iotaledger/wasp/client"
 	"github.com/iotaledger/wasp/packages/apilib"
 	"github.com/iotaledger/wasp/tools/wasp-cli/config"
 	"github.com/iotaledger/wasp/tools/wasp-cli/log"
@@ -10,25 +12,47 @@ import (
 	"github.com/spf13/pflag"
 )
 
-var committee []int
+var committee []string
+var accessNodes []string
 var quorum int
 var description string
 
 func initDeployFlags(flags *pflag.FlagSet) {
-	flags.IntSliceVarP(&committee, "committee", "", []int{0, 1, 2, 3}, "committee indices")
-	flags.IntVarP(&quorum, "quorum", "", 3, "quorum")
+	flags.StringSliceVarP(&committee, "committee", "", []string{"0", "1", "2", "3"},
+		"committee nodes: indices of nodes in the config or API addresses (host:port)")
+	flags.StringSliceVarP(&accessNodes, "access-nodes", "", nil,
+		"access nodes which follow the chain without taking part in the consensus: indices of nodes in the config or API addresses (host:port)")
+	flags.IntVarP(&quorum, "quorum", "", 0, "quorum (default: 2/3 of the committee + 1)")
 	flags.StringVarP(&description, "description", "", "", "description")
 }
 
+// deployCmd runs DKG on the committee nodes, posts origin and init transactions of the new chain,
+// activates the chain on the committee and access nodes and prints the chain record
 func deployCmd(args []string) {
 	alias := GetChainAlias()
 
+	apiHosts, peeringHosts := resolveCommittee(committee)
+	var accessApiHosts, accessPeeringHosts []string
+	if len(accessNodes) > 0 {
+		accessApiHosts, accessPeeringHosts = resolveCommittee(accessNodes)
+	}
+	n := len(apiHosts)
+	t := quorum
+	if t == 0 {
+		t = defaultQuorum(n)
+	}
+	if t < 1 || t > n {
+		log.Fatal("quorum must be between 1 and the committee size %d", n)
+	}
+
 	chainid, _, _, err := apilib.DeployChain(apilib.CreateChainParams{
 		Node:                  config.GoshimmerClient(),
-		CommitteeApiHosts:     config.CommitteeApi(committee),
-		CommitteePeeringHosts: config.CommitteePeering(committee),
-		N:                     uint16(len(committee)),
-		T:                     uint16(quorum),
+		CommitteeApiHosts:     apiHosts,
+		CommitteePeeringHosts: peeringHosts,
+		AccessApiHosts:        accessApiHosts,
+		AccessPeeringHosts:    accessPeeringHosts,
+		N:                     uint16(n),
+		T:                     uint16(t),
 		OriginatorSigScheme:   wallet.Load().SignatureScheme(),
 		Description:           description,
 		Textout:               os.Stdout,
@@ -36,5 +60,44 @@ func deployCmd(args []string) {
 	})
 	log.Check(err)
 
-	AddChainAlias(alias, chainid.String())
+	AddChainAlias(alias, chainid.Bech32())
+
+	chain, err := client.NewWaspClient(apiHosts[0]).GetChainRecord(*chainid)
+	log.Check(err)
+	log.Printf("Chain ID: %s\n", chain.ChainID.Bech32())
+	log.Printf("Committee nodes: %+v\n", chain.CommitteeNodes)
+	if len(chain.AccessNodes) > 0 {
+		log.Printf("Access nodes: %+v\n", chain.AccessNodes)
+	}
+	log.Printf("Quorum: %d\n", t)
+	log.Printf("Active: %v\n", chain.Active)
+}
+
+// resolveCommittee returns API and peering addresses of the committee (or access) nodes.
+// A node is given either by its index in the config, or by its API address. In the latter case
+// the peering address is queried from the node
+func resolveCommittee(nodes []string) ([]string, []string) {
+	if len(nodes) == 0 {
+		log.Fatal("committee is empty")
+	}
+	apiHosts := make([]string, len(nodes))
+	peeringHosts := make([]string, len(nodes))
+	for i, node := range nodes {
+		if index, err := strconv.Atoi(node); err == nil {
+			apiHosts[i] = config.CommitteeApi([]int{index})[0]
+			peeringHosts[i] = config.CommitteePeering([]int{index})[0]
+			continue
+		}
+		info, err := client.NewWaspClient(node).Info()
+		log.Check(err)
+		apiHosts[i] = node
+		peeringHosts[i] = info.NetworkId
+		log.Verbose("node %s: peering address %s\n", node, info.NetworkId)
+	}
+	return apiHosts, peeringHosts
+}
+
+// defaultQuorum is the smallest quorum which tolerates the maximum number of faulty nodes
+func defaultQuorum(n int) int {
+	return n - (n-1)/3
 }
@@ -0,0 +1,64 @@
+package chain
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	cliutil "github.com/iotaledger/wasp/tools/wasp-cli/util"
+)
+
+var depositPolicies = map[string]int64{
+	"refund":  root.DepositRefund,
+	"forfeit": root.DepositForfeit,
+}
+
+func depositPolicyName(policy int64) string {
+	for name, p := range depositPolicies {
+		if p == policy {
+			return name
+		}
+	}
+	return strconv.FormatInt(policy, 10)
+}
+
+func depositCmd(args []string) {
+	info, err := SCClient(root.Interface.Hname()).CallView(root.FuncGetDepositInfo, nil)
+	log.Check(err)
+
+	minDeposit, _, err := codec.DecodeInt64(info.MustGet(root.ParamMinDeposit))
+	log.Check(err)
+	policy, _, err := codec.DecodeInt64(info.MustGet(root.ParamDepositPolicy))
+	log.Check(err)
+	log.Printf("Request deposit: %d iotas (%s)\n", minDeposit, depositPolicyName(policy))
+}
+
+// setDepositCmd sets the deposit of iotas each request must carry, 0 removes the requirement.
+// The policy defines whether the deposit is refunded to the sender or forfeited to the chain owner
+func setDepositCmd(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		log.Usage("%s chain set-deposit <iotas> [refund|forfeit]\n", os.Args[0])
+	}
+	minDeposit, err := strconv.ParseInt(args[0], 10, 64)
+	log.Check(err)
+	depositArgs := requestargs.New()
+	depositArgs.AddEncodeSimple(root.ParamMinDeposit, codec.EncodeInt64(minDeposit))
+	if len(args) == 2 {
+		policy, ok := depositPolicies[args[1]]
+		if !ok {
+			log.Fatal("unknown deposit policy: %s", args[1])
+		}
+		depositArgs.AddEncodeSimple(root.ParamDepositPolicy, codec.EncodeInt64(policy))
+	}
+	cliutil.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return SCClient(root.Interface.Hname()).PostRequest(
+			root.FuncSetRequestIntakePolicy,
+			chainclient.PostRequestParams{Args: depositArgs},
+		)
+	})
+}
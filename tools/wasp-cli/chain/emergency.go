@@ -0,0 +1,35 @@
+package chain
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+)
+
+// emergencyCmd votes in all committee nodes to delegate block production of the chain to one node.
+// The emergency mode starts when the emergency quorum of nodes votes the same
+func emergencyCmd(args []string) {
+	if len(args) != 2 {
+		log.Usage("%s chain emergency <delegate-index> <num-blocks>\n", os.Args[0])
+	}
+	delegate, err := strconv.ParseUint(args[0], 10, 16)
+	log.Check(err)
+	numBlocks := parseBlockIndex(args[1])
+	log.Check(MultiClient().DeclareEmergency(GetCurrentChainID(), uint16(delegate), numBlocks))
+}
+
+func emergencyRecordsCmd(args []string) {
+	chainID := GetCurrentChainID()
+	records, err := config.WaspClient().GetEmergencyRecords(&chainID)
+	log.Check(err)
+	if len(records) == 0 {
+		log.Printf("no emergency records\n")
+		return
+	}
+	for _, rec := range records {
+		log.Printf("state #%d: blocks #%d..#%d delegated to #%d by %d votes\n",
+			rec.BlockIndex, rec.BlockIndex+1, rec.BlockIndex+rec.NumBlocks, rec.Delegate, len(rec.Votes))
+	}
+}
@@ -0,0 +1,49 @@
+package chain
+
+import (
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	cliutil "github.com/iotaledger/wasp/tools/wasp-cli/util"
+)
+
+// evidenceCmd lists the evidence of equivocating leaders recorded on the chain
+func evidenceCmd(args []string) {
+	ret, err := SCClient(root.Interface.Hname()).CallView(root.FuncGetEquivocations, nil)
+	log.Check(err)
+	arr := collections.NewArrayReadOnly(ret, root.ParamEvidence)
+	if arr.MustLen() == 0 {
+		log.Printf("no equivocation evidence\n")
+		return
+	}
+	for i := uint16(0); i < arr.MustLen(); i++ {
+		ev, err := root.DecodeEquivocationEvidence(arr.MustGetAt(i))
+		log.Check(err)
+		log.Printf("%s\n", ev.String())
+	}
+}
+
+// postEvidenceCmd submits the evidence of equivocating leaders found by the node to the chain
+func postEvidenceCmd(args []string) {
+	chainID := GetCurrentChainID()
+	lst, err := config.WaspClient().GetEquivocationEvidence(&chainID)
+	log.Check(err)
+	if len(lst) == 0 {
+		log.Printf("no equivocation evidence found by the node\n")
+		return
+	}
+	for _, ev := range lst {
+		evArgs := requestargs.New()
+		evArgs.AddEncodeSimple(root.ParamEvidence, ev.Evidence.Bytes())
+		cliutil.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+			return SCClient(root.Interface.Hname()).PostRequest(
+				root.FuncRecordEquivocation,
+				chainclient.PostRequestParams{Args: evArgs},
+			)
+		})
+	}
+}
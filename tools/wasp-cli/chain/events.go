@@ -0,0 +1,152 @@
+package chain
+
+import (
+	"encoding/hex"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv/collections"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/spf13/pflag"
+)
+
+var (
+	followEvents   bool
+	eventsContract string
+)
+
+func initEventsFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&followEvents, "follow", "f", false, "stream chain events in real time")
+	flags.StringVarP(&eventsContract, "contract", "", "", "show only events of the contract")
+}
+
+// eventsCmd prints the event log of the contract or, with --follow, streams state transitions,
+// requests and events of contracts of the chain until interrupted
+func eventsCmd(args []string) {
+	if !followEvents {
+		if eventsContract == "" {
+			log.Usage("%s chain events --follow [--contract <name>]\n       %s chain events --contract <name>\n", os.Args[0], os.Args[0])
+		}
+		logCmd([]string{eventsContract})
+		return
+	}
+
+	chainID := GetCurrentChainID()
+	names := contractNames()
+	filter := ""
+	if eventsContract != "" {
+		filter = coretypes.Hn(eventsContract).String()
+	}
+
+	done := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		close(done)
+	}()
+
+	msgs, err := config.WaspClient().ChainEvents(&chainID, done)
+	log.Check(err)
+	log.Printf("streaming events of chain %s. Press Ctrl+C to stop\n", chainID.Bech32())
+	for msg := range msgs {
+		if filter != "" && !isContractEvent(msg, filter) {
+			continue
+		}
+		if s, ok := formatEvent(msg, names); ok {
+			log.Printf("%s %s\n", time.Now().Format("15:04:05"), s)
+		}
+	}
+}
+
+// contractNames returns names of contracts deployed on the chain by hname
+func contractNames() map[string]string {
+	info, err := SCClient(root.Interface.Hname()).CallView(root.FuncGetChainInfo, nil)
+	log.Check(err)
+	contracts, err := root.DecodeContractRegistry(collections.NewMapReadOnly(info, root.VarContractRegistry))
+	log.Check(err)
+	ret := make(map[string]string)
+	for hname, rec := range contracts {
+		ret[hname.String()] = rec.Name
+	}
+	return ret
+}
+
+func isContractEvent(msg *model.ChainEvent, hname string) bool {
+	return (msg.Type == "vmmsg" || msg.Type == "vmevent") && len(msg.Parts) > 0 && msg.Parts[0] == hname
+}
+
+// formatEvent decodes the message in the human readable form. Returns false for unknown or malformed messages
+func formatEvent(msg *model.ChainEvent, names map[string]string) (string, bool) {
+	p := msg.Parts
+	switch msg.Type {
+	case "state":
+		// block index, block size, state tx ID, state hash, timestamp
+		if len(p) < 4 {
+			return "", false
+		}
+		return "state #" + p[0] + ": " + p[1] + " request(s), tx " + p[2] + ", state hash " + p[3], true
+	case "request_in":
+		// tx ID, request index
+		if len(p) < 2 {
+			return "", false
+		}
+		return "request received: " + p[0] + "[" + p[1] + "]", true
+	case "request_out":
+		// tx ID, request index, block index, index in block, block size
+		if len(p) < 5 {
+			return "", false
+		}
+		pos, _ := strconv.Atoi(p[3])
+		return "request processed: " + p[0] + "[" + p[1] + "] in block #" + p[2] +
+			" (" + strconv.Itoa(pos+1) + "/" + p[4] + ")", true
+	case "vmmsg":
+		// contract hname, message
+		if len(p) < 2 {
+			return "", false
+		}
+		return contractName(p[0], names) + ": " + strings.Join(p[1:], " "), true
+	case "vmevent":
+		// contract hname, topic, hex encoded fields key=value
+		if len(p) < 2 {
+			return "", false
+		}
+		fields := make([]string, 0, len(p)-2)
+		for _, f := range p[2:] {
+			fields = append(fields, decodeEventField(f))
+		}
+		return contractName(p[0], names) + ": event '" + p[1] + "' " + strings.Join(fields, " "), true
+	}
+	return "", false
+}
+
+func contractName(hname string, names map[string]string) string {
+	if name, ok := names[hname]; ok {
+		return name
+	}
+	return hname
+}
+
+// decodeEventField decodes the hex encoded value of the field. Printable values are shown as strings
+func decodeEventField(f string) string {
+	kv := strings.SplitN(f, "=", 2)
+	if len(kv) != 2 {
+		return f
+	}
+	value, err := hex.DecodeString(kv[1])
+	if err != nil {
+		return f
+	}
+	if utf8.Valid(value) && strings.IndexFunc(string(value), func(r rune) bool { return r < ' ' }) < 0 {
+		return kv[0] + "=" + strconv.Quote(string(value))
+	}
+	return kv[0] + "=0x" + kv[1]
+}
@@ -0,0 +1,37 @@
+package chain
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+)
+
+// exportBlocksCmd saves blocks of the chain into the file, to be replayed in Solo with ImportChainFromFile
+func exportBlocksCmd(args []string) {
+	if len(args) < 1 || len(args) > 3 {
+		log.Usage("%s chain export-blocks <file> [from] [to]\n", os.Args[0])
+	}
+	from := uint32(0)
+	var to *uint32
+	if len(args) > 1 {
+		from = parseBlockIndex(args[1])
+	}
+	if len(args) > 2 {
+		t := parseBlockIndex(args[2])
+		to = &t
+	}
+	chainID := GetCurrentChainID()
+	data, err := config.WaspClient().ExportBlocks(&chainID, from, to)
+	log.Check(err)
+	log.Check(ioutil.WriteFile(args[0], data, 0644))
+	log.Printf("blocks of chain %s saved to %s\n", chainID.Bech32(), args[0])
+}
+
+func parseBlockIndex(s string) uint32 {
+	ret, err := strconv.ParseUint(s, 10, 32)
+	log.Check(err)
+	return uint32(ret)
+}
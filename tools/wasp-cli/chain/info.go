@@ -13,8 +13,11 @@ func infoCmd(args []string) {
 	chain, err := config.WaspClient().GetChainRecord(GetCurrentChainID())
 	log.Check(err)
 
-	log.Printf("Chain ID: %s\n", chain.ChainID)
+	log.Printf("Chain ID: %s\n", chain.ChainID.Bech32())
 	log.Printf("Committee nodes: %+v\n", chain.CommitteeNodes)
+	if len(chain.AccessNodes) > 0 {
+		log.Printf("Access nodes: %+v\n", chain.AccessNodes)
+	}
 	log.Printf("Active: %v\n", chain.Active)
 
 	if chain.Active {
@@ -33,23 +36,39 @@ func infoCmd(args []string) {
 		log.Check(err)
 		log.Printf("Description: %s\n", description)
 
+		website, _, err := codec.DecodeString(info.MustGet(root.VarWebsite))
+		log.Check(err)
+		if website != "" {
+			log.Printf("Website: %s\n", website)
+		}
+
 		contracts, err := root.DecodeContractRegistry(collections.NewMapReadOnly(info, root.VarContractRegistry))
 		log.Check(err)
 		log.Printf("#Contracts: %d\n", len(contracts))
 
 		ownerID, _, err := codec.DecodeAgentID(info.MustGet(root.VarChainOwnerID))
 		log.Check(err)
-		log.Printf("Owner: %s\n", ownerID)
+		log.Printf("Owner: %s\n", ownerID.Bech32())
 
 		delegated, ok, err := codec.DecodeAgentID(info.MustGet(root.VarChainOwnerIDDelegated))
 		log.Check(err)
 		if ok {
-			log.Printf("Delegated owner: %s\n", delegated)
+			log.Printf("Delegated owner: %s\n", delegated.Bech32())
 		}
 
 		feeColor, defaultOwnerFee, defaultValidatorFee, err := root.GetDefaultFeeInfo(info)
 		log.Check(err)
 		log.Printf("Default owner fee: %d %s\n", defaultOwnerFee, feeColor)
 		log.Printf("Default validator fee: %d %s\n", defaultValidatorFee, feeColor)
+
+		deposit, err := SCClient(root.Interface.Hname()).CallView(root.FuncGetDepositInfo, nil)
+		log.Check(err)
+		minDeposit, _, err := codec.DecodeInt64(deposit.MustGet(root.ParamMinDeposit))
+		log.Check(err)
+		if minDeposit > 0 {
+			policy, _, err := codec.DecodeInt64(deposit.MustGet(root.ParamDepositPolicy))
+			log.Check(err)
+			log.Printf("Request deposit: %d iotas (%s)\n", minDeposit, depositPolicyName(policy))
+		}
 	}
 }
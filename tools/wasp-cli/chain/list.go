@@ -21,7 +21,7 @@ func showChainList(chains []*registry.ChainRecord) {
 	rows := make([][]string, len(chains))
 	for i, chain := range chains {
 		rows[i] = []string{
-			chain.ChainID.String(),
+			chain.ChainID.Bech32(),
 			chain.Color.String(),
 			fmt.Sprintf("%v", chain.CommitteeNodes),
 			fmt.Sprintf("%v", chain.Active),
@@ -18,7 +18,7 @@ func listContractsCmd(args []string) {
 	feeColor, defaultOwnerFee, defaultValidatorFee, err := root.GetDefaultFeeInfo(info)
 	log.Check(err)
 
-	log.Printf("Total %d contracts in chain %s\n", len(contracts), GetCurrentChainID())
+	log.Printf("Total %d contracts in chain %s\n", len(contracts), GetCurrentChainID().Bech32())
 
 	header := []string{
 		"hname",
@@ -0,0 +1,54 @@
+package chain
+
+import (
+	"os"
+
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	cliutil "github.com/iotaledger/wasp/tools/wasp-cli/util"
+)
+
+func metadataCmd(args []string) {
+	chainID := GetCurrentChainID()
+	meta, err := config.WaspClient().GetChainMetadata(&chainID)
+	log.Check(err)
+
+	log.Printf("Description: %s\n", meta.Description)
+	log.Printf("Website: %s\n", meta.Website)
+	for k, v := range meta.Values {
+		log.Printf("%s: %s\n", k, v)
+	}
+}
+
+// setMetadataCmd sets the description, the website or the custom metadata of the chain.
+// Empty value of the website or of the custom metadata removes it
+func setMetadataCmd(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		log.Usage("%s chain set-metadata <description|website|key> [value]\n", os.Args[0])
+	}
+	value := ""
+	if len(args) == 2 {
+		value = args[1]
+	}
+	metaArgs := requestargs.New()
+	switch args[0] {
+	case "description":
+		metaArgs.AddEncodeSimple(root.ParamDescription, codec.EncodeString(value))
+	case "website":
+		metaArgs.AddEncodeSimple(root.ParamWebsite, codec.EncodeString(value))
+	default:
+		metaArgs.AddEncodeSimple(root.ParamMetadataKey, codec.EncodeString(args[0]))
+		metaArgs.AddEncodeSimple(root.ParamMetadataVal, codec.EncodeString(value))
+	}
+	cliutil.WithSCTransaction(func() (*sctransaction.Transaction, error) {
+		return SCClient(root.Interface.Hname()).PostRequest(
+			root.FuncSetChainMetadata,
+			chainclient.PostRequestParams{Args: metaArgs},
+		)
+	})
+}
@@ -0,0 +1,270 @@
+package chain
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/client/scclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/requestargs"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var replBuiltins = []string{"help", "list", "exit", "quit"}
+
+type repl struct {
+	iface  *replInterface
+	client *scclient.SCClient
+	out    io.Writer
+}
+
+func replCmd(args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		log.Fatal("Usage: %s chain repl <name> [interface.json]", os.Args[0])
+	}
+	ifaceFile := ""
+	if len(args) == 2 {
+		ifaceFile = args[1]
+	}
+	iface, err := loadReplInterface(args[0], ifaceFile)
+	log.Check(err)
+
+	r := &repl{
+		iface:  iface,
+		client: SCClient(coretypes.Hn(iface.Name)),
+	}
+	if terminal.IsTerminal(int(os.Stdin.Fd())) {
+		r.runTerminal()
+	} else {
+		r.out = os.Stdout
+		r.runScanner(os.Stdin)
+	}
+}
+
+func (r *repl) prompt() string {
+	return r.iface.Name + "> "
+}
+
+func (r *repl) runTerminal() {
+	fd := int(os.Stdin.Fd())
+	oldState, err := terminal.MakeRaw(fd)
+	log.Check(err)
+	defer terminal.Restore(fd, oldState)
+
+	term := terminal.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, r.prompt())
+	term.AutoCompleteCallback = r.autoComplete
+	r.out = term
+
+	r.printf("Type 'help' for the list of commands, <tab> to complete\n")
+	for {
+		line, err := term.ReadLine()
+		if err != nil {
+			// io.EOF on Ctrl-D
+			return
+		}
+		if !r.execute(line) {
+			return
+		}
+	}
+}
+
+func (r *repl) runScanner(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if !r.execute(scanner.Text()) {
+			return
+		}
+	}
+	log.Check(scanner.Err())
+}
+
+func (r *repl) printf(format string, args ...interface{}) {
+	// the terminal takes care of translating \n into \r\n in raw mode
+	fmt.Fprintf(r.out, format, args...)
+}
+
+// execute runs one line of input. Returns false when the session must be finished
+func (r *repl) execute(line string) bool {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return true
+	}
+	switch words[0] {
+	case "exit", "quit":
+		return false
+	case "help":
+		r.help()
+		return true
+	case "list":
+		r.list()
+		return true
+	}
+	if err := r.call(words[0], words[1:]); err != nil {
+		r.printf("error: %v\n", err)
+	}
+	return true
+}
+
+func (r *repl) help() {
+	r.printf("Commands:\n")
+	r.printf("  <entrypoint> [key=value ...]       call a func or a view of the contract\n")
+	r.printf("  <entrypoint> [key:type=value ...]  same, with explicit type of the value\n")
+	r.printf("  list                               list entry points and their parameters\n")
+	r.printf("  help                               show this message\n")
+	r.printf("  exit, quit                         leave the REPL\n")
+	r.printf("Types: int, string, color, agentid, chainid, hname, hash, base58\n")
+}
+
+func (r *repl) list() {
+	for _, name := range r.iface.entryPointNames() {
+		ep, isView, _ := r.iface.entryPoint(name)
+		kind := "func"
+		if isView {
+			kind = "view"
+		}
+		params := make([]string, 0, len(ep.Params))
+		for _, p := range ep.paramNames() {
+			params = append(params, fmt.Sprintf("%s:%s", p, ep.Params[p]))
+		}
+		r.printf("  %s %s(%s)\n", kind, name, strings.Join(params, ", "))
+	}
+}
+
+func (r *repl) call(fname string, params []string) error {
+	ep, isView, ok := r.iface.entryPoint(fname)
+	if !ok {
+		return fmt.Errorf("unknown entry point '%s'. Type 'list' to see available entry points", fname)
+	}
+	args, err := r.encodeArgs(ep, params)
+	if err != nil {
+		return err
+	}
+	if isView {
+		res, err := r.client.CallView(fname, args)
+		if err != nil {
+			return err
+		}
+		r.printResults(ep, res)
+		return nil
+	}
+	tx, err := r.client.PostRequest(fname, chainclient.PostRequestParams{
+		Args: requestargs.New().AddEncodeSimpleMany(args),
+	})
+	if err != nil {
+		return err
+	}
+	r.printf("Posted transaction %s\n", tx.ID())
+	if config.WaitForCompletion {
+		if err := config.WaspClient().WaitUntilAllRequestsProcessed(tx, 1*time.Minute); err != nil {
+			return err
+		}
+		r.printf("Request processed\n")
+	}
+	return nil
+}
+
+func (r *repl) encodeArgs(ep *replEntryPoint, params []string) (dict.Dict, error) {
+	ret := dict.New()
+	for _, p := range params {
+		parts := strings.SplitN(p, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid parameter '%s': expected key=value", p)
+		}
+		key, value := parts[0], parts[1]
+		vtype := ""
+		if i := strings.Index(key, ":"); i >= 0 {
+			key, vtype = key[:i], key[i+1:]
+		}
+		if vtype == "" {
+			vtype = ep.Params[key]
+		}
+		v, err := encodeReplValue(vtype, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of parameter '%s': %v", key, err)
+		}
+		ret.Set(kv.Key(key), v)
+	}
+	return ret, nil
+}
+
+func (r *repl) printResults(ep *replEntryPoint, res dict.Dict) {
+	if len(res) == 0 {
+		r.printf("(no results)\n")
+		return
+	}
+	keys := make([]string, 0, len(res))
+	for k := range res {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		r.printf("  %s: %s\n", k, decodeReplValue(ep.Results[k], res[kv.Key(k)]))
+	}
+}
+
+// autoComplete completes entry point names in the first word and parameter names in the following ones
+func (r *repl) autoComplete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' || pos != len(line) {
+		return "", 0, false
+	}
+	start := strings.LastIndex(line, " ") + 1
+	prefix := line[start:]
+	var candidates []string
+	if start == 0 || strings.TrimSpace(line[:start]) == "" {
+		candidates = append(r.iface.entryPointNames(), replBuiltins...)
+	} else {
+		ep, _, ok := r.iface.entryPoint(strings.Fields(line)[0])
+		if !ok || strings.Contains(prefix, "=") {
+			return "", 0, false
+		}
+		for _, p := range ep.paramNames() {
+			candidates = append(candidates, p+"=")
+		}
+	}
+	matches := make([]string, 0)
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", 0, false
+	case 1:
+		completed := line[:start] + matches[0]
+		if !strings.HasSuffix(completed, "=") {
+			completed += " "
+		}
+		return completed, len(completed), true
+	}
+	common := commonPrefix(matches)
+	if len(common) > len(prefix) {
+		completed := line[:start] + common
+		return completed, len(completed), true
+	}
+	r.printf("%s\n", strings.Join(matches, "  "))
+	return "", 0, false
+}
+
+func commonPrefix(ss []string) string {
+	ret := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, ret) {
+			ret = ret[:len(ret)-1]
+		}
+	}
+	return ret
+}
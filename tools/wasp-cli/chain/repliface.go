@@ -0,0 +1,230 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/coretypes/coreutil"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/codec"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/packages/vm/core/blob"
+	"github.com/iotaledger/wasp/packages/vm/core/eventlog"
+	"github.com/iotaledger/wasp/packages/vm/core/root"
+	"github.com/mr-tron/base58"
+)
+
+// replInterface describes the entry points of a contract together with the types of their
+// parameters and results. It is loaded from a JSON file, for example:
+//   {
+//     "name": "inccounter",
+//     "funcs": {"increment": {}, "incrementRepeatMany": {"params": {"numRepeats": "int"}}},
+//     "views": {"getCounter": {"results": {"counter": "int"}}}
+//   }
+// Supported types are: int, string, color, agentid, chainid, hname, hash, base58
+type replInterface struct {
+	Name  string                     `json:"name"`
+	Funcs map[string]*replEntryPoint `json:"funcs"`
+	Views map[string]*replEntryPoint `json:"views"`
+}
+
+type replEntryPoint struct {
+	Params  map[string]string `json:"params"`
+	Results map[string]string `json:"results"`
+}
+
+var coreInterfaces = []*coreutil.ContractInterface{
+	root.Interface,
+	accounts.Interface,
+	blob.Interface,
+	eventlog.Interface,
+}
+
+// loadReplInterface reads the interface description from the file or,
+// if the file name is empty, takes entry points from the core contract with the given name
+func loadReplInterface(name string, fname string) (*replInterface, error) {
+	if fname != "" {
+		data, err := ioutil.ReadFile(fname)
+		if err != nil {
+			return nil, err
+		}
+		ret := &replInterface{}
+		if err := json.Unmarshal(data, ret); err != nil {
+			return nil, fmt.Errorf("invalid interface description %s: %v", fname, err)
+		}
+		if ret.Name == "" {
+			ret.Name = name
+		}
+		ret.normalize()
+		return ret, nil
+	}
+	for _, iface := range coreInterfaces {
+		if iface.Name != name {
+			continue
+		}
+		ret := &replInterface{Name: name}
+		ret.normalize()
+		for _, f := range iface.Functions {
+			if f.Hname() == coretypes.EntryPointInit {
+				continue
+			}
+			if f.IsView() {
+				ret.Views[f.Name] = &replEntryPoint{}
+			} else {
+				ret.Funcs[f.Name] = &replEntryPoint{}
+			}
+		}
+		ret.normalize()
+		return ret, nil
+	}
+	return nil, fmt.Errorf("no interface description for contract '%s'. Provide the interface file", name)
+}
+
+func (iface *replInterface) normalize() {
+	if iface.Funcs == nil {
+		iface.Funcs = make(map[string]*replEntryPoint)
+	}
+	if iface.Views == nil {
+		iface.Views = make(map[string]*replEntryPoint)
+	}
+	for _, eps := range []map[string]*replEntryPoint{iface.Funcs, iface.Views} {
+		for name, ep := range eps {
+			if ep == nil {
+				ep = &replEntryPoint{}
+				eps[name] = ep
+			}
+			if ep.Params == nil {
+				ep.Params = make(map[string]string)
+			}
+			if ep.Results == nil {
+				ep.Results = make(map[string]string)
+			}
+		}
+	}
+}
+
+// entryPoint returns the entry point description and whether it is a view
+func (iface *replInterface) entryPoint(name string) (*replEntryPoint, bool, bool) {
+	if ep, ok := iface.Funcs[name]; ok {
+		return ep, false, true
+	}
+	if ep, ok := iface.Views[name]; ok {
+		return ep, true, true
+	}
+	return nil, false, false
+}
+
+func (iface *replInterface) entryPointNames() []string {
+	ret := make([]string, 0, len(iface.Funcs)+len(iface.Views))
+	for name := range iface.Funcs {
+		ret = append(ret, name)
+	}
+	for name := range iface.Views {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (ep *replEntryPoint) paramNames() []string {
+	ret := make([]string, 0, len(ep.Params))
+	for name := range ep.Params {
+		ret = append(ret, name)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func encodeReplValue(vtype string, s string) ([]byte, error) {
+	switch vtype {
+	case "", "string":
+		return codec.EncodeString(s), nil
+	case "int":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return codec.EncodeInt64(n), nil
+	case "color":
+		col, err := util.ColorFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		return codec.EncodeColor(col), nil
+	case "agentid":
+		agentID, err := coretypes.NewAgentIDFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		return codec.EncodeAgentID(agentID), nil
+	case "chainid":
+		chainID, err := coretypes.NewChainIDFromString(s)
+		if err != nil {
+			return nil, err
+		}
+		return codec.EncodeChainID(chainID), nil
+	case "hname":
+		return codec.EncodeHname(coretypes.Hn(s)), nil
+	case "hash":
+		h, err := hashing.HashValueFromBase58(s)
+		if err != nil {
+			return nil, err
+		}
+		return codec.EncodeHashValue(h), nil
+	case "base58":
+		return base58.Decode(s)
+	}
+	return nil, fmt.Errorf("unsupported type '%s'", vtype)
+}
+
+func decodeReplValue(vtype string, b []byte) string {
+	switch vtype {
+	case "string":
+		if s, _, err := codec.DecodeString(b); err == nil {
+			return strconv.Quote(s)
+		}
+	case "int":
+		if n, _, err := codec.DecodeInt64(b); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case "color":
+		if col, _, err := codec.DecodeColor(b); err == nil {
+			return col.String()
+		}
+	case "agentid":
+		if agentID, _, err := codec.DecodeAgentID(b); err == nil {
+			return agentID.Bech32()
+		}
+	case "chainid":
+		if chainID, _, err := codec.DecodeChainID(b); err == nil {
+			return chainID.Bech32()
+		}
+	case "hname":
+		if hn, _, err := codec.DecodeHname(b); err == nil {
+			return hn.String()
+		}
+	case "hash":
+		if h, _, err := codec.DecodeHashValue(b); err == nil {
+			return h.String()
+		}
+	}
+	if isPrintable(b) {
+		return strconv.Quote(string(b))
+	}
+	return "base58:" + base58.Encode(b)
+}
+
+func isPrintable(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	return strings.IndexFunc(string(b), func(r rune) bool {
+		return r < 0x20 || r > 0x7e
+	}) < 0
+}
@@ -0,0 +1,42 @@
+package chain
+
+import (
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+)
+
+// reshareCmd distributes new shares of the chain key among the nodes given by --committee.
+// The chain address stays the same. The DKG is initiated by the node of the config, which must
+// hold a share of the key. The chain record is not changed, the committee is rotated separately
+func reshareCmd(args []string) {
+	chainID := GetCurrentChainID()
+	record, err := config.WaspClient().GetChainRecord(chainID)
+	log.Check(err)
+
+	_, peeringHosts := resolveCommittee(committee)
+	n := len(peeringHosts)
+	t := quorum
+	if t == 0 {
+		t = defaultQuorum(n)
+	}
+	if t < 1 || t > n {
+		log.Fatal("quorum must be between 1 and the committee size %d", n)
+	}
+
+	sharedAddress := address.Address(chainID)
+	dkShares, err := config.WaspClient().DKSharesReshare(&sharedAddress, &model.DKSharesReshareRequest{
+		OldPeerNetIDs: record.CommitteeNodes,
+		PeerNetIDs:    peeringHosts,
+		Threshold:     uint16(t),
+		TimeoutMS:     60000, // 1 min
+	})
+	log.Check(err)
+
+	log.Printf("Key of the chain %s reshared\n", chainID.Bech32())
+	log.Printf("Address: %s\n", dkShares.Address)
+	log.Printf("Old committee nodes: %+v\n", record.CommitteeNodes)
+	log.Printf("New committee nodes: %+v\n", peeringHosts)
+	log.Printf("Quorum: %d\n", t)
+}
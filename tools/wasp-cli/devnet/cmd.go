@@ -0,0 +1,49 @@
+package devnet
+
+import (
+	"os"
+	"strings"
+
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/spf13/pflag"
+)
+
+var (
+	l1BindAddress  string
+	apiBindAddress string
+	debug          bool
+)
+
+func InitCommands(commands map[string]func([]string), flags *pflag.FlagSet) {
+	commands["devnet"] = devnetCmd
+
+	fs := pflag.NewFlagSet("devnet", pflag.ExitOnError)
+	fs.StringVarP(&l1BindAddress, "devnet-l1", "", "127.0.0.1:8080", "bind address of the UTXODB ledger API of the devnet")
+	fs.StringVarP(&apiBindAddress, "devnet-api", "", "127.0.0.1:9090", "bind address of the Wasp web API of the devnet")
+	fs.BoolVarP(&debug, "devnet-debug", "", false, "debug logging of the devnet")
+	flags.AddFlagSet(fs)
+}
+
+var subcmds = map[string]func([]string){
+	"up": upCmd,
+}
+
+func devnetCmd(args []string) {
+	if len(args) < 1 {
+		usage()
+	}
+	subcmd, ok := subcmds[args[0]]
+	if !ok {
+		usage()
+	}
+	subcmd(args[1:])
+}
+
+func usage() {
+	cmdNames := make([]string, 0)
+	for k := range subcmds {
+		cmdNames = append(cmdNames, k)
+	}
+
+	log.Usage("%s devnet [%s]\n", os.Args[0], strings.Join(cmdNames, "|"))
+}
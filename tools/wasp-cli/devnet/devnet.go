@@ -0,0 +1,96 @@
+package devnet
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"testing"
+
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/tools/wasp-cli/chain"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/iotaledger/wasp/tools/wasp-cli/wallet"
+	"github.com/labstack/echo/v4"
+	"github.com/spf13/viper"
+)
+
+const (
+	devnetChainAlias = "devnet"
+	// devnetNetID identifies the only node of the devnet in the committee of the chain
+	devnetNetID = "devnet:0"
+)
+
+// upCmd starts the local single-node devnet: the Solo environment with one chain,
+// the UTXODB ledger API with the faucet and the Wasp web API of the chain.
+// wasp-cli is configured to use the devnet, so all chain, wallet and blob commands work against it
+func upCmd(args []string) {
+	if len(args) != 0 {
+		log.Usage("%s devnet up\n", os.Args[0])
+	}
+	ch := startChain()
+
+	l1 := echo.New()
+	l1.HideBanner = true
+	(&l1API{env: ch.Env}).addEndpoints(l1)
+	api := echo.New()
+	api.HideBanner = true
+	(&waspAPI{ch: ch, netID: devnetNetID}).addEndpoints(api)
+
+	go serve(l1, l1BindAddress)
+	go serve(api, apiBindAddress)
+
+	configure(ch)
+	if viper.GetString("wallet.seed") != "" {
+		addr := wallet.Load().Address()
+		log.Check(ch.Env.RequestFunds(addr))
+		log.Printf("funded wallet address %s\n", addr)
+	}
+
+	log.Printf("devnet is up\n")
+	log.Printf("  chain ID: %s (alias '%s')\n", ch.ChainID, devnetChainAlias)
+	log.Printf("  UTXODB ledger API: %s\n", l1BindAddress)
+	log.Printf("  Wasp web API: %s\n", apiBindAddress)
+	log.Printf("Use `%s request-funds` to get iotas from the faucet. Press Ctrl-C to stop\n", os.Args[0])
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	<-interrupt
+	log.Printf("devnet stopped. The state of the chain is discarded\n")
+}
+
+// startChain creates the Solo environment and deploys the chain.
+// Solo reports failures through the context of the test. The devnet runs without the test, so the
+// environment is created with an empty context in a separate goroutine: a failure ends the goroutine
+func startChain() *solo.Chain {
+	t := &testing.T{}
+	var ch *solo.Chain
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		env := solo.New(t, debug, false)
+		ch = env.NewChain(nil, devnetChainAlias)
+	}()
+	<-done
+	if ch == nil || t.Failed() {
+		log.Fatal("failed to start the devnet chain")
+	}
+	return ch
+}
+
+func serve(e *echo.Echo, bindAddress string) {
+	if err := e.Start(bindAddress); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal("devnet: serving %s: %v", bindAddress, err)
+	}
+}
+
+// configure points wasp-cli to the devnet
+func configure(ch *solo.Chain) {
+	config.Set("utxodb", true)
+	config.Set(config.GoshimmerApiConfigVar(), l1BindAddress)
+	config.Set("wasp."+config.HostKindApi, apiBindAddress)
+	config.Set(config.CommitteeApiConfigVar(0), apiBindAddress)
+	config.Set(config.CommitteePeeringConfigVar(0), devnetNetID)
+	chain.AddChainAlias(devnetChainAlias, ch.ChainID.String())
+}
@@ -0,0 +1,85 @@
+package devnet
+
+import (
+	"net/http"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/address"
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/transaction"
+	"github.com/iotaledger/goshimmer/dapps/waspconn/packages/apilib"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/labstack/echo/v4"
+	"github.com/mr-tron/base58"
+)
+
+// l1API is the UTXODB API of Goshimmer, as used by wasp-cli with `utxodb` set to true,
+// backed by the ledger of the Solo environment. The faucet of the ledger is the faucet of the devnet
+type l1API struct {
+	env *solo.Solo
+}
+
+func (l *l1API) addEndpoints(e *echo.Echo) {
+	e.GET("/utxodb/outputs/:address", l.handleGetAddressOutputs)
+	e.GET("/utxodb/confirmed/:txid", l.handleIsConfirmed)
+	e.POST("/utxodb/tx", l.handlePostTransaction)
+	e.GET("/utxodb/requestfunds/:address", l.handleRequestFunds)
+}
+
+func (l *l1API) handleGetAddressOutputs(c echo.Context) error {
+	addr, err := address.FromBase58(c.Param("address"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &apilib.GetAccountOutputsResponse{Err: err.Error()})
+	}
+	out := make(map[string][]apilib.OutputBalance)
+	for txOutID, txOutputs := range l.env.GetAddressOutputs(addr) {
+		txOut := make([]apilib.OutputBalance, len(txOutputs))
+		for i, txOutput := range txOutputs {
+			txOut[i] = apilib.OutputBalance{
+				Value: txOutput.Value,
+				Color: transaction.ID(txOutput.Color).String(),
+			}
+		}
+		out[txOutID.String()] = txOut
+	}
+	return c.JSON(http.StatusOK, &apilib.GetAccountOutputsResponse{
+		Address: c.Param("address"),
+		Outputs: out,
+	})
+}
+
+func (l *l1API) handlePostTransaction(c echo.Context) error {
+	var req apilib.PostTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, &apilib.PostTransactionResponse{Err: err.Error()})
+	}
+	txBytes, err := base58.Decode(req.Tx)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &apilib.PostTransactionResponse{Err: err.Error()})
+	}
+	tx, _, err := transaction.FromBytes(txBytes)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &apilib.PostTransactionResponse{Err: err.Error()})
+	}
+	if err := l.env.PostTransaction(tx); err != nil {
+		return c.JSON(http.StatusConflict, &apilib.PostTransactionResponse{Err: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &apilib.PostTransactionResponse{})
+}
+
+func (l *l1API) handleIsConfirmed(c echo.Context) error {
+	txid, err := transaction.IDFromBase58(c.Param("txid"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &apilib.IsConfirmedResponse{Err: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &apilib.IsConfirmedResponse{Confirmed: l.env.IsConfirmed(txid)})
+}
+
+func (l *l1API) handleRequestFunds(c echo.Context) error {
+	addr, err := address.FromBase58(c.Param("address"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, &apilib.RequestFundsResponse{Err: err.Error()})
+	}
+	if err := l.env.RequestFunds(addr); err != nil {
+		return c.JSON(http.StatusInternalServerError, &apilib.RequestFundsResponse{Err: err.Error()})
+	}
+	return c.JSON(http.StatusOK, &apilib.RequestFundsResponse{})
+}
@@ -0,0 +1,208 @@
+package devnet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/hashing"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/registry"
+	"github.com/iotaledger/wasp/packages/solo"
+	"github.com/iotaledger/wasp/packages/vm/viewcontext"
+	"github.com/iotaledger/wasp/packages/webapi/httperrors"
+	"github.com/iotaledger/wasp/packages/webapi/model"
+	"github.com/iotaledger/wasp/packages/webapi/routes"
+	"github.com/labstack/echo/v4"
+)
+
+// waspAPI is the subset of the web API of the Wasp node used by wasp-cli and by dApp clients:
+// info, view calls, request status, blobs and the chain record. The endpoints have the same
+// routes and models as in the node and are backed by the Solo chain of the devnet
+type waspAPI struct {
+	ch *solo.Chain
+	// netID is the network ID of the devnet node, the only committee node of the chain
+	netID string
+}
+
+func (w *waspAPI) addEndpoints(e *echo.Echo) {
+	e.HTTPErrorHandler = httpErrorHandler
+
+	e.GET(routes.Info(), w.handleInfo)
+	e.GET(routes.CallView(":contractID", ":fname"), w.handleCallView)
+	e.GET(routes.RequestStatus(":chainID", ":reqID"), w.handleRequestStatus)
+	e.GET(routes.WaitRequestProcessed(":chainID", ":reqID"), w.handleWaitRequestProcessed)
+	e.GET(routes.PutBlob(), w.handlePutBlob)
+	e.GET(routes.GetBlob(":hash"), w.handleGetBlob)
+	e.GET(routes.HasBlob(":hash"), w.handleHasBlob)
+	e.GET(routes.GetChainRecord(":chainID"), w.handleGetChainRecord)
+	e.GET(routes.ListChainRecords(), w.handleGetChainRecordList)
+}
+
+// httpErrorHandler is the same as in the webapi plugin of the node
+func httpErrorHandler(err error, c echo.Context) {
+	he, ok := err.(*httperrors.HTTPError)
+	if ok {
+		if !c.Response().Committed {
+			if c.Request().Method == http.MethodHead {
+				err = c.NoContent(he.Code)
+			} else {
+				err = c.JSON(he.Code, he)
+			}
+		}
+	}
+	c.Echo().DefaultHTTPErrorHandler(err, c)
+}
+
+func (w *waspAPI) handleInfo(c echo.Context) error {
+	return c.JSON(http.StatusOK, model.InfoResponse{
+		Version:   "devnet",
+		NetworkId: w.netID,
+	})
+}
+
+func (w *waspAPI) checkChainID(chainID *coretypes.ChainID) error {
+	if *chainID != w.ch.ChainID {
+		return httperrors.NotFound(fmt.Sprintf("Chain not found: %s", chainID))
+	}
+	return nil
+}
+
+func (w *waspAPI) handleCallView(c echo.Context) error {
+	contractID, err := coretypes.NewContractIDFromBase58(c.Param("contractID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid contract ID: %+v", c.Param("contractID")))
+	}
+	chainID := contractID.ChainID()
+	if err := w.checkChainID(&chainID); err != nil {
+		return err
+	}
+	var params dict.Dict
+	if c.Request().Body != nil {
+		if err := json.NewDecoder(c.Request().Body).Decode(&params); err != nil {
+			return httperrors.BadRequest("Invalid request body")
+		}
+	}
+	ret, err := w.ch.CallViewByHname(contractID.Hname(), coretypes.Hn(c.Param("fname")), params)
+	if errors.Is(err, viewcontext.ErrTimeout) {
+		return httperrors.Timeout(fmt.Sprintf("View call failed: %v", err))
+	}
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("View call failed: %v", err))
+	}
+	return c.JSON(http.StatusOK, ret)
+}
+
+func (w *waspAPI) parseRequestParams(c echo.Context) (*coretypes.RequestID, error) {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return nil, httperrors.BadRequest(fmt.Sprintf("Invalid chain ID %+v: %s", c.Param("chainID"), err.Error()))
+	}
+	if err := w.checkChainID(&chainID); err != nil {
+		return nil, err
+	}
+	reqID, err := coretypes.NewRequestIDFromBase58(c.Param("reqID"))
+	if err != nil {
+		return nil, httperrors.BadRequest(fmt.Sprintf("Invalid request id %+v: %s", c.Param("reqID"), err.Error()))
+	}
+	return &reqID, nil
+}
+
+func (w *waspAPI) handleRequestStatus(c echo.Context) error {
+	reqID, err := w.parseRequestParams(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.RequestStatusResponse{
+		IsProcessed: w.ch.IsRequestProcessed(*reqID),
+	})
+}
+
+func (w *waspAPI) handleWaitRequestProcessed(c echo.Context) error {
+	reqID, err := w.parseRequestParams(c)
+	if err != nil {
+		return err
+	}
+	req := model.WaitRequestProcessedParams{
+		Timeout: model.WaitRequestProcessedDefaultTimeout,
+	}
+	if c.Request().Header.Get("Content-Type") == "application/json" {
+		if err := c.Bind(&req); err != nil {
+			return httperrors.BadRequest("Invalid request body")
+		}
+	}
+	deadline := time.Now().Add(req.Timeout)
+	for !w.ch.IsRequestProcessed(*reqID) {
+		if time.Now().After(deadline) {
+			return httperrors.Timeout("Timeout while waiting for request to be processed")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil
+}
+
+func (w *waspAPI) handlePutBlob(c echo.Context) error {
+	var req model.BlobData
+	if err := c.Bind(&req); err != nil {
+		return httperrors.BadRequest(err.Error())
+	}
+	hash, err := w.ch.Env.BlobCache().PutBlob(req.Data.Bytes())
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewBlobInfo(true, hash))
+}
+
+func (w *waspAPI) handleGetBlob(c echo.Context) error {
+	hash, err := hashing.HashValueFromBase58(c.Param("hash"))
+	if err != nil {
+		return httperrors.BadRequest("Invalid hash")
+	}
+	data, ok, err := w.ch.Env.BlobCache().GetBlob(hash)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return httperrors.NotFound(fmt.Sprintf("Blob not found: %s", hash.String()))
+	}
+	return c.JSON(http.StatusOK, model.NewBlobData(data))
+}
+
+func (w *waspAPI) handleHasBlob(c echo.Context) error {
+	hash, err := hashing.HashValueFromBase58(c.Param("hash"))
+	if err != nil {
+		return httperrors.BadRequest("Invalid hash")
+	}
+	ok, err := w.ch.Env.BlobCache().HasBlob(hash)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewBlobInfo(ok, hash))
+}
+
+func (w *waspAPI) chainRecord() *registry.ChainRecord {
+	return &registry.ChainRecord{
+		ChainID:        w.ch.ChainID,
+		Color:          w.ch.ChainColor,
+		CommitteeNodes: []string{w.netID},
+		Active:         true,
+	}
+}
+
+func (w *waspAPI) handleGetChainRecord(c echo.Context) error {
+	chainID, err := coretypes.NewChainIDFromString(c.Param("chainID"))
+	if err != nil {
+		return httperrors.BadRequest(fmt.Sprintf("Invalid chain ID: %+v", c.Param("chainID")))
+	}
+	if err := w.checkChainID(&chainID); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewChainRecord(w.chainRecord()))
+}
+
+func (w *waspAPI) handleGetChainRecordList(c echo.Context) error {
+	return c.JSON(http.StatusOK, []*model.ChainRecord{model.NewChainRecord(w.chainRecord())})
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/iotaledger/wasp/tools/wasp-cli/chain"
 	"github.com/iotaledger/wasp/tools/wasp-cli/config"
 	"github.com/iotaledger/wasp/tools/wasp-cli/decode"
+	"github.com/iotaledger/wasp/tools/wasp-cli/devnet"
 	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/iotaledger/wasp/tools/wasp-cli/registry"
 	"github.com/iotaledger/wasp/tools/wasp-cli/wallet"
 	"github.com/spf13/pflag"
 )
@@ -35,6 +37,8 @@ func main() {
 	chain.InitCommands(commands, flags)
 	decode.InitCommands(commands, flags)
 	blob.InitCommands(commands, flags)
+	registry.InitCommands(commands, flags)
+	devnet.InitCommands(commands, flags)
 
 	log.Check(flags.Parse(os.Args[1:]))
 
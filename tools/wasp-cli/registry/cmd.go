@@ -0,0 +1,67 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/spf13/pflag"
+)
+
+func InitCommands(commands map[string]func([]string), flags *pflag.FlagSet) {
+	commands["registry"] = registryCmd
+}
+
+var subcmds = map[string]func([]string){
+	"export": exportCmd,
+	"import": importCmd,
+	"pubkey": pubKeyCmd,
+}
+
+func registryCmd(args []string) {
+	if len(args) < 1 {
+		usage()
+	}
+	subcmd, ok := subcmds[args[0]]
+	if !ok {
+		usage()
+	}
+	subcmd(args[1:])
+}
+
+func usage() {
+	cmdNames := make([]string, 0)
+	for k := range subcmds {
+		cmdNames = append(cmdNames, k)
+	}
+
+	log.Usage("%s registry [%s]\n", os.Args[0], strings.Join(cmdNames, "|"))
+}
+
+func exportCmd(args []string) {
+	if len(args) != 2 {
+		log.Usage("%s registry export <filename> <importer-pubkey>\n", os.Args[0])
+	}
+	data, err := config.WaspClient().ExportRegistry(args[1])
+	log.Check(err)
+	log.Check(ioutil.WriteFile(args[0], data, 0600))
+	log.Printf("Registry bundle of %s saved to %s (%d bytes)\n", config.WaspApi(), args[0], len(data))
+}
+
+func importCmd(args []string) {
+	if len(args) != 2 {
+		log.Usage("%s registry import <filename> <exporter-pubkey>\n", os.Args[0])
+	}
+	data, err := ioutil.ReadFile(args[0])
+	log.Check(err)
+	log.Check(config.WaspClient().ImportRegistry(data, args[1]))
+	log.Printf("Registry bundle %s imported into %s\n", args[0], config.WaspApi())
+}
+
+func pubKeyCmd(args []string) {
+	info, err := config.WaspClient().Info()
+	log.Check(err)
+	log.Printf("%s\n", info.PubKey)
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/sctransaction"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	"github.com/spf13/viper"
+)
+
+// trackedChainsConfigVar lists the chains the wallet has interacted with, so that balances
+// can be aggregated over all of them
+const trackedChainsConfigVar = "wallet.chains"
+
+// TrackChain adds the chain to the list of chains the wallet has interacted with
+func TrackChain(chainID coretypes.ChainID) {
+	s := chainID.String()
+	chains := viper.GetStringSlice(trackedChainsConfigVar)
+	for _, c := range chains {
+		if c == s {
+			return
+		}
+	}
+	config.Set(trackedChainsConfigVar, append(chains, s))
+}
+
+// TrackedChains returns the chains the wallet has interacted with
+func TrackedChains() []coretypes.ChainID {
+	ret := make([]coretypes.ChainID, 0)
+	for _, s := range viper.GetStringSlice(trackedChainsConfigVar) {
+		chainID, err := coretypes.NewChainIDFromString(s)
+		log.Check(err)
+		ret = append(ret, chainID)
+	}
+	return ret
+}
+
+// ChainAlias returns the alias of the chain in the configuration, or the chain ID if the chain has no alias
+func ChainAlias(chainID coretypes.ChainID) string {
+	for alias, s := range viper.GetStringMapString("chains") {
+		if id, err := coretypes.NewChainIDFromString(s); err == nil && id == chainID {
+			return alias
+		}
+	}
+	return chainID.Bech32()
+}
+
+func trackTargetChains(tx *sctransaction.Transaction) {
+	for _, req := range tx.Requests() {
+		TrackChain(req.Target().ChainID())
+	}
+}
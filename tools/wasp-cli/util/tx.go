@@ -29,6 +29,7 @@ func WithTransaction(f func() (*transaction.Transaction, error)) *transaction.Tr
 func WithSCTransaction(f func() (*sctransaction.Transaction, error), forceWait ...bool) *sctransaction.Transaction {
 	tx, err := f()
 	log.Check(err)
+	trackTargetChains(tx)
 
 	log.Printf("Posted transaction %s\n", tx.ID())
 	if config.WaitForCompletion || (len(forceWait) > 0) {
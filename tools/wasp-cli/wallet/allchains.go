@@ -0,0 +1,152 @@
+package wallet
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/iotaledger/goshimmer/dapps/valuetransfers/packages/balance"
+	"github.com/iotaledger/wasp/client/chainclient"
+	"github.com/iotaledger/wasp/packages/coretypes"
+	"github.com/iotaledger/wasp/packages/kv"
+	"github.com/iotaledger/wasp/packages/kv/dict"
+	"github.com/iotaledger/wasp/packages/txutil"
+	"github.com/iotaledger/wasp/packages/util"
+	"github.com/iotaledger/wasp/packages/vm/core/accounts"
+	"github.com/iotaledger/wasp/tools/wasp-cli/config"
+	"github.com/iotaledger/wasp/tools/wasp-cli/log"
+	cliutil "github.com/iotaledger/wasp/tools/wasp-cli/util"
+)
+
+var allChains bool
+
+// ledgerEntry is the balance of one color in one location: the address on L1 or the account on a chain
+type ledgerEntry struct {
+	location string
+	color    balance.Color
+	amount   int64
+}
+
+// allChainsBalanceCmd displays the consolidated ledger of the wallet address: the L1 balance and the balances
+// of the on-chain account of the address in every chain the wallet has interacted with
+func allChainsBalanceCmd() {
+	wallet := Load()
+	address := wallet.Address()
+	agentID := coretypes.NewAgentIDFromAddress(address)
+
+	outs, err := config.GoshimmerClient().GetConfirmedAccountOutputs(&address)
+	log.Check(err)
+	l1, _ := txutil.OutputBalancesByColor(outs)
+
+	entries := make([]ledgerEntry, 0)
+	for color, amount := range l1 {
+		entries = append(entries, ledgerEntry{location: "L1", color: color, amount: amount})
+	}
+
+	tracked := cliutil.TrackedChains()
+	clients := make([]*chainclient.Client, 0, len(tracked))
+	for _, chainID := range tracked {
+		client := chainclient.New(config.GoshimmerClient(), config.WaspClient(), chainID, wallet.SignatureScheme())
+		bals, err := accountBalances(client, agentID)
+		if err != nil {
+			// the chain may be deactivated or not served by the node: the rest of the ledger is still shown
+			log.Printf("Warning: can't query chain %s: %v\n", cliutil.ChainAlias(chainID), err)
+			continue
+		}
+		clients = append(clients, client)
+		for color, amount := range bals {
+			entries = append(entries, ledgerEntry{location: cliutil.ChainAlias(chainID), color: color, amount: amount})
+		}
+	}
+
+	log.Printf("Address index %d\n", addressIndex)
+	log.Printf("  Address:  %s\n", address)
+	log.Printf("  Agent ID: %s\n", agentID.Bech32())
+	log.Printf("  Chains:   %d\n", len(tracked))
+
+	meta := make(map[balance.Color]*accounts.TokenMetadata)
+	totals := make(map[balance.Color]int64)
+	for _, e := range entries {
+		if _, ok := meta[e.color]; !ok {
+			meta[e.color] = tokenMetadata(clients, e.color)
+		}
+		totals[e.color] += e.amount
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].location != entries[j].location {
+			// L1 first
+			return entries[i].location == "L1" || (entries[j].location != "L1" && entries[i].location < entries[j].location)
+		}
+		return entries[i].color.String() < entries[j].color.String()
+	})
+
+	header := []string{"location", "color", "amount", "token"}
+	rows := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, []string{e.location, e.color.String(), fmt.Sprintf("%d", e.amount), formatToken(meta[e.color], e.amount)})
+	}
+	log.PrintTable(header, rows)
+
+	log.Printf("\nTotal:\n")
+	colors := make([]balance.Color, 0, len(totals))
+	for color := range totals {
+		colors = append(colors, color)
+	}
+	sort.Slice(colors, func(i, j int) bool { return colors[i].String() < colors[j].String() })
+	rows = make([][]string, 0, len(colors))
+	for _, color := range colors {
+		rows = append(rows, []string{color.String(), fmt.Sprintf("%d", totals[color]), formatToken(meta[color], totals[color])})
+	}
+	log.PrintTable([]string{"color", "amount", "token"}, rows)
+}
+
+func accountBalances(client *chainclient.Client, agentID coretypes.AgentID) (map[balance.Color]int64, error) {
+	ret, err := client.CallView(accounts.Interface.Hname(), accounts.FuncBalance, dict.FromGoMap(map[kv.Key][]byte{
+		accounts.ParamAgentID: agentID[:],
+	}))
+	if err != nil {
+		return nil, err
+	}
+	bals := make(map[balance.Color]int64)
+	for k, v := range ret {
+		color, _, err := balance.ColorFromBytes([]byte(k))
+		if err != nil {
+			return nil, err
+		}
+		amount, err := util.Uint64From8Bytes(v)
+		if err != nil {
+			return nil, err
+		}
+		bals[color] = int64(amount)
+	}
+	return bals, nil
+}
+
+// tokenMetadata returns metadata of the colored token from the first chain the token is registered on, or nil
+func tokenMetadata(clients []*chainclient.Client, color balance.Color) *accounts.TokenMetadata {
+	if color == balance.ColorIOTA {
+		return nil
+	}
+	for _, client := range clients {
+		ret, err := client.CallView(accounts.Interface.Hname(), accounts.FuncGetTokenMetadata, dict.FromGoMap(map[kv.Key][]byte{
+			accounts.ParamColor: color[:],
+		}))
+		if err != nil {
+			continue
+		}
+		data := ret.MustGet(accounts.ParamTokenMetadata)
+		if data == nil {
+			continue
+		}
+		if meta, err := accounts.DecodeTokenMetadata(data); err == nil {
+			return meta
+		}
+	}
+	return nil
+}
+
+func formatToken(meta *accounts.TokenMetadata, amount int64) string {
+	if meta == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", meta.FormatAmount(amount), meta.Name)
+}
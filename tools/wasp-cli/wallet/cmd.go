@@ -14,5 +14,6 @@ func InitCommands(commands map[string]func([]string), flags *pflag.FlagSet) {
 
 	fs := pflag.NewFlagSet("wallet", pflag.ExitOnError)
 	fs.IntVarP(&addressIndex, "address-index", "i", 0, "address index")
+	fs.BoolVar(&allChains, "all-chains", false, "balance: include accounts on all chains the wallet has interacted with")
 	flags.AddFlagSet(fs)
 }
@@ -18,6 +18,10 @@ func addressCmd(args []string) {
 }
 
 func balanceCmd(args []string) {
+	if allChains {
+		allChainsBalanceCmd()
+		return
+	}
 	wallet := Load()
 	address := wallet.Address()
 